@@ -2,19 +2,49 @@
 
 package api
 
-import "github.com/netapp/trident/storage"
+import (
+	"encoding/json"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_class"
+)
 
 type Backend struct {
-	Name   string `json:"name"`
-	Config struct {
-		Version           int      `json:"version"`
-		StorageDriverName string   `json:"storageDriverName"`
-		StoragePrefix     string   `json:"storagePrefix"`
-		SerialNumbers     []string `json:"serialNumbers"`
-	} `json:"config"`
-	Storage interface{} `json:"storage"`
-	Online  bool        `json:"online"`
-	Volumes []string    `json:"volumes"`
+	Name        string        `json:"name"`
+	BackendUUID string        `json:"backendUUID"`
+	Config      BackendConfig `json:"config"`
+	Storage     interface{}   `json:"storage"`
+	Online      bool          `json:"online"`
+	Cordoned    bool          `json:"cordoned"`
+	Degraded    string        `json:"degraded,omitempty"`
+	Volumes     []string      `json:"volumes"`
+}
+
+// BackendConfig holds the settings of a backend common to all drivers, while keeping the driver's
+// own settings in Settings so that they survive being written out again.
+type BackendConfig struct {
+	Version           int      `json:"version"`
+	StorageDriverName string   `json:"storageDriverName"`
+	StoragePrefix     string   `json:"storagePrefix"`
+	SerialNumbers     []string `json:"serialNumbers"`
+
+	Settings map[string]interface{} `json:"-"`
+}
+
+type backendConfigFields BackendConfig
+
+func (c *BackendConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*backendConfigFields)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.Settings)
+}
+
+func (c BackendConfig) MarshalJSON() ([]byte, error) {
+	if c.Settings != nil {
+		return json.Marshal(c.Settings)
+	}
+	return json.Marshal(backendConfigFields(c))
 }
 
 type GetBackendResponse struct {
@@ -26,15 +56,32 @@ type MultipleBackendResponse struct {
 	Items []Backend `json:"items"`
 }
 
+// Pool is a storage pool as reported in a backend's storage map, along with the name of its backend.
+type Pool struct {
+	Backend        string                     `json:"backend"`
+	Name           string                     `json:"name"`
+	StorageClasses []string                   `json:"storageClasses"`
+	Attributes     map[string]json.RawMessage `json:"storageAttributes"`
+	VolumeCount    int                        `json:"volumeCount"`
+	Capacity       *storage.PoolCapacity      `json:"capacity,omitempty"`
+}
+
+type MultiplePoolResponse struct {
+	Items []Pool `json:"items"`
+}
+
 type StorageClass struct {
 	Config struct {
 		Version         string              `json:"version"`
 		Name            string              `json:"name"`
+		Aliases         []string            `json:"aliases,omitempty"`
+		Revision        int                 `json:"revision,omitempty"`
 		Attributes      interface{}         `json:"attributes"`
 		Pools           map[string][]string `json:"storagePools"`
 		AdditionalPools map[string][]string `json:"additionalStoragePools"`
 	} `json:"Config"`
-	Storage interface{} `json:"storage"`
+	Storage    map[string][]string      `json:"storage"`
+	Validation *storageclass.Validation `json:"validation,omitempty"`
 }
 
 type GetStorageClassResponse struct {
@@ -42,6 +89,20 @@ type GetStorageClassResponse struct {
 	Error        string `json:"error"`
 }
 
+// UpdateStorageClassResponse is the updated storage class, along with the storage pools it gained
+// and lost, the volumes left on pools it lost, and the volumes changed to match it.
+type UpdateStorageClassResponse struct {
+	Update struct {
+		StorageClass    StorageClass        `json:"storageClass"`
+		AddedPools      map[string][]string `json:"addedPools"`
+		RemovedPools    map[string][]string `json:"removedPools"`
+		StrandedVolumes []string            `json:"strandedVolumes"`
+		UpdatedVolumes  []string            `json:"updatedVolumes"`
+		FailedVolumes   map[string]string   `json:"failedVolumes,omitempty"`
+	} `json:"update"`
+	Error string `json:"error"`
+}
+
 type MultipleStorageClassResponse struct {
 	Items []StorageClass `json:"items"`
 }
@@ -50,6 +111,22 @@ type MultipleVolumeResponse struct {
 	Items []storage.VolumeExternal `json:"items"`
 }
 
+type MultipleVolumeStatsResponse struct {
+	Items []*storage.VolumeStatsReport `json:"items"`
+}
+
+type MultipleSnapshotScheduleResponse struct {
+	Items []storage.SnapshotScheduleExternal `json:"items"`
+}
+
+type MultipleVolumeGroupResponse struct {
+	Items []storage.VolumeGroupExternal `json:"items"`
+}
+
+type MultipleVolumeMigrationResponse struct {
+	Items []storage.VolumeMigration `json:"items"`
+}
+
 type Version struct {
 	Version       string `json:"version"`
 	MajorVersion  uint   `json:"majorVersion"`
@@ -0,0 +1,88 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(cordonCmd)
+	RootCmd.AddCommand(uncordonCmd)
+}
+
+var cordonCmd = &cobra.Command{
+	Use:   "cordon <backend> [<backend>...]",
+	Short: "Stop provisioning new volumes on one or more backends",
+	Long: "Cordon one or more backends, so that their existing volumes continue to work but no " +
+		"new volumes or clones are provisioned on them, such as during a storage upgrade.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"cordon"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return backendCordon(args, true)
+		}
+	},
+}
+
+var uncordonCmd = &cobra.Command{
+	Use:   "uncordon <backend> [<backend>...]",
+	Short: "Resume provisioning new volumes on one or more backends",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"uncordon"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return backendCordon(args, false)
+		}
+	},
+}
+
+func backendCordon(backendNames []string, cordoned bool) error {
+
+	if len(backendNames) == 0 {
+		return errors.New("backend name not specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	method, action := "POST", "cordon"
+	if !cordoned {
+		method, action = "DELETE", "uncordon"
+	}
+
+	backends := make([]api.Backend, 0, len(backendNames))
+
+	for _, backendName := range backendNames {
+		url := baseURL + "/backend/" + backendName + "/cordon"
+
+		response, responseBody, err := api.InvokeRESTAPI(method, url, nil, Debug)
+		if err != nil {
+			return err
+		} else if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not %s backend %s. %v", action, backendName, response.Status)
+		}
+
+		var getBackendResponse api.GetBackendResponse
+		if err = json.Unmarshal(responseBody, &getBackendResponse); err != nil {
+			return err
+		}
+		backends = append(backends, getBackendResponse.Backend)
+	}
+
+	WriteBackends(backends)
+
+	return nil
+}
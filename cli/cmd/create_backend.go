@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -15,24 +16,39 @@ import (
 
 	"github.com/netapp/trident/cli/api"
 	"github.com/netapp/trident/frontend/rest"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 var filename string
 var b64Data string
+var sampleDriver string
 
 func init() {
 	createCmd.AddCommand(createBackendCmd)
 	createBackendCmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to YAML or JSON file")
 	createBackendCmd.Flags().StringVarP(&b64Data, "base64", "", "", "Base64 encoding")
 	createBackendCmd.Flags().MarkHidden("base64")
+	createBackendCmd.Flags().StringVarP(&sampleDriver, "sample", "", "",
+		"Print a commented sample config for a storage driver instead of adding a backend")
 }
 
 var createBackendCmd = &cobra.Command{
 	Use:     "backend",
 	Short:   "Add a backend to Trident",
 	Aliases: []string{"b"},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Samples are generated locally, so Trident needn't be reachable
+		if sampleDriver != "" {
+			return nil
+		}
+		return discoverOperatingMode(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 
+		if sampleDriver != "" {
+			return backendSample(sampleDriver)
+		}
+
 		jsonData, err := getBackendCreateData()
 		if err != nil {
 			return err
@@ -69,8 +85,8 @@ func getBackendCreateData() ([]byte, error) {
 		return nil, err
 	}
 
-	// Ensure the file is valid JSON/YAML, and return JSON
-	jsonData, err := yaml.YAMLToJSON(rawData)
+	// Ensure the file is valid JSON/YAML, and return JSON.  Comments, as in sample configs, are removed.
+	jsonData, err := yaml.YAMLToJSON(drivers.StripConfigComments(rawData))
 	if err != nil {
 		return nil, err
 	}
@@ -115,3 +131,15 @@ func backendCreate(postData []byte) error {
 
 	return nil
 }
+
+func backendSample(driverName string) error {
+
+	sample, err := drivers.SampleConfig(driverName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(sample)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleSpec         string
+	scheduleRetain       int
+	scheduleVolumes      []string
+	scheduleStorageClass string
+)
+
+func init() {
+	createCmd.AddCommand(createSnapshotScheduleCmd)
+	createSnapshotScheduleCmd.Flags().StringVar(&scheduleSpec, "schedule", "",
+		"When to take snapshots, in crontab format and UTC, such as \"0 * * * *\" or @daily")
+	createSnapshotScheduleCmd.Flags().IntVar(&scheduleRetain, "retain", 0,
+		"Number of the schedule's snapshots of each volume to keep")
+	createSnapshotScheduleCmd.Flags().StringSliceVar(&scheduleVolumes, "volume", []string{},
+		"Volumes to snapshot")
+	createSnapshotScheduleCmd.Flags().StringVar(&scheduleStorageClass, "storage-class", "",
+		"Storage class whose volumes to snapshot")
+}
+
+var createSnapshotScheduleCmd = &cobra.Command{
+	Use:   "snapshotschedule <name>",
+	Short: "Add a snapshot schedule to Trident",
+	Long: "Take snapshots of the named volumes, and of the volumes in a storage class, on a " +
+		"schedule.  The oldest of the schedule's snapshots of each volume are deleted so that " +
+		"only --retain of them remain.",
+	Aliases: []string{"ss"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"create", "snapshotschedule",
+				"--schedule", scheduleSpec, "--retain", strconv.Itoa(scheduleRetain)}
+			for _, volume := range scheduleVolumes {
+				command = append(command, "--volume", volume)
+			}
+			if scheduleStorageClass != "" {
+				command = append(command, "--storage-class", scheduleStorageClass)
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return snapshotScheduleCreate(args)
+		}
+	},
+}
+
+func snapshotScheduleCreate(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("a single snapshot schedule name must be specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	postData, err := json.Marshal(&storage.SnapshotScheduleConfig{
+		Name:         args[0],
+		Schedule:     scheduleSpec,
+		Volumes:      scheduleVolumes,
+		StorageClass: scheduleStorageClass,
+		Retain:       scheduleRetain,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/snapshotschedule"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, postData, Debug)
+	if err != nil {
+		return err
+	}
+
+	var addScheduleResponse rest.AddSnapshotScheduleResponse
+	err = json.Unmarshal(responseBody, &addScheduleResponse)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusCreated {
+		if addScheduleResponse.Error != "" {
+			return fmt.Errorf("could not add snapshot schedule %s: %s", args[0], addScheduleResponse.Error)
+		}
+		return errors.New(response.Status)
+	}
+
+	// Retrieve the newly created schedule and write to stdout
+	schedule, err := GetSnapshotSchedule(baseURL, addScheduleResponse.SnapshotScheduleID)
+	if err != nil {
+		return err
+	}
+
+	WriteSnapshotSchedules([]storage.SnapshotScheduleExternal{schedule})
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+func init() {
+	createCmd.AddCommand(createVolumeGroupCmd)
+	createVolumeGroupCmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to YAML or JSON file")
+	createVolumeGroupCmd.Flags().StringVarP(&b64Data, "base64", "", "", "Base64 encoding")
+	createVolumeGroupCmd.Flags().MarkHidden("base64")
+}
+
+var createVolumeGroupCmd = &cobra.Command{
+	Use:     "volumegroup",
+	Short:   "Create a group of related volumes in Trident, or clone an existing group",
+	Aliases: []string{"vg"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		jsonData, err := getVolumeGroupCreateData()
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{"create", "volumegroup", "--base64", base64.StdEncoding.EncodeToString(jsonData)}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeGroupCreate(jsonData)
+		}
+	},
+}
+
+func getVolumeGroupCreateData() ([]byte, error) {
+
+	var err error
+	var rawData []byte
+
+	if b64Data == "" && filename == "" {
+		return nil, errors.New("no input file was specified")
+	}
+
+	// Read from file or stdin or b64 data
+	if b64Data != "" {
+		rawData, err = base64.StdEncoding.DecodeString(b64Data)
+	} else if filename == "-" {
+		rawData, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		rawData, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the file is valid JSON/YAML, and return JSON
+	return yaml.YAMLToJSON(rawData)
+}
+
+func volumeGroupCreate(postData []byte) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/volumegroup"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, postData, Debug)
+	if err != nil {
+		return err
+	} else if response.StatusCode != http.StatusCreated {
+		return errors.New(response.Status)
+	}
+
+	var addGroupResponse rest.AddVolumeGroupResponse
+	err = json.Unmarshal(responseBody, &addGroupResponse)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the newly created group and write to stdout
+	group, err := GetVolumeGroup(baseURL, addGroupResponse.VolumeGroupID)
+	if err != nil {
+		return err
+	}
+
+	WriteVolumeGroups([]storage.VolumeGroupExternal{group})
+
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	deleteCmd.AddCommand(deleteMigrationCmd)
+}
+
+var deleteMigrationCmd = &cobra.Command{
+	Use:     "migration",
+	Short:   "Cancel one or more volume migrations, or delete the original volumes of those that cut over",
+	Aliases: []string{"migrations"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"delete", "migration"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return migrationDelete(args)
+		}
+	},
+}
+
+func migrationDelete(volumeNames []string) error {
+
+	if len(volumeNames) == 0 {
+		return errors.New("volume name not specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	for _, volumeName := range volumeNames {
+		url := baseURL + "/migration/" + volumeName
+
+		response, _, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
+		if err != nil {
+			return err
+		} else if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not cancel migration of volume %s. %v", volumeName, response.Status)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	deleteCmd.AddCommand(deleteSnapshotScheduleCmd)
+}
+
+var deleteSnapshotScheduleCmd = &cobra.Command{
+	Use:     "snapshotschedule",
+	Short:   "Delete one or more snapshot schedules from Trident, keeping the snapshots they took",
+	Aliases: []string{"ss", "snapshotschedules"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"delete", "snapshotschedule"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return snapshotScheduleDelete(args)
+		}
+	},
+}
+
+func snapshotScheduleDelete(scheduleNames []string) error {
+
+	if len(scheduleNames) == 0 {
+		return errors.New("snapshot schedule name not specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	for _, scheduleName := range scheduleNames {
+		url := baseURL + "/snapshotschedule/" + scheduleName
+
+		response, _, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
+		if err != nil {
+			return err
+		} else if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not delete snapshot schedule %s. %v", scheduleName, response.Status)
+		}
+	}
+
+	return nil
+}
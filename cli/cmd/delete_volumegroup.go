@@ -0,0 +1,56 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	deleteCmd.AddCommand(deleteVolumeGroupCmd)
+}
+
+var deleteVolumeGroupCmd = &cobra.Command{
+	Use:     "volumegroup",
+	Short:   "Delete one or more volume groups from Trident, along with their volumes",
+	Aliases: []string{"vg", "volumegroups"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"delete", "volumegroup"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeGroupDelete(args)
+		}
+	},
+}
+
+func volumeGroupDelete(groupNames []string) error {
+
+	if len(groupNames) == 0 {
+		return errors.New("volume group name not specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	for _, groupName := range groupNames {
+		url := baseURL + "/volumegroup/" + groupName
+
+		response, _, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
+		if err != nil {
+			return err
+		} else if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not delete volume group %s. %v", groupName, response.Status)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var detachForce bool
+
+func init() {
+	RootCmd.AddCommand(detachCmd)
+	detachCmd.Flags().BoolVar(&detachForce, "force", false,
+		"Detach the volumes even if containers on the host still have them mounted")
+}
+
+var detachCmd = &cobra.Command{
+	Use:   "detach <volume> [<volume>...] --force",
+	Short: "Force one or more volumes to be detached from the host running Trident",
+	Long: "Detach volumes that Trident's Docker plugin left attached to its host, such as when " +
+		"an unmount was lost, even if the plugin believes containers still have them mounted. " +
+		"Volumes are otherwise detached when the last container using them unmounts them.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"detach"}
+			if detachForce {
+				command = append(command, "--force")
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeDetach(args)
+		}
+	},
+}
+
+func volumeDetach(volumeNames []string) error {
+
+	if len(volumeNames) == 0 {
+		return errors.New("volume name not specified")
+	}
+	if !detachForce {
+		return errors.New("volumes are detached when they are no longer mounted; use --force to " +
+			"detach them while they may still be in use")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	detached := make([]rest.ForceDetachVolumeResponse, 0, len(volumeNames))
+
+	for _, volumeName := range volumeNames {
+		url := baseURL + "/volume/" + volumeName + "/detach"
+
+		response, responseBody, err := api.InvokeRESTAPI("POST", url, nil, Debug)
+		if err != nil {
+			return err
+		}
+
+		var detachResponse rest.ForceDetachVolumeResponse
+		if err = json.Unmarshal(responseBody, &detachResponse); err != nil {
+			return err
+		}
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not detach volume %s: %v", volumeName, detachResponse.Error)
+		}
+		detached = append(detached, detachResponse)
+	}
+
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(detached)
+	case FormatYAML:
+		WriteYAML(detached)
+	default:
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Volume", "Released mounts"})
+		for _, volume := range detached {
+			table.Append([]string{volume.Volume, strings.Join(volume.ReleasedMounts, "\n")})
+		}
+		table.Render()
+	}
+
+	return nil
+}
@@ -15,8 +15,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var getBackendDetail bool
+
 func init() {
 	getCmd.AddCommand(getBackendCmd)
+	getBackendCmd.Flags().BoolVar(&getBackendDetail, "detail", false,
+		"Show each backend's whole config, less credentials, instead of a summary")
 }
 
 var getBackendCmd = &cobra.Command{
@@ -26,6 +30,9 @@ var getBackendCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if OperatingMode == ModeTunnel {
 			command := []string{"get", "backend"}
+			if getBackendDetail {
+				command = append(command, "--detail")
+			}
 			TunnelCommand(append(command, args...))
 			return nil
 		} else {
@@ -54,7 +61,7 @@ func backendList(backendNames []string) error {
 	// Get the actual backend objects
 	for _, backendName := range backendNames {
 
-		backend, err := GetBackend(baseURL, backendName)
+		backend, err := getBackend(baseURL, backendName, getBackendDetail)
 		if err != nil {
 			return err
 		}
@@ -87,19 +94,28 @@ func GetBackends(baseURL string) ([]string, error) {
 }
 
 func GetBackend(baseURL, backendName string) (api.Backend, error) {
+	return getBackend(baseURL, backendName, false)
+}
+
+// getBackend gets a backend, with its driver's whole config, less credentials, if detail is set.
+func getBackend(baseURL, backendName string, detail bool) (api.Backend, error) {
 
 	url := baseURL + "/backend/" + backendName
+	if detail {
+		url += "?detail=true"
+	}
 
 	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
 	if err != nil {
 		return api.Backend{}, err
-	} else if response.StatusCode != http.StatusOK {
-		return api.Backend{}, fmt.Errorf("could not get backend %s. %v", backendName, response.Status)
 	}
 
 	var getBackendResponse api.GetBackendResponse
 	err = json.Unmarshal(responseBody, &getBackendResponse)
-	if err != nil {
+	if response.StatusCode != http.StatusOK {
+		return api.Backend{}, fmt.Errorf("could not get backend %s. %v %s", backendName, response.Status,
+			getBackendResponse.Error)
+	} else if err != nil {
 		return api.Backend{}, err
 	}
 
@@ -114,6 +130,8 @@ func WriteBackends(backends []api.Backend) {
 		WriteYAML(api.MultipleBackendResponse{backends})
 	case FormatName:
 		writeBackendNames(backends)
+	case FormatWide:
+		writeWideBackendTable(backends)
 	default:
 		writeBackendTable(backends)
 	}
@@ -122,13 +140,34 @@ func WriteBackends(backends []api.Backend) {
 func writeBackendTable(backends []api.Backend) {
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Storage Driver", "Online", "Volumes"})
+	table.SetHeader([]string{"Name", "Storage Driver", "Online", "Cordoned", "Volumes"})
+
+	for _, b := range backends {
+		table.Append([]string{
+			b.Name,
+			b.Config.StorageDriverName,
+			strconv.FormatBool(b.Online),
+			strconv.FormatBool(b.Cordoned),
+			strconv.Itoa(len(b.Volumes)),
+		})
+	}
+
+	table.Render()
+}
+
+func writeWideBackendTable(backends []api.Backend) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "UUID", "Storage Driver", "Online", "Cordoned", "Degraded", "Volumes"})
 
 	for _, b := range backends {
 		table.Append([]string{
 			b.Name,
+			b.BackendUUID,
 			b.Config.StorageDriverName,
 			strconv.FormatBool(b.Online),
+			strconv.FormatBool(b.Cordoned),
+			strconv.FormatBool(b.Degraded != ""),
 			strconv.Itoa(len(b.Volumes)),
 		})
 	}
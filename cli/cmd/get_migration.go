@@ -0,0 +1,175 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	getCmd.AddCommand(getMigrationCmd)
+}
+
+var getMigrationCmd = &cobra.Command{
+	Use:     "migration",
+	Short:   "Get the progress of one or more volume migrations from Trident",
+	Aliases: []string{"migrations"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "migration"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return migrationList(args)
+		}
+	},
+}
+
+func migrationList(volumeNames []string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// If no volumes were specified, we'll get all migrations
+	if len(volumeNames) == 0 {
+		volumeNames, err = GetVolumeMigrations(baseURL)
+		if err != nil {
+			return err
+		}
+		sort.Strings(volumeNames)
+	}
+
+	migrations := make([]storage.VolumeMigration, 0, 10)
+
+	for _, volumeName := range volumeNames {
+
+		migration, err := GetVolumeMigration(baseURL, volumeName)
+		if err != nil {
+			return err
+		}
+		migrations = append(migrations, migration)
+	}
+
+	WriteVolumeMigrations(migrations)
+
+	return nil
+}
+
+func GetVolumeMigrations(baseURL string) ([]string, error) {
+
+	url := baseURL + "/migration"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get volume migrations. %v", response.Status)
+	}
+
+	var listMigrationsResponse rest.ListVolumeMigrationsResponse
+	err = json.Unmarshal(responseBody, &listMigrationsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listMigrationsResponse.Migrations, nil
+}
+
+func GetVolumeMigration(baseURL, volumeName string) (storage.VolumeMigration, error) {
+
+	url := baseURL + "/migration/" + volumeName
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return storage.VolumeMigration{}, err
+	} else if response.StatusCode != http.StatusOK {
+		return storage.VolumeMigration{}, fmt.Errorf("could not get migration of volume %s. %v",
+			volumeName, response.Status)
+	}
+
+	var getMigrationResponse rest.GetVolumeMigrationResponse
+	err = json.Unmarshal(responseBody, &getMigrationResponse)
+	if err != nil {
+		return storage.VolumeMigration{}, err
+	}
+
+	return *getMigrationResponse.Migration, nil
+}
+
+func WriteVolumeMigrations(migrations []storage.VolumeMigration) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleVolumeMigrationResponse{migrations})
+	case FormatYAML:
+		WriteYAML(api.MultipleVolumeMigrationResponse{migrations})
+	case FormatName:
+		writeVolumeMigrationNames(migrations)
+	case FormatWide:
+		writeWideVolumeMigrationTable(migrations)
+	default:
+		writeVolumeMigrationTable(migrations)
+	}
+}
+
+func writeVolumeMigrationTable(migrations []storage.VolumeMigration) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Volume", "From", "To", "State", "Message"})
+
+	for _, migration := range migrations {
+		table.Append([]string{
+			migration.Volume,
+			migration.SourceBackend,
+			migration.Backend,
+			string(migration.State),
+			migration.Message,
+		})
+	}
+
+	table.Render()
+}
+
+func writeWideVolumeMigrationTable(migrations []storage.VolumeMigration) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Volume", "From", "To", "Pool", "Method", "State", "Transfers",
+		"Last Transfer (s)", "Max Disruption (s)", "Job", "Message"})
+
+	for _, migration := range migrations {
+		table.Append([]string{
+			migration.Volume,
+			migration.SourceBackend,
+			migration.Backend,
+			migration.Pool,
+			migration.Method,
+			string(migration.State),
+			strconv.Itoa(migration.Passes),
+			strconv.FormatFloat(migration.LastTransferSeconds, 'f', 0, 64),
+			strconv.Itoa(migration.MaxDisruptionSeconds),
+			migration.Job,
+			migration.Message,
+		})
+	}
+
+	table.Render()
+}
+
+func writeVolumeMigrationNames(migrations []storage.VolumeMigration) {
+
+	for _, migration := range migrations {
+		fmt.Println(migration.Volume)
+	}
+}
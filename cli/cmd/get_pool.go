@@ -0,0 +1,222 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/netapp/trident/cli/api"
+	sa "github.com/netapp/trident/storage_attribute"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	getCmd.AddCommand(getPoolCmd)
+}
+
+var getPoolCmd = &cobra.Command{
+	Use:     "pool [<backend>...]",
+	Short:   "Get the storage pools of one or more backends from Trident",
+	Aliases: []string{"p", "pools"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "pool"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return poolList(args)
+		}
+	},
+}
+
+func poolList(backendNames []string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// If no backends were specified, we'll get the pools of all of them
+	if len(backendNames) == 0 {
+		backendNames, err = GetBackends(baseURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	pools := make([]api.Pool, 0, 10)
+
+	for _, backendName := range backendNames {
+
+		backend, err := GetBackend(baseURL, backendName)
+		if err != nil {
+			return err
+		}
+
+		backendPools, err := getBackendPools(backend)
+		if err != nil {
+			return err
+		}
+		pools = append(pools, backendPools...)
+	}
+
+	WritePools(pools)
+
+	return nil
+}
+
+// getBackendPools extracts the storage pools from a backend, sorted by name.
+func getBackendPools(backend api.Backend) ([]api.Pool, error) {
+
+	// The storage map is left untyped in the backend, so round-trip it through JSON
+	storageBytes, err := json.Marshal(backend.Storage)
+	if err != nil {
+		return nil, err
+	}
+	storagePools := make(map[string]api.Pool)
+	if err = json.Unmarshal(storageBytes, &storagePools); err != nil {
+		return nil, fmt.Errorf("could not parse the storage pools of backend %s; %v", backend.Name, err)
+	}
+
+	pools := make([]api.Pool, 0, len(storagePools))
+	for _, pool := range storagePools {
+		pool.Backend = backend.Name
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	return pools, nil
+}
+
+func WritePools(pools []api.Pool) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultiplePoolResponse{pools})
+	case FormatYAML:
+		WriteYAML(api.MultiplePoolResponse{pools})
+	case FormatName:
+		writePoolNames(pools)
+	case FormatWide:
+		writeWidePoolTable(pools)
+	default:
+		writePoolTable(pools)
+	}
+}
+
+func writePoolTable(pools []api.Pool) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Backend", "Pool", "Media", "Features", "Volumes", "Available"})
+
+	for _, pool := range pools {
+		table.Append([]string{
+			pool.Backend,
+			pool.Name,
+			poolStringOffer(pool, sa.Media),
+			poolFeatures(pool),
+			strconv.Itoa(pool.VolumeCount),
+			poolAvailable(pool),
+		})
+	}
+
+	table.Render()
+}
+
+func writeWidePoolTable(pools []api.Pool) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{
+		"Backend",
+		"Pool",
+		"Media",
+		"Provisioning",
+		"Features",
+		"Storage Classes",
+		"Volumes",
+		"Available",
+		"Used",
+		"Total",
+	}
+	table.SetHeader(header)
+
+	for _, pool := range pools {
+		table.Append([]string{
+			pool.Backend,
+			pool.Name,
+			poolStringOffer(pool, sa.Media),
+			poolStringOffer(pool, sa.ProvisioningType),
+			poolFeatures(pool),
+			strings.Join(pool.StorageClasses, "\n"),
+			strconv.Itoa(pool.VolumeCount),
+			poolAvailable(pool),
+			poolUsed(pool),
+			poolTotal(pool),
+		})
+	}
+
+	table.Render()
+}
+
+func writePoolNames(pools []api.Pool) {
+
+	for _, pool := range pools {
+		fmt.Printf("%s:%s\n", pool.Backend, pool.Name)
+	}
+}
+
+// poolStringOffer returns the comma-separated values a pool offers for a string attribute.
+func poolStringOffer(pool api.Pool, attribute string) string {
+
+	var offer struct {
+		Offers []string `json:"offer"`
+	}
+	if raw, ok := pool.Attributes[attribute]; !ok || json.Unmarshal(raw, &offer) != nil {
+		return ""
+	}
+	return strings.Join(offer.Offers, ",")
+}
+
+// poolFeatures returns the boolean attributes, such as snapshots and clones, that a pool offers.
+func poolFeatures(pool api.Pool) string {
+
+	features := make([]string, 0)
+	for _, attribute := range []string{sa.Snapshots, sa.Clones, sa.Encryption} {
+		var offer struct {
+			Offer bool `json:"offer"`
+		}
+		if raw, ok := pool.Attributes[attribute]; ok && json.Unmarshal(raw, &offer) == nil && offer.Offer {
+			features = append(features, attribute)
+		}
+	}
+	return strings.Join(features, ",")
+}
+
+// poolAvailable formats a pool's available space, leaving it blank if the driver didn't report capacity.
+func poolAvailable(pool api.Pool) string {
+	if pool.Capacity == nil {
+		return ""
+	}
+	return humanize.IBytes(pool.Capacity.AvailableBytes)
+}
+
+// poolUsed and poolTotal format space that not every driver can report, so zero is left blank.
+func poolUsed(pool api.Pool) string {
+	if pool.Capacity == nil || pool.Capacity.UsedBytes == 0 {
+		return ""
+	}
+	return humanize.IBytes(pool.Capacity.UsedBytes)
+}
+
+func poolTotal(pool api.Pool) string {
+	if pool.Capacity == nil || pool.Capacity.TotalBytes == 0 {
+		return ""
+	}
+	return humanize.IBytes(pool.Capacity.TotalBytes)
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/netapp/trident/cli/api"
+	sa "github.com/netapp/trident/storage_attribute"
+)
+
+// backendJSON is a backend as the REST API returns it, with two pools.
+const backendJSON = `{
+	"name": "ontapnas",
+	"storage": {
+		"aggr2": {
+			"name": "aggr2",
+			"storageClasses": [],
+			"storageAttributes": {
+				"media": {"offer": ["ssd"]},
+				"snapshots": {"offer": true},
+				"clones": {"offer": false}
+			},
+			"volumeCount": 0
+		},
+		"aggr1": {
+			"name": "aggr1",
+			"storageClasses": ["gold", "silver"],
+			"storageAttributes": {
+				"media": {"offer": ["hdd", "hybrid"]},
+				"provisioningType": {"offer": ["thick", "thin"]},
+				"snapshots": {"offer": true},
+				"clones": {"offer": true},
+				"encryption": {"offer": true}
+			},
+			"volumeCount": 3,
+			"capacity": {"totalBytes": 2147483648, "usedBytes": 1073741824, "availableBytes": 1073741824}
+		}
+	}
+}`
+
+func TestGetBackendPools(t *testing.T) {
+	var backend api.Backend
+	if err := json.Unmarshal([]byte(backendJSON), &backend); err != nil {
+		t.Fatal("Unable to parse backend: ", err)
+	}
+
+	pools, err := getBackendPools(backend)
+	if err != nil {
+		t.Fatal("Unable to get pools: ", err)
+	}
+	if len(pools) != 2 || pools[0].Name != "aggr1" || pools[1].Name != "aggr2" {
+		t.Fatalf("Expected pools aggr1 and aggr2 in order, got %+v", pools)
+	}
+	for _, pool := range pools {
+		if pool.Backend != "ontapnas" {
+			t.Errorf("Expected pool %s to name its backend, got %s", pool.Name, pool.Backend)
+		}
+	}
+
+	aggr1, aggr2 := pools[0], pools[1]
+	if media := poolStringOffer(aggr1, sa.Media); media != "hdd,hybrid" {
+		t.Errorf("Expected media hdd,hybrid, got %s", media)
+	}
+	if provisioning := poolStringOffer(aggr2, sa.ProvisioningType); provisioning != "" {
+		t.Errorf("Expected no provisioning types, got %s", provisioning)
+	}
+	if features := poolFeatures(aggr1); features != "snapshots,clones,encryption" {
+		t.Errorf("Expected every feature, got %s", features)
+	}
+	if features := poolFeatures(aggr2); features != "snapshots" {
+		t.Errorf("Expected only snapshots, got %s", features)
+	}
+
+	// Capacity is blank if it wasn't reported
+	if aggr1.VolumeCount != 3 || poolAvailable(aggr1) != "1.0 GiB" || poolTotal(aggr1) != "2.0 GiB" {
+		t.Errorf("Expected 3 volumes and 1 GiB of 2 GiB available, got %d, %s and %s",
+			aggr1.VolumeCount, poolAvailable(aggr1), poolTotal(aggr1))
+	}
+	if poolAvailable(aggr2) != "" || poolUsed(aggr2) != "" {
+		t.Error("Expected no capacity for a pool that didn't report it")
+	}
+}
+
+func TestGetBackendPoolsInvalid(t *testing.T) {
+	backend := api.Backend{Name: "broken", Storage: []string{"aggr1"}}
+	if _, err := getBackendPools(backend); err == nil {
+		t.Error("Expected storage that isn't a map of pools to be rejected")
+	}
+}
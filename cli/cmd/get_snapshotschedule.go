@@ -0,0 +1,161 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	getCmd.AddCommand(getSnapshotScheduleCmd)
+}
+
+var getSnapshotScheduleCmd = &cobra.Command{
+	Use:     "snapshotschedule",
+	Short:   "Get one or more snapshot schedules from Trident",
+	Aliases: []string{"ss", "snapshotschedules"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "snapshotschedule"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return snapshotScheduleList(args)
+		}
+	},
+}
+
+func snapshotScheduleList(scheduleNames []string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// If no schedules were specified, we'll get all of them
+	if len(scheduleNames) == 0 {
+		scheduleNames, err = GetSnapshotSchedules(baseURL)
+		if err != nil {
+			return err
+		}
+		sort.Strings(scheduleNames)
+	}
+
+	schedules := make([]storage.SnapshotScheduleExternal, 0, 10)
+
+	// Get the actual schedule objects
+	for _, scheduleName := range scheduleNames {
+
+		schedule, err := GetSnapshotSchedule(baseURL, scheduleName)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	WriteSnapshotSchedules(schedules)
+
+	return nil
+}
+
+func GetSnapshotSchedules(baseURL string) ([]string, error) {
+
+	url := baseURL + "/snapshotschedule"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get snapshot schedules. %v", response.Status)
+	}
+
+	var listSchedulesResponse rest.ListSnapshotSchedulesResponse
+	err = json.Unmarshal(responseBody, &listSchedulesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listSchedulesResponse.SnapshotSchedules, nil
+}
+
+func GetSnapshotSchedule(baseURL, scheduleName string) (storage.SnapshotScheduleExternal, error) {
+
+	url := baseURL + "/snapshotschedule/" + scheduleName
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return storage.SnapshotScheduleExternal{}, err
+	} else if response.StatusCode != http.StatusOK {
+		return storage.SnapshotScheduleExternal{}, fmt.Errorf("could not get snapshot schedule %s. %v",
+			scheduleName, response.Status)
+	}
+
+	var getScheduleResponse rest.GetSnapshotScheduleResponse
+	err = json.Unmarshal(responseBody, &getScheduleResponse)
+	if err != nil {
+		return storage.SnapshotScheduleExternal{}, err
+	}
+
+	return *getScheduleResponse.SnapshotSchedule, nil
+}
+
+func WriteSnapshotSchedules(schedules []storage.SnapshotScheduleExternal) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleSnapshotScheduleResponse{schedules})
+	case FormatYAML:
+		WriteYAML(api.MultipleSnapshotScheduleResponse{schedules})
+	case FormatName:
+		writeSnapshotScheduleNames(schedules)
+	default:
+		writeSnapshotScheduleTable(schedules)
+	}
+}
+
+func writeSnapshotScheduleTable(schedules []storage.SnapshotScheduleExternal) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Schedule", "Retain", "Volumes", "Storage Class", "Next Run", "Errors"})
+
+	for _, schedule := range schedules {
+
+		failures := 0
+		for _, volumeState := range schedule.State.Volumes {
+			if volumeState.Error != "" {
+				failures++
+			}
+		}
+
+		table.Append([]string{
+			schedule.Config.Name,
+			schedule.Config.Schedule,
+			strconv.Itoa(schedule.Config.Retain),
+			strings.Join(schedule.Config.Volumes, ","),
+			schedule.Config.StorageClass,
+			schedule.NextRun.Format(time.RFC3339),
+			strconv.Itoa(failures),
+		})
+	}
+
+	table.Render()
+}
+
+func writeSnapshotScheduleNames(schedules []storage.SnapshotScheduleExternal) {
+
+	for _, schedule := range schedules {
+		fmt.Println(schedule.Config.Name)
+	}
+}
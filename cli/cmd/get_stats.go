@@ -0,0 +1,150 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var statsInterval int
+
+func init() {
+	getCmd.AddCommand(getStatsCmd)
+	getStatsCmd.Flags().IntVar(&statsInterval, "interval", 5,
+		"Seconds over which to measure each volume's performance, from 1 to 20")
+}
+
+var getStatsCmd = &cobra.Command{
+	Use:     "stats [<volume>...]",
+	Short:   "Get the performance of one or more volumes from Trident",
+	Long:    "Measure the IOPS, throughput and latency of volumes whose backends can report them, busiest first.",
+	Aliases: []string{"stat"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "stats", "--interval", strconv.Itoa(statsInterval)}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return statsList(args)
+		}
+	},
+}
+
+func statsList(volumeNames []string) error {
+
+	if statsInterval < 1 || statsInterval > 20 {
+		return fmt.Errorf("interval must be from 1 to 20 seconds")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// Every volume is sampled at once, so that only one interval is spent however many are wanted
+	url := fmt.Sprintf("%s/stats/volume?interval=%d", baseURL, statsInterval)
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return err
+	} else if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not get volume statistics. %v", response.Status)
+	}
+
+	var listResponse rest.ListVolumeStatsResponse
+	if err = json.Unmarshal(responseBody, &listResponse); err != nil {
+		return err
+	}
+
+	reports := listResponse.Items
+	if len(volumeNames) > 0 {
+		reportsByVolume := make(map[string]*storage.VolumeStatsReport, len(reports))
+		for _, report := range reports {
+			reportsByVolume[report.Volume] = report
+		}
+		reports = make([]*storage.VolumeStatsReport, 0, len(volumeNames))
+		for _, volumeName := range volumeNames {
+			report, ok := reportsByVolume[volumeName]
+			if !ok {
+				return fmt.Errorf("no statistics were reported for volume %s", volumeName)
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	// Busiest first, so that noisy neighbors stand out
+	sort.SliceStable(reports, func(i, j int) bool {
+		return totalIOPS(reports[i]) > totalIOPS(reports[j])
+	})
+
+	WriteVolumeStats(reports)
+
+	return nil
+}
+
+func totalIOPS(report *storage.VolumeStatsReport) float64 {
+	if report.Rates == nil {
+		return -1
+	}
+	return report.Rates.TotalIOPS
+}
+
+func WriteVolumeStats(reports []*storage.VolumeStatsReport) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleVolumeStatsResponse{reports})
+	case FormatYAML:
+		WriteYAML(api.MultipleVolumeStatsResponse{reports})
+	case FormatName:
+		writeVolumeStatsNames(reports)
+	default:
+		writeVolumeStatsTable(reports)
+	}
+}
+
+func writeVolumeStatsTable(reports []*storage.VolumeStatsReport) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Volume", "Backend", "Read IOPS", "Write IOPS", "Total IOPS",
+		"Read/s", "Write/s", "Latency (us)", "Error"})
+
+	for _, report := range reports {
+		if report.Rates == nil {
+			table.Append([]string{report.Volume, report.Backend, "", "", "", "", "", "", report.Error})
+			continue
+		}
+		rates := report.Rates
+		table.Append([]string{
+			report.Volume,
+			report.Backend,
+			strconv.FormatFloat(rates.ReadIOPS, 'f', 0, 64),
+			strconv.FormatFloat(rates.WriteIOPS, 'f', 0, 64),
+			strconv.FormatFloat(rates.TotalIOPS, 'f', 0, 64),
+			humanize.IBytes(uint64(rates.ReadBytesPerSecond)),
+			humanize.IBytes(uint64(rates.WriteBytesPerSecond)),
+			strconv.FormatFloat(rates.AverageLatencyMicros, 'f', 0, 64),
+			report.Error,
+		})
+	}
+
+	table.Render()
+}
+
+func writeVolumeStatsNames(reports []*storage.VolumeStatsReport) {
+
+	for _, report := range reports {
+		fmt.Println(report.Volume)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/netapp/trident/cli/api"
 	"github.com/netapp/trident/frontend/rest"
@@ -113,6 +115,8 @@ func WriteStorageClasses(storageClasses []api.StorageClass) {
 		WriteYAML(api.MultipleStorageClassResponse{storageClasses})
 	case FormatName:
 		writeStorageClassNames(storageClasses)
+	case FormatWide:
+		writeWideStorageClassTable(storageClasses)
 	default:
 		writeStorageClassTable(storageClasses)
 	}
@@ -132,6 +136,32 @@ func writeStorageClassTable(storageClasses []api.StorageClass) {
 	table.Render()
 }
 
+func writeWideStorageClassTable(storageClasses []api.StorageClass) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Matched Pools", "Unmatched Pools", "Unsatisfied Attributes"})
+
+	for _, sc := range storageClasses {
+		matched := 0
+		for _, pools := range sc.Storage {
+			matched += len(pools)
+		}
+		unmatched, unsatisfied := "", ""
+		if sc.Validation != nil {
+			unmatched = strconv.Itoa(len(sc.Validation.UnmatchedPools))
+			unsatisfied = strings.Join(sc.Validation.UnsatisfiedAttributes, ", ")
+		}
+		table.Append([]string{
+			sc.Config.Name,
+			strconv.Itoa(matched),
+			unmatched,
+			unsatisfied,
+		})
+	}
+
+	table.Render()
+}
+
 func writeStorageClassNames(storageClasses []api.StorageClass) {
 
 	for _, sc := range storageClasses {
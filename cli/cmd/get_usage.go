@@ -0,0 +1,148 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageStart string
+	usageEnd   string
+)
+
+func init() {
+	getCmd.AddCommand(getUsageCmd)
+	getUsageCmd.Flags().StringVar(&usageStart, "start", "",
+		"RFC 3339 time at which the period starts (default 30 days before its end)")
+	getUsageCmd.Flags().StringVar(&usageEnd, "end", "", "RFC 3339 time at which the period ends (default now)")
+}
+
+var getUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Get the capacity provisioned and used by namespace, storage class and backend from Trident",
+	Long: "Report the capacity provisioned and used over a period, in GiB-hours and on average, by " +
+		"namespace, storage class and backend, for chargeback. Use -o csv to export the report.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "usage"}
+			if usageStart != "" {
+				command = append(command, "--start", usageStart)
+			}
+			if usageEnd != "" {
+				command = append(command, "--end", usageEnd)
+			}
+			TunnelCommand(command)
+			return nil
+		} else {
+			return usageReport()
+		}
+	},
+}
+
+func usageReport() error {
+
+	query := url.Values{}
+	for param, value := range map[string]string{"start": usageStart, "end": usageEnd} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid %s time %s; must be in RFC 3339 format", param, value)
+		}
+		query.Set(param, value)
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	reportURL := baseURL + "/usage"
+	if len(query) > 0 {
+		reportURL += "?" + query.Encode()
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", reportURL, nil, Debug)
+	if err != nil {
+		return err
+	}
+
+	var reportResponse rest.UsageReportResponse
+	if err = json.Unmarshal(responseBody, &reportResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not get usage report. %v %s", response.Status, reportResponse.Error)
+	}
+
+	WriteUsageReport(reportResponse.Report)
+
+	return nil
+}
+
+func WriteUsageReport(report *storage.UsageReport) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(report)
+	case FormatYAML:
+		WriteYAML(report)
+	case FormatCSV:
+		writeUsageReportCSV(report)
+	default:
+		writeUsageReportTable(report)
+	}
+}
+
+func writeUsageReportTable(report *storage.UsageReport) {
+
+	fmt.Printf("Usage from %s to %s (%s hours sampled)\n", report.Start.Format(time.RFC3339),
+		report.End.Format(time.RFC3339), strconv.FormatFloat(report.Hours, 'f', 1, 64))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Namespace", "Storage Class", "Backend", "Volumes", "Provisioned GiB-h",
+		"Used GiB-h", "Avg Provisioned", "Avg Used"})
+
+	for _, entry := range report.Entries {
+		used := humanize.IBytes(entry.AverageUsedBytes)
+		if !entry.UsedMeasured {
+			used += " (partial)"
+		}
+		table.Append([]string{
+			entry.Namespace,
+			entry.StorageClass,
+			entry.Backend,
+			strconv.Itoa(entry.Volumes),
+			strconv.FormatFloat(entry.ProvisionedGiBHours, 'f', 1, 64),
+			strconv.FormatFloat(entry.UsedGiBHours, 'f', 1, 64),
+			humanize.IBytes(entry.AverageProvisionedBytes),
+			used,
+		})
+	}
+
+	table.Render()
+}
+
+func writeUsageReportCSV(report *storage.UsageReport) {
+
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write(storage.UsageReportColumns)
+	for _, entry := range report.Entries {
+		writer.Write(entry.Values())
+	}
+	writer.Flush()
+}
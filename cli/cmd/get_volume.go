@@ -157,6 +157,7 @@ func writeWideVolumeTable(volumes []storage.VolumeExternal) {
 		"Backend",
 		"Pool",
 		"Access Mode",
+		"WWID",
 	}
 	table.SetHeader(header)
 
@@ -173,6 +174,7 @@ func writeWideVolumeTable(volumes []storage.VolumeExternal) {
 			volume.Backend,
 			volume.Pool,
 			string(volume.Config.AccessMode),
+			volume.Config.AccessInfo.IscsiLunWWID,
 		})
 	}
 
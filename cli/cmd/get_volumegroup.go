@@ -0,0 +1,152 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	getCmd.AddCommand(getVolumeGroupCmd)
+}
+
+var getVolumeGroupCmd = &cobra.Command{
+	Use:     "volumegroup",
+	Short:   "Get one or more volume groups from Trident",
+	Aliases: []string{"vg", "volumegroups"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "volumegroup"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeGroupList(args)
+		}
+	},
+}
+
+func volumeGroupList(groupNames []string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// If no groups were specified, we'll get all of them
+	if len(groupNames) == 0 {
+		groupNames, err = GetVolumeGroups(baseURL)
+		if err != nil {
+			return err
+		}
+		sort.Strings(groupNames)
+	}
+
+	groups := make([]storage.VolumeGroupExternal, 0, 10)
+
+	// Get the actual group objects
+	for _, groupName := range groupNames {
+
+		group, err := GetVolumeGroup(baseURL, groupName)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, group)
+	}
+
+	WriteVolumeGroups(groups)
+
+	return nil
+}
+
+func GetVolumeGroups(baseURL string) ([]string, error) {
+
+	url := baseURL + "/volumegroup"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get volume groups. %v", response.Status)
+	}
+
+	var listGroupsResponse rest.ListVolumeGroupsResponse
+	err = json.Unmarshal(responseBody, &listGroupsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listGroupsResponse.VolumeGroups, nil
+}
+
+func GetVolumeGroup(baseURL, groupName string) (storage.VolumeGroupExternal, error) {
+
+	url := baseURL + "/volumegroup/" + groupName
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return storage.VolumeGroupExternal{}, err
+	} else if response.StatusCode != http.StatusOK {
+		return storage.VolumeGroupExternal{}, fmt.Errorf("could not get volume group %s. %v",
+			groupName, response.Status)
+	}
+
+	var getGroupResponse rest.GetVolumeGroupResponse
+	err = json.Unmarshal(responseBody, &getGroupResponse)
+	if err != nil {
+		return storage.VolumeGroupExternal{}, err
+	}
+
+	return *getGroupResponse.VolumeGroup, nil
+}
+
+func WriteVolumeGroups(groups []storage.VolumeGroupExternal) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleVolumeGroupResponse{groups})
+	case FormatYAML:
+		WriteYAML(api.MultipleVolumeGroupResponse{groups})
+	case FormatName:
+		writeVolumeGroupNames(groups)
+	default:
+		writeVolumeGroupTable(groups)
+	}
+}
+
+func writeVolumeGroupTable(groups []storage.VolumeGroupExternal) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Volumes"})
+
+	for _, group := range groups {
+
+		volumeNames := make([]string, 0, len(group.Volumes))
+		for _, volume := range group.Volumes {
+			volumeNames = append(volumeNames, volume.Config.Name)
+		}
+
+		table.Append([]string{
+			group.Name,
+			strings.Join(volumeNames, ","),
+		})
+	}
+
+	table.Render()
+}
+
+func writeVolumeGroupNames(groups []storage.VolumeGroupExternal) {
+
+	for _, group := range groups {
+		fmt.Println(group.Name)
+	}
+}
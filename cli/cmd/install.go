@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -893,7 +894,7 @@ func createPV(sb *storage.Backend) error {
 	volAttributes := make(map[string]sa.Request)
 
 	// Create the volume on the backend
-	volume, err := sb.AddVolume(volConfig, pool, volAttributes)
+	volume, err := sb.AddVolume(context.Background(), volConfig, pool, volAttributes)
 	if err != nil {
 		return fmt.Errorf("could not create a volume on the storage backend; %v", err)
 	}
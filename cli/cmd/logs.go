@@ -3,15 +3,23 @@
 package cmd
 
 import (
-	"archive/zip"
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/netapp/trident/cli/api"
 	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/logging"
+	drivers "github.com/netapp/trident/storage_drivers"
 	"github.com/spf13/cobra"
 )
 
@@ -19,18 +27,26 @@ const (
 	LogLimitBytes         = 10485760 // 10 MiB
 	tridentLogTrident     = "trident"
 	tridentLogEtcd        = "etcd"
-	archiveFilenameFormat = "support-2006-01-02T15-04-05-MST.zip"
+	archiveFilenameFormat = "support-2006-01-02T15-04-05-MST.tar.gz"
 )
 
 var (
-	Log     string
-	archive bool
+	Log          string
+	archive      bool
+	logComponent string
+	logLevel     string
 )
 
+// redactedSettings are backend settings whose values are never written to a support archive.
+var redactedSettings = []string{"password", "secret", "apikey", "privatekey", "token"}
+
 func init() {
 	RootCmd.AddCommand(logsCmd)
 	logsCmd.Flags().StringVarP(&Log, "log", "l", "auto", "Trident log to display. One of trident|etcd|auto|all")
 	logsCmd.Flags().BoolVarP(&archive, "archive", "a", false, "Create a support archive with all logs unless otherwise specified.")
+	logsCmd.Flags().StringVar(&logComponent, "component", "",
+		"Comma-separated Trident log components to display. Any of "+strings.Join(logging.GetComponents(), "|"))
+	logsCmd.Flags().StringVar(&logLevel, "level", "", "Least severe Trident log level to display")
 }
 
 var logsCmd = &cobra.Command{
@@ -50,6 +66,8 @@ var logsCmd = &cobra.Command{
 
 		if archive {
 			return archiveLogs()
+		} else if OutputFormat == FormatJSON {
+			return jsonLogs()
 		} else {
 			return consoleLogs()
 		}
@@ -78,33 +96,131 @@ func archiveLogs() error {
 		return errors.New("no Trident-related logs found")
 	}
 
+	// The backend configs help to make sense of the logs, but the archive is useful without them
+	backends, err := getArchiveBackends()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not get backends for the archive. %v\n", err)
+	}
+
 	// Create archive file.
-	filename = time.Now().Format(archiveFilenameFormat)
-	zipFile, err := os.Create(filename)
+	archiveFilename := time.Now().Format(archiveFilenameFormat)
+	archiveFile, err := os.Create(archiveFilename)
 	if err != nil {
 		return err
 	}
-	defer zipFile.Close()
+	defer archiveFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	gzipWriter := gzip.NewWriter(archiveFile)
+	defer gzipWriter.Close()
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
 
 	// Write to the archive file.
 	for log, logBytes := range logMap {
-		entry, err := zipWriter.Create(log)
-		if err != nil {
+		if err = writeArchiveEntry(tarWriter, log+".log", logBytes); err != nil {
 			return err
 		}
-		_, err = entry.Write(logBytes)
+		fmt.Printf("Wrote %s log to %s archive file.\n", log, archiveFilename)
+	}
+	for _, backend := range backends {
+		redactBackendSettings(backend.Config.Settings)
+		backendBytes, err := json.MarshalIndent(backend, "", "  ")
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Wrote %s log to %s archive file.\n", log, filename)
+		if err = writeArchiveEntry(tarWriter, "backends/"+backend.Name+".json", backendBytes); err != nil {
+			return err
+		}
+	}
+	if len(backends) > 0 {
+		fmt.Printf("Wrote %d backend configs to %s archive file.\n", len(backends), archiveFilename)
 	}
 
 	return nil
 }
 
+func writeArchiveEntry(tarWriter *tar.Writer, name string, data []byte) error {
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tarWriter.Write(data)
+	return err
+}
+
+// getArchiveBackends returns all of Trident's backends, including their driver-specific settings.
+func getArchiveBackends() ([]api.Backend, error) {
+
+	if OperatingMode == ModeTunnel {
+		output, err := TunnelCommandRaw([]string{"get", "backend", "--output", FormatJSON})
+		if err != nil {
+			return nil, fmt.Errorf("%v; %s", err, strings.TrimSpace(string(output)))
+		}
+		var backendsResponse api.MultipleBackendResponse
+		if err = json.Unmarshal(output, &backendsResponse); err != nil {
+			return nil, err
+		}
+		return backendsResponse.Items, nil
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return nil, err
+	}
+	backendNames, err := GetBackends(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	backends := make([]api.Backend, 0, len(backendNames))
+	for _, backendName := range backendNames {
+		backend, err := GetBackend(baseURL, backendName)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, backend)
+	}
+	return backends, nil
+}
+
+// redactBackendSettings hides the values of any credentials in a backend's settings.  Trident
+// leaves credentials out of the configs it reports, so this only guards against a driver that
+// doesn't.
+func redactBackendSettings(settings map[string]interface{}) {
+
+	for key, value := range settings {
+		for _, redacted := range redactedSettings {
+			if strings.Contains(strings.ToLower(key), redacted) {
+				settings[key] = drivers.RedactedValue
+			}
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactBackendSettings(nested)
+		}
+	}
+}
+
+func jsonLogs() error {
+
+	if Log != tridentLogTrident && Log != "auto" {
+		return errors.New("only the trident log may be displayed as JSON")
+	}
+
+	entries, err := getTridentLogEntries()
+	if err != nil {
+		return err
+	}
+
+	WriteJSON(rest.LogsResponse{Entries: entries})
+
+	return nil
+}
+
 func consoleLogs() error {
 
 	logMap := make(map[string][]byte)
@@ -147,16 +263,21 @@ func getLogs(logMap map[string][]byte) error {
 	case ModeTunnel:
 		switch Log {
 		case "trident", "auto":
-			err = getTridentLogs(tridentLogTrident, logMap)
+			err = getTridentLogs(logMap)
 		case "etcd":
-			err = getTridentLogs(tridentLogEtcd, logMap)
+			err = getContainerLogs(tridentLogEtcd, logMap)
 		case "all":
-			getTridentLogs(tridentLogTrident, logMap)
-			getTridentLogs(tridentLogEtcd, logMap)
+			getTridentLogs(logMap)
+			getContainerLogs(tridentLogEtcd, logMap)
 		}
 
 	case ModeDirect:
-		err = errors.New("'tridentctl logs' only supports Trident running in a Kubernetes pod")
+		switch Log {
+		case "trident", "auto", "all":
+			err = getTridentLogs(logMap)
+		case "etcd":
+			err = errors.New("the etcd log is only available when Trident is running in a Kubernetes pod")
+		}
 	}
 
 	return err
@@ -171,7 +292,76 @@ func checkValidLog() error {
 	}
 }
 
-func getTridentLogs(log string, logMap map[string][]byte) error {
+// getTridentLogs gets Trident's own log from its REST API, so the component and level filters apply.
+func getTridentLogs(logMap map[string][]byte) error {
+
+	entries, err := getTridentLogEntries()
+	if err != nil {
+		logMap["error"] = appendError(logMap["error"], []byte(err.Error()))
+	} else {
+		logMap[tridentLogTrident] = logging.FormatEntries(entries)
+	}
+	return err
+}
+
+// getTridentLogEntries retrieves Trident's recent log entries.  When tunneling, the entries are
+// retrieved as JSON by tridentctl in the Trident pod.
+func getTridentLogEntries() ([]logging.Entry, error) {
+
+	if OperatingMode == ModeTunnel {
+		command := []string{"logs", "--log", tridentLogTrident, "--output", FormatJSON}
+		if logComponent != "" {
+			command = append(command, "--component", logComponent)
+		}
+		if logLevel != "" {
+			command = append(command, "--level", logLevel)
+		}
+		output, err := TunnelCommandRaw(command)
+		if err != nil {
+			return nil, fmt.Errorf("%v; %s", err, strings.TrimSpace(string(output)))
+		}
+		var logsResponse rest.LogsResponse
+		if err = json.Unmarshal(output, &logsResponse); err != nil {
+			return nil, err
+		}
+		return logsResponse.Entries, nil
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if logComponent != "" {
+		query.Set("component", logComponent)
+	}
+	if logLevel != "" {
+		query.Set("level", logLevel)
+	}
+	logsURL := baseURL + "/logs"
+	if len(query) > 0 {
+		logsURL += "?" + query.Encode()
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", logsURL, nil, Debug)
+	if err != nil {
+		return nil, err
+	}
+
+	var logsResponse rest.LogsResponse
+	if err = json.Unmarshal(responseBody, &logsResponse); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get Trident logs: %s", logsResponse.Error)
+	}
+
+	return logsResponse.Entries, nil
+}
+
+// getContainerLogs gets the log of one of the containers in the Trident pod from Kubernetes.
+func getContainerLogs(log string, logMap map[string][]byte) error {
 
 	var container string
 
@@ -0,0 +1,155 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+var (
+	migrateBackend       string
+	migratePool          string
+	migrateMaxDisruption int
+	migrateAutoCutover   bool
+)
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+	RootCmd.AddCommand(cutoverCmd)
+	migrateCmd.Flags().StringVar(&migrateBackend, "backend", "", "Backend to move the volume to")
+	migrateCmd.Flags().StringVar(&migratePool, "pool", "", "Storage pool of the backend to move the volume to")
+	migrateCmd.Flags().IntVar(&migrateMaxDisruption, "max-disruption", storage.DefaultMaxDisruptionSeconds,
+		"Longest time, in seconds, that cutover may keep the volume unavailable")
+	migrateCmd.Flags().BoolVar(&migrateAutoCutover, "auto-cutover", false,
+		"Cut over as soon as the migration is ready, instead of waiting for the cutover command")
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <volume>",
+	Short: "Start moving a volume to another backend",
+	Long: "Copy a volume to another backend while it remains in use. Once the copy can be brought " +
+		"up to date within the disruption window, the migration is ready, and the cutover command " +
+		"switches the volume to its new backend.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"migrate", "--backend", migrateBackend, "--pool", migratePool,
+				"--max-disruption", strconv.Itoa(migrateMaxDisruption)}
+			if migrateAutoCutover {
+				command = append(command, "--auto-cutover")
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeMigrate(args)
+		}
+	},
+}
+
+var cutoverCmd = &cobra.Command{
+	Use:   "cutover <volume>",
+	Short: "Switch a migrating volume to its new backend",
+	Long: "Copy a migrating volume's remaining changes and switch it to its new backend. The volume's " +
+		"users should stop writing to it first. If the cutover cannot finish within the disruption " +
+		"window, the volume stays on its current backend. The original volume is kept until the " +
+		"migration is deleted, as hosts, such as through Kubernetes persistent volumes, may still " +
+		"refer to it.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"cutover"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeCutover(args)
+		}
+	},
+}
+
+func volumeMigrate(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("exactly one volume name must be specified")
+	}
+	volumeName := args[0]
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	migrationConfig := &storage.VolumeMigrationConfig{
+		Backend:              migrateBackend,
+		Pool:                 migratePool,
+		MaxDisruptionSeconds: migrateMaxDisruption,
+		AutoCutover:          migrateAutoCutover,
+	}
+	if err = migrationConfig.Validate(); err != nil {
+		return err
+	}
+	postData, err := json.Marshal(migrationConfig)
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/migration/" + volumeName
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, postData, Debug)
+	if err != nil {
+		return err
+	}
+
+	var migrationResponse rest.GetVolumeMigrationResponse
+	if err = json.Unmarshal(responseBody, &migrationResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("could not migrate volume %s. %v %s", volumeName, response.Status,
+			migrationResponse.Error)
+	}
+
+	WriteVolumeMigrations([]storage.VolumeMigration{*migrationResponse.Migration})
+
+	return nil
+}
+
+func volumeCutover(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("exactly one volume name must be specified")
+	}
+	volumeName := args[0]
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/migration/" + volumeName + "/cutover"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, nil, Debug)
+	if err != nil {
+		return err
+	}
+
+	var migrationResponse rest.GetVolumeMigrationResponse
+	if err = json.Unmarshal(responseBody, &migrationResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not cut over volume %s. %v %s", volumeName, response.Status,
+			migrationResponse.Error)
+	}
+
+	WriteVolumeMigrations([]storage.VolumeMigration{*migrationResponse.Migration})
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileNow     bool
+	reconcileCleanup bool
+)
+
+func init() {
+	RootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().BoolVar(&reconcileNow, "now", false,
+		"Check the backends now instead of showing the latest report")
+	reconcileCmd.Flags().BoolVar(&reconcileCleanup, "cleanup", false,
+		"Check the backends now and delete any orphaned objects")
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Report objects that are orphaned on, or missing from, the storage backends",
+	Long: "Compare the volumes known to Trident with the objects present on its backends. " +
+		"Orphans exist on a backend but are unknown to Trident; ghosts are known to Trident but " +
+		"missing from their backend.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"reconcile"}
+			if reconcileNow {
+				command = append(command, "--now")
+			}
+			if reconcileCleanup {
+				command = append(command, "--cleanup")
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return reconcile()
+		}
+	},
+}
+
+func reconcile() error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/reconcile"
+	method := "GET"
+	if reconcileNow || reconcileCleanup {
+		method = "POST"
+		url = fmt.Sprintf("%s?cleanup=%t", url, reconcileCleanup)
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI(method, url, nil, Debug)
+	if err != nil {
+		return err
+	}
+
+	var reconcileResponse rest.ReconcileResponse
+	if err = json.Unmarshal(responseBody, &reconcileResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		if reconcileResponse.Error != "" {
+			return fmt.Errorf("could not reconcile backends: %s", reconcileResponse.Error)
+		}
+		return fmt.Errorf("could not reconcile backends. %v", response.Status)
+	}
+	if reconcileResponse.Report == nil {
+		return fmt.Errorf("no reconciliation report was returned")
+	}
+
+	WriteReconciliationReport(reconcileResponse.Report)
+
+	return nil
+}
+
+func WriteReconciliationReport(report *storage.ReconciliationReport) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(report)
+	case FormatYAML:
+		WriteYAML(report)
+	default:
+		writeReconciliationTable(report)
+	}
+}
+
+func writeReconciliationTable(report *storage.ReconciliationReport) {
+
+	fmt.Printf("Report time: %s\n", report.Time)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Backend", "Orphans", "Ghosts", "Cleaned", "Kept", "Errors"})
+
+	for _, backend := range report.Backends {
+		table.Append([]string{
+			backend.Backend,
+			strings.Join(backend.Orphans, "\n"),
+			strings.Join(backend.Ghosts, "\n"),
+			strings.Join(backend.Cleaned, "\n"),
+			strings.Join(backend.Kept, "\n"),
+			strings.Join(backend.Errors, "\n"),
+		})
+	}
+
+	table.Render()
+}
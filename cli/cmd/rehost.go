@@ -0,0 +1,81 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+var rehostBackend string
+
+func init() {
+	RootCmd.AddCommand(rehostCmd)
+	rehostCmd.Flags().StringVar(&rehostBackend, "backend", "", "Backend to move the volume to")
+}
+
+var rehostCmd = &cobra.Command{
+	Use:   "rehost <volume> --backend <backend>",
+	Short: "Move a volume to another backend's SVM of the same cluster",
+	Long: "Move a volume to another backend whose storage can take it over without copying it, such " +
+		"as an ontap-nas backend managing another SVM of the same cluster. The volume is unavailable " +
+		"while it moves, so its users should stop using it first.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"rehost", "--backend", rehostBackend}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeRehost(args)
+		}
+	},
+}
+
+func volumeRehost(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("exactly one volume name must be specified")
+	}
+	if rehostBackend == "" {
+		return errors.New("a backend must be specified")
+	}
+	volumeName := args[0]
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	postData, err := json.Marshal(&rest.RehostVolumeRequest{Backend: rehostBackend})
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/volume/" + volumeName + "/rehost"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, postData, Debug)
+	if err != nil {
+		return err
+	}
+
+	var rehostResponse rest.GetVolumeResponse
+	if err = json.Unmarshal(responseBody, &rehostResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not rehost volume %s. %v %s", volumeName, response.Status,
+			rehostResponse.Error)
+	}
+
+	WriteVolumes([]storage.VolumeExternal{*rehostResponse.Volume})
+
+	return nil
+}
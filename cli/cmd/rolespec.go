@@ -0,0 +1,44 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"fmt"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap"
+	"github.com/spf13/cobra"
+)
+
+var (
+	roleSpecDriver string
+	roleSpecSVM    string
+	roleSpecRole   string
+	roleSpecUser   string
+)
+
+func init() {
+	RootCmd.AddCommand(roleSpecCmd)
+	roleSpecCmd.Flags().StringVar(&roleSpecDriver, "driver", drivers.OntapNASStorageDriverName,
+		"ONTAP driver the role is for: ontap-nas, ontap-nas-economy or ontap-san")
+	roleSpecCmd.Flags().StringVar(&roleSpecSVM, "svm", "<svm>", "SVM in which to create the role and user")
+	roleSpecCmd.Flags().StringVar(&roleSpecRole, "role", ontap.DefaultRoleName, "Name of the role")
+	roleSpecCmd.Flags().StringVar(&roleSpecUser, "user", "", "Name of the user (default the role name)")
+}
+
+var roleSpecCmd = &cobra.Command{
+	Use:   "rolespec",
+	Short: "Print the ONTAP commands that create a least-privilege role for Trident",
+	Long: "Print the ONTAP CLI commands that create a role granting only the commands an ONTAP " +
+		"driver uses, and a user with that role for the backend config.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := ontap.RoleSpec(roleSpecDriver, roleSpecSVM, roleSpecRole, roleSpecUser)
+		if err != nil {
+			return err
+		}
+		for _, command := range spec {
+			fmt.Println(command)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	traceEnable  []string
+	traceDisable []string
+)
+
+func init() {
+	RootCmd.AddCommand(traceCmd)
+	traceCmd.Flags().StringSliceVar(&traceEnable, "enable", []string{},
+		"Debug trace flags to enable, such as method,api")
+	traceCmd.Flags().StringSliceVar(&traceDisable, "disable", []string{},
+		"Debug trace flags to disable")
+}
+
+var traceCmd = &cobra.Command{
+	Use:   "trace <backend>",
+	Short: "Show or change the debug trace flags of a backend",
+	Long: "Enable or disable debug tracing, such as of driver methods (method) or storage API " +
+		"calls (api), on a running backend without re-adding it.  Without --enable or --disable, " +
+		"the current flags are shown.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"trace"}
+			for _, flag := range traceEnable {
+				command = append(command, "--enable", flag)
+			}
+			for _, flag := range traceDisable {
+				command = append(command, "--disable", flag)
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return backendTrace(args)
+		}
+	},
+}
+
+func backendTrace(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("a single backend name must be specified")
+	}
+	backendName := args[0]
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	request := rest.UpdateBackendTraceFlagsRequest{DebugTraceFlags: make(map[string]bool)}
+	for _, flag := range traceEnable {
+		request.DebugTraceFlags[flag] = true
+	}
+	for _, flag := range traceDisable {
+		if _, ok := request.DebugTraceFlags[flag]; ok {
+			return fmt.Errorf("trace flag %s may not be both enabled and disabled", flag)
+		}
+		request.DebugTraceFlags[flag] = false
+	}
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/backend/" + backendName + "/trace"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, requestBody, Debug)
+	if err != nil {
+		return err
+	}
+
+	var traceResponse rest.BackendTraceFlagsResponse
+	if err = json.Unmarshal(responseBody, &traceResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not change trace flags of backend %s. %v %s", backendName,
+			response.Status, traceResponse.Error)
+	}
+
+	WriteTraceFlags(&traceResponse)
+
+	return nil
+}
+
+func WriteTraceFlags(traceResponse *rest.BackendTraceFlagsResponse) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(traceResponse)
+	case FormatYAML:
+		WriteYAML(traceResponse)
+	default:
+		writeTraceFlagsTable(traceResponse.DebugTraceFlags)
+	}
+}
+
+func writeTraceFlagsTable(flags map[string]bool) {
+
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Flag", "Enabled"})
+	for _, name := range names {
+		table.Append([]string{name, strconv.FormatBool(flags[name])})
+	}
+	table.Render()
+}
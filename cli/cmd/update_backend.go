@@ -0,0 +1,113 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+)
+
+var newBackendName string
+
+func init() {
+	updateCmd.AddCommand(updateBackendCmd)
+	updateBackendCmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to YAML or JSON file")
+	updateBackendCmd.Flags().StringVarP(&b64Data, "base64", "", "", "Base64 encoding")
+	updateBackendCmd.Flags().MarkHidden("base64")
+	updateBackendCmd.Flags().StringVar(&newBackendName, "name", "", "New name for the backend")
+}
+
+var updateBackendCmd = &cobra.Command{
+	Use:     "backend <backend>",
+	Short:   "Update a backend's config or name in Trident, keeping its volumes",
+	Aliases: []string{"b"},
+	Long: "Replace the config of a backend, identified by name or UUID, or rename it. Unlike adding " +
+		"a changed config as a new backend, the backend keeps its UUID and volumes even if the new " +
+		"config, such as one with a different management or data LIF, gives it a different name.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if len(args) != 1 {
+			return errors.New("exactly one backend name or UUID must be specified")
+		}
+		if newBackendName != "" {
+			if filename != "" || b64Data != "" {
+				return errors.New("a backend may not be renamed and given a new config at once")
+			}
+			if OperatingMode == ModeTunnel {
+				TunnelCommand([]string{"update", "backend", args[0], "--name", newBackendName})
+				return nil
+			}
+			return backendRename(args[0], newBackendName)
+		}
+
+		jsonData, err := getBackendCreateData()
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{"update", "backend", "--base64", base64.StdEncoding.EncodeToString(jsonData)}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return backendUpdate(args[0], jsonData)
+		}
+	},
+}
+
+func backendUpdate(backendName string, putData []byte) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/backend/" + backendName
+
+	return invokeBackendUpdate("PUT", url, putData, backendName)
+}
+
+func backendRename(backendName, newName string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	postData, err := json.Marshal(rest.RenameBackendRequest{Name: newName})
+	if err != nil {
+		return err
+	}
+	url := baseURL + "/backend/" + backendName + "/rename"
+
+	return invokeBackendUpdate("POST", url, postData, backendName)
+}
+
+func invokeBackendUpdate(method, url string, requestData []byte, backendName string) error {
+
+	response, responseBody, err := api.InvokeRESTAPI(method, url, requestData, Debug)
+	if err != nil {
+		return err
+	}
+
+	var getBackendResponse api.GetBackendResponse
+	if err = json.Unmarshal(responseBody, &getBackendResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not update backend %s. %v %s", backendName, response.Status,
+			getBackendResponse.Error)
+	}
+
+	WriteBackends([]api.Backend{getBackendResponse.Backend})
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+)
+
+func init() {
+	updateCmd.AddCommand(updateStorageClassCmd)
+	updateStorageClassCmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to YAML or JSON file")
+	updateStorageClassCmd.Flags().StringVarP(&b64Data, "base64", "", "", "Base64 encoding")
+	updateStorageClassCmd.Flags().MarkHidden("base64")
+}
+
+var updateStorageClassCmd = &cobra.Command{
+	Use:     "storageclass <storageclass>",
+	Short:   "Update a storage class's attributes, pools or aliases in Trident",
+	Aliases: []string{"sc"},
+	Long: "Replace the definition of a storage class, keeping its name so that its volumes and claims " +
+		"are unaffected. The pools the class gains and loses are reported, along with any existing " +
+		"volumes left on pools that new volumes of the class will no longer be provisioned on. A change " +
+		"to the class's snapshotDirectory attribute is applied to its existing volumes that don't set " +
+		"their own.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if len(args) != 1 {
+			return errors.New("exactly one storage class name must be specified")
+		}
+
+		jsonData, err := getBackendCreateData()
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{"update", "storageclass", "--base64", base64.StdEncoding.EncodeToString(jsonData)}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return storageClassUpdate(args[0], jsonData)
+		}
+	},
+}
+
+func storageClassUpdate(storageClassName string, putData []byte) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/storageclass/" + storageClassName
+
+	response, responseBody, err := api.InvokeRESTAPI("PUT", url, putData, Debug)
+	if err != nil {
+		return err
+	}
+
+	var updateResponse api.UpdateStorageClassResponse
+	if err = json.Unmarshal(responseBody, &updateResponse); err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not update storage class %s. %v %s", storageClassName, response.Status,
+			updateResponse.Error)
+	}
+
+	update := updateResponse.Update
+	WriteStorageClasses([]api.StorageClass{update.StorageClass})
+
+	if OutputFormat == FormatJSON || OutputFormat == FormatYAML || OutputFormat == FormatName {
+		return nil
+	}
+	if pools := formatPools(update.AddedPools); pools != "" {
+		fmt.Printf("Added pools: %s\n", pools)
+	}
+	if pools := formatPools(update.RemovedPools); pools != "" {
+		fmt.Printf("Removed pools: %s\n", pools)
+	}
+	if len(update.StrandedVolumes) > 0 {
+		fmt.Printf("Volumes left on removed pools: %s\n", strings.Join(update.StrandedVolumes, ", "))
+	}
+	if len(update.UpdatedVolumes) > 0 {
+		fmt.Printf("Volumes updated to match the class: %s\n", strings.Join(update.UpdatedVolumes, ", "))
+	}
+	failed := make([]string, 0, len(update.FailedVolumes))
+	for volume := range update.FailedVolumes {
+		failed = append(failed, volume)
+	}
+	sort.Strings(failed)
+	for _, volume := range failed {
+		fmt.Printf("Volume %s could not be updated: %s\n", volume, update.FailedVolumes[volume])
+	}
+
+	return nil
+}
+
+// formatPools lists pools by backend, such as "ontapnas_10.0.0.1:aggr1, ontapnas_10.0.0.1:aggr2".
+func formatPools(pools map[string][]string) string {
+	names := make([]string, 0)
+	for backend, poolNames := range pools {
+		for _, pool := range poolNames {
+			names = append(names, backend+":"+pool)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
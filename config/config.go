@@ -45,6 +45,8 @@ const (
 	OntapISCSI        VolumeType = "ONTAP_iSCSI"
 	SolidFireISCSI    VolumeType = "SolidFire_iSCSI"
 	ESeriesISCSI      VolumeType = "Eseries_iSCSI"
+	GCPNFS            VolumeType = "GCP_NFS"
+	GenericNFS        VolumeType = "Generic_NFS"
 	UnknownVolumeType VolumeType = ""
 
 	/* Driver-related constants */
@@ -54,6 +56,10 @@ const (
 	/* REST frontend constants */
 	MaxRESTRequestSize = 10240
 
+	/* Bulk volume operation constants */
+	MaxBulkRESTRequestSize = 1048576
+	MaxBulkVolumes         = 500
+
 	/* Kubernetes deployment constants */
 	ContainerTrident = "trident-main"
 	ContainerEtcd    = "etcd"
@@ -98,9 +104,29 @@ var (
 	VersionURL      = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/version"
 	BackendURL      = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/backend"
 	VolumeURL       = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/volume"
+	PlacementURL    = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/placement"
 	TransactionURL  = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/txn"
+	JournalURL      = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/journal"
+	ScheduleURL     = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/snapshotschedule"
+	VolumeGroupURL  = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/volumegroup"
+	VolumeNameURL   = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/volumename"
+	MigrationURL    = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/migration"
+	ReconcileURL    = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/reconcile"
 	StorageClassURL = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/storageclass"
+	JobURL          = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/job"
+	BatchURL        = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/batch"
+	LoggingURL      = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/logging"
+	LogsURL         = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/logs"
+	StatsURL        = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/stats"
+	UsageURL        = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/usage"
+	OpenAPIURL      = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/openapi.json"
 	StoreURL        = "/" + OrchestratorName + "/store"
+	LeaseURL        = "/" + OrchestratorName + "/lease"
+	SchemaURL       = "/" + OrchestratorName + "/schema"
+	BackupURL       = "/" + OrchestratorName + "/backup"
+	HealthURL       = "/healthz"
+	ReadyURL        = "/readyz"
+	MetricsURL      = "/metrics"
 
 	UsingPassthroughStore bool
 	CurrentDriverContext  DriverContext
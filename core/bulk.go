@@ -0,0 +1,201 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// AddVolumes creates or clones each of the requested volumes, returning a result for each in
+// the order requested.  Each volume operation holds the orchestrator's mutex for as long as it
+// calls its backend, so the volumes are created one at a time.  If the context ends, the volumes
+// not yet created fail with its error.
+func (o *TridentOrchestrator) AddVolumes(
+	ctx context.Context, volumeConfigs []*storage.VolumeConfig,
+) []*storage.BulkVolumeResult {
+
+	results := make([]*storage.BulkVolumeResult, len(volumeConfigs))
+
+	for i, volumeConfig := range volumeConfigs {
+		if volumeConfig == nil {
+			results[i] = &storage.BulkVolumeResult{Error: "missing volume config"}
+			continue
+		}
+		results[i] = &storage.BulkVolumeResult{Volume: volumeConfig.Name}
+		if err := volumeConfig.Validate(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		var (
+			vol *storage.VolumeExternal
+			err error
+		)
+		if volumeConfig.CloneSourceVolume != "" {
+			vol, err = o.CloneVolume(ctx, volumeConfig)
+		} else {
+			vol, err = o.AddVolume(ctx, volumeConfig)
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+		} else if vol != nil {
+			results[i].Backend = vol.Backend
+		}
+	}
+
+	logBulkResults("create", results)
+	return results
+}
+
+// DeleteVolumes deletes each of the named volumes one at a time, as AddVolumes creates them,
+// returning a result for each in the order requested.
+func (o *TridentOrchestrator) DeleteVolumes(ctx context.Context, volumeNames []string) []*storage.BulkVolumeResult {
+
+	results := make([]*storage.BulkVolumeResult, len(volumeNames))
+
+	for i, volumeName := range volumeNames {
+		results[i] = &storage.BulkVolumeResult{
+			Volume:  volumeName,
+			Backend: o.volumeBackendName(volumeName),
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if _, err := o.DeleteVolume(ctx, volumeName); err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	logBulkResults("delete", results)
+	return results
+}
+
+// CloneVolumes creates many clones of one source volume, returning a result for each in the order
+// of their names.  Unless the request names a snapshot, one snapshot of the source is taken for
+// all of the clones, so that they share a point in time and the backend is spared a snapshot per
+// clone.  The snapshot is left in place, as the clones may depend on it.  As with AddVolumes, the
+// clones are created one at a time.
+func (o *TridentOrchestrator) CloneVolumes(
+	ctx context.Context, fanout *storage.CloneFanoutConfig,
+) (*storage.CloneFanoutResult, error) {
+
+	if fanout.NamePrefix == "" {
+		return nil, drivers.NewFatalError("a name prefix for the clones is required")
+	}
+	if fanout.Count < 1 || fanout.Count > config.MaxBulkVolumes {
+		return nil, drivers.NewFatalError(fmt.Sprintf("the number of clones must be between 1 and %d",
+			config.MaxBulkVolumes))
+	}
+
+	snapshotName, err := o.cloneFanoutSnapshot(fanout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &storage.CloneFanoutResult{
+		SourceVolume: fanout.SourceVolume,
+		Snapshot:     snapshotName,
+		Results:      make([]*storage.BulkVolumeResult, fanout.Count),
+	}
+
+	for i := range result.Results {
+		volumeConfig := &storage.VolumeConfig{
+			Name:                fanout.CloneName(i + 1),
+			CloneSourceVolume:   fanout.SourceVolume,
+			CloneSourceSnapshot: snapshotName,
+			SplitOnClone:        fanout.SplitOnClone,
+			OnDelete:            fanout.OnDelete,
+			ReadOnly:            fanout.ReadOnly,
+			Namespace:           fanout.Namespace,
+		}
+		result.Results[i] = &storage.BulkVolumeResult{Volume: volumeConfig.Name}
+		if err := ctx.Err(); err != nil {
+			result.Results[i].Error = err.Error()
+			continue
+		}
+
+		if vol, err := o.CloneVolume(ctx, volumeConfig); err != nil {
+			result.Results[i].Error = err.Error()
+		} else if vol != nil {
+			result.Results[i].Backend = vol.Backend
+		}
+	}
+
+	logBulkResults("clone", result.Results)
+	return result, nil
+}
+
+// cloneFanoutSnapshot returns the snapshot a fan-out's clones are made from, taking one of the
+// source volume if the request doesn't name one and its backend can.  Otherwise no snapshot is
+// returned, and the driver snapshots the source for each clone as it would for any other.
+func (o *TridentOrchestrator) cloneFanoutSnapshot(fanout *storage.CloneFanoutConfig) (string, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	source, ok := o.volumes[fanout.SourceVolume]
+	if !ok {
+		return "", drivers.NewFatalError(fmt.Sprintf("source volume not found: %s", fanout.SourceVolume))
+	}
+	if fanout.SourceSnapshot != "" {
+		return fanout.SourceSnapshot, nil
+	}
+	backend, ok := o.backends[source.Backend]
+	if !ok {
+		return "", fmt.Errorf("backend %s for the source volume was not found: %s", source.Backend,
+			fanout.SourceVolume)
+	}
+	if _, ok = backend.Driver.(storage.SnapshotDriver); !ok || !backend.Online {
+		return "", nil
+	}
+
+	snapshotName := fanout.NamePrefix + "-" + time.Now().UTC().Format("20060102T150405Z")
+	snapshot, err := backend.Guarded().CreateSnapshot(snapshotName, source.Config.InternalName)
+	if err != nil {
+		return "", fmt.Errorf("could not snapshot volume %s to clone: %v", fanout.SourceVolume, err)
+	}
+	log.WithFields(log.Fields{
+		"volume":   fanout.SourceVolume,
+		"snapshot": snapshot.Name,
+		"clones":   fanout.Count,
+	}).Info("Took snapshot to clone.")
+	return snapshot.Name, nil
+}
+
+// volumeBackendName returns the name of the backend hosting a volume, or an empty string if
+// the volume is unknown.
+func (o *TridentOrchestrator) volumeBackendName(volumeName string) string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if volume, ok := o.volumes[volumeName]; ok {
+		return volume.Backend
+	}
+	return ""
+}
+
+func logBulkResults(operation string, results []*storage.BulkVolumeResult) {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	log.WithFields(log.Fields{
+		"operation": operation,
+		"volumes":   len(results),
+		"failed":    failed,
+	}).Info("Bulk volume operation complete.")
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// prepareCache checks a request for a cache of another volume and fills in what it leaves out
+// from the origin volume, which the cache shares its storage class, protocol and size with unless
+// the request says otherwise.  It returns the origin's backend.
+func (o *TridentOrchestrator) prepareCache(volumeConfig *storage.VolumeConfig) (*storage.Backend, error) {
+
+	origin, ok := o.volumes[volumeConfig.CacheSourceVolume]
+	if !ok {
+		return nil, drivers.NewFatalError(fmt.Sprintf("origin volume not found: %s",
+			volumeConfig.CacheSourceVolume))
+	}
+	if origin.Config.IsCache() {
+		return nil, drivers.NewFatalError(fmt.Sprintf("volume %s is itself a cache of %s, which may be cached "+
+			"instead", origin.Config.Name, origin.Config.CacheSourceVolume))
+	}
+	if volumeConfig.WarmPool || volumeConfig.CloneSourceVolume != "" {
+		return nil, drivers.NewFatalError("a cache may not be cloned or kept in a warm pool")
+	}
+	originBackend, ok := o.backends[origin.Backend]
+	if !ok {
+		return nil, fmt.Errorf("backend %s for the origin volume was not found: %s", origin.Backend,
+			volumeConfig.CacheSourceVolume)
+	}
+
+	if volumeConfig.StorageClass == "" {
+		volumeConfig.StorageClass = origin.Config.StorageClass
+	}
+	if volumeConfig.Protocol == "" {
+		volumeConfig.Protocol = origin.Config.Protocol
+	}
+	if volumeConfig.Size == "" || volumeConfig.Size == "0" {
+		volumeConfig.Size = origin.Config.Size
+	}
+	volumeConfig.CacheSourceVolumeInternal = origin.Config.InternalName
+	return originBackend, nil
+}
+
+// cachePools returns the pools on which caches of volumes on the origin backend may be created.
+func cachePools(pools []*storage.Pool, origin *storage.Backend) []*storage.Pool {
+	cache := make([]*storage.Pool, 0, len(pools))
+	for _, pool := range pools {
+		if pool.Backend.SupportsCaches() && pool.Backend.CanCacheFrom(origin) {
+			cache = append(cache, pool)
+		}
+	}
+	return cache
+}
+
+// volumeCaches returns the names of the volumes that cache a volume.
+func (o *TridentOrchestrator) volumeCaches(volumeName string) []string {
+	caches := make([]string, 0)
+	for _, vol := range o.volumes {
+		if vol.Config.CacheSourceVolume == volumeName {
+			caches = append(caches, vol.Config.Name)
+		}
+	}
+	sort.Strings(caches)
+	return caches
+}
+
+// checkNoCaches returns an error if a volume has caches, which must be deleted before it can be.
+func (o *TridentOrchestrator) checkNoCaches(volumeName string) error {
+	if caches := o.volumeCaches(volumeName); len(caches) > 0 {
+		return fmt.Errorf("volume %s is cached by %s, which must be deleted first", volumeName,
+			strings.Join(caches, ", "))
+	}
+	return nil
+}
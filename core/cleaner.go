@@ -0,0 +1,156 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	persistentstore "github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	// DefaultCleanupInterval is how often failed operations are cleaned up by default.
+	DefaultCleanupInterval = 15 * time.Minute
+
+	// DefaultStaleOperationAge is how long a volume transaction or journaled operation may go
+	// unfinished, by default, before the cleaner rolls back, completes or reclaims it.  Until then,
+	// retrying the operation resumes it.
+	DefaultStaleOperationAge = time.Hour
+)
+
+const cleanupTask = "clean-up-failed-operations"
+
+// StartCleaner cleans up after failed operations periodically for as long as Trident runs, rather
+// than only when Trident starts.  A non-positive interval disables the cleaner.
+func (o *TridentOrchestrator) StartCleaner(interval, staleAge time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	log.WithFields(log.Fields{
+		"interval": interval,
+		"staleAge": staleAge,
+	}).Info("Starting periodic cleanup of failed operations.")
+
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:         cleanupTask,
+		Interval:     interval,
+		InitialDelay: interval,
+		Run:          func() { o.cleanUpFailedOperations(staleAge) },
+	}); err != nil {
+		log.Errorf("Could not start periodic cleanup of failed operations. %v", err)
+	}
+}
+
+// cleanUpFailedOperations reclaims what failed operations have left on the backends, as
+// bootstrapping does after a restart:
+//   - Volume transactions left by creates and deletes that couldn't clean up after themselves
+//     are rolled back, which deletes half-created volumes, as the transaction marks them pending.
+//     Rehosts whose volumes the storage has already moved are finished instead.
+//   - Journaled operations, such as clones whose split was never started, that have gone
+//     unfinished for longer than staleAge are completed or cleaned up by their drivers.
+//   - Snapshots Trident created for clones it no longer knows about are deleted.
+//
+// An operation may still be running when the cleaner does, such as one that continues in the
+// background, so volume transactions, like journal entries, are only cleaned up once they are
+// older than staleAge.  Migrations manage their own transactions, so they are left alone.  Offline
+// backends are skipped.
+func (o *TridentOrchestrator) cleanUpFailedOperations(staleAge time.Duration) {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	cleaned, failed := 0, 0
+
+	volTxns, err := o.storeClient.GetVolumeTransactions()
+	if err != nil {
+		log.Warnf("Could not read volume transactions to clean them up. %v", err)
+	}
+	for _, volTxn := range volTxns {
+		if volTxn.Op == persistentstore.MigrateVolume || !isStale(volTxn.StartTime, staleAge) {
+			continue
+		}
+		if err = o.rollBackTransaction(volTxn); err != nil {
+			log.WithFields(log.Fields{
+				"volume": volTxn.Config.Name,
+				"op":     volTxn.Op,
+			}).Errorf("Could not roll back failed volume transaction. %v", err)
+			failed++
+			continue
+		}
+		cleaned++
+	}
+
+	entries, err := o.storeClient.GetJournalEntries()
+	if err != nil {
+		log.Warnf("Could not read the driver operation journal to clean it up. %v", err)
+	}
+	for _, entry := range entries {
+		if !isStale(entry.StartTime, staleAge) {
+			continue
+		}
+		if backend, ok := o.backends[entry.Backend]; ok && !backend.Online {
+			continue
+		}
+		if err = o.reconcileJournalEntry(entry, "Cleanup"); err != nil {
+			log.Error(err)
+			failed++
+			continue
+		}
+		cleaned++
+	}
+
+	backendNames := make([]string, 0, len(o.backends))
+	for name := range o.backends {
+		backendNames = append(backendNames, name)
+	}
+	sort.Strings(backendNames)
+	for _, name := range backendNames {
+		backend := o.backends[name]
+		if _, ok := backend.Driver.(storage.OrphanDetector); !ok || !backend.Online {
+			continue
+		}
+		knownVolumes := make(map[string]bool)
+		for _, vol := range backend.Volumes {
+			knownVolumes[vol.Config.InternalName] = true
+		}
+		objects, err := backend.Guarded().ListOrphanedObjects(knownVolumes)
+		if err != nil {
+			log.WithField("backend", name).Warnf("Could not list orphaned objects to clean them up. %v", err)
+			continue
+		}
+		for _, object := range objects {
+			if !strings.HasPrefix(object, storage.OrphanedSnapshotPrefix) {
+				continue
+			}
+			logFields := log.Fields{"backend": name, "object": object}
+			if err = backend.Guarded().DeleteOrphanedObject(object); err != nil {
+				log.WithFields(logFields).Errorf("Could not delete orphaned snapshot. %v", err)
+				failed++
+				continue
+			}
+			log.WithFields(logFields).Info("Deleted orphaned snapshot.")
+			cleaned++
+		}
+	}
+
+	logFields := log.Fields{"cleaned": cleaned, "failed": failed}
+	if cleaned > 0 || failed > 0 {
+		log.WithFields(logFields).Info("Cleaned up after failed operations.")
+	} else {
+		log.WithFields(logFields).Debug("Found no failed operations to clean up.")
+	}
+}
+
+// isStale returns whether an operation that started at the given time, in RFC3339 format, has gone
+// unfinished for at least staleAge.  An operation recorded without a valid start time, as by older
+// releases, is taken to be stale.
+func isStale(startTime string, staleAge time.Duration) bool {
+	started, err := time.Parse(time.RFC3339, startTime)
+	return err != nil || time.Since(started) >= staleAge
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/persistent_store"
+)
+
+// StoreHealth reports whether Trident can reach its persistent store.
+type StoreHealth struct {
+	Type      string `json:"type"`
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BackendHealth reports the state of a single backend.
+type BackendHealth struct {
+	Name     string `json:"name"`
+	Online   bool   `json:"online"`
+	Cordoned bool   `json:"cordoned"`
+	Degraded string `json:"degraded,omitempty"`
+}
+
+// HealthReport is the result of a health check.  Backends are only included if requested.
+type HealthReport struct {
+	Bootstrapped bool             `json:"bootstrapped"`
+	Store        StoreHealth      `json:"store"`
+	Backends     []*BackendHealth `json:"backends,omitempty"`
+}
+
+// Healthy returns true if Trident is able to do its job at all, which requires its store.
+func (r *HealthReport) Healthy() bool {
+	return r.Store.Connected
+}
+
+// Ready returns true if Trident is healthy and has loaded its state, so is able to serve requests.
+func (r *HealthReport) Ready() bool {
+	return r.Healthy() && r.Bootstrapped
+}
+
+// CheckHealth checks whether the persistent store is reachable and, if includeBackends is set,
+// reports the state of each backend.  The store check doesn't wait for other operations, so it is
+// safe to use as a liveness check, while listing the backends must wait for the orchestrator lock.
+func (o *TridentOrchestrator) CheckHealth(includeBackends bool) *HealthReport {
+
+	report := &HealthReport{
+		Bootstrapped: o.bootstrapped,
+		Store: StoreHealth{
+			Type:      string(o.storeClient.GetType()),
+			Connected: true,
+		},
+	}
+
+	// Any response from the store, even that the version hasn't been saved, shows it is reachable
+	if _, err := o.storeClient.GetVersion(); err != nil && !persistentstore.MatchKeyNotFoundErr(err) {
+		log.WithField("store", report.Store.Type).Warnf("Persistent store health check failed. %v", err)
+		report.Store.Connected = false
+		report.Store.Error = err.Error()
+	}
+
+	if !includeBackends {
+		return report
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	report.Backends = make([]*BackendHealth, 0, len(o.backends))
+	for _, backend := range o.backends {
+		report.Backends = append(report.Backends, &BackendHealth{
+			Name:     backend.Name,
+			Online:   backend.Online,
+			Cordoned: backend.Cordoned,
+			Degraded: backend.Guarded().Degraded(),
+		})
+	}
+	sort.Slice(report.Backends, func(i, j int) bool {
+		return report.Backends[i].Name < report.Backends[j].Name
+	})
+
+	return report
+}
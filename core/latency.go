@@ -0,0 +1,84 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+// DefaultSlowOperationThreshold is how long a volume operation may take before it is logged as
+// slow, unless the orchestrator is given another threshold.
+const DefaultSlowOperationThreshold = 30 * time.Second
+
+// Volume operations whose latencies are tracked
+const (
+	operationCreate = "create"
+	operationClone  = "clone"
+	operationDelete = "delete"
+)
+
+// SetSlowOperationThreshold sets how long a volume operation may take before it is logged as
+// slow and counted against the latency objective.  A threshold of zero disables the logging.
+func (o *TridentOrchestrator) SetSlowOperationThreshold(threshold time.Duration) {
+	o.latency.SetThreshold(threshold)
+}
+
+// GetOperationLatencies returns the distribution of the durations of each volume operation.
+func (o *TridentOrchestrator) GetOperationLatencies() map[string]*utils.LatencyHistogram {
+	return o.latency.Histograms()
+}
+
+// startOperation returns a copy of the context that records the calls a volume operation makes
+// to its storage, along with a function to call once the operation is done.  That adds the
+// operation's duration to its latency distribution, and logs it if it was slow, along with the
+// time spent waiting on the storage, so that delays can be told apart from Trident's own.  The
+// context also carries a correlation ID, by which the storage's records of the operation's
+// changes can be matched to it.
+func (o *TridentOrchestrator) startOperation(
+	ctx context.Context, operation, volume string,
+) (context.Context, func(backend string, err error)) {
+
+	start := time.Now()
+	ctx, timings := utils.WithCallTimings(ctx)
+	ctx, audit := utils.WithAuditInfo(ctx, operation, volume)
+	log.WithFields(audit.Fields()).Debug("Starting volume operation.")
+
+	return ctx, func(backend string, err error) {
+		duration := time.Since(start)
+		if !o.latency.Observe(operation, duration) {
+			return
+		}
+
+		storageTime := timings.Total()
+		fields := log.Fields{
+			"operation":     operation,
+			"volume":        volume,
+			"correlationID": audit.CorrelationID,
+			"backend":       backend,
+			"duration":      duration.Round(time.Millisecond),
+			"storageTime":   storageTime.Round(time.Millisecond),
+			"tridentTime":   (duration - storageTime).Round(time.Millisecond),
+		}
+		if calls := timings.String(); calls != "" {
+			fields["storageCalls"] = calls
+		}
+		if err != nil {
+			fields["error"] = err
+		}
+		log.WithFields(fields).Warn("Slow volume operation.")
+	}
+}
+
+// operationBackend returns the backend of a volume an operation returned, if it returned one.
+func operationBackend(vol *storage.VolumeExternal) string {
+	if vol == nil {
+		return ""
+	}
+	return vol.Backend
+}
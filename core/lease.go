@@ -0,0 +1,205 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	persistentstore "github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	// DefaultLeaseDuration is how long an instance's lease on its persistent store lasts without
+	// being renewed, by default.  Another instance refuses to start until the lease has lapsed.
+	DefaultLeaseDuration = time.Minute
+
+	// leaseRenewals is how many times a lease is renewed within its duration, so that a renewal
+	// delayed by a slow store doesn't lose it.
+	leaseRenewals = 3
+)
+
+const leaseTask = "renew-lease"
+
+// SetLeaseDuration sets how long this instance's lease on the persistent store lasts unless
+// renewed.  It must be called before bootstrapping; a non-positive duration holds no lease, so
+// nothing stops other instances from managing the same store.
+func (o *TridentOrchestrator) SetLeaseDuration(duration time.Duration) {
+	o.leaseMutex.Lock()
+	defer o.leaseMutex.Unlock()
+	o.leaseDuration = duration
+}
+
+// LeaseLost returns a channel that is closed if this instance loses its lease on the persistent
+// store, either to another instance or because the store couldn't be reached to renew it.  Another
+// instance may then be managing the same volumes, so this one should stop.
+func (o *TridentOrchestrator) LeaseLost() <-chan struct{} {
+	return o.leaseLost
+}
+
+// acquireLease claims the persistent store for this instance, so that two instances never manage
+// the same volumes at once.  If another instance holds an unexpired lease, the lease is watched
+// for up to its duration: if it is renewed, the other instance is running and an error is
+// returned, but if it isn't, its holder has gone without releasing it and the lease is taken over.
+// The lease is only written if it is unchanged since it was read, so of two instances starting at
+// once, only one acquires it.  The lease is renewed in the background from then on.
+func (o *TridentOrchestrator) acquireLease() error {
+
+	o.leaseMutex.Lock()
+	defer o.leaseMutex.Unlock()
+
+	if o.leaseDuration <= 0 {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+
+	var (
+		watched  *persistentstore.Lease
+		deadline time.Time
+	)
+	for {
+		current, err := o.storeClient.GetLease()
+		if persistentstore.MatchKeyNotFoundErr(err) {
+			current = nil
+		} else if err != nil {
+			return fmt.Errorf("could not read the lease on the persistent store: %v", err)
+		}
+		now := time.Now()
+
+		if current != nil && current.Owner != o.instanceID && !current.Expired(now) {
+			if watched == nil {
+				log.WithFields(log.Fields{
+					"owner":   current.Owner,
+					"host":    current.Host,
+					"renewed": current.Renewed,
+				}).Warning("Another Trident instance holds the lease on the persistent store, waiting to " +
+					"see whether it is still running.")
+				watched, deadline = current, now.Add(current.Duration)
+			} else if current.Owner != watched.Owner || !current.Renewed.Equal(watched.Renewed) {
+				return fmt.Errorf("another Trident instance, %s on host %s, is managing the persistent "+
+					"store; only one instance may use a store at a time", current.Owner, current.Host)
+			}
+			if now.Before(deadline) {
+				time.Sleep(current.Duration / (2 * leaseRenewals))
+				continue
+			}
+			log.WithFields(log.Fields{
+				"owner": current.Owner,
+				"host":  current.Host,
+			}).Warning("Lease on the persistent store wasn't renewed, taking it over.")
+		}
+
+		lease := &persistentstore.Lease{
+			Owner:    o.instanceID,
+			Host:     host,
+			Version:  config.OrchestratorVersion.String(),
+			Acquired: now,
+			Renewed:  now,
+			Duration: o.leaseDuration,
+		}
+		if err = o.storeClient.SwapLease(current, lease); persistentstore.MatchKeyChangedErr(err) {
+			// Another instance starting at the same time wrote its lease first
+			watched = nil
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not acquire the lease on the persistent store: %v", err)
+		}
+
+		o.lease = lease
+		log.WithFields(log.Fields{
+			"owner":    lease.Owner,
+			"host":     lease.Host,
+			"duration": lease.Duration,
+		}).Info("Acquired the lease on the persistent store.")
+		break
+	}
+
+	return o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:         leaseTask,
+		Interval:     o.leaseDuration / leaseRenewals,
+		InitialDelay: o.leaseDuration / leaseRenewals,
+		Run:          o.renewLease,
+	})
+}
+
+// renewLease extends this instance's lease on the persistent store.  The lease is only written if
+// it is unchanged since this instance last wrote it, so it is lost if another instance has taken
+// it, or if it couldn't be renewed before it expired, after which another instance may have taken
+// it.
+func (o *TridentOrchestrator) renewLease() {
+
+	o.leaseMutex.Lock()
+	defer o.leaseMutex.Unlock()
+
+	if o.lease == nil {
+		return
+	}
+	now := time.Now()
+
+	renewed := *o.lease
+	renewed.Renewed = now
+	err := o.storeClient.SwapLease(o.lease, &renewed)
+	if persistentstore.MatchKeyChangedErr(err) {
+		current, getErr := o.storeClient.GetLease()
+		switch {
+		case getErr != nil:
+			o.loseLease(fmt.Sprintf("was released by another Trident instance: %v", getErr))
+			return
+		case current.Owner != o.instanceID || !current.Acquired.Equal(o.lease.Acquired):
+			o.loseLease(fmt.Sprintf("was taken by Trident instance %s on host %s", current.Owner, current.Host))
+			return
+		}
+		// An earlier renewal was written although the store didn't confirm it
+		renewed.Renewed = now
+		err = o.storeClient.SwapLease(current, &renewed)
+	}
+	if err == nil {
+		o.lease = &renewed
+		return
+	}
+
+	if o.lease.Expired(now) {
+		o.loseLease(fmt.Sprintf("couldn't be renewed before it expired: %v", err))
+		return
+	}
+	log.Warningf("Could not renew the lease on the persistent store. %v", err)
+}
+
+// loseLease gives up this instance's lease and signals that it must stop.  The caller must hold
+// the lease mutex.
+func (o *TridentOrchestrator) loseLease(reason string) {
+	log.Errorf("The lease on the persistent store %s. Another Trident instance may be managing the "+
+		"same volumes, so this instance must stop.", reason)
+	o.lease = nil
+	close(o.leaseLost)
+	o.housekeeping.Cancel(leaseTask)
+}
+
+// releaseLease gives up this instance's lease on the persistent store as it stops, so that another
+// instance may start at once.  A lease since taken by another instance is left alone.
+func (o *TridentOrchestrator) releaseLease() {
+
+	o.leaseMutex.Lock()
+	defer o.leaseMutex.Unlock()
+
+	if o.lease == nil {
+		return
+	}
+	lease := o.lease
+	o.lease = nil
+
+	if err := o.storeClient.DeleteLease(lease); persistentstore.MatchKeyChangedErr(err) ||
+		persistentstore.MatchKeyNotFoundErr(err) {
+		return
+	} else if err != nil {
+		log.Warningf("Could not release the lease on the persistent store. %v", err)
+		return
+	}
+	log.Info("Released the lease on the persistent store.")
+}
@@ -3,13 +3,18 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netapp/trident/config"
@@ -30,6 +35,39 @@ type TridentOrchestrator struct {
 	storageClasses map[string]*storageclass.StorageClass
 	storeClient    persistentstore.Client
 	bootstrapped   bool
+
+	// reconciliationReport is the result of the most recent call to ReconcileBackends
+	reconciliationReport *storage.ReconciliationReport
+
+	snapshotSchedules map[string]*storage.SnapshotSchedule
+	volumeGroups      map[string]*storage.VolumeGroup
+	migrations        map[string]*volumeMigration
+
+	// volumeNames maps each volume's internal name on its backend back to the volume
+	volumeNames map[string]*storage.VolumeNameMapping
+
+	// warmVolumes holds the volumes created ahead of requests for storage classes' warm pools,
+	// which are kept apart from the volumes that have been handed out
+	warmVolumes map[string]*storage.Volume
+
+	// housekeeping runs the orchestrator's periodic background work, such as reconciliation
+	housekeeping *utils.HousekeepingScheduler
+
+	// latency tracks how long volume operations take
+	latency *utils.LatencyTracker
+
+	// usageInterval and usageRetention are how often the volumes' capacity is sampled for usage
+	// reports, and how long the samples are kept
+	usageInterval  time.Duration
+	usageRetention time.Duration
+
+	// instanceID identifies this instance in its lease on the persistent store, which stops other
+	// instances from managing the same volumes while it is held
+	instanceID    string
+	leaseDuration time.Duration
+	lease         *persistentstore.Lease
+	leaseLost     chan struct{}
+	leaseMutex    *sync.Mutex
 }
 
 // NewTridentOrchestrator returns a storage orchestrator instance
@@ -42,9 +80,29 @@ func NewTridentOrchestrator(client persistentstore.Client) *TridentOrchestrator
 		mutex:          &sync.Mutex{},
 		storeClient:    client,
 		bootstrapped:   false,
+
+		snapshotSchedules: make(map[string]*storage.SnapshotSchedule),
+		volumeGroups:      make(map[string]*storage.VolumeGroup),
+		migrations:        make(map[string]*volumeMigration),
+		volumeNames:       make(map[string]*storage.VolumeNameMapping),
+		warmVolumes:       make(map[string]*storage.Volume),
+		housekeeping:      utils.NewHousekeepingScheduler("orchestrator"),
+		latency:           utils.NewLatencyTracker(DefaultSlowOperationThreshold),
+		usageInterval:     DefaultUsageSampleInterval,
+		usageRetention:    DefaultUsageRetention,
+		instanceID:        uuid.New(),
+		leaseLost:         make(chan struct{}),
+		leaseMutex:        &sync.Mutex{},
 	}
 }
 
+// Stop stops the orchestrator's background work, waiting for any in progress to finish, and then
+// releases its lease on the persistent store.
+func (o *TridentOrchestrator) Stop() {
+	o.housekeeping.Stop()
+	o.releaseLease()
+}
+
 func (o *TridentOrchestrator) transformPersistentState() error {
 	// Transforming persistent state happens under two scenarios:
 	// 1) Change in the persistent store version (e.g., from etcdv2 to etcdv3)
@@ -73,6 +131,14 @@ func (o *TridentOrchestrator) transformPersistentState() error {
 		return fmt.Errorf("data migration failed: %v", err)
 	}
 
+	// Upgrade the records stored by earlier versions of Trident.  Only etcd stores keep records
+	// from one version of Trident to the next.
+	if etcdClient, ok := o.storeClient.(persistentstore.EtcdClient); ok {
+		if err = persistentstore.NewSchemaMigrator(etcdClient).Run(); err != nil {
+			return fmt.Errorf("schema migration failed: %v", err)
+		}
+	}
+
 	// Store the persistent store and API versions
 	version.OrchestratorAPIVersion = config.OrchestratorAPIVersion
 	version.PersistentStoreVersion = string(o.storeClient.GetType())
@@ -98,6 +164,11 @@ func (o *TridentOrchestrator) Bootstrap() error {
 		config.OrchestratorTelemetry.PlatformVersion = dockerFrontend.Version()
 	}
 
+	// Claim the persistent store before changing anything in it
+	if err = o.acquireLease(); err != nil {
+		return err
+	}
+
 	// Transform persistent state, if necessary
 	if err = o.transformPersistentState(); err != nil {
 		return err
@@ -119,24 +190,53 @@ func (o *TridentOrchestrator) bootstrapBackends() error {
 		return err
 	}
 
+	backendNamesByUUID := make(map[string]string)
 	for _, b := range persistentBackends {
 		// TODO:  If the API evolves, check the Version field here.
+
+		// A rename interrupted by a restart leaves the backend stored under both its old and new
+		// names.  Its volumes are bound to it by UUID, so either record may be kept.
+		if name, ok := backendNamesByUUID[b.BackendUUID]; ok && b.BackendUUID != "" {
+			log.WithFields(log.Fields{
+				"backend":     b.Name,
+				"backendUUID": b.BackendUUID,
+				"keptBackend": name,
+				"handler":     "Bootstrap",
+			}).Warn("Deleting duplicate record of a renamed backend.")
+			if err = o.storeClient.DeleteBackend(&storage.Backend{Name: b.Name}); err != nil {
+				return err
+			}
+			continue
+		}
+
 		serializedConfig, err := b.MarshalConfig()
 		if err != nil {
 			return err
 		}
-		newBackendExternal, err := o.AddStorageBackend(serializedConfig)
+		o.mutex.Lock()
+		newBackendExternal, err := o.addStorageBackend(serializedConfig, b.BackendUUID)
+		o.mutex.Unlock()
 		if err != nil {
 			return err
 		}
 
-		// Note that AddStorageBackend returns an external copy of the newly
+		// Note that addStorageBackend returns an external copy of the newly
 		// added backend, so we have to go fetch it manually.
 		newBackend := o.backends[newBackendExternal.Name]
 		newBackend.Online = b.Online
+		newBackend.Cordoned = b.Cordoned
+		backendNamesByUUID[newBackend.BackendUUID] = newBackend.Name
+
+		// Backends stored before they had UUIDs keep the one just generated
+		if b.BackendUUID == "" {
+			if err = o.storeClient.UpdateBackend(newBackend); err != nil {
+				return err
+			}
+		}
 		log.WithFields(log.Fields{
-			"backend": newBackend.Name,
-			"handler": "Bootstrap",
+			"backend":     newBackend.Name,
+			"backendUUID": newBackend.BackendUUID,
+			"handler":     "Bootstrap",
 		}).Info("Added an existing backend.")
 	}
 	return nil
@@ -172,12 +272,22 @@ func (o *TridentOrchestrator) bootstrapVolumes() error {
 		var backend *storage.Backend
 		var ok bool
 		backend, ok = o.backends[v.Backend]
+		// The backend may have been renamed since the volume was stored
+		if byUUID := o.backendByUUID(v.BackendUUID); byUUID != nil {
+			backend, ok = byUUID, true
+		}
 		if !ok {
 			return fmt.Errorf("couldn't find backend %s for volume %s",
 				v.Backend, v.Config.Name)
 		}
 		vol := storage.NewVolume(v.Config, backend.Name, v.Pool, v.Orphaned)
-		backend.Volumes[vol.Config.Name], o.volumes[vol.Config.Name] = vol, vol
+		vol.BackendUUID = backend.BackendUUID
+		backend.Volumes[vol.Config.Name] = vol
+		if vol.Config.WarmPool {
+			o.warmVolumes[vol.Config.Name] = vol
+		} else {
+			o.volumes[vol.Config.Name] = vol
+		}
 
 		log.WithFields(log.Fields{
 			"volume":       vol.Config.Name,
@@ -186,6 +296,7 @@ func (o *TridentOrchestrator) bootstrapVolumes() error {
 			"backend":      vol.Backend,
 			"pool":         vol.Pool,
 			"orphaned":     vol.Orphaned,
+			"warmPool":     vol.Config.WarmPool,
 			"handler":      "Bootstrap",
 		}).Info("Added an existing volume.")
 	}
@@ -208,12 +319,80 @@ func (o *TridentOrchestrator) bootstrapVolTxns() error {
 	return nil
 }
 
+// bootstrapJournal asks each backend's driver to complete or clean up any multi-step operation
+// that was interrupted when Trident last stopped.  This runs after volume transactions have been
+// rolled back, so the drivers see the backends as the orchestrator has left them.
+func (o *TridentOrchestrator) bootstrapJournal() error {
+	entries, err := o.storeClient.GetJournalEntries()
+	if err != nil {
+		log.Warnf("Couldn't retrieve driver operation journal: %v", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if err = o.reconcileJournalEntry(entry, "Bootstrap"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileJournalEntry asks the driver of the entry's backend to complete or clean up after the
+// journaled operation, then deletes the entry.  An entry whose backend can't be found, or whose
+// driver fails to reconcile it, is left for a later attempt, which isn't an error; only failing
+// to delete the entry is.
+func (o *TridentOrchestrator) reconcileJournalEntry(entry *drivers.JournalEntry, handler string) error {
+	logFields := log.Fields{
+		"backend":   entry.Backend,
+		"volume":    entry.Volume,
+		"operation": entry.Operation,
+		"steps":     entry.Steps,
+		"handler":   handler,
+	}
+
+	backend, ok := o.backends[entry.Backend]
+	if !ok {
+		log.WithFields(logFields).Warn("Backend for journaled operation not found, leaving journal entry.")
+		return nil
+	}
+	if _, ok := backend.Driver.(storage.JournalingDriver); ok {
+		if err := backend.Guarded().ReconcileJournalEntry(entry); err != nil {
+			log.WithFields(logFields).Errorf("Could not reconcile journaled operation: %v", err)
+			return nil
+		}
+	} else {
+		log.WithFields(logFields).Warn("Backend no longer journals operations, discarding journal entry.")
+	}
+
+	if err := o.storeClient.DeleteJournalEntry(entry); err != nil {
+		return fmt.Errorf("failed to clean up journal entry for volume %s: %v", entry.Volume, err)
+	}
+	log.WithFields(logFields).Info("Reconciled journaled operation.")
+	return nil
+}
+
+// backendJournal stores a backend's driver operation journal in the persistent store.
+type backendJournal struct {
+	backend     string
+	storeClient persistentstore.Client
+}
+
+func (j *backendJournal) Record(entry *drivers.JournalEntry) error {
+	entry.Backend = j.backend
+	return j.storeClient.AddJournalEntry(entry)
+}
+
+func (j *backendJournal) Remove(entry *drivers.JournalEntry) error {
+	entry.Backend = j.backend
+	return j.storeClient.DeleteJournalEntry(entry)
+}
+
 func (o *TridentOrchestrator) bootstrap() error {
 	// Fetching backend information
 
 	type bootstrapFunc func() error
 	for _, f := range []bootstrapFunc{o.bootstrapBackends,
-		o.bootstrapStorageClasses, o.bootstrapVolumes, o.bootstrapVolTxns} {
+		o.bootstrapStorageClasses, o.bootstrapVolumes, o.bootstrapVolTxns, o.bootstrapJournal,
+		o.bootstrapSnapshotSchedules, o.bootstrapVolumeGroups, o.bootstrapVolumeNames} {
 		err := f()
 		if err != nil {
 			if persistentstore.MatchKeyNotFoundErr(err) {
@@ -262,7 +441,7 @@ func (o *TridentOrchestrator) rollBackTransaction(v *persistentstore.VolumeTrans
 			// If the volume was added to etcd, we will have loaded the
 			// volume into memory, and we can just delete it normally.
 			// Handles case 3)
-			err := o.deleteVolume(v.Config.Name)
+			err := o.deleteVolume(context.Background(), v.Config.Name)
 			if err != nil {
 				return fmt.Errorf("unable to clean up volume %s: %v", v.Config.Name, err)
 			}
@@ -283,9 +462,18 @@ func (o *TridentOrchestrator) rollBackTransaction(v *persistentstore.VolumeTrans
 				// return a standardized error when a volume is not found.
 				// For now, though, fail on an error, since backends currently
 				// do not report errors for volumes not present.
-				if err := backend.Driver.Destroy(
-					backend.Driver.GetInternalVolumeName(v.Config.Name),
-				); err != nil {
+				cleanupConfig := *v.Config
+				internalName, err := backend.GetInternalVolumeName(&cleanupConfig)
+				if err != nil {
+					// The volume could not have been created under a name the backend can't make
+					continue
+				}
+				cleanupConfig.InternalName = internalName
+				if backend.CheckInternalNameCollision(&cleanupConfig) != nil {
+					// The name belongs to another volume, so this one was never created
+					continue
+				}
+				if err := backend.Guarded().Destroy(context.Background(), internalName); err != nil {
 					return fmt.Errorf("error attempting to clean up volume %s from backend %s: %v", v.Config.Name,
 						backend.Name, err)
 				}
@@ -307,7 +495,7 @@ func (o *TridentOrchestrator) rollBackTransaction(v *persistentstore.VolumeTrans
 			log.WithFields(log.Fields{
 				"name": v.Config.Name,
 			}).Info("Volume for delete transaction found.")
-			err := o.deleteVolume(v.Config.Name)
+			err := o.deleteVolume(context.Background(), v.Config.Name)
 			if err != nil {
 				return fmt.Errorf("unable to clean up deleted volume %s: %v", v.Config.Name, err)
 			}
@@ -319,6 +507,29 @@ func (o *TridentOrchestrator) rollBackTransaction(v *persistentstore.VolumeTrans
 		if err := o.storeClient.DeleteVolumeTransaction(v); err != nil {
 			return fmt.Errorf("failed to clean up volume deletion transaction: %v", err)
 		}
+	case persistentstore.MigrateVolume:
+		// The volume is bound to whichever backend the migration last
+		// recorded in etcd.  A replica is removed, but an original is kept
+		// along with the transaction until the migration is deleted.
+		retained, err := o.rollBackMigration(v)
+		if err != nil {
+			return err
+		}
+		if retained {
+			return nil
+		}
+		if err := o.storeClient.DeleteVolumeTransaction(v); err != nil {
+			return fmt.Errorf("failed to clean up volume migration transaction: %v", err)
+		}
+	case persistentstore.RehostVolume:
+		// The storage may have moved the volume without it being bound
+		// to the new backend, so the rehost is finished rather than undone.
+		if err := o.finishRehost(v); err != nil {
+			return err
+		}
+		if err := o.storeClient.DeleteVolumeTransaction(v); err != nil {
+			return fmt.Errorf("failed to clean up volume rehost transaction: %v", err)
+		}
 	}
 	return nil
 }
@@ -358,33 +569,170 @@ func (o *TridentOrchestrator) AddStorageBackend(configJSON string) (
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
+	return o.addStorageBackend(configJSON, "")
+}
+
+// addStorageBackend adds a backend, or updates the backend of the same name.  A new backend is
+// given the UUID passed in, or a new UUID if none is.  The caller must hold the mutex.
+func (o *TridentOrchestrator) addStorageBackend(configJSON, backendUUID string) (
+	*storage.BackendExternal, error) {
+
 	storageBackend, err := factory.NewStorageBackendForConfig(configJSON)
 	if err != nil {
 		return nil, err
 	}
-	newBackend := true
 	originalBackend, ok := o.backends[storageBackend.Name]
-	if ok {
-		newBackend = false
-		if err = o.validateBackendUpdate(originalBackend, storageBackend); err != nil {
+	if !ok {
+		if backendUUID == "" {
+			backendUUID = uuid.New()
+		}
+		storageBackend.BackendUUID = backendUUID
+		originalBackend = nil
+	}
+	return o.replaceBackend(originalBackend, storageBackend)
+}
+
+// UpdateBackend replaces the config of an existing backend, identified by name or UUID.  Unlike
+// AddStorageBackend, the new config may give the backend a different name, such as when the
+// address its name was generated from changes; the backend keeps its UUID and its volumes.
+func (o *TridentOrchestrator) UpdateBackend(backendName, configJSON string) (
+	*storage.BackendExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	originalBackend := o.backendByNameOrUUID(backendName)
+	if originalBackend == nil {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	storageBackend, err := factory.NewStorageBackendForConfig(configJSON)
+	if err != nil {
+		return nil, err
+	}
+	if other, ok := o.backends[storageBackend.Name]; ok && other != originalBackend {
+		storageBackend.Terminate()
+		return nil, fmt.Errorf("backend %s already exists", storageBackend.Name)
+	}
+	return o.replaceBackend(originalBackend, storageBackend)
+}
+
+// RenameBackend gives an existing backend, identified by name or UUID, a new name.  The name is
+// saved in the backend's config, so it no longer follows the address it was generated from.
+func (o *TridentOrchestrator) RenameBackend(backendName, newName string) (
+	*storage.BackendExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	originalBackend := o.backendByNameOrUUID(backendName)
+	if originalBackend == nil {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	if newName == "" {
+		return nil, errors.New("the new backend name may not be empty")
+	}
+	if other, ok := o.backends[newName]; ok && other != originalBackend {
+		return nil, fmt.Errorf("backend %s already exists", newName)
+	}
+
+	configJSON, err := originalBackend.ConstructPersistent().MarshalConfig()
+	if err != nil {
+		return nil, err
+	}
+	var configMap map[string]interface{}
+	if err = json.Unmarshal([]byte(configJSON), &configMap); err != nil {
+		return nil, err
+	}
+	configMap["backendName"] = newName
+	renamedJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBackend, err := factory.NewStorageBackendForConfig(string(renamedJSON))
+	if err != nil {
+		return nil, err
+	}
+	return o.replaceBackend(originalBackend, storageBackend)
+}
+
+// checkDuplicateBackend returns an error if a backend other than the one being replaced manages
+// the same storage as a new backend, such as the same ONTAP SVM reached through a different LIF.
+// Both would otherwise manage, and might delete, the same volumes.  Duplicates that were already
+// saved are only warned about while bootstrapping, so that none of their volumes is lost.  The
+// caller must hold the mutex.
+func (o *TridentOrchestrator) checkDuplicateBackend(originalBackend, storageBackend *storage.Backend) error {
+
+	identity := storageBackend.StorageIdentity()
+	if identity == "" {
+		return nil
+	}
+	for _, other := range o.backends {
+		if other == originalBackend || other.StorageIdentity() != identity {
+			continue
+		}
+		if !o.bootstrapped {
+			log.WithFields(log.Fields{
+				"backend":   storageBackend.Name,
+				"duplicate": other.Name,
+			}).Warning("Backends manage the same storage; one of them should be deleted.")
+			return nil
+		}
+		return fmt.Errorf("backend %s manages the same storage as existing backend %s; update that "+
+			"backend instead", storageBackend.Name, other.Name)
+	}
+	return nil
+}
+
+// replaceBackend installs a newly created backend, in place of originalBackend if that isn't
+// nil.  If it can't be installed, the new backend is terminated, so that its background work,
+// such as an ONTAP heartbeat, doesn't outlive it.  The caller must hold the mutex.
+func (o *TridentOrchestrator) replaceBackend(originalBackend, storageBackend *storage.Backend) (
+	backendExternal *storage.BackendExternal, err error) {
+
+	defer func() {
+		if err != nil {
+			storageBackend.Terminate()
+		}
+	}()
+
+	newBackend := originalBackend == nil
+	if err := o.checkDuplicateBackend(originalBackend, storageBackend); err != nil {
+		return nil, err
+	}
+	if !newBackend {
+		if err := o.validateBackendUpdate(originalBackend, storageBackend); err != nil {
 			return nil, err
 		}
+		// Updating a backend's config doesn't lift a cordon on it or change its identity
+		storageBackend.Cordoned = originalBackend.Cordoned
+		storageBackend.BackendUUID = originalBackend.BackendUUID
 	}
+	renamed := !newBackend && originalBackend.Name != storageBackend.Name
 
 	// Update backend information
 	log.WithFields(log.Fields{
 		"backend":       storageBackend.Name,
+		"backendUUID":   storageBackend.BackendUUID,
 		"backendUpdate": !newBackend,
+		"renamed":       renamed,
 	}).Debug("Adding backend.")
-	if err = o.updateBackendOnPersistentStore(storageBackend, newBackend); err != nil {
+	if renamed {
+		if err := o.renameBackendOnPersistentStore(originalBackend, storageBackend); err != nil {
+			return nil, err
+		}
+	} else if err := o.updateBackendOnPersistentStore(storageBackend, newBackend); err != nil {
 		return nil, err
 	}
 
 	if !newBackend {
 		originalBackend.Terminate()
+		delete(o.backends, originalBackend.Name)
 	}
 	o.backends[storageBackend.Name] = storageBackend
 
+	if _, ok := storageBackend.Driver.(storage.JournalingDriver); ok {
+		storageBackend.Guarded().SetJournal(&backendJournal{storageBackend.Name, o.storeClient})
+	}
+
 	// Update volume information
 	// Identify orphaned volumes (i.e., volumes that are not present on the
 	// new backend). Such a scenario can happen if a subset of volumes are
@@ -392,8 +740,11 @@ func (o *TridentOrchestrator) AddStorageBackend(configJSON string) (
 	// such volumes are likely to fail, so here we just warn the users about
 	// such volumes and mark them as orphaned.
 	for volName, vol := range o.volumes {
+		if vol.Backend != storageBackend.Name {
+			continue
+		}
 		updatePersistentStore := false
-		volExternal, _ := storageBackend.Driver.GetVolumeExternal(vol.Config.InternalName)
+		volExternal, _ := storageBackend.GetVolumeExternal(vol.Config.InternalName)
 		if volExternal == nil {
 			if vol.Orphaned == false {
 				vol.Orphaned = true
@@ -444,17 +795,61 @@ func (o *TridentOrchestrator) AddStorageBackend(configJSON string) (
 	return storageBackend.ConstructExternal(), nil
 }
 
+// GetBackend returns the backend with the given name or UUID.
 func (o *TridentOrchestrator) GetBackend(backend string) *storage.BackendExternal {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
-	var storageBackend *storage.Backend
-	var found bool
-	if storageBackend, found = o.backends[backend]; !found {
+	storageBackend := o.backendByNameOrUUID(backend)
+	if storageBackend == nil {
 		return nil
 	}
 	return storageBackend.ConstructExternal()
 }
 
+// backendByUUID returns the backend with the given UUID, or nil if there is none.
+func (o *TridentOrchestrator) backendByUUID(backendUUID string) *storage.Backend {
+	if backendUUID == "" {
+		return nil
+	}
+	for _, backend := range o.backends {
+		if backend.BackendUUID == backendUUID {
+			return backend
+		}
+	}
+	return nil
+}
+
+func (o *TridentOrchestrator) backendByNameOrUUID(backend string) *storage.Backend {
+	if storageBackend, ok := o.backends[backend]; ok {
+		return storageBackend
+	}
+	return o.backendByUUID(backend)
+}
+
+// GetBackendWithCapacity is like GetBackend, but it also reports the capacity of each storage
+// pool, as gathered from the backend's driver.
+func (o *TridentOrchestrator) GetBackendWithCapacity(backend string) *storage.BackendExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	storageBackend := o.backendByNameOrUUID(backend)
+	if storageBackend == nil {
+		return nil
+	}
+	return storageBackend.ConstructExternalWithCapacity()
+}
+
+// GetBackendWithConfigDetail is like GetBackendWithCapacity, but it reports the driver's whole
+// config, less its credentials, instead of the summary the driver exposes.
+func (o *TridentOrchestrator) GetBackendWithConfigDetail(backend string) (*storage.BackendExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	storageBackend := o.backendByNameOrUUID(backend)
+	if storageBackend == nil {
+		return nil, fmt.Errorf("backend %s not found", backend)
+	}
+	return storageBackend.ConstructExternalWithConfigDetail()
+}
+
 func (o *TridentOrchestrator) ListBackends() []*storage.BackendExternal {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
@@ -486,6 +881,7 @@ func (o *TridentOrchestrator) OfflineBackend(backendName string) (bool, error) {
 	for _, sc := range storageClasses {
 		sc.RemovePoolsForBackend(backend)
 	}
+	o.deleteWarmVolumesForBackend(backend)
 	if !backend.HasVolumes() {
 		backend.Terminate()
 		delete(o.backends, backendName)
@@ -494,7 +890,79 @@ func (o *TridentOrchestrator) OfflineBackend(backendName string) (bool, error) {
 	return true, o.storeClient.UpdateBackend(backend)
 }
 
-func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
+// CordonBackend cordons or uncordons a backend.  A cordoned backend continues to serve its
+// existing volumes, but no new volumes or clones are provisioned on it.
+func (o *TridentOrchestrator) CordonBackend(backendName string, cordoned bool) (
+	*storage.BackendExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backend := o.backendByNameOrUUID(backendName)
+	if backend == nil || !backend.Online {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	if backend.Cordoned != cordoned {
+		backend.Cordoned = cordoned
+		if err := o.storeClient.UpdateBackend(backend); err != nil {
+			backend.Cordoned = !cordoned
+			return nil, err
+		}
+		log.WithFields(log.Fields{
+			"backend":  backendName,
+			"cordoned": cordoned,
+		}).Info("Changed backend cordon state.")
+	}
+	return backend.ConstructExternal(), nil
+}
+
+// UpdateBackendDebugTraceFlags enables or disables debug trace flags, such as "method" and "api",
+// on a running backend and returns the resulting flags.  Flags not named in the changes are left
+// as they were.  The new flags are persisted, so they survive a restart.
+func (o *TridentOrchestrator) UpdateBackendDebugTraceFlags(backendName string, changes map[string]bool) (
+	map[string]bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backend := o.backendByNameOrUUID(backendName)
+	if backend == nil || !backend.Online {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	previous, err := backend.GetDebugTraceFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	flags := make(map[string]bool, len(previous)+len(changes))
+	for flag, enabled := range previous {
+		flags[flag] = enabled
+	}
+	for flag, enabled := range changes {
+		if flags[flag] != enabled {
+			changed = true
+		}
+		flags[flag] = enabled
+	}
+	if !changed {
+		return previous, nil
+	}
+
+	if err = backend.SetDebugTraceFlags(flags); err != nil {
+		return nil, err
+	}
+	if err = o.storeClient.UpdateBackend(backend); err != nil {
+		backend.SetDebugTraceFlags(previous)
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"backend": backendName,
+		"flags":   flags,
+	}).Info("Changed backend debug trace flags.")
+
+	return backend.GetDebugTraceFlags()
+}
+
+func (o *TridentOrchestrator) AddVolume(ctx context.Context, volumeConfig *storage.VolumeConfig) (
 	externalVol *storage.VolumeExternal, err error) {
 	var (
 		backend *storage.Backend
@@ -506,18 +974,84 @@ func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
 	if _, ok := o.volumes[volumeConfig.Name]; ok {
 		return nil, fmt.Errorf("volume %s already exists", volumeConfig.Name)
 	}
+	if _, ok := o.warmVolumes[volumeConfig.Name]; ok {
+		return nil, fmt.Errorf("volume %s already exists", volumeConfig.Name)
+	}
 	volumeConfig.Version = config.OrchestratorAPIVersion
 
-	sc, ok := o.storageClasses[volumeConfig.StorageClass]
-	if !ok {
+	ctx, done := o.startOperation(ctx, operationCreate, volumeConfig.Name)
+	defer func() { done(operationBackend(externalVol), err) }()
+
+	// Only clones may be left on their storage when deleted
+	if err = drivers.ValidateOnDelete(volumeConfig.OnDelete); err != nil {
+		return nil, err
+	}
+	if volumeConfig.OnDelete != "" && volumeConfig.OnDelete != drivers.OnDeleteDelete {
+		return nil, fmt.Errorf("onDelete %s may only be set when cloning a volume", volumeConfig.OnDelete)
+	}
+
+	// Caches are created from their origin volume by a backend that can reach it
+	var originBackend *storage.Backend
+	if volumeConfig.IsCache() {
+		if originBackend, err = o.prepareCache(volumeConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	sc := o.resolveStorageClass(volumeConfig.StorageClass)
+	if sc == nil {
 		return nil, fmt.Errorf("unknown storage class: %s",
 			volumeConfig.StorageClass)
 	}
+	volumeConfig.StorageClass = sc.GetName()
+	if err = checkStorageClassAccess(volumeConfig, sc); err != nil {
+		return nil, err
+	}
+	if err = applyStorageClassDefaultSize(volumeConfig, sc); err != nil {
+		return nil, err
+	}
 	pools := sc.GetStoragePoolsForProtocol(volumeConfig.Protocol)
 	if len(pools) == 0 {
 		return nil, fmt.Errorf("no available backends for storage class %s",
 			volumeConfig.StorageClass)
 	}
+	if volumeConfig.ReadOnly {
+		if pools = readOnlyPools(pools); len(pools) == 0 {
+			return nil, drivers.NewFatalError(fmt.Sprintf(
+				"no backends for storage class %s support read-only volumes", volumeConfig.StorageClass))
+		}
+	}
+	if originBackend != nil {
+		if pools = cachePools(pools, originBackend); len(pools) == 0 {
+			return nil, drivers.NewFatalError(fmt.Sprintf("no backends for storage class %s can cache volume %s",
+				volumeConfig.StorageClass, volumeConfig.CacheSourceVolume))
+		}
+	}
+
+	// Skip cordoned backends.  If that leaves nothing, the request may succeed once
+	// maintenance is complete, so it is worth retrying.
+	uncordonedPools := make([]*storage.Pool, 0, len(pools))
+	for _, pool := range pools {
+		if !pool.Backend.Cordoned {
+			uncordonedPools = append(uncordonedPools, pool)
+		}
+	}
+	if len(uncordonedPools) == 0 {
+		return nil, drivers.NewRetryableError(fmt.Sprintf(
+			"all backends for storage class %s are cordoned", volumeConfig.StorageClass))
+	}
+	pools = uncordonedPools
+
+	// Hand out a volume created ahead of time, if the class keeps any that suit the request
+	if warmVol := o.claimWarmVolume(ctx, volumeConfig, pools); warmVol != nil {
+		return warmVol.ConstructExternal(), nil
+	}
+	if volumeConfig.WarmPool {
+		if pools = warmPoolPools(pools); len(pools) == 0 {
+			return nil, fmt.Errorf("no backends for storage class %s support warm pools",
+				volumeConfig.StorageClass)
+		}
+	}
 
 	// Add transaction in case the operation must be rolled back later
 	volTxn, err := o.addVolumeTransaction(volumeConfig)
@@ -536,11 +1070,19 @@ func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
 	}).Debugf("Looking through %d storage pools.", len(pools))
 
 	errorMessages := make([]string, 0)
-
-	// Choose a pool at random.
-	for _, num := range rand.Perm(len(pools)) {
-		backend = pools[num].Backend
-		vol, err = backend.AddVolume(volumeConfig, pools[num], sc.GetAttributes())
+	allFatal := true
+	anyRetryable := false
+
+	// Try the pools in order of priority, choosing among pools of equal priority at random.
+	for _, pool := range orderPoolsForPlacement(pools) {
+		backend = pool.Backend
+		drivers.ReportProgress(ctx, "BackendSelected", fmt.Sprintf(
+			"Creating volume on storage pool %s from backend %s.", pool.Name, backend.Name))
+		if originBackend != nil {
+			vol, err = backend.AddCache(ctx, volumeConfig, pool, originBackend)
+		} else {
+			vol, err = backend.AddVolume(ctx, volumeConfig, pool, sc.GetAttributes())
+		}
 		if vol != nil && err == nil {
 			if vol.Config.Protocol == config.ProtocolAny {
 				vol.Config.Protocol = backend.GetProtocol()
@@ -549,21 +1091,30 @@ func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
 			if err != nil {
 				return nil, err
 			}
-			o.volumes[volumeConfig.Name] = vol
+			if volumeConfig.WarmPool {
+				o.warmVolumes[volumeConfig.Name] = vol
+			} else {
+				o.volumes[volumeConfig.Name] = vol
+				o.addVolumeNameMapping(vol)
+			}
 			externalVol = vol.ConstructExternal()
 			return externalVol, nil
 		} else if err != nil {
 			log.WithFields(log.Fields{
 				"backend": backend.Name,
-				"pool":    pools[num].Name,
+				"pool":    pool.Name,
 				"volume":  volumeConfig.Name,
 				"error":   err,
 			}).Warn("Failed to create the volume on this backend!")
+			drivers.ReportWarning(ctx, "BackendFailed", fmt.Sprintf(
+				"Failed to create volume on storage pool %s from backend %s: %v", pool.Name, backend.Name, err))
 			errorMessages = append(errorMessages,
 				fmt.Sprintf("[Failed to create volume %s "+
 					"on storage pool %s from backend %s: %s]",
-					volumeConfig.Name, pools[num].Name, backend.Name,
+					volumeConfig.Name, pool.Name, backend.Name,
 					err.Error()))
+			allFatal = allFatal && drivers.IsFatalError(err)
+			anyRetryable = anyRetryable || drivers.IsRetryableError(err)
 		}
 	}
 
@@ -574,15 +1125,100 @@ func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
 			" under %s", volumeConfig.Protocol,
 			volumeConfig.StorageClass, volumeConfig.Size, config.BackendURL)
 	} else {
-		err = fmt.Errorf("encountered error(s) in creating the volume: %s",
+		// Preserve the error classification so the frontends know whether to retry.  Only when
+		// every pool failed permanently is the request itself hopeless.
+		message := fmt.Sprintf("encountered error(s) in creating the volume: %s",
 			strings.Join(errorMessages, ", "))
+		switch {
+		case anyRetryable:
+			err = drivers.NewRetryableError(message)
+		case allFatal:
+			err = drivers.NewFatalError(message)
+		default:
+			err = errors.New(message)
+		}
 	}
 	return nil, err
 }
 
+// PreviewVolumePlacement reports which storage pools would be considered for a volume, in the
+// order they would be tried, along with the options each backend would use to create it.
+// Nothing is created, so this is useful for debugging storage class matching.
+func (o *TridentOrchestrator) PreviewVolumePlacement(volumeConfig *storage.VolumeConfig) (
+	*storage.PlacementPreview, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	sc := o.resolveStorageClass(volumeConfig.StorageClass)
+	if sc == nil {
+		return nil, fmt.Errorf("unknown storage class: %s", volumeConfig.StorageClass)
+	}
+	if err := checkStorageClassAccess(volumeConfig, sc); err != nil {
+		return nil, err
+	}
+
+	preview := &storage.PlacementPreview{
+		Volume:       volumeConfig.Name,
+		StorageClass: sc.GetName(),
+		Candidates:   make([]*storage.PlacementCandidate, 0),
+	}
+
+	uncordonedPools := make([]*storage.Pool, 0)
+	for _, pool := range sc.GetStoragePoolsForProtocol(volumeConfig.Protocol) {
+		if pool.Backend.Cordoned {
+			candidate := newPlacementCandidate(pool)
+			candidate.Reason = "backend is cordoned"
+			preview.Excluded = append(preview.Excluded, candidate)
+			continue
+		}
+		if volumeConfig.ReadOnly && !pool.Backend.SupportsReadOnly() {
+			candidate := newPlacementCandidate(pool)
+			candidate.Reason = "backend does not support read-only volumes"
+			preview.Excluded = append(preview.Excluded, candidate)
+			continue
+		}
+		uncordonedPools = append(uncordonedPools, pool)
+	}
+
+	for _, pool := range orderPoolsForPlacement(uncordonedPools) {
+		candidate := newPlacementCandidate(pool)
+
+		// Work on a copy, as drivers may fill in the config while determining the options
+		candidateConfig := *volumeConfig
+		internalName, err := pool.Backend.GetInternalVolumeName(volumeConfig)
+		if err != nil {
+			candidate.Reason = err.Error()
+			preview.Excluded = append(preview.Excluded, candidate)
+			continue
+		}
+		candidateConfig.InternalName = internalName
+		candidate.InternalName = internalName
+
+		options, err := pool.Backend.Guarded().GetVolumeOpts(&candidateConfig, pool, sc.GetAttributes())
+		if err != nil {
+			candidate.Reason = err.Error()
+			preview.Excluded = append(preview.Excluded, candidate)
+			continue
+		}
+		candidate.Options = options
+		preview.Candidates = append(preview.Candidates, candidate)
+	}
+
+	return preview, nil
+}
+
+func newPlacementCandidate(pool *storage.Pool) *storage.PlacementCandidate {
+	return &storage.PlacementCandidate{
+		Backend:  pool.Backend.Name,
+		Pool:     pool.Name,
+		Priority: pool.Priority,
+		Weight:   pool.Weight,
+	}
+}
+
 func (o *TridentOrchestrator) CloneVolume(
-	volumeConfig *storage.VolumeConfig,
-) (*storage.VolumeExternal, error) {
+	ctx context.Context, volumeConfig *storage.VolumeConfig,
+) (externalVol *storage.VolumeExternal, err error) {
 
 	var (
 		found   bool
@@ -596,6 +1232,12 @@ func (o *TridentOrchestrator) CloneVolume(
 		return nil, fmt.Errorf("volume %s already exists", volumeConfig.Name)
 	}
 	volumeConfig.Version = config.OrchestratorAPIVersion
+	if err := drivers.ValidateOnDelete(volumeConfig.OnDelete); err != nil {
+		return nil, err
+	}
+
+	ctx, done := o.startOperation(ctx, operationClone, volumeConfig.Name)
+	defer func() { done(operationBackend(externalVol), err) }()
 
 	// Get the source volume
 	sourceVolume, found := o.volumes[volumeConfig.CloneSourceVolume]
@@ -603,6 +1245,10 @@ func (o *TridentOrchestrator) CloneVolume(
 		return nil, fmt.Errorf("source volume not found: %s",
 			volumeConfig.CloneSourceVolume)
 	}
+	if sourceVolume.Config.IsCache() {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf("volume %s is a cache, which cannot be cloned",
+			volumeConfig.CloneSourceVolume))
+	}
 	if sourceVolume.Orphaned {
 		log.WithFields(log.Fields{
 			"source_volume": sourceVolume.Config.Name,
@@ -623,6 +1269,18 @@ func (o *TridentOrchestrator) CloneVolume(
 	cloneConfig.CloneSourceSnapshot = volumeConfig.CloneSourceSnapshot
 	cloneConfig.QoS = volumeConfig.QoS
 	cloneConfig.QoSType = volumeConfig.QoSType
+	cloneConfig.OnDelete = volumeConfig.OnDelete
+	cloneConfig.ReadOnly = volumeConfig.ReadOnly
+	cloneConfig.Namespace = volumeConfig.Namespace
+	cloneConfig.RequestName = volumeConfig.RequestName
+	cloneConfig.CloneSourceVolumeInternal = sourceVolume.Config.InternalName
+
+	// The clone belongs to the source's storage class, which the requester must be allowed to use
+	if sc, ok := o.storageClasses[cloneConfig.StorageClass]; ok {
+		if err = checkStorageClassAccess(cloneConfig, sc); err != nil {
+			return nil, err
+		}
+	}
 
 	// Add transaction in case the operation must be rolled back later
 	volTxn, err := o.addVolumeTransaction(volumeConfig)
@@ -641,10 +1299,48 @@ func (o *TridentOrchestrator) CloneVolume(
 				volumeConfig.CloneSourceVolume)
 	}
 
-	vol, err = backend.CloneVolume(cloneConfig)
+	// Fail fast if the backend has told us it can't clone, preserving the error type for the frontends
+	if !backend.SupportsClones() {
+		err = drivers.NewUnsupportedError(fmt.Sprintf("backend %s does not support cloning", backend.Name))
+		return nil, err
+	}
+
+	if !backend.SupportsOnDelete(cloneConfig.OnDelete) {
+		err = drivers.NewUnsupportedError(fmt.Sprintf("backend %s does not support onDelete %s",
+			backend.Name, cloneConfig.OnDelete))
+		return nil, err
+	}
+
+	if cloneConfig.ReadOnly && !backend.SupportsReadOnly() {
+		err = drivers.NewUnsupportedError(fmt.Sprintf("backend %s does not support read-only volumes",
+			backend.Name))
+		return nil, err
+	}
+
+	// Clones land on the source volume's backend, so they must wait out a cordon
+	if backend.Cordoned {
+		err = drivers.NewRetryableError(fmt.Sprintf("backend %s is cordoned", backend.Name))
+		return nil, err
+	}
+
+	drivers.ReportProgress(ctx, "BackendSelected", fmt.Sprintf(
+		"Cloning volume %s on backend %s.", cloneConfig.CloneSourceVolume, backend.Name))
+	vol, err = backend.CloneVolume(ctx, cloneConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cloned volume %s on backend %s: %v", cloneConfig.Name,
+		if drivers.IsUnsupportedError(err) {
+			return nil, err
+		}
+		message := fmt.Sprintf("failed to create cloned volume %s on backend %s: %v", cloneConfig.Name,
 			backend.Name, err)
+		switch {
+		case drivers.IsFatalError(err):
+			err = drivers.NewFatalError(message)
+		case drivers.IsRetryableError(err):
+			err = drivers.NewRetryableError(message)
+		default:
+			err = errors.New(message)
+		}
+		return nil, err
 	}
 
 	// Save references to new volume
@@ -653,6 +1349,7 @@ func (o *TridentOrchestrator) CloneVolume(
 		return nil, err
 	}
 	o.volumes[cloneConfig.Name] = vol
+	o.addVolumeNameMapping(vol)
 
 	return vol.ConstructExternal(), nil
 }
@@ -668,8 +1365,9 @@ func (o *TridentOrchestrator) addVolumeTransaction(
 	// If this fails, return an error.  If it succeeds or no transaction
 	// existed, log a new transaction in the persistent store and proceed.
 	volTxn := &persistentstore.VolumeTransaction{
-		Config: volumeConfig,
-		Op:     persistentstore.AddVolume,
+		Config:    volumeConfig,
+		Op:        persistentstore.AddVolume,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
 	}
 	oldTxn, err := o.storeClient.GetExistingVolumeTransaction(volTxn)
 	if err != nil {
@@ -713,8 +1411,8 @@ func (o *TridentOrchestrator) addVolumeCleanup(
 		// volume txn at this point.
 		if backend != nil && vol != nil {
 			// We succeeded in adding the volume to the backend; now
-			// delete it
-			cleanupErr = backend.RemoveVolume(vol)
+			// delete it, even if the caller has given up on the operation
+			cleanupErr = backend.RemoveVolume(context.Background(), vol)
 			if cleanupErr != nil {
 				cleanupErr = fmt.Errorf("Unable to delete volume "+
 					"from backend during cleanup:  %v", cleanupErr)
@@ -733,6 +1431,9 @@ func (o *TridentOrchestrator) addVolumeCleanup(
 	if cleanupErr != nil || txErr != nil {
 		// Remove the volume from memory, if it's there, so that the user
 		// can try to re-add.  This will trigger recovery code.
+		if vol != nil {
+			o.deleteVolumeNameMapping(vol)
+		}
 		delete(o.volumes, volumeConfig.Name)
 		//externalVol = nil
 		// Report on all errors we encountered.
@@ -758,6 +1459,44 @@ func (o *TridentOrchestrator) GetVolume(volume string) *storage.VolumeExternal {
 	return vol.ConstructExternal()
 }
 
+// UpdateVolumeQoS changes the QoS of an existing volume, either to that of a named QoS type, such as
+// a SolidFire QoS tier, or to explicit IOPS in the backend's format.  The change takes effect
+// immediately and is persisted with the volume.
+func (o *TridentOrchestrator) UpdateVolumeQoS(volumeName, qos, qosType string) (*storage.VolumeExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	vol, found := o.volumes[volumeName]
+	if !found {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if qos == "" && qosType == "" {
+		return nil, drivers.NewFatalError("either a QoS type or QoS values must be specified")
+	}
+	backend, found := o.backends[vol.Backend]
+	if !found {
+		return nil, fmt.Errorf("backend %s for volume %s not found", vol.Backend, volumeName)
+	}
+
+	previousQoS, previousQoSType := vol.Config.QoS, vol.Config.QoSType
+	if err := backend.ModifyVolumeQoS(vol, qos, qosType); err != nil {
+		return nil, err
+	}
+	if err := o.updateVolumeOnPersistentStore(vol); err != nil {
+		log.WithField("volume", volumeName).Errorf(
+			"QoS changed on the storage, but the volume could not be updated in the store. %v", err)
+		vol.Config.QoS, vol.Config.QoSType = previousQoS, previousQoSType
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"volume":  volumeName,
+		"qos":     qos,
+		"qosType": qosType,
+	}).Info("Changed volume QoS.")
+
+	return vol.ConstructExternal(), nil
+}
+
 func (o *TridentOrchestrator) GetDriverTypeForVolume(
 	vol *storage.VolumeExternal,
 ) string {
@@ -765,7 +1504,7 @@ func (o *TridentOrchestrator) GetDriverTypeForVolume(
 	defer o.mutex.Unlock()
 
 	if b, ok := o.backends[vol.Backend]; ok {
-		return b.Driver.Name()
+		return b.Guarded().Name()
 	}
 	return config.UnknownDriver
 }
@@ -788,6 +1527,10 @@ func (o *TridentOrchestrator) GetVolumeType(vol *storage.VolumeExternal) config.
 		return config.SolidFireISCSI
 	case driver == drivers.EseriesIscsiStorageDriverName:
 		return config.ESeriesISCSI
+	case driver == drivers.GCPNFSStorageDriverName:
+		return config.GCPNFS
+	case driver == drivers.GenericNFSStorageDriverName:
+		return config.GenericNFS
 	default:
 		return config.UnknownVolumeType
 	}
@@ -808,14 +1551,14 @@ func (o *TridentOrchestrator) ListVolumes() []*storage.VolumeExternal {
 // not construct a transaction, nor does it take locks; it assumes that the
 // caller will take care of both of these.  It also assumes that the volume
 // exists in memory.
-func (o *TridentOrchestrator) deleteVolume(volumeName string) error {
+func (o *TridentOrchestrator) deleteVolume(ctx context.Context, volumeName string) error {
 	volume := o.volumes[volumeName]
 	volumeBackend := o.backends[volume.Backend]
 
 	// Note that this call will only return an error if the backend actually
 	// fails to delete the volume.  If the volume does not exist on the backend,
 	// the nDVP will not return an error.  Thus, we're fine.
-	if err := volumeBackend.RemoveVolume(volume); err != nil {
+	if err := volumeBackend.RemoveVolume(ctx, volume); err != nil {
 		log.WithFields(log.Fields{
 			"volume":  volumeName,
 			"backend": volume.Backend,
@@ -846,6 +1589,7 @@ func (o *TridentOrchestrator) deleteVolume(volumeName string) error {
 		volumeBackend.Terminate()
 		delete(o.backends, volume.Backend)
 	}
+	o.deleteVolumeNameMapping(volume)
 	delete(o.volumes, volumeName)
 	return nil
 }
@@ -857,7 +1601,7 @@ func (o *TridentOrchestrator) deleteVolume(volumeName string) error {
 // successfully, ensuring that the deletion will complete either upon retrying
 // the delete or upon reboot of Trident.
 // Returns true if the volume is found and false otherwise.
-func (o *TridentOrchestrator) DeleteVolume(volumeName string) (found bool, err error) {
+func (o *TridentOrchestrator) DeleteVolume(ctx context.Context, volumeName string) (found bool, err error) {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -865,15 +1609,25 @@ func (o *TridentOrchestrator) DeleteVolume(volumeName string) (found bool, err e
 	if !ok {
 		return false, fmt.Errorf("volume %s not found", volumeName)
 	}
+	if o.volumeMigrating(volumeName) {
+		return true, fmt.Errorf("volume %s is being migrated", volumeName)
+	}
+	if err = o.checkNoCaches(volumeName); err != nil {
+		return true, err
+	}
+
+	ctx, done := o.startOperation(ctx, operationDelete, volumeName)
+	defer func() { done(volume.Backend, err) }()
 
 	volTxn := &persistentstore.VolumeTransaction{
-		Config: volume.Config,
-		Op:     persistentstore.DeleteVolume,
+		Config:    volume.Config,
+		Op:        persistentstore.DeleteVolume,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
 	}
 	if err = o.storeClient.AddVolumeTransaction(volTxn); err != nil {
 		return true, err
 	}
-	if err = o.deleteVolume(volumeName); err != nil {
+	if err = o.deleteVolume(ctx, volumeName); err != nil {
 		// Do not try to delete the volume transaction here; instead, if we
 		// fail, leave the transaction around and let the deletion be attempted
 		// again.
@@ -887,7 +1641,9 @@ func (o *TridentOrchestrator) DeleteVolume(volumeName string) (found bool, err e
 			"finalize.")
 		// Reinsert the volume so that it can be deleted again
 		o.volumes[volumeName] = volume
+		o.addVolumeNameMapping(volume)
 	}
+	o.removeVolumeFromGroups(volumeName)
 	return true, nil
 }
 
@@ -901,6 +1657,9 @@ func (o *TridentOrchestrator) ListVolumesByPlugin(pluginName string) []*storage.
 			continue
 		}
 		for _, vol := range backend.Volumes {
+			if vol.Config.WarmPool {
+				continue
+			}
 			volumes = append(volumes, vol.ConstructExternal())
 		}
 	}
@@ -921,6 +1680,16 @@ func (o *TridentOrchestrator) AttachVolume(volumeName, mountpoint string, option
 
 	log.WithFields(log.Fields{"volume": volumeName, "mountpoint": mountpoint}).Debug("Mounting volume.")
 
+	// Check if volume is already mounted
+	mounted, err := utils.IsMounted(mountpoint)
+	if err != nil {
+		return fmt.Errorf("error checking if %v is already mounted: %v", mountpoint, err)
+	}
+	if mounted {
+		log.Debugf("%v is already mounted", mountpoint)
+		return nil
+	}
+
 	// Ensure mount point exists and is a directory
 	fileInfo, err := os.Lstat(mountpoint)
 	if os.IsNotExist(err) {
@@ -934,20 +1703,7 @@ func (o *TridentOrchestrator) AttachVolume(volumeName, mountpoint string, option
 		return fmt.Errorf("%v already exists and it's not a directory", mountpoint)
 	}
 
-	// Check if volume is already mounted
-	dfOutput, dfOuputErr := utils.GetDFOutput()
-	if dfOuputErr != nil {
-		err = fmt.Errorf("error checking if %v is already mounted: %v", mountpoint, dfOuputErr)
-		return err
-	}
-	for _, e := range dfOutput {
-		if e.Target == mountpoint {
-			log.Debugf("%v is already mounted", mountpoint)
-			return nil
-		}
-	}
-
-	return o.backends[volume.Backend].Driver.Attach(volume.Config.InternalName, mountpoint,
+	return o.backends[volume.Backend].Guarded().Attach(volume.Config.InternalName, mountpoint,
 		options)
 }
 
@@ -971,7 +1727,7 @@ func (o *TridentOrchestrator) DetachVolume(volumeName, mountpoint string) error
 	}
 
 	// Unmount the volume
-	err = o.backends[volume.Backend].Driver.Detach(volume.Config.InternalName,
+	err = o.backends[volume.Backend].Guarded().Detach(volume.Config.InternalName,
 		mountpoint)
 	if err != nil {
 		return err
@@ -982,6 +1738,40 @@ func (o *TridentOrchestrator) DetachVolume(volumeName, mountpoint string) error
 	return nil
 }
 
+// ForceDetachVolume detaches a volume from the local host through the frontend that mounts volumes
+// here, even if the frontend believes the volume is still in use, and returns the IDs of the mounts
+// that were released.  It is meant for mounts left behind, such as by a lost unmount request.
+func (o *TridentOrchestrator) ForceDetachVolume(volumeName string) ([]string, error) {
+
+	// The frontend calls back into the orchestrator, so the lock is only held to look it up
+	o.mutex.Lock()
+	_, ok := o.volumes[volumeName]
+	var mounter frontend.VolumeMounter
+	for _, f := range o.frontends {
+		if m, isMounter := f.(frontend.VolumeMounter); isMounter {
+			mounter = m
+		}
+	}
+	o.mutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if mounter == nil {
+		return nil, drivers.NewUnsupportedError("no frontend mounts volumes on this host")
+	}
+
+	released, err := mounter.ForceDetachVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"volume":         volumeName,
+		"releasedMounts": strings.Join(released, ","),
+	}).Warn("Forced volume to be detached.")
+	return released, nil
+}
+
 func (o *TridentOrchestrator) ListVolumeSnapshots(volumeName string) ([]*storage.SnapshotExternal, error) {
 
 	volume, ok := o.volumes[volumeName]
@@ -989,7 +1779,7 @@ func (o *TridentOrchestrator) ListVolumeSnapshots(volumeName string) ([]*storage
 		return nil, fmt.Errorf("volume %s not found", volumeName)
 	}
 
-	snapshots, err := o.backends[volume.Backend].Driver.SnapshotList(volume.Config.InternalName)
+	snapshots, err := o.backends[volume.Backend].Guarded().SnapshotList(volume.Config.InternalName)
 	if err != nil {
 		return nil, err
 	}
@@ -1017,6 +1807,7 @@ func (o *TridentOrchestrator) ReloadVolumes() error {
 	for k, v := range o.volumes {
 		tempVolumes[k] = v
 	}
+	tempWarmVolumes := o.warmVolumes
 
 	// Clear out cached volumes in the backends
 	for _, backend := range o.backends {
@@ -1025,6 +1816,7 @@ func (o *TridentOrchestrator) ReloadVolumes() error {
 
 	// Re-run the volume bootstrapping code
 	o.volumes = make(map[string]*storage.Volume)
+	o.warmVolumes = make(map[string]*storage.Volume)
 	err := o.bootstrapVolumes()
 
 	// If anything went wrong, reinstate the original volumes
@@ -1032,11 +1824,260 @@ func (o *TridentOrchestrator) ReloadVolumes() error {
 		log.Errorf("Volume reload failed, restoring original volume list: %v", err)
 		o.backends = tempBackends
 		o.volumes = tempVolumes
+		o.warmVolumes = tempWarmVolumes
 	}
 
 	return err
 }
 
+// ReconcileBackends compares the volumes Trident knows about with the objects present on each
+// online backend, reporting orphans and ghosts.  If cleanup is true, orphans are deleted, but only
+// those the storage records as created by the same backend more than orphanMinAge ago, so that
+// volumes still being created, and those of other Trident instances sharing the storage, are
+// kept.  Cleanup is refused with a passthrough store, which is how several hosts commonly share
+// the same storage.  Ghosts are only ever reported, since a missing volume may reflect a
+// misconfigured backend rather than lost data.  The backends are read without holding up other
+// operations, and each orphan is checked again, with provisioning locked out, before it is deleted.
+func (o *TridentOrchestrator) ReconcileBackends(cleanup bool) (*storage.ReconciliationReport, error) {
+
+	if cleanup && o.storeClient.GetType() == persistentstore.PassthroughStore {
+		return nil, errors.New("orphans can't be cleaned up with a passthrough store, as other " +
+			"Trident instances may be using the same storage")
+	}
+
+	// Note the volumes Trident knows about, so the backends can be read without the lock
+	o.mutex.Lock()
+	backends := make([]*storage.Backend, 0, len(o.backends))
+	knownVolumes := make(map[string]map[string]string)
+	for _, backend := range o.backends {
+		if !backend.Online {
+			continue
+		}
+		backends = append(backends, backend)
+		knownVolumes[backend.Name] = make(map[string]string, len(backend.Volumes))
+		for _, vol := range backend.Volumes {
+			knownVolumes[backend.Name][vol.Config.InternalName] = vol.Config.Name
+		}
+	}
+	// Originals kept after a migration cut over aren't orphans
+	for _, m := range o.migrations {
+		if known, ok := knownVolumes[m.source.Name]; ok && m.state.SourceRetained {
+			known[m.state.SourceInternalName] = m.state.Volume
+		}
+	}
+	o.mutex.Unlock()
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+
+	report := &storage.ReconciliationReport{
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		Cleanup:  cleanup,
+		Backends: make([]*storage.BackendReconciliation, 0, len(backends)),
+	}
+	for _, backend := range backends {
+		report.Backends = append(report.Backends,
+			o.reconcileBackend(backend, knownVolumes[backend.Name], cleanup))
+	}
+
+	o.mutex.Lock()
+	o.reconciliationReport = report
+	o.mutex.Unlock()
+	return report, nil
+}
+
+// GetReconciliationReport returns the report from the most recent reconciliation, or nil if
+// none has run.
+func (o *TridentOrchestrator) GetReconciliationReport() *storage.ReconciliationReport {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.reconciliationReport
+}
+
+const reconcileTask = "reconcile-backends"
+
+// orphanMinAge is how long ago an orphan must have been created for cleanup to delete it, so that
+// a volume whose creation hasn't yet been recorded is never mistaken for a leftover.
+const orphanMinAge = time.Hour
+
+// StartReconciler reconciles the backends periodically for as long as Trident runs.  A
+// non-positive interval disables periodic reconciliation.
+func (o *TridentOrchestrator) StartReconciler(interval time.Duration, cleanup bool) {
+	if interval <= 0 {
+		return
+	}
+	log.WithFields(log.Fields{
+		"interval": interval,
+		"cleanup":  cleanup,
+	}).Info("Starting periodic backend reconciliation.")
+
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:         reconcileTask,
+		Interval:     interval,
+		InitialDelay: interval,
+		Run: func() {
+			if _, err := o.ReconcileBackends(cleanup); err != nil {
+				log.Errorf("Could not reconcile backends. %v", err)
+			}
+		},
+	}); err != nil {
+		log.Errorf("Could not start periodic backend reconciliation. %v", err)
+	}
+}
+
+// reconcileBackend compares the volumes on a backend with those Trident knew about on it, given
+// as the volume names by internal name, and deletes the orphans it may if cleanup is true.
+func (o *TridentOrchestrator) reconcileBackend(
+	backend *storage.Backend, knownVolumes map[string]string, cleanup bool,
+) *storage.BackendReconciliation {
+
+	result := &storage.BackendReconciliation{
+		Backend: backend.Name,
+		Orphans: make([]string, 0),
+		Ghosts:  make([]string, 0),
+	}
+
+	known := make(map[string]bool, len(knownVolumes))
+	for internalName := range knownVolumes {
+		known[internalName] = true
+	}
+
+	// Find the volumes present on the backend
+	foundVolumes := make(map[string]bool)
+	orphanVolumes := make([]string, 0)
+	channel := make(chan *storage.VolumeExternalWrapper)
+	go backend.GetVolumeExternalWrappers(channel)
+	for wrapper := range channel {
+		if wrapper.Error != nil {
+			result.Errors = append(result.Errors, wrapper.Error.Error())
+			continue
+		}
+		internalName := wrapper.Volume.Config.InternalName
+		foundVolumes[internalName] = true
+		if !known[internalName] {
+			orphanVolumes = append(orphanVolumes, internalName)
+		}
+	}
+
+	// If the backend couldn't be listed, every known volume would look like a ghost
+	if len(result.Errors) > 0 {
+		return result
+	}
+
+	ghosts := make([]string, 0)
+	for internalName := range knownVolumes {
+		if !foundVolumes[internalName] {
+			ghosts = append(ghosts, internalName)
+		}
+	}
+
+	// Let the driver find any other leftovers, such as snapshots
+	orphanObjects := make([]string, 0)
+	_, isDetector := backend.Driver.(storage.OrphanDetector)
+	if isDetector {
+		objects, err := backend.Guarded().ListOrphanedObjects(known)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			orphanObjects = objects
+		}
+	}
+
+	sort.Strings(orphanVolumes)
+	sort.Strings(orphanObjects)
+	result.Orphans = append(append(result.Orphans, orphanVolumes...), orphanObjects...)
+
+	if cleanup {
+		// Delete volumes first, as they may be what holds other objects in place.  Snapshots
+		// Trident created only for an operation hold no data of their own, so only other orphans
+		// must be shown to be the backend's own.
+		now := time.Now()
+		for _, orphan := range result.Orphans {
+			isSnapshot := strings.HasPrefix(orphan, storage.OrphanedSnapshotPrefix)
+			if !isSnapshot {
+				if reason := orphanOwnership(backend, orphan, now); reason != "" {
+					log.WithFields(log.Fields{
+						"backend": backend.Name,
+						"orphan":  orphan,
+					}).Infof("Keeping orphan, as %s.", reason)
+					result.Kept = append(result.Kept, orphan)
+					continue
+				}
+			}
+			if err := o.deleteOrphan(backend, orphan, isSnapshot || !foundVolumes[orphan]); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("could not delete %s: %v", orphan, err))
+			} else {
+				result.Cleaned = append(result.Cleaned, orphan)
+			}
+		}
+	}
+
+	// Volumes deleted since the backend was read are gone from Trident too, so aren't ghosts
+	o.mutex.Lock()
+	for _, internalName := range ghosts {
+		if vol, ok := backend.Volumes[knownVolumes[internalName]]; ok && vol.Config.InternalName == internalName {
+			result.Ghosts = append(result.Ghosts, knownVolumes[internalName])
+		}
+	}
+	o.mutex.Unlock()
+	sort.Strings(result.Ghosts)
+
+	logFields := log.Fields{
+		"backend": backend.Name,
+		"orphans": len(result.Orphans),
+		"ghosts":  len(result.Ghosts),
+		"cleaned": len(result.Cleaned),
+		"kept":    len(result.Kept),
+		"errors":  len(result.Errors),
+	}
+	if len(result.Orphans) > 0 || len(result.Ghosts) > 0 || len(result.Errors) > 0 {
+		log.WithFields(logFields).Warn("Backend reconciliation found discrepancies.")
+	} else {
+		log.WithFields(logFields).Debug("Backend reconciliation found no discrepancies.")
+	}
+
+	return result
+}
+
+// orphanOwnership returns why an orphan mustn't be deleted, or an empty string if the storage
+// records that the backend created it more than orphanMinAge ago.
+func orphanOwnership(backend *storage.Backend, orphan string, now time.Time) string {
+	if _, ok := backend.Driver.(storage.OwnershipDriver); !ok {
+		return "the backend doesn't record which volumes it created"
+	}
+	owner, created, err := backend.Guarded().GetOwnership(orphan)
+	if err != nil {
+		return fmt.Sprintf("its owner couldn't be read: %v", err)
+	}
+	if owner == "" || owner != backend.BackendUUID {
+		return "it wasn't created by this backend"
+	}
+	if now.Sub(created) < orphanMinAge {
+		return "it was created too recently"
+	}
+	return ""
+}
+
+// deleteOrphan deletes an orphaned volume, or another object if isObject is true, once it has
+// checked, with provisioning locked out, that the backend is unchanged and the orphan still
+// unknown to Trident.
+func (o *TridentOrchestrator) deleteOrphan(backend *storage.Backend, orphan string, isObject bool) error {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if current, ok := o.backends[backend.Name]; !ok || current != backend || !backend.Online {
+		return fmt.Errorf("backend %s changed during reconciliation", backend.Name)
+	}
+	for _, vol := range backend.Volumes {
+		if vol.Config.InternalName == orphan {
+			return fmt.Errorf("volume %s was added during reconciliation", vol.Config.Name)
+		}
+	}
+	if isObject {
+		return backend.Guarded().DeleteOrphanedObject(orphan)
+	}
+	return backend.Guarded().Destroy(context.Background(), orphan)
+}
+
 // getProtocol returns the appropriate protocol name based on volume access mode
 // or an empty string if all protocols are applicable.
 // ReadWriteOnce -> Any (File + Block)
@@ -1058,10 +2099,13 @@ func (o *TridentOrchestrator) getProtocol(mode config.AccessMode) config.Protoco
 func (o *TridentOrchestrator) AddStorageClass(scConfig *storageclass.Config) (*storageclass.External, error) {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
-	sc := storageclass.New(scConfig)
-	if _, ok := o.storageClasses[sc.GetName()]; ok {
-		return nil, fmt.Errorf("storage class %s already exists", sc.GetName())
+	if _, ok := o.storageClasses[scConfig.Name]; ok {
+		return nil, fmt.Errorf("storage class %s already exists", scConfig.Name)
+	}
+	if err := o.validateStorageClass(scConfig); err != nil {
+		return nil, err
 	}
+	sc := storageclass.New(scConfig)
 	err := o.storeClient.AddStorageClass(sc)
 	if err != nil {
 		return nil, err
@@ -1080,19 +2124,84 @@ func (o *TridentOrchestrator) AddStorageClass(scConfig *storageclass.Config) (*s
 			"storageClass": sc.GetName(),
 		}).Infof("Storage class satisfied by %d storage pools.", added)
 	}
-	return sc.ConstructExternal(), nil
+
+	external := o.storageClassExternal(sc)
+	if len(external.Validation.UnsatisfiedAttributes) > 0 {
+		log.WithFields(log.Fields{
+			"storageClass": sc.GetName(),
+			"attributes":   external.Validation.UnsatisfiedAttributes,
+		}).Warn("No storage pool offers some of the storage class's attributes; " +
+			"volumes of this class can't be created until a backend that offers them is added.")
+	}
+	return external, nil
+}
+
+// checkStorageClassAccess returns a fatal error if the volume was requested from a namespace its
+// storage class doesn't allow, so that frontends don't retry the request until it changes.
+func checkStorageClassAccess(volumeConfig *storage.VolumeConfig, sc *storageclass.StorageClass) error {
+	if sc.AllowsNamespace(volumeConfig.Namespace) {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"volume":            volumeConfig.Name,
+		"namespace":         volumeConfig.Namespace,
+		"storageClass":      sc.GetName(),
+		"allowedNamespaces": sc.GetAllowedNamespaces(),
+	}).Warn("Volume requested from a namespace that may not use its storage class.")
+	return drivers.NewFatalError(fmt.Sprintf("storage class %s may not be used from namespace %s",
+		sc.GetName(), volumeConfig.Namespace))
+}
+
+// applyStorageClassDefaultSize gives a volume requested without a size the default size of its
+// storage class, if the class has one.  Otherwise the backend's default size applies.
+func applyStorageClassDefaultSize(volumeConfig *storage.VolumeConfig, sc *storageclass.StorageClass) error {
+	defaultSize := sc.GetDefaultSize()
+	if defaultSize == "" {
+		return nil
+	}
+	if volumeConfig.Size != "" {
+		requestedSize, err := utils.ConvertSizeToBytes(volumeConfig.Size)
+		if err != nil {
+			return drivers.NewFatalError(fmt.Sprintf("could not convert volume size %s: %v",
+				volumeConfig.Size, err))
+		}
+		if requestedSize != "0" {
+			return nil
+		}
+	}
+	sizeBytes, err := utils.ConvertSizeToBytes(defaultSize)
+	if err != nil {
+		return fmt.Errorf("invalid defaultSize for storage class %s: %v", sc.GetName(), err)
+	}
+	log.WithFields(log.Fields{
+		"volume":       volumeConfig.Name,
+		"storageClass": sc.GetName(),
+		"size":         sizeBytes,
+	}).Debug("Using the storage class's default size.")
+	volumeConfig.Size = sizeBytes
+	return nil
+}
+
+// storageClassExternal returns the external form of a storage class, along with its validation
+// against the current backends and the number of volumes in its warm pool.
+func (o *TridentOrchestrator) storageClassExternal(sc *storageclass.StorageClass) *storageclass.External {
+	external := sc.ConstructExternal()
+	external.Validation = sc.Validate(o.backends)
+	external.WarmVolumes = o.countWarmVolumes(sc.GetName())
+	return external
 }
 
+// GetStorageClass returns the storage class with the name or alias, or nil if there is none.
 func (o *TridentOrchestrator) GetStorageClass(scName string) *storageclass.External {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
-	sc, ok := o.storageClasses[scName]
-	if !ok {
+	sc := o.resolveStorageClass(scName)
+	if sc == nil {
 		return nil
 	}
 	// Storage classes aren't threadsafe (we modify them during runtime),
 	// so return a copy, rather than the original
-	return sc.ConstructExternal()
+	return o.storageClassExternal(sc)
 }
 
 func (o *TridentOrchestrator) ListStorageClasses() []*storageclass.External {
@@ -1100,7 +2209,7 @@ func (o *TridentOrchestrator) ListStorageClasses() []*storageclass.External {
 	defer o.mutex.Unlock()
 	ret := make([]*storageclass.External, 0, len(o.storageClasses))
 	for _, sc := range o.storageClasses {
-		ret = append(ret, sc.ConstructExternal())
+		ret = append(ret, o.storageClassExternal(sc))
 	}
 	return ret
 }
@@ -1126,6 +2235,40 @@ func (o *TridentOrchestrator) DeleteStorageClass(scName string) (bool, error) {
 	return found, nil
 }
 
+// renameBackendOnPersistentStore stores a backend under its new name and rebinds its volumes to
+// that name.  The new record is added before the old one is deleted, so that a restart in between
+// finds the backend under at least one name.  The in-memory volumes are only changed once all of
+// them are stored.
+func (o *TridentOrchestrator) renameBackendOnPersistentStore(
+	originalBackend, renamedBackend *storage.Backend,
+) error {
+	if !o.bootstrapped && !config.UsingPassthroughStore {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"backend":     originalBackend.Name,
+		"newName":     renamedBackend.Name,
+		"backendUUID": renamedBackend.BackendUUID,
+	}).Info("Renaming an existing backend.")
+
+	if err := o.storeClient.AddBackend(renamedBackend); err != nil {
+		return err
+	}
+	for _, vol := range originalBackend.Volumes {
+		renamedVol := *vol
+		renamedVol.Backend = renamedBackend.Name
+		renamedVol.BackendUUID = renamedBackend.BackendUUID
+		if err := o.storeClient.UpdateVolume(&renamedVol); err != nil {
+			return err
+		}
+	}
+	for _, vol := range originalBackend.Volumes {
+		vol.Backend = renamedBackend.Name
+		vol.BackendUUID = renamedBackend.BackendUUID
+	}
+	return o.storeClient.DeleteBackend(originalBackend)
+}
+
 func (o *TridentOrchestrator) updateBackendOnPersistentStore(
 	backend *storage.Backend, newBackend bool,
 ) error {
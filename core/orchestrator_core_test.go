@@ -3,12 +3,14 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -19,6 +21,7 @@ import (
 	sa "github.com/netapp/trident/storage_attribute"
 	"github.com/netapp/trident/storage_class"
 	tu "github.com/netapp/trident/storage_class/test_utils"
+	drivers "github.com/netapp/trident/storage_drivers"
 	fakedriver "github.com/netapp/trident/storage_drivers/fake"
 )
 
@@ -232,7 +235,7 @@ func runDeleteTest(
 		}
 		orchestrator.mutex.Unlock()
 	}
-	found, err := orchestrator.DeleteVolume(d.name)
+	found, err := orchestrator.DeleteVolume(context.Background(), d.name)
 	if err == nil && !d.expectedSuccess {
 		t.Errorf("%s:  volume delete succeeded when it should not have.",
 			d.name)
@@ -744,7 +747,7 @@ func TestAddStorageClassVolumes(t *testing.T) {
 			deleteAfterSC: false,
 		},
 	} {
-		vol, err := orchestrator.AddVolume(s.config)
+		vol, err := orchestrator.AddVolume(context.Background(), s.config)
 		if err != nil && s.expectedSuccess {
 			t.Errorf("%s:  got unexpected error %v", s.name, err)
 			continue
@@ -1087,7 +1090,7 @@ func TestCloneVolumes(t *testing.T) {
 		},
 	} {
 		// Create the source volume
-		_, err := orchestrator.AddVolume(s.config)
+		_, err := orchestrator.AddVolume(context.Background(), s.config)
 		if err != nil {
 			t.Errorf("%s:  got unexpected error %v", s.name, err)
 			continue
@@ -1100,7 +1103,7 @@ func TestCloneVolumes(t *testing.T) {
 			StorageClass:      s.config.StorageClass,
 			CloneSourceVolume: s.config.Name,
 		}
-		cloneResult, err := orchestrator.CloneVolume(cloneConfig)
+		cloneResult, err := orchestrator.CloneVolume(context.Background(), cloneConfig)
 		if err != nil {
 			t.Errorf("%s:  got unexpected error %v", s.name, err)
 			continue
@@ -1219,7 +1222,7 @@ func TestBackendUpdateAndDelete(t *testing.T) {
 	}
 	orchestrator.mutex.Unlock()
 
-	_, err := orchestrator.AddVolume(generateVolumeConfig(volumeName, 50, scName,
+	_, err := orchestrator.AddVolume(context.Background(), generateVolumeConfig(volumeName, 50, scName,
 		config.File))
 	if err != nil {
 		t.Fatal("Unable to create volume: ", err)
@@ -1372,7 +1375,7 @@ func TestBackendUpdateAndDelete(t *testing.T) {
 	if !backend.Driver.Initialized() {
 		t.Errorf("Offlined backend with volumes %s is not initialized.", backendName)
 	}
-	_, err = orchestrator.AddVolume(generateVolumeConfig(offlineVolumeName, 50,
+	_, err = orchestrator.AddVolume(context.Background(), generateVolumeConfig(offlineVolumeName, 50,
 		scName, config.File))
 	if err == nil {
 		t.Error("Created volume volume on offline backend.")
@@ -1446,7 +1449,7 @@ func TestBackendUpdateAndDelete(t *testing.T) {
 	newOrchestrator.mutex.Unlock()
 
 	// Test that deleting the volume causes the backend to be deleted.
-	_, err = orchestrator.DeleteVolume(volumeName)
+	_, err = orchestrator.DeleteVolume(context.Background(), volumeName)
 	if err != nil {
 		t.Fatal("Unable to delete volume for offline backend:  ", err)
 	}
@@ -1510,7 +1513,7 @@ func TestBackendCleanup(t *testing.T) {
 
 	orchestrator := getOrchestrator()
 	addBackendStorageClass(t, orchestrator, offlineBackendName, scName)
-	_, err := orchestrator.AddVolume(generateVolumeConfig(volumeName, 50,
+	_, err := orchestrator.AddVolume(context.Background(), generateVolumeConfig(volumeName, 50,
 		scName, config.File))
 	if err != nil {
 		t.Fatal("Unable to create volume: ", err)
@@ -1704,7 +1707,7 @@ func TestAddVolumeRecovery(t *testing.T) {
 	// afterwards
 	fullVolumeConfig := generateVolumeConfig(fullVolumeName, 50, scName,
 		config.File)
-	_, err := orchestrator.AddVolume(fullVolumeConfig)
+	_, err := orchestrator.AddVolume(context.Background(), fullVolumeConfig)
 	if err != nil {
 		t.Fatal("Unable to add volume: ", err)
 	}
@@ -1731,17 +1734,17 @@ func TestDeleteVolumeRecovery(t *testing.T) {
 	// For the full test, we delete everything but the ending transaction.
 	fullVolumeConfig := generateVolumeConfig(fullVolumeName, 50, scName,
 		config.File)
-	_, err := orchestrator.AddVolume(fullVolumeConfig)
+	_, err := orchestrator.AddVolume(context.Background(), fullVolumeConfig)
 	if err != nil {
 		t.Fatal("Unable to add volume: ", err)
 	}
-	_, err = orchestrator.DeleteVolume(fullVolumeName)
+	_, err = orchestrator.DeleteVolume(context.Background(), fullVolumeName)
 	if err != nil {
 		t.Fatal("Unable to remove full volume:  ", err)
 	}
 	txOnlyVolumeConfig := generateVolumeConfig(txOnlyVolumeName, 50, scName,
 		config.File)
-	_, err = orchestrator.AddVolume(txOnlyVolumeConfig)
+	_, err = orchestrator.AddVolume(context.Background(), txOnlyVolumeConfig)
 	if err != nil {
 		t.Fatal("Unable to add tx only volume: ", err)
 	}
@@ -1756,160 +1759,2291 @@ func TestDeleteVolumeRecovery(t *testing.T) {
 	cleanup(t, orchestrator)
 }
 
-func TestBadBootstrapEtcdV2(t *testing.T) {
-	if *etcdV2 == "" {
-		t.SkipNow()
-	}
-	_, err := persistentstore.NewEtcdClientV2("invalidIPAddress")
-	if err != nil && persistentstore.MatchUnavailableClusterErr(err) {
-	} else {
-		panic(fmt.Errorf("didn't catch invalid etcdv2 client"))
+func TestJournalRecovery(t *testing.T) {
+	const (
+		backendName = "journalRecoveryBackend"
+		scName      = "journalRecoveryBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	// The fake driver doesn't journal, so its entry should be discarded, while the entry for a
+	// missing backend should be left for a later bootstrap.
+	fakeEntry := drivers.NewJournalEntry("clone", "journalRecoveryVolume", nil)
+	fakeEntry.Backend = backendName
+	missingEntry := drivers.NewJournalEntry("clone", "journalRecoveryVolume", nil)
+	missingEntry.Backend = "journalRecoveryMissingBackend"
+	for _, entry := range []*drivers.JournalEntry{fakeEntry, missingEntry} {
+		if err := orchestrator.storeClient.AddJournalEntry(entry); err != nil {
+			t.Fatal("Unable to add journal entry: ", err)
+		}
 	}
-}
 
-func TestBadBootstrapEtcdV3(t *testing.T) {
-	if *etcdV3 == "" {
-		t.SkipNow()
+	newOrchestrator := getOrchestrator()
+	entries, err := newOrchestrator.storeClient.GetJournalEntries()
+	if err != nil {
+		t.Fatal("Unable to get journal entries: ", err)
 	}
-	_, err := persistentstore.NewEtcdClientV3("invalidIPAddress")
-	if err != nil && persistentstore.MatchUnavailableClusterErr(err) {
-	} else {
-		panic(fmt.Errorf("didn't catch invalid etcdv3 client"))
+	if len(entries) != 1 || entries[0].Key() != missingEntry.Key() {
+		t.Errorf("Expected only the journal entry for the missing backend to remain, got %v.", entries)
 	}
+
+	if err = newOrchestrator.storeClient.DeleteJournalEntry(missingEntry); err != nil {
+		t.Error("Unable to delete journal entry: ", err)
+	}
+	cleanup(t, newOrchestrator)
 }
 
-func TestBootstrapEtcdV2ToEtcdV3Migration(t *testing.T) {
+func TestCleanUpFailedOperations(t *testing.T) {
 	const (
-		backendName = "bootstrapV2toV3_backend"
-		scName      = "bootstrapV2toV3_class"
-		volumeName  = "bootstrapV2toV3_vol"
+		backendName = "cleanupBackend"
+		scName      = "cleanupBackendSC"
+		volumeName  = "cleanupVolumePending"
 	)
-	if *etcdV3 == "" {
-		t.SkipNow()
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	// Leave a half-created volume behind, as a create that failed to clean up after itself would
+	volumeConfig := generateVolumeConfig(volumeName, 1, scName, config.File)
+	if _, err := orchestrator.AddVolume(context.Background(), volumeConfig); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	vol := orchestrator.volumes[volumeName]
+	orchestrator.deleteVolumeNameMapping(vol)
+	delete(orchestrator.volumes, volumeName)
+	delete(orchestrator.backends[backendName].Volumes, volumeName)
+	if err := orchestrator.storeClient.DeleteVolume(vol); err != nil {
+		t.Fatal("Unable to delete volume from the store: ", err)
+	}
+	err := orchestrator.storeClient.AddVolumeTransaction(&persistentstore.VolumeTransaction{
+		Config:    volumeConfig,
+		Op:        persistentstore.AddVolume,
+		StartTime: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal("Unable to add volume transaction: ", err)
 	}
-	cleanupStoreVersion(t, *etcdV3)
 
-	// Populate the etcdv2 cluster with one backend, one storage class, and one volume
-	etcdV2Orig := etcdV2
-	etcdV2 = etcdV3
-	orchestratorV2 := getOrchestrator()
-	addBackendStorageClass(t, orchestratorV2, backendName, scName)
+	// A transaction of an operation that may still be running is left alone
+	recentConfig := generateVolumeConfig("cleanupVolumeRunning", 1, scName, config.File)
+	recentTxn := &persistentstore.VolumeTransaction{
+		Config:    recentConfig,
+		Op:        persistentstore.AddVolume,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err = orchestrator.storeClient.AddVolumeTransaction(recentTxn); err != nil {
+		t.Fatal("Unable to add volume transaction: ", err)
+	}
 
-	orchestratorV2.mutex.Lock()
-	if _, ok := orchestratorV2.storageClasses[scName]; !ok {
-		t.Fatal("Storage class not found in the orchestrator map!")
+	staleEntry := drivers.NewJournalEntry("clone", "cleanupVolumeStale", nil)
+	staleEntry.Backend = backendName
+	staleEntry.StartTime = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	recentEntry := drivers.NewJournalEntry("clone", "cleanupVolumeRecent", nil)
+	recentEntry.Backend = backendName
+	for _, entry := range []*drivers.JournalEntry{staleEntry, recentEntry} {
+		if err = orchestrator.storeClient.AddJournalEntry(entry); err != nil {
+			t.Fatal("Unable to add journal entry: ", err)
+		}
 	}
-	orchestratorV2.mutex.Unlock()
 
-	v2Volume, err := orchestratorV2.AddVolume(generateVolumeConfig(volumeName, 50, scName,
-		config.File))
+	orchestrator.cleanUpFailedOperations(time.Hour)
+
+	f := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	if !f.DestroyedVolumes[f.GetInternalVolumeName(volumeName)] {
+		t.Error("Expected the half-created volume to be destroyed.")
+	}
+	if txns, err := orchestrator.storeClient.GetVolumeTransactions(); err != nil {
+		t.Error("Unable to get volume transactions: ", err)
+	} else if len(txns) != 1 || txns[0].Config.Name != recentConfig.Name {
+		t.Errorf("Expected only the recent volume transaction to be kept, got %v.", txns)
+	}
+	if f.DestroyedVolumes[f.GetInternalVolumeName(recentConfig.Name)] {
+		t.Error("Expected the running operation's volume to be left alone.")
+	}
+	if err = orchestrator.storeClient.DeleteVolumeTransaction(recentTxn); err != nil {
+		t.Error("Unable to delete volume transaction: ", err)
+	}
+	entries, err := orchestrator.storeClient.GetJournalEntries()
 	if err != nil {
-		t.Fatal("Unable to create volume: ", err)
+		t.Fatal("Unable to get journal entries: ", err)
 	}
-	orchestratorV2.mutex.Lock()
-	if _, ok := orchestratorV2.volumes[volumeName]; !ok {
-		t.Fatal("Volume name found in orchestrator map!")
+	if len(entries) != 1 || entries[0].Key() != recentEntry.Key() {
+		t.Errorf("Expected only the recent journal entry to remain, got %v.", entries)
 	}
-	orchestratorV2.mutex.Unlock()
 
-	// Bootstrap etcdv3 orchestrator with etcdv2 data
-	etcdV2 = etcdV2Orig
-	orchestratorV3 := getOrchestrator()
+	if err = orchestrator.storeClient.DeleteJournalEntry(recentEntry); err != nil {
+		t.Error("Unable to delete journal entry: ", err)
+	}
+	cleanup(t, orchestrator)
+}
 
-	// Verify etcdv2 to etcdv3 transformation
-	if orchestratorV3.GetBackend(backendName) == nil {
-		t.Fatalf("Failed to find backend %s after bootstrapping!", backendName)
+func TestReconcileBackends(t *testing.T) {
+	const (
+		backendName     = "reconcileBackend"
+		scName          = "reconcileBackendSC"
+		knownVolumeName = "reconcileVolumeKnown"
+		ghostVolumeName = "reconcileVolumeGhost"
+		orphanName      = "reconcileVolumeOrphan"
+		unownedName     = "reconcileVolumeUnowned"
+		recentName      = "reconcileVolumeRecent"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	volumes := make(map[string]*storage.VolumeExternal)
+	for _, name := range []string{knownVolumeName, ghostVolumeName} {
+		vol, err := orchestrator.AddVolume(context.Background(),
+			generateVolumeConfig(name, 1, scName, config.File))
+		if err != nil {
+			t.Fatal("Unable to add volume: ", err)
+		}
+		volumes[name] = vol
 	}
-	//TODO: Optionally we can diff etcdv2 and etcdv3 backends here
-	if orchestratorV3.GetStorageClass(scName) == nil {
-		t.Fatalf("Failed to find storage class %s after bootstrapping!",
-			scName)
+
+	// Remove one volume behind Trident's back and leave another that Trident doesn't know about
+	f := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	delete(f.Volumes, volumes[ghostVolumeName].Config.InternalName)
+	backendUUID := orchestrator.backends[backendName].BackendUUID
+	created := time.Now().Add(-2 * orphanMinAge)
+	f.Volumes[orphanName] = fake.Volume{Name: orphanName, PoolName: "primary", SizeBytes: 1024,
+		Owner: backendUUID, Created: created}
+
+	// Neither a volume another instance created nor one just created by this backend may be cleaned up
+	f.Volumes[unownedName] = fake.Volume{Name: unownedName, PoolName: "primary", SizeBytes: 1024,
+		Owner: "another-backend", Created: created}
+	f.Volumes[recentName] = fake.Volume{Name: recentName, PoolName: "primary", SizeBytes: 1024,
+		Owner: backendUUID, Created: time.Now()}
+	orphans := []string{orphanName, recentName, unownedName}
+
+	findBackend := func(cleanup bool) *storage.BackendReconciliation {
+		report, err := orchestrator.ReconcileBackends(cleanup)
+		if err != nil {
+			t.Fatalf("Unable to reconcile backends: %v", err)
+		}
+		for _, backend := range report.Backends {
+			if backend.Backend == backendName {
+				return backend
+			}
+		}
+		t.Fatalf("Backend %s missing from reconciliation report.", backendName)
+		return nil
 	}
-	//TODO: Optionally we can diff etcdv2 and etcdv3 storage classes here
-	v3Volume := orchestratorV3.GetVolume(volumeName)
-	if v3Volume == nil {
-		t.Fatalf("Failed to find storage class %s after bootstrapping!",
-			volumeName)
+
+	result := findBackend(false)
+	if !reflect.DeepEqual(result.Orphans, orphans) {
+		t.Errorf("Expected orphans %v, got %v.", orphans, result.Orphans)
 	}
-	// Verifying the same volume exists on both clusters
-	if !reflect.DeepEqual(v2Volume, v3Volume) {
-		t.Fatalf("etcdv2 volume (%v) doesn't match the etcdv3 volume (%v)!",
-			v2Volume, v3Volume)
+	if !reflect.DeepEqual(result.Ghosts, []string{ghostVolumeName}) {
+		t.Errorf("Expected ghosts %v, got %v.", []string{ghostVolumeName}, result.Ghosts)
 	}
-	etcdv3Client, err := persistentstore.NewEtcdClientV3(*etcdV3)
+	if len(result.Cleaned) != 0 || f.DestroyedVolumes[orphanName] {
+		t.Error("Orphan was deleted without cleanup being requested.")
+	}
+	if orchestrator.GetReconciliationReport() == nil {
+		t.Error("Reconciliation report was not saved.")
+	}
+
+	result = findBackend(true)
+	if !reflect.DeepEqual(result.Cleaned, []string{orphanName}) || !f.DestroyedVolumes[orphanName] {
+		t.Errorf("Expected orphan %s to be cleaned up, got %v.", orphanName, result.Cleaned)
+	}
+	if kept := []string{recentName, unownedName}; !reflect.DeepEqual(result.Kept, kept) {
+		t.Errorf("Expected orphans %v to be kept, got %v.", kept, result.Kept)
+	}
+	if f.DestroyedVolumes[unownedName] || f.DestroyedVolumes[recentName] {
+		t.Error("An orphan this backend didn't create long enough ago was deleted.")
+	}
+	if _, ok := f.Volumes[volumes[knownVolumeName].Config.InternalName]; !ok {
+		t.Error("Known volume was deleted during cleanup.")
+	}
+
+	result = findBackend(false)
+	if kept := []string{recentName, unownedName}; !reflect.DeepEqual(result.Orphans, kept) {
+		t.Errorf("Expected orphans %v after cleanup, got %v.", kept, result.Orphans)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestCordonBackend(t *testing.T) {
+	const (
+		backendName = "cordonBackend"
+		scName      = "cordonBackendSC"
+		volumeName  = "cordonVolume"
+		cloneName   = "cordonVolumeClone"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	_, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File))
 	if err != nil {
-		t.Fatalf("Creating etcdv3 client failed: %v", err)
+		t.Fatal("Unable to add volume: ", err)
 	}
-	versionJSON, err := etcdv3Client.Read(config.StoreURL)
+
+	backend, err := orchestrator.CordonBackend(backendName, true)
 	if err != nil {
-		t.Fatalf("Couldn't determine the orchestrator persistent state version: %v",
-			err)
+		t.Fatal("Unable to cordon backend: ", err)
 	}
-	version := &persistentstore.PersistentStateVersion{}
-	err = json.Unmarshal([]byte(versionJSON), version)
+	if !backend.Cordoned {
+		t.Error("Backend was not reported as cordoned.")
+	}
+	if _, err = orchestrator.CordonBackend("cordonMissingBackend", true); err == nil {
+		t.Error("Expected an error cordoning a missing backend.")
+	}
+
+	// Neither new volumes nor clones may land on a cordoned backend
+	_, err = orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig("cordonVolumeNew", 1, scName, config.File))
+	if !drivers.IsRetryableError(err) {
+		t.Errorf("Expected a retryable error adding a volume to a cordoned backend, got %v.", err)
+	}
+	cloneConfig := generateVolumeConfig(cloneName, 1, scName, config.File)
+	cloneConfig.CloneSourceVolume = volumeName
+	if _, err = orchestrator.CloneVolume(context.Background(), cloneConfig); !drivers.IsRetryableError(err) {
+		t.Errorf("Expected a retryable error cloning on a cordoned backend, got %v.", err)
+	}
+
+	// The cordon must survive a restart
+	if backend := getOrchestrator().GetBackend(backendName); backend == nil || !backend.Cordoned {
+		t.Fatal("Backend cordon was not persisted.")
+	}
+
+	if _, err = orchestrator.CordonBackend(backendName, false); err != nil {
+		t.Fatal("Unable to uncordon backend: ", err)
+	}
+	if _, err = orchestrator.CloneVolume(context.Background(), cloneConfig); err != nil {
+		t.Error("Unable to clone volume on uncordoned backend: ", err)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestGetBackendWithCapacity(t *testing.T) {
+	const (
+		backendName = "capacityBackend"
+		scName      = "capacityBackendSC"
+		volumeName  = "capacityVolume"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	_, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File))
 	if err != nil {
-		t.Fatalf("Couldn't unmarshal the orchestrator persistent state version: %v", err)
+		t.Fatal("Unable to add volume: ", err)
 	}
-	if config.OrchestratorAPIVersion != version.OrchestratorAPIVersion ||
-		string(orchestratorV3.storeClient.GetType()) != version.PersistentStoreVersion {
-		t.Fatalf("Failed to set the orchestrator persistent state version after bootsrapping: %v",
-			err)
+
+	backend := orchestrator.GetBackendWithCapacity(backendName)
+	if backend == nil {
+		t.Fatal("Backend not found.")
+	}
+	pool := backend.Storage["primary"]
+	if pool.VolumeCount != 1 {
+		t.Errorf("Expected 1 volume in the pool, got %d.", pool.VolumeCount)
+	}
+	expected := storage.PoolCapacity{
+		TotalBytes:     100 * 1024 * 1024 * 1024,
+		UsedBytes:      1024 * 1024 * 1024,
+		AvailableBytes: 99 * 1024 * 1024 * 1024,
+	}
+	if pool.Capacity == nil || *pool.Capacity != expected {
+		t.Errorf("Expected capacity %+v, got %+v.", expected, pool.Capacity)
 	}
 
-	// cleanup
-	if err = etcdv3Client.Stop(); err != nil {
-		t.Fatalf("Couldn't shut down etcdv3 client: %v", err)
+	// Capacity is only gathered on request
+	if backend = orchestrator.GetBackend(backendName); backend.Storage["primary"].Capacity != nil {
+		t.Error("Expected no capacity without asking for it.")
 	}
-	cleanup(t, orchestratorV2)
-	cleanup(t, orchestratorV3)
-	cleanupStoreVersion(t, *etcdV3)
+	if orchestrator.GetBackendWithCapacity("capacityMissingBackend") != nil {
+		t.Error("Expected no result for a missing backend.")
+	}
+	cleanup(t, orchestrator)
 }
 
-// The next series of tests test that bootstrap doesn't exit early if it
-// encounters a key error for one of the main types of entries.
-func TestStorageClassOnlyBootstrap(t *testing.T) {
-	const scName = "storageclass-only"
+func TestGetBackendWithConfigDetail(t *testing.T) {
+	const (
+		backendName = "detailBackend"
+		scName      = "detailBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	backend, err := orchestrator.GetBackendWithConfigDetail(backendName)
+	if err != nil {
+		t.Fatal("Unable to get backend config detail: ", err)
+	}
+	detail, ok := backend.Config.(*drivers.FakeStorageDriverConfig)
+	if !ok {
+		t.Fatalf("Expected the fake driver's whole config, got %T.", backend.Config)
+	}
+	if detail.InstanceName != backendName || len(detail.Pools) == 0 {
+		t.Errorf("Expected the config of backend %s with its pools, got %+v.", backendName, detail)
+	}
+	if backend.Storage["primary"] == nil || backend.Storage["primary"].Capacity == nil {
+		t.Error("Expected the backend's pools with their capacity.")
+	}
+
+	if _, err = orchestrator.GetBackendWithConfigDetail("detailMissingBackend"); err == nil {
+		t.Error("Expected an error for a missing backend.")
+	}
+	cleanup(t, orchestrator)
+}
 
+func TestAddStorageClassValidation(t *testing.T) {
+	const (
+		backendName = "validationBackend"
+		scName      = "validationBackendSC"
+	)
 	orchestrator := getOrchestrator()
-	originalSC, err := orchestrator.AddStorageClass(
-		&storageclass.Config{
-			Name: scName,
-			Attributes: map[string]sa.Request{
-				sa.Media:            sa.NewStringRequest("hdd"),
-				sa.ProvisioningType: sa.NewStringRequest("thick"),
-				sa.RecoveryTest:     sa.NewBoolRequest(true),
-			},
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	sc, err := orchestrator.AddStorageClass(&storageclass.Config{
+		Name: "validationSSD",
+		Attributes: map[string]sa.Request{
+			sa.Media:        sa.NewStringRequest("ssd"),
+			sa.RecoveryTest: sa.NewBoolRequest(true),
 		},
-	)
+	})
 	if err != nil {
 		t.Fatal("Unable to add storage class: ", err)
 	}
-	newOrchestrator := getOrchestrator()
-	if bootstrappedSC := newOrchestrator.GetStorageClass(scName); bootstrappedSC == nil {
-		t.Error("Unable to find storage class after bootstrapping.")
-	} else if !reflect.DeepEqual(bootstrappedSC, originalSC) {
-		t.Errorf("External storage classs differ:\n\tOriginal:  %v\n\t."+
-			"Bootstrapped:  %v", originalSC, bootstrappedSC)
+	if sc.Validation == nil {
+		t.Fatal("Expected the storage class to be validated.")
+	}
+	if len(sc.Validation.UnsatisfiedAttributes) != 1 || sc.Validation.UnsatisfiedAttributes[0] != "media=ssd" {
+		t.Errorf("Expected only media=ssd to be unsatisfied, got %v.", sc.Validation.UnsatisfiedAttributes)
+	}
+	if len(sc.Validation.UnmatchedPools) != 1 || sc.Validation.UnmatchedPools[0].Backend != backendName {
+		t.Errorf("Expected the backend's pool to be unmatched, got %+v.", sc.Validation.UnmatchedPools)
+	}
+
+	// A class the backend satisfies has no unmatched pools, however it's retrieved
+	if sc = orchestrator.GetStorageClass(scName); len(sc.Validation.UnmatchedPools) != 0 ||
+		len(sc.Validation.UnsatisfiedAttributes) != 0 || len(sc.StoragePools[backendName]) != 1 {
+		t.Errorf("Expected storage class %s to match the backend's pool, got %+v.", scName, sc)
 	}
 	cleanup(t, orchestrator)
 }
 
-func TestFirstVolumeRecovery(t *testing.T) {
+func TestStorageClassDefaultSize(t *testing.T) {
 	const (
-		backendName      = "firstRecoveryBackend"
-		scName           = "firstRecoveryBackendSC"
-		fullVolumeName   = "firstRecoveryVolumeFull"
-		txOnlyVolumeName = "firstRecoveryVolumeTxOnly"
+		backendName = "defaultSizeBackend"
+		scName      = "defaultSizeBackendSC"
 	)
 	orchestrator := getOrchestrator()
 	prepRecoveryTest(t, orchestrator, backendName, scName)
-	txOnlyVolumeConfig := generateVolumeConfig(txOnlyVolumeName, 50, scName,
-		config.File)
-	// BEGIN actual test
-	runRecoveryTests(t, orchestrator, backendName, persistentstore.AddVolume,
-		[]recoveryTest{
-			{name: "firstTXOnly", volumeConfig: txOnlyVolumeConfig,
-				expectDestroy: true},
-		})
+
+	scConfig := &storageclass.Config{
+		Name: "defaultSizeSC",
+		Attributes: map[string]sa.Request{
+			sa.RecoveryTest: sa.NewBoolRequest(true),
+		},
+		DefaultSize: "lots",
+	}
+	if _, err := orchestrator.AddStorageClass(scConfig); err == nil {
+		t.Error("Expected an error adding a storage class with an invalid default size.")
+	}
+	scConfig.DefaultSize = "2Gi"
+	if _, err := orchestrator.AddStorageClass(scConfig); err != nil {
+		t.Fatal("Unable to add storage class: ", err)
+	}
+
+	for name, expected := range map[string]string{"sized": "1073741824", "unsized": "2147483648"} {
+		volumeConfig := generateVolumeConfig(name, 1, scConfig.Name, config.File)
+		if name == "unsized" {
+			volumeConfig.Size = "0"
+		}
+		if _, err := orchestrator.AddVolume(context.Background(), volumeConfig); err != nil {
+			t.Fatalf("Unable to add volume %s: %v", name, err)
+		}
+		if vol := orchestrator.GetVolume(name); vol == nil || vol.Config.Size != expected {
+			t.Errorf("Expected volume %s to be %s bytes, got %+v.", name, expected, vol)
+		}
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestPreviewVolumePlacement(t *testing.T) {
+	const (
+		backendName = "previewBackend"
+		scName      = "previewBackendSC"
+		volumeName  = "previewVolume"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	volumeConfig := generateVolumeConfig(volumeName, 1, scName, config.File)
+
+	preview, err := orchestrator.PreviewVolumePlacement(volumeConfig)
+	if err != nil {
+		t.Fatal("Unable to preview volume placement: ", err)
+	}
+	if len(preview.Candidates) != 1 {
+		t.Fatalf("Expected 1 candidate, got %d.", len(preview.Candidates))
+	}
+	candidate := preview.Candidates[0]
+	if candidate.Backend != backendName || candidate.Pool != "primary" {
+		t.Errorf("Expected candidate %s/primary, got %s/%s.", backendName, candidate.Backend, candidate.Pool)
+	}
+	if candidate.InternalName == "" {
+		t.Error("Candidate has no internal volume name.")
+	}
+	if orchestrator.GetVolume(volumeName) != nil || volumeConfig.InternalName != "" {
+		t.Error("Previewing the placement modified or created the volume.")
+	}
+
+	if _, err = orchestrator.CordonBackend(backendName, true); err != nil {
+		t.Fatal("Unable to cordon backend: ", err)
+	}
+	if preview, err = orchestrator.PreviewVolumePlacement(volumeConfig); err != nil {
+		t.Fatal("Unable to preview volume placement: ", err)
+	}
+	if len(preview.Candidates) != 0 || len(preview.Excluded) != 1 {
+		t.Errorf("Expected the cordoned pool to be excluded, got %d candidates and %d excluded.",
+			len(preview.Candidates), len(preview.Excluded))
+	}
+
+	volumeConfig.StorageClass = "previewMissingSC"
+	if _, err = orchestrator.PreviewVolumePlacement(volumeConfig); err == nil {
+		t.Error("Expected an error previewing placement with an unknown storage class.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestBulkVolumes(t *testing.T) {
+	const (
+		backendName = "bulkBackend"
+		scName      = "bulkBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	volumeConfigs := make([]*storage.VolumeConfig, 0)
+	for i := 0; i < 10; i++ {
+		volumeConfigs = append(volumeConfigs,
+			generateVolumeConfig(fmt.Sprintf("bulkVolume%d", i), 1, scName, config.File))
+	}
+	cloneConfig := generateVolumeConfig("bulkVolumeClone", 1, scName, config.File)
+	cloneConfig.CloneSourceVolume = "bulkVolumeMissing"
+	invalidConfig := generateVolumeConfig("bulkVolumeInvalid", 1, scName, config.File)
+	invalidConfig.Size = ""
+	volumeConfigs = append(volumeConfigs, cloneConfig, invalidConfig)
+
+	results := orchestrator.AddVolumes(context.Background(), volumeConfigs)
+	if len(results) != len(volumeConfigs) {
+		t.Fatalf("Expected %d results, got %d.", len(volumeConfigs), len(results))
+	}
+	for i, result := range results {
+		if result.Volume != volumeConfigs[i].Name {
+			t.Errorf("Result %d is for volume %s, expected %s.", i, result.Volume, volumeConfigs[i].Name)
+		}
+		expectError := i >= 10
+		if (result.Error != "") != expectError {
+			t.Errorf("Unexpected result for volume %s: %s", result.Volume, result.Error)
+		}
+		if !expectError && result.Backend != backendName {
+			t.Errorf("Expected volume %s on backend %s, got %s.", result.Volume, backendName, result.Backend)
+		}
+	}
+
+	volumeNames := []string{"bulkVolume0", "bulkVolume1", "bulkVolumeMissing"}
+	results = orchestrator.DeleteVolumes(context.Background(), volumeNames)
+	for i, result := range results {
+		if (result.Error != "") != (i == 2) {
+			t.Errorf("Unexpected result deleting volume %s: %s", result.Volume, result.Error)
+		}
+	}
+	if orchestrator.GetVolume("bulkVolume0") != nil || orchestrator.GetVolume("bulkVolume2") == nil {
+		t.Error("Bulk delete removed the wrong volumes.")
+	}
+
+	// Once the request is cancelled, no further volumes are deleted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range orchestrator.DeleteVolumes(ctx, []string{"bulkVolume2"}) {
+		if result.Error == "" {
+			t.Errorf("Expected volume %s not to be deleted after the request was cancelled.", result.Volume)
+		}
+	}
+	if orchestrator.GetVolume("bulkVolume2") == nil {
+		t.Error("Volume was deleted after the request was cancelled.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestUpdateBackendDebugTraceFlags(t *testing.T) {
+	const (
+		backendName = "traceBackend"
+		scName      = "traceBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	flags, err := orchestrator.UpdateBackendDebugTraceFlags(backendName,
+		map[string]bool{"method": true, "api": true})
+	if err != nil {
+		t.Fatal("Unable to update debug trace flags: ", err)
+	}
+	if !flags["method"] || !flags["api"] {
+		t.Errorf("Expected method and api tracing to be enabled, got %v.", flags)
+	}
+
+	// Flags not named are left alone
+	if flags, err = orchestrator.UpdateBackendDebugTraceFlags(backendName,
+		map[string]bool{"api": false}); err != nil {
+		t.Fatal("Unable to update debug trace flags: ", err)
+	}
+	if !flags["method"] || flags["api"] {
+		t.Errorf("Expected only method tracing to be enabled, got %v.", flags)
+	}
+	if _, err = orchestrator.UpdateBackendDebugTraceFlags("traceMissingBackend", nil); err == nil {
+		t.Error("Expected an error updating a missing backend.")
+	}
+
+	// The flags must survive a restart
+	backend, ok := getOrchestrator().backends[backendName]
+	if !ok {
+		t.Fatal("Backend not found after restart.")
+	}
+	if flags, err = backend.GetDebugTraceFlags(); err != nil {
+		t.Fatal("Unable to get debug trace flags: ", err)
+	}
+	if !flags["method"] || flags["api"] {
+		t.Errorf("Debug trace flags were not persisted; got %v.", flags)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestUpdateVolumeQoS(t *testing.T) {
+	const (
+		backendName = "qosBackend"
+		scName      = "qosBackendSC"
+		volumeName  = "qosVolume"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	if _, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 50, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	volume, err := orchestrator.UpdateVolumeQoS(volumeName, "1000,2000,4000", "")
+	if err != nil {
+		t.Fatal("Unable to update volume QoS: ", err)
+	}
+	if volume.Config.QoS != "1000,2000,4000" || volume.Config.QoSType != "" {
+		t.Errorf("Expected the volume's QoS to change, got %s/%s.", volume.Config.QoS, volume.Config.QoSType)
+	}
+	if _, err = orchestrator.UpdateVolumeQoS(volumeName, "", ""); err == nil {
+		t.Error("Expected an error updating QoS without any settings.")
+	}
+	if _, err = orchestrator.UpdateVolumeQoS("qosMissingVolume", "", "Gold"); err == nil {
+		t.Error("Expected an error updating a missing volume.")
+	}
+
+	// The new QoS must survive a restart
+	if volume = getOrchestrator().GetVolume(volumeName); volume == nil {
+		t.Fatal("Volume not found after restart.")
+	}
+	if volume.Config.QoS != "1000,2000,4000" {
+		t.Errorf("Volume QoS was not persisted; got %s.", volume.Config.QoS)
+	}
+	cleanup(t, orchestrator)
+}
+
+// unreachableStore is a persistent store that fails its health checks.
+type unreachableStore struct {
+	persistentstore.Client
+}
+
+func (s *unreachableStore) GetVersion() (*persistentstore.PersistentStateVersion, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestCheckHealth(t *testing.T) {
+	const (
+		backendName = "healthBackend"
+		scName      = "healthBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	report := orchestrator.CheckHealth(false)
+	if !report.Healthy() || !report.Ready() {
+		t.Errorf("Expected a healthy, ready orchestrator, got %v.", report)
+	}
+	if report.Backends != nil {
+		t.Errorf("Expected no backends in a liveness check, got %v.", report.Backends)
+	}
+
+	report = orchestrator.CheckHealth(true)
+	found := false
+	for _, backend := range report.Backends {
+		if backend.Name == backendName {
+			found = true
+			if !backend.Online {
+				t.Errorf("Expected backend %s to be online.", backendName)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected backend %s in the health report, got %v.", backendName, report.Backends)
+	}
+
+	// A store that can't be reached makes the orchestrator unhealthy
+	storeClient := orchestrator.storeClient
+	orchestrator.storeClient = &unreachableStore{storeClient}
+	report = orchestrator.CheckHealth(true)
+	if report.Healthy() || report.Ready() || report.Store.Error == "" {
+		t.Errorf("Expected an unhealthy orchestrator, got %v.", report)
+	}
+	orchestrator.storeClient = storeClient
+
+	cleanup(t, orchestrator)
+}
+
+func TestBadBootstrapEtcdV2(t *testing.T) {
+	if *etcdV2 == "" {
+		t.SkipNow()
+	}
+	_, err := persistentstore.NewEtcdClientV2("invalidIPAddress")
+	if err != nil && persistentstore.MatchUnavailableClusterErr(err) {
+	} else {
+		panic(fmt.Errorf("didn't catch invalid etcdv2 client"))
+	}
+}
+
+func TestBadBootstrapEtcdV3(t *testing.T) {
+	if *etcdV3 == "" {
+		t.SkipNow()
+	}
+	_, err := persistentstore.NewEtcdClientV3("invalidIPAddress")
+	if err != nil && persistentstore.MatchUnavailableClusterErr(err) {
+	} else {
+		panic(fmt.Errorf("didn't catch invalid etcdv3 client"))
+	}
+}
+
+func TestBootstrapEtcdV2ToEtcdV3Migration(t *testing.T) {
+	const (
+		backendName = "bootstrapV2toV3_backend"
+		scName      = "bootstrapV2toV3_class"
+		volumeName  = "bootstrapV2toV3_vol"
+	)
+	if *etcdV3 == "" {
+		t.SkipNow()
+	}
+	cleanupStoreVersion(t, *etcdV3)
+
+	// Populate the etcdv2 cluster with one backend, one storage class, and one volume
+	etcdV2Orig := etcdV2
+	etcdV2 = etcdV3
+	orchestratorV2 := getOrchestrator()
+	addBackendStorageClass(t, orchestratorV2, backendName, scName)
+
+	orchestratorV2.mutex.Lock()
+	if _, ok := orchestratorV2.storageClasses[scName]; !ok {
+		t.Fatal("Storage class not found in the orchestrator map!")
+	}
+	orchestratorV2.mutex.Unlock()
+
+	v2Volume, err := orchestratorV2.AddVolume(context.Background(), generateVolumeConfig(volumeName, 50, scName,
+		config.File))
+	if err != nil {
+		t.Fatal("Unable to create volume: ", err)
+	}
+	orchestratorV2.mutex.Lock()
+	if _, ok := orchestratorV2.volumes[volumeName]; !ok {
+		t.Fatal("Volume name found in orchestrator map!")
+	}
+	orchestratorV2.mutex.Unlock()
+
+	// Bootstrap etcdv3 orchestrator with etcdv2 data
+	etcdV2 = etcdV2Orig
+	orchestratorV3 := getOrchestrator()
+
+	// Verify etcdv2 to etcdv3 transformation
+	if orchestratorV3.GetBackend(backendName) == nil {
+		t.Fatalf("Failed to find backend %s after bootstrapping!", backendName)
+	}
+	//TODO: Optionally we can diff etcdv2 and etcdv3 backends here
+	if orchestratorV3.GetStorageClass(scName) == nil {
+		t.Fatalf("Failed to find storage class %s after bootstrapping!",
+			scName)
+	}
+	//TODO: Optionally we can diff etcdv2 and etcdv3 storage classes here
+	v3Volume := orchestratorV3.GetVolume(volumeName)
+	if v3Volume == nil {
+		t.Fatalf("Failed to find storage class %s after bootstrapping!",
+			volumeName)
+	}
+	// Verifying the same volume exists on both clusters
+	if !reflect.DeepEqual(v2Volume, v3Volume) {
+		t.Fatalf("etcdv2 volume (%v) doesn't match the etcdv3 volume (%v)!",
+			v2Volume, v3Volume)
+	}
+	etcdv3Client, err := persistentstore.NewEtcdClientV3(*etcdV3)
+	if err != nil {
+		t.Fatalf("Creating etcdv3 client failed: %v", err)
+	}
+	versionJSON, err := etcdv3Client.Read(config.StoreURL)
+	if err != nil {
+		t.Fatalf("Couldn't determine the orchestrator persistent state version: %v",
+			err)
+	}
+	version := &persistentstore.PersistentStateVersion{}
+	err = json.Unmarshal([]byte(versionJSON), version)
+	if err != nil {
+		t.Fatalf("Couldn't unmarshal the orchestrator persistent state version: %v", err)
+	}
+	if config.OrchestratorAPIVersion != version.OrchestratorAPIVersion ||
+		string(orchestratorV3.storeClient.GetType()) != version.PersistentStoreVersion {
+		t.Fatalf("Failed to set the orchestrator persistent state version after bootsrapping: %v",
+			err)
+	}
+
+	// cleanup
+	if err = etcdv3Client.Stop(); err != nil {
+		t.Fatalf("Couldn't shut down etcdv3 client: %v", err)
+	}
+	cleanup(t, orchestratorV2)
+	cleanup(t, orchestratorV3)
+	cleanupStoreVersion(t, *etcdV3)
+}
+
+// The next series of tests test that bootstrap doesn't exit early if it
+// encounters a key error for one of the main types of entries.
+func TestStorageClassOnlyBootstrap(t *testing.T) {
+	const scName = "storageclass-only"
+
+	orchestrator := getOrchestrator()
+	originalSC, err := orchestrator.AddStorageClass(
+		&storageclass.Config{
+			Name: scName,
+			Attributes: map[string]sa.Request{
+				sa.Media:            sa.NewStringRequest("hdd"),
+				sa.ProvisioningType: sa.NewStringRequest("thick"),
+				sa.RecoveryTest:     sa.NewBoolRequest(true),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Unable to add storage class: ", err)
+	}
+	newOrchestrator := getOrchestrator()
+	if bootstrappedSC := newOrchestrator.GetStorageClass(scName); bootstrappedSC == nil {
+		t.Error("Unable to find storage class after bootstrapping.")
+	} else if !reflect.DeepEqual(bootstrappedSC, originalSC) {
+		t.Errorf("External storage classs differ:\n\tOriginal:  %v\n\t."+
+			"Bootstrapped:  %v", originalSC, bootstrappedSC)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestFirstVolumeRecovery(t *testing.T) {
+	const (
+		backendName      = "firstRecoveryBackend"
+		scName           = "firstRecoveryBackendSC"
+		fullVolumeName   = "firstRecoveryVolumeFull"
+		txOnlyVolumeName = "firstRecoveryVolumeTxOnly"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	txOnlyVolumeConfig := generateVolumeConfig(txOnlyVolumeName, 50, scName,
+		config.File)
+	// BEGIN actual test
+	runRecoveryTests(t, orchestrator, backendName, persistentstore.AddVolume,
+		[]recoveryTest{
+			{name: "firstTXOnly", volumeConfig: txOnlyVolumeConfig,
+				expectDestroy: true},
+		})
+	cleanup(t, orchestrator)
+}
+
+// panickingDriver stands in for a driver that panics on a malformed response from its storage.
+type panickingDriver struct {
+	*fakedriver.StorageDriver
+}
+
+func (d *panickingDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
+	panic("malformed response")
+}
+
+func (d *panickingDriver) SnapshotList(name string) ([]storage.Snapshot, error) {
+	var snapshots []storage.Snapshot
+	return snapshots[:1], nil
+}
+
+func (d *panickingDriver) GetVolumeExternalWrappers(channel chan *storage.VolumeExternalWrapper) {
+	defer close(channel)
+	panic("malformed response")
+}
+
+func TestDriverPanicRecovery(t *testing.T) {
+	const (
+		backendName = "panicBackend"
+		scName      = "panicBackendSC"
+		volumeName  = "panicVolume"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	if _, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	backend := orchestrator.backends[backendName]
+	backend.Driver = &panickingDriver{backend.Driver.(*fakedriver.StorageDriver)}
+
+	if _, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig("panicVolume2", 1, scName, config.File)); err == nil {
+		t.Error("Expected an error from a driver that panics creating a volume.")
+	}
+	if _, ok := orchestrator.volumes["panicVolume2"]; ok {
+		t.Error("Expected no volume after the driver panicked.")
+	}
+
+	if _, err := orchestrator.ListVolumeSnapshots(volumeName); err == nil {
+		t.Error("Expected an error from a driver that panics listing snapshots.")
+	}
+
+	reconciled := false
+	report, err := orchestrator.ReconcileBackends(false)
+	if err != nil {
+		t.Fatal("Unable to reconcile backends: ", err)
+	}
+	for _, result := range report.Backends {
+		if result.Backend == backendName {
+			reconciled = len(result.Errors) > 0
+		}
+	}
+	if !reconciled {
+		t.Error("Expected a reconciliation error from a driver that panics listing volumes.")
+	}
+
+	// The orchestrator is still usable afterwards
+	if orchestrator.GetVolume(volumeName) == nil {
+		t.Error("Unable to get volume after driver panics.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestCloneOnDelete(t *testing.T) {
+	const (
+		backendName = "onDeleteBackend"
+		scName      = "onDeleteBackendSC"
+		volumeName  = "onDeleteVolume"
+		cloneName   = "onDeleteClone"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+
+	// Only clones may be retained
+	volumeConfig := generateVolumeConfig(volumeName, 1, scName, config.File)
+	volumeConfig.OnDelete = drivers.OnDeleteRetain
+	if _, err := orchestrator.AddVolume(context.Background(), volumeConfig); err == nil {
+		t.Error("Expected an error adding a volume that is retained on delete.")
+	}
+	volumeConfig.OnDelete = ""
+	if _, err := orchestrator.AddVolume(context.Background(), volumeConfig); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	cloneConfig := generateVolumeConfig(cloneName, 1, scName, config.File)
+	cloneConfig.CloneSourceVolume = volumeName
+	cloneConfig.OnDelete = "archive"
+	if _, err := orchestrator.CloneVolume(context.Background(), cloneConfig); err == nil {
+		t.Error("Expected an error cloning with an invalid onDelete value.")
+	}
+	cloneConfig.OnDelete = drivers.OnDeleteRetain
+	clone, err := orchestrator.CloneVolume(context.Background(), cloneConfig)
+	if err != nil {
+		t.Fatal("Unable to clone volume: ", err)
+	}
+	if clone.Config.OnDelete != drivers.OnDeleteRetain {
+		t.Errorf("Expected clone to be retained on delete, got %s.", clone.Config.OnDelete)
+	}
+
+	// The retained clone leaves Trident but stays on its storage
+	if _, err = orchestrator.DeleteVolume(context.Background(), cloneName); err != nil {
+		t.Fatal("Unable to delete clone: ", err)
+	}
+	if orchestrator.GetVolume(cloneName) != nil {
+		t.Error("Expected the clone to be deleted from Trident.")
+	}
+	f := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	if _, ok := f.Volumes[clone.Config.InternalName]; !ok || f.DestroyedVolumes[clone.Config.InternalName] {
+		t.Error("Expected the clone to be retained by the driver.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestVolumeStats(t *testing.T) {
+	const (
+		backendName = "statsBackend"
+		scName      = "statsBackendSC"
+		volumeName  = "statsVolume"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	if _, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	report, err := orchestrator.GetVolumeStats(volumeName, 0)
+	if err != nil {
+		t.Fatal("Unable to get volume stats: ", err)
+	}
+	if report.Volume != volumeName || report.Backend != backendName || report.Stats == nil || report.Rates != nil {
+		t.Errorf("Expected a single sample of the volume's counters, got %+v.", report)
+	}
+
+	report, err = orchestrator.GetVolumeStats(volumeName, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal("Unable to get volume stats: ", err)
+	}
+	if report.Rates == nil || report.Rates.TotalIOPS != 0 {
+		t.Errorf("Expected an idle volume's rates, got %+v.", report.Rates)
+	}
+
+	if _, err = orchestrator.GetVolumeStats(volumeName, MaxVolumeStatsInterval+time.Second); err == nil {
+		t.Error("Expected an error for too long an interval.")
+	}
+	if _, err = orchestrator.GetVolumeStats("statsMissingVolume", 0); err == nil {
+		t.Error("Expected an error for a missing volume.")
+	}
+
+	reports, err := orchestrator.ListVolumeStats(0)
+	if err != nil {
+		t.Fatal("Unable to list volume stats: ", err)
+	}
+	found := false
+	for _, report := range reports {
+		found = found || (report.Volume == volumeName && report.Stats != nil)
+	}
+	if !found {
+		t.Error("Expected the volume's stats to be listed.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestSnapshotSchedules(t *testing.T) {
+	const (
+		backendName  = "scheduleBackend"
+		scName       = "scheduleBackendSC"
+		volumeName   = "scheduleVolume"
+		scheduleName = "every-minute"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	volume, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	if _, err = orchestrator.AddSnapshotSchedule(&storage.SnapshotScheduleConfig{
+		Name: scheduleName, Schedule: "* * * * *", Volumes: []string{"scheduleMissingVolume"}, Retain: 2,
+	}); err == nil {
+		t.Error("Expected an error adding a schedule for a missing volume.")
+	}
+	schedule, err := orchestrator.AddSnapshotSchedule(&storage.SnapshotScheduleConfig{
+		Name: scheduleName, Schedule: "* * * * *", Volumes: []string{volumeName}, Retain: 2,
+	})
+	if err != nil {
+		t.Fatal("Unable to add snapshot schedule: ", err)
+	}
+	if _, err = orchestrator.AddSnapshotSchedule(schedule.Config); err == nil {
+		t.Error("Expected an error adding a duplicate schedule.")
+	}
+
+	// A snapshot taken outside the schedule must never be pruned
+	f := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	if _, err = f.CreateSnapshot("manual", volume.Config.InternalName); err != nil {
+		t.Fatal("Unable to take manual snapshot: ", err)
+	}
+
+	// Nothing is due before the first scheduled time
+	orchestrator.takeScheduledSnapshots(schedule.NextRun.Add(-time.Second))
+	if len(f.Snapshots[volume.Config.InternalName]) != 1 {
+		t.Fatalf("Expected no scheduled snapshots yet, got %v.", f.Snapshots[volume.Config.InternalName])
+	}
+
+	var lastRun time.Time
+	for i := 0; i < 3; i++ {
+		lastRun = schedule.NextRun.Add(time.Duration(i) * time.Minute)
+		orchestrator.takeScheduledSnapshots(lastRun)
+	}
+	names := make([]string, 0)
+	for _, snapshot := range f.Snapshots[volume.Config.InternalName] {
+		names = append(names, snapshot.Name)
+	}
+	expected := []string{
+		"manual",
+		scheduleName + "-" + lastRun.Add(-time.Minute).Format("20060102-1504"),
+		scheduleName + "-" + lastRun.Format("20060102-1504"),
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected snapshots %v, got %v.", expected, names)
+	}
+
+	// The schedule's state must survive a restart
+	newOrchestrator := getOrchestrator()
+	restored := newOrchestrator.GetSnapshotSchedule(scheduleName)
+	if restored == nil {
+		t.Fatal("Snapshot schedule not found after restart.")
+	}
+	if !restored.State.LastRun.Equal(lastRun) {
+		t.Errorf("Expected last run at %v, got %v.", lastRun, restored.State.LastRun)
+	}
+	if state := restored.State.Volumes[volumeName]; state == nil || state.LastSnapshot != expected[2] {
+		t.Errorf("Expected last snapshot %s, got %+v.", expected[2], state)
+	}
+
+	if _, err = orchestrator.DeleteSnapshotSchedule(scheduleName); err != nil {
+		t.Error("Unable to delete snapshot schedule: ", err)
+	}
+	if found, _ := orchestrator.DeleteSnapshotSchedule(scheduleName); found {
+		t.Error("Snapshot schedule was found after deletion.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestVolumeGroups(t *testing.T) {
+	const (
+		backendName = "groupBackend"
+		scName      = "groupBackendSC"
+	)
+	orchestrator := getOrchestrator()
+	prepRecoveryTest(t, orchestrator, backendName, scName)
+	ctx := context.Background()
+
+	group, err := orchestrator.AddVolumeGroup(ctx, &storage.VolumeGroupConfig{
+		Name: "db",
+		Volumes: []*storage.VolumeConfig{
+			generateVolumeConfig("db-data", 10, scName, config.File),
+			generateVolumeConfig("db-log", 1, scName, config.File),
+		},
+	})
+	if err != nil {
+		t.Fatal("Unable to add volume group: ", err)
+	}
+	if len(group.Volumes) != 2 {
+		t.Errorf("Expected 2 volumes in the group, got %d.", len(group.Volumes))
+	}
+
+	// A group whose volumes can't all be created isn't added, and leaves no volumes behind
+	if _, err = orchestrator.AddVolumeGroup(ctx, &storage.VolumeGroupConfig{
+		Name: "broken",
+		Volumes: []*storage.VolumeConfig{
+			generateVolumeConfig("broken-data", 1, scName, config.File),
+			generateVolumeConfig("broken-log", 1, "groupMissingSC", config.File),
+		},
+	}); err == nil {
+		t.Error("Expected an error adding a volume group with an unknown storage class.")
+	}
+	if orchestrator.GetVolume("broken-data") != nil || orchestrator.GetVolumeGroup("broken") != nil {
+		t.Error("Failed volume group was not cleaned up.")
+	}
+
+	snapshot, err := orchestrator.SnapshotVolumeGroup("db", "backup")
+	if err != nil {
+		t.Fatal("Unable to snapshot volume group: ", err)
+	}
+	if !snapshot.Consistent || len(snapshot.Volumes) != 2 || snapshot.Volumes["db-log"] == nil {
+		t.Errorf("Expected a consistent snapshot of both volumes, got %+v.", snapshot)
+	}
+	if _, err = orchestrator.SnapshotVolumeGroup("db", "backup"); err == nil {
+		t.Error("Expected an error taking a duplicate snapshot.")
+	}
+	f := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	for _, volume := range group.Volumes {
+		if snapshots := f.Snapshots[volume.Config.InternalName]; len(snapshots) != 1 {
+			t.Errorf("Expected one snapshot of %s after a failed snapshot, got %v.", volume.Config.Name, snapshots)
+		}
+	}
+
+	clone, err := orchestrator.AddVolumeGroup(ctx, &storage.VolumeGroupConfig{
+		Name:                "db2",
+		CloneSourceGroup:    "db",
+		CloneSourceSnapshot: "backup",
+	})
+	if err != nil {
+		t.Fatal("Unable to clone volume group: ", err)
+	}
+	cloneNames := make([]string, 0)
+	for _, volume := range clone.Volumes {
+		cloneNames = append(cloneNames, volume.Config.Name)
+	}
+	if !reflect.DeepEqual(cloneNames, []string{"db2-data", "db2-log"}) {
+		t.Errorf("Expected clones db2-data and db2-log, got %v.", cloneNames)
+	}
+
+	// Deleting a volume removes it from its group, and the change survives a restart
+	if _, err = orchestrator.DeleteVolume(ctx, "db2-log"); err != nil {
+		t.Fatal("Unable to delete volume: ", err)
+	}
+	restored := getOrchestrator().GetVolumeGroup("db2")
+	if restored == nil || len(restored.Volumes) != 1 || restored.Volumes[0].Config.Name != "db2-data" {
+		t.Errorf("Expected volume group db2 with only db2-data after restart, got %+v.", restored)
+	}
+
+	for _, name := range []string{"db", "db2"} {
+		if _, err = orchestrator.DeleteVolumeGroup(ctx, name); err != nil {
+			t.Errorf("Unable to delete volume group %s: %v", name, err)
+		}
+	}
+	if len(orchestrator.ListVolumeGroups()) != 0 || len(orchestrator.ListVolumes()) != 0 {
+		t.Error("Volume groups or their volumes remain after deletion.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func waitForMigration(
+	t *testing.T, orchestrator *TridentOrchestrator, volumeName string, state storage.MigrationState,
+) *storage.VolumeMigration {
+	for i := 0; i < 100; i++ {
+		migration := orchestrator.GetVolumeMigration(volumeName)
+		if migration != nil && migration.State == state {
+			return migration
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Migration of volume %s did not become %s; got %+v.", volumeName, state,
+		orchestrator.GetVolumeMigration(volumeName))
+	return nil
+}
+
+func TestVolumeMigration(t *testing.T) {
+	const (
+		scName     = "migrateSC"
+		volumeName = "migrateVolume"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, "migrateBackend1", scName)
+	addBackend(t, orchestrator, "migrateBackend2")
+	ctx := context.Background()
+
+	volume, err := orchestrator.AddVolume(ctx, generateVolumeConfig(volumeName, 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	// Either backend may hold the volume, so migrate it to the other one
+	sourceName, destinationName := "migrateBackend1", "migrateBackend2"
+	if volume.Backend == destinationName {
+		sourceName, destinationName = destinationName, sourceName
+	}
+	source := orchestrator.backends[sourceName].Driver.(*fakedriver.StorageDriver)
+	destination := orchestrator.backends[destinationName].Driver.(*fakedriver.StorageDriver)
+
+	if _, err = orchestrator.MigrateVolume(volumeName, &storage.VolumeMigrationConfig{Backend: sourceName}); err == nil {
+		t.Error("Expected an error migrating a volume to its own backend.")
+	}
+	if _, err = orchestrator.MigrateVolume(volumeName, &storage.VolumeMigrationConfig{}); err == nil {
+		t.Error("Expected an error migrating a volume without a backend.")
+	}
+
+	// A cancelled migration leaves the volume where it was and deletes its replica
+	migration, err := orchestrator.MigrateVolume(volumeName, &storage.VolumeMigrationConfig{Backend: destinationName})
+	if err != nil {
+		t.Fatal("Unable to migrate volume: ", err)
+	}
+	if migration.Method != storage.MigrationMethodReplication {
+		t.Errorf("Expected the volume to be replicated between fake backends, got %s.", migration.Method)
+	}
+	waitForMigration(t, orchestrator, volumeName, storage.MigrationReady)
+	if _, err = orchestrator.DeleteVolume(ctx, volumeName); err == nil {
+		t.Error("Expected an error deleting a migrating volume.")
+	}
+	if _, err = orchestrator.CancelVolumeMigration(volumeName); err != nil {
+		t.Fatal("Unable to cancel volume migration: ", err)
+	}
+	if migration = orchestrator.GetVolumeMigration(volumeName); migration.State != storage.MigrationFailed {
+		t.Errorf("Expected a cancelled migration to have failed, got %s.", migration.State)
+	}
+	if _, ok := destination.Volumes[volume.Config.InternalName]; ok {
+		t.Error("Replica of cancelled migration was not deleted.")
+	}
+
+	if _, err = orchestrator.MigrateVolume(volumeName, &storage.VolumeMigrationConfig{Backend: destinationName}); err != nil {
+		t.Fatal("Unable to migrate volume again: ", err)
+	}
+	waitForMigration(t, orchestrator, volumeName, storage.MigrationReady)
+	if migration, err = orchestrator.CutoverVolumeMigration(volumeName); err != nil {
+		t.Fatal("Unable to cut over volume migration: ", err)
+	}
+	if migration.State != storage.MigrationCompleted || migration.Passes < 3 {
+		t.Errorf("Expected a completed migration after at least three transfers, got %+v.", migration)
+	}
+
+	if backend := orchestrator.GetVolume(volumeName).Backend; backend != destinationName {
+		t.Errorf("Expected volume on backend %s, got %s.", destinationName, backend)
+	}
+	if _, ok := orchestrator.backends[sourceName].Volumes[volumeName]; ok {
+		t.Error("Migrated volume is still recorded on its source backend.")
+	}
+	if _, ok := source.Volumes[volume.Config.InternalName]; !ok || !migration.SourceRetained {
+		t.Error("Original volume was not kept after cutover.")
+	}
+
+	// The original is still kept after a restart, until the migration is deleted
+	restarted := getOrchestrator()
+	if restored := restarted.GetVolume(volumeName); restored == nil || restored.Backend != destinationName {
+		t.Errorf("Expected volume on backend %s after restart, got %+v.", destinationName, restored)
+	}
+	source = restarted.backends[sourceName].Driver.(*fakedriver.StorageDriver)
+	if migration = restarted.GetVolumeMigration(volumeName); migration == nil || !migration.SourceRetained {
+		t.Fatalf("Expected the cut over migration to be restored, got %+v.", migration)
+	}
+	if source.DestroyedVolumes[volume.Config.InternalName] {
+		t.Error("Original volume was deleted after restart.")
+	}
+	if _, err = restarted.CancelVolumeMigration(volumeName); err != nil {
+		t.Fatal("Unable to delete volume migration: ", err)
+	}
+	if !source.DestroyedVolumes[volume.Config.InternalName] {
+		t.Error("Original volume was not deleted with its migration.")
+	}
+	if txns, err := restarted.storeClient.GetVolumeTransactions(); err != nil || len(txns) != 0 {
+		t.Errorf("Expected no volume transactions after the migration was deleted, got %v (%v).", txns, err)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestRehostVolume(t *testing.T) {
+	const (
+		scName     = "rehostSC"
+		volumeName = "rehostVolume"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, "rehostBackend1", scName)
+	addBackend(t, orchestrator, "rehostBackend2")
+	ctx := context.Background()
+
+	volume, err := orchestrator.AddVolume(ctx, generateVolumeConfig(volumeName, 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	sourceName, destinationName := "rehostBackend1", "rehostBackend2"
+	if volume.Backend == destinationName {
+		sourceName, destinationName = destinationName, sourceName
+	}
+	source := orchestrator.backends[sourceName].Driver.(*fakedriver.StorageDriver)
+	destination := orchestrator.backends[destinationName].Driver.(*fakedriver.StorageDriver)
+
+	if _, err = orchestrator.RehostVolume(volumeName, sourceName); err == nil {
+		t.Error("Expected an error rehosting a volume to its own backend.")
+	}
+	if _, err = orchestrator.RehostVolume(volumeName, "missingBackend"); err == nil {
+		t.Error("Expected an error rehosting a volume to a missing backend.")
+	}
+
+	rehosted, err := orchestrator.RehostVolume(volumeName, destinationName)
+	if err != nil {
+		t.Fatal("Unable to rehost volume: ", err)
+	}
+	if rehosted.Backend != destinationName {
+		t.Errorf("Expected volume on backend %s, got %s.", destinationName, rehosted.Backend)
+	}
+	if _, ok := source.Volumes[volume.Config.InternalName]; ok {
+		t.Error("Rehosted volume is still on its source backend's storage.")
+	}
+	if _, ok := destination.Volumes[volume.Config.InternalName]; !ok {
+		t.Error("Rehosted volume is not on its new backend's storage.")
+	}
+	if _, ok := orchestrator.backends[sourceName].Volumes[volumeName]; ok {
+		t.Error("Rehosted volume is still recorded on its source backend.")
+	}
+	if restored := getOrchestrator().GetVolume(volumeName); restored == nil || restored.Backend != destinationName {
+		t.Errorf("Expected volume on backend %s after restart, got %+v.", destinationName, restored)
+	}
+	if txns, err := orchestrator.storeClient.GetVolumeTransactions(); err != nil || len(txns) != 0 {
+		t.Errorf("Expected no volume transactions after rehost, got %v (%v).", txns, err)
+	}
+
+	// A rehost interrupted after the storage moved the volume is finished by the cleanup
+	orchestrator.mutex.Lock()
+	current := orchestrator.volumes[volumeName]
+	orchestrator.mutex.Unlock()
+	if err = source.RehostVolume(ctx, current.Config, destination); err != nil {
+		t.Fatal("Unable to move fake volume: ", err)
+	}
+	volTxn := &persistentstore.VolumeTransaction{
+		Config:        current.Config,
+		Op:            persistentstore.RehostVolume,
+		RehostBackend: sourceName,
+	}
+	if err = orchestrator.storeClient.AddVolumeTransaction(volTxn); err != nil {
+		t.Fatal("Unable to add volume transaction: ", err)
+	}
+	orchestrator.cleanUpFailedOperations(DefaultStaleOperationAge)
+	if backend := orchestrator.GetVolume(volumeName).Backend; backend != sourceName {
+		t.Errorf("Expected the interrupted rehost to finish on backend %s, got %s.", sourceName, backend)
+	}
+	if txns, err := orchestrator.storeClient.GetVolumeTransactions(); err != nil || len(txns) != 0 {
+		t.Errorf("Expected no volume transactions after cleanup, got %v (%v).", txns, err)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestRenameBackend(t *testing.T) {
+	const (
+		backendName = "renameBackend"
+		scName      = "renameBackendSC"
+		volumeName  = "renameVolume"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	_, err := orchestrator.AddVolume(context.Background(),
+		generateVolumeConfig(volumeName, 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	backendUUID := orchestrator.GetBackend(backendName).BackendUUID
+	if backendUUID == "" {
+		t.Fatal("Backend was not given a UUID.")
+	}
+
+	if _, err = orchestrator.RenameBackend(backendName, ""); err == nil {
+		t.Error("Expected an error renaming a backend to an empty name.")
+	}
+	backend, err := orchestrator.RenameBackend(backendUUID, "renamedBackend")
+	if err != nil {
+		t.Fatal("Unable to rename backend: ", err)
+	}
+	if backend.Name != "renamedBackend" || backend.BackendUUID != backendUUID {
+		t.Errorf("Expected backend renamedBackend with UUID %s, got %s with %s.", backendUUID,
+			backend.Name, backend.BackendUUID)
+	}
+	if orchestrator.GetBackend(backendName) != nil {
+		t.Error("Backend is still found under its old name.")
+	}
+	if volume := orchestrator.GetVolume(volumeName); volume.Backend != "renamedBackend" {
+		t.Errorf("Expected volume on backend renamedBackend, got %s.", volume.Backend)
+	}
+
+	// A config whose generated name differs still updates the backend it is applied to
+	configJSON, err := fakedriver.NewFakeStorageDriverConfigJSON("reidentifiedBackend", config.File,
+		map[string]*fake.StoragePool{
+			"primary": {
+				Attrs: map[string]sa.Offer{
+					sa.Media:            sa.NewStringOffer("hdd"),
+					sa.ProvisioningType: sa.NewStringOffer("thick", "thin"),
+					sa.TestingAttribute: sa.NewBoolOffer(true),
+				},
+				Bytes: 100 * 1024 * 1024 * 1024,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Unable to create mock driver config JSON: ", err)
+	}
+	if backend, err = orchestrator.UpdateBackend("renamedBackend", configJSON); err != nil {
+		t.Fatal("Unable to update backend: ", err)
+	}
+	if backend.Name != "reidentifiedBackend" || backend.BackendUUID != backendUUID {
+		t.Errorf("Expected backend reidentifiedBackend with UUID %s, got %s with %s.", backendUUID,
+			backend.Name, backend.BackendUUID)
+	}
+	if len(orchestrator.ListBackends()) != 1 {
+		t.Errorf("Expected one backend after updating it, got %d.", len(orchestrator.ListBackends()))
+	}
+
+	// The new name and the volume's binding must survive a restart
+	restarted := getOrchestrator()
+	if backend = restarted.GetBackend(backendUUID); backend == nil || backend.Name != "reidentifiedBackend" {
+		t.Fatalf("Expected backend reidentifiedBackend after restart, got %+v.", backend)
+	}
+	if volume := restarted.GetVolume(volumeName); volume == nil || volume.Backend != "reidentifiedBackend" ||
+		volume.BackendUUID != backendUUID {
+		t.Errorf("Expected volume bound to backend reidentifiedBackend after restart, got %+v.", volume)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestAddDuplicateBackend(t *testing.T) {
+	const backendName = "duplicateBackend"
+	orchestrator := getOrchestrator()
+	configJSON, err := fakedriver.NewFakeStorageDriverConfigJSON(backendName, config.File,
+		map[string]*fake.StoragePool{
+			"primary": {
+				Attrs: map[string]sa.Offer{sa.Media: sa.NewStringOffer("hdd")},
+				Bytes: 100 * 1024 * 1024 * 1024,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Unable to generate config JSON: ", err)
+	}
+	if _, err = orchestrator.AddStorageBackend(configJSON); err != nil {
+		t.Fatal("Unable to add backend: ", err)
+	}
+
+	// The same storage under a different name is rejected
+	var configMap map[string]interface{}
+	if err = json.Unmarshal([]byte(configJSON), &configMap); err != nil {
+		t.Fatal("Unable to parse config JSON: ", err)
+	}
+	configMap["backendName"] = "otherDuplicateBackend"
+	duplicateJSON, err := json.Marshal(configMap)
+	if err != nil {
+		t.Fatal("Unable to generate duplicate config JSON: ", err)
+	}
+	if _, err = orchestrator.AddStorageBackend(string(duplicateJSON)); err == nil {
+		t.Error("Expected an error adding a backend for the same storage.")
+	}
+	if orchestrator.GetBackend("otherDuplicateBackend") != nil {
+		t.Error("Duplicate backend was added.")
+	}
+
+	// Updating the original backend is still allowed
+	if _, err = orchestrator.UpdateBackend(backendName, configJSON); err != nil {
+		t.Error("Unable to update backend: ", err)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestVolumeNameMappings(t *testing.T) {
+	const (
+		backendName = "namesBackend"
+		scName      = "namesSC"
+		volumeName  = "names-volume"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	ctx := context.Background()
+
+	vol, err := orchestrator.AddVolume(ctx, generateVolumeConfig(volumeName, 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	internalName := vol.Config.InternalName
+	if found := orchestrator.GetVolumeByInternalName(backendName, internalName); found == nil ||
+		found.Config.Name != volumeName {
+		t.Errorf("Expected internal name %s to map to volume %s, got %+v.", internalName, volumeName, found)
+	}
+	if orchestrator.GetVolumeByInternalName("otherBackend", internalName) != nil {
+		t.Error("Expected no volume for an unknown backend.")
+	}
+
+	// A mapping lost from the store is recreated from the volume when Trident restarts
+	mapping := storage.NewVolumeNameMapping(orchestrator.volumes[volumeName])
+	if err = orchestrator.storeClient.DeleteVolumeNameMapping(mapping); err != nil {
+		t.Fatal("Unable to delete volume name mapping: ", err)
+	}
+	if found := getOrchestrator().GetVolumeByInternalName(backendName, internalName); found == nil {
+		t.Error("Expected the volume name mapping to be restored after a restart.")
+	}
+	mappings, err := orchestrator.storeClient.GetVolumeNameMappings()
+	if err != nil || len(mappings) != 1 || mappings[0].Volume != volumeName {
+		t.Errorf("Expected one stored mapping for %s, got %v (%v).", volumeName, mappings, err)
+	}
+
+	if _, err = orchestrator.DeleteVolume(ctx, volumeName); err != nil {
+		t.Fatal("Unable to delete volume: ", err)
+	}
+	if orchestrator.GetVolumeByInternalName(backendName, internalName) != nil {
+		t.Error("Expected no volume for the internal name of a deleted volume.")
+	}
+	if mappings, _ = orchestrator.storeClient.GetVolumeNameMappings(); len(mappings) != 0 {
+		t.Errorf("Expected no stored mappings after deleting the volume, got %v.", mappings)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestWarmPool(t *testing.T) {
+	const (
+		backendName = "warmBackend"
+		scName      = "warmSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackend(t, orchestrator, backendName)
+	ctx := context.Background()
+
+	scConfig := &storageclass.Config{
+		Name: scName,
+		Attributes: map[string]sa.Request{
+			sa.TestingAttribute: sa.NewBoolRequest(true),
+		},
+		WarmPoolSize:   -1,
+		WarmVolumeSize: "1Gi",
+	}
+	if _, err := orchestrator.AddStorageClass(scConfig); err == nil {
+		t.Error("Expected an error adding a storage class with a negative warm pool size.")
+	}
+	scConfig.WarmPoolSize = 2
+	if _, err := orchestrator.AddStorageClass(scConfig); err != nil {
+		t.Fatal("Unable to add storage class: ", err)
+	}
+
+	orchestrator.fillWarmPools()
+	if len(orchestrator.warmVolumes) != 2 {
+		t.Fatalf("Expected 2 warm volumes, got %d.", len(orchestrator.warmVolumes))
+	}
+	if volumes := orchestrator.ListVolumes(); len(volumes) != 0 {
+		t.Errorf("Expected warm volumes not to be listed, got %d volumes.", len(volumes))
+	}
+	if sc := orchestrator.GetStorageClass(scName); sc == nil || sc.WarmVolumes != 2 {
+		t.Errorf("Expected the storage class to report 2 warm volumes, got %+v.", sc)
+	}
+
+	// A request the warm pool can serve is bound to one of its volumes, grown to the requested size
+	claimConfig := generateVolumeConfig("claimed", 2, scName, config.File)
+	claimConfig.SnapshotPolicy, claimConfig.SnapshotDir = "", ""
+	vol, err := orchestrator.AddVolume(ctx, claimConfig)
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	if len(orchestrator.warmVolumes) != 1 {
+		t.Errorf("Expected 1 warm volume left after a claim, got %d.", len(orchestrator.warmVolumes))
+	}
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	if len(driver.Volumes) != 2 {
+		t.Errorf("Expected the claim to create no new volume, found %d on the backend.", len(driver.Volumes))
+	}
+	if fakeVolume, ok := driver.Volumes[vol.Config.InternalName]; !ok || fakeVolume.SizeBytes != 2*1024*1024*1024 {
+		t.Errorf("Expected the claimed volume to be renamed %s and grown to 2 GiB, got %+v.",
+			vol.Config.InternalName, fakeVolume)
+	}
+	if vol.Config.WarmPool || vol.Config.Size != "2147483648" {
+		t.Errorf("Expected the claimed volume to have the requested config, got %+v.", vol.Config)
+	}
+	if found := orchestrator.GetVolumeByInternalName(backendName, vol.Config.InternalName); found == nil {
+		t.Error("Expected the claimed volume's internal name to be mapped.")
+	}
+
+	// A request that sets options applied at creation gets a new volume
+	if _, err = orchestrator.AddVolume(ctx, generateVolumeConfig("unclaimed", 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	if len(orchestrator.warmVolumes) != 1 || len(driver.Volumes) != 3 {
+		t.Errorf("Expected a new volume, leaving 1 warm volume, got %d warm of %d volumes.",
+			len(orchestrator.warmVolumes), len(driver.Volumes))
+	}
+
+	// The warm pool is kept apart from the other volumes across a restart
+	restarted := getOrchestrator()
+	if len(restarted.warmVolumes) != 1 || len(restarted.volumes) != 2 {
+		t.Errorf("Expected 1 warm volume and 2 volumes after a restart, got %d and %d.",
+			len(restarted.warmVolumes), len(restarted.volumes))
+	}
+
+	orchestrator.fillWarmPools()
+	if len(orchestrator.warmVolumes) != 2 {
+		t.Errorf("Expected the warm pool to be refilled to 2 volumes, got %d.", len(orchestrator.warmVolumes))
+	}
+
+	// Deleting the class drains its warm pool
+	if _, err = orchestrator.DeleteStorageClass(scName); err != nil {
+		t.Fatal("Unable to delete storage class: ", err)
+	}
+	orchestrator.fillWarmPools()
+	if len(orchestrator.warmVolumes) != 0 || len(driver.Volumes) != 2 {
+		t.Errorf("Expected the warm pool to be drained, got %d warm of %d volumes.",
+			len(orchestrator.warmVolumes), len(driver.Volumes))
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestOperationLatencies(t *testing.T) {
+	const (
+		backendName = "latencyBackend"
+		scName      = "latencySC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	ctx := context.Background()
+
+	// Every operation is slow with the shortest threshold
+	orchestrator.SetSlowOperationThreshold(time.Nanosecond)
+	if _, err := orchestrator.AddVolume(ctx, generateVolumeConfig("latency", 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	if _, err := orchestrator.AddVolume(ctx, generateVolumeConfig("latency", 1, scName, config.File)); err == nil {
+		t.Fatal("Expected an error adding a volume that exists.")
+	}
+	orchestrator.SetSlowOperationThreshold(0)
+	if _, err := orchestrator.DeleteVolume(ctx, "latency"); err != nil {
+		t.Fatal("Unable to delete volume: ", err)
+	}
+
+	latencies := orchestrator.GetOperationLatencies()
+	if create := latencies[operationCreate]; create == nil || create.Count != 1 || create.Slow != 1 {
+		t.Errorf("Expected one slow create, got %+v.", create)
+	}
+	if remove := latencies[operationDelete]; remove == nil || remove.Count != 1 || remove.Slow != 0 {
+		t.Errorf("Expected one delete that wasn't slow, got %+v.", remove)
+	}
+	if _, ok := latencies[operationClone]; ok {
+		t.Error("Expected no clone latencies.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestUpdateStorageClass(t *testing.T) {
+	const (
+		backendName = "scUpdateBackend"
+		scName      = "scUpdate"
+		alias       = "scUpdateAlias"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	ctx := context.Background()
+
+	if _, err := orchestrator.AddVolume(ctx, generateVolumeConfig("scUpdateVolume", 1, scName,
+		config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	// Requiring SSDs leaves the backend's only pool, and the volume on it, behind
+	update, err := orchestrator.UpdateStorageClass(&storageclass.Config{
+		Name: scName,
+		Attributes: map[string]sa.Request{
+			sa.Media:            sa.NewStringRequest("ssd"),
+			sa.TestingAttribute: sa.NewBoolRequest(true),
+		},
+	})
+	if err != nil {
+		t.Fatal("Unable to update storage class: ", err)
+	}
+	if len(update.AddedPools) != 0 || !reflect.DeepEqual(update.RemovedPools,
+		map[string][]string{backendName: {"primary"}}) {
+		t.Errorf("Unexpected pool changes: added %v, removed %v.", update.AddedPools, update.RemovedPools)
+	}
+	if !reflect.DeepEqual(update.StrandedVolumes, []string{"scUpdateVolume"}) {
+		t.Errorf("Unexpected stranded volumes %v.", update.StrandedVolumes)
+	}
+	if update.StorageClass.Config.Revision != 1 || len(update.StorageClass.StoragePools) != 0 {
+		t.Errorf("Unexpected updated storage class %+v.", update.StorageClass)
+	}
+	if pool := orchestrator.backends[backendName].Storage["primary"]; len(pool.StorageClasses) != 0 {
+		t.Errorf("Expected the pool to have no storage classes, got %v.", pool.StorageClasses)
+	}
+
+	// Restoring the attributes regains the pool, and adds an alias
+	update, err = orchestrator.UpdateStorageClass(&storageclass.Config{
+		Name:    scName,
+		Aliases: []string{alias},
+		Attributes: map[string]sa.Request{
+			sa.Media:            sa.NewStringRequest("hdd"),
+			sa.TestingAttribute: sa.NewBoolRequest(true),
+		},
+	})
+	if err != nil {
+		t.Fatal("Unable to update storage class: ", err)
+	}
+	if !reflect.DeepEqual(update.AddedPools, map[string][]string{backendName: {"primary"}}) ||
+		len(update.RemovedPools) != 0 || len(update.StrandedVolumes) != 0 {
+		t.Errorf("Unexpected update %+v.", update)
+	}
+	if update.StorageClass.Config.Revision != 2 {
+		t.Errorf("Expected revision 2, got %d.", update.StorageClass.Config.Revision)
+	}
+
+	// The alias refers to the class, both when retrieving it and when creating volumes
+	if sc := orchestrator.GetStorageClass(alias); sc == nil || sc.GetName() != scName {
+		t.Errorf("Expected the alias to resolve to %s, got %v.", scName, sc)
+	}
+	vol, err := orchestrator.AddVolume(ctx, generateVolumeConfig("scAliasVolume", 1, alias, config.File))
+	if err != nil {
+		t.Fatal("Unable to add volume by storage class alias: ", err)
+	}
+	if vol.Config.StorageClass != scName {
+		t.Errorf("Expected the volume's storage class to be %s, got %s.", scName, vol.Config.StorageClass)
+	}
+
+	// Names and aliases may refer to only one class
+	if _, err = orchestrator.AddStorageClass(&storageclass.Config{Name: alias}); err == nil {
+		t.Error("Expected an error adding a storage class named for another's alias.")
+	}
+	if _, err = orchestrator.AddStorageClass(&storageclass.Config{
+		Name: "scUpdateOther", Aliases: []string{scName}}); err == nil {
+		t.Error("Expected an error adding a storage class aliased to another's name.")
+	}
+	if _, err = orchestrator.UpdateStorageClass(&storageclass.Config{Name: "scUpdateMissing"}); err == nil {
+		t.Error("Expected an error updating a storage class that doesn't exist.")
+	}
+
+	// The update survives a restart
+	newOrchestrator := getOrchestrator()
+	if sc := newOrchestrator.GetStorageClass(alias); sc == nil || sc.Config.Revision != 2 {
+		t.Errorf("Expected the updated storage class after bootstrapping, got %v.", sc)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestUpdateStorageClassSnapshotDir(t *testing.T) {
+	const (
+		backendName = "snapshotDirBackend"
+		scName      = "snapshotDirClass"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	ctx := context.Background()
+
+	// The volume follows its storage class, while the override sets its own snapshot directory
+	volConfig := generateVolumeConfig("snapshotDirVolume", 1, scName, config.File)
+	volConfig.SnapshotDir = ""
+	vol, err := orchestrator.AddVolume(ctx, volConfig)
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	overrideConfig := generateVolumeConfig("snapshotDirOverride", 1, scName, config.File)
+	overrideConfig.SnapshotDir = "false"
+	override, err := orchestrator.AddVolume(ctx, overrideConfig)
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	attributes := map[string]sa.Request{
+		sa.Media:            sa.NewStringRequest("hdd"),
+		sa.ProvisioningType: sa.NewStringRequest("thick"),
+		sa.TestingAttribute: sa.NewBoolRequest(true),
+		sa.SnapshotDir:      sa.NewBoolRequest(true),
+	}
+	update, err := orchestrator.UpdateStorageClass(&storageclass.Config{Name: scName, Attributes: attributes})
+	if err != nil {
+		t.Fatal("Unable to update storage class: ", err)
+	}
+	if !reflect.DeepEqual(update.UpdatedVolumes, []string{"snapshotDirVolume"}) || len(update.FailedVolumes) != 0 {
+		t.Errorf("Expected only the volume without its own setting to be updated, got %v and %v.",
+			update.UpdatedVolumes, update.FailedVolumes)
+	}
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	if !driver.Volumes[vol.Config.InternalName].SnapshotDirVisible {
+		t.Error("Expected the volume's snapshot directory to be shown.")
+	}
+	if driver.Volumes[override.Config.InternalName].SnapshotDirVisible {
+		t.Error("Expected the volume that sets its own snapshot directory to be left alone.")
+	}
+
+	// An update that leaves the setting as it was doesn't touch the volumes
+	if update, err = orchestrator.UpdateStorageClass(&storageclass.Config{Name: scName,
+		Attributes: attributes}); err != nil {
+		t.Fatal("Unable to update storage class: ", err)
+	}
+	if len(update.UpdatedVolumes) != 0 {
+		t.Errorf("Expected no volumes to be updated, got %v.", update.UpdatedVolumes)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestStorageClassAllowedNamespaces(t *testing.T) {
+	const (
+		backendName = "namespaceBackend"
+		scName      = "namespaceSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackend(t, orchestrator, backendName)
+	if _, err := orchestrator.AddStorageClass(&storageclass.Config{
+		Name:              scName,
+		AllowedNamespaces: []string{"gold-*"},
+		Attributes: map[string]sa.Request{
+			sa.Media:            sa.NewStringRequest("hdd"),
+			sa.TestingAttribute: sa.NewBoolRequest(true),
+		},
+	}); err != nil {
+		t.Fatal("Unable to add storage class: ", err)
+	}
+	if _, err := orchestrator.AddStorageClass(&storageclass.Config{
+		Name: "badNamespaceSC", AllowedNamespaces: []string{"gold-["}}); err == nil {
+		t.Error("Expected an error adding a storage class with an invalid namespace pattern.")
+	}
+	ctx := context.Background()
+
+	allowed := generateVolumeConfig("namespaceAllowed", 1, scName, config.File)
+	allowed.Namespace = "gold-tenant"
+	if _, err := orchestrator.AddVolume(ctx, allowed); err != nil {
+		t.Fatal("Unable to add volume from an allowed namespace: ", err)
+	}
+
+	denied := generateVolumeConfig("namespaceDenied", 1, scName, config.File)
+	denied.Namespace = "bronze-tenant"
+	if _, err := orchestrator.AddVolume(ctx, denied); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error adding a volume from another namespace, got %v.", err)
+	}
+	if _, err := orchestrator.PreviewVolumePlacement(denied); err == nil {
+		t.Error("Expected an error previewing a volume from another namespace.")
+	}
+
+	// Clones take the source's class, so the same namespaces apply
+	clone := generateVolumeConfig("namespaceClone", 1, "", config.File)
+	clone.CloneSourceVolume = "namespaceAllowed"
+	clone.Namespace = "bronze-tenant"
+	if _, err := orchestrator.CloneVolume(ctx, clone); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error cloning a volume from another namespace, got %v.", err)
+	}
+	if orchestrator.GetVolume("namespaceDenied") != nil || orchestrator.GetVolume("namespaceClone") != nil {
+		t.Error("Expected no volumes to be created from another namespace.")
+	}
+
+	// Requests from outside any namespace are unaffected
+	if _, err := orchestrator.AddVolume(ctx, generateVolumeConfig("namespaceNone", 1, scName,
+		config.File)); err != nil {
+		t.Error("Unable to add volume from outside any namespace: ", err)
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestUsageReport(t *testing.T) {
+	const (
+		backendName = "usageBackend"
+		scName      = "usageSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	volConfig := generateVolumeConfig("usageVolume", 1, scName, config.File)
+	volConfig.Namespace = "tenant"
+	if _, err := orchestrator.AddVolume(context.Background(), volConfig); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	start := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, time.Hour} {
+		record, err := orchestrator.recordUsage(start.Add(offset))
+		if err != nil {
+			t.Fatal("Unable to record usage: ", err)
+		}
+		if len(record.Samples) != 1 || record.Samples[0].UsedBytes == nil ||
+			record.Samples[0].ProvisionedBytes != 1<<30 {
+			t.Fatalf("Unexpected usage samples %+v.", record.Samples)
+		}
+	}
+
+	// The last record counts for one sampling interval, and the period ends before the next
+	report, err := orchestrator.GetUsageReport(start, start.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal("Unable to get usage report: ", err)
+	}
+	if report.Records != 2 || report.Hours != 1.5 || len(report.Entries) != 1 {
+		t.Fatalf("Unexpected usage report %+v.", report)
+	}
+	entry := report.Entries[0]
+	if entry.Namespace != "tenant" || entry.StorageClass != scName || entry.Backend != backendName ||
+		entry.Volumes != 1 || entry.ProvisionedGiBHours != 1.5 || entry.AverageProvisionedBytes != 1<<30 ||
+		entry.PeakProvisionedBytes != 1<<30 || !entry.UsedMeasured {
+		t.Errorf("Unexpected usage report entry %+v.", entry)
+	}
+	if report, err = orchestrator.GetUsageReport(start.Add(2*time.Hour), start.Add(3*time.Hour)); err != nil {
+		t.Error("Unable to get usage report: ", err)
+	} else if report.Records != 0 || report.Hours != 0 || len(report.Entries) != 0 {
+		t.Errorf("Expected an empty usage report for a period without samples, got %+v.", report)
+	}
+	if _, err = orchestrator.GetUsageReport(start.Add(time.Hour), start); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error for a usage report that ends before it starts, got %v.", err)
+	}
+
+	// Samples older than the retention period are deleted when the next are recorded
+	orchestrator.usageRetention = time.Hour
+	if _, err = orchestrator.recordUsage(start.Add(3 * time.Hour)); err != nil {
+		t.Fatal("Unable to record usage: ", err)
+	}
+	records, err := orchestrator.storeClient.GetUsageRecords()
+	if err != nil {
+		t.Fatal("Unable to get usage records: ", err)
+	}
+	if len(records) != 1 || !records[0].Time.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("Expected only the latest usage record to be kept, got %d.", len(records))
+	}
+	for _, record := range records {
+		if err = orchestrator.storeClient.DeleteUsageRecord(record); err != nil {
+			t.Error("Unable to delete usage record: ", err)
+		}
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestResyncVolumes(t *testing.T) {
+	const (
+		backendName = "resyncBackend"
+		scName      = "resyncSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	for _, name := range []string{"resyncResized", "resyncDeleted", "resyncUnstored", "resyncUnchanged"} {
+		if _, err := orchestrator.AddVolume(context.Background(),
+			generateVolumeConfig(name, 1, scName, config.File)); err != nil {
+			t.Fatalf("Unable to add volume %s: %v", name, err)
+		}
+	}
+	if changes := orchestrator.ResyncVolumes(); len(changes) != 0 {
+		t.Errorf("Expected no changes before any drift, got %d.", len(changes))
+	}
+
+	// Resize and delete volumes behind Trident's back, and lose one from the store
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	resizedName := orchestrator.volumes["resyncResized"].Config.InternalName
+	resized := driver.Volumes[resizedName]
+	resized.SizeBytes = 2 << 30
+	driver.Volumes[resizedName] = resized
+	deletedName := orchestrator.volumes["resyncDeleted"].Config.InternalName
+	deleted := driver.Volumes[deletedName]
+	delete(driver.Volumes, deletedName)
+	if err := orchestrator.storeClient.DeleteVolume(orchestrator.volumes["resyncUnstored"]); err != nil {
+		t.Fatal("Unable to delete volume from the store: ", err)
+	}
+
+	changes := orchestrator.ResyncVolumes()
+	expected := map[string]string{
+		"resyncResized":  storage.ResyncResized,
+		"resyncDeleted":  storage.ResyncMissing,
+		"resyncUnstored": storage.ResyncStored,
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d.", len(expected), len(changes))
+	}
+	for _, change := range changes {
+		if expected[change.Volume] != change.Change {
+			t.Errorf("Unexpected change %+v.", change)
+		}
+	}
+	if size := orchestrator.GetVolume("resyncResized").Config.Size; size != fmt.Sprintf("%d", 2<<30) {
+		t.Errorf("Expected the resized volume's size to be updated, got %s.", size)
+	}
+	if !orchestrator.GetVolume("resyncDeleted").Orphaned {
+		t.Error("Expected the deleted volume to be orphaned.")
+	}
+	storedVolumes, err := orchestrator.storeClient.GetVolumes()
+	if err != nil {
+		t.Fatal("Unable to get volumes from the store: ", err)
+	}
+	for _, vol := range storedVolumes {
+		if vol.Config.Name == "resyncResized" && vol.Config.Size != fmt.Sprintf("%d", 2<<30) ||
+			vol.Config.Name == "resyncDeleted" && !vol.Orphaned {
+			t.Errorf("Expected the store to be corrected, got %+v.", vol)
+		}
+	}
+	if len(storedVolumes) != 4 {
+		t.Errorf("Expected 4 volumes in the store, got %d.", len(storedVolumes))
+	}
+
+	// A volume that reappears is no longer orphaned
+	driver.Volumes[deletedName] = deleted
+	changes = orchestrator.ResyncVolumes()
+	if len(changes) != 1 || changes[0].Change != storage.ResyncRestored {
+		t.Errorf("Expected the deleted volume to be restored, got %d changes.", len(changes))
+	}
+	if orchestrator.GetVolume("resyncDeleted").Orphaned {
+		t.Error("Expected the restored volume not to be orphaned.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestReadOnlyVolumes(t *testing.T) {
+	const (
+		backendName = "readOnlyBackend"
+		scName      = "readOnlySC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	ctx := context.Background()
+
+	readOnlyConfig := generateVolumeConfig("readOnlySource", 1, scName, config.File)
+	readOnlyConfig.ReadOnly = true
+	vol, err := orchestrator.AddVolume(ctx, readOnlyConfig)
+	if err != nil {
+		t.Fatal("Unable to add read-only volume: ", err)
+	}
+	if !vol.Config.ReadOnly || !driver.Volumes[vol.Config.InternalName].ReadOnly {
+		t.Error("Expected the volume to be read-only on its backend.")
+	}
+
+	// Clones are read-only only if requested, whatever their source
+	for _, readOnly := range []bool{false, true} {
+		cloneConfig := generateVolumeConfig(fmt.Sprintf("readOnlyClone-%t", readOnly), 1, "", config.File)
+		cloneConfig.CloneSourceVolume = "readOnlySource"
+		cloneConfig.ReadOnly = readOnly
+		clone, err := orchestrator.CloneVolume(ctx, cloneConfig)
+		if err != nil {
+			t.Fatal("Unable to clone read-only volume: ", err)
+		}
+		if clone.Config.ReadOnly != readOnly || driver.Volumes[clone.Config.InternalName].ReadOnly != readOnly {
+			t.Errorf("Expected a clone with readOnly %t to be read-only %t on its backend.", readOnly, readOnly)
+		}
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestCloneVolumeFanout(t *testing.T) {
+	const (
+		backendName = "fanoutBackend"
+		scName      = "fanoutSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	ctx := context.Background()
+
+	source, err := orchestrator.AddVolume(ctx, generateVolumeConfig("fanoutSource", 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add source volume: ", err)
+	}
+
+	for _, fanout := range []*storage.CloneFanoutConfig{
+		{SourceVolume: "missing", NamePrefix: "ci", Count: 1},
+		{SourceVolume: "fanoutSource", Count: 1},
+		{SourceVolume: "fanoutSource", NamePrefix: "ci", Count: 0},
+		{SourceVolume: "fanoutSource", NamePrefix: "ci", Count: config.MaxBulkVolumes + 1},
+	} {
+		if _, err = orchestrator.CloneVolumes(ctx, fanout); err == nil {
+			t.Errorf("Expected fan-out %+v to fail.", fanout)
+		}
+	}
+
+	// An existing volume named like a clone fails only that clone
+	if _, err = orchestrator.AddVolume(ctx, generateVolumeConfig("ci-2", 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+	result, err := orchestrator.CloneVolumes(ctx, &storage.CloneFanoutConfig{
+		SourceVolume: "fanoutSource",
+		NamePrefix:   "ci",
+		Count:        3,
+	})
+	if err != nil {
+		t.Fatal("Unable to clone volumes: ", err)
+	}
+	snapshots := driver.Snapshots[source.Config.InternalName]
+	if len(snapshots) != 1 || result.Snapshot != snapshots[0].Name {
+		t.Errorf("Expected one snapshot of the source for all clones, got %v and %s.", snapshots, result.Snapshot)
+	}
+	if len(result.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d.", len(result.Results))
+	}
+	for i, cloneResult := range result.Results {
+		name := fmt.Sprintf("ci-%d", i+1)
+		if cloneResult.Volume != name {
+			t.Errorf("Expected result %d for %s, got %s.", i, name, cloneResult.Volume)
+		}
+		if failed := cloneResult.Error != ""; failed != (name == "ci-2") {
+			t.Errorf("Unexpected result %+v.", cloneResult)
+		} else if !failed {
+			vol := orchestrator.GetVolume(name)
+			if vol == nil || vol.Config.CloneSourceSnapshot != result.Snapshot || cloneResult.Backend != backendName {
+				t.Errorf("Expected %s to be cloned from snapshot %s on %s, got %+v.", name, result.Snapshot,
+					backendName, vol)
+			}
+		}
+	}
+
+	// A named snapshot is used as is
+	result, err = orchestrator.CloneVolumes(ctx, &storage.CloneFanoutConfig{
+		SourceVolume:   "fanoutSource",
+		SourceSnapshot: result.Snapshot,
+		NamePrefix:     "ci-again",
+		Count:          1,
+	})
+	if err != nil || result.Results[0].Error != "" {
+		t.Fatalf("Unable to clone volumes from a snapshot: %v %+v", err, result)
+	}
+	if len(driver.Snapshots[source.Config.InternalName]) != 1 {
+		t.Error("Expected no snapshot to be taken when one is named.")
+	}
+	cleanup(t, orchestrator)
+}
+
+func TestCacheVolumes(t *testing.T) {
+	const (
+		backendName = "cacheBackend"
+		scName      = "cacheSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	driver := orchestrator.backends[backendName].Driver.(*fakedriver.StorageDriver)
+	ctx := context.Background()
+
+	origin, err := orchestrator.AddVolume(ctx, generateVolumeConfig("cacheOrigin", 1, scName, config.File))
+	if err != nil {
+		t.Fatal("Unable to add origin volume: ", err)
+	}
+
+	// A cache takes its storage class and size from its origin unless given them
+	cacheConfig := generateVolumeConfig("cache", 0, "", "")
+	cacheConfig.CacheSourceVolume = "cacheOrigin"
+	cache, err := orchestrator.AddVolume(ctx, cacheConfig)
+	if err != nil {
+		t.Fatal("Unable to add cache: ", err)
+	}
+	if cache.Config.StorageClass != scName || cache.Config.Size != origin.Config.Size ||
+		cache.Config.CacheSourceVolumeInternal != origin.Config.InternalName {
+		t.Errorf("Expected the cache to take its origin's config, got %+v.", cache.Config)
+	}
+	if fakeCache, ok := driver.Volumes[cache.Config.InternalName]; !ok ||
+		fakeCache.CacheOrigin != origin.Config.InternalName {
+		t.Errorf("Expected a cache of %s on the backend, got %+v.", origin.Config.InternalName, fakeCache)
+	}
+
+	for name, volConfig := range map[string]*storage.VolumeConfig{
+		"missing origin": {Name: "badCache1", CacheSourceVolume: "missing"},
+		"cache of cache": {Name: "badCache2", CacheSourceVolume: "cache"},
+		"warm pool":      {Name: "badCache3", CacheSourceVolume: "cacheOrigin", WarmPool: true},
+	} {
+		if _, err = orchestrator.AddVolume(ctx, volConfig); err == nil {
+			t.Errorf("Expected a cache with %s to fail.", name)
+		}
+	}
+
+	cloneConfig := generateVolumeConfig("cacheClone", 1, "", config.File)
+	cloneConfig.CloneSourceVolume = "cache"
+	if _, err = orchestrator.CloneVolume(ctx, cloneConfig); err == nil {
+		t.Error("Expected cloning a cache to fail.")
+	}
+
+	// The origin may be deleted only once its caches are
+	if _, err = orchestrator.DeleteVolume(ctx, "cacheOrigin"); err == nil {
+		t.Error("Expected deleting a cached volume to fail.")
+	}
+	if _, err = orchestrator.DeleteVolume(ctx, "cache"); err != nil {
+		t.Fatal("Unable to delete cache: ", err)
+	}
+	if _, ok := driver.Volumes[cache.Config.InternalName]; ok {
+		t.Error("Expected the cache to be destroyed on the backend.")
+	}
+	if _, err = orchestrator.DeleteVolume(ctx, "cacheOrigin"); err != nil {
+		t.Error("Unable to delete origin volume: ", err)
+	}
+	cleanup(t, orchestrator)
+}
+
+// fakeMounter is a frontend that mounts volumes, recording those it is forced to detach.
+type fakeMounter struct {
+	detached []string
+}
+
+func (f *fakeMounter) Activate() error   { return nil }
+func (f *fakeMounter) Deactivate() error { return nil }
+func (f *fakeMounter) GetName() string   { return "fakeMounter" }
+func (f *fakeMounter) Version() string   { return "1" }
+
+func (f *fakeMounter) ForceDetachVolume(volumeName string) ([]string, error) {
+	f.detached = append(f.detached, volumeName)
+	return []string{"mount1"}, nil
+}
+
+func TestForceDetachVolume(t *testing.T) {
+	orchestrator := getOrchestrator()
+	orchestrator.volumes["vol1"] = storage.NewVolume(&storage.VolumeConfig{Name: "vol1"}, "backend1", "pool1", false)
+
+	if _, err := orchestrator.ForceDetachVolume("vol1"); !drivers.IsUnsupportedError(err) {
+		t.Errorf("Expected an unsupported error without a frontend that mounts volumes, got %v", err)
+	}
+
+	mounter := &fakeMounter{}
+	orchestrator.AddFrontend(mounter)
+	released, err := orchestrator.ForceDetachVolume("vol1")
+	if err != nil {
+		t.Fatalf("Unable to force volume to be detached: %v", err)
+	}
+	if !reflect.DeepEqual(released, []string{"mount1"}) || !reflect.DeepEqual(mounter.detached, []string{"vol1"}) {
+		t.Errorf("Expected vol1 to be detached, releasing mount1; got %v and %v", mounter.detached, released)
+	}
+
+	if _, err = orchestrator.ForceDetachVolume("missing"); err == nil {
+		t.Error("Expected forcing a missing volume to be detached to fail")
+	}
+}
+
+func TestLease(t *testing.T) {
+	const duration = 300 * time.Millisecond
+
+	storeClient := persistentstore.NewInMemoryClient()
+	newOrchestrator := func() *TridentOrchestrator {
+		o := NewTridentOrchestrator(storeClient)
+		o.SetLeaseDuration(duration)
+		return o
+	}
+
+	first := newOrchestrator()
+	if err := first.Bootstrap(); err != nil {
+		t.Fatal("Unable to bootstrap the first instance: ", err)
+	}
+
+	// Another instance sees the lease being renewed and refuses to start
+	if err := newOrchestrator().Bootstrap(); err == nil {
+		t.Error("Expected a second instance to refuse to start while the lease is held.")
+	}
+
+	// Stopping releases the lease, so that the next instance starts at once
+	first.Stop()
+	if _, err := storeClient.GetLease(); !persistentstore.MatchKeyNotFoundErr(err) {
+		t.Errorf("Expected the lease to be released, got %v.", err)
+	}
+	second := newOrchestrator()
+	if err := second.Bootstrap(); err != nil {
+		t.Fatal("Unable to bootstrap after the lease was released: ", err)
+	}
+
+	// A lease that is no longer renewed is taken over
+	second.housekeeping.Stop()
+	third := newOrchestrator()
+	if err := third.Bootstrap(); err != nil {
+		t.Fatal("Unable to bootstrap after the lease lapsed: ", err)
+	}
+	if lease, err := storeClient.GetLease(); err != nil || lease.Owner != third.instanceID {
+		t.Errorf("Expected the lease to be taken over, got %v.", lease)
+	}
+
+	// An instance whose lease is taken by another is told to stop
+	var lease *persistentstore.Lease
+	for {
+		lease, _ = storeClient.GetLease()
+		other := *lease
+		other.Owner = "other"
+		other.Renewed = time.Now()
+		// The instance may renew the lease between reading and writing it
+		if err := storeClient.SwapLease(lease, &other); err == nil {
+			break
+		} else if !persistentstore.MatchKeyChangedErr(err) {
+			t.Fatal("Unable to take over the lease: ", err)
+		}
+	}
+
+	// A lease is only replaced if it is unchanged since it was read
+	stale := *lease
+	stale.Renewed = time.Now()
+	if err := storeClient.SwapLease(lease, &stale); !persistentstore.MatchKeyChangedErr(err) {
+		t.Errorf("Expected a stale lease not to be written, got %v.", err)
+	}
+	select {
+	case <-third.LeaseLost():
+	case <-time.After(5 * duration):
+		t.Error("Expected the lease to be lost to another instance.")
+	}
+	third.Stop()
+	if lease, err := storeClient.GetLease(); err != nil || lease.Owner != "other" {
+		t.Errorf("Expected the other instance's lease to be kept, got %v.", lease)
+	}
+}
+
+func TestCloneVolumeUnsupported(t *testing.T) {
+	const (
+		backendName = "noCloneBackend"
+		scName      = "noCloneSC"
+	)
+	orchestrator := getOrchestrator()
+	addBackendStorageClass(t, orchestrator, backendName, scName)
+	backend := orchestrator.backends[backendName]
+	ctx := context.Background()
+
+	if _, err := orchestrator.AddVolume(ctx, generateVolumeConfig("noCloneSource", 1, scName, config.File)); err != nil {
+		t.Fatal("Unable to add source volume: ", err)
+	}
+
+	// A backend whose pools advertise no cloning fails the clone before its driver is asked
+	for _, pool := range backend.Storage {
+		pool.Attributes[sa.Clones] = sa.NewBoolOffer(false)
+	}
+	cloneConfig := generateVolumeConfig("noCloneClone", 1, scName, config.File)
+	cloneConfig.CloneSourceVolume = "noCloneSource"
+	if _, err := orchestrator.CloneVolume(ctx, cloneConfig); !drivers.IsUnsupportedError(err) {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+	if orchestrator.GetVolume("noCloneClone") != nil {
+		t.Error("Expected no clone to be created")
+	}
+
+	// One that advertises cloning is asked to clone
+	for _, pool := range backend.Storage {
+		pool.Attributes[sa.Clones] = sa.NewBoolOffer(true)
+	}
+	if _, err := orchestrator.CloneVolume(ctx, cloneConfig); err != nil {
+		t.Errorf("Unable to clone volume: %v", err)
+	}
 	cleanup(t, orchestrator)
 }
@@ -3,6 +3,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -15,10 +16,12 @@ import (
 
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/frontend"
+	"github.com/netapp/trident/persistent_store"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage_class"
 	drivers "github.com/netapp/trident/storage_drivers"
 	"github.com/netapp/trident/storage_drivers/ontap"
+	"github.com/netapp/trident/utils"
 )
 
 type mockBackend struct {
@@ -51,6 +54,10 @@ type MockOrchestrator struct {
 	storageClasses map[string]*storageclass.StorageClass
 	volumes        map[string]*storage.Volume
 	mutex          *sync.Mutex
+
+	snapshotSchedules map[string]*storage.SnapshotSchedule
+	volumeGroups      map[string]*storage.VolumeGroup
+	migrations        map[string]*storage.VolumeMigration
 }
 
 func (m *MockOrchestrator) Bootstrap() error {
@@ -128,6 +135,52 @@ func (m *MockOrchestrator) GetBackend(backend string) *storage.BackendExternal {
 	return b.ConstructExternal()
 }
 
+func (m *MockOrchestrator) UpdateBackend(backend, configJSON string) (*storage.BackendExternal, error) {
+	// Implement this if it becomes necessary to test.
+	return nil, nil
+}
+
+func (m *MockOrchestrator) RenameBackend(backend, newName string) (*storage.BackendExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, found := m.backends[backend]
+	if !found {
+		return nil, fmt.Errorf("backend %s not found", backend)
+	}
+	if _, found = m.backends[newName]; found {
+		return nil, fmt.Errorf("backend %s already exists", newName)
+	}
+	delete(m.backends, backend)
+	b.Name = newName
+	m.backends[newName] = b
+	m.mockBackends[newName] = m.mockBackends[backend]
+	delete(m.mockBackends, backend)
+	return b.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) GetBackendWithCapacity(backend string) *storage.BackendExternal {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, found := m.backends[backend]
+	if !found {
+		return nil
+	}
+	return b.ConstructExternalWithCapacity()
+}
+
+func (m *MockOrchestrator) GetBackendWithConfigDetail(backend string) (*storage.BackendExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, found := m.backends[backend]
+	if !found {
+		return nil, fmt.Errorf("backend %s not found", backend)
+	}
+	return b.ConstructExternalWithConfigDetail()
+}
+
 func (m *MockOrchestrator) ListBackends() []*storage.BackendExternal {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -143,7 +196,28 @@ func (m *MockOrchestrator) OfflineBackend(backend string) (bool, error) {
 	return false, nil
 }
 
-func (m *MockOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error) {
+func (m *MockOrchestrator) CordonBackend(backend string, cordoned bool) (*storage.BackendExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, found := m.backends[backend]
+	if !found {
+		return nil, fmt.Errorf("backend %s not found", backend)
+	}
+	b.Cordoned = cordoned
+	return b.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) UpdateBackendDebugTraceFlags(
+	backend string, changes map[string]bool,
+) (map[string]bool, error) {
+	// Implement this if it becomes necessary to test.
+	return nil, nil
+}
+
+func (m *MockOrchestrator) AddVolume(
+	ctx context.Context, volumeConfig *storage.VolumeConfig,
+) (*storage.VolumeExternal, error) {
 	var mockBackends map[string]*mockBackend
 
 	// Don't bother with actually getting the backends from the storage class;
@@ -200,11 +274,55 @@ func (m *MockOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (*stora
 	return volume.ConstructExternal(), nil
 }
 
-func (m *MockOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error) {
+func (m *MockOrchestrator) CloneVolume(
+	ctx context.Context, volumeConfig *storage.VolumeConfig,
+) (*storage.VolumeExternal, error) {
 	// TODO: write this method to enable CloneVolume unit tests
 	return nil, nil
 }
 
+func (m *MockOrchestrator) AddVolumes(
+	ctx context.Context, volumeConfigs []*storage.VolumeConfig,
+) []*storage.BulkVolumeResult {
+	results := make([]*storage.BulkVolumeResult, 0, len(volumeConfigs))
+	for _, volumeConfig := range volumeConfigs {
+		result := &storage.BulkVolumeResult{Volume: volumeConfig.Name}
+		if vol, err := m.AddVolume(ctx, volumeConfig); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Backend = vol.Backend
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (m *MockOrchestrator) DeleteVolumes(ctx context.Context, volumeNames []string) []*storage.BulkVolumeResult {
+	results := make([]*storage.BulkVolumeResult, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		result := &storage.BulkVolumeResult{Volume: volumeName}
+		if _, err := m.DeleteVolume(ctx, volumeName); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (m *MockOrchestrator) CloneVolumes(
+	ctx context.Context, fanout *storage.CloneFanoutConfig,
+) (*storage.CloneFanoutResult, error) {
+	// Implement this if it becomes necessary to test.
+	return nil, nil
+}
+
+func (m *MockOrchestrator) PreviewVolumePlacement(
+	volumeConfig *storage.VolumeConfig,
+) (*storage.PlacementPreview, error) {
+	// Implement this if it becomes necessary to test.
+	return nil, nil
+}
+
 func (m *MockOrchestrator) ValidateVolumes(
 	t *testing.T,
 	expectedConfigs []*storage.VolumeConfig,
@@ -237,6 +355,49 @@ func (m *MockOrchestrator) GetVolume(volume string) *storage.VolumeExternal {
 	return vol.ConstructExternal()
 }
 
+func (m *MockOrchestrator) GetVolumeByInternalName(backend, internalName string) *storage.VolumeExternal {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, vol := range m.volumes {
+		if vol.Backend == backend && vol.Config.InternalName == internalName {
+			return vol.ConstructExternal()
+		}
+	}
+	return nil
+}
+
+func (m *MockOrchestrator) UpdateVolumeQoS(volume, qos, qosType string) (*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	vol, found := m.volumes[volume]
+	if !found {
+		return nil, fmt.Errorf("volume %s not found", volume)
+	}
+	vol.Config.QoS = qos
+	vol.Config.QoSType = qosType
+	return vol.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) GetVolumeStats(
+	volume string, interval time.Duration,
+) (*storage.VolumeStatsReport, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	vol, found := m.volumes[volume]
+	if !found {
+		return nil, fmt.Errorf("volume %s not found", volume)
+	}
+	return &storage.VolumeStatsReport{
+		Volume:  vol.Config.Name,
+		Backend: vol.Backend,
+		Stats:   &storage.VolumeStats{Time: time.Now()},
+	}, nil
+}
+
+func (m *MockOrchestrator) ListVolumeStats(interval time.Duration) ([]*storage.VolumeStatsReport, error) {
+	return make([]*storage.VolumeStatsReport, 0), nil
+}
+
 // Copied verbatim from TridentOrchestrator
 func (m *MockOrchestrator) GetDriverTypeForVolume(
 	vol *storage.VolumeExternal,
@@ -245,7 +406,7 @@ func (m *MockOrchestrator) GetDriverTypeForVolume(
 	defer m.mutex.Unlock()
 
 	if b, ok := m.backends[vol.Backend]; ok {
-		return b.Driver.Name()
+		return b.Guarded().Name()
 	}
 	return config.UnknownDriver
 }
@@ -265,6 +426,10 @@ func (m *MockOrchestrator) GetVolumeType(vol *storage.VolumeExternal) config.Vol
 		return config.SolidFireISCSI
 	case driver == drivers.EseriesIscsiStorageDriverName:
 		return config.ESeriesISCSI
+	case driver == drivers.GCPNFSStorageDriverName:
+		return config.GCPNFS
+	case driver == drivers.GenericNFSStorageDriverName:
+		return config.GenericNFS
 	default:
 		return config.UnknownVolumeType
 	}
@@ -280,7 +445,7 @@ func (m *MockOrchestrator) ListVolumes() []*storage.VolumeExternal {
 	return volumes
 }
 
-func (m *MockOrchestrator) DeleteVolume(volumeName string) (found bool, err error) {
+func (m *MockOrchestrator) DeleteVolume(ctx context.Context, volumeName string) (found bool, err error) {
 
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -310,6 +475,13 @@ func (m *MockOrchestrator) DetachVolume(volumeName, mountpoint string) error {
 	return nil
 }
 
+func (m *MockOrchestrator) ForceDetachVolume(volumeName string) ([]string, error) {
+	if _, ok := m.volumes[volumeName]; !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	return make([]string, 0), nil
+}
+
 func (m *MockOrchestrator) ListVolumeSnapshots(volumeName string) ([]*storage.SnapshotExternal, error) {
 	return make([]*storage.SnapshotExternal, 0), nil
 }
@@ -318,6 +490,49 @@ func (m *MockOrchestrator) ReloadVolumes() error {
 	return nil
 }
 
+func (m *MockOrchestrator) ReconcileBackends(cleanup bool) (*storage.ReconciliationReport, error) {
+	return &storage.ReconciliationReport{
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		Cleanup:  cleanup,
+		Backends: make([]*storage.BackendReconciliation, 0),
+	}, nil
+}
+
+func (m *MockOrchestrator) GetReconciliationReport() *storage.ReconciliationReport {
+	return nil
+}
+
+func (m *MockOrchestrator) GetOperationLatencies() map[string]*utils.LatencyHistogram {
+	return make(map[string]*utils.LatencyHistogram)
+}
+
+func (m *MockOrchestrator) GetUsageReport(start, end time.Time) (*storage.UsageReport, error) {
+	return &storage.UsageReport{Start: start, End: end, Entries: make([]*storage.UsageReportEntry, 0)}, nil
+}
+
+func (m *MockOrchestrator) GetVolumeUsedBytes(volumeName string) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, found := m.volumes[volumeName]; !found {
+		return 0, fmt.Errorf("volume %s not found", volumeName)
+	}
+	return 0, nil
+}
+
+func (m *MockOrchestrator) CheckHealth(includeBackends bool) *HealthReport {
+	report := &HealthReport{
+		Bootstrapped: true,
+		Store:        StoreHealth{Type: string(persistentstore.MemoryStore), Connected: true},
+	}
+	if includeBackends {
+		report.Backends = make([]*BackendHealth, 0)
+		for _, backend := range m.backends {
+			report.Backends = append(report.Backends, &BackendHealth{Name: backend.Name, Online: backend.Online})
+		}
+	}
+	return report
+}
+
 func NewMockOrchestrator() *MockOrchestrator {
 	return &MockOrchestrator{
 		backends:       make(map[string]*storage.Backend),
@@ -325,6 +540,10 @@ func NewMockOrchestrator() *MockOrchestrator {
 		storageClasses: make(map[string]*storageclass.StorageClass),
 		volumes:        make(map[string]*storage.Volume),
 		mutex:          &sync.Mutex{},
+
+		snapshotSchedules: make(map[string]*storage.SnapshotSchedule),
+		volumeGroups:      make(map[string]*storage.VolumeGroup),
+		migrations:        make(map[string]*storage.VolumeMigration),
 	}
 }
 
@@ -336,6 +555,23 @@ func (m *MockOrchestrator) AddStorageClass(
 	return sc.ConstructExternal(), nil
 }
 
+func (m *MockOrchestrator) UpdateStorageClass(scConfig *storageclass.Config) (*storageclass.Update, error) {
+	previous, ok := m.storageClasses[scConfig.Name]
+	if !ok {
+		return nil, fmt.Errorf("storage class %s not found", scConfig.Name)
+	}
+	scConfig.Revision = previous.GetRevision() + 1
+	sc := storageclass.New(scConfig)
+	m.storageClasses[sc.GetName()] = sc
+	return &storageclass.Update{
+		StorageClass:    sc.ConstructExternal(),
+		AddedPools:      make(map[string][]string),
+		RemovedPools:    make(map[string][]string),
+		StrandedVolumes: make([]string, 0),
+		UpdatedVolumes:  make([]string, 0),
+	}, nil
+}
+
 func (m *MockOrchestrator) GetStorageClass(scName string) *storageclass.External {
 	if sc, ok := m.storageClasses[scName]; ok {
 		return sc.ConstructExternal()
@@ -359,3 +595,191 @@ func (m *MockOrchestrator) DeleteStorageClass(scName string) (bool, error) {
 	delete(m.storageClasses, scName)
 	return true, nil
 }
+
+func (m *MockOrchestrator) AddSnapshotSchedule(
+	scheduleConfig *storage.SnapshotScheduleConfig,
+) (*storage.SnapshotScheduleExternal, error) {
+	if err := scheduleConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := m.snapshotSchedules[scheduleConfig.Name]; ok {
+		return nil, fmt.Errorf("snapshot schedule %s already exists", scheduleConfig.Name)
+	}
+	schedule := storage.NewSnapshotSchedule(scheduleConfig, time.Now())
+	m.snapshotSchedules[scheduleConfig.Name] = schedule
+	return schedule.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) GetSnapshotSchedule(scheduleName string) *storage.SnapshotScheduleExternal {
+	if schedule, ok := m.snapshotSchedules[scheduleName]; ok {
+		return schedule.ConstructExternal()
+	}
+	return nil
+}
+
+func (m *MockOrchestrator) ListSnapshotSchedules() []*storage.SnapshotScheduleExternal {
+	schedules := make([]*storage.SnapshotScheduleExternal, 0, len(m.snapshotSchedules))
+	for _, schedule := range m.snapshotSchedules {
+		schedules = append(schedules, schedule.ConstructExternal())
+	}
+	return schedules
+}
+
+func (m *MockOrchestrator) DeleteSnapshotSchedule(scheduleName string) (bool, error) {
+	if _, ok := m.snapshotSchedules[scheduleName]; !ok {
+		return false, fmt.Errorf("snapshot schedule %s not found", scheduleName)
+	}
+	delete(m.snapshotSchedules, scheduleName)
+	return true, nil
+}
+
+func (m *MockOrchestrator) AddVolumeGroup(
+	ctx context.Context, groupConfig *storage.VolumeGroupConfig,
+) (*storage.VolumeGroupExternal, error) {
+	if err := groupConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := m.volumeGroups[groupConfig.Name]; ok {
+		return nil, fmt.Errorf("volume group %s already exists", groupConfig.Name)
+	}
+	group := &storage.VolumeGroup{Name: groupConfig.Name, Volumes: make([]string, 0)}
+	for _, volumeConfig := range groupConfig.Volumes {
+		if _, err := m.AddVolume(ctx, volumeConfig); err != nil {
+			return nil, err
+		}
+		group.Volumes = append(group.Volumes, volumeConfig.Name)
+	}
+	m.volumeGroups[group.Name] = group
+	return m.GetVolumeGroup(group.Name), nil
+}
+
+func (m *MockOrchestrator) GetVolumeGroup(groupName string) *storage.VolumeGroupExternal {
+	group, ok := m.volumeGroups[groupName]
+	if !ok {
+		return nil
+	}
+	external := &storage.VolumeGroupExternal{Name: group.Name, Volumes: make([]*storage.VolumeExternal, 0)}
+	for _, volumeName := range group.Volumes {
+		if volume, ok := m.volumes[volumeName]; ok {
+			external.Volumes = append(external.Volumes, volume.ConstructExternal())
+		}
+	}
+	return external
+}
+
+func (m *MockOrchestrator) ListVolumeGroups() []*storage.VolumeGroupExternal {
+	groups := make([]*storage.VolumeGroupExternal, 0, len(m.volumeGroups))
+	for name := range m.volumeGroups {
+		groups = append(groups, m.GetVolumeGroup(name))
+	}
+	return groups
+}
+
+func (m *MockOrchestrator) SnapshotVolumeGroup(groupName, snapshotName string) (*storage.VolumeGroupSnapshot, error) {
+	group, ok := m.volumeGroups[groupName]
+	if !ok {
+		return nil, fmt.Errorf("volume group %s not found", groupName)
+	}
+	result := &storage.VolumeGroupSnapshot{
+		Name:       snapshotName,
+		Consistent: true,
+		Volumes:    make(map[string]*storage.SnapshotExternal),
+	}
+	for _, volumeName := range group.Volumes {
+		result.Volumes[volumeName] = &storage.SnapshotExternal{
+			Snapshot: storage.Snapshot{Name: snapshotName, Created: time.Now().UTC().Format(time.RFC3339)},
+		}
+	}
+	return result, nil
+}
+
+func (m *MockOrchestrator) DeleteVolumeGroup(ctx context.Context, groupName string) (bool, error) {
+	group, ok := m.volumeGroups[groupName]
+	if !ok {
+		return false, fmt.Errorf("volume group %s not found", groupName)
+	}
+	for _, volumeName := range group.Volumes {
+		m.DeleteVolume(ctx, volumeName)
+	}
+	delete(m.volumeGroups, groupName)
+	return true, nil
+}
+
+func (m *MockOrchestrator) MigrateVolume(
+	volumeName string, migrationConfig *storage.VolumeMigrationConfig,
+) (*storage.VolumeMigration, error) {
+	if err := migrationConfig.Validate(); err != nil {
+		return nil, err
+	}
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if _, ok := m.backends[migrationConfig.Backend]; !ok {
+		return nil, fmt.Errorf("backend %s not found", migrationConfig.Backend)
+	}
+	migration := &storage.VolumeMigration{
+		Volume:               volumeName,
+		SourceBackend:        volume.Backend,
+		SourceInternalName:   volume.Config.InternalName,
+		Backend:              migrationConfig.Backend,
+		Pool:                 migrationConfig.Pool,
+		InternalName:         volume.Config.InternalName,
+		Method:               storage.MigrationMethodCopy,
+		MaxDisruptionSeconds: migrationConfig.MaxDisruptionSeconds,
+		AutoCutover:          migrationConfig.AutoCutover,
+		State:                storage.MigrationReady,
+	}
+	m.migrations[volumeName] = migration
+	return migration, nil
+}
+
+func (m *MockOrchestrator) GetVolumeMigration(volumeName string) *storage.VolumeMigration {
+	return m.migrations[volumeName]
+}
+
+func (m *MockOrchestrator) ListVolumeMigrations() []*storage.VolumeMigration {
+	migrations := make([]*storage.VolumeMigration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+func (m *MockOrchestrator) CutoverVolumeMigration(volumeName string) (*storage.VolumeMigration, error) {
+	migration, ok := m.migrations[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s is not being migrated", volumeName)
+	}
+	if migration.State != storage.MigrationReady {
+		return nil, fmt.Errorf("migration of volume %s is %s, not ready to cut over", volumeName, migration.State)
+	}
+	volume := m.volumes[volumeName]
+	delete(m.backends[volume.Backend].Volumes, volumeName)
+	volume.Backend = migration.Backend
+	m.backends[migration.Backend].Volumes[volumeName] = volume
+	migration.State = storage.MigrationCompleted
+	return migration, nil
+}
+
+func (m *MockOrchestrator) CancelVolumeMigration(volumeName string) (bool, error) {
+	if _, ok := m.migrations[volumeName]; !ok {
+		return false, fmt.Errorf("volume %s is not being migrated", volumeName)
+	}
+	delete(m.migrations, volumeName)
+	return true, nil
+}
+
+func (m *MockOrchestrator) RehostVolume(volumeName, backendName string) (*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if _, ok := m.backends[backendName]; !ok {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	volume.Backend = backendName
+	return volume.ConstructExternal(), nil
+}
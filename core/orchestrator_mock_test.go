@@ -3,6 +3,7 @@
 package core
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -57,7 +58,7 @@ func addAndRetrieveVolume(
 		t.Fatalf("Unable to add storage class %s (%s): %v", vc.Name,
 			vc.Protocol, err)
 	}
-	vol, err := m.AddVolume(vc)
+	vol, err := m.AddVolume(context.Background(), vc)
 	if err != nil {
 		t.Fatalf("Unable to add volume %s (%s): %s", vc.Name, vc.Protocol, err)
 	}
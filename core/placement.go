@@ -0,0 +1,75 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/netapp/trident/storage"
+)
+
+// orderPoolsForPlacement returns the pools in the order in which volume creation should be
+// attempted.  Pools with a higher priority come first, so lower priority pools are only used
+// once the others are full or otherwise unable to satisfy a request.  Pools sharing a priority
+// are shuffled, with those of higher weight more likely to come first.
+func orderPoolsForPlacement(pools []*storage.Pool) []*storage.Pool {
+
+	poolsByPriority := make(map[int][]*storage.Pool)
+	priorities := make([]int, 0)
+	for _, pool := range pools {
+		if _, ok := poolsByPriority[pool.Priority]; !ok {
+			priorities = append(priorities, pool.Priority)
+		}
+		poolsByPriority[pool.Priority] = append(poolsByPriority[pool.Priority], pool)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	ordered := make([]*storage.Pool, 0, len(pools))
+	for _, priority := range priorities {
+		ordered = append(ordered, weightedShuffle(poolsByPriority[priority])...)
+	}
+	return ordered
+}
+
+// weightedShuffle returns the pools in random order, where each position is filled by one of
+// the remaining pools with probability proportional to its weight.
+func weightedShuffle(pools []*storage.Pool) []*storage.Pool {
+
+	remaining := append([]*storage.Pool(nil), pools...)
+	shuffled := make([]*storage.Pool, 0, len(pools))
+
+	for len(remaining) > 0 {
+		totalWeight := 0
+		for _, pool := range remaining {
+			totalWeight += poolWeight(pool)
+		}
+		n := rand.Intn(totalWeight)
+		for i, pool := range remaining {
+			if n -= poolWeight(pool); n < 0 {
+				shuffled = append(shuffled, pool)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return shuffled
+}
+
+func poolWeight(pool *storage.Pool) int {
+	if pool.Weight < 1 {
+		return storage.DefaultPoolWeight
+	}
+	return pool.Weight
+}
+
+// readOnlyPools returns the pools on which read-only volumes may be created.
+func readOnlyPools(pools []*storage.Pool) []*storage.Pool {
+	readOnly := make([]*storage.Pool, 0, len(pools))
+	for _, pool := range pools {
+		if pool.Backend.SupportsReadOnly() {
+			readOnly = append(readOnly, pool)
+		}
+	}
+	return readOnly
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/netapp/trident/storage"
+)
+
+func TestOrderPoolsForPlacementPriority(t *testing.T) {
+	gold := &storage.Pool{Name: "gold", Priority: 10, Weight: 1}
+	silver1 := &storage.Pool{Name: "silver1", Priority: 5, Weight: 1}
+	silver2 := &storage.Pool{Name: "silver2", Priority: 5, Weight: 3}
+	archive := &storage.Pool{Name: "archive", Priority: -1, Weight: 100}
+
+	for i := 0; i < 20; i++ {
+		ordered := orderPoolsForPlacement([]*storage.Pool{archive, silver1, gold, silver2})
+		if len(ordered) != 4 {
+			t.Fatalf("Expected 4 pools, got %d.", len(ordered))
+		}
+		if ordered[0] != gold || ordered[3] != archive {
+			t.Fatalf("Pools not ordered by priority: %s, %s, %s, %s.",
+				ordered[0].Name, ordered[1].Name, ordered[2].Name, ordered[3].Name)
+		}
+		if !(ordered[1] == silver1 && ordered[2] == silver2) && !(ordered[1] == silver2 && ordered[2] == silver1) {
+			t.Fatal("Pools of equal priority were not kept together.")
+		}
+	}
+}
+
+func TestOrderPoolsForPlacementWeight(t *testing.T) {
+	light := &storage.Pool{Name: "light", Weight: 1}
+	heavy := &storage.Pool{Name: "heavy", Weight: 9}
+	unset := &storage.Pool{Name: "unset"}
+
+	const trials = 10000
+	first := make(map[string]int)
+	for i := 0; i < trials; i++ {
+		first[orderPoolsForPlacement([]*storage.Pool{light, heavy, unset})[0].Name]++
+	}
+
+	// The heavy pool should come first about 9 times in 11, and the others about once each
+	if first["heavy"] < trials*7/11 {
+		t.Errorf("Heavily weighted pool came first only %d times in %d.", first["heavy"], trials)
+	}
+	if first["light"] == 0 || first["unset"] == 0 {
+		t.Errorf("Lightly weighted pools never came first: %v.", first)
+	}
+}
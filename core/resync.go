@@ -0,0 +1,172 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+const resyncTask = "resync-volumes"
+
+// StartResync refreshes the volumes from their backends periodically for as long as Trident
+// runs.  A non-positive interval disables the resync.
+func (o *TridentOrchestrator) StartResync(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	log.WithField("interval", interval).Info("Starting periodic volume resync.")
+
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:         resyncTask,
+		Interval:     interval,
+		InitialDelay: interval,
+		Run:          func() { o.ResyncVolumes() },
+	}); err != nil {
+		log.Errorf("Could not start periodic volume resync. %v", err)
+	}
+}
+
+// ResyncVolumes refreshes the existence and size of every volume from its backend, correcting
+// Trident's state, and the persistent store, where they have drifted.  Volumes missing from
+// their backend are marked orphaned rather than deleted, since that may reflect a misconfigured
+// backend rather than lost data, and are restored if they reappear.  Backends that are offline
+// or can't be listed are skipped.  Provisioning is locked out meanwhile.
+func (o *TridentOrchestrator) ResyncVolumes() []*storage.VolumeResync {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	stored := make(map[string]*storage.VolumeExternal)
+	storedVolumes, err := o.storeClient.GetVolumes()
+	if err != nil {
+		log.Errorf("Could not read volumes from the persistent store to resync them. %v", err)
+		return nil
+	}
+	for _, vol := range storedVolumes {
+		stored[vol.Config.Name] = vol
+	}
+
+	changes := make([]*storage.VolumeResync, 0)
+	for _, backend := range o.backends {
+		if !backend.Online {
+			continue
+		}
+		found, err := listBackendVolumes(backend)
+		if err != nil {
+			log.WithField("backend", backend.Name).Warnf("Could not list volumes to resync them. %v", err)
+			continue
+		}
+		for _, vol := range o.volumes {
+			if vol.Backend == backend.Name {
+				changes = append(changes, o.resyncVolume(vol, found[vol.Config.InternalName], stored)...)
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Volume < changes[j].Volume })
+
+	if len(changes) > 0 {
+		log.WithField("changes", len(changes)).Info("Volume resync corrected drift from the backends.")
+	} else {
+		log.Debug("Volume resync found no drift from the backends.")
+	}
+	return changes
+}
+
+// listBackendVolumes returns the volumes on a backend by internal name.
+func listBackendVolumes(backend *storage.Backend) (map[string]*storage.VolumeExternal, error) {
+
+	var listErr error
+	found := make(map[string]*storage.VolumeExternal)
+	channel := make(chan *storage.VolumeExternalWrapper)
+	go backend.GetVolumeExternalWrappers(channel)
+	for wrapper := range channel {
+		if wrapper.Error != nil {
+			// Keep reading until the driver closes the channel
+			listErr = wrapper.Error
+			continue
+		}
+		found[wrapper.Volume.Config.InternalName] = wrapper.Volume
+	}
+	return found, listErr
+}
+
+// resyncVolume corrects a volume from its state on the backend, which is nil if the volume
+// wasn't found, and saves it if it changed or its copy in the persistent store differs.
+func (o *TridentOrchestrator) resyncVolume(
+	vol *storage.Volume, found *storage.VolumeExternal, stored map[string]*storage.VolumeExternal,
+) []*storage.VolumeResync {
+
+	changes := make([]*storage.VolumeResync, 0)
+	logFields := log.Fields{"volume": vol.Config.Name, "backend": vol.Backend}
+
+	if found == nil {
+		if !vol.Orphaned {
+			vol.Orphaned = true
+			changes = append(changes, &storage.VolumeResync{
+				Volume: vol.Config.Name, Backend: vol.Backend, Change: storage.ResyncMissing})
+			log.WithFields(logFields).Warn("Volume is missing from its backend; marking it orphaned.")
+		}
+	} else {
+		if vol.Orphaned {
+			vol.Orphaned = false
+			changes = append(changes, &storage.VolumeResync{
+				Volume: vol.Config.Name, Backend: vol.Backend, Change: storage.ResyncRestored})
+			log.WithFields(logFields).Info("Volume is present on its backend again; it is no longer orphaned.")
+		}
+		if resized(vol.Config.Size, found.Config.Size) {
+			changes = append(changes, &storage.VolumeResync{
+				Volume:       vol.Config.Name,
+				Backend:      vol.Backend,
+				Change:       storage.ResyncResized,
+				PreviousSize: vol.Config.Size,
+				Size:         found.Config.Size,
+			})
+			logFields["previousSize"] = vol.Config.Size
+			logFields["size"] = found.Config.Size
+			log.WithFields(logFields).Warn("Volume was resized outside of Trident; updating its size.")
+			vol.Config.Size = found.Config.Size
+		}
+	}
+
+	storedVol, isStored := stored[vol.Config.Name]
+	if len(changes) == 0 && isStored && storedVol.Config.Size == vol.Config.Size &&
+		storedVol.Orphaned == vol.Orphaned {
+		return changes
+	}
+
+	var err error
+	if isStored {
+		err = o.storeClient.UpdateVolume(vol)
+	} else {
+		err = o.storeClient.AddVolume(vol)
+	}
+	if err != nil {
+		log.WithFields(logFields).Errorf("Could not save resynced volume. %v", err)
+	} else if len(changes) == 0 {
+		changes = append(changes, &storage.VolumeResync{
+			Volume: vol.Config.Name, Backend: vol.Backend, Change: storage.ResyncStored})
+		log.WithFields(logFields).Warn("Volume differed in the persistent store; saved it again.")
+	}
+	return changes
+}
+
+// resized returns whether a volume's size on its backend differs from the size Trident has for
+// it.  Sizes that can't be compared are left alone.
+func resized(size, backendSize string) bool {
+	bytes, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return false
+	}
+	backendBytes, err := strconv.ParseUint(backendSize, 10, 64)
+	if err != nil || backendBytes == 0 {
+		return false
+	}
+	return bytes != backendBytes
+}
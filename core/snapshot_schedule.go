@@ -0,0 +1,239 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+// snapshotScheduleInterval is how often the scheduler checks for schedules that are due.  Since
+// schedules are specified to the minute, checking more often would gain nothing.
+const snapshotScheduleInterval = time.Minute
+
+const snapshotScheduleTask = "snapshot-schedules"
+
+func (o *TridentOrchestrator) bootstrapSnapshotSchedules() error {
+	schedules, err := o.storeClient.GetSnapshotSchedules()
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if schedule.State.Volumes == nil {
+			schedule.State.Volumes = make(map[string]*storage.ScheduledVolumeState)
+		}
+		o.snapshotSchedules[schedule.Config.Name] = schedule
+		log.WithFields(log.Fields{
+			"snapshotSchedule": schedule.Config.Name,
+			"schedule":         schedule.Config.Schedule,
+			"nextRun":          schedule.NextRun(),
+			"handler":          "Bootstrap",
+		}).Info("Added an existing snapshot schedule.")
+	}
+	return nil
+}
+
+// StartSnapshotScheduler takes scheduled snapshots for as long as Trident runs.  Any runs missed
+// while Trident was stopped are collapsed into one run when it starts.
+func (o *TridentOrchestrator) StartSnapshotScheduler() {
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:     snapshotScheduleTask,
+		Interval: snapshotScheduleInterval,
+		Run:      func() { o.takeScheduledSnapshots(time.Now()) },
+	}); err != nil {
+		log.Errorf("Could not start the snapshot scheduler. %v", err)
+	}
+}
+
+// takeScheduledSnapshots runs every schedule that has come due by now.  Provisioning is locked
+// out meanwhile, so a volume can't be deleted while it is being snapshotted.
+func (o *TridentOrchestrator) takeScheduledSnapshots(now time.Time) {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now = now.UTC()
+	for _, schedule := range o.snapshotSchedules {
+		nextRun := schedule.NextRun()
+		if nextRun.IsZero() || nextRun.After(now) {
+			continue
+		}
+		o.runSnapshotSchedule(schedule, now)
+	}
+}
+
+func (o *TridentOrchestrator) runSnapshotSchedule(schedule *storage.SnapshotSchedule, now time.Time) {
+
+	// Volumes that no longer exist are kept in the state so that users can see why
+	volumeStates := make(map[string]*storage.ScheduledVolumeState)
+	for _, name := range schedule.Config.Volumes {
+		if _, ok := o.volumes[name]; !ok {
+			volumeStates[name] = &storage.ScheduledVolumeState{Error: fmt.Sprintf("volume %s not found", name)}
+		}
+	}
+
+	snapshotName := schedule.SnapshotName(now)
+	for name, volume := range o.volumes {
+		if !schedule.SelectsVolume(volume) {
+			continue
+		}
+		volumeState := &storage.ScheduledVolumeState{}
+		if err := o.takeScheduledSnapshot(schedule, volume, snapshotName); err != nil {
+			log.WithFields(log.Fields{
+				"snapshotSchedule": schedule.Config.Name,
+				"volume":           name,
+			}).Errorf("Could not take scheduled snapshot: %v", err)
+			volumeState.Error = err.Error()
+		} else {
+			volumeState.LastSnapshot = snapshotName
+		}
+		volumeStates[name] = volumeState
+	}
+
+	schedule.State.LastRun = now
+	schedule.State.Volumes = volumeStates
+	if err := o.storeClient.AddSnapshotSchedule(schedule); err != nil {
+		log.WithFields(log.Fields{
+			"snapshotSchedule": schedule.Config.Name,
+		}).Errorf("Could not save snapshot schedule state: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"snapshotSchedule": schedule.Config.Name,
+		"volumes":          len(volumeStates),
+		"nextRun":          schedule.NextRun(),
+	}).Info("Ran snapshot schedule.")
+}
+
+// takeScheduledSnapshot snapshots a volume, then deletes the schedule's oldest snapshots of it
+// beyond the number to retain.  Snapshots that were not taken by the schedule are never deleted.
+func (o *TridentOrchestrator) takeScheduledSnapshot(
+	schedule *storage.SnapshotSchedule, volume *storage.Volume, snapshotName string,
+) error {
+
+	backend, ok := o.backends[volume.Backend]
+	if !ok {
+		return fmt.Errorf("backend %s not found", volume.Backend)
+	}
+	if !backend.Online {
+		return fmt.Errorf("backend %s is offline", backend.Name)
+	}
+	if _, ok = backend.Driver.(storage.SnapshotDriver); !ok {
+		return fmt.Errorf("backend %s does not support taking snapshots", backend.Name)
+	}
+
+	internalName := volume.Config.InternalName
+	if _, err := backend.Guarded().CreateSnapshot(snapshotName, internalName); err != nil {
+		return err
+	}
+
+	snapshots, err := backend.Guarded().SnapshotList(internalName)
+	if err != nil {
+		return fmt.Errorf("took snapshot %s, but could not list snapshots to prune: %v", snapshotName, err)
+	}
+	scheduled := make([]string, 0)
+	for _, snapshot := range snapshots {
+		if schedule.IsScheduledSnapshot(snapshot.Name) {
+			scheduled = append(scheduled, snapshot.Name)
+		}
+	}
+	sort.Strings(scheduled)
+
+	for len(scheduled) > schedule.Config.Retain {
+		if err = backend.Guarded().DeleteSnapshot(scheduled[0], internalName); err != nil {
+			return fmt.Errorf("took snapshot %s, but could not delete snapshot %s: %v",
+				snapshotName, scheduled[0], err)
+		}
+		scheduled = scheduled[1:]
+	}
+	return nil
+}
+
+// AddSnapshotSchedule adds a schedule, which first runs at the next time it matches.
+func (o *TridentOrchestrator) AddSnapshotSchedule(
+	scheduleConfig *storage.SnapshotScheduleConfig,
+) (*storage.SnapshotScheduleExternal, error) {
+
+	if err := scheduleConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, ok := o.snapshotSchedules[scheduleConfig.Name]; ok {
+		return nil, fmt.Errorf("snapshot schedule %s already exists", scheduleConfig.Name)
+	}
+	for _, name := range scheduleConfig.Volumes {
+		volume, ok := o.volumes[name]
+		if !ok {
+			return nil, fmt.Errorf("volume %s not found", name)
+		}
+		if backend, ok := o.backends[volume.Backend]; ok {
+			if _, ok = backend.Driver.(storage.SnapshotDriver); !ok {
+				return nil, fmt.Errorf("volume %s is on backend %s, which does not support taking snapshots",
+					name, backend.Name)
+			}
+		}
+	}
+	if scheduleConfig.StorageClass != "" {
+		if _, ok := o.storageClasses[scheduleConfig.StorageClass]; !ok {
+			return nil, fmt.Errorf("storage class %s not found", scheduleConfig.StorageClass)
+		}
+	}
+
+	schedule := storage.NewSnapshotSchedule(scheduleConfig, time.Now())
+	if err := o.storeClient.AddSnapshotSchedule(schedule); err != nil {
+		return nil, err
+	}
+	o.snapshotSchedules[scheduleConfig.Name] = schedule
+
+	log.WithFields(log.Fields{
+		"snapshotSchedule": scheduleConfig.Name,
+		"schedule":         scheduleConfig.Schedule,
+		"nextRun":          schedule.NextRun(),
+	}).Info("Added snapshot schedule.")
+
+	return schedule.ConstructExternal(), nil
+}
+
+func (o *TridentOrchestrator) GetSnapshotSchedule(scheduleName string) *storage.SnapshotScheduleExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	schedule, ok := o.snapshotSchedules[scheduleName]
+	if !ok {
+		return nil
+	}
+	return schedule.ConstructExternal()
+}
+
+func (o *TridentOrchestrator) ListSnapshotSchedules() []*storage.SnapshotScheduleExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	schedules := make([]*storage.SnapshotScheduleExternal, 0, len(o.snapshotSchedules))
+	for _, schedule := range o.snapshotSchedules {
+		schedules = append(schedules, schedule.ConstructExternal())
+	}
+	return schedules
+}
+
+// DeleteSnapshotSchedule stops a schedule.  The snapshots it has already taken are kept.
+func (o *TridentOrchestrator) DeleteSnapshotSchedule(scheduleName string) (bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	schedule, found := o.snapshotSchedules[scheduleName]
+	if !found {
+		return found, fmt.Errorf("snapshot schedule %s not found", scheduleName)
+	}
+	if err := o.storeClient.DeleteSnapshotSchedule(schedule); err != nil {
+		return found, err
+	}
+	delete(o.snapshotSchedules, scheduleName)
+	return found, nil
+}
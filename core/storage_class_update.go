@@ -0,0 +1,209 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage_attribute"
+	"github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
+)
+
+// UpdateStorageClass replaces the definition of an existing storage class, keeping its name so
+// that volumes and claims that refer to it are unaffected.  The class's pools are chosen afresh,
+// and the returned update reports which pools were gained and lost, along with the existing
+// volumes left on pools that new volumes of the class will no longer be provisioned on.
+func (o *TridentOrchestrator) UpdateStorageClass(scConfig *storageclass.Config) (*storageclass.Update, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	previous, ok := o.storageClasses[scConfig.Name]
+	if !ok {
+		return nil, fmt.Errorf("storage class %s not found", scConfig.Name)
+	}
+	if err := o.validateStorageClass(scConfig); err != nil {
+		return nil, err
+	}
+	scConfig.Revision = previous.GetRevision() + 1
+
+	updated := storageclass.New(scConfig)
+	if err := o.storeClient.UpdateStorageClass(updated); err != nil {
+		return nil, err
+	}
+
+	previousPools := storageClassPoolNames(previous)
+	for _, storagePool := range previous.GetStoragePoolsForProtocol(config.ProtocolAny) {
+		storagePool.RemoveStorageClass(scConfig.Name)
+	}
+	o.storageClasses[scConfig.Name] = updated
+	for _, backend := range o.backends {
+		updated.CheckAndAddBackend(backend)
+	}
+	updatedPools := storageClassPoolNames(updated)
+
+	update := &storageclass.Update{
+		StorageClass:    o.storageClassExternal(updated),
+		AddedPools:      poolNamesDifference(updatedPools, previousPools),
+		RemovedPools:    poolNamesDifference(previousPools, updatedPools),
+		StrandedVolumes: make([]string, 0),
+		UpdatedVolumes:  make([]string, 0),
+	}
+	for _, vol := range o.volumes {
+		if vol.Config.StorageClass == scConfig.Name && utils.SliceContainsString(
+			update.RemovedPools[vol.Backend], vol.Pool) {
+			update.StrandedVolumes = append(update.StrandedVolumes, vol.Config.Name)
+		}
+	}
+	sort.Strings(update.StrandedVolumes)
+
+	if visible, ok := storageClassSnapshotDir(updated); ok {
+		if previousVisible, previousOK := storageClassSnapshotDir(previous); !previousOK || visible != previousVisible {
+			o.applyStorageClassSnapshotDir(update, scConfig.Name, visible)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"storageClass":    scConfig.Name,
+		"revision":        scConfig.Revision,
+		"addedPools":      update.AddedPools,
+		"removedPools":    update.RemovedPools,
+		"strandedVolumes": len(update.StrandedVolumes),
+	}).Info("Updated storage class.")
+	if len(update.StrandedVolumes) > 0 {
+		log.WithFields(log.Fields{
+			"storageClass": scConfig.Name,
+			"volumes":      update.StrandedVolumes,
+		}).Warn("Some volumes of the storage class are on pools that no longer satisfy it; " +
+			"they are left in place, but new volumes of the class won't be provisioned on those pools.")
+	}
+	return update, nil
+}
+
+// storageClassSnapshotDir returns whether a storage class's volumes show their .snapshot
+// directories, and whether the class says so at all.
+func storageClassSnapshotDir(sc *storageclass.StorageClass) (visible, ok bool) {
+	request, found := sc.GetAttributes()[storageattribute.SnapshotDir]
+	if !found {
+		return false, false
+	}
+	visible, ok = request.Value().(bool)
+	return
+}
+
+// applyStorageClassSnapshotDir shows or hides the .snapshot directories of a storage class's
+// existing volumes, so that a change to the class reaches volumes made before it.  Volumes that
+// set their own snapshotDirectory are left alone, as are volumes that are missing from their
+// backends.
+func (o *TridentOrchestrator) applyStorageClassSnapshotDir(update *storageclass.Update, scName string, visible bool) {
+
+	for _, vol := range o.volumes {
+		if vol.Config.StorageClass != scName || vol.Config.SnapshotDir != "" || vol.Orphaned {
+			continue
+		}
+		backend, ok := o.backends[vol.Backend]
+		if !ok {
+			continue
+		}
+		if err := backend.SetSnapshotDirVisible(vol, visible); err != nil {
+			log.WithFields(log.Fields{
+				"storageClass": scName,
+				"volume":       vol.Config.Name,
+				"backend":      vol.Backend,
+			}).Warnf("Could not change the snapshot directory of the volume to match its storage class. %v", err)
+			if update.FailedVolumes == nil {
+				update.FailedVolumes = make(map[string]string)
+			}
+			update.FailedVolumes[vol.Config.Name] = err.Error()
+			continue
+		}
+		update.UpdatedVolumes = append(update.UpdatedVolumes, vol.Config.Name)
+	}
+	sort.Strings(update.UpdatedVolumes)
+
+	log.WithFields(log.Fields{
+		"storageClass":      scName,
+		"snapshotDirectory": visible,
+		"updatedVolumes":    len(update.UpdatedVolumes),
+		"failedVolumes":     len(update.FailedVolumes),
+	}).Info("Applied the storage class's snapshot directory setting to its volumes.")
+}
+
+// validateStorageClass checks a storage class's config before it is added or updated.
+func (o *TridentOrchestrator) validateStorageClass(scConfig *storageclass.Config) error {
+
+	if scConfig.DefaultSize != "" {
+		if _, err := utils.ConvertSizeToBytes(scConfig.DefaultSize); err != nil {
+			return fmt.Errorf("invalid defaultSize for storage class %s: %v", scConfig.Name, err)
+		}
+	}
+	if err := validateWarmPool(scConfig); err != nil {
+		return err
+	}
+	for _, pattern := range scConfig.AllowedNamespaces {
+		if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+			return fmt.Errorf("storage class %s has an invalid allowed namespace '%s'", scConfig.Name, pattern)
+		}
+	}
+
+	// Each name may refer to only one class
+	names := append([]string{scConfig.Name}, scConfig.Aliases...)
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("storage class %s has an empty alias", scConfig.Name)
+		}
+		if utils.SliceContainsString(names[:i], name) {
+			return fmt.Errorf("storage class %s is named %s more than once", scConfig.Name, name)
+		}
+		for _, sc := range o.storageClasses {
+			if sc.GetName() != scConfig.Name && sc.HasName(name) {
+				return fmt.Errorf("storage class %s may not be named %s, which refers to storage class %s",
+					scConfig.Name, name, sc.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+// resolveStorageClass returns the storage class with the name or alias, or nil if there is none.
+func (o *TridentOrchestrator) resolveStorageClass(name string) *storageclass.StorageClass {
+	if sc, ok := o.storageClasses[name]; ok {
+		return sc
+	}
+	for _, sc := range o.storageClasses {
+		if sc.HasName(name) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// storageClassPoolNames returns the names of the storage pools that satisfy a class, by backend.
+func storageClassPoolNames(sc *storageclass.StorageClass) map[string][]string {
+	pools := make(map[string][]string)
+	for _, storagePool := range sc.GetStoragePoolsForProtocol(config.ProtocolAny) {
+		pools[storagePool.Backend.Name] = append(pools[storagePool.Backend.Name], storagePool.Name)
+	}
+	return pools
+}
+
+// poolNamesDifference returns the pools, by backend, that are in the first set but not the second.
+func poolNamesDifference(pools, others map[string][]string) map[string][]string {
+	difference := make(map[string][]string)
+	for backend, names := range pools {
+		for _, name := range names {
+			if !utils.SliceContainsString(others[backend], name) {
+				difference[backend] = append(difference[backend], name)
+			}
+		}
+	}
+	for _, names := range difference {
+		sort.Strings(names)
+	}
+	return difference
+}
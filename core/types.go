@@ -3,37 +3,81 @@
 package core
 
 import (
+	"context"
+	"time"
+
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/frontend"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
 )
 
 type Orchestrator interface {
 	Bootstrap() error
 	AddFrontend(f frontend.Plugin)
 	GetVersion() string
+	CheckHealth(includeBackends bool) *HealthReport
 
 	AddStorageBackend(configJSON string) (*storage.BackendExternal, error)
+	UpdateBackend(backend, configJSON string) (*storage.BackendExternal, error)
+	RenameBackend(backend, newName string) (*storage.BackendExternal, error)
 	GetBackend(backend string) *storage.BackendExternal
+	GetBackendWithCapacity(backend string) *storage.BackendExternal
+	GetBackendWithConfigDetail(backend string) (*storage.BackendExternal, error)
 	ListBackends() []*storage.BackendExternal
 	OfflineBackend(backend string) (bool, error)
+	CordonBackend(backend string, cordoned bool) (*storage.BackendExternal, error)
+	UpdateBackendDebugTraceFlags(backend string, changes map[string]bool) (map[string]bool, error)
 
-	AddVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
-	CloneVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
+	AddVolume(ctx context.Context, volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
+	CloneVolume(ctx context.Context, volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
+	PreviewVolumePlacement(volumeConfig *storage.VolumeConfig) (*storage.PlacementPreview, error)
+	AddVolumes(ctx context.Context, volumeConfigs []*storage.VolumeConfig) []*storage.BulkVolumeResult
+	DeleteVolumes(ctx context.Context, volumeNames []string) []*storage.BulkVolumeResult
+	CloneVolumes(ctx context.Context, fanout *storage.CloneFanoutConfig) (*storage.CloneFanoutResult, error)
 	GetVolume(volume string) *storage.VolumeExternal
+	GetVolumeByInternalName(backend, internalName string) *storage.VolumeExternal
+	UpdateVolumeQoS(volume, qos, qosType string) (*storage.VolumeExternal, error)
+	GetVolumeStats(volume string, interval time.Duration) (*storage.VolumeStatsReport, error)
+	ListVolumeStats(interval time.Duration) ([]*storage.VolumeStatsReport, error)
 	GetDriverTypeForVolume(vol *storage.VolumeExternal) string
 	GetVolumeType(vol *storage.VolumeExternal) config.VolumeType
 	ListVolumes() []*storage.VolumeExternal
-	DeleteVolume(volume string) (found bool, err error)
+	DeleteVolume(ctx context.Context, volume string) (found bool, err error)
 	ListVolumesByPlugin(pluginName string) []*storage.VolumeExternal
 	AttachVolume(volumeName, mountpoint string, options map[string]string) error
 	DetachVolume(volumeName, mountpoint string) error
+	ForceDetachVolume(volumeName string) ([]string, error)
 	ListVolumeSnapshots(volumeName string) ([]*storage.SnapshotExternal, error)
 	ReloadVolumes() error
+	ReconcileBackends(cleanup bool) (*storage.ReconciliationReport, error)
+	GetReconciliationReport() *storage.ReconciliationReport
+	GetOperationLatencies() map[string]*utils.LatencyHistogram
+	GetUsageReport(start, end time.Time) (*storage.UsageReport, error)
+	GetVolumeUsedBytes(volumeName string) (uint64, error)
 
 	AddStorageClass(scConfig *storageclass.Config) (*storageclass.External, error)
+	UpdateStorageClass(scConfig *storageclass.Config) (*storageclass.Update, error)
 	GetStorageClass(scName string) *storageclass.External
 	ListStorageClasses() []*storageclass.External
 	DeleteStorageClass(scName string) (bool, error)
+
+	AddSnapshotSchedule(scheduleConfig *storage.SnapshotScheduleConfig) (*storage.SnapshotScheduleExternal, error)
+	GetSnapshotSchedule(scheduleName string) *storage.SnapshotScheduleExternal
+	ListSnapshotSchedules() []*storage.SnapshotScheduleExternal
+	DeleteSnapshotSchedule(scheduleName string) (bool, error)
+
+	AddVolumeGroup(ctx context.Context, groupConfig *storage.VolumeGroupConfig) (*storage.VolumeGroupExternal, error)
+	GetVolumeGroup(groupName string) *storage.VolumeGroupExternal
+	ListVolumeGroups() []*storage.VolumeGroupExternal
+	SnapshotVolumeGroup(groupName, snapshotName string) (*storage.VolumeGroupSnapshot, error)
+	DeleteVolumeGroup(ctx context.Context, groupName string) (bool, error)
+
+	MigrateVolume(volumeName string, migrationConfig *storage.VolumeMigrationConfig) (*storage.VolumeMigration, error)
+	GetVolumeMigration(volumeName string) *storage.VolumeMigration
+	ListVolumeMigrations() []*storage.VolumeMigration
+	CutoverVolumeMigration(volumeName string) (*storage.VolumeMigration, error)
+	CancelVolumeMigration(volumeName string) (bool, error)
+	RehostVolume(volumeName, backendName string) (*storage.VolumeExternal, error)
 }
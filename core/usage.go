@@ -0,0 +1,209 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	// DefaultUsageSampleInterval is how often the capacity of the volumes is sampled for usage
+	// reports, unless the orchestrator is given another interval.
+	DefaultUsageSampleInterval = time.Hour
+
+	// DefaultUsageRetention is how long samples of the volumes' capacity are kept.
+	DefaultUsageRetention = 90 * 24 * time.Hour
+)
+
+const usageTask = "usage-samples"
+
+// usageTarget is a volume whose capacity is to be sampled, captured while holding the
+// orchestrator lock so that reading the space it uses, which may be slow, can happen without it.
+type usageTarget struct {
+	sample       *storage.UsageSample
+	backend      *storage.Backend
+	internalName string
+}
+
+// StartUsageSampler samples the capacity of every volume at the interval, keeping the samples for
+// the retention period, so that usage may be reported for chargeback.  A non-positive interval
+// disables sampling, and a non-positive retention keeps the samples indefinitely.
+func (o *TridentOrchestrator) StartUsageSampler(interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	log.WithFields(log.Fields{
+		"interval":  interval,
+		"retention": retention,
+	}).Info("Starting volume usage sampling.")
+
+	o.mutex.Lock()
+	o.usageInterval = interval
+	o.usageRetention = retention
+	o.mutex.Unlock()
+
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:     usageTask,
+		Interval: interval,
+		Run: func() {
+			if _, err := o.recordUsage(time.Now()); err != nil {
+				log.Errorf("Could not record volume usage. %v", err)
+			}
+		},
+	}); err != nil {
+		log.Errorf("Could not start volume usage sampling. %v", err)
+	}
+}
+
+// GetVolumeUsedBytes reads the space a volume consumes on its storage, which may be much less than
+// its size if it is thinly provisioned.
+func (o *TridentOrchestrator) GetVolumeUsedBytes(volumeName string) (uint64, error) {
+
+	o.mutex.Lock()
+	vol, found := o.volumes[volumeName]
+	if !found {
+		o.mutex.Unlock()
+		return 0, fmt.Errorf("volume %s not found", volumeName)
+	}
+	backend, found := o.backends[vol.Backend]
+	if !found {
+		o.mutex.Unlock()
+		return 0, fmt.Errorf("backend %s for volume %s not found", vol.Backend, volumeName)
+	}
+	internalName := vol.Config.InternalName
+	o.mutex.Unlock()
+
+	if !backend.SupportsVolumeUsage() {
+		return 0, drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not report the space used by volumes", backend.GetDriverName()))
+	}
+	return backend.Guarded().GetVolumeUsedBytes(internalName)
+}
+
+// GetUsageReport aggregates the capacity provisioned and used between the start and end times by
+// namespace, storage class and backend.
+func (o *TridentOrchestrator) GetUsageReport(start, end time.Time) (*storage.UsageReport, error) {
+
+	if !start.Before(end) {
+		return nil, drivers.NewFatalError("the start of a usage report must be before its end")
+	}
+
+	o.mutex.Lock()
+	interval := o.usageInterval
+	o.mutex.Unlock()
+
+	records, err := o.storeClient.GetUsageRecords()
+	if err != nil {
+		return nil, fmt.Errorf("could not read volume usage. %v", err)
+	}
+	return storage.NewUsageReport(records, start, end, interval), nil
+}
+
+// recordUsage samples the capacity of every volume, saves the samples, and deletes those that are
+// older than the retention period.
+func (o *TridentOrchestrator) recordUsage(now time.Time) (*storage.UsageRecord, error) {
+
+	o.mutex.Lock()
+	targets := make([]*usageTarget, 0, len(o.volumes))
+	for _, vol := range o.volumes {
+		provisionedBytes, _ := strconv.ParseUint(vol.Config.Size, 10, 64)
+		target := &usageTarget{
+			sample: &storage.UsageSample{
+				Volume:           vol.Config.Name,
+				Namespace:        vol.Config.Namespace,
+				StorageClass:     vol.Config.StorageClass,
+				Backend:          vol.Backend,
+				ProvisionedBytes: provisionedBytes,
+			},
+			internalName: vol.Config.InternalName,
+		}
+		if backend, found := o.backends[vol.Backend]; found && backend.SupportsVolumeUsage() {
+			target.backend = backend
+		}
+		targets = append(targets, target)
+	}
+	retention := o.usageRetention
+	o.mutex.Unlock()
+
+	readUsedBytes(targets)
+
+	record := &storage.UsageRecord{
+		Time:    now.UTC().Truncate(time.Second),
+		Samples: make([]*storage.UsageSample, 0, len(targets)),
+	}
+	for _, target := range targets {
+		record.Samples = append(record.Samples, target.sample)
+	}
+	sort.Slice(record.Samples, func(i, j int) bool { return record.Samples[i].Volume < record.Samples[j].Volume })
+
+	if err := o.storeClient.AddUsageRecord(record); err != nil {
+		return nil, err
+	}
+	if retention > 0 {
+		o.pruneUsageRecords(now.Add(-retention))
+	}
+
+	log.WithFields(log.Fields{
+		"time":    record.Time,
+		"volumes": len(record.Samples),
+	}).Debug("Recorded volume usage.")
+
+	return record, nil
+}
+
+// readUsedBytes reads the space used by each volume whose backend can report it, several at a
+// time.  Volumes that can't be read are left without a used size.
+func readUsedBytes(targets []*usageTarget) {
+
+	semaphore := make(chan struct{}, volumeStatsConcurrency)
+
+	wg := &sync.WaitGroup{}
+	for _, target := range targets {
+		if target.backend == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(target *usageTarget) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			usedBytes, err := target.backend.Guarded().GetVolumeUsedBytes(target.internalName)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"volume":  target.sample.Volume,
+					"backend": target.sample.Backend,
+				}).Warnf("Could not read the space used by a volume. %v", err)
+				return
+			}
+			target.sample.UsedBytes = &usedBytes
+		}(target)
+	}
+	wg.Wait()
+}
+
+// pruneUsageRecords deletes the samples of the volumes' capacity taken before the cutoff.
+func (o *TridentOrchestrator) pruneUsageRecords(cutoff time.Time) {
+
+	records, err := o.storeClient.GetUsageRecords()
+	if err != nil {
+		log.Errorf("Could not read volume usage to delete old samples. %v", err)
+		return
+	}
+	for _, record := range records {
+		if record.Time.Before(cutoff) {
+			if err = o.storeClient.DeleteUsageRecord(record); err != nil {
+				log.WithField("time", record.Time).Errorf("Could not delete old volume usage. %v", err)
+			}
+		}
+	}
+}
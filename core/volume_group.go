@@ -0,0 +1,344 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+)
+
+func (o *TridentOrchestrator) bootstrapVolumeGroups() error {
+	groups, err := o.storeClient.GetVolumeGroups()
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		for _, volumeName := range group.Volumes {
+			if _, ok := o.volumes[volumeName]; !ok {
+				log.WithFields(log.Fields{
+					"volumeGroup": group.Name,
+					"volume":      volumeName,
+				}).Warn("Volume in volume group not found.")
+			}
+		}
+		o.volumeGroups[group.Name] = group
+		log.WithFields(log.Fields{
+			"volumeGroup": group.Name,
+			"volumes":     group.Volumes,
+			"handler":     "Bootstrap",
+		}).Info("Added an existing volume group.")
+	}
+	return nil
+}
+
+// AddVolumeGroup creates each of a group's volumes, or clones each volume of its source group,
+// and then records the group.  If any volume can't be created, those already created are deleted.
+func (o *TridentOrchestrator) AddVolumeGroup(
+	ctx context.Context, groupConfig *storage.VolumeGroupConfig,
+) (*storage.VolumeGroupExternal, error) {
+
+	if err := groupConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	volumeConfigs, err := o.volumeGroupVolumeConfigs(groupConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &storage.VolumeGroup{Name: groupConfig.Name, Volumes: make([]string, 0, len(volumeConfigs))}
+	for _, volumeConfig := range volumeConfigs {
+		if volumeConfig.CloneSourceVolume != "" {
+			_, err = o.CloneVolume(ctx, volumeConfig)
+		} else {
+			_, err = o.AddVolume(ctx, volumeConfig)
+		}
+		if err != nil {
+			err = fmt.Errorf("could not create volume %s of volume group %s: %v", volumeConfig.Name,
+				groupConfig.Name, err)
+			o.deleteVolumeGroupVolumes(ctx, group)
+			return nil, err
+		}
+		group.Volumes = append(group.Volumes, volumeConfig.Name)
+	}
+
+	external, err := o.saveNewVolumeGroup(group)
+	if err != nil {
+		o.deleteVolumeGroupVolumes(ctx, group)
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"volumeGroup": group.Name,
+		"volumes":     group.Volumes,
+	}).Info("Added volume group.")
+
+	return external, nil
+}
+
+// saveNewVolumeGroup records a group once its volumes have been created.
+func (o *TridentOrchestrator) saveNewVolumeGroup(group *storage.VolumeGroup) (*storage.VolumeGroupExternal, error) {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// Another request may have added a group of the same name meanwhile
+	if _, ok := o.volumeGroups[group.Name]; ok {
+		return nil, fmt.Errorf("volume group %s already exists", group.Name)
+	}
+	if err := o.storeClient.AddVolumeGroup(group); err != nil {
+		return nil, err
+	}
+	o.volumeGroups[group.Name] = group
+	return o.constructVolumeGroupExternal(group), nil
+}
+
+// volumeGroupVolumeConfigs returns the configs of the volumes to create for a new group.  A clone
+// of a group gets a clone of each of its source group's volumes.
+func (o *TridentOrchestrator) volumeGroupVolumeConfigs(
+	groupConfig *storage.VolumeGroupConfig,
+) ([]*storage.VolumeConfig, error) {
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, ok := o.volumeGroups[groupConfig.Name]; ok {
+		return nil, fmt.Errorf("volume group %s already exists", groupConfig.Name)
+	}
+	if groupConfig.CloneSourceGroup == "" {
+		return groupConfig.Volumes, nil
+	}
+
+	sourceGroup, ok := o.volumeGroups[groupConfig.CloneSourceGroup]
+	if !ok {
+		return nil, fmt.Errorf("source volume group %s not found", groupConfig.CloneSourceGroup)
+	}
+	if len(sourceGroup.Volumes) == 0 {
+		return nil, fmt.Errorf("source volume group %s has no volumes", sourceGroup.Name)
+	}
+
+	volumeConfigs := make([]*storage.VolumeConfig, 0, len(sourceGroup.Volumes))
+	for _, sourceVolumeName := range sourceGroup.Volumes {
+		volumeConfigs = append(volumeConfigs, &storage.VolumeConfig{
+			Name:                groupConfig.CloneVolumeName(sourceVolumeName),
+			CloneSourceVolume:   sourceVolumeName,
+			CloneSourceSnapshot: groupConfig.CloneSourceSnapshot,
+		})
+	}
+	return volumeConfigs, nil
+}
+
+// deleteVolumeGroupVolumes deletes the volumes created for a group that couldn't be added.
+// Failures are only logged, since the error that prevented adding the group is more useful.
+func (o *TridentOrchestrator) deleteVolumeGroupVolumes(ctx context.Context, group *storage.VolumeGroup) {
+	for _, volumeName := range group.Volumes {
+		if _, err := o.DeleteVolume(ctx, volumeName); err != nil {
+			log.WithFields(log.Fields{
+				"volumeGroup": group.Name,
+				"volume":      volumeName,
+			}).Errorf("Could not clean up volume of volume group: %v", err)
+		}
+	}
+}
+
+func (o *TridentOrchestrator) constructVolumeGroupExternal(group *storage.VolumeGroup) *storage.VolumeGroupExternal {
+	external := &storage.VolumeGroupExternal{
+		Name:    group.Name,
+		Volumes: make([]*storage.VolumeExternal, 0, len(group.Volumes)),
+	}
+	for _, volumeName := range group.Volumes {
+		if volume, ok := o.volumes[volumeName]; ok {
+			external.Volumes = append(external.Volumes, volume.ConstructExternal())
+		}
+	}
+	return external
+}
+
+func (o *TridentOrchestrator) GetVolumeGroup(groupName string) *storage.VolumeGroupExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	group, ok := o.volumeGroups[groupName]
+	if !ok {
+		return nil
+	}
+	return o.constructVolumeGroupExternal(group)
+}
+
+func (o *TridentOrchestrator) ListVolumeGroups() []*storage.VolumeGroupExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	groups := make([]*storage.VolumeGroupExternal, 0, len(o.volumeGroups))
+	for _, group := range o.volumeGroups {
+		groups = append(groups, o.constructVolumeGroupExternal(group))
+	}
+	return groups
+}
+
+// SnapshotVolumeGroup snapshots each of a group's volumes.  The volumes on each backend whose
+// driver supports group snapshots, such as an ONTAP consistency group snapshot, are snapshotted
+// together; the others are snapshotted one at a time.  If any snapshot fails, those already taken
+// are deleted.
+func (o *TridentOrchestrator) SnapshotVolumeGroup(
+	groupName, snapshotName string,
+) (*storage.VolumeGroupSnapshot, error) {
+
+	if snapshotName == "" {
+		return nil, errors.New("a snapshot name is required")
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	group, ok := o.volumeGroups[groupName]
+	if !ok {
+		return nil, fmt.Errorf("volume group %s not found", groupName)
+	}
+	if len(group.Volumes) == 0 {
+		return nil, fmt.Errorf("volume group %s has no volumes", groupName)
+	}
+
+	// Gather the volumes on each backend, checking that every backend can take snapshots
+	backendVolumes := make(map[string][]*storage.Volume)
+	for _, volumeName := range group.Volumes {
+		volume, ok := o.volumes[volumeName]
+		if !ok {
+			return nil, fmt.Errorf("volume %s not found", volumeName)
+		}
+		backend, ok := o.backends[volume.Backend]
+		if !ok {
+			return nil, fmt.Errorf("backend %s not found", volume.Backend)
+		}
+		if !backend.Online {
+			return nil, fmt.Errorf("backend %s is offline", backend.Name)
+		}
+		if _, ok = backend.Driver.(storage.SnapshotDriver); !ok {
+			return nil, fmt.Errorf("backend %s does not support taking snapshots", backend.Name)
+		}
+		backendVolumes[backend.Name] = append(backendVolumes[backend.Name], volume)
+	}
+	backendNames := make([]string, 0, len(backendVolumes))
+	for name := range backendVolumes {
+		backendNames = append(backendNames, name)
+	}
+	sort.Strings(backendNames)
+
+	result := &storage.VolumeGroupSnapshot{
+		Name:       snapshotName,
+		Consistent: len(group.Volumes) == 1,
+		Volumes:    make(map[string]*storage.SnapshotExternal),
+	}
+	taken := make([]*storage.Volume, 0, len(group.Volumes))
+
+	var err error
+	for _, backendName := range backendNames {
+		volumes := backendVolumes[backendName]
+		backend := o.backends[backendName]
+
+		var snapshots []*storage.Snapshot
+		if _, ok := backend.Driver.(storage.GroupSnapshotDriver); ok && len(volumes) > 1 {
+			internalNames := make([]string, 0, len(volumes))
+			for _, volume := range volumes {
+				internalNames = append(internalNames, volume.Config.InternalName)
+			}
+			snapshots, err = backend.Guarded().CreateGroupSnapshot(snapshotName, internalNames)
+			if err == nil {
+				result.Consistent = len(backendNames) == 1
+				taken = append(taken, volumes...)
+			}
+		} else {
+			for _, volume := range volumes {
+				var snapshot *storage.Snapshot
+				if snapshot, err = backend.Guarded().CreateSnapshot(snapshotName, volume.Config.InternalName); err != nil {
+					break
+				}
+				snapshots = append(snapshots, snapshot)
+				taken = append(taken, volume)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("could not snapshot volume group %s on backend %s: %v", groupName, backendName, err)
+			break
+		}
+		for i, snapshot := range snapshots {
+			result.Volumes[volumes[i].Config.Name] = snapshot.ConstructExternal()
+		}
+	}
+
+	if err != nil {
+		for _, volume := range taken {
+			backend := o.backends[volume.Backend]
+			if deleteErr := backend.Guarded().DeleteSnapshot(snapshotName, volume.Config.InternalName); deleteErr != nil {
+				log.WithFields(log.Fields{
+					"volumeGroup": groupName,
+					"volume":      volume.Config.Name,
+					"snapshot":    snapshotName,
+				}).Errorf("Could not clean up snapshot of volume group: %v", deleteErr)
+			}
+		}
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"volumeGroup": groupName,
+		"snapshot":    snapshotName,
+		"consistent":  result.Consistent,
+	}).Info("Snapshotted volume group.")
+
+	return result, nil
+}
+
+// DeleteVolumeGroup deletes each of a group's volumes and then the group.  If a volume can't be
+// deleted, the group remains with the volumes not yet deleted, so the delete may be retried.
+func (o *TridentOrchestrator) DeleteVolumeGroup(ctx context.Context, groupName string) (bool, error) {
+
+	o.mutex.Lock()
+	group, found := o.volumeGroups[groupName]
+	var volumeNames []string
+	if found {
+		volumeNames = append(volumeNames, group.Volumes...)
+	}
+	o.mutex.Unlock()
+
+	if !found {
+		return found, fmt.Errorf("volume group %s not found", groupName)
+	}
+
+	for _, volumeName := range volumeNames {
+		// Deleting a volume removes it from its group
+		if volumeFound, err := o.DeleteVolume(ctx, volumeName); err != nil && volumeFound {
+			return found, fmt.Errorf("could not delete volume %s of volume group %s: %v", volumeName,
+				groupName, err)
+		}
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if err := o.storeClient.DeleteVolumeGroup(group); err != nil {
+		return found, err
+	}
+	delete(o.volumeGroups, groupName)
+	return found, nil
+}
+
+// removeVolumeFromGroups removes a deleted volume from any group it belonged to.  The volume is
+// already gone, so a failure to save the group is only logged; the group is saved again when it
+// next changes.
+func (o *TridentOrchestrator) removeVolumeFromGroups(volumeName string) {
+	for _, group := range o.volumeGroups {
+		if !group.RemoveVolume(volumeName) {
+			continue
+		}
+		if err := o.storeClient.AddVolumeGroup(group); err != nil {
+			log.WithFields(log.Fields{
+				"volumeGroup": group.Name,
+				"volume":      volumeName,
+			}).Errorf("Could not save volume group after deleting one of its volumes: %v", err)
+		}
+	}
+}
@@ -0,0 +1,683 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+// migrationMaxPasses limits how many transfers a migration makes while waiting for one to be short
+// enough to repeat within the disruption window.
+const migrationMaxPasses = 10
+
+// volumeMigration is a migration along with what is needed to carry it out.  Its state is guarded
+// by the orchestrator's mutex, but its data is transferred without holding the mutex.
+type volumeMigration struct {
+	state         *storage.VolumeMigration
+	source        *storage.Backend
+	destination   *storage.Backend
+	replicaConfig *storage.VolumeConfig
+	volTxn        *persistentstore.VolumeTransaction
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+// MigrateVolume starts moving a volume to another backend.  The volume's data is copied in the
+// background while the volume stays in use on its current backend, and once the remaining changes
+// can be copied within the disruption window, the migration is ready to cut over.
+func (o *TridentOrchestrator) MigrateVolume(
+	volumeName string, migrationConfig *storage.VolumeMigrationConfig,
+) (*storage.VolumeMigration, error) {
+
+	if err := migrationConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, ok := o.volumes[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if volume.Orphaned {
+		return nil, fmt.Errorf("volume %s is orphaned", volumeName)
+	}
+	if volume.Config.IsCache() || len(o.volumeCaches(volumeName)) > 0 {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf("volume %s is a cache or is cached, so it cannot "+
+			"be migrated", volumeName))
+	}
+	if m, ok := o.migrations[volumeName]; ok && m.state.Active() {
+		return nil, fmt.Errorf("volume %s is already being migrated", volumeName)
+	}
+	source, ok := o.backends[volume.Backend]
+	if !ok {
+		return nil, fmt.Errorf("backend %s for volume %s not found", volume.Backend, volumeName)
+	}
+	destination, ok := o.backends[migrationConfig.Backend]
+	if !ok {
+		return nil, fmt.Errorf("backend %s not found", migrationConfig.Backend)
+	}
+	if destination == source {
+		return nil, fmt.Errorf("volume %s is already on backend %s", volumeName, destination.Name)
+	}
+	if !destination.Online {
+		return nil, fmt.Errorf("backend %s is offline", destination.Name)
+	}
+	if destination.Cordoned {
+		return nil, drivers.NewRetryableError(fmt.Sprintf("backend %s is cordoned", destination.Name))
+	}
+	if !destination.SupportsOnDelete(volume.Config.OnDelete) {
+		return nil, fmt.Errorf("backend %s does not support onDelete %s of volume %s", destination.Name,
+			volume.Config.OnDelete, volumeName)
+	}
+	if volume.Config.ReadOnly && !destination.SupportsReadOnly() {
+		return nil, fmt.Errorf("backend %s does not support read-only volumes", destination.Name)
+	}
+	if destination.GetProtocol() != source.GetProtocol() {
+		return nil, fmt.Errorf("backend %s does not serve %s volumes", destination.Name, source.GetProtocol())
+	}
+
+	pool, attributes, err := o.migrationPool(volume, destination, migrationConfig.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	sizeBytes, err := volumeSizeBytes(volume.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaConfig := &storage.VolumeConfig{}
+	volume.Config.ConstructClone(replicaConfig)
+	if replicaConfig.InternalName, err = destination.GetInternalVolumeName(replicaConfig); err != nil {
+		return nil, err
+	}
+	if destination.Guarded().Get(replicaConfig.InternalName) == nil {
+		return nil, fmt.Errorf("volume %s already exists on backend %s", replicaConfig.InternalName,
+			destination.Name)
+	}
+	opts, err := destination.Guarded().GetVolumeOpts(replicaConfig, pool, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	method := storage.MigrationMethodCopy
+	if _, ok := destination.Driver.(storage.MigrationDriver); ok &&
+		destination.Guarded().CanReplicateFrom(source.Driver) {
+		method = storage.MigrationMethodReplication
+	}
+
+	maxDisruptionSeconds := migrationConfig.MaxDisruptionSeconds
+	if maxDisruptionSeconds == 0 {
+		maxDisruptionSeconds = storage.DefaultMaxDisruptionSeconds
+	}
+
+	state := &storage.VolumeMigration{
+		Volume:               volumeName,
+		SourceBackend:        source.Name,
+		SourceInternalName:   volume.Config.InternalName,
+		Backend:              destination.Name,
+		Pool:                 pool.Name,
+		InternalName:         replicaConfig.InternalName,
+		Method:               method,
+		MaxDisruptionSeconds: maxDisruptionSeconds,
+		AutoCutover:          migrationConfig.AutoCutover,
+		State:                storage.MigrationTransferring,
+		Job:                  utils.StartJob("migrate", volumeName),
+	}
+
+	// Record the migration, so that whichever copy of the volume is left over can be cleaned up
+	// if Trident restarts before the migration finishes
+	volTxn := &persistentstore.VolumeTransaction{
+		Config:    volume.Config,
+		Op:        persistentstore.MigrateVolume,
+		Migration: state,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err = o.storeClient.AddVolumeTransaction(volTxn); err != nil {
+		utils.FinishJob(state.Job, err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &volumeMigration{
+		state:         state,
+		source:        source,
+		destination:   destination,
+		replicaConfig: replicaConfig,
+		volTxn:        volTxn,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	o.migrations[volumeName] = m
+
+	log.WithFields(log.Fields{
+		"volume":        volumeName,
+		"sourceBackend": source.Name,
+		"backend":       destination.Name,
+		"pool":          pool.Name,
+		"method":        method,
+		"job":           state.Job,
+	}).Info("Started volume migration.")
+
+	go o.runMigration(m, sizeBytes, opts)
+
+	stateCopy := *state
+	return &stateCopy, nil
+}
+
+// migrationPool picks the destination pool for a volume's migration, which must be able to hold
+// volumes of the volume's storage class if that still exists.
+func (o *TridentOrchestrator) migrationPool(
+	volume *storage.Volume, destination *storage.Backend, poolName string,
+) (*storage.Pool, map[string]storageattribute.Request, error) {
+
+	sc, ok := o.storageClasses[volume.Config.StorageClass]
+	if !ok {
+		log.WithFields(log.Fields{
+			"volume":       volume.Config.Name,
+			"storageClass": volume.Config.StorageClass,
+		}).Warn("Storage class of migrating volume not found; any destination pool may be used.")
+
+		if poolName != "" {
+			pool, ok := destination.Storage[poolName]
+			if !ok {
+				return nil, nil, fmt.Errorf("pool %s not found on backend %s", poolName, destination.Name)
+			}
+			return pool, nil, nil
+		}
+		for _, pool := range orderPoolsForPlacement(poolsOfBackend(destination)) {
+			return pool, nil, nil
+		}
+		return nil, nil, fmt.Errorf("backend %s has no storage pools", destination.Name)
+	}
+
+	for _, pool := range orderPoolsForPlacement(sc.GetStoragePoolsForProtocol(volume.Config.Protocol)) {
+		if pool.Backend == destination && (poolName == "" || pool.Name == poolName) {
+			return pool, sc.GetAttributes(), nil
+		}
+	}
+	if poolName != "" {
+		return nil, nil, fmt.Errorf("pool %s of backend %s cannot hold volumes of storage class %s",
+			poolName, destination.Name, sc.GetName())
+	}
+	return nil, nil, fmt.Errorf("backend %s has no pools that can hold volumes of storage class %s",
+		destination.Name, sc.GetName())
+}
+
+func poolsOfBackend(backend *storage.Backend) []*storage.Pool {
+	pools := make([]*storage.Pool, 0, len(backend.Storage))
+	for _, pool := range backend.Storage {
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+func volumeSizeBytes(volConfig *storage.VolumeConfig) (uint64, error) {
+	size, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return 0, fmt.Errorf("could not convert volume size %s: %v", volConfig.Size, err)
+	}
+	return strconv.ParseUint(size, 10, 64)
+}
+
+// runMigration copies a volume's data to its destination until the migration is ready to cut over.
+func (o *TridentOrchestrator) runMigration(m *volumeMigration, sizeBytes uint64, opts map[string]string) {
+
+	err := o.createMigrationReplica(m, sizeBytes, opts)
+	if err == nil {
+		err = o.transferUntilReady(m)
+	}
+	if err != nil {
+		if m.ctx.Err() == context.Canceled {
+			err = errors.New("the migration was cancelled")
+		}
+		o.mutex.Lock()
+		o.abandonMigration(m, err)
+		o.mutex.Unlock()
+		return
+	}
+
+	if m.state.AutoCutover {
+		if _, err = o.CutoverVolumeMigration(m.state.Volume); err != nil {
+			log.WithField("volume", m.state.Volume).Warnf("Automatic cutover failed. %v", err)
+		}
+	}
+}
+
+// createMigrationReplica creates the volume on the destination backend that will take the place
+// of the migrating volume.
+func (o *TridentOrchestrator) createMigrationReplica(
+	m *volumeMigration, sizeBytes uint64, opts map[string]string,
+) error {
+
+	if m.state.Method == storage.MigrationMethodReplication {
+		return m.destination.Guarded().CreateReplica(m.ctx, m.state.InternalName, m.state.SourceInternalName,
+			m.source.Driver, sizeBytes, opts)
+	}
+
+	if err := m.destination.Guarded().Create(m.ctx, m.state.InternalName, sizeBytes, opts); err != nil {
+		return err
+	}
+
+	// The replica must be accessible for its data to be copied into it
+	return m.destination.Guarded().CreateFollowup(m.replicaConfig)
+}
+
+// transferUntilReady copies the volume's data to its replica, then copies the changes made since,
+// until doing so is quick enough to be done within the disruption window.
+func (o *TridentOrchestrator) transferUntilReady(m *volumeMigration) error {
+
+	// A block volume's filesystem may only be mounted by one host at a time, so it can't be copied
+	// through this host while it is in use
+	if m.state.Method == storage.MigrationMethodCopy && m.source.GetProtocol() == config.Block {
+		return o.setMigrationReady(m, "Block volumes are copied in full during cutover.")
+	}
+
+	window := m.state.MaxDisruption()
+	var elapsed time.Duration
+	for pass := 1; pass <= migrationMaxPasses; pass++ {
+		start := time.Now()
+		if err := transferMigrationData(m.ctx, m); err != nil {
+			return err
+		}
+		elapsed = time.Since(start)
+		o.recordMigrationTransfer(m, elapsed)
+
+		// The first transfer copies everything, so only later ones show how long the final one will take
+		if pass > 1 && elapsed <= window {
+			return o.setMigrationReady(m, "")
+		}
+	}
+	return fmt.Errorf("after %d transfers, the last took %.0fs, longer than the disruption window of %.0fs",
+		migrationMaxPasses, elapsed.Seconds(), window.Seconds())
+}
+
+// transferMigrationData copies whatever the volume's replica lacks.
+func transferMigrationData(ctx context.Context, m *volumeMigration) error {
+	if m.state.Method == storage.MigrationMethodReplication {
+		return m.destination.Guarded().UpdateReplica(ctx, m.state.InternalName)
+	}
+	return copyMigrationData(ctx, m)
+}
+
+// copyMigrationData attaches the volume and its replica to this host and copies the volume's files.
+func copyMigrationData(ctx context.Context, m *volumeMigration) error {
+
+	sourceMount, err := ioutil.TempDir("", "trident-migrate-src-")
+	if err != nil {
+		return fmt.Errorf("could not create source mountpoint: %v", err)
+	}
+	defer os.Remove(sourceMount)
+
+	destinationMount, err := ioutil.TempDir("", "trident-migrate-dst-")
+	if err != nil {
+		return fmt.Errorf("could not create destination mountpoint: %v", err)
+	}
+	defer os.Remove(destinationMount)
+
+	if err = m.source.Guarded().Attach(m.state.SourceInternalName, sourceMount, map[string]string{}); err != nil {
+		return fmt.Errorf("could not attach volume %s: %v", m.state.SourceInternalName, err)
+	}
+	defer m.source.Guarded().Detach(m.state.SourceInternalName, sourceMount)
+
+	if err = m.destination.Guarded().Attach(m.state.InternalName, destinationMount, map[string]string{}); err != nil {
+		return fmt.Errorf("could not attach volume %s: %v", m.state.InternalName, err)
+	}
+	defer m.destination.Guarded().Detach(m.state.InternalName, destinationMount)
+
+	return utils.SyncDirectory(ctx, sourceMount, destinationMount)
+}
+
+func (o *TridentOrchestrator) recordMigrationTransfer(m *volumeMigration, elapsed time.Duration) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	m.state.Passes++
+	m.state.LastTransferSeconds = elapsed.Seconds()
+	utils.UpdateJob(m.state.Job, 50, fmt.Sprintf("transfer %d took %.0fs", m.state.Passes, elapsed.Seconds()))
+
+	log.WithFields(log.Fields{
+		"volume":   m.state.Volume,
+		"transfer": m.state.Passes,
+		"seconds":  elapsed.Seconds(),
+	}).Debug("Transferred migrating volume's data.")
+}
+
+func (o *TridentOrchestrator) setMigrationReady(m *volumeMigration, message string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// A migration cancelled during its last transfer must not become ready
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	m.state.State = storage.MigrationReady
+	m.state.Message = message
+	utils.UpdateJob(m.state.Job, 90, "ready to cut over")
+
+	log.WithField("volume", m.state.Volume).Info("Volume migration is ready to cut over.")
+	return nil
+}
+
+// abandonMigration destroys a migration's replica, leaving the volume where it was.  The caller
+// must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) abandonMigration(m *volumeMigration, reason error) {
+
+	m.cancel()
+	m.state.State = storage.MigrationFailed
+	m.state.Message = reason.Error()
+
+	if err := deleteMigrationReplica(m); err != nil {
+		// Leave the transaction, so that deleting the replica is retried when Trident restarts
+		m.state.Message = fmt.Sprintf("%s; could not delete volume %s from backend %s: %v",
+			m.state.Message, m.state.InternalName, m.destination.Name, err)
+	} else if err = o.storeClient.DeleteVolumeTransaction(m.volTxn); err != nil {
+		log.WithField("volume", m.state.Volume).Warnf("Could not delete volume migration transaction. %v", err)
+	}
+	utils.FinishJob(m.state.Job, errors.New(m.state.Message))
+
+	log.WithFields(log.Fields{
+		"volume":  m.state.Volume,
+		"backend": m.destination.Name,
+	}).Warnf("Volume migration abandoned: %s", m.state.Message)
+}
+
+func deleteMigrationReplica(m *volumeMigration) error {
+	if m.state.Method == storage.MigrationMethodReplication {
+		return m.destination.Guarded().DeleteReplica(context.Background(),
+			m.state.InternalName, m.state.SourceInternalName, m.source.Driver)
+	}
+	return m.destination.Guarded().Destroy(context.Background(), m.state.InternalName)
+}
+
+// CutoverVolumeMigration moves a volume to its migration's destination backend.  The volume's users
+// must have stopped writing to it, as the remaining changes are copied before the volume is switched
+// to its replica.  If they can't be copied within the disruption window, the cutover is abandoned and
+// the volume stays on its current backend, so that cutover may be tried again later.
+func (o *TridentOrchestrator) CutoverVolumeMigration(volumeName string) (*storage.VolumeMigration, error) {
+
+	m, err := o.startCutover(volumeName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.cutover(m)
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err != nil && m.state.State == storage.MigrationCuttingOver {
+		m.state.State = storage.MigrationReady
+		m.state.Message = err.Error()
+		utils.UpdateJob(m.state.Job, 90, "cutover failed; ready to cut over")
+	}
+	stateCopy := *m.state
+	return &stateCopy, err
+}
+
+func (o *TridentOrchestrator) startCutover(volumeName string) (*volumeMigration, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	m, ok := o.migrations[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s is not being migrated", volumeName)
+	}
+	if m.state.State != storage.MigrationReady {
+		return nil, fmt.Errorf("migration of volume %s is %s, not ready to cut over", volumeName, m.state.State)
+	}
+	m.state.State = storage.MigrationCuttingOver
+	m.state.Message = ""
+	utils.UpdateJob(m.state.Job, 95, "cutting over")
+	return m, nil
+}
+
+func (o *TridentOrchestrator) cutover(m *volumeMigration) error {
+
+	window := m.state.MaxDisruption()
+	ctx, cancel := context.WithTimeout(m.ctx, window)
+	start := time.Now()
+	err := transferMigrationData(ctx, m)
+	cancel()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("the final transfer did not finish within the disruption window of %.0fs, "+
+				"so volume %s remains on backend %s", window.Seconds(), m.state.Volume, m.source.Name)
+		}
+		return fmt.Errorf("the final transfer failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if m.state.Method == storage.MigrationMethodReplication {
+		if err = m.destination.Guarded().PromoteReplica(context.Background(), m.state.InternalName,
+			m.state.SourceInternalName, m.source.Driver); err != nil {
+			return fmt.Errorf("could not make volume %s on backend %s writable: %v", m.state.InternalName,
+				m.destination.Name, err)
+		}
+	}
+	if err = m.destination.Guarded().CreateFollowup(m.replicaConfig); err != nil {
+		return err
+	}
+	if m.replicaConfig.ReadOnly {
+		if err = m.destination.Guarded().SetVolumeReadOnly(m.replicaConfig); err != nil {
+			return err
+		}
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err = o.swapVolumeBackend(m); err != nil {
+		return err
+	}
+	m.state.Passes++
+	m.state.LastTransferSeconds = elapsed.Seconds()
+	m.cancel()
+	o.retainMigrationSource(m)
+
+	// The transaction now records the original volume, which would otherwise be forgotten
+	if err = o.storeClient.AddVolumeTransaction(m.volTxn); err != nil {
+		log.WithField("volume", m.state.Volume).Warnf("Could not update volume migration transaction. %v", err)
+	}
+	utils.FinishJob(m.state.Job, nil)
+
+	log.WithFields(log.Fields{
+		"volume":        m.state.Volume,
+		"sourceBackend": m.source.Name,
+		"backend":       m.destination.Name,
+		"seconds":       elapsed.Seconds(),
+	}).Info("Volume migration cut over.")
+	return nil
+}
+
+// retainMigrationSource completes a migration that has cut over, keeping the original volume.
+// Frontends such as Kubernetes record where a volume is served from when it is attached, so hosts
+// may still refer to the original until, for example, the volume's persistent volume is
+// recreated.  The original is deleted when the migration is.  The caller must hold the
+// orchestrator's mutex.
+func (o *TridentOrchestrator) retainMigrationSource(m *volumeMigration) {
+	m.state.State = storage.MigrationCompleted
+	m.state.SourceRetained = true
+	m.state.Message = fmt.Sprintf("the original volume %s is kept on backend %s until this migration "+
+		"is deleted; recreate any persistent volume that refers to it first", m.state.SourceInternalName,
+		m.source.Name)
+}
+
+// deleteMigrationSource deletes the original volume kept after a migration cut over, along with
+// the migration's transaction.  The caller must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) deleteMigrationSource(m *volumeMigration) error {
+	if err := m.source.Guarded().Destroy(context.Background(), m.state.SourceInternalName); err != nil {
+		return fmt.Errorf("could not delete the original volume %s from backend %s: %v",
+			m.state.SourceInternalName, m.source.Name, err)
+	}
+	if err := o.storeClient.DeleteVolumeTransaction(m.volTxn); err != nil {
+		return fmt.Errorf("could not delete volume migration transaction: %v", err)
+	}
+	m.state.SourceRetained = false
+	return nil
+}
+
+// swapVolumeBackend switches a volume to its replica on the migration's destination backend.  The
+// volume's record is replaced in a single write to the persistent store, so the volume is never
+// bound to both backends or to neither.  The caller must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) swapVolumeBackend(m *volumeMigration) error {
+
+	migrated := storage.NewVolume(m.replicaConfig, m.destination.Name, m.state.Pool, false)
+	migrated.BackendUUID = m.destination.BackendUUID
+	if err := o.storeClient.UpdateVolume(migrated); err != nil {
+		return fmt.Errorf("could not switch volume %s to backend %s: %v", m.state.Volume, m.destination.Name, err)
+	}
+
+	if original, ok := o.volumes[m.state.Volume]; ok {
+		o.deleteVolumeNameMapping(original)
+	}
+	delete(m.source.Volumes, m.state.Volume)
+	m.destination.Volumes[m.state.Volume] = migrated
+	o.volumes[m.state.Volume] = migrated
+	o.addVolumeNameMapping(migrated)
+	return nil
+}
+
+func (o *TridentOrchestrator) GetVolumeMigration(volumeName string) *storage.VolumeMigration {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	m, ok := o.migrations[volumeName]
+	if !ok {
+		return nil
+	}
+	stateCopy := *m.state
+	return &stateCopy
+}
+
+func (o *TridentOrchestrator) ListVolumeMigrations() []*storage.VolumeMigration {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	migrations := make([]*storage.VolumeMigration, 0, len(o.migrations))
+	for _, m := range o.migrations {
+		stateCopy := *m.state
+		migrations = append(migrations, &stateCopy)
+	}
+	return migrations
+}
+
+// CancelVolumeMigration abandons a migration that has yet to cut over, destroying the volume's
+// replica.  A migration still transferring data is cancelled in the background.  Finished
+// migrations are forgotten, deleting the original volume of one that cut over.
+func (o *TridentOrchestrator) CancelVolumeMigration(volumeName string) (bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	m, found := o.migrations[volumeName]
+	if !found {
+		return found, fmt.Errorf("volume %s is not being migrated", volumeName)
+	}
+	switch m.state.State {
+	case storage.MigrationTransferring:
+		m.cancel()
+	case storage.MigrationReady:
+		o.abandonMigration(m, errors.New("the migration was cancelled"))
+	case storage.MigrationCuttingOver:
+		return found, fmt.Errorf("volume %s is cutting over to backend %s", volumeName, m.destination.Name)
+	default:
+		if m.state.SourceRetained {
+			if err := o.deleteMigrationSource(m); err != nil {
+				return found, err
+			}
+		}
+		delete(o.migrations, volumeName)
+	}
+	return found, nil
+}
+
+// volumeMigrating returns whether a volume is being migrated, and so may not be deleted.
+func (o *TridentOrchestrator) volumeMigrating(volumeName string) bool {
+	m, ok := o.migrations[volumeName]
+	return ok && m.state.Active()
+}
+
+// rollBackMigration cleans up after a migration interrupted by a restart, deleting the replica.
+// If the volume had been switched to its destination, the migration is restored as cut over
+// instead, keeping the original until the migration is deleted, and true is returned so that its
+// transaction is kept.
+func (o *TridentOrchestrator) rollBackMigration(v *persistentstore.VolumeTransaction) (bool, error) {
+
+	m := v.Migration
+	if m == nil {
+		return false, fmt.Errorf("migration of volume %s was not recorded", v.Config.Name)
+	}
+
+	if volume, ok := o.volumes[m.Volume]; ok && volume.Backend == m.Backend {
+		source, sourceFound := o.backends[m.SourceBackend]
+		destination, destinationFound := o.backends[m.Backend]
+		if !sourceFound || !destinationFound {
+			log.WithFields(log.Fields{
+				"volume":        m.Volume,
+				"sourceBackend": m.SourceBackend,
+				"handler":       "Bootstrap",
+			}).Warn("Backend of cut over migration not found, forgetting the original volume.")
+			return false, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		migration := &volumeMigration{
+			state:       m,
+			source:      source,
+			destination: destination,
+			volTxn:      v,
+			ctx:         ctx,
+			cancel:      cancel,
+		}
+		o.retainMigrationSource(migration)
+		o.migrations[m.Volume] = migration
+		return true, nil
+	}
+
+	backendName, internalName := m.Backend, m.InternalName
+
+	logFields := log.Fields{
+		"volume":       m.Volume,
+		"backend":      backendName,
+		"internalName": internalName,
+		"handler":      "Bootstrap",
+	}
+
+	backend, ok := o.backends[backendName]
+	if !ok {
+		log.WithFields(logFields).Warn("Backend of interrupted migration not found.")
+		return false, nil
+	}
+	if source, ok := o.backends[m.SourceBackend]; ok && m.Method == storage.MigrationMethodReplication {
+		if _, ok := backend.Driver.(storage.MigrationDriver); ok {
+			if err := backend.Guarded().DeleteReplica(context.Background(), internalName, m.SourceInternalName,
+				source.Driver); err != nil {
+				return false, fmt.Errorf("unable to clean up migration of volume %s: %v", m.Volume, err)
+			}
+			log.WithFields(logFields).Info("Deleted volume left over from interrupted migration.")
+			return false, nil
+		}
+	}
+	if err := backend.Guarded().Destroy(context.Background(), internalName); err != nil {
+		return false, fmt.Errorf("unable to clean up migration of volume %s: %v", m.Volume, err)
+	}
+	log.WithFields(logFields).Info("Deleted volume left over from interrupted migration.")
+	return false, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/storage"
+)
+
+// bootstrapVolumeNames loads the mappings of volumes' internal names.  Mappings of volumes that
+// no longer exist, or that have since been renamed on their storage, are deleted, and volumes
+// stored before mappings were kept are given one, so the table matches the volumes exactly.
+func (o *TridentOrchestrator) bootstrapVolumeNames() error {
+	mappings, err := o.storeClient.GetVolumeNameMappings()
+	if err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		vol, ok := o.volumes[mapping.Volume]
+		if !ok || vol.BackendUUID != mapping.BackendUUID || vol.Config.InternalName != mapping.InternalName {
+			log.WithFields(log.Fields{
+				"volume":       mapping.Volume,
+				"internalName": mapping.InternalName,
+				"handler":      "Bootstrap",
+			}).Debug("Deleting stale volume name mapping.")
+			if err = o.storeClient.DeleteVolumeNameMapping(mapping); err != nil {
+				return err
+			}
+			continue
+		}
+		o.volumeNames[mapping.Key()] = mapping
+	}
+	for _, vol := range o.volumes {
+		if _, ok := o.volumeNames[storage.NewVolumeNameMapping(vol).Key()]; !ok {
+			o.addVolumeNameMapping(vol)
+		}
+	}
+	return nil
+}
+
+// addVolumeNameMapping records the internal name of a new volume.  A mapping that can't be saved
+// is only logged, as it is recreated from the volume the next time Trident starts.  The caller
+// must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) addVolumeNameMapping(vol *storage.Volume) {
+	mapping := storage.NewVolumeNameMapping(vol)
+	if err := o.storeClient.AddVolumeNameMapping(mapping); err != nil {
+		log.WithFields(log.Fields{
+			"volume":       vol.Config.Name,
+			"internalName": vol.Config.InternalName,
+			"error":        err,
+		}).Warn("Unable to save volume name mapping.")
+	}
+	o.volumeNames[mapping.Key()] = mapping
+}
+
+// deleteVolumeNameMapping forgets the internal name of a volume that no longer exists on its
+// backend.  The caller must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) deleteVolumeNameMapping(vol *storage.Volume) {
+	mapping := storage.NewVolumeNameMapping(vol)
+	if err := o.storeClient.DeleteVolumeNameMapping(mapping); err != nil && !persistentstore.MatchKeyNotFoundErr(err) {
+		log.WithFields(log.Fields{
+			"volume":       vol.Config.Name,
+			"internalName": vol.Config.InternalName,
+			"error":        err,
+		}).Warn("Unable to delete volume name mapping.")
+	}
+	delete(o.volumeNames, mapping.Key())
+}
+
+// GetVolumeByInternalName returns the volume that a backend gave an internal name, such as an
+// ONTAP Flexvol's name, or nil if the backend has no such volume.
+func (o *TridentOrchestrator) GetVolumeByInternalName(backendName, internalName string) *storage.VolumeExternal {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backend, ok := o.backends[backendName]
+	if !ok {
+		return nil
+	}
+	mapping, ok := o.volumeNames[storage.VolumeNameMappingKey(backend.BackendUUID, internalName)]
+	if !ok {
+		return nil
+	}
+	vol, ok := o.volumes[mapping.Volume]
+	if !ok {
+		return nil
+	}
+	return vol.ConstructExternal()
+}
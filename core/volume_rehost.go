@@ -0,0 +1,167 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// RehostVolume moves a volume to another backend whose storage can take it over without copying
+// it, such as an ontap-nas backend managing another SVM of the same cluster, and binds the volume
+// to that backend.  Unlike a migration, the volume is unavailable only while the storage moves it.
+// A rehost that fails after the storage has moved the volume is finished by retrying it, or when
+// Trident next cleans up failed operations.
+func (o *TridentOrchestrator) RehostVolume(volumeName, backendName string) (*storage.VolumeExternal, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, ok := o.volumes[volumeName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	if volume.Orphaned {
+		return nil, fmt.Errorf("volume %s is orphaned", volumeName)
+	}
+	if volume.Config.IsCache() || len(o.volumeCaches(volumeName)) > 0 {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf("volume %s is a cache or is cached, so it cannot "+
+			"be rehosted", volumeName))
+	}
+	if o.volumeMigrating(volumeName) {
+		return nil, fmt.Errorf("volume %s is being migrated", volumeName)
+	}
+	source, ok := o.backends[volume.Backend]
+	if !ok {
+		return nil, fmt.Errorf("backend %s for volume %s not found", volume.Backend, volumeName)
+	}
+	destination, ok := o.backends[backendName]
+	if !ok {
+		return nil, fmt.Errorf("backend %s not found", backendName)
+	}
+	if destination == source {
+		return nil, fmt.Errorf("volume %s is already on backend %s", volumeName, destination.Name)
+	}
+	if !destination.Online {
+		return nil, fmt.Errorf("backend %s is offline", destination.Name)
+	}
+	if destination.Cordoned {
+		return nil, drivers.NewRetryableError(fmt.Sprintf("backend %s is cordoned", destination.Name))
+	}
+	if !destination.Guarded().CanRehostFrom(source.Driver) {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf("backend %s cannot rehost volumes from backend %s",
+			destination.Name, source.Name))
+	}
+	if !destination.SupportsOnDelete(volume.Config.OnDelete) {
+		return nil, fmt.Errorf("backend %s does not support onDelete %s of volume %s", destination.Name,
+			volume.Config.OnDelete, volumeName)
+	}
+	for _, vol := range destination.Volumes {
+		if vol.Config.InternalName == volume.Config.InternalName {
+			return nil, fmt.Errorf("volume %s on backend %s already has the name %s", vol.Config.Name,
+				destination.Name, volume.Config.InternalName)
+		}
+	}
+
+	volTxn := &persistentstore.VolumeTransaction{
+		Config:        volume.Config,
+		Op:            persistentstore.RehostVolume,
+		RehostBackend: destination.Name,
+		StartTime:     time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := o.storeClient.AddVolumeTransaction(volTxn); err != nil {
+		return nil, err
+	}
+
+	rehosted, err := o.rehostVolume(volume, source, destination)
+	if err != nil {
+		// Until the storage has moved the volume, there is nothing left to finish
+		if destination.Guarded().Get(volume.Config.InternalName) != nil {
+			if txnErr := o.storeClient.DeleteVolumeTransaction(volTxn); txnErr != nil {
+				log.WithField("volume", volumeName).Errorf("Could not delete volume transaction. %v", txnErr)
+			}
+		}
+		return nil, err
+	}
+	if err = o.storeClient.DeleteVolumeTransaction(volTxn); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"volume":        volumeName,
+		"sourceBackend": source.Name,
+		"backend":       destination.Name,
+	}).Info("Volume rehosted.")
+	return rehosted.ConstructExternal(), nil
+}
+
+// rehostVolume has the destination's storage take over a volume, which it does idempotently, and
+// then binds the volume to the destination in a single write to the persistent store.  The caller
+// must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) rehostVolume(
+	volume *storage.Volume, source, destination *storage.Backend,
+) (*storage.Volume, error) {
+
+	rehostedConfig := *volume.Config
+	if err := destination.Guarded().RehostVolume(context.Background(), &rehostedConfig,
+		source.Driver); err != nil {
+		return nil, fmt.Errorf("could not rehost volume %s to backend %s: %v", volume.Config.Name,
+			destination.Name, err)
+	}
+
+	rehosted := storage.NewVolume(&rehostedConfig, destination.Name, volume.Pool, false)
+	rehosted.BackendUUID = destination.BackendUUID
+	if err := o.storeClient.UpdateVolume(rehosted); err != nil {
+		return nil, fmt.Errorf("could not switch volume %s to backend %s: %v", volume.Config.Name,
+			destination.Name, err)
+	}
+
+	o.deleteVolumeNameMapping(volume)
+	delete(source.Volumes, volume.Config.Name)
+	destination.Volumes[volume.Config.Name] = rehosted
+	o.volumes[volume.Config.Name] = rehosted
+	o.addVolumeNameMapping(rehosted)
+	return rehosted, nil
+}
+
+// finishRehost completes a rehost interrupted after the storage may have moved the volume.  If
+// the volume is on the destination's storage, it is bound to the destination; otherwise the
+// rehost never happened and the volume stays where it is.
+func (o *TridentOrchestrator) finishRehost(v *persistentstore.VolumeTransaction) error {
+
+	volume, ok := o.volumes[v.Config.Name]
+	if !ok || volume.Backend == v.RehostBackend {
+		return nil
+	}
+	source, ok := o.backends[volume.Backend]
+	if !ok {
+		return fmt.Errorf("backend %s for volume %s not found", volume.Backend, v.Config.Name)
+	}
+	destination, ok := o.backends[v.RehostBackend]
+	if !ok {
+		log.WithFields(log.Fields{
+			"volume":  v.Config.Name,
+			"backend": v.RehostBackend,
+		}).Warn("Backend of interrupted rehost not found, leaving volume on its original backend.")
+		return nil
+	}
+	if destination.Guarded().Get(volume.Config.InternalName) != nil {
+		return nil
+	}
+
+	if _, err := o.rehostVolume(volume, source, destination); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"volume":        v.Config.Name,
+		"sourceBackend": source.Name,
+		"backend":       destination.Name,
+	}).Info("Finished interrupted volume rehost.")
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+const (
+	// MaxVolumeStatsInterval limits the time between two samples of volume statistics, so that
+	// sampling completes well within the REST server's timeout.
+	MaxVolumeStatsInterval = 20 * time.Second
+
+	// volumeStatsConcurrency limits how many volumes are sampled at once, so that sampling every
+	// volume doesn't flood their storage with requests.
+	volumeStatsConcurrency = 10
+)
+
+// volumeStatsTarget is a volume to be sampled, captured while holding the orchestrator lock so
+// that sampling, which may be slow, can happen without it.
+type volumeStatsTarget struct {
+	report       *storage.VolumeStatsReport
+	backend      *storage.Backend
+	internalName string
+}
+
+// GetVolumeStats samples the performance counters of a volume.  If an interval is given, the
+// volume is sampled again after it, and its rates over the interval are reported as well.
+func (o *TridentOrchestrator) GetVolumeStats(
+	volumeName string, interval time.Duration,
+) (*storage.VolumeStatsReport, error) {
+
+	if err := validateVolumeStatsInterval(interval); err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	vol, found := o.volumes[volumeName]
+	if !found {
+		o.mutex.Unlock()
+		return nil, fmt.Errorf("volume %s not found", volumeName)
+	}
+	backend, found := o.backends[vol.Backend]
+	if !found {
+		o.mutex.Unlock()
+		return nil, fmt.Errorf("backend %s for volume %s not found", vol.Backend, volumeName)
+	}
+	if !backend.SupportsVolumeStats() {
+		o.mutex.Unlock()
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support volume statistics", backend.GetDriverName()))
+	}
+	target := newVolumeStatsTarget(vol, backend)
+	o.mutex.Unlock()
+
+	sampleVolumeStats([]*volumeStatsTarget{target}, interval)
+	return target.report, nil
+}
+
+// ListVolumeStats samples the performance counters of every volume whose backend can report them,
+// sorted by volume name.  If an interval is given, the volumes are sampled again after it, and
+// their rates over the interval are reported as well.  Volumes that can't be sampled are reported
+// with an error.
+func (o *TridentOrchestrator) ListVolumeStats(interval time.Duration) ([]*storage.VolumeStatsReport, error) {
+
+	if err := validateVolumeStatsInterval(interval); err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	targets := make([]*volumeStatsTarget, 0, len(o.volumes))
+	for _, vol := range o.volumes {
+		if backend, found := o.backends[vol.Backend]; found && backend.SupportsVolumeStats() {
+			targets = append(targets, newVolumeStatsTarget(vol, backend))
+		}
+	}
+	o.mutex.Unlock()
+
+	sampleVolumeStats(targets, interval)
+
+	reports := make([]*storage.VolumeStatsReport, 0, len(targets))
+	for _, target := range targets {
+		reports = append(reports, target.report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Volume < reports[j].Volume })
+
+	return reports, nil
+}
+
+func validateVolumeStatsInterval(interval time.Duration) error {
+	if interval < 0 || interval > MaxVolumeStatsInterval {
+		return drivers.NewFatalError(fmt.Sprintf("the interval between samples must be from 0 to %v",
+			MaxVolumeStatsInterval))
+	}
+	return nil
+}
+
+func newVolumeStatsTarget(vol *storage.Volume, backend *storage.Backend) *volumeStatsTarget {
+	return &volumeStatsTarget{
+		report:       &storage.VolumeStatsReport{Volume: vol.Config.Name, Backend: backend.Name},
+		backend:      backend,
+		internalName: vol.Config.InternalName,
+	}
+}
+
+// sampleVolumeStats fills in the reports of the target volumes, sampling them twice if an interval
+// is given.
+func sampleVolumeStats(targets []*volumeStatsTarget, interval time.Duration) {
+
+	first, errs := sampleVolumes(targets)
+	if interval == 0 {
+		for i, target := range targets {
+			if errs[i] != nil {
+				target.report.Error = errs[i].Error()
+			}
+			target.report.Stats = first[i]
+		}
+		return
+	}
+
+	time.Sleep(interval)
+
+	second, secondErrs := sampleVolumes(targets)
+	for i, target := range targets {
+		switch {
+		case errs[i] != nil:
+			target.report.Error = errs[i].Error()
+		case secondErrs[i] != nil:
+			target.report.Error = secondErrs[i].Error()
+		default:
+			target.report.Stats = second[i]
+			target.report.Rates = second[i].RatesSince(first[i])
+		}
+	}
+}
+
+// sampleVolumes reads the counters of each volume, several at a time.
+func sampleVolumes(targets []*volumeStatsTarget) ([]*storage.VolumeStats, []error) {
+
+	stats := make([]*storage.VolumeStats, len(targets))
+	errs := make([]error, len(targets))
+	semaphore := make(chan struct{}, volumeStatsConcurrency)
+
+	wg := &sync.WaitGroup{}
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *volumeStatsTarget) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			stats[i], errs[i] = target.backend.Guarded().GetVolumeStats(target.internalName)
+		}(i, target)
+	}
+	wg.Wait()
+
+	return stats, errs
+}
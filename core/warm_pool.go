@@ -0,0 +1,383 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_attribute"
+	"github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
+)
+
+// warmPoolInterval is how often the warm pools are refilled after volumes are claimed from them.
+const warmPoolInterval = 30 * time.Second
+
+const warmPoolTask = "warm-pools"
+
+// warmVolumePrefix begins the names of volumes created for warm pools.
+const warmVolumePrefix = "warm-"
+
+// StartWarmPools keeps each storage class's warm pool filled for as long as Trident runs.
+func (o *TridentOrchestrator) StartWarmPools() {
+	if err := o.housekeeping.Schedule(utils.HousekeepingTask{
+		Name:     warmPoolTask,
+		Interval: warmPoolInterval,
+		Run:      o.fillWarmPools,
+	}); err != nil {
+		log.Errorf("Could not start maintaining warm pools. %v", err)
+	}
+}
+
+// validateWarmPool checks a storage class's warm pool settings.
+func validateWarmPool(scConfig *storageclass.Config) error {
+	if scConfig.WarmPoolSize < 0 {
+		return fmt.Errorf("invalid warmPoolSize %d for storage class %s", scConfig.WarmPoolSize, scConfig.Name)
+	}
+	if scConfig.WarmVolumeSize != "" {
+		if _, err := utils.ConvertSizeToBytes(scConfig.WarmVolumeSize); err != nil {
+			return fmt.Errorf("invalid warmVolumeSize for storage class %s: %v", scConfig.Name, err)
+		}
+	}
+	// A minimum IOPS is given each volume by a QoS policy named after it, which renaming the
+	// volume would leave behind
+	if _, ok := scConfig.Attributes[storageattribute.MinIOPS]; ok && scConfig.WarmPoolSize > 0 {
+		return fmt.Errorf("storage class %s may not have a warm pool, as it requests %s",
+			scConfig.Name, storageattribute.MinIOPS)
+	}
+	return nil
+}
+
+// warmPoolCanServe reports whether a request may be met with a volume from its class's warm pool.
+// Warm volumes are created with only their class's attributes, so a request that sets any option
+// applied when a volume is created needs a new volume.
+func warmPoolCanServe(volumeConfig *storage.VolumeConfig) bool {
+	return !volumeConfig.WarmPool &&
+		volumeConfig.CloneSourceVolume == "" &&
+		volumeConfig.CacheSourceVolume == "" &&
+		volumeConfig.SpaceReserve == "" &&
+		volumeConfig.SecurityStyle == "" &&
+		volumeConfig.SnapshotPolicy == "" &&
+		volumeConfig.ExportPolicy == "" &&
+		volumeConfig.SnapshotDir == "" &&
+		volumeConfig.UnixPermissions == "" &&
+		volumeConfig.BlockSize == "" &&
+		volumeConfig.Encryption == "" &&
+		volumeConfig.QoS == "" &&
+		volumeConfig.QoSType == "" &&
+		volumeConfig.OnDelete == "" &&
+		!volumeConfig.ReadOnly
+}
+
+// warmPoolPools returns the pools on which warm volumes may be created.
+func warmPoolPools(pools []*storage.Pool) []*storage.Pool {
+	warmPools := make([]*storage.Pool, 0, len(pools))
+	for _, pool := range pools {
+		if pool.Backend.SupportsWarmPool() {
+			warmPools = append(warmPools, pool)
+		}
+	}
+	return warmPools
+}
+
+// claimWarmVolume hands out a volume from the warm pool of the requested storage class, if one
+// suits the request, renaming it and growing it to the requested size.  It returns nil if the
+// request must be met with a new volume.  A warm volume that can't be claimed because it no longer
+// exists on its backend, such as one lost to an earlier failure, is dropped from the pool.  The
+// caller must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) claimWarmVolume(
+	ctx context.Context, volumeConfig *storage.VolumeConfig, pools []*storage.Pool,
+) *storage.Volume {
+
+	if !warmPoolCanServe(volumeConfig) {
+		return nil
+	}
+	requestedSize, err := utils.ConvertSizeToBytes(volumeConfig.Size)
+	if err != nil {
+		return nil
+	}
+	volSize, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	for _, warm := range o.warmVolumesFor(volumeConfig, pools, volSize) {
+		vol, err := o.claim(ctx, warm, volumeConfig)
+		if err == nil {
+			log.WithFields(log.Fields{
+				"volume":       vol.Config.Name,
+				"internalName": vol.Config.InternalName,
+				"warmVolume":   warm.Config.Name,
+				"backend":      vol.Backend,
+				"storageClass": vol.Config.StorageClass,
+			}).Info("Claimed volume from warm pool.")
+			return vol
+		}
+		log.WithFields(log.Fields{
+			"volume":     volumeConfig.Name,
+			"warmVolume": warm.Config.Name,
+			"backend":    warm.Backend,
+			"error":      err,
+		}).Warn("Could not claim volume from warm pool.")
+
+		// If the warm volume is intact, the request itself is at fault, and a new volume is
+		// left to report why
+		if backend := o.backendByUUID(warm.BackendUUID); backend != nil &&
+			backend.Guarded().Get(warm.Config.InternalName) == nil {
+			return nil
+		}
+		if err = o.deleteWarmVolume(warm); err != nil {
+			log.WithFields(log.Fields{
+				"warmVolume": warm.Config.Name,
+				"backend":    warm.Backend,
+				"error":      err,
+			}).Warn("Could not delete warm volume.")
+		}
+	}
+	return nil
+}
+
+// warmVolumesFor returns the warm volumes that could be claimed for a request, from pools the
+// request could otherwise be placed in.  The largest come first, as they need the least growing.
+func (o *TridentOrchestrator) warmVolumesFor(
+	volumeConfig *storage.VolumeConfig, pools []*storage.Pool, volSize uint64,
+) []*storage.Volume {
+
+	eligiblePools := make(map[string]bool)
+	for _, pool := range pools {
+		eligiblePools[pool.Backend.BackendUUID+"/"+pool.Name] = true
+	}
+
+	candidates := make([]*storage.Volume, 0)
+	sizes := make(map[string]uint64)
+	for _, warm := range o.warmVolumes {
+		if warm.Config.StorageClass != volumeConfig.StorageClass || !eligiblePools[warm.BackendUUID+"/"+warm.Pool] {
+			continue
+		}
+		if volumeConfig.Protocol != config.ProtocolAny && volumeConfig.Protocol != "" &&
+			volumeConfig.Protocol != warm.Config.Protocol {
+			continue
+		}
+		warmSize, err := strconv.ParseUint(warm.Config.Size, 10, 64)
+		if err != nil || (volSize > 0 && warmSize > volSize) {
+			// Volumes can't be shrunk
+			continue
+		}
+		sizes[warm.Config.Name] = warmSize
+		candidates = append(candidates, warm)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if sizes[candidates[i].Config.Name] != sizes[candidates[j].Config.Name] {
+			return sizes[candidates[i].Config.Name] > sizes[candidates[j].Config.Name]
+		}
+		return candidates[i].Config.Name < candidates[j].Config.Name
+	})
+	return candidates
+}
+
+// claim turns a warm volume into the requested volume.  The request is recorded as a volume
+// transaction, so that a claim interrupted by a restart is cleaned up like any other create.
+func (o *TridentOrchestrator) claim(
+	ctx context.Context, warm *storage.Volume, volumeConfig *storage.VolumeConfig,
+) (*storage.Volume, error) {
+
+	backend := o.backendByUUID(warm.BackendUUID)
+	if backend == nil {
+		return nil, fmt.Errorf("backend %s of warm volume %s not found", warm.Backend, warm.Config.Name)
+	}
+
+	volTxn, err := o.addVolumeTransaction(volumeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	claimConfig := *volumeConfig
+	if claimConfig.Protocol == config.ProtocolAny || claimConfig.Protocol == "" {
+		claimConfig.Protocol = warm.Config.Protocol
+	}
+	vol, err := backend.ClaimWarmVolume(ctx, warm, &claimConfig)
+	if err == nil {
+		if err = o.storeClient.AddVolume(vol); err != nil {
+			// The warm volume is already renamed, so rather than restore it, delete it
+			if cleanupErr := backend.RemoveVolume(context.Background(), vol); cleanupErr != nil {
+				// Leave the transaction, so the volume is deleted when Trident restarts
+				delete(backend.Volumes, vol.Config.Name)
+				log.WithFields(log.Fields{
+					"volume":  vol.Config.InternalName,
+					"backend": backend.Name,
+					"error":   cleanupErr,
+				}).Warn("Could not delete claimed volume after failing to save it.")
+				return nil, err
+			}
+		}
+	}
+	if txErr := o.storeClient.DeleteVolumeTransaction(volTxn); txErr != nil {
+		log.WithFields(log.Fields{
+			"volume": volumeConfig.Name,
+			"error":  txErr,
+		}).Warn("Unable to delete volume transaction.")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = o.storeClient.DeleteVolumeIgnoreNotFound(warm); err != nil {
+		log.WithFields(log.Fields{
+			"warmVolume": warm.Config.Name,
+			"error":      err,
+		}).Warn("Unable to delete claimed warm volume from the persistent store.")
+	}
+	delete(o.warmVolumes, warm.Config.Name)
+	o.volumes[vol.Config.Name] = vol
+	o.addVolumeNameMapping(vol)
+	return vol, nil
+}
+
+// fillWarmPools deletes warm volumes that their classes no longer need, then creates volumes for
+// each class whose warm pool is short.  The orchestrator's mutex is held only while each volume
+// is created or deleted, so requests may be served in between.
+func (o *TridentOrchestrator) fillWarmPools() {
+
+	o.mutex.Lock()
+	counts := make(map[string]int)
+	surplus := make([]*storage.Volume, 0)
+	names := make([]string, 0, len(o.warmVolumes))
+	for name := range o.warmVolumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		warm := o.warmVolumes[name]
+		sc, ok := o.storageClasses[warm.Config.StorageClass]
+		if !ok || counts[warm.Config.StorageClass] >= sc.GetWarmPoolSize() {
+			surplus = append(surplus, warm)
+			continue
+		}
+		counts[warm.Config.StorageClass]++
+	}
+	for _, warm := range surplus {
+		if err := o.deleteWarmVolume(warm); err != nil {
+			log.WithFields(log.Fields{
+				"warmVolume": warm.Config.Name,
+				"backend":    warm.Backend,
+				"error":      err,
+			}).Warn("Could not delete surplus warm volume.")
+		}
+	}
+	missing := make(map[string]int)
+	for name, sc := range o.storageClasses {
+		n := sc.GetWarmPoolSize() - counts[name]
+		if n <= 0 {
+			continue
+		}
+		if len(warmPoolPools(sc.GetStoragePoolsForProtocol(config.ProtocolAny))) == 0 {
+			log.WithField("storageClass", name).Debug("No backends of storage class support warm pools.")
+			continue
+		}
+		missing[name] = n
+	}
+	o.mutex.Unlock()
+
+	for scName, n := range missing {
+		for i := 0; i < n; i++ {
+			if err := o.addWarmVolume(scName); err != nil {
+				log.WithFields(log.Fields{
+					"storageClass": scName,
+					"error":        err,
+				}).Warn("Could not create volume for warm pool.")
+				break
+			}
+		}
+	}
+}
+
+// addWarmVolume creates a volume for a storage class's warm pool.
+func (o *TridentOrchestrator) addWarmVolume(scName string) error {
+
+	o.mutex.Lock()
+	sc, ok := o.storageClasses[scName]
+	if !ok {
+		o.mutex.Unlock()
+		return fmt.Errorf("unknown storage class: %s", scName)
+	}
+	size := sc.GetWarmVolumeSize()
+	o.mutex.Unlock()
+
+	// The backend's default size applies if the class sets none
+	if size == "" {
+		size = "0"
+	}
+	volumeConfig := &storage.VolumeConfig{
+		Name:         warmVolumePrefix + uuid.New(),
+		Size:         size,
+		Protocol:     config.ProtocolAny,
+		StorageClass: scName,
+		WarmPool:     true,
+	}
+	vol, err := o.AddVolume(context.Background(), volumeConfig)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"warmVolume":   vol.Config.Name,
+		"internalName": vol.Config.InternalName,
+		"backend":      vol.Backend,
+		"storageClass": scName,
+		"size":         vol.Config.Size,
+	}).Info("Created volume for warm pool.")
+	return nil
+}
+
+// deleteWarmVolume deletes a volume from its warm pool.  The caller must hold the orchestrator's
+// mutex.
+func (o *TridentOrchestrator) deleteWarmVolume(warm *storage.Volume) error {
+	if backend := o.backendByUUID(warm.BackendUUID); backend != nil {
+		if err := backend.RemoveVolume(context.Background(), warm); err != nil {
+			return err
+		}
+	}
+	if err := o.storeClient.DeleteVolumeIgnoreNotFound(warm); err != nil {
+		return err
+	}
+	delete(o.warmVolumes, warm.Config.Name)
+	return nil
+}
+
+// deleteWarmVolumesForBackend deletes a backend's warm volumes, which were never handed out and
+// so needn't keep an offline backend around.  The caller must hold the orchestrator's mutex.
+func (o *TridentOrchestrator) deleteWarmVolumesForBackend(backend *storage.Backend) {
+	for _, warm := range o.warmVolumes {
+		if warm.BackendUUID != backend.BackendUUID {
+			continue
+		}
+		if err := o.deleteWarmVolume(warm); err != nil {
+			log.WithFields(log.Fields{
+				"warmVolume": warm.Config.Name,
+				"backend":    backend.Name,
+				"error":      err,
+			}).Warn("Could not delete warm volume of offline backend.")
+		}
+	}
+}
+
+// countWarmVolumes returns the number of volumes in a storage class's warm pool.  The caller must
+// hold the orchestrator's mutex.
+func (o *TridentOrchestrator) countWarmVolumes(scName string) int {
+	count := 0
+	for _, warm := range o.warmVolumes {
+		if warm.Config.StorageClass == scName {
+			count++
+		}
+	}
+	return count
+}
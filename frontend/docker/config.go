@@ -2,7 +2,13 @@
 
 package docker
 
+import "time"
+
 const (
 	pluginName             = "docker"
 	autoStorageClassPrefix = "auto_sc_%d"
+
+	// dockerRequestTimeout is how long Docker waits for a volume plugin to create or remove a
+	// volume before it gives up on the request.
+	dockerRequestTimeout = 2 * time.Minute
 )
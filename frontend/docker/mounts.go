@@ -0,0 +1,140 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// mountsFile holds the IDs of the mounts of each volume, in the plugin's volume directory, so that a
+// volume shared by containers stays attached until the last of them unmounts it even if the plugin
+// restarts in between.
+const mountsFile = ".mounts.json"
+
+func (p *Plugin) mountsPath() string {
+	return filepath.Join(p.volumePath, mountsFile)
+}
+
+// loadMounts reads the mounts saved before the plugin last stopped.  If they can't be read, mounts
+// made before then aren't tracked, and unmounting them detaches their volume.
+func (p *Plugin) loadMounts() {
+
+	p.mountMutex.Lock()
+	defer p.mountMutex.Unlock()
+
+	contents, err := ioutil.ReadFile(p.mountsPath())
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		log.WithField("path", p.mountsPath()).Warnf("Could not read volume mounts. %v", err)
+		return
+	}
+
+	mounts := make(map[string][]string)
+	if err = json.Unmarshal(contents, &mounts); err != nil {
+		log.WithField("path", p.mountsPath()).Warnf("Could not parse volume mounts. %v", err)
+		return
+	}
+	for name, ids := range mounts {
+		p.mounts[name] = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			p.mounts[name][id] = true
+		}
+		log.WithFields(log.Fields{
+			"name":   name,
+			"mounts": len(ids),
+		}).Debug("Restored volume mounts.")
+	}
+}
+
+// saveMounts writes the mounts of each volume, replacing the file so that a crash doesn't leave it
+// partly written.  The caller must hold the mount mutex.
+func (p *Plugin) saveMounts() {
+
+	mounts := make(map[string][]string, len(p.mounts))
+	for name := range p.mounts {
+		mounts[name] = p.mountIDs(name)
+	}
+	contents, err := json.Marshal(mounts)
+	if err != nil {
+		log.Errorf("Could not save volume mounts. %v", err)
+		return
+	}
+
+	tempPath := p.mountsPath() + ".tmp"
+	if err = ioutil.WriteFile(tempPath, contents, 0600); err == nil {
+		err = os.Rename(tempPath, p.mountsPath())
+	}
+	if err != nil {
+		log.WithField("path", p.mountsPath()).Errorf("Could not save volume mounts. %v", err)
+	}
+}
+
+// mountIDs returns the sorted IDs of a volume's mounts.  The caller must hold the mount mutex.
+func (p *Plugin) mountIDs(name string) []string {
+	ids := make([]string, 0, len(p.mounts[name]))
+	for id := range p.mounts[name] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkNotMounted returns an error if containers on this host still have a volume mounted.
+func (p *Plugin) checkNotMounted(name string) error {
+
+	p.mountMutex.Lock()
+	defer p.mountMutex.Unlock()
+
+	if count := len(p.mounts[name]); count > 0 {
+		return fmt.Errorf("volume %s is mounted by %d container(s) on this host; unmount it or force it "+
+			"to be detached first", name, count)
+	}
+	return nil
+}
+
+// ForceDetachVolume detaches a volume from this host whatever containers Docker last said were
+// using it, such as when a container's unmount was lost and left the volume attached.  It returns
+// the IDs of the mounts it released.
+func (p *Plugin) ForceDetachVolume(name string) ([]string, error) {
+
+	log.WithFields(log.Fields{
+		"method": "ForceDetachVolume",
+		"name":   name,
+	}).Debug("Docker frontend method is invoked.")
+
+	tridentVol := p.orchestrator.GetVolume(name)
+	if tridentVol == nil {
+		return nil, fmt.Errorf("volume %s not found", name)
+	}
+
+	mountpoint := p.mountpoint(tridentVol.Config.InternalName)
+
+	p.mountMutex.Lock()
+	defer p.mountMutex.Unlock()
+
+	released := p.mountIDs(name)
+	if len(released) > 0 {
+		log.WithFields(log.Fields{
+			"name":   name,
+			"mounts": released,
+		}).Warn("Forcing volume to be detached while mounted.")
+	}
+
+	if err := p.orchestrator.DetachVolume(name, mountpoint); err != nil {
+		log.Error(err)
+		return nil, fmt.Errorf("error detaching volume %v, mountpoint %v, error: %v", name, mountpoint, err)
+	}
+
+	delete(p.mounts, name)
+	p.saveMounts()
+
+	return released, nil
+}
@@ -3,12 +3,15 @@
 package docker
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 
@@ -17,6 +20,8 @@ import (
 
 	"github.com/netapp/trident/core"
 	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
 )
 
 type Plugin struct {
@@ -26,10 +31,25 @@ type Plugin struct {
 	volumePath   string
 	version      *Version
 	mutex        *sync.Mutex
+
+	// Docker mounts a volume once for each container that uses it, and expects the plugin to
+	// detach it only when the last of them unmounts it, so the mount IDs are tracked by volume
+	// and saved in case the plugin restarts.
+	mounts     map[string]map[string]bool
+	mountMutex *sync.Mutex
+
+	// Operations started for Docker's requests are cancelled when the plugin is deactivated
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewPlugin(driverName, driverPort string, orchestrator core.Orchestrator) (*Plugin, error) {
 
+	// Docker reaches plugins on Windows hosts over TCP, as it has no Unix sockets there
+	if runtime.GOOS == utils.Windows && driverPort == "" {
+		return nil, fmt.Errorf("the Docker plugin must listen on a TCP port on Windows; set driver_port")
+	}
+
 	// Get the Docker version
 	version, err := getDockerVersion()
 	if err != nil {
@@ -39,6 +59,7 @@ func NewPlugin(driverName, driverPort string, orchestrator core.Orchestrator) (*
 	}
 
 	// Create the plugin object
+	ctx, cancel := context.WithCancel(context.Background())
 	plugin := &Plugin{
 		orchestrator: orchestrator,
 		driverName:   driverName,
@@ -46,11 +67,16 @@ func NewPlugin(driverName, driverPort string, orchestrator core.Orchestrator) (*
 		volumePath:   filepath.Join(volume.DefaultDockerRootDirectory, driverName),
 		version:      version,
 		mutex:        &sync.Mutex{},
+		mounts:       make(map[string]map[string]bool),
+		mountMutex:   &sync.Mutex{},
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
 	// Register the plugin with Docker
 	err = registerDockerVolumePlugin(plugin.volumePath)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
@@ -59,6 +85,8 @@ func NewPlugin(driverName, driverPort string, orchestrator core.Orchestrator) (*
 		"volumeDriver": driverName,
 	}).Info("Initializing Trident plugin for Docker.")
 
+	plugin.loadMounts()
+
 	return plugin, nil
 }
 
@@ -134,6 +162,7 @@ func (p *Plugin) Activate() error {
 }
 
 func (p *Plugin) Deactivate() error {
+	p.cancel()
 	return nil
 }
 
@@ -141,6 +170,13 @@ func (p *Plugin) GetName() string {
 	return pluginName
 }
 
+// requestContext returns a context for the orchestrator operations of one of Docker's requests,
+// which is cancelled once Docker stops waiting for the answer or the plugin is deactivated.
+func (p *Plugin) requestContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(p.ctx, dockerRequestTimeout)
+	return utils.WithRequester(ctx, pluginName), cancel
+}
+
 func (p *Plugin) Version() string {
 	if p.version == nil {
 		return "unknown"
@@ -170,10 +206,12 @@ func (p *Plugin) Create(request *volume.CreateRequest) error {
 	}
 
 	// Invoke the orchestrator to create or clone the new volume
+	ctx, cancel := p.requestContext()
+	defer cancel()
 	if volConfig.CloneSourceVolume != "" {
-		_, err = p.orchestrator.CloneVolume(volConfig)
+		_, err = p.orchestrator.CloneVolume(ctx, volConfig)
 	} else {
-		_, err = p.orchestrator.AddVolume(volConfig)
+		_, err = p.orchestrator.AddVolume(ctx, volConfig)
 	}
 	return err
 }
@@ -225,9 +263,8 @@ func (p *Plugin) Get(request *volume.GetRequest) (*volume.GetResponse, error) {
 	if err != nil {
 		return &volume.GetResponse{}, err
 	}
-	status := map[string]interface{}{
-		"Snapshots": snapshots,
-	}
+	status := p.volumeStatus(tridentVol)
+	status["Snapshots"] = snapshots
 
 	// Get the mountpoint, if this volume is mounted
 	mountpoint, _ := p.getPath(tridentVol)
@@ -248,7 +285,13 @@ func (p *Plugin) Remove(request *volume.RemoveRequest) error {
 		"name":   request.Name,
 	}).Debug("Docker frontend method is invoked.")
 
-	found, err := p.orchestrator.DeleteVolume(request.Name)
+	if err := p.checkNotMounted(request.Name); err != nil {
+		return err
+	}
+
+	ctx, cancel := p.requestContext()
+	defer cancel()
+	found, err := p.orchestrator.DeleteVolume(ctx, request.Name)
 	if !found {
 		log.WithField("volume", request.Name).Warn("Volume not found.")
 	}
@@ -291,6 +334,20 @@ func (p *Plugin) Mount(request *volume.MountRequest) (*volume.MountResponse, err
 	mountpoint := p.mountpoint(tridentVol.Config.InternalName)
 	options := make(map[string]string)
 
+	p.mountMutex.Lock()
+	defer p.mountMutex.Unlock()
+
+	// Another container on this host already has the volume attached
+	if len(p.mounts[request.Name]) > 0 {
+		p.mounts[request.Name][request.ID] = true
+		p.saveMounts()
+		log.WithFields(log.Fields{
+			"name":   request.Name,
+			"mounts": len(p.mounts[request.Name]),
+		}).Debug("Volume already attached.")
+		return &volume.MountResponse{Mountpoint: mountpoint}, nil
+	}
+
 	err := p.orchestrator.AttachVolume(request.Name, mountpoint, options)
 	if err != nil {
 		log.Error(err)
@@ -298,6 +355,9 @@ func (p *Plugin) Mount(request *volume.MountRequest) (*volume.MountResponse, err
 		return &volume.MountResponse{}, err
 	}
 
+	p.mounts[request.Name] = map[string]bool{request.ID: true}
+	p.saveMounts()
+
 	return &volume.MountResponse{Mountpoint: mountpoint}, nil
 }
 
@@ -316,12 +376,30 @@ func (p *Plugin) Unmount(request *volume.UnmountRequest) error {
 
 	mountpoint := p.mountpoint(tridentVol.Config.InternalName)
 
+	p.mountMutex.Lock()
+	defer p.mountMutex.Unlock()
+
+	// Leave the volume attached while other containers on this host use it.  Mounts that weren't
+	// tracked, such as if the saved mounts couldn't be read, detach the volume as they always have.
+	delete(p.mounts[request.Name], request.ID)
+	if len(p.mounts[request.Name]) > 0 {
+		p.saveMounts()
+		log.WithFields(log.Fields{
+			"name":   request.Name,
+			"mounts": len(p.mounts[request.Name]),
+		}).Debug("Volume still in use, not detaching.")
+		return nil
+	}
+
 	err := p.orchestrator.DetachVolume(request.Name, mountpoint)
 	if err != nil {
 		log.Error(err)
 		return fmt.Errorf("error detaching volume %v, mountpoint %v, error: %v", request.Name, mountpoint, err)
 	}
 
+	delete(p.mounts, request.Name)
+	p.saveMounts()
+
 	return nil
 }
 
@@ -334,6 +412,53 @@ func (p *Plugin) Capabilities() *volume.CapabilitiesResponse {
 	return &volume.CapabilitiesResponse{Capabilities: volume.Capability{Scope: "global"}}
 }
 
+// volumeStatus returns the details of a volume shown by 'docker volume inspect'.  Usage and
+// performance counters are only included if the volume's backend can report them.
+func (p *Plugin) volumeStatus(vol *storage.VolumeExternal) map[string]interface{} {
+
+	status := map[string]interface{}{
+		"Backend":   vol.Backend,
+		"Pool":      vol.Pool,
+		"SizeBytes": vol.Config.Size,
+	}
+	if vol.Config.StorageClass != "" {
+		status["StorageClass"] = vol.Config.StorageClass
+	}
+
+	accessInfo := vol.Config.AccessInfo
+	if accessInfo.NfsServerIP != "" {
+		status["ExportPath"] = accessInfo.NfsServerIP + ":" + accessInfo.NfsPath
+	}
+	if accessInfo.SmbPath != "" {
+		status["SharePath"] = accessInfo.SmbPath
+	}
+	if accessInfo.IscsiTargetIQN != "" {
+		status["TargetIQN"] = accessInfo.IscsiTargetIQN
+		status["LUN"] = accessInfo.IscsiLunNumber
+	}
+
+	logFields := log.Fields{"name": vol.Config.Name, "backend": vol.Backend}
+
+	usedBytes, err := p.orchestrator.GetVolumeUsedBytes(vol.Config.Name)
+	if err == nil {
+		status["UsedBytes"] = usedBytes
+	} else if !drivers.IsUnsupportedError(err) {
+		log.WithFields(logFields).Warnf("Could not read the space used by the volume. %v", err)
+	}
+
+	report, err := p.orchestrator.GetVolumeStats(vol.Config.Name, 0)
+	if err == nil && report.Error != "" {
+		err = errors.New(report.Error)
+	}
+	if err == nil {
+		status["Stats"] = report.Stats
+	} else if !drivers.IsUnsupportedError(err) {
+		log.WithFields(logFields).Warnf("Could not read the volume's performance counters. %v", err)
+	}
+
+	return status
+}
+
 // getPath returns the mount point if the path exists.
 func (p *Plugin) getPath(vol *storage.VolumeExternal) (string, error) {
 
@@ -0,0 +1,63 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package docker
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/core"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_class"
+)
+
+func TestVolumeStatus(t *testing.T) {
+	orchestrator := core.NewMockOrchestrator()
+	orchestrator.AddMockONTAPNFSBackend("ontapnas", "10.0.0.1")
+	if _, err := orchestrator.AddStorageClass(&storageclass.Config{Name: "gold"}); err != nil {
+		t.Fatal("Unable to add storage class: ", err)
+	}
+	p := &Plugin{orchestrator: orchestrator, mutex: &sync.Mutex{}}
+
+	vol, err := orchestrator.AddVolume(context.Background(), &storage.VolumeConfig{
+		Name:         "vol1",
+		Size:         "1073741824",
+		StorageClass: "gold",
+		Protocol:     config.File,
+	})
+	if err != nil {
+		t.Fatal("Unable to add volume: ", err)
+	}
+
+	status := p.volumeStatus(vol)
+	if status["Backend"] != "ontapnas" || status["Pool"] != vol.Pool || status["StorageClass"] != "gold" {
+		t.Errorf("Expected backend ontapnas, pool %s and storage class gold, got %v", vol.Pool, status)
+	}
+	if exportPath := "10.0.0.1:" + vol.Config.AccessInfo.NfsPath; status["ExportPath"] != exportPath {
+		t.Errorf("Expected export path %s, got %v", exportPath, status["ExportPath"])
+	}
+	if _, ok := status["TargetIQN"]; ok {
+		t.Errorf("Expected no target IQN for an NFS volume, got %v", status["TargetIQN"])
+	}
+	if usedBytes, ok := status["UsedBytes"].(uint64); !ok || usedBytes != 0 {
+		t.Errorf("Expected the used bytes, got %v", status["UsedBytes"])
+	}
+	if stats, ok := status["Stats"].(*storage.VolumeStats); !ok || stats == nil {
+		t.Errorf("Expected the volume's stats, got %v", status["Stats"])
+	}
+
+	// Usage the orchestrator can't read is left out rather than failing the inspect
+	missing := &storage.VolumeExternal{Config: &storage.VolumeConfig{Name: "missing"}, Backend: "ontapnas"}
+	status = p.volumeStatus(missing)
+	if _, ok := status["UsedBytes"]; ok {
+		t.Errorf("Expected no used bytes for an unknown volume, got %v", status["UsedBytes"])
+	}
+	if _, ok := status["Stats"]; ok {
+		t.Errorf("Expected no stats for an unknown volume, got %v", status["Stats"])
+	}
+	if _, ok := status["ExportPath"]; ok {
+		t.Errorf("Expected no export path without access info, got %v", status["ExportPath"])
+	}
+}
@@ -4,6 +4,7 @@ package docker
 
 import (
 	"fmt"
+	"strconv"
 
 	hash "github.com/mitchellh/hashstructure"
 	log "github.com/sirupsen/logrus"
@@ -17,11 +18,22 @@ import (
 )
 
 // getStorageClass accepts a list of volume creation options and returns a
-// matching storage class.  If the orchestrator already has a matching
-// storage class, that is returned; otherwise a new one is created and
-// registered with the orchestrator.
+// matching storage class.  If the options name a storage class, such as one
+// added with tridentctl, that class is returned.  Otherwise, if the
+// orchestrator already has a matching storage class, that is returned; if
+// not, a new one is created and registered with the orchestrator.
 func getStorageClass(options map[string]string, o core.Orchestrator) (*storageclass.Config, error) {
 
+	if scName := utils.GetV(options, "storageClass", ""); scName != "" {
+		delete(options, "storageClass")
+		sc := o.GetStorageClass(scName)
+		if sc == nil {
+			return nil, fmt.Errorf("unknown storage class: %s", scName)
+		}
+		log.WithField("storageClass", scName).Debug("Using the named storage class.")
+		return sc.Config, nil
+	}
+
 	// Create a storage class based on available options
 	newScConfig, err := makeStorageClass(options, o)
 	if err != nil {
@@ -94,6 +106,11 @@ func getVolumeConfig(name, storageClass string, opts map[string]string) (*storag
 	}
 	delete(opts, "size")
 
+	readOnly, err := strconv.ParseBool(utils.GetV(opts, "readOnly", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for readOnly: %v", err)
+	}
+
 	return &storage.VolumeConfig{
 		Name:                name,
 		Size:                fmt.Sprintf("%d", sizeBytes),
@@ -111,8 +128,13 @@ func getVolumeConfig(name, storageClass string, opts map[string]string) (*storag
 		QoS:                 utils.GetV(opts, "qos", ""),
 		QoSType:             utils.GetV(opts, "type", ""),
 		FileSystem:          utils.GetV(opts, "fstype|fileSystemType", ""),
+		FileSystemOwner:     utils.GetV(opts, "fileSystemOwner", ""),
+		FileSystemMode:      utils.GetV(opts, "fileSystemMode", ""),
 		Encryption:          utils.GetV(opts, "encryption", ""),
 		CloneSourceVolume:   utils.GetV(opts, "from", ""),
 		CloneSourceSnapshot: utils.GetV(opts, "fromSnapshot", ""),
+		OnDelete:            utils.GetV(opts, "onDelete", ""),
+		ReadOnly:            readOnly,
+		CacheSourceVolume:   utils.GetV(opts, "cacheFrom", ""),
 	}, nil
 }
@@ -8,3 +8,11 @@ type Plugin interface {
 	GetName() string
 	Version() string
 }
+
+// VolumeMounter is implemented by frontends that mount volumes on the local host for their users,
+// so that a volume left attached, such as by a lost unmount, may be detached by an administrator.
+type VolumeMounter interface {
+	// ForceDetachVolume detaches a volume from the local host even if the frontend believes it
+	// is still in use, returning the IDs of the mounts it released.
+	ForceDetachVolume(volumeName string) ([]string, error)
+}
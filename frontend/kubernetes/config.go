@@ -11,6 +11,14 @@ import (
 const (
 	KubernetesSyncPeriod = 60 * time.Second
 
+	// Backoff bounds for claims whose provisioning failed with a transient error
+	ClaimRetryInitialInterval = KubernetesSyncPeriod
+	ClaimRetryMaxInterval     = 16 * KubernetesSyncPeriod
+
+	// Time allowed for each volume operation made for a claim or PV before it is cancelled, after
+	// which it is retried like any other failure
+	VolumeOperationTimeout = 10 * time.Minute
+
 	// Kubernetes-defined storage class parameters
 	K8sFsType = "fsType"
 
@@ -31,6 +39,7 @@ const (
 	AnnSnapshotPolicy  = AnnPrefix + "/snapshotPolicy"
 	AnnSnapshotDir     = AnnPrefix + "/snapshotDirectory"
 	AnnUnixPermissions = AnnPrefix + "/unixPermissions"
+	AnnSecurityStyle   = AnnPrefix + "/securityStyle"
 	AnnVendor          = AnnPrefix + "/vendor"
 	AnnBackendID       = AnnPrefix + "/backendID"
 	AnnExportPolicy    = AnnPrefix + "/exportPolicy"
@@ -38,4 +47,8 @@ const (
 	AnnFileSystem      = AnnPrefix + "/fileSystem"
 	AnnCloneFromPVC    = AnnPrefix + "/cloneFromPVC"
 	AnnSplitOnClone    = AnnPrefix + "/splitOnClone"
+	AnnOnDelete        = AnnPrefix + "/onDelete"
+	AnnReadOnly        = AnnPrefix + "/readOnly"
+	AnnCacheFromPVC    = AnnPrefix + "/cacheFromPVC"
+	AnnLunWWID         = AnnPrefix + "/lunWWID"
 )
@@ -5,15 +5,19 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	k8sstoragev1 "k8s.io/api/storage/v1"
 	k8sstoragev1beta "k8s.io/api/storage/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sversion "k8s.io/apimachinery/pkg/version"
@@ -84,6 +88,7 @@ type Plugin struct {
 	classSource              cache.ListerWatcher
 	mutex                    *sync.Mutex
 	pendingClaimMatchMap     map[string]*v1.PersistentVolume
+	failedClaims             map[string]*claimFailure
 	kubernetesVersion        *k8sversion.Info
 	defaultStorageClasses    map[string]bool
 	storageClassCache        map[string]*StorageClassSummary
@@ -145,6 +150,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 		classControllerStopChan:  make(chan struct{}),
 		mutex:                 &sync.Mutex{},
 		pendingClaimMatchMap:  make(map[string]*v1.PersistentVolume),
+		failedClaims:          make(map[string]*claimFailure),
 		defaultStorageClasses: make(map[string]bool, 1),
 		storageClassCache:     make(map[string]*StorageClassSummary),
 		tridentNamespace:      tridentNamespace,
@@ -291,6 +297,21 @@ func (p *Plugin) GetName() string {
 	return "kubernetes"
 }
 
+// operationContext returns a context that names the requester of an orchestrator operation, and
+// that is cancelled once VolumeOperationTimeout has passed or the frontend is deactivated, so that
+// storage that stops responding doesn't hold up the frontend's controllers indefinitely.
+func (p *Plugin) operationContext(requester string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), VolumeOperationTimeout)
+	go func() {
+		select {
+		case <-p.claimControllerStopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return k8sutilversion.WithRequester(ctx, requester), cancel
+}
+
 func (p *Plugin) Version() string {
 	return p.kubernetesVersion.GitVersion
 }
@@ -546,7 +567,9 @@ func (p *Plugin) processLostClaim(claim *v1.PersistentVolumeClaim) {
 	if p.orchestrator.GetVolume(volName) == nil {
 		return
 	}
-	_, err := p.orchestrator.DeleteVolume(volName)
+	ctx, cancel := p.operationContext(p.GetName())
+	defer cancel()
+	_, err := p.orchestrator.DeleteVolume(ctx, volName)
 	if err != nil {
 		message := "Kubernetes frontend failed to delete the provisioned " +
 			"volume for the lost PVC (will retry upon resync)."
@@ -577,6 +600,7 @@ func (p *Plugin) processDeletedClaim(claim *v1.PersistentVolumeClaim) {
 	// Remove the pending claim, if present.
 	p.mutex.Lock()
 	delete(p.pendingClaimMatchMap, getUniqueClaimName(claim))
+	delete(p.failedClaims, getUniqueClaimName(claim))
 	p.mutex.Unlock()
 }
 
@@ -585,6 +609,17 @@ func (p *Plugin) processPendingClaim(claim *v1.PersistentVolumeClaim) {
 	orchestratorClaimName := getUniqueClaimName(claim)
 	p.mutex.Lock()
 
+	// Don't hammer the backends with a claim that failed recently or can never succeed as written
+	if failure, ok := p.failedClaims[orchestratorClaimName]; ok && failure.shouldSkip(claim) {
+		p.mutex.Unlock()
+		log.WithFields(log.Fields{
+			"PVC":         claim.Name,
+			"fatal":       failure.fatal,
+			"nextAttempt": failure.nextAttempt,
+		}).Debug("Kubernetes frontend is deferring a previously failed claim.")
+		return
+	}
+
 	// Check whether we have already provisioned a PV for this claim
 	if pv, ok := p.pendingClaimMatchMap[orchestratorClaimName]; ok {
 		// If there's an entry for this claim in the pending claim match
@@ -617,17 +652,20 @@ func (p *Plugin) processPendingClaim(claim *v1.PersistentVolumeClaim) {
 	// We need to provision a new volume for this claim.
 	pv, err := p.createVolumeAndPV(orchestratorClaimName, claim)
 	if err != nil {
-		if pv == nil {
-			p.updateClaimWithEvent(claim, v1.EventTypeNormal,
-				"ProvisioningFailed", err.Error())
+		p.mutex.Lock()
+		failure := p.recordClaimFailure(orchestratorClaimName, claim, err)
+		p.mutex.Unlock()
+		message := provisioningFailureMessage(err, failure)
+		if failure.fatal || pv != nil {
+			p.updateClaimWithEvent(claim, v1.EventTypeWarning, "ProvisioningFailed", message)
 		} else {
-			p.updateClaimWithEvent(claim, v1.EventTypeWarning,
-				"ProvisioningFailed", err.Error())
+			p.updateClaimWithEvent(claim, v1.EventTypeNormal, "ProvisioningFailed", message)
 		}
 		return
 	}
 	p.mutex.Lock()
 	p.pendingClaimMatchMap[orchestratorClaimName] = pv
+	delete(p.failedClaims, orchestratorClaimName)
 	p.mutex.Unlock()
 	message := "Kubernetes frontend provisioned a volume and a PV for the PVC."
 	p.updateClaimWithEvent(claim, v1.EventTypeNormal,
@@ -651,8 +689,10 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 	defer func() {
 		if vol != nil && err != nil {
 			err1 := err
-			// Delete the volume on the backend
-			_, err = p.orchestrator.DeleteVolume(vol.Config.Name)
+			// Delete the volume on the backend, allowing it time even if the create ran out of it
+			ctx, cancel := p.operationContext(p.GetName())
+			defer cancel()
+			_, err = p.orchestrator.DeleteVolume(ctx, vol.Config.Name)
 			if err != nil {
 				err2 := "Kubernetes frontend couldn't delete the volume " +
 					"after failed creation: " + err.Error()
@@ -683,6 +723,16 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 		annotations[AnnClass] = GetPersistentVolumeClaimClass(claim)
 	}
 
+	// Mount options given by the claim take the place of the storage class's, so they are held to
+	// the same policy before anything is created
+	var claimMountOptions []string
+	if options := getAnnotation(annotations, AnnMountOptions); options != "" {
+		claimMountOptions = k8sutilversion.ParseMountOptions(options)
+		if err = k8sutilversion.ValidateMountOptions(claimMountOptions); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %v", AnnMountOptions, err)
+		}
+	}
+
 	// Set the file system type based on the value in the storage class
 	if _, found := annotations[AnnFileSystem]; !found && storageClassParams != nil {
 		if fsType, found := storageClassParams[K8sFsType]; found {
@@ -698,10 +748,35 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 			claim.Namespace, err.Error())
 	}
 
+	// Relay the orchestrator's progress to the claim as events, giving up on the claim until it is
+	// retried if it isn't provisioned in time
+	ctx, cancel := p.operationContext(p.GetName() + ":" + claim.Namespace + "/" + claim.Name)
+	defer cancel()
+	ctx = drivers.WithProgressReporter(ctx, &claimProgressReporter{p, claim})
+
 	// Create the volume configuration object
 	volConfig := getVolumeConfig(accessModes, uniqueName, size, annotations)
+	volConfig.Namespace = claim.Namespace
+	volConfig.RequestName = claim.Name
+
+	// A cache, like a clone, is of a PVC in the same namespace, named as it's understood by Trident
+	if volConfig.CacheSourceVolume != "" {
+		var pvc *v1.PersistentVolumeClaim
+		if pvc, err = k8sClient.GetPVC(volConfig.CacheSourceVolume, metav1.GetOptions{}); err != nil {
+			err = fmt.Errorf("caching a PVC requires both PVCs be in the same namespace")
+			log.WithFields(log.Fields{
+				"originPVC":     volConfig.CacheSourceVolume,
+				"PVC":           claim.Name,
+				"PVC_namespace": claim.Namespace,
+			}).Debugf("Kubernetes frontend detected an invalid configuration "+
+				"for caching a PVC: %v", err.Error())
+			return
+		}
+		volConfig.CacheSourceVolume = getUniqueClaimName(pvc)
+	}
+
 	if volConfig.CloneSourceVolume == "" {
-		vol, err = p.orchestrator.AddVolume(volConfig)
+		vol, err = p.orchestrator.AddVolume(ctx, volConfig)
 	} else {
 		var (
 			options metav1.GetOptions
@@ -740,16 +815,29 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 		volConfig.CloneSourceVolume = getUniqueClaimName(pvc)
 
 		// 4) Clone the existing volume
-		vol, err = p.orchestrator.CloneVolume(volConfig)
+		vol, err = p.orchestrator.CloneVolume(ctx, volConfig)
 	}
 	if err != nil {
-		log.WithFields(log.Fields{
-			"volume": uniqueName,
-		}).Warnf("Kubernetes frontend couldn't provision a volume: %s "+
-			"(will retry upon resync)", err.Error())
+		if drivers.IsFatalError(err) {
+			log.WithFields(log.Fields{
+				"volume": uniqueName,
+			}).Errorf("Kubernetes frontend couldn't provision a volume: %s "+
+				"(will not retry until the PVC is modified)", err.Error())
+		} else {
+			log.WithFields(log.Fields{
+				"volume": uniqueName,
+			}).Warnf("Kubernetes frontend couldn't provision a volume: %s "+
+				"(will retry upon resync)", err.Error())
+		}
 		return
 	}
 
+	// Report the capacity actually provisioned, which the backend may have rounded up
+	if provisioned, parseErr := resource.ParseQuantity(vol.Config.Size); parseErr == nil &&
+		provisioned.Cmp(size) > 0 {
+		size = provisioned
+	}
+
 	claimRef := v1.ObjectReference{
 		Namespace: claim.Namespace,
 		Name:      claim.Name,
@@ -790,6 +878,14 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 		pv.Spec.StorageClassName = GetPersistentVolumeClaimClass(claim)
 	}
 
+	if claimMountOptions != nil {
+		if kubeVersion.AtLeast(k8sutilversion.MustParseSemantic("v1.8.0")) {
+			pv.Spec.MountOptions = claimMountOptions
+		} else {
+			pv.Annotations[AnnMountOptions] = strings.Join(claimMountOptions, ",")
+		}
+	}
+
 	// PVC annotation takes precedence over the storage class field
 	if getClaimReclaimPolicy(claim) ==
 		string(v1.PersistentVolumeReclaimRetain) {
@@ -826,7 +922,9 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 		}
 		pv.Spec.ISCSI = iscsiSource
 	case driverType == drivers.OntapNASStorageDriverName ||
-		driverType == drivers.OntapNASQtreeStorageDriverName:
+		driverType == drivers.OntapNASQtreeStorageDriverName ||
+		driverType == drivers.GCPNFSStorageDriverName ||
+		driverType == drivers.GenericNFSStorageDriverName:
 		nfsSource = CreateNFSVolumeSource(vol)
 		pv.Spec.NFS = nfsSource
 	case driverType == drivers.FakeStorageDriverName:
@@ -852,12 +950,30 @@ func (p *Plugin) createVolumeAndPV(uniqueName string, claim *v1.PersistentVolume
 		err = fmt.Errorf("unrecognized volume type by Kubernetes")
 		return
 	}
+
+	// Volumes exported or mapped read-only by their storage can only be mounted read-only
+	if vol.Config.ReadOnly {
+		if pv.Spec.NFS != nil {
+			pv.Spec.NFS.ReadOnly = true
+		}
+		if pv.Spec.ISCSI != nil {
+			pv.Spec.ISCSI.ReadOnly = true
+		}
+	}
+
+	// Let host automation match an iSCSI PV to its block devices
+	if pv.Spec.ISCSI != nil && vol.Config.AccessInfo.IscsiLunWWID != "" {
+		pv.Annotations[AnnLunWWID] = vol.Config.AccessInfo.IscsiLunWWID
+	}
+
 	pv, err = p.kubeClient.Core().PersistentVolumes().Create(pv)
 	return
 }
 
 func (p *Plugin) deleteVolumeAndPV(volume *v1.PersistentVolume) error {
-	found, err := p.orchestrator.DeleteVolume(volume.GetName())
+	ctx, cancel := p.operationContext(p.GetName())
+	defer cancel()
+	found, err := p.orchestrator.DeleteVolume(ctx, volume.GetName())
 	if found && err != nil {
 		message := fmt.Sprintf(
 			"Kubernetes frontend failed to delete the volume "+
@@ -974,7 +1090,9 @@ func (p *Plugin) processUpdatedVolume(volume *v1.PersistentVolume) {
 		if volume.Spec.PersistentVolumeReclaimPolicy != v1.PersistentVolumeReclaimDelete {
 			return
 		}
-		found, err := p.orchestrator.DeleteVolume(volume.Name)
+		ctx, cancel := p.operationContext(p.GetName())
+		defer cancel()
+		found, err := p.orchestrator.DeleteVolume(ctx, volume.Name)
 		if found && err != nil {
 			// Updating the PV's phase to "VolumeFailed", so that
 			// a storage admin can take action.
@@ -1055,6 +1173,35 @@ func (p *Plugin) updateVolumePhase(volume *v1.PersistentVolume, phase v1.Persist
 	return newVol, err
 }
 
+// claimProgressReporter emits the orchestrator's provisioning progress as events on a claim,
+// so that users can follow it with "kubectl describe pvc".
+type claimProgressReporter struct {
+	plugin *Plugin
+	claim  *v1.PersistentVolumeClaim
+}
+
+func (r *claimProgressReporter) ReportProgress(reason, message string) {
+	r.plugin.updateClaimWithEvent(r.claim, v1.EventTypeNormal, reason, message)
+}
+
+func (r *claimProgressReporter) ReportWarning(reason, message string) {
+	r.plugin.updateClaimWithEvent(r.claim, v1.EventTypeWarning, reason, message)
+}
+
+// provisioningFailureMessage describes a provisioning error for a claim event, including its
+// classification and when provisioning will next be attempted.
+func provisioningFailureMessage(err error, failure *claimFailure) string {
+	switch {
+	case failure.fatal:
+		return fmt.Sprintf("Fatal error: %v (will not retry until the PVC is modified)", err)
+	case drivers.IsRetryableError(err):
+		return fmt.Sprintf("Retryable error: %v (will retry after %s)", err,
+			failure.nextAttempt.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("Error: %v (will retry after %s)", err, failure.nextAttempt.Format(time.RFC3339))
+	}
+}
+
 // updateClaimWithEvent emits given event on the claim.
 // (Based on pkg/controller/volume/persistentvolume/pv_controller.go)
 func (p *Plugin) updateClaimWithEvent(
@@ -1156,6 +1303,16 @@ func (p *Plugin) processClass(
 }
 
 func (p *Plugin) processAddedClass(class *k8sstoragev1.StorageClass) {
+	// Mount options reach every node that mounts the class's volumes, so they must be allowed
+	if err := k8sutilversion.ValidateMountOptions(class.MountOptions); err != nil {
+		log.WithFields(log.Fields{
+			"storageClass":              class.Name,
+			"storageClass_provisioner":  class.Provisioner,
+			"storageClass_mountOptions": strings.Join(class.MountOptions, ","),
+		}).Errorf("Kubernetes frontend couldn't add the storage class: %v", err)
+		return
+	}
+
 	scConfig := new(storageclass.Config)
 	scConfig.Name = class.Name
 	scConfig.Attributes = make(map[string]storageattribute.Request)
@@ -1194,6 +1351,36 @@ func (p *Plugin) processAddedClass(class *k8sstoragev1.StorageClass) {
 			}
 			scConfig.Pools = pools
 
+		case storageattribute.DefaultSize:
+			// format:  defaultSize: "10Gi"
+			scConfig.DefaultSize = v
+
+		case storageattribute.WarmPoolSize:
+			// format:  warmPoolSize: "5"
+			size, err := strconv.Atoi(v)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"storageClass":             class.Name,
+					"storageClass_provisioner": class.Provisioner,
+					"storageClass_parameters":  class.Parameters,
+					"error":                    err,
+				}).Errorf("Kubernetes frontend couldn't process the storage class parameter %s", k)
+				return
+			}
+			scConfig.WarmPoolSize = size
+
+		case storageattribute.WarmVolumeSize:
+			// format:  warmVolumeSize: "1Gi"
+			scConfig.WarmVolumeSize = v
+
+		case storageattribute.AllowedNamespaces:
+			// format:  allowedNamespaces: "finance,team-*"
+			for _, namespace := range strings.Split(v, ",") {
+				if namespace = strings.TrimSpace(namespace); namespace != "" {
+					scConfig.AllowedNamespaces = append(scConfig.AllowedNamespaces, namespace)
+				}
+			}
+
 		default:
 			// format:  attribute: "value"
 			req, err := storageattribute.CreateAttributeRequestFromAttributeValue(k, v)
@@ -1348,3 +1535,47 @@ func GetPersistentVolumeClaimClass(claim *v1.PersistentVolumeClaim) string {
 
 	return ""
 }
+
+// claimFailure records why provisioning a claim last failed, so that resyncs don't retry a
+// fatal error until the claim changes and back off exponentially from transient ones.
+type claimFailure struct {
+	resourceVersion string
+	fatal           bool
+	attempts        int
+	nextAttempt     time.Time
+}
+
+// shouldSkip returns true if the claim should not be provisioned during this pass.
+func (f *claimFailure) shouldSkip(claim *v1.PersistentVolumeClaim) bool {
+	if f.fatal {
+		return claim.ResourceVersion == f.resourceVersion
+	}
+	return time.Now().Before(f.nextAttempt)
+}
+
+// recordClaimFailure notes a failed provisioning attempt for a claim and returns the updated
+// record.  The caller must hold the plugin mutex.
+func (p *Plugin) recordClaimFailure(
+	claimName string, claim *v1.PersistentVolumeClaim, err error,
+) *claimFailure {
+
+	failure, ok := p.failedClaims[claimName]
+	if !ok || failure.resourceVersion != claim.ResourceVersion {
+		failure = &claimFailure{resourceVersion: claim.ResourceVersion}
+		p.failedClaims[claimName] = failure
+	}
+
+	failure.fatal = drivers.IsFatalError(err)
+	failure.attempts++
+
+	interval := ClaimRetryInitialInterval
+	for i := 1; i < failure.attempts && interval < ClaimRetryMaxInterval; i++ {
+		interval *= 2
+	}
+	if interval > ClaimRetryMaxInterval {
+		interval = ClaimRetryMaxInterval
+	}
+	failure.nextAttempt = time.Now().Add(interval)
+
+	return failure
+}
@@ -176,10 +176,10 @@ func testVolumeConfig(
 	annotations map[string]string,
 	kubeVersion *k8sversion.Info,
 ) *storage.VolumeConfig {
-	ret := getVolumeConfig(accessModes,
-		getUniqueClaimName(testClaim(name, pvcUID, size, accessModes,
-			v1.ClaimPending, annotations, kubeVersion)),
-		resource.MustParse(size), annotations)
+	claim := testClaim(name, pvcUID, size, accessModes, v1.ClaimPending, annotations, kubeVersion)
+	ret := getVolumeConfig(accessModes, getUniqueClaimName(claim), resource.MustParse(size), annotations)
+	ret.Namespace = claim.Namespace
+	ret.RequestName = claim.Name
 	ret.InternalName = core.GetFakeInternalName(ret.Name)
 	ret.AccessInfo.NfsServerIP = testNFSServer
 	ret.AccessInfo.NfsPath = fmt.Sprintf("/%s",
@@ -213,6 +213,7 @@ func newTestPlugin(
 		classControllerStopChan:  make(chan struct{}),
 		mutex:                 &sync.Mutex{},
 		pendingClaimMatchMap:  make(map[string]*v1.PersistentVolume),
+		failedClaims:          make(map[string]*claimFailure),
 		defaultStorageClasses: make(map[string]bool, 1),
 		storageClassCache:     make(map[string]*StorageClassSummary),
 	}
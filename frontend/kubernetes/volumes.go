@@ -4,6 +4,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
@@ -84,6 +85,7 @@ func getVolumeConfig(
 	if getAnnotation(annotations, AnnFileSystem) == "" {
 		annotations[AnnFileSystem] = "ext4"
 	}
+	readOnly, _ := strconv.ParseBool(getAnnotation(annotations, AnnReadOnly))
 
 	return &storage.VolumeConfig{
 		Name:              name,
@@ -93,11 +95,15 @@ func getVolumeConfig(
 		ExportPolicy:      getAnnotation(annotations, AnnExportPolicy),
 		SnapshotDir:       getAnnotation(annotations, AnnSnapshotDir),
 		UnixPermissions:   getAnnotation(annotations, AnnUnixPermissions),
+		SecurityStyle:     getAnnotation(annotations, AnnSecurityStyle),
 		StorageClass:      getAnnotation(annotations, AnnClass),
 		BlockSize:         getAnnotation(annotations, AnnBlockSize),
 		FileSystem:        getAnnotation(annotations, AnnFileSystem),
 		CloneSourceVolume: getAnnotation(annotations, AnnCloneFromPVC),
 		SplitOnClone:      getAnnotation(annotations, AnnSplitOnClone),
+		OnDelete:          getAnnotation(annotations, AnnOnDelete),
+		ReadOnly:          readOnly,
+		CacheSourceVolume: getAnnotation(annotations, AnnCacheFromPVC),
 		AccessMode:        accessMode,
 	}
 }
@@ -155,6 +161,7 @@ func CreateISCSIPersistentVolumeSource(k8sClient k8sclient.Interface, kubeVersio
 
 		return &v1.ISCSIPersistentVolumeSource{
 			TargetPortal:      volConfig.AccessInfo.IscsiTargetPortal,
+			Portals:           volConfig.AccessInfo.IscsiPortals,
 			IQN:               volConfig.AccessInfo.IscsiTargetIQN,
 			Lun:               volConfig.AccessInfo.IscsiLunNumber,
 			ISCSIInterface:    volConfig.AccessInfo.IscsiInterface,
@@ -167,6 +174,7 @@ func CreateISCSIPersistentVolumeSource(k8sClient k8sclient.Interface, kubeVersio
 		// non-CHAP logic
 		return &v1.ISCSIPersistentVolumeSource{
 			TargetPortal:   volConfig.AccessInfo.IscsiTargetPortal,
+			Portals:        volConfig.AccessInfo.IscsiPortals,
 			IQN:            volConfig.AccessInfo.IscsiTargetIQN,
 			Lun:            volConfig.AccessInfo.IscsiLunNumber,
 			ISCSIInterface: volConfig.AccessInfo.IscsiInterface,
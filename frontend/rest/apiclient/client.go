@@ -0,0 +1,92 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+// Package apiclient is a Go client for Trident's REST API.  Its methods are generated from the
+// API's routes by the generator in this package's generator directory; see README.md.
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the REST API of a Trident instance.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Error is returned when Trident doesn't respond to a request with the status of success.  The
+// response is still decoded and returned along with the error, if it was valid JSON.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Trident returned status %d (%s)", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("Trident returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// NewClient creates a client for the Trident REST API at a URL such as http://127.0.0.1:8000.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request to Trident and decodes its JSON response.  A nil request sends no body.
+func (c *Client) do(method, path string, query url.Values, request, response interface{}, status int) error {
+
+	var body io.Reader
+	if request != nil {
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(requestBytes)
+	}
+
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	httpRequest, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBytes, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(responseBytes, response)
+
+	if httpResponse.StatusCode != status {
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(responseBytes, &errorResponse)
+		return &Error{StatusCode: httpResponse.StatusCode, Message: errorResponse.Error}
+	}
+	return decodeErr
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/netapp/trident/frontend/rest"
+)
+
+const (
+	committedClient = "../client_generated.go"
+	committedSpec   = "../../../../docs/reference/openapi.json"
+)
+
+// TestGeneratedFilesCurrent ensures that the client and specification have been regenerated
+// since the routes last changed.
+func TestGeneratedFilesCurrent(t *testing.T) {
+	client, err := generateClient()
+	if err != nil {
+		t.Fatal("Unable to generate client: ", err)
+	}
+	if committed, err := ioutil.ReadFile(committedClient); err != nil || !bytes.Equal(committed, client) {
+		t.Errorf("%s is out of date; run make apiclient_generate.", committedClient)
+	}
+
+	spec, err := rest.OpenAPISpec()
+	if err != nil {
+		t.Fatal("Unable to generate specification: ", err)
+	}
+	if committed, err := ioutil.ReadFile(committedSpec); err != nil || !bytes.Equal(committed, append(spec, '\n')) {
+		t.Errorf("%s is out of date; run make apiclient_generate.", committedSpec)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	specJSON, err := rest.OpenAPISpec()
+	if err != nil {
+		t.Fatal("Unable to generate specification: ", err)
+	}
+
+	var spec struct {
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]json.RawMessage            `json:"definitions"`
+	}
+	if err = json.Unmarshal(specJSON, &spec); err != nil {
+		t.Fatal("Invalid specification: ", err)
+	}
+
+	if _, ok := spec.Paths["/trident/v1/backend/{backend}/cordon"]["delete"]; !ok {
+		t.Error("Expected UncordonBackend in the specification.")
+	}
+	for _, ref := range strings.Split(string(specJSON), `"$ref": "#/definitions/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		if _, ok := spec.Definitions[name]; !ok {
+			t.Errorf("Definition %s is referenced but not defined.", name)
+		}
+	}
+
+	// A Go int is 64 bits, so its schema must not understate the range
+	var pool struct {
+		Properties map[string]struct {
+			Format string `json:"format"`
+		} `json:"properties"`
+	}
+	if err = json.Unmarshal(spec.Definitions["storage.PoolExternal"], &pool); err != nil {
+		t.Fatal("Invalid pool definition: ", err)
+	}
+	if format := pool.Properties["volumeCount"].Format; format != "int64" {
+		t.Errorf("Expected format int64 for volumeCount, got %s.", format)
+	}
+}
+
+func TestMethodPath(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		"/trident/v1/volume":                  `"/trident/v1/volume"`,
+		"/trident/v1/volume/{volume}":         `"/trident/v1/volume/" + url.PathEscape(volume)`,
+		"/trident/v1/backend/{backend}/trace": `"/trident/v1/backend/" + url.PathEscape(backend) + "/trace"`,
+	} {
+		if path := methodPath(pattern); path != expected {
+			t.Errorf("Expected %s for %s, got %s.", expected, pattern, path)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+// The generator writes the methods of the apiclient package and the OpenAPI specification of the
+// REST API, both from the routes of the rest package.  See ../README.md for how to run it with make.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/netapp/trident/frontend/rest"
+)
+
+var (
+	clientFile = flag.String("client", "client_generated.go", "File to which to write the client methods")
+	specFile   = flag.String("spec", "", "File to which to write the OpenAPI specification")
+)
+
+func main() {
+
+	flag.Parse()
+
+	if err := generate(*clientFile, *specFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the client methods, and the specification if a file is named for it.
+func generate(clientFile, specFile string) error {
+
+	client, err := generateClient()
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(clientFile, client, 0644); err != nil {
+		return err
+	}
+
+	if specFile == "" {
+		return nil
+	}
+	spec, err := rest.OpenAPISpec()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(specFile, append(spec, '\n'), 0644)
+}
+
+// method is a client method for an operation.
+type method struct {
+	Name     string
+	Comment  string
+	Method   string
+	Path     string
+	Params   []string
+	Query    bool
+	Request  string
+	Response string
+	Status   int
+}
+
+const clientTemplate = `// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+// Code generated by generator/main.go from the routes of the rest package.  DO NOT EDIT.
+
+package apiclient
+
+import (
+{{- range .StdImports}}
+	"{{.}}"
+{{- end}}
+{{range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{range .Methods}}
+// {{.Comment}}
+func (c *Client) {{.Name}}({{range .Params}}{{.}} string, {{end}}{{if .Query}}query url.Values, {{end}}{{if .Request}}request {{.Request}}{{end}}) (*{{.Response}}, error) {
+	response := new({{.Response}})
+	err := c.do("{{.Method}}", {{.Path}}, {{if .Query}}query{{else}}nil{{end}}, {{if .Request}}request{{else}}nil{{end}}, response, {{.Status}})
+	return response, err
+}
+{{end}}`
+
+// generateClient returns the source of a client method for each operation of the REST API.
+func generateClient() ([]byte, error) {
+
+	operations, err := rest.Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]bool{}
+	methods := make([]method, 0, len(operations))
+
+	for _, operation := range operations {
+
+		m := method{
+			Name:     operation.Name,
+			Comment:  methodComment(operation),
+			Method:   operation.Method,
+			Path:     methodPath(operation.Pattern),
+			Params:   operation.PathParams,
+			Query:    len(operation.QueryParams) > 0,
+			Response: goType(operation.Response.Elem(), imports),
+			Status:   operation.Status,
+		}
+		if len(m.Params) > 0 || m.Query {
+			imports["net/url"] = true
+		}
+		if operation.Request != nil {
+			m.Request = goType(operation.Request, imports)
+		}
+		methods = append(methods, m)
+	}
+
+	// Group the standard library imports before the others, as goimports does
+	stdImports := make([]string, 0)
+	otherImports := make([]string, 0)
+	for importPath := range imports {
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			otherImports = append(otherImports, importPath)
+		} else {
+			stdImports = append(stdImports, importPath)
+		}
+	}
+	sort.Strings(stdImports)
+	sort.Strings(otherImports)
+
+	var buffer bytes.Buffer
+	err = template.Must(template.New("client").Parse(clientTemplate)).Execute(&buffer, map[string]interface{}{
+		"StdImports": stdImports,
+		"Imports":    otherImports,
+		"Methods":    methods,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the trailing commas of the parameter lists and format the source
+	return format.Source(bytes.Replace(buffer.Bytes(), []byte(", )"), []byte(")"), -1))
+}
+
+// methodComment turns an operation's summary, such as "Get a volume", into a doc comment.
+func methodComment(operation rest.Operation) string {
+	words := strings.SplitN(operation.Summary, " ", 2)
+	comment := operation.Name + " " + strings.ToLower(words[0][:1]) + words[0][1:] + "s"
+	if len(words) > 1 {
+		comment += " " + words[1]
+	}
+	return comment + "."
+}
+
+// methodPath returns a Go expression for the path of an operation, with each {param} in the route
+// pattern replaced by the escaped value of the method parameter of that name.
+func methodPath(pattern string) string {
+
+	parts := make([]string, 0)
+	for len(pattern) > 0 {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			parts = append(parts, fmt.Sprintf("%q", pattern))
+			break
+		}
+		end := strings.Index(pattern, "}")
+		if start > 0 {
+			parts = append(parts, fmt.Sprintf("%q", pattern[:start]))
+		}
+		parts = append(parts, "url.PathEscape("+pattern[start+1:end]+")")
+		pattern = pattern[end+1:]
+	}
+	return strings.Join(parts, " + ")
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// goType returns the Go name of a type, adding its package to the imports.
+func goType(t reflect.Type, imports map[string]bool) string {
+
+	// Name raw JSON as the client's users know it, whatever encoding/json defines it as
+	if t == rawMessageType {
+		imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+
+	elem := t
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+	if elem.PkgPath() != "" {
+		imports[elem.PkgPath()] = true
+	}
+	return t.String()
+}
@@ -3,18 +3,27 @@
 package rest
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/core"
+	"github.com/netapp/trident/logging"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage_class"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
 )
 
 type listResponse interface {
@@ -238,22 +247,217 @@ type GetBackendResponse struct {
 	Error   string                   `json:"error,omitempty"`
 }
 
+// GetBackend returns a backend with the summary of its config that its driver exposes.  If the
+// detail query parameter is true, it returns the driver's whole config instead, less credentials,
+// but only to administrators.
 func GetBackend(w http.ResponseWriter, r *http.Request) {
 	response := &GetBackendResponse{}
 	GetGeneric(w, r, "backend", response,
 		func(backendName string) int {
-			backend := orchestrator.GetBackend(backendName)
-			if backend == nil {
+			detail := false
+			if detailParam := r.URL.Query().Get("detail"); detailParam != "" {
+				var err error
+				if detail, err = strconv.ParseBool(detailParam); err != nil {
+					response.Error = fmt.Sprintf("invalid value for detail: %s", detailParam)
+					return http.StatusBadRequest
+				}
+			}
+			if detail && !isAdminRequest(r) {
+				response.Error = "the detailed backend config is only available from Trident's own host"
+				return http.StatusForbidden
+			}
+
+			if orchestrator.GetBackend(backendName) == nil {
+				response.Error = fmt.Sprintf("Backend %v was not found!",
+					backendName)
+				return http.StatusNotFound
+			}
+			if detail {
+				backend, err := orchestrator.GetBackendWithConfigDetail(backendName)
+				if err != nil {
+					response.Error = err.Error()
+					return http.StatusInternalServerError
+				}
+				response.Backend = backend
+			} else {
+				response.Backend = orchestrator.GetBackendWithCapacity(backendName)
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+// isAdminRequest reports whether a request came from Trident's own host, or its own pod, as do
+// tridentctl's requests.  Trident has no other notion of an administrator, so anything that could
+// reach the REST interface from elsewhere on the network is treated as an ordinary client.
+func isAdminRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// UpdateBackend replaces a backend's config.  The new config may give the backend a different
+// name, which, unlike adding the config as a new backend, keeps the backend's volumes bound to it.
+func UpdateBackend(w http.ResponseWriter, r *http.Request) {
+	response := &GetBackendResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backendName string) int {
+			if orchestrator.GetBackend(backendName) == nil {
+				response.Error = fmt.Sprintf("Backend %v was not found!",
+					backendName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			backend, err := orchestrator.UpdateBackend(backendName, string(body))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Backend = backend
+			return http.StatusOK
+		},
+	)
+}
+
+type RenameBackendRequest struct {
+	Name string `json:"name"`
+}
+
+// RenameBackend gives a backend a new name, keeping its UUID and its volumes.
+func RenameBackend(w http.ResponseWriter, r *http.Request) {
+	response := &GetBackendResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backendName string) int {
+			if orchestrator.GetBackend(backendName) == nil {
+				response.Error = fmt.Sprintf("Backend %v was not found!",
+					backendName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &RenameBackendRequest{}
+			if err = json.Unmarshal(body, request); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			backend, err := orchestrator.RenameBackend(backendName, request.Name)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Backend = backend
+			return http.StatusOK
+		},
+	)
+}
+
+// CordonBackend stops new volumes from being provisioned on a backend.
+func CordonBackend(w http.ResponseWriter, r *http.Request) {
+	setBackendCordon(w, r, true)
+}
+
+// UncordonBackend allows new volumes to be provisioned on a cordoned backend again.
+func UncordonBackend(w http.ResponseWriter, r *http.Request) {
+	setBackendCordon(w, r, false)
+}
+
+func setBackendCordon(w http.ResponseWriter, r *http.Request, cordoned bool) {
+	response := &GetBackendResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backendName string) int {
+			if orchestrator.GetBackend(backendName) == nil {
 				response.Error = fmt.Sprintf("Backend %v was not found!",
 					backendName)
 				return http.StatusNotFound
 			}
+			backend, err := orchestrator.CordonBackend(backendName, cordoned)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusInternalServerError
+			}
 			response.Backend = backend
 			return http.StatusOK
 		},
 	)
 }
 
+type UpdateBackendTraceFlagsRequest struct {
+	DebugTraceFlags map[string]bool `json:"debugTraceFlags"`
+}
+
+type BackendTraceFlagsResponse struct {
+	Backend         string          `json:"backend"`
+	DebugTraceFlags map[string]bool `json:"debugTraceFlags"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// UpdateBackendTraceFlags enables or disables debug tracing on a running backend.  Flags not
+// named in the request are left unchanged, so an empty request returns the current flags.
+func UpdateBackendTraceFlags(w http.ResponseWriter, r *http.Request) {
+	response := &BackendTraceFlagsResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backendName string) int {
+			response.Backend = backendName
+			if orchestrator.GetBackend(backendName) == nil {
+				response.Error = fmt.Sprintf("Backend %v was not found!",
+					backendName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &UpdateBackendTraceFlagsRequest{}
+			if len(body) > 0 {
+				if err = json.Unmarshal(body, request); err != nil {
+					response.Error = "Invalid JSON: " + err.Error()
+					return http.StatusBadRequest
+				}
+			}
+			flags, err := orchestrator.UpdateBackendDebugTraceFlags(backendName, request.DebugTraceFlags)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsUnsupportedError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
+			}
+			response.DebugTraceFlags = flags
+			return http.StatusOK
+		},
+	)
+}
+
+// GetBackendVolume gets the volume that a backend gave a name on its storage, such as an ONTAP
+// Flexvol, however the backend transformed the volume's name.
+func GetBackendVolume(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backendName string) int {
+			internalName := mux.Vars(r)["internalName"]
+			volume := orchestrator.GetVolumeByInternalName(backendName, internalName)
+			if volume == nil {
+				response.Error = fmt.Sprintf("Backend %v has no volume named %v!",
+					backendName, internalName)
+				return http.StatusNotFound
+			}
+			response.Volume = volume.Redacted()
+			return http.StatusOK
+		},
+	)
+}
+
 // DeleteBackend calls OfflineBackend in the orchestrator, as we currently do
 // not allow for full deletion of backends due to the potential for race
 // conditions and the additional bookkeeping that would be required.
@@ -303,7 +507,7 @@ func AddVolume(w http.ResponseWriter, r *http.Request) {
 				response.setError(err)
 				return
 			}
-			volume, err := orchestrator.AddVolume(volumeConfig)
+			volume, err := orchestrator.AddVolume(r.Context(), volumeConfig)
 			if err != nil {
 				response.setError(err)
 			}
@@ -314,6 +518,145 @@ func AddVolume(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+type AddVolumesRequest struct {
+	Volumes []*storage.VolumeConfig `json:"volumes"`
+}
+
+type DeleteVolumesRequest struct {
+	Volumes []string `json:"volumes"`
+}
+
+type BulkVolumeResponse struct {
+	Results []*storage.BulkVolumeResult `json:"results"`
+	Error   string                      `json:"error,omitempty"`
+}
+
+// readBulkRequest decodes the body of a bulk volume request, which may be larger than other
+// requests, and checks that it doesn't name too many volumes.
+func readBulkRequest(r *http.Request, request interface{}, count func() int) error {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxBulkRESTRequestSize))
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, request); err != nil {
+		return fmt.Errorf("Invalid JSON: %v", err)
+	}
+	if count() == 0 {
+		return fmt.Errorf("no volumes specified")
+	} else if count() > config.MaxBulkVolumes {
+		return fmt.Errorf("too many volumes specified; the limit is %d", config.MaxBulkVolumes)
+	}
+	return nil
+}
+
+// AddVolumes creates each of the volumes in the request, reporting the result for each.
+func AddVolumes(w http.ResponseWriter, r *http.Request) {
+	response := &BulkVolumeResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			request := &AddVolumesRequest{}
+			if err := readBulkRequest(r, request, func() int { return len(request.Volumes) }); err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Results = orchestrator.AddVolumes(r.Context(), request.Volumes)
+			return http.StatusOK
+		},
+	)
+}
+
+// DeleteVolumes deletes each of the volumes named in the request, reporting the result for each.
+func DeleteVolumes(w http.ResponseWriter, r *http.Request) {
+	response := &BulkVolumeResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			request := &DeleteVolumesRequest{}
+			if err := readBulkRequest(r, request, func() int { return len(request.Volumes) }); err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Results = orchestrator.DeleteVolumes(r.Context(), request.Volumes)
+			return http.StatusOK
+		},
+	)
+}
+
+type CloneVolumesResponse struct {
+	Result *storage.CloneFanoutResult `json:"result,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+}
+
+// CloneVolumes creates the number of clones of a volume given in the request, reporting the
+// result for each.
+func CloneVolumes(w http.ResponseWriter, r *http.Request) {
+	response := &CloneVolumesResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!",
+					volName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &storage.CloneFanoutConfig{}
+			if err = json.Unmarshal(body, request); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			request.SourceVolume = volName
+			response.Result, err = orchestrator.CloneVolumes(r.Context(), request)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsFatalError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+type PlacementResponse struct {
+	Preview *storage.PlacementPreview `json:"preview"`
+	Error   string                    `json:"error,omitempty"`
+}
+
+// PreviewVolumePlacement reports where the volume described in the request body would be
+// created, without creating it.
+func PreviewVolumePlacement(w http.ResponseWriter, r *http.Request) {
+	response := &PlacementResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			volumeConfig := new(storage.VolumeConfig)
+			if err = json.Unmarshal(body, volumeConfig); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			if err = volumeConfig.Validate(); err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			preview, err := orchestrator.PreviewVolumePlacement(volumeConfig)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Preview = preview
+			return http.StatusOK
+		},
+	)
+}
+
 type ListVolumesResponse struct {
 	Volumes []string `json:"volumes"`
 	Error   string   `json:"error,omitempty"`
@@ -355,77 +698,274 @@ func GetVolume(w http.ResponseWriter, r *http.Request) {
 					volName)
 				return http.StatusNotFound
 			}
-			response.Volume = volume
+			response.Volume = volume.Redacted()
 			return http.StatusOK
 		},
 	)
 }
 
-func DeleteVolume(w http.ResponseWriter, r *http.Request) {
-	DeleteGeneric(w, r, orchestrator.DeleteVolume, "volume")
-}
-
-type AddStorageClassResponse struct {
-	StorageClassID string `json:"storageClass"`
-	Error          string `json:"error,omitempty"`
-}
-
-func (a *AddStorageClassResponse) setError(err error) {
-	a.Error = err.Error()
+type UpdateVolumeQoSRequest struct {
+	QoS     string `json:"qos"`
+	QoSType string `json:"type"`
 }
 
-func (a *AddStorageClassResponse) isError() bool {
-	return a.Error != ""
+// UpdateVolumeQoS changes the QoS of an existing volume to a named QoS type or to explicit IOPS.
+func UpdateVolumeQoS(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!",
+					volName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &UpdateVolumeQoSRequest{}
+			if err = json.Unmarshal(body, request); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			volume, err := orchestrator.UpdateVolumeQoS(volName, request.QoS, request.QoSType)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsUnsupportedError(err) || drivers.IsFatalError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
+			}
+			response.Volume = volume.Redacted()
+			return http.StatusOK
+		},
+	)
 }
 
-func (a *AddStorageClassResponse) logSuccess() {
-	log.WithFields(log.Fields{
-		"handler":      "AddStorageClass",
-		"storageClass": a.StorageClassID,
-	}).Info("Added a new storage class.")
-}
-func (a *AddStorageClassResponse) logFailure() {
-	log.WithFields(log.Fields{
-		"handler":      "AddStorageClass",
-		"storageClass": a.StorageClassID,
-	}).Error(a.Error)
+type RehostVolumeRequest struct {
+	Backend string `json:"backend"`
 }
 
-func AddStorageClass(w http.ResponseWriter, r *http.Request) {
-	response := &AddStorageClassResponse{
-		StorageClassID: "",
-		Error:          "",
-	}
-	AddGeneric(w, r, response,
-		func(body []byte) {
-			scConfig := new(storageclass.Config)
-			err := json.Unmarshal(body, scConfig)
+// RehostVolume moves a volume to another backend whose storage can take it over without copying
+// it, such as an ontap-nas backend managing another SVM of the same cluster.
+func RehostVolume(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!",
+					volName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
 			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &RehostVolumeRequest{}
+			if err = json.Unmarshal(body, request); err != nil {
 				response.Error = "Invalid JSON: " + err.Error()
-				return
+				return http.StatusBadRequest
 			}
-			sc, err := orchestrator.AddStorageClass(scConfig)
-			if err != nil {
-				response.setError(err)
+			if request.Backend == "" {
+				response.Error = "a backend is required"
+				return http.StatusBadRequest
 			}
-			if sc != nil {
-				response.StorageClassID = sc.GetName()
+			volume, err := orchestrator.RehostVolume(volName, request.Backend)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsUnsupportedError(err) || drivers.IsFatalError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
 			}
+			response.Volume = volume.Redacted()
+			return http.StatusOK
 		},
 	)
 }
 
-type ListStorageClassesResponse struct {
-	StorageClasses []string `json:"storageClasses"`
+type ForceDetachVolumeResponse struct {
+	Volume         string   `json:"volume"`
+	ReleasedMounts []string `json:"releasedMounts"`
 	Error          string   `json:"error,omitempty"`
 }
 
-func (l *ListStorageClassesResponse) setList(payload []string) {
-	l.StorageClasses = payload
+// ForceDetachVolume detaches a volume from the host running Trident even if its frontend believes
+// the volume is still mounted, such as when an unmount was lost.
+func ForceDetachVolume(w http.ResponseWriter, r *http.Request) {
+	response := &ForceDetachVolumeResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			response.Volume = volName
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!",
+					volName)
+				return http.StatusNotFound
+			}
+			released, err := orchestrator.ForceDetachVolume(volName)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsUnsupportedError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
+			}
+			response.ReleasedMounts = released
+			return http.StatusOK
+		},
+	)
 }
 
-func ListStorageClasses(w http.ResponseWriter, r *http.Request) {
-	ListGeneric(w, r,
+type GetVolumeStatsResponse struct {
+	Stats *storage.VolumeStatsReport `json:"stats"`
+	Error string                     `json:"error,omitempty"`
+}
+
+// GetVolumeStats samples the performance counters of a volume and, if an interval is given, its
+// rates over that interval.
+func GetVolumeStats(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeStatsResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!",
+					volName)
+				return http.StatusNotFound
+			}
+			interval, err := volumeStatsInterval(r)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Stats, err = orchestrator.GetVolumeStats(volName, interval)
+			if err != nil {
+				response.Error = err.Error()
+				if drivers.IsUnsupportedError(err) || drivers.IsFatalError(err) {
+					return http.StatusBadRequest
+				}
+				return http.StatusInternalServerError
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+type ListVolumeStatsResponse struct {
+	Items []*storage.VolumeStatsReport `json:"items"`
+	Error string                       `json:"error,omitempty"`
+}
+
+// ListVolumeStats samples the performance counters of every volume whose backend can report them
+// and, if an interval is given, their rates over that interval.
+func ListVolumeStats(w http.ResponseWriter, r *http.Request) {
+	response := &ListVolumeStatsResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			interval, err := volumeStatsInterval(r)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			if response.Items, err = orchestrator.ListVolumeStats(interval); err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+// volumeStatsInterval parses the interval, in seconds, between two samples of volume statistics.
+func volumeStatsInterval(r *http.Request) (time.Duration, error) {
+	intervalParam := r.URL.Query().Get("interval")
+	if intervalParam == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.Atoi(intervalParam)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for interval: %s", intervalParam)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func DeleteVolume(w http.ResponseWriter, r *http.Request) {
+	DeleteGeneric(w, r,
+		func(volumeName string) (bool, error) {
+			return orchestrator.DeleteVolume(r.Context(), volumeName)
+		},
+		"volume",
+	)
+}
+
+// AddStorageClassResponse reports, along with the name of the storage class, the storage pools
+// that satisfy it, and why the others don't.
+type AddStorageClassResponse struct {
+	StorageClassID string                   `json:"storageClass"`
+	StoragePools   map[string][]string      `json:"storage,omitempty"`
+	Validation     *storageclass.Validation `json:"validation,omitempty"`
+	Error          string                   `json:"error,omitempty"`
+}
+
+func (a *AddStorageClassResponse) setError(err error) {
+	a.Error = err.Error()
+}
+
+func (a *AddStorageClassResponse) isError() bool {
+	return a.Error != ""
+}
+
+func (a *AddStorageClassResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"handler":      "AddStorageClass",
+		"storageClass": a.StorageClassID,
+	}).Info("Added a new storage class.")
+}
+func (a *AddStorageClassResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"handler":      "AddStorageClass",
+		"storageClass": a.StorageClassID,
+	}).Error(a.Error)
+}
+
+func AddStorageClass(w http.ResponseWriter, r *http.Request) {
+	response := &AddStorageClassResponse{
+		StorageClassID: "",
+		Error:          "",
+	}
+	AddGeneric(w, r, response,
+		func(body []byte) {
+			scConfig := new(storageclass.Config)
+			err := json.Unmarshal(body, scConfig)
+			if err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return
+			}
+			sc, err := orchestrator.AddStorageClass(scConfig)
+			if err != nil {
+				response.setError(err)
+			}
+			if sc != nil {
+				response.StorageClassID = sc.GetName()
+				response.StoragePools = sc.StoragePools
+				response.Validation = sc.Validation
+			}
+		},
+	)
+}
+
+type ListStorageClassesResponse struct {
+	StorageClasses []string `json:"storageClasses"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func (l *ListStorageClassesResponse) setList(payload []string) {
+	l.StorageClasses = payload
+}
+
+func ListStorageClasses(w http.ResponseWriter, r *http.Request) {
+	ListGeneric(w, r,
 		&ListStorageClassesResponse{},
 		func() []string {
 			storageClasses := orchestrator.ListStorageClasses()
@@ -459,6 +999,687 @@ func GetStorageClass(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// UpdateStorageClassResponse reports how updating a storage class changes the storage pools on
+// which its volumes are provisioned.
+type UpdateStorageClassResponse struct {
+	Update *storageclass.Update `json:"update,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// UpdateStorageClass replaces the definition of a storage class, keeping its name so that the
+// volumes and claims that refer to it are unaffected.
+func UpdateStorageClass(w http.ResponseWriter, r *http.Request) {
+	response := &UpdateStorageClassResponse{}
+	GetGeneric(w, r, "storageClass", response,
+		func(scName string) int {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			scConfig := new(storageclass.Config)
+			if err = json.Unmarshal(body, scConfig); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			if scConfig.Name == "" {
+				scConfig.Name = scName
+			} else if scConfig.Name != scName {
+				response.Error = fmt.Sprintf("storage class %s may not be renamed %s; add the new name "+
+					"as an alias instead", scName, scConfig.Name)
+				return http.StatusBadRequest
+			}
+			if orchestrator.GetStorageClass(scName) == nil {
+				response.Error = fmt.Sprintf("StorageClass %s was not found!", scName)
+				return http.StatusNotFound
+			}
+			update, err := orchestrator.UpdateStorageClass(scConfig)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Update = update
+			return http.StatusOK
+		},
+	)
+}
+
 func DeleteStorageClass(w http.ResponseWriter, r *http.Request) {
 	DeleteGeneric(w, r, orchestrator.DeleteStorageClass, "storageClass")
 }
+
+type AddSnapshotScheduleResponse struct {
+	SnapshotScheduleID string `json:"snapshotSchedule"`
+	Error              string `json:"error,omitempty"`
+}
+
+func (a *AddSnapshotScheduleResponse) setError(err error) {
+	a.Error = err.Error()
+}
+
+func (a *AddSnapshotScheduleResponse) isError() bool {
+	return a.Error != ""
+}
+
+func (a *AddSnapshotScheduleResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"handler":          "AddSnapshotSchedule",
+		"snapshotSchedule": a.SnapshotScheduleID,
+	}).Info("Added a new snapshot schedule.")
+}
+func (a *AddSnapshotScheduleResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"handler":          "AddSnapshotSchedule",
+		"snapshotSchedule": a.SnapshotScheduleID,
+	}).Error(a.Error)
+}
+
+func AddSnapshotSchedule(w http.ResponseWriter, r *http.Request) {
+	response := &AddSnapshotScheduleResponse{}
+	AddGeneric(w, r, response,
+		func(body []byte) {
+			scheduleConfig := new(storage.SnapshotScheduleConfig)
+			err := json.Unmarshal(body, scheduleConfig)
+			if err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return
+			}
+			schedule, err := orchestrator.AddSnapshotSchedule(scheduleConfig)
+			if err != nil {
+				response.setError(err)
+			}
+			if schedule != nil {
+				response.SnapshotScheduleID = schedule.Config.Name
+			}
+		},
+	)
+}
+
+type ListSnapshotSchedulesResponse struct {
+	SnapshotSchedules []string `json:"snapshotSchedules"`
+	Error             string   `json:"error,omitempty"`
+}
+
+func (l *ListSnapshotSchedulesResponse) setList(payload []string) {
+	l.SnapshotSchedules = payload
+}
+
+func ListSnapshotSchedules(w http.ResponseWriter, r *http.Request) {
+	ListGeneric(w, r,
+		&ListSnapshotSchedulesResponse{},
+		func() []string {
+			schedules := orchestrator.ListSnapshotSchedules()
+			scheduleNames := make([]string, 0, len(schedules))
+			for _, schedule := range schedules {
+				scheduleNames = append(scheduleNames, schedule.Config.Name)
+			}
+			return scheduleNames
+		},
+	)
+}
+
+type GetSnapshotScheduleResponse struct {
+	SnapshotSchedule *storage.SnapshotScheduleExternal `json:"snapshotSchedule"`
+	Error            string                            `json:"error,omitempty"`
+}
+
+func GetSnapshotSchedule(w http.ResponseWriter, r *http.Request) {
+	response := &GetSnapshotScheduleResponse{}
+	GetGeneric(w, r, "snapshotSchedule", response,
+		func(scheduleName string) int {
+			schedule := orchestrator.GetSnapshotSchedule(scheduleName)
+			if schedule == nil {
+				response.Error = fmt.Sprintf("Snapshot schedule %s was not found!", scheduleName)
+				return http.StatusNotFound
+			}
+			response.SnapshotSchedule = schedule
+			return http.StatusOK
+		},
+	)
+}
+
+func DeleteSnapshotSchedule(w http.ResponseWriter, r *http.Request) {
+	DeleteGeneric(w, r, orchestrator.DeleteSnapshotSchedule, "snapshotSchedule")
+}
+
+type AddVolumeGroupResponse struct {
+	VolumeGroupID string `json:"volumeGroup"`
+	Error         string `json:"error,omitempty"`
+}
+
+func (a *AddVolumeGroupResponse) setError(err error) {
+	a.Error = err.Error()
+}
+
+func (a *AddVolumeGroupResponse) isError() bool {
+	return a.Error != ""
+}
+
+func (a *AddVolumeGroupResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"handler":     "AddVolumeGroup",
+		"volumeGroup": a.VolumeGroupID,
+	}).Info("Added a new volume group.")
+}
+func (a *AddVolumeGroupResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"handler":     "AddVolumeGroup",
+		"volumeGroup": a.VolumeGroupID,
+	}).Error(a.Error)
+}
+
+func AddVolumeGroup(w http.ResponseWriter, r *http.Request) {
+	response := &AddVolumeGroupResponse{}
+	AddGeneric(w, r, response,
+		func(body []byte) {
+			groupConfig := new(storage.VolumeGroupConfig)
+			err := json.Unmarshal(body, groupConfig)
+			if err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return
+			}
+			group, err := orchestrator.AddVolumeGroup(r.Context(), groupConfig)
+			if err != nil {
+				response.setError(err)
+			}
+			if group != nil {
+				response.VolumeGroupID = group.Name
+			}
+		},
+	)
+}
+
+type ListVolumeGroupsResponse struct {
+	VolumeGroups []string `json:"volumeGroups"`
+	Error        string   `json:"error,omitempty"`
+}
+
+func (l *ListVolumeGroupsResponse) setList(payload []string) {
+	l.VolumeGroups = payload
+}
+
+func ListVolumeGroups(w http.ResponseWriter, r *http.Request) {
+	ListGeneric(w, r,
+		&ListVolumeGroupsResponse{},
+		func() []string {
+			groups := orchestrator.ListVolumeGroups()
+			groupNames := make([]string, 0, len(groups))
+			for _, group := range groups {
+				groupNames = append(groupNames, group.Name)
+			}
+			return groupNames
+		},
+	)
+}
+
+type GetVolumeGroupResponse struct {
+	VolumeGroup *storage.VolumeGroupExternal `json:"volumeGroup"`
+	Error       string                       `json:"error,omitempty"`
+}
+
+func GetVolumeGroup(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeGroupResponse{}
+	GetGeneric(w, r, "volumeGroup", response,
+		func(groupName string) int {
+			group := orchestrator.GetVolumeGroup(groupName)
+			if group == nil {
+				response.Error = fmt.Sprintf("Volume group %s was not found!", groupName)
+				return http.StatusNotFound
+			}
+			response.VolumeGroup = group
+			return http.StatusOK
+		},
+	)
+}
+
+type SnapshotVolumeGroupRequest struct {
+	Name string `json:"name"`
+}
+
+type SnapshotVolumeGroupResponse struct {
+	Snapshot *storage.VolumeGroupSnapshot `json:"snapshot"`
+	Error    string                       `json:"error,omitempty"`
+}
+
+// SnapshotVolumeGroup takes a snapshot of each of a group's volumes, at the same point in time
+// where the backends allow it.
+func SnapshotVolumeGroup(w http.ResponseWriter, r *http.Request) {
+	response := &SnapshotVolumeGroupResponse{}
+	GetGeneric(w, r, "volumeGroup", response,
+		func(groupName string) int {
+			if orchestrator.GetVolumeGroup(groupName) == nil {
+				response.Error = fmt.Sprintf("Volume group %s was not found!", groupName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			request := &SnapshotVolumeGroupRequest{}
+			if err = json.Unmarshal(body, request); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			snapshot, err := orchestrator.SnapshotVolumeGroup(groupName, request.Name)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusInternalServerError
+			}
+			response.Snapshot = snapshot
+			return http.StatusCreated
+		},
+	)
+}
+
+func DeleteVolumeGroup(w http.ResponseWriter, r *http.Request) {
+	DeleteGeneric(w, r,
+		func(groupName string) (bool, error) {
+			return orchestrator.DeleteVolumeGroup(r.Context(), groupName)
+		},
+		"volumeGroup",
+	)
+}
+
+type GetVolumeMigrationResponse struct {
+	Migration *storage.VolumeMigration `json:"migration"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// MigrateVolume starts moving a volume to another backend.  The migration runs in the background,
+// so the response only reports that it has begun.
+func MigrateVolume(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeMigrationResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolume(volName) == nil {
+				response.Error = fmt.Sprintf("Volume %v was not found!", volName)
+				return http.StatusNotFound
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			migrationConfig := new(storage.VolumeMigrationConfig)
+			if err = json.Unmarshal(body, migrationConfig); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			migration, err := orchestrator.MigrateVolume(volName, migrationConfig)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Migration = migration
+			return http.StatusAccepted
+		},
+	)
+}
+
+type ListVolumeMigrationsResponse struct {
+	Migrations []string `json:"migrations"`
+	Error      string   `json:"error,omitempty"`
+}
+
+func (l *ListVolumeMigrationsResponse) setList(payload []string) {
+	l.Migrations = payload
+}
+
+func ListVolumeMigrations(w http.ResponseWriter, r *http.Request) {
+	ListGeneric(w, r,
+		&ListVolumeMigrationsResponse{},
+		func() []string {
+			migrations := orchestrator.ListVolumeMigrations()
+			volumeNames := make([]string, 0, len(migrations))
+			for _, migration := range migrations {
+				volumeNames = append(volumeNames, migration.Volume)
+			}
+			return volumeNames
+		},
+	)
+}
+
+func GetVolumeMigration(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeMigrationResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			migration := orchestrator.GetVolumeMigration(volName)
+			if migration == nil {
+				response.Error = fmt.Sprintf("Migration of volume %s was not found!", volName)
+				return http.StatusNotFound
+			}
+			response.Migration = migration
+			return http.StatusOK
+		},
+	)
+}
+
+// CutoverVolumeMigration switches a volume to its migration's destination backend.  If the cutover
+// can't finish within the migration's disruption window, the volume stays where it was and the
+// response carries both the error and the migration, which is ready to cut over again.
+func CutoverVolumeMigration(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeMigrationResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			if orchestrator.GetVolumeMigration(volName) == nil {
+				response.Error = fmt.Sprintf("Migration of volume %s was not found!", volName)
+				return http.StatusNotFound
+			}
+			migration, err := orchestrator.CutoverVolumeMigration(volName)
+			response.Migration = migration
+			if err != nil {
+				response.Error = err.Error()
+				if migration == nil {
+					return http.StatusConflict
+				}
+				return http.StatusInternalServerError
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+func CancelVolumeMigration(w http.ResponseWriter, r *http.Request) {
+	DeleteGeneric(w, r, orchestrator.CancelVolumeMigration, "volume")
+}
+
+type ListJobsResponse struct {
+	Jobs  []string `json:"jobs"`
+	Error string   `json:"error,omitempty"`
+}
+
+func (l *ListJobsResponse) setList(payload []string) {
+	l.Jobs = payload
+}
+
+func ListJobs(w http.ResponseWriter, r *http.Request) {
+	ListGeneric(w, r,
+		&ListJobsResponse{},
+		func() []string {
+			jobs := utils.ListJobs()
+			jobIDs := make([]string, 0, len(jobs))
+			for _, job := range jobs {
+				jobIDs = append(jobIDs, job.ID)
+			}
+			return jobIDs
+		},
+	)
+}
+
+type GetJobResponse struct {
+	Job   *utils.Job `json:"job"`
+	Error string     `json:"error,omitempty"`
+}
+
+func GetJob(w http.ResponseWriter, r *http.Request) {
+	response := &GetJobResponse{}
+	GetGeneric(w, r, "job", response,
+		func(jobID string) int {
+			job := utils.GetJob(jobID)
+			if job == nil {
+				response.Error = fmt.Sprintf("Job %s was not found!", jobID)
+				return http.StatusNotFound
+			}
+			response.Job = job
+			return http.StatusOK
+		},
+	)
+}
+
+type ReconcileResponse struct {
+	Report *storage.ReconciliationReport `json:"report"`
+	Error  string                        `json:"error,omitempty"`
+}
+
+// GetReconciliationReport returns the most recent reconciliation report, running a reconciliation
+// without cleanup if there hasn't been one yet.
+func GetReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	response := &ReconcileResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			response.Report = orchestrator.GetReconciliationReport()
+			if response.Report == nil {
+				report, err := orchestrator.ReconcileBackends(false)
+				if err != nil {
+					response.Error = err.Error()
+					return http.StatusInternalServerError
+				}
+				response.Report = report
+			}
+			return http.StatusOK
+		},
+	)
+}
+
+// ReconcileBackends runs a reconciliation now, deleting orphans if the cleanup query parameter
+// is true.
+func ReconcileBackends(w http.ResponseWriter, r *http.Request) {
+	response := &ReconcileResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			cleanup := false
+			if cleanupParam := r.URL.Query().Get("cleanup"); cleanupParam != "" {
+				var err error
+				if cleanup, err = strconv.ParseBool(cleanupParam); err != nil {
+					response.Error = fmt.Sprintf("invalid value for cleanup: %s", cleanupParam)
+					return http.StatusBadRequest
+				}
+			}
+			report, err := orchestrator.ReconcileBackends(cleanup)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Report = report
+			return http.StatusOK
+		},
+	)
+}
+
+type LoggingResponse struct {
+	Config *logging.Config `json:"config"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// GetLoggingConfig returns the current log format and levels.
+func GetLoggingConfig(w http.ResponseWriter, r *http.Request) {
+	response := &LoggingResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			response.Config = logging.GetConfig()
+			return http.StatusOK
+		},
+	)
+}
+
+// SetLoggingConfig changes the log format and levels while Trident is running.  Fields omitted
+// from the request are left unchanged.
+func SetLoggingConfig(w http.ResponseWriter, r *http.Request) {
+	response := &LoggingResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			loggingConfig := &logging.Config{}
+			if err = json.Unmarshal(body, loggingConfig); err != nil {
+				response.Error = "Invalid JSON: " + err.Error()
+				return http.StatusBadRequest
+			}
+			if err = logging.SetConfig(loggingConfig); err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Config = logging.GetConfig()
+			log.WithField("config", response.Config).Info("Changed logging configuration.")
+			return http.StatusOK
+		},
+	)
+}
+
+type LogsResponse struct {
+	Entries []logging.Entry `json:"entries"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// GetLogs returns Trident's recent log entries.  The entries may be filtered with the query
+// parameters component (a comma-separated list), level (the least severe level to include),
+// since (an RFC 3339 time) and limit (the number of most recent entries to return).
+func GetLogs(w http.ResponseWriter, r *http.Request) {
+	response := &LogsResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			query := r.URL.Query()
+			filter := &logging.EntryFilter{Level: query.Get("level")}
+			if components := query.Get("component"); components != "" {
+				filter.Components = strings.Split(components, ",")
+			}
+			if since := query.Get("since"); since != "" {
+				sinceTime, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					response.Error = fmt.Sprintf("invalid time %s; must be in RFC 3339 format", since)
+					return http.StatusBadRequest
+				}
+				filter.Since = sinceTime
+			}
+			if limit := query.Get("limit"); limit != "" {
+				limitValue, err := strconv.Atoi(limit)
+				if err != nil || limitValue < 0 {
+					response.Error = fmt.Sprintf("invalid limit %s", limit)
+					return http.StatusBadRequest
+				}
+				filter.Limit = limitValue
+			}
+
+			entries, err := logging.GetEntries(filter)
+			if err != nil {
+				response.Error = err.Error()
+				return http.StatusBadRequest
+			}
+			response.Entries = entries
+			return http.StatusOK
+		},
+	)
+}
+
+// defaultUsageReportPeriod is the period a usage report covers if it isn't given a start.
+const defaultUsageReportPeriod = 30 * 24 * time.Hour
+
+type UsageReportResponse struct {
+	Report *storage.UsageReport `json:"report,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// GetUsageReport returns the capacity provisioned and used over a period by namespace, storage
+// class and backend, for chargeback.  The period is given by the query parameters start and end
+// (RFC 3339 times), and defaults to the 30 days before now.  With the query parameter format=csv,
+// the report's entries are returned as CSV instead of JSON.
+func GetUsageReport(w http.ResponseWriter, r *http.Request) {
+	response := &UsageReportResponse{}
+	status := getUsageReport(r, response)
+	if status == http.StatusOK && r.URL.Query().Get("format") == "csv" {
+		writeUsageReportCSV(w, response.Report)
+		return
+	}
+	GetGenericNoArg(w, r, response, func() int { return status })
+}
+
+func getUsageReport(r *http.Request, response *UsageReportResponse) int {
+	query := r.URL.Query()
+	if format := query.Get("format"); format != "" && format != "json" && format != "csv" {
+		response.Error = fmt.Sprintf("invalid format %s; must be json or csv", format)
+		return http.StatusBadRequest
+	}
+
+	end := time.Now()
+	if endParam := query.Get("end"); endParam != "" {
+		endTime, err := time.Parse(time.RFC3339, endParam)
+		if err != nil {
+			response.Error = fmt.Sprintf("invalid time %s; must be in RFC 3339 format", endParam)
+			return http.StatusBadRequest
+		}
+		end = endTime
+	}
+	start := end.Add(-defaultUsageReportPeriod)
+	if startParam := query.Get("start"); startParam != "" {
+		startTime, err := time.Parse(time.RFC3339, startParam)
+		if err != nil {
+			response.Error = fmt.Sprintf("invalid time %s; must be in RFC 3339 format", startParam)
+			return http.StatusBadRequest
+		}
+		start = startTime
+	}
+
+	report, err := orchestrator.GetUsageReport(start, end)
+	if err != nil {
+		response.Error = err.Error()
+		if drivers.IsFatalError(err) {
+			return http.StatusBadRequest
+		}
+		return http.StatusInternalServerError
+	}
+	response.Report = report
+	return http.StatusOK
+}
+
+// writeUsageReportCSV writes a usage report's entries as CSV, with a row of column headings.
+func writeUsageReportCSV(w http.ResponseWriter, report *storage.UsageReport) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=trident-usage-%s-%s.csv",
+		report.Start.UTC().Format("20060102"), report.End.UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(storage.UsageReportColumns)
+	for _, entry := range report.Entries {
+		writer.Write(entry.Values())
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Errorf("Could not write usage report. %v", err)
+	}
+}
+
+// HealthResponse is returned by the health and readiness checks.  Status is "ok" if the check
+// passed or "unavailable" if it did not.
+type HealthResponse struct {
+	Status string `json:"status"`
+	*core.HealthReport
+}
+
+// Healthz reports whether Trident can reach its persistent store, which Kubernetes may use as a
+// liveness probe.  It doesn't wait for other operations to complete.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	response := &HealthResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			response.HealthReport = orchestrator.CheckHealth(false)
+			return healthStatus(response, response.Healthy())
+		},
+	)
+}
+
+// Readyz reports whether Trident has loaded its state and can reach its persistent store, which
+// Kubernetes may use as a readiness probe.  The state of each backend is included for monitoring,
+// but offline backends don't make Trident unready.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	response := &HealthResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			response.HealthReport = orchestrator.CheckHealth(true)
+			return healthStatus(response, response.Ready())
+		},
+	)
+}
+
+func healthStatus(response *HealthResponse, passed bool) int {
+	if passed {
+		response.Status = "ok"
+		return http.StatusOK
+	}
+	response.Status = "unavailable"
+	return http.StatusServiceUnavailable
+}
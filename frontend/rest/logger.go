@@ -7,17 +7,26 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/utils"
 )
 
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		// Name the client as the requester of any volume operations the call makes
+		r = r.WithContext(utils.WithRequester(r.Context(), "rest:"+r.RemoteAddr))
 		inner.ServeHTTP(w, r)
-		log.WithFields(log.Fields{
+		logEntry := log.WithFields(log.Fields{
 			"method":   r.Method,
 			"uri":      r.RequestURI,
 			"route":    name,
 			"duration": time.Since(start),
-		}).Info("API server REST call.")
+		})
+		if probeRoutes[name] {
+			logEntry.Debug("API server REST call.")
+		} else {
+			logEntry.Info("API server REST call.")
+		}
 	})
 }
@@ -0,0 +1,139 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// volumeMetric is a volume statistic exported to Prometheus.  Each is a cumulative counter, in
+// Prometheus's base units of bytes and seconds.
+type volumeMetric struct {
+	name  string
+	help  string
+	value func(stats *storage.VolumeStats) float64
+}
+
+var volumeMetrics = []volumeMetric{
+	{"trident_volume_read_ops_total", "Read operations served by the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.ReadOps) }},
+	{"trident_volume_write_ops_total", "Write operations served by the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.WriteOps) }},
+	{"trident_volume_other_ops_total", "Operations other than reads and writes served by the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.OtherOps) }},
+	{"trident_volume_read_bytes_total", "Bytes read from the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.ReadBytes) }},
+	{"trident_volume_write_bytes_total", "Bytes written to the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.WriteBytes) }},
+	{"trident_volume_read_latency_seconds_total", "Time spent serving reads from the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.ReadLatencyMicros) / 1e6 }},
+	{"trident_volume_write_latency_seconds_total", "Time spent serving writes to the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.WriteLatencyMicros) / 1e6 }},
+	{"trident_volume_other_latency_seconds_total", "Time spent serving other operations on the volume.",
+		func(s *storage.VolumeStats) float64 { return float64(s.OtherLatencyMicros) / 1e6 }},
+}
+
+// Metrics serves the statistics of every volume whose backend can report them in the Prometheus
+// text format.  The counters are cumulative, so IOPS, throughput and latency are found with
+// Prometheus's rate function, such as rate(trident_volume_read_latency_seconds_total[5m]) /
+// rate(trident_volume_read_ops_total[5m]) for the mean read latency.  The durations of volume
+// operations, such as creates, are served as histograms.  Metrics aren't JSON, so this handler is
+// served outside the documented REST API.
+func Metrics(w http.ResponseWriter, r *http.Request) {
+
+	reports, err := orchestrator.ListVolumeStats(0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buffer bytes.Buffer
+	writeVolumeMetrics(&buffer, reports)
+	writeOperationMetrics(&buffer, orchestrator.GetOperationLatencies())
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// writeVolumeMetrics writes each volume metric, along with a count of the volumes that couldn't be
+// sampled, so that missing volumes can be alerted on.
+func writeVolumeMetrics(w io.Writer, reports []*storage.VolumeStatsReport) {
+
+	failed := 0
+	for _, report := range reports {
+		if report.Error != "" || report.Stats == nil {
+			log.WithFields(log.Fields{
+				"volume":  report.Volume,
+				"backend": report.Backend,
+			}).Debugf("Could not sample volume statistics. %s", report.Error)
+			failed++
+		}
+	}
+
+	for _, metric := range volumeMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, report := range reports {
+			if report.Stats == nil {
+				continue
+			}
+			fmt.Fprintf(w, "%s{volume=\"%s\",backend=\"%s\"} %g\n", metric.name,
+				escapeLabelValue(report.Volume), escapeLabelValue(report.Backend), metric.value(report.Stats))
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP trident_volume_stats_errors Volumes whose statistics could not be read.\n")
+	fmt.Fprintf(w, "# TYPE trident_volume_stats_errors gauge\ntrident_volume_stats_errors %d\n", failed)
+}
+
+// writeOperationMetrics writes the distribution of each volume operation's durations, along with
+// a count of those slower than the slow-operation threshold.
+func writeOperationMetrics(w io.Writer, histograms map[string]*utils.LatencyHistogram) {
+
+	operations := make([]string, 0, len(histograms))
+	for operation := range histograms {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	const durationMetric = "trident_operation_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time taken by volume operations.\n# TYPE %s histogram\n",
+		durationMetric, durationMetric)
+	for _, operation := range operations {
+		histogram := histograms[operation]
+		label := escapeLabelValue(operation)
+		for i, bound := range utils.LatencyBuckets {
+			fmt.Fprintf(w, "%s_bucket{operation=\"%s\",le=\"%g\"} %d\n", durationMetric, label,
+				bound.Seconds(), histogram.Buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{operation=\"%s\",le=\"+Inf\"} %d\n", durationMetric, label, histogram.Count)
+		fmt.Fprintf(w, "%s_sum{operation=\"%s\"} %g\n", durationMetric, label, histogram.Sum.Seconds())
+		fmt.Fprintf(w, "%s_count{operation=\"%s\"} %d\n", durationMetric, label, histogram.Count)
+	}
+
+	const slowMetric = "trident_slow_operations_total"
+	fmt.Fprintf(w, "# HELP %s Volume operations slower than the slow-operation threshold.\n"+
+		"# TYPE %s counter\n", slowMetric, slowMetric)
+	for _, operation := range operations {
+		fmt.Fprintf(w, "%s{operation=\"%s\"} %d\n", slowMetric, escapeLabelValue(operation),
+			histograms[operation].Slow)
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
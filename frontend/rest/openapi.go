@@ -0,0 +1,591 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/logging"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_class"
+)
+
+// Operation describes a REST API route well enough to document it and to generate a client for it.
+type Operation struct {
+	Name        string
+	Method      string
+	Pattern     string
+	Summary     string
+	PathParams  []string
+	QueryParams []QueryParam
+	// Request is the type of the request body, or nil if the operation has no body.
+	Request reflect.Type
+	// Response is the type of the response body, which is JSON for every operation.
+	Response reflect.Type
+	// Status is the HTTP status returned when the operation succeeds.
+	Status int
+}
+
+// QueryParam is an optional query parameter accepted by an operation.
+type QueryParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// routeDoc holds the documentation of a route.  Every route must have one, keyed by route name.
+type routeDoc struct {
+	summary  string
+	request  interface{}
+	response interface{}
+	status   int
+	query    []QueryParam
+}
+
+var routeDocs = map[string]routeDoc{
+	"GetVersion": {
+		summary:  "Get the version of Trident",
+		response: &GetVersionResponse{},
+	},
+	"GetOpenAPISpec": {
+		summary:  "Get the OpenAPI specification of this REST API",
+		response: &json.RawMessage{},
+	},
+	"AddBackend": {
+		summary:  "Add a backend from a backend config, updating the backend if it already exists",
+		request:  json.RawMessage{},
+		response: &AddBackendResponse{},
+		status:   http.StatusCreated,
+	},
+	"GetBackend": {
+		summary:  "Get a backend, by name or UUID, with its storage pools and volumes",
+		response: &GetBackendResponse{},
+		query: []QueryParam{
+			{"detail", "boolean", "Whether to return the driver's whole config, less credentials; " +
+				"allowed only from Trident's own host"},
+		},
+	},
+	"ListBackends": {
+		summary:  "List the names of all backends",
+		response: &ListBackendsResponse{},
+	},
+	"UpdateBackend": {
+		summary:  "Replace a backend's config, keeping its UUID and volumes even if its name changes",
+		request:  json.RawMessage{},
+		response: &GetBackendResponse{},
+	},
+	"RenameBackend": {
+		summary:  "Rename a backend, keeping its UUID and volumes",
+		request:  &RenameBackendRequest{},
+		response: &GetBackendResponse{},
+	},
+	"DeleteBackend": {
+		summary:  "Delete a backend, which stays offline until its volumes are deleted",
+		response: &DeleteResponse{},
+	},
+	"CordonBackend": {
+		summary:  "Stop placing new volumes on a backend",
+		response: &GetBackendResponse{},
+	},
+	"UncordonBackend": {
+		summary:  "Resume placing new volumes on a backend",
+		response: &GetBackendResponse{},
+	},
+	"UpdateBackendTraceFlags": {
+		summary:  "Set the debug trace flags of a backend",
+		request:  &UpdateBackendTraceFlagsRequest{},
+		response: &BackendTraceFlagsResponse{},
+	},
+	"GetBackendVolume": {
+		summary:  "Get the volume that a backend gave a name on its storage",
+		response: &GetVolumeResponse{},
+	},
+	"AddVolume": {
+		summary:  "Create a volume, cloning it if the config names a clone source",
+		request:  &storage.VolumeConfig{},
+		response: &AddVolumeResponse{},
+		status:   http.StatusCreated,
+	},
+	"GetVolume": {
+		summary:  "Get a volume",
+		response: &GetVolumeResponse{},
+	},
+	"ListVolumes": {
+		summary:  "List the names of all volumes",
+		response: &ListVolumesResponse{},
+	},
+	"DeleteVolume": {
+		summary:  "Delete a volume",
+		response: &DeleteResponse{},
+	},
+	"UpdateVolumeQoS": {
+		summary:  "Change the QoS of a volume to a named QoS type or to explicit IOPS",
+		request:  &UpdateVolumeQoSRequest{},
+		response: &GetVolumeResponse{},
+	},
+	"ForceDetachVolume": {
+		summary:  "Force a volume to be detached from the host running Trident, even if it is still mounted",
+		response: &ForceDetachVolumeResponse{},
+	},
+	"RehostVolume": {
+		summary:  "Move a volume to another backend's SVM of the same cluster without copying it",
+		request:  &RehostVolumeRequest{},
+		response: &GetVolumeResponse{},
+	},
+	"GetVolumeStats": {
+		summary:  "Get the performance counters of a volume and, if an interval is given, its rates over it",
+		response: &GetVolumeStatsResponse{},
+		query: []QueryParam{
+			{"interval", "integer", "Seconds between two samples of the counters, from which rates are found"},
+		},
+	},
+	"ListVolumeStats": {
+		summary:  "Get the performance counters of every volume whose backend can report them",
+		response: &ListVolumeStatsResponse{},
+		query: []QueryParam{
+			{"interval", "integer", "Seconds between two samples of the counters, from which rates are found"},
+		},
+	},
+	"AddVolumes": {
+		summary:  "Create several volumes at once, cloning those that name a clone source",
+		request:  &AddVolumesRequest{},
+		response: &BulkVolumeResponse{},
+	},
+	"DeleteVolumes": {
+		summary:  "Delete several volumes at once",
+		request:  &DeleteVolumesRequest{},
+		response: &BulkVolumeResponse{},
+	},
+	"CloneVolumes": {
+		summary:  "Create many clones of a volume from one snapshot, which is taken if none is named",
+		request:  &storage.CloneFanoutConfig{},
+		response: &CloneVolumesResponse{},
+	},
+	"PreviewVolumePlacement": {
+		summary:  "Preview the storage pools on which a volume would be created",
+		request:  &storage.VolumeConfig{},
+		response: &PlacementResponse{},
+	},
+	"AddStorageClass": {
+		summary:  "Add a storage class, reporting which storage pools satisfy it and why the others don't",
+		request:  &storageclass.Config{},
+		response: &AddStorageClassResponse{},
+		status:   http.StatusCreated,
+	},
+	"GetStorageClass": {
+		summary:  "Get a storage class with its matching storage pools and why the others don't match",
+		response: &GetStorageClassResponse{},
+	},
+	"ListStorageClasses": {
+		summary:  "List the names of all storage classes",
+		response: &ListStorageClassesResponse{},
+	},
+	"UpdateStorageClass": {
+		summary:  "Replace a storage class's definition, reporting the storage pools it gains and loses",
+		request:  &storageclass.Config{},
+		response: &UpdateStorageClassResponse{},
+	},
+	"DeleteStorageClass": {
+		summary:  "Delete a storage class",
+		response: &DeleteResponse{},
+	},
+	"AddSnapshotSchedule": {
+		summary:  "Add a schedule on which to take snapshots of volumes",
+		request:  &storage.SnapshotScheduleConfig{},
+		response: &AddSnapshotScheduleResponse{},
+		status:   http.StatusCreated,
+	},
+	"GetSnapshotSchedule": {
+		summary:  "Get a snapshot schedule with its next run and the outcome of its last run",
+		response: &GetSnapshotScheduleResponse{},
+	},
+	"ListSnapshotSchedules": {
+		summary:  "List the names of all snapshot schedules",
+		response: &ListSnapshotSchedulesResponse{},
+	},
+	"DeleteSnapshotSchedule": {
+		summary:  "Delete a snapshot schedule, keeping the snapshots it has taken",
+		response: &DeleteResponse{},
+	},
+	"AddVolumeGroup": {
+		summary:  "Create a group of volumes, or clone each volume of another group",
+		request:  &storage.VolumeGroupConfig{},
+		response: &AddVolumeGroupResponse{},
+		status:   http.StatusCreated,
+	},
+	"GetVolumeGroup": {
+		summary:  "Get a volume group with its volumes",
+		response: &GetVolumeGroupResponse{},
+	},
+	"ListVolumeGroups": {
+		summary:  "List the names of all volume groups",
+		response: &ListVolumeGroupsResponse{},
+	},
+	"SnapshotVolumeGroup": {
+		summary:  "Snapshot each volume of a group, at the same point in time where possible",
+		request:  &SnapshotVolumeGroupRequest{},
+		response: &SnapshotVolumeGroupResponse{},
+		status:   http.StatusCreated,
+	},
+	"DeleteVolumeGroup": {
+		summary:  "Delete a volume group and its volumes",
+		response: &DeleteResponse{},
+	},
+	"MigrateVolume": {
+		summary:  "Start moving a volume to another backend",
+		request:  &storage.VolumeMigrationConfig{},
+		response: &GetVolumeMigrationResponse{},
+		status:   http.StatusAccepted,
+	},
+	"GetVolumeMigration": {
+		summary:  "Get the progress of a volume's migration",
+		response: &GetVolumeMigrationResponse{},
+	},
+	"ListVolumeMigrations": {
+		summary:  "List the names of all volumes being or recently migrated",
+		response: &ListVolumeMigrationsResponse{},
+	},
+	"CutoverVolumeMigration": {
+		summary:  "Switch a migrating volume to its new backend within the disruption window",
+		response: &GetVolumeMigrationResponse{},
+	},
+	"CancelVolumeMigration": {
+		summary:  "Cancel a volume's migration, deleting the copy on the new backend",
+		response: &DeleteResponse{},
+	},
+	"GetJob": {
+		summary:  "Get a long-running job",
+		response: &GetJobResponse{},
+	},
+	"ListJobs": {
+		summary:  "List the IDs of all jobs",
+		response: &ListJobsResponse{},
+	},
+	"GetLoggingConfig": {
+		summary:  "Get the log format and log levels",
+		response: &LoggingResponse{},
+	},
+	"SetLoggingConfig": {
+		summary:  "Change the log format and log levels",
+		request:  &logging.Config{},
+		response: &LoggingResponse{},
+	},
+	"GetLogs": {
+		summary:  "Get Trident's most recent log entries",
+		response: &LogsResponse{},
+		query: []QueryParam{
+			{"component", "string", "Comma-separated log components whose entries to return"},
+			{"level", "string", "Least severe level of the entries to return"},
+			{"since", "string", "RFC 3339 time before which entries are not returned"},
+			{"limit", "integer", "Number of most recent entries to return"},
+		},
+	},
+	"GetUsageReport": {
+		summary:  "Get the capacity provisioned and used over a period by namespace, storage class and backend",
+		response: &UsageReportResponse{},
+		query: []QueryParam{
+			{"start", "string", "RFC 3339 time at which the period starts; by default 30 days before its end"},
+			{"end", "string", "RFC 3339 time at which the period ends; by default now"},
+			{"format", "string", "json, the default, or csv for the report's entries as CSV"},
+		},
+	},
+	"GetReconciliationReport": {
+		summary:  "Get the most recent reconciliation of Trident's volumes with its backends",
+		response: &ReconcileResponse{},
+	},
+	"ReconcileBackends": {
+		summary:  "Reconcile Trident's volumes with its backends",
+		response: &ReconcileResponse{},
+		query: []QueryParam{
+			{"cleanup", "boolean", "Whether to delete orphaned volumes"},
+		},
+	},
+	"Healthz": {
+		summary:  "Check whether Trident can reach its persistent store",
+		response: &HealthResponse{},
+	},
+	"Readyz": {
+		summary:  "Check whether Trident is ready to serve requests",
+		response: &HealthResponse{},
+	},
+}
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+// Operations returns the operations of the REST API in the order of its routes.  An operation
+// served at more than one URL, such as the version, is returned once with its versioned URL.
+func Operations() ([]Operation, error) {
+
+	operations := make([]Operation, 0, len(routes))
+	indexes := make(map[string]int)
+
+	for _, route := range routes {
+
+		doc, ok := routeDocs[route.Name]
+		if !ok {
+			return nil, fmt.Errorf("route %s is not documented", route.Name)
+		}
+
+		operation := Operation{
+			Name:        route.Name,
+			Method:      route.Method,
+			Pattern:     route.Pattern,
+			Summary:     doc.summary,
+			QueryParams: doc.query,
+			Response:    reflect.TypeOf(doc.response),
+			Status:      doc.status,
+		}
+		for _, match := range pathParamRegex.FindAllStringSubmatch(route.Pattern, -1) {
+			operation.PathParams = append(operation.PathParams, match[1])
+		}
+		if doc.request != nil {
+			operation.Request = reflect.TypeOf(doc.request)
+		}
+		if operation.Status == 0 {
+			operation.Status = http.StatusOK
+		}
+
+		if i, ok := indexes[route.Name]; ok {
+			if strings.HasPrefix(route.Pattern, config.BaseURL) {
+				operations[i] = operation
+			}
+			continue
+		}
+		indexes[route.Name] = len(operations)
+		operations = append(operations, operation)
+	}
+
+	return operations, nil
+}
+
+// openAPISpec is a Swagger 2.0 document, limited to the parts that describe Trident's API.
+type openAPISpec struct {
+	Swagger     string                                  `json:"swagger"`
+	Info        openAPIInfo                             `json:"info"`
+	Consumes    []string                                `json:"consumes"`
+	Produces    []string                                `json:"produces"`
+	Paths       map[string]map[string]*openAPIOperation `json:"paths"`
+	Definitions map[string]*openAPISchema               `json:"definitions"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required"`
+	Type        string         `json:"type,omitempty"`
+	Schema      *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string         `json:"description"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// OpenAPISpec returns an OpenAPI (Swagger 2.0) specification of the REST API in JSON.  The
+// request and response schemas are derived from the JSON encoding of the types in routeDocs.
+func OpenAPISpec() ([]byte, error) {
+
+	operations, err := Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &openAPISpec{
+		Swagger:     "2.0",
+		Info:        openAPIInfo{Title: "Trident REST API", Version: config.OrchestratorAPIVersion},
+		Consumes:    []string{contentType},
+		Produces:    []string{contentType},
+		Paths:       make(map[string]map[string]*openAPIOperation),
+		Definitions: make(map[string]*openAPISchema),
+	}
+
+	for _, operation := range operations {
+
+		specOperation := &openAPIOperation{
+			OperationID: operation.Name,
+			Summary:     operation.Summary,
+			Responses: map[string]openAPIResponse{
+				fmt.Sprint(operation.Status): {
+					Description: http.StatusText(operation.Status),
+					Schema:      spec.schema(operation.Response),
+				},
+				"default": {
+					Description: "Error, described by the error field of the response",
+					Schema:      spec.schema(operation.Response),
+				},
+			},
+		}
+		for _, name := range operation.PathParams {
+			specOperation.Parameters = append(specOperation.Parameters,
+				openAPIParameter{Name: name, In: "path", Required: true, Type: "string"})
+		}
+		for _, param := range operation.QueryParams {
+			specOperation.Parameters = append(specOperation.Parameters, openAPIParameter{
+				Name: param.Name, In: "query", Description: param.Description, Type: param.Type,
+			})
+		}
+		if operation.Request != nil {
+			specOperation.Parameters = append(specOperation.Parameters, openAPIParameter{
+				Name: "body", In: "body", Required: true, Schema: spec.schema(operation.Request),
+			})
+		}
+
+		if spec.Paths[operation.Pattern] == nil {
+			spec.Paths[operation.Pattern] = make(map[string]*openAPIOperation)
+		}
+		spec.Paths[operation.Pattern][strings.ToLower(operation.Method)] = specOperation
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// schema returns the schema of a type's JSON encoding.  Named structs are added to the spec's
+// definitions and referenced, while anything encoded as arbitrary JSON has an empty schema.
+func (spec *openAPISpec) schema(t reflect.Type) *openAPISchema {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == rawMessageType:
+		return &openAPISchema{}
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		// Go's int and uint are 64 bits on every platform Trident supports
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: spec.schema(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: spec.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return spec.structSchema(t)
+		}
+		name := t.String()
+		if _, ok := spec.Definitions[name]; !ok {
+			// Reserve the name first, in case the struct refers to itself
+			spec.Definitions[name] = &openAPISchema{}
+			spec.Definitions[name] = spec.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/definitions/" + name}
+	}
+
+	return &openAPISchema{}
+}
+
+// structSchema returns the schema of a struct's fields, flattening embedded structs without a JSON
+// name as encoding/json does.
+func (spec *openAPISpec) structSchema(t reflect.Type) *openAPISchema {
+
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+
+	for i := 0; i < t.NumField(); i++ {
+
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embedded := range spec.structSchema(fieldType).Properties {
+				schema.Properties[embeddedName] = embedded
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if len(tag) > 1 && tag[1] == "string" {
+			schema.Properties[name] = &openAPISchema{Type: "string"}
+		} else {
+			schema.Properties[name] = spec.schema(field.Type)
+		}
+	}
+
+	return schema
+}
+
+// apiSpec is generated from the routes when the package is initialized, since the routes themselves
+// serve it.
+var (
+	apiSpec    []byte
+	apiSpecErr error
+)
+
+func init() {
+	apiSpec, apiSpecErr = OpenAPISpec()
+}
+
+// GetOpenAPISpec returns the OpenAPI specification of the REST API.
+func GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if apiSpecErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		if err := json.NewEncoder(w).Encode(map[string]string{"error": apiSpecErr.Error()}); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(apiSpec)
+}
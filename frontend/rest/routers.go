@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"github.com/netapp/trident/config"
 )
 
 func NewRouter() *mux.Router {
@@ -25,5 +27,12 @@ func NewRouter() *mux.Router {
 			Handler(handler)
 	}
 
+	// Metrics are in the Prometheus text format rather than JSON, so aren't among the documented routes
+	router.
+		Methods("GET").
+		Path(config.MetricsURL).
+		Name("Metrics").
+		Handler(Logger(http.HandlerFunc(Metrics), "Metrics"))
+
 	return router
 }
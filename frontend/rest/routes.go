@@ -31,6 +31,12 @@ var routes = Routes{
 		config.VersionURL,
 		GetVersion,
 	},
+	Route{
+		"GetOpenAPISpec",
+		"GET",
+		config.OpenAPIURL,
+		GetOpenAPISpec,
+	},
 	Route{
 		"AddBackend",
 		"POST",
@@ -49,12 +55,48 @@ var routes = Routes{
 		config.BackendURL,
 		ListBackends,
 	},
+	Route{
+		"UpdateBackend",
+		"PUT",
+		config.BackendURL + "/{backend}",
+		UpdateBackend,
+	},
+	Route{
+		"RenameBackend",
+		"POST",
+		config.BackendURL + "/{backend}/rename",
+		RenameBackend,
+	},
 	Route{
 		"DeleteBackend",
 		"DELETE",
 		config.BackendURL + "/{backend}",
 		DeleteBackend,
 	},
+	Route{
+		"CordonBackend",
+		"POST",
+		config.BackendURL + "/{backend}/cordon",
+		CordonBackend,
+	},
+	Route{
+		"UncordonBackend",
+		"DELETE",
+		config.BackendURL + "/{backend}/cordon",
+		UncordonBackend,
+	},
+	Route{
+		"UpdateBackendTraceFlags",
+		"POST",
+		config.BackendURL + "/{backend}/trace",
+		UpdateBackendTraceFlags,
+	},
+	Route{
+		"GetBackendVolume",
+		"GET",
+		config.BackendURL + "/{backend}/volume/{internalName}",
+		GetBackendVolume,
+	},
 	Route{
 		"AddVolume",
 		"POST",
@@ -79,6 +121,60 @@ var routes = Routes{
 		config.VolumeURL + "/{volume}",
 		DeleteVolume,
 	},
+	Route{
+		"UpdateVolumeQoS",
+		"PUT",
+		config.VolumeURL + "/{volume}/qos",
+		UpdateVolumeQoS,
+	},
+	Route{
+		"GetVolumeStats",
+		"GET",
+		config.VolumeURL + "/{volume}/stats",
+		GetVolumeStats,
+	},
+	Route{
+		"ForceDetachVolume",
+		"POST",
+		config.VolumeURL + "/{volume}/detach",
+		ForceDetachVolume,
+	},
+	Route{
+		"RehostVolume",
+		"POST",
+		config.VolumeURL + "/{volume}/rehost",
+		RehostVolume,
+	},
+	Route{
+		"ListVolumeStats",
+		"GET",
+		config.StatsURL + "/volume",
+		ListVolumeStats,
+	},
+	Route{
+		"AddVolumes",
+		"POST",
+		config.BatchURL + "/volume",
+		AddVolumes,
+	},
+	Route{
+		"DeleteVolumes",
+		"DELETE",
+		config.BatchURL + "/volume",
+		DeleteVolumes,
+	},
+	Route{
+		"CloneVolumes",
+		"POST",
+		config.VolumeURL + "/{volume}/clones",
+		CloneVolumes,
+	},
+	Route{
+		"PreviewVolumePlacement",
+		"POST",
+		config.PlacementURL,
+		PreviewVolumePlacement,
+	},
 	Route{
 		"AddStorageClass",
 		"POST",
@@ -97,10 +193,167 @@ var routes = Routes{
 		config.StorageClassURL,
 		ListStorageClasses,
 	},
+	Route{
+		"UpdateStorageClass",
+		"PUT",
+		config.StorageClassURL + "/{storageClass}",
+		UpdateStorageClass,
+	},
 	Route{
 		"DeleteStorageClass",
 		"DELETE",
 		config.StorageClassURL + "/{storageClass}",
 		DeleteStorageClass,
 	},
+	Route{
+		"AddSnapshotSchedule",
+		"POST",
+		config.ScheduleURL,
+		AddSnapshotSchedule,
+	},
+	Route{
+		"GetSnapshotSchedule",
+		"GET",
+		config.ScheduleURL + "/{snapshotSchedule}",
+		GetSnapshotSchedule,
+	},
+	Route{
+		"ListSnapshotSchedules",
+		"GET",
+		config.ScheduleURL,
+		ListSnapshotSchedules,
+	},
+	Route{
+		"DeleteSnapshotSchedule",
+		"DELETE",
+		config.ScheduleURL + "/{snapshotSchedule}",
+		DeleteSnapshotSchedule,
+	},
+	Route{
+		"AddVolumeGroup",
+		"POST",
+		config.VolumeGroupURL,
+		AddVolumeGroup,
+	},
+	Route{
+		"GetVolumeGroup",
+		"GET",
+		config.VolumeGroupURL + "/{volumeGroup}",
+		GetVolumeGroup,
+	},
+	Route{
+		"ListVolumeGroups",
+		"GET",
+		config.VolumeGroupURL,
+		ListVolumeGroups,
+	},
+	Route{
+		"SnapshotVolumeGroup",
+		"POST",
+		config.VolumeGroupURL + "/{volumeGroup}/snapshot",
+		SnapshotVolumeGroup,
+	},
+	Route{
+		"DeleteVolumeGroup",
+		"DELETE",
+		config.VolumeGroupURL + "/{volumeGroup}",
+		DeleteVolumeGroup,
+	},
+	Route{
+		"MigrateVolume",
+		"POST",
+		config.MigrationURL + "/{volume}",
+		MigrateVolume,
+	},
+	Route{
+		"GetVolumeMigration",
+		"GET",
+		config.MigrationURL + "/{volume}",
+		GetVolumeMigration,
+	},
+	Route{
+		"ListVolumeMigrations",
+		"GET",
+		config.MigrationURL,
+		ListVolumeMigrations,
+	},
+	Route{
+		"CutoverVolumeMigration",
+		"POST",
+		config.MigrationURL + "/{volume}/cutover",
+		CutoverVolumeMigration,
+	},
+	Route{
+		"CancelVolumeMigration",
+		"DELETE",
+		config.MigrationURL + "/{volume}",
+		CancelVolumeMigration,
+	},
+	Route{
+		"GetJob",
+		"GET",
+		config.JobURL + "/{job}",
+		GetJob,
+	},
+	Route{
+		"ListJobs",
+		"GET",
+		config.JobURL,
+		ListJobs,
+	},
+	Route{
+		"GetLoggingConfig",
+		"GET",
+		config.LoggingURL,
+		GetLoggingConfig,
+	},
+	Route{
+		"SetLoggingConfig",
+		"POST",
+		config.LoggingURL,
+		SetLoggingConfig,
+	},
+	Route{
+		"GetLogs",
+		"GET",
+		config.LogsURL,
+		GetLogs,
+	},
+	Route{
+		"GetUsageReport",
+		"GET",
+		config.UsageURL,
+		GetUsageReport,
+	},
+	Route{
+		"GetReconciliationReport",
+		"GET",
+		config.ReconcileURL,
+		GetReconciliationReport,
+	},
+	Route{
+		"ReconcileBackends",
+		"POST",
+		config.ReconcileURL,
+		ReconcileBackends,
+	},
+	Route{
+		"Healthz",
+		"GET",
+		config.HealthURL,
+		Healthz,
+	},
+	Route{
+		"Readyz",
+		"GET",
+		config.ReadyURL,
+		Readyz,
+	},
+}
+
+// probeRoutes are polled frequently by Kubernetes and monitoring tools, so are logged at debug level.
+var probeRoutes = map[string]bool{
+	"Healthz": true,
+	"Readyz":  true,
+	"Metrics": true,
 }
@@ -0,0 +1,173 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Entry is a log entry kept in memory, so that Trident's recent logs may be retrieved over REST
+// without access to its container or log file.
+type Entry struct {
+	Time      time.Time         `json:"time"`
+	Level     string            `json:"level"`
+	Component string            `json:"component,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// EntryFilter selects buffered log entries.  Entries match if they are from any of the components,
+// at least as severe as the level, and logged after the time.  Zero values match all entries, and
+// a limit keeps only the most recent matching entries.
+type EntryFilter struct {
+	Components []string
+	Level      string
+	Since      time.Time
+	Limit      int
+}
+
+// BufferHook keeps the most recent log entries that pass the component levels in a ring buffer.
+type BufferHook struct {
+	mutex   sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+var logBuffer = NewBufferHook(MaxBufferedEntries)
+
+// NewBufferHook creates a log hook that keeps up to size entries.
+func NewBufferHook(size int) *BufferHook {
+	return &BufferHook{entries: make([]Entry, size)}
+}
+
+// InitLogBuffer starts keeping recent log entries for GetEntries.
+func InitLogBuffer() {
+	log.AddHook(logBuffer)
+}
+
+func (hook *BufferHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (hook *BufferHook) Fire(entry *log.Entry) error {
+
+	component := callerComponent()
+	if entry.Level > componentLevel(component) {
+		return nil
+	}
+
+	message := entry.Message
+	if len(message) > MaxLogEntryLength {
+		message = message[:MaxLogEntryLength] + "<truncated>"
+	}
+
+	buffered := Entry{
+		Time:      entry.Time,
+		Level:     entry.Level.String(),
+		Component: component,
+		Message:   message,
+	}
+	if len(entry.Data) > 0 {
+		buffered.Fields = make(map[string]string, len(entry.Data))
+		for k, v := range entry.Data {
+			buffered.Fields[k] = fmt.Sprint(v)
+		}
+	}
+
+	hook.mutex.Lock()
+	hook.entries[hook.next] = buffered
+	hook.next = (hook.next + 1) % len(hook.entries)
+	if hook.next == 0 {
+		hook.full = true
+	}
+	hook.mutex.Unlock()
+
+	return nil
+}
+
+// Entries returns the buffered entries that match a filter, oldest first.
+func (hook *BufferHook) Entries(filter *EntryFilter) ([]Entry, error) {
+
+	level := log.DebugLevel
+	if filter.Level != "" {
+		parsedLevel, err := log.ParseLevel(filter.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = parsedLevel
+	}
+	for _, component := range filter.Components {
+		if !isComponent(component) {
+			return nil, fmt.Errorf("unknown log component %s; must be one of %s", component,
+				strings.Join(components, ", "))
+		}
+	}
+
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+
+	buffered := hook.entries[:hook.next]
+	if hook.full {
+		buffered = append(append([]Entry(nil), hook.entries[hook.next:]...), buffered...)
+	}
+
+	entries := make([]Entry, 0)
+	for _, entry := range buffered {
+		entryLevel, _ := log.ParseLevel(entry.Level)
+		if entryLevel > level || entry.Time.Before(filter.Since) || !filter.matchesComponent(entry.Component) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
+
+func (filter *EntryFilter) matchesComponent(component string) bool {
+	if len(filter.Components) == 0 {
+		return true
+	}
+	for _, c := range filter.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEntries returns Trident's recent log entries that match a filter, oldest first.
+func GetEntries(filter *EntryFilter) ([]Entry, error) {
+	return logBuffer.Entries(filter)
+}
+
+// FormatEntries writes log entries as plain text, in the same format as Trident's log file.
+func FormatEntries(entries []Entry) []byte {
+
+	var b bytes.Buffer
+	formatter := &PlainTextFormatter{}
+
+	for _, entry := range entries {
+		level, _ := log.ParseLevel(entry.Level)
+		data := make(log.Fields, len(entry.Fields)+1)
+		for k, v := range entry.Fields {
+			data[k] = v
+		}
+		if entry.Component != "" {
+			data["component"] = entry.Component
+		}
+		line, _ := formatter.Format(&log.Entry{Time: entry.Time, Level: level, Message: entry.Message, Data: data})
+		b.Write(line)
+	}
+
+	return b.Bytes()
+}
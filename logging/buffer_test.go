@@ -0,0 +1,77 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package logging
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestBufferHook(t *testing.T) {
+	hook := NewBufferHook(3)
+	start := time.Now()
+
+	for i, level := range []log.Level{log.InfoLevel, log.WarnLevel, log.DebugLevel, log.ErrorLevel} {
+		hook.Fire(&log.Entry{
+			Time:    start.Add(time.Duration(i) * time.Second),
+			Level:   level,
+			Message: level.String(),
+			Data:    log.Fields{"index": i},
+		})
+	}
+
+	// The debug entry is below the default level, and the ring drops the oldest entries
+	entries, err := hook.Entries(&EntryFilter{})
+	if err != nil {
+		t.Fatal("Unable to get entries: ", err)
+	}
+	if len(entries) != 3 || entries[0].Message != "info" || entries[2].Message != "error" {
+		t.Fatalf("Unexpected entries %+v", entries)
+	}
+	if entries[1].Fields["index"] != "1" {
+		t.Errorf("Expected fields to be kept, got %v", entries[1].Fields)
+	}
+
+	entries, _ = hook.Entries(&EntryFilter{Level: "warn"})
+	if len(entries) != 2 || entries[0].Message != "warning" {
+		t.Errorf("Expected only warnings and errors, got %+v", entries)
+	}
+	entries, _ = hook.Entries(&EntryFilter{Since: start.Add(1500 * time.Millisecond)})
+	if len(entries) != 1 || entries[0].Message != "error" {
+		t.Errorf("Expected only the last entry, got %+v", entries)
+	}
+	entries, _ = hook.Entries(&EntryFilter{Limit: 1})
+	if len(entries) != 1 || entries[0].Message != "error" {
+		t.Errorf("Expected only the most recent entry, got %+v", entries)
+	}
+
+	hook.entries[0].Component = ComponentCore
+	entries, _ = hook.Entries(&EntryFilter{Components: []string{ComponentCore, ComponentAPI}})
+	if len(entries) != 1 || entries[0].Component != ComponentCore {
+		t.Errorf("Expected only the core entry, got %+v", entries)
+	}
+
+	for _, filter := range []*EntryFilter{{Level: "loud"}, {Components: []string{"nonexistent"}}} {
+		if _, err = hook.Entries(filter); err == nil {
+			t.Errorf("Expected an error for filter %+v.", filter)
+		}
+	}
+}
+
+func TestFormatEntries(t *testing.T) {
+	text := string(FormatEntries([]Entry{{
+		Time:      time.Date(2018, 4, 1, 12, 0, 0, 0, time.UTC),
+		Level:     "warning",
+		Component: ComponentCore,
+		Message:   "Backend offline.",
+		Fields:    map[string]string{"backend": "ontapnas_10.0.0.1"},
+	}}))
+
+	if !strings.HasPrefix(text, "WARN[2018-04-01T12:00:00Z] Backend offline.") ||
+		!strings.Contains(text, `backend="ontapnas_10.0.0.1"`) || !strings.Contains(text, "component=core") {
+		t.Errorf("Unexpected formatted entry %q", text)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Log components, each of which may be given its own logging level
+const (
+	ComponentAPI       = "api"
+	ComponentONTAP     = "ontap"
+	ComponentSolidFire = "solidfire"
+	ComponentESeries   = "eseries"
+	ComponentCore      = "core"
+	ComponentStore     = "store"
+	ComponentFrontend  = "frontend"
+)
+
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var components = []string{
+	ComponentAPI, ComponentONTAP, ComponentSolidFire, ComponentESeries,
+	ComponentCore, ComponentStore, ComponentFrontend,
+}
+
+// componentPackages maps package paths to components.  The first matching entry wins, so the
+// storage API clients must precede the drivers that use them.
+var componentPackages = []struct {
+	pkg       string
+	component string
+}{
+	{"/storage_drivers/ontap/api", ComponentAPI},
+	{"/storage_drivers/solidfire/api", ComponentAPI},
+	{"/storage_drivers/eseries/api", ComponentAPI},
+	{"/storage_drivers/ontap", ComponentONTAP},
+	{"/storage_drivers/solidfire", ComponentSolidFire},
+	{"/storage_drivers/eseries", ComponentESeries},
+	{"/persistent_store", ComponentStore},
+	{"/frontend", ComponentFrontend},
+	{"/core", ComponentCore},
+	{"/storage", ComponentCore},
+}
+
+// Config describes the logging configuration, which may be changed while Trident is running.
+type Config struct {
+	Format     string            `json:"format"`
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+var logConfig = struct {
+	sync.RWMutex
+	format          string
+	level           log.Level
+	componentLevels map[string]log.Level
+}{
+	format:          FormatText,
+	level:           log.InfoLevel,
+	componentLevels: make(map[string]log.Level),
+}
+
+// GetConfig returns the current logging configuration.
+func GetConfig() *Config {
+	logConfig.RLock()
+	defer logConfig.RUnlock()
+
+	config := &Config{
+		Format:     logConfig.format,
+		Level:      logConfig.level.String(),
+		Components: make(map[string]string),
+	}
+	for component, level := range logConfig.componentLevels {
+		config.Components[component] = level.String()
+	}
+	return config
+}
+
+// SetConfig validates and applies a logging configuration.  Empty fields are left unchanged,
+// and a component level of "default" makes the component follow the overall level again.
+func SetConfig(config *Config) error {
+
+	format := config.Format
+	if format != "" && format != FormatText && format != FormatJSON {
+		return fmt.Errorf("invalid log format %s; must be %s or %s", format, FormatText, FormatJSON)
+	}
+
+	var level *log.Level
+	if config.Level != "" {
+		parsedLevel, err := log.ParseLevel(config.Level)
+		if err != nil {
+			return err
+		}
+		level = &parsedLevel
+	}
+
+	componentLevels := make(map[string]*log.Level)
+	for component, levelName := range config.Components {
+		if !isComponent(component) {
+			return fmt.Errorf("unknown log component %s; must be one of %s", component,
+				strings.Join(components, ", "))
+		}
+		if levelName == "default" {
+			componentLevels[component] = nil
+			continue
+		}
+		parsedLevel, err := log.ParseLevel(levelName)
+		if err != nil {
+			return fmt.Errorf("invalid level for log component %s: %v", component, err)
+		}
+		componentLevels[component] = &parsedLevel
+	}
+
+	logConfig.Lock()
+	if format != "" {
+		logConfig.format = format
+	}
+	if level != nil {
+		logConfig.level = *level
+	}
+	for component, componentLevel := range componentLevels {
+		if componentLevel == nil {
+			delete(logConfig.componentLevels, component)
+		} else {
+			logConfig.componentLevels[component] = *componentLevel
+		}
+	}
+	maxLevel := logConfig.level
+	for _, componentLevel := range logConfig.componentLevels {
+		if componentLevel > maxLevel {
+			maxLevel = componentLevel
+		}
+	}
+	logConfig.Unlock()
+
+	// Logrus discards anything below its global level before our formatters see it, so that
+	// must be the most verbose level of any component.
+	log.SetLevel(maxLevel)
+	return nil
+}
+
+// ParseComponentLevels parses a list of component levels such as "core=debug,api=warn".
+func ParseComponentLevels(levels string) (map[string]string, error) {
+	componentLevels := make(map[string]string)
+	if levels == "" {
+		return componentLevels, nil
+	}
+	for _, setting := range strings.Split(levels, ",") {
+		parts := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid log component level %s; must be <component>=<level>", setting)
+		}
+		componentLevels[parts[0]] = parts[1]
+	}
+	return componentLevels, nil
+}
+
+// GetComponents returns the names of the log components.
+func GetComponents() []string {
+	names := append([]string(nil), components...)
+	sort.Strings(names)
+	return names
+}
+
+func isComponent(name string) bool {
+	for _, component := range components {
+		if component == name {
+			return true
+		}
+	}
+	return false
+}
+
+// componentLevel returns the level of a component, below which its entries are dropped.
+func componentLevel(component string) log.Level {
+	logConfig.RLock()
+	defer logConfig.RUnlock()
+
+	if level, ok := logConfig.componentLevels[component]; ok {
+		return level
+	}
+	return logConfig.level
+}
+
+// ComponentFormatter formats log entries in the configured format, dropping those that are
+// below the level of the component that logged them.
+type ComponentFormatter struct {
+	text log.Formatter
+	json *log.JSONFormatter
+}
+
+// NewComponentFormatter returns a formatter that writes text entries with the supplied text
+// formatter, or JSON entries when so configured.
+func NewComponentFormatter(text log.Formatter) *ComponentFormatter {
+	return &ComponentFormatter{
+		text: text,
+		json: &log.JSONFormatter{},
+	}
+}
+
+func (f *ComponentFormatter) Format(entry *log.Entry) ([]byte, error) {
+
+	component := callerComponent()
+
+	// Returning nothing causes logrus to write nothing
+	if entry.Level > componentLevel(component) {
+		return nil, nil
+	}
+
+	logConfig.RLock()
+	format := logConfig.format
+	logConfig.RUnlock()
+
+	if component != "" {
+		entry = entryWithComponent(entry, component)
+	}
+	if format == FormatJSON {
+		return f.json.Format(entry)
+	}
+	return f.text.Format(entry)
+}
+
+// entryWithComponent returns a copy of the entry with the component added to its fields, so
+// the entry's own fields, which may be shared, are left alone.
+func entryWithComponent(entry *log.Entry, component string) *log.Entry {
+	data := make(log.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data["component"] = component
+
+	entryCopy := *entry
+	entryCopy.Data = data
+	return &entryCopy
+}
+
+// callerComponent returns the component containing the code that made the logging call, or
+// an empty string if the caller isn't part of any component.
+func callerComponent() string {
+
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "sirupsen/logrus") &&
+			!strings.Contains(frame.Function, "/trident/logging.") {
+			return packageComponent(frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packageComponent returns the component for a fully qualified function name.
+func packageComponent(function string) string {
+
+	// Trim the function name, leaving only the package path
+	if slash := strings.LastIndex(function, "/"); slash >= 0 {
+		if dot := strings.Index(function[slash:], "."); dot >= 0 {
+			function = function[:slash+dot]
+		}
+	}
+	if !strings.Contains(function, "/trident/") || strings.Contains(function, "/vendor/") {
+		return ""
+	}
+	path := function[strings.Index(function, "/trident/")+len("/trident"):]
+
+	for _, mapping := range componentPackages {
+		if strings.HasPrefix(path, mapping.pkg) {
+			return mapping.component
+		}
+	}
+	return ""
+}
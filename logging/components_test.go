@@ -0,0 +1,76 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package logging
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestPackageComponent(t *testing.T) {
+	for function, expected := range map[string]string{
+		"github.com/netapp/trident/storage_drivers/ontap/api.(*Client).VolumeCreate":  ComponentAPI,
+		"github.com/netapp/trident/storage_drivers/ontap.(*NASStorageDriver).Create":  ComponentONTAP,
+		"github.com/netapp/trident/storage_drivers/solidfire/api.(*Client).Request":   ComponentAPI,
+		"github.com/netapp/trident/core.(*TridentOrchestrator).AddVolume.func1":       ComponentCore,
+		"github.com/netapp/trident/storage.(*Backend).AddVolume":                      ComponentCore,
+		"github.com/netapp/trident/persistent_store.(*EtcdClientV3).AddVolume":        ComponentStore,
+		"github.com/netapp/trident/frontend/kubernetes.(*Plugin).processPendingClaim": ComponentFrontend,
+		"github.com/netapp/trident/vendor/github.com/coreos/etcd/clientv3.(*kv).Get":  "",
+		"main.main": "",
+	} {
+		if component := packageComponent(function); component != expected {
+			t.Errorf("Expected component %q for %s, got %q.", expected, function, component)
+		}
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	defer SetConfig(&Config{Format: FormatText, Level: "info", Components: map[string]string{
+		ComponentCore: "default", ComponentAPI: "default"}})
+
+	err := SetConfig(&Config{Format: FormatJSON, Level: "warn", Components: map[string]string{
+		ComponentCore: "debug", ComponentAPI: "error"}})
+	if err != nil {
+		t.Fatal("Unable to set logging config: ", err)
+	}
+	config := GetConfig()
+	if config.Format != FormatJSON || config.Level != log.WarnLevel.String() ||
+		config.Components[ComponentCore] != log.DebugLevel.String() ||
+		config.Components[ComponentAPI] != log.ErrorLevel.String() {
+		t.Errorf("Unexpected logging config: %+v", config)
+	}
+
+	// A component may revert to the overall level
+	if err = SetConfig(&Config{Components: map[string]string{ComponentCore: "default"}}); err != nil {
+		t.Fatal("Unable to set logging config: ", err)
+	}
+	if _, ok := GetConfig().Components[ComponentCore]; ok {
+		t.Error("Component level was not reset to the default.")
+	}
+
+	for _, config := range []*Config{
+		{Format: "xml"},
+		{Level: "loud"},
+		{Components: map[string]string{"nonexistent": "debug"}},
+		{Components: map[string]string{ComponentCore: "loud"}},
+	} {
+		if err = SetConfig(config); err == nil {
+			t.Errorf("Expected an error for logging config %+v.", config)
+		}
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels("core=debug, api=warn")
+	if err != nil {
+		t.Fatal("Unable to parse component levels: ", err)
+	}
+	if len(levels) != 2 || levels["core"] != "debug" || levels["api"] != "warn" {
+		t.Errorf("Unexpected component levels: %v", levels)
+	}
+	if _, err = ParseComponentLevels("core"); err == nil {
+		t.Error("Expected an error for a component level without a level.")
+	}
+}
@@ -8,4 +8,5 @@ const (
 	LogRoot              = "/var/log/" + config.OrchestratorName
 	LogRotationThreshold = 10485760 // 10 MB
 	MaxLogEntryLength    = 64000
+	MaxBufferedEntries   = 10000 // recent entries kept in memory for retrieval over REST
 )
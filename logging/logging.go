@@ -52,27 +52,36 @@ func InitLogging(logName string) error {
 // otherwise the logLevel flag (debug, info, warn, error, fatal) is used.
 func InitLogLevel(debug bool, logLevel string) error {
 	if debug {
-		log.SetLevel(log.DebugLevel)
-	} else {
-		level, err := log.ParseLevel(logLevel)
-		if err != nil {
-			return err
-		}
-		log.SetLevel(level)
+		logLevel = log.DebugLevel.String()
+	}
+	return SetConfig(&Config{Level: logLevel})
+}
+
+// InitLogFormat configures the log format (text or json) and any per-component logging levels,
+// which are specified as a list such as "core=debug,api=warn".
+func InitLogFormat(logFormat, componentLevels string) error {
+	levels, err := ParseComponentLevels(componentLevels)
+	if err != nil {
+		return err
+	}
+	if err = SetConfig(&Config{Format: logFormat, Components: levels}); err != nil {
+		return err
 	}
+	log.SetFormatter(NewComponentFormatter(&log.TextFormatter{}))
 	return nil
 }
 
 // ConsoleHook sends log entries to stdout.
 type ConsoleHook struct {
-	formatter log.Formatter
+	textFormatter *log.TextFormatter
+	formatter     log.Formatter
 }
 
 // NewConsoleHook creates a new log hook for writing to stdout/stderr.
 func NewConsoleHook() *ConsoleHook {
 
-	formatter := &log.TextFormatter{FullTimestamp: true}
-	return &ConsoleHook{formatter}
+	textFormatter := &log.TextFormatter{FullTimestamp: true}
+	return &ConsoleHook{textFormatter, NewComponentFormatter(textFormatter)}
 }
 
 func (hook *ConsoleHook) Levels() []log.Level {
@@ -100,7 +109,7 @@ func (hook *ConsoleHook) Fire(entry *log.Entry) error {
 	}
 
 	// Write log entry to output stream
-	hook.formatter.(*log.TextFormatter).ForceColors = hook.checkIfTerminal(logWriter)
+	hook.textFormatter.ForceColors = hook.checkIfTerminal(logWriter)
 	lineBytes, err := hook.formatter.Format(entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to read entry, %v", err)
@@ -126,7 +135,7 @@ type FileHook struct {
 // NewFileHook creates a new log hook for writing to a file.
 func NewFileHook(logName string) (*FileHook, error) {
 
-	formatter := &PlainTextFormatter{}
+	formatter := NewComponentFormatter(&PlainTextFormatter{})
 
 	// If config.LogRoot doesn't exist, make it
 	dir, err := os.Lstat(LogRoot)
@@ -170,6 +179,11 @@ func (hook *FileHook) Fire(entry *log.Entry) error {
 		return err
 	}
 
+	// Nothing to write if the entry was filtered out
+	if len(lineBytes) == 0 {
+		return nil
+	}
+
 	// Write log entry to file
 	logFile, err := hook.openFile()
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -21,12 +22,16 @@ import (
 	"github.com/netapp/trident/frontend/rest"
 	"github.com/netapp/trident/logging"
 	"github.com/netapp/trident/persistent_store"
+	"github.com/netapp/trident/utils"
 )
 
 var (
 	// Logging
-	debug    = flag.Bool("debug", false, "Enable debugging output")
-	logLevel = flag.String("log_level", "info", "Logging level (debug, info, warn, error, fatal)")
+	debug              = flag.Bool("debug", false, "Enable debugging output")
+	logLevel           = flag.String("log_level", "info", "Logging level (debug, info, warn, error, fatal)")
+	logFormat          = flag.String("log_format", "text", "Logging format (text, json)")
+	logComponentLevels = flag.String("log_component_levels", "", "Logging levels for individual "+
+		"components (api, ontap, solidfire, eseries, core, store, frontend), e.g. core=debug,api=warn")
 
 	// Kubernetes
 	k8sAPIServer = flag.String("k8s_api_server", "", "Kubernetes API server "+
@@ -57,12 +62,42 @@ var (
 		"any metadata.  WILL LOSE TRACK OF VOLUMES ON REBOOT/CRASH.")
 	usePassthrough = flag.Bool("passthrough", false, "Uses the storage backends "+
 		"as the source of truth.  No data is stored anywhere else.")
+	leaseDuration = flag.Duration("lease_duration", core.DefaultLeaseDuration, "How long this "+
+		"instance's lease on the persistent store lasts unless renewed; other instances refuse to "+
+		"use the store while it is held (0 disables the lease)")
 
 	// REST interface
 	address    = flag.String("address", "127.0.0.1", "Storage orchestrator API address")
 	port       = flag.String("port", "8000", "Storage orchestrator API port")
 	enableREST = flag.Bool("rest", true, "Enable REST interface")
 
+	// Backend reconciliation
+	reconcileInterval = flag.Duration("reconcile_interval", time.Hour, "Interval between "+
+		"checks for orphaned and missing backend objects (0 disables periodic checks)")
+	reconcileCleanup = flag.Bool("reconcile_cleanup", false, "Delete orphaned backend "+
+		"objects found by periodic checks (not allowed with a passthrough store)")
+	resyncInterval = flag.Duration("resync_interval", 6*time.Hour, "Interval between "+
+		"refreshes of the volumes' existence and size from their backends (0 disables the resync)")
+	cleanupInterval = flag.Duration("cleanup_interval", core.DefaultCleanupInterval, "Interval between "+
+		"cleanups of what failed operations left on the backends (0 disables periodic cleanups)")
+	staleOperationAge = flag.Duration("stale_operation_age", core.DefaultStaleOperationAge,
+		"How long a volume operation, such as a clone, may go unfinished before it is cleaned up")
+
+	// Latency tracking
+	slowOperationThreshold = flag.Duration("slow_operation_threshold", core.DefaultSlowOperationThreshold,
+		"Duration beyond which volume operations are logged as slow (0 disables logging)")
+
+	// Usage reporting
+	usageSampleInterval = flag.Duration("usage_sample_interval", core.DefaultUsageSampleInterval,
+		"Interval between samples of the volumes' capacity for usage reports (0 disables sampling)")
+	usageRetention = flag.Duration("usage_retention", core.DefaultUsageRetention,
+		"How long samples of the volumes' capacity are kept (0 keeps them indefinitely)")
+
+	// Mount option policy
+	allowedMountOptions = flag.String("allowed_mount_options", "", "Mount options users may set in "+
+		"storage classes and PVC annotations beyond the defaults, separated by commas, or * for any "+
+		"not known to be unsafe")
+
 	storeClient      persistentstore.Client
 	enableKubernetes bool
 	enableDocker     bool
@@ -165,6 +200,11 @@ func processCmdLineArgs() {
 	}
 
 	config.UsingPassthroughStore = storeClient.GetType() == persistentstore.PassthroughStore
+
+	// Other hosts may be using the same storage, so their volumes would look like orphans
+	if *reconcileCleanup && config.UsingPassthroughStore {
+		log.Fatal("The reconcile_cleanup option can't be used with a passthrough store.")
+	}
 }
 
 func main() {
@@ -180,6 +220,10 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err = logging.InitLogFormat(*logFormat, *logComponentLevels); err != nil {
+		log.Fatal(err)
+	}
+	logging.InitLogBuffer()
 
 	log.WithFields(log.Fields{
 		"version":    config.OrchestratorVersion.String(),
@@ -188,6 +232,7 @@ func main() {
 	}).Info("Running Trident storage orchestrator.")
 
 	processCmdLineArgs()
+	utils.SetAllowedMountOptions(strings.Split(*allowedMountOptions, ","))
 
 	orchestrator := core.NewTridentOrchestrator(storeClient)
 
@@ -239,9 +284,17 @@ func main() {
 	}
 
 	// Bootstrap the orchestrator and start its frontends
+	orchestrator.SetLeaseDuration(*leaseDuration)
 	if err = orchestrator.Bootstrap(); err != nil {
 		log.Fatal(err.Error())
 	}
+	orchestrator.SetSlowOperationThreshold(*slowOperationThreshold)
+	orchestrator.StartReconciler(*reconcileInterval, *reconcileCleanup)
+	orchestrator.StartResync(*resyncInterval)
+	orchestrator.StartCleaner(*cleanupInterval, *staleOperationAge)
+	orchestrator.StartSnapshotScheduler()
+	orchestrator.StartWarmPools()
+	orchestrator.StartUsageSampler(*usageSampleInterval, *usageRetention)
 	for _, f := range frontends {
 		f.Activate()
 	}
@@ -249,10 +302,20 @@ func main() {
 	// Register and wait for a shutdown signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
-	log.Info("Shutting down.")
+	leaseLost := false
+	select {
+	case <-c:
+		log.Info("Shutting down.")
+	case <-orchestrator.LeaseLost():
+		log.Error("Shutting down, as another instance may be managing the persistent store.")
+		leaseLost = true
+	}
 	for _, f := range frontends {
 		f.Deactivate()
 	}
+	orchestrator.Stop()
 	storeClient.Stop()
+	if leaseLost {
+		os.Exit(1)
+	}
 }
@@ -5,6 +5,7 @@ package persistentstore
 const (
 	KeyNotFoundErr        = "Unable to find key"
 	KeyExistsErr          = "Key already exists"
+	KeyChangedErr         = "Key was changed by another client"
 	UnavailableClusterErr = "Unavailable etcd cluster"
 )
 
@@ -33,6 +34,13 @@ func MatchKeyNotFoundErr(err error) bool {
 	return false
 }
 
+func MatchKeyChangedErr(err error) bool {
+	if err != nil && err.Error() == KeyChangedErr {
+		return true
+	}
+	return false
+}
+
 func MatchUnavailableClusterErr(err error) bool {
 	if err != nil && err.Error() == UnavailableClusterErr {
 		return true
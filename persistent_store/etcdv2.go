@@ -15,6 +15,7 @@ import (
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage_class"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 type EtcdClientV2 struct {
@@ -223,6 +224,73 @@ func (p *EtcdClientV2) SetVersion(version *PersistentStateVersion) error {
 	return p.Set(config.StoreURL, string(versionJSON))
 }
 
+// GetLease returns the record of the Trident instance managing the persistent store
+func (p *EtcdClientV2) GetLease() (*Lease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+	resp, err := p.keysAPI.Get(ctx, config.LeaseURL, &etcdclientv2.GetOptions{Quorum: true})
+	cancel()
+	if err != nil {
+		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
+			return nil, NewPersistentStoreError(KeyNotFoundErr, config.LeaseURL)
+		}
+		return nil, err
+	}
+	lease := &Lease{}
+	if err = json.Unmarshal([]byte(resp.Node.Value), lease); err != nil {
+		return nil, err
+	}
+	lease.Revision = int64(resp.Node.ModifiedIndex)
+	return lease, nil
+}
+
+// SwapLease records the Trident instance managing the persistent store, provided the stored lease
+// is still the previous one read, or there is none if previous is nil.  Otherwise the lease is
+// left as it is and a KeyChangedErr is returned.
+func (p *EtcdClientV2) SwapLease(previous, lease *Lease) error {
+	leaseJSON, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	options := &etcdclientv2.SetOptions{PrevExist: etcdclientv2.PrevNoExist}
+	if previous != nil {
+		options = &etcdclientv2.SetOptions{PrevIndex: uint64(previous.Revision)}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+	resp, err := p.keysAPI.Set(ctx, config.LeaseURL, string(leaseJSON), options)
+	cancel()
+	if err != nil {
+		return leaseErrorV2(err)
+	}
+	lease.Revision = int64(resp.Node.ModifiedIndex)
+	return nil
+}
+
+// DeleteLease forgets the Trident instance managing the persistent store, provided the stored
+// lease is still the one read
+func (p *EtcdClientV2) DeleteLease(lease *Lease) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+	_, err := p.keysAPI.Delete(ctx, config.LeaseURL,
+		&etcdclientv2.DeleteOptions{PrevIndex: uint64(lease.Revision)})
+	cancel()
+	if err != nil {
+		return leaseErrorV2(err)
+	}
+	return nil
+}
+
+// leaseErrorV2 turns the errors of a conditional write to the lease into persistent store errors
+func leaseErrorV2(err error) error {
+	if etcdErr, ok := err.(etcdclientv2.Error); ok {
+		switch etcdErr.Code {
+		case etcdclientv2.ErrorCodeKeyNotFound:
+			return NewPersistentStoreError(KeyNotFoundErr, config.LeaseURL)
+		case etcdclientv2.ErrorCodeTestFailed, etcdclientv2.ErrorCodeNodeExist:
+			return NewPersistentStoreError(KeyChangedErr, config.LeaseURL)
+		}
+	}
+	return err
+}
+
 // AddBackend saves the minimally required backend state to the persistent store
 func (p *EtcdClientV2) AddBackend(b *storage.Backend) error {
 	backend := b.ConstructPersistent()
@@ -309,8 +377,7 @@ func (p *EtcdClientV2) DeleteBackends() error {
 
 // AddVolume saves a volume's state to the persistent store
 func (p *EtcdClientV2) AddVolume(vol *storage.Volume) error {
-	volExternal := vol.ConstructExternal()
-	volJSON, err := json.Marshal(volExternal)
+	volJSON, err := json.Marshal(vol.ConstructPersistent())
 	if err != nil {
 		return err
 	}
@@ -337,8 +404,7 @@ func (p *EtcdClientV2) GetVolume(volName string) (*storage.VolumeExternal, error
 
 // UpdateVolume updates a volume's state on the persistent store
 func (p *EtcdClientV2) UpdateVolume(vol *storage.Volume) error {
-	volExternal := vol.ConstructExternal()
-	volJSON, err := json.Marshal(volExternal)
+	volJSON, err := json.Marshal(vol.ConstructPersistent())
 	if err != nil {
 		return err
 	}
@@ -469,6 +535,191 @@ func (p *EtcdClientV2) DeleteVolumeTransaction(volTxn *VolumeTransaction) error
 	return nil
 }
 
+// AddJournalEntry saves a driver operation journal entry, overwriting any earlier version of it
+func (p *EtcdClientV2) AddJournalEntry(entry *drivers.JournalEntry) error {
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.JournalURL+"/"+entry.Key(), string(entryJSON))
+}
+
+// GetJournalEntries retrieves the entries for driver operations that haven't finished
+func (p *EtcdClientV2) GetJournalEntries() ([]*drivers.JournalEntry, error) {
+	entryList := make([]*drivers.JournalEntry, 0)
+	keys, err := p.ReadKeys(config.JournalURL)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return entryList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		entry := &drivers.JournalEntry{}
+		entryJSON, err := p.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(entryJSON), entry); err != nil {
+			return nil, err
+		}
+		entryList = append(entryList, entry)
+	}
+	return entryList, nil
+}
+
+// DeleteJournalEntry deletes the entry for a finished driver operation
+func (p *EtcdClientV2) DeleteJournalEntry(entry *drivers.JournalEntry) error {
+	return p.Delete(config.JournalURL + "/" + entry.Key())
+}
+
+// AddSnapshotSchedule saves a snapshot schedule and its state, overwriting any earlier version of it
+func (p *EtcdClientV2) AddSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	scheduleJSON, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.ScheduleURL+"/"+schedule.Config.Name, string(scheduleJSON))
+}
+
+// GetSnapshotSchedules retrieves all snapshot schedules
+func (p *EtcdClientV2) GetSnapshotSchedules() ([]*storage.SnapshotSchedule, error) {
+	scheduleList := make([]*storage.SnapshotSchedule, 0)
+	keys, err := p.ReadKeys(config.ScheduleURL)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return scheduleList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		schedule := &storage.SnapshotSchedule{}
+		scheduleJSON, err := p.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(scheduleJSON), schedule); err != nil {
+			return nil, err
+		}
+		scheduleList = append(scheduleList, schedule)
+	}
+	return scheduleList, nil
+}
+
+// DeleteSnapshotSchedule deletes a snapshot schedule
+func (p *EtcdClientV2) DeleteSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	return p.Delete(config.ScheduleURL + "/" + schedule.Config.Name)
+}
+
+// AddVolumeGroup saves a volume group, overwriting any earlier version of it
+func (p *EtcdClientV2) AddVolumeGroup(group *storage.VolumeGroup) error {
+	groupJSON, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.VolumeGroupURL+"/"+group.Name, string(groupJSON))
+}
+
+// GetVolumeGroups retrieves all volume groups
+func (p *EtcdClientV2) GetVolumeGroups() ([]*storage.VolumeGroup, error) {
+	groupList := make([]*storage.VolumeGroup, 0)
+	keys, err := p.ReadKeys(config.VolumeGroupURL)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return groupList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		group := &storage.VolumeGroup{}
+		groupJSON, err := p.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(groupJSON), group); err != nil {
+			return nil, err
+		}
+		groupList = append(groupList, group)
+	}
+	return groupList, nil
+}
+
+// DeleteVolumeGroup deletes a volume group
+func (p *EtcdClientV2) DeleteVolumeGroup(group *storage.VolumeGroup) error {
+	return p.Delete(config.VolumeGroupURL + "/" + group.Name)
+}
+
+// AddVolumeNameMapping saves the mapping of a volume's internal name, overwriting any earlier version of it
+func (p *EtcdClientV2) AddVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.VolumeNameURL+"/"+mapping.Key(), string(mappingJSON))
+}
+
+// GetVolumeNameMappings retrieves the mappings of all volumes' internal names
+func (p *EtcdClientV2) GetVolumeNameMappings() ([]*storage.VolumeNameMapping, error) {
+	mappingList := make([]*storage.VolumeNameMapping, 0)
+	keys, err := p.ReadKeys(config.VolumeNameURL)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return mappingList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		mapping := &storage.VolumeNameMapping{}
+		mappingJSON, err := p.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(mappingJSON), mapping); err != nil {
+			return nil, err
+		}
+		mappingList = append(mappingList, mapping)
+	}
+	return mappingList, nil
+}
+
+// DeleteVolumeNameMapping deletes the mapping of a volume's internal name
+func (p *EtcdClientV2) DeleteVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	return p.Delete(config.VolumeNameURL + "/" + mapping.Key())
+}
+
+// AddUsageRecord saves a sample of the capacity of every volume
+func (p *EtcdClientV2) AddUsageRecord(record *storage.UsageRecord) error {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.UsageURL+"/"+record.Key(), string(recordJSON))
+}
+
+// GetUsageRecords retrieves all samples of the capacity of the volumes
+func (p *EtcdClientV2) GetUsageRecords() ([]*storage.UsageRecord, error) {
+	recordList := make([]*storage.UsageRecord, 0)
+	keys, err := p.ReadKeys(config.UsageURL)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return recordList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		record := &storage.UsageRecord{}
+		recordJSON, err := p.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(recordJSON), record); err != nil {
+			return nil, err
+		}
+		recordList = append(recordList, record)
+	}
+	return recordList, nil
+}
+
+// DeleteUsageRecord deletes a sample of the capacity of the volumes
+func (p *EtcdClientV2) DeleteUsageRecord(record *storage.UsageRecord) error {
+	return p.Delete(config.UsageURL + "/" + record.Key())
+}
+
 func (p *EtcdClientV2) AddStorageClass(sc *storageclass.StorageClass) error {
 	sClass := sc.ConstructPersistent()
 	storageClassJSON, err := json.Marshal(sClass)
@@ -483,6 +734,15 @@ func (p *EtcdClientV2) AddStorageClass(sc *storageclass.StorageClass) error {
 	return nil
 }
 
+// UpdateStorageClass replaces the state of an existing storage class in the persistent store
+func (p *EtcdClientV2) UpdateStorageClass(sc *storageclass.StorageClass) error {
+	storageClassJSON, err := json.Marshal(sc.ConstructPersistent())
+	if err != nil {
+		return err
+	}
+	return p.Update(config.StorageClassURL+"/"+sc.GetName(), string(storageClassJSON))
+}
+
 func (p *EtcdClientV2) GetStorageClass(scName string) (*storageclass.Persistent, error) {
 	var sc storageclass.Persistent
 	scJSON, err := p.Read(config.StorageClassURL + "/" + scName)
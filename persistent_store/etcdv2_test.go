@@ -715,6 +715,22 @@ func TestEtcdv2AddStorageClass(t *testing.T) {
 		}
 	}
 
+	// Validating that an update replaces the stored config
+	bronzeConfig.Attributes["media"] = storageattribute.NewStringRequest("ssd")
+	bronzeConfig.Aliases = []string{"copper"}
+	bronzeConfig.Revision = 1
+	if err := p.UpdateStorageClass(bronzeClass); err != nil {
+		t.Fatal(err.Error())
+	}
+	retrievedSC, err = p.GetStorageClass(bronzeConfig.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if retrievedSC.Config.Attributes["media"].Value().(string) != "ssd" ||
+		retrievedSC.Config.Revision != 1 || len(retrievedSC.Config.Aliases) != 1 {
+		t.Errorf("Could not retrieve the updated storage class, got %+v", retrievedSC.Config)
+	}
+
 	if err := p.DeleteStorageClass(bronzeClass); err != nil {
 		t.Fatal(err.Error())
 	}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -569,6 +570,139 @@ func TestEtcdv3VolumeTransactions(t *testing.T) {
 	}
 }
 
+func TestEtcdv3JournalEntries(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+
+	// Adding journal entries, recording a step for each
+	for i := 1; i <= 3; i++ {
+		entry := drivers.NewJournalEntry("clone", "vol"+strconv.Itoa(i), map[string]string{"source": "src"})
+		entry.Backend = "ontapnas_10.0.0.1"
+		if err = p.AddJournalEntry(entry); err != nil {
+			t.Error(err.Error())
+			t.FailNow()
+		}
+		entry.Steps = append(entry.Steps, "snapshot")
+		if err = p.AddJournalEntry(entry); err != nil {
+			t.Error(err.Error())
+			t.FailNow()
+		}
+	}
+
+	// Retrieving journal entries
+	entries, err := p.GetJournalEntries()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 journal entries, got %d.", len(entries))
+	}
+	for _, entry := range entries {
+		if !entry.HasStep("snapshot") || entry.Params["source"] != "src" {
+			t.Errorf("Journal entry %s wasn't updated correctly.", entry.Key())
+		}
+	}
+
+	// Deleting journal entries
+	for _, entry := range entries {
+		if err = p.DeleteJournalEntry(entry); err != nil {
+			t.Error(err.Error())
+		}
+	}
+	entries, err = p.GetJournalEntries()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(entries) != 0 {
+		t.Error("Didn't delete all journal entries!")
+	}
+}
+
+func TestEtcdv3SnapshotSchedules(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+
+	// Adding a schedule, then saving it again after it runs
+	schedule := storage.NewSnapshotSchedule(&storage.SnapshotScheduleConfig{
+		Name:         "hourly",
+		Schedule:     "0 * * * *",
+		StorageClass: "gold",
+		Retain:       24,
+	}, time.Now())
+	if err = p.AddSnapshotSchedule(schedule); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	schedule.State.Volumes["vol1"] = &storage.ScheduledVolumeState{LastSnapshot: "hourly-20180401-1200"}
+	if err = p.AddSnapshotSchedule(schedule); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+
+	// Retrieving schedules
+	schedules, err := p.GetSnapshotSchedules()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(schedules) != 1 || schedules[0].Config.Retain != 24 ||
+		schedules[0].State.Volumes["vol1"].LastSnapshot != "hourly-20180401-1200" {
+		t.Errorf("Snapshot schedule wasn't saved correctly: %v", schedules)
+	}
+
+	// Deleting schedules
+	if err = p.DeleteSnapshotSchedule(schedule); err != nil {
+		t.Error(err.Error())
+	}
+	schedules, err = p.GetSnapshotSchedules()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(schedules) != 0 {
+		t.Error("Didn't delete the snapshot schedule!")
+	}
+}
+
+func TestEtcdv3VolumeGroups(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+
+	// Adding a group, then saving it again after one of its volumes is deleted
+	group := &storage.VolumeGroup{Name: "db", Volumes: []string{"db-data", "db-log"}}
+	if err = p.AddVolumeGroup(group); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	group.RemoveVolume("db-log")
+	if err = p.AddVolumeGroup(group); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+
+	// Retrieving groups
+	groups, err := p.GetVolumeGroups()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0].Volumes, []string{"db-data"}) {
+		t.Errorf("Volume group wasn't saved correctly: %v", groups)
+	}
+
+	// Deleting groups
+	if err = p.DeleteVolumeGroup(group); err != nil {
+		t.Error(err.Error())
+	}
+	groups, err = p.GetVolumeGroups()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if len(groups) != 0 {
+		t.Error("Didn't delete the volume group!")
+	}
+}
+
 func TestEtcdv3DuplicateVolumeTransaction(t *testing.T) {
 	firstTxn := &VolumeTransaction{
 		Config: &storage.VolumeConfig{
@@ -710,6 +844,22 @@ func TestEtcdv3AddStorageClass(t *testing.T) {
 		}
 	}
 
+	// Validating that an update replaces the stored config
+	bronzeConfig.Attributes["media"] = storageattribute.NewStringRequest("ssd")
+	bronzeConfig.Aliases = []string{"copper"}
+	bronzeConfig.Revision = 1
+	if err := p.UpdateStorageClass(bronzeClass); err != nil {
+		t.Fatal(err.Error())
+	}
+	retrievedSC, err = p.GetStorageClass(bronzeConfig.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if retrievedSC.Config.Attributes["media"].Value().(string) != "ssd" ||
+		retrievedSC.Config.Revision != 1 || len(retrievedSC.Config.Aliases) != 1 {
+		t.Errorf("Could not retrieve the updated storage class, got %+v", retrievedSC.Config)
+	}
+
 	if err := p.DeleteStorageClass(bronzeClass); err != nil {
 		t.Fatal(err.Error())
 	}
@@ -4,10 +4,12 @@ package persistentstore
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/storage"
 	sc "github.com/netapp/trident/storage_class"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 type InMemoryClient struct {
@@ -19,7 +21,18 @@ type InMemoryClient struct {
 	storageClassesAdded int
 	volumeTxns          map[string]*VolumeTransaction
 	volumeTxnsAdded     int
+	journalEntries      map[string]*drivers.JournalEntry
+	schedules           map[string]*storage.SnapshotSchedule
+	volumeGroups        map[string]*storage.VolumeGroup
+	volumeNames         map[string]*storage.VolumeNameMapping
+	usageRecords        map[string]*storage.UsageRecord
 	version             *PersistentStateVersion
+
+	// The lease is read and swapped by the goroutine renewing it as well as by its holder, so it
+	// is guarded by the mutex
+	lease         *Lease
+	leaseRevision int64
+	mutex         *sync.Mutex
 }
 
 func NewInMemoryClient() *InMemoryClient {
@@ -28,9 +41,15 @@ func NewInMemoryClient() *InMemoryClient {
 		volumes:        make(map[string]*storage.VolumeExternal),
 		storageClasses: make(map[string]*sc.Persistent),
 		volumeTxns:     make(map[string]*VolumeTransaction),
+		journalEntries: make(map[string]*drivers.JournalEntry),
+		schedules:      make(map[string]*storage.SnapshotSchedule),
+		volumeGroups:   make(map[string]*storage.VolumeGroup),
+		volumeNames:    make(map[string]*storage.VolumeNameMapping),
+		usageRecords:   make(map[string]*storage.UsageRecord),
 		version: &PersistentStateVersion{
 			"memory", config.OrchestratorAPIVersion,
 		},
+		mutex: &sync.Mutex{},
 	}
 }
 
@@ -58,6 +77,42 @@ func (c *InMemoryClient) SetVersion(version *PersistentStateVersion) error {
 	return nil
 }
 
+func (c *InMemoryClient) GetLease() (*Lease, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lease == nil {
+		return nil, NewPersistentStoreError(KeyNotFoundErr, "Lease")
+	}
+	lease := *c.lease
+	return &lease, nil
+}
+
+func (c *InMemoryClient) SwapLease(previous, lease *Lease) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if (previous == nil) != (c.lease == nil) || (previous != nil && previous.Revision != c.lease.Revision) {
+		return NewPersistentStoreError(KeyChangedErr, "Lease")
+	}
+	c.leaseRevision++
+	lease.Revision = c.leaseRevision
+	stored := *lease
+	c.lease = &stored
+	return nil
+}
+
+func (c *InMemoryClient) DeleteLease(lease *Lease) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lease == nil {
+		return NewPersistentStoreError(KeyNotFoundErr, "Lease")
+	}
+	if lease.Revision != c.lease.Revision {
+		return NewPersistentStoreError(KeyChangedErr, "Lease")
+	}
+	c.lease = nil
+	return nil
+}
+
 func (c *InMemoryClient) AddBackend(b *storage.Backend) error {
 	backend := b.ConstructPersistent()
 	if _, ok := c.backends[backend.Name]; ok {
@@ -214,6 +269,130 @@ func (c *InMemoryClient) DeleteVolumeTransaction(volTxn *VolumeTransaction) erro
 	return nil
 }
 
+func (c *InMemoryClient) AddJournalEntry(entry *drivers.JournalEntry) error {
+	// Like volume transactions, journal entries overwrite existing keys
+	entryCopy := *entry
+	entryCopy.Steps = append([]string{}, entry.Steps...)
+	c.journalEntries[entry.Key()] = &entryCopy
+	return nil
+}
+
+func (c *InMemoryClient) GetJournalEntries() ([]*drivers.JournalEntry, error) {
+	ret := make([]*drivers.JournalEntry, 0, len(c.journalEntries))
+	for _, entry := range c.journalEntries {
+		ret = append(ret, entry)
+	}
+	return ret, nil
+}
+
+func (c *InMemoryClient) DeleteJournalEntry(entry *drivers.JournalEntry) error {
+	if _, ok := c.journalEntries[entry.Key()]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, "JournalEntries")
+	}
+	delete(c.journalEntries, entry.Key())
+	return nil
+}
+
+func (c *InMemoryClient) AddSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	// Schedules are saved again each time they run, so they overwrite existing keys.  Save a copy,
+	// as the orchestrator goes on to change the schedule's state.
+	c.schedules[schedule.Config.Name] = &storage.SnapshotSchedule{
+		Config: schedule.Config,
+		State:  schedule.ConstructExternal().State,
+	}
+	return nil
+}
+
+func (c *InMemoryClient) GetSnapshotSchedules() ([]*storage.SnapshotSchedule, error) {
+	ret := make([]*storage.SnapshotSchedule, 0, len(c.schedules))
+	for _, schedule := range c.schedules {
+		ret = append(ret, schedule)
+	}
+	return ret, nil
+}
+
+func (c *InMemoryClient) DeleteSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	if _, ok := c.schedules[schedule.Config.Name]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, "SnapshotSchedules")
+	}
+	delete(c.schedules, schedule.Config.Name)
+	return nil
+}
+
+func (c *InMemoryClient) AddVolumeGroup(group *storage.VolumeGroup) error {
+	// Groups are saved again as their volumes change, so save a copy of the volume list
+	c.volumeGroups[group.Name] = &storage.VolumeGroup{
+		Name:    group.Name,
+		Volumes: append([]string{}, group.Volumes...),
+	}
+	return nil
+}
+
+func (c *InMemoryClient) GetVolumeGroups() ([]*storage.VolumeGroup, error) {
+	ret := make([]*storage.VolumeGroup, 0, len(c.volumeGroups))
+	for _, group := range c.volumeGroups {
+		ret = append(ret, &storage.VolumeGroup{
+			Name:    group.Name,
+			Volumes: append([]string{}, group.Volumes...),
+		})
+	}
+	return ret, nil
+}
+
+func (c *InMemoryClient) DeleteVolumeGroup(group *storage.VolumeGroup) error {
+	if _, ok := c.volumeGroups[group.Name]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, "VolumeGroups")
+	}
+	delete(c.volumeGroups, group.Name)
+	return nil
+}
+
+func (c *InMemoryClient) AddVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	stored := *mapping
+	c.volumeNames[mapping.Key()] = &stored
+	return nil
+}
+
+func (c *InMemoryClient) GetVolumeNameMappings() ([]*storage.VolumeNameMapping, error) {
+	ret := make([]*storage.VolumeNameMapping, 0, len(c.volumeNames))
+	for _, mapping := range c.volumeNames {
+		stored := *mapping
+		ret = append(ret, &stored)
+	}
+	return ret, nil
+}
+
+func (c *InMemoryClient) DeleteVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	if _, ok := c.volumeNames[mapping.Key()]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, "VolumeNames")
+	}
+	delete(c.volumeNames, mapping.Key())
+	return nil
+}
+
+func (c *InMemoryClient) AddUsageRecord(record *storage.UsageRecord) error {
+	stored := *record
+	c.usageRecords[record.Key()] = &stored
+	return nil
+}
+
+func (c *InMemoryClient) GetUsageRecords() ([]*storage.UsageRecord, error) {
+	ret := make([]*storage.UsageRecord, 0, len(c.usageRecords))
+	for _, record := range c.usageRecords {
+		stored := *record
+		ret = append(ret, &stored)
+	}
+	return ret, nil
+}
+
+func (c *InMemoryClient) DeleteUsageRecord(record *storage.UsageRecord) error {
+	if _, ok := c.usageRecords[record.Key()]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, "UsageRecords")
+	}
+	delete(c.usageRecords, record.Key())
+	return nil
+}
+
 func (c *InMemoryClient) AddStorageClass(s *sc.StorageClass) error {
 	storageClass := s.ConstructPersistent()
 	if _, ok := c.storageClasses[storageClass.GetName()]; ok {
@@ -224,6 +403,14 @@ func (c *InMemoryClient) AddStorageClass(s *sc.StorageClass) error {
 	return nil
 }
 
+func (c *InMemoryClient) UpdateStorageClass(s *sc.StorageClass) error {
+	if _, ok := c.storageClasses[s.GetName()]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, s.GetName())
+	}
+	c.storageClasses[s.GetName()] = s.ConstructPersistent()
+	return nil
+}
+
 func (c *InMemoryClient) GetStorageClass(scName string) (
 	*sc.Persistent, error,
 ) {
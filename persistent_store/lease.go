@@ -0,0 +1,25 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import "time"
+
+// Lease records which Trident instance manages the state in a persistent store.  Its owner renews
+// it periodically, so a lease that hasn't been renewed within its duration has been abandoned.
+type Lease struct {
+	Owner    string        `json:"owner"`
+	Host     string        `json:"host"`
+	Version  string        `json:"version"`
+	Acquired time.Time     `json:"acquired"`
+	Renewed  time.Time     `json:"renewed"`
+	Duration time.Duration `json:"duration"`
+
+	// Revision is the store's revision of the lease when it was read or written, which must be
+	// unchanged for the lease to be replaced or deleted.
+	Revision int64 `json:"-"`
+}
+
+// Expired returns true if the lease was last renewed longer ago than its duration.
+func (l *Lease) Expired(now time.Time) bool {
+	return now.After(l.Renewed.Add(l.Duration))
+}
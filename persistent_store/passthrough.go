@@ -9,8 +9,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ghodss/yaml"
 	log "github.com/sirupsen/logrus"
@@ -158,6 +160,10 @@ func (c *PassthroughClient) unmarshalConfig(fileContents []byte) (string, error)
 		configType = "solidfire_config"
 	case drivers.EseriesIscsiStorageDriverName:
 		configType = "eseries_config"
+	case drivers.GCPNFSStorageDriverName:
+		configType = "gcp_config"
+	case drivers.GenericNFSStorageDriverName:
+		configType = "nfs_config"
 	case drivers.FakeStorageDriverName:
 		configType = "fake_config"
 	default:
@@ -199,6 +205,21 @@ func (c *PassthroughClient) SetVersion(version *PersistentStateVersion) error {
 	return nil
 }
 
+// GetLease returns no lease, as each passthrough store is read from a configuration file of its
+// own.  Nothing is shared through which instances using the same storage could find one another,
+// so a passthrough store doesn't keep them from managing the same volumes.
+func (c *PassthroughClient) GetLease() (*Lease, error) {
+	return nil, NewPersistentStoreError(KeyNotFoundErr, "Lease")
+}
+
+func (c *PassthroughClient) SwapLease(previous, lease *Lease) error {
+	return nil
+}
+
+func (c *PassthroughClient) DeleteLease(lease *Lease) error {
+	return nil
+}
+
 func (c *PassthroughClient) AddBackend(backend *storage.Backend) error {
 
 	// The passthrough store persists backends for the purpose of contacting
@@ -282,11 +303,21 @@ func (c *PassthroughClient) DeleteVolumeIgnoreNotFound(vol *storage.Volume) erro
 	return nil
 }
 
+// passthroughListAttempts is how many times a backend's volumes are listed before the passthrough
+// store gives up on it, and passthroughListRetryDelay how long it waits between attempts, so that a
+// controller that is briefly busy while Trident starts doesn't leave its volumes unknown.
+var (
+	passthroughListAttempts   = 3
+	passthroughListRetryDelay = 5 * time.Second
+)
+
 // GetVolumes gets up-to-date volume info from each storage backend.  To increase
 // efficiency, it contacts each backend in a separate goroutine.  Because multiple
 // backends may be managed by the orchestrator, the passthrough layer should remain
 // as responsive as possible even if a backend is unavailable or returns an error
-// during volume discovery.
+// during volume discovery.  The volumes found are what the orchestrator knows of
+// after a restart, so clones are linked to their source volumes where the storage
+// records them, and a name found on more than one backend is only used once.
 func (c *PassthroughClient) GetVolumes() ([]*storage.VolumeExternal, error) {
 
 	volumeChannel := make(chan *storage.VolumeExternalWrapper)
@@ -315,27 +346,97 @@ func (c *PassthroughClient) GetVolumes() ([]*storage.VolumeExternal, error) {
 		}
 	}
 
-	return volumes, nil
+	// Order the volumes so that any duplicates are resolved the same way on every start
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Backend != volumes[j].Backend {
+			return volumes[i].Backend < volumes[j].Backend
+		}
+		return volumes[i].Config.InternalName < volumes[j].Config.InternalName
+	})
+
+	unique := make([]*storage.VolumeExternal, 0, len(volumes))
+	byName := make(map[string]*storage.VolumeExternal)
+	byInternalName := make(map[string]*storage.VolumeExternal)
+	for _, volume := range volumes {
+		if existing, ok := byName[volume.Config.Name]; ok {
+			log.WithFields(log.Fields{
+				"volume":       volume.Config.Name,
+				"backend":      volume.Backend,
+				"usedBackend":  existing.Backend,
+				"internalName": volume.Config.InternalName,
+			}).Error("Volume was found on more than one backend; ignoring all but the first.")
+			continue
+		}
+		byName[volume.Config.Name] = volume
+		byInternalName[volume.Backend+"/"+volume.Config.InternalName] = volume
+		unique = append(unique, volume)
+	}
+
+	// A clone's source is only known to the storage by its internal name
+	for _, volume := range unique {
+		sourceInternalName := volume.Config.CloneSourceVolumeInternal
+		if sourceInternalName == "" || volume.Config.CloneSourceVolume != "" {
+			continue
+		}
+		if source, ok := byInternalName[volume.Backend+"/"+sourceInternalName]; ok {
+			volume.Config.CloneSourceVolume = source.Config.Name
+		}
+	}
+
+	return unique, nil
 }
 
 // getVolumesFromBackend reads all of the volumes managed by a single backend.
 // This method is designed to run in a goroutine, so it passes its results back
-// via a channel that is shared by all such goroutines.
+// via a channel that is shared by all such goroutines.  If the backend can't be
+// listed, it is tried again, and only the volumes of a complete listing are sent
+// unless every attempt fails.  Drivers configured to normalize the volumes they
+// find do so before the volumes are sent.
 func (c *PassthroughClient) getVolumesFromBackend(
 	backend *storage.Backend, volumeChannel chan *storage.VolumeExternalWrapper,
 	waitGroup *sync.WaitGroup,
 ) {
 	defer waitGroup.Done()
 
-	// Create a channel that each backend can use, then copy values from
-	// there to the common channel until the backend channel is closed.
-	backendChannel := make(chan *storage.VolumeExternalWrapper)
-	go backend.Driver.GetVolumeExternalWrappers(backendChannel)
-	for volume := range backendChannel {
-		if volume.Volume != nil {
-			volume.Volume.Backend = backend.Name
+	var wrappers []*storage.VolumeExternalWrapper
+	for attempt := 1; attempt <= passthroughListAttempts; attempt++ {
+
+		// Create a channel that each backend can use, then copy values from
+		// there until the backend channel is closed.
+		wrappers = make([]*storage.VolumeExternalWrapper, 0)
+		failed := false
+		backendChannel := make(chan *storage.VolumeExternalWrapper)
+		go backend.GetVolumeExternalWrappers(backendChannel)
+		for wrapper := range backendChannel {
+			if wrapper.Error != nil {
+				failed = true
+			}
+			wrappers = append(wrappers, wrapper)
+		}
+		if !failed {
+			break
+		}
+
+		logFields := log.Fields{"backend": backend.Name, "attempt": attempt}
+		if attempt < passthroughListAttempts {
+			log.WithFields(logFields).Warn("Could not list the backend's volumes; retrying.")
+			time.Sleep(passthroughListRetryDelay)
+		} else {
+			log.WithFields(logFields).Error("Could not list the backend's volumes; some may be unknown.")
+		}
+	}
+
+	_, isNormalizing := backend.Driver.(storage.NormalizingDriver)
+	for _, wrapper := range wrappers {
+		if isNormalizing && wrapper.Error == nil {
+			if err := backend.Guarded().NormalizeVolume(wrapper.Volume); err != nil {
+				log.WithFields(log.Fields{
+					"backend": backend.Name,
+					"volume":  wrapper.Volume.Config.InternalName,
+				}).Warnf("Could not normalize the volume to the backend's defaults. %v", err)
+			}
 		}
-		volumeChannel <- volume
+		volumeChannel <- wrapper
 	}
 }
 
@@ -359,10 +460,76 @@ func (c *PassthroughClient) DeleteVolumeTransaction(volTxn *VolumeTransaction) e
 	return nil
 }
 
+func (c *PassthroughClient) AddJournalEntry(entry *drivers.JournalEntry) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetJournalEntries() ([]*drivers.JournalEntry, error) {
+	return make([]*drivers.JournalEntry, 0), nil
+}
+
+func (c *PassthroughClient) DeleteJournalEntry(entry *drivers.JournalEntry) error {
+	return nil
+}
+
+func (c *PassthroughClient) AddSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	return nil
+}
+
+func (c *PassthroughClient) AddVolumeGroup(group *storage.VolumeGroup) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetVolumeGroups() ([]*storage.VolumeGroup, error) {
+	return make([]*storage.VolumeGroup, 0), nil
+}
+
+func (c *PassthroughClient) DeleteVolumeGroup(group *storage.VolumeGroup) error {
+	return nil
+}
+
+// AddVolumeNameMapping does nothing, as volume names remain reversible with a passthrough store
+func (c *PassthroughClient) AddVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetVolumeNameMappings() ([]*storage.VolumeNameMapping, error) {
+	return make([]*storage.VolumeNameMapping, 0), nil
+}
+
+func (c *PassthroughClient) DeleteVolumeNameMapping(mapping *storage.VolumeNameMapping) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetSnapshotSchedules() ([]*storage.SnapshotSchedule, error) {
+	return make([]*storage.SnapshotSchedule, 0), nil
+}
+
+func (c *PassthroughClient) DeleteSnapshotSchedule(schedule *storage.SnapshotSchedule) error {
+	return nil
+}
+
+// AddUsageRecord does nothing, as a passthrough store keeps no history
+func (c *PassthroughClient) AddUsageRecord(record *storage.UsageRecord) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetUsageRecords() ([]*storage.UsageRecord, error) {
+	return make([]*storage.UsageRecord, 0), nil
+}
+
+func (c *PassthroughClient) DeleteUsageRecord(record *storage.UsageRecord) error {
+	return nil
+}
+
 func (c *PassthroughClient) AddStorageClass(sc *sc.StorageClass) error {
 	return nil
 }
 
+func (c *PassthroughClient) UpdateStorageClass(sc *sc.StorageClass) error {
+	return nil
+}
+
 func (c *PassthroughClient) GetStorageClass(scName string) (*sc.Persistent, error) {
 	return nil, NewPersistentStoreError(KeyNotFoundErr, scName)
 }
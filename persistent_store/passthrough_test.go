@@ -3,6 +3,7 @@
 package persistentstore
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -73,7 +74,7 @@ func getFakeVolumeTransaction() *VolumeTransaction {
 		InternalName: "really_fake_volume",
 	}
 
-	return &VolumeTransaction{volumeConfig, AddVolume}
+	return &VolumeTransaction{Config: volumeConfig, Op: AddVolume}
 }
 
 func getFakeStorageClass() *sc.StorageClass {
@@ -524,8 +525,8 @@ func TestPassthroughClient_GetVolumes(t *testing.T) {
 	p := newPassthroughClient()
 	fakeBackend := getFakeBackend()
 	createOpts := map[string]string{"pool": "pool-0"}
-	fakeBackend.Driver.Create("fake_volume_1", 1000000000, createOpts)
-	fakeBackend.Driver.Create("fake_volume_2", 2000000000, createOpts)
+	fakeBackend.Driver.Create(context.Background(), "fake_volume_1", 1000000000, createOpts)
+	fakeBackend.Driver.Create(context.Background(), "fake_volume_2", 2000000000, createOpts)
 	p.AddBackend(fakeBackend)
 
 	result, err := p.GetVolumes()
@@ -548,6 +549,43 @@ func TestPassthroughClient_GetVolumes(t *testing.T) {
 	}
 }
 
+func TestPassthroughClient_GetVolumesClonesAndDuplicates(t *testing.T) {
+	p := newPassthroughClient()
+	createOpts := map[string]string{"pool": "pool-0"}
+
+	fakeBackend := getFakeBackend()
+	fakeBackend.Driver.Create(context.Background(), "fake_volume_1", 1000000000, createOpts)
+	fakeBackend.Driver.CreateClone(context.Background(), "fake_clone_1", "fake_volume_1", "snap1", nil)
+	p.AddBackend(fakeBackend)
+
+	otherBackend := getFakeBackend()
+	otherBackend.Name = "other_backend"
+	otherBackend.Driver.Create(context.Background(), "fake_volume_1", 1000000000, createOpts)
+	p.AddBackend(otherBackend)
+
+	result, err := p.GetVolumes()
+
+	if err != nil {
+		t.Fatal("Could not get volumes from passthrough client!")
+	}
+	volMap := make(map[string]*storage.VolumeExternal)
+	for _, vol := range result {
+		volMap[vol.Config.Name] = vol
+	}
+	if len(result) != 2 || len(volMap) != 2 {
+		t.Fatalf("Expected one volume and its clone, got %d volumes", len(result))
+	}
+	if volMap["fake_volume_1"].Backend != fakeBackend.Name {
+		t.Errorf("Expected the duplicate volume to be used from %s, got %s", fakeBackend.Name,
+			volMap["fake_volume_1"].Backend)
+	}
+	clone := volMap["fake_clone_1"]
+	if clone.Config.CloneSourceVolume != "fake_volume_1" || clone.Config.CloneSourceSnapshot != "snap1" {
+		t.Errorf("Expected the clone to be linked to fake_volume_1@snap1, got %s@%s",
+			clone.Config.CloneSourceVolume, clone.Config.CloneSourceSnapshot)
+	}
+}
+
 func TestPassthroughClient_GetVolumesNonexistent(t *testing.T) {
 	p := newPassthroughClient()
 	fakeBackend := getFakeBackend()
@@ -0,0 +1,249 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+)
+
+// SchemaVersion is the version of the structure of everything Trident stores, which is raised
+// whenever the schema version of any kind of record is.  A store with a later version was written
+// by a later version of Trident, which this one can't safely use.
+const SchemaVersion = 1
+
+// schemaMigration upgrades a stored record, decoded as JSON, by one schema version.
+type schemaMigration func(m *SchemaMigrator, record map[string]interface{}) error
+
+// backendMigrations upgrade stored backends; the migration at index i upgrades version i to i+1.
+var backendMigrations = []schemaMigration{
+	// Version 1 gives every backend a UUID, by which its volumes refer to it
+	func(m *SchemaMigrator, record map[string]interface{}) error {
+		if backendUUID, _ := record["backendUUID"].(string); backendUUID == "" {
+			record["backendUUID"] = uuid.New()
+		}
+		return nil
+	},
+}
+
+// volumeMigrations upgrade stored volumes; the migration at index i upgrades version i to i+1.
+var volumeMigrations = []schemaMigration{
+	// Version 1 refers to each volume's backend by UUID, so that renaming the backend keeps it
+	func(m *SchemaMigrator, record map[string]interface{}) error {
+		if backendUUID, _ := record["backendUUID"].(string); backendUUID == "" {
+			backendName, _ := record["backend"].(string)
+			if backendUUID, ok := m.backendUUIDs[backendName]; ok {
+				record["backendUUID"] = backendUUID
+			}
+		}
+		return nil
+	},
+}
+
+// storeSchema records the schema version of a store.
+type storeSchema struct {
+	Version int `json:"version"`
+}
+
+// schemaRecord is a stored record being upgraded.
+type schemaRecord struct {
+	key      string
+	value    string
+	version  int
+	outdated bool
+	fields   map[string]interface{}
+}
+
+// SchemaMigrator upgrades the records stored by earlier versions of Trident to the current schema
+// when Trident starts.  Each record carries its own schema version, so a migration that is
+// interrupted resumes where it stopped, and every record is backed up before any is changed.
+type SchemaMigrator struct {
+	client       EtcdClient
+	backendUUIDs map[string]string
+}
+
+func NewSchemaMigrator(client EtcdClient) *SchemaMigrator {
+	return &SchemaMigrator{
+		client:       client,
+		backendUUIDs: make(map[string]string),
+	}
+}
+
+// Run upgrades every backend and volume in the store whose schema version is earlier than the
+// current one, and then records that the store has the current version.  A store with a later
+// version than this one is refused.
+func (m *SchemaMigrator) Run() error {
+
+	version, err := m.getSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("the persistent store has schema version %d, but this version of Trident "+
+			"only supports up to %d; upgrade Trident or restore the store from a backup",
+			version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+
+	backends, err := m.readRecords(config.BackendURL, storage.BackendSchemaVersion)
+	if err != nil {
+		return err
+	}
+	volumes, err := m.readRecords(config.VolumeURL, storage.VolumeSchemaVersion)
+	if err != nil {
+		return err
+	}
+
+	outdated := make([]*schemaRecord, 0)
+	for _, record := range append(backends, volumes...) {
+		if record.outdated {
+			outdated = append(outdated, record)
+		}
+	}
+	log.WithFields(log.Fields{
+		"currentVersion": version,
+		"desiredVersion": SchemaVersion,
+		"records":        len(outdated),
+	}).Info("Upgrading the persistent store schema.")
+
+	if len(outdated) > 0 {
+		backupPrefix := fmt.Sprintf("%s/schema-v%d-%s", config.BackupURL, version,
+			time.Now().UTC().Format("20060102T150405Z"))
+		for _, record := range outdated {
+			if err = m.client.Set(backupPrefix+record.key, record.value); err != nil {
+				return fmt.Errorf("could not back up %s: %v", record.key, err)
+			}
+		}
+		log.WithField("backup", backupPrefix).Info("Backed up the records to be upgraded.")
+	}
+
+	// Volumes refer to their backends by UUID, which the backends may only now have been given
+	if err = m.migrateRecords(backends, storage.BackendSchemaVersion, backendMigrations); err != nil {
+		return err
+	}
+	for _, record := range backends {
+		name, _ := record.fields["name"].(string)
+		m.backendUUIDs[name], _ = record.fields["backendUUID"].(string)
+	}
+	if err = m.migrateRecords(volumes, storage.VolumeSchemaVersion, volumeMigrations); err != nil {
+		return err
+	}
+
+	schemaJSON, err := json.Marshal(&storeSchema{Version: SchemaVersion})
+	if err != nil {
+		return err
+	}
+	if err = m.client.Set(config.SchemaURL, string(schemaJSON)); err != nil {
+		return fmt.Errorf("could not record the persistent store schema version: %v", err)
+	}
+	log.WithField("version", SchemaVersion).Info("Upgraded the persistent store schema.")
+	return nil
+}
+
+// getSchemaVersion returns the schema version of the store, which is 0 if it was last used by a
+// version of Trident from before schemas were versioned.
+func (m *SchemaMigrator) getSchemaVersion() (int, error) {
+	schemaJSON, err := m.client.Read(config.SchemaURL)
+	if MatchKeyNotFoundErr(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("could not read the persistent store schema version: %v", err)
+	}
+	schema := &storeSchema{}
+	if err = json.Unmarshal([]byte(schemaJSON), schema); err != nil {
+		return 0, fmt.Errorf("could not parse the persistent store schema version: %v", err)
+	}
+	return schema.Version, nil
+}
+
+// readRecords reads every record under a key prefix along with its schema version, which is 0 for
+// records stored before they were versioned.  Records from a later version are refused.
+func (m *SchemaMigrator) readRecords(keyPrefix string, currentVersion int) ([]*schemaRecord, error) {
+
+	keys, err := m.client.ReadKeys(keyPrefix)
+	if MatchKeyNotFoundErr(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	records := make([]*schemaRecord, 0, len(keys))
+	for _, key := range keys {
+		// Other kinds of records, such as volume groups, may share the prefix
+		if !strings.HasPrefix(key, keyPrefix+"/") {
+			continue
+		}
+		value, err := m.client.Read(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Numbers are kept as they were written, rather than as floating point
+		record := &schemaRecord{key: key, value: value}
+		decoder := json.NewDecoder(bytes.NewBufferString(value))
+		decoder.UseNumber()
+		if err = decoder.Decode(&record.fields); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", key, err)
+		}
+		if version, ok := record.fields["schemaVersion"].(json.Number); ok {
+			v, err := version.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("could not parse the schema version of %s: %v", key, err)
+			}
+			record.version = int(v)
+		}
+		if record.version > currentVersion {
+			return nil, fmt.Errorf("%s has schema version %d, but this version of Trident only "+
+				"supports up to %d", key, record.version, currentVersion)
+		}
+		record.outdated = record.version < currentVersion
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// migrateRecords applies, in order, the migrations each record hasn't had, and saves it.
+func (m *SchemaMigrator) migrateRecords(
+	records []*schemaRecord, currentVersion int, migrations []schemaMigration,
+) error {
+	if len(migrations) != currentVersion {
+		return fmt.Errorf("schema version %d has %d migrations", currentVersion, len(migrations))
+	}
+	for _, record := range records {
+		if !record.outdated {
+			continue
+		}
+		for _, migrate := range migrations[record.version:] {
+			if err := migrate(m, record.fields); err != nil {
+				return fmt.Errorf("could not upgrade %s from schema version %d: %v", record.key,
+					record.version, err)
+			}
+		}
+		record.fields["schemaVersion"] = currentVersion
+
+		value, err := json.Marshal(record.fields)
+		if err != nil {
+			return err
+		}
+		if err = m.client.Set(record.key, string(value)); err != nil {
+			return fmt.Errorf("could not save %s: %v", record.key, err)
+		}
+		log.WithFields(log.Fields{
+			"key":         record.key,
+			"fromVersion": record.version,
+			"toVersion":   currentVersion,
+		}).Debug("Upgraded stored record.")
+	}
+	return nil
+}
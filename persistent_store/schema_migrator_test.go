@@ -0,0 +1,158 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/netapp/trident/config"
+)
+
+// keyValueClient is an etcd client that keeps its keys in memory.
+type keyValueClient struct {
+	*InMemoryClient
+	keys map[string]string
+}
+
+func newKeyValueClient() *keyValueClient {
+	return &keyValueClient{InMemoryClient: NewInMemoryClient(), keys: make(map[string]string)}
+}
+
+func (c *keyValueClient) Create(key, value string) error {
+	if _, ok := c.keys[key]; ok {
+		return NewPersistentStoreError(KeyExistsErr, key)
+	}
+	c.keys[key] = value
+	return nil
+}
+
+func (c *keyValueClient) Read(key string) (string, error) {
+	if value, ok := c.keys[key]; ok {
+		return value, nil
+	}
+	return "", NewPersistentStoreError(KeyNotFoundErr, key)
+}
+
+func (c *keyValueClient) ReadKeys(keyPrefix string) ([]string, error) {
+	keys := make([]string, 0)
+	for key := range c.keys {
+		if strings.HasPrefix(key, keyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return keys, NewPersistentStoreError(KeyNotFoundErr, keyPrefix)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (c *keyValueClient) Update(key, value string) error {
+	if _, ok := c.keys[key]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, key)
+	}
+	c.keys[key] = value
+	return nil
+}
+
+func (c *keyValueClient) Set(key, value string) error {
+	c.keys[key] = value
+	return nil
+}
+
+func (c *keyValueClient) Delete(key string) error {
+	if _, ok := c.keys[key]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, key)
+	}
+	delete(c.keys, key)
+	return nil
+}
+
+func (c *keyValueClient) DeleteKeys(keyPrefix string) error {
+	keys, err := c.ReadKeys(keyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(c.keys, key)
+	}
+	return nil
+}
+
+func readRecord(t *testing.T, client *keyValueClient, key string) map[string]interface{} {
+	record := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(client.keys[key]), &record); err != nil {
+		t.Fatalf("Unable to parse %s: %v", key, err)
+	}
+	return record
+}
+
+func TestSchemaMigrator(t *testing.T) {
+	client := newKeyValueClient()
+	group := `{"name":"group","volumes":["vol1"]}`
+	client.keys = map[string]string{
+		config.BackendURL + "/backend1": `{"version":"1","config":{},"name":"backend1","online":true}`,
+		config.BackendURL + "/backend2": `{"version":"1","config":{},"name":"backend2","online":true,` +
+			`"backendUUID":"uuid2"}`,
+		config.VolumeURL + "/vol1": `{"Config":{"name":"vol1","size":"1073741824"},"backend":"backend1"}`,
+		config.VolumeURL + "/vol2": `{"Config":{"name":"vol2","snapshotReserve":12345678901234567890},` +
+			`"backend":"backend2"}`,
+		config.VolumeGroupURL + "/group": group,
+	}
+
+	if err := NewSchemaMigrator(client).Run(); err != nil {
+		t.Fatal("Unable to upgrade the schema: ", err)
+	}
+
+	backend1 := readRecord(t, client, config.BackendURL+"/backend1")
+	if backend1["backendUUID"] == "" || backend1["schemaVersion"] != float64(1) {
+		t.Errorf("Expected the backend to be given a UUID, got %v.", backend1)
+	}
+	backend2 := readRecord(t, client, config.BackendURL+"/backend2")
+	if backend2["backendUUID"] != "uuid2" {
+		t.Errorf("Expected the backend to keep its UUID, got %v.", backend2)
+	}
+	vol1 := readRecord(t, client, config.VolumeURL+"/vol1")
+	if vol1["backendUUID"] != backend1["backendUUID"] || vol1["schemaVersion"] != float64(1) {
+		t.Errorf("Expected the volume to refer to its backend's new UUID, got %v.", vol1)
+	}
+	if vol2 := readRecord(t, client, config.VolumeURL+"/vol2"); vol2["backendUUID"] != "uuid2" {
+		t.Errorf("Expected the volume to refer to its backend's UUID, got %v.", vol2)
+	}
+	if !strings.Contains(client.keys[config.VolumeURL+"/vol2"], "12345678901234567890") {
+		t.Errorf("Expected numbers to be kept as they were, got %s.", client.keys[config.VolumeURL+"/vol2"])
+	}
+	if client.keys[config.VolumeGroupURL+"/group"] != group {
+		t.Errorf("Expected other records to be left alone, got %s.", client.keys[config.VolumeGroupURL+"/group"])
+	}
+
+	backups, _ := client.ReadKeys(config.BackupURL)
+	if len(backups) != 4 {
+		t.Errorf("Expected 4 records to be backed up, got %v.", backups)
+	}
+	for _, backup := range backups {
+		if !strings.HasSuffix(backup, config.BackendURL+"/backend1") {
+			continue
+		}
+		if client.keys[backup] != `{"version":"1","config":{},"name":"backend1","online":true}` {
+			t.Errorf("Expected the backup to be the record as it was, got %s.", client.keys[backup])
+		}
+	}
+
+	// An upgraded store isn't upgraded again
+	if err := NewSchemaMigrator(client).Run(); err != nil {
+		t.Fatal("Unable to check the upgraded schema: ", err)
+	}
+	if backups, _ = client.ReadKeys(config.BackupURL); len(backups) != 4 {
+		t.Errorf("Expected no more backups, got %v.", backups)
+	}
+
+	// A store from a later version of Trident is refused
+	client.keys[config.SchemaURL] = `{"version":2}`
+	if err := NewSchemaMigrator(client).Run(); err == nil {
+		t.Error("Expected a store with a later schema to be refused.")
+	}
+}
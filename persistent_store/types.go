@@ -7,6 +7,7 @@ import (
 
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage_class"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 type StoreType string
@@ -35,6 +36,10 @@ type Client interface {
 	GetType() StoreType
 	Stop() error
 
+	GetLease() (*Lease, error)
+	SwapLease(previous, lease *Lease) error
+	DeleteLease(lease *Lease) error
+
 	AddBackend(b *storage.Backend) error
 	GetBackend(backendName string) (*storage.BackendPersistent, error)
 	UpdateBackend(b *storage.Backend) error
@@ -56,7 +61,28 @@ type Client interface {
 		error)
 	DeleteVolumeTransaction(volTxn *VolumeTransaction) error
 
+	AddJournalEntry(entry *drivers.JournalEntry) error
+	GetJournalEntries() ([]*drivers.JournalEntry, error)
+	DeleteJournalEntry(entry *drivers.JournalEntry) error
+
+	AddSnapshotSchedule(schedule *storage.SnapshotSchedule) error
+	GetSnapshotSchedules() ([]*storage.SnapshotSchedule, error)
+	DeleteSnapshotSchedule(schedule *storage.SnapshotSchedule) error
+
+	AddVolumeGroup(group *storage.VolumeGroup) error
+	GetVolumeGroups() ([]*storage.VolumeGroup, error)
+	DeleteVolumeGroup(group *storage.VolumeGroup) error
+
+	AddVolumeNameMapping(mapping *storage.VolumeNameMapping) error
+	GetVolumeNameMappings() ([]*storage.VolumeNameMapping, error)
+	DeleteVolumeNameMapping(mapping *storage.VolumeNameMapping) error
+
+	AddUsageRecord(record *storage.UsageRecord) error
+	GetUsageRecords() ([]*storage.UsageRecord, error)
+	DeleteUsageRecord(record *storage.UsageRecord) error
+
 	AddStorageClass(sc *storageclass.StorageClass) error
+	UpdateStorageClass(sc *storageclass.StorageClass) error
 	GetStorageClass(scName string) (*storageclass.Persistent, error)
 	GetStorageClasses() ([]*storageclass.Persistent, error)
 	DeleteStorageClass(sc *storageclass.StorageClass) error
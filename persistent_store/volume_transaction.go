@@ -13,11 +13,26 @@ type VolumeOperation string
 const (
 	AddVolume    VolumeOperation = "addVolume"
 	DeleteVolume VolumeOperation = "deleteVolume"
+	// MigrateVolume transactions last from when a volume's migration starts until it completes
+	// or is abandoned, so that a restart can clean up whichever copy of the volume is left over.
+	MigrateVolume VolumeOperation = "migrateVolume"
+	// RehostVolume transactions last from when a volume's storage starts moving to another backend
+	// until the volume is bound to it, so that a restart can finish binding a volume already moved.
+	RehostVolume VolumeOperation = "rehostVolume"
 )
 
 type VolumeTransaction struct {
 	Config *storage.VolumeConfig
 	Op     VolumeOperation
+
+	// Migration is set for MigrateVolume transactions
+	Migration *storage.VolumeMigration `json:",omitempty"`
+
+	// RehostBackend is the backend a RehostVolume transaction moves the volume to
+	RehostBackend string `json:",omitempty"`
+
+	// StartTime is when the operation began, in RFC3339 format
+	StartTime string `json:",omitempty"`
 }
 
 // getKey returns a unique identifier for the VolumeTransaction.  Volume
@@ -3,6 +3,7 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,9 +26,9 @@ type Driver interface {
 	Initialized() bool
 	// Terminate tells the driver to clean up, as it won't be called again.
 	Terminate()
-	Create(name string, sizeBytes uint64, opts map[string]string) error
-	CreateClone(name, source, snapshot string, opts map[string]string) error
-	Destroy(name string) error
+	Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error
+	CreateClone(ctx context.Context, name, source, snapshot string, opts map[string]string) error
+	Destroy(ctx context.Context, name string) error
 	Attach(name, mountpoint string, opts map[string]string) error
 	Detach(name, mountpoint string) error
 	SnapshotList(name string) ([]Snapshot, error)
@@ -56,12 +57,193 @@ type Driver interface {
 	GetVolumeExternalWrappers(chan *VolumeExternalWrapper)
 }
 
+// VolumeSizeDriver is implemented by drivers that can say in advance what size of volume Create
+// will make, so that the size actually provisioned is recorded rather than the size requested.
+type VolumeSizeDriver interface {
+	// GetVolumeSize returns the size of the volume Create makes for a requested size, after the
+	// driver's default size and rounding are applied, or an error if that size isn't allowed.
+	GetVolumeSize(sizeBytes uint64) (uint64, error)
+}
+
+// JournalingDriver is implemented by drivers that journal their multi-step operations, so that an
+// operation interrupted by a restart may be completed or cleaned up afterwards.
+type JournalingDriver interface {
+	SetJournal(journal drivers.Journal)
+	// ReconcileJournalEntry completes or cleans up after the operation recorded in the entry.
+	ReconcileJournalEntry(entry *drivers.JournalEntry) error
+}
+
+// DebugTraceDriver is implemented by drivers whose debug trace flags may be changed while they
+// are running.
+type DebugTraceDriver interface {
+	GetDebugTraceFlags() map[string]bool
+	// SetDebugTraceFlags replaces the driver's trace flags.  Operations already in progress may
+	// still be reading the old flags, so the new map must not be modified afterwards.
+	SetDebugTraceFlags(flags map[string]bool)
+}
+
+// QoSDriver is implemented by drivers that can change the QoS of an existing volume.
+type QoSDriver interface {
+	// ModifyVolumeQoS applies the QoS named by the config's QoSType, or if that is empty, the
+	// IOPS in its QoS field, to an existing volume.
+	ModifyVolumeQoS(volConfig *VolumeConfig) error
+}
+
+// CapacityDriver is implemented by drivers that can report how much space their storage pools have.
+type CapacityDriver interface {
+	// GetPoolCapacity returns the space in a storage pool.  Pools that share space, such as those
+	// offering different QoS on the same cluster, each report the shared space.
+	GetPoolCapacity(pool *Pool) (*PoolCapacity, error)
+}
+
+// SnapshotDriver is implemented by drivers that can take and delete snapshots of their volumes,
+// so that Trident can take snapshots on a schedule.
+type SnapshotDriver interface {
+	// CreateSnapshot takes a snapshot of a volume, named by its internal name, and returns the
+	// snapshot as SnapshotList would report it.
+	CreateSnapshot(snapshotName, volumeName string) (*Snapshot, error)
+	DeleteSnapshot(snapshotName, volumeName string) error
+}
+
+// GroupSnapshotDriver is implemented by drivers that can snapshot several of their volumes at the
+// same point in time, so that volumes written together, such as a database's data and logs, remain
+// consistent with one another.
+type GroupSnapshotDriver interface {
+	SnapshotDriver
+	// CreateGroupSnapshot snapshots the volumes, named by their internal names, returning their
+	// snapshots in the same order.
+	CreateGroupSnapshot(snapshotName string, volumeNames []string) ([]*Snapshot, error)
+}
+
+// MigrationDriver is implemented by drivers that can replicate volumes from another backend's
+// storage into their own, such as with SnapMirror between ONTAP SVMs, so that volumes can be
+// migrated to them without copying the data through Trident's host.  Volumes are named by their
+// internal names.
+type MigrationDriver interface {
+	// CanReplicateFrom reports whether volumes on the source driver's storage can be replicated.
+	CanReplicateFrom(source Driver) bool
+	// CreateReplica creates a volume to receive the source volume's data and starts copying it.
+	CreateReplica(
+		ctx context.Context, name, sourceName string, source Driver, sizeBytes uint64, opts map[string]string,
+	) error
+	// UpdateReplica transfers any changes made to the source since the last transfer, returning
+	// once they have all been transferred.
+	UpdateReplica(ctx context.Context, name string) error
+	// PromoteReplica stops replication from the source and makes the replica writable.
+	PromoteReplica(ctx context.Context, name, sourceName string, source Driver) error
+	// DeleteReplica stops replication from the source and destroys the replica.
+	DeleteReplica(ctx context.Context, name, sourceName string, source Driver) error
+}
+
+// ReclaimDriver is implemented by drivers whose Destroy can leave a volume on the storage, rather
+// than deleting it, according to the on-delete behavior in its context.
+type ReclaimDriver interface {
+	// SupportsOnDelete reports whether Destroy carries out the on-delete behavior.
+	SupportsOnDelete(onDelete string) bool
+}
+
+// IdentityDriver is implemented by drivers that can identify the storage they manage, so that two
+// backends managing the same storage through different addresses can be recognized.
+type IdentityDriver interface {
+	// StorageIdentity returns a string that is the same for any two drivers that would manage
+	// the same volumes, such as one made from an ONTAP SVM's UUID, or empty if it isn't known.
+	StorageIdentity() string
+}
+
+// VolumeNamingDriver is implemented by drivers that can name a volume from more of its config
+// than its name, such as from a naming template that includes its Kubernetes namespace.
+type VolumeNamingDriver interface {
+	// GetInternalVolumeNameFromConfig returns the volume's name on the storage, or an error if no
+	// valid name can be made from its config.
+	GetInternalVolumeNameFromConfig(volConfig *VolumeConfig) (string, error)
+}
+
+// WarmPoolDriver is implemented by drivers that can rename and grow a volume that has never been
+// attached, so that volumes created ahead of requests can be handed out as the volumes requested.
+type WarmPoolDriver interface {
+	// RenameVolume renames a volume on the storage, updating anything else named after it, such
+	// as its NFS junction.
+	RenameVolume(ctx context.Context, name, newName string) error
+	// ResizeVolume grows a volume to the given size, which the driver has already rounded.
+	ResizeVolume(ctx context.Context, name string, sizeBytes uint64) error
+}
+
+// ReadOnlyDriver is implemented by drivers that can export or map volumes read-only at the
+// storage layer.
+type ReadOnlyDriver interface {
+	// SetVolumeReadOnly makes a volume read-only if its config says so, or writable otherwise,
+	// as a clone of a read-only volume may need to be.
+	SetVolumeReadOnly(volConfig *VolumeConfig) error
+}
+
+// SnapshotDirDriver is implemented by drivers that can show or hide the .snapshot directory of an
+// existing volume, so that changing a storage class's snapshotDirectory reaches its volumes.
+type SnapshotDirDriver interface {
+	// SetSnapshotDirVisible shows or hides the .snapshot directory of a volume, named by its
+	// internal name.
+	SetSnapshotDirVisible(name string, visible bool) error
+}
+
+// CacheDriver is implemented by drivers that can create caches of volumes on another backend's
+// storage, such as ONTAP FlexCache volumes of a volume on the same or a peered SVM, so that many
+// readers can be served close to them without loading the origin volume.  Volumes are named by
+// their internal names.
+type CacheDriver interface {
+	// CanCacheFrom reports whether volumes on the origin driver's storage can be cached.
+	CanCacheFrom(origin Driver) bool
+	// CreateCache creates a cache of the origin volume, waiting until it exists.
+	CreateCache(
+		ctx context.Context, name, originName string, origin Driver, sizeBytes uint64, opts map[string]string,
+	) error
+	// DestroyCache disconnects a cache from its origin and destroys it.
+	DestroyCache(ctx context.Context, name string) error
+}
+
+// RehostDriver is implemented by drivers that can take over a volume from another backend's
+// storage without copying its data, such as by rehosting an ONTAP FlexVol from another SVM of the
+// same cluster, so that volumes can follow tenants that are reorganized across SVMs.
+type RehostDriver interface {
+	// CanRehostFrom reports whether volumes on the source driver's storage can be rehosted.
+	CanRehostFrom(source Driver) bool
+	// RehostVolume moves the source's volume, named by the config's internal name, to this
+	// driver's storage and makes it accessible as if this driver had created it, updating the
+	// config's access details.  A rehost that was interrupted may be repeated to complete it.
+	RehostVolume(ctx context.Context, volConfig *VolumeConfig, source Driver) error
+}
+
+// NormalizingDriver is implemented by drivers that can bring the volumes they find on their storage
+// into line with the defaults the backend gives new volumes, for backends configured to.
+type NormalizingDriver interface {
+	// NormalizeVolume changes a volume found on the storage where it differs from the backend's
+	// defaults, and updates the volume's config to match, if the backend is configured to.
+	NormalizeVolume(volume *VolumeExternal) error
+}
+
+// DegradedDriver is implemented by drivers that stop calling their storage after it repeatedly
+// fails to respond, so that the backend fails fast instead of adding to the load on storage that
+// is already struggling.
+type DegradedDriver interface {
+	// Degraded returns why the driver has stopped calling its storage, or an empty string if it
+	// hasn't.
+	Degraded() string
+}
+
 type Backend struct {
 	Driver  Driver
 	Name    string
 	Online  bool
 	Storage map[string]*Pool
 	Volumes map[string]*Volume
+	// Cordoned backends continue to serve their existing volumes but
+	// accept no new ones, such as while the storage is being upgraded.
+	Cordoned bool
+
+	// Timeouts limit how long volume operations on this backend may take.
+	Timeouts drivers.OperationTimeouts
+
+	// BackendUUID identifies the backend for as long as it exists, so that its volumes stay bound
+	// to it when it is renamed or its name is regenerated from a changed config.
+	BackendUUID string
 }
 
 func NewStorageBackend(driver Driver) (*Backend, error) {
@@ -73,7 +255,7 @@ func NewStorageBackend(driver Driver) (*Backend, error) {
 	}
 
 	// retrieve backend specs
-	if err := backend.Driver.GetStorageBackendSpecs(&backend); err != nil {
+	if err := backend.Guarded().GetStorageBackendSpecs(&backend); err != nil {
 		return nil, err
 	}
 
@@ -84,15 +266,184 @@ func (b *Backend) AddStoragePool(pool *Pool) {
 	b.Storage[pool.Name] = pool
 }
 
+// SetPlacement applies the placement priority and weight from a backend's config to its
+// pools.  Settings for an individual pool override those for the backend as a whole.
+func (b *Backend) SetPlacement(config *drivers.CommonStorageDriverConfig) {
+	weight := config.Weight
+	if weight == 0 {
+		weight = DefaultPoolWeight
+	}
+	for _, pool := range b.Storage {
+		pool.Priority = config.Priority
+		pool.Weight = weight
+	}
+	for poolName, placement := range config.PoolPlacement {
+		pool, ok := b.Storage[poolName]
+		if !ok {
+			log.WithFields(log.Fields{
+				"backend": b.Name,
+				"pool":    poolName,
+			}).Warn("Ignoring placement settings for unknown pool.")
+			continue
+		}
+		if placement.Priority != nil {
+			pool.Priority = *placement.Priority
+		}
+		if placement.Weight != nil {
+			pool.Weight = *placement.Weight
+		}
+	}
+}
+
+// StorageIdentity identifies the storage the backend manages, or returns empty if its driver can't.
+func (b *Backend) StorageIdentity() string {
+	if _, ok := b.Driver.(IdentityDriver); !ok {
+		return ""
+	}
+	return b.Guarded().StorageIdentity()
+}
+
+// GetInternalVolumeName returns the name the backend gives a volume on its storage.
+func (b *Backend) GetInternalVolumeName(volConfig *VolumeConfig) (string, error) {
+	if _, ok := b.Driver.(VolumeNamingDriver); !ok {
+		return b.Guarded().GetInternalVolumeName(volConfig.Name), nil
+	}
+	return b.Guarded().GetInternalVolumeNameFromConfig(volConfig)
+}
+
+// CheckInternalNameCollision returns an error if another of the backend's volumes has the internal
+// name in a volume's config, as a naming template may give two volumes the same name.
+func (b *Backend) CheckInternalNameCollision(volConfig *VolumeConfig) error {
+	for _, vol := range b.Volumes {
+		if vol.Config.Name != volConfig.Name && vol.Config.InternalName == volConfig.InternalName {
+			return fmt.Errorf("volume %s would have the same name, %s, as volume %s on backend %s",
+				volConfig.Name, volConfig.InternalName, vol.Config.Name, b.Name)
+		}
+	}
+	return nil
+}
+
+// checkInternalName returns an error if the backend can't name a new volume, or if the name is
+// taken by another of its volumes.
+func (b *Backend) checkInternalName(volConfig *VolumeConfig) error {
+	if _, ok := b.Driver.(VolumeNamingDriver); !ok {
+		return nil
+	}
+	internalName, err := b.GetInternalVolumeName(volConfig)
+	if err != nil {
+		return err
+	}
+	nameConfig := *volConfig
+	nameConfig.InternalName = internalName
+	return b.CheckInternalNameCollision(&nameConfig)
+}
+
+// GetDebugTraceFlags returns a copy of the debug trace flags in effect on the backend.
+func (b *Backend) GetDebugTraceFlags() (map[string]bool, error) {
+	if _, ok := b.Driver.(DebugTraceDriver); !ok {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support changing debug trace flags", b.GetDriverName()))
+	}
+	flags := make(map[string]bool)
+	for flag, enabled := range b.Guarded().GetDebugTraceFlags() {
+		flags[flag] = enabled
+	}
+	return flags, nil
+}
+
+// SetDebugTraceFlags replaces the debug trace flags on a running backend.
+func (b *Backend) SetDebugTraceFlags(flags map[string]bool) error {
+	if _, ok := b.Driver.(DebugTraceDriver); !ok {
+		return drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support changing debug trace flags", b.GetDriverName()))
+	}
+	b.Guarded().SetDebugTraceFlags(flags)
+	return nil
+}
+
+// ModifyVolumeQoS changes the QoS of one of the backend's volumes, updating its config to match.
+func (b *Backend) ModifyVolumeQoS(vol *Volume, qos, qosType string) error {
+	if _, ok := b.Driver.(QoSDriver); !ok {
+		return drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support changing volume QoS", b.GetDriverName()))
+	}
+	volConfig := *vol.Config
+	volConfig.QoS = qos
+	volConfig.QoSType = qosType
+	if err := b.Guarded().ModifyVolumeQoS(&volConfig); err != nil {
+		return err
+	}
+	vol.Config.QoS = qos
+	vol.Config.QoSType = qosType
+	return nil
+}
+
+// SetSnapshotDirVisible shows or hides the .snapshot directory of one of the backend's volumes.
+func (b *Backend) SetSnapshotDirVisible(vol *Volume, visible bool) error {
+	if _, ok := b.Driver.(SnapshotDirDriver); !ok {
+		return drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support changing the snapshot directory of a volume", b.GetDriverName()))
+	}
+	return b.Guarded().SetSnapshotDirVisible(vol.Config.InternalName, visible)
+}
+
+// SupportsVolumeStats reports whether the backend's driver can read the performance counters of
+// its volumes.
+// GetVolumeExternal reads a volume from the backend's storage by its internal name, returning the
+// driver's full representation of it on this backend.
+func (b *Backend) GetVolumeExternal(internalName string) (*VolumeExternal, error) {
+	volume, err := b.Guarded().GetVolumeExternal(internalName)
+	if err != nil {
+		return nil, err
+	}
+	b.setVolumeExternalBackend(volume)
+	return volume, nil
+}
+
+// GetVolumeExternalWrappers sends each volume the driver manages on the backend's storage to the
+// channel, as the driver reads them, followed by any errors, and closes the channel once done.
+func (b *Backend) GetVolumeExternalWrappers(channel chan *VolumeExternalWrapper) {
+
+	defer close(channel)
+
+	driverChannel := make(chan *VolumeExternalWrapper)
+	go b.Guarded().GetVolumeExternalWrappers(driverChannel)
+	for wrapper := range driverChannel {
+		if wrapper.Volume != nil {
+			b.setVolumeExternalBackend(wrapper.Volume)
+		}
+		channel <- wrapper
+	}
+}
+
+// setVolumeExternalBackend records the backend in a volume read by its driver, which doesn't know
+// the backend's name.
+func (b *Backend) setVolumeExternalBackend(volume *VolumeExternal) {
+	volume.Backend = b.Name
+	volume.BackendUUID = b.BackendUUID
+}
+
+func (b *Backend) SupportsVolumeStats() bool {
+	_, ok := b.Driver.(StatsDriver)
+	return ok
+}
+
+// SupportsVolumeUsage reports whether the backend's driver can read the space its volumes consume.
+func (b *Backend) SupportsVolumeUsage() bool {
+	_, ok := b.Driver.(UsageDriver)
+	return ok
+}
+
 func (b *Backend) GetDriverName() string {
-	return b.Driver.Name()
+	return b.Guarded().Name()
 }
 
 func (b *Backend) GetProtocol() config.Protocol {
-	return b.Driver.GetProtocol()
+	return b.Guarded().GetProtocol()
 }
 
 func (b *Backend) AddVolume(
+	ctx context.Context,
 	volConfig *VolumeConfig,
 	storagePool *Pool,
 	volumeAttributes map[string]storageattribute.Request,
@@ -101,11 +452,11 @@ func (b *Backend) AddVolume(
 	// Determine volume size in bytes
 	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
 	if err != nil {
-		return nil, fmt.Errorf("could not convert volume size %s: %v", volConfig.Size, err)
+		return nil, drivers.NewFatalError(fmt.Sprintf("could not convert volume size %s: %v", volConfig.Size, err))
 	}
 	volSize, err := strconv.ParseUint(requestedSize, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("%v is an invalid volume size: %v", volConfig.Size, err)
+		return nil, drivers.NewFatalError(fmt.Sprintf("%v is an invalid volume size: %v", volConfig.Size, err))
 	}
 
 	log.WithFields(log.Fields{
@@ -114,13 +465,27 @@ func (b *Backend) AddVolume(
 		"volConfig.StorageClass": volConfig.StorageClass,
 	}).Debug("Attempting volume create.")
 
+	if err = b.checkInternalName(volConfig); err != nil {
+		return nil, err
+	}
+
 	// CreatePrepare should perform the following tasks:
 	// 1. Sanitize the volume name
 	// 2. Ensure no volume with the same name exists on that backend
-	if b.Driver.CreatePrepare(volConfig) {
+	if b.Guarded().CreatePrepare(volConfig) {
+
+		// Create the volume at the size the driver will actually provision
+		sizer, reportsSize := b.Driver.(VolumeSizeDriver)
+		if reportsSize {
+			if volSize, err = sizer.GetVolumeSize(volSize); err != nil {
+				return nil, err
+			}
+		}
+
+		volConfig.ManagedQoSPolicy = managedQoSPolicy(volumeAttributes)
 
 		// add volume to the backend
-		args, err := b.Driver.GetVolumeOpts(volConfig, storagePool,
+		args, err := b.Guarded().GetVolumeOpts(volConfig, storagePool,
 			volumeAttributes)
 		if err != nil {
 			// An error on GetVolumeOpts is almost certainly going to indicate
@@ -129,16 +494,21 @@ func (b *Backend) AddVolume(
 			return nil, err
 		}
 
-		if err := b.Driver.Create(volConfig.InternalName, volSize, args); err != nil {
+		// Drivers that record the owner of each volume on the storage mark it as this backend's
+		createCtx, cancel := withTimeout(utils.WithOwner(ctx, b.BackendUUID), b.Timeouts.Create)
+		err = b.Guarded().Create(createCtx, volConfig.InternalName, volSize, args)
+		cancel()
+		if err != nil {
 			// Implement idempotency at the Trident layer
 			// Ignore the error if the volume exists already
-			if b.Driver.Get(volConfig.InternalName) != nil {
+			if b.Guarded().Get(volConfig.InternalName) != nil {
 				return nil, err
 			}
 		}
 
-		if err = b.Driver.CreateFollowup(volConfig); err != nil {
-			errDestroy := b.Driver.Destroy(volConfig.InternalName)
+		if err = b.createFollowup(volConfig); err != nil {
+			// Clean up even if the caller has given up on the create
+			errDestroy := b.Guarded().Destroy(context.Background(), volConfig.InternalName)
 			if errDestroy != nil {
 				log.WithFields(log.Fields{
 					"backend": b.Name,
@@ -150,7 +520,16 @@ func (b *Backend) AddVolume(
 			}
 			return nil, err
 		}
+		if reportsSize && strconv.FormatUint(volSize, 10) != volConfig.Size {
+			log.WithFields(log.Fields{
+				"volume":        volConfig.Name,
+				"requestedSize": volConfig.Size,
+				"actualSize":    volSize,
+			}).Debug("Volume size was adjusted by the backend.")
+			volConfig.Size = strconv.FormatUint(volSize, 10)
+		}
 		vol := NewVolume(volConfig, b.Name, storagePool.Name, false)
+		vol.BackendUUID = b.BackendUUID
 		b.Volumes[vol.Config.Name] = vol
 		return vol, err
 	} else {
@@ -163,7 +542,7 @@ func (b *Backend) AddVolume(
 	return nil, nil
 }
 
-func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
+func (b *Backend) CloneVolume(ctx context.Context, volConfig *VolumeConfig) (*Volume, error) {
 
 	log.WithFields(log.Fields{
 		"storageClass":   volConfig.StorageClass,
@@ -172,15 +551,19 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 		"cloneVolume":    volConfig.Name,
 	}).Debug("Attempting volume clone.")
 
+	if err := b.checkInternalName(volConfig); err != nil {
+		return nil, err
+	}
+
 	// CreatePrepare should perform the following tasks:
 	// 1. Sanitize the volume name
 	// 2. Ensure no volume with the same name exists on that backend
-	if !b.Driver.CreatePrepare(volConfig) {
+	if !b.Guarded().CreatePrepare(volConfig) {
 		return nil, errors.New("failed to prepare clone create")
 	}
 
 	nilAttributes := make(map[string]storageattribute.Request)
-	args, err := b.Driver.GetVolumeOpts(volConfig, nil, nilAttributes)
+	args, err := b.Guarded().GetVolumeOpts(volConfig, nil, nilAttributes)
 	if err != nil {
 		// An error on GetVolumeOpts is almost certainly going to indicate
 		// a formatting mistake, so go ahead and return an error, rather
@@ -188,7 +571,10 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 		return nil, err
 	}
 
-	err = b.Driver.CreateClone(volConfig.InternalName,
+	ctx, cancel := withTimeout(utils.WithOwner(ctx, b.BackendUUID), b.Timeouts.Clone)
+	defer cancel()
+
+	err = b.Guarded().CreateClone(ctx, volConfig.InternalName,
 		volConfig.CloneSourceVolumeInternal, volConfig.CloneSourceSnapshot,
 		args)
 	if err != nil {
@@ -197,7 +583,7 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 
 	// The clone may not be fully created when the clone API returns, so wait here until it exists.
 	checkCloneExists := func() error {
-		return b.Driver.Get(volConfig.InternalName)
+		return b.Guarded().Get(volConfig.InternalName)
 	}
 	cloneExistsNotify := func(err error, duration time.Duration) {
 		log.WithField("increment", duration).Debug("Clone not yet present, waiting.")
@@ -207,18 +593,23 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 	cloneBackoff.Multiplier = 2
 	cloneBackoff.RandomizationFactor = 0.1
 	cloneBackoff.MaxElapsedTime = 90 * time.Second
+	if b.Timeouts.Clone > 0 {
+		cloneBackoff.MaxElapsedTime = b.Timeouts.Clone
+	}
 
-	// Run the clone check using an exponential backoff
-	if err := backoff.RetryNotify(checkCloneExists, cloneBackoff, cloneExistsNotify); err != nil {
+	// Run the clone check using an exponential backoff, giving up early if the caller does
+	cloneCheckBackoff := backoff.WithContext(cloneBackoff, ctx)
+	if err := backoff.RetryNotify(checkCloneExists, cloneCheckBackoff, cloneExistsNotify); err != nil {
 		log.WithField("cloneVolume", volConfig.Name).Warnf("Could not find clone after %3.2f seconds.",
 			cloneBackoff.MaxElapsedTime)
 	} else {
 		log.WithField("cloneVolume", volConfig.Name).Debug("Clone found.")
 	}
 
-	err = b.Driver.CreateFollowup(volConfig)
+	err = b.createFollowup(volConfig)
 	if err != nil {
-		errDestroy := b.Driver.Destroy(volConfig.InternalName)
+		// Clean up even if the caller has given up on the clone
+		errDestroy := b.Guarded().Destroy(context.Background(), volConfig.InternalName)
 		if errDestroy != nil {
 			log.WithFields(log.Fields{
 				"backend": b.Name,
@@ -231,18 +622,251 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 		return nil, err
 	}
 	vol := NewVolume(volConfig, b.Name, drivers.UnsetPool, false)
+	vol.BackendUUID = b.BackendUUID
+	b.Volumes[vol.Config.Name] = vol
+	return vol, nil
+}
+
+// createFollowup completes a new volume or clone once it exists, making it read-only if requested.
+// Clones are always set one way or the other, as they may take on their source's access.
+func (b *Backend) createFollowup(volConfig *VolumeConfig) error {
+	if err := b.Guarded().CreateFollowup(volConfig); err != nil {
+		return err
+	}
+	if volConfig.ReadOnly || (volConfig.CloneSourceVolume != "" && b.SupportsReadOnly()) {
+		return b.Guarded().SetVolumeReadOnly(volConfig)
+	}
+	return nil
+}
+
+// SupportsReadOnly reports whether the backend's driver can make volumes read-only.
+func (b *Backend) SupportsReadOnly() bool {
+	_, ok := b.Driver.(ReadOnlyDriver)
+	return ok
+}
+
+// SupportsCaches reports whether the backend's driver can create caches of other volumes.
+func (b *Backend) SupportsCaches() bool {
+	_, ok := b.Driver.(CacheDriver)
+	return ok
+}
+
+// CanCacheFrom reports whether the backend can create caches of volumes on the origin backend.
+func (b *Backend) CanCacheFrom(origin *Backend) bool {
+	return origin.Online && b.Guarded().CanCacheFrom(origin.Driver)
+}
+
+// AddCache creates a cache of a volume on the origin backend in one of this backend's pools.  The
+// cache is sized as requested, which may be smaller than its origin, as it only holds the data
+// being read.
+func (b *Backend) AddCache(
+	ctx context.Context, volConfig *VolumeConfig, storagePool *Pool, origin *Backend,
+) (*Volume, error) {
+
+	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return nil, drivers.NewFatalError(fmt.Sprintf("could not convert volume size %s: %v", volConfig.Size, err))
+	}
+	volSize, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return nil, drivers.NewFatalError(fmt.Sprintf("%v is an invalid volume size: %v", volConfig.Size, err))
+	}
+
+	log.WithFields(log.Fields{
+		"storagePool":   storagePool.Name,
+		"size":          volSize,
+		"originVolume":  volConfig.CacheSourceVolume,
+		"originBackend": origin.Name,
+	}).Debug("Attempting cache create.")
+
+	if err = b.checkInternalName(volConfig); err != nil {
+		return nil, err
+	}
+	if !b.Guarded().CreatePrepare(volConfig) {
+		return nil, errors.New("failed to prepare cache create")
+	}
+
+	nilAttributes := make(map[string]storageattribute.Request)
+	args, err := b.Guarded().GetVolumeOpts(volConfig, storagePool, nilAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	createCtx, cancel := withTimeout(ctx, b.Timeouts.Create)
+	err = b.Guarded().CreateCache(createCtx, volConfig.InternalName, volConfig.CacheSourceVolumeInternal,
+		origin.Driver, volSize, args)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.createFollowup(volConfig); err != nil {
+		// Clean up even if the caller has given up on the cache
+		if errDestroy := b.Guarded().DestroyCache(context.Background(), volConfig.InternalName); errDestroy != nil {
+			log.WithFields(log.Fields{
+				"backend": b.Name,
+				"volume":  volConfig.InternalName,
+			}).Warnf("Completing the created cache failed "+
+				"and %s wasn't able to delete it afterwards: %s. "+
+				"Volume needs to be manually deleted.",
+				config.OrchestratorName, errDestroy)
+		}
+		return nil, err
+	}
+
+	vol := NewVolume(volConfig, b.Name, storagePool.Name, false)
+	vol.BackendUUID = b.BackendUUID
+	b.Volumes[vol.Config.Name] = vol
+	return vol, nil
+}
+
+// SupportsWarmPool reports whether the backend's driver can hand out volumes created ahead of
+// requests.
+func (b *Backend) SupportsWarmPool() bool {
+	_, ok := b.Driver.(WarmPoolDriver)
+	return ok
+}
+
+// ClaimWarmVolume turns a volume created ahead of time into the volume described by a request,
+// renaming it on the storage and growing it if the request is larger.  The claimed volume takes
+// the warm volume's place among the backend's volumes.  If the volume can't be claimed, any
+// rename is undone so the warm volume remains as it was.
+func (b *Backend) ClaimWarmVolume(ctx context.Context, warm *Volume, volConfig *VolumeConfig) (*Volume, error) {
+
+	if !b.SupportsWarmPool() {
+		return nil, drivers.NewUnsupportedError(fmt.Sprintf(
+			"the %s driver does not support warm pools", b.GetDriverName()))
+	}
+
+	warmSize, err := strconv.ParseUint(warm.Config.Size, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("warm volume %s has an invalid size %s: %v", warm.Config.Name, warm.Config.Size, err)
+	}
+	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return nil, drivers.NewFatalError(fmt.Sprintf("could not convert volume size %s: %v", volConfig.Size, err))
+	}
+	volSize, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return nil, drivers.NewFatalError(fmt.Sprintf("%v is an invalid volume size: %v", volConfig.Size, err))
+	}
+	if volSize > warmSize {
+		if sizer, ok := b.Driver.(VolumeSizeDriver); ok {
+			if volSize, err = sizer.GetVolumeSize(volSize); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	internalName, err := b.GetInternalVolumeName(volConfig)
+	if err != nil {
+		return nil, err
+	}
+	volConfig.InternalName = internalName
+	if err = b.CheckInternalNameCollision(volConfig); err != nil {
+		return nil, err
+	}
+	if b.Guarded().Get(internalName) == nil {
+		return nil, fmt.Errorf("volume %s already exists on backend %s", internalName, b.Name)
+	}
+
+	log.WithFields(log.Fields{
+		"backend":      b.Name,
+		"warmVolume":   warm.Config.InternalName,
+		"volume":       volConfig.Name,
+		"internalName": internalName,
+		"size":         volSize,
+	}).Debug("Claiming warm volume.")
+
+	ctx, cancel := withTimeout(ctx, b.Timeouts.Create)
+	defer cancel()
+
+	if err = b.Guarded().RenameVolume(ctx, warm.Config.InternalName, internalName); err != nil {
+		return nil, err
+	}
+	undoRename := func(cause error) error {
+		if errRename := b.Guarded().RenameVolume(context.Background(), internalName,
+			warm.Config.InternalName); errRename != nil {
+			log.WithFields(log.Fields{
+				"backend":      b.Name,
+				"volume":       internalName,
+				"originalName": warm.Config.InternalName,
+				"error":        errRename,
+			}).Warn("Could not restore the name of a warm volume that failed to be claimed.")
+		}
+		return cause
+	}
+
+	if volSize > warmSize {
+		if err = b.Guarded().ResizeVolume(ctx, internalName, volSize); err != nil {
+			return nil, undoRename(err)
+		}
+	} else {
+		volSize = warmSize
+	}
+	volConfig.Size = strconv.FormatUint(volSize, 10)
+	volConfig.ManagedQoSPolicy = warm.Config.ManagedQoSPolicy
+
+	if err = b.Guarded().CreateFollowup(volConfig); err != nil {
+		return nil, undoRename(err)
+	}
+
+	vol := NewVolume(volConfig, b.Name, warm.Pool, false)
+	vol.BackendUUID = b.BackendUUID
+	delete(b.Volumes, warm.Config.Name)
 	b.Volumes[vol.Config.Name] = vol
 	return vol, nil
 }
 
+// SupportsClones returns false if the backend's storage pools advertise that cloning is unavailable.
+// Backends whose pools say nothing about cloning are assumed to support it, leaving the final say
+// to the driver.
+func (b *Backend) SupportsClones() bool {
+	advertised := false
+	for _, pool := range b.Storage {
+		if offer, ok := pool.Attributes[storageattribute.Clones]; ok {
+			if offer.Matches(storageattribute.NewBoolRequest(true)) {
+				return true
+			}
+			advertised = true
+		}
+	}
+	return !advertised
+}
+
 // HasVolumes returns true if the Backend has one or more volumes
 // provisioned on it.
 func (b *Backend) HasVolumes() bool {
 	return len(b.Volumes) > 0
 }
 
-func (b *Backend) RemoveVolume(vol *Volume) error {
-	if err := b.Driver.Destroy(vol.Config.InternalName); err != nil {
+// SupportsOnDelete reports whether the backend's driver can carry out a volume's on-delete
+// behavior.  Every driver supports the default of deleting the volume.
+func (b *Backend) SupportsOnDelete(onDelete string) bool {
+	if onDelete == "" || onDelete == drivers.OnDeleteDelete {
+		return true
+	}
+	if _, ok := b.Driver.(ReclaimDriver); !ok {
+		return false
+	}
+	return b.Guarded().SupportsOnDelete(onDelete)
+}
+
+func (b *Backend) RemoveVolume(ctx context.Context, vol *Volume) error {
+	// Rather than destroy a volume that should have been retained, leave it to be deleted again
+	if !b.SupportsOnDelete(vol.Config.OnDelete) {
+		return drivers.NewUnsupportedError(fmt.Sprintf("the %s driver cannot carry out onDelete %s for volume %s",
+			b.GetDriverName(), vol.Config.OnDelete, vol.Config.Name))
+	}
+	ctx = drivers.WithOnDelete(ctx, vol.Config.OnDelete)
+	ctx = drivers.WithManagedQoSPolicy(ctx, vol.Config.ManagedQoSPolicy)
+	ctx, cancel := withTimeout(ctx, b.Timeouts.Delete)
+	defer cancel()
+	if vol.Config.IsCache() {
+		if err := b.Guarded().DestroyCache(ctx, vol.Config.InternalName); err != nil {
+			return err
+		}
+	} else if err := b.Guarded().Destroy(ctx, vol.Config.InternalName); err != nil {
 		// TODO:  Check the error being returned once the nDVP throws errors
 		// for volumes that aren't found.
 		return err
@@ -253,6 +877,31 @@ func (b *Backend) RemoveVolume(vol *Volume) error {
 	return nil
 }
 
+// managedQoSPolicy returns the QoS policy named by a storage class that also gives limits for it,
+// which Trident creates if it is missing and deletes once no volumes use it.  A policy named
+// without limits must already exist, and is left alone.
+func managedQoSPolicy(volumeAttributes map[string]storageattribute.Request) string {
+	request, ok := volumeAttributes[storageattribute.QoSPolicy]
+	if !ok {
+		return ""
+	}
+	_, maxIOPS := volumeAttributes[storageattribute.QoSMaxIOPS]
+	_, maxMBps := volumeAttributes[storageattribute.QoSMaxMBps]
+	if !maxIOPS && !maxMBps {
+		return ""
+	}
+	policy, _ := request.Value().(string)
+	return policy
+}
+
+// withTimeout bounds a context by one of the backend's timeouts, if it is set.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // Terminate informs the backend that it is being deleted from the core
 // and will not be called again.  This may be a signal to the storage
 // driver to clean up and stop any ongoing operations.
@@ -263,7 +912,7 @@ func (b *Backend) Terminate() {
 		"driverName":  b.GetDriverName(),
 	}).Debug("Terminating backend.")
 
-	b.Driver.Terminate()
+	b.Guarded().Terminate()
 }
 
 type BackendExternal struct {
@@ -272,35 +921,95 @@ type BackendExternal struct {
 	Storage map[string]*PoolExternal `json:"storage"`
 	Online  bool                     `json:"online"`
 	Volumes []string                 `json:"volumes"`
+
+	Cordoned    bool   `json:"cordoned"`
+	BackendUUID string `json:"backendUUID"`
+	Degraded    string `json:"degraded,omitempty"`
 }
 
 func (b *Backend) ConstructExternal() *BackendExternal {
 	backendExternal := BackendExternal{
 		Name:    b.Name,
-		Config:  b.Driver.GetExternalConfig(),
+		Config:  b.Guarded().GetExternalConfig(),
 		Storage: make(map[string]*PoolExternal),
 		Online:  b.Online,
 		Volumes: make([]string, 0),
+
+		Cordoned:    b.Cordoned,
+		BackendUUID: b.BackendUUID,
+		Degraded:    b.Guarded().Degraded(),
 	}
 
 	for name, pool := range b.Storage {
 		backendExternal.Storage[name] = pool.ConstructExternal()
 	}
-	for volName := range b.Volumes {
+	for volName, vol := range b.Volumes {
 		backendExternal.Volumes = append(backendExternal.Volumes, volName)
+		if pool, ok := backendExternal.Storage[vol.Pool]; ok {
+			pool.VolumeCount++
+		}
 	}
 	return &backendExternal
 }
 
+// ConstructExternalWithCapacity is like ConstructExternal, but it also asks the driver for the
+// capacity of each storage pool, if the driver can report it.  Pools whose capacity can't be read
+// are logged and reported without it.
+func (b *Backend) ConstructExternalWithCapacity() *BackendExternal {
+
+	backendExternal := b.ConstructExternal()
+
+	if _, ok := b.Driver.(CapacityDriver); !ok || !b.Online {
+		return backendExternal
+	}
+
+	for name, pool := range b.Storage {
+		capacity, err := b.Guarded().GetPoolCapacity(pool)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"backend": b.Name,
+				"pool":    name,
+			}).Warnf("Could not get storage pool capacity. %v", err)
+			continue
+		}
+		backendExternal.Storage[name].Capacity = capacity
+	}
+
+	return backendExternal
+}
+
+// ConstructExternalWithConfigDetail is like ConstructExternalWithCapacity, but it reports the
+// driver's whole config, with its credentials redacted, instead of the summary the driver chooses
+// to expose.
+func (b *Backend) ConstructExternalWithConfigDetail() (*BackendExternal, error) {
+
+	backendExternal := b.ConstructExternalWithCapacity()
+
+	persistentBackend := b.ConstructPersistent()
+	driverConfig := persistentBackend.Config.driverConfig()
+	if driverConfig == nil {
+		return nil, fmt.Errorf("no recognized config found for backend %s", b.Name)
+	}
+	redactedConfig, err := drivers.RedactedConfig(driverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not redact config of backend %s; %v", b.Name, err)
+	}
+	backendExternal.Config = redactedConfig
+
+	return backendExternal, nil
+}
+
 // Used to store the requisite info for a backend in etcd.  Other than
 // the configuration, all other data will be reconstructed during the bootstrap
 // phase
 
 type PersistentStorageBackendConfig struct {
-	OntapConfig             *drivers.OntapStorageDriverConfig     `json:"ontap_config,omitempty"`
-	SolidfireConfig         *drivers.SolidfireStorageDriverConfig `json:"solidfire_config,omitempty"`
-	EseriesConfig           *drivers.ESeriesStorageDriverConfig   `json:"eseries_config,omitempty"`
-	FakeStorageDriverConfig *drivers.FakeStorageDriverConfig      `json:"fake_config,omitempty"`
+	OntapConfig             *drivers.OntapStorageDriverConfig      `json:"ontap_config,omitempty"`
+	SolidfireConfig         *drivers.SolidfireStorageDriverConfig  `json:"solidfire_config,omitempty"`
+	EseriesConfig           *drivers.ESeriesStorageDriverConfig    `json:"eseries_config,omitempty"`
+	GCPConfig               *drivers.GCPNFSStorageDriverConfig     `json:"gcp_config,omitempty"`
+	NFSConfig               *drivers.GenericNFSStorageDriverConfig `json:"nfs_config,omitempty"`
+	FakeStorageDriverConfig *drivers.FakeStorageDriverConfig       `json:"fake_config,omitempty"`
 }
 
 type BackendPersistent struct {
@@ -308,16 +1017,29 @@ type BackendPersistent struct {
 	Config  PersistentStorageBackendConfig `json:"config"`
 	Name    string                         `json:"name"`
 	Online  bool                           `json:"online"`
+
+	Cordoned      bool   `json:"cordoned,omitempty"`
+	BackendUUID   string `json:"backendUUID,omitempty"`
+	SchemaVersion int    `json:"schemaVersion,omitempty"`
 }
 
+// BackendSchemaVersion is the version of the structure of stored backends.  It is raised, along
+// with a migration in the persistent store that upgrades backends stored by earlier versions,
+// whenever that structure changes.
+const BackendSchemaVersion = 1
+
 func (b *Backend) ConstructPersistent() *BackendPersistent {
 	persistentBackend := &BackendPersistent{
 		Version: config.OrchestratorAPIVersion,
 		Config:  PersistentStorageBackendConfig{},
 		Name:    b.Name,
 		Online:  b.Online,
+
+		Cordoned:      b.Cordoned,
+		BackendUUID:   b.BackendUUID,
+		SchemaVersion: BackendSchemaVersion,
 	}
-	b.Driver.StoreConfig(&persistentBackend.Config)
+	b.Guarded().StoreConfig(&persistentBackend.Config)
 	return persistentBackend
 }
 
@@ -326,24 +1048,32 @@ func (b *Backend) ConstructPersistent() *BackendPersistent {
 // Ideally, BackendPersistent would just store a serialized config, but
 // doing so appears to cause problems with the json.RawMessage fields.
 func (p *BackendPersistent) MarshalConfig() (string, error) {
-	var (
-		bytes []byte
-		err   error
-	)
-	switch {
-	case p.Config.OntapConfig != nil:
-		bytes, err = json.Marshal(p.Config.OntapConfig)
-	case p.Config.SolidfireConfig != nil:
-		bytes, err = json.Marshal(p.Config.SolidfireConfig)
-	case p.Config.EseriesConfig != nil:
-		bytes, err = json.Marshal(p.Config.EseriesConfig)
-	case p.Config.FakeStorageDriverConfig != nil:
-		bytes, err = json.Marshal(p.Config.FakeStorageDriverConfig)
-	default:
+	driverConfig := p.Config.driverConfig()
+	if driverConfig == nil {
 		return "", fmt.Errorf("no recognized config found for backend %s", p.Name)
 	}
+	bytes, err := json.Marshal(driverConfig)
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), err
 }
+
+// driverConfig returns the config of the backend's driver, or nil if no recognized config is set.
+func (c *PersistentStorageBackendConfig) driverConfig() interface{} {
+	switch {
+	case c.OntapConfig != nil:
+		return c.OntapConfig
+	case c.SolidfireConfig != nil:
+		return c.SolidfireConfig
+	case c.EseriesConfig != nil:
+		return c.EseriesConfig
+	case c.GCPConfig != nil:
+		return c.GCPConfig
+	case c.NFSConfig != nil:
+		return c.NFSConfig
+	case c.FakeStorageDriverConfig != nil:
+		return c.FakeStorageDriverConfig
+	}
+	return nil
+}
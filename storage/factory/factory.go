@@ -16,6 +16,8 @@ import (
 	drivers "github.com/netapp/trident/storage_drivers"
 	"github.com/netapp/trident/storage_drivers/eseries"
 	"github.com/netapp/trident/storage_drivers/fake"
+	"github.com/netapp/trident/storage_drivers/gcp"
+	"github.com/netapp/trident/storage_drivers/nfs"
 	"github.com/netapp/trident/storage_drivers/ontap"
 	ontapi "github.com/netapp/trident/storage_drivers/ontap/api"
 	"github.com/netapp/trident/storage_drivers/solidfire"
@@ -61,6 +63,10 @@ func NewStorageBackendForConfig(configJSON string) (sb *storage.Backend, err err
 		storageDriver = &solidfire.SANStorageDriver{}
 	case drivers.EseriesIscsiStorageDriverName:
 		storageDriver = &eseries.SANStorageDriver{}
+	case drivers.GCPNFSStorageDriverName:
+		storageDriver = &gcp.NFSStorageDriver{}
+	case drivers.GenericNFSStorageDriverName:
+		storageDriver = &nfs.StorageDriver{}
 	case drivers.FakeStorageDriverName:
 		storageDriver = &fake.StorageDriver{}
 	default:
@@ -235,6 +241,12 @@ func NewStorageBackendForConfig(configJSON string) (sb *storage.Backend, err err
 			}).Warnf("Please ensure all relevant hosts are added to Host Group %s.", driver.Config.AccessGroup)
 		}
 
+	case drivers.GCPNFSStorageDriverName:
+		break
+
+	case drivers.GenericNFSStorageDriverName:
+		break
+
 	case drivers.FakeStorageDriverName:
 		break
 
@@ -244,6 +256,16 @@ func NewStorageBackendForConfig(configJSON string) (sb *storage.Backend, err err
 	}
 
 	sb, err = storage.NewStorageBackend(storageDriver)
+	if err != nil {
+		return
+	}
+	// A configured name keeps the backend's identity stable when the address its name is
+	// otherwise generated from changes
+	if commonConfig.BackendName != "" {
+		sb.Name = commonConfig.BackendName
+	}
+	sb.SetPlacement(commonConfig)
+	sb.Timeouts = commonConfig.Timeouts
 
 	log.WithField("driver", commonConfig.StorageDriverName).Debug("Storage driver initialized.")
 
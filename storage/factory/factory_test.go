@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage/fake"
 	drivers "github.com/netapp/trident/storage_drivers"
+	fakedriver "github.com/netapp/trident/storage_drivers/fake"
 )
 
 // TestInitializeRecovery intentionally passes a bogus config to
@@ -37,3 +40,49 @@ func TestInitializeRecovery(t *testing.T) {
 		t.Error("Failed to get error for invalid configuration.")
 	}
 }
+
+func TestPlacementConfig(t *testing.T) {
+	configJSON, err := fakedriver.NewFakeStorageDriverConfigJSON("placement", config.File,
+		map[string]*fake.StoragePool{
+			"gold":    {Bytes: 1024 * 1024 * 1024},
+			"archive": {Bytes: 1024 * 1024 * 1024},
+		},
+	)
+	if err != nil {
+		t.Fatal("Unable to generate fake config: ", err)
+	}
+	var configMap map[string]interface{}
+	if err = json.Unmarshal([]byte(configJSON), &configMap); err != nil {
+		t.Fatal("Unable to parse fake config: ", err)
+	}
+	configMap["priority"] = 10
+	configMap["weight"] = 3
+	configMap["poolPlacement"] = map[string]interface{}{
+		"archive": map[string]interface{}{"priority": -1},
+	}
+	marshaledJSON, err := json.Marshal(configMap)
+	if err != nil {
+		t.Fatal("Unable to marshal fake config: ", err)
+	}
+
+	backend, err := NewStorageBackendForConfig(string(marshaledJSON))
+	if err != nil {
+		t.Fatal("Unable to create backend: ", err)
+	}
+	if pool := backend.Storage["gold"]; pool.Priority != 10 || pool.Weight != 3 {
+		t.Errorf("Expected gold pool priority 10 and weight 3, got %d and %d.", pool.Priority, pool.Weight)
+	}
+	if pool := backend.Storage["archive"]; pool.Priority != -1 || pool.Weight != 3 {
+		t.Errorf("Expected archive pool priority -1 and weight 3, got %d and %d.", pool.Priority, pool.Weight)
+	}
+
+	configMap["poolPlacement"] = map[string]interface{}{
+		"archive": map[string]interface{}{"weight": 0},
+	}
+	if marshaledJSON, err = json.Marshal(configMap); err != nil {
+		t.Fatal("Unable to marshal fake config: ", err)
+	}
+	if _, err = NewStorageBackendForConfig(string(marshaledJSON)); err == nil {
+		t.Error("Expected an error for a pool weight of zero.")
+	}
+}
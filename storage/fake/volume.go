@@ -1,7 +1,20 @@
 package fake
 
+import "time"
+
 type Volume struct {
 	Name      string
 	PoolName  string
 	SizeBytes uint64
+	ReadOnly  bool
+	// SnapshotDirVisible is whether the volume's .snapshot directory is shown
+	SnapshotDirVisible bool
+	// CacheOrigin is the volume a cache was created from
+	CacheOrigin string
+	// CloneSource and CloneSnapshot are the volume and snapshot a clone was created from
+	CloneSource   string
+	CloneSnapshot string
+	// Owner and Created record the backend that created the volume, and when
+	Owner   string
+	Created time.Time
 }
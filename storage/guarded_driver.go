@@ -0,0 +1,514 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// GuardedDriver invokes a backend's driver such that a panic in any of its methods, such as one
+// caused by a malformed response from the storage system, is logged and returned as an error
+// rather than crashing Trident.  Calls that may reach the storage system are also timed.
+//
+// Whether the driver implements an optional interface, such as SnapshotDriver, is still checked
+// against the backend's Driver; the corresponding methods here fail if it does not.
+type GuardedDriver struct {
+	backend string
+	driver  Driver
+}
+
+// Guarded returns the backend's driver guarded against panics.  All calls into the driver from
+// outside the driver package should be made through it.
+func (b *Backend) Guarded() *GuardedDriver {
+	return &GuardedDriver{backend: b.Name, driver: b.Driver}
+}
+
+// call runs a driver method that may reach the storage system, returning any panic as an error.
+func (g *GuardedDriver) call(method string, f func() error) (err error) {
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = g.recovered(method, r)
+		}
+		log.WithFields(log.Fields{
+			"backend":  g.backend,
+			"method":   method,
+			"duration": time.Since(start),
+			"error":    err,
+		}).Debug("Invoked storage driver.")
+	}()
+
+	return f()
+}
+
+// get runs a driver method that only reads the driver's own state, logging any panic.  The
+// method's results are left at their zero values if it panics.
+func (g *GuardedDriver) get(method string, f func()) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			g.recovered(method, r)
+		}
+	}()
+
+	f()
+}
+
+func (g *GuardedDriver) recovered(method string, r interface{}) error {
+	log.WithFields(log.Fields{
+		"backend": g.backend,
+		"method":  method,
+		"panic":   r,
+	}).Errorf("Storage driver panicked.\n%s", debug.Stack())
+	return fmt.Errorf("storage driver for backend %s failed in %s: %v", g.backend, method, r)
+}
+
+func (g *GuardedDriver) unsupported(capability string) error {
+	return fmt.Errorf("storage driver for backend %s does not support %s", g.backend, capability)
+}
+
+func (g *GuardedDriver) Name() (name string) {
+	g.get("Name", func() { name = g.driver.Name() })
+	return
+}
+
+func (g *GuardedDriver) Initialized() (initialized bool) {
+	g.get("Initialized", func() { initialized = g.driver.Initialized() })
+	return
+}
+
+func (g *GuardedDriver) Terminate() {
+	g.call("Terminate", func() error {
+		g.driver.Terminate()
+		return nil
+	})
+}
+
+func (g *GuardedDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
+	return g.call("Create", func() error { return g.driver.Create(ctx, name, sizeBytes, opts) })
+}
+
+func (g *GuardedDriver) CreateClone(ctx context.Context, name, source, snapshot string, opts map[string]string) error {
+	return g.call("CreateClone", func() error { return g.driver.CreateClone(ctx, name, source, snapshot, opts) })
+}
+
+func (g *GuardedDriver) Destroy(ctx context.Context, name string) error {
+	return g.call("Destroy", func() error { return g.driver.Destroy(ctx, name) })
+}
+
+func (g *GuardedDriver) Attach(name, mountpoint string, opts map[string]string) error {
+	return g.call("Attach", func() error { return g.driver.Attach(name, mountpoint, opts) })
+}
+
+func (g *GuardedDriver) Detach(name, mountpoint string) error {
+	return g.call("Detach", func() error { return g.driver.Detach(name, mountpoint) })
+}
+
+func (g *GuardedDriver) SnapshotList(name string) (snapshots []Snapshot, err error) {
+	err = g.call("SnapshotList", func() error {
+		snapshots, err = g.driver.SnapshotList(name)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) List() (volumes []string, err error) {
+	err = g.call("List", func() error {
+		volumes, err = g.driver.List()
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) Get(name string) error {
+	return g.call("Get", func() error { return g.driver.Get(name) })
+}
+
+func (g *GuardedDriver) CreatePrepare(volConfig *VolumeConfig) (ok bool) {
+	g.get("CreatePrepare", func() { ok = g.driver.CreatePrepare(volConfig) })
+	return
+}
+
+func (g *GuardedDriver) CreateFollowup(volConfig *VolumeConfig) error {
+	return g.call("CreateFollowup", func() error { return g.driver.CreateFollowup(volConfig) })
+}
+
+func (g *GuardedDriver) GetInternalVolumeName(name string) (internalName string) {
+	g.get("GetInternalVolumeName", func() { internalName = g.driver.GetInternalVolumeName(name) })
+	return
+}
+
+func (g *GuardedDriver) GetStorageBackendSpecs(backend *Backend) error {
+	return g.call("GetStorageBackendSpecs", func() error { return g.driver.GetStorageBackendSpecs(backend) })
+}
+
+func (g *GuardedDriver) GetVolumeOpts(
+	volConfig *VolumeConfig, pool *Pool, requests map[string]storageattribute.Request,
+) (opts map[string]string, err error) {
+	err = g.call("GetVolumeOpts", func() error {
+		opts, err = g.driver.GetVolumeOpts(volConfig, pool, requests)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) GetProtocol() (protocol config.Protocol) {
+	g.get("GetProtocol", func() { protocol = g.driver.GetProtocol() })
+	return
+}
+
+func (g *GuardedDriver) StoreConfig(b *PersistentStorageBackendConfig) {
+	g.get("StoreConfig", func() { g.driver.StoreConfig(b) })
+}
+
+func (g *GuardedDriver) GetExternalConfig() (externalConfig interface{}) {
+	g.get("GetExternalConfig", func() { externalConfig = g.driver.GetExternalConfig() })
+	return
+}
+
+func (g *GuardedDriver) GetVolumeExternal(name string) (volume *VolumeExternal, err error) {
+	err = g.call("GetVolumeExternal", func() error {
+		volume, err = g.driver.GetVolumeExternal(name)
+		return err
+	})
+	return
+}
+
+// GetVolumeExternalWrappers passes on the volumes the driver sends, followed by an error if the
+// driver panics, and closes the channel once the driver has returned.
+func (g *GuardedDriver) GetVolumeExternalWrappers(channel chan *VolumeExternalWrapper) {
+
+	defer close(channel)
+
+	// The driver closes its channel, perhaps while panicking, so it must have one of its own
+	driverChannel := make(chan *VolumeExternalWrapper)
+	finished := make(chan error, 1)
+	go func() {
+		finished <- g.call("GetVolumeExternalWrappers", func() error {
+			g.driver.GetVolumeExternalWrappers(driverChannel)
+			return nil
+		})
+	}()
+
+	for {
+		select {
+		case wrapper, ok := <-driverChannel:
+			if !ok {
+				driverChannel = nil
+				continue
+			}
+			channel <- wrapper
+		case err := <-finished:
+			// The channel is unbuffered, so the driver can't have sent anything not yet received
+			if err != nil {
+				channel <- &VolumeExternalWrapper{Volume: nil, Error: err}
+			}
+			return
+		}
+	}
+}
+
+func (g *GuardedDriver) SetJournal(journal drivers.Journal) {
+	if driver, ok := g.driver.(JournalingDriver); ok {
+		g.get("SetJournal", func() { driver.SetJournal(journal) })
+	}
+}
+
+func (g *GuardedDriver) ReconcileJournalEntry(entry *drivers.JournalEntry) error {
+	driver, ok := g.driver.(JournalingDriver)
+	if !ok {
+		return g.unsupported("journaling")
+	}
+	return g.call("ReconcileJournalEntry", func() error { return driver.ReconcileJournalEntry(entry) })
+}
+
+func (g *GuardedDriver) GetDebugTraceFlags() (flags map[string]bool) {
+	if driver, ok := g.driver.(DebugTraceDriver); ok {
+		g.get("GetDebugTraceFlags", func() { flags = driver.GetDebugTraceFlags() })
+	}
+	return
+}
+
+func (g *GuardedDriver) SetDebugTraceFlags(flags map[string]bool) {
+	if driver, ok := g.driver.(DebugTraceDriver); ok {
+		g.get("SetDebugTraceFlags", func() { driver.SetDebugTraceFlags(flags) })
+	}
+}
+
+func (g *GuardedDriver) ModifyVolumeQoS(volConfig *VolumeConfig) error {
+	driver, ok := g.driver.(QoSDriver)
+	if !ok {
+		return g.unsupported("QoS")
+	}
+	return g.call("ModifyVolumeQoS", func() error { return driver.ModifyVolumeQoS(volConfig) })
+}
+
+func (g *GuardedDriver) GetPoolCapacity(pool *Pool) (capacity *PoolCapacity, err error) {
+	driver, ok := g.driver.(CapacityDriver)
+	if !ok {
+		return nil, g.unsupported("capacity reporting")
+	}
+	err = g.call("GetPoolCapacity", func() error {
+		capacity, err = driver.GetPoolCapacity(pool)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) CreateSnapshot(snapshotName, volumeName string) (snapshot *Snapshot, err error) {
+	driver, ok := g.driver.(SnapshotDriver)
+	if !ok {
+		return nil, g.unsupported("snapshots")
+	}
+	err = g.call("CreateSnapshot", func() error {
+		snapshot, err = driver.CreateSnapshot(snapshotName, volumeName)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+	driver, ok := g.driver.(SnapshotDriver)
+	if !ok {
+		return g.unsupported("snapshots")
+	}
+	return g.call("DeleteSnapshot", func() error { return driver.DeleteSnapshot(snapshotName, volumeName) })
+}
+
+func (g *GuardedDriver) CreateGroupSnapshot(snapshotName string, volumeNames []string) (
+	snapshots []*Snapshot, err error) {
+
+	driver, ok := g.driver.(GroupSnapshotDriver)
+	if !ok {
+		return nil, g.unsupported("group snapshots")
+	}
+	err = g.call("CreateGroupSnapshot", func() error {
+		snapshots, err = driver.CreateGroupSnapshot(snapshotName, volumeNames)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) CanReplicateFrom(source Driver) (ok bool) {
+	if driver, isMigrationDriver := g.driver.(MigrationDriver); isMigrationDriver {
+		g.get("CanReplicateFrom", func() { ok = driver.CanReplicateFrom(source) })
+	}
+	return
+}
+
+func (g *GuardedDriver) CreateReplica(
+	ctx context.Context, name, sourceName string, source Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	driver, ok := g.driver.(MigrationDriver)
+	if !ok {
+		return g.unsupported("replication")
+	}
+	return g.call("CreateReplica", func() error {
+		return driver.CreateReplica(ctx, name, sourceName, source, sizeBytes, opts)
+	})
+}
+
+func (g *GuardedDriver) UpdateReplica(ctx context.Context, name string) error {
+	driver, ok := g.driver.(MigrationDriver)
+	if !ok {
+		return g.unsupported("replication")
+	}
+	return g.call("UpdateReplica", func() error { return driver.UpdateReplica(ctx, name) })
+}
+
+func (g *GuardedDriver) PromoteReplica(ctx context.Context, name, sourceName string, source Driver) error {
+	driver, ok := g.driver.(MigrationDriver)
+	if !ok {
+		return g.unsupported("replication")
+	}
+	return g.call("PromoteReplica", func() error { return driver.PromoteReplica(ctx, name, sourceName, source) })
+}
+
+func (g *GuardedDriver) DeleteReplica(ctx context.Context, name, sourceName string, source Driver) error {
+	driver, ok := g.driver.(MigrationDriver)
+	if !ok {
+		return g.unsupported("replication")
+	}
+	return g.call("DeleteReplica", func() error { return driver.DeleteReplica(ctx, name, sourceName, source) })
+}
+
+func (g *GuardedDriver) CanCacheFrom(origin Driver) (ok bool) {
+	if driver, isCacheDriver := g.driver.(CacheDriver); isCacheDriver {
+		g.get("CanCacheFrom", func() { ok = driver.CanCacheFrom(origin) })
+	}
+	return
+}
+
+func (g *GuardedDriver) CreateCache(
+	ctx context.Context, name, originName string, origin Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	driver, ok := g.driver.(CacheDriver)
+	if !ok {
+		return g.unsupported("caches")
+	}
+	return g.call("CreateCache", func() error {
+		return driver.CreateCache(ctx, name, originName, origin, sizeBytes, opts)
+	})
+}
+
+func (g *GuardedDriver) DestroyCache(ctx context.Context, name string) error {
+	driver, ok := g.driver.(CacheDriver)
+	if !ok {
+		return g.unsupported("caches")
+	}
+	return g.call("DestroyCache", func() error { return driver.DestroyCache(ctx, name) })
+}
+
+func (g *GuardedDriver) CanRehostFrom(source Driver) (ok bool) {
+	if driver, isRehostDriver := g.driver.(RehostDriver); isRehostDriver {
+		g.get("CanRehostFrom", func() { ok = driver.CanRehostFrom(source) })
+	}
+	return
+}
+
+func (g *GuardedDriver) RehostVolume(ctx context.Context, volConfig *VolumeConfig, source Driver) error {
+	driver, ok := g.driver.(RehostDriver)
+	if !ok {
+		return g.unsupported("rehosting volumes")
+	}
+	return g.call("RehostVolume", func() error { return driver.RehostVolume(ctx, volConfig, source) })
+}
+
+func (g *GuardedDriver) GetVolumeStats(name string) (stats *VolumeStats, err error) {
+	driver, ok := g.driver.(StatsDriver)
+	if !ok {
+		return nil, g.unsupported("volume statistics")
+	}
+	err = g.call("GetVolumeStats", func() error {
+		stats, err = driver.GetVolumeStats(name)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) GetVolumeUsedBytes(name string) (usedBytes uint64, err error) {
+	driver, ok := g.driver.(UsageDriver)
+	if !ok {
+		return 0, g.unsupported("volume usage")
+	}
+	err = g.call("GetVolumeUsedBytes", func() error {
+		usedBytes, err = driver.GetVolumeUsedBytes(name)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) SetVolumeReadOnly(volConfig *VolumeConfig) error {
+	driver, ok := g.driver.(ReadOnlyDriver)
+	if !ok {
+		return g.unsupported("read-only volumes")
+	}
+	return g.call("SetVolumeReadOnly", func() error { return driver.SetVolumeReadOnly(volConfig) })
+}
+
+func (g *GuardedDriver) SetSnapshotDirVisible(name string, visible bool) error {
+	driver, ok := g.driver.(SnapshotDirDriver)
+	if !ok {
+		return g.unsupported("changing the snapshot directory")
+	}
+	return g.call("SetSnapshotDirVisible", func() error { return driver.SetSnapshotDirVisible(name, visible) })
+}
+
+func (g *GuardedDriver) NormalizeVolume(volume *VolumeExternal) error {
+	driver, ok := g.driver.(NormalizingDriver)
+	if !ok {
+		return g.unsupported("normalizing volumes")
+	}
+	return g.call("NormalizeVolume", func() error { return driver.NormalizeVolume(volume) })
+}
+
+func (g *GuardedDriver) SupportsOnDelete(onDelete string) (ok bool) {
+	if driver, isReclaimDriver := g.driver.(ReclaimDriver); isReclaimDriver {
+		g.get("SupportsOnDelete", func() { ok = driver.SupportsOnDelete(onDelete) })
+	}
+	return
+}
+
+func (g *GuardedDriver) StorageIdentity() (identity string) {
+	if driver, ok := g.driver.(IdentityDriver); ok {
+		g.get("StorageIdentity", func() { identity = driver.StorageIdentity() })
+	}
+	return
+}
+
+func (g *GuardedDriver) Degraded() (reason string) {
+	if driver, ok := g.driver.(DegradedDriver); ok {
+		g.get("Degraded", func() { reason = driver.Degraded() })
+	}
+	return
+}
+
+func (g *GuardedDriver) GetInternalVolumeNameFromConfig(volConfig *VolumeConfig) (name string, err error) {
+	driver, ok := g.driver.(VolumeNamingDriver)
+	if !ok {
+		return g.GetInternalVolumeName(volConfig.Name), nil
+	}
+	err = g.call("GetInternalVolumeNameFromConfig", func() error {
+		name, err = driver.GetInternalVolumeNameFromConfig(volConfig)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) RenameVolume(ctx context.Context, name, newName string) error {
+	driver, ok := g.driver.(WarmPoolDriver)
+	if !ok {
+		return g.unsupported("renaming volumes")
+	}
+	return g.call("RenameVolume", func() error { return driver.RenameVolume(ctx, name, newName) })
+}
+
+func (g *GuardedDriver) ResizeVolume(ctx context.Context, name string, sizeBytes uint64) error {
+	driver, ok := g.driver.(WarmPoolDriver)
+	if !ok {
+		return g.unsupported("resizing volumes")
+	}
+	return g.call("ResizeVolume", func() error { return driver.ResizeVolume(ctx, name, sizeBytes) })
+}
+
+func (g *GuardedDriver) ListOrphanedObjects(knownVolumes map[string]bool) (objects []string, err error) {
+	driver, ok := g.driver.(OrphanDetector)
+	if !ok {
+		return nil, g.unsupported("orphan detection")
+	}
+	err = g.call("ListOrphanedObjects", func() error {
+		objects, err = driver.ListOrphanedObjects(knownVolumes)
+		return err
+	})
+	return
+}
+
+func (g *GuardedDriver) DeleteOrphanedObject(object string) error {
+	driver, ok := g.driver.(OrphanDetector)
+	if !ok {
+		return g.unsupported("orphan detection")
+	}
+	return g.call("DeleteOrphanedObject", func() error { return driver.DeleteOrphanedObject(object) })
+}
+
+func (g *GuardedDriver) GetOwnership(name string) (owner string, created time.Time, err error) {
+	driver, ok := g.driver.(OwnershipDriver)
+	if !ok {
+		return "", time.Time{}, g.unsupported("volume owners")
+	}
+	err = g.call("GetOwnership", func() error {
+		owner, created, err = driver.GetOwnership(name)
+		return err
+	})
+	return
+}
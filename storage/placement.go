@@ -0,0 +1,25 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+// PlacementPreview describes where a volume would be created, without creating it.  Candidates
+// are listed in the order creation would be attempted; pools of equal priority are shuffled by
+// weight, so their order may differ between previews and from an actual create.
+type PlacementPreview struct {
+	Volume       string                `json:"volume"`
+	StorageClass string                `json:"storageClass"`
+	Candidates   []*PlacementCandidate `json:"candidates"`
+	Excluded     []*PlacementCandidate `json:"excluded,omitempty"`
+}
+
+// PlacementCandidate describes a storage pool that matches a volume's storage class, along with
+// the options the backend's driver would use when creating the volume in that pool.
+type PlacementCandidate struct {
+	Backend      string            `json:"backend"`
+	Pool         string            `json:"pool"`
+	Priority     int               `json:"priority"`
+	Weight       int               `json:"weight"`
+	InternalName string            `json:"internalName,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+	Reason       string            `json:"reason,omitempty"`
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import "time"
+
+// ReconciliationReport describes the differences between the volumes Trident knows about and the
+// objects that actually exist on its backends.
+type ReconciliationReport struct {
+	Time     string                   `json:"time"`
+	Cleanup  bool                     `json:"cleanup"`
+	Backends []*BackendReconciliation `json:"backends"`
+}
+
+// BackendReconciliation describes the differences found on a single backend.  Orphans are storage
+// objects that Trident created but no longer tracks, while ghosts are volumes Trident tracks that
+// no longer exist on the storage.  Kept orphans are those a cleanup left alone, as the storage
+// doesn't show that they were created by the backend long enough ago to be its leftovers.
+type BackendReconciliation struct {
+	Backend string   `json:"backend"`
+	Orphans []string `json:"orphans"`
+	Ghosts  []string `json:"ghosts"`
+	Cleaned []string `json:"cleaned,omitempty"`
+	Kept    []string `json:"kept,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// OrphanDetector is implemented by drivers that can find leftover storage objects other than
+// volumes, such as snapshots created for clones that no longer exist.
+type OrphanDetector interface {
+	// ListOrphanedObjects returns identifiers for leftover objects, given the internal names of
+	// the volumes Trident knows about on the backend.
+	ListOrphanedObjects(knownVolumes map[string]bool) ([]string, error)
+	// DeleteOrphanedObject removes an object returned by ListOrphanedObjects.
+	DeleteOrphanedObject(object string) error
+}
+
+// OwnershipDriver is implemented by drivers that record on the storage which backend created each
+// volume, and when, so that orphans may be deleted without touching the volumes of other Trident
+// instances sharing the same storage.  Drivers learn the owner from the context they create
+// volumes with.
+type OwnershipDriver interface {
+	// GetOwnership returns the owner recorded on a volume, named by its internal name, or on an
+	// object returned by ListOrphanedObjects, or an empty string if none was recorded, along with
+	// when it was created.
+	GetOwnership(name string) (owner string, created time.Time, err error)
+}
+
+// OrphanedSnapshotPrefix begins the identifiers OrphanDetector uses for snapshots that Trident
+// created only for the duration of an operation, such as cloning a volume.  Unlike volumes, these
+// hold no data of their own, so they may be reclaimed without being asked.
+const OrphanedSnapshotPrefix = "snapshot:"
+
+// Changes made to a volume by a resync with its backend
+const (
+	ResyncResized  = "resized"
+	ResyncMissing  = "missing"
+	ResyncRestored = "restored"
+	ResyncStored   = "stored"
+)
+
+// VolumeResync describes a correction made to a volume when its state in Trident, or in the
+// persistent store, had drifted from that on its backend, such as after a storage admin resized
+// or deleted it directly.
+type VolumeResync struct {
+	Volume  string `json:"volume"`
+	Backend string `json:"backend"`
+	Change  string `json:"change"`
+	// PreviousSize and Size are the volume's sizes, in bytes, before and after it was resized
+	PreviousSize string `json:"previousSize,omitempty"`
+	Size         string `json:"size,omitempty"`
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/netapp/trident/utils"
+)
+
+// scheduledSnapshotTimeFormat is appended to a schedule's name to name its snapshots, so that
+// they sort by the time they were taken.
+const scheduledSnapshotTimeFormat = "20060102-1504"
+
+// snapshotScheduleNameRegex limits schedule names to characters that every driver allows in
+// snapshot names.
+var snapshotScheduleNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,63}$`)
+
+// SnapshotScheduleConfig describes a schedule on which Trident takes snapshots of volumes.
+type SnapshotScheduleConfig struct {
+	Name string `json:"name"`
+	// Schedule is in crontab format, such as "0 * * * *" for hourly snapshots, and is in UTC.
+	Schedule string `json:"schedule"`
+	// Volumes and StorageClass select the volumes to snapshot.  Volumes created in the storage
+	// class after the schedule are included as well.
+	Volumes      []string `json:"volumes,omitempty"`
+	StorageClass string   `json:"storageClass,omitempty"`
+	// Retain is the number of the schedule's snapshots of each volume to keep.
+	Retain int `json:"retain"`
+}
+
+// SnapshotScheduleState records what a schedule has done, so that it survives restarts.
+type SnapshotScheduleState struct {
+	LastRun time.Time                        `json:"lastRun"`
+	Volumes map[string]*ScheduledVolumeState `json:"volumes,omitempty"`
+}
+
+// ScheduledVolumeState is the outcome of the most recent scheduled snapshot of a volume.
+type ScheduledVolumeState struct {
+	LastSnapshot string `json:"lastSnapshot,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type SnapshotSchedule struct {
+	Config *SnapshotScheduleConfig `json:"config"`
+	State  *SnapshotScheduleState  `json:"state"`
+}
+
+type SnapshotScheduleExternal struct {
+	Config  *SnapshotScheduleConfig `json:"config"`
+	State   *SnapshotScheduleState  `json:"state"`
+	NextRun time.Time               `json:"nextRun"`
+}
+
+// NewSnapshotSchedule returns a schedule that first runs at the next time after now that it matches.
+func NewSnapshotSchedule(config *SnapshotScheduleConfig, now time.Time) *SnapshotSchedule {
+	return &SnapshotSchedule{
+		Config: config,
+		State: &SnapshotScheduleState{
+			LastRun: now.UTC(),
+			Volumes: make(map[string]*ScheduledVolumeState),
+		},
+	}
+}
+
+// Validate checks that a schedule config is complete and that its schedule can be parsed.
+func (c *SnapshotScheduleConfig) Validate() error {
+
+	if !snapshotScheduleNameRegex.MatchString(c.Name) {
+		return fmt.Errorf("invalid snapshot schedule name %q; names must start with a letter, "+
+			"contain only letters, digits and hyphens, and be at most 64 characters", c.Name)
+	}
+	if _, err := utils.ParseCronSchedule(c.Schedule); err != nil {
+		return err
+	}
+	if len(c.Volumes) == 0 && c.StorageClass == "" {
+		return errors.New("a snapshot schedule must name volumes, a storage class, or both")
+	}
+	if c.Retain < 1 {
+		return fmt.Errorf("a snapshot schedule must retain at least one snapshot, not %d", c.Retain)
+	}
+	return nil
+}
+
+// NextRun returns the time after the schedule's last run at which it should run again.
+func (s *SnapshotSchedule) NextRun() time.Time {
+	cronSchedule, err := utils.ParseCronSchedule(s.Config.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	return cronSchedule.Next(s.State.LastRun)
+}
+
+// SnapshotName returns the name of the schedule's snapshot taken at a time.
+func (s *SnapshotSchedule) SnapshotName(t time.Time) string {
+	return s.Config.Name + "-" + t.UTC().Format(scheduledSnapshotTimeFormat)
+}
+
+// IsScheduledSnapshot checks whether a snapshot was taken by the schedule.
+func (s *SnapshotSchedule) IsScheduledSnapshot(snapshotName string) bool {
+	prefix := s.Config.Name + "-"
+	if !strings.HasPrefix(snapshotName, prefix) {
+		return false
+	}
+	_, err := time.Parse(scheduledSnapshotTimeFormat, strings.TrimPrefix(snapshotName, prefix))
+	return err == nil
+}
+
+// SelectsVolume checks whether the schedule takes snapshots of a volume.
+func (s *SnapshotSchedule) SelectsVolume(volume *Volume) bool {
+	if s.Config.StorageClass != "" && volume.Config.StorageClass == s.Config.StorageClass {
+		return true
+	}
+	for _, name := range s.Config.Volumes {
+		if name == volume.Config.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *SnapshotSchedule) ConstructExternal() *SnapshotScheduleExternal {
+
+	state := &SnapshotScheduleState{
+		LastRun: s.State.LastRun,
+		Volumes: make(map[string]*ScheduledVolumeState, len(s.State.Volumes)),
+	}
+	for name, volumeState := range s.State.Volumes {
+		volumeStateCopy := *volumeState
+		state.Volumes[name] = &volumeStateCopy
+	}
+
+	return &SnapshotScheduleExternal{
+		Config:  s.Config,
+		State:   state,
+		NextRun: s.NextRun(),
+	}
+}
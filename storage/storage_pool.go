@@ -8,6 +8,9 @@ import (
 	sa "github.com/netapp/trident/storage_attribute"
 )
 
+// DefaultPoolWeight is the placement weight of a pool whose backend config doesn't specify one.
+const DefaultPoolWeight = 1
+
 type Pool struct {
 	Name string
 	// A Trident storage pool can potentially satisfy more than one storage
@@ -15,6 +18,11 @@ type Pool struct {
 	StorageClasses []string
 	Backend        *Backend
 	Attributes     map[string]sa.Offer
+	// Pools with a higher priority are tried first when placing a volume.
+	// Among pools of equal priority, those with a higher weight are more
+	// likely to be chosen.
+	Priority int
+	Weight   int
 }
 
 func NewStoragePool(backend *Backend, name string) *Pool {
@@ -23,6 +31,7 @@ func NewStoragePool(backend *Backend, name string) *Pool {
 		StorageClasses: make([]string, 0),
 		Backend:        backend,
 		Attributes:     make(map[string]sa.Offer),
+		Weight:         DefaultPoolWeight,
 	}
 }
 
@@ -45,11 +54,26 @@ func (pool *Pool) RemoveStorageClass(class string) bool {
 	return found
 }
 
+// PoolCapacity describes the space in a storage pool, in bytes.  Drivers that can only tell how
+// much space is available leave the total and used space unset.
+type PoolCapacity struct {
+	TotalBytes     uint64 `json:"totalBytes,omitempty"`
+	UsedBytes      uint64 `json:"usedBytes,omitempty"`
+	AvailableBytes uint64 `json:"availableBytes"`
+}
+
 type PoolExternal struct {
 	Name           string   `json:"name"`
 	StorageClasses []string `json:"storageClasses"`
 	//TODO: can't have an interface here for unmarshalling
 	Attributes map[string]sa.Offer `json:"storageAttributes"`
+	Priority   int                 `json:"priority"`
+	Weight     int                 `json:"weight"`
+
+	// Number of Trident volumes in the pool
+	VolumeCount int `json:"volumeCount"`
+	// Capacity is only gathered from the driver on request
+	Capacity *PoolCapacity `json:"capacity,omitempty"`
 }
 
 func (pool *Pool) ConstructExternal() *PoolExternal {
@@ -57,6 +81,8 @@ func (pool *Pool) ConstructExternal() *PoolExternal {
 		Name:           pool.Name,
 		StorageClasses: pool.StorageClasses,
 		Attributes:     make(map[string]sa.Offer),
+		Priority:       pool.Priority,
+		Weight:         pool.Weight,
 	}
 	for k, v := range pool.Attributes {
 		external.Attributes[k] = v
@@ -0,0 +1,203 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// UsageSample is the capacity of one volume when usage was sampled, along with the namespace,
+// storage class and backend it is charged to.
+type UsageSample struct {
+	Volume           string `json:"volume"`
+	Namespace        string `json:"namespace,omitempty"`
+	StorageClass     string `json:"storageClass,omitempty"`
+	Backend          string `json:"backend"`
+	ProvisionedBytes uint64 `json:"provisionedBytes"`
+	// UsedBytes is the space the volume consumes on its storage, if its backend could report it
+	UsedBytes *uint64 `json:"usedBytes,omitempty"`
+}
+
+// UsageRecord is the capacity of every volume at one point in time.  Records are persisted so
+// that usage may be reported over periods longer than Trident has been running.
+type UsageRecord struct {
+	Time    time.Time      `json:"time"`
+	Samples []*UsageSample `json:"samples"`
+}
+
+// Key returns a unique identifier for the record, which sorts in time order.
+func (r *UsageRecord) Key() string {
+	return r.Time.UTC().Format("20060102T150405Z")
+}
+
+// UsageDriver is implemented by drivers that can report how much space their volumes consume,
+// which may be much less than their size for thinly provisioned volumes.
+type UsageDriver interface {
+	// GetVolumeUsedBytes returns the space consumed by a volume, named by its internal name.
+	GetVolumeUsedBytes(name string) (uint64, error)
+}
+
+// UsageReport is the capacity provisioned and used over a period, by namespace, storage class and
+// backend, for chargeback.
+type UsageReport struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Records is the number of samples of usage taken in the period
+	Records int `json:"records"`
+	// Hours is the part of the period covered by the samples, which excludes any time that
+	// Trident wasn't sampling, such as while it was stopped
+	Hours   float64             `json:"hours"`
+	Entries []*UsageReportEntry `json:"entries"`
+}
+
+// UsageReportEntry is the capacity provisioned and used over a report's period by the volumes of
+// one namespace and storage class on one backend.  Capacity is given in GiB-hours, the product of
+// a capacity and how long it was held, along with averages and peaks over the covered hours.
+type UsageReportEntry struct {
+	Namespace               string  `json:"namespace"`
+	StorageClass            string  `json:"storageClass"`
+	Backend                 string  `json:"backend"`
+	Volumes                 int     `json:"volumes"`
+	ProvisionedGiBHours     float64 `json:"provisionedGiBHours"`
+	UsedGiBHours            float64 `json:"usedGiBHours"`
+	AverageProvisionedBytes uint64  `json:"averageProvisionedBytes"`
+	AverageUsedBytes        uint64  `json:"averageUsedBytes"`
+	PeakProvisionedBytes    uint64  `json:"peakProvisionedBytes"`
+	PeakUsedBytes           uint64  `json:"peakUsedBytes"`
+	// UsedMeasured is false if the used space of some of the volumes couldn't be read, in which
+	// case they count as using none
+	UsedMeasured bool `json:"usedMeasured"`
+}
+
+// UsageReportColumns are the CSV column headings of a usage report, in the order of the values
+// returned by UsageReportEntry.Values.
+var UsageReportColumns = []string{
+	"namespace", "storageClass", "backend", "volumes", "provisionedGiBHours", "usedGiBHours",
+	"averageProvisionedBytes", "averageUsedBytes", "peakProvisionedBytes", "peakUsedBytes", "usedMeasured",
+}
+
+type usageKey struct {
+	namespace, storageClass, backend string
+}
+
+type usageTotal struct {
+	provisionedBytes, usedBytes uint64
+}
+
+// NewUsageReport aggregates the usage recorded between the start and end times.  Each record
+// stands for the period until the next one, but no longer than the sampling interval, so that
+// periods without samples aren't charged for.
+func NewUsageReport(records []*UsageRecord, start, end time.Time, interval time.Duration) *UsageReport {
+
+	inPeriod := make([]*UsageRecord, 0, len(records))
+	for _, record := range records {
+		if !record.Time.Before(start) && record.Time.Before(end) {
+			inPeriod = append(inPeriod, record)
+		}
+	}
+	sort.Slice(inPeriod, func(i, j int) bool { return inPeriod[i].Time.Before(inPeriod[j].Time) })
+
+	report := &UsageReport{Start: start, End: end, Records: len(inPeriod), Entries: make([]*UsageReportEntry, 0)}
+	entries := make(map[usageKey]*UsageReportEntry)
+	volumes := make(map[usageKey]map[string]bool)
+	var covered time.Duration
+
+	for i, record := range inPeriod {
+		until := record.Time.Add(interval)
+		if i+1 < len(inPeriod) && inPeriod[i+1].Time.Before(until) {
+			until = inPeriod[i+1].Time
+		}
+		if end.Before(until) {
+			until = end
+		}
+		span := until.Sub(record.Time)
+		covered += span
+
+		totals := make(map[usageKey]*usageTotal)
+		for _, sample := range record.Samples {
+			key := usageKey{sample.Namespace, sample.StorageClass, sample.Backend}
+			entry, ok := entries[key]
+			if !ok {
+				entry = &UsageReportEntry{
+					Namespace:    sample.Namespace,
+					StorageClass: sample.StorageClass,
+					Backend:      sample.Backend,
+					UsedMeasured: true,
+				}
+				entries[key] = entry
+				volumes[key] = make(map[string]bool)
+			}
+			volumes[key][sample.Volume] = true
+
+			total, ok := totals[key]
+			if !ok {
+				total = &usageTotal{}
+				totals[key] = total
+			}
+			total.provisionedBytes += sample.ProvisionedBytes
+			if sample.UsedBytes != nil {
+				total.usedBytes += *sample.UsedBytes
+			} else {
+				entry.UsedMeasured = false
+			}
+		}
+
+		// Each record's totals are added over its span, and may set a new peak
+		for key, total := range totals {
+			entry := entries[key]
+			entry.ProvisionedGiBHours += gibHours(total.provisionedBytes, span)
+			entry.UsedGiBHours += gibHours(total.usedBytes, span)
+			if total.provisionedBytes > entry.PeakProvisionedBytes {
+				entry.PeakProvisionedBytes = total.provisionedBytes
+			}
+			if total.usedBytes > entry.PeakUsedBytes {
+				entry.PeakUsedBytes = total.usedBytes
+			}
+		}
+	}
+
+	report.Hours = covered.Hours()
+	for key, entry := range entries {
+		entry.Volumes = len(volumes[key])
+		if report.Hours > 0 {
+			entry.AverageProvisionedBytes = uint64(entry.ProvisionedGiBHours / report.Hours * (1 << 30))
+			entry.AverageUsedBytes = uint64(entry.UsedGiBHours / report.Hours * (1 << 30))
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i], report.Entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.StorageClass != b.StorageClass {
+			return a.StorageClass < b.StorageClass
+		}
+		return a.Backend < b.Backend
+	})
+
+	return report
+}
+
+// Values returns the entry's fields as text, in the order of UsageReportColumns.
+func (e *UsageReportEntry) Values() []string {
+	return []string{
+		e.Namespace,
+		e.StorageClass,
+		e.Backend,
+		strconv.Itoa(e.Volumes),
+		strconv.FormatFloat(e.ProvisionedGiBHours, 'f', 3, 64),
+		strconv.FormatFloat(e.UsedGiBHours, 'f', 3, 64),
+		strconv.FormatUint(e.AverageProvisionedBytes, 10),
+		strconv.FormatUint(e.AverageUsedBytes, 10),
+		strconv.FormatUint(e.PeakProvisionedBytes, 10),
+		strconv.FormatUint(e.PeakUsedBytes, 10),
+		strconv.FormatBool(e.UsedMeasured),
+	}
+}
+
+func gibHours(bytes uint64, span time.Duration) float64 {
+	return float64(bytes) / (1 << 30) * span.Hours()
+}
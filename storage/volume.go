@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 type VolumeConfig struct {
@@ -28,6 +29,8 @@ type VolumeConfig struct {
 	AccessInfo                VolumeAccessInfo  `json:"accessInformation"`
 	BlockSize                 string            `json:"blockSize"`
 	FileSystem                string            `json:"fileSystem"`
+	FileSystemOwner           string            `json:"fileSystemOwner,omitempty"`
+	FileSystemMode            string            `json:"fileSystemMode,omitempty"`
 	Encryption                string            `json:"encryption"`
 	CloneSourceVolume         string            `json:"cloneSourceVolume"`
 	CloneSourceVolumeInternal string            `json:"cloneSourceVolumeInternal"`
@@ -35,23 +38,47 @@ type VolumeConfig struct {
 	SplitOnClone              string            `json:"splitOnClone"`
 	QoS                       string            `json:"qos,omitempty"`
 	QoSType                   string            `json:"type,omitempty"`
+	// OnDelete is what becomes of a clone on its storage when it is deleted from Trident, such
+	// as drivers.OnDeleteRetain; by default it is deleted
+	OnDelete string `json:"onDelete,omitempty"`
+	// ManagedQoSPolicy is a QoS policy that the volume's storage class gave limits for, so that
+	// Trident creates it if it is missing and deletes it once no volumes use it
+	ManagedQoSPolicy string `json:"managedQosPolicy,omitempty"`
+	// Namespace and RequestName identify what requested the volume in the frontend, such as a
+	// Kubernetes PVC, for drivers that name volumes from a template
+	Namespace   string `json:"namespace,omitempty"`
+	RequestName string `json:"requestName,omitempty"`
+	// WarmPool marks a volume created ahead of requests for its storage class's warm pool, which
+	// is renamed and handed out as the next suitable volume requested of the class
+	WarmPool bool `json:"warmPool,omitempty"`
+	// ReadOnly volumes are exported or mapped read-only by their storage, so that a dataset may be
+	// shared by many consumers without any of them being able to change it
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// CacheSourceVolume names the volume this volume caches, such as with an ONTAP FlexCache, which
+	// serves reads of the origin volume's data from wherever the cache is without copying all of it
+	CacheSourceVolume         string `json:"cacheSourceVolume,omitempty"`
+	CacheSourceVolumeInternal string `json:"cacheSourceVolumeInternal,omitempty"`
 }
 
 type VolumeAccessInfo struct {
 	IscsiAccessInfo
 	NfsAccessInfo
+	SmbAccessInfo
 }
 
 type IscsiAccessInfo struct {
-	IscsiTargetPortal    string  `json:"iscsiTargetPortal,omitempty"`
-	IscsiTargetIQN       string  `json:"iscsiTargetIqn,omitempty"`
-	IscsiLunNumber       int32   `json:"iscsiLunNumber,omitempty"`
-	IscsiInterface       string  `json:"iscsiInterface,omitempty"`
-	IscsiIgroup          string  `json:"iscsiIgroup,omitempty"`
-	IscsiVAGs            []int64 `json:"iscsiVags,omitempty"`
-	IscsiUsername        string  `json:"iscsiUsername,omitempty"`
-	IscsiInitiatorSecret string  `json:"iscsiInitiatorSecret,omitempty"`
-	IscsiTargetSecret    string  `json:"iscsiTargetSecret,omitempty"`
+	IscsiTargetPortal    string   `json:"iscsiTargetPortal,omitempty"`
+	IscsiPortals         []string `json:"iscsiPortals,omitempty"`
+	IscsiTargetIQN       string   `json:"iscsiTargetIqn,omitempty"`
+	IscsiLunNumber       int32    `json:"iscsiLunNumber,omitempty"`
+	IscsiLunSerial       string   `json:"iscsiLunSerial,omitempty"`
+	IscsiLunWWID         string   `json:"iscsiLunWwid,omitempty"`
+	IscsiInterface       string   `json:"iscsiInterface,omitempty"`
+	IscsiIgroup          string   `json:"iscsiIgroup,omitempty"`
+	IscsiVAGs            []int64  `json:"iscsiVags,omitempty"`
+	IscsiUsername        string   `json:"iscsiUsername,omitempty"`
+	IscsiInitiatorSecret string   `json:"iscsiInitiatorSecret,omitempty" sensitive:"true"`
+	IscsiTargetSecret    string   `json:"iscsiTargetSecret,omitempty" sensitive:"true"`
 }
 
 type NfsAccessInfo struct {
@@ -59,6 +86,11 @@ type NfsAccessInfo struct {
 	NfsPath     string `json:"nfsPath,omitempty"`
 }
 
+// SmbAccessInfo holds the UNC path, \\server\share\path, by which a volume is reached over SMB.
+type SmbAccessInfo struct {
+	SmbPath string `json:"smbPath,omitempty"`
+}
+
 func (c *VolumeConfig) Validate() error {
 	if c.Name == "" || c.Size == "" {
 		return fmt.Errorf("the following fields for \"Volume\" are mandatory: name and size")
@@ -72,6 +104,11 @@ func (c *VolumeConfig) Validate() error {
 	return nil
 }
 
+// IsCache returns whether the volume is a cache of another volume rather than a volume of its own.
+func (c *VolumeConfig) IsCache() bool {
+	return c.CacheSourceVolume != ""
+}
+
 func (c *VolumeConfig) ConstructClone(clone *VolumeConfig) {
 	buff := new(bytes.Buffer)
 	enc := gob.NewEncoder(buff)
@@ -85,6 +122,9 @@ type Volume struct {
 	Backend  string // Name of the storage backend
 	Pool     string // Name of the pool on which this volume was first provisioned
 	Orphaned bool   // An Orphaned volume isn't currently tracked by the storage backend
+
+	// BackendUUID identifies the storage backend even if its name changes
+	BackendUUID string
 }
 
 func NewVolume(conf *VolumeConfig, backend string, pool string, orphaned bool) *Volume {
@@ -96,11 +136,29 @@ func NewVolume(conf *VolumeConfig, backend string, pool string, orphaned bool) *
 	}
 }
 
+// Volume states reported by drivers in VolumeExternal.  Drivers may report other states their
+// storage has, such as a LUN that is offline for lack of space.
+const (
+	VolumeStateOnline     = "online"
+	VolumeStateOffline    = "offline"
+	VolumeStateRestricted = "restricted"
+)
+
+// VolumeSchemaVersion is the version of the structure of stored volumes.  It is raised, along with
+// a migration in the persistent store that upgrades volumes stored by earlier versions, whenever
+// that structure changes.
+const VolumeSchemaVersion = 1
+
 type VolumeExternal struct {
 	Config   *VolumeConfig
 	Backend  string `json:"backend"`
 	Pool     string `json:"pool"`
 	Orphaned bool   `json:"orphaned"`
+
+	BackendUUID string `json:"backendUUID,omitempty"`
+	// State is the state of the volume on its backend, such as VolumeStateOnline, if the driver
+	// read it from the storage
+	State string `json:"state,omitempty"`
 }
 
 func (v *VolumeExternal) GetCHAPSecretName() string {
@@ -117,9 +175,71 @@ func (v *Volume) ConstructExternal() *VolumeExternal {
 		Backend:  v.Backend,
 		Pool:     v.Pool,
 		Orphaned: v.Orphaned,
+
+		BackendUUID: v.BackendUUID,
+	}
+}
+
+// VolumePersistent is the form in which a volume is stored, which records the version of its
+// structure alongside the fields that the REST API shares.
+type VolumePersistent struct {
+	VolumeExternal
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+}
+
+func (v *Volume) ConstructPersistent() *VolumePersistent {
+	return &VolumePersistent{
+		VolumeExternal: *v.ConstructExternal(),
+		SchemaVersion:  VolumeSchemaVersion,
 	}
 }
 
+// Redacted returns a copy of the volume whose CHAP secrets and other sensitive details are
+// replaced by drivers.RedactedValue, so that it may be returned to clients.
+func (v *VolumeExternal) Redacted() *VolumeExternal {
+	redacted := *v
+	if v.Config != nil {
+		configCopy := *v.Config
+		drivers.RedactSensitiveFields(&configCopy)
+		redacted.Config = &configCopy
+	}
+	return &redacted
+}
+
+// BulkVolumeResult reports the outcome of one item in a bulk volume operation.
+type BulkVolumeResult struct {
+	Volume  string `json:"volume"`
+	Backend string `json:"backend,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CloneFanoutConfig requests many clones of one source volume, named <namePrefix>-1 through
+// <namePrefix>-<count>.  Unless a source snapshot is given, one snapshot of the source is taken
+// and shared by all of the clones.
+type CloneFanoutConfig struct {
+	SourceVolume   string `json:"sourceVolume"`
+	SourceSnapshot string `json:"sourceSnapshot,omitempty"`
+	NamePrefix     string `json:"namePrefix"`
+	Count          int    `json:"count"`
+	SplitOnClone   string `json:"splitOnClone,omitempty"`
+	OnDelete       string `json:"onDelete,omitempty"`
+	ReadOnly       bool   `json:"readOnly,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+}
+
+// CloneName returns the name of the clone with the given index, counting from one.
+func (c *CloneFanoutConfig) CloneName(index int) string {
+	return fmt.Sprintf("%s-%d", c.NamePrefix, index)
+}
+
+// CloneFanoutResult reports the snapshot a fan-out cloned from and the outcome of each clone, in
+// the order of their names.
+type CloneFanoutResult struct {
+	SourceVolume string              `json:"sourceVolume"`
+	Snapshot     string              `json:"snapshot,omitempty"`
+	Results      []*BulkVolumeResult `json:"results"`
+}
+
 // VolumeExternalWrapper is used to return volumes and errors via channels between goroutines
 type VolumeExternalWrapper struct {
 	Volume *VolumeExternal
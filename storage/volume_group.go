@@ -0,0 +1,105 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VolumeGroupConfig describes a group of related volumes, such as a database's data and log
+// volumes, that are created, snapshotted, cloned and deleted together.  A group is either created
+// from the configs of its volumes or cloned, volume for volume, from another group.
+type VolumeGroupConfig struct {
+	Name    string          `json:"name"`
+	Volumes []*VolumeConfig `json:"volumes,omitempty"`
+	// CloneSourceGroup names the group to clone, optionally from one of its group snapshots
+	CloneSourceGroup    string `json:"cloneSourceGroup,omitempty"`
+	CloneSourceSnapshot string `json:"cloneSourceSnapshot,omitempty"`
+}
+
+// Validate checks that a group config either lists volumes or names a group to clone.
+func (c *VolumeGroupConfig) Validate() error {
+
+	if c.Name == "" {
+		return errors.New("a volume group must have a name")
+	}
+	if c.CloneSourceGroup != "" {
+		if len(c.Volumes) > 0 {
+			return errors.New("a cloned volume group may not also list volumes")
+		}
+		return nil
+	}
+	if c.CloneSourceSnapshot != "" {
+		return errors.New("cloneSourceSnapshot requires cloneSourceGroup")
+	}
+	if len(c.Volumes) == 0 {
+		return errors.New("a volume group must list its volumes or name a group to clone")
+	}
+
+	names := make(map[string]bool, len(c.Volumes))
+	for _, volumeConfig := range c.Volumes {
+		if volumeConfig == nil {
+			return errors.New("missing volume config")
+		}
+		if err := volumeConfig.Validate(); err != nil {
+			return err
+		}
+		if names[volumeConfig.Name] {
+			return fmt.Errorf("volume %s is listed more than once", volumeConfig.Name)
+		}
+		names[volumeConfig.Name] = true
+	}
+	return nil
+}
+
+// CloneVolumeName returns the name of the clone of a source group's volume.  A volume named after
+// its group, such as db-data in group db, keeps its suffix, so its clone in group db2 is db2-data.
+// Other volumes are prefixed with the clone group's name.
+func (c *VolumeGroupConfig) CloneVolumeName(sourceVolumeName string) string {
+	if prefix := c.CloneSourceGroup + "-"; strings.HasPrefix(sourceVolumeName, prefix) {
+		return c.Name + "-" + strings.TrimPrefix(sourceVolumeName, prefix)
+	}
+	return c.Name + "-" + sourceVolumeName
+}
+
+// VolumeGroup is a group of volumes as saved in the persistent store.
+type VolumeGroup struct {
+	Name    string   `json:"name"`
+	Volumes []string `json:"volumes"`
+}
+
+// HasVolume checks whether a volume belongs to the group.
+func (g *VolumeGroup) HasVolume(volumeName string) bool {
+	for _, name := range g.Volumes {
+		if name == volumeName {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveVolume removes a volume from the group, returning whether it was a member.
+func (g *VolumeGroup) RemoveVolume(volumeName string) bool {
+	for i, name := range g.Volumes {
+		if name == volumeName {
+			g.Volumes = append(g.Volumes[:i:i], g.Volumes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type VolumeGroupExternal struct {
+	Name    string            `json:"name"`
+	Volumes []*VolumeExternal `json:"volumes"`
+}
+
+// VolumeGroupSnapshot is the result of snapshotting each of a group's volumes.  Consistent is
+// true if all the snapshots were taken at the same point in time.
+type VolumeGroupSnapshot struct {
+	Name       string                       `json:"name"`
+	Consistent bool                         `json:"consistent"`
+	Volumes    map[string]*SnapshotExternal `json:"volumes"`
+}
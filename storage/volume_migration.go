@@ -0,0 +1,89 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultMaxDisruptionSeconds bounds how long a migration's cutover may keep a volume unavailable
+// when the request doesn't say.
+const DefaultMaxDisruptionSeconds = 60
+
+type MigrationState string
+
+const (
+	// MigrationTransferring migrations are copying the volume's data to the destination backend.
+	MigrationTransferring MigrationState = "transferring"
+	// MigrationReady migrations have copied nearly all of the volume's data, and the remainder can
+	// be copied within the disruption window once the volume's users have stopped writing to it.
+	MigrationReady       MigrationState = "ready"
+	MigrationCuttingOver MigrationState = "cuttingOver"
+	MigrationCompleted   MigrationState = "completed"
+	MigrationFailed      MigrationState = "failed"
+)
+
+const (
+	// MigrationMethodReplication copies data with the destination driver's own replication, such
+	// as SnapMirror between ONTAP backends.
+	MigrationMethodReplication = "replication"
+	// MigrationMethodCopy copies data through Trident's host, which attaches both volumes.
+	MigrationMethodCopy = "copy"
+)
+
+// VolumeMigrationConfig requests that a volume be moved to another backend.
+type VolumeMigrationConfig struct {
+	Backend string `json:"backend"`
+	// Pool is the destination storage pool, which defaults to the first of the backend's pools
+	// that can hold volumes of the volume's storage class.
+	Pool string `json:"pool,omitempty"`
+	// MaxDisruptionSeconds is how long the final transfer during cutover may take.
+	MaxDisruptionSeconds int `json:"maxDisruptionSeconds,omitempty"`
+	// AutoCutover cuts over as soon as the migration is ready, rather than waiting to be told.
+	AutoCutover bool `json:"autoCutover,omitempty"`
+}
+
+func (c *VolumeMigrationConfig) Validate() error {
+	if c.Backend == "" {
+		return errors.New("a destination backend must be specified")
+	}
+	if c.MaxDisruptionSeconds < 0 {
+		return errors.New("maxDisruptionSeconds may not be negative")
+	}
+	return nil
+}
+
+// VolumeMigration records the progress of moving a volume to another backend.  The volume remains
+// on its source backend, and in use, until the migration cuts over.
+type VolumeMigration struct {
+	Volume               string         `json:"volume"`
+	SourceBackend        string         `json:"sourceBackend"`
+	SourceInternalName   string         `json:"sourceInternalName"`
+	Backend              string         `json:"backend"`
+	Pool                 string         `json:"pool"`
+	InternalName         string         `json:"internalName"`
+	Method               string         `json:"method"`
+	MaxDisruptionSeconds int            `json:"maxDisruptionSeconds"`
+	AutoCutover          bool           `json:"autoCutover,omitempty"`
+	State                MigrationState `json:"state"`
+	// Job tracks the migration's progress under the job REST endpoint.
+	Job string `json:"job"`
+	// Passes counts the transfers made so far, the first of which copies everything.
+	Passes              int     `json:"passes"`
+	LastTransferSeconds float64 `json:"lastTransferSeconds"`
+	// SourceRetained is set once the migration has cut over, as the original volume is kept on the
+	// source backend until the migration is deleted, because hosts may still refer to it.
+	SourceRetained bool   `json:"sourceRetained,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+// MaxDisruption returns the longest the final transfer during cutover may take.
+func (m *VolumeMigration) MaxDisruption() time.Duration {
+	return time.Duration(m.MaxDisruptionSeconds) * time.Second
+}
+
+// Active returns whether the migration is still underway, so the volume may not be changed.
+func (m *VolumeMigration) Active() bool {
+	return m.State != MigrationCompleted && m.State != MigrationFailed
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import "fmt"
+
+// VolumeNameMapping records the name a backend gave a volume on its storage.  Drivers may change
+// a volume's name in ways that can't be undone, such as ONTAP replacing hyphens with underscores,
+// so the mapping is what ties a volume found on the storage back to the volume it was created for.
+type VolumeNameMapping struct {
+	BackendUUID  string `json:"backendUUID"`
+	InternalName string `json:"internalName"`
+	Volume       string `json:"volume"`
+}
+
+// NewVolumeNameMapping returns the mapping of a volume's internal name on its backend.
+func NewVolumeNameMapping(vol *Volume) *VolumeNameMapping {
+	return &VolumeNameMapping{
+		BackendUUID:  vol.BackendUUID,
+		InternalName: vol.Config.InternalName,
+		Volume:       vol.Config.Name,
+	}
+}
+
+// Key returns a unique identifier for the mapping.  Internal names are unique within a backend.
+func (m *VolumeNameMapping) Key() string {
+	return VolumeNameMappingKey(m.BackendUUID, m.InternalName)
+}
+
+// VolumeNameMappingKey returns the key of the mapping of an internal name on a backend.
+func VolumeNameMappingKey(backendUUID, internalName string) string {
+	return fmt.Sprintf("%s.%s", backendUUID, internalName)
+}
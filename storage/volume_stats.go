@@ -0,0 +1,96 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storage
+
+import (
+	"time"
+)
+
+// VolumeStats is a sample of a volume's performance counters, as read from its storage.  The
+// counters are cumulative, so rates are found by comparing two samples.  Latencies are the total
+// time, in microseconds, spent serving operations of each kind.
+type VolumeStats struct {
+	Time               time.Time `json:"time"`
+	ReadOps            uint64    `json:"readOps"`
+	WriteOps           uint64    `json:"writeOps"`
+	OtherOps           uint64    `json:"otherOps"`
+	ReadBytes          uint64    `json:"readBytes"`
+	WriteBytes         uint64    `json:"writeBytes"`
+	ReadLatencyMicros  uint64    `json:"readLatencyMicros"`
+	WriteLatencyMicros uint64    `json:"writeLatencyMicros"`
+	OtherLatencyMicros uint64    `json:"otherLatencyMicros"`
+}
+
+// VolumeRates is a volume's average performance between two samples of its counters.  Latencies
+// are the mean time, in microseconds, taken by each operation.
+type VolumeRates struct {
+	IntervalSeconds      float64 `json:"intervalSeconds"`
+	ReadIOPS             float64 `json:"readIOPS"`
+	WriteIOPS            float64 `json:"writeIOPS"`
+	TotalIOPS            float64 `json:"totalIOPS"`
+	ReadBytesPerSecond   float64 `json:"readBytesPerSecond"`
+	WriteBytesPerSecond  float64 `json:"writeBytesPerSecond"`
+	ReadLatencyMicros    float64 `json:"readLatencyMicros"`
+	WriteLatencyMicros   float64 `json:"writeLatencyMicros"`
+	AverageLatencyMicros float64 `json:"averageLatencyMicros"`
+}
+
+// VolumeStatsReport is the latest sample of a volume's counters and, if two samples were taken,
+// its rates between them.  The error explains why a volume couldn't be sampled.
+type VolumeStatsReport struct {
+	Volume  string       `json:"volume"`
+	Backend string       `json:"backend"`
+	Stats   *VolumeStats `json:"stats,omitempty"`
+	Rates   *VolumeRates `json:"rates,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// StatsDriver is implemented by drivers that can read the performance counters of their volumes,
+// so that busy volumes, such as noisy neighbors, can be identified from Trident.
+type StatsDriver interface {
+	// GetVolumeStats samples the counters of a volume, named by its internal name.
+	GetVolumeStats(name string) (*VolumeStats, error)
+}
+
+// RatesSince returns the volume's average performance since an earlier sample.  A counter that
+// went backwards, such as after the storage was restarted, is treated as unchanged.
+func (s *VolumeStats) RatesSince(previous *VolumeStats) *VolumeRates {
+
+	interval := s.Time.Sub(previous.Time).Seconds()
+	if interval <= 0 {
+		return nil
+	}
+
+	readOps := counterDelta(s.ReadOps, previous.ReadOps)
+	writeOps := counterDelta(s.WriteOps, previous.WriteOps)
+	otherOps := counterDelta(s.OtherOps, previous.OtherOps)
+	readLatency := counterDelta(s.ReadLatencyMicros, previous.ReadLatencyMicros)
+	writeLatency := counterDelta(s.WriteLatencyMicros, previous.WriteLatencyMicros)
+	otherLatency := counterDelta(s.OtherLatencyMicros, previous.OtherLatencyMicros)
+
+	return &VolumeRates{
+		IntervalSeconds:      interval,
+		ReadIOPS:             readOps / interval,
+		WriteIOPS:            writeOps / interval,
+		TotalIOPS:            (readOps + writeOps + otherOps) / interval,
+		ReadBytesPerSecond:   counterDelta(s.ReadBytes, previous.ReadBytes) / interval,
+		WriteBytesPerSecond:  counterDelta(s.WriteBytes, previous.WriteBytes) / interval,
+		ReadLatencyMicros:    meanLatency(readLatency, readOps),
+		WriteLatencyMicros:   meanLatency(writeLatency, writeOps),
+		AverageLatencyMicros: meanLatency(readLatency+writeLatency+otherLatency, readOps+writeOps+otherOps),
+	}
+}
+
+func counterDelta(current, previous uint64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current - previous)
+}
+
+func meanLatency(latency, ops float64) float64 {
+	if ops == 0 {
+		return 0
+	}
+	return latency / ops
+}
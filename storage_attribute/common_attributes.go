@@ -4,17 +4,32 @@ package storageattribute
 
 const (
 	// Constants for integer storage category attributes
-	IOPS = "IOPS"
+	IOPS           = "IOPS"
+	VolumeHeadroom = "volumeHeadroom"
+	MinIOPS        = "minIOPS"
+	MaxIOPS        = "maxIOPS"
+	BurstIOPS      = "burstIOPS"
+	QoSMaxIOPS     = "qosMaxIOPS"
+	QoSMaxMBps     = "qosMaxMBps"
+	FlashPoolCache = "flashPoolCacheGiB"
 
 	// Constants for boolean storage category attributes
-	Snapshots  = "snapshots"
-	Clones     = "clones"
-	Encryption = "encryption"
+	Snapshots   = "snapshots"
+	Clones      = "clones"
+	Encryption  = "encryption"
+	Resize      = "resize"
+	QoS         = "qos"
+	Replication = "replication"
+	SnapshotDir = "snapshotDirectory"
 
 	// Constants for string list attributes
 	ProvisioningType = "provisioningType"
 	BackendType      = "backendType"
 	Media            = "media"
+	QoSTier          = "qosTier"
+	QoSPolicy        = "qosPolicy"
+	CachingPolicy    = "cachingPolicy"
+	PerformanceTier  = "performanceTier"
 
 	// Testing constants
 	RecoveryTest     = "recoveryTest"
@@ -27,19 +42,43 @@ const (
 	SSD    = "ssd"
 	Hybrid = "hybrid"
 
+	// Values for performanceTier
+	PerformanceTierPerformance = "performance"
+	PerformanceTierBalanced    = "balanced"
+	PerformanceTierCapacity    = "capacity"
+
 	RequiredStorage        = "requiredStorage" // deprecated, use additionalStoragePools
 	StoragePools           = "storagePools"
 	AdditionalStoragePools = "additionalStoragePools"
+	DefaultSize            = "defaultSize"
+	WarmPoolSize           = "warmPoolSize"
+	WarmVolumeSize         = "warmVolumeSize"
+	AllowedNamespaces      = "allowedNamespaces"
 )
 
 var attrTypes = map[string]Type{
 	IOPS:             intType,
+	VolumeHeadroom:   intType,
+	MinIOPS:          intType,
+	MaxIOPS:          intType,
+	BurstIOPS:        intType,
+	QoSMaxIOPS:       intType,
+	QoSMaxMBps:       intType,
+	FlashPoolCache:   intType,
 	Snapshots:        boolType,
 	Clones:           boolType,
 	Encryption:       boolType,
+	Resize:           boolType,
+	QoS:              boolType,
+	Replication:      boolType,
+	SnapshotDir:      boolType,
 	ProvisioningType: stringType,
 	BackendType:      stringType,
 	Media:            stringType,
+	QoSTier:          stringType,
+	QoSPolicy:        stringType,
+	CachingPolicy:    stringType,
+	PerformanceTier:  stringType,
 	RecoveryTest:     boolType,
 	UniqueOptions:    stringType,
 	TestingAttribute: boolType,
@@ -27,6 +27,8 @@ func TestMatches(t *testing.T) {
 			true},
 		{NewStringRequest("baz"), NewStringOffer("foo", "bar"),
 			false},
+		{NewStringRequest("baz"), NewAnyStringOffer(), true},
+		{NewIntRequest(5), NewAnyStringOffer(), false},
 		{NewIntRequest(5), NewStringOffer("foo", "bar"), false},
 		{NewIntRequest(5), NewBoolOffer(true), false},
 		{NewBoolRequest(false), NewIntOffer(0, 10), false},
@@ -52,6 +54,10 @@ func TestUnmarshalOffer(t *testing.T) {
 		ProvisioningType: &stringOffer{
 			Offers: []string{"foo", "bar"},
 		},
+		QoSPolicy: &stringOffer{
+			Offers: []string{},
+			Any:    true,
+		},
 	}
 	data, err := json.Marshal(offerMap)
 	if err != nil {
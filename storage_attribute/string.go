@@ -13,11 +13,23 @@ func NewStringOffer(offers ...string) Offer {
 	}
 }
 
+// NewAnyStringOffer returns an offer that matches every requested value, for attributes that
+// name something a pool can use or create on demand, such as a QoS policy.
+func NewAnyStringOffer() Offer {
+	return &stringOffer{
+		Offers: []string{},
+		Any:    true,
+	}
+}
+
 func (o *stringOffer) Matches(r Request) bool {
 	sr, ok := r.(*stringRequest)
 	if !ok {
 		return false
 	}
+	if o.Any {
+		return true
+	}
 	for _, s := range o.Offers {
 		if s == sr.Request {
 			return true
@@ -27,6 +39,9 @@ func (o *stringOffer) Matches(r Request) bool {
 }
 
 func (o *stringOffer) String() string {
+	if o.Any {
+		return "{Offers: *}"
+	}
 	return fmt.Sprintf("{Offers: %s}", strings.Join(o.Offers, ","))
 }
 
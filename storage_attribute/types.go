@@ -41,6 +41,7 @@ type boolRequest struct {
 
 type stringOffer struct {
 	Offers []string `json:"offer"`
+	Any    bool     `json:"any,omitempty"`
 }
 
 type stringRequest struct {
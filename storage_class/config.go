@@ -11,12 +11,18 @@ import (
 // UnmarshalJSON parses a JSON-formatted byte array into a storage class config struct.
 func (c *Config) UnmarshalJSON(data []byte) error {
 	var tmp struct {
-		Version         string              `json:"version"`
-		Name            string              `json:"name"`
-		Attributes      json.RawMessage     `json:"attributes,omitempty"`
-		Pools           map[string][]string `json:"storagePools,omitempty"`
-		RequiredStorage map[string][]string `json:"requiredStorage,omitempty"`
-		AdditionalPools map[string][]string `json:"additionalStoragePools,omitempty"`
+		Version           string              `json:"version"`
+		Name              string              `json:"name"`
+		Attributes        json.RawMessage     `json:"attributes,omitempty"`
+		Pools             map[string][]string `json:"storagePools,omitempty"`
+		RequiredStorage   map[string][]string `json:"requiredStorage,omitempty"`
+		AdditionalPools   map[string][]string `json:"additionalStoragePools,omitempty"`
+		DefaultSize       string              `json:"defaultSize,omitempty"`
+		WarmPoolSize      int                 `json:"warmPoolSize,omitempty"`
+		WarmVolumeSize    string              `json:"warmVolumeSize,omitempty"`
+		Aliases           []string            `json:"aliases,omitempty"`
+		AllowedNamespaces []string            `json:"allowedNamespaces,omitempty"`
+		Revision          int                 `json:"revision,omitempty"`
 	}
 	err := json.Unmarshal(data, &tmp)
 	if err != nil {
@@ -26,6 +32,12 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.Name = tmp.Name
 	c.Attributes, err = storageattribute.UnmarshalRequestMap(tmp.Attributes)
 	c.Pools = tmp.Pools
+	c.DefaultSize = tmp.DefaultSize
+	c.WarmPoolSize = tmp.WarmPoolSize
+	c.WarmVolumeSize = tmp.WarmVolumeSize
+	c.Aliases = tmp.Aliases
+	c.AllowedNamespaces = tmp.AllowedNamespaces
+	c.Revision = tmp.Revision
 
 	// Handle the renaming of "requiredStorage" to "additionalStoragePools"
 	if tmp.RequiredStorage != nil && tmp.AdditionalPools == nil {
@@ -40,16 +52,28 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 // MarshalJSON emits a storage class config struct as a JSON-formatted byte array.
 func (c *Config) MarshalJSON() ([]byte, error) {
 	var tmp struct {
-		Version         string              `json:"version"`
-		Name            string              `json:"name"`
-		Attributes      json.RawMessage     `json:"attributes,omitempty"`
-		Pools           map[string][]string `json:"storagePools,omitempty"`
-		AdditionalPools map[string][]string `json:"additionalStoragePools,omitempty"`
+		Version           string              `json:"version"`
+		Name              string              `json:"name"`
+		Attributes        json.RawMessage     `json:"attributes,omitempty"`
+		Pools             map[string][]string `json:"storagePools,omitempty"`
+		AdditionalPools   map[string][]string `json:"additionalStoragePools,omitempty"`
+		DefaultSize       string              `json:"defaultSize,omitempty"`
+		WarmPoolSize      int                 `json:"warmPoolSize,omitempty"`
+		WarmVolumeSize    string              `json:"warmVolumeSize,omitempty"`
+		Aliases           []string            `json:"aliases,omitempty"`
+		AllowedNamespaces []string            `json:"allowedNamespaces,omitempty"`
+		Revision          int                 `json:"revision,omitempty"`
 	}
 	tmp.Version = c.Version
 	tmp.Name = c.Name
 	tmp.Pools = c.Pools
 	tmp.AdditionalPools = c.AdditionalPools
+	tmp.DefaultSize = c.DefaultSize
+	tmp.WarmPoolSize = c.WarmPoolSize
+	tmp.WarmVolumeSize = c.WarmVolumeSize
+	tmp.Aliases = c.Aliases
+	tmp.AllowedNamespaces = c.AllowedNamespaces
+	tmp.Revision = c.Revision
 	attrs, err := storageattribute.MarshalRequestMap(c.Attributes)
 	if err != nil {
 		return nil, err
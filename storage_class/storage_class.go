@@ -5,6 +5,7 @@ package storageclass
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"sort"
 
 	log "github.com/sirupsen/logrus"
@@ -39,77 +40,133 @@ func NewFromPersistent(persistent *Persistent) *StorageClass {
 
 func (s *StorageClass) Matches(storagePool *storage.Pool) bool {
 
+	reasons := s.mismatchReasons(storagePool)
+
+	log.WithFields(log.Fields{
+		"match":        len(reasons) == 0,
+		"reasons":      reasons,
+		"pool":         storagePool.Name,
+		"storageClass": s.GetName(),
+	}).Debug("Result of pool match for storage class.")
+
+	return len(reasons) == 0
+}
+
+// mismatchReasons explains why a storage pool doesn't satisfy the storage class, returning no
+// reasons if it does.
+func (s *StorageClass) mismatchReasons(storagePool *storage.Pool) []string {
+
 	// Check additionalStoragePools first, since it can yield a match result by itself
 	if len(s.config.AdditionalPools) > 0 {
 		if storagePoolList, ok := s.config.AdditionalPools[storagePool.Backend.Name]; ok {
 			for _, storagePoolName := range storagePoolList {
 				if storagePoolName == storagePool.Name {
-					log.WithFields(log.Fields{
-						"storageClass": s.GetName(),
-						"pool":         storagePool.Name,
-					}).Debug("Matched by additionalStoragePools attribute.")
-					return true
+					return nil
 				}
 			}
 		}
 
 		// Handle the sub-case where additionalStoragePools is specified (but didn't match) and
 		// there are no attributes or storagePools specified in the storage class.  This should
-		// always return false.
+		// never match.
 		if len(s.config.Attributes) == 0 && len(s.config.Pools) == 0 {
-			log.WithFields(log.Fields{
-				"storageClass": s.GetName(),
-				"pool":         storagePool.Name,
-			}).Debug("Pool failed to match storage class additionalStoragePools attribute.")
-			return false
+			return []string{"not listed in additionalStoragePools"}
 		}
 	}
 
+	reasons := make([]string, 0)
+
 	// Attributes are used to narrow the pool selection.  Therefore if no attributes are
 	// specified, then all pools can match.  If one or more attributes are specified in the
 	// storage class, then all must match.
-	attributesMatch := true
-	for name, request := range s.config.Attributes {
-		if offer, ok := storagePool.Attributes[name]; !ok || !offer.Matches(request) {
-			log.WithFields(log.Fields{
-				"offer":        offer,
-				"request":      request,
-				"storageClass": s.GetName(),
-				"pool":         storagePool.Name,
-				"attribute":    name,
-				"found":        ok,
-			}).Debug("Attribute for storage pool failed to match storage class.")
-			attributesMatch = false
-			break
+	attributeNames := make([]string, 0, len(s.config.Attributes))
+	for name := range s.config.Attributes {
+		attributeNames = append(attributeNames, name)
+	}
+	sort.Strings(attributeNames)
+	for _, name := range attributeNames {
+		request := s.config.Attributes[name]
+		if offer, ok := storagePool.Attributes[name]; !ok {
+			reasons = append(reasons, fmt.Sprintf("does not offer %s", name))
+		} else if !offer.Matches(request) {
+			reasons = append(reasons, fmt.Sprintf("does not offer %s=%s", name, request.String()))
 		}
 	}
 
 	// The storagePools list is used to narrow the pool selection.  Therefore if no pools are
 	// specified, then all pools can match.  If one or more pools are listed in the storage
 	// class, then the pool must be in the list.
-	poolsMatch := true
 	if len(s.config.Pools) > 0 {
-		poolsMatch = false
+		listed := false
 		if storagePoolList, ok := s.config.Pools[storagePool.Backend.Name]; ok {
 			for _, storagePoolName := range storagePoolList {
 				if storagePoolName == storagePool.Name {
-					poolsMatch = true
+					listed = true
 				}
 			}
 		}
+		if !listed {
+			reasons = append(reasons, "not listed in storagePools")
+		}
 	}
 
-	result := attributesMatch && poolsMatch
+	return reasons
+}
 
-	log.WithFields(log.Fields{
-		"attributesMatch": attributesMatch,
-		"poolsMatch":      poolsMatch,
-		"match":           result,
-		"pool":            storagePool.Name,
-		"storageClass":    s.GetName(),
-	}).Debug("Result of pool match for storage class.")
+// Validate compares the storage class with every storage pool of the given backends.  It reports
+// why each pool that doesn't satisfy the class falls short, and which of the class's requested
+// attributes no pool offers at all, so that a class that can't be used is known when it's added
+// rather than when the first volume is requested.  Pools of offline backends are ignored.
+func (s *StorageClass) Validate(backends map[string]*storage.Backend) *Validation {
+
+	validation := &Validation{
+		UnmatchedPools:        make([]PoolMismatch, 0),
+		UnsatisfiedAttributes: make([]string, 0),
+	}
+	satisfied := make(map[string]bool)
 
-	return result
+	backendNames := make([]string, 0, len(backends))
+	for name, backend := range backends {
+		if backend.Online {
+			backendNames = append(backendNames, name)
+		}
+	}
+	sort.Strings(backendNames)
+
+	for _, backendName := range backendNames {
+		backend := backends[backendName]
+		poolNames := make([]string, 0, len(backend.Storage))
+		for poolName := range backend.Storage {
+			poolNames = append(poolNames, poolName)
+		}
+		sort.Strings(poolNames)
+
+		for _, poolName := range poolNames {
+			storagePool := backend.Storage[poolName]
+			for name, request := range s.config.Attributes {
+				if offer, ok := storagePool.Attributes[name]; ok && offer.Matches(request) {
+					satisfied[name] = true
+				}
+			}
+			if reasons := s.mismatchReasons(storagePool); len(reasons) > 0 {
+				validation.UnmatchedPools = append(validation.UnmatchedPools, PoolMismatch{
+					Backend: backend.Name,
+					Pool:    storagePool.Name,
+					Reasons: reasons,
+				})
+			}
+		}
+	}
+
+	for name, request := range s.config.Attributes {
+		if !satisfied[name] {
+			validation.UnsatisfiedAttributes = append(validation.UnsatisfiedAttributes,
+				fmt.Sprintf("%s=%s", name, request.String()))
+		}
+	}
+	sort.Strings(validation.UnsatisfiedAttributes)
+
+	return validation
 }
 
 // CheckAndAddBackend iterates through each of the storage pools
@@ -168,6 +225,69 @@ func (s *StorageClass) GetAdditionalStoragePools() map[string][]string {
 	return s.config.AdditionalPools
 }
 
+// GetDefaultSize returns the size of volumes of this class that are requested without one, or an
+// empty string if the backend's default size applies.
+func (s *StorageClass) GetDefaultSize() string {
+	return s.config.DefaultSize
+}
+
+// GetWarmPoolSize returns the number of volumes of this class to keep ready ahead of requests.
+func (s *StorageClass) GetWarmPoolSize() int {
+	return s.config.WarmPoolSize
+}
+
+// GetWarmVolumeSize returns the size of the volumes in this class's warm pool, or an empty string
+// if the backend's default size applies.
+func (s *StorageClass) GetWarmVolumeSize() string {
+	if s.config.WarmVolumeSize != "" {
+		return s.config.WarmVolumeSize
+	}
+	return s.config.DefaultSize
+}
+
+// GetAliases returns the other names by which volumes may request this class.
+func (s *StorageClass) GetAliases() []string {
+	return s.config.Aliases
+}
+
+// HasName returns true if the name is this class's name or one of its aliases.
+func (s *StorageClass) HasName(name string) bool {
+	if name == s.config.Name {
+		return true
+	}
+	for _, alias := range s.config.Aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAllowedNamespaces returns the namespace patterns from which volumes of this class may be
+// requested, or nil if there is no restriction.
+func (s *StorageClass) GetAllowedNamespaces() []string {
+	return s.config.AllowedNamespaces
+}
+
+// AllowsNamespace returns true if volumes of this class may be requested from the namespace.
+// Requests made outside any namespace, such as through Docker or the REST API, are allowed.
+func (s *StorageClass) AllowsNamespace(namespace string) bool {
+	if len(s.config.AllowedNamespaces) == 0 || namespace == "" {
+		return true
+	}
+	for _, pattern := range s.config.AllowedNamespaces {
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRevision returns the number of updates made to this class since it was added.
+func (s *StorageClass) GetRevision() int {
+	return s.config.Revision
+}
+
 func (s *StorageClass) GetStoragePoolsForProtocol(p config.Protocol) []*storage.Pool {
 	ret := make([]*storage.Pool, 0, len(s.pools))
 	// TODO:  Change this to work with indices of backends?
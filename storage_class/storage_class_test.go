@@ -3,6 +3,7 @@
 package storageclass
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -305,3 +306,84 @@ func TestSpecificBackends(t *testing.T) {
 		}
 	}
 }
+
+func TestValidate(t *testing.T) {
+	mockPools := tu.GetFakePools()
+	backends := make(map[string]*storage.Backend)
+	for _, name := range []string{"mock", "offline"} {
+		config, err := fake_driver.NewFakeStorageDriverConfigJSON(name, config.File, mockPools)
+		if err != nil {
+			t.Fatalf("Unable to construct config JSON.")
+		}
+		backend, err := factory.NewStorageBackendForConfig(config)
+		if err != nil {
+			t.Fatalf("Unable to construct backend using mock driver.")
+		}
+		backends[name] = backend
+	}
+	backends["offline"].Online = false
+
+	sc := New(&Config{
+		Name: "thick-snapshots",
+		Attributes: map[string]sa.Request{
+			sa.Snapshots:        sa.NewBoolRequest(true),
+			sa.ProvisioningType: sa.NewStringRequest("thick"),
+		},
+	})
+	validation := sc.Validate(backends)
+	if len(validation.UnsatisfiedAttributes) != 0 {
+		t.Errorf("Expected every attribute to be offered, got %v", validation.UnsatisfiedAttributes)
+	}
+	expected := []PoolMismatch{
+		{Backend: "mock", Pool: tu.FastThinOnly, Reasons: []string{"does not offer provisioningType=thick"}},
+		{Backend: "mock", Pool: tu.MediumOverlap, Reasons: []string{"does not offer provisioningType=thick"}},
+		{Backend: "mock", Pool: tu.SlowNoSnapshots, Reasons: []string{"does not offer snapshots=true"}},
+	}
+	if !reflect.DeepEqual(validation.UnmatchedPools, expected) {
+		t.Errorf("Expected unmatched pools %+v, got %+v", expected, validation.UnmatchedPools)
+	}
+
+	sc = New(&Config{
+		Name: "ssd",
+		Attributes: map[string]sa.Request{
+			sa.Snapshots: sa.NewBoolRequest(true),
+			sa.Media:     sa.NewStringRequest("ssd"),
+		},
+		Pools: map[string][]string{"mock": {tu.FastSmall}},
+	})
+	validation = sc.Validate(backends)
+	if !reflect.DeepEqual(validation.UnsatisfiedAttributes, []string{"media=ssd"}) {
+		t.Errorf("Expected media=ssd to be unsatisfied, got %v", validation.UnsatisfiedAttributes)
+	}
+	if len(validation.UnmatchedPools) != len(mockPools) {
+		t.Fatalf("Expected all %d pools to be unmatched, got %d", len(mockPools), len(validation.UnmatchedPools))
+	}
+	for _, mismatch := range validation.UnmatchedPools {
+		if mismatch.Reasons[0] != "does not offer media" {
+			t.Errorf("Expected pool %s to lack media, got %v", mismatch.Pool, mismatch.Reasons)
+		}
+		if listed := mismatch.Pool == tu.FastSmall; listed != (len(mismatch.Reasons) == 1) {
+			t.Errorf("Unexpected reasons for pool %s: %v", mismatch.Pool, mismatch.Reasons)
+		}
+	}
+}
+
+func TestAllowsNamespace(t *testing.T) {
+	open := New(&Config{Name: "open"})
+	if !open.AllowsNamespace("anything") {
+		t.Error("Expected a class without allowed namespaces to allow any namespace")
+	}
+
+	gold := New(&Config{Name: "gold", AllowedNamespaces: []string{"finance", "team-*"}})
+	for namespace, allowed := range map[string]bool{
+		"finance":   true,
+		"team-a":    true,
+		"team":      false,
+		"marketing": false,
+		"":          true,
+	} {
+		if gold.AllowsNamespace(namespace) != allowed {
+			t.Errorf("Expected namespace '%s' allowed to be %v", namespace, allowed)
+		}
+	}
+}
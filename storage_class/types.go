@@ -20,11 +20,62 @@ type Config struct {
 	Attributes      map[string]storageattribute.Request `json:"attributes,omitempty"`
 	Pools           map[string][]string                 `json:"storagePools,omitempty"`
 	AdditionalPools map[string][]string                 `json:"additionalStoragePools,omitempty"`
+	// DefaultSize is the size of volumes requested without one, such as by Docker
+	DefaultSize string `json:"defaultSize,omitempty"`
+	// WarmPoolSize is the number of volumes kept ready ahead of requests, so that a request can
+	// be bound by renaming one rather than waiting for a new volume
+	WarmPoolSize int `json:"warmPoolSize,omitempty"`
+	// WarmVolumeSize is the size of the volumes in the warm pool, which are grown as needed when
+	// claimed; by default it is the class's default size
+	WarmVolumeSize string `json:"warmVolumeSize,omitempty"`
+	// Aliases are other names by which volumes may request the class, such as names it replaces
+	Aliases []string `json:"aliases,omitempty"`
+	// AllowedNamespaces limits the class to volumes requested from the matching namespaces, such
+	// as Kubernetes namespaces, which may be given as patterns like "team-*"; by default any may
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// Revision counts the updates made to the class since it was added
+	Revision int `json:"revision,omitempty" hash:"ignore"`
 }
 
 type External struct {
 	Config       *Config
 	StoragePools map[string][]string `json:"storage"` // Backend -> list of StoragePools
+	Validation   *Validation         `json:"validation,omitempty"`
+	// WarmVolumes is the number of volumes in the class's warm pool ready to be claimed
+	WarmVolumes int `json:"warmVolumes,omitempty"`
+}
+
+// Update reports how updating a storage class changes where its volumes are provisioned.
+type Update struct {
+	StorageClass *External `json:"storageClass"`
+	// AddedPools lists the storage pools, by backend, that satisfy only the updated class
+	AddedPools map[string][]string `json:"addedPools"`
+	// RemovedPools lists the storage pools, by backend, that satisfy only the previous class
+	RemovedPools map[string][]string `json:"removedPools"`
+	// StrandedVolumes lists the existing volumes of the class on the removed pools, which are
+	// left in place but won't be joined by new volumes of the class
+	StrandedVolumes []string `json:"strandedVolumes"`
+	// UpdatedVolumes lists the existing volumes of the class that were changed to match it, such
+	// as by showing or hiding their .snapshot directories
+	UpdatedVolumes []string `json:"updatedVolumes"`
+	// FailedVolumes gives the reasons why existing volumes of the class couldn't be changed to
+	// match it
+	FailedVolumes map[string]string `json:"failedVolumes,omitempty"`
+}
+
+// Validation reports how a storage class compares with the storage pools of the online backends.
+type Validation struct {
+	// UnmatchedPools lists the pools that don't satisfy the class, with the reasons why
+	UnmatchedPools []PoolMismatch `json:"unmatchedPools"`
+	// UnsatisfiedAttributes lists the requested attributes that no pool offers, as name=value
+	UnsatisfiedAttributes []string `json:"unsatisfiedAttributes"`
+}
+
+// PoolMismatch explains why a storage pool doesn't satisfy a storage class.
+type PoolMismatch struct {
+	Backend string   `json:"backend"`
+	Pool    string   `json:"pool"`
+	Reasons []string `json:"reasons"`
 }
 
 // Persistent contains the minimal information needed to persist
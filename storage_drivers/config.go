@@ -18,6 +18,8 @@ const (
 	OntapNASQtreeStorageDriverName = "ontap-nas-economy"
 	OntapSANStorageDriverName      = "ontap-san"
 	SolidfireSANStorageDriverName  = "solidfire-san"
+	GCPNFSStorageDriverName        = "gcp-cvs"
+	GenericNFSStorageDriverName    = "generic-nfs"
 	FakeStorageDriverName          = "fake"
 )
 
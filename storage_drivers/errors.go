@@ -0,0 +1,78 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+// UnsupportedError indicates that a backend is incapable of performing the requested operation,
+// such as cloning on a backend without the necessary license.  Unlike other driver errors, retrying
+// the operation on the same backend is pointless, so callers may fail fast or choose a fallback.
+type UnsupportedError struct {
+	message string
+}
+
+func NewUnsupportedError(message string) error {
+	return &UnsupportedError{message}
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.message
+}
+
+// IsUnsupportedError returns true if the supplied error is an UnsupportedError.
+func IsUnsupportedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*UnsupportedError)
+	return ok
+}
+
+// RetryableError indicates a transient failure, such as a timeout or a busy resource, so the
+// operation may well succeed if it is attempted again later.
+type RetryableError struct {
+	message string
+}
+
+func NewRetryableError(message string) error {
+	return &RetryableError{message}
+}
+
+func (e *RetryableError) Error() string {
+	return e.message
+}
+
+// IsRetryableError returns true if the supplied error is a RetryableError.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*RetryableError)
+	return ok
+}
+
+// FatalError indicates a permanent failure, such as an invalid configuration or an exhausted quota,
+// that will recur until something other than the passage of time changes.
+type FatalError struct {
+	message string
+}
+
+func NewFatalError(message string) error {
+	return &FatalError{message}
+}
+
+func (e *FatalError) Error() string {
+	return e.message
+}
+
+// IsFatalError returns true if the supplied error is a FatalError.  An UnsupportedError is also
+// fatal, since the backend will never be able to perform the operation.
+func IsFatalError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err.(type) {
+	case *FatalError, *UnsupportedError:
+		return true
+	default:
+		return false
+	}
+}
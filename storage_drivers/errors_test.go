@@ -0,0 +1,34 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorClassification(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		err         error
+		retryable   bool
+		fatal       bool
+		unsupported bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain", err: errors.New("plain")},
+		{name: "retryable", err: NewRetryableError("busy"), retryable: true},
+		{name: "fatal", err: NewFatalError("invalid"), fatal: true},
+		{name: "unsupported", err: NewUnsupportedError("no clones"), fatal: true, unsupported: true},
+	} {
+		if IsRetryableError(test.err) != test.retryable {
+			t.Errorf("%s: expected IsRetryableError to be %v", test.name, test.retryable)
+		}
+		if IsFatalError(test.err) != test.fatal {
+			t.Errorf("%s: expected IsFatalError to be %v", test.name, test.fatal)
+		}
+		if IsUnsupportedError(test.err) != test.unsupported {
+			t.Errorf("%s: expected IsUnsupportedError to be %v", test.name, test.unsupported)
+		}
+	}
+}
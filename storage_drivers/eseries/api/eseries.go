@@ -107,6 +107,11 @@ func NewAPIClient(config ClientConfig) *Client {
 
 var volumeTags []VolumeTag
 
+// SetDebugTraceFlags replaces the debug trace flags used by this client.
+func (d *Client) SetDebugTraceFlags(flags map[string]bool) {
+	d.config.DebugTraceFlags = flags
+}
+
 // InvokeAPI makes a REST call to the Web Services Proxy. The body must be a marshaled JSON byte array (or nil).
 // The method is the HTTP verb (i.e. GET, POST, ...).  The resource path is appended to the base URL to identify
 // the desired server resource; it should start with '/'.
@@ -594,8 +599,9 @@ func (d Client) GetVolumeByRef(volumeRef string) (VolumeEx, error) {
 }
 
 // CreateVolume creates a volume (i.e. a LUN) on the array, and it returns the resulting VolumeEx structure.
+// The owner and mode for the volume's file system, if not empty, are saved in volume tags along with its type.
 func (d Client) CreateVolume(
-	name string, volumeGroupRef string, size uint64, mediaType, fstype string,
+	name string, volumeGroupRef string, size uint64, mediaType, fstype, fsOwner, fsMode string,
 ) (VolumeEx, error) {
 
 	if d.config.DebugTraceFlags["method"] {
@@ -620,6 +626,12 @@ func (d Client) CreateVolume(
 	// Copy static volume metadata and add fstype
 	tags := append([]VolumeTag(nil), volumeTags...)
 	tags = append(tags, VolumeTag{"fstype", fstype})
+	if fsOwner != "" {
+		tags = append(tags, VolumeTag{"fsowner", fsOwner})
+	}
+	if fsMode != "" {
+		tags = append(tags, VolumeTag{"fsmode", fsMode})
+	}
 
 	// Set up the volume create request
 	request := VolumeCreateRequest{
@@ -858,43 +870,200 @@ func (d Client) GetHostForIQN(iqn string) (HostEx, error) {
 	}
 
 	// Get hosts
+	hosts, err := d.GetHosts()
+	if err != nil {
+		return HostEx{}, err
+	}
+
+	// Find initiator with matching IQN
+	for _, host := range hosts {
+		if host.HasIQN(iqn) {
+
+			log.WithFields(log.Fields{
+				"Name": host.Label,
+				"IQN":  iqn,
+			}).Debug("Found host.")
+
+			return host, nil
+		}
+	}
+
+	// Nothing failed, so return an empty structure if we didn't find anything
+	log.WithField("IQN", iqn).Debug("No host found.")
+	return HostEx{}, nil
+}
+
+// GetHosts returns all Host objects defined on the array.
+func (d Client) GetHosts() ([]HostEx, error) {
+
+	if d.config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "GetHosts",
+			"Type":   "Client",
+		}
+		log.WithFields(fields).Debug(">>>> GetHosts")
+		defer log.WithFields(fields).Debug("<<<< GetHosts")
+	}
+
 	response, responseBody, err := d.InvokeAPI(nil, "GET", "/hosts")
 	if err != nil {
-		return HostEx{}, fmt.Errorf("API invocation failed. %v", err)
+		return nil, fmt.Errorf("API invocation failed. %v", err)
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return HostEx{}, Error{
+		return nil, Error{
 			Code:    response.StatusCode,
 			Message: "could not get hosts from array",
 		}
-		return HostEx{}, fmt.Errorf("could not get hosts from array; status code: %d", response.StatusCode)
 	}
 
 	// Parse JSON data
 	hosts := make([]HostEx, 0)
 	if err := json.Unmarshal(responseBody, &hosts); err != nil {
+		return nil, fmt.Errorf("could not parse host data: %s; %v", string(responseBody), err)
+	}
+
+	return hosts, nil
+}
+
+// EnsureHostInGroup ensures that a Host exists for the specified IQN and that it belongs to the specified HostGroup.
+// Unlike EnsureHostForIQN, the Host is named for its IQN rather than for the local system, so this method may be
+// used to define Hosts for other members of a cluster. A Host already placed in a different group is left alone,
+// since an admin may have put it there, but a warning is logged because volumes mapped to our group won't be
+// visible to it.
+func (d Client) EnsureHostInGroup(iqn string, hostGroup HostGroup) (HostEx, error) {
+
+	if d.config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "EnsureHostInGroup",
+			"Type":      "Client",
+			"iqn":       iqn,
+			"hostGroup": hostGroup.Label,
+		}
+		log.WithFields(fields).Debug(">>>> EnsureHostInGroup")
+		defer log.WithFields(fields).Debug("<<<< EnsureHostInGroup")
+	}
+
+	host, err := d.GetHostForIQN(iqn)
+	if err != nil {
+		return HostEx{}, fmt.Errorf("could not ensure host for IQN %s: %v", iqn, err)
+	}
+
+	// Create the host in the group if it doesn't exist
+	if host.HostRef == "" {
+		return d.CreateHost(d.createNameForIQN(iqn), iqn, d.config.HostType, hostGroup)
+	}
+
+	if host.ClusterRef == hostGroup.ClusterRef {
+		return host, nil
+	}
+
+	if d.IsRefValid(host.ClusterRef) {
+		log.WithFields(log.Fields{
+			"Name":      host.Label,
+			"IQN":       iqn,
+			"HostGroup": hostGroup.Label,
+		}).Warn("Host belongs to a different host group, so it cannot access volumes mapped to this group.")
+		return host, nil
+	}
+
+	// The host isn't in any group, so add it to ours
+	return d.AddHostToGroup(host, hostGroup)
+}
+
+// createNameForIQN picks a Host name from the unique part of an IQN, which is all that follows the last colon.
+func (d Client) createNameForIQN(iqn string) string {
+
+	name := iqn
+	if index := strings.LastIndex(iqn, ":"); index >= 0 && len(iqn) > index+1 {
+		name = iqn[index+1:]
+	}
+	if len(name) > maxNameLength {
+		name = name[len(name)-maxNameLength:]
+	}
+
+	return name
+}
+
+// AddHostToGroup moves a Host into the specified HostGroup and returns the updated Host structure.
+func (d Client) AddHostToGroup(host HostEx, hostGroup HostGroup) (HostEx, error) {
+
+	if d.config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "AddHostToGroup",
+			"Type":      "Client",
+			"host":      host.Label,
+			"hostGroup": hostGroup.Label,
+		}
+		log.WithFields(fields).Debug(">>>> AddHostToGroup")
+		defer log.WithFields(fields).Debug("<<<< AddHostToGroup")
+	}
+
+	request := HostUpdateRequest{GroupID: hostGroup.ClusterRef}
+
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		return HostEx{}, fmt.Errorf("could not marshal JSON request: %v; %v", request, err)
+	}
+
+	response, responseBody, err := d.InvokeAPI(jsonRequest, "POST", "/hosts/"+host.HostRef)
+	if err != nil {
+		return HostEx{}, fmt.Errorf("API invocation failed. %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return HostEx{}, Error{
+			Code:    response.StatusCode,
+			Message: fmt.Sprintf("could not add host %s to host group %s", host.Label, hostGroup.Label),
+		}
+	}
+
+	// Parse JSON data
+	updatedHost := HostEx{}
+	if err := json.Unmarshal(responseBody, &updatedHost); err != nil {
 		return HostEx{}, fmt.Errorf("could not parse host data: %s; %v", string(responseBody), err)
 	}
 
-	// Find initiator with matching IQN
-	for _, host := range hosts {
-		for _, f := range host.Initiators {
-			if f.NodeName.IoInterfaceType == "iscsi" && f.NodeName.IscsiNodeName == iqn {
+	log.WithFields(log.Fields{
+		"Name":       updatedHost.Label,
+		"HostRef":    updatedHost.HostRef,
+		"ClusterRef": updatedHost.ClusterRef,
+	}).Debug("Added host to group.")
 
-				log.WithFields(log.Fields{
-					"Name": host.Label,
-					"IQN":  iqn,
-				}).Debug("Found host.")
+	return updatedHost, nil
+}
 
-				return host, nil
-			}
+// DeleteHost deletes a Host from the array. The array refuses to delete a Host to which volumes are mapped.
+func (d Client) DeleteHost(host HostEx) error {
+
+	if d.config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "DeleteHost",
+			"Type":   "Client",
+			"host":   host.Label,
 		}
+		log.WithFields(fields).Debug(">>>> DeleteHost")
+		defer log.WithFields(fields).Debug("<<<< DeleteHost")
 	}
 
-	// Nothing failed, so return an empty structure if we didn't find anything
-	log.WithField("IQN", iqn).Debug("No host found.")
-	return HostEx{}, nil
+	response, _, err := d.InvokeAPI(nil, "DELETE", "/hosts/"+host.HostRef)
+	if err != nil {
+		return fmt.Errorf("API invocation failed. %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return Error{
+			Code:    response.StatusCode,
+			Message: fmt.Sprintf("could not delete host %s", host.Label),
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"Name":    host.Label,
+		"HostRef": host.HostRef,
+	}).Debug("Deleted host.")
+
+	return nil
 }
 
 // CreateHost creates a Host on the array. If a HostGroup is specified, the Host is placed in that group.
@@ -1177,8 +1346,9 @@ func (d Client) CreateHostGroup(name string) (HostGroup, error) {
 }
 
 // MapVolume maps a volume to the specified host and returns the resulting LUN mapping. If the volume is already mapped to the
-// specified host, either directly or to the containing host group, no action is taken. If the volume is mapped to a different host,
-// the method returns an error. Note that if the host is in a group, the volume will actually be mapped to the group instead of the
+// specified host, either directly or to the containing host group, no action is taken. If the volume is mapped directly to another
+// host in the same group, the mapping is moved to the group so that both hosts may use the volume. If the volume is mapped to a
+// different host outside the group, the method returns an error. Note that if the host is in a group, the volume will actually be mapped to the group instead of the
 // individual host.
 func (d Client) MapVolume(volume VolumeEx, host HostEx) (LUNMapping, error) {
 
@@ -1244,6 +1414,11 @@ func (d Client) MapVolume(volume VolumeEx, host HostEx) (LUNMapping, error) {
 
 			return mapping, nil
 
+		} else if mappedToGroupMember, mapping := d.volumeIsMappedToGroupMember(volume, host); mappedToGroupMember {
+
+			// Mapped to another host in the same group, so move the mapping to the group to share the volume
+			return d.MoveVolumeMapping(volume, mapping, host.ClusterRef)
+
 		} else {
 
 			// Mapped elsewhere, so return an error
@@ -1253,6 +1428,89 @@ func (d Client) MapVolume(volume VolumeEx, host HostEx) (LUNMapping, error) {
 	}
 }
 
+// volumeIsMappedToGroupMember checks whether a volume is mapped directly to some host in the host group containing the
+// specified host. If so, the method returns true with the associated mapping structure.
+func (d Client) volumeIsMappedToGroupMember(volume VolumeEx, host HostEx) (bool, LUNMapping) {
+
+	if !d.IsRefValid(host.ClusterRef) || len(volume.Mappings) == 0 {
+		return false, LUNMapping{}
+	}
+	mapping := volume.Mappings[0]
+	if mapping.Type != hostMappingType {
+		return false, LUNMapping{}
+	}
+
+	hosts, err := d.GetHosts()
+	if err != nil {
+		log.WithField("error", err).Warn("Could not get hosts to check volume mapping.")
+		return false, LUNMapping{}
+	}
+
+	for _, mappedHost := range hosts {
+		if mappedHost.HostRef == mapping.MapRef {
+			return mappedHost.ClusterRef == host.ClusterRef, mapping
+		}
+	}
+
+	return false, LUNMapping{}
+}
+
+// MoveVolumeMapping moves a volume mapping to a different host or host group, keeping the same LUN number, and
+// returns the resulting mapping. This is how a volume mapped to one host is shared with the rest of its group,
+// since E-series only supports a single mapping per volume.
+func (d Client) MoveVolumeMapping(volume VolumeEx, mapping LUNMapping, targetRef string) (LUNMapping, error) {
+
+	if d.config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "MoveVolumeMapping",
+			"Type":       "Client",
+			"volumeName": volume.Label,
+			"targetRef":  targetRef,
+		}
+		log.WithFields(fields).Debug(">>>> MoveVolumeMapping")
+		defer log.WithFields(fields).Debug("<<<< MoveVolumeMapping")
+	}
+
+	request := VolumeMappingMoveRequest{
+		TargetID:  targetRef,
+		LunNumber: mapping.LunNumber,
+	}
+
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		return LUNMapping{}, fmt.Errorf("could not marshal JSON request: %v; %v", request, err)
+	}
+
+	response, responseBody, err := d.InvokeAPI(jsonRequest, "POST",
+		"/volume-mappings/"+mapping.LunMappingRef+"/move")
+	if err != nil {
+		return LUNMapping{}, fmt.Errorf("API invocation failed. %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return LUNMapping{}, Error{
+			Code:    response.StatusCode,
+			Message: fmt.Sprintf("could not move mapping of volume %s", volume.Label),
+		}
+	}
+
+	// Parse JSON data
+	movedMapping := LUNMapping{}
+	if err := json.Unmarshal(responseBody, &movedMapping); err != nil {
+		return LUNMapping{}, fmt.Errorf("could not parse volume mapping data: %s; %v", string(responseBody), err)
+	}
+
+	log.WithFields(log.Fields{
+		"Name":      volume.Label,
+		"VolumeRef": volume.VolumeRef,
+		"MapRef":    movedMapping.MapRef,
+		"Type":      movedMapping.Type,
+		"LunNumber": movedMapping.LunNumber,
+	}).Debug("Volume mapping moved.")
+
+	return movedMapping, nil
+}
+
 // mapVolume maps a volume to a host with no checks for an existing mapping. If the host is in a host group, the volume is
 // mapped to the group instead. The resulting mapping structure is returned.
 func (d Client) mapVolume(volume VolumeEx, host HostEx) (LUNMapping, error) {
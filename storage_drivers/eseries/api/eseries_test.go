@@ -0,0 +1,243 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeProxy stands in for the Web Services Proxy, keeping the hosts and host groups of one array.
+type fakeProxy struct {
+	mutex   sync.Mutex
+	hosts   []HostEx
+	groups  []HostGroup
+	moves   []VolumeMappingMoveRequest
+	deleted []string
+	nextRef int
+}
+
+func (p *fakeProxy) newRef() string {
+	p.nextRef++
+	return fmt.Sprintf("%040d", p.nextRef)
+}
+
+func (p *fakeProxy) addHost(label, iqn, clusterRef string) HostEx {
+	host := HostEx{
+		HostRef:    p.newRef(),
+		ClusterRef: clusterRef,
+		Label:      label,
+		Initiators: []HostExInitiator{{NodeName: HostExScsiNodeName{IoInterfaceType: "iscsi", IscsiNodeName: iqn}}},
+	}
+	p.hosts = append(p.hosts, host)
+	return host
+}
+
+func (p *fakeProxy) host(ref string) *HostEx {
+	for i := range p.hosts {
+		if p.hosts[i].HostRef == ref {
+			return &p.hosts[i]
+		}
+	}
+	return nil
+}
+
+func (p *fakeProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/devmgr/v2/storage-systems/")
+	reply := func(status int, body interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+
+	switch {
+	case r.Method == "GET" && path == "/host-types":
+		reply(http.StatusOK, []HostType{{Name: "Linux DM-MP", Index: 28, Code: "LnxALUA"}})
+	case r.Method == "GET" && path == "/hosts":
+		reply(http.StatusOK, p.hosts)
+	case r.Method == "POST" && path == "/hosts":
+		var request HostCreateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		clusterRef := request.GroupID
+		if clusterRef == "" {
+			clusterRef = NullRef
+		}
+		reply(http.StatusCreated, p.addHost(request.Name, request.Ports[0].Port, clusterRef))
+	case r.Method == "POST" && strings.HasPrefix(path, "/hosts/"):
+		var request HostUpdateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		host := p.host(strings.TrimPrefix(path, "/hosts/"))
+		host.ClusterRef = request.GroupID
+		reply(http.StatusOK, host)
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/hosts/"):
+		p.deleted = append(p.deleted, strings.TrimPrefix(path, "/hosts/"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && path == "/host-groups":
+		reply(http.StatusOK, p.groups)
+	case r.Method == "POST" && path == "/host-groups":
+		var request HostGroupCreateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		group := HostGroup{ClusterRef: p.newRef(), Label: request.Name}
+		p.groups = append(p.groups, group)
+		reply(http.StatusCreated, group)
+	case r.Method == "POST" && strings.HasPrefix(path, "/volume-mappings/") && strings.HasSuffix(path, "/move"):
+		var request VolumeMappingMoveRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		p.moves = append(p.moves, request)
+		reply(http.StatusOK, LUNMapping{
+			LunMappingRef: strings.TrimSuffix(strings.TrimPrefix(path, "/volume-mappings/"), "/move"),
+			LunNumber:     request.LunNumber,
+			MapRef:        request.TargetID,
+			Type:          hostGroupMappingType,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newFakeProxyClient returns a client of a fake Web Services Proxy, whose array has the host group
+// the client manages.  The caller must close the server.
+func newFakeProxyClient(t *testing.T) (*Client, *fakeProxy, *httptest.Server) {
+	proxy := &fakeProxy{}
+	proxy.groups = []HostGroup{{ClusterRef: proxy.newRef(), Label: "trident"}}
+	server := httptest.NewServer(proxy)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Unable to parse the proxy URL: ", err)
+	}
+	client := NewAPIClient(ClientConfig{
+		WebProxyHostname: u.Hostname(),
+		WebProxyPort:     u.Port(),
+		WebProxyUseHTTP:  true,
+		AccessGroup:      "trident",
+		HostType:         "linux_dm_mp",
+	})
+	return client, proxy, server
+}
+
+func TestEnsureHostInGroup(t *testing.T) {
+	client, proxy, server := newFakeProxyClient(t)
+	defer server.Close()
+	group := proxy.groups[0]
+	other := HostGroup{ClusterRef: proxy.newRef(), Label: "other"}
+	proxy.groups = append(proxy.groups, other)
+
+	ungrouped := proxy.addHost("node2", "iqn.1994-05.com.redhat:node2", NullRef)
+	elsewhere := proxy.addHost("node3", "iqn.1994-05.com.redhat:node3", other.ClusterRef)
+
+	// A node without a host gets one named for its IQN, in the group
+	host, err := client.EnsureHostInGroup("iqn.1994-05.com.redhat:node1", group)
+	if err != nil {
+		t.Fatal("Unable to ensure host: ", err)
+	}
+	if host.Label != "node1" || host.ClusterRef != group.ClusterRef {
+		t.Errorf("Expected host node1 in group %s, got %+v.", group.Label, host)
+	}
+
+	// A host that isn't in any group is added to it
+	host, err = client.EnsureHostInGroup("iqn.1994-05.com.redhat:node2", group)
+	if err != nil {
+		t.Fatal("Unable to ensure host: ", err)
+	}
+	if host.HostRef != ungrouped.HostRef || host.ClusterRef != group.ClusterRef {
+		t.Errorf("Expected host node2 to be added to group %s, got %+v.", group.Label, host)
+	}
+
+	// A host an admin put in another group is left there
+	host, err = client.EnsureHostInGroup("iqn.1994-05.com.redhat:node3", group)
+	if err != nil {
+		t.Fatal("Unable to ensure host: ", err)
+	}
+	if host.ClusterRef != elsewhere.ClusterRef || proxy.host(elsewhere.HostRef).ClusterRef != other.ClusterRef {
+		t.Errorf("Expected host node3 to stay in group %s, got %+v.", other.Label, host)
+	}
+
+	// A host already in the group is unchanged
+	hostCount := len(proxy.hosts)
+	if _, err = client.EnsureHostInGroup("iqn.1994-05.com.redhat:node1", group); err != nil {
+		t.Fatal("Unable to ensure host: ", err)
+	}
+	if len(proxy.hosts) != hostCount {
+		t.Errorf("Expected no new host, got %d hosts.", len(proxy.hosts))
+	}
+}
+
+func TestCreateNameForIQN(t *testing.T) {
+	client, _, server := newFakeProxyClient(t)
+	defer server.Close()
+
+	for iqn, expected := range map[string]string{
+		"iqn.1994-05.com.redhat:node1":                              "node1",
+		"iqn.1994-05.com.redhat:":                                   "iqn.1994-05.com.redhat:",
+		"iqn.1994-05.com.redhat:0123456789abcdef0123456789abcdef01": "456789abcdef0123456789abcdef01",
+	} {
+		if name := client.createNameForIQN(iqn); name != expected {
+			t.Errorf("Expected name %s for %s, got %s.", expected, iqn, name)
+		}
+	}
+}
+
+func TestDeleteHost(t *testing.T) {
+	client, proxy, server := newFakeProxyClient(t)
+	defer server.Close()
+	host := proxy.addHost("node1", "iqn.1994-05.com.redhat:node1", proxy.groups[0].ClusterRef)
+
+	if err := client.DeleteHost(host); err != nil {
+		t.Fatal("Unable to delete host: ", err)
+	}
+	if len(proxy.deleted) != 1 || proxy.deleted[0] != host.HostRef {
+		t.Errorf("Expected host %s to be deleted, got %v.", host.HostRef, proxy.deleted)
+	}
+}
+
+func TestMapVolumeSharesWithGroup(t *testing.T) {
+	client, proxy, server := newFakeProxyClient(t)
+	defer server.Close()
+	group := proxy.groups[0]
+	node1 := proxy.addHost("node1", "iqn.1994-05.com.redhat:node1", group.ClusterRef)
+	node2 := proxy.addHost("node2", "iqn.1994-05.com.redhat:node2", group.ClusterRef)
+	outsider := proxy.addHost("node3", "iqn.1994-05.com.redhat:node3", NullRef)
+
+	volume := VolumeEx{
+		Label:     "vol1",
+		VolumeRef: "volumeRef1",
+		IsMapped:  true,
+		Mappings: []LUNMapping{{
+			LunMappingRef: "mappingRef1",
+			LunNumber:     3,
+			VolumeRef:     "volumeRef1",
+			MapRef:        node1.HostRef,
+			Type:          hostMappingType,
+		}},
+	}
+
+	// A volume mapped to another host in the group is moved to the group, keeping its LUN number
+	mapping, err := client.MapVolume(volume, node2)
+	if err != nil {
+		t.Fatal("Unable to map volume: ", err)
+	}
+	if mapping.MapRef != group.ClusterRef || mapping.Type != hostGroupMappingType || mapping.LunNumber != 3 {
+		t.Errorf("Expected LUN 3 mapped to group %s, got %+v.", group.Label, mapping)
+	}
+	if len(proxy.moves) != 1 || proxy.moves[0].TargetID != group.ClusterRef {
+		t.Errorf("Expected the mapping to be moved to the group, got %+v.", proxy.moves)
+	}
+
+	// A volume mapped to a host outside the group can't be shared
+	volume.Mappings[0].MapRef = outsider.HostRef
+	if _, err = client.MapVolume(volume, node2); err == nil {
+		t.Error("Expected a volume mapped outside the group to be refused.")
+	}
+	if len(proxy.moves) != 1 {
+		t.Errorf("Expected no further moves, got %+v.", proxy.moves)
+	}
+}
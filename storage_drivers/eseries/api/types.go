@@ -79,6 +79,10 @@ type VolumeGroupEx struct {
 	Label          string `json:"label"`
 	FreeSpace      string `json:"freeSpace"`      // Documentation says this is an int but really it is a string!
 	DriveMediaType string `json:"driveMediaType"` // 'hdd', 'ssd'
+
+	// Also strings, like FreeSpace
+	TotalRaidedSpace string `json:"totalRaidedSpace"`
+	UsedSpace        string `json:"usedSpace"`
 }
 
 // Functions to allow sorting storage pools by free space
@@ -117,6 +121,7 @@ func (t VolumeTag) Equals(otherTag VolumeTag) bool {
 type VolumeEx struct {
 	IsOffline      bool         `json:"offline"`
 	Label          string       `json:"label"`
+	WorldWideName  string       `json:"worldWideName"`
 	VolumeSize     string       `json:"capacity"`
 	SegmentSize    int          `json:"segmentSize"`
 	VolumeRef      string       `json:"volumeRef"`
@@ -153,6 +158,20 @@ type HostEx struct {
 	Initiators    []HostExInitiator `json:"initiators"`
 }
 
+// HasIQN returns true if the Host has an iSCSI initiator with the specified IQN.
+func (h HostEx) HasIQN(iqn string) bool {
+	for _, initiator := range h.Initiators {
+		if initiator.NodeName.IoInterfaceType == "iscsi" && initiator.NodeName.IscsiNodeName == iqn {
+			return true
+		}
+	}
+	return false
+}
+
+type HostUpdateRequest struct {
+	GroupID string `json:"groupId"`
+}
+
 type HostExInitiator struct {
 	InitiatorRef string             `json:"initiatorRef"`
 	NodeName     HostExScsiNodeName `json:"nodeName"`
@@ -180,6 +199,11 @@ type VolumeMappingCreateRequest struct {
 	LunNumber        int    `json:"lun,omitempty"`
 }
 
+type VolumeMappingMoveRequest struct {
+	TargetID  string `json:"targetId"`
+	LunNumber int    `json:"lun"`
+}
+
 type LUNMapping struct {
 	LunMappingRef string `json:"lunMappingRef"`
 	LunNumber     int    `json:"lun"`
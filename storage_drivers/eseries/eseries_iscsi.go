@@ -5,6 +5,7 @@
 package eseries
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -50,6 +51,15 @@ func (d *SANStorageDriver) Name() string {
 	return drivers.EseriesIscsiStorageDriverName
 }
 
+func (d *SANStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *SANStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.API.SetDebugTraceFlags(flags)
+}
+
 func (d *SANStorageDriver) Protocol() string {
 	return "iscsi"
 }
@@ -144,9 +154,14 @@ func (d *SANStorageDriver) Initialize(
 		}).Info("Controller serial numbers.")
 	}
 
+	if d.Config.ISCSIDiscovery == drivers.ISCSIDiscoveryStatic && context != trident.ContextDocker {
+		log.Warning("The iscsiDiscovery setting applies only to Docker hosts; Kubernetes nodes run " +
+			"discovery at the volume's portals themselves.")
+	}
+
 	if context == trident.ContextDocker {
 		// Make sure this host is logged into the E-series iSCSI target
-		err = utils.EnsureISCSISession(d.Config.HostDataIP)
+		err = d.ensureISCSISession()
 		if err != nil {
 			return fmt.Errorf("could not establish iSCSI session: %v", err)
 		}
@@ -156,6 +171,12 @@ func (d *SANStorageDriver) Initialize(
 		if err != nil {
 			return err
 		}
+	} else if len(d.Config.HostIQNs) > 0 {
+		// Make sure the host group contains exactly the cluster's nodes
+		err = d.reconcileHostGroup()
+		if err != nil {
+			return err
+		}
 	}
 
 	d.initialized = true
@@ -214,6 +235,10 @@ func (d *SANStorageDriver) populateConfigurationDefaults(config *drivers.ESeries
 			return fmt.Errorf("invalid config value for default volume size: %v", err)
 		}
 	}
+	if err := drivers.ValidateFilesystemPermissions(
+		config.DriverContext, config.FileSystemOwner, config.FileSystemMode); err != nil {
+		return err
+	}
 
 	log.WithFields(log.Fields{
 		"StoragePrefix":         *config.StoragePrefix,
@@ -251,10 +276,27 @@ func (d *SANStorageDriver) validate() error {
 	return nil
 }
 
+// GetVolumeSize returns the size of the volume that Create makes for a requested size.
+func (d *SANStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, d.Config.CommonStorageDriverConfig)
+	if sizeBytes < MinimumVolumeSizeBytes {
+		return 0, fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
+			sizeBytes, MinimumVolumeSizeBytes)
+	}
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
 // Create is called by Docker to create a container volume. Besides the volume name, a few optional parameters such as size
 // and disk media type may be provided in the opts map. If more than one pool on the storage controller can satisfy the request, the
 // one with the most free space is selected.
-func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+func (d *SANStorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -267,13 +309,9 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		defer log.WithFields(fields).Debug("<<<< Create")
 	}
 
-	if sizeBytes == 0 {
-		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
-		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
-	}
-	if sizeBytes < MinimumVolumeSizeBytes {
-		return fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
-			sizeBytes, MinimumVolumeSizeBytes)
+	sizeBytes, err := d.GetVolumeSize(sizeBytes)
+	if err != nil {
+		return err
 	}
 
 	// Get media type, or default to "hdd" if not specified
@@ -287,6 +325,11 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 	default:
 		return fmt.Errorf("unsupported fileSystemType option: %s", fstype)
 	}
+	fsOwner := utils.GetV(opts, "fileSystemOwner", d.Config.FileSystemOwner)
+	fsMode := utils.GetV(opts, "fileSystemMode", d.Config.FileSystemMode)
+	if err := utils.ValidateFilesystemPermissions(fsOwner, fsMode); err != nil {
+		return err
+	}
 
 	// Get pool name, or default to all pools if not specified
 	poolName := utils.GetV(opts, "pool", "")
@@ -305,7 +348,7 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 	pool := pools[0]
 
 	// Create the volume
-	vol, err := d.API.CreateVolume(name, pool.VolumeGroupRef, sizeBytes, mediaType, fstype)
+	vol, err := d.API.CreateVolume(name, pool.VolumeGroupRef, sizeBytes, mediaType, fstype, fsOwner, fsMode)
 	if err != nil {
 		return fmt.Errorf("could not create volume %s: %v", name, err)
 	}
@@ -322,7 +365,7 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 }
 
 // Destroy is called by Docker to delete a container volume.
-func (d *SANStorageDriver) Destroy(name string) error {
+func (d *SANStorageDriver) Destroy(ctx context.Context, name string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -399,6 +442,11 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		defer log.WithFields(fields).Debug("<<<< Attach")
 	}
 
+	// Ensure the host will assemble multipath devices from the volume's paths
+	if err := drivers.CheckHostMultipath(d.Config.CommonStorageDriverConfig, utils.ESeriesMultipathDevice); err != nil {
+		return err
+	}
+
 	// Get the volume
 	vol, err := d.API.GetVolume(name)
 	if err != nil {
@@ -408,13 +456,17 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		return fmt.Errorf("could not find volume %s", name)
 	}
 
-	// Get the fstype
-	fstype := ""
+	// Get the fstype, and the owner and mode to give a new file system
+	fstype, fsOwner, fsMode := "", "", ""
 	for _, tag := range vol.VolumeTags {
-		if tag.Key == "fstype" {
+		switch tag.Key {
+		case "fstype":
 			fstype = tag.Value
 			log.WithFields(log.Fields{"LUN": name, "fstype": fstype}).Debug("Found LUN fstype.")
-			break
+		case "fsowner":
+			fsOwner = tag.Value
+		case "fsmode":
+			fsMode = tag.Value
 		}
 	}
 	if fstype == "" {
@@ -435,12 +487,12 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 	}
 
 	// Rescan and wait for the device(s) to appear
-	err = utils.RescanTargetAndWaitForDevice(mapping.LunNumber, iSCSINodeName)
+	err = utils.RescanTargetAndWaitForDevice(mapping.LunNumber, iSCSINodeName, d.Config.Timeouts.Mount)
 	if err != nil {
 		return fmt.Errorf("could not find iSCSI device: %v", err)
 	}
 
-	err = utils.WaitForMultiPathDevice(mapping.LunNumber, iSCSINodeName)
+	err = utils.WaitForMultiPathDevice(mapping.LunNumber, iSCSINodeName, d.Config.Timeouts.Mount)
 	if err != nil {
 		return err
 	}
@@ -473,12 +525,14 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 	}
 
 	// Put a filesystem on it if there isn't one already there
+	formatted := false
 	if deviceInfo.Filesystem == "" {
 		log.WithFields(log.Fields{"LUN": name, "fstype": fstype}).Debug("Formatting LUN.")
 		err := utils.FormatVolume(devicePath, fstype)
 		if err != nil {
 			return fmt.Errorf("error formatting LUN %v, device %v: %v", name, deviceToUse, err)
 		}
+		formatted = true
 	} else if deviceInfo.Filesystem != fstype {
 		log.WithFields(log.Fields{
 			"LUN":             name,
@@ -496,9 +550,36 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 			mountpoint, err)
 	}
 
+	// Give a new file system the owner and mode requested when the volume was created
+	if formatted {
+		if err = utils.SetFilesystemPermissions(mountpoint, fsOwner, fsMode); err != nil {
+			utils.Umount(mountpoint)
+			return fmt.Errorf("could not set permissions of volume %s: %v", name, err)
+		}
+	}
+
 	return nil
 }
 
+// ensureISCSISession logs this host in to the array's iSCSI target, through discovery at the host
+// data IP or, if the backend forbids discovery, at each of the target's IPv4 portals.
+func (d *SANStorageDriver) ensureISCSISession() error {
+
+	if d.Config.ISCSIDiscovery != drivers.ISCSIDiscoveryStatic {
+		return utils.EnsureISCSISession(d.Config.HostDataIP)
+	}
+
+	iSCSINodeName, iSCSIInterfaces, err := d.getISCSITargetInfo()
+	if err != nil {
+		return err
+	}
+	portals := make([]string, 0, len(iSCSIInterfaces))
+	for _, iSCSIInterface := range iSCSIInterfaces {
+		portals = append(portals, strings.Split(iSCSIInterface, ":")[0])
+	}
+	return utils.EnsureISCSISessionStatic(iSCSINodeName, d.Config.HostDataIP, portals)
+}
+
 func (d *SANStorageDriver) getISCSITargetInfo() (iSCSINodeName string, iSCSIInterfaces []string, returnError error) {
 
 	targetSettings, err := d.API.GetTargetSettings()
@@ -544,6 +625,62 @@ func (d *SANStorageDriver) CreateHost() (api.HostEx, error) {
 	return host, nil
 }
 
+// reconcileHostGroup ensures a Host exists in the access group for each IQN in the config, and it deletes any
+// other Hosts in the group, since those belong to nodes that have left the cluster. Volumes mapped to the group
+// are thus visible to every node, which lets clustered applications share them. A Host with volumes mapped to it
+// directly is never deleted by the array, so such Hosts are left behind with a warning.
+func (d *SANStorageDriver) reconcileHostGroup() error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "reconcileHostGroup", "Type": "SANStorageDriver"}
+		log.WithFields(fields).Debug(">>>> reconcileHostGroup")
+		defer log.WithFields(fields).Debug("<<<< reconcileHostGroup")
+	}
+
+	hostGroup, err := d.API.EnsureHostGroup()
+	if err != nil {
+		return fmt.Errorf("could not ensure host group: %v", err)
+	}
+
+	for _, iqn := range d.Config.HostIQNs {
+		if _, err = d.API.EnsureHostInGroup(iqn, hostGroup); err != nil {
+			return fmt.Errorf("could not define array host for IQN %s: %v", iqn, err)
+		}
+	}
+
+	hosts, err := d.API.GetHosts()
+	if err != nil {
+		return fmt.Errorf("could not get hosts from array: %v", err)
+	}
+
+	for _, host := range hosts {
+		if host.ClusterRef != hostGroup.ClusterRef || d.hostHasConfiguredIQN(host) {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"host":      host.Label,
+			"hostGroup": hostGroup.Label,
+		}).Info("Deleting host of removed node.")
+
+		if err = d.API.DeleteHost(host); err != nil {
+			log.WithField("host", host.Label).Warnf("Could not delete host. %v", err)
+		}
+	}
+
+	return nil
+}
+
+// hostHasConfiguredIQN returns true if any of the host's initiators appear in the config.
+func (d *SANStorageDriver) hostHasConfiguredIQN(host api.HostEx) bool {
+	for _, iqn := range d.Config.HostIQNs {
+		if host.HasIQN(iqn) {
+			return true
+		}
+	}
+	return false
+}
+
 // MapVolumeToLocalHost gets the iSCSI identity of the local host, ensures a corresponding Host definition exists on the array
 // (defining a Host & HostGroup if not), maps the specified volume to the host/group (if it isn't already), and returns the mapping info.
 func (d *SANStorageDriver) MapVolumeToLocalHost(volume api.VolumeEx) (api.LUNMapping, error) {
@@ -620,7 +757,9 @@ func (d *SANStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error)
 
 // CreateClone creates a new volume from the named volume, either by direct clone or from the named snapshot. The E-series volume plugin
 // does not support cloning or snapshots, so this method always returns an error.
-func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *SANStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -634,7 +773,7 @@ func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[s
 		defer log.WithFields(fields).Debug("<<<< CreateClone")
 	}
 
-	return errors.New("cloning with E-Series is not supported")
+	return drivers.NewUnsupportedError("cloning with E-Series is not supported")
 }
 
 // List the list of volumes associated with this tenant
@@ -717,6 +856,37 @@ func (d *SANStorageDriver) Get(name string) error {
 	return nil
 }
 
+// GetPoolCapacity reports the space in the volume group or disk pool backing a storage pool.
+func (d *SANStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	pools, err := d.API.GetVolumePools("", 0, pool.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not get storage pools from array: %v", err)
+	}
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("storage pool %s not found on array", pool.Name)
+	}
+
+	total, err := strconv.ParseUint(pools[0].TotalRaidedSpace, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse total space of storage pool %s: %v", pool.Name, err)
+	}
+	used, err := strconv.ParseUint(pools[0].UsedSpace, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse used space of storage pool %s: %v", pool.Name, err)
+	}
+	free, err := strconv.ParseUint(pools[0].FreeSpace, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse free space of storage pool %s: %v", pool.Name, err)
+	}
+
+	return &storage.PoolCapacity{
+		TotalBytes:     total,
+		UsedBytes:      used,
+		AvailableBytes: free,
+	}, nil
+}
+
 // GetStorageBackendSpecs retrieve storage capabilities and register pools with specified backend.
 func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 
@@ -745,6 +915,9 @@ func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) erro
 		vc.Attributes[sa.Snapshots] = sa.NewBoolOffer(false)
 		vc.Attributes[sa.Clones] = sa.NewBoolOffer(false)
 		vc.Attributes[sa.Encryption] = sa.NewBoolOffer(false)
+		vc.Attributes[sa.Resize] = sa.NewBoolOffer(false)
+		vc.Attributes[sa.QoS] = sa.NewBoolOffer(false)
+		vc.Attributes[sa.Replication] = sa.NewBoolOffer(false)
 		vc.Attributes[sa.ProvisioningType] = sa.NewStringOffer("thick")
 
 		backend.AddStoragePool(vc)
@@ -841,6 +1014,12 @@ func (d *SANStorageDriver) GetVolumeOpts(
 	if volConfig.FileSystem != "" {
 		opts["fileSystemType"] = volConfig.FileSystem
 	}
+	if volConfig.FileSystemOwner != "" {
+		opts["fileSystemOwner"] = volConfig.FileSystemOwner
+	}
+	if volConfig.FileSystemMode != "" {
+		opts["fileSystemMode"] = volConfig.FileSystemMode
+	}
 
 	log.WithFields(log.Fields{
 		"volConfig": volConfig,
@@ -886,8 +1065,8 @@ func (d *SANStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error
 		return fmt.Errorf("could not get target IQN from array: %v", err)
 	}
 
-	// Get the Trident Host Group
-	hostGroup, err := d.API.GetHostGroup(d.Config.AccessGroup)
+	// Get the Trident Host Group, creating it if the cluster's hosts haven't been defined yet
+	hostGroup, err := d.API.EnsureHostGroup()
 	if err != nil {
 		return fmt.Errorf("could not get Host Group %s from array: %v", d.Config.AccessGroup, err)
 	}
@@ -905,12 +1084,14 @@ func (d *SANStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error
 	volConfig.AccessInfo.IscsiTargetPortal = d.Config.HostDataIP
 	volConfig.AccessInfo.IscsiTargetIQN = targetIQN
 	volConfig.AccessInfo.IscsiLunNumber = int32(mapping.LunNumber)
+	volConfig.AccessInfo.IscsiLunWWID = utils.NAAWWID(volume.WorldWideName)
 
 	log.WithFields(log.Fields{
 		"volume":          volConfig.Name,
 		"volume_internal": volConfig.InternalName,
 		"targetIQN":       volConfig.AccessInfo.IscsiTargetIQN,
 		"lunNumber":       volConfig.AccessInfo.IscsiLunNumber,
+		"lunWWID":         volConfig.AccessInfo.IscsiLunWWID,
 		"hostGroup":       hostGroup.Label,
 	}).Debug("Mapped E-series LUN.")
 
@@ -1079,8 +1260,14 @@ func (d *SANStorageDriver) getVolumeExternal(
 		FileSystem:      "",
 	}
 
+	state := storage.VolumeStateOnline
+	if volumeAttrs.IsOffline {
+		state = storage.VolumeStateOffline
+	}
+
 	return &storage.VolumeExternal{
 		Config: volumeConfig,
 		Pool:   poolAttrs.Label,
+		State:  state,
 	}
 }
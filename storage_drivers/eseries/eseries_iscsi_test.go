@@ -0,0 +1,94 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package eseries
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/eseries/api"
+)
+
+const (
+	tridentGroupRef = "0000000000000000000000000000000000000001"
+	otherGroupRef   = "0000000000000000000000000000000000000002"
+)
+
+func newTestHost(ref, label, iqn, clusterRef string) api.HostEx {
+	return api.HostEx{
+		HostRef:    ref,
+		ClusterRef: clusterRef,
+		Label:      label,
+		Initiators: []api.HostExInitiator{
+			{NodeName: api.HostExScsiNodeName{IoInterfaceType: "iscsi", IscsiNodeName: iqn}},
+		},
+	}
+}
+
+func TestReconcileHostGroup(t *testing.T) {
+	hosts := []api.HostEx{
+		newTestHost("host1", "node1", "iqn.1994-05.com.redhat:node1", tridentGroupRef),
+		newTestHost("host2", "removed", "iqn.1994-05.com.redhat:removed", tridentGroupRef),
+		newTestHost("host3", "admin", "iqn.1994-05.com.redhat:admin", otherGroupRef),
+	}
+	created := make([]string, 0)
+	deleted := make([]string, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/devmgr/v2/storage-systems/")
+		switch {
+		case r.Method == "GET" && path == "/host-groups":
+			json.NewEncoder(w).Encode([]api.HostGroup{{ClusterRef: tridentGroupRef, Label: "trident"}})
+		case r.Method == "GET" && path == "/host-types":
+			json.NewEncoder(w).Encode([]api.HostType{{Index: 28, Code: "LnxALUA"}})
+		case r.Method == "GET" && path == "/hosts":
+			json.NewEncoder(w).Encode(hosts)
+		case r.Method == "POST" && path == "/hosts":
+			var request api.HostCreateRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			created = append(created, request.Ports[0].Port)
+			host := newTestHost("new"+request.Name, request.Name, request.Ports[0].Port, request.GroupID)
+			hosts = append(hosts, host)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(host)
+		case r.Method == "DELETE" && strings.HasPrefix(path, "/hosts/"):
+			deleted = append(deleted, strings.TrimPrefix(path, "/hosts/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Unable to parse the proxy URL: ", err)
+	}
+	d := &SANStorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	d.Config.HostIQNs = []string{"iqn.1994-05.com.redhat:node1", "iqn.1994-05.com.redhat:node2"}
+	d.API = api.NewAPIClient(api.ClientConfig{
+		WebProxyHostname: u.Hostname(),
+		WebProxyPort:     u.Port(),
+		WebProxyUseHTTP:  true,
+		AccessGroup:      "trident",
+		HostType:         "linux_dm_mp",
+	})
+
+	if err = d.reconcileHostGroup(); err != nil {
+		t.Fatal("Unable to reconcile host group: ", err)
+	}
+
+	// The new node gets a host, and the removed node's host is deleted, but an admin's is left
+	if len(created) != 1 || created[0] != "iqn.1994-05.com.redhat:node2" {
+		t.Errorf("Expected a host for node2 only, got %v.", created)
+	}
+	if len(deleted) != 1 || deleted[0] != "host2" {
+		t.Errorf("Expected only the removed node's host to be deleted, got %v.", deleted)
+	}
+}
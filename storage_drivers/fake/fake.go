@@ -4,11 +4,13 @@ package fake
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -32,6 +34,9 @@ type StorageDriver struct {
 	// Volumes saves info about Volumes created on this driver
 	Volumes map[string]fake.Volume
 
+	// Snapshots saves the snapshots of each volume, in the order they were taken
+	Snapshots map[string][]storage.Snapshot
+
 	// DestroyedVolumes is here so that tests can check whether destroy
 	// has been called on a volume during or after bootstrapping, since
 	// different driver instances with the same config won't actually share
@@ -44,6 +49,7 @@ func NewFakeStorageDriver(config drivers.FakeStorageDriverConfig) *StorageDriver
 		initialized:      true,
 		Config:           config,
 		Volumes:          make(map[string]fake.Volume),
+		Snapshots:        make(map[string][]storage.Snapshot),
 		DestroyedVolumes: make(map[string]bool),
 	}
 }
@@ -85,6 +91,14 @@ func (d *StorageDriver) Name() string {
 	return drivers.FakeStorageDriverName
 }
 
+func (d *StorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *StorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+}
+
 func (d *StorageDriver) Initialize(
 	context config.DriverContext, configJSON string, commonConfig *drivers.CommonStorageDriverConfig,
 ) error {
@@ -100,6 +114,7 @@ func (d *StorageDriver) Initialize(
 	}
 
 	d.Volumes = make(map[string]fake.Volume)
+	d.Snapshots = make(map[string][]storage.Snapshot)
 	d.DestroyedVolumes = make(map[string]bool)
 	d.Config.SerialNumbers = []string{d.Config.InstanceName + "_SN"}
 
@@ -144,7 +159,24 @@ func (d *StorageDriver) populateConfigurationDefaults(config *drivers.FakeStorag
 	return nil
 }
 
-func (d *StorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+// GetVolumeSize returns the size of the volume that Create makes for a requested size.
+func (d *StorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, d.Config.CommonStorageDriverConfig)
+	if sizeBytes < MinimumVolumeSizeBytes {
+		return 0, fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
+			sizeBytes, MinimumVolumeSizeBytes)
+	}
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
+func (d *StorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
 
 	poolName, ok := opts[FakePoolAttribute]
 	if !ok {
@@ -160,13 +192,9 @@ func (d *StorageDriver) Create(name string, sizeBytes uint64, opts map[string]st
 		return fmt.Errorf("volume %s already exists", name)
 	}
 
-	if sizeBytes == 0 {
-		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
-		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
-	}
-	if sizeBytes < MinimumVolumeSizeBytes {
-		return fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
-			sizeBytes, MinimumVolumeSizeBytes)
+	sizeBytes, err := d.GetVolumeSize(sizeBytes)
+	if err != nil {
+		return err
 	}
 
 	if sizeBytes > pool.Bytes {
@@ -178,6 +206,8 @@ func (d *StorageDriver) Create(name string, sizeBytes uint64, opts map[string]st
 		Name:      name,
 		PoolName:  poolName,
 		SizeBytes: sizeBytes,
+		Owner:     utils.GetOwner(ctx),
+		Created:   time.Now(),
 	}
 	d.DestroyedVolumes[name] = false
 	pool.Bytes -= sizeBytes
@@ -192,7 +222,9 @@ func (d *StorageDriver) Create(name string, sizeBytes uint64, opts map[string]st
 	return nil
 }
 
-func (d *StorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *StorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	// Ensure source volume exists
 	sourceVolume, ok := d.Volumes[source]
@@ -220,9 +252,14 @@ func (d *StorageDriver) CreateClone(name, source, snapshot string, opts map[stri
 	}
 
 	d.Volumes[name] = fake.Volume{
-		Name:      name,
-		PoolName:  poolName,
-		SizeBytes: sizeBytes,
+		Name:          name,
+		PoolName:      poolName,
+		SizeBytes:     sizeBytes,
+		ReadOnly:      sourceVolume.ReadOnly,
+		CloneSource:   source,
+		CloneSnapshot: snapshot,
+		Owner:         utils.GetOwner(ctx),
+		Created:       time.Now(),
 	}
 	d.DestroyedVolumes[name] = false
 	pool.Bytes -= sizeBytes
@@ -239,7 +276,22 @@ func (d *StorageDriver) CreateClone(name, source, snapshot string, opts map[stri
 	return nil
 }
 
-func (d *StorageDriver) Destroy(name string) error {
+// SupportsOnDelete reports that Destroy can retain a volume, which for a fake volume is the
+// same as taking it offline.
+func (d *StorageDriver) SupportsOnDelete(onDelete string) bool {
+	return onDelete == drivers.OnDeleteRetain || onDelete == drivers.OnDeleteOffline
+}
+
+func (d *StorageDriver) Destroy(ctx context.Context, name string) error {
+
+	if onDelete := drivers.OnDeleteFromContext(ctx); onDelete != drivers.OnDeleteDelete {
+		log.WithFields(log.Fields{
+			"backend":  d.Config.InstanceName,
+			"Name":     name,
+			"onDelete": onDelete,
+		}).Debug("Retained fake volume.")
+		return nil
+	}
 
 	d.DestroyedVolumes[name] = true
 
@@ -255,6 +307,7 @@ func (d *StorageDriver) Destroy(name string) error {
 
 	pool.Bytes += volume.SizeBytes
 	delete(d.Volumes, name)
+	delete(d.Snapshots, name)
 
 	log.WithFields(log.Fields{
 		"backend":   d.Config.InstanceName,
@@ -266,6 +319,107 @@ func (d *StorageDriver) Destroy(name string) error {
 	return nil
 }
 
+// RenameVolume renames a fake volume, so that warm pools may be tested.
+func (d *StorageDriver) RenameVolume(ctx context.Context, name, newName string) error {
+
+	volume, ok := d.Volumes[name]
+	if !ok {
+		return fmt.Errorf("could not find volume %s", name)
+	}
+	if _, ok = d.Volumes[newName]; ok {
+		return fmt.Errorf("volume %s already exists", newName)
+	}
+
+	volume.Name = newName
+	d.Volumes[newName] = volume
+	delete(d.Volumes, name)
+	if snapshots, ok := d.Snapshots[name]; ok {
+		d.Snapshots[newName] = snapshots
+		delete(d.Snapshots, name)
+	}
+	d.DestroyedVolumes[newName] = false
+
+	log.WithFields(log.Fields{
+		"backend": d.Config.InstanceName,
+		"Name":    name,
+		"NewName": newName,
+	}).Debug("Renamed fake volume.")
+
+	return nil
+}
+
+// ResizeVolume grows a fake volume, taking the added space from its pool.
+func (d *StorageDriver) ResizeVolume(ctx context.Context, name string, sizeBytes uint64) error {
+
+	volume, ok := d.Volumes[name]
+	if !ok {
+		return fmt.Errorf("could not find volume %s", name)
+	}
+	pool, ok := d.Config.Pools[volume.PoolName]
+	if !ok {
+		return fmt.Errorf("could not find pool %s", volume.PoolName)
+	}
+	if sizeBytes < volume.SizeBytes {
+		return fmt.Errorf("volume %s can't be shrunk from %d to %d bytes", name, volume.SizeBytes, sizeBytes)
+	}
+	if sizeBytes-volume.SizeBytes > pool.Bytes {
+		return fmt.Errorf("requested volume is too large; requested %d more bytes; have %d available in pool %s",
+			sizeBytes-volume.SizeBytes, pool.Bytes, volume.PoolName)
+	}
+
+	pool.Bytes -= sizeBytes - volume.SizeBytes
+	volume.SizeBytes = sizeBytes
+	d.Volumes[name] = volume
+	return nil
+}
+
+// GetVolumeStats reports a fake volume as idle, since nothing is ever written to it.
+func (d *StorageDriver) GetVolumeStats(name string) (*storage.VolumeStats, error) {
+	if _, ok := d.Volumes[name]; !ok {
+		return nil, fmt.Errorf("could not find volume %s", name)
+	}
+	return &storage.VolumeStats{Time: time.Now()}, nil
+}
+
+// GetVolumeUsedBytes reports a fake volume as empty, since nothing is ever written to it.
+func (d *StorageDriver) GetVolumeUsedBytes(name string) (uint64, error) {
+	if _, ok := d.Volumes[name]; !ok {
+		return 0, fmt.Errorf("could not find volume %s", name)
+	}
+	return 0, nil
+}
+
+// SetVolumeReadOnly marks a volume read-only, or writable, so that tests can check which it is.
+func (d *StorageDriver) SetVolumeReadOnly(volConfig *storage.VolumeConfig) error {
+	volume, ok := d.Volumes[volConfig.InternalName]
+	if !ok {
+		return fmt.Errorf("could not find volume %s", volConfig.InternalName)
+	}
+	volume.ReadOnly = volConfig.ReadOnly
+	d.Volumes[volConfig.InternalName] = volume
+	return nil
+}
+
+// SetSnapshotDirVisible records whether a volume's .snapshot directory is shown, so that tests can
+// check which it is.
+func (d *StorageDriver) SetSnapshotDirVisible(name string, visible bool) error {
+	volume, ok := d.Volumes[name]
+	if !ok {
+		return fmt.Errorf("could not find volume %s", name)
+	}
+	volume.SnapshotDirVisible = visible
+	d.Volumes[name] = volume
+	return nil
+}
+
+// ModifyVolumeQoS accepts any QoS for an existing volume, so that QoS changes may be tested.
+func (d *StorageDriver) ModifyVolumeQoS(volConfig *storage.VolumeConfig) error {
+	if _, ok := d.Volumes[volConfig.InternalName]; !ok {
+		return fmt.Errorf("could not find volume %s", volConfig.InternalName)
+	}
+	return nil
+}
+
 func (d *StorageDriver) Attach(name, mountpoint string, opts map[string]string) error {
 	return errors.New("fake driver does not support attaching")
 }
@@ -275,7 +429,154 @@ func (d *StorageDriver) Detach(name, mountpoint string) error {
 }
 
 func (d *StorageDriver) SnapshotList(name string) ([]storage.Snapshot, error) {
-	return nil, errors.New("fake driver does not support SnapshotList")
+	if _, ok := d.Volumes[name]; !ok {
+		return nil, fmt.Errorf("could not find volume %s", name)
+	}
+	return append([]storage.Snapshot{}, d.Snapshots[name]...), nil
+}
+
+// CreateSnapshot records a snapshot of an existing volume, so that scheduled snapshots may be tested.
+func (d *StorageDriver) CreateSnapshot(snapshotName, volumeName string) (*storage.Snapshot, error) {
+
+	if _, ok := d.Volumes[volumeName]; !ok {
+		return nil, fmt.Errorf("could not find volume %s", volumeName)
+	}
+	for _, snapshot := range d.Snapshots[volumeName] {
+		if snapshot.Name == snapshotName {
+			return nil, fmt.Errorf("snapshot %s of volume %s already exists", snapshotName, volumeName)
+		}
+	}
+
+	snapshot := storage.Snapshot{Name: snapshotName, Created: time.Now().UTC().Format(time.RFC3339)}
+	d.Snapshots[volumeName] = append(d.Snapshots[volumeName], snapshot)
+	return &snapshot, nil
+}
+
+// CreateGroupSnapshot snapshots several volumes, taking none of the snapshots if any volume is missing.
+func (d *StorageDriver) CreateGroupSnapshot(snapshotName string, volumeNames []string) ([]*storage.Snapshot, error) {
+
+	for _, volumeName := range volumeNames {
+		if _, ok := d.Volumes[volumeName]; !ok {
+			return nil, fmt.Errorf("could not find volume %s", volumeName)
+		}
+	}
+
+	snapshots := make([]*storage.Snapshot, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		snapshot, err := d.CreateSnapshot(snapshotName, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (d *StorageDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+
+	snapshots := d.Snapshots[volumeName]
+	for i, snapshot := range snapshots {
+		if snapshot.Name == snapshotName {
+			d.Snapshots[volumeName] = append(snapshots[:i], snapshots[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("could not find snapshot %s of volume %s", snapshotName, volumeName)
+}
+
+// CanReplicateFrom allows replicating from other fake backends of the same protocol, so that
+// migrations may be tested.
+func (d *StorageDriver) CanReplicateFrom(source storage.Driver) bool {
+	sourceDriver, ok := source.(*StorageDriver)
+	return ok && sourceDriver.Config.Protocol == d.Config.Protocol
+}
+
+func (d *StorageDriver) CreateReplica(
+	ctx context.Context, name, sourceName string, source storage.Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	if _, ok := source.(*StorageDriver).Volumes[sourceName]; !ok {
+		return fmt.Errorf("source volume %s not found", sourceName)
+	}
+	return d.Create(ctx, name, sizeBytes, opts)
+}
+
+func (d *StorageDriver) UpdateReplica(ctx context.Context, name string) error {
+	if _, ok := d.Volumes[name]; !ok {
+		return fmt.Errorf("replica %s not found", name)
+	}
+	return nil
+}
+
+func (d *StorageDriver) PromoteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+	if _, ok := d.Volumes[name]; !ok {
+		return fmt.Errorf("replica %s not found", name)
+	}
+	return nil
+}
+
+func (d *StorageDriver) DeleteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+	return d.Destroy(ctx, name)
+}
+
+// CanCacheFrom allows caching volumes of other fake backends of the same protocol.
+func (d *StorageDriver) CanCacheFrom(origin storage.Driver) bool {
+	originDriver, ok := origin.(*StorageDriver)
+	return ok && originDriver.Config.Protocol == d.Config.Protocol
+}
+
+func (d *StorageDriver) CreateCache(
+	ctx context.Context, name, originName string, origin storage.Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	if _, ok := origin.(*StorageDriver).Volumes[originName]; !ok {
+		return fmt.Errorf("origin volume %s not found", originName)
+	}
+	if err := d.Create(ctx, name, sizeBytes, opts); err != nil {
+		return err
+	}
+	cache := d.Volumes[name]
+	cache.CacheOrigin = originName
+	d.Volumes[name] = cache
+	return nil
+}
+
+func (d *StorageDriver) DestroyCache(ctx context.Context, name string) error {
+	return d.Destroy(ctx, name)
+}
+
+// CanRehostFrom allows rehosting volumes of other fake backends of the same protocol.
+func (d *StorageDriver) CanRehostFrom(source storage.Driver) bool {
+	sourceDriver, ok := source.(*StorageDriver)
+	return ok && sourceDriver != d && sourceDriver.Config.Protocol == d.Config.Protocol
+}
+
+// RehostVolume moves a fake volume from the source into a pool of the same name on this backend.
+func (d *StorageDriver) RehostVolume(ctx context.Context, volConfig *storage.VolumeConfig, source storage.Driver) error {
+
+	name := volConfig.InternalName
+	if _, ok := d.Volumes[name]; !ok {
+		sourceDriver := source.(*StorageDriver)
+		volume, ok := sourceDriver.Volumes[name]
+		if !ok {
+			return fmt.Errorf("volume %s not found", name)
+		}
+		pool, ok := d.Config.Pools[volume.PoolName]
+		if !ok {
+			return fmt.Errorf("could not find pool %s", volume.PoolName)
+		}
+		if sourcePool, ok := sourceDriver.Config.Pools[volume.PoolName]; ok {
+			sourcePool.Bytes += volume.SizeBytes
+		}
+		pool.Bytes -= volume.SizeBytes
+		delete(sourceDriver.Volumes, name)
+		d.Volumes[name] = volume
+
+		log.WithFields(log.Fields{
+			"backend":  d.Config.InstanceName,
+			"Name":     name,
+			"PoolName": volume.PoolName,
+		}).Debug("Rehosted fake volume.")
+	}
+	return d.CreateFollowup(volConfig)
 }
 
 func (d *StorageDriver) List() ([]string, error) {
@@ -311,6 +612,28 @@ func (d *StorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 	return nil
 }
 
+// GetPoolCapacity reports the space left in a fake pool along with the space taken by its volumes.
+func (d *StorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	fakePool, ok := d.Config.Pools[pool.Name]
+	if !ok {
+		return nil, fmt.Errorf("could not find pool %s", pool.Name)
+	}
+
+	var usedBytes uint64
+	for _, volume := range d.Volumes {
+		if volume.PoolName == pool.Name {
+			usedBytes += volume.SizeBytes
+		}
+	}
+
+	return &storage.PoolCapacity{
+		TotalBytes:     usedBytes + fakePool.Bytes,
+		UsedBytes:      usedBytes,
+		AvailableBytes: fakePool.Bytes,
+	}, nil
+}
+
 func (d *StorageDriver) GetVolumeOpts(
 	volConfig *storage.VolumeConfig,
 	pool *storage.Pool,
@@ -371,6 +694,21 @@ func (d *StorageDriver) StoreConfig(b *storage.PersistentStorageBackendConfig) {
 	}
 }
 
+// GetOwnership returns the backend that created a fake volume, and when.
+func (d *StorageDriver) GetOwnership(name string) (string, time.Time, error) {
+	volume, ok := d.Volumes[name]
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("could not find volume %s", name)
+	}
+	return volume.Owner, volume.Created, nil
+}
+
+// StorageIdentity identifies the fake backend by its instance name, so that two configs for the
+// same instance are recognized as duplicates.
+func (d *StorageDriver) StorageIdentity() string {
+	return drivers.FakeStorageDriverName + ":" + d.Config.InstanceName
+}
+
 func (d *StorageDriver) GetExternalConfig() interface{} {
 
 	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
@@ -418,12 +756,23 @@ func (d *StorageDriver) getVolumeExternal(volume fake.Volume) *storage.VolumeExt
 		Name:         volume.Name,
 		InternalName: volume.Name,
 		Size:         strconv.FormatUint(volume.SizeBytes, 10),
+		Protocol:     d.Config.Protocol,
+		SnapshotDir:  strconv.FormatBool(volume.SnapshotDirVisible),
+		ReadOnly:     volume.ReadOnly,
+	}
+	if volume.CacheOrigin != "" {
+		volumeConfig.CacheSourceVolumeInternal = volume.CacheOrigin
+	}
+	if volume.CloneSource != "" {
+		volumeConfig.CloneSourceVolumeInternal = volume.CloneSource
+		volumeConfig.CloneSourceSnapshot = volume.CloneSnapshot
 	}
 
 	volumeExternal := &storage.VolumeExternal{
 		Config:  volumeConfig,
 		Backend: d.Name(),
 		Pool:    volume.PoolName,
+		State:   storage.VolumeStateOnline,
 	}
 
 	return volumeExternal
@@ -0,0 +1,25 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/utils"
+)
+
+// ValidateFilesystemPermissions checks a backend's default owner and mode for new file systems.
+// Trident applies them only where it formats volumes itself, which it does for Docker.  Kubernetes
+// nodes format volumes, and apply a pod's fsGroup when mounting them, so other contexts reject
+// the options rather than ignore them.
+func ValidateFilesystemPermissions(context trident.DriverContext, owner, mode string) error {
+	if err := utils.ValidateFilesystemPermissions(owner, mode); err != nil {
+		return fmt.Errorf("invalid config value for default file system permissions: %v", err)
+	}
+	if context != trident.ContextDocker && (owner != "" || mode != "") {
+		return fmt.Errorf("fileSystemOwner and fileSystemMode apply only to Docker hosts; "+
+			"use the pod's fsGroup on %s", context)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+)
+
+func TestValidateFilesystemPermissions(t *testing.T) {
+	if err := ValidateFilesystemPermissions(trident.ContextDocker, "1000:1000", "0770"); err != nil {
+		t.Errorf("Expected owner and mode to be valid for Docker: %v", err)
+	}
+	if err := ValidateFilesystemPermissions(trident.ContextDocker, "nobody", ""); err == nil {
+		t.Error("Expected an invalid owner to be rejected")
+	}
+	if err := ValidateFilesystemPermissions(trident.ContextKubernetes, "", ""); err != nil {
+		t.Errorf("Expected no owner or mode to be valid for Kubernetes: %v", err)
+	}
+	if err := ValidateFilesystemPermissions(trident.ContextKubernetes, "", "0770"); err == nil {
+		t.Error("Expected a mode to be rejected for Kubernetes")
+	}
+}
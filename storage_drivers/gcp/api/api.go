@@ -0,0 +1,481 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+// This package provides a high-level interface to the NetApp Cloud Volumes Service for GCP REST API.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	DefaultAPIURL      = "https://cloudvolumesgcp-api.netapp.com"
+	DefaultAPIAudience = DefaultAPIURL
+	httpTimeoutSeconds = 30
+)
+
+// ClientConfig holds configuration data for the API driver object.
+type ClientConfig struct {
+
+	// GCP project number
+	ProjectNumber string
+
+	// GCP CVS API authentication parameters
+	APIKey ServiceAccountKey
+
+	// GCP region
+	APIRegion string
+
+	// URL for accessing the API via an HTTP/HTTPS proxy
+	APIURL      string
+	APIAudience string
+
+	// Options
+	DebugTraceFlags map[string]bool
+}
+
+// Client is the object to use for interacting with the Cloud Volumes Service API.
+type Client struct {
+	config     *ClientConfig
+	httpClient *http.Client
+	tokens     *tokenCache
+	ctx        context.Context
+}
+
+// NewDriver is a factory method for creating a new instance.
+func NewDriver(config ClientConfig) *Client {
+
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	if config.APIAudience == "" {
+		config.APIAudience = DefaultAPIAudience
+	}
+
+	return &Client{
+		config:     &config,
+		httpClient: &http.Client{Timeout: httpTimeoutSeconds * time.Second},
+		tokens:     &tokenCache{},
+	}
+}
+
+// WithContext returns a copy of this client whose API calls are bound to the supplied context, so
+// that they may be cancelled or time-bounded by the caller.  The copy shares this client's token.
+func (d Client) WithContext(ctx context.Context) *Client {
+	clone := d
+	clone.ctx = ctx
+	return &clone
+}
+
+// Context returns the context to which this client's API calls are bound.
+func (d Client) Context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+	return d.ctx
+}
+
+// SetDebugTraceFlags replaces the debug trace flags used by this client.
+func (d *Client) SetDebugTraceFlags(flags map[string]bool) {
+	d.config.DebugTraceFlags = flags
+}
+
+// Init checks that the client can authenticate with the Cloud Volumes Service API.
+func (d *Client) Init() error {
+	_, err := d.getToken()
+	return err
+}
+
+// InvokeAPI makes a REST call to the Cloud Volumes Service API. The body must be a marshaled JSON byte
+// array (or nil).  The method is the HTTP verb (i.e. GET, POST, ...).  The resource path is appended to
+// the project and region's base URL to identify the desired server resource; it should start with '/'.
+func (d *Client) InvokeAPI(requestBody []byte, method string, resourcePath string) (*http.Response, []byte, error) {
+
+	token, err := d.getToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	url := fmt.Sprintf("%s/v2/projects/%s/locations/%s%s",
+		d.config.APIURL, d.config.ProjectNumber, d.config.APIRegion, resourcePath)
+
+	var request *http.Request
+	var prettyJSON bytes.Buffer
+
+	// Create the request
+	if requestBody == nil {
+		request, err = http.NewRequest(method, url, nil)
+	} else {
+		request, err = http.NewRequest(method, url, bytes.NewBuffer(requestBody))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	// Log the request
+	if d.config.DebugTraceFlags["api"] {
+		json.Indent(&prettyJSON, requestBody, "", "  ")
+		utils.LogHTTPRequest(request, prettyJSON.Bytes())
+	}
+
+	// Send the request
+	response, err := d.httpClient.Do(request.WithContext(d.Context()))
+	if err != nil {
+		log.Warnf("Error communicating with Cloud Volumes Service. %v", err)
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return response, nil, err
+	}
+
+	if d.config.DebugTraceFlags["api"] {
+		prettyJSON.Reset()
+		json.Indent(&prettyJSON, responseBody, "", "  ")
+		utils.LogHTTPResponse(response, prettyJSON.Bytes())
+	}
+
+	return response, responseBody, nil
+}
+
+// getErrorFromHTTPResponse converts an unsuccessful API response into an Error that embeds
+// the message returned by the service.
+func (d *Client) getErrorFromHTTPResponse(response *http.Response, responseBody []byte) error {
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	responseData := CallResponseError{}
+	if err := json.Unmarshal(responseBody, &responseData); err != nil || responseData.Message == "" {
+		return Error{Code: response.StatusCode, Message: strings.TrimSpace(string(responseBody))}
+	}
+
+	return Error{Code: response.StatusCode, Message: responseData.Message}
+}
+
+// invoke calls the API and unmarshals a successful response into result, if one is supplied.
+func (d *Client) invoke(method, resourcePath string, request, result interface{}) error {
+
+	var requestBody []byte
+	if request != nil {
+		var err error
+		if requestBody, err = json.Marshal(request); err != nil {
+			return err
+		}
+	}
+
+	response, responseBody, err := d.InvokeAPI(requestBody, method, resourcePath)
+	if err != nil {
+		return err
+	}
+	if err = d.getErrorFromHTTPResponse(response, responseBody); err != nil {
+		return err
+	}
+
+	if result != nil {
+		if err = json.Unmarshal(responseBody, result); err != nil {
+			return fmt.Errorf("could not parse API response: %s; %v", string(responseBody), err)
+		}
+	}
+	return nil
+}
+
+// GetVolumes returns all volumes in the project and region.
+func (d *Client) GetVolumes() (*[]Volume, error) {
+
+	var volumes []Volume
+	if err := d.invoke("GET", "/Volumes", nil, &volumes); err != nil {
+		return nil, fmt.Errorf("could not list volumes: %v", err)
+	}
+
+	// Volumes that have been deleted may be reported for a while afterwards
+	liveVolumes := make([]Volume, 0, len(volumes))
+	for _, volume := range volumes {
+		if volume.LifeCycleState != StateDeleted {
+			liveVolumes = append(liveVolumes, volume)
+		}
+	}
+
+	return &liveVolumes, nil
+}
+
+// GetVolumeByCreationToken returns the volume with the specified creation token, which is the
+// name Trident gives to the volume and the path at which it is exported.
+func (d *Client) GetVolumeByCreationToken(creationToken string) (*Volume, error) {
+
+	volumes, err := d.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, volume := range *volumes {
+		if volume.CreationToken == creationToken {
+			return &volume, nil
+		}
+	}
+
+	return nil, Error{Code: http.StatusNotFound, Message: fmt.Sprintf("volume %s not found", creationToken)}
+}
+
+// VolumeExistsByCreationToken returns true if a volume with the specified creation token exists.
+func (d *Client) VolumeExistsByCreationToken(creationToken string) (bool, *Volume, error) {
+
+	volume, err := d.GetVolumeByCreationToken(creationToken)
+	if IsNotFoundError(err) {
+		return false, nil, nil
+	} else if err != nil {
+		return false, nil, err
+	}
+	return true, volume, nil
+}
+
+// GetVolumeByID returns the volume with the specified ID.
+func (d *Client) GetVolumeByID(volumeID string) (*Volume, error) {
+
+	var volume Volume
+	if err := d.invoke("GET", "/Volumes/"+volumeID, nil, &volume); err != nil {
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// WaitForVolumeState polls a volume until it reaches the desired state, it reaches one of the abort
+// states, the timeout passes, or the client's context is done.  It returns the last state seen.
+func (d *Client) WaitForVolumeState(
+	volume *Volume, desiredState string, abortStates []string, timeout time.Duration,
+) (string, error) {
+
+	volumeState := ""
+
+	checkVolumeState := func() error {
+
+		v, err := d.GetVolumeByID(volume.VolumeID)
+		if err != nil {
+
+			// A deleted volume is soon forgotten by the service
+			if desiredState == StateDeleted && IsNotFoundError(err) {
+				volumeState = StateDeleted
+				return nil
+			}
+
+			volumeState = ""
+			return fmt.Errorf("could not get volume status; %v", err)
+		}
+		volumeState = v.LifeCycleState
+
+		if volumeState == desiredState {
+			return nil
+		}
+		for _, abortState := range abortStates {
+			if volumeState == abortState {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("volume state is %s, not %s", volumeState, desiredState)
+	}
+	stateNotify := func(err error, duration time.Duration) {
+		log.WithFields(log.Fields{
+			"increment": duration,
+			"message":   err.Error(),
+		}).Debug("Waiting for volume state.")
+	}
+	stateBackoff := backoff.NewExponentialBackOff()
+	stateBackoff.InitialInterval = 1 * time.Second
+	stateBackoff.Multiplier = 2
+	stateBackoff.RandomizationFactor = 0.1
+	stateBackoff.MaxInterval = 15 * time.Second
+	stateBackoff.MaxElapsedTime = timeout
+
+	// Run the state check using an exponential backoff, giving up early if the caller does
+	if err := backoff.RetryNotify(checkVolumeState, backoff.WithContext(stateBackoff, d.Context()),
+		stateNotify); err != nil {
+		log.WithFields(log.Fields{
+			"volume": volume.CreationToken,
+			"state":  volumeState,
+		}).Errorf("Volume did not reach the %s state after %3.2f seconds.", desiredState, timeout.Seconds())
+		return volumeState, err
+	}
+
+	if volumeState != desiredState {
+		return volumeState, fmt.Errorf("volume %s reached state %s while waiting for %s",
+			volume.CreationToken, volumeState, desiredState)
+	}
+
+	log.WithField("state", desiredState).Debug("Desired volume state reached.")
+	return volumeState, nil
+}
+
+// CreateVolume asks the service to create a volume.  Creation continues in the background, so
+// callers should wait for the volume to become available.
+func (d *Client) CreateVolume(request *VolumeCreateRequest) error {
+
+	if err := d.invoke("POST", "/Volumes", request, nil); err != nil {
+		return fmt.Errorf("could not create volume %s: %v", request.CreationToken, err)
+	}
+
+	log.WithFields(log.Fields{
+		"name":          request.Name,
+		"creationToken": request.CreationToken,
+		"serviceLevel":  request.ServiceLevel,
+	}).Info("Volume create request issued.")
+
+	return nil
+}
+
+// DeleteVolume asks the service to delete a volume.  Deletion continues in the background, so
+// callers should wait for the volume to be deleted.
+func (d *Client) DeleteVolume(volume *Volume) error {
+
+	if err := d.invoke("DELETE", "/Volumes/"+volume.VolumeID, nil, nil); err != nil {
+		return fmt.Errorf("could not delete volume %s: %v", volume.CreationToken, err)
+	}
+
+	log.WithField("volume", volume.CreationToken).Info("Volume delete request issued.")
+
+	return nil
+}
+
+// GetSnapshotsForVolume returns the snapshots of a volume.
+func (d *Client) GetSnapshotsForVolume(volume *Volume) (*[]Snapshot, error) {
+
+	var snapshots []Snapshot
+	if err := d.invoke("GET", "/Volumes/"+volume.VolumeID+"/Snapshots", nil, &snapshots); err != nil {
+		return nil, fmt.Errorf("could not list snapshots of volume %s: %v", volume.CreationToken, err)
+	}
+	return &snapshots, nil
+}
+
+// GetSnapshotForVolume returns the named snapshot of a volume.
+func (d *Client) GetSnapshotForVolume(volume *Volume, snapshotName string) (*Snapshot, error) {
+
+	snapshots, err := d.GetSnapshotsForVolume(volume)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range *snapshots {
+		if snapshot.Name == snapshotName {
+			return &snapshot, nil
+		}
+	}
+
+	return nil, Error{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("snapshot %s of volume %s not found", snapshotName, volume.CreationToken),
+	}
+}
+
+// CreateSnapshot asks the service to create a snapshot of a volume.
+func (d *Client) CreateSnapshot(request *SnapshotCreateRequest) error {
+
+	if err := d.invoke("POST", "/Volumes/"+request.VolumeID+"/Snapshots", request, nil); err != nil {
+		return fmt.Errorf("could not create snapshot %s: %v", request.Name, err)
+	}
+
+	log.WithFields(log.Fields{
+		"name":     request.Name,
+		"volumeID": request.VolumeID,
+	}).Info("Snapshot create request issued.")
+
+	return nil
+}
+
+// DeleteSnapshot asks the service to delete a snapshot of a volume.
+func (d *Client) DeleteSnapshot(volume *Volume, snapshot *Snapshot) error {
+
+	resourcePath := "/Volumes/" + volume.VolumeID + "/Snapshots/" + snapshot.SnapshotID
+	if err := d.invoke("DELETE", resourcePath, nil, nil); err != nil {
+		return fmt.Errorf("could not delete snapshot %s: %v", snapshot.Name, err)
+	}
+
+	log.WithFields(log.Fields{
+		"name":     snapshot.Name,
+		"volumeID": volume.VolumeID,
+	}).Info("Snapshot delete request issued.")
+
+	return nil
+}
+
+// WaitForSnapshotAvailable polls the named snapshot of a volume until it is available, it fails,
+// the timeout passes, or the client's context is done.
+func (d *Client) WaitForSnapshotAvailable(volume *Volume, snapshotName string, timeout time.Duration) error {
+
+	snapshotState := ""
+
+	checkSnapshotState := func() error {
+		snapshot, err := d.GetSnapshotForVolume(volume, snapshotName)
+		if err != nil {
+			snapshotState = ""
+			return err
+		}
+		snapshotState = snapshot.LifeCycleState
+		if snapshotState == StateAvailable || snapshotState == StateError {
+			return nil
+		}
+		return fmt.Errorf("snapshot state is %s, not %s", snapshotState, StateAvailable)
+	}
+	stateBackoff := backoff.NewExponentialBackOff()
+	stateBackoff.InitialInterval = 1 * time.Second
+	stateBackoff.Multiplier = 2
+	stateBackoff.RandomizationFactor = 0.1
+	stateBackoff.MaxInterval = 15 * time.Second
+	stateBackoff.MaxElapsedTime = timeout
+
+	if err := backoff.Retry(checkSnapshotState, backoff.WithContext(stateBackoff, d.Context())); err != nil {
+		return fmt.Errorf("snapshot %s did not become available: %v", snapshotName, err)
+	}
+	if snapshotState != StateAvailable {
+		return fmt.Errorf("snapshot %s reached state %s while waiting for %s",
+			snapshotName, snapshotState, StateAvailable)
+	}
+	return nil
+}
+
+// GCPServiceLevelFromUserServiceLevel converts a service level as shown to users into the one
+// the API expects.
+func GCPServiceLevelFromUserServiceLevel(userServiceLevel string) (string, error) {
+	switch strings.ToLower(userServiceLevel) {
+	case UserServiceLevelStandard:
+		return APIServiceLevelBasic, nil
+	case UserServiceLevelPremium:
+		return APIServiceLevelStandard, nil
+	case UserServiceLevelExtreme:
+		return APIServiceLevelExtreme, nil
+	default:
+		return "", errors.New("invalid service level: " + userServiceLevel)
+	}
+}
+
+// UserServiceLevelFromGCPServiceLevel converts a service level as returned by the API into the
+// one shown to users.
+func UserServiceLevelFromGCPServiceLevel(apiServiceLevel string) string {
+	switch apiServiceLevel {
+	case APIServiceLevelBasic:
+		return UserServiceLevelStandard
+	case APIServiceLevelStandard:
+		return UserServiceLevelPremium
+	case APIServiceLevelExtreme:
+		return UserServiceLevelExtreme
+	default:
+		return apiServiceLevel
+	}
+}
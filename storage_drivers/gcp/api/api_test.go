@@ -0,0 +1,152 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("Could not generate key: ", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal("Could not marshal key: ", err)
+	}
+	return privateKey, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestSignAssertion(t *testing.T) {
+	privateKey, keyPEM := newTestKey(t)
+	key := &ServiceAccountKey{
+		PrivateKey:  keyPEM,
+		ClientEmail: "trident@project.iam.gserviceaccount.com",
+		TokenURI:    "https://oauth2.googleapis.com/token",
+	}
+	now := time.Unix(1500000000, 0)
+
+	assertion, err := signAssertion(key, DefaultAPIAudience, now)
+	if err != nil {
+		t.Fatal("Could not sign assertion: ", err)
+	}
+
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT with 3 parts, got %d.", len(parts))
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if err = rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+		t.Errorf("Assertion signature did not verify: %v", err)
+	}
+
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims jwtClaims
+	if err = json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatal("Could not parse claims: ", err)
+	}
+	expected := jwtClaims{
+		Issuer:         key.ClientEmail,
+		Subject:        key.ClientEmail,
+		Audience:       key.TokenURI,
+		IssuedAt:       now.Unix(),
+		Expiry:         now.Add(tokenLifetime).Unix(),
+		TargetAudience: DefaultAPIAudience,
+	}
+	if claims != expected {
+		t.Errorf("Mismatch between claims.  Expected %+v, got %+v", expected, claims)
+	}
+
+	if _, err = signAssertion(&ServiceAccountKey{PrivateKey: "not a key"}, DefaultAPIAudience, now); err == nil {
+		t.Error("Expected an error signing with an invalid key.")
+	}
+}
+
+func TestInvokeAPI(t *testing.T) {
+	_, keyPEM := newTestKey(t)
+
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Write([]byte(`{"id_token": "secret-token"}`))
+	})
+	mux.HandleFunc("/v2/projects/123/locations/us-central1/Volumes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"volumeId": "1", "creationToken": "trident-a", "lifeCycleState": "available"},
+			{"volumeId": "2", "creationToken": "trident-b", "lifeCycleState": "deleted"}]`))
+	})
+	mux.HandleFunc("/v2/projects/123/locations/us-central1/Volumes/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 404, "message": "Volume not found"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewDriver(ClientConfig{
+		ProjectNumber: "123",
+		APIRegion:     "us-central1",
+		APIURL:        server.URL,
+		APIKey:        ServiceAccountKey{PrivateKey: keyPEM, TokenURI: server.URL + "/token"},
+	})
+
+	volumes, err := client.GetVolumes()
+	if err != nil {
+		t.Fatal("Could not list volumes: ", err)
+	}
+	if len(*volumes) != 1 || (*volumes)[0].CreationToken != "trident-a" {
+		t.Errorf("Expected only the live volume, got %+v", *volumes)
+	}
+
+	exists, _, err := client.VolumeExistsByCreationToken("trident-b")
+	if err != nil || exists {
+		t.Errorf("Expected deleted volume not to exist; exists: %v, error: %v", exists, err)
+	}
+
+	_, err = client.GetVolumeByID("3")
+	if !IsNotFoundError(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	if tokenRequests != 1 {
+		t.Errorf("Expected the token to be requested once, got %d requests.", tokenRequests)
+	}
+}
+
+func TestServiceLevels(t *testing.T) {
+	for userLevel, apiLevel := range map[string]string{
+		"standard": APIServiceLevelBasic,
+		"Premium":  APIServiceLevelStandard,
+		"extreme":  APIServiceLevelExtreme,
+	} {
+		got, err := GCPServiceLevelFromUserServiceLevel(userLevel)
+		if err != nil || got != apiLevel {
+			t.Errorf("Expected %s for %s, got %s (%v)", apiLevel, userLevel, got, err)
+		}
+		if back := UserServiceLevelFromGCPServiceLevel(got); back != strings.ToLower(userLevel) {
+			t.Errorf("Expected %s for %s, got %s", strings.ToLower(userLevel), got, back)
+		}
+	}
+
+	if _, err := GCPServiceLevelFromUserServiceLevel("basic"); err == nil {
+		t.Error("Expected an error for an API service level.")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	tokenLifetime      = time.Hour
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// jwtHeader is the fixed header of the assertions signed with a service account key
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+
+type jwtClaims struct {
+	Issuer         string `json:"iss"`
+	Subject        string `json:"sub"`
+	Audience       string `json:"aud"`
+	IssuedAt       int64  `json:"iat"`
+	Expiry         int64  `json:"exp"`
+	TargetAudience string `json:"target_audience"`
+}
+
+// tokenCache holds the identity token shared by a client and its copies.
+type tokenCache struct {
+	m      sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+// parsePrivateKey decodes the PEM-encoded RSA key of a service account.
+func parsePrivateKey(keyPEM string) (*rsa.PrivateKey, error) {
+
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %v", err)
+	}
+	return key, nil
+}
+
+// signAssertion returns a JWT, signed with the service account's key, that asks for an identity
+// token for the audience.
+func signAssertion(key *ServiceAccountKey, audience string, now time.Time) (string, error) {
+
+	privateKey, err := parsePrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(jwtClaims{
+		Issuer:         key.ClientEmail,
+		Subject:        key.ClientEmail,
+		Audience:       key.TokenURI,
+		IssuedAt:       now.Unix(),
+		Expiry:         now.Add(tokenLifetime).Unix(),
+		TargetAudience: audience,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("could not sign token request: %v", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// getToken returns an identity token for the Cloud Volumes Service API, exchanging a freshly
+// signed assertion for a new one when the cached token is missing or about to expire.
+func (d *Client) getToken() (string, error) {
+
+	d.tokens.m.Lock()
+	defer d.tokens.m.Unlock()
+
+	now := time.Now()
+	if d.tokens.token != "" && now.Add(tokenRefreshMargin).Before(d.tokens.expiry) {
+		return d.tokens.token, nil
+	}
+
+	assertion, err := signAssertion(&d.config.APIKey, d.config.APIAudience, now)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", jwtBearerGrantType)
+	form.Set("assertion", assertion)
+
+	request, err := http.NewRequest("POST", d.config.APIKey.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := d.httpClient.Do(request.WithContext(d.Context()))
+	if err != nil {
+		return "", fmt.Errorf("could not get token for Cloud Volumes Service: %v", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", Error{
+			Code:    response.StatusCode,
+			Message: fmt.Sprintf("could not get token for Cloud Volumes Service: %s", string(responseBody)),
+		}
+	}
+
+	var tokenResp tokenResponse
+	if err = json.Unmarshal(responseBody, &tokenResp); err != nil {
+		return "", fmt.Errorf("could not parse token response: %v", err)
+	}
+	if tokenResp.IDToken == "" {
+		return "", errors.New("token response did not include an identity token")
+	}
+
+	d.tokens.token = tokenResp.IDToken
+	d.tokens.expiry = now.Add(tokenLifetime)
+
+	return d.tokens.token, nil
+}
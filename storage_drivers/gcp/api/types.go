@@ -0,0 +1,141 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Volume states reported by Cloud Volumes Service
+const (
+	StateCreating  = "creating"
+	StateAvailable = "available"
+	StateUpdating  = "updating"
+	StateDeleting  = "deleting"
+	StateDeleted   = "deleted"
+	StateError     = "error"
+	StateDisabled  = "disabled"
+)
+
+// Service levels as named by the Cloud Volumes Service API, which differ from those shown to users
+const (
+	APIServiceLevelBasic    = "basic"
+	APIServiceLevelStandard = "standard"
+	APIServiceLevelExtreme  = "extreme"
+)
+
+// Service levels as named in the Cloud Volumes Service console and in Trident configs
+const (
+	UserServiceLevelStandard = "standard"
+	UserServiceLevelPremium  = "premium"
+	UserServiceLevelExtreme  = "extreme"
+)
+
+const ProtocolTypeNFSv3 = "NFSv3"
+
+// ServiceAccountKey is the JSON key of a GCP service account, as downloaded from the GCP console.
+type ServiceAccountKey struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"project_id"`
+	PrivateKeyID            string `json:"private_key_id" sensitive:"true"`
+	PrivateKey              string `json:"private_key" sensitive:"true"`
+	ClientEmail             string `json:"client_email"`
+	ClientID                string `json:"client_id"`
+	AuthURI                 string `json:"auth_uri"`
+	TokenURI                string `json:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url"`
+}
+
+// Error wrapper
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("cloud volumes API error: %s, Status code: %d", e.Message, e.Code)
+}
+
+// IsNotFoundError returns true if the error is an API error reporting that a resource doesn't exist.
+func IsNotFoundError(err error) bool {
+	apiErr, ok := err.(Error)
+	return ok && apiErr.Code == http.StatusNotFound
+}
+
+// CallResponseError is the body of an unsuccessful API response.
+type CallResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type ExportPolicy struct {
+	Rules []ExportRule `json:"rules"`
+}
+
+type ExportRule struct {
+	Access         string  `json:"access"`
+	AllowedClients string  `json:"allowedClients"`
+	NFSv3          Checked `json:"nfsv3"`
+	NFSv4          Checked `json:"nfsv4"`
+}
+
+type Checked struct {
+	Checked bool `json:"checked"`
+}
+
+type MountPoint struct {
+	Export       string `json:"export"`
+	Server       string `json:"server"`
+	ProtocolType string `json:"protocolType"`
+}
+
+type Volume struct {
+	Created           string            `json:"created,omitempty"`
+	LifeCycleState    string            `json:"lifeCycleState"`
+	VolumeID          string            `json:"volumeId"`
+	Name              string            `json:"name"`
+	CreationToken     string            `json:"creationToken"`
+	Region            string            `json:"region"`
+	ServiceLevel      string            `json:"serviceLevel"`
+	QuotaInBytes      int64             `json:"quotaInBytes"`
+	UsedBytes         int64             `json:"usedBytes"`
+	Network           string            `json:"network"`
+	ProtocolTypes     []string          `json:"protocolTypes"`
+	ExportPolicy      ExportPolicy      `json:"exportPolicy"`
+	SnapshotDirectory bool              `json:"snapshotDirectory"`
+	SnapshotID        string            `json:"snapshotId,omitempty"`
+	MountPoints       []MountPoint      `json:"mountPoints,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+}
+
+type VolumeCreateRequest struct {
+	Name              string            `json:"name"`
+	CreationToken     string            `json:"creationToken"`
+	Region            string            `json:"region"`
+	ServiceLevel      string            `json:"serviceLevel"`
+	QuotaInBytes      int64             `json:"quotaInBytes"`
+	Network           string            `json:"network"`
+	ProtocolTypes     []string          `json:"protocolTypes"`
+	ExportPolicy      ExportPolicy      `json:"exportPolicy"`
+	SnapshotDirectory bool              `json:"snapshotDirectory"`
+	SnapshotID        string            `json:"snapshotId,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+}
+
+type Snapshot struct {
+	Created        string `json:"created"`
+	LifeCycleState string `json:"lifeCycleState"`
+	SnapshotID     string `json:"snapshotId"`
+	Name           string `json:"name"`
+	VolumeID       string `json:"volumeId"`
+	Region         string `json:"region"`
+	UsedBytes      int64  `json:"usedBytes"`
+}
+
+type SnapshotCreateRequest struct {
+	Name     string `json:"name"`
+	VolumeID string `json:"volumeId"`
+	Region   string `json:"region"`
+}
@@ -0,0 +1,932 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/gcp/api"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	MinimumVolumeSizeBytes = 1099511627776   // 1 TiB
+	MaximumVolumeSizeBytes = 109951162777600 // 100 TiB
+
+	DefaultVolumeSize      = "1TiB"
+	DefaultServiceLevel    = api.UserServiceLevelStandard
+	DefaultNetwork         = "default"
+	DefaultNfsMountOptions = "-o nfsvers=3"
+	DefaultSnapshotDir     = "false"
+	DefaultExportRule      = "0.0.0.0/0"
+
+	// Cloud Volumes Service works in the background, so allow it some time to finish
+	volumeCreateTimeout   = 10 * time.Minute
+	volumeDeleteTimeout   = 60 * time.Second
+	snapshotCreateTimeout = 5 * time.Minute
+)
+
+// volumeNameRegex matches the creation tokens Cloud Volumes Service accepts, which are also the
+// paths at which the volumes are exported.
+var volumeNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,79}$`)
+
+// NFSStorageDriver is for storage provisioning using the Cloud Volumes Service for GCP.
+type NFSStorageDriver struct {
+	initialized bool
+	Config      drivers.GCPNFSStorageDriverConfig
+	API         *api.Client
+}
+
+type NFSStorageDriverConfigExternal struct {
+	*drivers.CommonStorageDriverConfigExternal
+	ProjectNumber string `json:"projectNumber"`
+	APIRegion     string `json:"apiRegion"`
+	Network       string `json:"network"`
+	ServiceLevel  string `json:"serviceLevel"`
+}
+
+func (d *NFSStorageDriver) Name() string {
+	return drivers.GCPNFSStorageDriverName
+}
+
+func (d *NFSStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *NFSStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.API.SetDebugTraceFlags(flags)
+}
+
+// Initialize from the provided config
+func (d *NFSStorageDriver) Initialize(
+	context trident.DriverContext, configJSON string, commonConfig *drivers.CommonStorageDriverConfig,
+) error {
+
+	// Trace logging hasn't been set up yet, so always do it here
+	fields := log.Fields{
+		"Method": "Initialize",
+		"Type":   "NFSStorageDriver",
+	}
+	log.WithFields(fields).Debug(">>>> Initialize")
+	defer log.WithFields(fields).Debug("<<<< Initialize")
+
+	commonConfig.DriverContext = context
+
+	config := &drivers.GCPNFSStorageDriverConfig{}
+	config.CommonStorageDriverConfig = commonConfig
+
+	// Decode configJSON into GCPNFSStorageDriverConfig object
+	err := json.Unmarshal([]byte(configJSON), &config)
+	if err != nil {
+		return fmt.Errorf("could not decode JSON configuration: %v", err)
+	}
+
+	// Apply config defaults
+	err = d.populateConfigurationDefaults(config)
+	if err != nil {
+		return fmt.Errorf("could not populate configuration defaults: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"Version":           config.Version,
+		"StorageDriverName": config.StorageDriverName,
+		"DebugTraceFlags":   config.DebugTraceFlags,
+		"DisableDelete":     config.DisableDelete,
+		"StoragePrefix":     *config.StoragePrefix,
+	}).Debug("Reparsed into GCPNFSStorageDriverConfig")
+
+	d.Config = *config
+
+	// Ensure the config is valid
+	err = d.validate()
+	if err != nil {
+		return fmt.Errorf("could not validate NFSStorageDriver config: %v", err)
+	}
+
+	d.API = api.NewDriver(api.ClientConfig{
+		ProjectNumber:   config.ProjectNumber,
+		APIKey:          config.APIKey,
+		APIRegion:       config.APIRegion,
+		APIURL:          config.APIURL,
+		APIAudience:     config.APIAudience,
+		DebugTraceFlags: config.DebugTraceFlags,
+	})
+
+	// Make sure the service account can authenticate
+	if err = d.API.Init(); err != nil {
+		return fmt.Errorf("could not initialize Cloud Volumes Service client: %v", err)
+	}
+
+	d.initialized = true
+	return nil
+}
+
+func (d *NFSStorageDriver) Initialized() bool {
+	return d.initialized
+}
+
+func (d *NFSStorageDriver) Terminate() {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "Terminate", "Type": "NFSStorageDriver"}
+		log.WithFields(fields).Debug(">>>> Terminate")
+		defer log.WithFields(fields).Debug("<<<< Terminate")
+	}
+
+	d.initialized = false
+}
+
+// populateConfigurationDefaults fills in default values for configuration settings if not supplied in the config file
+func (d *NFSStorageDriver) populateConfigurationDefaults(config *drivers.GCPNFSStorageDriverConfig) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "populateConfigurationDefaults", "Type": "NFSStorageDriver"}
+		log.WithFields(fields).Debug(">>>> populateConfigurationDefaults")
+		defer log.WithFields(fields).Debug("<<<< populateConfigurationDefaults")
+	}
+
+	// Cloud Volumes Service disallows underscores, so drop the one in the default prefix
+	if config.StoragePrefix == nil {
+		prefix := strings.TrimRight(drivers.GetDefaultStoragePrefix(config.DriverContext), "_")
+		config.StoragePrefix = &prefix
+	}
+	if config.Network == "" {
+		config.Network = DefaultNetwork
+	}
+	if config.NfsMountOptions == "" {
+		config.NfsMountOptions = DefaultNfsMountOptions
+	} else if err := utils.ValidateNFSMountOptions(config.NfsMountOptions); err != nil {
+		return err
+	}
+	if config.SnapshotDir == "" {
+		config.SnapshotDir = DefaultSnapshotDir
+	}
+	if config.ExportRule == "" {
+		config.ExportRule = DefaultExportRule
+	}
+
+	// Ensure the default volume size is valid, using a "default default" of 1 TiB if not set
+	if config.Size == "" {
+		config.Size = DefaultVolumeSize
+	} else {
+		_, err := utils.ConvertSizeToBytes(config.Size)
+		if err != nil {
+			return fmt.Errorf("invalid config value for default volume size: %v", err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"StoragePrefix":   *config.StoragePrefix,
+		"Network":         config.Network,
+		"NfsMountOptions": config.NfsMountOptions,
+		"SnapshotDir":     config.SnapshotDir,
+		"ExportRule":      config.ExportRule,
+		"Size":            config.Size,
+	}).Debugf("Configuration defaults")
+
+	return nil
+}
+
+// validate ensures the driver configuration and execution environment are valid and working
+func (d *NFSStorageDriver) validate() error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "validate", "Type": "NFSStorageDriver"}
+		log.WithFields(fields).Debug(">>>> validate")
+		defer log.WithFields(fields).Debug("<<<< validate")
+	}
+
+	// Make sure the essential information was specified in the config
+	if d.Config.ProjectNumber == "" {
+		return errors.New("projectNumber is empty; you must specify the number of the GCP project")
+	}
+	if d.Config.APIRegion == "" {
+		return errors.New("apiRegion is empty; you must specify the GCP region of the volumes")
+	}
+	if d.Config.APIKey.PrivateKey == "" || d.Config.APIKey.ClientEmail == "" || d.Config.APIKey.TokenURI == "" {
+		return errors.New("apiKey is incomplete; you must specify the JSON key of a GCP service account")
+	}
+
+	if d.Config.ServiceLevel != "" {
+		if _, err := api.GCPServiceLevelFromUserServiceLevel(d.Config.ServiceLevel); err != nil {
+			return err
+		}
+		d.Config.ServiceLevel = strings.ToLower(d.Config.ServiceLevel)
+	}
+
+	if *d.Config.StoragePrefix != "" && !volumeNameRegex.MatchString(*d.Config.StoragePrefix) {
+		return fmt.Errorf("storage prefix may only contain letters, numbers, and hyphens, and must begin "+
+			"with a letter: %s", *d.Config.StoragePrefix)
+	}
+
+	if _, err := strconv.ParseBool(d.Config.SnapshotDir); err != nil {
+		return fmt.Errorf("invalid boolean value for snapshotDir: %v", err)
+	}
+
+	if _, err := parseExportRule(d.Config.ExportRule); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseExportRule checks that an export rule is a comma-separated list of IP addresses and CIDR
+// blocks, returning it in the form the API expects.
+func parseExportRule(rule string) (string, error) {
+
+	clients := make([]string, 0)
+	for _, client := range strings.Split(rule, ",") {
+		client = strings.TrimSpace(client)
+		if client == "" {
+			continue
+		}
+		if net.ParseIP(client) == nil {
+			if _, _, err := net.ParseCIDR(client); err != nil {
+				return "", fmt.Errorf("%s in the export rule is neither an IP address nor a CIDR block", client)
+			}
+		}
+		clients = append(clients, client)
+	}
+
+	if len(clients) == 0 {
+		return "", errors.New("the export rule must allow at least one client")
+	}
+	return strings.Join(clients, ","), nil
+}
+
+// exportPolicy returns an export policy that allows the clients in an export rule read-write
+// access over NFSv3.
+func exportPolicy(allowedClients string) api.ExportPolicy {
+	return api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{
+				Access:         "ReadWrite",
+				AllowedClients: allowedClients,
+				NFSv3:          api.Checked{Checked: true},
+				NFSv4:          api.Checked{Checked: false},
+			},
+		},
+	}
+}
+
+// network returns the full name of the VPC network to which volumes are attached.
+func (d *NFSStorageDriver) network() string {
+	return fmt.Sprintf("projects/%s/global/networks/%s", d.Config.ProjectNumber, d.Config.Network)
+}
+
+// volumePrefix returns the part of a creation token that marks a volume as belonging to this backend.
+func (d *NFSStorageDriver) volumePrefix() string {
+	if *d.Config.StoragePrefix == "" {
+		return ""
+	}
+	return *d.Config.StoragePrefix + "-"
+}
+
+// GetVolumeSize returns the size of the volume that Create makes for a requested size.
+func (d *NFSStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, d.Config.CommonStorageDriverConfig)
+	if sizeBytes < MinimumVolumeSizeBytes || sizeBytes > MaximumVolumeSizeBytes {
+		return 0, drivers.NewFatalError(fmt.Sprintf("requested volume size (%d bytes) is outside the range "+
+			"Cloud Volumes Service allows (%d to %d bytes)", sizeBytes, MinimumVolumeSizeBytes,
+			MaximumVolumeSizeBytes))
+	}
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
+// Create a volume with the specified options
+func (d *NFSStorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "Create",
+			"Type":      "NFSStorageDriver",
+			"name":      name,
+			"sizeBytes": sizeBytes,
+			"opts":      opts,
+		}
+		log.WithFields(fields).Debug(">>>> Create")
+		defer log.WithFields(fields).Debug("<<<< Create")
+	}
+
+	client := d.API.WithContext(ctx)
+
+	if !volumeNameRegex.MatchString(name) {
+		return drivers.NewFatalError(fmt.Sprintf("volume name %s is invalid; names may only contain letters, "+
+			"numbers, and hyphens, and must begin with a letter", name))
+	}
+
+	// If the volume already exists, an earlier attempt may still be creating it
+	volExists, extantVolume, err := client.VolumeExistsByCreationToken(name)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume: %v", err)
+	}
+	if volExists {
+		if extantVolume.LifeCycleState == api.StateCreating {
+			_, err = client.WaitForVolumeState(extantVolume, api.StateAvailable, []string{api.StateError},
+				volumeCreateTimeout)
+			return err
+		}
+		return fmt.Errorf("volume %s already exists", name)
+	}
+
+	if sizeBytes, err = d.GetVolumeSize(sizeBytes); err != nil {
+		return err
+	}
+
+	// get options with default fallback values
+	serviceLevel := utils.GetV(opts, "serviceLevel", d.Config.ServiceLevel)
+	if serviceLevel == "" {
+		serviceLevel = DefaultServiceLevel
+	}
+	snapshotDir := utils.GetV(opts, "snapshotDir", d.Config.SnapshotDir)
+	exportRule := utils.GetV(opts, "exportRule", d.Config.ExportRule)
+
+	apiServiceLevel, err := api.GCPServiceLevelFromUserServiceLevel(serviceLevel)
+	if err != nil {
+		return drivers.NewFatalError(err.Error())
+	}
+	enableSnapshotDir, err := strconv.ParseBool(snapshotDir)
+	if err != nil {
+		return drivers.NewFatalError(fmt.Sprintf("invalid boolean value for snapshotDir: %v", err))
+	}
+	allowedClients, err := parseExportRule(exportRule)
+	if err != nil {
+		return drivers.NewFatalError(err.Error())
+	}
+
+	log.WithFields(log.Fields{
+		"name":         name,
+		"size":         sizeBytes,
+		"serviceLevel": serviceLevel,
+		"snapshotDir":  enableSnapshotDir,
+		"exportRule":   allowedClients,
+	}).Debug("Creating volume.")
+
+	createRequest := &api.VolumeCreateRequest{
+		Name:              name,
+		CreationToken:     name,
+		Region:            d.Config.APIRegion,
+		ServiceLevel:      apiServiceLevel,
+		QuotaInBytes:      int64(sizeBytes),
+		Network:           d.network(),
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		ExportPolicy:      exportPolicy(allowedClients),
+		SnapshotDirectory: enableSnapshotDir,
+	}
+
+	return d.createAndWait(client, createRequest)
+}
+
+// createAndWait issues a volume create request and waits for the new volume to become available.
+func (d *NFSStorageDriver) createAndWait(client *api.Client, createRequest *api.VolumeCreateRequest) error {
+
+	if err := client.CreateVolume(createRequest); err != nil {
+		return err
+	}
+
+	volume, err := client.GetVolumeByCreationToken(createRequest.CreationToken)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s after creating it: %v", createRequest.CreationToken, err)
+	}
+
+	_, err = client.WaitForVolumeState(volume, api.StateAvailable, []string{api.StateError}, volumeCreateTimeout)
+	return err
+}
+
+// CreateClone creates a new volume from a snapshot of the source volume.  If no snapshot is named,
+// one is created for the purpose.
+func (d *NFSStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":   "CreateClone",
+			"Type":     "NFSStorageDriver",
+			"name":     name,
+			"source":   source,
+			"snapshot": snapshot,
+		}
+		log.WithFields(fields).Debug(">>>> CreateClone")
+		defer log.WithFields(fields).Debug("<<<< CreateClone")
+	}
+
+	client := d.API.WithContext(ctx)
+
+	if !volumeNameRegex.MatchString(name) {
+		return drivers.NewFatalError(fmt.Sprintf("volume name %s is invalid; names may only contain letters, "+
+			"numbers, and hyphens, and must begin with a letter", name))
+	}
+
+	volExists, _, err := client.VolumeExistsByCreationToken(name)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume: %v", err)
+	}
+	if volExists {
+		return fmt.Errorf("volume %s already exists", name)
+	}
+
+	sourceVolume, err := client.GetVolumeByCreationToken(source)
+	if err != nil {
+		return fmt.Errorf("could not find source volume %s: %v", source, err)
+	}
+
+	// Clones are made from snapshots, so take one if the caller didn't name one
+	if snapshot == "" {
+		snapshot = name
+		snapshotRequest := &api.SnapshotCreateRequest{
+			Name:     snapshot,
+			VolumeID: sourceVolume.VolumeID,
+			Region:   sourceVolume.Region,
+		}
+		if err = client.CreateSnapshot(snapshotRequest); err != nil {
+			return err
+		}
+		if err = client.WaitForSnapshotAvailable(sourceVolume, snapshot, snapshotCreateTimeout); err != nil {
+			return err
+		}
+	}
+
+	sourceSnapshot, err := client.GetSnapshotForVolume(sourceVolume, snapshot)
+	if err != nil {
+		return fmt.Errorf("could not find snapshot %s of volume %s: %v", snapshot, source, err)
+	}
+
+	log.WithFields(log.Fields{
+		"name":     name,
+		"source":   source,
+		"snapshot": snapshot,
+	}).Debug("Cloning volume.")
+
+	createRequest := &api.VolumeCreateRequest{
+		Name:              name,
+		CreationToken:     name,
+		Region:            sourceVolume.Region,
+		ServiceLevel:      sourceVolume.ServiceLevel,
+		QuotaInBytes:      sourceVolume.QuotaInBytes,
+		Network:           sourceVolume.Network,
+		ProtocolTypes:     sourceVolume.ProtocolTypes,
+		ExportPolicy:      sourceVolume.ExportPolicy,
+		SnapshotDirectory: sourceVolume.SnapshotDirectory,
+		SnapshotID:        sourceSnapshot.SnapshotID,
+	}
+
+	return d.createAndWait(client, createRequest)
+}
+
+// Destroy the volume
+func (d *NFSStorageDriver) Destroy(ctx context.Context, name string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "Destroy",
+			"Type":   "NFSStorageDriver",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> Destroy")
+		defer log.WithFields(fields).Debug("<<<< Destroy")
+	}
+
+	client := d.API.WithContext(ctx)
+
+	// It's not an error if the volume no longer exists
+	volExists, extantVolume, err := client.VolumeExistsByCreationToken(name)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume: %v", err)
+	}
+	if !volExists {
+		log.WithField("volume", name).Warn("Volume already deleted.")
+		return nil
+	}
+
+	if extantVolume.LifeCycleState != api.StateDeleting {
+		if err = client.DeleteVolume(extantVolume); err != nil {
+			return err
+		}
+	}
+
+	_, err = client.WaitForVolumeState(extantVolume, api.StateDeleted, []string{api.StateError}, volumeDeleteTimeout)
+	return err
+}
+
+// Attach the volume
+func (d *NFSStorageDriver) Attach(name, mountpoint string, opts map[string]string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "Attach",
+			"Type":       "NFSStorageDriver",
+			"name":       name,
+			"mountpoint": mountpoint,
+			"opts":       opts,
+		}
+		log.WithFields(fields).Debug(">>>> Attach")
+		defer log.WithFields(fields).Debug("<<<< Attach")
+	}
+
+	volume, err := d.API.GetVolumeByCreationToken(name)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s: %v", name, err)
+	}
+	if len(volume.MountPoints) == 0 {
+		return fmt.Errorf("volume %s has no mount targets", name)
+	}
+
+	exportPath := fmt.Sprintf("%s:%s", volume.MountPoints[0].Server, volume.MountPoints[0].Export)
+
+	return d.mountVolume(exportPath, mountpoint)
+}
+
+// Detach the volume
+func (d *NFSStorageDriver) Detach(name, mountpoint string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "Detach",
+			"Type":       "NFSStorageDriver",
+			"name":       name,
+			"mountpoint": mountpoint,
+		}
+		log.WithFields(fields).Debug(">>>> Detach")
+		defer log.WithFields(fields).Debug("<<<< Detach")
+	}
+
+	return d.unmountVolume(mountpoint)
+}
+
+// mountVolume mounts an NFS export on the specified mountpoint.
+func (d *NFSStorageDriver) mountVolume(exportPath, mountpoint string) error {
+	return utils.MountNFS(exportPath, mountpoint, d.Config.NfsMountOptions)
+}
+
+// unmountVolume unmounts the volume mounted on the specified mountpoint.
+func (d *NFSStorageDriver) unmountVolume(mountpoint string) error {
+
+	cmd := fmt.Sprintf("umount %s", mountpoint)
+	log.WithField("command", cmd).Debug("Unmounting volume.")
+
+	if out, err := exec.Command("sh", "-c", cmd).CombinedOutput(); err != nil {
+		log.WithField("output", string(out)).Debug("Unmount failed.")
+		return fmt.Errorf("error unmounting NFS volume from mountpoint %v: %v", mountpoint, err)
+	}
+
+	return nil
+}
+
+// Return the list of snapshots associated with the named volume
+func (d *NFSStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "SnapshotList",
+			"Type":   "NFSStorageDriver",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> SnapshotList")
+		defer log.WithFields(fields).Debug("<<<< SnapshotList")
+	}
+
+	volume, err := d.API.GetVolumeByCreationToken(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s: %v", name, err)
+	}
+
+	snapshots, err := d.API.GetSnapshotsForVolume(volume)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotList := make([]storage.Snapshot, 0)
+	for _, snapshot := range *snapshots {
+		if snapshot.LifeCycleState != api.StateAvailable {
+			continue
+		}
+		snapshotList = append(snapshotList, storage.Snapshot{Name: snapshot.Name, Created: snapshot.Created})
+	}
+
+	return snapshotList, nil
+}
+
+// CreateSnapshot takes a snapshot of the named volume and waits for it to become available
+func (d *NFSStorageDriver) CreateSnapshot(snapshotName, volumeName string) (*storage.Snapshot, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "CreateSnapshot",
+			"Type":         "NFSStorageDriver",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> CreateSnapshot")
+		defer log.WithFields(fields).Debug("<<<< CreateSnapshot")
+	}
+
+	volume, err := d.API.GetVolumeByCreationToken(volumeName)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s: %v", volumeName, err)
+	}
+
+	snapshotRequest := &api.SnapshotCreateRequest{
+		Name:     snapshotName,
+		VolumeID: volume.VolumeID,
+		Region:   volume.Region,
+	}
+	if err = d.API.CreateSnapshot(snapshotRequest); err != nil {
+		return nil, err
+	}
+	if err = d.API.WaitForSnapshotAvailable(volume, snapshotName, snapshotCreateTimeout); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := d.API.GetSnapshotForVolume(volume, snapshotName)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Snapshot{Name: snapshot.Name, Created: snapshot.Created}, nil
+}
+
+// DeleteSnapshot deletes a snapshot of the named volume
+func (d *NFSStorageDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "DeleteSnapshot",
+			"Type":         "NFSStorageDriver",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> DeleteSnapshot")
+		defer log.WithFields(fields).Debug("<<<< DeleteSnapshot")
+	}
+
+	volume, err := d.API.GetVolumeByCreationToken(volumeName)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s: %v", volumeName, err)
+	}
+
+	snapshot, err := d.API.GetSnapshotForVolume(volume, snapshotName)
+	if err != nil {
+		return err
+	}
+	return d.API.DeleteSnapshot(volume, snapshot)
+}
+
+// Return the list of volumes associated with this tenant
+func (d *NFSStorageDriver) List() ([]string, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "List", "Type": "NFSStorageDriver"}
+		log.WithFields(fields).Debug(">>>> List")
+		defer log.WithFields(fields).Debug("<<<< List")
+	}
+
+	volumes, err := d.API.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := d.volumePrefix()
+	var volumeNames []string
+
+	for _, volume := range *volumes {
+		if strings.HasPrefix(volume.CreationToken, prefix) {
+			volumeNames = append(volumeNames, volume.CreationToken[len(prefix):])
+		}
+	}
+
+	return volumeNames, nil
+}
+
+// Test for the existence of a volume
+func (d *NFSStorageDriver) Get(name string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "Get", "Type": "NFSStorageDriver"}
+		log.WithFields(fields).Debug(">>>> Get")
+		defer log.WithFields(fields).Debug("<<<< Get")
+	}
+
+	_, err := d.API.GetVolumeByCreationToken(name)
+	return err
+}
+
+// GetStorageBackendSpecs offers a storage pool for each service level, or for just the one named
+// in the config.
+func (d *NFSStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
+
+	backend.Name = "gcpcvs_" + d.Config.ProjectNumber + "_" + d.Config.APIRegion
+
+	serviceLevels := []string{
+		api.UserServiceLevelStandard,
+		api.UserServiceLevelPremium,
+		api.UserServiceLevelExtreme,
+	}
+	if d.Config.ServiceLevel != "" {
+		serviceLevels = []string{d.Config.ServiceLevel}
+	}
+
+	for _, serviceLevel := range serviceLevels {
+		pool := storage.NewStoragePool(backend, serviceLevel)
+
+		pool.Attributes[sa.BackendType] = sa.NewStringOffer(d.Name())
+		pool.Attributes[sa.Snapshots] = sa.NewBoolOffer(true)
+		pool.Attributes[sa.Clones] = sa.NewBoolOffer(true)
+		pool.Attributes[sa.Encryption] = sa.NewBoolOffer(true)
+		pool.Attributes[sa.Resize] = sa.NewBoolOffer(false)
+		pool.Attributes[sa.QoS] = sa.NewBoolOffer(false)
+		pool.Attributes[sa.Replication] = sa.NewBoolOffer(false)
+		pool.Attributes[sa.ProvisioningType] = sa.NewStringOffer("thin")
+
+		backend.AddStoragePool(pool)
+	}
+
+	return nil
+}
+
+func (d *NFSStorageDriver) GetVolumeOpts(
+	volConfig *storage.VolumeConfig,
+	pool *storage.Pool,
+	requests map[string]sa.Request,
+) (map[string]string, error) {
+
+	opts := make(map[string]string)
+	if pool != nil {
+		opts["serviceLevel"] = pool.Name
+	}
+	if volConfig.SnapshotDir != "" {
+		opts["snapshotDir"] = volConfig.SnapshotDir
+	}
+	return opts, nil
+}
+
+func (d *NFSStorageDriver) GetInternalVolumeName(name string) string {
+
+	if trident.UsingPassthroughStore {
+		// With a passthrough store, the name mapping must remain reversible
+		return d.volumePrefix() + name
+	} else {
+		// With an external store, any transformation of the name is fine
+		internal := drivers.GetCommonInternalVolumeName(d.Config.CommonStorageDriverConfig, name)
+		internal = strings.Replace(internal, "_", "-", -1)  // CVS disallows underscores
+		internal = strings.Replace(internal, ".", "-", -1)  // CVS disallows periods
+		internal = strings.Replace(internal, "--", "-", -1) // Remove any double hyphens
+		return internal
+	}
+}
+
+func (d *NFSStorageDriver) CreatePrepare(volConfig *storage.VolumeConfig) bool {
+
+	volConfig.InternalName = d.GetInternalVolumeName(volConfig.Name)
+
+	if volConfig.CloneSourceVolume != "" {
+		volConfig.CloneSourceVolumeInternal = d.GetInternalVolumeName(volConfig.CloneSourceVolume)
+	}
+
+	return true
+}
+
+func (d *NFSStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "CreateFollowup",
+			"Type":   "NFSStorageDriver",
+			"name":   volConfig.InternalName,
+		}
+		log.WithFields(fields).Debug(">>>> CreateFollowup")
+		defer log.WithFields(fields).Debug("<<<< CreateFollowup")
+	}
+
+	volume, err := d.API.GetVolumeByCreationToken(volConfig.InternalName)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s: %v", volConfig.InternalName, err)
+	}
+	if volume.LifeCycleState != api.StateAvailable {
+		return fmt.Errorf("volume %s is in the %s state", volConfig.InternalName, volume.LifeCycleState)
+	}
+	if len(volume.MountPoints) == 0 {
+		return fmt.Errorf("volume %s has no mount targets", volConfig.InternalName)
+	}
+
+	volConfig.AccessInfo.NfsServerIP = volume.MountPoints[0].Server
+	volConfig.AccessInfo.NfsPath = volume.MountPoints[0].Export
+	volConfig.FileSystem = ""
+
+	return nil
+}
+
+func (d *NFSStorageDriver) GetProtocol() trident.Protocol {
+	return trident.File
+}
+
+func (d *NFSStorageDriver) StoreConfig(b *storage.PersistentStorageBackendConfig) {
+	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
+	b.GCPConfig = &d.Config
+}
+
+func (d *NFSStorageDriver) GetExternalConfig() interface{} {
+
+	return &NFSStorageDriverConfigExternal{
+		CommonStorageDriverConfigExternal: drivers.GetCommonStorageDriverConfigExternal(
+			d.Config.CommonStorageDriverConfig),
+		ProjectNumber: d.Config.ProjectNumber,
+		APIRegion:     d.Config.APIRegion,
+		Network:       d.Config.Network,
+		ServiceLevel:  d.Config.ServiceLevel,
+	}
+}
+
+// GetVolumeExternal queries the storage backend for all relevant info about
+// a single container volume managed by this driver and returns a VolumeExternal
+// representation of the volume.
+func (d *NFSStorageDriver) GetVolumeExternal(name string) (*storage.VolumeExternal, error) {
+
+	volume, err := d.API.GetVolumeByCreationToken(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.getVolumeExternal(volume), nil
+}
+
+// GetVolumeExternalWrappers queries the storage backend for all relevant info about
+// container volumes managed by this driver.  It then writes a VolumeExternal
+// representation of each volume to the supplied channel, closing the channel
+// when finished.
+func (d *NFSStorageDriver) GetVolumeExternalWrappers(channel chan *storage.VolumeExternalWrapper) {
+
+	// Let the caller know we're done by closing the channel
+	defer close(channel)
+
+	volumes, err := d.API.GetVolumes()
+	if err != nil {
+		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
+		return
+	}
+
+	// Convert all volumes belonging to this backend to VolumeExternal and write them to the channel
+	prefix := d.volumePrefix()
+	for _, volume := range *volumes {
+		if strings.HasPrefix(volume.CreationToken, prefix) {
+			v := volume
+			channel <- &storage.VolumeExternalWrapper{Volume: d.getVolumeExternal(&v), Error: nil}
+		}
+	}
+}
+
+// getVolumeExternal is a private method that accepts info about a volume
+// as returned by the storage backend and formats it as a VolumeExternal
+// object.
+func (d *NFSStorageDriver) getVolumeExternal(volume *api.Volume) *storage.VolumeExternal {
+
+	internalName := volume.CreationToken
+	name := strings.TrimPrefix(internalName, d.volumePrefix())
+
+	volumeConfig := &storage.VolumeConfig{
+		Version:      trident.OrchestratorAPIVersion,
+		Name:         name,
+		InternalName: internalName,
+		Size:         strconv.FormatInt(volume.QuotaInBytes, 10),
+		Protocol:     trident.File,
+		SnapshotDir:  strconv.FormatBool(volume.SnapshotDirectory),
+		AccessMode:   trident.ReadWriteMany,
+	}
+	if len(volume.MountPoints) > 0 {
+		volumeConfig.AccessInfo.NfsServerIP = volume.MountPoints[0].Server
+		volumeConfig.AccessInfo.NfsPath = volume.MountPoints[0].Export
+	}
+
+	state := volume.LifeCycleState
+	if state == api.StateAvailable {
+		state = storage.VolumeStateOnline
+	}
+
+	return &storage.VolumeExternal{
+		Config: volumeConfig,
+		Pool:   api.UserServiceLevelFromGCPServiceLevel(volume.ServiceLevel),
+		State:  state,
+	}
+}
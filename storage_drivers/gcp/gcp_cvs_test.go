@@ -0,0 +1,129 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package gcp
+
+import (
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/gcp/api"
+)
+
+func newTestDriver(prefix string) *NFSStorageDriver {
+	d := &NFSStorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix}
+	d.Config.ProjectNumber = "123"
+	d.Config.APIRegion = "us-central1"
+	d.Config.APIKey = api.ServiceAccountKey{PrivateKey: "key", ClientEmail: "a@b.c", TokenURI: "https://token"}
+	d.Config.SnapshotDir = "false"
+	d.Config.ExportRule = DefaultExportRule
+	return d
+}
+
+func TestParseExportRule(t *testing.T) {
+	for _, test := range []struct {
+		rule     string
+		expected string
+		valid    bool
+	}{
+		{rule: "0.0.0.0/0", expected: "0.0.0.0/0", valid: true},
+		{rule: "10.0.0.0/24, 192.168.1.5", expected: "10.0.0.0/24,192.168.1.5", valid: true},
+		{rule: "10.0.0.0/33", valid: false},
+		{rule: "host.example.com", valid: false},
+		{rule: " , ", valid: false},
+	} {
+		got, err := parseExportRule(test.rule)
+		if test.valid && (err != nil || got != test.expected) {
+			t.Errorf("Expected %q for %q, got %q (%v)", test.expected, test.rule, got, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected an error for %q.", test.rule)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := newTestDriver("trident")
+	d.Config.ServiceLevel = "Premium"
+	if err := d.validate(); err != nil {
+		t.Fatal("Unexpected error validating config: ", err)
+	}
+	if d.Config.ServiceLevel != api.UserServiceLevelPremium {
+		t.Errorf("Expected service level to be normalized, got %s", d.Config.ServiceLevel)
+	}
+
+	for name, breakConfig := range map[string]func(d *NFSStorageDriver){
+		"projectNumber": func(d *NFSStorageDriver) { d.Config.ProjectNumber = "" },
+		"apiKey":        func(d *NFSStorageDriver) { d.Config.APIKey.PrivateKey = "" },
+		"serviceLevel":  func(d *NFSStorageDriver) { d.Config.ServiceLevel = "gold" },
+		"storagePrefix": func(d *NFSStorageDriver) { *d.Config.StoragePrefix = "trident_" },
+		"exportRule":    func(d *NFSStorageDriver) { d.Config.ExportRule = "everyone" },
+	} {
+		d := newTestDriver("trident")
+		breakConfig(d)
+		if err := d.validate(); err == nil {
+			t.Errorf("Expected an error for an invalid %s.", name)
+		}
+	}
+}
+
+func TestGetInternalVolumeName(t *testing.T) {
+	d := newTestDriver("trident")
+
+	trident.UsingPassthroughStore = false
+	if got := d.GetInternalVolumeName("pvc_1.a"); got != "trident-pvc-1-a" {
+		t.Errorf("Expected trident-pvc-1-a, got %s", got)
+	}
+
+	trident.UsingPassthroughStore = true
+	defer func() { trident.UsingPassthroughStore = false }()
+	if got := d.GetInternalVolumeName("myvol"); got != "trident-myvol" {
+		t.Errorf("Expected trident-myvol, got %s", got)
+	}
+}
+
+func TestGetStorageBackendSpecs(t *testing.T) {
+	d := newTestDriver("trident")
+	backend := &storage.Backend{Storage: make(map[string]*storage.Pool)}
+	if err := d.GetStorageBackendSpecs(backend); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	for _, level := range []string{"standard", "premium", "extreme"} {
+		if _, ok := backend.Storage[level]; !ok {
+			t.Errorf("Expected a pool for service level %s.", level)
+		}
+	}
+
+	d.Config.ServiceLevel = "extreme"
+	backend = &storage.Backend{Storage: make(map[string]*storage.Pool)}
+	d.GetStorageBackendSpecs(backend)
+	if len(backend.Storage) != 1 || backend.Storage["extreme"] == nil {
+		t.Errorf("Expected only the configured service level, got %v", backend.Storage)
+	}
+}
+
+func TestGetVolumeExternal(t *testing.T) {
+	d := newTestDriver("trident")
+	volume := &api.Volume{
+		CreationToken:     "trident-pvc-1",
+		ServiceLevel:      api.APIServiceLevelBasic,
+		QuotaInBytes:      MinimumVolumeSizeBytes,
+		SnapshotDirectory: true,
+		MountPoints:       []api.MountPoint{{Server: "10.0.0.4", Export: "/trident-pvc-1"}},
+	}
+
+	external := d.getVolumeExternal(volume)
+	if external.Config.Name != "pvc-1" || external.Config.InternalName != "trident-pvc-1" {
+		t.Errorf("Unexpected names %s and %s", external.Config.Name, external.Config.InternalName)
+	}
+	if external.Pool != api.UserServiceLevelStandard {
+		t.Errorf("Expected pool %s, got %s", api.UserServiceLevelStandard, external.Pool)
+	}
+	if external.Config.AccessInfo.NfsServerIP != "10.0.0.4" || external.Config.AccessInfo.NfsPath != "/trident-pvc-1" {
+		t.Errorf("Unexpected access info %+v", external.Config.AccessInfo)
+	}
+	if external.Config.Size != "1099511627776" || external.Config.SnapshotDir != "true" {
+		t.Errorf("Unexpected size %s or snapshot directory %s", external.Config.Size, external.Config.SnapshotDir)
+	}
+}
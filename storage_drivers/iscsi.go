@@ -0,0 +1,25 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+)
+
+// Values of iscsiDiscovery in a backend's config
+const (
+	ISCSIDiscoverySendTargets = "sendtargets"
+	ISCSIDiscoveryStatic      = "static"
+)
+
+// validateISCSIDiscovery ensures the iscsiDiscovery setting is one of the known values.  An empty
+// setting means the default, sendtargets.
+func validateISCSIDiscovery(value string) error {
+	switch value {
+	case "", ISCSIDiscoverySendTargets, ISCSIDiscoveryStatic:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for iscsiDiscovery: %s; it must be %s or %s",
+			value, ISCSIDiscoverySendTargets, ISCSIDiscoveryStatic)
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"testing"
+)
+
+func TestValidateISCSIDiscovery(t *testing.T) {
+	for _, value := range []string{"", ISCSIDiscoverySendTargets, ISCSIDiscoveryStatic} {
+		if err := validateISCSIDiscovery(value); err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+	if err := validateISCSIDiscovery("isns"); err == nil {
+		t.Error("Expected invalid iscsiDiscovery to be rejected")
+	}
+}
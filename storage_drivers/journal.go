@@ -0,0 +1,67 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+	"time"
+)
+
+// JournalEntry records the progress of a multi-step driver operation, such as a clone that
+// involves a snapshot, a clone, a mount, and a split.  If Trident stops partway through, the
+// entry tells the driver enough to complete the operation or to clean up after it.
+type JournalEntry struct {
+	Backend   string            `json:"backend"`
+	Operation string            `json:"operation"`
+	Volume    string            `json:"volume"`
+	Params    map[string]string `json:"params,omitempty"`
+	Steps     []string          `json:"steps"`
+	StartTime string            `json:"startTime"`
+}
+
+// NewJournalEntry returns an entry for an operation on the named volume that has not yet
+// completed any steps.
+func NewJournalEntry(operation, volume string, params map[string]string) *JournalEntry {
+	return &JournalEntry{
+		Operation: operation,
+		Volume:    volume,
+		Params:    params,
+		Steps:     make([]string, 0),
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// Key returns a unique identifier for the entry.  Volume names are unique within a backend, and
+// only one operation at a time may be in progress on a volume.
+func (e *JournalEntry) Key() string {
+	return fmt.Sprintf("%s.%s", e.Backend, e.Volume)
+}
+
+// HasStep returns true if the entry records that the named step was completed.
+func (e *JournalEntry) HasStep(step string) bool {
+	for _, s := range e.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Journal persists journal entries on behalf of a driver.
+type Journal interface {
+	// Record saves the entry, overwriting any earlier version of it.
+	Record(entry *JournalEntry) error
+	// Remove discards the entry once its operation has finished.
+	Remove(entry *JournalEntry) error
+}
+
+// RecordStep marks a step of a journaled operation as complete.  A nil journal is permitted, so
+// drivers needn't check whether journaling is enabled.  Failing to journal a step must not fail
+// the operation itself, so any error is returned only for logging.
+func RecordStep(journal Journal, entry *JournalEntry, step string) error {
+	if journal == nil || entry == nil {
+		return nil
+	}
+	entry.Steps = append(entry.Steps, step)
+	return journal.Record(entry)
+}
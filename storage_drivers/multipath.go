@@ -0,0 +1,63 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/utils"
+)
+
+// Values of multipathCheck in a backend's config
+const (
+	MultipathCheckWarn   = "warn"
+	MultipathCheckFail   = "fail"
+	MultipathCheckIgnore = "ignore"
+)
+
+// validateMultipathCheck ensures the multipathCheck setting is one of the known values.  An empty
+// setting means the default, warn.
+func validateMultipathCheck(value string) error {
+	switch value {
+	case "", MultipathCheckWarn, MultipathCheckFail, MultipathCheckIgnore:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for multipathCheck: %s; it must be %s, %s or %s",
+			value, MultipathCheckWarn, MultipathCheckFail, MultipathCheckIgnore)
+	}
+}
+
+// CheckHostMultipath inspects this host's multipath setup before an iSCSI volume is attached,
+// and warns about or rejects the attachment, as the backend's config directs, if the volume
+// would end up using a single path.
+func CheckHostMultipath(config *CommonStorageDriverConfig, device utils.MultipathDeviceType) error {
+
+	if config.MultipathCheck == MultipathCheckIgnore {
+		return nil
+	}
+	return applyMultipathCheck(config.MultipathCheck, utils.CheckMultipathConfiguration(device))
+}
+
+// applyMultipathCheck logs the problems found with a host's multipath setup, and returns an
+// error listing them if the multipathCheck setting is fail.
+func applyMultipathCheck(setting string, problems []string) error {
+
+	if len(problems) == 0 || setting == MultipathCheckIgnore {
+		return nil
+	}
+
+	if setting == MultipathCheckFail {
+		return fmt.Errorf("host multipath configuration is unsuitable for iSCSI volumes: %s; "+
+			"set multipathCheck to warn in the backend config to attach anyway",
+			strings.Join(problems, "; "))
+	}
+
+	for _, problem := range problems {
+		log.WithField("remediation", problem).Warning(
+			"Host multipath configuration is unsuitable; iSCSI volumes may use a single path.")
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"testing"
+)
+
+func TestValidateMultipathCheck(t *testing.T) {
+	for _, value := range []string{"", MultipathCheckWarn, MultipathCheckFail, MultipathCheckIgnore} {
+		if err := validateMultipathCheck(value); err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+	if err := validateMultipathCheck("strict"); err == nil {
+		t.Error("Expected invalid multipathCheck to be rejected")
+	}
+}
+
+func TestApplyMultipathCheck(t *testing.T) {
+	problems := []string{"multipathd is not running"}
+
+	for _, test := range []struct {
+		setting  string
+		problems []string
+		fails    bool
+	}{
+		{setting: "", problems: problems, fails: false},
+		{setting: MultipathCheckWarn, problems: problems, fails: false},
+		{setting: MultipathCheckIgnore, problems: problems, fails: false},
+		{setting: MultipathCheckFail, problems: problems, fails: true},
+		{setting: MultipathCheckFail, problems: []string{}, fails: false},
+	} {
+		err := applyMultipathCheck(test.setting, test.problems)
+		if test.fails && err == nil {
+			t.Errorf("Expected %q with problems %v to fail", test.setting, test.problems)
+		} else if !test.fails && err != nil {
+			t.Errorf("Expected %q with problems %v to pass: %v", test.setting, test.problems, err)
+		}
+	}
+}
@@ -0,0 +1,772 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package nfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	DefaultMountRoot       = "/var/lib/trident/nfs"
+	DefaultNfsMountOptions = "-o nfsvers=3"
+	DefaultUnixPermissions = "0777"
+
+	QuotaTypeNone = "none"
+	QuotaTypeXFS  = "xfs"
+
+	// The single storage pool offered by each backend
+	PoolName = "export"
+)
+
+// volumeNameRegex matches the volume names this driver will turn into directories
+var volumeNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// StorageDriver provisions volumes as subdirectories of an NFS export served by any NFS server.
+// Trident reaches the export through a local mount, either one the driver makes itself or one
+// named in the config, and clients mount the subdirectories directly.
+type StorageDriver struct {
+	initialized bool
+	mounted     bool // true if the driver mounted the export and should unmount it
+	Config      drivers.GenericNFSStorageDriverConfig
+}
+
+type StorageDriverConfigExternal struct {
+	*drivers.CommonStorageDriverConfigExternal
+	NfsServer  string `json:"nfsServer"`
+	NfsExport  string `json:"nfsExport"`
+	MountPoint string `json:"mountPoint"`
+	QuotaType  string `json:"quotaType"`
+}
+
+func (d *StorageDriver) Name() string {
+	return drivers.GenericNFSStorageDriverName
+}
+
+func (d *StorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *StorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+}
+
+// Initialize from the provided config
+func (d *StorageDriver) Initialize(
+	context trident.DriverContext, configJSON string, commonConfig *drivers.CommonStorageDriverConfig,
+) error {
+
+	// Trace logging hasn't been set up yet, so always do it here
+	fields := log.Fields{
+		"Method": "Initialize",
+		"Type":   "StorageDriver",
+	}
+	log.WithFields(fields).Debug(">>>> Initialize")
+	defer log.WithFields(fields).Debug("<<<< Initialize")
+
+	commonConfig.DriverContext = context
+
+	config := &drivers.GenericNFSStorageDriverConfig{}
+	config.CommonStorageDriverConfig = commonConfig
+
+	// Decode configJSON into GenericNFSStorageDriverConfig object
+	err := json.Unmarshal([]byte(configJSON), &config)
+	if err != nil {
+		return fmt.Errorf("could not decode JSON configuration: %v", err)
+	}
+
+	// Apply config defaults
+	err = d.populateConfigurationDefaults(config)
+	if err != nil {
+		return fmt.Errorf("could not populate configuration defaults: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"Version":           config.Version,
+		"StorageDriverName": config.StorageDriverName,
+		"DebugTraceFlags":   config.DebugTraceFlags,
+		"DisableDelete":     config.DisableDelete,
+		"StoragePrefix":     *config.StoragePrefix,
+	}).Debug("Reparsed into GenericNFSStorageDriverConfig")
+
+	d.Config = *config
+
+	// Ensure the config is valid
+	err = d.validate()
+	if err != nil {
+		return fmt.Errorf("could not validate StorageDriver config: %v", err)
+	}
+
+	// Reach the export through a mount of our own unless the config names one
+	if !isMountPoint(d.Config.MountPoint) {
+		if err = os.MkdirAll(d.Config.MountPoint, 0755); err != nil {
+			return fmt.Errorf("could not create mount point %s: %v", d.Config.MountPoint, err)
+		}
+		if err = d.mount(d.Config.NfsExport, d.Config.MountPoint); err != nil {
+			return err
+		}
+		d.mounted = true
+	}
+
+	if d.Config.QuotaType == QuotaTypeXFS {
+		if err = checkXFSQuota(d.Config.MountPoint); err != nil {
+			return err
+		}
+	}
+
+	d.initialized = true
+	return nil
+}
+
+func (d *StorageDriver) Initialized() bool {
+	return d.initialized
+}
+
+func (d *StorageDriver) Terminate() {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "Terminate", "Type": "StorageDriver"}
+		log.WithFields(fields).Debug(">>>> Terminate")
+		defer log.WithFields(fields).Debug("<<<< Terminate")
+	}
+
+	if d.mounted {
+		if err := d.unmount(d.Config.MountPoint); err != nil {
+			log.Warnf("Could not unmount NFS export. %v", err)
+		}
+		d.mounted = false
+	}
+
+	d.initialized = false
+}
+
+// populateConfigurationDefaults fills in default values for configuration settings if not supplied in the config file
+func (d *StorageDriver) populateConfigurationDefaults(config *drivers.GenericNFSStorageDriverConfig) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "populateConfigurationDefaults", "Type": "StorageDriver"}
+		log.WithFields(fields).Debug(">>>> populateConfigurationDefaults")
+		defer log.WithFields(fields).Debug("<<<< populateConfigurationDefaults")
+	}
+
+	if config.StoragePrefix == nil {
+		prefix := drivers.GetDefaultStoragePrefix(config.DriverContext)
+		config.StoragePrefix = &prefix
+	}
+	if config.NfsExport != "" {
+		config.NfsExport = path.Clean(config.NfsExport)
+	}
+	if config.MountPoint == "" {
+		mountName := strings.Replace(config.NfsServer+config.NfsExport, "/", "_", -1)
+		config.MountPoint = filepath.Join(DefaultMountRoot, mountName)
+	}
+	if config.NfsMountOptions == "" {
+		config.NfsMountOptions = DefaultNfsMountOptions
+	} else if err := utils.ValidateNFSMountOptions(config.NfsMountOptions); err != nil {
+		return err
+	}
+	if config.QuotaType == "" {
+		config.QuotaType = QuotaTypeNone
+	}
+	if config.UnixPermissions == "" {
+		config.UnixPermissions = DefaultUnixPermissions
+	}
+
+	// Ensure the default volume size is valid, using a "default default" of 1G if not set
+	if config.Size == "" {
+		config.Size = drivers.DefaultVolumeSize
+	} else {
+		_, err := utils.ConvertSizeToBytes(config.Size)
+		if err != nil {
+			return fmt.Errorf("invalid config value for default volume size: %v", err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"StoragePrefix":   *config.StoragePrefix,
+		"MountPoint":      config.MountPoint,
+		"NfsMountOptions": config.NfsMountOptions,
+		"QuotaType":       config.QuotaType,
+		"UnixPermissions": config.UnixPermissions,
+		"Size":            config.Size,
+	}).Debugf("Configuration defaults")
+
+	return nil
+}
+
+// validate ensures the driver configuration is valid
+func (d *StorageDriver) validate() error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "validate", "Type": "StorageDriver"}
+		log.WithFields(fields).Debug(">>>> validate")
+		defer log.WithFields(fields).Debug("<<<< validate")
+	}
+
+	// Make sure the essential information was specified in the config
+	if d.Config.NfsServer == "" {
+		return errors.New("nfsServer is empty; you must specify the host/IP of the NFS server")
+	}
+	if !path.IsAbs(d.Config.NfsExport) || d.Config.NfsExport == "/" {
+		return errors.New("nfsExport must be the absolute path of an exported directory other than /")
+	}
+
+	switch d.Config.QuotaType {
+	case QuotaTypeNone, QuotaTypeXFS:
+	default:
+		return fmt.Errorf("invalid value for quotaType: %s", d.Config.QuotaType)
+	}
+
+	if _, err := parseUnixPermissions(d.Config.UnixPermissions); err != nil {
+		return err
+	}
+
+	if *d.Config.StoragePrefix != "" && !volumeNameRegex.MatchString(*d.Config.StoragePrefix) {
+		return fmt.Errorf("storage prefix may only contain letters, numbers, underscores, hyphens, "+
+			"and periods: %s", *d.Config.StoragePrefix)
+	}
+
+	return nil
+}
+
+// parseUnixPermissions converts an octal permission string such as 0755 into a file mode.
+func parseUnixPermissions(permissions string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(permissions, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid value for unixPermissions: %s", permissions)
+	}
+	return os.FileMode(mode), nil
+}
+
+// isMountPoint returns true if the path is on a different device than its parent directory.
+func isMountPoint(dir string) bool {
+	var stat, parentStat syscall.Stat_t
+	if err := syscall.Stat(dir, &stat); err != nil {
+		return false
+	}
+	if err := syscall.Stat(filepath.Dir(filepath.Clean(dir)), &parentStat); err != nil {
+		return false
+	}
+	return stat.Dev != parentStat.Dev
+}
+
+// volumePath returns the local path of a volume's directory.
+func (d *StorageDriver) volumePath(name string) string {
+	return filepath.Join(d.Config.MountPoint, name)
+}
+
+// exportPath returns the path of a volume's directory on the NFS server.
+func (d *StorageDriver) exportPath(name string) string {
+	return path.Join(d.Config.NfsExport, name)
+}
+
+// checkVolumeName makes sure a name can't escape the export when used as a directory name.
+func checkVolumeName(name string) error {
+	if !volumeNameRegex.MatchString(name) || name == "." || name == ".." {
+		return drivers.NewFatalError(fmt.Sprintf("volume name %s is invalid; names may only contain letters, "+
+			"numbers, underscores, hyphens, and periods", name))
+	}
+	return nil
+}
+
+// GetVolumeSize returns the size of the volume directory that Create makes for a requested size.
+func (d *StorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, d.Config.CommonStorageDriverConfig)
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
+// Create a volume directory with the specified options
+func (d *StorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "Create",
+			"Type":      "StorageDriver",
+			"name":      name,
+			"sizeBytes": sizeBytes,
+			"opts":      opts,
+		}
+		log.WithFields(fields).Debug(">>>> Create")
+		defer log.WithFields(fields).Debug("<<<< Create")
+	}
+
+	if err := checkVolumeName(name); err != nil {
+		return err
+	}
+
+	sizeBytes, err := d.GetVolumeSize(sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	unixPermissions := utils.GetV(opts, "unixPermissions", d.Config.UnixPermissions)
+	mode, err := parseUnixPermissions(unixPermissions)
+	if err != nil {
+		return drivers.NewFatalError(err.Error())
+	}
+
+	volumePath := d.volumePath(name)
+	if utils.PathExists(volumePath) {
+		return fmt.Errorf("volume %s already exists", name)
+	}
+
+	log.WithFields(log.Fields{
+		"name":            name,
+		"size":            sizeBytes,
+		"unixPermissions": unixPermissions,
+		"quotaType":       d.Config.QuotaType,
+	}).Debug("Creating volume directory.")
+
+	if err = os.Mkdir(volumePath, mode); err != nil {
+		return fmt.Errorf("could not create volume directory %s: %v", volumePath, err)
+	}
+
+	// Mkdir is subject to the umask, so set the mode explicitly
+	if err = os.Chmod(volumePath, mode); err != nil {
+		return fmt.Errorf("could not set permissions of volume directory %s: %v", volumePath, err)
+	}
+
+	return d.setQuota(ctx, name, sizeBytes)
+}
+
+// CreateClone creates a new volume by copying the contents of the source volume.  Snapshots
+// aren't supported, so no snapshot may be named.
+func (d *StorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":   "CreateClone",
+			"Type":     "StorageDriver",
+			"name":     name,
+			"source":   source,
+			"snapshot": snapshot,
+		}
+		log.WithFields(fields).Debug(">>>> CreateClone")
+		defer log.WithFields(fields).Debug("<<<< CreateClone")
+	}
+
+	if snapshot != "" {
+		return drivers.NewUnsupportedError("cloning from snapshots with generic NFS is not supported")
+	}
+	if err := checkVolumeName(name); err != nil {
+		return err
+	}
+
+	sourcePath := d.volumePath(source)
+	if !utils.PathExists(sourcePath) {
+		return fmt.Errorf("source volume %s does not exist", source)
+	}
+	volumePath := d.volumePath(name)
+	if utils.PathExists(volumePath) {
+		return fmt.Errorf("volume %s already exists", name)
+	}
+
+	log.WithFields(log.Fields{
+		"name":   name,
+		"source": source,
+	}).Debug("Copying volume directory.")
+
+	// Copy into a temporary directory so that a partial copy is never mistaken for a clone
+	tempPath := d.volumePath("." + name + ".clone")
+	os.RemoveAll(tempPath)
+
+	if out, err := exec.CommandContext(ctx, "cp", "-a", sourcePath, tempPath).CombinedOutput(); err != nil {
+		os.RemoveAll(tempPath)
+		return fmt.Errorf("could not copy volume %s: %v; %s", source, err, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(tempPath, volumePath); err != nil {
+		os.RemoveAll(tempPath)
+		return fmt.Errorf("could not rename copy of volume %s: %v", source, err)
+	}
+
+	// Give the clone the same quota as its source
+	if d.Config.QuotaType == QuotaTypeXFS {
+		limit, err := getXFSQuota(ctx, d.Config.MountPoint, source)
+		if err != nil {
+			return err
+		}
+		return d.setQuota(ctx, name, limit)
+	}
+
+	return nil
+}
+
+// Destroy the volume
+func (d *StorageDriver) Destroy(ctx context.Context, name string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "Destroy",
+			"Type":   "StorageDriver",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> Destroy")
+		defer log.WithFields(fields).Debug("<<<< Destroy")
+	}
+
+	if err := checkVolumeName(name); err != nil {
+		return err
+	}
+
+	// It's not an error if the volume no longer exists
+	volumePath := d.volumePath(name)
+	if !utils.PathExists(volumePath) {
+		log.WithField("volume", name).Warn("Volume already deleted.")
+		return nil
+	}
+
+	if err := os.RemoveAll(volumePath); err != nil {
+		return fmt.Errorf("could not delete volume directory %s: %v", volumePath, err)
+	}
+
+	if d.Config.QuotaType == QuotaTypeXFS {
+		if err := setXFSQuota(ctx, d.Config.MountPoint, "", name, 0); err != nil {
+			log.WithField("volume", name).Warnf("Could not clear quota. %v", err)
+		}
+	}
+
+	return nil
+}
+
+// setQuota limits the size of a volume directory if the backend uses quotas.
+func (d *StorageDriver) setQuota(ctx context.Context, name string, sizeBytes uint64) error {
+
+	if d.Config.QuotaType != QuotaTypeXFS {
+		return nil
+	}
+
+	if err := setXFSQuota(ctx, d.Config.MountPoint, d.volumePath(name), name, sizeBytes); err != nil {
+		// Don't leave behind a volume with no limit
+		os.RemoveAll(d.volumePath(name))
+		return err
+	}
+	return nil
+}
+
+// Attach the volume
+func (d *StorageDriver) Attach(name, mountpoint string, opts map[string]string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "Attach",
+			"Type":       "StorageDriver",
+			"name":       name,
+			"mountpoint": mountpoint,
+			"opts":       opts,
+		}
+		log.WithFields(fields).Debug(">>>> Attach")
+		defer log.WithFields(fields).Debug("<<<< Attach")
+	}
+
+	return d.mount(d.exportPath(name), mountpoint)
+}
+
+// Detach the volume
+func (d *StorageDriver) Detach(name, mountpoint string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "Detach",
+			"Type":       "StorageDriver",
+			"name":       name,
+			"mountpoint": mountpoint,
+		}
+		log.WithFields(fields).Debug(">>>> Detach")
+		defer log.WithFields(fields).Debug("<<<< Detach")
+	}
+
+	return d.unmount(mountpoint)
+}
+
+// mount mounts a path exported by the NFS server on the specified mountpoint.
+func (d *StorageDriver) mount(exportPath, mountpoint string) error {
+
+	export := fmt.Sprintf("%s:%s", d.Config.NfsServer, exportPath)
+
+	return utils.MountNFS(export, mountpoint, d.Config.NfsMountOptions)
+}
+
+// unmount unmounts the volume mounted on the specified mountpoint.
+func (d *StorageDriver) unmount(mountpoint string) error {
+
+	cmd := fmt.Sprintf("umount %s", mountpoint)
+	log.WithField("command", cmd).Debug("Unmounting volume.")
+
+	if out, err := exec.Command("sh", "-c", cmd).CombinedOutput(); err != nil {
+		log.WithField("output", string(out)).Debug("Unmount failed.")
+		return fmt.Errorf("error unmounting NFS volume from mountpoint %v: %v", mountpoint, err)
+	}
+
+	return nil
+}
+
+// SnapshotList returns an empty list, since generic NFS volumes have no snapshots
+func (d *StorageDriver) SnapshotList(name string) ([]storage.Snapshot, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "SnapshotList",
+			"Type":   "StorageDriver",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> SnapshotList")
+		defer log.WithFields(fields).Debug("<<<< SnapshotList")
+	}
+
+	return make([]storage.Snapshot, 0), nil
+}
+
+// listVolumeDirs returns the names of the volume directories belonging to this backend.
+func (d *StorageDriver) listVolumeDirs() ([]string, error) {
+
+	entries, err := ioutil.ReadDir(d.Config.MountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not read export: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		// Skip files and incomplete clones
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), *d.Config.StoragePrefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Return the list of volumes associated with this tenant
+func (d *StorageDriver) List() ([]string, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "List", "Type": "StorageDriver"}
+		log.WithFields(fields).Debug(">>>> List")
+		defer log.WithFields(fields).Debug("<<<< List")
+	}
+
+	dirs, err := d.listVolumeDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var volumeNames []string
+	for _, dir := range dirs {
+		volumeNames = append(volumeNames, dir[len(*d.Config.StoragePrefix):])
+	}
+	return volumeNames, nil
+}
+
+// Test for the existence of a volume
+func (d *StorageDriver) Get(name string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "Get", "Type": "StorageDriver"}
+		log.WithFields(fields).Debug(">>>> Get")
+		defer log.WithFields(fields).Debug("<<<< Get")
+	}
+
+	if info, err := os.Stat(d.volumePath(name)); err != nil || !info.IsDir() {
+		return fmt.Errorf("volume %s does not exist", name)
+	}
+	return nil
+}
+
+// GetStorageBackendSpecs offers a single storage pool backed by the export.
+func (d *StorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
+
+	backend.Name = "nfs_" + d.Config.NfsServer + "_" + strings.Replace(strings.Trim(d.Config.NfsExport, "/"), "/", "_", -1)
+
+	pool := storage.NewStoragePool(backend, PoolName)
+
+	pool.Attributes[sa.BackendType] = sa.NewStringOffer(d.Name())
+	pool.Attributes[sa.Snapshots] = sa.NewBoolOffer(false)
+	pool.Attributes[sa.Clones] = sa.NewBoolOffer(true)
+	pool.Attributes[sa.Encryption] = sa.NewBoolOffer(false)
+	pool.Attributes[sa.Resize] = sa.NewBoolOffer(false)
+	pool.Attributes[sa.QoS] = sa.NewBoolOffer(false)
+	pool.Attributes[sa.Replication] = sa.NewBoolOffer(false)
+	pool.Attributes[sa.ProvisioningType] = sa.NewStringOffer("thin")
+
+	backend.AddStoragePool(pool)
+
+	return nil
+}
+
+// GetPoolCapacity reports the space in the filesystem holding the export.
+func (d *StorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(d.Config.MountPoint, &stat); err != nil {
+		return nil, fmt.Errorf("could not get filesystem statistics for %s: %v", d.Config.MountPoint, err)
+	}
+
+	blockSize := uint64(stat.Bsize)
+	return &storage.PoolCapacity{
+		TotalBytes:     uint64(stat.Blocks) * blockSize,
+		UsedBytes:      uint64(stat.Blocks-stat.Bfree) * blockSize,
+		AvailableBytes: uint64(stat.Bavail) * blockSize,
+	}, nil
+}
+
+func (d *StorageDriver) GetVolumeOpts(
+	volConfig *storage.VolumeConfig,
+	pool *storage.Pool,
+	requests map[string]sa.Request,
+) (map[string]string, error) {
+
+	opts := make(map[string]string)
+	if volConfig.UnixPermissions != "" {
+		opts["unixPermissions"] = volConfig.UnixPermissions
+	}
+	return opts, nil
+}
+
+func (d *StorageDriver) GetInternalVolumeName(name string) string {
+
+	if trident.UsingPassthroughStore {
+		// With a passthrough store, the name mapping must remain reversible
+		return *d.Config.StoragePrefix + name
+	} else {
+		// With an external store, any transformation of the name is fine
+		internal := drivers.GetCommonInternalVolumeName(d.Config.CommonStorageDriverConfig, name)
+		internal = strings.Replace(internal, "-", "_", -1)  // match the other file drivers
+		internal = strings.Replace(internal, "__", "_", -1) // Remove any double underscores
+		return internal
+	}
+}
+
+func (d *StorageDriver) CreatePrepare(volConfig *storage.VolumeConfig) bool {
+
+	volConfig.InternalName = d.GetInternalVolumeName(volConfig.Name)
+
+	if volConfig.CloneSourceVolume != "" {
+		volConfig.CloneSourceVolumeInternal = d.GetInternalVolumeName(volConfig.CloneSourceVolume)
+	}
+
+	return true
+}
+
+func (d *StorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error {
+	volConfig.AccessInfo.NfsServerIP = d.Config.NfsServer
+	volConfig.AccessInfo.NfsPath = d.exportPath(volConfig.InternalName)
+	volConfig.FileSystem = ""
+	return nil
+}
+
+func (d *StorageDriver) GetProtocol() trident.Protocol {
+	return trident.File
+}
+
+func (d *StorageDriver) StoreConfig(b *storage.PersistentStorageBackendConfig) {
+	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
+	b.NFSConfig = &d.Config
+}
+
+func (d *StorageDriver) GetExternalConfig() interface{} {
+	return &StorageDriverConfigExternal{
+		CommonStorageDriverConfigExternal: drivers.GetCommonStorageDriverConfigExternal(
+			d.Config.CommonStorageDriverConfig),
+		NfsServer:  d.Config.NfsServer,
+		NfsExport:  d.Config.NfsExport,
+		MountPoint: d.Config.MountPoint,
+		QuotaType:  d.Config.QuotaType,
+	}
+}
+
+// GetVolumeExternal queries the storage backend for all relevant info about
+// a single container volume managed by this driver and returns a VolumeExternal
+// representation of the volume.
+func (d *StorageDriver) GetVolumeExternal(name string) (*storage.VolumeExternal, error) {
+
+	if err := d.Get(name); err != nil {
+		return nil, err
+	}
+	return d.getVolumeExternal(name), nil
+}
+
+// GetVolumeExternalWrappers queries the storage backend for all relevant info about
+// container volumes managed by this driver.  It then writes a VolumeExternal
+// representation of each volume to the supplied channel, closing the channel
+// when finished.
+func (d *StorageDriver) GetVolumeExternalWrappers(channel chan *storage.VolumeExternalWrapper) {
+
+	// Let the caller know we're done by closing the channel
+	defer close(channel)
+
+	dirs, err := d.listVolumeDirs()
+	if err != nil {
+		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
+		return
+	}
+
+	for _, dir := range dirs {
+		channel <- &storage.VolumeExternalWrapper{Volume: d.getVolumeExternal(dir), Error: nil}
+	}
+}
+
+// getVolumeExternal is a private method that accepts the name of a volume
+// directory and formats it as a VolumeExternal object.
+func (d *StorageDriver) getVolumeExternal(internalName string) *storage.VolumeExternal {
+
+	name := strings.TrimPrefix(internalName, *d.Config.StoragePrefix)
+
+	size := ""
+	if d.Config.QuotaType == QuotaTypeXFS {
+		if limit, err := getXFSQuota(context.Background(), d.Config.MountPoint, internalName); err == nil {
+			size = strconv.FormatUint(limit, 10)
+		}
+	}
+
+	unixPermissions := ""
+	if info, err := os.Stat(d.volumePath(internalName)); err == nil {
+		unixPermissions = fmt.Sprintf("%04o", info.Mode().Perm())
+	}
+
+	volumeConfig := &storage.VolumeConfig{
+		Version:         trident.OrchestratorAPIVersion,
+		Name:            name,
+		InternalName:    internalName,
+		Size:            size,
+		Protocol:        trident.File,
+		UnixPermissions: unixPermissions,
+		AccessMode:      trident.ReadWriteMany,
+	}
+	volumeConfig.AccessInfo.NfsServerIP = d.Config.NfsServer
+	volumeConfig.AccessInfo.NfsPath = d.exportPath(internalName)
+
+	return &storage.VolumeExternal{
+		Config: volumeConfig,
+		Pool:   PoolName,
+		State:  storage.VolumeStateOnline,
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package nfs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func newTestDriver(t *testing.T) *StorageDriver {
+	mountPoint, err := ioutil.TempDir("", "trident-nfs")
+	if err != nil {
+		t.Fatal("Could not create temporary directory: ", err)
+	}
+
+	prefix := "trident_"
+	d := &StorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix}
+	d.Config.NfsServer = "10.0.0.1"
+	d.Config.NfsExport = "/export/trident"
+	d.Config.MountPoint = mountPoint
+	d.Config.QuotaType = QuotaTypeNone
+	d.Config.UnixPermissions = DefaultUnixPermissions
+	d.Config.Size = drivers.DefaultVolumeSize
+	return d
+}
+
+func TestValidate(t *testing.T) {
+	d := newTestDriver(t)
+	defer os.RemoveAll(d.Config.MountPoint)
+	if err := d.validate(); err != nil {
+		t.Fatal("Unexpected error validating config: ", err)
+	}
+
+	for name, breakConfig := range map[string]func(d *StorageDriver){
+		"nfsServer":       func(d *StorageDriver) { d.Config.NfsServer = "" },
+		"nfsExport":       func(d *StorageDriver) { d.Config.NfsExport = "export" },
+		"quotaType":       func(d *StorageDriver) { d.Config.QuotaType = "ext4" },
+		"unixPermissions": func(d *StorageDriver) { d.Config.UnixPermissions = "0999" },
+		"storagePrefix":   func(d *StorageDriver) { *d.Config.StoragePrefix = "../" },
+	} {
+		d := newTestDriver(t)
+		os.RemoveAll(d.Config.MountPoint)
+		breakConfig(d)
+		if err := d.validate(); err == nil {
+			t.Errorf("Expected an error for an invalid %s.", name)
+		}
+	}
+}
+
+func TestVolumeLifecycle(t *testing.T) {
+	d := newTestDriver(t)
+	defer os.RemoveAll(d.Config.MountPoint)
+	ctx := context.Background()
+
+	if err := d.Create(ctx, "trident_a", 1048576, map[string]string{"unixPermissions": "0750"}); err != nil {
+		t.Fatal("Could not create volume: ", err)
+	}
+	info, err := os.Stat(filepath.Join(d.Config.MountPoint, "trident_a"))
+	if err != nil || info.Mode().Perm() != 0750 {
+		t.Fatalf("Expected a directory with mode 0750; %v, %v", info, err)
+	}
+	if err = d.Create(ctx, "trident_a", 1048576, nil); err == nil {
+		t.Error("Expected an error creating an existing volume.")
+	}
+	if err = d.Create(ctx, "..", 1048576, nil); err == nil {
+		t.Error("Expected an error creating a volume outside the export.")
+	}
+
+	ioutil.WriteFile(filepath.Join(d.Config.MountPoint, "trident_a", "data"), []byte("data"), 0644)
+	if err = d.CreateClone(ctx, "trident_b", "trident_a", "", nil); err != nil {
+		t.Fatal("Could not clone volume: ", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(d.Config.MountPoint, "trident_b", "data")); err != nil ||
+		string(data) != "data" {
+		t.Errorf("Expected the clone to contain the source data; %s, %v", data, err)
+	}
+	if err = d.CreateClone(ctx, "trident_c", "trident_a", "snap", nil); err == nil {
+		t.Error("Expected an error cloning from a snapshot.")
+	}
+
+	// Directories without the prefix belong to someone else
+	os.Mkdir(filepath.Join(d.Config.MountPoint, "other"), 0755)
+
+	names, err := d.List()
+	sort.Strings(names)
+	if err != nil || len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("Expected volumes a and b, got %v (%v)", names, err)
+	}
+	if err = d.Get("trident_b"); err != nil {
+		t.Errorf("Expected to find the clone: %v", err)
+	}
+
+	if err = d.Destroy(ctx, "trident_a"); err != nil {
+		t.Fatal("Could not destroy volume: ", err)
+	}
+	if err = d.Get("trident_a"); err == nil {
+		t.Error("Expected the volume to be gone.")
+	}
+	if err = d.Destroy(ctx, "trident_a"); err != nil {
+		t.Errorf("Expected no error destroying a missing volume, got %v", err)
+	}
+}
+
+func TestGetVolumeExternal(t *testing.T) {
+	d := newTestDriver(t)
+	defer os.RemoveAll(d.Config.MountPoint)
+
+	if err := d.Create(context.Background(), "trident_pvc_1", 0, nil); err != nil {
+		t.Fatal("Could not create volume: ", err)
+	}
+
+	external, err := d.GetVolumeExternal("trident_pvc_1")
+	if err != nil {
+		t.Fatal("Could not get volume: ", err)
+	}
+	if external.Config.Name != "pvc_1" || external.Pool != PoolName {
+		t.Errorf("Unexpected name %s or pool %s", external.Config.Name, external.Pool)
+	}
+	if external.Config.AccessInfo.NfsServerIP != "10.0.0.1" ||
+		external.Config.AccessInfo.NfsPath != "/export/trident/trident_pvc_1" {
+		t.Errorf("Unexpected access info %+v", external.Config.AccessInfo)
+	}
+	if external.Config.UnixPermissions != "0777" {
+		t.Errorf("Expected permissions 0777, got %s", external.Config.UnixPermissions)
+	}
+}
+
+func TestGetInternalVolumeName(t *testing.T) {
+	d := newTestDriver(t)
+	os.RemoveAll(d.Config.MountPoint)
+
+	trident.UsingPassthroughStore = false
+	if got := d.GetInternalVolumeName("pvc-1"); got != "trident_pvc_1" {
+		t.Errorf("Expected trident_pvc_1, got %s", got)
+	}
+
+	trident.UsingPassthroughStore = true
+	defer func() { trident.UsingPassthroughStore = false }()
+	if got := d.GetInternalVolumeName("myvol"); got != "trident_myvol" {
+		t.Errorf("Expected trident_myvol, got %s", got)
+	}
+}
+
+func TestGetStorageBackendSpecs(t *testing.T) {
+	d := newTestDriver(t)
+	os.RemoveAll(d.Config.MountPoint)
+
+	backend := &storage.Backend{Storage: make(map[string]*storage.Pool)}
+	if err := d.GetStorageBackendSpecs(backend); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if backend.Name != "nfs_10.0.0.1_export_trident" {
+		t.Errorf("Unexpected backend name %s", backend.Name)
+	}
+	if len(backend.Storage) != 1 || backend.Storage[PoolName] == nil {
+		t.Errorf("Expected a single pool, got %v", backend.Storage)
+	}
+}
+
+func TestParseXFSQuotaReport(t *testing.T) {
+	report := "#0 0 0 0 00 [------]\n#12345 4 0 1048576 00 [------]\n"
+	limit, err := parseXFSQuotaReport(report, 12345)
+	if err != nil || limit != 1073741824 {
+		t.Errorf("Expected a 1 GiB limit, got %d (%v)", limit, err)
+	}
+	if _, err = parseXFSQuotaReport(report, 54321); err == nil {
+		t.Error("Expected an error for a missing project.")
+	}
+	if xfsProjectID("trident_a") != xfsProjectID("trident_a") || xfsProjectID("trident_a") == 0 {
+		t.Error("Expected a stable, nonzero project ID.")
+	}
+}
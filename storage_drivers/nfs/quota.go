@@ -0,0 +1,104 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// XFS project quotas limit the size of each volume directory.  The export must be an XFS
+// filesystem mounted with prjquota on the NFS server, and Trident must run on that server
+// (or have the same filesystem mounted locally) so that xfs_quota can manage it.
+
+// xfsProjectID derives a stable, nonzero project ID from a volume name.
+func xfsProjectID(name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	id := h.Sum32() & 0x7fffffff
+	if id == 0 {
+		id = 1
+	}
+	return id
+}
+
+// xfsQuota runs an xfs_quota expert command against the filesystem at the mount point.
+func xfsQuota(ctx context.Context, mountPoint, command string) (string, error) {
+
+	log.WithFields(log.Fields{
+		"mountPoint": mountPoint,
+		"command":    command,
+	}).Debug("Running xfs_quota.")
+
+	out, err := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", command, mountPoint).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("xfs_quota command '%s' failed: %v; %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// checkXFSQuota ensures project quotas are being enforced on the filesystem at the mount point.
+func checkXFSQuota(mountPoint string) error {
+
+	out, err := xfsQuota(context.Background(), mountPoint, "state -p")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "Enforcement: ON") {
+		return fmt.Errorf("project quotas are not enforced on %s; mount the filesystem with prjquota", mountPoint)
+	}
+	return nil
+}
+
+// setXFSQuota assigns a directory to the project for a volume and limits the project's size.  If the
+// directory is empty, only the limit is set, and a size of zero removes the limit.
+func setXFSQuota(ctx context.Context, mountPoint, dir, name string, sizeBytes uint64) error {
+
+	id := xfsProjectID(name)
+
+	if dir != "" {
+		if _, err := xfsQuota(ctx, mountPoint, fmt.Sprintf("project -s -p %s %d", dir, id)); err != nil {
+			return err
+		}
+	}
+
+	_, err := xfsQuota(ctx, mountPoint, fmt.Sprintf("limit -p bhard=%d %d", sizeBytes, id))
+	return err
+}
+
+// getXFSQuota returns the size limit in bytes of the project for a volume.
+func getXFSQuota(ctx context.Context, mountPoint, name string) (uint64, error) {
+
+	id := xfsProjectID(name)
+
+	// Each line reads "#<id> <used> <soft> <hard> <warn> <grace>", with sizes in KiB
+	out, err := xfsQuota(ctx, mountPoint, "report -p -n -b -N")
+	if err != nil {
+		return 0, err
+	}
+	return parseXFSQuotaReport(out, id)
+}
+
+// parseXFSQuotaReport finds the hard block limit of a project in the output of an xfs_quota report.
+func parseXFSQuotaReport(report string, id uint32) (uint64, error) {
+
+	project := fmt.Sprintf("#%d", id)
+	for _, line := range strings.Split(report, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != project {
+			continue
+		}
+		hardKiB, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse quota for project %d: %v", id, err)
+		}
+		return hardKiB * 1024, nil
+	}
+	return 0, fmt.Errorf("no quota found for project %d", id)
+}
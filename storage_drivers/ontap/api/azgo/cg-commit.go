@@ -0,0 +1,136 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CgCommitRequest is a structure to represent a cg-commit ZAPI request object
+type CgCommitRequest struct {
+	XMLName xml.Name `xml:"cg-commit"`
+
+	CgIdPtr *int `xml:"cg-id"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *CgCommitRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewCgCommitRequest is a factory method for creating new instances of CgCommitRequest objects
+func NewCgCommitRequest() *CgCommitRequest { return &CgCommitRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *CgCommitRequest) ExecuteUsing(zr *ZapiRunner) (CgCommitResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "CgCommitRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return CgCommitResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return CgCommitResponse{}, readErr
+	}
+	if zr.TraceEnabled("cg-commit") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n CgCommitResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return CgCommitResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("cg-commit") {
+		log.Debugf("cg-commit result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgCommitRequest) String() string {
+	var buffer bytes.Buffer
+	if o.CgIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "cg-id", *o.CgIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("cg-id: nil\n"))
+	}
+	return buffer.String()
+}
+
+// CgId is a fluent style 'getter' method that can be chained
+func (o *CgCommitRequest) CgId() int {
+	r := *o.CgIdPtr
+	return r
+}
+
+// SetCgId is a fluent style 'setter' method that can be chained
+func (o *CgCommitRequest) SetCgId(newValue int) *CgCommitRequest {
+	o.CgIdPtr = &newValue
+	return o
+}
+
+// CgCommitResponse is a structure to represent a cg-commit ZAPI response object
+type CgCommitResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result CgCommitResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgCommitResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// CgCommitResponseResult is a structure to represent a cg-commit ZAPI object's result
+type CgCommitResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *CgCommitResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewCgCommitResponse is a factory method for creating new instances of CgCommitResponse objects
+func NewCgCommitResponse() *CgCommitResponse { return &CgCommitResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgCommitResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
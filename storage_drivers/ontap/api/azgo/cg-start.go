@@ -0,0 +1,228 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CgStartRequest is a structure to represent a cg-start ZAPI request object
+type CgStartRequest struct {
+	XMLName xml.Name `xml:"cg-start"`
+
+	SnapmirrorLabelPtr *string          `xml:"snapmirror-label"`
+	SnapshotPtr        *string          `xml:"snapshot"`
+	TimeoutPtr         *string          `xml:"timeout"`
+	UserTimestampPtr   *int             `xml:"user-timestamp"`
+	VolumesPtr         []VolumeNameType `xml:"volumes>volume-name"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *CgStartRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewCgStartRequest is a factory method for creating new instances of CgStartRequest objects
+func NewCgStartRequest() *CgStartRequest { return &CgStartRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *CgStartRequest) ExecuteUsing(zr *ZapiRunner) (CgStartResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "CgStartRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return CgStartResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return CgStartResponse{}, readErr
+	}
+	if zr.TraceEnabled("cg-start") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n CgStartResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return CgStartResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("cg-start") {
+		log.Debugf("cg-start result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgStartRequest) String() string {
+	var buffer bytes.Buffer
+	if o.SnapmirrorLabelPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "snapmirror-label", *o.SnapmirrorLabelPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("snapmirror-label: nil\n"))
+	}
+	if o.SnapshotPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "snapshot", *o.SnapshotPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("snapshot: nil\n"))
+	}
+	if o.TimeoutPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "timeout", *o.TimeoutPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("timeout: nil\n"))
+	}
+	if o.UserTimestampPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "user-timestamp", *o.UserTimestampPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("user-timestamp: nil\n"))
+	}
+	if o.VolumesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "volumes", o.VolumesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("volumes: nil\n"))
+	}
+	return buffer.String()
+}
+
+// SnapmirrorLabel is a fluent style 'getter' method that can be chained
+func (o *CgStartRequest) SnapmirrorLabel() string {
+	r := *o.SnapmirrorLabelPtr
+	return r
+}
+
+// SetSnapmirrorLabel is a fluent style 'setter' method that can be chained
+func (o *CgStartRequest) SetSnapmirrorLabel(newValue string) *CgStartRequest {
+	o.SnapmirrorLabelPtr = &newValue
+	return o
+}
+
+// Snapshot is a fluent style 'getter' method that can be chained
+func (o *CgStartRequest) Snapshot() string {
+	r := *o.SnapshotPtr
+	return r
+}
+
+// SetSnapshot is a fluent style 'setter' method that can be chained
+func (o *CgStartRequest) SetSnapshot(newValue string) *CgStartRequest {
+	o.SnapshotPtr = &newValue
+	return o
+}
+
+// Timeout is a fluent style 'getter' method that can be chained
+func (o *CgStartRequest) Timeout() string {
+	r := *o.TimeoutPtr
+	return r
+}
+
+// SetTimeout is a fluent style 'setter' method that can be chained
+func (o *CgStartRequest) SetTimeout(newValue string) *CgStartRequest {
+	o.TimeoutPtr = &newValue
+	return o
+}
+
+// UserTimestamp is a fluent style 'getter' method that can be chained
+func (o *CgStartRequest) UserTimestamp() int {
+	r := *o.UserTimestampPtr
+	return r
+}
+
+// SetUserTimestamp is a fluent style 'setter' method that can be chained
+func (o *CgStartRequest) SetUserTimestamp(newValue int) *CgStartRequest {
+	o.UserTimestampPtr = &newValue
+	return o
+}
+
+// Volumes is a fluent style 'getter' method that can be chained
+func (o *CgStartRequest) Volumes() []VolumeNameType {
+	r := o.VolumesPtr
+	return r
+}
+
+// SetVolumes is a fluent style 'setter' method that can be chained
+func (o *CgStartRequest) SetVolumes(newValue []VolumeNameType) *CgStartRequest {
+	newSlice := make([]VolumeNameType, len(newValue))
+	copy(newSlice, newValue)
+	o.VolumesPtr = newSlice
+	return o
+}
+
+// CgStartResponse is a structure to represent a cg-start ZAPI response object
+type CgStartResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result CgStartResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgStartResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// CgStartResponseResult is a structure to represent a cg-start ZAPI object's result
+type CgStartResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+	CgIdPtr          *int   `xml:"cg-id"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *CgStartResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewCgStartResponse is a factory method for creating new instances of CgStartResponse objects
+func NewCgStartResponse() *CgStartResponse { return &CgStartResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o CgStartResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.CgIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "cg-id", *o.CgIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("cg-id: nil\n"))
+	}
+	return buffer.String()
+}
+
+// CgId is a fluent style 'getter' method that can be chained
+func (o *CgStartResponseResult) CgId() int {
+	r := *o.CgIdPtr
+	return r
+}
+
+// SetCgId is a fluent style 'setter' method that can be chained
+func (o *CgStartResponseResult) SetCgId(newValue int) *CgStartResponseResult {
+	o.CgIdPtr = &newValue
+	return o
+}
@@ -4,12 +4,18 @@ package azgo
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/utils"
 )
 
 type ZAPIRequest interface {
@@ -24,6 +30,25 @@ type ZapiRunner struct {
 	Secure          bool
 	OntapiVersion   string
 	DebugTraceFlags map[string]bool // Example: {"api":false, "method":true}
+	Context         context.Context // optional; if set, requests are abandoned when it is done
+
+	// Transport is optional; if set, it replaces the default HTTPS transport, such as to record
+	// or replay ZAPI exchanges.
+	Transport http.RoundTripper
+
+	// Timeout is optional; if set, it limits how long each ZAPI call may take.
+	Timeout time.Duration
+
+	// Breaker is optional; if set, it stops calls to a cluster that has stopped responding.  It is
+	// shared by every runner cloned from this one.
+	Breaker *utils.CircuitBreaker
+}
+
+// NewZapiTransport returns the transport used to reach ONTAP when a runner has none of its own.
+func NewZapiTransport() http.RoundTripper {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
 }
 
 // SendZapi sends the provided ZAPIRequest to the Ontap system
@@ -39,6 +64,8 @@ func (o *ZapiRunner) SendZapi(r ZAPIRequest) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	name := zapiName(zapiCommand)
+	trace := o.TraceEnabled(name)
 
 	var s = ""
 	if o.SVM == "" {
@@ -52,39 +79,150 @@ func (o *ZapiRunner) SendZapi(r ZAPIRequest) (*http.Response, error) {
             %s
         </netapp>`, "vfiler=\""+o.SVM+"\"", zapiCommand)
 	}
-	if o.DebugTraceFlags["api"] {
-		log.Debugf("sending to '%s' xml: \n%s", o.ManagementLIF, s)
+	if trace {
+		log.Debugf("sending to '%s' xml: \n%s", o.ManagementLIF, RedactZapi(s))
 	}
 
 	url := "http://" + o.ManagementLIF + "/servlets/netapp.servlets.admin.XMLrequest_filer"
 	if o.Secure {
 		url = "https://" + o.ManagementLIF + "/servlets/netapp.servlets.admin.XMLrequest_filer"
 	}
-	if o.DebugTraceFlags["api"] {
+	if trace {
 		log.Debugf("URL:> %s", url)
 	}
 
 	b := []byte(s)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(b))
+	if o.Context != nil {
+		req = req.WithContext(o.Context)
+	}
 	req.Header.Set("Content-Type", "application/xml")
 	req.SetBasicAuth(o.Username, o.Password)
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	tr := o.Transport
+	if tr == nil {
+		tr = NewZapiTransport()
 	}
 
-	client := &http.Client{Transport: tr}
+	if err = o.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Transport: tr, Timeout: o.Timeout}
+	start := time.Now()
 	resp, err := client.Do(req)
+	utils.RecordCall(o.Context, name, time.Since(start))
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		// A server error carries no ZAPI response, so it fails the call like no response at all
+		err = fmt.Errorf("response code %s", resp.Status)
+		resp.Body.Close()
+		resp = nil
+	}
+	o.recordOutcome(err)
+	if isMutatingZapi(name) {
+		o.auditCall(name, resp, err)
+	}
 	if err != nil {
 		return nil, err
 	} else if resp.StatusCode == 401 {
 		return nil, errors.New("response code 401 (Unauthorized): incorrect or missing credentials")
 	}
 
-	if o.DebugTraceFlags["api"] {
+	if trace {
 		log.Debugf("response Status: %s", resp.Status)
 		log.Debugf("response Headers: %s", resp.Header)
 	}
 
 	return resp, err
 }
+
+// recordOutcome tells the circuit breaker whether the cluster responded to a call, given the
+// call's error.  Any response but a server error shows that it did, even one refusing the call,
+// while a call abandoned by its caller shows nothing either way.
+func (o *ZapiRunner) recordOutcome(err error) {
+	switch {
+	case err != nil && o.Context != nil && o.Context.Err() != nil:
+		o.Breaker.Abandon()
+	case err != nil:
+		o.Breaker.Failure(err)
+	default:
+		o.Breaker.Success()
+	}
+}
+
+// TraceEnabled returns true if calls to the named ZAPI are to be traced, logging their payloads.
+// The api trace flag traces every call, while a flag naming one ZAPI, such as
+// api:volume-clone-create, or ending in a wildcard, such as api:snapshot-*, traces only the calls
+// named, so that they may be traced in production without tracing everything else.
+func (o *ZapiRunner) TraceEnabled(name string) bool {
+	if o.DebugTraceFlags["api"] || o.DebugTraceFlags["api:"+name] {
+		return true
+	}
+	for flag, enabled := range o.DebugTraceFlags {
+		if enabled && strings.HasPrefix(flag, "api:") && strings.HasSuffix(flag, "*") &&
+			strings.HasPrefix("api:"+name, strings.TrimSuffix(flag, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedZapiValue replaces the secrets in ZAPI payloads that are logged or recorded.
+const redactedZapiValue = "********"
+
+var (
+	// zapiSecretElementRegex matches the value of an XML element holding a secret, such as the
+	// CHAP user-password and outbound-passphrase of an iSCSI initiator
+	zapiSecretElementRegex = regexp.MustCompile(
+		`(?i)(<[a-z0-9-]*(?:password|passphrase|passwd|secret)[a-z0-9-]*>)[^<]*(</)`)
+
+	// zapiSecretFieldRegex matches the value of a field holding a secret in the string form of a
+	// ZAPI object, which lists one field per line
+	zapiSecretFieldRegex = regexp.MustCompile(
+		`(?im)^(\s*[a-z0-9-]*(?:password|passphrase|passwd|secret)[a-z0-9-]*: ).*$`)
+)
+
+// RedactZapi returns a ZAPI payload, or the string form of a ZAPI object, with the values of its
+// passwords, passphrases and other secrets replaced, so that it may be logged or recorded.
+func RedactZapi(payload string) string {
+	payload = zapiSecretElementRegex.ReplaceAllString(payload, "${1}"+redactedZapiValue+"${2}")
+	return zapiSecretFieldRegex.ReplaceAllString(payload, "${1}"+redactedZapiValue)
+}
+
+// auditCall logs a ZAPI call that changes ONTAP, along with the Trident operation it was made for,
+// if any, so that the changes in ONTAP's audit log can be matched to Trident's operations.
+func (o *ZapiRunner) auditCall(name string, resp *http.Response, err error) {
+
+	fields := log.Fields{
+		"api":           name,
+		"managementLIF": o.ManagementLIF,
+		"svm":           o.SVM,
+	}
+	if audit := utils.GetAuditInfo(o.Context); audit != nil {
+		for key, value := range audit.Fields() {
+			fields[key] = value
+		}
+	}
+	if err != nil {
+		fields["error"] = err
+	} else {
+		fields["httpStatus"] = resp.StatusCode
+	}
+	log.WithFields(fields).Info("ONTAP API call.")
+}
+
+// isMutatingZapi returns true if a ZAPI may change ONTAP, which all but those that get, list or
+// report the status of objects may.
+func isMutatingZapi(name string) bool {
+	return name != "" && !strings.Contains(name, "-get") && !strings.Contains(name, "-list") &&
+		!strings.HasSuffix(name, "-status")
+}
+
+// zapiName returns the name of a ZAPI from its XML, such as volume-create.
+func zapiName(zapiCommand string) string {
+	name := strings.TrimLeft(strings.TrimSpace(zapiCommand), "<")
+	if end := strings.IndexAny(name, " />\n"); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
@@ -55,18 +55,18 @@ func (o *EmsAutosupportLogRequest) ExecuteUsing(zr *ZapiRunner) (EmsAutosupportL
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return EmsAutosupportLogResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("ems-autosupport-log") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n EmsAutosupportLogResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return EmsAutosupportLogResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("ems-autosupport-log result:\n%s", n.Result)
+	if zr.TraceEnabled("ems-autosupport-log") {
+		log.Debugf("ems-autosupport-log result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
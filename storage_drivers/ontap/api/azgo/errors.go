@@ -12,6 +12,7 @@ const EVDISK_ERROR_VDISK_EXISTS = "9012"
 const EVDISK_ERROR_INITGROUP_HAS_NODE = "9008"
 const EVDISK_ERROR_VDISK_NOT_ENABLED = "9014"
 const EVDISK_ERROR_VDISK_NOT_DISABLED = "9015"
+const EVDISK_ERROR_NO_SUCH_LUNMAP = "9016"
 const EVDISK_ERROR_INITGROUP_HAS_VDISK = "9023"
 const EVDISK_ERROR_INITGROUP_HAS_LUN = "9024"
 const EVDISK_ERROR_INITGROUP_MAPS_EXIST = "9029"
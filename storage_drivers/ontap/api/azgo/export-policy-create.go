@@ -49,18 +49,18 @@ func (o *ExportPolicyCreateRequest) ExecuteUsing(zr *ZapiRunner) (ExportPolicyCr
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return ExportPolicyCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("export-policy-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n ExportPolicyCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return ExportPolicyCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("export-policy-create result:\n%s", n.Result)
+	if zr.TraceEnabled("export-policy-create") {
+		log.Debugf("export-policy-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
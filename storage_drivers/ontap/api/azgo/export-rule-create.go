@@ -59,18 +59,18 @@ func (o *ExportRuleCreateRequest) ExecuteUsing(zr *ZapiRunner) (ExportRuleCreate
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return ExportRuleCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("export-rule-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n ExportRuleCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return ExportRuleCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("export-rule-create result:\n%s", n.Result)
+	if zr.TraceEnabled("export-rule-create") {
+		log.Debugf("export-rule-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
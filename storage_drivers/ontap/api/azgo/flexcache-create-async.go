@@ -0,0 +1,304 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FlexcacheCreateAsyncRequest is a structure to represent a flexcache-create-async ZAPI request object
+type FlexcacheCreateAsyncRequest struct {
+	XMLName xml.Name `xml:"flexcache-create-async"`
+
+	AggrListPtr      []AggrNameType `xml:"aggr-list>aggr-name"`
+	JunctionPathPtr  *string        `xml:"junction-path"`
+	OriginVolumePtr  *string        `xml:"origin-volume"`
+	OriginVserverPtr *string        `xml:"origin-vserver"`
+	SizePtr          *int           `xml:"size"`
+	VolumePtr        *string        `xml:"volume"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *FlexcacheCreateAsyncRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewFlexcacheCreateAsyncRequest is a factory method for creating new instances of FlexcacheCreateAsyncRequest objects
+func NewFlexcacheCreateAsyncRequest() *FlexcacheCreateAsyncRequest {
+	return &FlexcacheCreateAsyncRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *FlexcacheCreateAsyncRequest) ExecuteUsing(zr *ZapiRunner) (FlexcacheCreateAsyncResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "FlexcacheCreateAsyncRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return FlexcacheCreateAsyncResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return FlexcacheCreateAsyncResponse{}, readErr
+	}
+	if zr.TraceEnabled("flexcache-create-async") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n FlexcacheCreateAsyncResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return FlexcacheCreateAsyncResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("flexcache-create-async") {
+		log.Debugf("flexcache-create-async result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheCreateAsyncRequest) String() string {
+	var buffer bytes.Buffer
+	if o.AggrListPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "aggr-list", o.AggrListPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("aggr-list: nil\n"))
+	}
+	if o.JunctionPathPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "junction-path", *o.JunctionPathPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("junction-path: nil\n"))
+	}
+	if o.OriginVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "origin-volume", *o.OriginVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("origin-volume: nil\n"))
+	}
+	if o.OriginVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "origin-vserver", *o.OriginVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("origin-vserver: nil\n"))
+	}
+	if o.SizePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "size", *o.SizePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("size: nil\n"))
+	}
+	if o.VolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "volume", *o.VolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("volume: nil\n"))
+	}
+	return buffer.String()
+}
+
+// AggrList is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) AggrList() []AggrNameType {
+	r := o.AggrListPtr
+	return r
+}
+
+// SetAggrList is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetAggrList(newValue []AggrNameType) *FlexcacheCreateAsyncRequest {
+	newSlice := make([]AggrNameType, len(newValue))
+	copy(newSlice, newValue)
+	o.AggrListPtr = newSlice
+	return o
+}
+
+// JunctionPath is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) JunctionPath() string {
+	r := *o.JunctionPathPtr
+	return r
+}
+
+// SetJunctionPath is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetJunctionPath(newValue string) *FlexcacheCreateAsyncRequest {
+	o.JunctionPathPtr = &newValue
+	return o
+}
+
+// OriginVolume is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) OriginVolume() string {
+	r := *o.OriginVolumePtr
+	return r
+}
+
+// SetOriginVolume is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetOriginVolume(newValue string) *FlexcacheCreateAsyncRequest {
+	o.OriginVolumePtr = &newValue
+	return o
+}
+
+// OriginVserver is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) OriginVserver() string {
+	r := *o.OriginVserverPtr
+	return r
+}
+
+// SetOriginVserver is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetOriginVserver(newValue string) *FlexcacheCreateAsyncRequest {
+	o.OriginVserverPtr = &newValue
+	return o
+}
+
+// Size is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) Size() int {
+	r := *o.SizePtr
+	return r
+}
+
+// SetSize is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetSize(newValue int) *FlexcacheCreateAsyncRequest {
+	o.SizePtr = &newValue
+	return o
+}
+
+// Volume is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) Volume() string {
+	r := *o.VolumePtr
+	return r
+}
+
+// SetVolume is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncRequest) SetVolume(newValue string) *FlexcacheCreateAsyncRequest {
+	o.VolumePtr = &newValue
+	return o
+}
+
+// FlexcacheCreateAsyncResponse is a structure to represent a flexcache-create-async ZAPI response object
+type FlexcacheCreateAsyncResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result FlexcacheCreateAsyncResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheCreateAsyncResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// FlexcacheCreateAsyncResponseResult is a structure to represent a flexcache-create-async ZAPI object's result
+type FlexcacheCreateAsyncResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr      string  `xml:"status,attr"`
+	ResultReasonAttr      string  `xml:"reason,attr"`
+	ResultErrnoAttr       string  `xml:"errno,attr"`
+	ResultErrorCodePtr    *int    `xml:"result-error-code"`
+	ResultErrorMessagePtr *string `xml:"result-error-message"`
+	ResultJobidPtr        *int    `xml:"result-jobid"`
+	ResultStatusPtr       *string `xml:"result-status"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *FlexcacheCreateAsyncResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewFlexcacheCreateAsyncResponse is a factory method for creating new instances of FlexcacheCreateAsyncResponse objects
+func NewFlexcacheCreateAsyncResponse() *FlexcacheCreateAsyncResponse {
+	return &FlexcacheCreateAsyncResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheCreateAsyncResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.ResultErrorCodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-code", *o.ResultErrorCodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-code: nil\n"))
+	}
+	if o.ResultErrorMessagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-message", *o.ResultErrorMessagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-message: nil\n"))
+	}
+	if o.ResultJobidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-jobid", *o.ResultJobidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-jobid: nil\n"))
+	}
+	if o.ResultStatusPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-status", *o.ResultStatusPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-status: nil\n"))
+	}
+	return buffer.String()
+}
+
+// ResultErrorCode is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) ResultErrorCode() int {
+	r := *o.ResultErrorCodePtr
+	return r
+}
+
+// SetResultErrorCode is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) SetResultErrorCode(newValue int) *FlexcacheCreateAsyncResponseResult {
+	o.ResultErrorCodePtr = &newValue
+	return o
+}
+
+// ResultErrorMessage is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) ResultErrorMessage() string {
+	r := *o.ResultErrorMessagePtr
+	return r
+}
+
+// SetResultErrorMessage is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) SetResultErrorMessage(newValue string) *FlexcacheCreateAsyncResponseResult {
+	o.ResultErrorMessagePtr = &newValue
+	return o
+}
+
+// ResultJobid is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) ResultJobid() int {
+	r := *o.ResultJobidPtr
+	return r
+}
+
+// SetResultJobid is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) SetResultJobid(newValue int) *FlexcacheCreateAsyncResponseResult {
+	o.ResultJobidPtr = &newValue
+	return o
+}
+
+// ResultStatus is a fluent style 'getter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) ResultStatus() string {
+	r := *o.ResultStatusPtr
+	return r
+}
+
+// SetResultStatus is a fluent style 'setter' method that can be chained
+func (o *FlexcacheCreateAsyncResponseResult) SetResultStatus(newValue string) *FlexcacheCreateAsyncResponseResult {
+	o.ResultStatusPtr = &newValue
+	return o
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FlexcacheDestroyAsyncRequest is a structure to represent a flexcache-destroy-async ZAPI request object
+type FlexcacheDestroyAsyncRequest struct {
+	XMLName xml.Name `xml:"flexcache-destroy-async"`
+
+	VolumePtr *string `xml:"volume"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *FlexcacheDestroyAsyncRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewFlexcacheDestroyAsyncRequest is a factory method for creating new instances of FlexcacheDestroyAsyncRequest objects
+func NewFlexcacheDestroyAsyncRequest() *FlexcacheDestroyAsyncRequest {
+	return &FlexcacheDestroyAsyncRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *FlexcacheDestroyAsyncRequest) ExecuteUsing(zr *ZapiRunner) (FlexcacheDestroyAsyncResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "FlexcacheDestroyAsyncRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return FlexcacheDestroyAsyncResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return FlexcacheDestroyAsyncResponse{}, readErr
+	}
+	if zr.TraceEnabled("flexcache-destroy-async") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n FlexcacheDestroyAsyncResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return FlexcacheDestroyAsyncResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("flexcache-destroy-async") {
+		log.Debugf("flexcache-destroy-async result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheDestroyAsyncRequest) String() string {
+	var buffer bytes.Buffer
+	if o.VolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "volume", *o.VolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("volume: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Volume is a fluent style 'getter' method that can be chained
+func (o *FlexcacheDestroyAsyncRequest) Volume() string {
+	r := *o.VolumePtr
+	return r
+}
+
+// SetVolume is a fluent style 'setter' method that can be chained
+func (o *FlexcacheDestroyAsyncRequest) SetVolume(newValue string) *FlexcacheDestroyAsyncRequest {
+	o.VolumePtr = &newValue
+	return o
+}
+
+// FlexcacheDestroyAsyncResponse is a structure to represent a flexcache-destroy-async ZAPI response object
+type FlexcacheDestroyAsyncResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result FlexcacheDestroyAsyncResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheDestroyAsyncResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// FlexcacheDestroyAsyncResponseResult is a structure to represent a flexcache-destroy-async ZAPI object's result
+type FlexcacheDestroyAsyncResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr      string  `xml:"status,attr"`
+	ResultReasonAttr      string  `xml:"reason,attr"`
+	ResultErrnoAttr       string  `xml:"errno,attr"`
+	ResultErrorCodePtr    *int    `xml:"result-error-code"`
+	ResultErrorMessagePtr *string `xml:"result-error-message"`
+	ResultJobidPtr        *int    `xml:"result-jobid"`
+	ResultStatusPtr       *string `xml:"result-status"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *FlexcacheDestroyAsyncResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewFlexcacheDestroyAsyncResponse is a factory method for creating new instances of FlexcacheDestroyAsyncResponse objects
+func NewFlexcacheDestroyAsyncResponse() *FlexcacheDestroyAsyncResponse {
+	return &FlexcacheDestroyAsyncResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o FlexcacheDestroyAsyncResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.ResultErrorCodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-code", *o.ResultErrorCodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-code: nil\n"))
+	}
+	if o.ResultErrorMessagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-message", *o.ResultErrorMessagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-message: nil\n"))
+	}
+	if o.ResultJobidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-jobid", *o.ResultJobidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-jobid: nil\n"))
+	}
+	if o.ResultStatusPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-status", *o.ResultStatusPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-status: nil\n"))
+	}
+	return buffer.String()
+}
+
+// ResultErrorCode is a fluent style 'getter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) ResultErrorCode() int {
+	r := *o.ResultErrorCodePtr
+	return r
+}
+
+// SetResultErrorCode is a fluent style 'setter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) SetResultErrorCode(newValue int) *FlexcacheDestroyAsyncResponseResult {
+	o.ResultErrorCodePtr = &newValue
+	return o
+}
+
+// ResultErrorMessage is a fluent style 'getter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) ResultErrorMessage() string {
+	r := *o.ResultErrorMessagePtr
+	return r
+}
+
+// SetResultErrorMessage is a fluent style 'setter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) SetResultErrorMessage(newValue string) *FlexcacheDestroyAsyncResponseResult {
+	o.ResultErrorMessagePtr = &newValue
+	return o
+}
+
+// ResultJobid is a fluent style 'getter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) ResultJobid() int {
+	r := *o.ResultJobidPtr
+	return r
+}
+
+// SetResultJobid is a fluent style 'setter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) SetResultJobid(newValue int) *FlexcacheDestroyAsyncResponseResult {
+	o.ResultJobidPtr = &newValue
+	return o
+}
+
+// ResultStatus is a fluent style 'getter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) ResultStatus() string {
+	r := *o.ResultStatusPtr
+	return r
+}
+
+// SetResultStatus is a fluent style 'setter' method that can be chained
+func (o *FlexcacheDestroyAsyncResponseResult) SetResultStatus(newValue string) *FlexcacheDestroyAsyncResponseResult {
+	o.ResultStatusPtr = &newValue
+	return o
+}
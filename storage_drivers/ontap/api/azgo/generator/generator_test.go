@@ -0,0 +1,202 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleSchema = "testdata/sample.xsd"
+
+const sampleTypes = `// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+type NullableSizeType string
+
+// VserverAggrInfoType has been modified by hand.  azgo:keep
+type VserverAggrInfoType struct {
+	AggrAvailsizePtr *NullableSizeType
+}
+
+type VolumeQosAttributesType struct {
+	XMLName xml.Name ` + "`xml:\"volume-qos-attributes\"`" + `
+
+	PolicyGroupNamePtr *string ` + "`xml:\"policy-group-name\"`" + `
+}
+
+func (o *VolumeQosAttributesType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	return string(output), err
+}
+
+func NewVolumeQosAttributesType() *VolumeQosAttributesType { return &VolumeQosAttributesType{} }
+
+func (o *VolumeQosAttributesType) PolicyGroupName() string {
+	r := *o.PolicyGroupNamePtr
+	return r
+}
+
+type ProtocolType string
+`
+
+func TestLoadSchema(t *testing.T) {
+	schema, err := LoadSchema(sampleSchema)
+	if err != nil {
+		t.Fatal("Unable to load schema: ", err)
+	}
+
+	iter := schema.APIs["volume-get-iter"]
+	if iter == nil {
+		t.Fatal("Expected volume-get-iter in the schema.")
+	}
+	if !iter.Iter() {
+		t.Error("Expected volume-get-iter to be paginated.")
+	}
+	expected := map[string]Field{
+		"desired-attributes": {Name: "desired-attributes", GoName: "DesiredAttributes",
+			Type: "VolumeAttributesType", Path: "desired-attributes>volume-attributes"},
+		"max-records": {Name: "max-records", GoName: "MaxRecords", Type: "int", Path: "max-records"},
+	}
+	for _, field := range iter.Inputs {
+		if want, ok := expected[field.Name]; ok && field != want {
+			t.Errorf("Expected input %v, got %v.", want, field)
+		}
+	}
+	if iter.Outputs[0].Name != "attributes-list" || !iter.Outputs[0].Slice {
+		t.Errorf("Expected a list of attributes, got %v.", iter.Outputs[0])
+	}
+
+	if modify := schema.APIs["volume-modify-tiering-policy"]; modify == nil || modify.Iter() {
+		t.Error("Expected volume-modify-tiering-policy to be a simple API.")
+	}
+	if policy := schema.Types["tiering-policy"]; policy == nil || policy.Base != "string" {
+		t.Errorf("Expected tiering-policy to be a string, got %v.", policy)
+	}
+}
+
+func TestGenerateAPI(t *testing.T) {
+	schema, err := LoadSchema(sampleSchema)
+	if err != nil {
+		t.Fatal("Unable to load schema: ", err)
+	}
+
+	source, err := GenerateAPI(schema.APIs["volume-get-iter"])
+	if err != nil {
+		t.Fatal("Unable to generate volume-get-iter: ", err)
+	}
+	for _, expected := range []string{
+		"type VolumeGetIterRequest struct {",
+		"`xml:\"desired-attributes>volume-attributes\"`",
+		"AttributesListPtr []VolumeAttributesType `xml:\"attributes-list>volume-attributes\"`",
+		"o.SetTag(*nextTagPtr)",
+		"func (o *VolumeGetIterResponseResult) SetNumRecords(newValue int) *VolumeGetIterResponseResult {",
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("Expected %s in generated volume-get-iter.", expected)
+		}
+	}
+
+	source, err = GenerateAPI(schema.APIs["volume-modify-tiering-policy"])
+	if err != nil {
+		t.Fatal("Unable to generate volume-modify-tiering-policy: ", err)
+	}
+	if strings.Contains(string(source), "nextTagPtr") {
+		t.Error("Expected volume-modify-tiering-policy not to be paginated.")
+	}
+	if !strings.Contains(string(source), "TieringPolicyPtr *TieringPolicyType `xml:\"tiering-policy\"`") {
+		t.Error("Expected a tiering policy in generated volume-modify-tiering-policy.")
+	}
+}
+
+func TestMergeTypes(t *testing.T) {
+	schema, err := LoadSchema(sampleSchema)
+	if err != nil {
+		t.Fatal("Unable to load schema: ", err)
+	}
+	generated := make(map[string][]byte)
+	for _, name := range []string{"volume-qos-attributes", "tiering-policy"} {
+		typeDef := schema.Types[name]
+		if generated[typeDef.GoName], err = GenerateType(typeDef); err != nil {
+			t.Fatalf("Unable to generate %s: %v", name, err)
+		}
+	}
+
+	merged, err := MergeTypes([]byte(sampleTypes), generated, false)
+	if err != nil {
+		t.Fatal("Unable to merge types: ", err)
+	}
+	result := string(merged)
+
+	// The replacement takes the place of the original, which is removed entirely
+	qos := strings.Index(result, "type VolumeQosAttributesType struct {")
+	if qos < 0 || qos > strings.Index(result, "type ProtocolType string") {
+		t.Error("Expected VolumeQosAttributesType to be replaced in place.")
+	}
+	if strings.Count(result, "func NewVolumeQosAttributesType()") != 1 {
+		t.Error("Expected a single VolumeQosAttributesType factory.")
+	}
+	if !strings.Contains(result, "func (o *VolumeQosAttributesType) AdaptivePolicyGroupName() string {") {
+		t.Error("Expected the adaptive QoS policy group to be added.")
+	}
+
+	// New types are appended, and everything else is untouched
+	if !strings.HasSuffix(result, "type ProtocolType string\n\ntype TieringPolicyType string\n") {
+		t.Errorf("Expected TieringPolicyType to be appended, got:\n%s", result)
+	}
+	if !strings.Contains(result, sampleTypes[:strings.Index(sampleTypes, "type VolumeQosAttributesType")]) {
+		t.Error("Expected the preceding types to be unchanged.")
+	}
+
+	// Hand-modified types are only replaced if forced
+	vserverAggrInfo := &TypeDef{Name: "vserver-aggr-info", GoName: "VserverAggrInfoType"}
+	source, err := GenerateType(vserverAggrInfo)
+	if err != nil {
+		t.Fatal("Unable to generate vserver-aggr-info: ", err)
+	}
+	generated = map[string][]byte{"VserverAggrInfoType": source}
+	if _, err = MergeTypes([]byte(sampleTypes), generated, false); err == nil {
+		t.Error("Expected an error replacing a type marked " + keepMarker + ".")
+	}
+	if _, err = MergeTypes([]byte(sampleTypes), generated, true); err != nil {
+		t.Error("Unable to force replacement of a type: ", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "azgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, typesFile), []byte(sampleTypes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = generate(sampleSchema, dir, []string{"volume-get-iter"}, []string{"volume-qos-attributes"}, false)
+	if err != nil {
+		t.Fatal("Unable to generate bindings: ", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "volume-get-iter.go")); err != nil {
+		t.Error("Expected volume-get-iter.go to be written: ", err)
+	}
+	types, err := ioutil.ReadFile(filepath.Join(dir, typesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Types the API depends on are added too
+	for _, name := range []string{"VolumeAttributesType", "VolumeIdAttributesType", "VolumeCompAggrAttributesType",
+		"TieringPolicyType"} {
+		if !strings.Contains(string(types), "type "+name+" ") {
+			t.Errorf("Expected %s to be generated.", name)
+		}
+	}
+	if strings.Count(string(types), "type VolumeQosAttributesType struct") != 1 {
+		t.Error("Expected VolumeQosAttributesType to be declared once.")
+	}
+}
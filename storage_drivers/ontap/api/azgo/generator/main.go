@@ -0,0 +1,206 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+// The generator regenerates the azgo ZAPI bindings from ONTAP ZAPI schemas.  Each API named on
+// the command line is written to its own file in the output directory, and each typedef named with
+// -types is replaced in the output directory's types.go.  Typedefs referenced by the generated
+// code that the azgo package doesn't yet declare are added to types.go as well.  See README.md
+// for how to run it with make.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const typesFile = "types.go"
+
+var (
+	schemaPath = flag.String("schema", "", "ZAPI schema file, or directory of .xsd files")
+	outDir     = flag.String("out", ".", "Directory of the azgo package")
+	typeNames  = flag.String("types", "", "Comma-separated typedefs to regenerate, such as volume-attributes")
+	force      = flag.Bool("force", false, "Regenerate typedefs marked "+keepMarker)
+)
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -schema <path> [-out <dir>] [-types <typedefs>] [api ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *schemaPath == "" || (flag.NArg() == 0 && *typeNames == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	if err := generate(*schemaPath, *outDir, flag.Args(), types, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the named APIs and typedefs, plus any typedefs they need, to the azgo package.
+func generate(schemaPath, outDir string, apiNames, typeNames []string, force bool) error {
+
+	schema, err := LoadSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	sources, err := readPackage(outDir)
+	if err != nil {
+		return err
+	}
+
+	// Generate the APIs, noting the types they use
+	referenced := make(map[string]bool)
+	for _, name := range apiNames {
+		api, ok := schema.APIs[name]
+		if !ok {
+			return fmt.Errorf("API %s is not in the schema", name)
+		}
+		source, err := GenerateAPI(api)
+		if err != nil {
+			return err
+		}
+		filename := name + ".go"
+		sources[filename] = source
+		if err = ioutil.WriteFile(filepath.Join(outDir, filename), source, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Generated %s.\n", filename)
+
+		for _, field := range api.Inputs {
+			referenced[field.Type] = true
+		}
+		for _, field := range api.Outputs {
+			referenced[field.Type] = true
+		}
+	}
+
+	// Generate the requested typedefs, along with any missing ones they or the APIs depend on
+	declared, err := DeclaredTypes(sources)
+	if err != nil {
+		return err
+	}
+	byGoName := make(map[string]*TypeDef)
+	for _, typeDef := range schema.Types {
+		byGoName[typeDef.GoName] = typeDef
+	}
+
+	generated := make(map[string][]byte)
+	pending := make([]*TypeDef, 0)
+	for _, name := range typeNames {
+		typeDef, ok := schema.Types[name]
+		if !ok {
+			return fmt.Errorf("typedef %s is not in the schema", name)
+		}
+		pending = append(pending, typeDef)
+	}
+	for goName := range referenced {
+		if typeDef, ok := byGoName[goName]; ok && !declared[goName] {
+			pending = append(pending, typeDef)
+		}
+	}
+	for len(pending) > 0 {
+		typeDef := pending[0]
+		pending = pending[1:]
+		if _, ok := generated[typeDef.GoName]; ok {
+			continue
+		}
+		if generated[typeDef.GoName], err = GenerateType(typeDef); err != nil {
+			return err
+		}
+		for _, field := range typeDef.Fields {
+			if dependency, ok := byGoName[field.Type]; ok && !declared[field.Type] {
+				pending = append(pending, dependency)
+			}
+		}
+	}
+	if len(generated) == 0 {
+		return nil
+	}
+
+	merged, err := MergeTypes(sources[typesFile], generated, force)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(filepath.Join(outDir, typesFile), merged, 0644); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(generated))
+	for name := range generated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("Generated %s in %s.\n", strings.Join(names, ", "), typesFile)
+
+	return nil
+}
+
+// readPackage reads the Go source files in a directory, excluding tests.
+func readPackage(dir string) (map[string][]byte, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string][]byte)
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		if sources[filepath.Base(filename)], err = ioutil.ReadFile(filename); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := sources[typesFile]; !ok {
+		sources[typesFile] = []byte("// Copyright 2018 NetApp, Inc. All Rights Reserved.\n\npackage azgo\n")
+	}
+	return sources, nil
+}
+
+// GenerateAPI returns the formatted source of a file holding the bindings for a ZAPI call.
+func GenerateAPI(api *API) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(fileHeader)
+	if err := templates.ExecuteTemplate(&buffer, "api", api); err != nil {
+		return nil, err
+	}
+	return formatSource(api.Name, buffer.Bytes())
+}
+
+// GenerateType returns the formatted source of the bindings for a ZAPI typedef.
+func GenerateType(typeDef *TypeDef) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("package azgo\n")
+	if err := templates.ExecuteTemplate(&buffer, "typedef", typeDef); err != nil {
+		return nil, err
+	}
+	source, err := formatSource(typeDef.Name, buffer.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimPrefix(source, []byte("package azgo\n")), nil
+}
+
+func formatSource(name string, source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code for %s: %v\n%s", name, err, source)
+	}
+	return formatted, nil
+}
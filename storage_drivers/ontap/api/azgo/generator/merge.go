@@ -0,0 +1,163 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// keepMarker identifies a typedef in types.go that has been customized by hand, so must not be
+// overwritten by the generator.
+const keepMarker = "azgo:keep"
+
+// span is a range of byte offsets within a source file.
+type span struct {
+	start, end int
+}
+
+// MergeTypes replaces the declarations of the supplied typedefs in a types file, along with their
+// factories and methods, with newly generated code.  Each replacement takes the place of the
+// declaration it replaces; typedefs not yet declared are appended.  The rest of the file is left
+// exactly as it was.
+func MergeTypes(source []byte, generated map[string][]byte, force bool) ([]byte, error) {
+
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "types.go", source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(pos token.Pos) int { return fileSet.Position(pos).Offset }
+
+	removals := make([]span, 0)
+	insertAt := make(map[string]int)
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE || len(decl.Specs) != 1 {
+				continue
+			}
+			name := decl.Specs[0].(*ast.TypeSpec).Name.Name
+			if _, ok := generated[name]; !ok {
+				continue
+			}
+			start := offset(decl.Pos())
+			if decl.Doc != nil {
+				start = offset(decl.Doc.Pos())
+				if strings.Contains(decl.Doc.Text(), keepMarker) && !force {
+					return nil, fmt.Errorf("%s is marked %s; use -force to regenerate it", name, keepMarker)
+				}
+			}
+			insertAt[name] = start
+			removals = append(removals, span{start, offset(decl.End())})
+
+		case *ast.FuncDecl:
+			if name := declaredFor(decl); name != "" {
+				if _, ok := generated[name]; !ok {
+					continue
+				}
+				start := offset(decl.Pos())
+				if decl.Doc != nil {
+					start = offset(decl.Doc.Pos())
+				}
+				removals = append(removals, span{start, offset(decl.End())})
+			}
+		}
+	}
+
+	sort.Slice(removals, func(i, j int) bool { return removals[i].start < removals[j].start })
+
+	var buffer bytes.Buffer
+	last := 0
+	for _, removal := range removals {
+		buffer.Write(source[last:removal.start])
+		for name, at := range insertAt {
+			if at == removal.start {
+				buffer.Write(bytes.TrimSpace(generated[name]))
+				buffer.WriteString("\n\n")
+			}
+		}
+		last = skipBlankLines(source, removal.end)
+	}
+	buffer.Write(source[last:])
+	merged := append(bytes.TrimRight(buffer.Bytes(), "\n"), '\n')
+
+	// Append any typedefs that weren't already declared, in a stable order
+	names := make([]string, 0)
+	for name := range generated {
+		if _, ok := insertAt[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		merged = append(merged, '\n')
+		merged = append(merged, bytes.TrimSpace(generated[name])...)
+		merged = append(merged, '\n')
+	}
+
+	// Make sure the result is still valid Go
+	if _, err = parser.ParseFile(token.NewFileSet(), "types.go", merged, 0); err != nil {
+		return nil, fmt.Errorf("merged types are invalid: %v", err)
+	}
+	return merged, nil
+}
+
+// declaredFor returns the type whose method or factory a function is, or an empty string.
+func declaredFor(decl *ast.FuncDecl) string {
+	if decl.Recv != nil && len(decl.Recv.List) == 1 {
+		receiver := decl.Recv.List[0].Type
+		if star, ok := receiver.(*ast.StarExpr); ok {
+			receiver = star.X
+		}
+		if ident, ok := receiver.(*ast.Ident); ok {
+			return ident.Name
+		}
+		return ""
+	}
+	if strings.HasPrefix(decl.Name.Name, "New") {
+		return strings.TrimPrefix(decl.Name.Name, "New")
+	}
+	return ""
+}
+
+// skipBlankLines returns the offset of the first line after position i that isn't blank.
+func skipBlankLines(source []byte, i int) int {
+	for i < len(source) {
+		end := bytes.IndexByte(source[i:], '\n')
+		if end < 0 {
+			return i
+		}
+		if len(bytes.TrimSpace(source[i:i+end])) != 0 {
+			return i
+		}
+		i += end + 1
+	}
+	return i
+}
+
+// DeclaredTypes returns the names of the types declared in a set of Go source files.
+func DeclaredTypes(sources map[string][]byte) (map[string]bool, error) {
+	declared := make(map[string]bool)
+	fileSet := token.NewFileSet()
+	for filename, source := range sources {
+		file, err := parser.ParseFile(fileSet, filename, source, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+				for _, spec := range decl.Specs {
+					declared[spec.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+	return declared, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// resultsSuffix names the top-level schema element describing an API's outputs
+const resultsSuffix = "-results"
+
+type xsdSchema struct {
+	ComplexTypes []xsdComplexType `xml:"complexType"`
+	SimpleTypes  []xsdSimpleType  `xml:"simpleType"`
+	Elements     []xsdElement     `xml:"element"`
+}
+
+type xsdComplexType struct {
+	Name     string       `xml:"name,attr"`
+	Elements []xsdElement `xml:"sequence>element"`
+}
+
+type xsdSimpleType struct {
+	Name        string `xml:"name,attr"`
+	Restriction struct {
+		Base string `xml:"base,attr"`
+	} `xml:"restriction"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+}
+
+// Field is a single element of a ZAPI request, result, or typedef.
+type Field struct {
+	Name   string // ZAPI element name, such as desired-attributes
+	GoName string // such as DesiredAttributes
+	Type   string // Go type of each value, such as VolumeAttributesType
+	Path   string // XML path, such as desired-attributes>volume-attributes
+	Slice  bool
+}
+
+// TypeDef is a ZAPI typedef, either a structure or a simple type such as volume-name.
+type TypeDef struct {
+	Name   string
+	GoName string
+	Base   string // Go type underlying a simple type; empty for structures
+	Fields []Field
+}
+
+// API is a ZAPI call with its inputs and outputs.
+type API struct {
+	Name    string
+	GoName  string
+	Inputs  []Field
+	Outputs []Field
+}
+
+// Iter returns true if the API returns its records in pages, which must be requested in turn.
+func (a *API) Iter() bool {
+	hasNextTag, hasAttributesList := false, false
+	for _, output := range a.Outputs {
+		switch {
+		case output.Name == "next-tag":
+			hasNextTag = true
+		case output.Name == "attributes-list" && output.Slice:
+			hasAttributesList = true
+		}
+	}
+	return hasNextTag && hasAttributesList
+}
+
+// Schema holds the typedefs and APIs read from one or more schema files.
+type Schema struct {
+	Types map[string]*TypeDef
+	APIs  map[string]*API
+}
+
+// LoadSchema reads a schema file, or every .xsd file in a directory.
+func LoadSchema(path string) (*Schema, error) {
+
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.xsd")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	schema := &Schema{
+		Types: make(map[string]*TypeDef),
+		APIs:  make(map[string]*API),
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err = schema.add(content); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", file, err)
+		}
+	}
+	return schema, nil
+}
+
+func (s *Schema) add(content []byte) error {
+
+	var x xsdSchema
+	if err := xml.Unmarshal(content, &x); err != nil {
+		return err
+	}
+
+	for _, simpleType := range x.SimpleTypes {
+		s.Types[simpleType.Name] = &TypeDef{
+			Name:   simpleType.Name,
+			GoName: typeName(simpleType.Name),
+			Base:   builtinType(simpleType.Restriction.Base),
+		}
+	}
+	for _, complexType := range x.ComplexTypes {
+		s.Types[complexType.Name] = &TypeDef{
+			Name:   complexType.Name,
+			GoName: typeName(complexType.Name),
+			Fields: fields(complexType.Elements),
+		}
+	}
+
+	// Each API is described by an element holding its inputs and another holding its outputs
+	outputs := make(map[string][]Field)
+	for _, element := range x.Elements {
+		var children []xsdElement
+		if element.ComplexType != nil {
+			children = element.ComplexType.Elements
+		}
+		if strings.HasSuffix(element.Name, resultsSuffix) {
+			outputs[strings.TrimSuffix(element.Name, resultsSuffix)] = fields(children)
+			continue
+		}
+		s.APIs[element.Name] = &API{
+			Name:   element.Name,
+			GoName: goName(element.Name),
+			Inputs: fields(children),
+		}
+	}
+	for name, apiOutputs := range outputs {
+		api, ok := s.APIs[name]
+		if !ok {
+			return fmt.Errorf("found %s%s without %s", name, resultsSuffix, name)
+		}
+		api.Outputs = apiOutputs
+	}
+	return nil
+}
+
+// fields converts schema elements to fields, sorted by name as in the existing bindings.  An
+// element wrapping a single child, such as attributes-list, becomes a field with a nested path.
+func fields(elements []xsdElement) []Field {
+	result := make([]Field, 0, len(elements))
+	for _, element := range elements {
+		field := Field{
+			Name:   element.Name,
+			GoName: goName(element.Name),
+			Path:   element.Name,
+		}
+		valueElement := element
+		if element.ComplexType != nil && len(element.ComplexType.Elements) == 1 {
+			valueElement = element.ComplexType.Elements[0]
+			field.Path = element.Name + ">" + valueElement.Name
+		}
+		field.Type = elementType(valueElement.Type)
+		field.Slice = valueElement.MaxOccurs == "unbounded" || element.MaxOccurs == "unbounded"
+		result = append(result, field)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// elementType returns the Go type for an element's schema type.
+func elementType(schemaType string) string {
+	if builtin := builtinType(schemaType); builtin != "" {
+		return builtin
+	}
+	return typeName(schemaType)
+}
+
+// builtinType returns the Go type for an XML Schema built-in type, or an empty string if the
+// type is a ZAPI typedef.
+func builtinType(schemaType string) string {
+	if colon := strings.Index(schemaType, ":"); colon >= 0 {
+		schemaType = schemaType[colon+1:]
+	}
+	switch schemaType {
+	case "string", "":
+		return "string"
+	case "boolean":
+		return "bool"
+	case "int", "integer", "long", "short", "unsignedInt", "unsignedLong", "nonNegativeInteger":
+		return "int"
+	}
+	return ""
+}
+
+// goName converts a ZAPI name, such as volume-get-iter, to a Go name, such as VolumeGetIter.
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' })
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// typeName returns the Go type for a ZAPI typedef, such as VolumeAttributesType.
+func typeName(name string) string {
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		name = name[colon+1:]
+	}
+	return goName(name) + "Type"
+}
@@ -0,0 +1,298 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+)
+
+// The templates below reproduce the bindings already in the azgo package, so that a regenerated
+// file differs from its predecessor only where the schema does.
+
+var templateFuncs = template.FuncMap{
+	"goType": func(f Field) string {
+		if f.Slice {
+			return "[]" + f.Type
+		}
+		return "*" + f.Type
+	},
+	"deref": func(f Field) string {
+		if f.Slice {
+			return ""
+		}
+		return "*"
+	},
+	"lower": func(s string) string { return strings.ToLower(s[:1]) + s[1:] },
+}
+
+const fileHeader = `// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+`
+
+// accessorsTemplate emits the String method and fluent getters and setters for a structure.
+const accessorsTemplate = `
+{{define "string"}}{{$comment := .Comment}}
+{{if $comment}}// String returns a string representation of this object's fields and implements the Stringer interface
+{{end}}func (o {{.Receiver}}) String() string {
+	var buffer bytes.Buffer
+{{- range .Attrs}}
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "{{lower .}}", o.{{.}}))
+{{- end}}
+{{- range .Fields}}
+	if o.{{.GoName}}Ptr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "{{.Name}}", {{deref .}}o.{{.GoName}}Ptr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("{{.Name}}: nil\n"))
+	}
+{{- end}}
+	return buffer.String()
+}
+{{end}}
+
+{{define "accessors"}}{{$receiver := .Receiver}}{{$comment := .Comment}}
+{{- range .Fields}}
+{{if $comment}}// {{.GoName}} is a fluent style 'getter' method that can be chained
+{{end}}func (o *{{$receiver}}) {{.GoName}}() {{if .Slice}}[]{{end}}{{.Type}} {
+	r := {{deref .}}o.{{.GoName}}Ptr
+	return r
+}
+
+{{if $comment}}// Set{{.GoName}} is a fluent style 'setter' method that can be chained
+{{end}}func (o *{{$receiver}}) Set{{.GoName}}(newValue {{if .Slice}}[]{{end}}{{.Type}}) *{{$receiver}} {
+{{- if .Slice}}
+	newSlice := make([]{{.Type}}, len(newValue))
+	copy(newSlice, newValue)
+	o.{{.GoName}}Ptr = newSlice
+{{- else}}
+	o.{{.GoName}}Ptr = &newValue
+{{- end}}
+	return o
+}
+{{end}}
+{{- end}}
+
+{{define "fields"}}
+{{- range .}}
+	{{.GoName}}Ptr {{goType .}} ` + "`" + `xml:"{{.Path}}"` + "`" + `
+{{- end}}
+{{- end}}
+`
+
+const apiTemplate = accessorsTemplate + `
+{{- define "api"}}{{$request := printf "%sRequest" .GoName}}{{$response := printf "%sResponse" .GoName}}
+{{- $result := printf "%sResponseResult" .GoName}}
+// {{$request}} is a structure to represent a {{.Name}} ZAPI request object
+type {{$request}} struct {
+	XMLName xml.Name ` + "`" + `xml:"{{.Name}}"` + "`" + `
+{{if .Inputs}}
+{{template "fields" .Inputs}}
+{{- end}}
+}
+
+// ToXML converts this object into an xml string representation
+func (o *{{$request}}) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// New{{$request}} is a factory method for creating new instances of {{$request}} objects
+func New{{$request}}() *{{$request}} { return &{{$request}}{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *{{$request}}) ExecuteUsing(zr *ZapiRunner) ({{$response}}, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "{{$request}}"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+{{if .Iter}}
+	combined := New{{$response}}()
+	var nextTagPtr *string
+	done := false
+	for done != true {
+
+		resp, err := zr.SendZapi(o)
+		if err != nil {
+			log.Errorf("API invocation failed. %v", err.Error())
+			return *combined, err
+		}
+		defer resp.Body.Close()
+		body, readErr := ioutil.ReadAll(resp.Body)
+		if readErr != nil {
+			log.Errorf("Error reading response body. %v", readErr.Error())
+			return *combined, readErr
+		}
+		if zr.TraceEnabled("{{.Name}}") {
+			log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+		}
+
+		var n {{$response}}
+		unmarshalErr := xml.Unmarshal(body, &n)
+		if unmarshalErr != nil {
+			log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+			//return *combined, unmarshalErr
+		}
+		if zr.TraceEnabled("{{.Name}}") {
+			log.Debugf("{{.Name}} result:\n%s", RedactZapi(n.Result.String()))
+		}
+
+		if err == nil {
+			nextTagPtr = n.Result.NextTagPtr
+			if nextTagPtr == nil {
+				done = true
+			} else {
+				o.SetTag(*nextTagPtr)
+			}
+
+			if n.Result.NumRecordsPtr == nil {
+				done = true
+			} else {
+				recordsRead := n.Result.NumRecords()
+				if recordsRead == 0 {
+					done = true
+				}
+			}
+
+			if n.Result.AttributesListPtr != nil {
+				combined.Result.SetAttributesList(append(combined.Result.AttributesList(), n.Result.AttributesList()...))
+			}
+
+			if done == true {
+				combined.Result.ResultErrnoAttr = n.Result.ResultErrnoAttr
+				combined.Result.ResultReasonAttr = n.Result.ResultReasonAttr
+				combined.Result.ResultStatusAttr = n.Result.ResultStatusAttr
+				combined.Result.SetNumRecords(len(combined.Result.AttributesList()))
+			}
+		}
+	}
+
+	return *combined, nil
+}
+{{else}}
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return {{$response}}{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return {{$response}}{}, readErr
+	}
+	if zr.TraceEnabled("{{.Name}}") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n {{$response}}
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return {{$response}}{}, unmarshalErr
+	}
+	if zr.TraceEnabled("{{.Name}}") {
+		log.Debugf("{{.Name}} result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+{{end}}
+{{- template "string" (structure $request .Inputs nil true)}}
+{{- template "accessors" (structure $request .Inputs nil true)}}
+// {{$response}} is a structure to represent a {{.Name}} ZAPI response object
+type {{$response}} struct {
+	XMLName xml.Name ` + "`" + `xml:"netapp"` + "`" + `
+
+	ResponseVersion string ` + "`" + `xml:"version,attr"` + "`" + `
+	ResponseXmlns   string ` + "`" + `xml:"xmlns,attr"` + "`" + `
+
+	Result {{$result}} ` + "`" + `xml:"results"` + "`" + `
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o {{$response}}) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// {{$result}} is a structure to represent a {{.Name}} ZAPI object's result
+type {{$result}} struct {
+	XMLName xml.Name ` + "`" + `xml:"results"` + "`" + `
+
+	ResultStatusAttr string ` + "`" + `xml:"status,attr"` + "`" + `
+	ResultReasonAttr string ` + "`" + `xml:"reason,attr"` + "`" + `
+	ResultErrnoAttr  string ` + "`" + `xml:"errno,attr"` + "`" + `
+{{- template "fields" .Outputs}}
+}
+
+// ToXML converts this object into an xml string representation
+func (o *{{$response}}) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// New{{$response}} is a factory method for creating new instances of {{$response}} objects
+func New{{$response}}() *{{$response}} { return &{{$response}}{} }
+{{template "string" (structure $result .Outputs resultAttrs true)}}
+{{- template "accessors" (structure $result .Outputs nil true)}}
+{{- end}}
+
+{{- define "typedef"}}
+{{- if .Base}}
+type {{.GoName}} {{.Base}}
+{{else}}
+type {{.GoName}} struct {
+	XMLName xml.Name ` + "`" + `xml:"{{.Name}}"` + "`" + `
+{{if .Fields}}
+{{template "fields" .Fields}}
+{{- end}}
+}
+
+func (o *{{.GoName}}) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func New{{.GoName}}() *{{.GoName}} { return &{{.GoName}}{} }
+{{template "string" (structure .GoName .Fields nil false)}}
+{{- template "accessors" (structure .GoName .Fields nil false)}}
+{{- end}}
+{{- end}}
+`
+
+// structure is the data passed to the string and accessors templates.
+type structure struct {
+	Receiver string
+	Fields   []Field
+	Attrs    []string
+	Comment  bool
+}
+
+var templates = template.Must(template.New("azgo").Funcs(templateFuncs).Funcs(template.FuncMap{
+	"structure": func(receiver string, fields []Field, attrs []string, comment bool) structure {
+		return structure{Receiver: receiver, Fields: fields, Attrs: attrs, Comment: comment}
+	},
+	"resultAttrs": func() []string {
+		return []string{"ResultStatusAttr", "ResultReasonAttr", "ResultErrnoAttr"}
+	},
+}).Parse(apiTemplate))
@@ -50,18 +50,18 @@ func (o *IgroupAddRequest) ExecuteUsing(zr *ZapiRunner) (IgroupAddResponse, erro
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return IgroupAddResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("igroup-add") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n IgroupAddResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return IgroupAddResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("igroup-add result:\n%s", n.Result)
+	if zr.TraceEnabled("igroup-add") {
+		log.Debugf("igroup-add result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
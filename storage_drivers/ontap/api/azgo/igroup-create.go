@@ -52,18 +52,18 @@ func (o *IgroupCreateRequest) ExecuteUsing(zr *ZapiRunner) (IgroupCreateResponse
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return IgroupCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("igroup-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n IgroupCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return IgroupCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("igroup-create result:\n%s", n.Result)
+	if zr.TraceEnabled("igroup-create") {
+		log.Debugf("igroup-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
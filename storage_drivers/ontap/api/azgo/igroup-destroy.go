@@ -49,18 +49,18 @@ func (o *IgroupDestroyRequest) ExecuteUsing(zr *ZapiRunner) (IgroupDestroyRespon
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return IgroupDestroyResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("igroup-destroy") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n IgroupDestroyResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return IgroupDestroyResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("igroup-destroy result:\n%s", n.Result)
+	if zr.TraceEnabled("igroup-destroy") {
+		log.Debugf("igroup-destroy result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -56,18 +56,18 @@ func (o *IgroupGetIterRequest) ExecuteUsing(zr *ZapiRunner) (IgroupGetIterRespon
 			log.Errorf("Error reading response body. %v", readErr.Error())
 			return *combined, readErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("response Body:\n%s", string(body))
+		if zr.TraceEnabled("igroup-get-iter") {
+			log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 		}
 
 		var n IgroupGetIterResponse
 		unmarshalErr := xml.Unmarshal(body, &n)
 		if unmarshalErr != nil {
-			log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+			log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 			//return *combined, unmarshalErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("igroup-get-iter result:\n%s", n.Result)
+		if zr.TraceEnabled("igroup-get-iter") {
+			log.Debugf("igroup-get-iter result:\n%s", RedactZapi(n.Result.String()))
 		}
 
 		if err == nil {
@@ -50,18 +50,18 @@ func (o *IgroupRemoveRequest) ExecuteUsing(zr *ZapiRunner) (IgroupRemoveResponse
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return IgroupRemoveResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("igroup-remove") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n IgroupRemoveResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return IgroupRemoveResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("igroup-remove result:\n%s", n.Result)
+	if zr.TraceEnabled("igroup-remove") {
+		log.Debugf("igroup-remove result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
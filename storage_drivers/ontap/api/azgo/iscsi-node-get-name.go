@@ -46,18 +46,18 @@ func (o *IscsiNodeGetNameRequest) ExecuteUsing(zr *ZapiRunner) (IscsiNodeGetName
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return IscsiNodeGetNameResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("iscsi-node-get-name") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n IscsiNodeGetNameResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return IscsiNodeGetNameResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("iscsi-node-get-name result:\n%s", n.Result)
+	if zr.TraceEnabled("iscsi-node-get-name") {
+		log.Debugf("iscsi-node-get-name result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -0,0 +1,137 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LicenseV2ListInfoRequest is a structure to represent a license-v2-list-info ZAPI request object
+type LicenseV2ListInfoRequest struct {
+	XMLName xml.Name `xml:"license-v2-list-info"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *LicenseV2ListInfoRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewLicenseV2ListInfoRequest is a factory method for creating new instances of LicenseV2ListInfoRequest objects
+func NewLicenseV2ListInfoRequest() *LicenseV2ListInfoRequest { return &LicenseV2ListInfoRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *LicenseV2ListInfoRequest) ExecuteUsing(zr *ZapiRunner) (LicenseV2ListInfoResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "LicenseV2ListInfoRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return LicenseV2ListInfoResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return LicenseV2ListInfoResponse{}, readErr
+	}
+	if zr.TraceEnabled("license-v2-list-info") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n LicenseV2ListInfoResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return LicenseV2ListInfoResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("license-v2-list-info") {
+		log.Debugf("license-v2-list-info result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LicenseV2ListInfoRequest) String() string {
+	var buffer bytes.Buffer
+	return buffer.String()
+}
+
+// LicenseV2ListInfoResponse is a structure to represent a license-v2-list-info ZAPI response object
+type LicenseV2ListInfoResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result LicenseV2ListInfoResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LicenseV2ListInfoResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// LicenseV2ListInfoResponseResult is a structure to represent a license-v2-list-info ZAPI object's result
+type LicenseV2ListInfoResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string              `xml:"status,attr"`
+	ResultReasonAttr string              `xml:"reason,attr"`
+	ResultErrnoAttr  string              `xml:"errno,attr"`
+	LicensesPtr      []LicenseV2InfoType `xml:"licenses>license-v2-info"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *LicenseV2ListInfoResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewLicenseV2ListInfoResponse is a factory method for creating new instances of LicenseV2ListInfoResponse objects
+func NewLicenseV2ListInfoResponse() *LicenseV2ListInfoResponse { return &LicenseV2ListInfoResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LicenseV2ListInfoResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.LicensesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "licenses", o.LicensesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("licenses: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Licenses is a fluent style 'getter' method that can be chained
+func (o *LicenseV2ListInfoResponseResult) Licenses() []LicenseV2InfoType {
+	r := o.LicensesPtr
+	return r
+}
+
+// SetLicenses is a fluent style 'setter' method that can be chained
+func (o *LicenseV2ListInfoResponseResult) SetLicenses(newValue []LicenseV2InfoType) *LicenseV2ListInfoResponseResult {
+	newSlice := make([]LicenseV2InfoType, len(newValue))
+	copy(newSlice, newValue)
+	o.LicensesPtr = newSlice
+	return o
+}
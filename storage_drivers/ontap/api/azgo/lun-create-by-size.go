@@ -59,18 +59,18 @@ func (o *LunCreateBySizeRequest) ExecuteUsing(zr *ZapiRunner) (LunCreateBySizeRe
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunCreateBySizeResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-create-by-size") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunCreateBySizeResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunCreateBySizeResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-create-by-size result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-create-by-size") {
+		log.Debugf("lun-create-by-size result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
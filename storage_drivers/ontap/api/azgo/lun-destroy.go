@@ -50,18 +50,18 @@ func (o *LunDestroyRequest) ExecuteUsing(zr *ZapiRunner) (LunDestroyResponse, er
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunDestroyResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-destroy") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunDestroyResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunDestroyResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-destroy result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-destroy") {
+		log.Debugf("lun-destroy result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -49,18 +49,18 @@ func (o *LunGetAttributeRequest) ExecuteUsing(zr *ZapiRunner) (LunGetAttributeRe
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunGetAttributeResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-get-attribute") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunGetAttributeResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunGetAttributeResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-get-attribute result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-get-attribute") {
+		log.Debugf("lun-get-attribute result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -56,18 +56,18 @@ func (o *LunGetIterRequest) ExecuteUsing(zr *ZapiRunner) (LunGetIterResponse, er
 			log.Errorf("Error reading response body. %v", readErr.Error())
 			return *combined, readErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("response Body:\n%s", string(body))
+		if zr.TraceEnabled("lun-get-iter") {
+			log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 		}
 
 		var n LunGetIterResponse
 		unmarshalErr := xml.Unmarshal(body, &n)
 		if unmarshalErr != nil {
-			log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+			log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 			//return *combined, unmarshalErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("lun-get-iter result:\n%s", n.Result)
+		if zr.TraceEnabled("lun-get-iter") {
+			log.Debugf("lun-get-iter result:\n%s", RedactZapi(n.Result.String()))
 		}
 
 		if err == nil {
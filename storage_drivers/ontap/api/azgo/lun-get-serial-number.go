@@ -48,18 +48,18 @@ func (o *LunGetSerialNumberRequest) ExecuteUsing(zr *ZapiRunner) (LunGetSerialNu
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunGetSerialNumberResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-get-serial-number") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunGetSerialNumberResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunGetSerialNumberResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-get-serial-number result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-get-serial-number") {
+		log.Debugf("lun-get-serial-number result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -48,18 +48,18 @@ func (o *LunMapListInfoRequest) ExecuteUsing(zr *ZapiRunner) (LunMapListInfoResp
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunMapListInfoResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-map-list-info") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunMapListInfoResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunMapListInfoResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-map-list-info result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-map-list-info") {
+		log.Debugf("lun-map-list-info result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
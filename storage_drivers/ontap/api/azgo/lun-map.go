@@ -52,18 +52,18 @@ func (o *LunMapRequest) ExecuteUsing(zr *ZapiRunner) (LunMapResponse, error) {
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunMapResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-map") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunMapResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunMapResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-map result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-map") {
+		log.Debugf("lun-map result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
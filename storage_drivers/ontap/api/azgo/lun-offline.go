@@ -48,18 +48,18 @@ func (o *LunOfflineRequest) ExecuteUsing(zr *ZapiRunner) (LunOfflineResponse, er
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunOfflineResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-offline") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunOfflineResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunOfflineResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-offline result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-offline") {
+		log.Debugf("lun-offline result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
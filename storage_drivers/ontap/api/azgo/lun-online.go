@@ -49,18 +49,18 @@ func (o *LunOnlineRequest) ExecuteUsing(zr *ZapiRunner) (LunOnlineResponse, erro
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunOnlineResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-online") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunOnlineResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunOnlineResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-online result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-online") {
+		log.Debugf("lun-online result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
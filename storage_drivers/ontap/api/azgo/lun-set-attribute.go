@@ -50,18 +50,18 @@ func (o *LunSetAttributeRequest) ExecuteUsing(zr *ZapiRunner) (LunSetAttributeRe
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return LunSetAttributeResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("lun-set-attribute") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n LunSetAttributeResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return LunSetAttributeResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("lun-set-attribute result:\n%s", n.Result)
+	if zr.TraceEnabled("lun-set-attribute") {
+		log.Debugf("lun-set-attribute result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
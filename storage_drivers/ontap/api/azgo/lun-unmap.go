@@ -0,0 +1,154 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LunUnmapRequest is a structure to represent a lun-unmap ZAPI request object
+type LunUnmapRequest struct {
+	XMLName xml.Name `xml:"lun-unmap"`
+
+	InitiatorGroupPtr *string `xml:"initiator-group"`
+	PathPtr           *string `xml:"path"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *LunUnmapRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewLunUnmapRequest is a factory method for creating new instances of LunUnmapRequest objects
+func NewLunUnmapRequest() *LunUnmapRequest { return &LunUnmapRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *LunUnmapRequest) ExecuteUsing(zr *ZapiRunner) (LunUnmapResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "LunUnmapRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return LunUnmapResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return LunUnmapResponse{}, readErr
+	}
+	if zr.TraceEnabled("lun-unmap") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n LunUnmapResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return LunUnmapResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("lun-unmap") {
+		log.Debugf("lun-unmap result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LunUnmapRequest) String() string {
+	var buffer bytes.Buffer
+	if o.InitiatorGroupPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "initiator-group", *o.InitiatorGroupPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("initiator-group: nil\n"))
+	}
+	if o.PathPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "path", *o.PathPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("path: nil\n"))
+	}
+	return buffer.String()
+}
+
+// InitiatorGroup is a fluent style 'getter' method that can be chained
+func (o *LunUnmapRequest) InitiatorGroup() string {
+	r := *o.InitiatorGroupPtr
+	return r
+}
+
+// SetInitiatorGroup is a fluent style 'setter' method that can be chained
+func (o *LunUnmapRequest) SetInitiatorGroup(newValue string) *LunUnmapRequest {
+	o.InitiatorGroupPtr = &newValue
+	return o
+}
+
+// Path is a fluent style 'getter' method that can be chained
+func (o *LunUnmapRequest) Path() string {
+	r := *o.PathPtr
+	return r
+}
+
+// SetPath is a fluent style 'setter' method that can be chained
+func (o *LunUnmapRequest) SetPath(newValue string) *LunUnmapRequest {
+	o.PathPtr = &newValue
+	return o
+}
+
+// LunUnmapResponse is a structure to represent a lun-unmap ZAPI response object
+type LunUnmapResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result LunUnmapResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LunUnmapResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// LunUnmapResponseResult is a structure to represent a lun-unmap ZAPI object's result
+type LunUnmapResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *LunUnmapResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewLunUnmapResponse is a factory method for creating new instances of LunUnmapResponse objects
+func NewLunUnmapResponse() *LunUnmapResponse { return &LunUnmapResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o LunUnmapResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
@@ -0,0 +1,218 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PerfObjectGetInstancesRequest is a structure to represent a perf-object-get-instances ZAPI request object
+type PerfObjectGetInstancesRequest struct {
+	XMLName xml.Name `xml:"perf-object-get-instances"`
+
+	CountersPtr   []string `xml:"counters>counter"`
+	InstancesPtr  []string `xml:"instances>instance"`
+	ObjectnamePtr *string  `xml:"objectname"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *PerfObjectGetInstancesRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewPerfObjectGetInstancesRequest is a factory method for creating new instances of PerfObjectGetInstancesRequest objects
+func NewPerfObjectGetInstancesRequest() *PerfObjectGetInstancesRequest {
+	return &PerfObjectGetInstancesRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *PerfObjectGetInstancesRequest) ExecuteUsing(zr *ZapiRunner) (PerfObjectGetInstancesResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "PerfObjectGetInstancesRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return PerfObjectGetInstancesResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return PerfObjectGetInstancesResponse{}, readErr
+	}
+	if zr.TraceEnabled("perf-object-get-instances") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n PerfObjectGetInstancesResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return PerfObjectGetInstancesResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("perf-object-get-instances") {
+		log.Debugf("perf-object-get-instances result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o PerfObjectGetInstancesRequest) String() string {
+	var buffer bytes.Buffer
+	if o.CountersPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "counters", o.CountersPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("counters: nil\n"))
+	}
+	if o.InstancesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "instances", o.InstancesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("instances: nil\n"))
+	}
+	if o.ObjectnamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "objectname", *o.ObjectnamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("objectname: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Counters is a fluent style 'getter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) Counters() []string {
+	r := o.CountersPtr
+	return r
+}
+
+// SetCounters is a fluent style 'setter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) SetCounters(newValue []string) *PerfObjectGetInstancesRequest {
+	newSlice := make([]string, len(newValue))
+	copy(newSlice, newValue)
+	o.CountersPtr = newSlice
+	return o
+}
+
+// Instances is a fluent style 'getter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) Instances() []string {
+	r := o.InstancesPtr
+	return r
+}
+
+// SetInstances is a fluent style 'setter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) SetInstances(newValue []string) *PerfObjectGetInstancesRequest {
+	newSlice := make([]string, len(newValue))
+	copy(newSlice, newValue)
+	o.InstancesPtr = newSlice
+	return o
+}
+
+// Objectname is a fluent style 'getter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) Objectname() string {
+	r := *o.ObjectnamePtr
+	return r
+}
+
+// SetObjectname is a fluent style 'setter' method that can be chained
+func (o *PerfObjectGetInstancesRequest) SetObjectname(newValue string) *PerfObjectGetInstancesRequest {
+	o.ObjectnamePtr = &newValue
+	return o
+}
+
+// PerfObjectGetInstancesResponse is a structure to represent a perf-object-get-instances ZAPI response object
+type PerfObjectGetInstancesResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result PerfObjectGetInstancesResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o PerfObjectGetInstancesResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// PerfObjectGetInstancesResponseResult is a structure to represent a perf-object-get-instances ZAPI object's result
+type PerfObjectGetInstancesResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string             `xml:"status,attr"`
+	ResultReasonAttr string             `xml:"reason,attr"`
+	ResultErrnoAttr  string             `xml:"errno,attr"`
+	InstancesPtr     []InstanceDataType `xml:"instances>instance-data"`
+	TimestampPtr     *string            `xml:"timestamp"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *PerfObjectGetInstancesResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewPerfObjectGetInstancesResponse is a factory method for creating new instances of PerfObjectGetInstancesResponse objects
+func NewPerfObjectGetInstancesResponse() *PerfObjectGetInstancesResponse {
+	return &PerfObjectGetInstancesResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o PerfObjectGetInstancesResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.InstancesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "instances", o.InstancesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("instances: nil\n"))
+	}
+	if o.TimestampPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "timestamp", *o.TimestampPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("timestamp: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Instances is a fluent style 'getter' method that can be chained
+func (o *PerfObjectGetInstancesResponseResult) Instances() []InstanceDataType {
+	r := o.InstancesPtr
+	return r
+}
+
+// SetInstances is a fluent style 'setter' method that can be chained
+func (o *PerfObjectGetInstancesResponseResult) SetInstances(newValue []InstanceDataType) *PerfObjectGetInstancesResponseResult {
+	newSlice := make([]InstanceDataType, len(newValue))
+	copy(newSlice, newValue)
+	o.InstancesPtr = newSlice
+	return o
+}
+
+// Timestamp is a fluent style 'getter' method that can be chained
+func (o *PerfObjectGetInstancesResponseResult) Timestamp() string {
+	r := *o.TimestampPtr
+	return r
+}
+
+// SetTimestamp is a fluent style 'setter' method that can be chained
+func (o *PerfObjectGetInstancesResponseResult) SetTimestamp(newValue string) *PerfObjectGetInstancesResponseResult {
+	o.TimestampPtr = &newValue
+	return o
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QosPolicyGroupCreateRequest is a structure to represent a qos-policy-group-create ZAPI request object
+type QosPolicyGroupCreateRequest struct {
+	XMLName xml.Name `xml:"qos-policy-group-create"`
+
+	MaxThroughputPtr *string `xml:"max-throughput"`
+	MinThroughputPtr *string `xml:"min-throughput"`
+	PolicyGroupPtr   *string `xml:"policy-group"`
+	VserverPtr       *string `xml:"vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *QosPolicyGroupCreateRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewQosPolicyGroupCreateRequest is a factory method for creating new instances of QosPolicyGroupCreateRequest objects
+func NewQosPolicyGroupCreateRequest() *QosPolicyGroupCreateRequest {
+	return &QosPolicyGroupCreateRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *QosPolicyGroupCreateRequest) ExecuteUsing(zr *ZapiRunner) (QosPolicyGroupCreateResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "QosPolicyGroupCreateRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return QosPolicyGroupCreateResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return QosPolicyGroupCreateResponse{}, readErr
+	}
+	if zr.TraceEnabled("qos-policy-group-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n QosPolicyGroupCreateResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return QosPolicyGroupCreateResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("qos-policy-group-create") {
+		log.Debugf("qos-policy-group-create result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupCreateRequest) String() string {
+	var buffer bytes.Buffer
+	if o.MaxThroughputPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "max-throughput", *o.MaxThroughputPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("max-throughput: nil\n"))
+	}
+	if o.MinThroughputPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "min-throughput", *o.MinThroughputPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("min-throughput: nil\n"))
+	}
+	if o.PolicyGroupPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy-group", *o.PolicyGroupPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy-group: nil\n"))
+	}
+	if o.VserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "vserver", *o.VserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// MaxThroughput is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) MaxThroughput() string {
+	r := *o.MaxThroughputPtr
+	return r
+}
+
+// SetMaxThroughput is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) SetMaxThroughput(newValue string) *QosPolicyGroupCreateRequest {
+	o.MaxThroughputPtr = &newValue
+	return o
+}
+
+// MinThroughput is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) MinThroughput() string {
+	r := *o.MinThroughputPtr
+	return r
+}
+
+// SetMinThroughput is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) SetMinThroughput(newValue string) *QosPolicyGroupCreateRequest {
+	o.MinThroughputPtr = &newValue
+	return o
+}
+
+// PolicyGroup is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) PolicyGroup() string {
+	r := *o.PolicyGroupPtr
+	return r
+}
+
+// SetPolicyGroup is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) SetPolicyGroup(newValue string) *QosPolicyGroupCreateRequest {
+	o.PolicyGroupPtr = &newValue
+	return o
+}
+
+// Vserver is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) Vserver() string {
+	r := *o.VserverPtr
+	return r
+}
+
+// SetVserver is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupCreateRequest) SetVserver(newValue string) *QosPolicyGroupCreateRequest {
+	o.VserverPtr = &newValue
+	return o
+}
+
+// QosPolicyGroupCreateResponse is a structure to represent a qos-policy-group-create ZAPI response object
+type QosPolicyGroupCreateResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result QosPolicyGroupCreateResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupCreateResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// QosPolicyGroupCreateResponseResult is a structure to represent a qos-policy-group-create ZAPI object's result
+type QosPolicyGroupCreateResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *QosPolicyGroupCreateResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewQosPolicyGroupCreateResponse is a factory method for creating new instances of QosPolicyGroupCreateResponse objects
+func NewQosPolicyGroupCreateResponse() *QosPolicyGroupCreateResponse {
+	return &QosPolicyGroupCreateResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupCreateResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
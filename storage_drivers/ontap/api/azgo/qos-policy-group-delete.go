@@ -0,0 +1,158 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QosPolicyGroupDeleteRequest is a structure to represent a qos-policy-group-delete ZAPI request object
+type QosPolicyGroupDeleteRequest struct {
+	XMLName xml.Name `xml:"qos-policy-group-delete"`
+
+	ForcePtr       *bool   `xml:"force"`
+	PolicyGroupPtr *string `xml:"policy-group"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *QosPolicyGroupDeleteRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewQosPolicyGroupDeleteRequest is a factory method for creating new instances of QosPolicyGroupDeleteRequest objects
+func NewQosPolicyGroupDeleteRequest() *QosPolicyGroupDeleteRequest {
+	return &QosPolicyGroupDeleteRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *QosPolicyGroupDeleteRequest) ExecuteUsing(zr *ZapiRunner) (QosPolicyGroupDeleteResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "QosPolicyGroupDeleteRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return QosPolicyGroupDeleteResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return QosPolicyGroupDeleteResponse{}, readErr
+	}
+	if zr.TraceEnabled("qos-policy-group-delete") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n QosPolicyGroupDeleteResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return QosPolicyGroupDeleteResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("qos-policy-group-delete") {
+		log.Debugf("qos-policy-group-delete result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupDeleteRequest) String() string {
+	var buffer bytes.Buffer
+	if o.ForcePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "force", *o.ForcePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("force: nil\n"))
+	}
+	if o.PolicyGroupPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy-group", *o.PolicyGroupPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy-group: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Force is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupDeleteRequest) Force() bool {
+	r := *o.ForcePtr
+	return r
+}
+
+// SetForce is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupDeleteRequest) SetForce(newValue bool) *QosPolicyGroupDeleteRequest {
+	o.ForcePtr = &newValue
+	return o
+}
+
+// PolicyGroup is a fluent style 'getter' method that can be chained
+func (o *QosPolicyGroupDeleteRequest) PolicyGroup() string {
+	r := *o.PolicyGroupPtr
+	return r
+}
+
+// SetPolicyGroup is a fluent style 'setter' method that can be chained
+func (o *QosPolicyGroupDeleteRequest) SetPolicyGroup(newValue string) *QosPolicyGroupDeleteRequest {
+	o.PolicyGroupPtr = &newValue
+	return o
+}
+
+// QosPolicyGroupDeleteResponse is a structure to represent a qos-policy-group-delete ZAPI response object
+type QosPolicyGroupDeleteResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result QosPolicyGroupDeleteResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupDeleteResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// QosPolicyGroupDeleteResponseResult is a structure to represent a qos-policy-group-delete ZAPI object's result
+type QosPolicyGroupDeleteResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *QosPolicyGroupDeleteResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewQosPolicyGroupDeleteResponse is a factory method for creating new instances of QosPolicyGroupDeleteResponse objects
+func NewQosPolicyGroupDeleteResponse() *QosPolicyGroupDeleteResponse {
+	return &QosPolicyGroupDeleteResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o QosPolicyGroupDeleteResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
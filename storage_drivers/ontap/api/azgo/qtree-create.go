@@ -53,18 +53,18 @@ func (o *QtreeCreateRequest) ExecuteUsing(zr *ZapiRunner) (QtreeCreateResponse,
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QtreeCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("qtree-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QtreeCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QtreeCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("qtree-create result:\n%s", n.Result)
+	if zr.TraceEnabled("qtree-create") {
+		log.Debugf("qtree-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -49,18 +49,18 @@ func (o *QtreeDeleteAsyncRequest) ExecuteUsing(zr *ZapiRunner) (QtreeDeleteAsync
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QtreeDeleteAsyncResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("qtree-delete-async") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QtreeDeleteAsyncResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QtreeDeleteAsyncResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("qtree-delete-async result:\n%s", n.Result)
+	if zr.TraceEnabled("qtree-delete-async") {
+		log.Debugf("qtree-delete-async result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -56,18 +56,18 @@ func (o *QtreeListIterRequest) ExecuteUsing(zr *ZapiRunner) (QtreeListIterRespon
 			log.Errorf("Error reading response body. %v", readErr.Error())
 			return *combined, readErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("response Body:\n%s", string(body))
+		if zr.TraceEnabled("qtree-list-iter") {
+			log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 		}
 
 		var n QtreeListIterResponse
 		unmarshalErr := xml.Unmarshal(body, &n)
 		if unmarshalErr != nil {
-			log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+			log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 			//return *combined, unmarshalErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("qtree-list-iter result:\n%s", n.Result)
+		if zr.TraceEnabled("qtree-list-iter") {
+			log.Debugf("qtree-list-iter result:\n%s", RedactZapi(n.Result.String()))
 		}
 
 		if err == nil {
@@ -49,18 +49,18 @@ func (o *QtreeRenameRequest) ExecuteUsing(zr *ZapiRunner) (QtreeRenameResponse,
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QtreeRenameResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("qtree-rename") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QtreeRenameResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QtreeRenameResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("qtree-rename result:\n%s", n.Result)
+	if zr.TraceEnabled("qtree-rename") {
+		log.Debugf("qtree-rename result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
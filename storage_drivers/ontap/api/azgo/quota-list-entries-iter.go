@@ -58,18 +58,18 @@ func (o *QuotaListEntriesIterRequest) ExecuteUsing(zr *ZapiRunner) (QuotaListEnt
 			log.Errorf("Error reading response body. %v", readErr.Error())
 			return *combined, readErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("response Body:\n%s", string(body))
+		if zr.TraceEnabled("quota-list-entries-iter") {
+			log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 		}
 
 		var n QuotaListEntriesIterResponse
 		unmarshalErr := xml.Unmarshal(body, &n)
 		if unmarshalErr != nil {
-			log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+			log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 			//return *combined, unmarshalErr
 		}
-		if zr.DebugTraceFlags["api"] {
-			log.Debugf("quota-list-entries-iter result:\n%s", n.Result)
+		if zr.TraceEnabled("quota-list-entries-iter") {
+			log.Debugf("quota-list-entries-iter result:\n%s", RedactZapi(n.Result.String()))
 		}
 
 		if err == nil {
@@ -48,18 +48,18 @@ func (o *QuotaOffRequest) ExecuteUsing(zr *ZapiRunner) (QuotaOffResponse, error)
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QuotaOffResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("quota-off") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QuotaOffResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QuotaOffResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("quota-off result:\n%s", n.Result)
+	if zr.TraceEnabled("quota-off") {
+		log.Debugf("quota-off result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
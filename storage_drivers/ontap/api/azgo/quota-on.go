@@ -48,18 +48,18 @@ func (o *QuotaOnRequest) ExecuteUsing(zr *ZapiRunner) (QuotaOnResponse, error) {
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QuotaOnResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("quota-on") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QuotaOnResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QuotaOnResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("quota-on result:\n%s", n.Result)
+	if zr.TraceEnabled("quota-on") {
+		log.Debugf("quota-on result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -48,18 +48,18 @@ func (o *QuotaResizeRequest) ExecuteUsing(zr *ZapiRunner) (QuotaResizeResponse,
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QuotaResizeResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("quota-resize") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QuotaResizeResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QuotaResizeResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("quota-resize result:\n%s", n.Result)
+	if zr.TraceEnabled("quota-resize") {
+		log.Debugf("quota-resize result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -58,18 +58,18 @@ func (o *QuotaSetEntryRequest) ExecuteUsing(zr *ZapiRunner) (QuotaSetEntryRespon
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QuotaSetEntryResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("quota-set-entry") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QuotaSetEntryResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QuotaSetEntryResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("quota-set-entry result:\n%s", n.Result)
+	if zr.TraceEnabled("quota-set-entry") {
+		log.Debugf("quota-set-entry result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -48,18 +48,18 @@ func (o *QuotaStatusRequest) ExecuteUsing(zr *ZapiRunner) (QuotaStatusResponse,
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return QuotaStatusResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("quota-status") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n QuotaStatusResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return QuotaStatusResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("quota-status result:\n%s", n.Result)
+	if zr.TraceEnabled("quota-status") {
+		log.Debugf("quota-status result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
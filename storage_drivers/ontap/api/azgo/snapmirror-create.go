@@ -0,0 +1,280 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapmirrorCreateRequest is a structure to represent a snapmirror-create ZAPI request object
+type SnapmirrorCreateRequest struct {
+	XMLName xml.Name `xml:"snapmirror-create"`
+
+	DestinationLocationPtr *string `xml:"destination-location"`
+	DestinationVolumePtr   *string `xml:"destination-volume"`
+	DestinationVserverPtr  *string `xml:"destination-vserver"`
+	PolicyPtr              *string `xml:"policy"`
+	RelationshipTypePtr    *string `xml:"relationship-type"`
+	SchedulePtr            *string `xml:"schedule"`
+	SourceLocationPtr      *string `xml:"source-location"`
+	SourceVolumePtr        *string `xml:"source-volume"`
+	SourceVserverPtr       *string `xml:"source-vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorCreateRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorCreateRequest is a factory method for creating new instances of SnapmirrorCreateRequest objects
+func NewSnapmirrorCreateRequest() *SnapmirrorCreateRequest { return &SnapmirrorCreateRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapmirrorCreateRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorCreateResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapmirrorCreateRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapmirrorCreateResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapmirrorCreateResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapmirror-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapmirrorCreateResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapmirrorCreateResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapmirror-create") {
+		log.Debugf("snapmirror-create result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorCreateRequest) String() string {
+	var buffer bytes.Buffer
+	if o.DestinationLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-location", *o.DestinationLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-location: nil\n"))
+	}
+	if o.DestinationVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-volume", *o.DestinationVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-volume: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.RelationshipTypePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "relationship-type", *o.RelationshipTypePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("relationship-type: nil\n"))
+	}
+	if o.SchedulePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule", *o.SchedulePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule: nil\n"))
+	}
+	if o.SourceLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-location", *o.SourceLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-location: nil\n"))
+	}
+	if o.SourceVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-volume", *o.SourceVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-volume: nil\n"))
+	}
+	if o.SourceVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-vserver", *o.SourceVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// DestinationLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) DestinationLocation() string {
+	r := *o.DestinationLocationPtr
+	return r
+}
+
+// SetDestinationLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetDestinationLocation(newValue string) *SnapmirrorCreateRequest {
+	o.DestinationLocationPtr = &newValue
+	return o
+}
+
+// DestinationVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) DestinationVolume() string {
+	r := *o.DestinationVolumePtr
+	return r
+}
+
+// SetDestinationVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetDestinationVolume(newValue string) *SnapmirrorCreateRequest {
+	o.DestinationVolumePtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetDestinationVserver(newValue string) *SnapmirrorCreateRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// Policy is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+// SetPolicy is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetPolicy(newValue string) *SnapmirrorCreateRequest {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+// RelationshipType is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) RelationshipType() string {
+	r := *o.RelationshipTypePtr
+	return r
+}
+
+// SetRelationshipType is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetRelationshipType(newValue string) *SnapmirrorCreateRequest {
+	o.RelationshipTypePtr = &newValue
+	return o
+}
+
+// Schedule is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) Schedule() string {
+	r := *o.SchedulePtr
+	return r
+}
+
+// SetSchedule is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetSchedule(newValue string) *SnapmirrorCreateRequest {
+	o.SchedulePtr = &newValue
+	return o
+}
+
+// SourceLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) SourceLocation() string {
+	r := *o.SourceLocationPtr
+	return r
+}
+
+// SetSourceLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetSourceLocation(newValue string) *SnapmirrorCreateRequest {
+	o.SourceLocationPtr = &newValue
+	return o
+}
+
+// SourceVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) SourceVolume() string {
+	r := *o.SourceVolumePtr
+	return r
+}
+
+// SetSourceVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetSourceVolume(newValue string) *SnapmirrorCreateRequest {
+	o.SourceVolumePtr = &newValue
+	return o
+}
+
+// SourceVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorCreateRequest) SourceVserver() string {
+	r := *o.SourceVserverPtr
+	return r
+}
+
+// SetSourceVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorCreateRequest) SetSourceVserver(newValue string) *SnapmirrorCreateRequest {
+	o.SourceVserverPtr = &newValue
+	return o
+}
+
+// SnapmirrorCreateResponse is a structure to represent a snapmirror-create ZAPI response object
+type SnapmirrorCreateResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapmirrorCreateResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorCreateResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapmirrorCreateResponseResult is a structure to represent a snapmirror-create ZAPI object's result
+type SnapmirrorCreateResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorCreateResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorCreateResponse is a factory method for creating new instances of SnapmirrorCreateResponse objects
+func NewSnapmirrorCreateResponse() *SnapmirrorCreateResponse { return &SnapmirrorCreateResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorCreateResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
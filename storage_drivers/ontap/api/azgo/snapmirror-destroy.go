@@ -0,0 +1,316 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapmirrorDestroyRequest is a structure to represent a snapmirror-destroy ZAPI request object
+type SnapmirrorDestroyRequest struct {
+	XMLName xml.Name `xml:"snapmirror-destroy"`
+
+	DestinationLocationPtr *string `xml:"destination-location"`
+	DestinationVolumePtr   *string `xml:"destination-volume"`
+	DestinationVserverPtr  *string `xml:"destination-vserver"`
+	SourceLocationPtr      *string `xml:"source-location"`
+	SourceVolumePtr        *string `xml:"source-volume"`
+	SourceVserverPtr       *string `xml:"source-vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorDestroyRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorDestroyRequest is a factory method for creating new instances of SnapmirrorDestroyRequest objects
+func NewSnapmirrorDestroyRequest() *SnapmirrorDestroyRequest { return &SnapmirrorDestroyRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapmirrorDestroyRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorDestroyResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapmirrorDestroyRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapmirrorDestroyResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapmirrorDestroyResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapmirror-destroy") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapmirrorDestroyResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapmirrorDestroyResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapmirror-destroy") {
+		log.Debugf("snapmirror-destroy result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorDestroyRequest) String() string {
+	var buffer bytes.Buffer
+	if o.DestinationLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-location", *o.DestinationLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-location: nil\n"))
+	}
+	if o.DestinationVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-volume", *o.DestinationVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-volume: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.SourceLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-location", *o.SourceLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-location: nil\n"))
+	}
+	if o.SourceVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-volume", *o.SourceVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-volume: nil\n"))
+	}
+	if o.SourceVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-vserver", *o.SourceVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// DestinationLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) DestinationLocation() string {
+	r := *o.DestinationLocationPtr
+	return r
+}
+
+// SetDestinationLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetDestinationLocation(newValue string) *SnapmirrorDestroyRequest {
+	o.DestinationLocationPtr = &newValue
+	return o
+}
+
+// DestinationVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) DestinationVolume() string {
+	r := *o.DestinationVolumePtr
+	return r
+}
+
+// SetDestinationVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetDestinationVolume(newValue string) *SnapmirrorDestroyRequest {
+	o.DestinationVolumePtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetDestinationVserver(newValue string) *SnapmirrorDestroyRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// SourceLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SourceLocation() string {
+	r := *o.SourceLocationPtr
+	return r
+}
+
+// SetSourceLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetSourceLocation(newValue string) *SnapmirrorDestroyRequest {
+	o.SourceLocationPtr = &newValue
+	return o
+}
+
+// SourceVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SourceVolume() string {
+	r := *o.SourceVolumePtr
+	return r
+}
+
+// SetSourceVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetSourceVolume(newValue string) *SnapmirrorDestroyRequest {
+	o.SourceVolumePtr = &newValue
+	return o
+}
+
+// SourceVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SourceVserver() string {
+	r := *o.SourceVserverPtr
+	return r
+}
+
+// SetSourceVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyRequest) SetSourceVserver(newValue string) *SnapmirrorDestroyRequest {
+	o.SourceVserverPtr = &newValue
+	return o
+}
+
+// SnapmirrorDestroyResponse is a structure to represent a snapmirror-destroy ZAPI response object
+type SnapmirrorDestroyResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapmirrorDestroyResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorDestroyResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapmirrorDestroyResponseResult is a structure to represent a snapmirror-destroy ZAPI object's result
+type SnapmirrorDestroyResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr      string  `xml:"status,attr"`
+	ResultReasonAttr      string  `xml:"reason,attr"`
+	ResultErrnoAttr       string  `xml:"errno,attr"`
+	ResultErrorCodePtr    *int    `xml:"result-error-code"`
+	ResultErrorMessagePtr *string `xml:"result-error-message"`
+	ResultJobidPtr        *int    `xml:"result-jobid"`
+	ResultOperationIdPtr  *string `xml:"result-operation-id"`
+	ResultStatusPtr       *string `xml:"result-status"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorDestroyResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorDestroyResponse is a factory method for creating new instances of SnapmirrorDestroyResponse objects
+func NewSnapmirrorDestroyResponse() *SnapmirrorDestroyResponse { return &SnapmirrorDestroyResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorDestroyResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.ResultErrorCodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-code", *o.ResultErrorCodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-code: nil\n"))
+	}
+	if o.ResultErrorMessagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-message", *o.ResultErrorMessagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-message: nil\n"))
+	}
+	if o.ResultJobidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-jobid", *o.ResultJobidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-jobid: nil\n"))
+	}
+	if o.ResultOperationIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-operation-id", *o.ResultOperationIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-operation-id: nil\n"))
+	}
+	if o.ResultStatusPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-status", *o.ResultStatusPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-status: nil\n"))
+	}
+	return buffer.String()
+}
+
+// ResultErrorCode is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) ResultErrorCode() int {
+	r := *o.ResultErrorCodePtr
+	return r
+}
+
+// SetResultErrorCode is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) SetResultErrorCode(newValue int) *SnapmirrorDestroyResponseResult {
+	o.ResultErrorCodePtr = &newValue
+	return o
+}
+
+// ResultErrorMessage is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) ResultErrorMessage() string {
+	r := *o.ResultErrorMessagePtr
+	return r
+}
+
+// SetResultErrorMessage is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) SetResultErrorMessage(newValue string) *SnapmirrorDestroyResponseResult {
+	o.ResultErrorMessagePtr = &newValue
+	return o
+}
+
+// ResultJobid is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) ResultJobid() int {
+	r := *o.ResultJobidPtr
+	return r
+}
+
+// SetResultJobid is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) SetResultJobid(newValue int) *SnapmirrorDestroyResponseResult {
+	o.ResultJobidPtr = &newValue
+	return o
+}
+
+// ResultOperationId is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) ResultOperationId() string {
+	r := *o.ResultOperationIdPtr
+	return r
+}
+
+// SetResultOperationId is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) SetResultOperationId(newValue string) *SnapmirrorDestroyResponseResult {
+	o.ResultOperationIdPtr = &newValue
+	return o
+}
+
+// ResultStatus is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) ResultStatus() string {
+	r := *o.ResultStatusPtr
+	return r
+}
+
+// SetResultStatus is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorDestroyResponseResult) SetResultStatus(newValue string) *SnapmirrorDestroyResponseResult {
+	o.ResultStatusPtr = &newValue
+	return o
+}
@@ -0,0 +1,320 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapmirrorInitializeRequest is a structure to represent a snapmirror-initialize ZAPI request object
+type SnapmirrorInitializeRequest struct {
+	XMLName xml.Name `xml:"snapmirror-initialize"`
+
+	DestinationLocationPtr *string `xml:"destination-location"`
+	DestinationVolumePtr   *string `xml:"destination-volume"`
+	DestinationVserverPtr  *string `xml:"destination-vserver"`
+	SourceLocationPtr      *string `xml:"source-location"`
+	SourceVolumePtr        *string `xml:"source-volume"`
+	SourceVserverPtr       *string `xml:"source-vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorInitializeRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorInitializeRequest is a factory method for creating new instances of SnapmirrorInitializeRequest objects
+func NewSnapmirrorInitializeRequest() *SnapmirrorInitializeRequest {
+	return &SnapmirrorInitializeRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapmirrorInitializeRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorInitializeResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapmirrorInitializeRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapmirrorInitializeResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapmirrorInitializeResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapmirror-initialize") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapmirrorInitializeResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapmirrorInitializeResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapmirror-initialize") {
+		log.Debugf("snapmirror-initialize result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorInitializeRequest) String() string {
+	var buffer bytes.Buffer
+	if o.DestinationLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-location", *o.DestinationLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-location: nil\n"))
+	}
+	if o.DestinationVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-volume", *o.DestinationVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-volume: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.SourceLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-location", *o.SourceLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-location: nil\n"))
+	}
+	if o.SourceVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-volume", *o.SourceVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-volume: nil\n"))
+	}
+	if o.SourceVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-vserver", *o.SourceVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// DestinationLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) DestinationLocation() string {
+	r := *o.DestinationLocationPtr
+	return r
+}
+
+// SetDestinationLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetDestinationLocation(newValue string) *SnapmirrorInitializeRequest {
+	o.DestinationLocationPtr = &newValue
+	return o
+}
+
+// DestinationVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) DestinationVolume() string {
+	r := *o.DestinationVolumePtr
+	return r
+}
+
+// SetDestinationVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetDestinationVolume(newValue string) *SnapmirrorInitializeRequest {
+	o.DestinationVolumePtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetDestinationVserver(newValue string) *SnapmirrorInitializeRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// SourceLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SourceLocation() string {
+	r := *o.SourceLocationPtr
+	return r
+}
+
+// SetSourceLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetSourceLocation(newValue string) *SnapmirrorInitializeRequest {
+	o.SourceLocationPtr = &newValue
+	return o
+}
+
+// SourceVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SourceVolume() string {
+	r := *o.SourceVolumePtr
+	return r
+}
+
+// SetSourceVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetSourceVolume(newValue string) *SnapmirrorInitializeRequest {
+	o.SourceVolumePtr = &newValue
+	return o
+}
+
+// SourceVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SourceVserver() string {
+	r := *o.SourceVserverPtr
+	return r
+}
+
+// SetSourceVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeRequest) SetSourceVserver(newValue string) *SnapmirrorInitializeRequest {
+	o.SourceVserverPtr = &newValue
+	return o
+}
+
+// SnapmirrorInitializeResponse is a structure to represent a snapmirror-initialize ZAPI response object
+type SnapmirrorInitializeResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapmirrorInitializeResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorInitializeResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapmirrorInitializeResponseResult is a structure to represent a snapmirror-initialize ZAPI object's result
+type SnapmirrorInitializeResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr      string  `xml:"status,attr"`
+	ResultReasonAttr      string  `xml:"reason,attr"`
+	ResultErrnoAttr       string  `xml:"errno,attr"`
+	ResultErrorCodePtr    *int    `xml:"result-error-code"`
+	ResultErrorMessagePtr *string `xml:"result-error-message"`
+	ResultJobidPtr        *int    `xml:"result-jobid"`
+	ResultOperationIdPtr  *string `xml:"result-operation-id"`
+	ResultStatusPtr       *string `xml:"result-status"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorInitializeResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorInitializeResponse is a factory method for creating new instances of SnapmirrorInitializeResponse objects
+func NewSnapmirrorInitializeResponse() *SnapmirrorInitializeResponse {
+	return &SnapmirrorInitializeResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorInitializeResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.ResultErrorCodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-code", *o.ResultErrorCodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-code: nil\n"))
+	}
+	if o.ResultErrorMessagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-message", *o.ResultErrorMessagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-message: nil\n"))
+	}
+	if o.ResultJobidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-jobid", *o.ResultJobidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-jobid: nil\n"))
+	}
+	if o.ResultOperationIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-operation-id", *o.ResultOperationIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-operation-id: nil\n"))
+	}
+	if o.ResultStatusPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-status", *o.ResultStatusPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-status: nil\n"))
+	}
+	return buffer.String()
+}
+
+// ResultErrorCode is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) ResultErrorCode() int {
+	r := *o.ResultErrorCodePtr
+	return r
+}
+
+// SetResultErrorCode is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) SetResultErrorCode(newValue int) *SnapmirrorInitializeResponseResult {
+	o.ResultErrorCodePtr = &newValue
+	return o
+}
+
+// ResultErrorMessage is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) ResultErrorMessage() string {
+	r := *o.ResultErrorMessagePtr
+	return r
+}
+
+// SetResultErrorMessage is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) SetResultErrorMessage(newValue string) *SnapmirrorInitializeResponseResult {
+	o.ResultErrorMessagePtr = &newValue
+	return o
+}
+
+// ResultJobid is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) ResultJobid() int {
+	r := *o.ResultJobidPtr
+	return r
+}
+
+// SetResultJobid is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) SetResultJobid(newValue int) *SnapmirrorInitializeResponseResult {
+	o.ResultJobidPtr = &newValue
+	return o
+}
+
+// ResultOperationId is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) ResultOperationId() string {
+	r := *o.ResultOperationIdPtr
+	return r
+}
+
+// SetResultOperationId is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) SetResultOperationId(newValue string) *SnapmirrorInitializeResponseResult {
+	o.ResultOperationIdPtr = &newValue
+	return o
+}
+
+// ResultStatus is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) ResultStatus() string {
+	r := *o.ResultStatusPtr
+	return r
+}
+
+// SetResultStatus is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorInitializeResponseResult) SetResultStatus(newValue string) *SnapmirrorInitializeResponseResult {
+	o.ResultStatusPtr = &newValue
+	return o
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapmirrorQuiesceRequest is a structure to represent a snapmirror-quiesce ZAPI request object
+type SnapmirrorQuiesceRequest struct {
+	XMLName xml.Name `xml:"snapmirror-quiesce"`
+
+	DestinationLocationPtr *string `xml:"destination-location"`
+	DestinationVolumePtr   *string `xml:"destination-volume"`
+	DestinationVserverPtr  *string `xml:"destination-vserver"`
+	SourceLocationPtr      *string `xml:"source-location"`
+	SourceVolumePtr        *string `xml:"source-volume"`
+	SourceVserverPtr       *string `xml:"source-vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorQuiesceRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorQuiesceRequest is a factory method for creating new instances of SnapmirrorQuiesceRequest objects
+func NewSnapmirrorQuiesceRequest() *SnapmirrorQuiesceRequest { return &SnapmirrorQuiesceRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapmirrorQuiesceRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorQuiesceResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapmirrorQuiesceRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapmirrorQuiesceResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapmirrorQuiesceResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapmirror-quiesce") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapmirrorQuiesceResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapmirrorQuiesceResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapmirror-quiesce") {
+		log.Debugf("snapmirror-quiesce result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorQuiesceRequest) String() string {
+	var buffer bytes.Buffer
+	if o.DestinationLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-location", *o.DestinationLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-location: nil\n"))
+	}
+	if o.DestinationVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-volume", *o.DestinationVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-volume: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.SourceLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-location", *o.SourceLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-location: nil\n"))
+	}
+	if o.SourceVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-volume", *o.SourceVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-volume: nil\n"))
+	}
+	if o.SourceVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-vserver", *o.SourceVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// DestinationLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) DestinationLocation() string {
+	r := *o.DestinationLocationPtr
+	return r
+}
+
+// SetDestinationLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetDestinationLocation(newValue string) *SnapmirrorQuiesceRequest {
+	o.DestinationLocationPtr = &newValue
+	return o
+}
+
+// DestinationVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) DestinationVolume() string {
+	r := *o.DestinationVolumePtr
+	return r
+}
+
+// SetDestinationVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetDestinationVolume(newValue string) *SnapmirrorQuiesceRequest {
+	o.DestinationVolumePtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetDestinationVserver(newValue string) *SnapmirrorQuiesceRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// SourceLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SourceLocation() string {
+	r := *o.SourceLocationPtr
+	return r
+}
+
+// SetSourceLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetSourceLocation(newValue string) *SnapmirrorQuiesceRequest {
+	o.SourceLocationPtr = &newValue
+	return o
+}
+
+// SourceVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SourceVolume() string {
+	r := *o.SourceVolumePtr
+	return r
+}
+
+// SetSourceVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetSourceVolume(newValue string) *SnapmirrorQuiesceRequest {
+	o.SourceVolumePtr = &newValue
+	return o
+}
+
+// SourceVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SourceVserver() string {
+	r := *o.SourceVserverPtr
+	return r
+}
+
+// SetSourceVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorQuiesceRequest) SetSourceVserver(newValue string) *SnapmirrorQuiesceRequest {
+	o.SourceVserverPtr = &newValue
+	return o
+}
+
+// SnapmirrorQuiesceResponse is a structure to represent a snapmirror-quiesce ZAPI response object
+type SnapmirrorQuiesceResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapmirrorQuiesceResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorQuiesceResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapmirrorQuiesceResponseResult is a structure to represent a snapmirror-quiesce ZAPI object's result
+type SnapmirrorQuiesceResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorQuiesceResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorQuiesceResponse is a factory method for creating new instances of SnapmirrorQuiesceResponse objects
+func NewSnapmirrorQuiesceResponse() *SnapmirrorQuiesceResponse { return &SnapmirrorQuiesceResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorQuiesceResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
@@ -0,0 +1,334 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapmirrorReleaseRequest is a structure to represent a snapmirror-release ZAPI request object
+type SnapmirrorReleaseRequest struct {
+	XMLName xml.Name `xml:"snapmirror-release"`
+
+	DestinationLocationPtr  *string `xml:"destination-location"`
+	DestinationVolumePtr    *string `xml:"destination-volume"`
+	DestinationVserverPtr   *string `xml:"destination-vserver"`
+	RelationshipInfoOnlyPtr *bool   `xml:"relationship-info-only"`
+	SourceLocationPtr       *string `xml:"source-location"`
+	SourceVolumePtr         *string `xml:"source-volume"`
+	SourceVserverPtr        *string `xml:"source-vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorReleaseRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorReleaseRequest is a factory method for creating new instances of SnapmirrorReleaseRequest objects
+func NewSnapmirrorReleaseRequest() *SnapmirrorReleaseRequest { return &SnapmirrorReleaseRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapmirrorReleaseRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorReleaseResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapmirrorReleaseRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapmirrorReleaseResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapmirrorReleaseResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapmirror-release") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapmirrorReleaseResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapmirrorReleaseResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapmirror-release") {
+		log.Debugf("snapmirror-release result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorReleaseRequest) String() string {
+	var buffer bytes.Buffer
+	if o.DestinationLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-location", *o.DestinationLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-location: nil\n"))
+	}
+	if o.DestinationVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-volume", *o.DestinationVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-volume: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.RelationshipInfoOnlyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "relationship-info-only", *o.RelationshipInfoOnlyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("relationship-info-only: nil\n"))
+	}
+	if o.SourceLocationPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-location", *o.SourceLocationPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-location: nil\n"))
+	}
+	if o.SourceVolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-volume", *o.SourceVolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-volume: nil\n"))
+	}
+	if o.SourceVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "source-vserver", *o.SourceVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("source-vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// DestinationLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) DestinationLocation() string {
+	r := *o.DestinationLocationPtr
+	return r
+}
+
+// SetDestinationLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetDestinationLocation(newValue string) *SnapmirrorReleaseRequest {
+	o.DestinationLocationPtr = &newValue
+	return o
+}
+
+// DestinationVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) DestinationVolume() string {
+	r := *o.DestinationVolumePtr
+	return r
+}
+
+// SetDestinationVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetDestinationVolume(newValue string) *SnapmirrorReleaseRequest {
+	o.DestinationVolumePtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetDestinationVserver(newValue string) *SnapmirrorReleaseRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// RelationshipInfoOnly is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) RelationshipInfoOnly() bool {
+	r := *o.RelationshipInfoOnlyPtr
+	return r
+}
+
+// SetRelationshipInfoOnly is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetRelationshipInfoOnly(newValue bool) *SnapmirrorReleaseRequest {
+	o.RelationshipInfoOnlyPtr = &newValue
+	return o
+}
+
+// SourceLocation is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SourceLocation() string {
+	r := *o.SourceLocationPtr
+	return r
+}
+
+// SetSourceLocation is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetSourceLocation(newValue string) *SnapmirrorReleaseRequest {
+	o.SourceLocationPtr = &newValue
+	return o
+}
+
+// SourceVolume is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SourceVolume() string {
+	r := *o.SourceVolumePtr
+	return r
+}
+
+// SetSourceVolume is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetSourceVolume(newValue string) *SnapmirrorReleaseRequest {
+	o.SourceVolumePtr = &newValue
+	return o
+}
+
+// SourceVserver is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SourceVserver() string {
+	r := *o.SourceVserverPtr
+	return r
+}
+
+// SetSourceVserver is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseRequest) SetSourceVserver(newValue string) *SnapmirrorReleaseRequest {
+	o.SourceVserverPtr = &newValue
+	return o
+}
+
+// SnapmirrorReleaseResponse is a structure to represent a snapmirror-release ZAPI response object
+type SnapmirrorReleaseResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapmirrorReleaseResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorReleaseResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapmirrorReleaseResponseResult is a structure to represent a snapmirror-release ZAPI object's result
+type SnapmirrorReleaseResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr      string  `xml:"status,attr"`
+	ResultReasonAttr      string  `xml:"reason,attr"`
+	ResultErrnoAttr       string  `xml:"errno,attr"`
+	ResultErrorCodePtr    *int    `xml:"result-error-code"`
+	ResultErrorMessagePtr *string `xml:"result-error-message"`
+	ResultJobidPtr        *int    `xml:"result-jobid"`
+	ResultOperationIdPtr  *string `xml:"result-operation-id"`
+	ResultStatusPtr       *string `xml:"result-status"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapmirrorReleaseResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapmirrorReleaseResponse is a factory method for creating new instances of SnapmirrorReleaseResponse objects
+func NewSnapmirrorReleaseResponse() *SnapmirrorReleaseResponse { return &SnapmirrorReleaseResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapmirrorReleaseResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	if o.ResultErrorCodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-code", *o.ResultErrorCodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-code: nil\n"))
+	}
+	if o.ResultErrorMessagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-error-message", *o.ResultErrorMessagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-error-message: nil\n"))
+	}
+	if o.ResultJobidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-jobid", *o.ResultJobidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-jobid: nil\n"))
+	}
+	if o.ResultOperationIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-operation-id", *o.ResultOperationIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-operation-id: nil\n"))
+	}
+	if o.ResultStatusPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "result-status", *o.ResultStatusPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("result-status: nil\n"))
+	}
+	return buffer.String()
+}
+
+// ResultErrorCode is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) ResultErrorCode() int {
+	r := *o.ResultErrorCodePtr
+	return r
+}
+
+// SetResultErrorCode is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) SetResultErrorCode(newValue int) *SnapmirrorReleaseResponseResult {
+	o.ResultErrorCodePtr = &newValue
+	return o
+}
+
+// ResultErrorMessage is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) ResultErrorMessage() string {
+	r := *o.ResultErrorMessagePtr
+	return r
+}
+
+// SetResultErrorMessage is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) SetResultErrorMessage(newValue string) *SnapmirrorReleaseResponseResult {
+	o.ResultErrorMessagePtr = &newValue
+	return o
+}
+
+// ResultJobid is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) ResultJobid() int {
+	r := *o.ResultJobidPtr
+	return r
+}
+
+// SetResultJobid is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) SetResultJobid(newValue int) *SnapmirrorReleaseResponseResult {
+	o.ResultJobidPtr = &newValue
+	return o
+}
+
+// ResultOperationId is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) ResultOperationId() string {
+	r := *o.ResultOperationIdPtr
+	return r
+}
+
+// SetResultOperationId is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) SetResultOperationId(newValue string) *SnapmirrorReleaseResponseResult {
+	o.ResultOperationIdPtr = &newValue
+	return o
+}
+
+// ResultStatus is a fluent style 'getter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) ResultStatus() string {
+	r := *o.ResultStatusPtr
+	return r
+}
+
+// SetResultStatus is a fluent style 'setter' method that can be chained
+func (o *SnapmirrorReleaseResponseResult) SetResultStatus(newValue string) *SnapmirrorReleaseResponseResult {
+	o.ResultStatusPtr = &newValue
+	return o
+}
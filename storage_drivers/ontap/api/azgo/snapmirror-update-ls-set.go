@@ -53,18 +53,18 @@ func (o *SnapmirrorUpdateLsSetRequest) ExecuteUsing(zr *ZapiRunner) (SnapmirrorU
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return SnapmirrorUpdateLsSetResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("snapmirror-update-ls-set") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n SnapmirrorUpdateLsSetResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return SnapmirrorUpdateLsSetResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("snapmirror-update-ls-set result:\n%s", n.Result)
+	if zr.TraceEnabled("snapmirror-update-ls-set") {
+		log.Debugf("snapmirror-update-ls-set result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -52,18 +52,18 @@ func (o *SnapshotCreateRequest) ExecuteUsing(zr *ZapiRunner) (SnapshotCreateResp
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return SnapshotCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("snapshot-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n SnapshotCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return SnapshotCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("snapshot-create result:\n%s", n.Result)
+	if zr.TraceEnabled("snapshot-create") {
+		log.Debugf("snapshot-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
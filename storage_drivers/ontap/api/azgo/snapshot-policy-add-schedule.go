@@ -0,0 +1,194 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapshotPolicyAddScheduleRequest is a structure to represent a snapshot-policy-add-schedule ZAPI request object
+type SnapshotPolicyAddScheduleRequest struct {
+	XMLName xml.Name `xml:"snapshot-policy-add-schedule"`
+
+	CountPtr    *int    `xml:"count"`
+	PolicyPtr   *string `xml:"policy"`
+	PrefixPtr   *string `xml:"prefix"`
+	SchedulePtr *string `xml:"schedule"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyAddScheduleRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyAddScheduleRequest is a factory method for creating new instances of SnapshotPolicyAddScheduleRequest objects
+func NewSnapshotPolicyAddScheduleRequest() *SnapshotPolicyAddScheduleRequest {
+	return &SnapshotPolicyAddScheduleRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapshotPolicyAddScheduleRequest) ExecuteUsing(zr *ZapiRunner) (SnapshotPolicyAddScheduleResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapshotPolicyAddScheduleRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapshotPolicyAddScheduleResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapshotPolicyAddScheduleResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapshot-policy-add-schedule") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapshotPolicyAddScheduleResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapshotPolicyAddScheduleResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapshot-policy-add-schedule") {
+		log.Debugf("snapshot-policy-add-schedule result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyAddScheduleRequest) String() string {
+	var buffer bytes.Buffer
+	if o.CountPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "count", *o.CountPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("count: nil\n"))
+	}
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.PrefixPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "prefix", *o.PrefixPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("prefix: nil\n"))
+	}
+	if o.SchedulePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule", *o.SchedulePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Count is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) Count() int {
+	r := *o.CountPtr
+	return r
+}
+
+// SetCount is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) SetCount(newValue int) *SnapshotPolicyAddScheduleRequest {
+	o.CountPtr = &newValue
+	return o
+}
+
+// Policy is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+// SetPolicy is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) SetPolicy(newValue string) *SnapshotPolicyAddScheduleRequest {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+// Prefix is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) Prefix() string {
+	r := *o.PrefixPtr
+	return r
+}
+
+// SetPrefix is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) SetPrefix(newValue string) *SnapshotPolicyAddScheduleRequest {
+	o.PrefixPtr = &newValue
+	return o
+}
+
+// Schedule is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) Schedule() string {
+	r := *o.SchedulePtr
+	return r
+}
+
+// SetSchedule is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyAddScheduleRequest) SetSchedule(newValue string) *SnapshotPolicyAddScheduleRequest {
+	o.SchedulePtr = &newValue
+	return o
+}
+
+// SnapshotPolicyAddScheduleResponse is a structure to represent a snapshot-policy-add-schedule ZAPI response object
+type SnapshotPolicyAddScheduleResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapshotPolicyAddScheduleResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyAddScheduleResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapshotPolicyAddScheduleResponseResult is a structure to represent a snapshot-policy-add-schedule ZAPI object's result
+type SnapshotPolicyAddScheduleResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyAddScheduleResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyAddScheduleResponse is a factory method for creating new instances of SnapshotPolicyAddScheduleResponse objects
+func NewSnapshotPolicyAddScheduleResponse() *SnapshotPolicyAddScheduleResponse {
+	return &SnapshotPolicyAddScheduleResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyAddScheduleResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
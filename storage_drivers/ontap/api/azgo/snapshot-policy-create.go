@@ -0,0 +1,230 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapshotPolicyCreateRequest is a structure to represent a snapshot-policy-create ZAPI request object
+type SnapshotPolicyCreateRequest struct {
+	XMLName xml.Name `xml:"snapshot-policy-create"`
+
+	CommentPtr   *string `xml:"comment"`
+	Count1Ptr    *int    `xml:"count1"`
+	EnabledPtr   *bool   `xml:"enabled"`
+	PolicyPtr    *string `xml:"policy"`
+	Prefix1Ptr   *string `xml:"prefix1"`
+	Schedule1Ptr *string `xml:"schedule1"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyCreateRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyCreateRequest is a factory method for creating new instances of SnapshotPolicyCreateRequest objects
+func NewSnapshotPolicyCreateRequest() *SnapshotPolicyCreateRequest {
+	return &SnapshotPolicyCreateRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapshotPolicyCreateRequest) ExecuteUsing(zr *ZapiRunner) (SnapshotPolicyCreateResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapshotPolicyCreateRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapshotPolicyCreateResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapshotPolicyCreateResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapshot-policy-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapshotPolicyCreateResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapshotPolicyCreateResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapshot-policy-create") {
+		log.Debugf("snapshot-policy-create result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyCreateRequest) String() string {
+	var buffer bytes.Buffer
+	if o.CommentPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "comment", *o.CommentPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("comment: nil\n"))
+	}
+	if o.Count1Ptr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "count1", *o.Count1Ptr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("count1: nil\n"))
+	}
+	if o.EnabledPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "enabled", *o.EnabledPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("enabled: nil\n"))
+	}
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.Prefix1Ptr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "prefix1", *o.Prefix1Ptr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("prefix1: nil\n"))
+	}
+	if o.Schedule1Ptr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule1", *o.Schedule1Ptr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule1: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Comment is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Comment() string {
+	r := *o.CommentPtr
+	return r
+}
+
+// SetComment is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetComment(newValue string) *SnapshotPolicyCreateRequest {
+	o.CommentPtr = &newValue
+	return o
+}
+
+// Count1 is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Count1() int {
+	r := *o.Count1Ptr
+	return r
+}
+
+// SetCount1 is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetCount1(newValue int) *SnapshotPolicyCreateRequest {
+	o.Count1Ptr = &newValue
+	return o
+}
+
+// Enabled is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Enabled() bool {
+	r := *o.EnabledPtr
+	return r
+}
+
+// SetEnabled is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetEnabled(newValue bool) *SnapshotPolicyCreateRequest {
+	o.EnabledPtr = &newValue
+	return o
+}
+
+// Policy is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+// SetPolicy is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetPolicy(newValue string) *SnapshotPolicyCreateRequest {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+// Prefix1 is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Prefix1() string {
+	r := *o.Prefix1Ptr
+	return r
+}
+
+// SetPrefix1 is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetPrefix1(newValue string) *SnapshotPolicyCreateRequest {
+	o.Prefix1Ptr = &newValue
+	return o
+}
+
+// Schedule1 is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) Schedule1() string {
+	r := *o.Schedule1Ptr
+	return r
+}
+
+// SetSchedule1 is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyCreateRequest) SetSchedule1(newValue string) *SnapshotPolicyCreateRequest {
+	o.Schedule1Ptr = &newValue
+	return o
+}
+
+// SnapshotPolicyCreateResponse is a structure to represent a snapshot-policy-create ZAPI response object
+type SnapshotPolicyCreateResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapshotPolicyCreateResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyCreateResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapshotPolicyCreateResponseResult is a structure to represent a snapshot-policy-create ZAPI object's result
+type SnapshotPolicyCreateResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyCreateResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyCreateResponse is a factory method for creating new instances of SnapshotPolicyCreateResponse objects
+func NewSnapshotPolicyCreateResponse() *SnapshotPolicyCreateResponse {
+	return &SnapshotPolicyCreateResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyCreateResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
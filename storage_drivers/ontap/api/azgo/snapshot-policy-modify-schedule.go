@@ -0,0 +1,176 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapshotPolicyModifyScheduleRequest is a structure to represent a snapshot-policy-modify-schedule ZAPI request object
+type SnapshotPolicyModifyScheduleRequest struct {
+	XMLName xml.Name `xml:"snapshot-policy-modify-schedule"`
+
+	NewCountPtr *int    `xml:"new-count"`
+	PolicyPtr   *string `xml:"policy"`
+	SchedulePtr *string `xml:"schedule"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyModifyScheduleRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyModifyScheduleRequest is a factory method for creating new instances of SnapshotPolicyModifyScheduleRequest objects
+func NewSnapshotPolicyModifyScheduleRequest() *SnapshotPolicyModifyScheduleRequest {
+	return &SnapshotPolicyModifyScheduleRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapshotPolicyModifyScheduleRequest) ExecuteUsing(zr *ZapiRunner) (SnapshotPolicyModifyScheduleResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapshotPolicyModifyScheduleRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapshotPolicyModifyScheduleResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapshotPolicyModifyScheduleResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapshot-policy-modify-schedule") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapshotPolicyModifyScheduleResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapshotPolicyModifyScheduleResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapshot-policy-modify-schedule") {
+		log.Debugf("snapshot-policy-modify-schedule result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyModifyScheduleRequest) String() string {
+	var buffer bytes.Buffer
+	if o.NewCountPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "new-count", *o.NewCountPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("new-count: nil\n"))
+	}
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.SchedulePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule", *o.SchedulePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule: nil\n"))
+	}
+	return buffer.String()
+}
+
+// NewCount is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) NewCount() int {
+	r := *o.NewCountPtr
+	return r
+}
+
+// SetNewCount is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) SetNewCount(newValue int) *SnapshotPolicyModifyScheduleRequest {
+	o.NewCountPtr = &newValue
+	return o
+}
+
+// Policy is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+// SetPolicy is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) SetPolicy(newValue string) *SnapshotPolicyModifyScheduleRequest {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+// Schedule is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) Schedule() string {
+	r := *o.SchedulePtr
+	return r
+}
+
+// SetSchedule is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyModifyScheduleRequest) SetSchedule(newValue string) *SnapshotPolicyModifyScheduleRequest {
+	o.SchedulePtr = &newValue
+	return o
+}
+
+// SnapshotPolicyModifyScheduleResponse is a structure to represent a snapshot-policy-modify-schedule ZAPI response object
+type SnapshotPolicyModifyScheduleResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapshotPolicyModifyScheduleResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyModifyScheduleResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapshotPolicyModifyScheduleResponseResult is a structure to represent a snapshot-policy-modify-schedule ZAPI object's result
+type SnapshotPolicyModifyScheduleResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyModifyScheduleResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyModifyScheduleResponse is a factory method for creating new instances of SnapshotPolicyModifyScheduleResponse objects
+func NewSnapshotPolicyModifyScheduleResponse() *SnapshotPolicyModifyScheduleResponse {
+	return &SnapshotPolicyModifyScheduleResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyModifyScheduleResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SnapshotPolicyRemoveScheduleRequest is a structure to represent a snapshot-policy-remove-schedule ZAPI request object
+type SnapshotPolicyRemoveScheduleRequest struct {
+	XMLName xml.Name `xml:"snapshot-policy-remove-schedule"`
+
+	PolicyPtr   *string `xml:"policy"`
+	SchedulePtr *string `xml:"schedule"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyRemoveScheduleRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyRemoveScheduleRequest is a factory method for creating new instances of SnapshotPolicyRemoveScheduleRequest objects
+func NewSnapshotPolicyRemoveScheduleRequest() *SnapshotPolicyRemoveScheduleRequest {
+	return &SnapshotPolicyRemoveScheduleRequest{}
+}
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *SnapshotPolicyRemoveScheduleRequest) ExecuteUsing(zr *ZapiRunner) (SnapshotPolicyRemoveScheduleResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "SnapshotPolicyRemoveScheduleRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return SnapshotPolicyRemoveScheduleResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return SnapshotPolicyRemoveScheduleResponse{}, readErr
+	}
+	if zr.TraceEnabled("snapshot-policy-remove-schedule") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n SnapshotPolicyRemoveScheduleResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return SnapshotPolicyRemoveScheduleResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("snapshot-policy-remove-schedule") {
+		log.Debugf("snapshot-policy-remove-schedule result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyRemoveScheduleRequest) String() string {
+	var buffer bytes.Buffer
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.SchedulePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule", *o.SchedulePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule: nil\n"))
+	}
+	return buffer.String()
+}
+
+// Policy is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyRemoveScheduleRequest) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+// SetPolicy is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyRemoveScheduleRequest) SetPolicy(newValue string) *SnapshotPolicyRemoveScheduleRequest {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+// Schedule is a fluent style 'getter' method that can be chained
+func (o *SnapshotPolicyRemoveScheduleRequest) Schedule() string {
+	r := *o.SchedulePtr
+	return r
+}
+
+// SetSchedule is a fluent style 'setter' method that can be chained
+func (o *SnapshotPolicyRemoveScheduleRequest) SetSchedule(newValue string) *SnapshotPolicyRemoveScheduleRequest {
+	o.SchedulePtr = &newValue
+	return o
+}
+
+// SnapshotPolicyRemoveScheduleResponse is a structure to represent a snapshot-policy-remove-schedule ZAPI response object
+type SnapshotPolicyRemoveScheduleResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result SnapshotPolicyRemoveScheduleResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyRemoveScheduleResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// SnapshotPolicyRemoveScheduleResponseResult is a structure to represent a snapshot-policy-remove-schedule ZAPI object's result
+type SnapshotPolicyRemoveScheduleResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *SnapshotPolicyRemoveScheduleResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewSnapshotPolicyRemoveScheduleResponse is a factory method for creating new instances of SnapshotPolicyRemoveScheduleResponse objects
+func NewSnapshotPolicyRemoveScheduleResponse() *SnapshotPolicyRemoveScheduleResponse {
+	return &SnapshotPolicyRemoveScheduleResponse{}
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o SnapshotPolicyRemoveScheduleResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
@@ -48,18 +48,18 @@ func (o *SystemGetOntapiVersionRequest) ExecuteUsing(zr *ZapiRunner) (SystemGetO
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return SystemGetOntapiVersionResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("system-get-ontapi-version") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n SystemGetOntapiVersionResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return SystemGetOntapiVersionResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("system-get-ontapi-version result:\n%s", n.Result)
+	if zr.TraceEnabled("system-get-ontapi-version") {
+		log.Debugf("system-get-ontapi-version result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
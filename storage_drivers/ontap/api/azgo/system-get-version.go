@@ -46,18 +46,18 @@ func (o *SystemGetVersionRequest) ExecuteUsing(zr *ZapiRunner) (SystemGetVersion
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return SystemGetVersionResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("system-get-version") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n SystemGetVersionResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return SystemGetVersionResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("system-get-version result:\n%s", n.Result)
+	if zr.TraceEnabled("system-get-version") {
+		log.Debugf("system-get-version result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
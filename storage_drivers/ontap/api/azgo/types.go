@@ -13,6 +13,7 @@ import (
 
 type NullableSizeType string
 
+// VserverAggrInfoType has been modified by hand for ONTAP 9 compatibility.  azgo:keep
 type VserverAggrInfoType struct {
 	XMLName xml.Name `xml:"vserver-aggr-info"`
 
@@ -712,6 +713,8 @@ type AggrAttributesType struct {
 	XMLName               xml.Name                `xml:"aggr-attributes"`
 	AggrRaidAttributesPtr *AggrRaidAttributesType `xml:"aggr-raid-attributes"`
 	AggregateNamePtr      *string                 `xml:"aggregate-name"`
+
+	AggrSpaceAttributesPtr *AggrSpaceAttributesType `xml:"aggr-space-attributes"`
 }
 
 func (o *AggrAttributesType) AggrRaidAttributes() AggrRaidAttributesType {
@@ -725,8 +728,11 @@ func (o *AggrAttributesType) AggregateName() string {
 }
 
 type AggrRaidAttributesType struct {
-	AggregateTypePtr *string `xml:"aggregate-type"`
-	RaidTypePtr      *string `xml:"raid-type"`
+	AggregateTypePtr        *string `xml:"aggregate-type"`
+	EncryptWithAggrKeyPtr   *bool   `xml:"encrypt-with-aggr-key"`
+	HybridCacheSizeTotalPtr *int    `xml:"hybrid-cache-size-total"`
+	IsHybridPtr             *bool   `xml:"is-hybrid"`
+	RaidTypePtr             *string `xml:"raid-type"`
 }
 
 func (o *AggrRaidAttributesType) AggregateType() string {
@@ -734,11 +740,53 @@ func (o *AggrRaidAttributesType) AggregateType() string {
 	return r
 }
 
+func (o *AggrRaidAttributesType) EncryptWithAggrKey() bool {
+	r := *o.EncryptWithAggrKeyPtr
+	return r
+}
+
+// HybridCacheSizeTotal is the usable size of a Flash Pool aggregate's SSD cache, in MB
+func (o *AggrRaidAttributesType) HybridCacheSizeTotal() int {
+	r := *o.HybridCacheSizeTotalPtr
+	return r
+}
+
+func (o *AggrRaidAttributesType) IsHybrid() bool {
+	r := *o.IsHybridPtr
+	return r
+}
+
 func (o *AggrRaidAttributesType) RaidType() string {
 	r := *o.RaidTypePtr
 	return r
 }
 
+func (o *AggrAttributesType) AggrSpaceAttributes() AggrSpaceAttributesType {
+	r := *o.AggrSpaceAttributesPtr
+	return r
+}
+
+type AggrSpaceAttributesType struct {
+	SizeAvailablePtr *int `xml:"size-available"`
+	SizeTotalPtr     *int `xml:"size-total"`
+	SizeUsedPtr      *int `xml:"size-used"`
+}
+
+func (o *AggrSpaceAttributesType) SizeAvailable() int {
+	r := *o.SizeAvailablePtr
+	return r
+}
+
+func (o *AggrSpaceAttributesType) SizeTotal() int {
+	r := *o.SizeTotalPtr
+	return r
+}
+
+func (o *AggrSpaceAttributesType) SizeUsed() int {
+	r := *o.SizeUsedPtr
+	return r
+}
+
 type VolumeModifyIterInfoType struct {
 	XMLName xml.Name `xml:"volume-modify-iter-info"`
 
@@ -10303,3 +10351,1121 @@ func (o *IscsiInterfaceListEntryInfoType) SetVserver(newValue string) *IscsiInte
 	o.VserverPtr = &newValue
 	return o
 }
+
+type LicenseV2InfoType struct {
+	XMLName xml.Name `xml:"license-v2-info"`
+
+	CustomerIdPtr     *string `xml:"customer-id"`
+	DescriptionPtr    *string `xml:"description"`
+	ExpirationTimePtr *int    `xml:"expiration-time"`
+	LegacyPtr         *bool   `xml:"legacy"`
+	OwnerPtr          *string `xml:"owner"`
+	PackagePtr        *string `xml:"package"`
+	SerialNumberPtr   *string `xml:"serial-number"`
+	TypePtr           *string `xml:"type"`
+}
+
+func (o *LicenseV2InfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewLicenseV2InfoType() *LicenseV2InfoType { return &LicenseV2InfoType{} }
+
+func (o LicenseV2InfoType) String() string {
+	var buffer bytes.Buffer
+	if o.CustomerIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "customer-id", *o.CustomerIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("customer-id: nil\n"))
+	}
+	if o.DescriptionPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "description", *o.DescriptionPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("description: nil\n"))
+	}
+	if o.ExpirationTimePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "expiration-time", *o.ExpirationTimePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("expiration-time: nil\n"))
+	}
+	if o.LegacyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "legacy", *o.LegacyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("legacy: nil\n"))
+	}
+	if o.OwnerPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "owner", *o.OwnerPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("owner: nil\n"))
+	}
+	if o.PackagePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "package", *o.PackagePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("package: nil\n"))
+	}
+	if o.SerialNumberPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "serial-number", *o.SerialNumberPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("serial-number: nil\n"))
+	}
+	if o.TypePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "type", *o.TypePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("type: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *LicenseV2InfoType) CustomerId() string {
+	r := *o.CustomerIdPtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetCustomerId(newValue string) *LicenseV2InfoType {
+	o.CustomerIdPtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) Description() string {
+	r := *o.DescriptionPtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetDescription(newValue string) *LicenseV2InfoType {
+	o.DescriptionPtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) ExpirationTime() int {
+	r := *o.ExpirationTimePtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetExpirationTime(newValue int) *LicenseV2InfoType {
+	o.ExpirationTimePtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) Legacy() bool {
+	r := *o.LegacyPtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetLegacy(newValue bool) *LicenseV2InfoType {
+	o.LegacyPtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) Owner() string {
+	r := *o.OwnerPtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetOwner(newValue string) *LicenseV2InfoType {
+	o.OwnerPtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) Package() string {
+	r := *o.PackagePtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetPackage(newValue string) *LicenseV2InfoType {
+	o.PackagePtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) SerialNumber() string {
+	r := *o.SerialNumberPtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetSerialNumber(newValue string) *LicenseV2InfoType {
+	o.SerialNumberPtr = &newValue
+	return o
+}
+
+func (o *LicenseV2InfoType) Type() string {
+	r := *o.TypePtr
+	return r
+}
+
+func (o *LicenseV2InfoType) SetType(newValue string) *LicenseV2InfoType {
+	o.TypePtr = &newValue
+	return o
+}
+
+type KeyManagerKeyInfoType struct {
+	XMLName xml.Name `xml:"key-manager-key-info"`
+
+	KeyIdPtr      *string `xml:"key-id"`
+	KeyManagerPtr *string `xml:"key-manager"`
+	KeyStorePtr   *string `xml:"key-store"`
+	KeyTagPtr     *string `xml:"key-tag"`
+	KeyTypePtr    *string `xml:"key-type"`
+	NodePtr       *string `xml:"node"`
+	RestoredPtr   *bool   `xml:"restored"`
+}
+
+func (o *KeyManagerKeyInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewKeyManagerKeyInfoType() *KeyManagerKeyInfoType { return &KeyManagerKeyInfoType{} }
+
+func (o KeyManagerKeyInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.KeyIdPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "key-id", *o.KeyIdPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("key-id: nil\n"))
+	}
+	if o.KeyManagerPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "key-manager", *o.KeyManagerPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("key-manager: nil\n"))
+	}
+	if o.KeyStorePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "key-store", *o.KeyStorePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("key-store: nil\n"))
+	}
+	if o.KeyTagPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "key-tag", *o.KeyTagPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("key-tag: nil\n"))
+	}
+	if o.KeyTypePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "key-type", *o.KeyTypePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("key-type: nil\n"))
+	}
+	if o.NodePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "node", *o.NodePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("node: nil\n"))
+	}
+	if o.RestoredPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "restored", *o.RestoredPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("restored: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *KeyManagerKeyInfoType) KeyId() string {
+	r := *o.KeyIdPtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetKeyId(newValue string) *KeyManagerKeyInfoType {
+	o.KeyIdPtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) KeyManager() string {
+	r := *o.KeyManagerPtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetKeyManager(newValue string) *KeyManagerKeyInfoType {
+	o.KeyManagerPtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) KeyStore() string {
+	r := *o.KeyStorePtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetKeyStore(newValue string) *KeyManagerKeyInfoType {
+	o.KeyStorePtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) KeyTag() string {
+	r := *o.KeyTagPtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetKeyTag(newValue string) *KeyManagerKeyInfoType {
+	o.KeyTagPtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) KeyType() string {
+	r := *o.KeyTypePtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetKeyType(newValue string) *KeyManagerKeyInfoType {
+	o.KeyTypePtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) Node() string {
+	r := *o.NodePtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetNode(newValue string) *KeyManagerKeyInfoType {
+	o.NodePtr = &newValue
+	return o
+}
+
+func (o *KeyManagerKeyInfoType) Restored() bool {
+	r := *o.RestoredPtr
+	return r
+}
+
+func (o *KeyManagerKeyInfoType) SetRestored(newValue bool) *KeyManagerKeyInfoType {
+	o.RestoredPtr = &newValue
+	return o
+}
+
+type CounterDataType struct {
+	XMLName xml.Name `xml:"counter-data"`
+
+	NamePtr  *string `xml:"name"`
+	ValuePtr *string `xml:"value"`
+}
+
+func (o *CounterDataType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewCounterDataType() *CounterDataType { return &CounterDataType{} }
+
+func (o CounterDataType) String() string {
+	var buffer bytes.Buffer
+	if o.NamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "name", *o.NamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("name: nil\n"))
+	}
+	if o.ValuePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "value", *o.ValuePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("value: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *CounterDataType) Name() string {
+	r := *o.NamePtr
+	return r
+}
+
+func (o *CounterDataType) SetName(newValue string) *CounterDataType {
+	o.NamePtr = &newValue
+	return o
+}
+
+func (o *CounterDataType) Value() string {
+	r := *o.ValuePtr
+	return r
+}
+
+func (o *CounterDataType) SetValue(newValue string) *CounterDataType {
+	o.ValuePtr = &newValue
+	return o
+}
+
+type InstanceDataType struct {
+	XMLName xml.Name `xml:"instance-data"`
+
+	CountersPtr []CounterDataType `xml:"counters>counter-data"`
+	NamePtr     *string           `xml:"name"`
+	UuidPtr     *string           `xml:"uuid"`
+}
+
+func (o *InstanceDataType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewInstanceDataType() *InstanceDataType { return &InstanceDataType{} }
+
+func (o InstanceDataType) String() string {
+	var buffer bytes.Buffer
+	if o.CountersPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "counters", o.CountersPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("counters: nil\n"))
+	}
+	if o.NamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "name", *o.NamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("name: nil\n"))
+	}
+	if o.UuidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "uuid", *o.UuidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("uuid: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *InstanceDataType) Counters() []CounterDataType {
+	r := o.CountersPtr
+	return r
+}
+
+func (o *InstanceDataType) SetCounters(newValue []CounterDataType) *InstanceDataType {
+	newSlice := make([]CounterDataType, len(newValue))
+	copy(newSlice, newValue)
+	o.CountersPtr = newSlice
+	return o
+}
+
+func (o *InstanceDataType) Name() string {
+	r := *o.NamePtr
+	return r
+}
+
+func (o *InstanceDataType) SetName(newValue string) *InstanceDataType {
+	o.NamePtr = &newValue
+	return o
+}
+
+func (o *InstanceDataType) Uuid() string {
+	r := *o.UuidPtr
+	return r
+}
+
+func (o *InstanceDataType) SetUuid(newValue string) *InstanceDataType {
+	o.UuidPtr = &newValue
+	return o
+}
+
+type QosPolicyGroupInfoType struct {
+	XMLName xml.Name `xml:"qos-policy-group-info"`
+
+	MaxThroughputPtr    *string `xml:"max-throughput"`
+	MinThroughputPtr    *string `xml:"min-throughput"`
+	NumWorkloadsPtr     *int    `xml:"num-workloads"`
+	PgidPtr             *int    `xml:"pgid"`
+	PolicyGroupPtr      *string `xml:"policy-group"`
+	PolicyGroupClassPtr *string `xml:"policy-group-class"`
+	UuidPtr             *string `xml:"uuid"`
+	VserverPtr          *string `xml:"vserver"`
+}
+
+func (o *QosPolicyGroupInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewQosPolicyGroupInfoType() *QosPolicyGroupInfoType { return &QosPolicyGroupInfoType{} }
+
+func (o QosPolicyGroupInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.MaxThroughputPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "max-throughput", *o.MaxThroughputPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("max-throughput: nil\n"))
+	}
+	if o.MinThroughputPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "min-throughput", *o.MinThroughputPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("min-throughput: nil\n"))
+	}
+	if o.NumWorkloadsPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "num-workloads", *o.NumWorkloadsPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("num-workloads: nil\n"))
+	}
+	if o.PgidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "pgid", *o.PgidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("pgid: nil\n"))
+	}
+	if o.PolicyGroupPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy-group", *o.PolicyGroupPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy-group: nil\n"))
+	}
+	if o.PolicyGroupClassPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy-group-class", *o.PolicyGroupClassPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy-group-class: nil\n"))
+	}
+	if o.UuidPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "uuid", *o.UuidPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("uuid: nil\n"))
+	}
+	if o.VserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "vserver", *o.VserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *QosPolicyGroupInfoType) MaxThroughput() string {
+	r := *o.MaxThroughputPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetMaxThroughput(newValue string) *QosPolicyGroupInfoType {
+	o.MaxThroughputPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) MinThroughput() string {
+	r := *o.MinThroughputPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetMinThroughput(newValue string) *QosPolicyGroupInfoType {
+	o.MinThroughputPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) NumWorkloads() int {
+	r := *o.NumWorkloadsPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetNumWorkloads(newValue int) *QosPolicyGroupInfoType {
+	o.NumWorkloadsPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) Pgid() int {
+	r := *o.PgidPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetPgid(newValue int) *QosPolicyGroupInfoType {
+	o.PgidPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) PolicyGroup() string {
+	r := *o.PolicyGroupPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetPolicyGroup(newValue string) *QosPolicyGroupInfoType {
+	o.PolicyGroupPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) PolicyGroupClass() string {
+	r := *o.PolicyGroupClassPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetPolicyGroupClass(newValue string) *QosPolicyGroupInfoType {
+	o.PolicyGroupClassPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) Uuid() string {
+	r := *o.UuidPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetUuid(newValue string) *QosPolicyGroupInfoType {
+	o.UuidPtr = &newValue
+	return o
+}
+
+func (o *QosPolicyGroupInfoType) Vserver() string {
+	r := *o.VserverPtr
+	return r
+}
+
+func (o *QosPolicyGroupInfoType) SetVserver(newValue string) *QosPolicyGroupInfoType {
+	o.VserverPtr = &newValue
+	return o
+}
+
+type CapabilityInfoType struct {
+	XMLName xml.Name `xml:"capability-info"`
+
+	ObjectNamePtr    *string             `xml:"object-name"`
+	OperationListPtr []OperationInfoType `xml:"operation-list>operation-info"`
+}
+
+func (o *CapabilityInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewCapabilityInfoType() *CapabilityInfoType { return &CapabilityInfoType{} }
+
+func (o CapabilityInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.ObjectNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "object-name", *o.ObjectNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("object-name: nil\n"))
+	}
+	if o.OperationListPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "operation-list", o.OperationListPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("operation-list: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *CapabilityInfoType) ObjectName() string {
+	r := *o.ObjectNamePtr
+	return r
+}
+
+func (o *CapabilityInfoType) SetObjectName(newValue string) *CapabilityInfoType {
+	o.ObjectNamePtr = &newValue
+	return o
+}
+
+func (o *CapabilityInfoType) OperationList() []OperationInfoType {
+	r := o.OperationListPtr
+	return r
+}
+
+func (o *CapabilityInfoType) SetOperationList(newValue []OperationInfoType) *CapabilityInfoType {
+	newSlice := make([]OperationInfoType, len(newValue))
+	copy(newSlice, newValue)
+	o.OperationListPtr = newSlice
+	return o
+}
+
+type OperationInfoType struct {
+	XMLName xml.Name `xml:"operation-info"`
+
+	ApiNamePtr    *string `xml:"api-name"`
+	NamePtr       *string `xml:"name"`
+	PermissionPtr *string `xml:"permission"`
+}
+
+func (o *OperationInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewOperationInfoType() *OperationInfoType { return &OperationInfoType{} }
+
+func (o OperationInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.ApiNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "api-name", *o.ApiNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("api-name: nil\n"))
+	}
+	if o.NamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "name", *o.NamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("name: nil\n"))
+	}
+	if o.PermissionPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "permission", *o.PermissionPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("permission: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *OperationInfoType) ApiName() string {
+	r := *o.ApiNamePtr
+	return r
+}
+
+func (o *OperationInfoType) SetApiName(newValue string) *OperationInfoType {
+	o.ApiNamePtr = &newValue
+	return o
+}
+
+func (o *OperationInfoType) Name() string {
+	r := *o.NamePtr
+	return r
+}
+
+func (o *OperationInfoType) SetName(newValue string) *OperationInfoType {
+	o.NamePtr = &newValue
+	return o
+}
+
+func (o *OperationInfoType) Permission() string {
+	r := *o.PermissionPtr
+	return r
+}
+
+func (o *OperationInfoType) SetPermission(newValue string) *OperationInfoType {
+	o.PermissionPtr = &newValue
+	return o
+}
+
+type ClusterPeerInfoType struct {
+	XMLName xml.Name `xml:"cluster-peer-info"`
+
+	ActiveAddressesPtr   []RemoteInetAddressType `xml:"active-addresses>remote-inet-address"`
+	AvailabilityPtr      *string                 `xml:"availability"`
+	ClusterNamePtr       *string                 `xml:"cluster-name"`
+	PeerAddressesPtr     []RemoteInetAddressType `xml:"peer-addresses>remote-inet-address"`
+	RemoteClusterNamePtr *string                 `xml:"remote-cluster-name"`
+}
+
+func (o *ClusterPeerInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewClusterPeerInfoType() *ClusterPeerInfoType { return &ClusterPeerInfoType{} }
+
+func (o ClusterPeerInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.ActiveAddressesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "active-addresses", o.ActiveAddressesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("active-addresses: nil\n"))
+	}
+	if o.AvailabilityPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "availability", *o.AvailabilityPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("availability: nil\n"))
+	}
+	if o.ClusterNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "cluster-name", *o.ClusterNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("cluster-name: nil\n"))
+	}
+	if o.PeerAddressesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "peer-addresses", o.PeerAddressesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("peer-addresses: nil\n"))
+	}
+	if o.RemoteClusterNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "remote-cluster-name", *o.RemoteClusterNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("remote-cluster-name: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *ClusterPeerInfoType) ActiveAddresses() []RemoteInetAddressType {
+	r := o.ActiveAddressesPtr
+	return r
+}
+
+func (o *ClusterPeerInfoType) SetActiveAddresses(newValue []RemoteInetAddressType) *ClusterPeerInfoType {
+	newSlice := make([]RemoteInetAddressType, len(newValue))
+	copy(newSlice, newValue)
+	o.ActiveAddressesPtr = newSlice
+	return o
+}
+
+func (o *ClusterPeerInfoType) Availability() string {
+	r := *o.AvailabilityPtr
+	return r
+}
+
+func (o *ClusterPeerInfoType) SetAvailability(newValue string) *ClusterPeerInfoType {
+	o.AvailabilityPtr = &newValue
+	return o
+}
+
+func (o *ClusterPeerInfoType) ClusterName() string {
+	r := *o.ClusterNamePtr
+	return r
+}
+
+func (o *ClusterPeerInfoType) SetClusterName(newValue string) *ClusterPeerInfoType {
+	o.ClusterNamePtr = &newValue
+	return o
+}
+
+func (o *ClusterPeerInfoType) PeerAddresses() []RemoteInetAddressType {
+	r := o.PeerAddressesPtr
+	return r
+}
+
+func (o *ClusterPeerInfoType) SetPeerAddresses(newValue []RemoteInetAddressType) *ClusterPeerInfoType {
+	newSlice := make([]RemoteInetAddressType, len(newValue))
+	copy(newSlice, newValue)
+	o.PeerAddressesPtr = newSlice
+	return o
+}
+
+func (o *ClusterPeerInfoType) RemoteClusterName() string {
+	r := *o.RemoteClusterNamePtr
+	return r
+}
+
+func (o *ClusterPeerInfoType) SetRemoteClusterName(newValue string) *ClusterPeerInfoType {
+	o.RemoteClusterNamePtr = &newValue
+	return o
+}
+
+type RemoteInetAddressType string
+
+type VserverPeerApplicationType string
+
+type VserverPeerInfoType struct {
+	XMLName xml.Name `xml:"vserver-peer-info"`
+
+	ApplicationsPtr      []VserverPeerApplicationType `xml:"applications>vserver-peer-application"`
+	PeerClusterPtr       *string                      `xml:"peer-cluster"`
+	PeerStatePtr         *VserverPeerStateType        `xml:"peer-state"`
+	PeerVserverPtr       *string                      `xml:"peer-vserver"`
+	RemoteVserverNamePtr *string                      `xml:"remote-vserver-name"`
+	VserverPtr           *string                      `xml:"vserver"`
+}
+
+func (o *VserverPeerInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewVserverPeerInfoType() *VserverPeerInfoType { return &VserverPeerInfoType{} }
+
+func (o VserverPeerInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.ApplicationsPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "applications", o.ApplicationsPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("applications: nil\n"))
+	}
+	if o.PeerClusterPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "peer-cluster", *o.PeerClusterPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("peer-cluster: nil\n"))
+	}
+	if o.PeerStatePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "peer-state", *o.PeerStatePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("peer-state: nil\n"))
+	}
+	if o.PeerVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "peer-vserver", *o.PeerVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("peer-vserver: nil\n"))
+	}
+	if o.RemoteVserverNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "remote-vserver-name", *o.RemoteVserverNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("remote-vserver-name: nil\n"))
+	}
+	if o.VserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "vserver", *o.VserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *VserverPeerInfoType) Applications() []VserverPeerApplicationType {
+	r := o.ApplicationsPtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetApplications(newValue []VserverPeerApplicationType) *VserverPeerInfoType {
+	newSlice := make([]VserverPeerApplicationType, len(newValue))
+	copy(newSlice, newValue)
+	o.ApplicationsPtr = newSlice
+	return o
+}
+
+func (o *VserverPeerInfoType) PeerCluster() string {
+	r := *o.PeerClusterPtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetPeerCluster(newValue string) *VserverPeerInfoType {
+	o.PeerClusterPtr = &newValue
+	return o
+}
+
+func (o *VserverPeerInfoType) PeerState() VserverPeerStateType {
+	r := *o.PeerStatePtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetPeerState(newValue VserverPeerStateType) *VserverPeerInfoType {
+	o.PeerStatePtr = &newValue
+	return o
+}
+
+func (o *VserverPeerInfoType) PeerVserver() string {
+	r := *o.PeerVserverPtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetPeerVserver(newValue string) *VserverPeerInfoType {
+	o.PeerVserverPtr = &newValue
+	return o
+}
+
+func (o *VserverPeerInfoType) RemoteVserverName() string {
+	r := *o.RemoteVserverNamePtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetRemoteVserverName(newValue string) *VserverPeerInfoType {
+	o.RemoteVserverNamePtr = &newValue
+	return o
+}
+
+func (o *VserverPeerInfoType) Vserver() string {
+	r := *o.VserverPtr
+	return r
+}
+
+func (o *VserverPeerInfoType) SetVserver(newValue string) *VserverPeerInfoType {
+	o.VserverPtr = &newValue
+	return o
+}
+
+type VserverPeerStateType string
+
+type SnapshotPolicyInfoType struct {
+	XMLName xml.Name `xml:"snapshot-policy-info"`
+
+	CommentPtr                 *string                    `xml:"comment"`
+	EnabledPtr                 *bool                      `xml:"enabled"`
+	PolicyPtr                  *string                    `xml:"policy"`
+	PolicyOwnerPtr             *string                    `xml:"policy-owner"`
+	SnapshotPolicySchedulesPtr []SnapshotScheduleInfoType `xml:"snapshot-policy-schedules>snapshot-schedule-info"`
+	TotalSchedulesPtr          *int                       `xml:"total-schedules"`
+	VserverNamePtr             *string                    `xml:"vserver-name"`
+}
+
+func (o *SnapshotPolicyInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewSnapshotPolicyInfoType() *SnapshotPolicyInfoType { return &SnapshotPolicyInfoType{} }
+
+func (o SnapshotPolicyInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.CommentPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "comment", *o.CommentPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("comment: nil\n"))
+	}
+	if o.EnabledPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "enabled", *o.EnabledPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("enabled: nil\n"))
+	}
+	if o.PolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy", *o.PolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy: nil\n"))
+	}
+	if o.PolicyOwnerPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "policy-owner", *o.PolicyOwnerPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("policy-owner: nil\n"))
+	}
+	if o.SnapshotPolicySchedulesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "snapshot-policy-schedules", o.SnapshotPolicySchedulesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("snapshot-policy-schedules: nil\n"))
+	}
+	if o.TotalSchedulesPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "total-schedules", *o.TotalSchedulesPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("total-schedules: nil\n"))
+	}
+	if o.VserverNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "vserver-name", *o.VserverNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("vserver-name: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *SnapshotPolicyInfoType) Comment() string {
+	r := *o.CommentPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetComment(newValue string) *SnapshotPolicyInfoType {
+	o.CommentPtr = &newValue
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) Enabled() bool {
+	r := *o.EnabledPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetEnabled(newValue bool) *SnapshotPolicyInfoType {
+	o.EnabledPtr = &newValue
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) Policy() string {
+	r := *o.PolicyPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetPolicy(newValue string) *SnapshotPolicyInfoType {
+	o.PolicyPtr = &newValue
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) PolicyOwner() string {
+	r := *o.PolicyOwnerPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetPolicyOwner(newValue string) *SnapshotPolicyInfoType {
+	o.PolicyOwnerPtr = &newValue
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) SnapshotPolicySchedules() []SnapshotScheduleInfoType {
+	r := o.SnapshotPolicySchedulesPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetSnapshotPolicySchedules(newValue []SnapshotScheduleInfoType) *SnapshotPolicyInfoType {
+	newSlice := make([]SnapshotScheduleInfoType, len(newValue))
+	copy(newSlice, newValue)
+	o.SnapshotPolicySchedulesPtr = newSlice
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) TotalSchedules() int {
+	r := *o.TotalSchedulesPtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetTotalSchedules(newValue int) *SnapshotPolicyInfoType {
+	o.TotalSchedulesPtr = &newValue
+	return o
+}
+
+func (o *SnapshotPolicyInfoType) VserverName() string {
+	r := *o.VserverNamePtr
+	return r
+}
+
+func (o *SnapshotPolicyInfoType) SetVserverName(newValue string) *SnapshotPolicyInfoType {
+	o.VserverNamePtr = &newValue
+	return o
+}
+
+type SnapshotScheduleInfoType struct {
+	XMLName xml.Name `xml:"snapshot-schedule-info"`
+
+	CountPtr           *int    `xml:"count"`
+	PrefixPtr          *string `xml:"prefix"`
+	SchedulePtr        *string `xml:"schedule"`
+	SnapmirrorLabelPtr *string `xml:"snapmirror-label"`
+}
+
+func (o *SnapshotScheduleInfoType) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	if err != nil {
+		log.Errorf("error: %v", err)
+	}
+	return string(output), err
+}
+
+func NewSnapshotScheduleInfoType() *SnapshotScheduleInfoType { return &SnapshotScheduleInfoType{} }
+
+func (o SnapshotScheduleInfoType) String() string {
+	var buffer bytes.Buffer
+	if o.CountPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "count", *o.CountPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("count: nil\n"))
+	}
+	if o.PrefixPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "prefix", *o.PrefixPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("prefix: nil\n"))
+	}
+	if o.SchedulePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "schedule", *o.SchedulePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("schedule: nil\n"))
+	}
+	if o.SnapmirrorLabelPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "snapmirror-label", *o.SnapmirrorLabelPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("snapmirror-label: nil\n"))
+	}
+	return buffer.String()
+}
+
+func (o *SnapshotScheduleInfoType) Count() int {
+	r := *o.CountPtr
+	return r
+}
+
+func (o *SnapshotScheduleInfoType) SetCount(newValue int) *SnapshotScheduleInfoType {
+	o.CountPtr = &newValue
+	return o
+}
+
+func (o *SnapshotScheduleInfoType) Prefix() string {
+	r := *o.PrefixPtr
+	return r
+}
+
+func (o *SnapshotScheduleInfoType) SetPrefix(newValue string) *SnapshotScheduleInfoType {
+	o.PrefixPtr = &newValue
+	return o
+}
+
+func (o *SnapshotScheduleInfoType) Schedule() string {
+	r := *o.SchedulePtr
+	return r
+}
+
+func (o *SnapshotScheduleInfoType) SetSchedule(newValue string) *SnapshotScheduleInfoType {
+	o.SchedulePtr = &newValue
+	return o
+}
+
+func (o *SnapshotScheduleInfoType) SnapmirrorLabel() string {
+	r := *o.SnapmirrorLabelPtr
+	return r
+}
+
+func (o *SnapshotScheduleInfoType) SetSnapmirrorLabel(newValue string) *SnapshotScheduleInfoType {
+	o.SnapmirrorLabelPtr = &newValue
+	return o
+}
@@ -57,18 +57,18 @@ func (o *VolumeCloneCreateRequest) ExecuteUsing(zr *ZapiRunner) (VolumeCloneCrea
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeCloneCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-clone-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeCloneCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeCloneCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-clone-create result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-clone-create") {
+		log.Debugf("volume-clone-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -50,18 +50,18 @@ func (o *VolumeCloneSplitStartRequest) ExecuteUsing(zr *ZapiRunner) (VolumeClone
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeCloneSplitStartResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-clone-split-start") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeCloneSplitStartResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeCloneSplitStartResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-clone-split-start result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-clone-split-start") {
+		log.Debugf("volume-clone-split-start result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
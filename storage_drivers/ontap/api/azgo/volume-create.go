@@ -47,6 +47,7 @@ type VolumeCreateRequest struct {
 	StripeConstituentVolumeCountPtr *int    `xml:"stripe-constituent-volume-count"`
 	StripeOptimizePtr               *string `xml:"stripe-optimize"`
 	StripeWidthPtr                  *int    `xml:"stripe-width"`
+	TieringPolicyPtr                *string `xml:"tiering-policy"`
 	UnixPermissionsPtr              *string `xml:"unix-permissions"`
 	UserIdPtr                       *int    `xml:"user-id"`
 	VmAlignSectorPtr                *int    `xml:"vm-align-sector"`
@@ -89,18 +90,18 @@ func (o *VolumeCreateRequest) ExecuteUsing(zr *ZapiRunner) (VolumeCreateResponse
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeCreateResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-create") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeCreateResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeCreateResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-create result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-create") {
+		log.Debugf("volume-create result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -269,6 +270,11 @@ func (o VolumeCreateRequest) String() string {
 	} else {
 		buffer.WriteString(fmt.Sprintf("stripe-width: nil\n"))
 	}
+	if o.TieringPolicyPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "tiering-policy", *o.TieringPolicyPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("tiering-policy: nil\n"))
+	}
 	if o.UnixPermissionsPtr != nil {
 		buffer.WriteString(fmt.Sprintf("%s: %v\n", "unix-permissions", *o.UnixPermissionsPtr))
 	} else {
@@ -706,6 +712,18 @@ func (o *VolumeCreateRequest) SetStripeWidth(newValue int) *VolumeCreateRequest
 	return o
 }
 
+// TieringPolicy is a fluent style 'getter' method that can be chained
+func (o *VolumeCreateRequest) TieringPolicy() string {
+	r := *o.TieringPolicyPtr
+	return r
+}
+
+// SetTieringPolicy is a fluent style 'setter' method that can be chained
+func (o *VolumeCreateRequest) SetTieringPolicy(newValue string) *VolumeCreateRequest {
+	o.TieringPolicyPtr = &newValue
+	return o
+}
+
 // UnixPermissions is a fluent style 'getter' method that can be chained
 func (o *VolumeCreateRequest) UnixPermissions() string {
 	r := *o.UnixPermissionsPtr
@@ -49,18 +49,18 @@ func (o *VolumeDestroyRequest) ExecuteUsing(zr *ZapiRunner) (VolumeDestroyRespon
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeDestroyResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-destroy") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeDestroyResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeDestroyResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-destroy result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-destroy") {
+		log.Debugf("volume-destroy result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
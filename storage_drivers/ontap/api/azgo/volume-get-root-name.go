@@ -46,18 +46,18 @@ func (o *VolumeGetRootNameRequest) ExecuteUsing(zr *ZapiRunner) (VolumeGetRootNa
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeGetRootNameResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-get-root-name") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeGetRootNameResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeGetRootNameResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-get-root-name result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-get-root-name") {
+		log.Debugf("volume-get-root-name result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
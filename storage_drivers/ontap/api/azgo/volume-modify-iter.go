@@ -55,18 +55,18 @@ func (o *VolumeModifyIterRequest) ExecuteUsing(zr *ZapiRunner) (VolumeModifyIter
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeModifyIterResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-modify-iter") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeModifyIterResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeModifyIterResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-modify-iter result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-modify-iter") {
+		log.Debugf("volume-modify-iter result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -51,18 +51,18 @@ func (o *VolumeMountRequest) ExecuteUsing(zr *ZapiRunner) (VolumeMountResponse,
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeMountResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-mount") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeMountResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeMountResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-mount result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-mount") {
+		log.Debugf("volume-mount result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
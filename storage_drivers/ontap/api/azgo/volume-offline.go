@@ -48,18 +48,18 @@ func (o *VolumeOfflineRequest) ExecuteUsing(zr *ZapiRunner) (VolumeOfflineRespon
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeOfflineResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-offline") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeOfflineResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeOfflineResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-offline result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-offline") {
+		log.Debugf("volume-offline result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
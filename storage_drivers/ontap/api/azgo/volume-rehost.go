@@ -0,0 +1,208 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// VolumeRehostRequest is a structure to represent a volume-rehost ZAPI request object
+type VolumeRehostRequest struct {
+	XMLName xml.Name `xml:"volume-rehost"`
+
+	AutoRemapLunsPtr      *bool   `xml:"auto-remap-luns"`
+	DestinationVserverPtr *string `xml:"destination-vserver"`
+	ForceUnmapLunsPtr     *bool   `xml:"force-unmap-luns"`
+	VolumePtr             *string `xml:"volume"`
+	VserverPtr            *string `xml:"vserver"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *VolumeRehostRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewVolumeRehostRequest is a factory method for creating new instances of VolumeRehostRequest objects
+func NewVolumeRehostRequest() *VolumeRehostRequest { return &VolumeRehostRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *VolumeRehostRequest) ExecuteUsing(zr *ZapiRunner) (VolumeRehostResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "VolumeRehostRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return VolumeRehostResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return VolumeRehostResponse{}, readErr
+	}
+	if zr.TraceEnabled("volume-rehost") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n VolumeRehostResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return VolumeRehostResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("volume-rehost") {
+		log.Debugf("volume-rehost result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeRehostRequest) String() string {
+	var buffer bytes.Buffer
+	if o.AutoRemapLunsPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "auto-remap-luns", *o.AutoRemapLunsPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("auto-remap-luns: nil\n"))
+	}
+	if o.DestinationVserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "destination-vserver", *o.DestinationVserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("destination-vserver: nil\n"))
+	}
+	if o.ForceUnmapLunsPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "force-unmap-luns", *o.ForceUnmapLunsPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("force-unmap-luns: nil\n"))
+	}
+	if o.VolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "volume", *o.VolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("volume: nil\n"))
+	}
+	if o.VserverPtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "vserver", *o.VserverPtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("vserver: nil\n"))
+	}
+	return buffer.String()
+}
+
+// AutoRemapLuns is a fluent style 'getter' method that can be chained
+func (o *VolumeRehostRequest) AutoRemapLuns() bool {
+	r := *o.AutoRemapLunsPtr
+	return r
+}
+
+// SetAutoRemapLuns is a fluent style 'setter' method that can be chained
+func (o *VolumeRehostRequest) SetAutoRemapLuns(newValue bool) *VolumeRehostRequest {
+	o.AutoRemapLunsPtr = &newValue
+	return o
+}
+
+// DestinationVserver is a fluent style 'getter' method that can be chained
+func (o *VolumeRehostRequest) DestinationVserver() string {
+	r := *o.DestinationVserverPtr
+	return r
+}
+
+// SetDestinationVserver is a fluent style 'setter' method that can be chained
+func (o *VolumeRehostRequest) SetDestinationVserver(newValue string) *VolumeRehostRequest {
+	o.DestinationVserverPtr = &newValue
+	return o
+}
+
+// ForceUnmapLuns is a fluent style 'getter' method that can be chained
+func (o *VolumeRehostRequest) ForceUnmapLuns() bool {
+	r := *o.ForceUnmapLunsPtr
+	return r
+}
+
+// SetForceUnmapLuns is a fluent style 'setter' method that can be chained
+func (o *VolumeRehostRequest) SetForceUnmapLuns(newValue bool) *VolumeRehostRequest {
+	o.ForceUnmapLunsPtr = &newValue
+	return o
+}
+
+// Volume is a fluent style 'getter' method that can be chained
+func (o *VolumeRehostRequest) Volume() string {
+	r := *o.VolumePtr
+	return r
+}
+
+// SetVolume is a fluent style 'setter' method that can be chained
+func (o *VolumeRehostRequest) SetVolume(newValue string) *VolumeRehostRequest {
+	o.VolumePtr = &newValue
+	return o
+}
+
+// Vserver is a fluent style 'getter' method that can be chained
+func (o *VolumeRehostRequest) Vserver() string {
+	r := *o.VserverPtr
+	return r
+}
+
+// SetVserver is a fluent style 'setter' method that can be chained
+func (o *VolumeRehostRequest) SetVserver(newValue string) *VolumeRehostRequest {
+	o.VserverPtr = &newValue
+	return o
+}
+
+// VolumeRehostResponse is a structure to represent a volume-rehost ZAPI response object
+type VolumeRehostResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result VolumeRehostResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeRehostResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// VolumeRehostResponseResult is a structure to represent a volume-rehost ZAPI object's result
+type VolumeRehostResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *VolumeRehostResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewVolumeRehostResponse is a factory method for creating new instances of VolumeRehostResponse objects
+func NewVolumeRehostResponse() *VolumeRehostResponse { return &VolumeRehostResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeRehostResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+	return buffer.String()
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package azgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// VolumeSetOptionRequest is a structure to represent a volume-set-option ZAPI request object
+type VolumeSetOptionRequest struct {
+	XMLName xml.Name `xml:"volume-set-option"`
+
+	OptionNamePtr  *string `xml:"option-name"`
+	OptionValuePtr *string `xml:"option-value"`
+	VolumePtr      *string `xml:"volume"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *VolumeSetOptionRequest) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Errorf("error: %v\n", err) }
+	return string(output), err
+}
+
+// NewVolumeSetOptionRequest is a factory method for creating new instances of VolumeSetOptionRequest objects
+func NewVolumeSetOptionRequest() *VolumeSetOptionRequest { return &VolumeSetOptionRequest{} }
+
+// ExecuteUsing converts this object to a ZAPI XML representation and uses the supplied ZapiRunner to send to a filer
+func (o *VolumeSetOptionRequest) ExecuteUsing(zr *ZapiRunner) (VolumeSetOptionResponse, error) {
+
+	if zr.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ExecuteUsing", "Type": "VolumeSetOptionRequest"}
+		log.WithFields(fields).Debug(">>>> ExecuteUsing")
+		defer log.WithFields(fields).Debug("<<<< ExecuteUsing")
+	}
+
+	resp, err := zr.SendZapi(o)
+	if err != nil {
+		log.Errorf("API invocation failed. %v", err.Error())
+		return VolumeSetOptionResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, readErr := ioutil.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Errorf("Error reading response body. %v", readErr.Error())
+		return VolumeSetOptionResponse{}, readErr
+	}
+	if zr.TraceEnabled("volume-set-option") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
+	}
+
+	var n VolumeSetOptionResponse
+	unmarshalErr := xml.Unmarshal(body, &n)
+	if unmarshalErr != nil {
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		//return VolumeSetOptionResponse{}, unmarshalErr
+	}
+	if zr.TraceEnabled("volume-set-option") {
+		log.Debugf("volume-set-option result:\n%s", RedactZapi(n.Result.String()))
+	}
+
+	return n, nil
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeSetOptionRequest) String() string {
+	var buffer bytes.Buffer
+	if o.OptionNamePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "option-name", *o.OptionNamePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("option-name: nil\n"))
+	}
+	if o.OptionValuePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "option-value", *o.OptionValuePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("option-value: nil\n"))
+	}
+	if o.VolumePtr != nil {
+		buffer.WriteString(fmt.Sprintf("%s: %v\n", "volume", *o.VolumePtr))
+	} else {
+		buffer.WriteString(fmt.Sprintf("volume: nil\n"))
+	}
+	return buffer.String()
+}
+
+// OptionName is a fluent style 'getter' method that can be chained
+func (o *VolumeSetOptionRequest) OptionName() string {
+	r := *o.OptionNamePtr
+	return r
+}
+
+// SetOptionName is a fluent style 'setter' method that can be chained
+func (o *VolumeSetOptionRequest) SetOptionName(newValue string) *VolumeSetOptionRequest {
+	o.OptionNamePtr = &newValue
+	return o
+}
+
+// OptionValue is a fluent style 'getter' method that can be chained
+func (o *VolumeSetOptionRequest) OptionValue() string {
+	r := *o.OptionValuePtr
+	return r
+}
+
+// SetOptionValue is a fluent style 'setter' method that can be chained
+func (o *VolumeSetOptionRequest) SetOptionValue(newValue string) *VolumeSetOptionRequest {
+	o.OptionValuePtr = &newValue
+	return o
+}
+
+// Volume is a fluent style 'getter' method that can be chained
+func (o *VolumeSetOptionRequest) Volume() string {
+	r := *o.VolumePtr
+	return r
+}
+
+// SetVolume is a fluent style 'setter' method that can be chained
+func (o *VolumeSetOptionRequest) SetVolume(newValue string) *VolumeSetOptionRequest {
+	o.VolumePtr = &newValue
+	return o
+}
+
+// VolumeSetOptionResponse is a structure to represent a volume-set-option ZAPI response object
+type VolumeSetOptionResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+
+	ResponseVersion string `xml:"version,attr"`
+	ResponseXmlns   string `xml:"xmlns,attr"`
+
+	Result VolumeSetOptionResponseResult `xml:"results"`
+}
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeSetOptionResponse) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "version", o.ResponseVersion))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "xmlns", o.ResponseXmlns))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "results", o.Result))
+	return buffer.String()
+}
+
+// VolumeSetOptionResponseResult is a structure to represent a volume-set-option ZAPI object's result
+type VolumeSetOptionResponseResult struct {
+	XMLName xml.Name `xml:"results"`
+
+	ResultStatusAttr string `xml:"status,attr"`
+	ResultReasonAttr string `xml:"reason,attr"`
+	ResultErrnoAttr  string `xml:"errno,attr"`
+}
+
+// ToXML converts this object into an xml string representation
+func (o *VolumeSetOptionResponse) ToXML() (string, error) {
+	output, err := xml.MarshalIndent(o, " ", "    ")
+	//if err != nil { log.Debugf("error: %v", err) }
+	return string(output), err
+}
+
+// NewVolumeSetOptionResponse is a factory method for creating new instances of VolumeSetOptionResponse objects
+func NewVolumeSetOptionResponse() *VolumeSetOptionResponse { return &VolumeSetOptionResponse{} }
+
+// String returns a string representation of this object's fields and implements the Stringer interface
+func (o VolumeSetOptionResponseResult) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultStatusAttr", o.ResultStatusAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultReasonAttr", o.ResultReasonAttr))
+	buffer.WriteString(fmt.Sprintf("%s: %s\n", "resultErrnoAttr", o.ResultErrnoAttr))
+
+	return buffer.String()
+}
@@ -49,18 +49,18 @@ func (o *VolumeSizeRequest) ExecuteUsing(zr *ZapiRunner) (VolumeSizeResponse, er
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeSizeResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-size") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeSizeResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeSizeResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-size result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-size") {
+		log.Debugf("volume-size result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -49,18 +49,18 @@ func (o *VolumeUnmountRequest) ExecuteUsing(zr *ZapiRunner) (VolumeUnmountRespon
 		log.Errorf("Error reading response body. %v", readErr.Error())
 		return VolumeUnmountResponse{}, readErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("response Body:\n%s", string(body))
+	if zr.TraceEnabled("volume-unmount") {
+		log.Debugf("response Body:\n%s", RedactZapi(string(body)))
 	}
 
 	var n VolumeUnmountResponse
 	unmarshalErr := xml.Unmarshal(body, &n)
 	if unmarshalErr != nil {
-		log.WithField("body", string(body)).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
+		log.WithField("body", RedactZapi(string(body))).Warnf("Error unmarshaling response body. %v", unmarshalErr.Error())
 		//return VolumeUnmountResponse{}, unmarshalErr
 	}
-	if zr.DebugTraceFlags["api"] {
-		log.Debugf("volume-unmount result:\n%s", n.Result)
+	if zr.TraceEnabled("volume-unmount") {
+		log.Debugf("volume-unmount result:\n%s", RedactZapi(n.Result.String()))
 	}
 
 	return n, nil
@@ -0,0 +1,185 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+// ZapiClient is the set of ONTAP operations used by the ONTAP drivers.  It is implemented by
+// Client, and it lets the drivers be tested against a mock that embeds this interface and
+// overrides only the methods a test needs.
+type ZapiClient interface {
+	// WithContext returns a copy of the client whose ZAPI calls are bound to the supplied context.
+	WithContext(ctx context.Context) ZapiClient
+	Context() context.Context
+	SetDebugTraceFlags(flags map[string]bool)
+	SupportsFeature(feature Feature) bool
+
+	// IGROUP operations
+	IgroupCreate(initiatorGroupName, initiatorGroupType, osType string) (azgo.IgroupCreateResponse, error)
+	IgroupAdd(initiatorGroupName, initiator string) (azgo.IgroupAddResponse, error)
+	IgroupRemove(initiatorGroupName, initiator string, force bool) (azgo.IgroupRemoveResponse, error)
+	IgroupDestroy(initiatorGroupName string) (azgo.IgroupDestroyResponse, error)
+	IgroupList() (azgo.IgroupGetIterResponse, error)
+
+	// LUN operations
+	LunCreate(lunPath string, sizeInBytes int, osType string, spaceReserved bool) (
+		azgo.LunCreateBySizeResponse, error)
+	LunGetSerialNumber(lunPath string) (azgo.LunGetSerialNumberResponse, error)
+	LunMap(initiatorGroupName, lunPath string, lunID int) (azgo.LunMapResponse, error)
+	LunMapAutoID(initiatorGroupName, lunPath string) (azgo.LunMapResponse, error)
+	LunMapIfNotMapped(initiatorGroupName, lunPath string) (int, error)
+	LunMapListInfo(lunPath string) (azgo.LunMapListInfoResponse, error)
+	LunUnmap(initiatorGroupName, lunPath string) (azgo.LunUnmapResponse, error)
+	LunOffline(lunPath string) (azgo.LunOfflineResponse, error)
+	LunOnline(lunPath string) (azgo.LunOnlineResponse, error)
+	LunDestroy(lunPath string) (azgo.LunDestroyResponse, error)
+	LunSetAttribute(lunPath, name, value string) (azgo.LunSetAttributeResponse, error)
+	LunGetAttribute(lunPath, name string) (azgo.LunGetAttributeResponse, error)
+	LunGet(path string) (azgo.LunInfoType, error)
+	LunGetAll(pathPattern string) (azgo.LunGetIterResponse, error)
+
+	// VOLUME operations
+	VolumeCreate(name, aggregateName, size, spaceReserve, snapshotPolicy, unixPermissions,
+		exportPolicy, securityStyle, tieringPolicy string, encrypt *bool) (azgo.VolumeCreateResponse, error)
+	VolumeCreateDataProtection(name, aggregateName, size, exportPolicy string) (azgo.VolumeCreateResponse, error)
+	VolumeCloneCreate(name, source, snapshot string) (azgo.VolumeCloneCreateResponse, error)
+	VolumeCloneSplitStart(name string) (azgo.VolumeCloneSplitStartResponse, error)
+	VolumeDisableSnapshotDirectoryAccess(name string) (azgo.VolumeModifyIterResponse, error)
+	VolumeSetSnapshotDirectoryAccess(name string, enable bool) (azgo.VolumeModifyIterResponse, error)
+	VolumeExists(name string) (bool, error)
+	VolumeSize(name string) (azgo.VolumeSizeResponse, error)
+	SetVolumeSize(name, newSize string) (azgo.VolumeSizeResponse, error)
+	VolumeRename(name, newName string) (azgo.VolumeRenameResponse, error)
+	VolumeMount(name, junctionPath string) (azgo.VolumeMountResponse, error)
+	VolumeUnmount(name string, force bool) (azgo.VolumeUnmountResponse, error)
+	VolumeRehost(name, sourceSVM string) (azgo.VolumeRehostResponse, error)
+	VolumeOffline(name string) (azgo.VolumeOfflineResponse, error)
+	VolumeSetOption(name, option, value string) (azgo.VolumeSetOptionResponse, error)
+	VolumeDestroy(name string, force bool) (azgo.VolumeDestroyResponse, error)
+	VolumeGet(name string) (azgo.VolumeAttributesType, error)
+	VolumeGetAll(prefix string) (azgo.VolumeGetIterResponse, error)
+	VolumeList(prefix string) (azgo.VolumeGetIterResponse, error)
+	VolumeCount() (int, error)
+	VolumeCountByAggregate(aggregate string) (int, error)
+	VolumeListByAttrs(prefix, aggregate, spaceReserve, snapshotPolicy string, snapshotDir bool,
+		encrypt *bool) (azgo.VolumeGetIterResponse, error)
+	VolumeGetRootName() (azgo.VolumeGetRootNameResponse, error)
+	VolumeSetQosPolicyGroupName(name, qosPolicyGroup string) (azgo.VolumeModifyIterResponse, error)
+	VolumeCountByQosPolicyGroup(prefix, qosPolicyGroup string) (int, error)
+	VolumeSetCachingPolicy(name, cachingPolicy string) (azgo.VolumeModifyIterResponse, error)
+	VolumeSetExportPolicy(name, exportPolicy string) (azgo.VolumeModifyIterResponse, error)
+	VolumeSetSnapshotPolicy(name, snapshotPolicy string) (azgo.VolumeModifyIterResponse, error)
+	VolumeSetSecurityStyle(name, securityStyle string) (azgo.VolumeModifyIterResponse, error)
+	VolumeSetComment(name, comment string) (azgo.VolumeModifyIterResponse, error)
+
+	// QTREE operations
+	QtreeCreate(name, volumeName, unixPermissions, exportPolicy, securityStyle string) (
+		azgo.QtreeCreateResponse, error)
+	QtreeRename(path, newPath string) (azgo.QtreeRenameResponse, error)
+	QtreeDestroyAsync(path string, force bool) (azgo.QtreeDeleteAsyncResponse, error)
+	QtreeList(prefix, volumePrefix string) (azgo.QtreeListIterResponse, error)
+	QtreeCount(volume string) (int, error)
+	QtreeExists(name, volumePrefix string) (bool, string, error)
+	QtreeGet(name, volumePrefix string) (azgo.QtreeInfoType, error)
+	QtreeGetAll(volumePrefix string) (azgo.QtreeListIterResponse, error)
+
+	// QUOTA operations
+	QuotaOn(volume string) (azgo.QuotaOnResponse, error)
+	QuotaOff(volume string) (azgo.QuotaOffResponse, error)
+	QuotaResize(volume string) (azgo.QuotaResizeResponse, error)
+	QuotaStatus(volume string) (azgo.QuotaStatusResponse, error)
+	QuotaSetEntry(qtreeName, volumeName, quotaTarget, quotaType, diskLimit string) (
+		azgo.QuotaSetEntryResponse, error)
+	QuotaEntryGet(target string) (azgo.QuotaEntryType, error)
+	QuotaEntryList(volume string) (azgo.QuotaListEntriesIterResponse, error)
+
+	// EXPORT POLICY operations
+	ExportPolicyCreate(policy string) (azgo.ExportPolicyCreateResponse, error)
+	ExportRuleCreate(policy, clientMatch string, protocols, roSecFlavors, rwSecFlavors,
+		suSecFlavors []string) (azgo.ExportRuleCreateResponse, error)
+	ExportRuleGetIterRequest(policy string) (azgo.ExportRuleGetIterResponse, error)
+
+	// SNAPSHOT operations
+	SnapshotCreate(name, volumeName string) (azgo.SnapshotCreateResponse, error)
+	SnapshotDelete(name, volumeName string) (azgo.SnapshotDeleteResponse, error)
+	ConsistencyGroupSnapshot(name string, volumeNames []string) (azgo.CgCommitResponse, error)
+	SnapshotGetByVolume(volumeName string) (azgo.SnapshotGetIterResponse, error)
+	SnapshotList(namePattern, volumePattern string) (azgo.SnapshotGetIterResponse, error)
+
+	// SNAPSHOT POLICY operations
+	SnapshotPolicyCreate(name, schedule string, count int, prefix string) (azgo.SnapshotPolicyCreateResponse, error)
+	SnapshotPolicyGet(name string) (*azgo.SnapshotPolicyInfoType, error)
+	SnapshotPolicyAddSchedule(name, schedule string, count int, prefix string) (
+		azgo.SnapshotPolicyAddScheduleResponse, error)
+	SnapshotPolicyModifySchedule(name, schedule string, count int) (azgo.SnapshotPolicyModifyScheduleResponse, error)
+	SnapshotPolicyRemoveSchedule(name, schedule string) (azgo.SnapshotPolicyRemoveScheduleResponse, error)
+
+	// ISCSI operations
+	IscsiServiceGetIterRequest() (azgo.IscsiServiceGetIterResponse, error)
+	IscsiNodeGetNameRequest() (azgo.IscsiNodeGetNameResponse, error)
+	IscsiInterfaceGetIterRequest() (azgo.IscsiInterfaceGetIterResponse, error)
+
+	// VSERVER and AGGREGATE operations
+	VserverGetIterRequest() (azgo.VserverGetIterResponse, error)
+	GetVserverAggregateNames() ([]string, error)
+	VserverGetMaxVolumes() (int, error)
+	VserverGetUUID() (string, error)
+	VserverShowAggrGetIterRequest() (azgo.VserverShowAggrGetIterResponse, error)
+	VserverPeerGetIterRequest() (azgo.VserverPeerGetIterResponse, error)
+	ClusterPeerGetIterRequest() (azgo.ClusterPeerGetIterResponse, error)
+	AggrGetIterRequest() (azgo.AggrGetIterResponse, error)
+	AggrEncryptionStatus() (map[string]bool, error)
+	AggrSpaceStatus() (map[string]AggrSpace, error)
+	AggrHybridCacheSizes() (map[string]int, error)
+
+	// SNAPMIRROR operations
+	SnapmirrorGetLoadSharingMirrors(volume string) (azgo.SnapmirrorGetIterResponse, error)
+	SnapmirrorUpdateLoadSharingMirrors(sourceLocation string) (azgo.SnapmirrorUpdateLsSetResponse, error)
+	SnapmirrorCreate(sourceSVM, sourceVolume, destinationSVM, destinationVolume string) (
+		azgo.SnapmirrorCreateResponse, error)
+	SnapmirrorInitialize(destinationSVM, destinationVolume string) (azgo.SnapmirrorInitializeResponse, error)
+	SnapmirrorUpdate(destinationSVM, destinationVolume string) (azgo.SnapmirrorUpdateResponse, error)
+	SnapmirrorGet(destinationSVM, destinationVolume string) (azgo.SnapmirrorInfoType, error)
+	SnapmirrorQuiesce(destinationSVM, destinationVolume string) (azgo.SnapmirrorQuiesceResponse, error)
+	SnapmirrorBreak(destinationSVM, destinationVolume string) (azgo.SnapmirrorBreakResponse, error)
+	SnapmirrorDestroy(destinationSVM, destinationVolume string) (azgo.SnapmirrorDestroyResponse, error)
+	SnapmirrorRelease(sourceSVM, sourceVolume, destinationSVM, destinationVolume string) (
+		azgo.SnapmirrorReleaseResponse, error)
+
+	// FLEXCACHE operations
+	FlexcacheCreateAsync(name, aggregateName, originSVM, originVolume string, sizeBytes int) (
+		azgo.FlexcacheCreateAsyncResponse, error)
+	FlexcacheDestroyAsync(name string) (azgo.FlexcacheDestroyAsyncResponse, error)
+
+	// QOS operations
+	QosPolicyGroupCreate(name, minThroughput, maxThroughput string) (azgo.QosPolicyGroupCreateResponse, error)
+	QosPolicyGroupGet(name string) (*azgo.QosPolicyGroupInfoType, error)
+	QosPolicyGroupDelete(name string) (azgo.QosPolicyGroupDeleteResponse, error)
+
+	// PERF operations
+	VolumePerfCounters(name string, counters []string) (map[string]uint64, time.Time, error)
+
+	// MISC operations
+	NetInterfaceGet() (azgo.NetInterfaceGetIterResponse, error)
+	NetInterfaceGetDataLIFs(protocol string) ([]string, error)
+	SystemGetVersion() (azgo.SystemGetVersionResponse, error)
+	SystemGetOntapiVersion() (string, error)
+	ListNodeSerialNumbers() ([]string, error)
+	ListNodeModels() ([]string, error)
+	LicenseV2ListInfo() (azgo.LicenseV2ListInfoResponse, error)
+	ListLicensedPackages() ([]string, error)
+	SecurityKeyManagerKeyGetIterRequest() (azgo.SecurityKeyManagerKeyGetIterResponse, error)
+	KeyManagerConfigured() (bool, error)
+	ListUserCapabilities() (map[string]bool, error)
+	EmsAutosupportLog(appVersion string, autoSupport bool, category string, computerName string,
+		eventDescription string, eventID int, eventSource string, logLevel int) (
+		azgo.EmsAutosupportLogResponse, error)
+}
+
+// Client must satisfy the interface the drivers depend on
+var _ ZapiClient = &Client{}
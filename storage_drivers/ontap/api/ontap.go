@@ -3,11 +3,15 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -24,6 +28,15 @@ type ClientConfig struct {
 	Username        string
 	Password        string
 	DebugTraceFlags map[string]bool
+
+	// Transport, if set, replaces the default HTTPS transport, such as to replay a recording
+	Transport http.RoundTripper
+	// RecordFile, if set, names a file to which each ZAPI exchange is appended, minus credentials
+	RecordFile string
+	// Timeout, if set, limits how long each ZAPI call may take
+	Timeout time.Duration
+	// Breaker, if set, stops ZAPI calls after the cluster has repeatedly failed to respond
+	Breaker *utils.CircuitBreaker
 }
 
 // Client is the object to use for interacting with ONTAP controllers
@@ -35,6 +48,10 @@ type Client struct {
 
 // NewClient is a factory method for creating a new instance
 func NewClient(config ClientConfig) *Client {
+	transport := config.Transport
+	if config.RecordFile != "" {
+		transport = NewRecordingTransport(config.RecordFile, transport)
+	}
 	d := &Client{
 		config: config,
 		zr: &azgo.ZapiRunner{
@@ -44,12 +61,39 @@ func NewClient(config ClientConfig) *Client {
 			Password:        config.Password,
 			Secure:          true,
 			DebugTraceFlags: config.DebugTraceFlags,
+			Transport:       transport,
+			Timeout:         config.Timeout,
+			Breaker:         config.Breaker,
 		},
 		m: &sync.Mutex{},
 	}
 	return d
 }
 
+// WithContext returns a copy of this client whose ZAPI calls are bound to the supplied context, so
+// that they may be cancelled or time-bounded by the caller.
+func (d Client) WithContext(ctx context.Context) ZapiClient {
+	clone := d
+	clone.zr = d.GetClonedZapiRunner()
+	clone.zr.Context = ctx
+	return &clone
+}
+
+// Context returns the context to which this client's ZAPI calls are bound.
+func (d Client) Context() context.Context {
+	if d.zr.Context == nil {
+		return context.Background()
+	}
+	return d.zr.Context
+}
+
+// SetDebugTraceFlags replaces the debug trace flags used by this client.  Clients already
+// returned by WithContext keep the flags they were created with.
+func (d *Client) SetDebugTraceFlags(flags map[string]bool) {
+	d.config.DebugTraceFlags = flags
+	d.zr.DebugTraceFlags = flags
+}
+
 // GetClonedZapiRunner returns a clone of the ZapiRunner configured on this driver.
 func (d Client) GetClonedZapiRunner() *azgo.ZapiRunner {
 	clone := new(azgo.ZapiRunner)
@@ -162,26 +206,46 @@ func GetError(zapiResult interface{}, errorIn error) (errorOut error) {
 /////////////////////////////////////////////////////////////////////////////
 // API feature operations BEGIN
 
-type feature string
+// Feature is an ONTAP capability that depends on the ONTAPI version
+type Feature string
 
 // Define new version-specific feature constants here
 const (
-	MinimumONTAPIVersion   feature = "MINIMUM_ONTAPI_VERSION"
-	VServerShowAggr        feature = "VSERVER_SHOW_AGGR"
-	FlexGroups             feature = "FLEX_GROUPS"
-	NetAppVolumeEncryption feature = "NETAPP_VOLUME_ENCRYPTION"
+	MinimumONTAPIVersion   Feature = "MINIMUM_ONTAPI_VERSION"
+	VServerShowAggr        Feature = "VSERVER_SHOW_AGGR"
+	FlexGroups             Feature = "FLEX_GROUPS"
+	NetAppVolumeEncryption Feature = "NETAPP_VOLUME_ENCRYPTION"
+	QosMinThroughput       Feature = "QOS_MIN_THROUGHPUT"
+	FlexCache              Feature = "FLEXCACHE"
+	FabricPool             Feature = "FABRICPOOL"
+	AdaptiveQos            Feature = "ADAPTIVE_QOS"
+	RESTAPI                Feature = "REST_API"
 )
 
-// Indicate the minimum Ontapi version for each feature here
-var features = map[feature]*utils.Version{
+// The version matrix: indicate the minimum Ontapi version for each feature here
+var features = map[Feature]*utils.Version{
 	MinimumONTAPIVersion:   utils.MustParseSemantic("1.30.0"),  // cDOT 8.3.0
 	VServerShowAggr:        utils.MustParseSemantic("1.100.0"), // cDOT 9.0.0
 	FlexGroups:             utils.MustParseSemantic("1.100.0"), // cDOT 9.0.0
 	NetAppVolumeEncryption: utils.MustParseSemantic("1.110.0"), // cDOT 9.1.0
+	FabricPool:             utils.MustParseSemantic("1.120.0"), // cDOT 9.2.0
+	QosMinThroughput:       utils.MustParseSemantic("1.130.0"), // cDOT 9.3.0
+	AdaptiveQos:            utils.MustParseSemantic("1.130.0"), // cDOT 9.3.0
+	FlexCache:              utils.MustParseSemantic("1.150.0"), // cDOT 9.5.0
+	RESTAPI:                utils.MustParseSemantic("1.160.0"), // cDOT 9.6.0
+}
+
+// Features returns every feature in the version matrix, in no particular order
+func Features() []Feature {
+	list := make([]Feature, 0, len(features))
+	for feature := range features {
+		list = append(list, feature)
+	}
+	return list
 }
 
 // SupportsFeature returns true if the Ontapi version supports the supplied feature
-func (d Client) SupportsFeature(feature feature) bool {
+func (d Client) SupportsFeature(feature Feature) bool {
 
 	ontapiVersion, err := d.SystemGetOntapiVersion()
 	if err != nil {
@@ -358,6 +422,16 @@ func (d Client) LunMapListInfo(lunPath string) (response azgo.LunMapListInfoResp
 	return
 }
 
+// LunUnmap deletes the lun mapping for the given initiator group
+// equivalent to filer::> lun unmap -vserver iscsi_vs -path /vol/v/lun0 -igroup docker
+func (d Client) LunUnmap(initiatorGroupName, lunPath string) (response azgo.LunUnmapResponse, err error) {
+	response, err = azgo.NewLunUnmapRequest().
+		SetInitiatorGroup(initiatorGroupName).
+		SetPath(lunPath).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // LunOffline offlines a lun
 // equivalent to filer::> lun offline -vserver iscsi_vs -path /vol/v/lun0
 func (d Client) LunOffline(lunPath string) (response azgo.LunOfflineResponse, err error) {
@@ -415,7 +489,9 @@ func (d Client) LunGet(path string) (azgo.LunInfoType, error) {
 	desiredAttributes := azgo.NewLunInfoType().
 		SetPath("").
 		SetVolume("").
-		SetSize(0)
+		SetSize(0).
+		SetState("").
+		SetSerialNumber("")
 
 	response, err := azgo.NewLunGetIterRequest().
 		SetMaxRecords(defaultZapiRecords).
@@ -445,7 +521,9 @@ func (d Client) LunGetAll(pathPattern string) (response azgo.LunGetIterResponse,
 	desiredAttributes := azgo.NewLunInfoType().
 		SetPath("").
 		SetVolume("").
-		SetSize(0)
+		SetSize(0).
+		SetState("").
+		SetSerialNumber("")
 
 	response, err = azgo.NewLunGetIterRequest().
 		SetMaxRecords(defaultZapiRecords).
@@ -464,7 +542,7 @@ func (d Client) LunGetAll(pathPattern string) (response azgo.LunGetIterResponse,
 // VolumeCreate creates a volume with the specified options
 // equivalent to filer::> volume create -vserver iscsi_vs -volume v -aggregate aggr1 -size 1g -state online -type RW -policy default -unix-permissions ---rwxr-xr-x -space-guarantee none -snapshot-policy none -security-style unix -encrypt false
 func (d Client) VolumeCreate(name, aggregateName, size, spaceReserve, snapshotPolicy, unixPermissions,
-	exportPolicy, securityStyle string, encrypt *bool) (response azgo.VolumeCreateResponse, err error) {
+	exportPolicy, securityStyle, tieringPolicy string, encrypt *bool) (response azgo.VolumeCreateResponse, err error) {
 	request := azgo.NewVolumeCreateRequest().
 		SetVolume(name).
 		SetContainingAggrName(aggregateName).
@@ -480,6 +558,55 @@ func (d Client) VolumeCreate(name, aggregateName, size, spaceReserve, snapshotPo
 		request.SetEncrypt(*encrypt)
 	}
 
+	// Likewise for the tiering policy, which requires ONTAP 9.4 or later
+	if tieringPolicy != "" {
+		request.SetTieringPolicy(tieringPolicy)
+	}
+
+	// Record the operation that created the volume, so that it can be traced back to Trident, and
+	// the backend that owns it
+	if comment := VolumeComment(d.zr.Context); comment != "" {
+		request.SetVolumeComment(comment)
+	}
+
+	response, err = request.ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeComment returns the comment recording the Trident operation, and the owning backend, for
+// which a volume is created with the context, or an empty string if the context names neither.
+func VolumeComment(ctx context.Context) string {
+	audit := utils.GetAuditInfo(ctx)
+	owner := utils.GetOwner(ctx)
+	if audit == nil && owner == "" {
+		return ""
+	}
+	comment := "Created by Trident"
+	if audit != nil {
+		comment += ": " + audit.String()
+	}
+	if owner != "" {
+		comment += " " + utils.OwnerMarker(owner)
+	}
+	return comment
+}
+
+// VolumeCreateDataProtection creates a data protection Flexvol, which can be the destination of a SnapMirror
+// relationship but is otherwise read-only.
+// equivalent to filer::> volume create -type DP
+func (d Client) VolumeCreateDataProtection(
+	name, aggregateName, size, exportPolicy string,
+) (response azgo.VolumeCreateResponse, err error) {
+	request := azgo.NewVolumeCreateRequest().
+		SetVolume(name).
+		SetContainingAggrName(aggregateName).
+		SetSize(size).
+		SetVolumeType("dp")
+
+	if exportPolicy != "" {
+		request.SetExportPolicy(exportPolicy)
+	}
+
 	response, err = request.ExecuteUsing(d.zr)
 	return
 }
@@ -505,7 +632,14 @@ func (d Client) VolumeCloneSplitStart(name string) (response azgo.VolumeCloneSpl
 // VolumeDisableSnapshotDirectoryAccess disables access to the ".snapshot" directory
 // Disable '.snapshot' to allow official mysql container's chmod-in-init to work
 func (d Client) VolumeDisableSnapshotDirectoryAccess(name string) (response azgo.VolumeModifyIterResponse, err error) {
-	ssattr := azgo.NewVolumeSnapshotAttributesType().SetSnapdirAccessEnabled(false)
+	return d.VolumeSetSnapshotDirectoryAccess(name, false)
+}
+
+// VolumeSetSnapshotDirectoryAccess enables or disables access to the ".snapshot" directory
+func (d Client) VolumeSetSnapshotDirectoryAccess(
+	name string, enable bool,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	ssattr := azgo.NewVolumeSnapshotAttributesType().SetSnapdirAccessEnabled(enable)
 	volattr := azgo.NewVolumeAttributesType().SetVolumeSnapshotAttributes(*ssattr)
 	volidattr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
 	queryattr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volidattr)
@@ -517,6 +651,21 @@ func (d Client) VolumeDisableSnapshotDirectoryAccess(name string) (response azgo
 	return
 }
 
+// VolumeSetComment replaces the comment of a volume
+// equivalent to filer::> volume modify -comment
+func (d Client) VolumeSetComment(name, comment string) (response azgo.VolumeModifyIterResponse, err error) {
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(
+		*azgo.NewVolumeIdAttributesType().SetComment(comment))
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // VolumeExists tests for the existence of a Flexvol
 func (d Client) VolumeExists(name string) (bool, error) {
 	response, err := azgo.NewVolumeSizeRequest().
@@ -556,6 +705,15 @@ func (d Client) SetVolumeSize(name, newSize string) (response azgo.VolumeSizeRes
 	return
 }
 
+// VolumeRename renames a volume
+func (d Client) VolumeRename(name, newName string) (response azgo.VolumeRenameResponse, err error) {
+	response, err = azgo.NewVolumeRenameRequest().
+		SetVolume(name).
+		SetNewVolumeName(newName).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // VolumeMount mounts a volume at the specified junction
 func (d Client) VolumeMount(name, junctionPath string) (response azgo.VolumeMountResponse, err error) {
 	response, err = azgo.NewVolumeMountRequest().
@@ -574,6 +732,19 @@ func (d Client) VolumeUnmount(name string, force bool) (response azgo.VolumeUnmo
 	return
 }
 
+// VolumeRehost moves a volume from another SVM of the cluster to the configured one, keeping its
+// name and data.  The volume must be unmounted first, and is left unmounted.  Requires cluster scope.
+// equivalent to filer::> volume rehost -vserver <sourceSVM> -volume <name> -destination-vserver <svm>
+func (d Client) VolumeRehost(name, sourceSVM string) (response azgo.VolumeRehostResponse, err error) {
+	zr := d.GetNontunneledZapiRunner()
+	response, err = azgo.NewVolumeRehostRequest().
+		SetVserver(sourceSVM).
+		SetVolume(name).
+		SetDestinationVserver(d.config.SVM).
+		ExecuteUsing(zr)
+	return
+}
+
 // VolumeOffline offlines a volume
 func (d Client) VolumeOffline(name string) (response azgo.VolumeOfflineResponse, err error) {
 	response, err = azgo.NewVolumeOfflineRequest().
@@ -582,6 +753,17 @@ func (d Client) VolumeOffline(name string) (response azgo.VolumeOfflineResponse,
 	return
 }
 
+// VolumeSetOption sets an arbitrary option on a volume
+// equivalent to filer::> volume option set -volume <name> -option-name <option> -option-value <value>
+func (d Client) VolumeSetOption(name, option, value string) (response azgo.VolumeSetOptionResponse, err error) {
+	response, err = azgo.NewVolumeSetOptionRequest().
+		SetVolume(name).
+		SetOptionName(option).
+		SetOptionValue(value).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // VolumeDestroy destroys a volume
 func (d Client) VolumeDestroy(name string, force bool) (response azgo.VolumeDestroyResponse, err error) {
 	response, err = azgo.NewVolumeDestroyRequest().
@@ -634,23 +816,40 @@ func (d Client) VolumeGetAll(prefix string) (response azgo.VolumeGetIterResponse
 		SetPolicy("")
 	desiredVolIDAttrs := azgo.NewVolumeIdAttributesType().
 		SetName("").
-		SetContainingAggregateName("")
+		SetContainingAggregateName("").
+		SetJunctionPath("")
 	desiredVolSecurityUnixAttrs := azgo.NewVolumeSecurityUnixAttributesType().
 		SetPermissions("")
 	desiredVolSecurityAttrs := azgo.NewVolumeSecurityAttributesType().
+		SetStyle("").
 		SetVolumeSecurityUnixAttributes(*desiredVolSecurityUnixAttrs)
 	desiredVolSpaceAttrs := azgo.NewVolumeSpaceAttributesType().
-		SetSize(0)
+		SetSize(0).
+		SetSpaceGuarantee("")
 	desiredVolSnapshotAttrs := azgo.NewVolumeSnapshotAttributesType().
 		SetSnapdirAccessEnabled(true).
 		SetSnapshotPolicy("")
+	desiredVolStateAttrs := azgo.NewVolumeStateAttributesType().
+		SetState("")
+	desiredVolCloneParentAttrs := azgo.NewVolumeCloneParentAttributesType().
+		SetName("").
+		SetSnapshotName("")
+	desiredVolCloneAttrs := azgo.NewVolumeCloneAttributesType().
+		SetVolumeCloneParentAttributes(*desiredVolCloneParentAttrs)
 
 	desiredAttributes := azgo.NewVolumeAttributesType().
 		SetVolumeExportAttributes(*desiredVolExportAttrs).
 		SetVolumeIdAttributes(*desiredVolIDAttrs).
 		SetVolumeSecurityAttributes(*desiredVolSecurityAttrs).
 		SetVolumeSpaceAttributes(*desiredVolSpaceAttrs).
-		SetVolumeSnapshotAttributes(*desiredVolSnapshotAttrs)
+		SetVolumeSnapshotAttributes(*desiredVolSnapshotAttrs).
+		SetVolumeStateAttributes(*desiredVolStateAttrs).
+		SetVolumeCloneAttributes(*desiredVolCloneAttrs)
+
+	// Older versions of ONTAP don't know of encryption
+	if d.SupportsFeature(NetAppVolumeEncryption) {
+		desiredAttributes.SetEncrypt(true)
+	}
 
 	response, err = azgo.NewVolumeGetIterRequest().
 		SetMaxRecords(defaultZapiRecords).
@@ -682,6 +881,48 @@ func (d Client) VolumeList(prefix string) (response azgo.VolumeGetIterResponse,
 	return
 }
 
+// VolumeCount returns the number of volumes of any kind, including the root volume, on the vserver.
+// The request follows each page's next-tag, so volumes beyond the first page are counted too.
+func (d Client) VolumeCount() (int, error) {
+
+	// Limit the returned data to only the volume names
+	desiredVolIDAttrs := azgo.NewVolumeIdAttributesType().SetName("")
+	desiredAttributes := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*desiredVolIDAttrs)
+
+	response, err := azgo.NewVolumeGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return 0, err
+	}
+	return len(response.Result.AttributesList()), nil
+}
+
+// VolumeCountByAggregate returns the number of volumes of the SVM in the specified aggregate.  As
+// with VolumeCount, every page of the listing is counted.
+func (d Client) VolumeCountByAggregate(aggregate string) (int, error) {
+
+	// Limit the returned data to only the volume names
+	queryVolIDAttrs := azgo.NewVolumeIdAttributesType().SetContainingAggregateName(aggregate)
+	query := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*queryVolIDAttrs)
+
+	desiredVolIDAttrs := azgo.NewVolumeIdAttributesType().SetName("")
+	desiredAttributes := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*desiredVolIDAttrs)
+
+	response, err := azgo.NewVolumeGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return 0, err
+	}
+	return len(response.Result.AttributesList()), nil
+}
+
 // VolumeListByAttrs returns the names of all Flexvols matching the specified attributes
 func (d Client) VolumeListByAttrs(
 	prefix, aggregate, spaceReserve, snapshotPolicy string, snapshotDir bool, encrypt *bool,
@@ -720,6 +961,117 @@ func (d Client) VolumeListByAttrs(
 	return
 }
 
+// VolumeSetQosPolicyGroupName assigns a volume to a QoS policy group
+// equivalent to filer::> volume modify -qos-policy-group
+func (d Client) VolumeSetQosPolicyGroupName(
+	name, qosPolicyGroup string,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	qosAttr := azgo.NewVolumeQosAttributesType().SetPolicyGroupName(qosPolicyGroup)
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeQosAttributes(*qosAttr)
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeSetCachingPolicy sets the policy by which a volume's data is cached in Flash Pool SSDs
+// equivalent to filer::> volume modify -caching-policy
+func (d Client) VolumeSetCachingPolicy(
+	name, cachingPolicy string,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	cacheAttr := azgo.NewVolumeHybridCacheAttributesType().SetCachingPolicy(cachingPolicy)
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeHybridCacheAttributes(*cacheAttr)
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeSetExportPolicy sets the export policy that controls NFS access to a volume
+// equivalent to filer::> volume modify -policy
+func (d Client) VolumeSetExportPolicy(
+	name, exportPolicy string,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	exportAttr := azgo.NewVolumeExportAttributesType().SetPolicy(exportPolicy)
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeExportAttributes(*exportAttr)
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeSetSnapshotPolicy sets the snapshot policy of a volume
+// equivalent to filer::> volume modify -snapshot-policy
+func (d Client) VolumeSetSnapshotPolicy(
+	name, snapshotPolicy string,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	snapshotAttr := azgo.NewVolumeSnapshotAttributesType().SetSnapshotPolicy(snapshotPolicy)
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeSnapshotAttributes(*snapshotAttr)
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeSetSecurityStyle sets the security style of a volume
+// equivalent to filer::> volume modify -security-style
+func (d Client) VolumeSetSecurityStyle(
+	name, securityStyle string,
+) (response azgo.VolumeModifyIterResponse, err error) {
+	securityAttr := azgo.NewVolumeSecurityAttributesType().SetStyle(securityStyle)
+	volAttr := azgo.NewVolumeAttributesType().SetVolumeSecurityAttributes(*securityAttr)
+	volIDAttr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	queryAttr := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volIDAttr)
+
+	response, err = azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryAttr).
+		SetAttributes(*volAttr).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// VolumeCountByQosPolicyGroup returns the number of Flexvols whose names match the supplied prefix
+// and that are assigned to the specified QoS policy group
+func (d Client) VolumeCountByQosPolicyGroup(prefix, qosPolicyGroup string) (int, error) {
+
+	queryVolIDAttrs := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(prefix + "*"))
+	queryVolQosAttrs := azgo.NewVolumeQosAttributesType().SetPolicyGroupName(qosPolicyGroup)
+	query := azgo.NewVolumeAttributesType().
+		SetVolumeIdAttributes(*queryVolIDAttrs).
+		SetVolumeQosAttributes(*queryVolQosAttrs)
+
+	// Limit the returned data to only the volume names
+	desiredVolIDAttrs := azgo.NewVolumeIdAttributesType().SetName("")
+	desiredAttributes := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*desiredVolIDAttrs)
+
+	response, err := azgo.NewVolumeGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return 0, err
+	}
+	return len(response.Result.AttributesList()), nil
+}
+
 // VolumeGetRootName gets the name of the root volume of a vserver
 func (d Client) VolumeGetRootName() (response azgo.VolumeGetRootNameResponse, err error) {
 	response, err = azgo.NewVolumeGetRootNameRequest().
@@ -1071,6 +1423,40 @@ func (d Client) SnapshotCreate(name, volumeName string) (response azgo.SnapshotC
 	return
 }
 
+// SnapshotDelete deletes a snapshot of a volume
+func (d Client) SnapshotDelete(name, volumeName string) (response azgo.SnapshotDeleteResponse, err error) {
+	response, err = azgo.NewSnapshotDeleteRequest().
+		SetSnapshot(name).
+		SetVolume(volumeName).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// ConsistencyGroupSnapshot creates snapshots of several volumes at the same point in time.  Writes
+// to the volumes are fenced by cg-start until cg-commit completes the snapshots.
+func (d Client) ConsistencyGroupSnapshot(name string, volumeNames []string) (
+	response azgo.CgCommitResponse, err error,
+) {
+	volumes := make([]azgo.VolumeNameType, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		volumes = append(volumes, azgo.VolumeNameType(volumeName))
+	}
+
+	startResponse, err := azgo.NewCgStartRequest().
+		SetSnapshot(name).
+		SetTimeout("relaxed").
+		SetVolumes(volumes).
+		ExecuteUsing(d.zr)
+	if err = GetError(startResponse, err); err != nil {
+		return response, fmt.Errorf("could not start consistency group snapshot: %v", err)
+	}
+
+	response, err = azgo.NewCgCommitRequest().
+		SetCgId(startResponse.Result.CgId()).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // SnapshotGetByVolume returns the list of snapshots associated with a volume
 func (d Client) SnapshotGetByVolume(volumeName string) (response azgo.SnapshotGetIterResponse, err error) {
 	query := azgo.NewSnapshotInfoType().SetVolume(volumeName)
@@ -1082,9 +1468,115 @@ func (d Client) SnapshotGetByVolume(volumeName string) (response azgo.SnapshotGe
 	return
 }
 
+// SnapshotList returns the snapshots whose names match the supplied pattern on volumes whose names
+// match the supplied pattern
+func (d Client) SnapshotList(namePattern, volumePattern string) (response azgo.SnapshotGetIterResponse, err error) {
+	query := azgo.NewSnapshotInfoType().SetName(namePattern).SetVolume(volumePattern)
+
+	response, err = azgo.NewSnapshotGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // SNAPSHOT operations END
 /////////////////////////////////////////////////////////////////////////////
 
+/////////////////////////////////////////////////////////////////////////////
+// SNAPSHOT POLICY operations BEGIN
+
+// SnapshotPolicyCreate creates an enabled snapshot policy on the SVM with a single schedule, which
+// keeps the specified number of snapshots, named with the prefix if one is given.  ONTAP requires a
+// policy to have at least one schedule; others may be added with SnapshotPolicyAddSchedule.
+// equivalent to filer::> volume snapshot policy create -policy gold -enabled true -schedule1 hourly -count1 6
+func (d Client) SnapshotPolicyCreate(
+	name, schedule string, count int, prefix string,
+) (response azgo.SnapshotPolicyCreateResponse, err error) {
+	request := azgo.NewSnapshotPolicyCreateRequest().
+		SetPolicy(name).
+		SetEnabled(true).
+		SetSchedule1(schedule).
+		SetCount1(count).
+		SetComment("Created by Trident")
+	if prefix != "" {
+		request.SetPrefix1(prefix)
+	}
+
+	response, err = request.ExecuteUsing(d.zr)
+	return
+}
+
+// SnapshotPolicyGet returns the snapshot policy of the SVM with the specified name, or nil if
+// there isn't one
+// equivalent to filer::> volume snapshot policy show -vserver svm -policy gold
+func (d Client) SnapshotPolicyGet(name string) (*azgo.SnapshotPolicyInfoType, error) {
+
+	query := azgo.NewSnapshotPolicyInfoType().
+		SetPolicy(name).
+		SetVserverName(d.config.SVM)
+
+	response, err := azgo.NewSnapshotPolicyGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return nil, err
+	}
+	for _, policy := range response.Result.AttributesList() {
+		if policy.PolicyPtr != nil && policy.Policy() == name {
+			return &policy, nil
+		}
+	}
+	return nil, nil
+}
+
+// SnapshotPolicyAddSchedule adds a schedule to a snapshot policy
+// equivalent to filer::> volume snapshot policy add-schedule -policy gold -schedule daily -count 2
+func (d Client) SnapshotPolicyAddSchedule(
+	name, schedule string, count int, prefix string,
+) (response azgo.SnapshotPolicyAddScheduleResponse, err error) {
+	request := azgo.NewSnapshotPolicyAddScheduleRequest().
+		SetPolicy(name).
+		SetSchedule(schedule).
+		SetCount(count)
+	if prefix != "" {
+		request.SetPrefix(prefix)
+	}
+
+	response, err = request.ExecuteUsing(d.zr)
+	return
+}
+
+// SnapshotPolicyModifySchedule changes the number of snapshots a snapshot policy's schedule keeps
+// equivalent to filer::> volume snapshot policy modify-schedule -policy gold -schedule daily -newcount 4
+func (d Client) SnapshotPolicyModifySchedule(
+	name, schedule string, count int,
+) (response azgo.SnapshotPolicyModifyScheduleResponse, err error) {
+	response, err = azgo.NewSnapshotPolicyModifyScheduleRequest().
+		SetPolicy(name).
+		SetSchedule(schedule).
+		SetNewCount(count).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapshotPolicyRemoveSchedule removes a schedule from a snapshot policy
+// equivalent to filer::> volume snapshot policy remove-schedule -policy gold -schedule weekly
+func (d Client) SnapshotPolicyRemoveSchedule(
+	name, schedule string,
+) (response azgo.SnapshotPolicyRemoveScheduleResponse, err error) {
+	response, err = azgo.NewSnapshotPolicyRemoveScheduleRequest().
+		SetPolicy(name).
+		SetSchedule(schedule).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SNAPSHOT POLICY operations END
+/////////////////////////////////////////////////////////////////////////////
+
 /////////////////////////////////////////////////////////////////////////////
 // ISCSI operations BEGIN
 
@@ -1158,6 +1650,72 @@ func (d Client) GetVserverAggregateNames() ([]string, error) {
 	return aggrNames, nil
 }
 
+// VserverGetMaxVolumes returns the maximum number of volumes the configured vserver may contain,
+// or zero if the vserver has no limit.
+// equivalent to filer::> vserver show -vserver <svm> -fields max-volumes
+func (d Client) VserverGetMaxVolumes() (int, error) {
+
+	query := azgo.NewVserverInfoType()
+	query.SetVserverName(d.config.SVM)
+
+	desiredAttributes := azgo.NewVserverInfoType()
+	desiredAttributes.SetMaxVolumes("")
+
+	response, err := azgo.NewVserverGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return 0, err
+	}
+	if response.Result.NumRecords() != 1 {
+		return 0, fmt.Errorf("could not find SVM %s", d.config.SVM)
+	}
+
+	vserver := response.Result.AttributesList()[0]
+	if vserver.MaxVolumesPtr == nil || vserver.MaxVolumes() == "unlimited" {
+		return 0, nil
+	}
+
+	maxVolumes, err := strconv.Atoi(vserver.MaxVolumes())
+	if err != nil {
+		return 0, fmt.Errorf("could not parse max-volumes value %s: %v", vserver.MaxVolumes(), err)
+	}
+	return maxVolumes, nil
+}
+
+// VserverGetUUID returns the UUID of the configured vserver.
+// equivalent to filer::> vserver show -vserver <svm> -fields uuid
+func (d Client) VserverGetUUID() (string, error) {
+
+	query := azgo.NewVserverInfoType()
+	query.SetVserverName(d.config.SVM)
+
+	desiredAttributes := azgo.NewVserverInfoType()
+	desiredAttributes.SetUuid("")
+
+	response, err := azgo.NewVserverGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return "", err
+	}
+	if response.Result.NumRecords() != 1 {
+		return "", fmt.Errorf("could not find SVM %s", d.config.SVM)
+	}
+
+	vserver := response.Result.AttributesList()[0]
+	if vserver.UuidPtr == nil || vserver.Uuid() == "" {
+		return "", fmt.Errorf("could not read the UUID of SVM %s", d.config.SVM)
+	}
+	return string(vserver.Uuid()), nil
+}
+
 // VserverShowAggrGetIterRequest returns the aggregates on the vserver.  Requires ONTAP 9 or later.
 // equivalent to filer::> vserver show-aggregates
 func (d Client) VserverShowAggrGetIterRequest() (response azgo.VserverShowAggrGetIterResponse, err error) {
@@ -1168,6 +1726,30 @@ func (d Client) VserverShowAggrGetIterRequest() (response azgo.VserverShowAggrGe
 	return
 }
 
+// VserverPeerGetIterRequest returns the SVMs peered with the configured vserver
+// equivalent to filer::> vserver peer show -vserver <svm>
+func (d Client) VserverPeerGetIterRequest() (response azgo.VserverPeerGetIterResponse, err error) {
+
+	query := azgo.NewVserverPeerInfoType()
+	query.SetVserver(d.config.SVM)
+
+	response, err = azgo.NewVserverPeerGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// ClusterPeerGetIterRequest returns the clusters peered with this one, along with which of their
+// intercluster LIFs are reachable.  Requires cluster scope.
+// equivalent to filer::> cluster peer show
+func (d Client) ClusterPeerGetIterRequest() (response azgo.ClusterPeerGetIterResponse, err error) {
+	response, err = azgo.NewClusterPeerGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // VSERVER operations END
 /////////////////////////////////////////////////////////////////////////////
 
@@ -1189,6 +1771,97 @@ func (d Client) AggrGetIterRequest() (response azgo.AggrGetIterResponse, err err
 	return
 }
 
+// AggrEncryptionStatus returns a map of aggregate names to whether each aggregate uses NetApp
+// Aggregate Encryption (NAE).  Like AggrGetIterRequest, this requires cluster scope.
+func (d Client) AggrEncryptionStatus() (map[string]bool, error) {
+
+	response, err := d.AggrGetIterRequest()
+	if err = GetError(response, err); err != nil {
+		return nil, err
+	}
+
+	status := make(map[string]bool)
+	for _, aggr := range response.Result.AttributesList() {
+		if aggr.AggregateNamePtr == nil {
+			continue
+		}
+		encrypted := false
+		if raidAttrs := aggr.AggrRaidAttributesPtr; raidAttrs != nil && raidAttrs.EncryptWithAggrKeyPtr != nil {
+			encrypted = raidAttrs.EncryptWithAggrKey()
+		}
+		status[aggr.AggregateName()] = encrypted
+	}
+	return status, nil
+}
+
+// AggrHybridCacheSizes returns a map of the names of Flash Pool (hybrid) aggregates to the size of
+// each one's SSD cache, in bytes.  Like AggrGetIterRequest, this requires cluster scope.
+func (d Client) AggrHybridCacheSizes() (map[string]int, error) {
+
+	response, err := d.AggrGetIterRequest()
+	if err = GetError(response, err); err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int)
+	for _, aggr := range response.Result.AttributesList() {
+		raidAttrs := aggr.AggrRaidAttributesPtr
+		if aggr.AggregateNamePtr == nil || raidAttrs == nil || raidAttrs.IsHybridPtr == nil ||
+			!raidAttrs.IsHybrid() || raidAttrs.HybridCacheSizeTotalPtr == nil {
+			continue
+		}
+		sizes[aggr.AggregateName()] = raidAttrs.HybridCacheSizeTotal() * 1024 * 1024
+	}
+	return sizes, nil
+}
+
+// AggrSpace holds the size of an aggregate and the space used and available in it, in bytes.  When
+// only the space available to the SVM is known, the total and used sizes are zero.
+type AggrSpace struct {
+	Total     int
+	Used      int
+	Available int
+}
+
+// AggrSpaceStatus returns a map of aggregate names to the space in each aggregate.  Without cluster
+// scope, only the space available to the SVM can be read, which requires ONTAP 9 or later.
+func (d Client) AggrSpaceStatus() (map[string]AggrSpace, error) {
+
+	status := make(map[string]AggrSpace)
+
+	response, err := d.AggrGetIterRequest()
+	if err = GetError(response, err); err == nil {
+		for _, aggr := range response.Result.AttributesList() {
+			spaceAttrs := aggr.AggrSpaceAttributesPtr
+			if aggr.AggregateNamePtr == nil || spaceAttrs == nil || spaceAttrs.SizeTotalPtr == nil ||
+				spaceAttrs.SizeUsedPtr == nil || spaceAttrs.SizeAvailablePtr == nil {
+				continue
+			}
+			status[aggr.AggregateName()] = AggrSpace{
+				Total:     spaceAttrs.SizeTotal(),
+				Used:      spaceAttrs.SizeUsed(),
+				Available: spaceAttrs.SizeAvailable(),
+			}
+		}
+		return status, nil
+	}
+
+	log.Debugf("Could not read aggregate space with cluster scope, trying SVM scope. %v", err)
+
+	vserverResponse, err := d.VserverShowAggrGetIterRequest()
+	if err = GetError(vserverResponse, err); err != nil {
+		return nil, err
+	}
+
+	for _, aggr := range vserverResponse.Result.AttributesList() {
+		if aggr.AggregateNamePtr == nil || aggr.AvailableSizePtr == nil {
+			continue
+		}
+		status[string(aggr.AggregateName())] = AggrSpace{Available: int(aggr.AvailableSize())}
+	}
+	return status, nil
+}
+
 // AGGREGATE operations END
 /////////////////////////////////////////////////////////////////////////////
 
@@ -1224,9 +1897,271 @@ func (d Client) SnapmirrorUpdateLoadSharingMirrors(
 	return
 }
 
+// SnapmirrorCreate creates a data protection SnapMirror relationship to a Flexvol in this client's SVM
+// equivalent to filer::> snapmirror create -type DP
+func (d Client) SnapmirrorCreate(
+	sourceSVM, sourceVolume, destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorCreateResponse, err error) {
+	response, err = azgo.NewSnapmirrorCreateRequest().
+		SetSourceVserver(sourceSVM).
+		SetSourceVolume(sourceVolume).
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		SetRelationshipType("data_protection").
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorInitialize starts the baseline transfer of a SnapMirror relationship
+// equivalent to filer::> snapmirror initialize
+func (d Client) SnapmirrorInitialize(
+	destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorInitializeResponse, err error) {
+	response, err = azgo.NewSnapmirrorInitializeRequest().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorUpdate starts an incremental transfer of a SnapMirror relationship
+// equivalent to filer::> snapmirror update
+func (d Client) SnapmirrorUpdate(
+	destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorUpdateResponse, err error) {
+	response, err = azgo.NewSnapmirrorUpdateRequest().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorGet returns the state of the SnapMirror relationship to a Flexvol
+// equivalent to filer::> snapmirror show -destination-volume
+func (d Client) SnapmirrorGet(destinationSVM, destinationVolume string) (azgo.SnapmirrorInfoType, error) {
+
+	query := azgo.NewSnapmirrorInfoType().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume)
+
+	desiredAttributes := azgo.NewSnapmirrorInfoType().
+		SetMirrorState("").
+		SetRelationshipStatus("").
+		SetLastTransferError("").
+		SetLastTransferEndTimestamp(0)
+
+	response, err := azgo.NewSnapmirrorGetIterRequest().
+		SetQuery(*query).
+		SetDesiredAttributes(*desiredAttributes).
+		ExecuteUsing(d.zr)
+
+	if err != nil {
+		return azgo.SnapmirrorInfoType{}, err
+	} else if response.Result.NumRecords() == 0 {
+		return azgo.SnapmirrorInfoType{}, fmt.Errorf("snapmirror relationship to %s:%s not found",
+			destinationSVM, destinationVolume)
+	}
+
+	return response.Result.AttributesList()[0], nil
+}
+
+// SnapmirrorQuiesce stops further transfers of a SnapMirror relationship
+// equivalent to filer::> snapmirror quiesce
+func (d Client) SnapmirrorQuiesce(
+	destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorQuiesceResponse, err error) {
+	response, err = azgo.NewSnapmirrorQuiesceRequest().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorBreak makes the destination of a quiesced SnapMirror relationship writable
+// equivalent to filer::> snapmirror break
+func (d Client) SnapmirrorBreak(
+	destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorBreakResponse, err error) {
+	response, err = azgo.NewSnapmirrorBreakRequest().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorDestroy deletes a SnapMirror relationship from its destination
+// equivalent to filer::> snapmirror delete
+func (d Client) SnapmirrorDestroy(
+	destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorDestroyResponse, err error) {
+	response, err = azgo.NewSnapmirrorDestroyRequest().
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// SnapmirrorRelease removes a SnapMirror relationship from its source, deleting the snapshots it retained
+// equivalent to filer::> snapmirror release
+func (d Client) SnapmirrorRelease(
+	sourceSVM, sourceVolume, destinationSVM, destinationVolume string,
+) (response azgo.SnapmirrorReleaseResponse, err error) {
+	response, err = azgo.NewSnapmirrorReleaseRequest().
+		SetSourceVserver(sourceSVM).
+		SetSourceVolume(sourceVolume).
+		SetDestinationVserver(destinationSVM).
+		SetDestinationVolume(destinationVolume).
+		ExecuteUsing(d.zr)
+	return
+}
+
 // SNAPMIRROR operations END
 /////////////////////////////////////////////////////////////////////////////
 
+/////////////////////////////////////////////////////////////////////////////
+// FLEXCACHE operations BEGIN
+
+// FlexcacheCreateAsync creates a FlexCache volume of an origin volume on this or a peered SVM,
+// mounted at a junction that matches its name.  The cache is created in the background.
+// equivalent to filer::> volume flexcache create -foreground false
+func (d Client) FlexcacheCreateAsync(
+	name, aggregateName, originSVM, originVolume string, sizeBytes int,
+) (response azgo.FlexcacheCreateAsyncResponse, err error) {
+	response, err = azgo.NewFlexcacheCreateAsyncRequest().
+		SetVolume(name).
+		SetAggrList([]azgo.AggrNameType{azgo.AggrNameType(aggregateName)}).
+		SetOriginVserver(originSVM).
+		SetOriginVolume(originVolume).
+		SetSize(sizeBytes).
+		SetJunctionPath("/" + name).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// FlexcacheDestroyAsync disconnects an offline FlexCache volume from its origin and destroys it in
+// the background.
+// equivalent to filer::> volume flexcache delete -foreground false
+func (d Client) FlexcacheDestroyAsync(name string) (response azgo.FlexcacheDestroyAsyncResponse, err error) {
+	response, err = azgo.NewFlexcacheDestroyAsyncRequest().
+		SetVolume(name).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// FLEXCACHE operations END
+/////////////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////////////
+// QOS operations BEGIN
+
+// QosPolicyGroupCreate creates a QoS policy group on the SVM with the specified throughput floor
+// and ceiling, such as "1000iops" or "100MB/s".  Either may be empty, and a floor requires ONTAP
+// 9.3 or later.
+// equivalent to filer::> qos policy-group create -policy-group gold -vserver svm -max-throughput 1000iops
+func (d Client) QosPolicyGroupCreate(
+	name, minThroughput, maxThroughput string,
+) (response azgo.QosPolicyGroupCreateResponse, err error) {
+	request := azgo.NewQosPolicyGroupCreateRequest().
+		SetPolicyGroup(name).
+		SetVserver(d.config.SVM)
+
+	// Don't send limits that weren't given, as older ONTAP won't accept a floor
+	if minThroughput != "" {
+		request.SetMinThroughput(minThroughput)
+	}
+	if maxThroughput != "" {
+		request.SetMaxThroughput(maxThroughput)
+	}
+
+	response, err = request.ExecuteUsing(d.zr)
+	return
+}
+
+// QosPolicyGroupGet returns the QoS policy group of the SVM with the specified name, or nil if
+// there isn't one
+// equivalent to filer::> qos policy-group show -policy-group gold -vserver svm
+func (d Client) QosPolicyGroupGet(name string) (*azgo.QosPolicyGroupInfoType, error) {
+
+	query := azgo.NewQosPolicyGroupInfoType().
+		SetPolicyGroup(name).
+		SetVserver(d.config.SVM)
+
+	response, err := azgo.NewQosPolicyGroupGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		SetQuery(*query).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return nil, err
+	}
+	for _, policyGroup := range response.Result.AttributesList() {
+		if policyGroup.PolicyGroup() == name {
+			return &policyGroup, nil
+		}
+	}
+	return nil, nil
+}
+
+// QosPolicyGroupDelete deletes a QoS policy group.  ONTAP won't delete a policy group that still
+// has workloads, such as volumes, assigned to it.
+// equivalent to filer::> qos policy-group delete -policy-group gold
+func (d Client) QosPolicyGroupDelete(name string) (response azgo.QosPolicyGroupDeleteResponse, err error) {
+	response, err = azgo.NewQosPolicyGroupDeleteRequest().
+		SetPolicyGroup(name).
+		ExecuteUsing(d.zr)
+	return
+}
+
+// QOS operations END
+/////////////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////////////
+// PERF operations BEGIN
+
+// VolumePerfCounters returns the named performance counters of a volume, along with the time at
+// which ONTAP read them.  Counters that ONTAP doesn't report, or that aren't numeric, are omitted.
+// equivalent to filer::> statistics volume show
+func (d Client) VolumePerfCounters(name string, counters []string) (map[string]uint64, time.Time, error) {
+
+	response, err := azgo.NewPerfObjectGetInstancesRequest().
+		SetObjectname("volume").
+		SetInstances([]string{name}).
+		SetCounters(counters).
+		ExecuteUsing(d.zr)
+
+	if err = GetError(response, err); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	timestamp := time.Now()
+	if response.Result.TimestampPtr != nil {
+		if seconds, err := strconv.ParseInt(response.Result.Timestamp(), 10, 64); err == nil {
+			timestamp = time.Unix(seconds, 0)
+		}
+	}
+
+	for _, instance := range response.Result.Instances() {
+		if instance.NamePtr == nil || instance.Name() != name {
+			continue
+		}
+		values := make(map[string]uint64)
+		for _, counter := range instance.Counters() {
+			if counter.NamePtr == nil || counter.ValuePtr == nil {
+				continue
+			}
+			if value, err := strconv.ParseUint(counter.Value(), 10, 64); err == nil {
+				values[counter.Name()] = value
+			}
+		}
+		return values, timestamp, nil
+	}
+
+	return nil, time.Time{}, fmt.Errorf("no performance data found for volume %s", name)
+}
+
+// PERF operations END
+/////////////////////////////////////////////////////////////////////////////
+
 /////////////////////////////////////////////////////////////////////////////
 // MISC operations BEGIN
 
@@ -1323,6 +2258,134 @@ func (d Client) ListNodeSerialNumbers() ([]string, error) {
 	return serialNumbers, nil
 }
 
+// ListNodeModels returns the model of each of the cluster's nodes, such as FAS8200 or FDvM300.
+// This info is not available to SVM-scoped users.
+func (d Client) ListNodeModels() ([]string, error) {
+
+	models := make([]string, 0)
+	zr := d.GetNontunneledZapiRunner()
+
+	// Limit the returned data to only the models
+	desiredAttributes := azgo.NewNodeDetailsInfoType().SetNodeModel("")
+
+	response, err := azgo.NewSystemNodeGetIterRequest().
+		SetDesiredAttributes(*desiredAttributes).
+		SetMaxRecords(defaultZapiRecords).
+		ExecuteUsing(zr)
+
+	if err = GetError(response, err); err != nil {
+		return models, err
+	}
+
+	for _, node := range response.Result.AttributesList() {
+		if node.NodeModelPtr != nil && node.NodeModel() != "" {
+			models = append(models, node.NodeModel())
+		}
+	}
+
+	if len(models) == 0 {
+		return models, errors.New("could not get node models")
+	}
+	return models, nil
+}
+
+// LicenseV2ListInfo returns the licenses installed on the cluster
+// equivalent to filer::> system license show
+func (d Client) LicenseV2ListInfo() (response azgo.LicenseV2ListInfoResponse, err error) {
+
+	// Licenses are cluster-scoped, so this API will only work for cluster-scoped users.
+	zr := d.GetNontunneledZapiRunner()
+
+	response, err = azgo.NewLicenseV2ListInfoRequest().ExecuteUsing(zr)
+	return
+}
+
+// ListLicensedPackages returns the names of the license packages installed on the cluster.
+// Node-locked licenses appear once per node, so the returned list is deduplicated.
+func (d Client) ListLicensedPackages() ([]string, error) {
+
+	packages := make([]string, 0)
+
+	response, err := d.LicenseV2ListInfo()
+	if err = GetError(response, err); err != nil {
+		return packages, err
+	}
+
+	seen := make(map[string]bool)
+	for _, license := range response.Result.Licenses() {
+		if license.PackagePtr == nil {
+			continue
+		}
+		pkg := strings.ToLower(license.Package())
+		if !seen[pkg] {
+			seen[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+
+	log.WithField("packages", strings.Join(packages, ",")).Debug("Read licensed packages.")
+
+	return packages, nil
+}
+
+// SecurityKeyManagerKeyGetIterRequest returns the encryption keys held by the onboard or external key
+// managers.  This requires cluster scope.
+// equivalent to filer::> security key-manager key show
+func (d Client) SecurityKeyManagerKeyGetIterRequest() (response azgo.SecurityKeyManagerKeyGetIterResponse, err error) {
+
+	zr := d.GetNontunneledZapiRunner()
+
+	response, err = azgo.NewSecurityKeyManagerKeyGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		ExecuteUsing(zr)
+	return
+}
+
+// KeyManagerConfigured returns true if an onboard or external key manager holds at least one key,
+// which ONTAP requires before it will create encrypted volumes.
+func (d Client) KeyManagerConfigured() (bool, error) {
+
+	response, err := d.SecurityKeyManagerKeyGetIterRequest()
+	if err = GetError(response, err); err != nil {
+		return false, err
+	}
+	return len(response.Result.AttributesList()) > 0, nil
+}
+
+// ListUserCapabilities returns the names of the ZAPIs the configured user's role allows.  An
+// operation the role denies, with a permission of "none", is left out.
+func (d Client) ListUserCapabilities() (map[string]bool, error) {
+
+	response, err := azgo.NewSystemUserCapabilityGetIterRequest().
+		SetMaxRecords(defaultZapiRecords).
+		ExecuteUsing(d.zr)
+	if err = GetError(response, err); err != nil {
+		return nil, err
+	}
+	if len(response.Result.AttributesList()) == 0 {
+		return nil, errors.New("no user capabilities were returned")
+	}
+
+	apis := make(map[string]bool)
+	for _, capability := range response.Result.AttributesList() {
+		for _, operation := range capability.OperationList() {
+			if operation.ApiNamePtr == nil {
+				continue
+			}
+			if operation.PermissionPtr != nil && strings.EqualFold(operation.Permission(), "none") {
+				continue
+			}
+			// An operation may map to several APIs
+			for _, name := range strings.Split(operation.ApiName(), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					apis[name] = true
+				}
+			}
+		}
+	}
+	return apis, nil
+}
+
 // EmsAutosupportLog generates an auto support message with the supplied parameters
 func (d Client) EmsAutosupportLog(
 	appVersion string,
@@ -0,0 +1,123 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/netapp/trident/utils"
+)
+
+func TestClientCircuitBreaker(t *testing.T) {
+
+	var calls int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	breaker := utils.NewCircuitBreaker("cluster", 2, time.Hour)
+	client := NewClient(ClientConfig{
+		ManagementLIF: server.Listener.Addr().String(),
+		SVM:           "svm0",
+		Breaker:       breaker,
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SystemGetVersion(); err == nil || utils.IsCircuitOpenError(err) {
+			t.Fatalf("Expected call %d to reach the server and fail, got %v.", i+1, err)
+		}
+	}
+	if state := breaker.State(); state != utils.CircuitOpen {
+		t.Fatalf("Expected the circuit to open after repeated server errors, got %s.", state)
+	}
+
+	// Further calls, including from clients bound to a context, fail without reaching the server
+	if _, err := client.SystemGetVersion(); !utils.IsCircuitOpenError(err) {
+		t.Errorf("Expected the open circuit to refuse the call, got %v.", err)
+	}
+	clone := client.WithContext(context.Background())
+	if _, err := clone.SystemGetVersion(); !utils.IsCircuitOpenError(err) {
+		t.Errorf("Expected the open circuit to refuse the cloned client's call, got %v.", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected 2 calls to reach the server, got %d.", n)
+	}
+}
+
+// volumePage returns a recorded volume-get-iter response listing the named volumes, with a
+// next-tag if more pages follow.
+func volumePage(names []string, nextTag string) *ZapiExchange {
+	var response bytes.Buffer
+	response.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<netapp version="1.21" xmlns="http://www.netapp.com/filer/admin"><results status="passed"><attributes-list>`)
+	for _, name := range names {
+		fmt.Fprintf(&response, "<volume-attributes><volume-id-attributes><name>%s</name>"+
+			"</volume-id-attributes></volume-attributes>", name)
+	}
+	response.WriteString("</attributes-list>")
+	if nextTag != "" {
+		fmt.Fprintf(&response, "<next-tag>%s</next-tag>", nextTag)
+	}
+	fmt.Fprintf(&response, "<num-records>%d</num-records></results></netapp>", len(names))
+	return &ZapiExchange{API: "volume-get-iter", StatusCode: http.StatusOK, Response: response.String()}
+}
+
+// volumePages returns the recorded pages of a volume-get-iter listing count volumes, 100 per page.
+func volumePages(count int) []*ZapiExchange {
+	pages := make([]*ZapiExchange, 0)
+	for start := 0; start < count; start += 100 {
+		names := make([]string, 0, 100)
+		for i := start; i < count && i < start+100; i++ {
+			names = append(names, fmt.Sprintf("vol%d", i))
+		}
+		nextTag := ""
+		if start+100 < count {
+			nextTag = fmt.Sprintf("tag%d", start+100)
+		}
+		pages = append(pages, volumePage(names, nextTag))
+	}
+	return pages
+}
+
+func TestVolumeCountPages(t *testing.T) {
+
+	replay := NewReplayTransport(volumePages(250))
+	client := NewClient(ClientConfig{SVM: "svm0", Transport: replay})
+
+	count, err := client.VolumeCount()
+	if err != nil {
+		t.Fatal("Unable to count volumes: ", err)
+	}
+	if count != 250 {
+		t.Errorf("Expected 250 volumes counted across three pages, got %d.", count)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("Expected every page to be read, %d remain.", replay.Remaining())
+	}
+}
+
+func TestVolumeCountByAggregatePages(t *testing.T) {
+
+	replay := NewReplayTransport(volumePages(120))
+	client := NewClient(ClientConfig{SVM: "svm0", Transport: replay})
+
+	count, err := client.VolumeCountByAggregate("aggr1")
+	if err != nil {
+		t.Fatal("Unable to count volumes: ", err)
+	}
+	if count != 120 {
+		t.Errorf("Expected 120 volumes counted across two pages, got %d.", count)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("Expected every page to be read, %d remain.", replay.Remaining())
+	}
+}
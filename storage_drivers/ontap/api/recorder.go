@@ -0,0 +1,229 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+// ZapiExchange is a single ZAPI request and the response ONTAP returned for it.
+type ZapiExchange struct {
+	API        string `json:"api"`
+	Request    string `json:"request"`
+	StatusCode int    `json:"statusCode"`
+	Response   string `json:"response"`
+}
+
+var zapiNameRegex = regexp.MustCompile(`<netapp[^>]*>\s*<([\w-]+)`)
+
+// zapiName returns the name of the API invoked by a ZAPI request, such as volume-get-iter.
+func zapiName(request string) string {
+	if match := zapiNameRegex.FindStringSubmatch(request); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// sanitizeZapi masks the values of any elements that may hold credentials, such as CHAP secrets.
+func sanitizeZapi(xml string) string {
+	return azgo.RedactZapi(xml)
+}
+
+// RecordingTransport passes ZAPI requests to another transport and appends each exchange, with
+// any credentials masked, to a file.  The file holds one JSON-encoded ZapiExchange per line, and
+// it may be replayed with a ReplayTransport.
+type RecordingTransport struct {
+	path  string
+	next  http.RoundTripper
+	mutex sync.Mutex
+}
+
+// NewRecordingTransport returns a transport that records to the named file.  If next is nil, the
+// default ZAPI transport is used.
+func NewRecordingTransport(path string, next http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{path: path, next: next}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	requestBody, err := readAndRestoreBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	next := t.next
+	if next == nil {
+		next = azgo.NewZapiTransport()
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readAndRestoreBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := &ZapiExchange{
+		API:        zapiName(string(requestBody)),
+		Request:    sanitizeZapi(string(requestBody)),
+		StatusCode: resp.StatusCode,
+		Response:   sanitizeZapi(string(responseBody)),
+	}
+	if err := t.record(exchange); err != nil {
+		log.WithFields(log.Fields{
+			"file": t.path,
+			"api":  exchange.API,
+		}).Warnf("Could not record ZAPI exchange. %v", err)
+	}
+
+	return resp, nil
+}
+
+func (t *RecordingTransport) record(exchange *ZapiExchange) error {
+
+	line, err := json.Marshal(exchange)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	file, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReplayTransport answers ZAPI requests from a recording rather than from ONTAP.  Each request is
+// answered with the earliest unused exchange for the same API, so the order of calls to different
+// APIs doesn't matter, but repeated calls to one API get their responses in the recorded order.
+type ReplayTransport struct {
+	mutex     sync.Mutex
+	exchanges map[string][]*ZapiExchange
+}
+
+// NewReplayTransport returns a transport that answers requests from the supplied exchanges.
+func NewReplayTransport(exchanges []*ZapiExchange) *ReplayTransport {
+	t := &ReplayTransport{exchanges: make(map[string][]*ZapiExchange)}
+	for _, exchange := range exchanges {
+		t.exchanges[exchange.API] = append(t.exchanges[exchange.API], exchange)
+	}
+	return t
+}
+
+// NewReplayTransportFromFile returns a transport that answers requests from a file written by a
+// RecordingTransport.
+func NewReplayTransportFromFile(path string) (*ReplayTransport, error) {
+	exchanges, err := ReadZapiRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayTransport(exchanges), nil
+}
+
+// ReadZapiRecording reads the exchanges from a file written by a RecordingTransport.
+func ReadZapiRecording(path string) ([]*ZapiExchange, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	exchanges := make([]*ZapiExchange, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		exchange := &ZapiExchange{}
+		if err = json.Unmarshal(scanner.Bytes(), exchange); err != nil {
+			return nil, fmt.Errorf("invalid ZAPI exchange on line %d of %s: %v", lineNumber, path, err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	requestBody, err := readAndRestoreBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	name := zapiName(string(requestBody))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	exchanges := t.exchanges[name]
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("no recorded response remains for ZAPI %s", name)
+	}
+	exchange := exchanges[0]
+	t.exchanges[name] = exchanges[1:]
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/xml"}},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(exchange.Response)),
+		ContentLength: int64(len(exchange.Response)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded exchanges that have not been replayed.
+func (t *ReplayTransport) Remaining() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	remaining := 0
+	for _, exchanges := range t.exchanges {
+		remaining += len(exchanges)
+	}
+	return remaining
+}
+
+// readAndRestoreBody reads a request or response body in full and replaces it with a copy, so
+// that it may still be read by whoever comes next.
+func readAndRestoreBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return []byte{}, nil
+	}
+	content, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+const vserverMaxVolumesResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<netapp version="1.21" xmlns="http://www.netapp.com/filer/admin">
+  <results status="passed">
+    <attributes-list><vserver-info><max-volumes>%d</max-volumes></vserver-info></attributes-list>
+    <num-records>1</num-records>
+  </results>
+</netapp>`
+
+func TestZapiName(t *testing.T) {
+	request := `<?xml version="1.0" encoding="UTF-8"?>
+        <netapp xmlns="http://www.netapp.com/filer/admin" version="1.21" vfiler="svm0">
+             <volume-get-iter><max-records>100</max-records></volume-get-iter>
+        </netapp>`
+	if name := zapiName(request); name != "volume-get-iter" {
+		t.Errorf("Expected volume-get-iter, got %s.", name)
+	}
+	if name := zapiName("not a ZAPI request"); name != "" {
+		t.Errorf("Expected no API name, got %s.", name)
+	}
+}
+
+func TestSanitizeZapi(t *testing.T) {
+	request := `<iscsi-initiator-set-default-auth><auth-type>CHAP</auth-type>` +
+		`<user-name>chap-user</user-name><password>hunter2</password>` +
+		`<outbound-passphrase>swordfish</outbound-passphrase></iscsi-initiator-set-default-auth>`
+
+	sanitized := sanitizeZapi(request)
+	for _, secret := range []string{"hunter2", "swordfish"} {
+		if strings.Contains(sanitized, secret) {
+			t.Errorf("Secret %s was not removed from %s.", secret, sanitized)
+		}
+	}
+	for _, kept := range []string{"<auth-type>CHAP</auth-type>", "<user-name>chap-user</user-name>",
+		"<password>********</password>"} {
+		if !strings.Contains(sanitized, kept) {
+			t.Errorf("Expected %s in %s.", kept, sanitized)
+		}
+	}
+
+	// Traced ZAPI objects list their fields one per line
+	result := "auth-type: CHAP\nuser-name: chap-user\noutbound-passphrase: swordfish\n"
+	if redacted := azgo.RedactZapi(result); redacted != "auth-type: CHAP\nuser-name: chap-user\n"+
+		"outbound-passphrase: ********\n" {
+		t.Errorf("Expected the passphrase to be removed from %s.", redacted)
+	}
+}
+
+func TestZapiTraceEnabled(t *testing.T) {
+	zr := &azgo.ZapiRunner{DebugTraceFlags: map[string]bool{
+		"api:volume-clone-create": true,
+		"api:snapshot-*":          true,
+		"api:lun-*":               false,
+	}}
+	for name, expected := range map[string]bool{
+		"volume-clone-create": true,
+		"volume-create":       false,
+		"snapshot-create":     true,
+		"lun-map":             false,
+	} {
+		if traced := zr.TraceEnabled(name); traced != expected {
+			t.Errorf("Expected tracing of %s to be %v.", name, expected)
+		}
+	}
+
+	zr.DebugTraceFlags["api"] = true
+	if !zr.TraceEnabled("lun-map") {
+		t.Error("Expected the api flag to trace every call.")
+	}
+}
+
+func TestZapiRecordAndReplay(t *testing.T) {
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, vserverMaxVolumesResponse, 100)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "zapi-recording")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	recordFile := filepath.Join(dir, "zapi.jsonl")
+
+	// Record an exchange with a live server
+	client := NewClient(ClientConfig{
+		ManagementLIF: server.Listener.Addr().String(),
+		SVM:           "svm0",
+		Username:      "admin",
+		Password:      "secret",
+		RecordFile:    recordFile,
+	})
+	if maxVolumes, err := client.VserverGetMaxVolumes(); err != nil {
+		t.Fatal("Unable to read SVM volume limit: ", err)
+	} else if maxVolumes != 100 {
+		t.Fatalf("Expected a limit of 100 volumes, got %d.", maxVolumes)
+	}
+
+	exchanges, err := ReadZapiRecording(recordFile)
+	if err != nil {
+		t.Fatal("Unable to read recording: ", err)
+	}
+	if len(exchanges) != 1 {
+		t.Fatalf("Expected 1 recorded exchange, got %d.", len(exchanges))
+	}
+	if exchanges[0].API != "vserver-get-iter" || exchanges[0].StatusCode != http.StatusOK {
+		t.Errorf("Unexpected exchange recorded: %v", exchanges[0])
+	}
+
+	// Replay it without the server
+	replay, err := NewReplayTransportFromFile(recordFile)
+	if err != nil {
+		t.Fatal("Unable to load recording: ", err)
+	}
+	client = NewClient(ClientConfig{SVM: "svm0", Transport: replay})
+	if maxVolumes, err := client.VserverGetMaxVolumes(); err != nil {
+		t.Fatal("Unable to replay SVM volume limit: ", err)
+	} else if maxVolumes != 100 {
+		t.Errorf("Expected a replayed limit of 100 volumes, got %d.", maxVolumes)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("Expected all exchanges to be replayed, %d remain.", replay.Remaining())
+	}
+	if _, err = client.VserverGetMaxVolumes(); err == nil {
+		t.Error("Expected an error once the recording was exhausted.")
+	}
+}
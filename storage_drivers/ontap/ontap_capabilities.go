@@ -0,0 +1,119 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+// Features that need more than SVM scope, which a backend does without if the configured user
+// lacks the rights to them
+const (
+	FeatureAggregateMedia = "aggregateMedia" // media type of aggregates, for the 'media' attribute
+	FeatureNodeSerials    = "nodeSerials"    // serial numbers of the cluster's nodes
+	FeatureLicenses       = "licenses"       // the cluster's licenses, for license checks
+	FeatureEMS            = "ems"            // EMS messages, used for heartbeats
+
+	// Features that the configured user's role may not allow, found by CheckPermissions
+	FeatureQoSPolicyGroups = "qosPolicyGroups" // QoS policy groups, for the 'qos' attribute
+	FeatureSnapMirror      = "snapMirror"      // SnapMirror, for the 'replication' attribute
+	FeatureFlexCache       = "flexCache"       // FlexCache, for caches of volumes
+	FeatureSVMPeers        = "svmPeers"        // SVM peers, for checking replication and cache sources
+
+	emsProbeLogLevel = 7 // debug, so the probe message is filtered out by default
+)
+
+// ProbeCapabilities finds which features the configured user's rights don't allow and records
+// them in the config, so that the driver runs without them rather than failing each time they
+// are used.  It relies on the node serial numbers and licenses having been read already, and
+// keeps any features CheckPermissions found unavailable.
+func ProbeCapabilities(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ProbeCapabilities", "Type": "ontap_common"}
+		log.WithFields(fields).Debug(">>>> ProbeCapabilities")
+		defer log.WithFields(fields).Debug("<<<< ProbeCapabilities")
+	}
+
+	unavailable := append(make([]string, 0), config.UnavailableFeatures...)
+
+	if len(config.SerialNumbers) == 0 {
+		unavailable = append(unavailable, FeatureNodeSerials)
+	}
+	if config.Licenses == nil {
+		unavailable = append(unavailable, FeatureLicenses)
+	}
+
+	// ONTAP 9 shows SVMs their aggregates, but earlier releases need cluster scope
+	if !SupportsVersionFeature(config, api.VServerShowAggr) {
+		response, err := client.AggrGetIterRequest()
+		if isScopeError(api.GetError(response, err)) {
+			unavailable = append(unavailable, FeatureAggregateMedia)
+		}
+	}
+
+	if isScopeError(probeEMS(client, config)) {
+		unavailable = append(unavailable, FeatureEMS)
+	}
+
+	config.UnavailableFeatures = unavailable
+	if len(unavailable) > 0 {
+		log.WithFields(log.Fields{
+			"username":            config.Username,
+			"svm":                 config.SVM,
+			"unavailableFeatures": strings.Join(unavailable, ","),
+		}).Warn("User has insufficient privileges for some features; the backend will run without them.")
+	}
+}
+
+// probeEMS logs a debug-level EMS message announcing the backend, which only succeeds if the
+// user may log EMS messages.
+func probeEMS(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	response, err := client.EmsAutosupportLog(
+		strconv.Itoa(drivers.ConfigVersion), false, "initialization", hostname,
+		trident.OrchestratorName+" initialized "+config.StorageDriverName+" backend for SVM "+config.SVM,
+		1, config.EMSEventSource, emsProbeLogLevel)
+	return api.GetError(response, err)
+}
+
+// supportsReplication returns true if volumes may be replicated with SnapMirror, which must be
+// licensed and allowed by the configured user's role.
+func supportsReplication(config *drivers.OntapStorageDriverConfig) bool {
+	return IsLicensed(config, LicenseSnapMirror) && IsFeatureAvailable(config, FeatureSnapMirror)
+}
+
+// supportsFlexCache returns true if caches of volumes may be created with FlexCache, which needs
+// ONTAP 9.5 or later and must be allowed by the configured user's role.
+func supportsFlexCache(config *drivers.OntapStorageDriverConfig) bool {
+	return SupportsVersionFeature(config, api.FlexCache) && IsFeatureAvailable(config, FeatureFlexCache)
+}
+
+// isScopeError returns true if an error shows that the user's rights don't allow a ZAPI call.
+func isScopeError(err error) bool {
+	zerr, ok := err.(api.ZapiError)
+	return ok && zerr.IsScopeError()
+}
+
+// IsFeatureAvailable returns false if the configured user was found to lack the rights to a
+// feature when the driver was initialized.
+func IsFeatureAvailable(config *drivers.OntapStorageDriverConfig, feature string) bool {
+	for _, f := range config.UnavailableFeatures {
+		if f == feature {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+func TestProbeCapabilities(t *testing.T) {
+	privilegeErr := api.NewZapiError(azgo.EmsAutosupportLogResponseResult{
+		ResultStatusAttr: "failed",
+		ResultErrnoAttr:  azgo.EAPIPRIVILEGE,
+	})
+	for _, test := range []struct {
+		name        string
+		client      *mockClient
+		serials     []string
+		licenses    []string
+		unavailable []string
+	}{
+		{
+			name:        "clusterScoped",
+			client:      &mockClient{features: map[api.Feature]bool{api.VServerShowAggr: true}},
+			serials:     []string{"1-80-000011"},
+			licenses:    []string{LicenseNFS},
+			unavailable: []string{},
+		},
+		{
+			name:        "svmScoped",
+			client:      &mockClient{aggrGetIterErr: privilegeErr, emsErr: privilegeErr},
+			unavailable: []string{FeatureNodeSerials, FeatureLicenses, FeatureAggregateMedia, FeatureEMS},
+		},
+		{
+			name:        "svmScopedOntap9",
+			client:      &mockClient{features: map[api.Feature]bool{api.VServerShowAggr: true}, emsErr: privilegeErr},
+			unavailable: []string{FeatureNodeSerials, FeatureLicenses, FeatureEMS},
+		},
+		{
+			// Failures other than a lack of rights don't rule out a feature
+			name: "emsUnreachable",
+			client: &mockClient{features: map[api.Feature]bool{api.VServerShowAggr: true},
+				emsErr: errors.New("connection refused")},
+			serials:     []string{"1-80-000011"},
+			licenses:    []string{LicenseNFS},
+			unavailable: []string{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+					StorageDriverName: drivers.OntapNASStorageDriverName,
+					SerialNumbers:     test.serials,
+				},
+				Licenses:        test.licenses,
+				VersionFeatures: versionFeatures(test.client.features),
+			}
+			ProbeCapabilities(test.client, config)
+			if !reflect.DeepEqual(config.UnavailableFeatures, test.unavailable) {
+				t.Errorf("Expected unavailable features %v, got %v", test.unavailable, config.UnavailableFeatures)
+			}
+			for _, feature := range test.unavailable {
+				if IsFeatureAvailable(config, feature) {
+					t.Errorf("Expected feature %s to be unavailable.", feature)
+				}
+			}
+		})
+	}
+}
+
+func TestSendHeartbeatWithoutEMS(t *testing.T) {
+	client := &mockClient{}
+	driver := newTestTelemetryDriver("")
+	driver.API = client
+	driver.Config.TelemetryTransport = TelemetryTransportEMS
+	driver.Config.UnavailableFeatures = []string{FeatureEMS}
+	driver.Telemetry = NewOntapTelemetry(driver)
+
+	SendHeartbeat(driver)
+	if client.emsMessages != 0 {
+		t.Errorf("Expected no EMS messages without EMS rights, got %d", client.emsMessages)
+	}
+
+	driver.Config.UnavailableFeatures = []string{}
+	SendHeartbeat(driver)
+	if client.emsMessages != 1 {
+		t.Errorf("Expected one EMS message, got %d", client.emsMessages)
+	}
+}
+
+func TestCapabilityOffers(t *testing.T) {
+	driver := newTestTelemetryDriver("")
+	driver.API = &mockClient{}
+	driver.Config.Licenses = []string{LicenseNFS, LicenseFlexClone, LicenseSnapMirror}
+
+	offersTrue := func(attribute string) bool {
+		offer, ok := driver.GetStoragePoolAttributes()[attribute]
+		return ok && offer.Matches(sa.NewBoolRequest(true))
+	}
+	for _, attribute := range []string{sa.Resize, sa.QoS, sa.Replication} {
+		if !offersTrue(attribute) {
+			t.Errorf("Expected ontap-nas pools to offer %s.", attribute)
+		}
+	}
+
+	// A role without QoS or SnapMirror rights rules out the capabilities that need them
+	driver.Config.UnavailableFeatures = []string{FeatureQoSPolicyGroups, FeatureSnapMirror}
+	if offersTrue(sa.QoS) || offersTrue(sa.Replication) {
+		t.Error("Expected no QoS or replication without the rights to them.")
+	}
+
+	// Replication needs a SnapMirror license
+	driver.Config.UnavailableFeatures = []string{}
+	driver.Config.Licenses = []string{LicenseNFS}
+	if offersTrue(sa.Replication) {
+		t.Error("Expected no replication without a SnapMirror license.")
+	}
+
+	// Clones made with FlexClone need its license, unless they may be made by copying instead
+	driver.Config.CloneMethod = CloneMethodFlexClone
+	if offersTrue(sa.Clones) {
+		t.Error("Expected no clones without a FlexClone license.")
+	}
+	driver.Config.CloneMethod = CloneMethodAuto
+	if !offersTrue(sa.Clones) {
+		t.Error("Expected clones by copy without a FlexClone license.")
+	}
+
+	san := &SANStorageDriver{API: &mockClient{}}
+	san.Config = driver.Config
+	if offer := san.GetStoragePoolAttributes()[sa.Clones]; offer.Matches(sa.NewBoolRequest(true)) {
+		t.Error("Expected no ontap-san clones without a FlexClone license.")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	// DefaultCircuitBreakerFailures is how many consecutive ZAPI calls the cluster may fail to
+	// answer, by default, before a backend stops calling it.
+	DefaultCircuitBreakerFailures = 5
+
+	// DefaultCircuitBreakerCooldownSecs is how long a backend stops calling the cluster, by
+	// default, before probing whether it has recovered.
+	DefaultCircuitBreakerCooldownSecs = 30
+)
+
+const circuitProbeTask = "probe-cluster"
+
+// initCircuitBreaker validates the circuit breaker settings and creates the breaker that the
+// driver's API clients share, so that a cluster that stops responding isn't sent a stream of
+// calls that can only time out.
+func initCircuitBreaker(config *drivers.OntapStorageDriverConfig) error {
+
+	if config.CircuitBreakerFailures == 0 {
+		config.CircuitBreakerFailures = DefaultCircuitBreakerFailures
+	} else if config.CircuitBreakerFailures < 0 {
+		return fmt.Errorf("invalid value for circuitBreakerFailures: %d; must be at least 1",
+			config.CircuitBreakerFailures)
+	}
+
+	cooldown, err := drivers.ParseTimeout("circuitBreakerCooldown", config.CircuitBreakerCooldown)
+	if err != nil {
+		return err
+	}
+	if cooldown == 0 {
+		cooldown = DefaultCircuitBreakerCooldownSecs * time.Second
+	}
+	config.CircuitBreakerCooldownDuration = cooldown
+
+	config.CircuitBreaker = utils.NewCircuitBreaker(
+		fmt.Sprintf("ONTAP cluster %s", config.ManagementLIF), config.CircuitBreakerFailures, cooldown)
+	return nil
+}
+
+// NewCircuitProbeTask returns the housekeeping task that, while a driver has stopped calling its
+// cluster, probes it once each cool-down has passed, so that the backend recovers as soon as the
+// cluster does rather than when the next volume operation happens to arrive.
+func NewCircuitProbeTask(d StorageDriver) utils.HousekeepingTask {
+	config := d.GetConfig()
+	return utils.HousekeepingTask{
+		Name:     circuitProbeTask,
+		Interval: config.CircuitBreakerCooldownDuration / 2,
+		Run: func() {
+			if !config.CircuitBreaker.ProbeDue() {
+				return
+			}
+			if _, err := d.GetAPI().SystemGetVersion(); err != nil {
+				log.WithField("driver", d.Name()).Debugf("Cluster probe failed. %v", err)
+			}
+		},
+	}
+}
+
+// Degraded returns why an ONTAP driver has stopped calling its cluster, or an empty string if it
+// hasn't.
+func Degraded(config *drivers.OntapStorageDriverConfig) string {
+	return config.CircuitBreaker.Reason()
+}
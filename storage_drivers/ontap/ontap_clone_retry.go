@@ -0,0 +1,55 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// cloneBusyMaxElapsedTime is how long a clone step that ONTAP reports as busy is retried before the
+// clone fails with a retryable error.  Clones are created while the orchestrator is locked, so the
+// retries are kept short, and the frontend backs off before asking again.
+const cloneBusyMaxElapsedTime = 10 * time.Second
+
+// retryWhileBusy runs a clone step, retrying it briefly with a growing delay for as long as ONTAP
+// reports that it is busy, such as with other clones of the same parent, reporting each wait.
+func retryWhileBusy(ctx context.Context, step string, f func() error) error {
+
+	busyBackoff := backoff.NewExponentialBackOff()
+	busyBackoff.InitialInterval = time.Second
+	busyBackoff.Multiplier = 2
+	busyBackoff.RandomizationFactor = 0.1
+	busyBackoff.MaxInterval = 4 * time.Second
+	busyBackoff.MaxElapsedTime = cloneBusyMaxElapsedTime
+
+	// Only busy errors are retried; any other result ends the retries and is returned as it is
+	var lastErr error
+	operation := func() error {
+		lastErr = f()
+		if drivers.IsRetryableError(lastErr) {
+			return lastErr
+		}
+		return nil
+	}
+	notify := func(err error, duration time.Duration) {
+		log.WithField("increment", duration).Debugf("ONTAP is busy, retrying %s. %v", step, err)
+		drivers.ReportProgress(ctx, "CloneBusy", fmt.Sprintf(
+			"ONTAP is busy; retrying %s in %s.", step, duration.Round(time.Second)))
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := backoff.RetryNotify(operation, backoff.WithContext(busyBackoff, ctx), notify); err != nil &&
+		lastErr == nil {
+		return err
+	}
+	return lastErr
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func TestRetryWhileBusy(t *testing.T) {
+	calls := 0
+	err := retryWhileBusy(context.Background(), "clone creation", func() error {
+		calls++
+		if calls == 1 {
+			return drivers.NewRetryableError("error creating clone: volume is busy")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Expected success on the second attempt, got %v after %d.", err, calls)
+	}
+
+	calls = 0
+	err = retryWhileBusy(context.Background(), "clone creation", func() error {
+		calls++
+		return errors.New("error creating clone: no such snapshot")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected an error that isn't busy to be returned at once, got %v after %d.", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retryWhileBusy(ctx, "clone creation", func() error {
+		return drivers.NewRetryableError("error creating clone: volume is busy")
+	})
+	if !drivers.IsRetryableError(err) {
+		t.Errorf("Expected the busy error once the caller gave up, got %v.", err)
+	}
+}
@@ -3,14 +3,18 @@
 package ontap
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,22 +33,16 @@ import (
 const (
 	LSMirrorIdleTimeoutSecs      = 30
 	MinimumVolumeSizeBytes       = 20971520 // 20 MiB
+	LUNBlockSizeBytes            = 4096     // LUN sizes are multiples of this
 	HousekeepingStartupDelaySecs = 10
+	HousekeepingMaxJitterSecs    = 10
+	ReplicaPollIntervalSecs      = 5
+	CachePollIntervalSecs        = 5
 )
 
-type Telemetry struct {
-	trident.Telemetry
-	Plugin        string        `json:"plugin"`
-	SVM           string        `json:"svm"`
-	StoragePrefix string        `json:"storagePrefix"`
-	Driver        StorageDriver `json:"-"`
-	done          chan struct{} `json:"-"`
-	ticker        *time.Ticker  `json:"-"`
-}
-
 type StorageDriver interface {
 	GetConfig() *drivers.OntapStorageDriverConfig
-	GetAPI() *api.Client
+	GetAPI() api.ZapiClient
 	GetTelemetry() *Telemetry
 	Name() string
 }
@@ -74,69 +72,15 @@ func InitializeOntapConfig(
 	return config, nil
 }
 
-func NewOntapTelemetry(d StorageDriver) *Telemetry {
-	t := &Telemetry{
-		Telemetry:     trident.OrchestratorTelemetry,
-		Plugin:        d.Name(),
-		SVM:           d.GetConfig().SVM,
-		StoragePrefix: *d.GetConfig().StoragePrefix,
-		Driver:        d,
-		done:          make(chan struct{}),
-	}
-
-	usageHeartbeat := d.GetConfig().UsageHeartbeat
-	heartbeatIntervalInHours := 24.0 // default to 24 hours
-	if usageHeartbeat != "" {
-		f, err := strconv.ParseFloat(usageHeartbeat, 64)
-		if err != nil {
-			log.WithField("interval", usageHeartbeat).Warnf("Invalid heartbeat interval. %v", err)
-		} else {
-			heartbeatIntervalInHours = f
-		}
-	}
-	log.WithField("intervalHours", heartbeatIntervalInHours).Debug("Configured EMS heartbeat.")
-
-	durationInHours := time.Millisecond * time.Duration(MSecPerHour*heartbeatIntervalInHours)
-	if durationInHours > 0 {
-		t.ticker = time.NewTicker(durationInHours)
-	}
-	return t
-}
-
-// Start starts the flow of ASUP messages for the driver
-// These messages can be viewed via filer::> event log show -severity NOTICE.
-func (t *Telemetry) Start() {
-	go func() {
-		time.Sleep(HousekeepingStartupDelaySecs * time.Second)
-		EMSHeartbeat(t.Driver)
-		for {
-			select {
-			case tick := <-t.ticker.C:
-				log.WithFields(log.Fields{
-					"tick":   tick,
-					"driver": t.Driver.Name(),
-				}).Debug("Sending EMS heartbeat.")
-				EMSHeartbeat(t.Driver)
-			case <-t.done:
-				log.WithFields(log.Fields{
-					"driver": t.Driver.Name(),
-				}).Debugf("Shut down EMS logs for the driver.")
-				return
-			}
-		}
-	}()
-}
-
-func (t *Telemetry) Stop() {
-	if t.ticker != nil {
-		t.ticker.Stop()
-	}
-	close(t.done)
+// NewHousekeepingScheduler returns the scheduler for a driver's background work, such as its
+// EMS heartbeat.  The driver must stop it when terminated.
+func NewHousekeepingScheduler(d StorageDriver) *utils.HousekeepingScheduler {
+	return utils.NewHousekeepingScheduler(fmt.Sprintf("%s/%s", d.Name(), d.GetConfig().SVM))
 }
 
 // InitializeOntapDriver sets up the API client and performs all other initialization tasks
 // that are common to all the ONTAP drivers.
-func InitializeOntapDriver(config *drivers.OntapStorageDriverConfig) (*api.Client, error) {
+func InitializeOntapDriver(config *drivers.OntapStorageDriverConfig) (api.ZapiClient, error) {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "InitializeOntapDriver", "Type": "ontap_common"}
@@ -155,24 +99,36 @@ func InitializeOntapDriver(config *drivers.OntapStorageDriverConfig) (*api.Clien
 		"addresses": addressesFromHostname,
 	}).Debug("Addresses found from ManagementLIF lookup.")
 
+	// Parse the timeouts the API client and LS mirror updates depend on, and set up the API client's
+	// circuit breaker
+	if config.ZapiTimeoutDuration, err = drivers.ParseTimeout("zapiTimeout", config.ZapiTimeout); err != nil {
+		return nil, err
+	}
+	if config.LSMirrorTimeoutDuration, err = drivers.ParseTimeout(
+		"lsMirrorTimeout", config.LSMirrorTimeout); err != nil {
+		return nil, err
+	}
+	if err = initCircuitBreaker(config); err != nil {
+		return nil, err
+	}
+
 	// Get the API client
 	client, err := InitializeOntapAPI(config)
 	if err != nil {
 		return nil, fmt.Errorf("could not create Data ONTAP API client: %v", err)
 	}
 
-	// Make sure we're using a valid ONTAP version
-	ontapi, err := client.SystemGetOntapiVersion()
-	if err != nil {
-		return nil, fmt.Errorf("could not determine Data ONTAP API version: %v", err)
+	// Make sure we're using a valid ONTAP version, and find what else the version allows
+	if err = DetectVersionMatrix(client, config); err != nil {
+		return nil, err
 	}
-	if !client.SupportsFeature(api.MinimumONTAPIVersion) {
+	if !SupportsVersionFeature(config, api.MinimumONTAPIVersion) {
 		return nil, errors.New("ONTAP 8.3 or later is required")
 	}
-	log.WithField("Ontapi", ontapi).Debug("ONTAP API version.")
 
 	// Log cluster node serial numbers if we can get them
 	config.SerialNumbers, err = client.ListNodeSerialNumbers()
+	config.SingleNode = err == nil && len(config.SerialNumbers) == 1
 	if err != nil {
 		log.Warnf("Could not determine controller serial numbers. %v", err)
 	} else {
@@ -181,18 +137,59 @@ func InitializeOntapDriver(config *drivers.OntapStorageDriverConfig) (*api.Clien
 		}).Info("Controller serial numbers.")
 	}
 
+	// Identify the SVM, so that backends managing it through different LIFs can be recognized
+	if config.SVMUUID, err = client.VserverGetUUID(); err != nil {
+		log.Warnf("Could not determine SVM UUID. %v", err)
+	} else {
+		log.WithField("uuid", config.SVMUUID).Debug("SVM UUID.")
+	}
+
+	// Recognize ONTAP Select, whose single-node deployments lack what full clusters are checked for
+	if models, err := client.ListNodeModels(); err != nil {
+		log.Debugf("Could not determine controller models. %v", err)
+	} else {
+		config.ONTAPSelect = IsONTAPSelect(models) && !IsCloudVolumesONTAP(config)
+		log.WithFields(log.Fields{
+			"models":      strings.Join(models, ","),
+			"ontapSelect": config.ONTAPSelect,
+			"singleNode":  config.SingleNode,
+		}).Debug("Controller models.")
+	}
+
+	// Make sure the features this driver depends on are licensed
+	err = ValidateLicenses(client, config)
+	if err != nil {
+		return nil, fmt.Errorf("license validation failed: %v", err)
+	}
+
 	// Load default config parameters
 	err = PopulateConfigurationDefaults(config)
 	if err != nil {
 		return nil, fmt.Errorf("could not populate configuration defaults: %v", err)
 	}
+	if err = PopulateVersionDefaults(config); err != nil {
+		return nil, fmt.Errorf("could not populate configuration defaults: %v", err)
+	}
+
+	// Make sure the user's role allows what the driver needs
+	if err = CheckPermissions(client, config); err != nil {
+		return nil, fmt.Errorf("permission check failed: %v", err)
+	}
+
+	// Find what an SVM-scoped user can't do, so the driver runs without it
+	ProbeCapabilities(client, config)
+
+	// Give volumes the snapshot policy the backend declares, as it declares it
+	if err = EnsureSnapshotPolicy(config, client); err != nil {
+		return nil, fmt.Errorf("could not reconcile snapshot policy: %v", err)
+	}
 
 	return client, nil
 }
 
 // InitializeOntapAPI returns an ontap.Client ZAPI client.  If the SVM isn't specified in the config
 // file, this method attempts to derive the one to use.
-func InitializeOntapAPI(config *drivers.OntapStorageDriverConfig) (*api.Client, error) {
+func InitializeOntapAPI(config *drivers.OntapStorageDriverConfig) (api.ZapiClient, error) {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "InitializeOntapAPI", "Type": "ontap_common"}
@@ -200,12 +197,20 @@ func InitializeOntapAPI(config *drivers.OntapStorageDriverConfig) (*api.Client,
 		defer log.WithFields(fields).Debug("<<<< InitializeOntapAPI")
 	}
 
+	if config.ZapiRecordFile != "" {
+		log.WithField("file", config.ZapiRecordFile).Warning(
+			"Recording ZAPI exchanges; remove zapiRecordFile from the backend config when finished.")
+	}
+
 	client := api.NewClient(api.ClientConfig{
 		ManagementLIF:   config.ManagementLIF,
 		SVM:             config.SVM,
 		Username:        config.Username,
 		Password:        config.Password,
 		DebugTraceFlags: config.DebugTraceFlags,
+		RecordFile:      config.ZapiRecordFile,
+		Timeout:         config.ZapiTimeoutDuration,
+		Breaker:         config.CircuitBreaker,
 	})
 
 	if config.SVM != "" {
@@ -231,14 +236,122 @@ func InitializeOntapAPI(config *drivers.OntapStorageDriverConfig) (*api.Client,
 		Username:        config.Username,
 		Password:        config.Password,
 		DebugTraceFlags: config.DebugTraceFlags,
+		RecordFile:      config.ZapiRecordFile,
+		Timeout:         config.ZapiTimeoutDuration,
+		Breaker:         config.CircuitBreaker,
 	})
 	log.WithField("SVM", config.SVM).Debug("Using derived SVM.")
 
 	return client, nil
 }
 
+// ONTAP license package names checked during driver initialization
+const (
+	LicenseNFS        = "nfs"
+	LicenseISCSI      = "iscsi"
+	LicenseFCP        = "fcp"
+	LicenseFlexClone  = "flexclone"
+	LicenseSnapMirror = "snapmirror"
+)
+
+// driverProtocolLicenses lists the protocol license each ONTAP driver cannot function without.
+var driverProtocolLicenses = map[string]string{
+	drivers.OntapNASStorageDriverName:      LicenseNFS,
+	drivers.OntapNASQtreeStorageDriverName: LicenseNFS,
+	drivers.OntapSANStorageDriverName:      LicenseISCSI,
+}
+
+// ValidateLicenses reads the licenses installed on the cluster and records them in the config.  A missing
+// protocol license is fatal, since no volume created by the driver could ever be used, as is a missing
+// FlexClone license when the config asks that clones always use FlexClone.  Other missing feature
+// licenses only disable the dependent features.  If the licenses cannot be read (i.e. the user is
+// SVM-scoped), the check is skipped and all features are assumed to be available.
+func ValidateLicenses(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ValidateLicenses", "Type": "ontap_common"}
+		log.WithFields(fields).Debug(">>>> ValidateLicenses")
+		defer log.WithFields(fields).Debug("<<<< ValidateLicenses")
+	}
+
+	licenses, err := client.ListLicensedPackages()
+	if zerr, ok := err.(api.ZapiError); ok && zerr.IsScopeError() {
+		log.WithFields(log.Fields{
+			"username": config.Username,
+		}).Warn("User has insufficient privileges to read cluster licenses. License checks will be skipped.")
+		return nil
+	} else if err != nil {
+		log.Warnf("Could not read cluster licenses; license checks will be skipped. %v", err)
+		return nil
+	}
+
+	config.Licenses = licenses
+	log.WithField("licenses", strings.Join(licenses, ",")).Info("Cluster licenses.")
+
+	if protocol, ok := driverProtocolLicenses[config.StorageDriverName]; ok && !IsLicensed(config, protocol) {
+		return fmt.Errorf("the %s driver requires the %s license, which is not installed",
+			config.StorageDriverName, strings.ToUpper(protocol))
+	}
+
+	if !IsLicensed(config, LicenseFlexClone) {
+		if config.CloneMethod == CloneMethodFlexClone {
+			return fmt.Errorf("cloneMethod %s requires the FlexClone license, which is not installed; "+
+				"use %s or %s instead", CloneMethodFlexClone, CloneMethodAuto, CloneMethodCopy)
+		}
+		log.WithField("driver", config.StorageDriverName).Warn(
+			"FlexClone is not licensed. Volumes cannot be cloned on this backend.")
+	}
+
+	if !IsLicensed(config, LicenseSnapMirror) && len(config.PeerSVMs) > 0 {
+		log.WithField("driver", config.StorageDriverName).Warn(
+			"SnapMirror is not licensed. Volumes cannot be replicated from the peer SVMs.")
+	}
+
+	return nil
+}
+
+// IsLicensed returns true if the named license package was found on the cluster.  If the licenses
+// could not be read during initialization, all packages are assumed to be licensed.
+func IsLicensed(config *drivers.OntapStorageDriverConfig, license string) bool {
+
+	if config.Licenses == nil {
+		return true
+	}
+
+	for _, l := range config.Licenses {
+		if l == license {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyError prefixes an error returned by the ONTAP API with the supplied message, and marks the
+// result as retryable or fatal if the underlying failure is known to be transient or permanent.
+func classifyError(err error, message string) error {
+
+	message = fmt.Sprintf("%s: %v", message, err)
+
+	if netErr, ok := err.(net.Error); ok && (netErr.Timeout() || netErr.Temporary()) {
+		return drivers.NewRetryableError(message)
+	}
+
+	if zerr, ok := err.(api.ZapiError); ok {
+		reason := strings.ToLower(zerr.Reason())
+		switch {
+		case zerr.IsScopeError(), zerr.Code() == azgo.EINVALIDINPUTERROR, zerr.Code() == azgo.EAGGRDOESNOTEXIST:
+			return drivers.NewFatalError(message)
+		case strings.Contains(reason, "busy"), strings.Contains(reason, "in progress"),
+			strings.Contains(reason, "try again"):
+			return drivers.NewRetryableError(message)
+		}
+	}
+
+	return errors.New(message)
+}
+
 // ValidateAggregate returns an error if the configured aggregate is not available to the Vserver.
-func ValidateAggregate(api *api.Client, config *drivers.OntapStorageDriverConfig) error {
+func ValidateAggregate(api api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "ValidateAggregate", "Type": "ontap_common"}
@@ -273,7 +386,7 @@ func ValidateAggregate(api *api.Client, config *drivers.OntapStorageDriverConfig
 }
 
 // ValidateNASDriver contains the validation logic shared between ontap-nas and ontap-nas-economy.
-func ValidateNASDriver(api *api.Client, config *drivers.OntapStorageDriverConfig) error {
+func ValidateNASDriver(api api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "ValidateNASDriver", "Type": "ontap_common"}
@@ -281,7 +394,15 @@ func ValidateNASDriver(api *api.Client, config *drivers.OntapStorageDriverConfig
 		defer log.WithFields(fields).Debug("<<<< ValidateNASDriver")
 	}
 
-	dataLIFs, err := api.NetInterfaceGetDataLIFs("nfs")
+	if err := validateSMBConfig(config); err != nil {
+		return err
+	}
+
+	protocol := "nfs"
+	if config.NASType == NASTypeSMB {
+		protocol = "cifs"
+	}
+	dataLIFs, err := api.NetInterfaceGetDataLIFs(protocol)
 	if err != nil {
 		return err
 	}
@@ -339,6 +460,11 @@ func ValidateDataLIFs(config *drivers.OntapStorageDriverConfig, dataLIFs []strin
 		}
 		if foundValidLIFAddress {
 			log.WithField("hostNameAddress", hostNameAddress).Debug("Found matching Data LIF.")
+		} else if IsCloudVolumesONTAP(config) {
+			// Cloud networks often reach a LIF through an address it doesn't know, such as a
+			// floating IP, so the mismatch may well be expected
+			log.WithField("hostNameAddress", hostNameAddress).Warning(
+				"Could not find matching Data LIF; using it anyway for Cloud Volumes ONTAP.")
 		} else {
 			log.WithField("hostNameAddress", hostNameAddress).Debug("Could not find matching Data LIF.")
 			return fmt.Errorf("could not find Data LIF for %s", hostNameAddress)
@@ -359,6 +485,33 @@ const DefaultNfsMountOptions = "-o nfsvers=3"
 const DefaultSplitOnClone = "false"
 const DefaultFileSystemType = "ext4"
 const DefaultEncryption = "false"
+const DefaultCloneMethod = CloneMethodFlexClone
+const DefaultFlexvolLimitWarningPercent = 90
+const DefaultEMSSeverity = "notice"
+const DefaultEMSAutoSupport = "false"
+
+// emsLogLevels maps EMS severities to the log levels of ems-autosupport-log
+var emsLogLevels = map[string]int{
+	"emergency":     0,
+	"alert":         1,
+	"critical":      2,
+	"error":         3,
+	"warning":       4,
+	"notice":        5,
+	"informational": 6,
+	"debug":         7,
+}
+
+const (
+	NASTypeNFS = "nfs" // hosts mount volumes over NFS
+	NASTypeSMB = "smb" // hosts reach volumes through an SMB share of the SVM's root junction
+)
+
+const (
+	CloneMethodFlexClone = "flexclone" // always use FlexClone
+	CloneMethodCopy      = "copy"      // always provision a new volume and copy the data
+	CloneMethodAuto      = "auto"      // copy only if FlexClone is not licensed
+)
 
 // PopulateConfigurationDefaults fills in default values for configuration settings if not supplied in the config file
 func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) error {
@@ -378,6 +531,15 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 			return fmt.Errorf("invalid config value for default volume size: %v", err)
 		}
 	}
+	if err := drivers.ValidateFilesystemPermissions(
+		config.DriverContext, config.FileSystemOwner, config.FileSystemMode); err != nil {
+		return err
+	}
+
+	// Apply the defaults of the backend's profile before the general ones
+	if err := PopulateProfileDefaults(config); err != nil {
+		return err
+	}
 
 	if config.StoragePrefix == nil {
 		prefix := drivers.GetDefaultStoragePrefix(config.DriverContext)
@@ -388,6 +550,19 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 		config.SpaceReserve = DefaultSpaceReserve
 	}
 
+	// A declared snapshot policy is the one volumes get, unless the backend names it too
+	if config.SnapshotPolicySpec != nil {
+		if err := ValidateSnapshotPolicySpec(config.SnapshotPolicySpec); err != nil {
+			return fmt.Errorf("invalid config value for snapshotPolicySpec: %v", err)
+		}
+		if config.SnapshotPolicy == "" {
+			config.SnapshotPolicy = config.SnapshotPolicySpec.Name
+		} else if config.SnapshotPolicy != config.SnapshotPolicySpec.Name {
+			return fmt.Errorf("snapshotPolicy %s differs from the snapshotPolicySpec name %s",
+				config.SnapshotPolicy, config.SnapshotPolicySpec.Name)
+		}
+	}
+
 	if config.SnapshotPolicy == "" {
 		config.SnapshotPolicy = DefaultSnapshotPolicy
 	}
@@ -404,12 +579,34 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 		config.ExportPolicy = DefaultExportPolicy
 	}
 
+	// Volumes are reached over NFS unless nasType says otherwise
+	if config.NASType != "" && config.NASType != NASTypeNFS && config.NASType != NASTypeSMB {
+		return fmt.Errorf("invalid value for nasType: %s; it must be %s or %s", config.NASType, NASTypeNFS,
+			NASTypeSMB)
+	}
+
 	if config.SecurityStyle == "" {
-		config.SecurityStyle = DefaultSecurityStyle
+		// Files reached over SMB are controlled by NTFS ACLs rather than Unix permissions
+		if config.NASType == NASTypeSMB {
+			config.SecurityStyle = "ntfs"
+		} else {
+			config.SecurityStyle = DefaultSecurityStyle
+		}
+	}
+
+	unixPermissions, err := ConvertUnixPermissions(config.UnixPermissions)
+	if err != nil {
+		return err
+	}
+	config.UnixPermissions = unixPermissions
+	if err = ValidateSecurityStyle(config.SecurityStyle); err != nil {
+		return err
 	}
 
 	if config.NfsMountOptions == "" {
 		config.NfsMountOptions = DefaultNfsMountOptions
+	} else if err := utils.ValidateNFSMountOptions(config.NfsMountOptions); err != nil {
+		return err
 	}
 
 	if config.SplitOnClone == "" {
@@ -429,6 +626,49 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 		config.Encryption = DefaultEncryption
 	}
 
+	for option := range config.AdvancedOptions {
+		if strings.TrimSpace(option) == "" {
+			return errors.New("invalid empty option name in advancedOptions")
+		}
+	}
+
+	switch config.CloneMethod {
+	case "":
+		config.CloneMethod = DefaultCloneMethod
+	case CloneMethodFlexClone, CloneMethodCopy, CloneMethodAuto:
+	default:
+		return fmt.Errorf("invalid value for cloneMethod: %s", config.CloneMethod)
+	}
+
+	if config.LimitFlexvolsPerSVM < 0 {
+		return fmt.Errorf("invalid value for limitFlexvolsPerSVM: %d", config.LimitFlexvolsPerSVM)
+	}
+	if config.LimitFlexvolsPerAggregate < 0 {
+		return fmt.Errorf("invalid value for limitFlexvolsPerAggregate: %d", config.LimitFlexvolsPerAggregate)
+	}
+	if config.FlexvolLimitWarningPercent == 0 {
+		config.FlexvolLimitWarningPercent = DefaultFlexvolLimitWarningPercent
+	} else if config.FlexvolLimitWarningPercent < 0 || config.FlexvolLimitWarningPercent > 100 {
+		return fmt.Errorf("invalid value for flexvolLimitWarningPercent: %d; must be from 1 to 100",
+			config.FlexvolLimitWarningPercent)
+	}
+
+	if err := populateEMSDefaults(config); err != nil {
+		return err
+	}
+
+	if err := populateTelemetryDefaults(config); err != nil {
+		return err
+	}
+
+	if err := validateNameTemplate(config.NameTemplate); err != nil {
+		return err
+	}
+
+	if err := validateTenantPrefixes(config); err != nil {
+		return err
+	}
+
 	log.WithFields(log.Fields{
 		"StoragePrefix":   *config.StoragePrefix,
 		"SpaceReserve":    config.SpaceReserve,
@@ -438,10 +678,20 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 		"ExportPolicy":    config.ExportPolicy,
 		"SecurityStyle":   config.SecurityStyle,
 		"NfsMountOptions": config.NfsMountOptions,
+		"NASType":         config.NASType,
 		"SplitOnClone":    config.SplitOnClone,
 		"FileSystemType":  config.FileSystemType,
 		"Encryption":      config.Encryption,
+		"CloneMethod":     config.CloneMethod,
+		"TieringPolicy":   config.TieringPolicy,
+		"Profile":         config.Profile,
+		"AdvancedOptions": config.AdvancedOptions,
 		"Size":            config.Size,
+		"NameTemplate":    config.NameTemplate,
+
+		"LimitFlexvolsPerSVM":        config.LimitFlexvolsPerSVM,
+		"LimitFlexvolsPerAggregate":  config.LimitFlexvolsPerAggregate,
+		"FlexvolLimitWarningPercent": config.FlexvolLimitWarningPercent,
 	}).Debugf("Configuration defaults")
 
 	return nil
@@ -449,176 +699,1834 @@ func PopulateConfigurationDefaults(config *drivers.OntapStorageDriverConfig) err
 
 // ValidateEncryptionAttribute returns true/false if encryption is being requested of a backend that
 // supports NetApp Volume Encryption, and nil otherwise so that the ZAPIs may be sent without
-// any reference to encryption.
-func ValidateEncryptionAttribute(encryption string, client *api.Client) (*bool, error) {
+// any reference to encryption.  A volume on an aggregate using NetApp Aggregate Encryption is
+// always encrypted, so encryption may be requested there without NVE, as getPoolEncryptionOffers
+// advertises.
+func ValidateEncryptionAttribute(
+	encryption, aggregate string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) (*bool, error) {
 
 	enableEncryption, err := strconv.ParseBool(encryption)
 	if err != nil {
-		return nil, fmt.Errorf("invalid boolean value for encryption: %v", err)
+		return nil, drivers.NewFatalError(fmt.Sprintf("invalid boolean value for encryption: %v", err))
+	}
+
+	if enableEncryption && aggregate != "" && getEncryptedAggregates(client)[aggregate] {
+		log.WithField("aggregate", aggregate).Debug("Aggregate is encrypted, so its volumes are too.")
+		return nil, nil
 	}
 
-	if client.SupportsFeature(api.NetAppVolumeEncryption) {
+	if SupportsVersionFeature(config, api.NetAppVolumeEncryption) {
+		if enableEncryption {
+			if err := checkKeyManager(client); err != nil {
+				return nil, err
+			}
+		}
 		return &enableEncryption, nil
 	} else {
 		if enableEncryption {
-			return nil, errors.New("encrypted volumes are not supported on this storage backend")
+			return nil, drivers.NewFatalError("encrypted volumes are not supported on this storage backend")
 		} else {
 			return nil, nil
 		}
 	}
 }
 
-func GetVolumeSize(sizeBytes uint64, config drivers.OntapStorageDriverConfig) (uint64, error) {
+// checkKeyManager returns an error if the cluster is known to lack a configured key manager.  Reading
+// the key manager state requires cluster scope, so if that isn't possible ONTAP has the final say.
+func checkKeyManager(client api.ZapiClient) error {
+
+	configured, err := client.KeyManagerConfigured()
+	if err != nil {
+		log.Debugf("Could not determine key manager state. %v", err)
+		return nil
+	}
+	if !configured {
+		return drivers.NewFatalError("encrypted volumes require a key manager, but none is configured on this cluster; " +
+			"run 'security key-manager onboard enable' (or 'security key-manager setup' on older releases) " +
+			"to enable onboard key management, or add an external key manager")
+	}
+	return nil
+}
+
+// getPoolEncryptionOffers determines whether each pool can provide encrypted volumes.  Volumes
+// may be encrypted individually (NVE) if ONTAP supports it and a key manager is configured, and
+// volumes on aggregates using aggregate encryption (NAE) are always encrypted.
+func getPoolEncryptionOffers(
+	config *drivers.OntapStorageDriverConfig, client api.ZapiClient, pools map[string]*storage.Pool,
+) map[string]sa.Offer {
+
+	// Check as ValidateEncryptionAttribute does, so that pools offer only what creating volumes allows
+	nveCapable := SupportsVersionFeature(config, api.NetAppVolumeEncryption)
+	if nveCapable && checkKeyManager(client) != nil {
+		log.Warning("No key manager is configured, so this backend cannot provide NVE encrypted volumes.")
+		nveCapable = false
+	}
+
+	naeAggregates := getEncryptedAggregates(client)
+
+	offers := make(map[string]sa.Offer)
+	for poolName := range pools {
+		encrypted := nveCapable || naeAggregates[poolName]
+		offers[poolName] = sa.NewBoolOffer(encrypted)
+
+		log.WithFields(log.Fields{
+			"pool":       poolName,
+			"nve":        nveCapable,
+			"nae":        naeAggregates[poolName],
+			"encryption": encrypted,
+		}).Debug("Determined pool encryption capability.")
+	}
+	return offers
+}
+
+// getEncryptedAggregates returns which aggregates use NetApp Aggregate Encryption.  Reading the
+// aggregates' state requires cluster scope, so if that isn't possible none are assumed to.
+func getEncryptedAggregates(client api.ZapiClient) map[string]bool {
+
+	naeAggregates, err := client.AggrEncryptionStatus()
+	if err != nil {
+		log.Debugf("Could not determine aggregate encryption state. %v", err)
+		return make(map[string]bool)
+	}
+	return naeAggregates
+}
+
+// GetVolumeSize returns the size of a new volume, applying the backend's default size if none was
+// requested and rounding it up to the backend's unit, and checks it against the ONTAP minimum and
+// the backend's size limits.
+func GetVolumeSize(sizeBytes uint64, config drivers.OntapStorageDriverConfig) (uint64, error) {
+
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, config.CommonStorageDriverConfig)
+	if sizeBytes < MinimumVolumeSizeBytes {
+		return 0, drivers.NewFatalError(fmt.Sprintf("requested volume size (%d bytes) is too small; "+
+			"the minimum volume size is %d bytes", sizeBytes, MinimumVolumeSizeBytes))
+	}
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
+// ApplyAdvancedOptions sets each of the options listed in the backend config's advancedOptions on the
+// named Flexvol.  This allows ONTAP tunables that Trident doesn't model to be set on new volumes
+// and clones.  The Flexvol already exists, so unless ONTAP rejects an option outright, a failure is
+// returned as a RetryableError, and the retried create sets the options again before completing.
+func ApplyAdvancedOptions(name string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) error {
+
+	// Apply options in a predictable order
+	options := make([]string, 0, len(config.AdvancedOptions))
+	for option := range config.AdvancedOptions {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+
+	for _, option := range options {
+		value := config.AdvancedOptions[option]
+
+		log.WithFields(log.Fields{
+			"volume": name,
+			"option": option,
+			"value":  value,
+		}).Debug("Setting volume option.")
+
+		optionResponse, err := client.VolumeSetOption(name, option, value)
+		if err = api.GetError(optionResponse, err); err != nil {
+			err = classifyError(err, fmt.Sprintf("error setting option %s=%s on volume %s", option, value, name))
+			if drivers.IsFatalError(err) {
+				return err
+			}
+			return drivers.NewRetryableError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// qosMaxThroughput returns the throughput ceiling of a QoS policy group for the limits given by a
+// storage class, such as "1000iops" or "100MB/s,1000iops", or an empty string if none were given.
+func qosMaxThroughput(opts map[string]string) string {
+	limits := make([]string, 0, 2)
+	if maxMBps := opts["qosMaxMBps"]; maxMBps != "" {
+		limits = append(limits, maxMBps+"MB/s")
+	}
+	if maxIOPS := opts["qosMaxIOPS"]; maxIOPS != "" {
+		limits = append(limits, maxIOPS+"iops")
+	}
+	return strings.Join(limits, ",")
+}
+
+// addQosPolicyGroupOffers adds the attributes offered by drivers that assign each Flexvol to the
+// QoS policy group named by its storage class.  Any policy group may be named, since it is created
+// if it is missing and the class gives its limits.  Where ONTAP supports QoS minimums, an IOPS
+// floor is offered as well, though only pools of SSD aggregates keep it.
+func addQosPolicyGroupOffers(
+	offers map[string]sa.Offer, config *drivers.OntapStorageDriverConfig,
+) map[string]sa.Offer {
+	offers[sa.QoSPolicy] = sa.NewAnyStringOffer()
+	offers[sa.QoSMaxIOPS] = sa.NewIntOffer(1, math.MaxInt32)
+	offers[sa.QoSMaxMBps] = sa.NewIntOffer(1, math.MaxInt32)
+	if SupportsVersionFeature(config, api.QosMinThroughput) {
+		offers[sa.MinIOPS] = sa.NewIntOffer(1, math.MaxInt32)
+	}
+	return offers
+}
+
+// cachingPolicies are the policies by which ONTAP caches a volume's data in the SSDs of a Flash Pool
+// (hybrid) aggregate.  The default, auto, caches randomly read metadata and data.
+var cachingPolicies = []string{
+	"auto", "none", "random_read", "noread-random_write", "meta", "meta-random_write", "random_read_write",
+	"all_read", "all_read-random_write", "all", "all-random_write",
+}
+
+// addCachingPolicyOffers adds the caching policies offered by drivers that create a Flexvol for each
+// volume.  Only pools of hybrid aggregates keep the offer, as others have no SSD cache to tune.
+func addCachingPolicyOffers(offers map[string]sa.Offer) map[string]sa.Offer {
+	offers[sa.CachingPolicy] = sa.NewStringOffer(cachingPolicies...)
+	return offers
+}
+
+// supportsCachingPolicy reports whether a pool's volumes may be given a caching policy, which
+// requires a Flash Pool aggregate.
+func supportsCachingPolicy(pool *storage.Pool) bool {
+	media, ok := pool.Attributes[sa.Media]
+	return ok && media.Matches(sa.NewStringRequest(sa.Hybrid))
+}
+
+// ValidateCachingPolicy checks a volume's caching policy, if it has one.
+func ValidateCachingPolicy(opts map[string]string) error {
+	policy := opts["cachingPolicy"]
+	if policy == "" {
+		return nil
+	}
+	for _, cachingPolicy := range cachingPolicies {
+		if policy == cachingPolicy {
+			return nil
+		}
+	}
+	return drivers.NewFatalError(fmt.Sprintf("invalid value for %s: %s; it must be one of %s",
+		sa.CachingPolicy, policy, strings.Join(cachingPolicies, ", ")))
+}
+
+// SetCachingPolicy sets the caching policy of a Flexvol, if it has one.
+func SetCachingPolicy(name string, opts map[string]string, client api.ZapiClient) error {
+
+	policy := opts["cachingPolicy"]
+	if policy == "" {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"volume":        name,
+		"cachingPolicy": policy,
+	}).Debug("Setting volume caching policy.")
+
+	modifyResponse, err := client.VolumeSetCachingPolicy(name, policy)
+	if err = api.GetError(modifyResponse, err); err != nil {
+		return fmt.Errorf("error setting caching policy %s on volume %s: %v", policy, name, err)
+	}
+
+	return nil
+}
+
+// securityStyles are the security styles of ONTAP volumes and qtrees, which decide whether Unix
+// permissions, NTFS ACLs or either of them control access to files.
+var securityStyles = []string{"unix", "mixed", "ntfs"}
+
+var (
+	// unixPermissionsOctalRegex matches a numeric mode, such as 755 or 0770
+	unixPermissionsOctalRegex = regexp.MustCompile(`^[0-7]?[0-7]{3}$`)
+	// unixPermissionsSymbolicRegex matches a symbolic mode, such as ---rwxr-xr-x, whose optional
+	// first three characters are ONTAP's setuid, setgid and sticky bits
+	unixPermissionsSymbolicRegex = regexp.MustCompile(`^([-s][-s][-t])?([-r][-w][-x]){3}$`)
+)
+
+// ConvertUnixPermissions checks a volume's Unix permissions and returns them in ONTAP's symbolic
+// format, translating a numeric mode such as 0770 into ---rwxrwx---.
+func ConvertUnixPermissions(permissions string) (string, error) {
+
+	if unixPermissionsSymbolicRegex.MatchString(permissions) {
+		if len(permissions) == 9 {
+			return "---" + permissions, nil
+		}
+		return permissions, nil
+	}
+	if !unixPermissionsOctalRegex.MatchString(permissions) {
+		return "", drivers.NewFatalError(fmt.Sprintf("invalid value for unixPermissions: %s; it must be "+
+			"a numeric mode such as 0755 or a symbolic one such as ---rwxr-xr-x", permissions))
+	}
+	mode, _ := strconv.ParseUint(permissions, 8, 32)
+
+	symbolic := []byte("---rwxrwxrwx")
+	for i := range symbolic {
+		if mode&(1<<uint(len(symbolic)-1-i)) == 0 {
+			symbolic[i] = '-'
+		}
+	}
+	if mode&04000 != 0 {
+		symbolic[0] = 's'
+	}
+	if mode&02000 != 0 {
+		symbolic[1] = 's'
+	}
+	if mode&01000 != 0 {
+		symbolic[2] = 't'
+	}
+	return string(symbolic), nil
+}
+
+// validateSMBConfig checks the settings by which hosts reach volumes over SMB.  Only Docker hosts
+// can, as the volumes are reached through a share of the SVM's root junction rather than a share
+// of their own.
+func validateSMBConfig(config *drivers.OntapStorageDriverConfig) error {
+
+	if config.NASType != NASTypeSMB {
+		return nil
+	}
+	if config.StorageDriverName != drivers.OntapNASStorageDriverName {
+		return fmt.Errorf("nasType %s is only supported by the %s driver", NASTypeSMB,
+			drivers.OntapNASStorageDriverName)
+	}
+	if config.DriverContext != trident.ContextDocker {
+		return fmt.Errorf("nasType %s is only supported with Docker", NASTypeSMB)
+	}
+	if config.SMBShare == "" || strings.ContainsAny(config.SMBShare, `/\`) {
+		return fmt.Errorf("nasType %s needs the name of the SVM's share of its root junction in smbShare",
+			NASTypeSMB)
+	}
+	return nil
+}
+
+// SMBSharePath returns the UNC path by which a Flexvol, mounted at its name, is reached through
+// the share of the SVM's root junction.
+func SMBSharePath(name string, config *drivers.OntapStorageDriverConfig) string {
+	return fmt.Sprintf(`\\%s\%s\%s`, config.DataLIF, config.SMBShare, name)
+}
+
+// ValidateSecurityStyle checks a volume's security style.
+func ValidateSecurityStyle(securityStyle string) error {
+	for _, style := range securityStyles {
+		if securityStyle == style {
+			return nil
+		}
+	}
+	return drivers.NewFatalError(fmt.Sprintf("invalid value for securityStyle: %s; it must be one of %s",
+		securityStyle, strings.Join(securityStyles, ", ")))
+}
+
+// readOnlyExportPolicy returns the name of the export policy that grants the clients of another
+// policy read-only access.
+func readOnlyExportPolicy(policy string) string {
+	return policy + "_ro"
+}
+
+// ensureReadOnlyExportPolicy returns the name of an export policy that grants the clients of a
+// policy read-only access, creating it if it doesn't exist.  A new policy gets a copy of each of
+// the policy's rules that allows neither writes nor superuser access, so that read-only volumes
+// are reachable by the same clients as the others.
+func ensureReadOnlyExportPolicy(policy string, client api.ZapiClient) (string, error) {
+
+	readOnlyPolicy := readOnlyExportPolicy(policy)
+
+	policyResponse, err := client.ExportPolicyCreate(readOnlyPolicy)
+	if err != nil {
+		return "", fmt.Errorf("error creating export policy %s: %v", readOnlyPolicy, err)
+	}
+	if zerr := api.NewZapiError(policyResponse); !zerr.IsPassed() {
+		if zerr.Code() != azgo.EDUPLICATEENTRY {
+			return "", fmt.Errorf("error creating export policy %s: %v", readOnlyPolicy, zerr)
+		}
+		// The policy's rules were copied when it was created, and may since have been changed
+		log.WithField("exportPolicy", readOnlyPolicy).Debug("Read-only export policy already exists.")
+		return readOnlyPolicy, nil
+	}
+
+	ruleListResponse, err := client.ExportRuleGetIterRequest(policy)
+	if err = api.GetError(ruleListResponse, err); err != nil {
+		return "", fmt.Errorf("error listing rules of export policy %s: %v", policy, err)
+	}
+	for _, rule := range ruleListResponse.Result.AttributesList() {
+		protocols := make([]string, 0)
+		for _, protocol := range rule.Protocol() {
+			protocols = append(protocols, string(protocol))
+		}
+		roRule := make([]string, 0)
+		for _, flavor := range rule.RoRule() {
+			roRule = append(roRule, string(flavor))
+		}
+		ruleResponse, err := client.ExportRuleCreate(readOnlyPolicy, rule.ClientMatch(), protocols, roRule,
+			[]string{"never"}, []string{"none"})
+		if err = api.GetError(ruleResponse, err); err != nil {
+			return "", fmt.Errorf("error creating rule for %s in export policy %s: %v", rule.ClientMatch(),
+				readOnlyPolicy, err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"exportPolicy": readOnlyPolicy,
+		"rules":        ruleListResponse.Result.NumRecords(),
+	}).Info("Created read-only export policy.")
+
+	return readOnlyPolicy, nil
+}
+
+// supportsQosMinimum reports whether ONTAP can guarantee an IOPS floor to volumes in a pool, which
+// it does only on all-flash platforms.
+func supportsQosMinimum(pool *storage.Pool) bool {
+	media, ok := pool.Attributes[sa.Media]
+	return ok && media.Matches(sa.NewStringRequest(sa.SSD))
+}
+
+// volumeQosPolicyGroupName returns the name of the QoS policy group that holds a Flexvol's IOPS
+// floor.  A floor applies to each volume, so each such volume has a policy group of its own.
+// Policy group names are unique across the cluster, so the name includes the SVM.
+func volumeQosPolicyGroupName(name string, config *drivers.OntapStorageDriverConfig) string {
+	return fmt.Sprintf("%s_%s", config.SVM, name)
+}
+
+// qosPolicyGroupForVolume returns the QoS policy group a Flexvol is to be assigned to, if any.
+func qosPolicyGroupForVolume(name string, opts map[string]string, config *drivers.OntapStorageDriverConfig) string {
+	if opts["qosMinIOPS"] != "" {
+		return volumeQosPolicyGroupName(name, config)
+	}
+	return opts["qosPolicy"]
+}
+
+// EnsureQosPolicyGroup makes sure that the QoS policy group for a Flexvol exists on the SVM.  A
+// volume with an IOPS floor gets a policy group of its own; otherwise a missing policy group named
+// by the storage class is created with the limits given by the class, if it gave any, so that
+// volumes are never created without the QoS their class calls for.
+func EnsureQosPolicyGroup(
+	name string, opts map[string]string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	minThroughput := ""
+	if minIOPS := opts["qosMinIOPS"]; minIOPS != "" {
+		if opts["qosPolicy"] != "" {
+			return drivers.NewFatalError(fmt.Sprintf("%s cannot be combined with %s, as a volume may "+
+				"belong to only one QoS policy group", sa.MinIOPS, sa.QoSPolicy))
+		}
+		if !SupportsVersionFeature(config, api.QosMinThroughput) {
+			return drivers.NewFatalError(fmt.Sprintf("%s requires ONTAP 9.3 or later", sa.MinIOPS))
+		}
+		minThroughput = minIOPS + "iops"
+	}
+
+	policy := qosPolicyGroupForVolume(name, opts, config)
+	if policy == "" {
+		return nil
+	}
+
+	policyGroup, err := client.QosPolicyGroupGet(policy)
+	if err != nil {
+		return classifyError(err, "error checking for existing QoS policy group")
+	}
+	if policyGroup != nil {
+		return nil
+	}
+
+	maxThroughput := qosMaxThroughput(opts)
+	if minThroughput == "" && maxThroughput == "" {
+		return drivers.NewFatalError(fmt.Sprintf("QoS policy group %s does not exist; specify %s or %s "+
+			"in the storage class for it to be created", policy, sa.QoSMaxIOPS, sa.QoSMaxMBps))
+	}
+
+	log.WithFields(log.Fields{
+		"policyGroup":   policy,
+		"minThroughput": minThroughput,
+		"maxThroughput": maxThroughput,
+	}).Info("Creating QoS policy group.")
+
+	createResponse, err := client.QosPolicyGroupCreate(policy, minThroughput, maxThroughput)
+	if err = api.GetError(createResponse, err); err != nil {
+		// Another volume of the same class may have created it first
+		if zerr, ok := err.(api.ZapiError); ok && zerr.Code() == azgo.EDUPLICATEENTRY {
+			return nil
+		}
+		return classifyError(err, fmt.Sprintf("error creating QoS policy group %s", policy))
+	}
+
+	return nil
+}
+
+// SetQosPolicyGroup assigns a Flexvol to its QoS policy group, if it has one.
+func SetQosPolicyGroup(
+	name string, opts map[string]string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	policy := qosPolicyGroupForVolume(name, opts, config)
+	if policy == "" {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"volume":      name,
+		"policyGroup": policy,
+	}).Debug("Setting volume QoS policy group.")
+
+	modifyResponse, err := client.VolumeSetQosPolicyGroupName(name, policy)
+	if err = api.GetError(modifyResponse, err); err != nil {
+		return fmt.Errorf("error setting QoS policy group %s on volume %s: %v", policy, name, err)
+	}
+
+	return nil
+}
+
+// DeleteVolumeQosPolicyGroup deletes the QoS policy group that held a destroyed Flexvol's IOPS
+// floor, if it had one.  Failures are only logged, as the volume is already gone.
+func DeleteVolumeQosPolicyGroup(name string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) {
+
+	policy := volumeQosPolicyGroupName(name, config)
+
+	deleteResponse, err := client.QosPolicyGroupDelete(policy)
+	if err = api.GetError(deleteResponse, err); err != nil {
+		if zerr, ok := err.(api.ZapiError); ok && zerr.Code() == azgo.EOBJECTNOTFOUND {
+			return
+		}
+		log.WithField("policyGroup", policy).Warnf("Could not delete the volume's QoS policy group. %v", err)
+		return
+	}
+
+	log.WithField("policyGroup", policy).Debug("Deleted volume QoS policy group.")
+}
+
+// ReleaseQosPolicyGroup deletes a QoS policy group that Trident manages once no Flexvols of this
+// backend are assigned to it.  The volume has already been destroyed, so failures are only logged;
+// ONTAP refuses to delete a policy group that is still used outside Trident, which is left alone.
+func ReleaseQosPolicyGroup(policy string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) {
+
+	if policy == "" {
+		return
+	}
+
+	logFields := log.Fields{"policyGroup": policy}
+
+	count := 0
+	for _, prefix := range storagePrefixes(config) {
+		prefixCount, err := client.VolumeCountByQosPolicyGroup(prefix, policy)
+		if err != nil {
+			log.WithFields(logFields).Warnf("Could not count the volumes using the QoS policy group. %v", err)
+			return
+		}
+		count += prefixCount
+	}
+	if count > 0 {
+		log.WithFields(logFields).WithField("volumes", count).Debug("QoS policy group is still in use.")
+		return
+	}
+
+	deleteResponse, err := client.QosPolicyGroupDelete(policy)
+	if err = api.GetError(deleteResponse, err); err != nil {
+		if zerr, ok := err.(api.ZapiError); ok && zerr.Code() == azgo.EOBJECTNOTFOUND {
+			log.WithFields(logFields).Debug("QoS policy group already deleted.")
+			return
+		}
+		log.WithFields(logFields).Warnf("Could not delete the unused QoS policy group. %v", err)
+		return
+	}
+
+	log.WithFields(logFields).Info("Deleted unused QoS policy group.")
+}
+
+// volumeLimit is a limit on the number of Flexvols that a scope, the SVM or one of its aggregates,
+// may hold, along with the number it holds now.
+type volumeLimit struct {
+	scope   string // what is limited, such as "SVM svm0"
+	setting string // the setting that imposes the limit
+	max     int
+	count   int
+}
+
+// headroom returns the number of volumes that may still be created within the limit.
+func (l volumeLimit) headroom() int {
+	if l.count >= l.max {
+		return 0
+	}
+	return l.max - l.count
+}
+
+// GetVolumeHeadroom returns the number of volumes that may still be created in the aggregate
+// before any limit is reached, whether the SVM's max-volumes or a Flexvol limit in the backend
+// config, or -1 if no limit applies.  An empty aggregate ignores the per-aggregate limit.
+func GetVolumeHeadroom(aggregate string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) (
+	int, error) {
+
+	limits, err := getSVMVolumeLimits(config, client)
+	if err != nil {
+		return 0, err
+	}
+	if aggregateLimit, err := getAggregateVolumeLimit(aggregate, config, client); err != nil {
+		return 0, err
+	} else if aggregateLimit != nil {
+		limits = append(limits, *aggregateLimit)
+	}
+	return leastHeadroom(limits), nil
+}
+
+// leastHeadroom returns the least headroom of the limits, or -1 if there are none.
+func leastHeadroom(limits []volumeLimit) int {
+	headroom := -1
+	for _, limit := range limits {
+		if headroom < 0 || limit.headroom() < headroom {
+			headroom = limit.headroom()
+		}
+	}
+	return headroom
+}
+
+// CheckVolumeLimit returns an error if creating the named volume in the aggregate would exceed
+// the SVM's max-volumes limit or a Flexvol limit in the backend config, and warns if it brings
+// the count near one.  If a limit cannot be determined, the check is skipped and ONTAP has the
+// final say.
+func CheckVolumeLimit(
+	name, aggregate string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	limits, err := getSVMVolumeLimits(config, client)
+	if err != nil {
+		log.WithField("volume", name).Warnf("Skipping SVM volume limit check. %v", err)
+	}
+	if aggregateLimit, err := getAggregateVolumeLimit(aggregate, config, client); err != nil {
+		log.WithField("volume", name).Warnf("Skipping aggregate volume limit check. %v", err)
+	} else if aggregateLimit != nil {
+		limits = append(limits, *aggregateLimit)
+	}
+
+	for _, limit := range limits {
+		if limit.headroom() == 0 {
+			return drivers.NewFatalError(fmt.Sprintf(
+				"cannot create volume %s; %s already has %d volumes and its limit (%s) is %d",
+				name, limit.scope, limit.count, limit.setting, limit.max))
+		}
+	}
+	for _, limit := range limits {
+		limit.count++
+		warnNearVolumeLimit(limit, config)
+	}
+	return nil
+}
+
+// warnNearVolumeLimit logs a warning if a Flexvol count has reached the configured percentage of
+// its limit, so that administrators can act before Trident, or ONTAP, refuses new volumes.
+func warnNearVolumeLimit(limit volumeLimit, config *drivers.OntapStorageDriverConfig) {
+
+	warningPercent := config.FlexvolLimitWarningPercent
+	if warningPercent == 0 {
+		warningPercent = DefaultFlexvolLimitWarningPercent
+	}
+	if limit.count*100 >= limit.max*warningPercent {
+		log.WithFields(log.Fields{
+			"scope":   limit.scope,
+			"setting": limit.setting,
+			"limit":   limit.max,
+			"volumes": limit.count,
+		}).Warnf("%s is approaching its volume limit.", limit.scope)
+	}
+}
+
+// getSVMVolumeLimits returns the limits on the SVM's volume count: its max-volumes, and the
+// backend's limitFlexvolsPerSVM.
+func getSVMVolumeLimits(config *drivers.OntapStorageDriverConfig, client api.ZapiClient) ([]volumeLimit, error) {
+
+	limits := make([]volumeLimit, 0)
+
+	maxVolumes, err := client.VserverGetMaxVolumes()
+	if err != nil {
+		return nil, fmt.Errorf("could not read SVM volume limit: %v", err)
+	}
+	if maxVolumes == 0 && config.LimitFlexvolsPerSVM == 0 {
+		return limits, nil
+	}
+
+	volumeCount, err := client.VolumeCount()
+	if err != nil {
+		return nil, fmt.Errorf("could not count SVM volumes: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"maxVolumes":          maxVolumes,
+		"limitFlexvolsPerSVM": config.LimitFlexvolsPerSVM,
+		"volumeCount":         volumeCount,
+	}).Debug("Read SVM volume limit.")
+
+	scope := "SVM " + config.SVM
+	if maxVolumes > 0 {
+		limits = append(limits, volumeLimit{scope: scope, setting: "max-volumes", max: maxVolumes, count: volumeCount})
+	}
+	if config.LimitFlexvolsPerSVM > 0 {
+		limits = append(limits, volumeLimit{
+			scope: scope, setting: "limitFlexvolsPerSVM", max: config.LimitFlexvolsPerSVM, count: volumeCount,
+		})
+	}
+	return limits, nil
+}
+
+// getAggregateVolumeLimit returns the backend's limitFlexvolsPerAggregate as it applies to the
+// aggregate, or nil if there is no such limit or no aggregate.
+func getAggregateVolumeLimit(
+	aggregate string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) (*volumeLimit, error) {
+
+	if aggregate == "" || config.LimitFlexvolsPerAggregate == 0 {
+		return nil, nil
+	}
+
+	volumeCount, err := client.VolumeCountByAggregate(aggregate)
+	if err != nil {
+		return nil, fmt.Errorf("could not count volumes in aggregate %s: %v", aggregate, err)
+	}
+
+	return &volumeLimit{
+		scope:   "aggregate " + aggregate,
+		setting: "limitFlexvolsPerAggregate",
+		max:     config.LimitFlexvolsPerAggregate,
+		count:   volumeCount,
+	}, nil
+}
+
+// populateEMSDefaults validates the settings of the backend's EMS heartbeat messages, so that
+// they may be matched by the cluster's EMS filters and routed to its notification destinations.
+func populateEMSDefaults(config *drivers.OntapStorageDriverConfig) error {
+
+	if config.EMSSeverity == "" {
+		config.EMSSeverity = DefaultEMSSeverity
+	}
+	logLevel, ok := emsLogLevels[strings.ToLower(config.EMSSeverity)]
+	if !ok {
+		return fmt.Errorf("invalid value for emsSeverity: %s", config.EMSSeverity)
+	}
+	config.EMSLogLevel = logLevel
+
+	if config.EMSEventSource == "" {
+		config.EMSEventSource = trident.OrchestratorName
+	}
+
+	if config.EMSAutoSupport == "" {
+		config.EMSAutoSupport = DefaultEMSAutoSupport
+	}
+	autoSupport, err := strconv.ParseBool(config.EMSAutoSupport)
+	if err != nil {
+		return fmt.Errorf("invalid boolean value for emsAutoSupport: %v", err)
+	}
+	config.EMSAutoSupportEnabled = autoSupport
+
+	return nil
+}
+
+// SendHeartbeat sends the driver's usage heartbeat by the backend's telemetry transport.  EMS
+// messages can be viewed via filer::> event log show -severity <emsSeverity>.
+func SendHeartbeat(driver StorageDriver) {
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warnf("Could not determine hostname. %v", err)
+		hostname = "unknown"
+	}
+
+	message, _ := json.Marshal(driver.GetTelemetry())
+
+	config := driver.GetConfig()
+	emsAvailable := IsFeatureAvailable(config, FeatureEMS)
+
+	switch config.TelemetryTransport {
+	case TelemetryTransportHTTPS:
+		err = sendHTTPSHeartbeat(driver, message, hostname)
+	case TelemetryTransportAuto:
+		if !emsAvailable {
+			err = sendHTTPSHeartbeat(driver, message, hostname)
+		} else if err = EMSHeartbeat(driver, message, hostname); err != nil {
+			log.WithFields(log.Fields{
+				"driver": driver.Name(),
+				"error":  err,
+			}).Warning("Could not log EMS message, posting heartbeat to ActiveIQ instead.")
+			err = sendHTTPSHeartbeat(driver, message, hostname)
+		}
+	default:
+		if !emsAvailable {
+			log.WithField("driver", driver.Name()).Debug("User may not log EMS messages, skipping heartbeat.")
+			return
+		}
+		err = EMSHeartbeat(driver, message, hostname)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"driver": driver.Name(),
+			"error":  err,
+		}).Error("Error sending heartbeat.")
+	}
+}
+
+// EMSHeartbeat logs a heartbeat message to the cluster's EMS as an ASUP message.
+func EMSHeartbeat(driver StorageDriver, message []byte, hostname string) error {
+
+	config := driver.GetConfig()
+	emsResponse, err := driver.GetAPI().EmsAutosupportLog(
+		strconv.Itoa(drivers.ConfigVersion), config.EMSAutoSupportEnabled, "heartbeat", hostname,
+		string(message), 1, config.EMSEventSource, config.EMSLogLevel)
+
+	if err = api.GetError(emsResponse, err); err != nil {
+		return fmt.Errorf("could not log EMS message: %v", err)
+	}
+	log.WithField("driver", driver.Name()).Info("Logged EMS message.")
+	return nil
+}
+
+// LogAuditEvent logs an EMS message announcing that a Trident operation is about to change a
+// volume, with the operation's correlation ID, so that the changes in ONTAP's audit log can be
+// matched to the operation and whoever requested it.  Nothing is logged if the operation has no
+// correlation ID or the user may not log EMS messages, and failures don't stop the operation.
+func LogAuditEvent(ctx context.Context, driver StorageDriver, name string) {
+
+	audit := utils.GetAuditInfo(ctx)
+	config := driver.GetConfig()
+	if audit == nil || !IsFeatureAvailable(config, FeatureEMS) {
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	emsResponse, err := driver.GetAPI().WithContext(ctx).EmsAutosupportLog(
+		strconv.Itoa(drivers.ConfigVersion), false, "audit", hostname,
+		fmt.Sprintf("%s %s, ONTAP volume %s", trident.OrchestratorName, audit, name),
+		1, config.EMSEventSource, config.EMSLogLevel)
+
+	if err = api.GetError(emsResponse, err); err != nil {
+		log.WithFields(audit.Fields()).Warnf("Could not log EMS audit message. %v", err)
+	}
+}
+
+const MSecPerHour = 1000 * 60 * 60 // millis * seconds * minutes
+
+// Journaled operations and the steps within them
+const (
+	JournalOperationClone     = "clone"
+	JournalOperationCopyClone = "copy-clone"
+
+	JournalStepSnapshot = "snapshot"
+	JournalStepClone    = "clone"
+	JournalStepMount    = "mount"
+)
+
+// Create a volume clone.  Each step is recorded in the supplied journal, which may be nil, so that
+// the clone can be completed or cleaned up if Trident stops partway through.
+func CreateOntapClone(
+	name, source, snapshot string, split bool, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+	journal drivers.Journal,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":   "CreateOntapClone",
+			"Type":     "ontap_common",
+			"name":     name,
+			"source":   source,
+			"snapshot": snapshot,
+			"split":    split,
+		}
+		log.WithFields(fields).Debug(">>>> CreateOntapClone")
+		defer log.WithFields(fields).Debug("<<<< CreateOntapClone")
+	}
+
+	// Without FlexClone, the clone ZAPI would fail with an obscure error
+	if !IsLicensed(config, LicenseFlexClone) {
+		return drivers.NewUnsupportedError(fmt.Sprintf(
+			"cannot clone volume %s; FlexClone is not licensed on SVM %s", source, config.SVM))
+	}
+
+	// If the clone already exists, it may have been left behind by an earlier attempt that failed
+	// partway through, in which case only the remaining steps need to be completed.
+	volExists, err := client.VolumeExists(name)
+	if err != nil {
+		return classifyError(err, "error checking for existing volume")
+	}
+	if volExists {
+		return resumeOntapClone(name, source, split, config, client)
+	}
+
+	// A clone lives in its source's aggregate, which matters only if that aggregate has a limit
+	aggregate := ""
+	if config.LimitFlexvolsPerAggregate > 0 {
+		sourceAttrs, err := client.VolumeGet(source)
+		if err != nil {
+			return classifyError(err, "error reading source volume")
+		}
+		if sourceAttrs.VolumeIdAttributesPtr != nil {
+			aggregate = sourceAttrs.VolumeIdAttributesPtr.ContainingAggregateName()
+		}
+	}
+
+	if err = CheckVolumeLimit(name, aggregate, config, client); err != nil {
+		return err
+	}
+
+	// Journal the clone before creating anything, so a snapshot is never created unrecorded
+	createSnapshot := snapshot == ""
+	if createSnapshot {
+		snapshot = cloneSnapshotName(name)
+	}
+	journalEntry := drivers.NewJournalEntry(JournalOperationClone, name, map[string]string{
+		"source":         source,
+		"snapshot":       snapshot,
+		"createSnapshot": strconv.FormatBool(createSnapshot),
+		"split":          strconv.FormatBool(split),
+	})
+	recordJournalStep(journal, journalEntry, "")
+
+	// If no specific snapshot was requested, create one.  Its name is derived from the clone's
+	// name, so a retried clone reuses the snapshot rather than leaving an extra one behind.
+	if createSnapshot {
+		snapExists, err := snapshotExists(snapshot, source, client)
+		if err != nil {
+			return classifyError(err, "error checking for existing snapshot")
+		}
+		if snapExists {
+			log.WithFields(log.Fields{
+				"snapshot": snapshot,
+				"source":   source,
+			}).Debug("Reusing snapshot from an earlier clone attempt.")
+		} else {
+			err = retryWhileBusy(client.Context(), "snapshot creation", func() error {
+				snapResponse, err := client.SnapshotCreate(snapshot, source)
+				if err = api.GetError(snapResponse, err); err != nil {
+					return classifyError(err, "error creating snapshot")
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			drivers.ReportProgress(client.Context(), "CloneSnapshotCreated", fmt.Sprintf(
+				"Created snapshot %s of volume %s for the clone.", snapshot, source))
+		}
+		recordJournalStep(journal, journalEntry, JournalStepSnapshot)
+	}
+
+	// Create the clone based on a snapshot, waiting out other clones of the same parent
+	err = retryWhileBusy(client.Context(), "clone creation", func() error {
+		cloneResponse, err := client.VolumeCloneCreate(name, source, snapshot)
+		if err != nil {
+			return classifyError(err, "error creating clone")
+		}
+		if zerr := api.NewZapiError(cloneResponse); !zerr.IsPassed() {
+			if zerr.Code() == azgo.EOBJECTNOTFOUND {
+				return drivers.NewFatalError(fmt.Sprintf("snapshot %s does not exist in volume %s", snapshot,
+					source))
+			} else {
+				return classifyError(zerr, "error creating clone")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	recordJournalStep(journal, journalEntry, JournalStepClone)
+	drivers.ReportProgress(client.Context(), "CloneCreated", fmt.Sprintf(
+		"Created clone %s from snapshot %s of volume %s.", name, snapshot, source))
+
+	// A clone starts with its source's comment, which names the source's operation and owner
+	if comment := api.VolumeComment(client.Context()); comment != "" {
+		commentResponse, err := client.VolumeSetComment(name, comment)
+		if err = api.GetError(commentResponse, err); err != nil {
+			log.WithField("volume", name).Warnf("Could not record the clone's operation and owner. %v", err)
+		}
+	}
+
+	// Apply any ONTAP options from the backend config that Trident doesn't model
+	if err = ApplyAdvancedOptions(name, config, client); err != nil {
+		return err
+	}
+
+	if config.StorageDriverName == drivers.OntapNASStorageDriverName {
+		// Mount the new volume
+		mountResponse, err := client.VolumeMount(name, "/"+name)
+		if err = api.GetError(mountResponse, err); err != nil {
+			return classifyError(err, "error mounting volume to junction")
+		}
+		recordJournalStep(journal, journalEntry, JournalStepMount)
+	}
+
+	// Split the clone if requested
+	if split {
+		splitResponse, err := client.VolumeCloneSplitStart(name)
+		if err = api.GetError(splitResponse, err); err != nil {
+			return classifyError(err, "error splitting clone")
+		}
+		drivers.ReportProgress(client.Context(), "CloneSplitStarted", fmt.Sprintf(
+			"Started splitting clone %s from volume %s; the split continues in the background.",
+			name, source))
+	}
+
+	// The clone is complete, so there is nothing left to reconcile.  A failed operation leaves its
+	// entry behind, to be finished by a retry or cleaned up when Trident next starts.
+	if journal != nil {
+		if err = journal.Remove(journalEntry); err != nil {
+			log.WithField("volume", name).Warnf("Could not remove clone journal entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// recordJournalStep saves a journal entry, after adding a completed step if one is specified.  A
+// journal that cannot be written mustn't fail the operation being journaled, so errors are logged.
+func recordJournalStep(journal drivers.Journal, entry *drivers.JournalEntry, step string) {
+	if journal == nil {
+		return
+	}
+	var err error
+	if step == "" {
+		err = journal.Record(entry)
+	} else {
+		err = drivers.RecordStep(journal, entry, step)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"volume":    entry.Volume,
+			"operation": entry.Operation,
+			"step":      step,
+		}).Warnf("Could not journal operation: %v", err)
+	}
+}
+
+// ReconcileOntapJournalEntry completes or cleans up after an ONTAP operation that was interrupted
+// when Trident stopped.  A clone whose Flexvol exists is rolled forward; otherwise any snapshot
+// Trident created for it is deleted.
+func ReconcileOntapJournalEntry(
+	entry *drivers.JournalEntry, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "ReconcileOntapJournalEntry",
+			"Type":      "ontap_common",
+			"volume":    entry.Volume,
+			"operation": entry.Operation,
+			"steps":     entry.Steps,
+		}
+		log.WithFields(fields).Debug(">>>> ReconcileOntapJournalEntry")
+		defer log.WithFields(fields).Debug("<<<< ReconcileOntapJournalEntry")
+	}
+
+	switch entry.Operation {
+	case JournalOperationClone:
+		source := entry.Params["source"]
+		snapshot := entry.Params["snapshot"]
+
+		volExists, err := client.VolumeExists(entry.Volume)
+		if err != nil {
+			return fmt.Errorf("error checking for existing volume: %v", err)
+		}
+		if volExists {
+			split, _ := strconv.ParseBool(entry.Params["split"])
+			log.WithField("volume", entry.Volume).Info("Completing interrupted clone.")
+			return resumeOntapClone(entry.Volume, source, split, config, client)
+		}
+
+		if entry.Params["createSnapshot"] != "true" {
+			return nil
+		}
+		snapExists, err := snapshotExists(snapshot, source, client)
+		if err != nil {
+			return fmt.Errorf("error checking for clone snapshot: %v", err)
+		}
+		if snapExists {
+			log.WithFields(log.Fields{
+				"volume":   entry.Volume,
+				"source":   source,
+				"snapshot": snapshot,
+			}).Info("Deleting snapshot left by interrupted clone.")
+			snapResponse, err := client.SnapshotDelete(snapshot, source)
+			if err = api.GetError(snapResponse, err); err != nil {
+				return fmt.Errorf("error deleting snapshot %s: %v", snapshot, err)
+			}
+		}
+		return nil
+
+	default:
+		log.WithFields(log.Fields{
+			"volume":    entry.Volume,
+			"operation": entry.Operation,
+		}).Warn("Unknown journaled operation, ignoring.")
+		return nil
+	}
+}
+
+// resumeOntapClone completes a clone whose Flexvol already exists.  An existing volume that is not
+// a clone of the requested source is a genuine name conflict.  Once a split finishes, a clone no
+// longer records its parent, so if a split was requested, a standalone volume is taken to be the
+// product of an earlier attempt.
+func resumeOntapClone(
+	name, source string, split bool, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	volAttrs, err := client.VolumeGet(name)
+	if err != nil {
+		return classifyError(err, "error reading existing volume")
+	}
+
+	isClone := false
+	cloneAttrs := volAttrs.VolumeCloneAttributesPtr
+	if cloneAttrs != nil && cloneAttrs.VolumeCloneParentAttributesPtr != nil {
+		parentAttrs := cloneAttrs.VolumeCloneParentAttributes()
+		if string(parentAttrs.Name()) != source {
+			return fmt.Errorf("volume %s already exists", name)
+		}
+		isClone = true
+	}
+
+	if !isClone && !split {
+		return fmt.Errorf("volume %s already exists", name)
+	}
+
+	junctionPath := getJunctionPath(volAttrs)
+
+	log.WithFields(log.Fields{
+		"name":     name,
+		"source":   source,
+		"isClone":  isClone,
+		"junction": junctionPath,
+	}).Info("Clone already exists, completing any remaining steps.")
+
+	if err = ApplyAdvancedOptions(name, config, client); err != nil {
+		return err
+	}
+
+	if config.StorageDriverName == drivers.OntapNASStorageDriverName && junctionPath == "" {
+		mountResponse, err := client.VolumeMount(name, "/"+name)
+		if err = api.GetError(mountResponse, err); err != nil {
+			return classifyError(err, "error mounting volume to junction")
+		}
+	}
+
+	// A split that is already underway (or done) needn't be started again
+	if split && isClone {
+		splitResponse, err := client.VolumeCloneSplitStart(name)
+		if err = api.GetError(splitResponse, err); err != nil {
+			if zerr, ok := err.(api.ZapiError); ok && strings.Contains(strings.ToLower(zerr.Reason()), "split") {
+				log.WithField("volume", name).Debugf("Clone split not restarted: %v", zerr)
+			} else {
+				return classifyError(err, "error splitting clone")
+			}
+		}
+	}
+
+	return nil
+}
+
+// cloneSnapshotName returns the name of the snapshot Trident creates when asked to clone a volume
+// without naming a snapshot.  The name acts as an operation token, since it depends only on the clone.
+func cloneSnapshotName(cloneName string) string {
+	return cloneSnapshotPrefix + cloneName
+}
+
+const (
+	cloneSnapshotPrefix = "trident_clone_"
+
+	// Prefixes identifying the kinds of leftover objects reported by ListOrphanedObjects
+	orphanSnapshotPrefix = storage.OrphanedSnapshotPrefix
+	orphanFlexvolPrefix  = "flexvol:"
+)
+
+// ListOrphanedCloneSnapshots returns the snapshots Trident created for cloning whose clones are
+// no longer known to Trident, in the form snapshot:<volume>@<snapshot>.
+func ListOrphanedCloneSnapshots(
+	knownVolumes map[string]bool, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) ([]string, error) {
+
+	orphans := make([]string, 0)
+	for _, prefix := range storagePrefixes(config) {
+		snapResponse, err := client.SnapshotList(cloneSnapshotPrefix+"*", prefix+"*")
+		if err = api.GetError(snapResponse, err); err != nil {
+			return nil, fmt.Errorf("error listing clone snapshots: %v", err)
+		}
+		for _, snap := range snapResponse.Result.AttributesList() {
+			clone := strings.TrimPrefix(snap.Name(), cloneSnapshotPrefix)
+			if !knownVolumes[clone] {
+				orphans = append(orphans, fmt.Sprintf("%s%s@%s", orphanSnapshotPrefix, snap.Volume(), snap.Name()))
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// RetainOntapVolume carries out an on-delete behavior other than deletion, leaving the Flexvol on
+// ONTAP for an administrator once Trident no longer manages it.  For OnDeleteOffline, the Flexvol
+// is also taken offline, after being unmounted from the SVM namespace if requested, so that it
+// can't be used or mistaken for a managed volume.  Retaining a Flexvol that no longer exists, or
+// taking one offline that already is, succeeds.
+func RetainOntapVolume(name, onDelete string, unmount bool, client api.ZapiClient) error {
+
+	volExists, err := client.VolumeExists(name)
+	if err != nil {
+		return classifyError(err, "error checking for existing volume")
+	}
+	if !volExists {
+		log.WithField("volume", name).Warn("Volume already deleted.")
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"volume":   name,
+		"onDelete": onDelete,
+	}).Info("Retaining volume on ONTAP.")
+
+	if onDelete != drivers.OnDeleteOffline {
+		return nil
+	}
+
+	volume, err := client.VolumeGet(name)
+	if err != nil {
+		return fmt.Errorf("error reading volume %s: %v", name, err)
+	}
+	if volume.VolumeStateAttributesPtr != nil && volume.VolumeStateAttributesPtr.StatePtr != nil &&
+		volume.VolumeStateAttributesPtr.State() == "offline" {
+		log.WithField("volume", name).Debug("Volume already offline.")
+		return nil
+	}
+
+	if unmount {
+		unmountResponse, err := client.VolumeUnmount(name, true)
+		if err = api.GetError(unmountResponse, err); err != nil {
+			return fmt.Errorf("error unmounting volume %s: %v", name, err)
+		}
+	}
+	offlineResponse, err := client.VolumeOffline(name)
+	if err = api.GetError(offlineResponse, err); err != nil {
+		return fmt.Errorf("error taking volume %s offline: %v", name, err)
+	}
+	return nil
+}
+
+// DeleteOrphanedOntapObject deletes an object reported by ListOrphanedCloneSnapshots or by a
+// driver's ListOrphanedObjects.
+func DeleteOrphanedOntapObject(object string, client api.ZapiClient) error {
+
+	switch {
+	case strings.HasPrefix(object, orphanSnapshotPrefix):
+		parts := strings.SplitN(strings.TrimPrefix(object, orphanSnapshotPrefix), "@", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid snapshot identifier %s", object)
+		}
+		snapResponse, err := client.SnapshotDelete(parts[1], parts[0])
+		if err = api.GetError(snapResponse, err); err != nil {
+			return fmt.Errorf("error deleting snapshot %s: %v", object, err)
+		}
+		return nil
+
+	case strings.HasPrefix(object, orphanFlexvolPrefix):
+		name := strings.TrimPrefix(object, orphanFlexvolPrefix)
+		volDestroyResponse, err := client.VolumeDestroy(name, true)
+		if err = api.GetError(volDestroyResponse, err); err != nil {
+			return fmt.Errorf("error destroying volume %s: %v", name, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown orphaned object %s", object)
+	}
+}
+
+// GetOntapOwnership returns the owner recorded in the comment of a Flexvol, named by itself or by an
+// identifier from ListOrphanedObjects, along with when the Flexvol was created.
+func GetOntapOwnership(name string, client api.ZapiClient) (string, time.Time, error) {
+
+	volAttrs, err := client.VolumeGet(strings.TrimPrefix(name, orphanFlexvolPrefix))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if volAttrs.VolumeIdAttributesPtr == nil {
+		return "", time.Time{}, fmt.Errorf("could not read the attributes of volume %s", name)
+	}
+	idAttrs := volAttrs.VolumeIdAttributesPtr
+	return utils.ParseOwnerMarker(idAttrs.Comment()), time.Unix(int64(idAttrs.CreationTime()), 0), nil
+}
+
+// snapshotExists returns true if the named snapshot exists on the specified volume.
+func snapshotExists(snapshot, volume string, client api.ZapiClient) (bool, error) {
+
+	snapResponse, err := client.SnapshotGetByVolume(volume)
+	if err = api.GetError(snapResponse, err); err != nil {
+		return false, err
+	}
+	for _, snap := range snapResponse.Result.AttributesList() {
+		if snap.Name() == snapshot {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getJunctionPath returns the junction path of a Flexvol, or an empty string if it isn't mounted.
+func getJunctionPath(volAttrs azgo.VolumeAttributesType) string {
+	if volAttrs.VolumeIdAttributesPtr == nil || volAttrs.VolumeIdAttributesPtr.JunctionPathPtr == nil {
+		return ""
+	}
+	return string(volAttrs.VolumeIdAttributesPtr.JunctionPath())
+}
+
+// Return the list of snapshots associated with the named volume
+func GetSnapshotList(name string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) ([]storage.Snapshot, error) {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "GetSnapshotList",
+			"Type":   "ontap_common",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> GetSnapshotList")
+		defer log.WithFields(fields).Debug("<<<< GetSnapshotList")
+	}
+
+	snapResponse, err := client.SnapshotGetByVolume(name)
+	if err = api.GetError(snapResponse, err); err != nil {
+		return nil, fmt.Errorf("error enumerating snapshots: %v", err)
+	}
+
+	log.Debugf("Returned %v snapshots.", snapResponse.Result.NumRecords())
+	snapshots := []storage.Snapshot{}
+
+	// AttributesList() returns []SnapshotInfoType
+	for _, snap := range snapResponse.Result.AttributesList() {
+
+		log.WithFields(log.Fields{
+			"name":       snap.Name(),
+			"accessTime": snap.AccessTime(),
+		}).Debug("Snapshot")
+
+		// Time format: yyyy-mm-ddThh:mm:ssZ
+		snapTime := time.Unix(int64(snap.AccessTime()), 0).UTC().Format("2006-01-02T15:04:05Z")
+
+		snapshots = append(snapshots, storage.Snapshot{snap.Name(), snapTime})
+	}
+
+	return snapshots, nil
+}
+
+// CreateSnapshot takes a snapshot of a volume and returns it as GetSnapshotList reports it
+func CreateSnapshot(
+	snapshotName, volumeName string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) (*storage.Snapshot, error) {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "CreateSnapshot",
+			"Type":         "ontap_common",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> CreateSnapshot")
+		defer log.WithFields(fields).Debug("<<<< CreateSnapshot")
+	}
+
+	snapResponse, err := client.SnapshotCreate(snapshotName, volumeName)
+	if err = api.GetError(snapResponse, err); err != nil {
+		return nil, fmt.Errorf("error creating snapshot: %v", err)
+	}
+
+	return getCreatedSnapshot(snapshotName, volumeName, config, client)
+}
+
+// CreateGroupSnapshot snapshots several volumes at the same point in time using an ONTAP
+// consistency group snapshot, and returns the snapshots in the order of the volumes
+func CreateGroupSnapshot(
+	snapshotName string, volumeNames []string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) ([]*storage.Snapshot, error) {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "CreateGroupSnapshot",
+			"Type":         "ontap_common",
+			"snapshotName": snapshotName,
+			"volumeNames":  volumeNames,
+		}
+		log.WithFields(fields).Debug(">>>> CreateGroupSnapshot")
+		defer log.WithFields(fields).Debug("<<<< CreateGroupSnapshot")
+	}
+
+	cgResponse, err := client.ConsistencyGroupSnapshot(snapshotName, volumeNames)
+	if err = api.GetError(cgResponse, err); err != nil {
+		return nil, fmt.Errorf("error creating consistency group snapshot: %v", err)
+	}
+
+	snapshots := make([]*storage.Snapshot, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		snapshot, err := getCreatedSnapshot(snapshotName, volumeName, config, client)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// getCreatedSnapshot returns a snapshot that has just been created as GetSnapshotList reports it
+func getCreatedSnapshot(
+	snapshotName, volumeName string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) (*storage.Snapshot, error) {
+
+	snapshots, err := GetSnapshotList(volumeName, config, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == snapshotName {
+			return &snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find snapshot %s of volume %s after creating it", snapshotName, volumeName)
+}
+
+// DeleteSnapshot deletes a snapshot of a volume
+func DeleteSnapshot(
+	snapshotName, volumeName string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "DeleteSnapshot",
+			"Type":         "ontap_common",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> DeleteSnapshot")
+		defer log.WithFields(fields).Debug("<<<< DeleteSnapshot")
+	}
+
+	snapResponse, err := client.SnapshotDelete(snapshotName, volumeName)
+	if err = api.GetError(snapResponse, err); err != nil {
+		return fmt.Errorf("error deleting snapshot: %v", err)
+	}
+	return nil
+}
+
+// CreateOntapReplica creates a data protection Flexvol the size of a Flexvol on another ONTAP backend,
+// and starts replicating the source into it with SnapMirror.  The SVMs must already be peered.
+func CreateOntapReplica(
+	name, sourceName string, source StorageDriver, aggregate, exportPolicy string,
+	config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "CreateOntapReplica",
+			"Type":       "ontap_common",
+			"name":       name,
+			"sourceName": sourceName,
+			"sourceSVM":  source.GetConfig().SVM,
+		}
+		log.WithFields(fields).Debug(">>>> CreateOntapReplica")
+		defer log.WithFields(fields).Debug("<<<< CreateOntapReplica")
+	}
+
+	// SnapMirror must be licensed on both clusters, or the relationship can't be created
+	for _, c := range []*drivers.OntapStorageDriverConfig{config, source.GetConfig()} {
+		if !IsLicensed(c, LicenseSnapMirror) {
+			return drivers.NewUnsupportedError(fmt.Sprintf(
+				"cannot replicate volume %s; SnapMirror is not licensed on SVM %s", sourceName, c.SVM))
+		}
+	}
+
+	sourceAttrs, err := source.GetAPI().VolumeGet(sourceName)
+	if err != nil {
+		return fmt.Errorf("error reading source volume %s: %v", sourceName, err)
+	}
+	if sourceAttrs.VolumeSpaceAttributesPtr == nil || sourceAttrs.VolumeSpaceAttributesPtr.SizePtr == nil {
+		return fmt.Errorf("could not determine size of source volume %s", sourceName)
+	}
+	size := strconv.Itoa(sourceAttrs.VolumeSpaceAttributesPtr.Size())
+
+	if err = CheckVolumeLimit(name, aggregate, config, client); err != nil {
+		return err
+	}
+
+	createResponse, err := client.VolumeCreateDataProtection(name, aggregate, size, exportPolicy)
+	if err = api.GetError(createResponse, err); err != nil {
+		return fmt.Errorf("error creating replica volume %s: %v", name, err)
+	}
+
+	sourceSVM := PeerSVMName(config, source.GetConfig().SVM)
+	smResponse, err := client.SnapmirrorCreate(sourceSVM, sourceName, config.SVM, name)
+	if err = api.GetError(smResponse, err); err != nil {
+		return fmt.Errorf("error creating snapmirror relationship from %s:%s: %v",
+			sourceSVM, sourceName, err)
+	}
+
+	initResponse, err := client.SnapmirrorInitialize(config.SVM, name)
+	if err = api.GetError(initResponse, err); err != nil {
+		return fmt.Errorf("error starting baseline transfer to %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// UpdateOntapReplica transfers any changes made to the source of a replica since the last transfer,
+// returning once the transfer is complete.  Any transfer already running, such as the baseline
+// transfer, is waited on first, as it may have started before the latest changes were made.
+func UpdateOntapReplica(
+	ctx context.Context, name string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "UpdateOntapReplica",
+			"Type":   "ontap_common",
+			"name":   name,
+		}
+		log.WithFields(fields).Debug(">>>> UpdateOntapReplica")
+		defer log.WithFields(fields).Debug("<<<< UpdateOntapReplica")
+	}
+
+	info, err := waitForOntapReplica(ctx, name, func(azgo.SnapmirrorInfoType) bool { return true }, config, client)
+	if err != nil {
+		return err
+	}
+	previousTransferEnd := 0
+	if info.LastTransferEndTimestampPtr != nil {
+		previousTransferEnd = info.LastTransferEndTimestamp()
+	}
+
+	updateResponse, err := client.SnapmirrorUpdate(config.SVM, name)
+	if err = api.GetError(updateResponse, err); err != nil {
+		return fmt.Errorf("error starting transfer to %s: %v", name, err)
+	}
+
+	_, err = waitForOntapReplica(ctx, name, func(info azgo.SnapmirrorInfoType) bool {
+		return info.LastTransferEndTimestampPtr != nil && info.LastTransferEndTimestamp() > previousTransferEnd
+	}, config, client)
+	return err
+}
+
+// waitForOntapReplica polls a replica's SnapMirror relationship until it is initialized, idle, and
+// satisfies the supplied condition, failing if a transfer fails meanwhile.
+func waitForOntapReplica(
+	ctx context.Context, name string, done func(azgo.SnapmirrorInfoType) bool,
+	config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) (azgo.SnapmirrorInfoType, error) {
+
+	ticker := time.NewTicker(ReplicaPollIntervalSecs * time.Second)
+	defer ticker.Stop()
+
+	previousTransferError := ""
+	for first := true; ; first = false {
+		info, err := client.SnapmirrorGet(config.SVM, name)
+		if err != nil {
+			return info, err
+		}
+
+		// Only an error that appears while waiting belongs to this transfer
+		transferError := ""
+		if info.LastTransferErrorPtr != nil {
+			transferError = info.LastTransferError()
+		}
+		if first {
+			previousTransferError = transferError
+		} else if transferError != "" && transferError != previousTransferError {
+			return info, fmt.Errorf("transfer to %s failed: %s", name, transferError)
+		}
+
+		if replicaIsIdle(info) && done(info) {
+			return info, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return info, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// replicaIsIdle returns whether a SnapMirror relationship has been initialized and isn't transferring
+func replicaIsIdle(info azgo.SnapmirrorInfoType) bool {
+	return info.MirrorStatePtr != nil && info.MirrorState() == "snapmirrored" &&
+		info.RelationshipStatusPtr != nil && info.RelationshipStatus() == "idle"
+}
+
+// PromoteOntapReplica stops replicating into a replica and makes it writable, removing the SnapMirror
+// relationship from both the replica and its source.
+func PromoteOntapReplica(
+	ctx context.Context, name, sourceName string, source StorageDriver,
+	config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "PromoteOntapReplica",
+			"Type":       "ontap_common",
+			"name":       name,
+			"sourceName": sourceName,
+		}
+		log.WithFields(fields).Debug(">>>> PromoteOntapReplica")
+		defer log.WithFields(fields).Debug("<<<< PromoteOntapReplica")
+	}
+
+	quiesceResponse, err := client.SnapmirrorQuiesce(config.SVM, name)
+	if err = api.GetError(quiesceResponse, err); err != nil {
+		return fmt.Errorf("error quiescing snapmirror relationship to %s: %v", name, err)
+	}
+
+	// Quiescing completes in the background, and the relationship can't be broken until it has
+	ticker := time.NewTicker(ReplicaPollIntervalSecs * time.Second)
+	defer ticker.Stop()
+	for {
+		info, err := client.SnapmirrorGet(config.SVM, name)
+		if err != nil {
+			return err
+		}
+		if info.RelationshipStatusPtr != nil && info.RelationshipStatus() == "quiesced" {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	breakResponse, err := client.SnapmirrorBreak(config.SVM, name)
+	if err = api.GetError(breakResponse, err); err != nil {
+		return fmt.Errorf("error breaking snapmirror relationship to %s: %v", name, err)
+	}
+
+	removeOntapReplication(name, sourceName, source, config, client)
+	return nil
+}
+
+// DeleteOntapReplica abandons a replica, removing its SnapMirror relationship and destroying it.
+func DeleteOntapReplica(
+	name, sourceName string, source StorageDriver, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
 
-	if sizeBytes == 0 {
-		defaultSize, _ := utils.ConvertSizeToBytes(config.Size)
-		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
-	}
-	if sizeBytes < MinimumVolumeSizeBytes {
-		return 0, fmt.Errorf("requested volume size (%d bytes) is too small; "+
-			"the minimum volume size is %d bytes", sizeBytes, MinimumVolumeSizeBytes)
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "DeleteOntapReplica",
+			"Type":       "ontap_common",
+			"name":       name,
+			"sourceName": sourceName,
+		}
+		log.WithFields(fields).Debug(">>>> DeleteOntapReplica")
+		defer log.WithFields(fields).Debug("<<<< DeleteOntapReplica")
 	}
-	return sizeBytes, nil
-}
 
-// EMSHeartbeat logs an ASUP message on a timer
-// view them via filer::> event log show -severity NOTICE
-func EMSHeartbeat(driver StorageDriver) {
+	removeOntapReplication(name, sourceName, source, config, client)
 
-	// log an informational message on a timer
-	hostname, err := os.Hostname()
+	destroyResponse, err := client.VolumeDestroy(name, true)
 	if err != nil {
-		log.Warnf("Could not determine hostname. %v", err)
-		hostname = "unknown"
+		return fmt.Errorf("error destroying replica volume %s: %v", name, err)
 	}
+	if zerr := api.NewZapiError(destroyResponse); !zerr.IsPassed() && zerr.Code() != azgo.EVOLUMEDOESNOTEXIST {
+		return fmt.Errorf("error destroying replica volume %s: %v", name, zerr)
+	}
+	return nil
+}
 
-	message, _ := json.Marshal(driver.GetTelemetry())
-
-	emsResponse, err := driver.GetAPI().EmsAutosupportLog(
-		strconv.Itoa(drivers.ConfigVersion), false, "heartbeat", hostname,
-		string(message), 1, trident.OrchestratorName, 5)
+// removeOntapReplication deletes a SnapMirror relationship from its destination, then releases it on the
+// source so that the source's SnapMirror snapshots are deleted.  Failures are logged rather than returned,
+// as the relationship may never have been created, and leftover snapshots do no harm beyond using space.
+func removeOntapReplication(
+	name, sourceName string, source StorageDriver, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) {
+	destroyResponse, err := client.SnapmirrorDestroy(config.SVM, name)
+	if err = api.GetError(destroyResponse, err); err != nil {
+		log.WithField("volume", name).Warnf("Could not delete snapmirror relationship: %v", err)
+	}
 
-	if err = api.GetError(emsResponse, err); err != nil {
+	releaseResponse, err := source.GetAPI().SnapmirrorRelease(source.GetConfig().SVM, sourceName,
+		PeerSVMName(source.GetConfig(), config.SVM), name)
+	if err = api.GetError(releaseResponse, err); err != nil {
 		log.WithFields(log.Fields{
-			"driver": driver.Name(),
-			"error":  err,
-		}).Error("Error logging EMS message.")
-	} else {
-		log.WithField("driver", driver.Name()).Info("Logged EMS message.")
+			"volume":       name,
+			"sourceVolume": sourceName,
+		}).Warnf("Could not release snapmirror relationship on the source; its snapmirror "+
+			"snapshots may need to be deleted manually. %v", err)
 	}
 }
 
-const MSecPerHour = 1000 * 60 * 60 // millis * seconds * minutes
-
-// Create a volume clone
-func CreateOntapClone(
-	name, source, snapshot string, split bool, config *drivers.OntapStorageDriverConfig, client *api.Client,
+// CreateOntapCache creates a FlexCache of a volume on the origin driver's SVM, which must be this
+// SVM or one peered with it, and waits for the cache to be created.
+func CreateOntapCache(
+	ctx context.Context, name, originName string, origin StorageDriver, aggregate, exportPolicy string,
+	sizeBytes uint64, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
 ) error {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{
-			"Method":   "CreateOntapClone",
-			"Type":     "ontap_common",
-			"name":     name,
-			"source":   source,
-			"snapshot": snapshot,
-			"split":    split,
+			"Method":     "CreateOntapCache",
+			"Type":       "ontap_common",
+			"name":       name,
+			"originName": originName,
+			"originSVM":  origin.GetConfig().SVM,
 		}
-		log.WithFields(fields).Debug(">>>> CreateOntapClone")
-		defer log.WithFields(fields).Debug("<<<< CreateOntapClone")
+		log.WithFields(fields).Debug(">>>> CreateOntapCache")
+		defer log.WithFields(fields).Debug("<<<< CreateOntapCache")
 	}
 
-	// If the specified volume already exists, return an error
-	volExists, err := client.VolumeExists(name)
-	if err != nil {
-		return fmt.Errorf("error checking for existing volume: %v", err)
-	}
-	if volExists {
-		return fmt.Errorf("volume %s already exists", name)
+	if err := CheckVolumeLimit(name, aggregate, config, client); err != nil {
+		return err
 	}
 
-	// If no specific snapshot was requested, create one
-	if snapshot == "" {
-		// This is golang being stupid: https://golang.org/pkg/time/#Time.Format
-		snapshot = time.Now().UTC().Format("20060102T150405Z")
-		snapResponse, err := client.SnapshotCreate(snapshot, source)
-		if err = api.GetError(snapResponse, err); err != nil {
-			return fmt.Errorf("error creating snapshot: %v", err)
-		}
+	originSVM := PeerSVMName(config, origin.GetConfig().SVM)
+	createResponse, err := client.FlexcacheCreateAsync(name, aggregate, originSVM, originName, int(sizeBytes))
+	if err = api.GetError(createResponse, err); err != nil {
+		return fmt.Errorf("error creating cache %s of volume %s:%s: %v", name, originSVM,
+			originName, err)
 	}
 
-	// Create the clone based on a snapshot
-	cloneResponse, err := client.VolumeCloneCreate(name, source, snapshot)
-	if err != nil {
-		return fmt.Errorf("error creating clone: %v", err)
-	}
-	if zerr := api.NewZapiError(cloneResponse); !zerr.IsPassed() {
-		if zerr.Code() == azgo.EOBJECTNOTFOUND {
-			return fmt.Errorf("snapshot %s does not exist in volume %s", snapshot, source)
-		} else {
-			return fmt.Errorf("error creating clone: %v", zerr)
+	// The cache is created by a job, so wait for the volume to appear
+	ticker := time.NewTicker(CachePollIntervalSecs * time.Second)
+	defer ticker.Stop()
+	for {
+		volExists, err := client.VolumeExists(name)
+		if err != nil {
+			return fmt.Errorf("error checking for cache %s: %v", name, err)
 		}
-	}
-
-	if config.StorageDriverName == drivers.OntapNASStorageDriverName {
-		// Mount the new volume
-		mountResponse, err := client.VolumeMount(name, "/"+name)
-		if err = api.GetError(mountResponse, err); err != nil {
-			return fmt.Errorf("error mounting volume to junction: %v", err)
+		if volExists {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cache %s was not created in time: %v", name, ctx.Err())
+		case <-ticker.C:
 		}
 	}
 
-	// Split the clone if requested
-	if split {
-		splitResponse, err := client.VolumeCloneSplitStart(name)
-		if err = api.GetError(splitResponse, err); err != nil {
-			return fmt.Errorf("error splitting clone: %v", err)
+	if exportPolicy != "" {
+		modifyResponse, err := client.VolumeSetExportPolicy(name, exportPolicy)
+		if err = api.GetError(modifyResponse, err); err != nil {
+			return fmt.Errorf("error setting export policy %s on cache %s: %v", exportPolicy, name, err)
 		}
 	}
-
 	return nil
 }
 
-// Return the list of snapshots associated with the named volume
-func GetSnapshotList(name string, config *drivers.OntapStorageDriverConfig, client *api.Client) ([]storage.Snapshot, error) {
+// DestroyOntapCache unmounts a FlexCache and takes it offline, as ONTAP requires, then disconnects it
+// from its origin and destroys it.  A cache that no longer exists is not an error.
+func DestroyOntapCache(name string, config *drivers.OntapStorageDriverConfig, client api.ZapiClient) error {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{
-			"Method": "GetSnapshotList",
+			"Method": "DestroyOntapCache",
 			"Type":   "ontap_common",
 			"name":   name,
 		}
-		log.WithFields(fields).Debug(">>>> GetSnapshotList")
-		defer log.WithFields(fields).Debug("<<<< GetSnapshotList")
+		log.WithFields(fields).Debug(">>>> DestroyOntapCache")
+		defer log.WithFields(fields).Debug("<<<< DestroyOntapCache")
 	}
 
-	snapResponse, err := client.SnapshotGetByVolume(name)
-	if err = api.GetError(snapResponse, err); err != nil {
-		return nil, fmt.Errorf("error enumerating snapshots: %v", err)
+	volExists, err := client.VolumeExists(name)
+	if err != nil {
+		return fmt.Errorf("error checking for cache %s: %v", name, err)
+	}
+	if !volExists {
+		log.WithField("volume", name).Warn("Cache already deleted.")
+		return nil
 	}
 
-	log.Debugf("Returned %v snapshots.", snapResponse.Result.NumRecords())
-	snapshots := []storage.Snapshot{}
+	unmountResponse, err := client.VolumeUnmount(name, true)
+	if err = api.GetError(unmountResponse, err); err != nil {
+		return fmt.Errorf("error unmounting cache %s: %v", name, err)
+	}
+	offlineResponse, err := client.VolumeOffline(name)
+	if err = api.GetError(offlineResponse, err); err != nil {
+		return fmt.Errorf("error taking cache %s offline: %v", name, err)
+	}
+	destroyResponse, err := client.FlexcacheDestroyAsync(name)
+	if err = api.GetError(destroyResponse, err); err != nil {
+		return fmt.Errorf("error destroying cache %s: %v", name, err)
+	}
+	return nil
+}
 
-	// AttributesList() returns []SnapshotInfoType
-	for _, snap := range snapResponse.Result.AttributesList() {
+// RehostOntapVolume moves a Flexvol from the source backend's SVM to this backend's SVM, which must
+// be on the same cluster, keeping its name and data.  ONTAP requires the Flexvol to be unmounted
+// from the source SVM's namespace first, and leaves it unmounted; if the rehost fails, the Flexvol
+// is mounted on the source SVM again.  A Flexvol already on this SVM, such as after an interrupted
+// rehost, is left as it is.  Rehosting needs cluster scope.
+func RehostOntapVolume(
+	name string, source StorageDriver, config *drivers.OntapStorageDriverConfig, client api.ZapiClient,
+) error {
+
+	sourceConfig := source.GetConfig()
 
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "RehostOntapVolume",
+			"Type":      "ontap_common",
+			"name":      name,
+			"sourceSVM": sourceConfig.SVM,
+		}
+		log.WithFields(fields).Debug(">>>> RehostOntapVolume")
+		defer log.WithFields(fields).Debug("<<<< RehostOntapVolume")
+	}
+
+	volExists, err := client.VolumeExists(name)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume: %v", err)
+	}
+	if volExists {
 		log.WithFields(log.Fields{
-			"name":       snap.Name(),
-			"accessTime": snap.AccessTime(),
-		}).Debug("Snapshot")
+			"volume": name,
+			"svm":    config.SVM,
+		}).Debug("Volume already rehosted.")
+		return nil
+	}
 
-		// Time format: yyyy-mm-ddThh:mm:ssZ
-		snapTime := time.Unix(int64(snap.AccessTime()), 0).UTC().Format("2006-01-02T15:04:05Z")
+	sourceClient := source.GetAPI()
+	junctionPath, err := getVolumeJunctionPath(name, sourceClient)
+	if err != nil {
+		return err
+	}
+	if junctionPath != "" {
+		unmountResponse, err := sourceClient.VolumeUnmount(name, true)
+		if err = api.GetError(unmountResponse, err); err != nil {
+			return fmt.Errorf("error unmounting volume %s from SVM %s: %v", name, sourceConfig.SVM, err)
+		}
+	}
 
-		snapshots = append(snapshots, storage.Snapshot{snap.Name(), snapTime})
+	rehostResponse, err := client.VolumeRehost(name, sourceConfig.SVM)
+	if err = api.GetError(rehostResponse, err); err != nil {
+		if junctionPath != "" {
+			mountResponse, mountErr := sourceClient.VolumeMount(name, junctionPath)
+			if mountErr = api.GetError(mountResponse, mountErr); mountErr != nil {
+				log.WithField("volume", name).Errorf("Could not remount volume on SVM %s. %v",
+					sourceConfig.SVM, mountErr)
+			}
+		}
+		return fmt.Errorf("error rehosting volume %s from SVM %s to SVM %s: %v", name, sourceConfig.SVM,
+			config.SVM, err)
 	}
 
-	return snapshots, nil
+	log.WithFields(log.Fields{
+		"volume":    name,
+		"sourceSVM": sourceConfig.SVM,
+		"svm":       config.SVM,
+	}).Info("Rehosted volume.")
+	return nil
+}
+
+// getVolumeJunctionPath returns where a Flexvol is mounted in its SVM's namespace, or an empty
+// string if it isn't mounted.
+func getVolumeJunctionPath(name string, client api.ZapiClient) (string, error) {
+	volume, err := client.VolumeGet(name)
+	if err != nil {
+		return "", fmt.Errorf("error reading volume %s: %v", name, err)
+	}
+	if volume.VolumeIdAttributesPtr == nil || volume.VolumeIdAttributesPtr.JunctionPathPtr == nil {
+		return "", nil
+	}
+	return string(volume.VolumeIdAttributesPtr.JunctionPath()), nil
 }
 
 // Return the list of volumes associated with the tenant
-func GetVolumeList(client *api.Client, config *drivers.OntapStorageDriverConfig) ([]string, error) {
+func GetVolumeList(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) ([]string, error) {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "GetVolumeList", "Type": "ontap_common"}
@@ -626,20 +2534,21 @@ func GetVolumeList(client *api.Client, config *drivers.OntapStorageDriverConfig)
 		defer log.WithFields(fields).Debug("<<<< GetVolumeList")
 	}
 
-	prefix := *config.StoragePrefix
-
-	volResponse, err := client.VolumeList(prefix)
-	if err = api.GetError(volResponse, err); err != nil {
-		return nil, fmt.Errorf("error enumerating volumes: %v", err)
-	}
-
 	var volumes []string
 
-	// AttributesList() returns []VolumeAttributesType
-	for _, volume := range volResponse.Result.AttributesList() {
-		volIDAttrs := volume.VolumeIdAttributes()
-		volName := string(volIDAttrs.Name())[len(prefix):]
-		volumes = append(volumes, volName)
+	// Each tenant's volumes are listed by their own prefix
+	for _, prefix := range storagePrefixes(config) {
+		volResponse, err := client.VolumeList(prefix)
+		if err = api.GetError(volResponse, err); err != nil {
+			return nil, fmt.Errorf("error enumerating volumes: %v", err)
+		}
+
+		// AttributesList() returns []VolumeAttributesType
+		for _, volume := range volResponse.Result.AttributesList() {
+			volIDAttrs := volume.VolumeIdAttributes()
+			volName := string(volIDAttrs.Name())[len(prefix):]
+			volumes = append(volumes, volName)
+		}
 	}
 
 	return volumes, nil
@@ -647,7 +2556,7 @@ func GetVolumeList(client *api.Client, config *drivers.OntapStorageDriverConfig)
 
 // GetVolume checks for the existence of a volume.  It returns nil if the volume
 // exists and an error if it does not (or the API call fails).
-func GetVolume(name string, client *api.Client, config *drivers.OntapStorageDriverConfig) error {
+func GetVolume(name string, client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
 
 	if config.DebugTraceFlags["method"] {
 		fields := log.Fields{"Method": "GetVolume", "Type": "ontap_common"}
@@ -681,27 +2590,41 @@ func MountVolume(exportPath, mountpoint string, config *drivers.OntapStorageDriv
 		defer log.WithFields(fields).Debug("<<<< MountVolume")
 	}
 
-	nfsMountOptions := config.NfsMountOptions
+	return utils.MountNFS(exportPath, mountpoint, config.NfsMountOptions)
+}
 
-	// Do the mount
-	var cmd string
-	switch runtime.GOOS {
-	case utils.Linux:
-		cmd = fmt.Sprintf("mount -v %s %s %s", nfsMountOptions, exportPath, mountpoint)
-	case utils.Darwin:
-		cmd = fmt.Sprintf("mount -v -o rw %s -t nfs %s %s", nfsMountOptions, exportPath, mountpoint)
-	default:
-		return fmt.Errorf("unsupported operating system: %v", runtime.GOOS)
-	}
+// MountSMBVolume reaches a volume over SMB at the specified location, by mapping its share on
+// Windows hosts and mounting it with mount.cifs on others.
+func MountSMBVolume(sharePath, mountpoint string, config *drivers.OntapStorageDriverConfig) error {
 
-	log.WithField("command", cmd).Debug("Mounting volume.")
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":     "MountSMBVolume",
+			"Type":       "ontap_common",
+			"sharePath":  sharePath,
+			"mountpoint": mountpoint,
+		}
+		log.WithFields(fields).Debug(">>>> MountSMBVolume")
+		defer log.WithFields(fields).Debug("<<<< MountSMBVolume")
+	}
 
-	if out, err := exec.Command("sh", "-c", cmd).CombinedOutput(); err != nil {
-		log.WithField("output", string(out)).Debug("Mount failed.")
-		return fmt.Errorf("error mounting NFS volume %v on mountpoint %v: %v", exportPath, mountpoint, err)
+	credentials := utils.CIFSCredentials{
+		Username: config.SMBUsername,
+		Password: config.SMBPassword,
+		Domain:   config.SMBDomain,
 	}
+	if runtime.GOOS == utils.Windows {
+		return utils.MapSMBShare(sharePath, mountpoint, credentials)
+	}
+	return utils.MountCIFS(sharePath, mountpoint, credentials, "")
+}
 
-	return nil
+// UnmountSMBVolume releases a volume reached over SMB at the specified location.
+func UnmountSMBVolume(mountpoint string, config *drivers.OntapStorageDriverConfig) error {
+	if runtime.GOOS == utils.Windows {
+		return utils.UnmapSMBShare(mountpoint)
+	}
+	return UnmountVolume(mountpoint, config)
 }
 
 // UnmountVolume unmounts the volume mounted on the specified mountpoint.
@@ -729,8 +2652,9 @@ func UnmountVolume(mountpoint string, config *drivers.OntapStorageDriverConfig)
 }
 
 // UpdateLoadSharingMirrors checks for the present of LS mirrors on the SVM root volume, and if
-// present, starts an update and waits for them to become idle.
-func UpdateLoadSharingMirrors(client *api.Client) {
+// present, starts an update and waits for them to become idle.  A zero idleTimeout means the
+// default of LSMirrorIdleTimeoutSecs.
+func UpdateLoadSharingMirrors(client api.ZapiClient, idleTimeout time.Duration) {
 
 	// We care about LS mirrors on the SVM root volume, so get the root volume name
 	rootVolumeResponse, err := client.VolumeGetRootName()
@@ -761,9 +2685,17 @@ func UpdateLoadSharingMirrors(client *api.Client) {
 	}
 
 	// Wait for LS mirrors to become idle
-	timeout := time.Now().Add(LSMirrorIdleTimeoutSecs * time.Second)
+	if idleTimeout <= 0 {
+		idleTimeout = LSMirrorIdleTimeoutSecs * time.Second
+	}
+	timeout := time.Now().Add(idleTimeout)
 	for {
-		time.Sleep(1 * time.Second)
+		select {
+		case <-client.Context().Done():
+			log.Warningf("Stopped waiting for load-sharing mirrors to become idle. %v", client.Context().Err())
+			return
+		case <-time.After(1 * time.Second):
+		}
 		log.Debug("Load-sharing mirrors not yet idle, polling...")
 
 		mirrorGetResponse, err = client.SnapmirrorGetLoadSharingMirrors(rootVolume)
@@ -810,6 +2742,85 @@ var ontapPerformanceClasses = map[ontapPerformanceClass]map[string]sa.Offer{
 	ontapSSD:    {sa.Media: sa.NewStringOffer(sa.SSD)},
 }
 
+// flashPoolBalancedCacheRatio is the smallest SSD cache, as a fraction of its aggregate's size, with
+// which a Flash Pool aggregate serves enough of a typical working set from flash to be placed in the
+// balanced performance tier rather than alongside HDD aggregates.
+const flashPoolBalancedCacheRatio = 0.05
+
+// ontapPerformanceTier returns the performance tier of an aggregate of the given class.  The size of
+// a Flash Pool aggregate's cache, and of the aggregate itself, are used if known (i.e. positive).
+func ontapPerformanceTier(class ontapPerformanceClass, cacheBytes, aggregateBytes int) string {
+	switch class {
+	case ontapSSD:
+		return sa.PerformanceTierPerformance
+	case ontapHybrid:
+		if cacheBytes > 0 && aggregateBytes > 0 &&
+			float64(cacheBytes) < flashPoolBalancedCacheRatio*float64(aggregateBytes) {
+			return sa.PerformanceTierCapacity
+		}
+		return sa.PerformanceTierBalanced
+	default:
+		return sa.PerformanceTierCapacity
+	}
+}
+
+// poolPerformanceClass returns the class of the aggregate backing a pool, if its media is known.
+func poolPerformanceClass(pool *storage.Pool) (ontapPerformanceClass, bool) {
+	media, ok := pool.Attributes[sa.Media]
+	if !ok {
+		return "", false
+	}
+	// Each class is named for the media it offers
+	for class := range ontapPerformanceClasses {
+		if media.Matches(sa.NewStringRequest(string(class))) {
+			return class, true
+		}
+	}
+	return "", false
+}
+
+// getPoolPerformanceOffers determines the performance tier of each pool whose media is known and,
+// if the cluster reports it, the size of its Flash Pool cache, which is zero for other aggregates.
+func getPoolPerformanceOffers(client api.ZapiClient, pools map[string]*storage.Pool) map[string]map[string]sa.Offer {
+
+	cacheSizes, err := client.AggrHybridCacheSizes()
+	if err != nil {
+		log.Debugf("Could not determine Flash Pool cache sizes. %v", err)
+		cacheSizes = nil
+	}
+	aggrSpace := make(map[string]api.AggrSpace)
+	if cacheSizes != nil {
+		if aggrSpace, err = client.AggrSpaceStatus(); err != nil {
+			log.Debugf("Could not determine aggregate sizes. %v", err)
+			aggrSpace = make(map[string]api.AggrSpace)
+		}
+	}
+
+	offers := make(map[string]map[string]sa.Offer)
+	for poolName, pool := range pools {
+		class, ok := poolPerformanceClass(pool)
+		if !ok {
+			continue
+		}
+		cacheBytes := cacheSizes[poolName]
+		tier := ontapPerformanceTier(class, cacheBytes, aggrSpace[poolName].Total)
+
+		offers[poolName] = map[string]sa.Offer{sa.PerformanceTier: sa.NewStringOffer(tier)}
+		if cacheSizes != nil {
+			cacheGiB := cacheBytes / (1024 * 1024 * 1024)
+			offers[poolName][sa.FlashPoolCache] = sa.NewIntOffer(0, cacheGiB)
+		}
+
+		log.WithFields(log.Fields{
+			"pool":            poolName,
+			"media":           class,
+			"cacheBytes":      cacheBytes,
+			"performanceTier": tier,
+		}).Debug("Determined pool performance tier.")
+	}
+	return offers
+}
+
 // getStorageBackendSpecsCommon discovers the aggregates assigned to the configured SVM, and it updates the specified Backend
 // object with StoragePools and their associated attributes.
 func getStorageBackendSpecsCommon(
@@ -845,7 +2856,9 @@ func getStorageBackendSpecsCommon(
 	// Define a storage pool for each of the SVM's aggregates
 	storagePools := make(map[string]*storage.Pool)
 	for _, aggrName := range vserverAggrs {
-		storagePools[aggrName] = storage.NewStoragePool(backend, aggrName)
+		if IsUsableAggregate(config, aggrName) {
+			storagePools[aggrName] = storage.NewStoragePool(backend, aggrName)
+		}
 	}
 
 	// Use all assigned aggregates unless 'aggregate' is set in the config
@@ -869,10 +2882,13 @@ func getStorageBackendSpecsCommon(
 
 	// Update pools with aggregate info (i.e. MediaType) using the best means possible
 	var aggrErr error
-	if client.SupportsFeature(api.VServerShowAggr) {
+	if SupportsVersionFeature(config, api.VServerShowAggr) {
 		aggrErr = getVserverAggregateAttributes(d, &storagePools)
-	} else {
+	} else if IsFeatureAvailable(config, FeatureAggregateMedia) {
 		aggrErr = getClusterAggregateAttributes(d, &storagePools)
+	} else {
+		log.WithField("username", config.Username).Debug(
+			"User may not read aggregate info; pools on this backend will not offer 'media'.")
 	}
 
 	if zerr, ok := aggrErr.(api.ZapiError); ok && zerr.IsScopeError() {
@@ -885,12 +2901,53 @@ func getStorageBackendSpecsCommon(
 			" not match pools on this backend: %v.", aggrErr)
 	}
 
+	// Report how many more volumes each pool can hold; all pools share the SVM's limits
+	svmLimits, limitErr := getSVMVolumeLimits(config, client)
+	if limitErr != nil {
+		log.Warnf("Could not determine SVM volume headroom. %v", limitErr)
+	}
+	for _, limit := range svmLimits {
+		log.WithFields(log.Fields{
+			"svm":      config.SVM,
+			"setting":  limit.setting,
+			"headroom": limit.headroom(),
+		}).Info("SVM has a volume limit.")
+		warnNearVolumeLimit(limit, config)
+	}
+
+	encryptionOffers := getPoolEncryptionOffers(config, client, storagePools)
+	performanceOffers := getPoolPerformanceOffers(client, storagePools)
+
 	// Add attributes common to each pool and register pools with backend
 	for _, pool := range storagePools {
 
 		for attrName, offer := range poolAttributes {
 			pool.Attributes[attrName] = offer
 		}
+		limits := append([]volumeLimit{}, svmLimits...)
+		if aggregateLimit, limitErr := getAggregateVolumeLimit(pool.Name, config, client); limitErr != nil {
+			log.WithField("pool", pool.Name).Warnf("Could not determine aggregate volume headroom. %v", limitErr)
+		} else if aggregateLimit != nil {
+			warnNearVolumeLimit(*aggregateLimit, config)
+			limits = append(limits, *aggregateLimit)
+		}
+		volumeHeadroom := leastHeadroom(limits)
+		if volumeHeadroom < 0 {
+			volumeHeadroom = math.MaxInt32
+		}
+		pool.Attributes[sa.VolumeHeadroom] = sa.NewIntOffer(0, volumeHeadroom)
+		if _, ok := poolAttributes[sa.Encryption]; ok {
+			pool.Attributes[sa.Encryption] = encryptionOffers[pool.Name]
+		}
+		if _, ok := poolAttributes[sa.MinIOPS]; ok && !supportsQosMinimum(pool) {
+			delete(pool.Attributes, sa.MinIOPS)
+		}
+		if _, ok := poolAttributes[sa.CachingPolicy]; ok && !supportsCachingPolicy(pool) {
+			delete(pool.Attributes, sa.CachingPolicy)
+		}
+		for attrName, offer := range performanceOffers[pool.Name] {
+			pool.Attributes[attrName] = offer
+		}
 
 		backend.AddStoragePool(pool)
 	}
@@ -898,6 +2955,67 @@ func getStorageBackendSpecsCommon(
 	return
 }
 
+// getPoolCapacityCommon reports the space in the aggregate backing a storage pool.  Without cluster
+// scope, only the space available to the SVM is known.
+func getPoolCapacityCommon(client api.ZapiClient, pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	aggrSpace, err := client.AggrSpaceStatus()
+	if err != nil {
+		return nil, fmt.Errorf("could not get aggregate space: %v", err)
+	}
+
+	space, ok := aggrSpace[pool.Name]
+	if !ok {
+		return nil, fmt.Errorf("aggregate %s not found", pool.Name)
+	}
+
+	return &storage.PoolCapacity{
+		TotalBytes:     uint64(space.Total),
+		UsedBytes:      uint64(space.Used),
+		AvailableBytes: uint64(space.Available),
+	}, nil
+}
+
+// volumePerfCounters are the counters of ONTAP's volume performance object from which a volume's
+// statistics are read.  The latencies are the total time spent on each kind of operation, in
+// microseconds, so that they may be averaged over any interval.
+var volumePerfCounters = []string{
+	"read_ops", "write_ops", "other_ops", "read_data", "write_data",
+	"read_latency", "write_latency", "other_latency",
+}
+
+// getVolumeStatsCommon samples the performance counters of a Flexvol.
+func getVolumeStatsCommon(client api.ZapiClient, name string) (*storage.VolumeStats, error) {
+
+	counters, timestamp, err := client.VolumePerfCounters(name, volumePerfCounters)
+	if err != nil {
+		return nil, fmt.Errorf("could not read performance counters of volume %s: %v", name, err)
+	}
+
+	return &storage.VolumeStats{
+		Time:               timestamp,
+		ReadOps:            counters["read_ops"],
+		WriteOps:           counters["write_ops"],
+		OtherOps:           counters["other_ops"],
+		ReadBytes:          counters["read_data"],
+		WriteBytes:         counters["write_data"],
+		ReadLatencyMicros:  counters["read_latency"],
+		WriteLatencyMicros: counters["write_latency"],
+		OtherLatencyMicros: counters["other_latency"],
+	}, nil
+}
+
+// getVolumeUsedBytesCommon returns the space consumed by a Flexvol, including its snapshots.
+func getVolumeUsedBytesCommon(client api.ZapiClient, name string) (uint64, error) {
+
+	volAttrs, err := client.VolumeGet(name)
+	if err != nil {
+		return 0, fmt.Errorf("could not read the space used by volume %s: %v", name, err)
+	}
+	volSpaceAttrs := volAttrs.VolumeSpaceAttributes()
+	return uint64(volSpaceAttrs.SizeUsed()), nil
+}
+
 // getVserverAggregateAttributes gets pool attributes using vserver-show-aggr-get-iter, which will only succeed on Data ONTAP 9 and later.
 // If the aggregate attributes are read successfully, the pools passed to this function are updated accordingly.
 func getVserverAggregateAttributes(d StorageDriver, storagePools *map[string]*storage.Pool) error {
@@ -1039,6 +3157,54 @@ func getVolumeOptsCommon(
 			}).Warnf("Expected bool for %s; ignoring.", sa.Encryption)
 		}
 	}
+	if qosPolicyReq, ok := requests[sa.QoSPolicy]; ok {
+		if qosPolicy, ok := qosPolicyReq.Value().(string); ok {
+			opts["qosPolicy"] = qosPolicy
+		} else {
+			log.WithFields(log.Fields{
+				"provisioner": "ONTAP",
+				"method":      "getVolumeOptsCommon",
+				"qosPolicy":   qosPolicyReq.Value(),
+			}).Warnf("Expected string for %s; ignoring.", sa.QoSPolicy)
+		}
+	}
+	if cachingPolicyReq, ok := requests[sa.CachingPolicy]; ok {
+		if cachingPolicy, ok := cachingPolicyReq.Value().(string); ok {
+			opts["cachingPolicy"] = cachingPolicy
+		} else {
+			log.WithFields(log.Fields{
+				"provisioner":   "ONTAP",
+				"method":        "getVolumeOptsCommon",
+				"cachingPolicy": cachingPolicyReq.Value(),
+			}).Warnf("Expected string for %s; ignoring.", sa.CachingPolicy)
+		}
+	}
+	if snapshotDirReq, ok := requests[sa.SnapshotDir]; ok {
+		if snapshotDir, ok := snapshotDirReq.Value().(bool); ok {
+			opts["snapshotDir"] = strconv.FormatBool(snapshotDir)
+		} else {
+			log.WithFields(log.Fields{
+				"provisioner":       "ONTAP",
+				"method":            "getVolumeOptsCommon",
+				"snapshotDirectory": snapshotDirReq.Value(),
+			}).Warnf("Expected bool for %s; ignoring.", sa.SnapshotDir)
+		}
+	}
+	for attribute, opt := range map[string]string{
+		sa.MinIOPS: "qosMinIOPS", sa.QoSMaxIOPS: "qosMaxIOPS", sa.QoSMaxMBps: "qosMaxMBps",
+	} {
+		if limitReq, ok := requests[attribute]; ok {
+			if limit, ok := limitReq.Value().(int); ok && limit > 0 {
+				opts[opt] = strconv.Itoa(limit)
+			} else {
+				log.WithFields(log.Fields{
+					"provisioner": "ONTAP",
+					"method":      "getVolumeOptsCommon",
+					attribute:     limitReq.Value(),
+				}).Warnf("Expected positive int for %s; ignoring.", attribute)
+			}
+		}
+	}
 	if volConfig.SnapshotPolicy != "" {
 		opts["snapshotPolicy"] = volConfig.SnapshotPolicy
 	}
@@ -1063,6 +3229,12 @@ func getVolumeOptsCommon(
 	if volConfig.FileSystem != "" {
 		opts["fileSystemType"] = volConfig.FileSystem
 	}
+	if volConfig.FileSystemOwner != "" {
+		opts["fileSystemOwner"] = volConfig.FileSystemOwner
+	}
+	if volConfig.FileSystemMode != "" {
+		opts["fileSystemMode"] = volConfig.FileSystemMode
+	}
 	if volConfig.Encryption != "" {
 		opts["encryption"] = volConfig.Encryption
 	}
@@ -1078,18 +3250,18 @@ func getInternalVolumeNameCommon(commonConfig *drivers.CommonStorageDriverConfig
 	} else {
 		// With an external store, any transformation of the name is fine
 		internal := drivers.GetCommonInternalVolumeName(commonConfig, name)
-		internal = strings.Replace(internal, "-", "_", -1)  // ONTAP disallows hyphens
-		internal = strings.Replace(internal, ".", "_", -1)  // ONTAP disallows periods
-		internal = strings.Replace(internal, "__", "_", -1) // Remove any double underscores
-		return internal
+		return sanitizeVolumeName(internal, maxNameLength(commonConfig.StorageDriverName))
 	}
 }
 
-func createPrepareCommon(d storage.Driver, volConfig *storage.VolumeConfig) bool {
+func createPrepareCommon(
+	d storage.Driver, config *drivers.OntapStorageDriverConfig, volConfig *storage.VolumeConfig,
+) bool {
 
-	volConfig.InternalName = d.GetInternalVolumeName(volConfig.Name)
+	volConfig.InternalName = getInternalVolumeNameFromTemplate(config, volConfig)
 
-	if volConfig.CloneSourceVolume != "" {
+	// A clone's source may have been named from a template, so prefer its known internal name
+	if volConfig.CloneSourceVolume != "" && volConfig.CloneSourceVolumeInternal == "" {
 		volConfig.CloneSourceVolumeInternal =
 			d.GetInternalVolumeName(volConfig.CloneSourceVolume)
 	}
@@ -1097,23 +3269,133 @@ func createPrepareCommon(d storage.Driver, volConfig *storage.VolumeConfig) bool
 	return true
 }
 
+// StorageIdentity identifies the volumes an ONTAP driver manages, by the SVM's UUID and the
+// driver's storage prefix, so that duplicate backends can be recognized.  If the UUID couldn't be
+// read, the SVM is identified by its name and the serial numbers of the cluster's nodes.
+func StorageIdentity(config *drivers.OntapStorageDriverConfig) string {
+
+	svm := config.SVMUUID
+	if svm == "" {
+		if len(config.SerialNumbers) == 0 || config.SVM == "" {
+			return ""
+		}
+		serialNumbers := append([]string{}, config.SerialNumbers...)
+		sort.Strings(serialNumbers)
+		svm = strings.Join(serialNumbers, ",") + "/" + config.SVM
+	}
+
+	prefix := ""
+	if config.StoragePrefix != nil {
+		prefix = *config.StoragePrefix
+	}
+	return fmt.Sprintf("%s:%s:%s", config.StorageDriverName, svm, prefix)
+}
+
+// setFlexvolExternalConfig fills in the parts of an external volume's config that come from its
+// Flexvol.  Attributes that weren't read, such as encryption on older versions of ONTAP, are left
+// as they are.
+func setFlexvolExternalConfig(volumeConfig *storage.VolumeConfig, volumeAttrs *azgo.VolumeAttributesType) {
+
+	if attrs := volumeAttrs.VolumeSnapshotAttributesPtr; attrs != nil {
+		if attrs.SnapshotPolicyPtr != nil {
+			volumeConfig.SnapshotPolicy = attrs.SnapshotPolicy()
+		}
+		if attrs.SnapdirAccessEnabledPtr != nil {
+			volumeConfig.SnapshotDir = strconv.FormatBool(attrs.SnapdirAccessEnabled())
+		}
+	}
+	if attrs := volumeAttrs.VolumeSpaceAttributesPtr; attrs != nil && attrs.SpaceGuaranteePtr != nil {
+		volumeConfig.SpaceReserve = attrs.SpaceGuarantee()
+	}
+	if attrs := volumeAttrs.VolumeSecurityAttributesPtr; attrs != nil {
+		if attrs.StylePtr != nil {
+			volumeConfig.SecurityStyle = attrs.Style()
+		}
+		if attrs.VolumeSecurityUnixAttributesPtr != nil && attrs.VolumeSecurityUnixAttributesPtr.PermissionsPtr != nil {
+			volumeConfig.UnixPermissions = attrs.VolumeSecurityUnixAttributesPtr.Permissions()
+		}
+	}
+	if volumeAttrs.EncryptPtr != nil {
+		volumeConfig.Encryption = strconv.FormatBool(volumeAttrs.Encrypt())
+	}
+}
+
+// setFlexvolCloneSource records the Flexvol and snapshot a Flexvol was cloned from in its external
+// volume's config.  A clone only records its parent until it is split from it.
+func setFlexvolCloneSource(volumeConfig *storage.VolumeConfig, volumeAttrs *azgo.VolumeAttributesType) {
+	if attrs := volumeAttrs.VolumeCloneAttributesPtr; attrs != nil && attrs.VolumeCloneParentAttributesPtr != nil {
+		parentAttrs := attrs.VolumeCloneParentAttributesPtr
+		if parentAttrs.NamePtr != nil {
+			volumeConfig.CloneSourceVolumeInternal = string(parentAttrs.Name())
+		}
+		if parentAttrs.SnapshotNamePtr != nil {
+			volumeConfig.CloneSourceSnapshot = parentAttrs.SnapshotName()
+		}
+	}
+}
+
+// isQtreePoolFlexvol returns true if a Flexvol holds the qtrees of an ontap-nas-economy backend,
+// whose names may begin with another backend's storage prefix, such as trident_.
+func isQtreePoolFlexvol(name string) bool {
+	for _, artifactPrefix := range []string{artifactPrefixDocker, artifactPrefixKubernetes} {
+		if strings.HasPrefix(name, artifactPrefix+"_qtree_pool_") {
+			return true
+		}
+	}
+	return false
+}
+
+// flexvolPool returns the aggregate containing a Flexvol, which is the pool of its volume.
+func flexvolPool(volumeAttrs *azgo.VolumeAttributesType) string {
+	if attrs := volumeAttrs.VolumeIdAttributesPtr; attrs != nil && attrs.ContainingAggregateNamePtr != nil {
+		return attrs.ContainingAggregateName()
+	}
+	return ""
+}
+
+// flexvolState returns the state of a Flexvol, such as online, or an empty string if it wasn't read.
+func flexvolState(volumeAttrs *azgo.VolumeAttributesType) string {
+	if attrs := volumeAttrs.VolumeStateAttributesPtr; attrs != nil && attrs.StatePtr != nil {
+		return attrs.State()
+	}
+	return ""
+}
+
 func getExternalConfig(config drivers.OntapStorageDriverConfig) interface{} {
 
 	drivers.SanitizeCommonStorageDriverConfig(config.CommonStorageDriverConfig)
 
 	return &struct {
 		*drivers.CommonStorageDriverConfigExternal
-		ManagementLIF string `json:"managementLIF"`
-		DataLIF       string `json:"dataLIF"`
-		IgroupName    string `json:"igroupName"`
-		SVM           string `json:"svm"`
+		ManagementLIF       string                 `json:"managementLIF"`
+		DataLIF             string                 `json:"dataLIF"`
+		IgroupName          string                 `json:"igroupName"`
+		IgroupPerNode       bool                   `json:"igroupPerNode,omitempty"`
+		ISCSIPortals        []string               `json:"iscsiPortals,omitempty"`
+		SVM                 string                 `json:"svm"`
+		NASType             string                 `json:"nasType,omitempty"`
+		SMBShare            string                 `json:"smbShare,omitempty"`
+		UnavailableFeatures []string               `json:"unavailableFeatures,omitempty"`
+		SVMPeers            []drivers.OntapSVMPeer `json:"svmPeers,omitempty"`
+		ONTAPVersion        string                 `json:"ontapVersion,omitempty"`
+		ONTAPIVersion       string                 `json:"ontapiVersion,omitempty"`
+		VersionFeatures     map[string]bool        `json:"versionFeatures,omitempty"`
 	}{
 		CommonStorageDriverConfigExternal: drivers.GetCommonStorageDriverConfigExternal(
 			config.CommonStorageDriverConfig,
 		),
-		ManagementLIF: config.ManagementLIF,
-		DataLIF:       config.DataLIF,
-		IgroupName:    config.IgroupName,
-		SVM:           config.SVM,
+		ManagementLIF:       config.ManagementLIF,
+		DataLIF:             config.DataLIF,
+		IgroupName:          config.IgroupName,
+		IgroupPerNode:       config.IgroupPerNode,
+		ISCSIPortals:        config.ISCSIPortals,
+		SVM:                 config.SVM,
+		NASType:             config.NASType,
+		SMBShare:            config.SMBShare,
+		UnavailableFeatures: config.UnavailableFeatures,
+		SVMPeers:            config.SVMPeers,
+		ONTAPVersion:        config.ONTAPVersion,
+		ONTAPIVersion:       config.ONTAPIVersion,
+		VersionFeatures:     config.VersionFeatures,
 	}
 }
@@ -0,0 +1,1719 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+	"github.com/netapp/trident/utils"
+)
+
+// mockClient stands in for ONTAP in tests.  Only the methods a test sets up are implemented;
+// calling any other panics, so a test can't silently depend on an unexpected ZAPI call.
+type mockClient struct {
+	api.ZapiClient
+	licenses             []string
+	licensesErr          error
+	features             map[api.Feature]bool
+	ontapiVersion        string
+	ontapVersion         string
+	keyManagerConfigured bool
+	keyManagerErr        error
+	aggrEncryption       map[string]bool
+	aggrEncryptionErr    error
+	aggrSpace            map[string]api.AggrSpace
+	snapshots            map[string][]string
+	maxVolumes           int
+	volumeCount          int
+	aggrVolumeCount      map[string]int
+	volumeStates         map[string]string
+	unmounted            map[string]bool
+	qosPolicyGroups      map[string][2]string
+	volumeQosPolicies    map[string]string
+	cachingPolicies      map[string]string
+	volumeAttributes     map[string]map[string]string
+	hybridCacheSizes     map[string]int
+	hybridCacheSizesErr  error
+	aggrGetIterErr       error
+	emsErr               error
+	emsMessages          int
+	lastEMSMessage       string
+	userCapabilities     map[string]bool
+	userCapabilitiesErr  error
+	exportRules          map[string][]azgo.ExportRuleInfoType
+	svmPeers             []azgo.VserverPeerInfoType
+	clusterPeers         []azgo.ClusterPeerInfoType
+	clusterPeersErr      error
+	snapshotPolicies     map[string][]azgo.SnapshotScheduleInfoType
+	junctions            map[string]string
+	rehosted             map[string]string
+	rehostErr            error
+	luns                 map[string]bool
+	lunAttributeErr      error
+	cloneParents         map[string]string
+	volumeOptions        map[string]map[string]string
+	volumeOptionErr      error
+	volumeOptionErrno    string
+	splitStarted         map[string]bool
+	qtrees               map[string]string
+	quotaEntries         map[string]string
+	quotaErr             error
+}
+
+func (c *mockClient) WithContext(ctx context.Context) api.ZapiClient {
+	return c
+}
+
+func (c *mockClient) Context() context.Context {
+	return context.Background()
+}
+
+func (c *mockClient) ListLicensedPackages() ([]string, error) {
+	return c.licenses, c.licensesErr
+}
+
+func (c *mockClient) AggrGetIterRequest() (azgo.AggrGetIterResponse, error) {
+	response := azgo.AggrGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, c.aggrGetIterErr
+}
+
+func (c *mockClient) EmsAutosupportLog(
+	appVersion string, autoSupport bool, category string, computerName string, eventDescription string,
+	eventID int, eventSource string, logLevel int,
+) (azgo.EmsAutosupportLogResponse, error) {
+	c.emsMessages++
+	c.lastEMSMessage = eventDescription
+	response := azgo.EmsAutosupportLogResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, c.emsErr
+}
+
+func (c *mockClient) ListUserCapabilities() (map[string]bool, error) {
+	return c.userCapabilities, c.userCapabilitiesErr
+}
+
+func (c *mockClient) SupportsFeature(feature api.Feature) bool {
+	return c.features[feature]
+}
+
+func (c *mockClient) SystemGetOntapiVersion() (string, error) {
+	return c.ontapiVersion, nil
+}
+
+func (c *mockClient) SystemGetVersion() (azgo.SystemGetVersionResponse, error) {
+	response := azgo.SystemGetVersionResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetVersion(c.ontapVersion)
+	return response, nil
+}
+
+// versionFeatures returns the version matrix a cluster with the supplied features is found to have
+func versionFeatures(features map[api.Feature]bool) map[string]bool {
+	matrix := make(map[string]bool)
+	for feature, supported := range features {
+		matrix[string(feature)] = supported
+	}
+	return matrix
+}
+
+func (c *mockClient) KeyManagerConfigured() (bool, error) {
+	return c.keyManagerConfigured, c.keyManagerErr
+}
+
+func (c *mockClient) AggrEncryptionStatus() (map[string]bool, error) {
+	return c.aggrEncryption, c.aggrEncryptionErr
+}
+
+func (c *mockClient) AggrSpaceStatus() (map[string]api.AggrSpace, error) {
+	return c.aggrSpace, nil
+}
+
+func (c *mockClient) AggrHybridCacheSizes() (map[string]int, error) {
+	return c.hybridCacheSizes, c.hybridCacheSizesErr
+}
+
+func (c *mockClient) VserverPeerGetIterRequest() (azgo.VserverPeerGetIterResponse, error) {
+	response := azgo.VserverPeerGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetAttributesList(c.svmPeers)
+	return response, nil
+}
+
+func (c *mockClient) ClusterPeerGetIterRequest() (azgo.ClusterPeerGetIterResponse, error) {
+	response := azgo.ClusterPeerGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetAttributesList(c.clusterPeers)
+	return response, c.clusterPeersErr
+}
+
+func (c *mockClient) VserverGetMaxVolumes() (int, error) {
+	return c.maxVolumes, nil
+}
+
+func (c *mockClient) VolumeCount() (int, error) {
+	return c.volumeCount, nil
+}
+
+func (c *mockClient) VolumeCountByAggregate(aggregate string) (int, error) {
+	return c.aggrVolumeCount[aggregate], nil
+}
+
+func (c *mockClient) ConsistencyGroupSnapshot(name string, volumeNames []string) (azgo.CgCommitResponse, error) {
+	for _, volumeName := range volumeNames {
+		if _, ok := c.snapshots[volumeName]; !ok {
+			return azgo.CgCommitResponse{}, fmt.Errorf("volume %s not found", volumeName)
+		}
+	}
+	for _, volumeName := range volumeNames {
+		c.snapshots[volumeName] = append(c.snapshots[volumeName], name)
+	}
+	response := azgo.CgCommitResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) SnapshotCreate(name, volumeName string) (azgo.SnapshotCreateResponse, error) {
+	c.snapshots[volumeName] = append(c.snapshots[volumeName], name)
+	response := azgo.SnapshotCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) SnapshotGetByVolume(volumeName string) (azgo.SnapshotGetIterResponse, error) {
+	snapshots := make([]azgo.SnapshotInfoType, 0)
+	for _, name := range c.snapshots[volumeName] {
+		snapshots = append(snapshots, *azgo.NewSnapshotInfoType().SetName(name).SetVolume(volumeName).SetAccessTime(0))
+	}
+	response := azgo.SnapshotGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetAttributesList(snapshots).SetNumRecords(len(snapshots))
+	return response, nil
+}
+
+func (c *mockClient) VolumeExists(name string) (bool, error) {
+	_, ok := c.volumeStates[name]
+	return ok, nil
+}
+
+func (c *mockClient) VolumeDestroy(name string, force bool) (azgo.VolumeDestroyResponse, error) {
+	response := azgo.VolumeDestroyResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	if _, ok := c.volumeStates[name]; !ok {
+		response.Result.ResultStatusAttr = "failed"
+		response.Result.ResultErrnoAttr = azgo.EVOLUMEDOESNOTEXIST
+	}
+	delete(c.volumeStates, name)
+	return response, nil
+}
+
+func (c *mockClient) VolumeList(prefix string) (azgo.VolumeGetIterResponse, error) {
+	names := make([]string, 0)
+	for name := range c.volumeStates {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	volumes := make([]azgo.VolumeAttributesType, 0)
+	for _, name := range names {
+		volumes = append(volumes, *azgo.NewVolumeAttributesType().SetVolumeIdAttributes(
+			*azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))))
+	}
+	response := azgo.VolumeGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetAttributesList(volumes).SetNumRecords(len(volumes))
+	return response, nil
+}
+
+func (c *mockClient) VolumeGet(name string) (azgo.VolumeAttributesType, error) {
+	state, ok := c.volumeStates[name]
+	if !ok {
+		return azgo.VolumeAttributesType{}, fmt.Errorf("volume %s not found", name)
+	}
+	attributes := azgo.NewVolumeAttributesType().SetVolumeStateAttributes(
+		*azgo.NewVolumeStateAttributesType().SetState(state))
+	if junction, ok := c.junctions[name]; ok {
+		attributes.SetVolumeIdAttributes(*azgo.NewVolumeIdAttributesType().SetJunctionPath(
+			azgo.JunctionPathType(junction)))
+	}
+	if parent, ok := c.cloneParents[name]; ok {
+		attributes.SetVolumeCloneAttributes(*azgo.NewVolumeCloneAttributesType().SetVolumeCloneParentAttributes(
+			*azgo.NewVolumeCloneParentAttributesType().SetName(azgo.VolumeNameType(parent))))
+	}
+	return *attributes, nil
+}
+
+func (c *mockClient) VolumeCloneCreate(name, source, snapshot string) (azgo.VolumeCloneCreateResponse, error) {
+	c.volumeStates[name] = "online"
+	c.cloneParents[name] = source
+	response := azgo.VolumeCloneCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeCloneSplitStart(name string) (azgo.VolumeCloneSplitStartResponse, error) {
+	c.splitStarted[name] = true
+	response := azgo.VolumeCloneSplitStartResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) QtreeExists(name, volumePrefix string) (bool, string, error) {
+	flexvol, ok := c.qtrees[name]
+	return ok, flexvol, nil
+}
+
+func (c *mockClient) QuotaSetEntry(
+	qtreeName, volumeName, quotaTarget, quotaType, diskLimit string,
+) (azgo.QuotaSetEntryResponse, error) {
+	response := azgo.QuotaSetEntryResponse{}
+	if c.quotaErr != nil {
+		return response, c.quotaErr
+	}
+	c.quotaEntries[quotaTarget] = diskLimit
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeSetOption(name, option, value string) (azgo.VolumeSetOptionResponse, error) {
+	response := azgo.VolumeSetOptionResponse{}
+	if c.volumeOptionErr != nil {
+		return response, c.volumeOptionErr
+	}
+	if c.volumeOptionErrno != "" {
+		response.Result.ResultStatusAttr = "failed"
+		response.Result.ResultErrnoAttr = c.volumeOptionErrno
+		return response, nil
+	}
+	if c.volumeOptions[name] == nil {
+		c.volumeOptions[name] = make(map[string]string)
+	}
+	c.volumeOptions[name][option] = value
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeMount(name, junctionPath string) (azgo.VolumeMountResponse, error) {
+	c.junctions[name] = junctionPath
+	response := azgo.VolumeMountResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeRehost(name, sourceSVM string) (azgo.VolumeRehostResponse, error) {
+	response := azgo.VolumeRehostResponse{}
+	if c.rehostErr != nil {
+		return response, c.rehostErr
+	}
+	c.rehosted[name] = sourceSVM
+	c.volumeStates[name] = "online"
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeUnmount(name string, force bool) (azgo.VolumeUnmountResponse, error) {
+	c.unmounted[name] = true
+	delete(c.junctions, name)
+	response := azgo.VolumeUnmountResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeOffline(name string) (azgo.VolumeOfflineResponse, error) {
+	c.volumeStates[name] = "offline"
+	response := azgo.VolumeOfflineResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) QosPolicyGroupGet(name string) (*azgo.QosPolicyGroupInfoType, error) {
+	limits, ok := c.qosPolicyGroups[name]
+	if !ok {
+		return nil, nil
+	}
+	return azgo.NewQosPolicyGroupInfoType().SetPolicyGroup(name).
+		SetMinThroughput(limits[0]).SetMaxThroughput(limits[1]), nil
+}
+
+func (c *mockClient) QosPolicyGroupCreate(
+	name, minThroughput, maxThroughput string,
+) (azgo.QosPolicyGroupCreateResponse, error) {
+	c.qosPolicyGroups[name] = [2]string{minThroughput, maxThroughput}
+	response := azgo.QosPolicyGroupCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) QosPolicyGroupDelete(name string) (azgo.QosPolicyGroupDeleteResponse, error) {
+	response := azgo.QosPolicyGroupDeleteResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	if _, ok := c.qosPolicyGroups[name]; !ok {
+		response.Result.ResultStatusAttr = "failed"
+		response.Result.ResultErrnoAttr = azgo.EOBJECTNOTFOUND
+	}
+	delete(c.qosPolicyGroups, name)
+	return response, nil
+}
+
+func (c *mockClient) VolumeSetQosPolicyGroupName(name, qosPolicyGroup string) (azgo.VolumeModifyIterResponse, error) {
+	c.volumeQosPolicies[name] = qosPolicyGroup
+	response := azgo.VolumeModifyIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeSetCachingPolicy(name, cachingPolicy string) (azgo.VolumeModifyIterResponse, error) {
+	c.cachingPolicies[name] = cachingPolicy
+	response := azgo.VolumeModifyIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+// setVolumeAttribute records an attribute set on a volume with volume-modify-iter.
+func (c *mockClient) setVolumeAttribute(name, attribute, value string) (azgo.VolumeModifyIterResponse, error) {
+	if c.volumeAttributes[name] == nil {
+		c.volumeAttributes[name] = make(map[string]string)
+	}
+	c.volumeAttributes[name][attribute] = value
+	response := azgo.VolumeModifyIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) VolumeSetExportPolicy(name, exportPolicy string) (azgo.VolumeModifyIterResponse, error) {
+	return c.setVolumeAttribute(name, "exportPolicy", exportPolicy)
+}
+
+func (c *mockClient) VolumeSetSnapshotPolicy(name, snapshotPolicy string) (azgo.VolumeModifyIterResponse, error) {
+	return c.setVolumeAttribute(name, "snapshotPolicy", snapshotPolicy)
+}
+
+func (c *mockClient) VolumeSetSecurityStyle(name, securityStyle string) (azgo.VolumeModifyIterResponse, error) {
+	return c.setVolumeAttribute(name, "securityStyle", securityStyle)
+}
+
+func (c *mockClient) ExportPolicyCreate(policy string) (azgo.ExportPolicyCreateResponse, error) {
+	response := azgo.ExportPolicyCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	if _, ok := c.exportRules[policy]; ok {
+		response.Result.ResultStatusAttr = "failed"
+		response.Result.ResultErrnoAttr = azgo.EDUPLICATEENTRY
+		return response, nil
+	}
+	c.exportRules[policy] = make([]azgo.ExportRuleInfoType, 0)
+	return response, nil
+}
+
+func (c *mockClient) ExportRuleCreate(
+	policy, clientMatch string, protocols, roSecFlavors, rwSecFlavors, suSecFlavors []string,
+) (azgo.ExportRuleCreateResponse, error) {
+	flavors := func(names []string) []azgo.SecurityFlavorType {
+		types := make([]azgo.SecurityFlavorType, 0)
+		for _, name := range names {
+			types = append(types, azgo.SecurityFlavorType(name))
+		}
+		return types
+	}
+	protocolTypes := make([]azgo.AccessProtocolType, 0)
+	for _, protocol := range protocols {
+		protocolTypes = append(protocolTypes, azgo.AccessProtocolType(protocol))
+	}
+	rule := azgo.NewExportRuleInfoType().SetPolicyName(azgo.ExportPolicyNameType(policy)).
+		SetClientMatch(clientMatch).SetProtocol(protocolTypes).SetRoRule(flavors(roSecFlavors)).
+		SetRwRule(flavors(rwSecFlavors)).SetSuperUserSecurity(flavors(suSecFlavors))
+	c.exportRules[policy] = append(c.exportRules[policy], *rule)
+	response := azgo.ExportRuleCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) ExportRuleGetIterRequest(policy string) (azgo.ExportRuleGetIterResponse, error) {
+	response := azgo.ExportRuleGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	response.Result.SetAttributesList(c.exportRules[policy])
+	response.Result.SetNumRecords(len(c.exportRules[policy]))
+	return response, nil
+}
+
+func (c *mockClient) VolumeCountByQosPolicyGroup(prefix, qosPolicyGroup string) (int, error) {
+	count := 0
+	for name, policy := range c.volumeQosPolicies {
+		if strings.HasPrefix(name, prefix) && policy == qosPolicyGroup {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (c *mockClient) SnapshotPolicyGet(name string) (*azgo.SnapshotPolicyInfoType, error) {
+	schedules, ok := c.snapshotPolicies[name]
+	if !ok {
+		return nil, nil
+	}
+	return azgo.NewSnapshotPolicyInfoType().SetPolicy(name).SetSnapshotPolicySchedules(schedules), nil
+}
+
+func (c *mockClient) SnapshotPolicyCreate(
+	name, schedule string, count int, prefix string,
+) (azgo.SnapshotPolicyCreateResponse, error) {
+	c.snapshotPolicies[name] = nil
+	c.SnapshotPolicyAddSchedule(name, schedule, count, prefix)
+	response := azgo.SnapshotPolicyCreateResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) SnapshotPolicyAddSchedule(
+	name, schedule string, count int, prefix string,
+) (azgo.SnapshotPolicyAddScheduleResponse, error) {
+	c.snapshotPolicies[name] = append(c.snapshotPolicies[name],
+		*azgo.NewSnapshotScheduleInfoType().SetSchedule(schedule).SetCount(count).SetPrefix(prefix))
+	response := azgo.SnapshotPolicyAddScheduleResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) SnapshotPolicyModifySchedule(
+	name, schedule string, count int,
+) (azgo.SnapshotPolicyModifyScheduleResponse, error) {
+	for i := range c.snapshotPolicies[name] {
+		if c.snapshotPolicies[name][i].Schedule() == schedule {
+			c.snapshotPolicies[name][i].SetCount(count)
+		}
+	}
+	response := azgo.SnapshotPolicyModifyScheduleResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) SnapshotPolicyRemoveSchedule(
+	name, schedule string,
+) (azgo.SnapshotPolicyRemoveScheduleResponse, error) {
+	kept := make([]azgo.SnapshotScheduleInfoType, 0)
+	for _, existing := range c.snapshotPolicies[name] {
+		if existing.Schedule() != schedule {
+			kept = append(kept, existing)
+		}
+	}
+	c.snapshotPolicies[name] = kept
+	response := azgo.SnapshotPolicyRemoveScheduleResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) LunCreate(
+	lunPath string, sizeInBytes int, osType string, spaceReserved bool,
+) (azgo.LunCreateBySizeResponse, error) {
+	c.luns[lunPath] = true
+	response := azgo.LunCreateBySizeResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+func (c *mockClient) LunSetAttribute(lunPath, name, value string) (azgo.LunSetAttributeResponse, error) {
+	response := azgo.LunSetAttributeResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, c.lunAttributeErr
+}
+
+func (c *mockClient) LunGetAll(pathPattern string) (azgo.LunGetIterResponse, error) {
+	response := azgo.LunGetIterResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	if c.luns[pathPattern] {
+		response.Result.SetNumRecords(1)
+	} else {
+		response.Result.SetNumRecords(0)
+	}
+	return response, nil
+}
+
+func (c *mockClient) LunDestroy(lunPath string) (azgo.LunDestroyResponse, error) {
+	delete(c.luns, lunPath)
+	response := azgo.LunDestroyResponse{}
+	response.Result.ResultStatusAttr = "passed"
+	return response, nil
+}
+
+// newReplayClient returns an API client that answers ZAPI calls from a recording in testdata.
+func newReplayClient(t *testing.T, recording string) (api.ZapiClient, *api.ReplayTransport) {
+	replay, err := api.NewReplayTransportFromFile("testdata/" + recording)
+	if err != nil {
+		t.Fatalf("Unable to load recording %s: %v", recording, err)
+	}
+	return api.NewClient(api.ClientConfig{SVM: "svm0", Transport: replay}), replay
+}
+
+func TestCheckVolumeLimit(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{SVM: "svm0"}
+
+	client, replay := newReplayClient(t, "svm_volume_limit_reached.jsonl")
+	err := CheckVolumeLimit("trident_pvc_2", "", config, client)
+	if !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error when the SVM volume limit is reached, got %v.", err)
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("Expected all recorded exchanges to be used, %d remain.", replay.Remaining())
+	}
+
+	// A limit that cannot be read is not enforced
+	client = api.NewClient(api.ClientConfig{SVM: "svm0", Transport: api.NewReplayTransport(nil)})
+	if err = CheckVolumeLimit("trident_pvc_2", "", config, client); err != nil {
+		t.Errorf("Expected the volume limit check to be skipped, got %v.", err)
+	}
+}
+
+func TestGetVolumeHeadroom(t *testing.T) {
+	client, _ := newReplayClient(t, "svm_volume_limit_reached.jsonl")
+	headroom, err := GetVolumeHeadroom("", &drivers.OntapStorageDriverConfig{SVM: "svm0"}, client)
+	if err != nil {
+		t.Fatal("Unable to get volume headroom: ", err)
+	}
+	if headroom != 0 {
+		t.Errorf("Expected no volume headroom, got %d.", headroom)
+	}
+}
+
+func TestFlexvolLimits(t *testing.T) {
+	client := &mockClient{maxVolumes: 500, volumeCount: 40, aggrVolumeCount: map[string]int{"aggr1": 10, "aggr2": 3}}
+
+	for _, test := range []struct {
+		name             string
+		perSVM           int
+		perAggregate     int
+		aggregate        string
+		expectedHeadroom int
+		expectedFatal    bool
+	}{
+		{"noSoftLimits", 0, 0, "aggr1", 460, false},
+		{"svmLimit", 50, 0, "aggr1", 10, false},
+		{"svmLimitReached", 40, 0, "aggr1", 0, true},
+		{"aggregateLimit", 0, 12, "aggr1", 2, false},
+		{"aggregateLimitReached", 0, 10, "aggr1", 0, true},
+		{"otherAggregate", 0, 10, "aggr2", 7, false},
+		{"aggregateUnknown", 0, 10, "", 460, false},
+		{"leastHeadroom", 45, 12, "aggr1", 2, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				SVM:                       "svm0",
+				LimitFlexvolsPerSVM:       test.perSVM,
+				LimitFlexvolsPerAggregate: test.perAggregate,
+			}
+
+			headroom, err := GetVolumeHeadroom(test.aggregate, config, client)
+			if err != nil {
+				t.Fatal("Unable to get volume headroom: ", err)
+			}
+			if headroom != test.expectedHeadroom {
+				t.Errorf("Expected volume headroom %d, got %d.", test.expectedHeadroom, headroom)
+			}
+
+			err = CheckVolumeLimit("trident_pvc_1", test.aggregate, config, client)
+			if drivers.IsFatalError(err) != test.expectedFatal {
+				t.Errorf("Expected fatal error %v, got %v.", test.expectedFatal, err)
+			}
+		})
+	}
+
+	// Without any limit, the headroom is unbounded
+	client = &mockClient{volumeCount: 40}
+	headroom, err := GetVolumeHeadroom("aggr1", &drivers.OntapStorageDriverConfig{SVM: "svm0"}, client)
+	if err != nil {
+		t.Fatal("Unable to get volume headroom: ", err)
+	}
+	if headroom != -1 {
+		t.Errorf("Expected unbounded volume headroom, got %d.", headroom)
+	}
+}
+
+func TestValidateLicenses(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   string
+		client   *mockClient
+		valid    bool
+		licenses []string
+	}{
+		{
+			name:     "nasLicensed",
+			driver:   drivers.OntapNASStorageDriverName,
+			client:   &mockClient{licenses: []string{LicenseNFS, LicenseFlexClone}},
+			valid:    true,
+			licenses: []string{LicenseNFS, LicenseFlexClone},
+		},
+		{
+			name:     "sanUnlicensed",
+			driver:   drivers.OntapSANStorageDriverName,
+			client:   &mockClient{licenses: []string{LicenseNFS}},
+			valid:    false,
+			licenses: []string{LicenseNFS},
+		},
+		{
+			name:   "svmScoped",
+			driver: drivers.OntapSANStorageDriverName,
+			client: &mockClient{licensesErr: api.NewZapiError(azgo.LicenseV2ListInfoResponseResult{
+				ResultStatusAttr: "failed",
+				ResultErrnoAttr:  azgo.EAPIPRIVILEGE,
+			})},
+			valid: true,
+		},
+		{
+			name:   "unreadable",
+			driver: drivers.OntapNASStorageDriverName,
+			client: &mockClient{licensesErr: errors.New("connection refused")},
+			valid:  true,
+		},
+	}
+	for _, test := range tests {
+		config := &drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: test.driver},
+		}
+		err := ValidateLicenses(test.client, config)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error.", test.name)
+		}
+		if len(config.Licenses) != len(test.licenses) {
+			t.Errorf("%s: expected licenses %v, got %v.", test.name, test.licenses, config.Licenses)
+		}
+	}
+}
+
+func TestValidateLicensesRecorded(t *testing.T) {
+	tests := []struct {
+		name        string
+		recording   string
+		driver      string
+		cloneMethod string
+		valid       bool
+		flexClone   bool
+		snapMirror  bool
+	}{
+		{"licensedNAS", "licenses_all.jsonl", drivers.OntapNASStorageDriverName, CloneMethodFlexClone, true, true, true},
+		{"licensedSAN", "licenses_all.jsonl", drivers.OntapSANStorageDriverName, "", true, true, true},
+		{"unlicensedNAS", "licenses_nfs_only.jsonl", drivers.OntapNASStorageDriverName, "", true, false, false},
+		{"unlicensedCopy", "licenses_nfs_only.jsonl", drivers.OntapNASStorageDriverName, CloneMethodAuto, true, false, false},
+		{"unlicensedFlexClone", "licenses_nfs_only.jsonl", drivers.OntapNASStorageDriverName, CloneMethodFlexClone,
+			false, false, false},
+		{"unlicensedSAN", "licenses_nfs_only.jsonl", drivers.OntapSANStorageDriverName, "", false, false, false},
+	}
+	for _, test := range tests {
+		client, replay := newReplayClient(t, test.recording)
+		config := &drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: test.driver},
+			PeerSVMs:                  []string{"svm1"},
+		}
+		config.CloneMethod = test.cloneMethod
+
+		err := ValidateLicenses(client, config)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error.", test.name)
+		}
+		if replay.Remaining() != 0 {
+			t.Errorf("%s: expected the licenses to be read.", test.name)
+		}
+		if IsLicensed(config, LicenseFlexClone) != test.flexClone ||
+			IsLicensed(config, LicenseSnapMirror) != test.snapMirror {
+			t.Errorf("%s: unexpected licenses %v.", test.name, config.Licenses)
+		}
+	}
+
+	// Node-locked licenses are listed once per node, but recorded once
+	client, _ := newReplayClient(t, "licenses_all.jsonl")
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+	}
+	if err := ValidateLicenses(client, config); err != nil {
+		t.Fatal("Unable to validate licenses: ", err)
+	}
+	expected := []string{"base", LicenseNFS, LicenseISCSI, LicenseFlexClone, LicenseSnapMirror}
+	if !reflect.DeepEqual(config.Licenses, expected) {
+		t.Errorf("Expected licenses %v, got %v.", expected, config.Licenses)
+	}
+}
+
+func TestCreateOntapCloneUnlicensed(t *testing.T) {
+	client, _ := newReplayClient(t, "licenses_nfs_only.jsonl")
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+		SVM:                       "svm0",
+	}
+	if err := ValidateLicenses(client, config); err != nil {
+		t.Fatal("Unable to validate licenses: ", err)
+	}
+
+	// Without FlexClone, the clone fails before any call to the cluster
+	err := CreateOntapClone("trident_clone", "trident_vol1", "", false, config, &mockClient{}, nil)
+	if !drivers.IsUnsupportedError(err) {
+		t.Errorf("Expected an unsupported error without FlexClone, got %v.", err)
+	}
+}
+
+func TestApplyAdvancedOptions(t *testing.T) {
+	client := &mockClient{volumeOptions: map[string]map[string]string{}}
+	config := &drivers.OntapStorageDriverConfig{
+		AdvancedOptions: map[string]string{"no_atime_update": "on", "fractional_reserve": "0"},
+	}
+
+	if err := ApplyAdvancedOptions("trident_vol1", config, client); err != nil {
+		t.Fatal("Unable to apply options: ", err)
+	}
+	if !reflect.DeepEqual(client.volumeOptions["trident_vol1"], config.AdvancedOptions) {
+		t.Errorf("Expected options %v, got %v.", config.AdvancedOptions, client.volumeOptions["trident_vol1"])
+	}
+
+	// The volume exists, so a failure is retried unless ONTAP rejected the option itself
+	client.volumeOptionErr = errors.New("connection reset")
+	if err := ApplyAdvancedOptions("trident_vol2", config, client); !drivers.IsRetryableError(err) {
+		t.Errorf("Expected a retryable error, got %v.", err)
+	}
+	client.volumeOptionErr = nil
+	client.volumeOptionErrno = azgo.EINVALIDINPUTERROR
+	if err := ApplyAdvancedOptions("trident_vol2", config, client); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error for a rejected option, got %v.", err)
+	}
+}
+
+func TestCreateOntapCloneAdvancedOptions(t *testing.T) {
+	client := &mockClient{
+		volumeStates:    map[string]string{"trident_vol1": "online"},
+		snapshots:       map[string][]string{"trident_vol1": {}},
+		junctions:       map[string]string{},
+		cloneParents:    map[string]string{},
+		volumeOptions:   map[string]map[string]string{},
+		volumeOptionErr: errors.New("connection reset"),
+	}
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+		AdvancedOptions:           map[string]string{"no_atime_update": "on"},
+	}
+
+	// A clone whose options can't be set is left unmounted, to be completed by a retry
+	err := CreateOntapClone("trident_clone", "trident_vol1", "", false, config, client, nil)
+	if !drivers.IsRetryableError(err) {
+		t.Fatalf("Expected a retryable error, got %v.", err)
+	}
+	if _, ok := client.junctions["trident_clone"]; ok {
+		t.Error("Expected the clone to be left unmounted.")
+	}
+
+	// The retry finds the clone, sets its options and mounts it
+	client.volumeOptionErr = nil
+	if err = CreateOntapClone("trident_clone", "trident_vol1", "", false, config, client, nil); err != nil {
+		t.Fatal("Unable to resume the clone: ", err)
+	}
+	if client.volumeOptions["trident_clone"]["no_atime_update"] != "on" {
+		t.Errorf("Expected the clone's options to be set, got %v.", client.volumeOptions["trident_clone"])
+	}
+	if client.junctions["trident_clone"] != "/trident_clone" {
+		t.Error("Expected the resumed clone to be mounted.")
+	}
+}
+
+func TestCreateOntapCloneReusesSnapshot(t *testing.T) {
+	snapshot := cloneSnapshotName("trident_clone")
+	client := &mockClient{
+		volumeStates:  map[string]string{"trident_vol1": "online"},
+		snapshots:     map[string][]string{"trident_vol1": {snapshot}},
+		junctions:     map[string]string{},
+		cloneParents:  map[string]string{},
+		volumeOptions: map[string]map[string]string{},
+	}
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+	}
+
+	// The snapshot left by an earlier attempt is used rather than another being created
+	if err := CreateOntapClone("trident_clone", "trident_vol1", "", false, config, client, nil); err != nil {
+		t.Fatal("Unable to create clone: ", err)
+	}
+	if !reflect.DeepEqual(client.snapshots["trident_vol1"], []string{snapshot}) {
+		t.Errorf("Expected only snapshot %s, got %v.", snapshot, client.snapshots["trident_vol1"])
+	}
+	if client.cloneParents["trident_clone"] != "trident_vol1" || client.junctions["trident_clone"] != "/trident_clone" {
+		t.Error("Expected the clone to be created and mounted.")
+	}
+}
+
+func TestResumeOntapClone(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+	}
+	tests := []struct {
+		name     string
+		parent   string
+		mounted  bool
+		split    bool
+		valid    bool
+		splitRun bool
+	}{
+		{"unmountedClone", "trident_vol1", false, false, true, false},
+		{"mountedClone", "trident_vol1", true, false, true, false},
+		{"cloneToSplit", "trident_vol1", true, true, true, true},
+		{"splitClone", "", true, true, true, false},
+		{"otherSource", "trident_vol2", false, false, false, false},
+		{"notClone", "", false, false, false, false},
+	}
+	for _, test := range tests {
+		client := &mockClient{
+			volumeStates:  map[string]string{"trident_clone": "online"},
+			junctions:     map[string]string{},
+			cloneParents:  map[string]string{},
+			volumeOptions: map[string]map[string]string{},
+			splitStarted:  map[string]bool{},
+		}
+		if test.parent != "" {
+			client.cloneParents["trident_clone"] = test.parent
+		}
+		if test.mounted {
+			client.junctions["trident_clone"] = "/trident_clone"
+		}
+
+		err := resumeOntapClone("trident_clone", "trident_vol1", test.split, config, client)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected the clone to be resumed to be %v, got %v.", test.name, test.valid, err)
+			continue
+		}
+		if test.valid && client.junctions["trident_clone"] != "/trident_clone" {
+			t.Errorf("%s: expected the clone to be mounted.", test.name)
+		}
+		if client.splitStarted["trident_clone"] != test.splitRun {
+			t.Errorf("%s: expected the split to be started to be %v.", test.name, test.splitRun)
+		}
+	}
+}
+
+func TestCreateOntapReplicaUnlicensed(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		destination string
+		source      string
+	}{
+		{"destinationUnlicensed", "licenses_nfs_only.jsonl", "licenses_all.jsonl"},
+		{"sourceUnlicensed", "licenses_all.jsonl", "licenses_nfs_only.jsonl"},
+	} {
+		// Each case reads the licenses afresh, as a recording is used up as it is replayed
+		destinationClient, _ := newReplayClient(t, test.destination)
+		sourceClient, _ := newReplayClient(t, test.source)
+
+		config := &drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: drivers.OntapNASStorageDriverName},
+			SVM:                       "svm0",
+		}
+		source := &NASStorageDriver{API: sourceClient}
+		source.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{
+			StorageDriverName: drivers.OntapNASStorageDriverName,
+		}
+		source.Config.SVM = "svm1"
+		if err := ValidateLicenses(destinationClient, config); err != nil {
+			t.Fatalf("%s: unable to validate licenses: %v", test.name, err)
+		}
+		if err := ValidateLicenses(sourceClient, &source.Config); err != nil {
+			t.Fatalf("%s: unable to validate licenses: %v", test.name, err)
+		}
+
+		// The relationship is refused before any call to either cluster
+		err := CreateOntapReplica("trident_replica", "trident_vol1", source, "aggr1", "default", config, &mockClient{})
+		if !drivers.IsUnsupportedError(err) {
+			t.Errorf("%s: expected an unsupported error without SnapMirror, got %v.", test.name, err)
+		}
+	}
+}
+
+func TestValidateEncryptionAttribute(t *testing.T) {
+	nve := map[api.Feature]bool{api.NetAppVolumeEncryption: true}
+	nae := map[string]bool{"aggr1": true}
+	tests := []struct {
+		name       string
+		encryption string
+		client     *mockClient
+		fatal      bool
+		expected   *bool
+	}{
+		{"invalid", "maybe", &mockClient{features: nve, keyManagerConfigured: true}, true, nil},
+		{"unsupported", "true", &mockClient{}, true, nil},
+		{"unsupportedDisabled", "false", &mockClient{}, false, nil},
+		{"noKeyManager", "true", &mockClient{features: nve}, true, nil},
+		{"keyManagerUnknown", "true", &mockClient{features: nve, keyManagerErr: errors.New("scope")},
+			false, &[]bool{true}[0]},
+		{"encrypted", "true", &mockClient{features: nve, keyManagerConfigured: true}, false, &[]bool{true}[0]},
+		{"unencrypted", "false", &mockClient{features: nve}, false, &[]bool{false}[0]},
+		{"aggregateEncrypted", "true", &mockClient{aggrEncryption: nae}, false, nil},
+		{"aggregateEncryptedNoKeyManager", "true", &mockClient{features: nve, aggrEncryption: nae}, false, nil},
+		{"otherAggregateEncrypted", "true", &mockClient{aggrEncryption: map[string]bool{"aggr2": true}}, true, nil},
+		{"aggregateUnknown", "true", &mockClient{aggrEncryptionErr: errors.New("scope")}, true, nil},
+	}
+	for _, test := range tests {
+		config := &drivers.OntapStorageDriverConfig{VersionFeatures: versionFeatures(test.client.features)}
+		encrypt, err := ValidateEncryptionAttribute(test.encryption, "aggr1", config, test.client)
+		if test.fatal {
+			if !drivers.IsFatalError(err) {
+				t.Errorf("%s: expected a fatal error, got %v.", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if (encrypt == nil) != (test.expected == nil) ||
+			(encrypt != nil && *encrypt != *test.expected) {
+			t.Errorf("%s: expected encryption %v, got %v.", test.name, test.expected, encrypt)
+		}
+	}
+}
+
+func TestCheckKeyManager(t *testing.T) {
+	if err := checkKeyManager(&mockClient{keyManagerConfigured: true}); err != nil {
+		t.Errorf("Expected a configured key manager to be accepted, got %v.", err)
+	}
+	if err := checkKeyManager(&mockClient{}); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error without a key manager, got %v.", err)
+	}
+
+	// ONTAP has the final say if the key manager can't be read
+	if err := checkKeyManager(&mockClient{keyManagerErr: errors.New("scope")}); err != nil {
+		t.Errorf("Expected an unreadable key manager state to be accepted, got %v.", err)
+	}
+}
+
+func TestGetPoolEncryptionOffers(t *testing.T) {
+	nve := map[api.Feature]bool{api.NetAppVolumeEncryption: true}
+	pools := map[string]*storage.Pool{"aggr1": nil, "aggr2": nil}
+
+	for _, test := range []struct {
+		name     string
+		client   *mockClient
+		expected map[string]bool
+	}{
+		{"noEncryption", &mockClient{}, map[string]bool{"aggr1": false, "aggr2": false}},
+		{"nve", &mockClient{features: nve, keyManagerConfigured: true}, map[string]bool{"aggr1": true, "aggr2": true}},
+		{"nveNoKeyManager", &mockClient{features: nve}, map[string]bool{"aggr1": false, "aggr2": false}},
+		{"nveKeyManagerUnknown", &mockClient{features: nve, keyManagerErr: errors.New("scope")},
+			map[string]bool{"aggr1": true, "aggr2": true}},
+		{"nae", &mockClient{aggrEncryption: map[string]bool{"aggr1": true}},
+			map[string]bool{"aggr1": true, "aggr2": false}},
+		{"naeNoKeyManager", &mockClient{features: nve, aggrEncryption: map[string]bool{"aggr2": true}},
+			map[string]bool{"aggr1": false, "aggr2": true}},
+	} {
+		config := &drivers.OntapStorageDriverConfig{VersionFeatures: versionFeatures(test.client.features)}
+		offers := getPoolEncryptionOffers(config, test.client, pools)
+
+		for pool, expected := range test.expected {
+			if offered := offers[pool].Matches(sa.NewBoolRequest(true)); offered != expected {
+				t.Errorf("%s: expected pool %s to offer encryption %v, got %v.", test.name, pool, expected, offered)
+			}
+
+			// Creating an encrypted volume must be allowed exactly where encryption is offered
+			if _, err := ValidateEncryptionAttribute("true", pool, config, test.client); (err == nil) != expected {
+				t.Errorf("%s: pool %s offers encryption %v, but creating an encrypted volume returned %v.",
+					test.name, pool, expected, err)
+			}
+		}
+	}
+}
+
+func TestGetPoolCapacityCommon(t *testing.T) {
+	client := &mockClient{aggrSpace: map[string]api.AggrSpace{
+		"aggr1": {Total: 1000, Used: 400, Available: 600},
+		"aggr2": {Available: 300},
+	}}
+
+	capacity, err := getPoolCapacityCommon(client, &storage.Pool{Name: "aggr1"})
+	if err != nil {
+		t.Fatal("Unable to get pool capacity: ", err)
+	}
+	expected := storage.PoolCapacity{TotalBytes: 1000, UsedBytes: 400, AvailableBytes: 600}
+	if *capacity != expected {
+		t.Errorf("Expected capacity %+v, got %+v.", expected, *capacity)
+	}
+
+	// With SVM scope, only the available space is known
+	capacity, err = getPoolCapacityCommon(client, &storage.Pool{Name: "aggr2"})
+	if err != nil || capacity.AvailableBytes != 300 || capacity.TotalBytes != 0 {
+		t.Errorf("Expected only available space, got %+v (%v).", capacity, err)
+	}
+
+	if _, err = getPoolCapacityCommon(client, &storage.Pool{Name: "aggr3"}); err == nil {
+		t.Error("Expected an error for an unknown aggregate.")
+	}
+}
+
+func TestGetVolumeStatsCommon(t *testing.T) {
+	client, replay := newReplayClient(t, "volume_perf_counters.jsonl")
+
+	stats, err := getVolumeStatsCommon(client, "trident_pvc_1")
+	if err != nil {
+		t.Fatal("Unable to get volume stats: ", err)
+	}
+	expected := storage.VolumeStats{
+		Time:               time.Unix(1530000000, 0),
+		ReadOps:            1000,
+		WriteOps:           500,
+		OtherOps:           100,
+		ReadBytes:          4096000,
+		WriteBytes:         2048000,
+		ReadLatencyMicros:  250000,
+		WriteLatencyMicros: 400000,
+		OtherLatencyMicros: 5000,
+	}
+	if *stats != expected {
+		t.Errorf("Expected stats %+v, got %+v.", expected, *stats)
+	}
+
+	if _, err = getVolumeStatsCommon(client, "trident_pvc_missing"); err == nil {
+		t.Error("Expected an error for a volume without performance data.")
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("Expected all recorded exchanges to be used, %d remain.", replay.Remaining())
+	}
+}
+
+// TestSampleConfigDefaults ensures the defaults shown in sample configs match those the driver applies.
+func TestSampleConfigDefaults(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	if err := PopulateConfigurationDefaults(config); err != nil {
+		t.Fatal("Unable to populate defaults: ", err)
+	}
+
+	defaults := reflect.ValueOf(config.OntapStorageDriverConfigDefaults)
+	for i := 0; i < defaults.NumField(); i++ {
+		field := defaults.Type().Field(i)
+		if sampleDefault, ok := field.Tag.Lookup("default"); ok && defaults.Field(i).String() != sampleDefault {
+			t.Errorf("Sample default for %s is %s, but the driver uses %s.", field.Name, sampleDefault,
+				defaults.Field(i).String())
+		}
+	}
+}
+
+func TestPopulateFlexvolLimitDefaults(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		perSVM          int
+		perAggregate    int
+		warningPercent  int
+		expectedPercent int
+		expectedErr     bool
+	}{
+		{"defaults", 0, 0, 0, DefaultFlexvolLimitWarningPercent, false},
+		{"limits", 200, 50, 75, 75, false},
+		{"negativeSVMLimit", -1, 0, 0, 0, true},
+		{"negativeAggregateLimit", 0, -1, 0, 0, true},
+		{"warningPercentTooHigh", 0, 0, 101, 0, true},
+		{"warningPercentNegative", 0, 0, -5, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig:  &drivers.CommonStorageDriverConfig{},
+				LimitFlexvolsPerSVM:        test.perSVM,
+				LimitFlexvolsPerAggregate:  test.perAggregate,
+				FlexvolLimitWarningPercent: test.warningPercent,
+			}
+			err := PopulateConfigurationDefaults(config)
+			if test.expectedErr {
+				if err == nil {
+					t.Error("Expected an error for an invalid Flexvol limit.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unable to populate defaults: ", err)
+			}
+			if config.FlexvolLimitWarningPercent != test.expectedPercent {
+				t.Errorf("Expected warning percent %d, got %d.", test.expectedPercent,
+					config.FlexvolLimitWarningPercent)
+			}
+		})
+	}
+}
+
+func TestPopulateEMSDefaults(t *testing.T) {
+	for _, test := range []struct {
+		name, severity, source, autoSupport string
+		expectedLevel                       int
+		expectedSource                      string
+		expectedAutoSupport                 bool
+		expectedErr                         bool
+	}{
+		{"defaults", "", "", "", 5, "trident", false, false},
+		{"configured", "Warning", "trident-prod", "true", 4, "trident-prod", true, false},
+		{"badSeverity", "loud", "", "", 0, "", false, true},
+		{"badAutoSupport", "", "", "sometimes", 0, "", false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+				EMSSeverity:               test.severity,
+				EMSEventSource:            test.source,
+				EMSAutoSupport:            test.autoSupport,
+			}
+			err := PopulateConfigurationDefaults(config)
+			if test.expectedErr {
+				if err == nil {
+					t.Error("Expected an error for invalid EMS settings.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unable to populate defaults: ", err)
+			}
+			if config.EMSLogLevel != test.expectedLevel || config.EMSEventSource != test.expectedSource ||
+				config.EMSAutoSupportEnabled != test.expectedAutoSupport {
+				t.Errorf("Expected level %d, source %s and autoSupport %v; got %d, %s and %v.",
+					test.expectedLevel, test.expectedSource, test.expectedAutoSupport,
+					config.EMSLogLevel, config.EMSEventSource, config.EMSAutoSupportEnabled)
+			}
+		})
+	}
+}
+
+func TestCreateGroupSnapshot(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	client := &mockClient{snapshots: map[string][]string{"db_data": {}, "db_log": {}}}
+
+	snapshots, err := CreateGroupSnapshot("backup", []string{"db_data", "db_log"}, config, client)
+	if err != nil {
+		t.Fatal("Unable to create group snapshot: ", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "backup" || snapshots[1].Name != "backup" {
+		t.Errorf("Expected a snapshot of each volume, got %v.", snapshots)
+	}
+
+	if _, err = CreateGroupSnapshot("backup2", []string{"db_data", "db_missing"}, config, client); err == nil {
+		t.Error("Expected an error snapshotting a missing volume.")
+	}
+	if len(client.snapshots["db_data"]) != 1 {
+		t.Errorf("Expected no snapshot after a failed group snapshot, got %v.", client.snapshots["db_data"])
+	}
+}
+
+func TestRetainOntapVolume(t *testing.T) {
+	client := &mockClient{
+		volumeStates: map[string]string{"retained": "online", "offlined": "online", "nasOfflined": "online"},
+		unmounted:    make(map[string]bool),
+	}
+
+	if err := RetainOntapVolume("retained", drivers.OnDeleteRetain, true, client); err != nil {
+		t.Error("Unable to retain volume: ", err)
+	}
+	if client.volumeStates["retained"] != "online" || client.unmounted["retained"] {
+		t.Error("Expected a retained volume to be left as it was.")
+	}
+
+	if err := RetainOntapVolume("offlined", drivers.OnDeleteOffline, false, client); err != nil {
+		t.Error("Unable to take volume offline: ", err)
+	}
+	if client.volumeStates["offlined"] != "offline" || client.unmounted["offlined"] {
+		t.Error("Expected volume to be taken offline without being unmounted.")
+	}
+
+	if err := RetainOntapVolume("nasOfflined", drivers.OnDeleteOffline, true, client); err != nil {
+		t.Error("Unable to take volume offline: ", err)
+	}
+	if client.volumeStates["nasOfflined"] != "offline" || !client.unmounted["nasOfflined"] {
+		t.Error("Expected volume to be unmounted and taken offline.")
+	}
+
+	// Repeating the operation, or retaining a missing volume, succeeds
+	delete(client.unmounted, "nasOfflined")
+	if err := RetainOntapVolume("nasOfflined", drivers.OnDeleteOffline, true, client); err != nil {
+		t.Error("Unable to take offline volume offline again: ", err)
+	}
+	if client.unmounted["nasOfflined"] {
+		t.Error("Expected an offline volume to be left alone.")
+	}
+	if err := RetainOntapVolume("missing", drivers.OnDeleteOffline, true, client); err != nil {
+		t.Error("Unexpected error retaining a missing volume: ", err)
+	}
+}
+
+func TestQosPolicyGroupLifecycle(t *testing.T) {
+	prefix := "trident_"
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix},
+	}
+	client := &mockClient{
+		qosPolicyGroups:   map[string][2]string{"existing": {"", "500iops"}},
+		volumeQosPolicies: make(map[string]string),
+	}
+
+	// A missing policy group can't be used unless the storage class gives its limits
+	if err := EnsureQosPolicyGroup("trident_a", map[string]string{"qosPolicy": "gold"}, config, client); err == nil {
+		t.Error("Expected an error for a missing policy group without limits.")
+	}
+	if err := EnsureQosPolicyGroup("trident_a", map[string]string{"qosPolicy": "existing"}, config, client); err != nil {
+		t.Error("Unexpected error for an existing policy group: ", err)
+	}
+	if err := EnsureQosPolicyGroup("trident_a", map[string]string{}, config, client); err != nil {
+		t.Error("Unexpected error without a policy group: ", err)
+	}
+
+	opts := map[string]string{"qosPolicy": "gold", "qosMaxIOPS": "1000", "qosMaxMBps": "100"}
+	if err := EnsureQosPolicyGroup("trident_a", opts, config, client); err != nil {
+		t.Fatal("Unable to create policy group: ", err)
+	}
+	if maxThroughput := client.qosPolicyGroups["gold"][1]; maxThroughput != "100MB/s,1000iops" {
+		t.Errorf("Expected policy group to be created with 100MB/s,1000iops, got %s", maxThroughput)
+	}
+	for _, name := range []string{"trident_a", "trident_b"} {
+		if err := SetQosPolicyGroup(name, opts, config, client); err != nil {
+			t.Errorf("Unable to set policy group on %s: %v", name, err)
+		}
+	}
+	client.volumeQosPolicies["other_c"] = "gold"
+
+	// The policy group remains while any of the backend's volumes use it
+	delete(client.volumeQosPolicies, "trident_a")
+	ReleaseQosPolicyGroup("gold", config, client)
+	if _, ok := client.qosPolicyGroups["gold"]; !ok {
+		t.Error("Expected a policy group in use to be kept.")
+	}
+
+	delete(client.volumeQosPolicies, "trident_b")
+	ReleaseQosPolicyGroup("gold", config, client)
+	if _, ok := client.qosPolicyGroups["gold"]; ok {
+		t.Error("Expected an unused policy group to be deleted.")
+	}
+
+	// Releasing a policy group that is already gone, or none at all, is harmless
+	ReleaseQosPolicyGroup("gold", config, client)
+	ReleaseQosPolicyGroup("", config, client)
+}
+
+func TestQosMinimum(t *testing.T) {
+	prefix := "trident_"
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix},
+		SVM:                       "svm0",
+	}
+	client := &mockClient{
+		qosPolicyGroups:   make(map[string][2]string),
+		volumeQosPolicies: make(map[string]string),
+	}
+
+	// Floors require ONTAP 9.3
+	opts := map[string]string{"qosMinIOPS": "500", "qosMaxIOPS": "2000"}
+	if err := EnsureQosPolicyGroup("trident_a", opts, config, client); err == nil {
+		t.Error("Expected an error for a floor on older ONTAP.")
+	}
+
+	config.VersionFeatures = versionFeatures(map[api.Feature]bool{api.QosMinThroughput: true})
+	conflicting := map[string]string{"qosMinIOPS": "500", "qosPolicy": "gold"}
+	if err := EnsureQosPolicyGroup("trident_a", conflicting, config, client); err == nil {
+		t.Error("Expected an error for a floor along with a shared policy group.")
+	}
+
+	// Each volume with a floor gets a policy group of its own
+	if err := EnsureQosPolicyGroup("trident_a", opts, config, client); err != nil {
+		t.Fatal("Unable to create volume policy group: ", err)
+	}
+	if err := SetQosPolicyGroup("trident_a", opts, config, client); err != nil {
+		t.Fatal("Unable to set volume policy group: ", err)
+	}
+	if limits := client.qosPolicyGroups["svm0_trident_a"]; limits != [2]string{"500iops", "2000iops"} {
+		t.Errorf("Expected policy group svm0_trident_a with 500iops to 2000iops, got %v", limits)
+	}
+	if policy := client.volumeQosPolicies["trident_a"]; policy != "svm0_trident_a" {
+		t.Errorf("Expected volume in policy group svm0_trident_a, got %s", policy)
+	}
+
+	delete(client.volumeQosPolicies, "trident_a")
+	DeleteVolumeQosPolicyGroup("trident_a", config, client)
+	if _, ok := client.qosPolicyGroups["svm0_trident_a"]; ok {
+		t.Error("Expected the volume policy group to be deleted.")
+	}
+	DeleteVolumeQosPolicyGroup("trident_b", config, client)
+}
+
+func TestQosMinimumOffers(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{}
+	if _, ok := addQosPolicyGroupOffers(map[string]sa.Offer{}, config)[sa.MinIOPS]; ok {
+		t.Error("Expected no IOPS floor to be offered by older ONTAP.")
+	}
+
+	config.VersionFeatures = versionFeatures(map[api.Feature]bool{api.QosMinThroughput: true})
+	if _, ok := addQosPolicyGroupOffers(map[string]sa.Offer{}, config)[sa.MinIOPS]; !ok {
+		t.Error("Expected an IOPS floor to be offered.")
+	}
+
+	for class, expected := range map[ontapPerformanceClass]bool{ontapSSD: true, ontapHybrid: false, ontapHDD: false} {
+		pool := storage.NewStoragePool(nil, "aggr1")
+		for name, offer := range ontapPerformanceClasses[class] {
+			pool.Attributes[name] = offer
+		}
+		if supported := supportsQosMinimum(pool); supported != expected {
+			t.Errorf("Expected floor support %v for %s aggregates, got %v", expected, class, supported)
+		}
+	}
+}
+
+func TestCachingPolicy(t *testing.T) {
+	client := &mockClient{cachingPolicies: make(map[string]string)}
+
+	if err := ValidateCachingPolicy(map[string]string{"cachingPolicy": "random_read"}); err != nil {
+		t.Error("Unexpected error validating a caching policy: ", err)
+	}
+	if err := ValidateCachingPolicy(map[string]string{"cachingPolicy": "everything"}); err == nil {
+		t.Error("Expected an error for an unknown caching policy.")
+	}
+
+	if err := SetCachingPolicy("trident_a", map[string]string{}, client); err != nil {
+		t.Fatal("Unable to skip caching policy: ", err)
+	}
+	if _, ok := client.cachingPolicies["trident_a"]; ok {
+		t.Error("Expected no caching policy to be set without one in the options.")
+	}
+	if err := SetCachingPolicy("trident_a", map[string]string{"cachingPolicy": "all_read"}, client); err != nil {
+		t.Fatal("Unable to set caching policy: ", err)
+	}
+	if policy := client.cachingPolicies["trident_a"]; policy != "all_read" {
+		t.Errorf("Expected caching policy all_read, got %s", policy)
+	}
+
+	for class, expected := range map[ontapPerformanceClass]bool{ontapSSD: false, ontapHybrid: true, ontapHDD: false} {
+		pool := storage.NewStoragePool(nil, "aggr1")
+		for name, offer := range ontapPerformanceClasses[class] {
+			pool.Attributes[name] = offer
+		}
+		if supported := supportsCachingPolicy(pool); supported != expected {
+			t.Errorf("Expected caching policy support %v for %s aggregates, got %v", expected, class, supported)
+		}
+	}
+}
+
+func TestConvertUnixPermissions(t *testing.T) {
+	for permissions, expected := range map[string]string{
+		"0770":         "---rwxrwx---",
+		"755":          "---rwxr-xr-x",
+		"4755":         "s--rwxr-xr-x",
+		"3777":         "-strwxrwxrwx",
+		"---rwxrwxrwx": "---rwxrwxrwx",
+		"rwxr-x---":    "---rwxr-x---",
+	} {
+		converted, err := ConvertUnixPermissions(permissions)
+		if err != nil {
+			t.Errorf("Unable to convert permissions %s: %v", permissions, err)
+		} else if converted != expected {
+			t.Errorf("Expected permissions %s to become %s, got %s.", permissions, expected, converted)
+		}
+	}
+	for _, permissions := range []string{"", "0778", "77", "07770", "rwxrwxrw", "---rwxrwxrwz", "u=rwx"} {
+		if _, err := ConvertUnixPermissions(permissions); err == nil {
+			t.Errorf("Expected an error for permissions %q.", permissions)
+		}
+	}
+
+	config := &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	config.UnixPermissions = "0750"
+	if err := PopulateConfigurationDefaults(config); err != nil {
+		t.Fatal("Unable to populate defaults: ", err)
+	}
+	if config.UnixPermissions != "---rwxr-x---" {
+		t.Errorf("Expected default permissions ---rwxr-x---, got %s.", config.UnixPermissions)
+	}
+}
+
+func TestValidateSecurityStyle(t *testing.T) {
+	for _, style := range []string{"unix", "mixed", "ntfs"} {
+		if err := ValidateSecurityStyle(style); err != nil {
+			t.Errorf("Unexpected error validating security style %s: %v", style, err)
+		}
+	}
+	if err := ValidateSecurityStyle("windows"); err == nil {
+		t.Error("Expected an error for an unknown security style.")
+	}
+
+	config := &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	config.SecurityStyle = "Unix"
+	if err := PopulateConfigurationDefaults(config); err == nil {
+		t.Error("Expected an error for an invalid default security style.")
+	}
+}
+
+func TestSMBConfig(t *testing.T) {
+	newConfig := func(nasType string) *drivers.OntapStorageDriverConfig {
+		config := &drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+				StorageDriverName: drivers.OntapNASStorageDriverName,
+				DriverContext:     trident.ContextDocker,
+			},
+			DataLIF:  "10.0.0.1",
+			NASType:  nasType,
+			SMBShare: "root",
+		}
+		if err := PopulateConfigurationDefaults(config); err != nil {
+			t.Fatal("Unable to populate defaults: ", err)
+		}
+		return config
+	}
+
+	if config := newConfig(""); config.SecurityStyle != DefaultSecurityStyle || validateSMBConfig(config) != nil {
+		t.Errorf("Expected NFS volumes to default to %s, got %s.", DefaultSecurityStyle, config.SecurityStyle)
+	}
+
+	config := newConfig(NASTypeSMB)
+	if config.SecurityStyle != "ntfs" {
+		t.Errorf("Expected SMB volumes to default to ntfs, got %s.", config.SecurityStyle)
+	}
+	if err := validateSMBConfig(config); err != nil {
+		t.Errorf("Unable to validate SMB config: %v", err)
+	}
+	expectedPath := `\\10.0.0.1\root\trident_vol1`
+	if path := SMBSharePath("trident_vol1", config); path != expectedPath {
+		t.Errorf("Expected SMB path %s, got %s.", expectedPath, path)
+	}
+
+	for _, modify := range []func(*drivers.OntapStorageDriverConfig){
+		func(c *drivers.OntapStorageDriverConfig) { c.SMBShare = "" },
+		func(c *drivers.OntapStorageDriverConfig) { c.SMBShare = `root\trident` },
+		func(c *drivers.OntapStorageDriverConfig) { c.DriverContext = trident.ContextKubernetes },
+		func(c *drivers.OntapStorageDriverConfig) {
+			c.StorageDriverName = drivers.OntapNASQtreeStorageDriverName
+		},
+	} {
+		config := newConfig(NASTypeSMB)
+		modify(config)
+		if err := validateSMBConfig(config); err == nil {
+			t.Errorf("Expected SMB config %+v to be rejected.", config)
+		}
+	}
+
+	config = &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	config.NASType = "cifs"
+	if err := PopulateConfigurationDefaults(config); err == nil {
+		t.Error("Expected an error for an invalid nasType.")
+	}
+}
+
+func TestLogAuditEvent(t *testing.T) {
+	client := &mockClient{}
+	driver := newTestTelemetryDriver("")
+	driver.API = client
+
+	LogAuditEvent(context.Background(), driver, "trident_vol1")
+	if client.emsMessages != 0 {
+		t.Errorf("Expected no EMS message outside of an operation, got %d.", client.emsMessages)
+	}
+
+	ctx := utils.WithRequester(context.Background(), "docker")
+	ctx, audit := utils.WithAuditInfo(ctx, "create", "vol1")
+	LogAuditEvent(ctx, driver, "trident_vol1")
+	if client.emsMessages != 1 || !strings.Contains(client.lastEMSMessage, audit.CorrelationID) ||
+		!strings.Contains(client.lastEMSMessage, "trident_vol1") {
+		t.Errorf("Expected an EMS message with the correlation ID, got %q.", client.lastEMSMessage)
+	}
+
+	driver.Config.UnavailableFeatures = []string{FeatureEMS}
+	LogAuditEvent(ctx, driver, "trident_vol1")
+	if client.emsMessages != 1 {
+		t.Error("Expected no EMS message without EMS rights.")
+	}
+}
+
+func TestPoolPerformanceOffers(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	for _, test := range []struct {
+		class                      ontapPerformanceClass
+		cacheBytes, aggregateBytes int
+		expected                   string
+	}{
+		{ontapSSD, 0, 0, sa.PerformanceTierPerformance},
+		{ontapHDD, 0, 0, sa.PerformanceTierCapacity},
+		{ontapHybrid, 0, 0, sa.PerformanceTierBalanced},
+		{ontapHybrid, 800 * gib, 10000 * gib, sa.PerformanceTierBalanced},
+		{ontapHybrid, 100 * gib, 10000 * gib, sa.PerformanceTierCapacity},
+	} {
+		if tier := ontapPerformanceTier(test.class, test.cacheBytes, test.aggregateBytes); tier != test.expected {
+			t.Errorf("Expected tier %s for %s aggregate with %d of %d bytes cached, got %s",
+				test.expected, test.class, test.cacheBytes, test.aggregateBytes, tier)
+		}
+	}
+
+	pools := make(map[string]*storage.Pool)
+	for name, class := range map[string]ontapPerformanceClass{"aggr1": ontapHybrid, "aggr2": ontapHDD} {
+		pools[name] = storage.NewStoragePool(nil, name)
+		for attrName, offer := range ontapPerformanceClasses[class] {
+			pools[name].Attributes[attrName] = offer
+		}
+	}
+	pools["aggr3"] = storage.NewStoragePool(nil, "aggr3")
+
+	client := &mockClient{
+		hybridCacheSizes: map[string]int{"aggr1": 800 * gib},
+		aggrSpace:        map[string]api.AggrSpace{"aggr1": {Total: 10000 * gib}, "aggr2": {Total: 10000 * gib}},
+	}
+	offers := getPoolPerformanceOffers(client, pools)
+	if !offers["aggr1"][sa.PerformanceTier].Matches(sa.NewStringRequest(sa.PerformanceTierBalanced)) ||
+		!offers["aggr1"][sa.FlashPoolCache].Matches(sa.NewIntRequest(500)) {
+		t.Errorf("Expected a balanced pool with 800 GiB of cache, got %v", offers["aggr1"])
+	}
+	if !offers["aggr2"][sa.PerformanceTier].Matches(sa.NewStringRequest(sa.PerformanceTierCapacity)) ||
+		offers["aggr2"][sa.FlashPoolCache].Matches(sa.NewIntRequest(1)) {
+		t.Errorf("Expected a capacity pool without cache, got %v", offers["aggr2"])
+	}
+	if _, ok := offers["aggr3"]; ok {
+		t.Error("Expected no offers for a pool of unknown media.")
+	}
+
+	// Without cluster scope, only the tier is known
+	client.hybridCacheSizesErr = errors.New("insufficient privileges")
+	offers = getPoolPerformanceOffers(client, pools)
+	if _, ok := offers["aggr1"][sa.FlashPoolCache]; ok {
+		t.Error("Expected no cache size offer without cluster scope.")
+	}
+}
+
+func TestStorageIdentity(t *testing.T) {
+	prefix := "trident_"
+	newConfig := func(svmUUID, svm string, serialNumbers ...string) *drivers.OntapStorageDriverConfig {
+		return &drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+				StorageDriverName: drivers.OntapNASStorageDriverName,
+				StoragePrefix:     &prefix,
+				SerialNumbers:     serialNumbers,
+			},
+			SVMUUID: svmUUID,
+			SVM:     svm,
+		}
+	}
+
+	byUUID := StorageIdentity(newConfig("8d8d5f3c-1d2f-11e8-b5b6-00a098d39e12", "svm0"))
+	if byUUID != StorageIdentity(newConfig("8d8d5f3c-1d2f-11e8-b5b6-00a098d39e12", "svm0-renamed")) {
+		t.Error("Expected the SVM UUID to identify the SVM whatever its name.")
+	}
+
+	bySerials := StorageIdentity(newConfig("", "svm0", "2", "1"))
+	if bySerials == "" || bySerials != StorageIdentity(newConfig("", "svm0", "1", "2")) {
+		t.Errorf("Expected the node serial numbers and SVM name to identify the SVM, got %s", bySerials)
+	}
+
+	if identity := StorageIdentity(newConfig("", "svm0")); identity != "" {
+		t.Errorf("Expected no identity without a UUID or serial numbers, got %s", identity)
+	}
+
+	other := newConfig("8d8d5f3c-1d2f-11e8-b5b6-00a098d39e12", "svm0")
+	otherPrefix := "other_"
+	other.StoragePrefix = &otherPrefix
+	if StorageIdentity(other) == byUUID {
+		t.Error("Expected a different storage prefix to give a different identity.")
+	}
+}
+
+func TestEnsureReadOnlyExportPolicy(t *testing.T) {
+	client := &mockClient{exportRules: make(map[string][]azgo.ExportRuleInfoType)}
+	client.ExportPolicyCreate("default")
+	client.ExportRuleCreate("default", "10.0.0.0/8", []string{"nfs"}, []string{"sys"}, []string{"sys"},
+		[]string{"sys"})
+	client.ExportRuleCreate("default", "192.168.0.0/16", []string{"nfs3"}, []string{"any"}, []string{"any"},
+		[]string{"none"})
+
+	policy, err := ensureReadOnlyExportPolicy("default", client)
+	if err != nil {
+		t.Fatal("Unable to create read-only export policy: ", err)
+	}
+	if policy != "default_ro" {
+		t.Errorf("Expected export policy default_ro, got %s.", policy)
+	}
+	rules := client.exportRules[policy]
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 export rules, got %d.", len(rules))
+	}
+	for i, clientMatch := range []string{"10.0.0.0/8", "192.168.0.0/16"} {
+		rule := rules[i]
+		if rule.ClientMatch() != clientMatch || len(rule.RwRule()) != 1 || rule.RwRule()[0] != "never" ||
+			len(rule.SuperUserSecurity()) != 1 || rule.SuperUserSecurity()[0] != "none" {
+			t.Errorf("Expected a read-only rule for %s, got %s.", clientMatch, rule.String())
+		}
+	}
+	if rules[0].RoRule()[0] != "sys" || rules[1].Protocol()[0] != "nfs3" {
+		t.Error("Expected the rules' read-only flavors and protocols to be copied.")
+	}
+
+	// An existing policy is used as it is
+	client.exportRules[policy] = rules[:1]
+	if policy, err = ensureReadOnlyExportPolicy("default", client); err != nil || policy != "default_ro" {
+		t.Errorf("Expected the existing read-only export policy, got %s, %v.", policy, err)
+	}
+	if len(client.exportRules[policy]) != 1 {
+		t.Error("Expected the existing read-only export policy to be left alone.")
+	}
+}
+
+func TestIsQtreePoolFlexvol(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"trident_qtree_pool_trident_ABCDEFGHIJ": true,
+		"ndvp_qtree_pool_netappdvp_ABCDEFGHIJ":  true,
+		"trident_pvc_1234":                      false,
+		"trident_qtree_pool":                    false,
+	} {
+		if isQtreePoolFlexvol(name) != expected {
+			t.Errorf("Expected isQtreePoolFlexvol(%q) to be %t", name, expected)
+		}
+	}
+}
+
+func TestRehostOntapVolume(t *testing.T) {
+	sourceClient := &mockClient{
+		volumeStates: map[string]string{"trident_vol1": "online"},
+		unmounted:    make(map[string]bool),
+		junctions:    map[string]string{"trident_vol1": "/trident_vol1"},
+	}
+	source := &NASStorageDriver{
+		Config: drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+			SVM:                       "svm0",
+		},
+		API: sourceClient,
+	}
+	client := &mockClient{
+		volumeStates: make(map[string]string),
+		junctions:    make(map[string]string),
+		rehosted:     make(map[string]string),
+		rehostErr:    errors.New("cluster scope required"),
+	}
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+		SVM:                       "svm1",
+	}
+
+	// A failed rehost leaves the volume mounted where it was
+	if err := RehostOntapVolume("trident_vol1", source, config, client); err == nil {
+		t.Fatal("Expected an error when ONTAP refuses to rehost the volume.")
+	}
+	if sourceClient.junctions["trident_vol1"] != "/trident_vol1" {
+		t.Error("Expected the volume to be mounted on the source SVM again.")
+	}
+
+	client.rehostErr = nil
+	if err := RehostOntapVolume("trident_vol1", source, config, client); err != nil {
+		t.Fatal("Unable to rehost volume: ", err)
+	}
+	if !sourceClient.unmounted["trident_vol1"] {
+		t.Error("Expected the volume to be unmounted from the source SVM.")
+	}
+	if client.rehosted["trident_vol1"] != "svm0" {
+		t.Errorf("Expected the volume to be rehosted from svm0, got %v.", client.rehosted)
+	}
+
+	// A volume already on this SVM is left alone
+	client.rehostErr = errors.New("volume already exists")
+	if err := RehostOntapVolume("trident_vol1", source, config, client); err != nil {
+		t.Errorf("Expected a rehosted volume to be left alone, got %v.", err)
+	}
+}
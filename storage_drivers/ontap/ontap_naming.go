@@ -0,0 +1,172 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// MaxVolumeNameLength is the longest name ONTAP allows a volume.
+const MaxVolumeNameLength = 203
+
+// Placeholders of a volume naming template
+const (
+	NamePlaceholderPrefix    = "prefix"
+	NamePlaceholderNamespace = "namespace"
+	NamePlaceholderPVC       = "pvc"
+	NamePlaceholderName      = "name"
+)
+
+var (
+	namePlaceholderRegex = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+	nameLiteralRegex     = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+	nameUnderscoresRegex = regexp.MustCompile(`_{2,}`)
+	nameInvalidRegex     = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// maxNameLength returns the longest name a driver may give a volume, which for ontap-nas-economy
+// is the limit on qtree names.
+func maxNameLength(driverName string) int {
+	if driverName == drivers.OntapNASQtreeStorageDriverName {
+		return maxQtreeNameLength
+	}
+	return MaxVolumeNameLength
+}
+
+// sanitizeVolumeName makes a name that ONTAP accepts.  Accents are removed from letters, and
+// hyphens and periods become underscores, as do any other characters ONTAP disallows, such as
+// letters outside ASCII.  A name starting with a digit is prefixed with an underscore.  If any
+// character had to be changed or dropped, other than hyphens and periods, or the name is longer
+// than maxLength, it is shortened as needed and suffixed with a hash of the original, so that
+// names that differ only in those characters or beyond the limit remain distinct.
+func sanitizeVolumeName(name string, maxLength int) string {
+
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	sanitized, _, err := transform.String(stripAccents, name)
+	if err != nil {
+		sanitized = name
+	}
+	altered := sanitized != name
+
+	sanitized = strings.Replace(sanitized, "-", "_", -1)  // ONTAP disallows hyphens
+	sanitized = strings.Replace(sanitized, ".", "_", -1)  // ONTAP disallows periods
+	sanitized = strings.Replace(sanitized, "__", "_", -1) // Remove any double underscores
+
+	if nameInvalidRegex.MatchString(sanitized) {
+		sanitized = nameUnderscoresRegex.ReplaceAllString(nameInvalidRegex.ReplaceAllString(sanitized, "_"), "_")
+		altered = true
+	}
+	if sanitized == "" || unicode.IsDigit(rune(sanitized[0])) {
+		sanitized = "_" + sanitized
+	}
+
+	if !altered && len(sanitized) <= maxLength {
+		return sanitized
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	if len(sanitized)+len(suffix) > maxLength {
+		sanitized = sanitized[:maxLength-len(suffix)]
+	}
+	return strings.TrimRight(sanitized, "_") + suffix
+}
+
+// validateNameTemplate checks that a volume naming template uses only known placeholders and
+// characters ONTAP allows, and includes a placeholder that tells volumes apart.
+func validateNameTemplate(template string) error {
+
+	if template == "" {
+		return nil
+	}
+
+	// With a passthrough store, volumes are found by reversing the name mapping
+	if trident.UsingPassthroughStore {
+		return fmt.Errorf("nameTemplate requires an external store, as templated volume names " +
+			"can't be mapped back to volumes")
+	}
+
+	identifying := false
+	for _, match := range namePlaceholderRegex.FindAllStringSubmatch(template, -1) {
+		switch match[1] {
+		case NamePlaceholderName, NamePlaceholderPVC:
+			identifying = true
+		case NamePlaceholderPrefix, NamePlaceholderNamespace:
+		default:
+			return fmt.Errorf("unknown placeholder %s in nameTemplate %s", match[0], template)
+		}
+	}
+	if !identifying {
+		return fmt.Errorf("nameTemplate %s must include {{%s}} or {{%s}}", template,
+			NamePlaceholderName, NamePlaceholderPVC)
+	}
+	if !nameLiteralRegex.MatchString(namePlaceholderRegex.ReplaceAllString(template, "")) {
+		return fmt.Errorf("nameTemplate %s may only contain placeholders, letters, digits, "+
+			"underscores, hyphens and periods", template)
+	}
+
+	return nil
+}
+
+// getInternalVolumeNameFromTemplate returns a volume's name on ONTAP, made from the backend's
+// naming template if it has one, and from the prefix of the tenant whose namespace requested it.
+// The PVC placeholder is the volume's name if it wasn't requested through Kubernetes.
+func getInternalVolumeNameFromTemplate(
+	config *drivers.OntapStorageDriverConfig, volConfig *storage.VolumeConfig,
+) string {
+
+	// A tenant's volumes are named with its own prefix in place of the backend's
+	commonConfig := config.CommonStorageDriverConfig
+	if len(config.TenantPrefixes) > 0 {
+		tenantConfig := *config.CommonStorageDriverConfig
+		prefix := tenantStoragePrefix(config, volConfig.Namespace)
+		tenantConfig.StoragePrefix = &prefix
+		commonConfig = &tenantConfig
+	}
+
+	if config.NameTemplate == "" {
+		return getInternalVolumeNameCommon(commonConfig, volConfig.Name)
+	}
+
+	values := map[string]string{
+		NamePlaceholderPrefix:    "",
+		NamePlaceholderNamespace: volConfig.Namespace,
+		NamePlaceholderPVC:       volConfig.RequestName,
+		NamePlaceholderName:      volConfig.Name,
+	}
+	if commonConfig.StoragePrefix != nil {
+		values[NamePlaceholderPrefix] = *commonConfig.StoragePrefix
+	}
+	if values[NamePlaceholderPVC] == "" {
+		values[NamePlaceholderPVC] = volConfig.Name
+	}
+
+	name := namePlaceholderRegex.ReplaceAllStringFunc(config.NameTemplate, func(placeholder string) string {
+		return values[namePlaceholderRegex.FindStringSubmatch(placeholder)[1]]
+	})
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name) // ONTAP disallows hyphens and periods
+	name = nameUnderscoresRegex.ReplaceAllString(name, "_")      // Collapse the gaps left by empty values
+	name = sanitizeVolumeName(strings.TrimRight(name, "_"), maxNameLength(config.StorageDriverName))
+
+	log.WithFields(log.Fields{
+		"volume":       volConfig.Name,
+		"internalName": name,
+		"template":     config.NameTemplate,
+	}).Debug("Named volume from template.")
+
+	return name
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func TestValidateNameTemplate(t *testing.T) {
+	for template, valid := range map[string]bool{
+		"":                                 true,
+		"{{prefix}}_{{namespace}}_{{pvc}}": true,
+		"{{ name }}":                       true,
+		"k8s-{{namespace}}.{{pvc}}":        true,
+		"{{prefix}}_{{namespace}}":         false,
+		"{{prefix}}_{{claim}}":             false,
+		"{{prefix}}/{{pvc}}":               false,
+		"{{prefix}}_{pvc}":                 false,
+	} {
+		if err := validateNameTemplate(template); (err == nil) != valid {
+			t.Errorf("Expected template %q valid=%v, got error %v", template, valid, err)
+		}
+	}
+
+	trident.UsingPassthroughStore = true
+	defer func() { trident.UsingPassthroughStore = false }()
+	if err := validateNameTemplate("{{prefix}}_{{pvc}}"); err == nil {
+		t.Error("Expected an error for a naming template with a passthrough store.")
+	}
+}
+
+func TestGetInternalVolumeNameFromTemplate(t *testing.T) {
+	prefix := "trident"
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix},
+	}
+	volConfig := &storage.VolumeConfig{
+		Name:        "prod-data-1a2b3",
+		Namespace:   "prod",
+		RequestName: "data",
+	}
+
+	for template, expected := range map[string]string{
+		"":                                 "trident_prod_data_1a2b3",
+		"{{prefix}}_{{namespace}}_{{pvc}}": "trident_prod_data",
+		"{{namespace}}-{{pvc}}.v1":         "prod_data_v1",
+		"{{prefix}}_{{name}}":              "trident_prod_data_1a2b3",
+	} {
+		config.NameTemplate = template
+		if name := getInternalVolumeNameFromTemplate(config, volConfig); name != expected {
+			t.Errorf("Expected name %s from template %q, got %s", expected, template, name)
+		}
+	}
+
+	// Volumes not requested through Kubernetes have no namespace, and their name stands in for the PVC
+	config.NameTemplate = "{{prefix}}_{{namespace}}_{{pvc}}"
+	name := getInternalVolumeNameFromTemplate(config, &storage.VolumeConfig{Name: "docker-vol"})
+	if name != "trident_docker_vol" {
+		t.Errorf("Expected name trident_docker_vol, got %s", name)
+	}
+
+	volConfig.RequestName = strings.Repeat("p", MaxVolumeNameLength)
+	if name = getInternalVolumeNameFromTemplate(config, volConfig); len(name) != MaxVolumeNameLength {
+		t.Errorf("Expected a name shortened to ONTAP's limit, got %d characters", len(name))
+	}
+}
+
+func TestSanitizeVolumeName(t *testing.T) {
+	for _, test := range []struct {
+		name, original, expected string
+	}{
+		{"plain", "trident-default-data-1a2b3", "trident_default_data_1a2b3"},
+		{"periods", "trident-app.v1", "trident_app_v1"},
+		{"pvcUID", "pvc-3f4c2a1e-9b7d-11e8-8f2a-005056a1b2c3", "pvc_3f4c2a1e_9b7d_11e8_8f2a_005056a1b2c3"},
+		{"leadingDigit", "3f4c2a1e-data", "_3f4c2a1e_data"},
+		{"accents", "trident-café", "trident_cafe_" + nameHash("trident-café")},
+		{"unicode", "trident-数据", "trident_" + nameHash("trident-数据")},
+		{"spaces", "trident-my data", "trident_my_data_" + nameHash("trident-my data")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if sanitized := sanitizeVolumeName(test.original, MaxVolumeNameLength); sanitized != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, sanitized)
+			}
+		})
+	}
+
+	// Kubernetes names claims <namespace>-<pvc>-<uid>, which can exceed the qtree name limit
+	long := "trident-" + strings.Repeat("namespace", 5) + "-" + strings.Repeat("claim", 8) + "-3f4c2"
+	other := "trident-" + strings.Repeat("namespace", 5) + "-" + strings.Repeat("claim", 8) + "-9b7d1"
+	sanitized := sanitizeVolumeName(long, maxNameLength(drivers.OntapNASQtreeStorageDriverName))
+	if len(sanitized) > maxQtreeNameLength {
+		t.Errorf("Expected a name of at most %d characters, got %s", maxQtreeNameLength, sanitized)
+	}
+	if sanitized == sanitizeVolumeName(other, maxQtreeNameLength) {
+		t.Errorf("Expected names differing beyond the limit to remain distinct, got %s for both", sanitized)
+	}
+	if !strings.HasSuffix(sanitized, nameHash(long)) {
+		t.Errorf("Expected %s to end with a hash of the original name", sanitized)
+	}
+	if sanitizeVolumeName(long, MaxVolumeNameLength) != "trident_"+strings.Repeat("namespace", 5)+"_"+
+		strings.Repeat("claim", 8)+"_3f4c2" {
+		t.Error("Expected a name within the Flexvol limit to be left whole.")
+	}
+}
+
+func nameHash(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
@@ -3,9 +3,14 @@
 package ontap
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -22,18 +27,30 @@ import (
 type NASStorageDriver struct {
 	initialized bool
 	Config      drivers.OntapStorageDriverConfig
-	API         *api.Client
+	API         api.ZapiClient
 	Telemetry   *Telemetry
+	journal     drivers.Journal
+
+	housekeeping *utils.HousekeepingScheduler
 }
 
 func (d *NASStorageDriver) GetConfig() *drivers.OntapStorageDriverConfig {
 	return &d.Config
 }
 
-func (d *NASStorageDriver) GetAPI() *api.Client {
+func (d *NASStorageDriver) GetAPI() api.ZapiClient {
 	return d.API
 }
 
+func (d *NASStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *NASStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.API.SetDebugTraceFlags(flags)
+}
+
 func (d *NASStorageDriver) GetTelemetry() *Telemetry {
 	return d.Telemetry
 }
@@ -71,9 +88,15 @@ func (d *NASStorageDriver) Initialize(
 		return fmt.Errorf("error validating %s driver: %v", d.Name(), err)
 	}
 
-	// Set up the autosupport heartbeat
+	// Set up the autosupport heartbeat, and probing of the cluster while it isn't responding
+	d.housekeeping = NewHousekeepingScheduler(d)
 	d.Telemetry = NewOntapTelemetry(d)
-	d.Telemetry.Start()
+	if err = d.Telemetry.Start(d.housekeeping); err != nil {
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
+	if err = d.housekeeping.Schedule(NewCircuitProbeTask(d)); err != nil {
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
 
 	d.initialized = true
 	return nil
@@ -91,6 +114,9 @@ func (d *NASStorageDriver) Terminate() {
 		defer log.WithFields(fields).Debug("<<<< Terminate")
 	}
 	d.Telemetry.Stop()
+	if d.housekeeping != nil {
+		d.housekeeping.Stop()
+	}
 	d.initialized = false
 }
 
@@ -108,11 +134,20 @@ func (d *NASStorageDriver) validate() error {
 		return fmt.Errorf("driver validation failed: %v", err)
 	}
 
+	if err = ValidateSVMPeers(d.API, &d.Config); err != nil {
+		return fmt.Errorf("SVM peer validation failed: %v", err)
+	}
+
 	return nil
 }
 
+// GetVolumeSize returns the size of the Flexvol that Create makes for a requested size.
+func (d *NASStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	return GetVolumeSize(sizeBytes, d.Config)
+}
+
 // Create a volume with the specified options
-func (d *NASStorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+func (d *NASStorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -126,16 +161,24 @@ func (d *NASStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		defer log.WithFields(fields).Debug("<<<< Create")
 	}
 
-	// If the volume already exists, bail out
-	volExists, err := d.API.VolumeExists(name)
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
+	// If the volume already exists, an earlier attempt may have failed partway through
+	volExists, err := client.VolumeExists(name)
 	if err != nil {
-		return fmt.Errorf("error checking for existing volume: %v", err)
+		return classifyError(err, "error checking for existing volume")
 	}
 	if volExists {
-		return fmt.Errorf("volume %s already exists", name)
+		return d.resumeCreate(client, name, opts)
 	}
 
-	sizeBytes, err = GetVolumeSize(sizeBytes, d.Config)
+	if err = CheckVolumeLimit(name, utils.GetV(opts, "aggregate", d.Config.Aggregate), &d.Config, client); err != nil {
+		return err
+	}
+
+	sizeBytes, err = d.GetVolumeSize(sizeBytes)
 	if err != nil {
 		return err
 	}
@@ -151,17 +194,32 @@ func (d *NASStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 	aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
 	securityStyle := utils.GetV(opts, "securityStyle", d.Config.SecurityStyle)
 	encryption := utils.GetV(opts, "encryption", d.Config.Encryption)
+	tieringPolicy := utils.GetV(opts, "tieringPolicy", d.Config.TieringPolicy)
 
 	enableSnapshotDir, err := strconv.ParseBool(snapshotDir)
 	if err != nil {
-		return fmt.Errorf("invalid boolean value for snapshotDir: %v", err)
+		return drivers.NewFatalError(fmt.Sprintf("invalid boolean value for snapshotDir: %v", err))
 	}
 
-	encrypt, err := ValidateEncryptionAttribute(encryption, d.API)
+	if unixPermissions, err = ConvertUnixPermissions(unixPermissions); err != nil {
+		return err
+	}
+	if err = ValidateSecurityStyle(securityStyle); err != nil {
+		return err
+	}
+
+	encrypt, err := ValidateEncryptionAttribute(encryption, aggregate, &d.Config, client)
 	if err != nil {
 		return err
 	}
 
+	if err = ValidateCachingPolicy(opts); err != nil {
+		return err
+	}
+	if err = EnsureQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+
 	log.WithFields(log.Fields{
 		"name":            name,
 		"size":            size,
@@ -173,12 +231,13 @@ func (d *NASStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		"aggregate":       aggregate,
 		"securityStyle":   securityStyle,
 		"encryption":      encryption,
+		"tieringPolicy":   tieringPolicy,
 	}).Debug("Creating Flexvol.")
 
 	// Create the volume
-	volCreateResponse, err := d.API.VolumeCreate(
+	volCreateResponse, err := client.VolumeCreate(
 		name, aggregate, size, spaceReserve, snapshotPolicy,
-		unixPermissions, exportPolicy, securityStyle, encrypt)
+		unixPermissions, exportPolicy, securityStyle, tieringPolicy, encrypt)
 
 	if err = api.GetError(volCreateResponse, err); err != nil {
 		if zerr, ok := err.(api.ZapiError); ok {
@@ -188,31 +247,90 @@ func (d *NASStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 				return nil
 			}
 		}
-		return fmt.Errorf("error creating volume: %v", err)
+		return classifyError(err, "error creating volume")
+	}
+
+	if err = SetQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+	if err = SetCachingPolicy(name, opts, client); err != nil {
+		return err
+	}
+
+	return d.finishCreate(client, name, enableSnapshotDir)
+}
+
+// resumeCreate completes the creation of a Flexvol that already exists.  Mounting is the last step
+// of a create, so a volume with a junction path is complete and there is nothing left to do.
+func (d *NASStorageDriver) resumeCreate(client api.ZapiClient, name string, opts map[string]string) error {
+
+	volAttrs, err := client.VolumeGet(name)
+	if err != nil {
+		return classifyError(err, "error reading existing volume")
+	}
+	if getJunctionPath(volAttrs) != "" {
+		log.WithField("volume", name).Debug("Volume already exists and is mounted.")
+		return nil
+	}
+
+	enableSnapshotDir, err := strconv.ParseBool(utils.GetV(opts, "snapshotDir", d.Config.SnapshotDir))
+	if err != nil {
+		return drivers.NewFatalError(fmt.Sprintf("invalid boolean value for snapshotDir: %v", err))
+	}
+
+	log.WithField("volume", name).Info("Volume already exists, completing any remaining creation steps.")
+
+	if err = ValidateCachingPolicy(opts); err != nil {
+		return err
+	}
+	if err = EnsureQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+	if err = SetQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+	if err = SetCachingPolicy(name, opts, client); err != nil {
+		return err
+	}
+
+	return d.finishCreate(client, name, enableSnapshotDir)
+}
+
+// finishCreate performs the steps that follow creation of a Flexvol.  Each step may safely be
+// repeated, so they may be retried after a partial failure.
+func (d *NASStorageDriver) finishCreate(client api.ZapiClient, name string, enableSnapshotDir bool) error {
+
+	// Apply any ONTAP options from the backend config that Trident doesn't model
+	if err := ApplyAdvancedOptions(name, &d.Config, client); err != nil {
+		return err
 	}
 
 	// Disable '.snapshot' to allow official mysql container's chmod-in-init to work
 	if !enableSnapshotDir {
-		snapDirResponse, err := d.API.VolumeDisableSnapshotDirectoryAccess(name)
+		snapDirResponse, err := client.VolumeDisableSnapshotDirectoryAccess(name)
 		if err = api.GetError(snapDirResponse, err); err != nil {
 			return fmt.Errorf("error disabling snapshot directory access: %v", err)
 		}
 	}
 
 	// Mount the volume at the specified junction
-	mountResponse, err := d.API.VolumeMount(name, "/"+name)
+	mountResponse, err := client.VolumeMount(name, "/"+name)
 	if err = api.GetError(mountResponse, err); err != nil {
-		return fmt.Errorf("error mounting volume to junction: %v", err)
+		return classifyError(err, "error mounting volume to junction")
 	}
 
 	// If LS mirrors are present on the SVM root volume, update them
-	UpdateLoadSharingMirrors(d.API)
+	if ShouldUpdateLoadSharingMirrors(&d.Config) {
+		UpdateLoadSharingMirrors(client, d.Config.LSMirrorTimeoutDuration)
+	}
 
 	return nil
 }
 
 // Create a volume clone
-func (d *NASStorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *NASStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -227,17 +345,248 @@ func (d *NASStorageDriver) CreateClone(name, source, snapshot string, opts map[s
 		defer log.WithFields(fields).Debug("<<<< CreateClone")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
 	split, err := strconv.ParseBool(utils.GetV(opts, "splitOnClone", d.Config.SplitOnClone))
 	if err != nil {
-		return fmt.Errorf("invalid boolean value for splitOnClone: %v", err)
+		return drivers.NewFatalError(fmt.Sprintf("invalid boolean value for splitOnClone: %v", err))
+	}
+
+	if d.useCopyClone() {
+		log.WithField("cloneMethod", d.Config.CloneMethod).Debug("Creating volume clone by copy.")
+		return d.createCopyClone(ctx, name, source, snapshot, opts)
 	}
 
 	log.WithField("splitOnClone", split).Debug("Creating volume clone.")
-	return CreateOntapClone(name, source, snapshot, split, &d.Config, d.API)
+	return CreateOntapClone(name, source, snapshot, split, &d.Config, client, d.journal)
+}
+
+// SetJournal provides the journal in which multi-step operations are recorded
+func (d *NASStorageDriver) SetJournal(journal drivers.Journal) {
+	d.journal = journal
+}
+
+// ReconcileJournalEntry completes or cleans up after an operation interrupted by a restart
+func (d *NASStorageDriver) ReconcileJournalEntry(entry *drivers.JournalEntry) error {
+	if entry.Operation == JournalOperationCopyClone {
+		return d.reconcileCopyClone(entry)
+	}
+	return ReconcileOntapJournalEntry(entry, &d.Config, d.API)
+}
+
+// ListOrphanedObjects returns the clone snapshots whose clones Trident no longer knows about
+func (d *NASStorageDriver) ListOrphanedObjects(knownVolumes map[string]bool) ([]string, error) {
+	return ListOrphanedCloneSnapshots(knownVolumes, &d.Config, d.API)
+}
+
+// DeleteOrphanedObject deletes an object returned by ListOrphanedObjects
+func (d *NASStorageDriver) DeleteOrphanedObject(object string) error {
+	return DeleteOrphanedOntapObject(object, d.API)
+}
+
+// GetOwnership returns the backend that created a volume, as recorded in its Flexvol's comment
+func (d *NASStorageDriver) GetOwnership(name string) (string, time.Time, error) {
+	return GetOntapOwnership(name, d.API)
+}
+
+// copyCloneJobType is the type of the job that tracks the copying of data into a clone
+const copyCloneJobType = "clone-copy"
+
+// useCopyClone determines whether clones should be made by copying data rather than with FlexClone.
+func (d *NASStorageDriver) useCopyClone() bool {
+	switch d.Config.CloneMethod {
+	case CloneMethodCopy:
+		return true
+	case CloneMethodAuto:
+		return !IsLicensed(&d.Config, LicenseFlexClone)
+	default:
+		return false
+	}
+}
+
+// createCopyClone provisions a new Flexvol the same size as the source and copies the source's
+// data into it in the background.  Until the copy has succeeded, each call for the clone returns a
+// RetryableError, so that the clone isn't handed out partly filled.  A copy that fails destroys
+// the clone, so that the next call starts over.  The copy is journaled, so that a clone left
+// partly filled when Trident stops is destroyed when it starts again.
+func (d *NASStorageDriver) createCopyClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
+
+	client := d.API.WithContext(ctx)
+
+	volExists, err := client.VolumeExists(name)
+	if err != nil {
+		return classifyError(err, "error checking for existing volume")
+	}
+	if volExists {
+		job := utils.LatestJob(copyCloneJobType, name)
+		switch {
+		case job == nil:
+			// Copies that didn't finish before a restart are destroyed at startup
+			log.WithField("volume", name).Info("Clone already exists, its data was copied earlier.")
+			return nil
+		case job.State == utils.JobRunning:
+			return drivers.NewRetryableError(fmt.Sprintf(
+				"copying data to clone %s, %d%% complete; track progress with job %s", name, job.Progress, job.ID))
+		case job.State == utils.JobFailed:
+			// The failed copy couldn't destroy the clone, so try again before reporting the failure
+			if err = d.destroyCopyClone(name); err != nil {
+				return err
+			}
+			return fmt.Errorf("error copying data to clone %s: %s", name, job.Message)
+		default:
+			return nil
+		}
+	}
+
+	sourceAttrs, err := client.VolumeGet(source)
+	if err != nil {
+		return fmt.Errorf("error reading source volume %s: %v", source, err)
+	}
+	if sourceAttrs.VolumeSpaceAttributesPtr == nil || sourceAttrs.VolumeSpaceAttributesPtr.SizePtr == nil {
+		return fmt.Errorf("could not determine size of source volume %s", source)
+	}
+	sizeBytes := uint64(sourceAttrs.VolumeSpaceAttributesPtr.Size())
+
+	// Journal the copy before creating the clone, so a clone is never left partly filled unrecorded
+	jobID := utils.StartJob(copyCloneJobType, name)
+	journalEntry := drivers.NewJournalEntry(JournalOperationCopyClone, name, map[string]string{
+		"source":   source,
+		"snapshot": snapshot,
+		"job":      jobID,
+	})
+	recordJournalStep(d.journal, journalEntry, "")
+
+	if err = d.Create(ctx, name, sizeBytes, opts); err != nil {
+		utils.FinishJob(jobID, err)
+		return err
+	}
+
+	// Copying from a snapshot requires the source volume's .snapshot directory
+	sourcePath := ""
+	if snapshot != "" {
+		sourcePath = ".snapshot/" + snapshot
+	}
+
+	log.WithFields(log.Fields{
+		"job":      jobID,
+		"name":     name,
+		"source":   source,
+		"snapshot": snapshot,
+	}).Info("Copying source volume data to clone.")
+	drivers.ReportProgress(ctx, "CloneCopyStarted", fmt.Sprintf(
+		"Started copying data from volume %s to clone %s; track progress with job %s.", source, name, jobID))
+
+	go func() {
+		err := d.copyVolumeData(jobID, source, sourcePath, name)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"job":  jobID,
+				"name": name,
+			}).Errorf("Could not copy data to clone, destroying it. %v", err)
+			if destroyErr := d.destroyCopyClone(name); destroyErr != nil {
+				// The journal entry is kept, so the clone is destroyed by a retry or a later cleanup
+				log.WithField("name", name).Error(destroyErr)
+				utils.FinishJob(jobID, err)
+				return
+			}
+		}
+		if d.journal != nil {
+			if removeErr := d.journal.Remove(journalEntry); removeErr != nil {
+				log.WithField("volume", name).Warnf("Could not remove copy journal entry: %v", removeErr)
+			}
+		}
+		utils.FinishJob(jobID, err)
+	}()
+
+	return drivers.NewRetryableError(fmt.Sprintf(
+		"copying data to clone %s; track progress with job %s", name, jobID))
+}
+
+// reconcileCopyClone destroys a clone whose journaled copy didn't succeed.  A copy still running in
+// this process is left to finish, and a clone whose copy succeeded is kept.
+func (d *NASStorageDriver) reconcileCopyClone(entry *drivers.JournalEntry) error {
+
+	if job := utils.GetJob(entry.Params["job"]); job != nil {
+		switch job.State {
+		case utils.JobRunning:
+			return fmt.Errorf("copy to clone %s is still running as job %s", entry.Volume, job.ID)
+		case utils.JobSucceeded:
+			return nil
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"volume": entry.Volume,
+		"source": entry.Params["source"],
+	}).Info("Destroying clone left partly copied.")
+	return d.destroyCopyClone(entry.Volume)
+}
+
+// destroyCopyClone destroys a clone whose data couldn't be copied.  A clone that no longer exists
+// isn't an error.
+func (d *NASStorageDriver) destroyCopyClone(name string) error {
+
+	volDestroyResponse, err := d.API.VolumeDestroy(name, true)
+	if err != nil {
+		return fmt.Errorf("error destroying clone %s: %v", name, err)
+	}
+	if zerr := api.NewZapiError(volDestroyResponse); !zerr.IsPassed() && zerr.Code() != azgo.EVOLUMEDOESNOTEXIST {
+		return fmt.Errorf("error destroying clone %s: %v", name, zerr)
+	}
+
+	DeleteVolumeQosPolicyGroup(name, &d.Config, d.API)
+	return nil
+}
+
+// copyVolumeData mounts the source and destination volumes on this host and copies the contents
+// of the source, optionally from a subdirectory such as a snapshot, into the destination.
+func (d *NASStorageDriver) copyVolumeData(jobID, source, sourcePath, destination string) error {
+
+	sourceMount, err := ioutil.TempDir("", "trident-clone-src-")
+	if err != nil {
+		return fmt.Errorf("could not create source mountpoint: %v", err)
+	}
+	defer os.Remove(sourceMount)
+
+	destinationMount, err := ioutil.TempDir("", "trident-clone-dst-")
+	if err != nil {
+		return fmt.Errorf("could not create destination mountpoint: %v", err)
+	}
+	defer os.Remove(destinationMount)
+
+	sourceExport := fmt.Sprintf("%s:/%s", d.Config.DataLIF, source)
+	if err := MountVolume(sourceExport, sourceMount, &d.Config); err != nil {
+		return err
+	}
+	defer UnmountVolume(sourceMount, &d.Config)
+
+	destinationExport := fmt.Sprintf("%s:/%s", d.Config.DataLIF, destination)
+	if err := MountVolume(destinationExport, destinationMount, &d.Config); err != nil {
+		return err
+	}
+	defer UnmountVolume(destinationMount, &d.Config)
+
+	return utils.CopyDirectory(filepath.Join(sourceMount, sourcePath), destinationMount,
+		func(copied, total int64) {
+			progress := 100
+			if total > 0 {
+				progress = int(copied * 100 / total)
+			}
+			utils.UpdateJob(jobID, progress, fmt.Sprintf("copied %d of %d bytes", copied, total))
+		})
+}
+
+// SupportsOnDelete reports that Destroy can retain a volume, optionally taking it offline
+func (d *NASStorageDriver) SupportsOnDelete(onDelete string) bool {
+	return onDelete == drivers.OnDeleteRetain || onDelete == drivers.OnDeleteOffline
 }
 
 // Destroy the volume
-func (d *NASStorageDriver) Destroy(name string) error {
+func (d *NASStorageDriver) Destroy(ctx context.Context, name string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -249,6 +598,15 @@ func (d *NASStorageDriver) Destroy(name string) error {
 		defer log.WithFields(fields).Debug("<<<< Destroy")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
+	// A clone may be left on ONTAP, no longer managed by Trident, instead of being destroyed
+	if onDelete := drivers.OnDeleteFromContext(ctx); onDelete != drivers.OnDeleteDelete {
+		return RetainOntapVolume(name, onDelete, true, client)
+	}
+
 	// TODO: If this is the parent of one or more clones, those clones have to split from this
 	// volume before it can be deleted, which means separate copies of those volumes.
 	// If there are a lot of clones on this volume, that could seriously balloon the amount of
@@ -256,7 +614,7 @@ func (d *NASStorageDriver) Destroy(name string) error {
 	// user to keep the volume around until all of the clones are gone? If we do that, need a
 	// way to list the clones. Maybe volume inspect.
 
-	volDestroyResponse, err := d.API.VolumeDestroy(name, true)
+	volDestroyResponse, err := client.VolumeDestroy(name, true)
 	if err != nil {
 		return fmt.Errorf("error destroying volume %v: %v", name, err)
 	}
@@ -270,6 +628,72 @@ func (d *NASStorageDriver) Destroy(name string) error {
 		}
 	}
 
+	DeleteVolumeQosPolicyGroup(name, &d.Config, client)
+	ReleaseQosPolicyGroup(drivers.ManagedQoSPolicyFromContext(ctx), &d.Config, client)
+
+	return nil
+}
+
+// RenameVolume renames a Flexvol that has never been attached, remounting it at a junction that
+// matches its new name.  This lets a volume from a warm pool be handed out under a requested name.
+func (d *NASStorageDriver) RenameVolume(ctx context.Context, name, newName string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":  "RenameVolume",
+			"Type":    "NASStorageDriver",
+			"name":    name,
+			"newName": newName,
+		}
+		log.WithFields(fields).Debug(">>>> RenameVolume")
+		defer log.WithFields(fields).Debug("<<<< RenameVolume")
+	}
+
+	client := d.API.WithContext(ctx)
+
+	unmountResponse, err := client.VolumeUnmount(name, true)
+	if err = api.GetError(unmountResponse, err); err != nil {
+		return fmt.Errorf("error unmounting volume %s: %v", name, err)
+	}
+
+	renameResponse, err := client.VolumeRename(name, newName)
+	if err = api.GetError(renameResponse, err); err != nil {
+		// Leave the volume where it was
+		if mountResponse, mountErr := client.VolumeMount(name, "/"+name); api.GetError(mountResponse, mountErr) != nil {
+			log.WithField("volume", name).Warn("Could not remount volume after failing to rename it.")
+		}
+		return fmt.Errorf("error renaming volume %s to %s: %v", name, newName, err)
+	}
+
+	mountResponse, err := client.VolumeMount(newName, "/"+newName)
+	if err = api.GetError(mountResponse, err); err != nil {
+		return fmt.Errorf("error mounting volume to junction: %v", err)
+	}
+	if ShouldUpdateLoadSharingMirrors(&d.Config) {
+		UpdateLoadSharingMirrors(client, d.Config.LSMirrorTimeoutDuration)
+	}
+
+	return nil
+}
+
+// ResizeVolume grows a Flexvol to the given size
+func (d *NASStorageDriver) ResizeVolume(ctx context.Context, name string, sizeBytes uint64) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":    "ResizeVolume",
+			"Type":      "NASStorageDriver",
+			"name":      name,
+			"sizeBytes": sizeBytes,
+		}
+		log.WithFields(fields).Debug(">>>> ResizeVolume")
+		defer log.WithFields(fields).Debug("<<<< ResizeVolume")
+	}
+
+	resizeResponse, err := d.API.WithContext(ctx).SetVolumeSize(name, strconv.FormatUint(sizeBytes, 10))
+	if err = api.GetError(resizeResponse, err); err != nil {
+		return fmt.Errorf("error resizing volume %s: %v", name, err)
+	}
 	return nil
 }
 
@@ -288,6 +712,10 @@ func (d *NASStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		defer log.WithFields(fields).Debug("<<<< Attach")
 	}
 
+	if d.Config.NASType == NASTypeSMB {
+		return MountSMBVolume(SMBSharePath(name, &d.Config), mountpoint, &d.Config)
+	}
+
 	exportPath := fmt.Sprintf("%s:/%s", d.Config.DataLIF, name)
 
 	return MountVolume(exportPath, mountpoint, &d.Config)
@@ -307,6 +735,9 @@ func (d *NASStorageDriver) Detach(name, mountpoint string) error {
 		defer log.WithFields(fields).Debug("<<<< Detach")
 	}
 
+	if d.Config.NASType == NASTypeSMB {
+		return UnmountSMBVolume(mountpoint, &d.Config)
+	}
 	return UnmountVolume(mountpoint, &d.Config)
 }
 
@@ -326,6 +757,132 @@ func (d *NASStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error)
 	return GetSnapshotList(name, &d.Config, d.API)
 }
 
+// CreateSnapshot takes a snapshot of the named volume
+func (d *NASStorageDriver) CreateSnapshot(snapshotName, volumeName string) (*storage.Snapshot, error) {
+	return CreateSnapshot(snapshotName, volumeName, &d.Config, d.API)
+}
+
+// CreateGroupSnapshot snapshots the named volumes at the same point in time
+func (d *NASStorageDriver) CreateGroupSnapshot(snapshotName string, volumeNames []string) ([]*storage.Snapshot, error) {
+	return CreateGroupSnapshot(snapshotName, volumeNames, &d.Config, d.API)
+}
+
+// DeleteSnapshot deletes a snapshot of the named volume
+func (d *NASStorageDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+	return DeleteSnapshot(snapshotName, volumeName, &d.Config, d.API)
+}
+
+// CanReplicateFrom reports whether volumes may be replicated from the source using SnapMirror,
+// which requires the source to be another ontap-nas backend whose SVM is peered with this one.
+func (d *NASStorageDriver) CanReplicateFrom(source storage.Driver) bool {
+	sourceDriver, ok := source.(*NASStorageDriver)
+	return ok && CanUseSVMPeer(&d.Config, &sourceDriver.Config, PeerApplicationSnapMirror)
+}
+
+// CreateReplica creates a data protection Flexvol and starts replicating the source into it
+func (d *NASStorageDriver) CreateReplica(
+	ctx context.Context, name, sourceName string, source storage.Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
+	exportPolicy := utils.GetV(opts, "exportPolicy", d.Config.ExportPolicy)
+	return CreateOntapReplica(name, sourceName, source.(StorageDriver), aggregate, exportPolicy,
+		&d.Config, d.API.WithContext(ctx))
+}
+
+// UpdateReplica transfers the changes made to the source since the last transfer
+func (d *NASStorageDriver) UpdateReplica(ctx context.Context, name string) error {
+	return UpdateOntapReplica(ctx, name, &d.Config, d.API.WithContext(ctx))
+}
+
+// PromoteReplica makes a replica writable and mounts it, so that it can take the source's place
+func (d *NASStorageDriver) PromoteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+
+	client := d.API.WithContext(ctx)
+
+	if err := PromoteOntapReplica(ctx, name, sourceName, source.(StorageDriver), &d.Config, client); err != nil {
+		return err
+	}
+
+	mountResponse, err := client.VolumeMount(name, "/"+name)
+	if err = api.GetError(mountResponse, err); err != nil {
+		return fmt.Errorf("error mounting volume to junction: %v", err)
+	}
+	if ShouldUpdateLoadSharingMirrors(&d.Config) {
+		UpdateLoadSharingMirrors(client, d.Config.LSMirrorTimeoutDuration)
+	}
+	return nil
+}
+
+// DeleteReplica stops replicating into a replica and destroys it
+func (d *NASStorageDriver) DeleteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+	return DeleteOntapReplica(name, sourceName, source.(StorageDriver), &d.Config, d.API.WithContext(ctx))
+}
+
+// CanCacheFrom reports whether volumes may be cached from the origin using FlexCache, which
+// requires the origin to be another ontap-nas backend whose SVM is this one or is peered with it.
+func (d *NASStorageDriver) CanCacheFrom(origin storage.Driver) bool {
+	originDriver, ok := origin.(*NASStorageDriver)
+	return ok && supportsFlexCache(&d.Config) &&
+		CanUseSVMPeer(&d.Config, &originDriver.Config, PeerApplicationFlexCache)
+}
+
+// CreateCache creates a FlexCache of a volume on the origin's SVM
+func (d *NASStorageDriver) CreateCache(
+	ctx context.Context, name, originName string, origin storage.Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
+	exportPolicy := utils.GetV(opts, "exportPolicy", d.Config.ExportPolicy)
+	return CreateOntapCache(ctx, name, originName, origin.(StorageDriver), aggregate, exportPolicy, sizeBytes,
+		&d.Config, d.API.WithContext(ctx))
+}
+
+// DestroyCache disconnects a FlexCache from its origin and destroys it
+func (d *NASStorageDriver) DestroyCache(ctx context.Context, name string) error {
+	return DestroyOntapCache(name, &d.Config, d.API.WithContext(ctx))
+}
+
+// CanRehostFrom reports whether volumes may be rehosted from the source, which must be another
+// ontap-nas backend managing a different SVM.  ONTAP checks that both SVMs are on the same cluster
+// when a volume is rehosted.
+func (d *NASStorageDriver) CanRehostFrom(source storage.Driver) bool {
+	sourceDriver, ok := source.(*NASStorageDriver)
+	return ok && !isSameSVM(&d.Config, &sourceDriver.Config)
+}
+
+// RehostVolume moves a Flexvol from the source's SVM to this one, then mounts it at its junction
+// and exports it as if it had been created here
+func (d *NASStorageDriver) RehostVolume(
+	ctx context.Context, volConfig *storage.VolumeConfig, source storage.Driver,
+) error {
+
+	client := d.API.WithContext(ctx)
+	name := volConfig.InternalName
+
+	if err := RehostOntapVolume(name, source.(StorageDriver), &d.Config, client); err != nil {
+		return err
+	}
+
+	junctionPath, err := getVolumeJunctionPath(name, client)
+	if err != nil {
+		return err
+	}
+	if junctionPath == "" {
+		mountResponse, err := client.VolumeMount(name, "/"+name)
+		if err = api.GetError(mountResponse, err); err != nil {
+			return fmt.Errorf("error mounting volume to junction: %v", err)
+		}
+		if ShouldUpdateLoadSharingMirrors(&d.Config) {
+			UpdateLoadSharingMirrors(client, d.Config.LSMirrorTimeoutDuration)
+		}
+	}
+
+	// The export policy is the volume's own if it named one, which must exist on this SVM too
+	if err = d.SetVolumeReadOnly(volConfig); err != nil {
+		return err
+	}
+	return d.CreateFollowup(volConfig)
+}
+
 // Return the list of volumes associated with this tenant
 func (d *NASStorageDriver) List() ([]string, error) {
 
@@ -350,6 +907,21 @@ func (d *NASStorageDriver) Get(name string) error {
 	return GetVolume(name, d.API, &d.Config)
 }
 
+// GetPoolCapacity reports the space in the aggregate backing a storage pool.
+func (d *NASStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+	return getPoolCapacityCommon(d.API, pool)
+}
+
+// GetVolumeStats samples the performance counters of a volume's Flexvol.
+func (d *NASStorageDriver) GetVolumeStats(name string) (*storage.VolumeStats, error) {
+	return getVolumeStatsCommon(d.API, name)
+}
+
+// GetVolumeUsedBytes returns the space consumed by a volume's Flexvol.
+func (d *NASStorageDriver) GetVolumeUsedBytes(name string) (uint64, error) {
+	return getVolumeUsedBytesCommon(d.API, name)
+}
+
 // Retrieve storage backend capabilities
 func (d *NASStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 
@@ -360,13 +932,20 @@ func (d *NASStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) erro
 
 func (d *NASStorageDriver) GetStoragePoolAttributes() map[string]sa.Offer {
 
-	return map[string]sa.Offer{
+	// Copy-based clones don't require FlexClone
+	clones := d.Config.CloneMethod != CloneMethodFlexClone || IsLicensed(&d.Config, LicenseFlexClone)
+
+	return addCachingPolicyOffers(addQosPolicyGroupOffers(map[string]sa.Offer{
 		sa.BackendType:      sa.NewStringOffer(d.Name()),
 		sa.Snapshots:        sa.NewBoolOffer(true),
-		sa.Clones:           sa.NewBoolOffer(true),
-		sa.Encryption:       sa.NewBoolOffer(d.API.SupportsFeature(api.NetAppVolumeEncryption)),
+		sa.Clones:           sa.NewBoolOffer(clones),
+		sa.Encryption:       sa.NewBoolOffer(SupportsVersionFeature(&d.Config, api.NetAppVolumeEncryption)),
+		sa.Resize:           sa.NewBoolOffer(true),
+		sa.QoS:              sa.NewBoolOffer(IsFeatureAvailable(&d.Config, FeatureQoSPolicyGroups)),
+		sa.Replication:      sa.NewBoolOffer(supportsReplication(&d.Config)),
+		sa.SnapshotDir:      sa.NewBoolOffer(true),
 		sa.ProvisioningType: sa.NewStringOffer("thick", "thin"),
-	}
+	}, &d.Config))
 }
 
 func (d *NASStorageDriver) GetVolumeOpts(
@@ -381,19 +960,133 @@ func (d *NASStorageDriver) GetInternalVolumeName(name string) string {
 	return getInternalVolumeNameCommon(d.Config.CommonStorageDriverConfig, name)
 }
 
+// GetInternalVolumeNameFromConfig names a volume from the backend's naming template, if it has one.
+func (d *NASStorageDriver) GetInternalVolumeNameFromConfig(volConfig *storage.VolumeConfig) (string, error) {
+	return getInternalVolumeNameFromTemplate(&d.Config, volConfig), nil
+}
+
 func (d *NASStorageDriver) CreatePrepare(volConfig *storage.VolumeConfig) bool {
-	return createPrepareCommon(d, volConfig)
+	return createPrepareCommon(d, &d.Config, volConfig)
 }
 
 func (d *NASStorageDriver) CreateFollowup(
 	volConfig *storage.VolumeConfig,
 ) error {
-	volConfig.AccessInfo.NfsServerIP = d.Config.DataLIF
-	volConfig.AccessInfo.NfsPath = "/" + volConfig.InternalName
+	if d.Config.NASType == NASTypeSMB {
+		volConfig.AccessInfo.SmbPath = SMBSharePath(volConfig.InternalName, &d.Config)
+	} else {
+		volConfig.AccessInfo.NfsServerIP = d.Config.DataLIF
+		volConfig.AccessInfo.NfsPath = "/" + volConfig.InternalName
+	}
 	volConfig.FileSystem = ""
 	return nil
 }
 
+// SetVolumeReadOnly exports a volume read-only, through a copy of its export policy that allows
+// no writes, or through its own export policy otherwise.
+func (d *NASStorageDriver) SetVolumeReadOnly(volConfig *storage.VolumeConfig) error {
+
+	policy := volConfig.ExportPolicy
+	if policy == "" {
+		policy = d.Config.ExportPolicy
+	}
+	if volConfig.ReadOnly {
+		var err error
+		if policy, err = ensureReadOnlyExportPolicy(policy, d.API); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"volume":       volConfig.InternalName,
+		"readOnly":     volConfig.ReadOnly,
+		"exportPolicy": policy,
+	}).Debug("Setting volume export policy.")
+
+	modifyResponse, err := d.API.VolumeSetExportPolicy(volConfig.InternalName, policy)
+	if err = api.GetError(modifyResponse, err); err != nil {
+		return fmt.Errorf("error setting export policy %s on volume %s: %v", policy, volConfig.InternalName, err)
+	}
+	return nil
+}
+
+// NormalizeVolume gives a Flexvol found on the storage the backend's export policy, snapshot
+// policy and security style where it has others, if the backend is configured to normalize the
+// volumes it finds.  A Flexvol exported read-only keeps its read-only export policy.
+func (d *NASStorageDriver) NormalizeVolume(volume *storage.VolumeExternal) error {
+
+	if !d.Config.NormalizeDiscoveredVolumes {
+		return nil
+	}
+
+	volConfig := volume.Config
+	name := volConfig.InternalName
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "NormalizeVolume", "Type": "NASStorageDriver", "name": name}
+		log.WithFields(fields).Debug(">>>> NormalizeVolume")
+		defer log.WithFields(fields).Debug("<<<< NormalizeVolume")
+	}
+
+	logFields := log.Fields{"volume": name}
+
+	exportPolicy := d.Config.ExportPolicy
+	if volConfig.ExportPolicy != exportPolicy && volConfig.ExportPolicy != readOnlyExportPolicy(exportPolicy) {
+		modifyResponse, err := d.API.VolumeSetExportPolicy(name, exportPolicy)
+		if err = api.GetError(modifyResponse, err); err != nil {
+			return fmt.Errorf("error setting export policy %s on volume %s: %v", exportPolicy, name, err)
+		}
+		logFields["exportPolicy"] = exportPolicy
+		volConfig.ExportPolicy = exportPolicy
+	}
+
+	snapshotPolicy := d.Config.SnapshotPolicy
+	if volConfig.SnapshotPolicy != snapshotPolicy {
+		modifyResponse, err := d.API.VolumeSetSnapshotPolicy(name, snapshotPolicy)
+		if err = api.GetError(modifyResponse, err); err != nil {
+			return fmt.Errorf("error setting snapshot policy %s on volume %s: %v", snapshotPolicy, name, err)
+		}
+		logFields["snapshotPolicy"] = snapshotPolicy
+		volConfig.SnapshotPolicy = snapshotPolicy
+	}
+
+	securityStyle := d.Config.SecurityStyle
+	if volConfig.SecurityStyle != securityStyle {
+		modifyResponse, err := d.API.VolumeSetSecurityStyle(name, securityStyle)
+		if err = api.GetError(modifyResponse, err); err != nil {
+			return fmt.Errorf("error setting security style %s on volume %s: %v", securityStyle, name, err)
+		}
+		logFields["securityStyle"] = securityStyle
+		volConfig.SecurityStyle = securityStyle
+	}
+
+	if len(logFields) > 1 {
+		log.WithFields(logFields).Info("Normalized volume found on the storage to the backend's defaults.")
+	}
+	return nil
+}
+
+// SetSnapshotDirVisible shows or hides the .snapshot directory of a Flexvol.
+func (d *NASStorageDriver) SetSnapshotDirVisible(name string, visible bool) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":  "SetSnapshotDirVisible",
+			"Type":    "NASStorageDriver",
+			"name":    name,
+			"visible": visible,
+		}
+		log.WithFields(fields).Debug(">>>> SetSnapshotDirVisible")
+		defer log.WithFields(fields).Debug("<<<< SetSnapshotDirVisible")
+	}
+
+	snapDirResponse, err := d.API.VolumeSetSnapshotDirectoryAccess(name, visible)
+	if err = api.GetError(snapDirResponse, err); err != nil {
+		return fmt.Errorf("error setting snapshot directory access on volume %s: %v", name, err)
+	}
+	return nil
+}
+
 func (d *NASStorageDriver) GetProtocol() trident.Protocol {
 	return trident.File
 }
@@ -405,6 +1098,16 @@ func (d *NASStorageDriver) StoreConfig(
 	b.OntapConfig = &d.Config
 }
 
+// StorageIdentity identifies the volumes the driver manages, so that duplicate backends can be
+// recognized.
+func (d *NASStorageDriver) StorageIdentity() string {
+	return StorageIdentity(&d.Config)
+}
+
+func (d *NASStorageDriver) Degraded() string {
+	return Degraded(&d.Config)
+}
+
 func (d *NASStorageDriver) GetExternalConfig() interface{} {
 	return getExternalConfig(d.Config)
 }
@@ -432,16 +1135,23 @@ func (d *NASStorageDriver) GetVolumeExternalWrappers(
 	// Let the caller know we're done by closing the channel
 	defer close(channel)
 
-	// Get all volumes matching the storage prefix
-	volumesResponse, err := d.API.VolumeGetAll(*d.Config.StoragePrefix)
-	if err = api.GetError(volumesResponse, err); err != nil {
-		channel <- &storage.VolumeExternalWrapper{nil, err}
-		return
-	}
+	// Each tenant's volumes are listed by their own prefix
+	for _, prefix := range storagePrefixes(&d.Config) {
+
+		// Get all volumes matching the prefix
+		volumesResponse, err := d.API.VolumeGetAll(prefix)
+		if err = api.GetError(volumesResponse, err); err != nil {
+			channel <- &storage.VolumeExternalWrapper{nil, err}
+			return
+		}
 
-	// Convert all volumes to VolumeExternal and write them to the channel
-	for _, volume := range volumesResponse.Result.AttributesList() {
-		channel <- &storage.VolumeExternalWrapper{d.getVolumeExternal(&volume), nil}
+		// Convert all volumes to VolumeExternal and write them to the channel
+		for _, volume := range volumesResponse.Result.AttributesList() {
+			if isQtreePoolFlexvol(string(volume.VolumeIdAttributesPtr.Name())) {
+				continue
+			}
+			channel <- &storage.VolumeExternalWrapper{d.getVolumeExternal(&volume), nil}
+		}
 	}
 }
 
@@ -451,35 +1161,51 @@ func (d *NASStorageDriver) GetVolumeExternalWrappers(
 func (d *NASStorageDriver) getVolumeExternal(
 	volumeAttrs *azgo.VolumeAttributesType) *storage.VolumeExternal {
 
-	volumeExportAttrs := volumeAttrs.VolumeExportAttributesPtr
 	volumeIDAttrs := volumeAttrs.VolumeIdAttributesPtr
-	volumeSecurityAttrs := volumeAttrs.VolumeSecurityAttributesPtr
-	volumeSecurityUnixAttrs := volumeSecurityAttrs.VolumeSecurityUnixAttributesPtr
-	volumeSpaceAttrs := volumeAttrs.VolumeSpaceAttributesPtr
-	volumeSnapshotAttrs := volumeAttrs.VolumeSnapshotAttributesPtr
 
 	internalName := string(volumeIDAttrs.Name())
-	name := internalName[len(*d.Config.StoragePrefix):]
+	tenant, name := splitStoragePrefix(&d.Config, internalName)
 
 	volumeConfig := &storage.VolumeConfig{
 		Version:         trident.OrchestratorAPIVersion,
 		Name:            name,
 		InternalName:    internalName,
-		Size:            strconv.FormatInt(int64(volumeSpaceAttrs.Size()), 10),
+		Namespace:       tenant,
+		Size:            "0",
 		Protocol:        trident.File,
-		SnapshotPolicy:  volumeSnapshotAttrs.SnapshotPolicy(),
-		ExportPolicy:    volumeExportAttrs.Policy(),
-		SnapshotDir:     strconv.FormatBool(volumeSnapshotAttrs.SnapdirAccessEnabled()),
-		UnixPermissions: volumeSecurityUnixAttrs.Permissions(),
+		SnapshotPolicy:  "",
+		ExportPolicy:    "",
+		SnapshotDir:     "false",
+		UnixPermissions: "",
 		StorageClass:    "",
 		AccessMode:      trident.ReadWriteMany,
 		AccessInfo:      storage.VolumeAccessInfo{},
 		BlockSize:       "",
 		FileSystem:      "",
 	}
+	setFlexvolExternalConfig(volumeConfig, volumeAttrs)
+	setFlexvolCloneSource(volumeConfig, volumeAttrs)
+
+	if volumeAttrs.VolumeSpaceAttributesPtr != nil && volumeAttrs.VolumeSpaceAttributesPtr.SizePtr != nil {
+		volumeConfig.Size = strconv.FormatInt(int64(volumeAttrs.VolumeSpaceAttributesPtr.Size()), 10)
+	}
+	if volumeAttrs.VolumeExportAttributesPtr != nil && volumeAttrs.VolumeExportAttributesPtr.PolicyPtr != nil {
+		volumeConfig.ExportPolicy = volumeAttrs.VolumeExportAttributesPtr.Policy()
+	}
+
+	// An unmounted Flexvol can't be reached over NFS or SMB until it is mounted again
+	if volumeIDAttrs.JunctionPathPtr != nil && volumeIDAttrs.JunctionPath() != "" {
+		if d.Config.NASType == NASTypeSMB {
+			volumeConfig.AccessInfo.SmbPath = SMBSharePath(internalName, &d.Config)
+		} else {
+			volumeConfig.AccessInfo.NfsServerIP = d.Config.DataLIF
+			volumeConfig.AccessInfo.NfsPath = string(volumeIDAttrs.JunctionPath())
+		}
+	}
 
 	return &storage.VolumeExternal{
 		Config: volumeConfig,
-		Pool:   volumeIDAttrs.ContainingAggregateName(),
+		Pool:   flexvolPool(volumeAttrs),
+		State:  flexvolState(volumeAttrs),
 	}
 }
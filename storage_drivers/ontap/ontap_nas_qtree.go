@@ -3,6 +3,7 @@
 package ontap
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -42,23 +43,32 @@ const (
 type NASQtreeStorageDriver struct {
 	initialized         bool
 	Config              drivers.OntapStorageDriverConfig
-	API                 *api.Client
+	API                 api.ZapiClient
 	Telemetry           *Telemetry
 	quotaResizeMap      map[string]bool
 	provMutex           *sync.Mutex
 	flexvolNamePrefix   string
 	flexvolExportPolicy string
-	housekeepingTasks   map[string]*HousekeepingTask
+	housekeeping        *utils.HousekeepingScheduler
 }
 
 func (d *NASQtreeStorageDriver) GetConfig() *drivers.OntapStorageDriverConfig {
 	return &d.Config
 }
 
-func (d *NASQtreeStorageDriver) GetAPI() *api.Client {
+func (d *NASQtreeStorageDriver) GetAPI() api.ZapiClient {
 	return d.API
 }
 
+func (d *NASQtreeStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *NASQtreeStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.API.SetDebugTraceFlags(flags)
+}
+
 func (d *NASQtreeStorageDriver) GetTelemetry() *Telemetry {
 	return d.Telemetry
 }
@@ -127,18 +137,20 @@ func (d *NASQtreeStorageDriver) Initialize(
 	d.queueAllFlexvolsForQuotaResize()
 
 	// Start periodic housekeeping tasks like cleaning up unused FlexVols
-	d.housekeepingTasks = make(map[string]*HousekeepingTask, 2)
-	pruneTasks := []func(){d.pruneUnusedFlexvols, d.reapDeletedQtrees}
-	d.housekeepingTasks[pruneTask] = NewPruneTask(d, pruneTasks)
-	resizeTasks := []func(){d.resizeQuotas}
-	d.housekeepingTasks[resizeTask] = NewResizeTask(d, resizeTasks)
-	for _, task := range d.housekeepingTasks {
-		task.Start()
+	d.housekeeping = NewHousekeepingScheduler(d)
+	for _, task := range []utils.HousekeepingTask{NewPruneTask(d), NewResizeTask(d), NewCircuitProbeTask(d)} {
+		if err = d.housekeeping.Schedule(task); err != nil {
+			d.housekeeping.Stop()
+			return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+		}
 	}
 
 	// Set up the autosupport heartbeat
 	d.Telemetry = NewOntapTelemetry(d)
-	d.Telemetry.Start()
+	if err = d.Telemetry.Start(d.housekeeping); err != nil {
+		d.housekeeping.Stop()
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
 
 	d.initialized = true
 	return nil
@@ -156,11 +168,13 @@ func (d *NASQtreeStorageDriver) Terminate() {
 		defer log.WithFields(fields).Debug("<<<< Terminate")
 	}
 
-	for _, task := range d.housekeepingTasks {
-		task.Stop()
-	}
 	d.Telemetry.Stop()
 
+	// Stopping the scheduler runs the prune and resize tasks one last time
+	if d.housekeeping != nil {
+		d.housekeeping.Stop()
+	}
+
 	d.initialized = false
 }
 
@@ -187,8 +201,15 @@ func (d *NASQtreeStorageDriver) validate() error {
 	return nil
 }
 
+// GetVolumeSize returns the size of the qtree that Create makes for a requested size.
+func (d *NASQtreeStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	return GetVolumeSize(sizeBytes, d.Config)
+}
+
 // Create a qtree-backed volume with the specified options
-func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+func (d *NASQtreeStorageDriver) Create(
+	ctx context.Context, name string, sizeBytes uint64, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -202,6 +223,10 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 		defer log.WithFields(fields).Debug("<<<< Create")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
 	// Ensure any Flexvol we create won't be pruned before we place a qtree on it
 	d.provMutex.Lock()
 	defer d.provMutex.Unlock()
@@ -210,21 +235,29 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 	createError := errors.New("volume creation failed")
 
 	// Ensure volume doesn't already exist
-	exists, existsInFlexvol, err := d.API.QtreeExists(name, d.FlexvolNamePrefix())
+	exists, existsInFlexvol, err := client.QtreeExists(name, d.FlexvolNamePrefix())
 	if err != nil {
 		log.Errorf("Error checking for existing volume: %v.", err)
 		return createError
 	}
-	if exists {
-		log.WithFields(log.Fields{"qtree": name, "flexvol": existsInFlexvol}).Debug("Qtree already exists.")
-		return fmt.Errorf("volume %s already exists", name)
-	}
 
-	sizeBytes, err = GetVolumeSize(sizeBytes, d.Config)
+	sizeBytes, err = d.GetVolumeSize(sizeBytes)
 	if err != nil {
 		return err
 	}
 
+	// An earlier attempt may have created the qtree and then failed to define its quota.  Setting
+	// the quota entry again is harmless, so doing so completes the create in either case.
+	if exists {
+		log.WithFields(log.Fields{"qtree": name, "flexvol": existsInFlexvol}).Info(
+			"Qtree already exists, completing any remaining creation steps.")
+		if err = d.addQuotaForQtree(name, existsInFlexvol, sizeBytes); err != nil {
+			log.Errorf("Qtree quota definition failed. %v", err)
+			return createError
+		}
+		return nil
+	}
+
 	// Ensure qtree name isn't too long
 	if len(name) > maxQtreeNameLength {
 		return fmt.Errorf("volume %s name exceeds the limit of %d characters", name, maxQtreeNameLength)
@@ -244,11 +277,23 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 		return fmt.Errorf("invalid boolean value for snapshotDir: %v", err)
 	}
 
-	encrypt, err := ValidateEncryptionAttribute(encryption, d.API)
+	encrypt, err := ValidateEncryptionAttribute(encryption, aggregate, &d.Config, client)
 	if err != nil {
 		return err
 	}
 
+	// Get qtree options with default fallback values
+	unixPermissions := utils.GetV(opts, "unixPermissions", d.Config.UnixPermissions)
+	exportPolicy := utils.GetV(opts, "exportPolicy", d.Config.ExportPolicy)
+	securityStyle := utils.GetV(opts, "securityStyle", d.Config.SecurityStyle)
+
+	if unixPermissions, err = ConvertUnixPermissions(unixPermissions); err != nil {
+		return err
+	}
+	if err = ValidateSecurityStyle(securityStyle); err != nil {
+		return err
+	}
+
 	// Make sure we have a Flexvol for the new qtree
 	flexvol, err := d.ensureFlexvolForQtree(
 		aggregate, spaceReserve, snapshotPolicy, enableSnapshotDir, encrypt)
@@ -263,7 +308,7 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 		log.Warnf("Could not calculate optimal Flexvol size. %v", err)
 
 		// Lacking the optimal size, just grow the Flexvol to contain the new qtree
-		resizeResponse, err := d.API.SetVolumeSize(flexvol, "+"+size)
+		resizeResponse, err := client.SetVolumeSize(flexvol, "+"+size)
 		if err = api.GetError(resizeResponse.Result, err); err != nil {
 			log.Errorf("Flexvol resize failed. %v", err)
 			return createError
@@ -272,27 +317,22 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 
 		// Got optimal size, so just set the Flexvol to that value
 		flexvolSizeStr := strconv.FormatUint(flexvolSizeBytes, 10)
-		resizeResponse, err := d.API.SetVolumeSize(flexvol, flexvolSizeStr)
+		resizeResponse, err := client.SetVolumeSize(flexvol, flexvolSizeStr)
 		if err = api.GetError(resizeResponse.Result, err); err != nil {
 			log.Errorf("Flexvol resize failed. %v", err)
 			return createError
 		}
 	}
 
-	// Get qtree options with default fallback values
-	unixPermissions := utils.GetV(opts, "unixPermissions", d.Config.UnixPermissions)
-	exportPolicy := utils.GetV(opts, "exportPolicy", d.Config.ExportPolicy)
-	securityStyle := utils.GetV(opts, "securityStyle", d.Config.SecurityStyle)
-
 	// Create the qtree
-	qtreeResponse, err := d.API.QtreeCreate(name, flexvol, unixPermissions, exportPolicy, securityStyle)
+	qtreeResponse, err := client.QtreeCreate(name, flexvol, unixPermissions, exportPolicy, securityStyle)
 	if err = api.GetError(qtreeResponse, err); err != nil {
 		log.Errorf("Qtree creation failed. %v", err)
 		return createError
 	}
 
 	// Add the quota
-	d.addQuotaForQtree(name, flexvol, sizeBytes)
+	err = d.addQuotaForQtree(name, flexvol, sizeBytes)
 	if err != nil {
 		log.Errorf("Qtree quota definition failed. %v", err)
 		return createError
@@ -302,7 +342,9 @@ func (d *NASQtreeStorageDriver) Create(name string, sizeBytes uint64, opts map[s
 }
 
 // Create a volume clone
-func (d *NASQtreeStorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *NASQtreeStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -317,11 +359,11 @@ func (d *NASQtreeStorageDriver) CreateClone(name, source, snapshot string, opts
 		defer log.WithFields(fields).Debug("<<<< CreateClone")
 	}
 
-	return errors.New("cloning with the ONTAP NAS Economy driver is not supported")
+	return drivers.NewUnsupportedError("cloning with the ONTAP NAS Economy driver is not supported")
 }
 
 // Destroy the volume
-func (d *NASQtreeStorageDriver) Destroy(name string) error {
+func (d *NASQtreeStorageDriver) Destroy(ctx context.Context, name string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -333,6 +375,10 @@ func (d *NASQtreeStorageDriver) Destroy(name string) error {
 		defer log.WithFields(fields).Debug("<<<< Destroy")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
 	// Ensure the deleted qtree reaping job doesn't interfere with this workflow
 	d.provMutex.Lock()
 	defer d.provMutex.Unlock()
@@ -340,7 +386,7 @@ func (d *NASQtreeStorageDriver) Destroy(name string) error {
 	// Generic user-facing message
 	deleteError := errors.New("volume deletion failed")
 
-	exists, flexvol, err := d.API.QtreeExists(name, d.FlexvolNamePrefix())
+	exists, flexvol, err := client.QtreeExists(name, d.FlexvolNamePrefix())
 	if err != nil {
 		log.Errorf("Error checking for existing qtree. %v", err)
 		return deleteError
@@ -360,14 +406,14 @@ func (d *NASQtreeStorageDriver) Destroy(name string) error {
 	}
 	deletedPath := fmt.Sprintf("/vol/%s/%s", flexvol, deletedName)
 
-	renameResponse, err := d.API.QtreeRename(path, deletedPath)
+	renameResponse, err := client.QtreeRename(path, deletedPath)
 	if err = api.GetError(renameResponse, err); err != nil {
 		log.Errorf("Qtree rename failed. %v", err)
 		return deleteError
 	}
 
 	// Destroy the qtree in the background.  If this fails, try to restore the original qtree name.
-	destroyResponse, err := d.API.QtreeDestroyAsync(deletedPath, true)
+	destroyResponse, err := client.QtreeDestroyAsync(deletedPath, true)
 	if err = api.GetError(destroyResponse, err); err != nil {
 		log.Errorf("Qtree async delete failed. %v", err)
 		defer d.API.QtreeRename(deletedPath, path)
@@ -547,6 +593,7 @@ func (d *NASQtreeStorageDriver) createFlexvolForQtree(
 	unixPermissions := "0700"
 	exportPolicy := d.flexvolExportPolicy
 	securityStyle := "unix"
+	tieringPolicy := d.Config.TieringPolicy // Flexvols are shared, so always use the backend's policy
 
 	encryption := false
 	if encrypt != nil {
@@ -564,16 +611,27 @@ func (d *NASQtreeStorageDriver) createFlexvolForQtree(
 		"exportPolicy":    exportPolicy,
 		"securityStyle":   securityStyle,
 		"encryption":      encryption,
+		"tieringPolicy":   tieringPolicy,
 	}).Debug("Creating Flexvol for qtrees.")
 
+	if err := CheckVolumeLimit(flexvol, aggregate, &d.Config, d.API); err != nil {
+		return "", err
+	}
+
 	// Create the Flexvol
 	createResponse, err := d.API.VolumeCreate(
 		flexvol, aggregate, size, spaceReserve, snapshotPolicy,
-		unixPermissions, exportPolicy, securityStyle, encrypt)
+		unixPermissions, exportPolicy, securityStyle, tieringPolicy, encrypt)
 	if err = api.GetError(createResponse, err); err != nil {
 		return "", fmt.Errorf("error creating Flexvol: %v", err)
 	}
 
+	// Apply any ONTAP options from the backend config that Trident doesn't model
+	if err = ApplyAdvancedOptions(flexvol, &d.Config, d.API); err != nil {
+		defer d.API.VolumeDestroy(flexvol, true)
+		return "", err
+	}
+
 	// Disable '.snapshot' as needed
 	if !enableSnapshotDir {
 		snapDirResponse, err := d.API.VolumeDisableSnapshotDirectoryAccess(flexvol)
@@ -591,7 +649,9 @@ func (d *NASQtreeStorageDriver) createFlexvolForQtree(
 	}
 
 	// If LS mirrors are present on the SVM root volume, update them
-	UpdateLoadSharingMirrors(d.API)
+	if ShouldUpdateLoadSharingMirrors(&d.Config) {
+		UpdateLoadSharingMirrors(d.API, d.Config.LSMirrorTimeoutDuration)
+	}
 
 	// Create the default quota rule so we can use quota-resize for new qtrees
 	err = d.addDefaultQuotaForFlexvol(flexvol)
@@ -994,6 +1054,11 @@ func (d *NASQtreeStorageDriver) ensureDefaultExportPolicyRule() error {
 	return nil
 }
 
+// GetPoolCapacity reports the space in the aggregate backing a storage pool.
+func (d *NASQtreeStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+	return getPoolCapacityCommon(d.API, pool)
+}
+
 // Retrieve storage backend capabilities
 func (d *NASQtreeStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 
@@ -1008,7 +1073,11 @@ func (d *NASQtreeStorageDriver) GetStoragePoolAttributes() map[string]sa.Offer {
 		sa.BackendType:      sa.NewStringOffer(d.Name()),
 		sa.Snapshots:        sa.NewBoolOffer(false),
 		sa.Clones:           sa.NewBoolOffer(false),
-		sa.Encryption:       sa.NewBoolOffer(d.API.SupportsFeature(api.NetAppVolumeEncryption)),
+		sa.Encryption:       sa.NewBoolOffer(SupportsVersionFeature(&d.Config, api.NetAppVolumeEncryption)),
+		sa.Resize:           sa.NewBoolOffer(false),
+		sa.QoS:              sa.NewBoolOffer(false),
+		sa.Replication:      sa.NewBoolOffer(false),
+		sa.SnapshotDir:      sa.NewBoolOffer(true),
 		sa.ProvisioningType: sa.NewStringOffer("thick", "thin"),
 	}
 }
@@ -1025,8 +1094,13 @@ func (d *NASQtreeStorageDriver) GetInternalVolumeName(name string) string {
 	return getInternalVolumeNameCommon(d.Config.CommonStorageDriverConfig, name)
 }
 
+// GetInternalVolumeNameFromConfig names a volume from the backend's naming template, if it has one.
+func (d *NASQtreeStorageDriver) GetInternalVolumeNameFromConfig(volConfig *storage.VolumeConfig) (string, error) {
+	return getInternalVolumeNameFromTemplate(&d.Config, volConfig), nil
+}
+
 func (d *NASQtreeStorageDriver) CreatePrepare(volConfig *storage.VolumeConfig) bool {
-	return createPrepareCommon(d, volConfig)
+	return createPrepareCommon(d, &d.Config, volConfig)
 }
 
 func (d *NASQtreeStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error {
@@ -1056,6 +1130,16 @@ func (d *NASQtreeStorageDriver) StoreConfig(b *storage.PersistentStorageBackendC
 	b.OntapConfig = &d.Config
 }
 
+// StorageIdentity identifies the volumes the driver manages, so that duplicate backends can be
+// recognized.
+func (d *NASQtreeStorageDriver) StorageIdentity() string {
+	return StorageIdentity(&d.Config)
+}
+
+func (d *NASQtreeStorageDriver) Degraded() string {
+	return Degraded(&d.Config)
+}
+
 func (d *NASQtreeStorageDriver) GetExternalConfig() interface{} {
 	return getExternalConfig(d.Config)
 }
@@ -1160,9 +1244,6 @@ func (d *NASQtreeStorageDriver) getVolumeExternal(
 	qtreeAttrs *azgo.QtreeInfoType, volumeAttrs *azgo.VolumeAttributesType,
 	quotaAttrs *azgo.QuotaEntryType) *storage.VolumeExternal {
 
-	volumeIDAttrs := volumeAttrs.VolumeIdAttributesPtr
-	volumeSnapshotAttrs := volumeAttrs.VolumeSnapshotAttributesPtr
-
 	internalName := qtreeAttrs.Qtree()
 	name := internalName[len(*d.Config.StoragePrefix):]
 
@@ -1179,10 +1260,10 @@ func (d *NASQtreeStorageDriver) getVolumeExternal(
 		InternalName:    internalName,
 		Size:            strconv.FormatInt(size, 10),
 		Protocol:        trident.File,
-		SnapshotPolicy:  volumeSnapshotAttrs.SnapshotPolicy(),
-		ExportPolicy:    qtreeAttrs.ExportPolicy(),
-		SnapshotDir:     strconv.FormatBool(volumeSnapshotAttrs.SnapdirAccessEnabled()),
-		UnixPermissions: qtreeAttrs.Mode(),
+		SnapshotPolicy:  "",
+		ExportPolicy:    "",
+		SnapshotDir:     "false",
+		UnixPermissions: "",
 		StorageClass:    "",
 		AccessMode:      trident.ReadWriteMany,
 		AccessInfo:      storage.VolumeAccessInfo{},
@@ -1190,61 +1271,34 @@ func (d *NASQtreeStorageDriver) getVolumeExternal(
 		FileSystem:      "",
 	}
 
-	return &storage.VolumeExternal{
-		Config: volumeConfig,
-		Pool:   volumeIDAttrs.ContainingAggregateName(),
+	// The qtree shares its Flexvol's snapshots and encryption, but has its own permissions
+	setFlexvolExternalConfig(volumeConfig, volumeAttrs)
+	if qtreeAttrs.ExportPolicyPtr != nil {
+		volumeConfig.ExportPolicy = qtreeAttrs.ExportPolicy()
+	}
+	if qtreeAttrs.ModePtr != nil {
+		volumeConfig.UnixPermissions = qtreeAttrs.Mode()
+	}
+	if qtreeAttrs.SecurityStylePtr != nil {
+		volumeConfig.SecurityStyle = qtreeAttrs.SecurityStyle()
 	}
-}
-
-type HousekeepingTask struct {
-	Name         string
-	Ticker       *time.Ticker
-	InitialDelay time.Duration
-	Done         chan struct{}
-	Tasks        []func()
-	Driver       *NASQtreeStorageDriver
-}
-
-func (t *HousekeepingTask) Start() {
-	go func() {
-		time.Sleep(t.InitialDelay)
-		t.run(time.Now())
-		for {
-			select {
-			case tick := <-t.Ticker.C:
-				t.run(tick)
-			case <-t.Done:
-				log.WithFields(log.Fields{
-					"driver": t.Driver.Name(),
-					"task":   t.Name,
-				}).Debugf("Shut down housekeeping tasks for the driver.")
-				return
-			}
-		}
-	}()
-}
 
-func (t *HousekeepingTask) Stop() {
-	t.Ticker.Stop()
-	close(t.Done)
-	// Run the housekeeping tasks one last time
-	for _, task := range t.Tasks {
-		task()
+	volumeIDAttrs := volumeAttrs.VolumeIdAttributesPtr
+	if volumeIDAttrs != nil && volumeIDAttrs.JunctionPathPtr != nil && volumeIDAttrs.JunctionPath() != "" {
+		volumeConfig.AccessInfo.NfsServerIP = d.Config.DataLIF
+		volumeConfig.AccessInfo.NfsPath = fmt.Sprintf("%s/%s", volumeIDAttrs.JunctionPath(), internalName)
 	}
-}
 
-func (t *HousekeepingTask) run(tick time.Time) {
-	for i, task := range t.Tasks {
-		log.WithFields(log.Fields{
-			"tick":   tick,
-			"driver": t.Driver.Name(),
-			"task":   t.Name,
-		}).Debugf("Performing housekeeping task %v.", i)
-		task()
+	return &storage.VolumeExternal{
+		Config: volumeConfig,
+		Pool:   flexvolPool(volumeAttrs),
+		State:  flexvolState(volumeAttrs),
 	}
 }
 
-func NewPruneTask(d *NASQtreeStorageDriver, tasks []func()) *HousekeepingTask {
+// NewPruneTask returns the housekeeping task that deletes Flexvols emptied of qtrees, along with
+// qtrees whose deletion was deferred.
+func NewPruneTask(d *NASQtreeStorageDriver) utils.HousekeepingTask {
 	// Read background task timings from config file, use defaults if missing or invalid
 	pruneFlexvolsPeriodSecs := defaultPruneFlexvolsPeriodSecs
 	if d.Config.QtreePruneFlexvolsPeriod != "" {
@@ -1260,19 +1314,22 @@ func NewPruneTask(d *NASQtreeStorageDriver, tasks []func()) *HousekeepingTask {
 		"IntervalSeconds": pruneFlexvolsPeriodSecs,
 	}).Debug("Configured Flexvol pruning period.")
 
-	task := &HousekeepingTask{
+	return utils.HousekeepingTask{
 		Name:         pruneTask,
-		Ticker:       time.NewTicker(time.Duration(pruneFlexvolsPeriodSecs) * time.Second),
+		Interval:     time.Duration(pruneFlexvolsPeriodSecs) * time.Second,
 		InitialDelay: HousekeepingStartupDelaySecs * time.Second,
-		Done:         make(chan struct{}),
-		Tasks:        tasks,
-		Driver:       d,
+		Jitter:       HousekeepingMaxJitterSecs * time.Second,
+		RunOnStop:    true,
+		Run: func() {
+			d.pruneUnusedFlexvols()
+			d.reapDeletedQtrees()
+		},
 	}
-
-	return task
 }
 
-func NewResizeTask(d *NASQtreeStorageDriver, tasks []func()) *HousekeepingTask {
+// NewResizeTask returns the housekeeping task that grows the quotas of Flexvols in which qtrees
+// were created or resized.
+func NewResizeTask(d *NASQtreeStorageDriver) utils.HousekeepingTask {
 	// Read background task timings from config file, use defaults if missing or invalid
 	resizeQuotasPeriodSecs := defaultResizeQuotasPeriodSecs
 	if d.Config.QtreeQuotaResizePeriod != "" {
@@ -1288,14 +1345,12 @@ func NewResizeTask(d *NASQtreeStorageDriver, tasks []func()) *HousekeepingTask {
 		"IntervalSeconds": resizeQuotasPeriodSecs,
 	}).Debug("Configured quota resize period.")
 
-	task := &HousekeepingTask{
+	return utils.HousekeepingTask{
 		Name:         resizeTask,
-		Ticker:       time.NewTicker(time.Duration(resizeQuotasPeriodSecs) * time.Second),
+		Interval:     time.Duration(resizeQuotasPeriodSecs) * time.Second,
 		InitialDelay: HousekeepingStartupDelaySecs * time.Second,
-		Done:         make(chan struct{}),
-		Tasks:        tasks,
-		Driver:       d,
+		Jitter:       HousekeepingMaxJitterSecs * time.Second,
+		RunOnStop:    true,
+		Run:          d.resizeQuotas,
 	}
-
-	return task
 }
@@ -0,0 +1,41 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func TestNASQtreeCreateResumesQuota(t *testing.T) {
+	client := &mockClient{
+		qtrees:       map[string]string{"trident_pvc1": "trident_qtree_pool_abc"},
+		quotaEntries: map[string]string{},
+		quotaErr:     errors.New("connection reset"),
+	}
+	d := &NASQtreeStorageDriver{API: client, quotaResizeMap: map[string]bool{}, provMutex: &sync.Mutex{}}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	ctx := context.Background()
+
+	// An existing qtree whose quota can't be defined isn't complete
+	if err := d.Create(ctx, "trident_pvc1", 1073741824, map[string]string{}); err == nil {
+		t.Fatal("Expected the failure to define the quota to be returned")
+	}
+
+	// The retry defines the quota of the qtree left by the earlier attempt
+	client.quotaErr = nil
+	if err := d.Create(ctx, "trident_pvc1", 1073741824, map[string]string{}); err != nil {
+		t.Fatalf("Unable to resume the create: %v", err)
+	}
+	target := "/vol/trident_qtree_pool_abc/trident_pvc1"
+	if client.quotaEntries[target] != "1048576" {
+		t.Errorf("Expected a 1 GiB quota for %s, got %v", target, client.quotaEntries)
+	}
+	if !d.quotaResizeMap["trident_qtree_pool_abc"] {
+		t.Error("Expected the Flexvol to be marked for a quota resize")
+	}
+}
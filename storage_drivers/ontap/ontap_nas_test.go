@@ -0,0 +1,226 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+	"github.com/netapp/trident/utils"
+)
+
+func TestNASGetVolumeExternal(t *testing.T) {
+	prefix := "trident_"
+	d := &NASStorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix}
+	d.Config.DataLIF = "10.0.0.1"
+
+	// A volume found on the storage is described as it is there, not as the backend would create it
+	d.Config.ExportPolicy = "trident_policy"
+	d.Config.SnapshotPolicy = "none"
+	d.Config.SecurityStyle = "unix"
+	volumeAttrs := azgo.NewVolumeAttributesType().
+		SetVolumeIdAttributes(*azgo.NewVolumeIdAttributesType().
+			SetName("trident_vol1").
+			SetContainingAggregateName("aggr1").
+			SetJunctionPath("/trident_vol1")).
+		SetVolumeSpaceAttributes(*azgo.NewVolumeSpaceAttributesType().SetSize(2147483648)).
+		SetVolumeExportAttributes(*azgo.NewVolumeExportAttributesType().SetPolicy("default")).
+		SetVolumeSnapshotAttributes(*azgo.NewVolumeSnapshotAttributesType().
+			SetSnapshotPolicy("daily").
+			SetSnapdirAccessEnabled(true)).
+		SetVolumeSecurityAttributes(*azgo.NewVolumeSecurityAttributesType().
+			SetStyle("mixed").
+			SetVolumeSecurityUnixAttributes(*azgo.NewVolumeSecurityUnixAttributesType().SetPermissions("0755")))
+
+	volume := d.getVolumeExternal(volumeAttrs)
+	if volume.Config.Name != "vol1" || volume.Config.Size != "2147483648" || volume.Pool != "aggr1" {
+		t.Errorf("Expected 2 GiB volume vol1 in aggr1, got %+v in %s", volume.Config, volume.Pool)
+	}
+	if volume.Config.ExportPolicy != "default" || volume.Config.SnapshotPolicy != "daily" ||
+		volume.Config.SecurityStyle != "mixed" || volume.Config.UnixPermissions != "0755" ||
+		volume.Config.SnapshotDir != "true" {
+		t.Errorf("Expected the volume's attributes on the storage, got %+v", volume.Config)
+	}
+	if volume.Config.AccessInfo.NfsServerIP != "10.0.0.1" || volume.Config.AccessInfo.NfsPath != "/trident_vol1" {
+		t.Errorf("Expected the volume to be reached at its junction, got %+v", volume.Config.AccessInfo)
+	}
+
+	// An unmounted volume can't be reached
+	volumeAttrs.VolumeIdAttributesPtr.SetJunctionPath("")
+	volume = d.getVolumeExternal(volumeAttrs)
+	if volume.Config.AccessInfo.NfsPath != "" {
+		t.Errorf("Expected no NFS path for an unmounted volume, got %s", volume.Config.AccessInfo.NfsPath)
+	}
+}
+
+func TestNASNormalizeVolume(t *testing.T) {
+	client := &mockClient{volumeAttributes: map[string]map[string]string{}}
+	d := &NASStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	d.Config.ExportPolicy = "trident_policy"
+	d.Config.SnapshotPolicy = "none"
+	d.Config.SecurityStyle = "unix"
+
+	newVolume := func(exportPolicy string) *storage.VolumeExternal {
+		return &storage.VolumeExternal{Config: &storage.VolumeConfig{
+			InternalName:   "trident_vol1",
+			ExportPolicy:   exportPolicy,
+			SnapshotPolicy: "daily",
+			SecurityStyle:  "mixed",
+		}}
+	}
+
+	// Volumes found on the storage are described as they are unless the backend normalizes them
+	volume := newVolume("default")
+	if err := d.NormalizeVolume(volume); err != nil {
+		t.Fatal("Unable to normalize volume: ", err)
+	}
+	if len(client.volumeAttributes) != 0 || volume.Config.ExportPolicy != "default" {
+		t.Errorf("Expected the volume to be left alone, got %v", client.volumeAttributes)
+	}
+
+	d.Config.NormalizeDiscoveredVolumes = true
+	if err := d.NormalizeVolume(volume); err != nil {
+		t.Fatal("Unable to normalize volume: ", err)
+	}
+	expected := map[string]string{"exportPolicy": "trident_policy", "snapshotPolicy": "none", "securityStyle": "unix"}
+	if !reflect.DeepEqual(client.volumeAttributes["trident_vol1"], expected) {
+		t.Errorf("Expected the backend's defaults to be set, got %v", client.volumeAttributes["trident_vol1"])
+	}
+	if volume.Config.ExportPolicy != "trident_policy" || volume.Config.SnapshotPolicy != "none" ||
+		volume.Config.SecurityStyle != "unix" {
+		t.Errorf("Expected the volume to be described by the backend's defaults, got %+v", volume.Config)
+	}
+
+	// A volume exported read-only stays read-only
+	client.volumeAttributes = map[string]map[string]string{}
+	volume = newVolume(readOnlyExportPolicy("trident_policy"))
+	if err := d.NormalizeVolume(volume); err != nil {
+		t.Fatal("Unable to normalize volume: ", err)
+	}
+	if _, ok := client.volumeAttributes["trident_vol1"]["exportPolicy"]; ok {
+		t.Error("Expected the read-only export policy to be kept")
+	}
+	if volume.Config.ExportPolicy != "trident_policy_ro" || volume.Config.SnapshotPolicy != "none" {
+		t.Errorf("Expected only the read-only export policy to be kept, got %+v", volume.Config)
+	}
+}
+
+func TestNASCreateExistingMountedVolume(t *testing.T) {
+	client := &mockClient{
+		volumeStates: map[string]string{"trident_vol1": "online"},
+		junctions:    map[string]string{"trident_vol1": "/trident_vol1"},
+	}
+	d := &NASStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+
+	// A mounted volume was completed by an earlier attempt, so nothing is left to do
+	if err := d.Create(context.Background(), "trident_vol1", 1073741824, map[string]string{}); err != nil {
+		t.Errorf("Expected the existing volume to be complete, got %v", err)
+	}
+}
+
+func TestNASCreateResumesAdvancedOptions(t *testing.T) {
+	client := &mockClient{
+		volumeStates:    map[string]string{"trident_vol1": "online"},
+		junctions:       map[string]string{},
+		volumeOptions:   map[string]map[string]string{},
+		volumeOptionErr: errors.New("connection reset"),
+	}
+	d := &NASStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	d.Config.ONTAPSelect = true
+	d.Config.SingleNode = true
+	d.Config.AdvancedOptions = map[string]string{"no_atime_update": "on"}
+	opts := map[string]string{"snapshotDir": "true"}
+
+	// A new volume whose options can't be set is left unmounted, and the create is retried
+	if err := d.finishCreate(client, "trident_vol1", true); !drivers.IsRetryableError(err) {
+		t.Fatalf("Expected a retryable error, got %v", err)
+	}
+	if _, ok := client.junctions["trident_vol1"]; ok {
+		t.Error("Expected the volume to be left unmounted")
+	}
+
+	// The retry finds the volume, sets its options and mounts it
+	client.volumeOptionErr = nil
+	if err := d.resumeCreate(client, "trident_vol1", opts); err != nil {
+		t.Fatalf("Unable to resume the create: %v", err)
+	}
+	if client.volumeOptions["trident_vol1"]["no_atime_update"] != "on" {
+		t.Errorf("Expected the volume's options to be set, got %v", client.volumeOptions["trident_vol1"])
+	}
+	if client.junctions["trident_vol1"] != "/trident_vol1" {
+		t.Error("Expected the resumed volume to be mounted")
+	}
+}
+
+func TestNASCreateCopyCloneInProgress(t *testing.T) {
+	client := &mockClient{volumeStates: map[string]string{"trident_copy1": "online"}}
+	d := &NASStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	ctx := context.Background()
+
+	// The clone can't be used while its data is being copied
+	jobID := utils.StartJob(copyCloneJobType, "trident_copy1")
+	if err := d.createCopyClone(ctx, "trident_copy1", "trident_vol1", "", nil); !drivers.IsRetryableError(err) {
+		t.Errorf("Expected a retryable error while copying, got %v", err)
+	}
+
+	// Once the copy succeeds, the clone is complete
+	utils.FinishJob(jobID, nil)
+	if err := d.createCopyClone(ctx, "trident_copy1", "trident_vol1", "", nil); err != nil {
+		t.Errorf("Expected the copied clone to be complete, got %v", err)
+	}
+
+	// A clone whose copy failed is destroyed, so that the next attempt starts over
+	jobID = utils.StartJob(copyCloneJobType, "trident_copy1")
+	utils.FinishJob(jobID, errors.New("copy failed"))
+	if err := d.createCopyClone(ctx, "trident_copy1", "trident_vol1", "", nil); err == nil ||
+		drivers.IsRetryableError(err) {
+		t.Errorf("Expected the failed copy to be reported, got %v", err)
+	}
+	if _, ok := client.volumeStates["trident_copy1"]; ok {
+		t.Error("Expected the partly copied clone to be destroyed")
+	}
+}
+
+func TestNASReconcileCopyClone(t *testing.T) {
+	client := &mockClient{volumeStates: map[string]string{"trident_copy2": "online", "trident_copy3": "online"}}
+	d := &NASStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+
+	// A copy still running in this process is left to finish
+	jobID := utils.StartJob(copyCloneJobType, "trident_copy2")
+	entry := drivers.NewJournalEntry(JournalOperationCopyClone, "trident_copy2", map[string]string{"job": jobID})
+	if err := d.ReconcileJournalEntry(entry); err == nil {
+		t.Error("Expected a running copy to keep its journal entry")
+	}
+	if _, ok := client.volumeStates["trident_copy2"]; !ok {
+		t.Error("Expected the clone being copied to be kept")
+	}
+
+	// A successful copy keeps its clone
+	utils.FinishJob(jobID, nil)
+	if err := d.ReconcileJournalEntry(entry); err != nil {
+		t.Errorf("Unexpected error reconciling a finished copy: %v", err)
+	}
+	if _, ok := client.volumeStates["trident_copy2"]; !ok {
+		t.Error("Expected the copied clone to be kept")
+	}
+
+	// A copy interrupted by a restart has no job, and its clone is destroyed
+	entry = drivers.NewJournalEntry(JournalOperationCopyClone, "trident_copy3", map[string]string{"job": "lost"})
+	if err := d.ReconcileJournalEntry(entry); err != nil {
+		t.Errorf("Unexpected error reconciling an interrupted copy: %v", err)
+	}
+	if _, ok := client.volumeStates["trident_copy3"]; ok {
+		t.Error("Expected the partly copied clone to be destroyed")
+	}
+}
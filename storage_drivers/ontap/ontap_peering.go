@@ -0,0 +1,234 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+// Applications an SVM peering must allow for the features that use it
+const (
+	PeerApplicationSnapMirror = "snapmirror"
+	PeerApplicationFlexCache  = "flexcache"
+
+	svmPeerStatePeered          = "peered"
+	interclusterUnavailable     = "unavailable"
+	interclusterPartlyAvailable = "partial"
+)
+
+// ValidateSVMPeers finds the SVMs peered with the backend's SVM, along with the health of the
+// intercluster LIFs linking this cluster to theirs, and records them in the config.  Each SVM
+// named by peerSVMs must be peered and reachable, while problems with other peers are only logged,
+// as they may not be used.  If the peers can't be read, the check is skipped unless peerSVMs is set.
+func ValidateSVMPeers(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "ValidateSVMPeers", "Type": "ontap_common"}
+		log.WithFields(fields).Debug(">>>> ValidateSVMPeers")
+		defer log.WithFields(fields).Debug("<<<< ValidateSVMPeers")
+	}
+
+	if !IsFeatureAvailable(config, FeatureSVMPeers) {
+		if len(config.PeerSVMs) > 0 {
+			return fmt.Errorf("the user's role doesn't allow reading the peers of SVM %s", config.SVM)
+		}
+		return nil
+	}
+
+	peers, err := GetSVMPeers(client, config)
+	if err != nil {
+		if len(config.PeerSVMs) > 0 {
+			return fmt.Errorf("could not read the peers of SVM %s: %v", config.SVM, err)
+		}
+		log.WithField("svm", config.SVM).Debugf("Could not read SVM peers. %v", err)
+		return nil
+	}
+	config.SVMPeers = peers
+
+	for _, peer := range peers {
+		fields := log.Fields{
+			"svm":          config.SVM,
+			"peerSVM":      peer.SVM,
+			"peerCluster":  peer.Cluster,
+			"state":        peer.State,
+			"applications": strings.Join(peer.Applications, ","),
+			"intercluster": peer.Intercluster,
+		}
+		if err := checkSVMPeer(&peer); err != nil {
+			log.WithFields(fields).Warnf("SVM peer can't be used. %v", err)
+		} else if peer.Intercluster == interclusterPartlyAvailable {
+			fields["activeAddresses"] = strings.Join(peer.ActiveAddresses, ",")
+			fields["peerAddresses"] = strings.Join(peer.PeerAddresses, ",")
+			log.WithFields(fields).Warn("Only some intercluster LIFs of the SVM peer's cluster are reachable.")
+		} else {
+			log.WithFields(fields).Debug("SVM peer.")
+		}
+	}
+
+	for _, name := range config.PeerSVMs {
+		peer := FindSVMPeer(config, name)
+		if peer == nil {
+			return fmt.Errorf("SVM %s is not peered with SVM %s", name, config.SVM)
+		}
+		if err = checkSVMPeer(peer); err != nil {
+			return fmt.Errorf("SVM %s can't be used: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// GetSVMPeers returns the SVMs peered with the backend's SVM, sorted by name.  The health of the
+// intercluster LIFs is only read if the user has cluster scope.
+func GetSVMPeers(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) ([]drivers.OntapSVMPeer, error) {
+
+	response, err := client.VserverPeerGetIterRequest()
+	if err = api.GetError(response, err); err != nil {
+		return nil, err
+	}
+
+	peers := make([]drivers.OntapSVMPeer, 0)
+	for _, info := range response.Result.AttributesList() {
+		peer := drivers.OntapSVMPeer{Applications: make([]string, 0)}
+		if info.PeerVserverPtr != nil {
+			peer.SVM = info.PeerVserver()
+		}
+		if info.RemoteVserverNamePtr != nil && info.RemoteVserverName() != "" &&
+			info.RemoteVserverName() != peer.SVM {
+			peer.LocalName = peer.SVM
+			peer.SVM = info.RemoteVserverName()
+		}
+		if info.PeerClusterPtr != nil {
+			peer.Cluster = info.PeerCluster()
+		}
+		if info.PeerStatePtr != nil {
+			peer.State = string(info.PeerState())
+		}
+		for _, application := range info.Applications() {
+			peer.Applications = append(peer.Applications, string(application))
+		}
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].SVM < peers[j].SVM })
+
+	// Peers on other clusters are reached through the clusters' intercluster LIFs
+	clusterResponse, err := client.ClusterPeerGetIterRequest()
+	if err = api.GetError(clusterResponse, err); err != nil {
+		log.WithField("svm", config.SVM).Debugf("Could not read the health of intercluster LIFs. %v", err)
+		return peers, nil
+	}
+	for _, cluster := range clusterResponse.Result.AttributesList() {
+		for i := range peers {
+			if !isClusterPeer(&cluster, peers[i].Cluster) {
+				continue
+			}
+			if cluster.AvailabilityPtr != nil {
+				peers[i].Intercluster = cluster.Availability()
+			}
+			for _, address := range cluster.ActiveAddresses() {
+				peers[i].ActiveAddresses = append(peers[i].ActiveAddresses, string(address))
+			}
+			for _, address := range cluster.PeerAddresses() {
+				peers[i].PeerAddresses = append(peers[i].PeerAddresses, string(address))
+			}
+		}
+	}
+	return peers, nil
+}
+
+// isClusterPeer returns true if a cluster peer is the named cluster, which SVM peers may know by
+// either its local or its remote name.
+func isClusterPeer(cluster *azgo.ClusterPeerInfoType, name string) bool {
+	if name == "" {
+		return false
+	}
+	return (cluster.ClusterNamePtr != nil && cluster.ClusterName() == name) ||
+		(cluster.RemoteClusterNamePtr != nil && cluster.RemoteClusterName() == name)
+}
+
+// FindSVMPeer returns the peer of the backend's SVM with the name, which may be either its own
+// name or the name it is known by on this cluster, or nil if there is none.
+func FindSVMPeer(config *drivers.OntapStorageDriverConfig, name string) *drivers.OntapSVMPeer {
+	for i, peer := range config.SVMPeers {
+		if peer.SVM == name || (peer.LocalName != "" && peer.LocalName == name) {
+			return &config.SVMPeers[i]
+		}
+	}
+	return nil
+}
+
+// PeerSVMName returns the name by which the backend's SVM knows an SVM, which differs from the
+// SVM's own name if the peering gave it a local name.
+func PeerSVMName(config *drivers.OntapStorageDriverConfig, name string) string {
+	if peer := FindSVMPeer(config, name); peer != nil && peer.LocalName != "" {
+		return peer.LocalName
+	}
+	return name
+}
+
+// CanUseSVMPeer reports whether volumes of another backend's SVM may be used by a feature that
+// needs the SVMs to be peered for an application, such as SnapMirror.  The SVM may be the
+// backend's own, and if the peers couldn't be read, any SVM is assumed to be usable.
+func CanUseSVMPeer(config, peerConfig *drivers.OntapStorageDriverConfig, application string) bool {
+
+	if isSameSVM(config, peerConfig) {
+		return true
+	}
+	if config.SVMPeers == nil {
+		return true
+	}
+	peer := FindSVMPeer(config, peerConfig.SVM)
+	if peer == nil {
+		log.WithFields(log.Fields{
+			"svm":     config.SVM,
+			"peerSVM": peerConfig.SVM,
+		}).Debug("SVM is not peered.")
+		return false
+	}
+	if err := checkSVMPeer(peer); err != nil {
+		log.WithFields(log.Fields{
+			"svm":     config.SVM,
+			"peerSVM": peerConfig.SVM,
+		}).Debugf("SVM peer can't be used. %v", err)
+		return false
+	}
+	for _, peerApplication := range peer.Applications {
+		if peerApplication == application {
+			return true
+		}
+	}
+	log.WithFields(log.Fields{
+		"svm":         config.SVM,
+		"peerSVM":     peerConfig.SVM,
+		"application": application,
+	}).Debug("SVM peering doesn't allow the application.")
+	return false
+}
+
+// isSameSVM returns true if two backends manage the same SVM, which is compared by UUID when both
+// are known, since SVMs on different clusters may share a name.
+func isSameSVM(config, otherConfig *drivers.OntapStorageDriverConfig) bool {
+	if config.SVMUUID != "" && otherConfig.SVMUUID != "" {
+		return config.SVMUUID == otherConfig.SVMUUID
+	}
+	return config.SVM == otherConfig.SVM
+}
+
+// checkSVMPeer returns an error if a peer's state or intercluster LIFs keep it from being used.
+// Intercluster LIFs that are only partly reachable are allowed, as transfers may still succeed.
+func checkSVMPeer(peer *drivers.OntapSVMPeer) error {
+	if peer.State != svmPeerStatePeered {
+		return fmt.Errorf("peering is %s", peer.State)
+	}
+	if peer.Intercluster == interclusterUnavailable {
+		return fmt.Errorf("no intercluster LIFs of cluster %s are reachable", peer.Cluster)
+	}
+	return nil
+}
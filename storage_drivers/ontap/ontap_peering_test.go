@@ -0,0 +1,137 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+func newSVMPeer(name, remoteName, cluster, state string, applications ...string) azgo.VserverPeerInfoType {
+	peer := azgo.NewVserverPeerInfoType().
+		SetVserver("svm").
+		SetPeerVserver(name).
+		SetPeerCluster(cluster).
+		SetPeerState(azgo.VserverPeerStateType(state))
+	if remoteName != "" {
+		peer.SetRemoteVserverName(remoteName)
+	}
+	peerApplications := make([]azgo.VserverPeerApplicationType, 0)
+	for _, application := range applications {
+		peerApplications = append(peerApplications, azgo.VserverPeerApplicationType(application))
+	}
+	peer.SetApplications(peerApplications)
+	return *peer
+}
+
+func newPeeringClient() *mockClient {
+	return &mockClient{
+		svmPeers: []azgo.VserverPeerInfoType{
+			newSVMPeer("svm_local", "", "cluster1", "peered", PeerApplicationSnapMirror, PeerApplicationFlexCache),
+			newSVMPeer("svm_dr_local", "svm_dr", "cluster2", "peered", PeerApplicationSnapMirror),
+			newSVMPeer("svm_down", "", "cluster3", "peered", PeerApplicationSnapMirror),
+			newSVMPeer("svm_pending", "", "cluster2", "pending", PeerApplicationSnapMirror),
+		},
+		clusterPeers: []azgo.ClusterPeerInfoType{
+			*azgo.NewClusterPeerInfoType().SetClusterName("cluster2").SetAvailability("available"),
+			*azgo.NewClusterPeerInfoType().SetClusterName("cluster3").SetAvailability("unavailable"),
+		},
+	}
+}
+
+func newPeeringConfig(peerSVMs ...string) *drivers.OntapStorageDriverConfig {
+	return &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+		SVM:                       "svm",
+		PeerSVMs:                  peerSVMs,
+	}
+}
+
+// sourceConfig is the config of another backend, whose SVM is to be used by the peering one.
+func sourceConfig(svm string) *drivers.OntapStorageDriverConfig {
+	config := newPeeringConfig()
+	config.SVM = svm
+	return config
+}
+
+func TestValidateSVMPeers(t *testing.T) {
+
+	config := newPeeringConfig("svm_local", "svm_dr")
+	if err := ValidateSVMPeers(newPeeringClient(), config); err != nil {
+		t.Fatal("Unable to validate SVM peers: ", err)
+	}
+	if len(config.SVMPeers) != 4 {
+		t.Fatalf("Expected 4 SVM peers, got %v.", config.SVMPeers)
+	}
+	peer := FindSVMPeer(config, "svm_dr")
+	if peer == nil || peer.LocalName != "svm_dr_local" || peer.Intercluster != "available" {
+		t.Errorf("Expected svm_dr to be known locally as svm_dr_local and reachable, got %+v.", peer)
+	}
+	if local := FindSVMPeer(config, "svm_local"); local == nil || local.Intercluster != "" {
+		t.Errorf("Expected svm_local to be on this cluster, got %+v.", local)
+	}
+	if name := PeerSVMName(config, "svm_dr"); name != "svm_dr_local" {
+		t.Errorf("Expected svm_dr to be addressed as svm_dr_local, got %s.", name)
+	}
+
+	for _, peerSVM := range []string{"svm_down", "svm_pending", "svm_missing"} {
+		if err := ValidateSVMPeers(newPeeringClient(), newPeeringConfig(peerSVM)); err == nil {
+			t.Errorf("Expected validation of peer %s to fail.", peerSVM)
+		}
+	}
+
+	// Intercluster health needs cluster scope, so peers are validated without it
+	client := newPeeringClient()
+	client.clusterPeersErr = errors.New("insufficient privileges")
+	config = newPeeringConfig("svm_dr")
+	if err := ValidateSVMPeers(client, config); err != nil {
+		t.Error("Unable to validate SVM peers without cluster scope: ", err)
+	}
+
+	// Peers are only required to be readable if some are named
+	config = newPeeringConfig()
+	config.UnavailableFeatures = []string{FeatureSVMPeers}
+	if err := ValidateSVMPeers(newPeeringClient(), config); err != nil || config.SVMPeers != nil {
+		t.Errorf("Expected peers to be skipped, got %v and %v.", err, config.SVMPeers)
+	}
+	config.PeerSVMs = []string{"svm_dr"}
+	if err := ValidateSVMPeers(newPeeringClient(), config); err == nil {
+		t.Error("Expected validation to fail without the rights to read peers.")
+	}
+}
+
+func TestCanUseSVMPeer(t *testing.T) {
+
+	config := newPeeringConfig()
+	if err := ValidateSVMPeers(newPeeringClient(), config); err != nil {
+		t.Fatal("Unable to validate SVM peers: ", err)
+	}
+
+	tests := []struct {
+		svm         string
+		application string
+		expected    bool
+	}{
+		{"svm", PeerApplicationFlexCache, true},
+		{"svm_local", PeerApplicationFlexCache, true},
+		{"svm_dr", PeerApplicationSnapMirror, true},
+		{"svm_dr_local", PeerApplicationSnapMirror, true},
+		{"svm_dr", PeerApplicationFlexCache, false},
+		{"svm_down", PeerApplicationSnapMirror, false},
+		{"svm_pending", PeerApplicationSnapMirror, false},
+		{"svm_missing", PeerApplicationSnapMirror, false},
+	}
+	for _, test := range tests {
+		if result := CanUseSVMPeer(config, sourceConfig(test.svm), test.application); result != test.expected {
+			t.Errorf("Expected %s for %s to be usable %t, got %t.", test.application, test.svm, test.expected, result)
+		}
+	}
+
+	// Without the peers, any SVM is assumed to be usable
+	if !CanUseSVMPeer(newPeeringConfig(), sourceConfig("svm_missing"), PeerApplicationSnapMirror) {
+		t.Error("Expected an SVM to be usable when the peers are unknown.")
+	}
+}
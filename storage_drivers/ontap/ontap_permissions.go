@@ -0,0 +1,197 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+// RoleCommand is an ONTAP command directory that an ONTAP role must grant for a driver to work,
+// along with the ZAPIs the driver calls through it.
+type RoleCommand struct {
+	Directory string   // command directory, such as "volume snapshot"
+	Access    string   // "all" or "readonly"
+	APIs      []string // ZAPIs the directory allows that the driver calls
+	Optional  bool     // the driver can do without it, losing only a feature
+	Feature   string   // the feature lost without an optional directory, if the driver tracks it
+}
+
+// Access levels of role commands
+const (
+	RoleAccessAll      = "all"
+	RoleAccessReadOnly = "readonly"
+
+	DefaultRoleName = "trident"
+)
+
+// commonRoleCommands are needed by every ONTAP driver.
+var commonRoleCommands = []RoleCommand{
+	{Directory: "version", Access: RoleAccessReadOnly,
+		APIs: []string{"system-get-version", "system-get-ontapi-version"}},
+	{Directory: "vserver", Access: RoleAccessReadOnly,
+		APIs: []string{"vserver-get-iter"}},
+	{Directory: "network interface", Access: RoleAccessReadOnly,
+		APIs: []string{"net-interface-get-iter"}},
+	{Directory: "volume", Access: RoleAccessAll,
+		APIs: []string{"volume-create", "volume-destroy", "volume-get-iter", "volume-modify-iter",
+			"volume-mount", "volume-unmount", "volume-offline", "volume-size", "volume-set-option",
+			"volume-rename"}},
+	{Directory: "volume clone", Access: RoleAccessAll,
+		APIs: []string{"volume-clone-create", "volume-clone-split-start"}},
+	{Directory: "volume snapshot", Access: RoleAccessAll,
+		APIs: []string{"snapshot-create", "snapshot-delete", "snapshot-get-iter"}},
+	{Directory: "volume snapshot policy", Access: RoleAccessAll, Optional: true,
+		APIs: []string{"snapshot-policy-create", "snapshot-policy-get-iter", "snapshot-policy-add-schedule",
+			"snapshot-policy-modify-schedule", "snapshot-policy-remove-schedule"}},
+	{Directory: "qos policy-group", Access: RoleAccessAll, Optional: true, Feature: FeatureQoSPolicyGroups,
+		APIs: []string{"qos-policy-group-create", "qos-policy-group-delete", "qos-policy-group-get-iter"}},
+	{Directory: "statistics", Access: RoleAccessReadOnly, Optional: true,
+		APIs: []string{"perf-object-get-instances"}},
+	{Directory: "event generate-autosupport-log", Access: RoleAccessAll, Optional: true,
+		APIs: []string{"ems-autosupport-log"}},
+}
+
+// driverRoleCommands are needed by individual ONTAP drivers, in addition to the common ones.
+var driverRoleCommands = map[string][]RoleCommand{
+	drivers.OntapNASStorageDriverName: {
+		{Directory: "vserver export-policy", Access: RoleAccessAll,
+			APIs: []string{"export-policy-create", "export-rule-create", "export-rule-get-iter"}},
+		{Directory: "snapmirror", Access: RoleAccessAll, Optional: true, Feature: FeatureSnapMirror,
+			APIs: append([]string{"snapmirror-get-iter", "snapmirror-update-ls-set"}, replicationAPIs...)},
+		{Directory: "volume flexcache", Access: RoleAccessAll, Optional: true, Feature: FeatureFlexCache,
+			APIs: []string{"flexcache-create-async", "flexcache-destroy-async"}},
+		{Directory: "vserver peer", Access: RoleAccessReadOnly, Optional: true, Feature: FeatureSVMPeers,
+			APIs: []string{"vserver-peer-get-iter"}},
+	},
+	drivers.OntapNASQtreeStorageDriverName: {
+		{Directory: "vserver export-policy", Access: RoleAccessAll,
+			APIs: []string{"export-policy-create", "export-rule-create", "export-rule-get-iter"}},
+		{Directory: "volume qtree", Access: RoleAccessAll,
+			APIs: []string{"qtree-create", "qtree-delete-async", "qtree-list-iter", "qtree-rename"}},
+		{Directory: "volume quota", Access: RoleAccessAll,
+			APIs: []string{"quota-on", "quota-off", "quota-resize", "quota-set-entry",
+				"quota-list-entries-iter", "quota-status"}},
+		{Directory: "snapmirror", Access: RoleAccessAll, Optional: true,
+			APIs: []string{"snapmirror-get-iter", "snapmirror-update-ls-set"}},
+	},
+	drivers.OntapSANStorageDriverName: {
+		{Directory: "lun", Access: RoleAccessAll,
+			APIs: []string{"lun-create-by-size", "lun-destroy", "lun-get-iter", "lun-get-attribute",
+				"lun-set-attribute", "lun-get-serial-number", "lun-online", "lun-offline"}},
+		{Directory: "lun mapping", Access: RoleAccessAll,
+			APIs: []string{"lun-map", "lun-unmap", "lun-map-list-info"}},
+		{Directory: "lun igroup", Access: RoleAccessAll,
+			APIs: []string{"igroup-create", "igroup-add", "igroup-remove", "igroup-destroy", "igroup-get-iter"}},
+		{Directory: "vserver iscsi", Access: RoleAccessReadOnly,
+			APIs: []string{"iscsi-service-get-iter", "iscsi-node-get-name", "iscsi-interface-get-iter"}},
+		{Directory: "snapmirror", Access: RoleAccessAll, Optional: true, Feature: FeatureSnapMirror,
+			APIs: append([]string{"snapmirror-get-iter"}, replicationAPIs...)},
+		{Directory: "vserver peer", Access: RoleAccessReadOnly, Optional: true, Feature: FeatureSVMPeers,
+			APIs: []string{"vserver-peer-get-iter"}},
+	},
+}
+
+// replicationAPIs are the SnapMirror ZAPIs called by drivers that replicate volumes.
+var replicationAPIs = []string{"snapmirror-create", "snapmirror-initialize", "snapmirror-update",
+	"snapmirror-quiesce", "snapmirror-break", "snapmirror-destroy", "snapmirror-release"}
+
+// RoleCommands returns the command directories an ONTAP role must grant for a driver to work.
+func RoleCommands(driverName string) ([]RoleCommand, error) {
+	commands, ok := driverRoleCommands[driverName]
+	if !ok {
+		return nil, fmt.Errorf("unknown ONTAP driver %s", driverName)
+	}
+	return append(append([]RoleCommand{}, commonRoleCommands...), commands...), nil
+}
+
+// RoleSpec returns the ONTAP CLI commands that create a role, and a user with that role, for a
+// driver to use with the least privilege.  The SVM may be a placeholder for the administrator
+// to fill in.
+func RoleSpec(driverName, svm, role, username string) ([]string, error) {
+
+	commands, err := RoleCommands(driverName)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		role = DefaultRoleName
+	}
+	if username == "" {
+		username = role
+	}
+
+	spec := make([]string, 0, len(commands)+1)
+	for _, command := range commands {
+		spec = append(spec, fmt.Sprintf("security login role create -vserver %s -role %s -cmddirname %q -access %s",
+			svm, role, command.Directory, command.Access))
+	}
+	spec = append(spec, fmt.Sprintf("security login create -vserver %s -user-or-group-name %s "+
+		"-application ontapi -authentication-method password -role %s", svm, username, role))
+	return spec, nil
+}
+
+// CheckPermissions verifies that the configured user's role allows the ZAPIs the driver calls.
+// A missing required ZAPI is an error, while a missing optional one only disables a feature,
+// which is recorded in the config as unavailable if the driver tracks it.  If the role's
+// capabilities can't be read, the check is skipped.
+func CheckPermissions(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "CheckPermissions", "Type": "ontap_common"}
+		log.WithFields(fields).Debug(">>>> CheckPermissions")
+		defer log.WithFields(fields).Debug("<<<< CheckPermissions")
+	}
+
+	commands, err := RoleCommands(config.StorageDriverName)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := client.ListUserCapabilities()
+	if err != nil {
+		log.Debugf("Could not read the user's capabilities; permission checks will be skipped. %v", err)
+		return nil
+	}
+
+	missingRequired := make([]string, 0)
+	missingOptional := make([]string, 0)
+	for _, command := range commands {
+		featureLost := false
+		for _, zapi := range command.APIs {
+			if allowed[zapi] {
+				continue
+			}
+			if command.Optional {
+				missingOptional = append(missingOptional, zapi)
+				featureLost = true
+			} else {
+				missingRequired = append(missingRequired, zapi)
+			}
+		}
+		if featureLost && command.Feature != "" && IsFeatureAvailable(config, command.Feature) {
+			config.UnavailableFeatures = append(config.UnavailableFeatures, command.Feature)
+		}
+	}
+	sort.Strings(missingRequired)
+	sort.Strings(missingOptional)
+
+	if len(missingOptional) > 0 {
+		log.WithFields(log.Fields{
+			"username": config.Username,
+			"apis":     strings.Join(missingOptional, ","),
+		}).Warn("User's role doesn't allow some APIs; features that use them will be unavailable.")
+	}
+	if len(missingRequired) > 0 {
+		return fmt.Errorf("user %s's role doesn't allow the APIs %s; 'tridentctl rolespec --driver %s' "+
+			"lists the ONTAP commands that create a role with the rights needed", config.Username,
+			strings.Join(missingRequired, ","), config.StorageDriverName)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+// allowedAPIs returns the ZAPIs of a driver's role commands, leaving out those named.
+func allowedAPIs(t *testing.T, driverName string, except ...string) map[string]bool {
+	commands, err := RoleCommands(driverName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := make(map[string]bool)
+	for _, command := range commands {
+		for _, zapi := range command.APIs {
+			allowed[zapi] = true
+		}
+	}
+	for _, zapi := range except {
+		delete(allowed, zapi)
+	}
+	return allowed
+}
+
+func TestCheckPermissions(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		driver string
+		client *mockClient
+		valid  bool
+	}{
+		{"allowed", drivers.OntapSANStorageDriverName,
+			&mockClient{userCapabilities: allowedAPIs(t, drivers.OntapSANStorageDriverName)}, true},
+		{"missingOptional", drivers.OntapNASStorageDriverName,
+			&mockClient{userCapabilities: allowedAPIs(t, drivers.OntapNASStorageDriverName, "ems-autosupport-log")},
+			true},
+		{"missingRequired", drivers.OntapNASQtreeStorageDriverName,
+			&mockClient{userCapabilities: allowedAPIs(t, drivers.OntapNASQtreeStorageDriverName, "quota-resize")},
+			false},
+		{"unreadable", drivers.OntapSANStorageDriverName,
+			&mockClient{userCapabilitiesErr: errors.New("API not found")}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StorageDriverName: test.driver},
+			}
+			err := CheckPermissions(test.client, config)
+			if test.valid && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if !test.valid && err == nil {
+				t.Error("Expected an error for a missing API.")
+			}
+		})
+	}
+}
+
+func TestCheckPermissionsRecordsFeatures(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+			StorageDriverName: drivers.OntapNASStorageDriverName,
+		},
+	}
+	client := &mockClient{userCapabilities: allowedAPIs(t, drivers.OntapNASStorageDriverName,
+		"qos-policy-group-create", "qos-policy-group-delete", "ems-autosupport-log")}
+	if err := CheckPermissions(client, config); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if !reflect.DeepEqual(config.UnavailableFeatures, []string{FeatureQoSPolicyGroups}) {
+		t.Errorf("Expected QoS policy groups to be unavailable, got %v", config.UnavailableFeatures)
+	}
+
+	// Probing the user's other capabilities keeps the features the role ruled out
+	config.VersionFeatures = versionFeatures(map[api.Feature]bool{api.VServerShowAggr: true})
+	ProbeCapabilities(&mockClient{features: map[api.Feature]bool{api.VServerShowAggr: true}}, config)
+	if IsFeatureAvailable(config, FeatureQoSPolicyGroups) || !IsFeatureAvailable(config, FeatureSnapMirror) {
+		t.Errorf("Expected only QoS policy groups of the role's features to be unavailable, got %v",
+			config.UnavailableFeatures)
+	}
+}
+
+func TestRoleSpec(t *testing.T) {
+	spec, err := RoleSpec(drivers.OntapSANStorageDriverName, "svm0", "", "")
+	if err != nil {
+		t.Fatal("Unable to generate role spec: ", err)
+	}
+	expected := `security login role create -vserver svm0 -role trident -cmddirname "lun" -access all`
+	found := false
+	for _, command := range spec {
+		if command == expected {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected role spec to include %s, got %v", expected, spec)
+	}
+	if last := spec[len(spec)-1]; !strings.HasPrefix(last, "security login create -vserver svm0 -user-or-group-name trident ") {
+		t.Errorf("Expected role spec to end by creating the user, got %s", last)
+	}
+
+	if _, err = RoleSpec("eseries-iscsi", "svm0", "", ""); err == nil {
+		t.Error("Expected an error for a driver other than ONTAP.")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// Backend profiles tune the ONTAP drivers for a particular kind of deployment
+const (
+	ProfileDefault           = ""
+	ProfileCloudVolumesONTAP = "cvo"
+)
+
+// Conventions followed by Cloud Volumes ONTAP
+const (
+	CVODefaultAggregate     = "aggr1"         // the first data aggregate created with the system
+	CVORootAggregatePrefix  = "aggr0"         // root aggregates, which hold no data volumes
+	CVODefaultTieringPolicy = "snapshot-only" // move cold Snapshot copies to object storage
+)
+
+// ONTAPSelectModelPrefix begins the model names ONTAP Select nodes report, such as FDvM300
+const ONTAPSelectModelPrefix = "FDvM"
+
+// tieringPolicies are the FabricPool tiering policies ONTAP accepts for a volume.
+var tieringPolicies = map[string]bool{
+	"none":          true,
+	"snapshot-only": true,
+	"auto":          true,
+	"backup":        true,
+}
+
+// PopulateProfileDefaults checks the backend's profile and fills in the defaults it implies.
+// Settings in the backend config always take precedence over those of the profile.
+func PopulateProfileDefaults(config *drivers.OntapStorageDriverConfig) error {
+
+	switch config.Profile {
+	case ProfileDefault:
+	case ProfileCloudVolumesONTAP:
+		if config.TieringPolicy == "" {
+			config.TieringPolicy = CVODefaultTieringPolicy
+		}
+		// Docker needs an aggregate, while Kubernetes may use any the SVM has been assigned
+		if config.Aggregate == "" && config.DriverContext == trident.ContextDocker {
+			config.Aggregate = CVODefaultAggregate
+		}
+	default:
+		return fmt.Errorf("invalid value for profile: %s", config.Profile)
+	}
+
+	if config.TieringPolicy != "" && !tieringPolicies[config.TieringPolicy] {
+		return fmt.Errorf("invalid value for tieringPolicy: %s", config.TieringPolicy)
+	}
+
+	return nil
+}
+
+// IsCloudVolumesONTAP returns true if the backend uses the Cloud Volumes ONTAP profile.
+func IsCloudVolumesONTAP(config *drivers.OntapStorageDriverConfig) bool {
+	return config.Profile == ProfileCloudVolumesONTAP
+}
+
+// IsUsableAggregate returns false for aggregates that new volumes should never be placed on,
+// which for Cloud Volumes ONTAP are its root aggregates.
+func IsUsableAggregate(config *drivers.OntapStorageDriverConfig, aggrName string) bool {
+	if IsCloudVolumesONTAP(config) && strings.HasPrefix(aggrName, CVORootAggregatePrefix) {
+		log.WithField("aggregate", aggrName).Debug("Ignoring Cloud Volumes ONTAP root aggregate.")
+		return false
+	}
+	return true
+}
+
+// IsONTAPSelect returns true if the cluster's nodes are ONTAP Select virtual machines, judging by
+// their models.  Cloud Volumes ONTAP runs on the same virtual platform, so its nodes match too.
+func IsONTAPSelect(models []string) bool {
+	if len(models) == 0 {
+		return false
+	}
+	for _, model := range models {
+		if !strings.HasPrefix(model, ONTAPSelectModelPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ShouldUpdateLoadSharingMirrors returns false if the SVM root volume can't have load-sharing
+// mirrors, which is the case for a single-node Cloud Volumes ONTAP or ONTAP Select system, so
+// checking for them would only slow down provisioning.
+func ShouldUpdateLoadSharingMirrors(config *drivers.OntapStorageDriverConfig) bool {
+	return !((IsCloudVolumesONTAP(config) || config.ONTAPSelect) && config.SingleNode)
+}
+
+// ExpectsHAPaths returns true if LUNs should be reachable through LIFs on more than one node, so
+// that they survive a takeover.  A single-node cluster has no partner to take over.
+func ExpectsHAPaths(config *drivers.OntapStorageDriverConfig) bool {
+	return !config.SingleNode
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func newProfileConfig(profile string, context trident.DriverContext) *drivers.OntapStorageDriverConfig {
+	return &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{DriverContext: context},
+		Profile:                   profile,
+	}
+}
+
+func TestPopulateProfileDefaults(t *testing.T) {
+	config := newProfileConfig(ProfileCloudVolumesONTAP, trident.ContextDocker)
+	if err := PopulateProfileDefaults(config); err != nil {
+		t.Fatal("Unable to populate profile defaults: ", err)
+	}
+	if config.TieringPolicy != CVODefaultTieringPolicy || config.Aggregate != CVODefaultAggregate {
+		t.Errorf("Expected Cloud Volumes ONTAP defaults, got tiering policy %s and aggregate %s.",
+			config.TieringPolicy, config.Aggregate)
+	}
+
+	// Kubernetes may use any aggregate, and explicit settings win
+	config = newProfileConfig(ProfileCloudVolumesONTAP, trident.ContextKubernetes)
+	config.TieringPolicy = "none"
+	if err := PopulateProfileDefaults(config); err != nil {
+		t.Fatal("Unable to populate profile defaults: ", err)
+	}
+	if config.TieringPolicy != "none" || config.Aggregate != "" {
+		t.Errorf("Expected the config to be left alone, got tiering policy %s and aggregate %s.",
+			config.TieringPolicy, config.Aggregate)
+	}
+
+	config = newProfileConfig(ProfileDefault, trident.ContextKubernetes)
+	if err := PopulateProfileDefaults(config); err != nil || config.TieringPolicy != "" {
+		t.Errorf("Expected no tiering policy by default, got %s, %v.", config.TieringPolicy, err)
+	}
+
+	if err := PopulateProfileDefaults(newProfileConfig("onprem", trident.ContextKubernetes)); err == nil {
+		t.Error("Expected an error for an unknown profile.")
+	}
+	config = newProfileConfig(ProfileDefault, trident.ContextKubernetes)
+	config.TieringPolicy = "sometimes"
+	if err := PopulateProfileDefaults(config); err == nil {
+		t.Error("Expected an error for an unknown tiering policy.")
+	}
+}
+
+func TestCloudVolumesONTAPConventions(t *testing.T) {
+	cvo := newProfileConfig(ProfileCloudVolumesONTAP, trident.ContextKubernetes)
+	onPrem := newProfileConfig(ProfileDefault, trident.ContextKubernetes)
+
+	if IsUsableAggregate(cvo, "aggr0_node1") || !IsUsableAggregate(cvo, "aggr1") {
+		t.Error("Expected only Cloud Volumes ONTAP root aggregates to be skipped.")
+	}
+	if !IsUsableAggregate(onPrem, "aggr0_node1") {
+		t.Error("Expected on-premises aggregates to be used regardless of name.")
+	}
+
+	cvo.SingleNode = true
+	onPrem.SingleNode = true
+	if ShouldUpdateLoadSharingMirrors(cvo) || !ShouldUpdateLoadSharingMirrors(onPrem) {
+		t.Error("Expected load-sharing mirrors to be skipped only for single-node Cloud Volumes ONTAP.")
+	}
+
+	onPrem.ONTAPSelect = true
+	if ShouldUpdateLoadSharingMirrors(onPrem) || ExpectsHAPaths(onPrem) {
+		t.Error("Expected load-sharing mirrors and HA paths to be skipped for single-node ONTAP Select.")
+	}
+	onPrem.ONTAPSelect = false
+	onPrem.SingleNode = false
+	if !ExpectsHAPaths(onPrem) {
+		t.Error("Expected HA paths for a multi-node cluster.")
+	}
+
+	// A data LIF the SVM doesn't report is tolerated only in the cloud
+	cvo.DataLIF = "10.0.0.9"
+	onPrem.DataLIF = "10.0.0.9"
+	dataLIFs := []string{"10.0.0.5"}
+	if err := ValidateDataLIFs(cvo, dataLIFs); err != nil {
+		t.Error("Unexpected error validating a Cloud Volumes ONTAP data LIF: ", err)
+	}
+	if err := ValidateDataLIFs(onPrem, dataLIFs); err == nil {
+		t.Error("Expected an error validating an unknown data LIF.")
+	}
+}
+
+func TestIsONTAPSelect(t *testing.T) {
+	for _, test := range []struct {
+		models   []string
+		expected bool
+	}{
+		{[]string{"FDvM300"}, true},
+		{[]string{"FDvM300", "FDvM300"}, true},
+		{[]string{"FAS8200", "FAS8200"}, false},
+		{[]string{"FDvM300", "AFF-A300"}, false},
+		{[]string{}, false},
+	} {
+		if IsONTAPSelect(test.models) != test.expected {
+			t.Errorf("Expected IsONTAPSelect(%v) == %v", test.models, test.expected)
+		}
+	}
+}
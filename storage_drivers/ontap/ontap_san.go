@@ -3,10 +3,14 @@
 package ontap
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -21,6 +25,15 @@ import (
 
 const LUNAttributeFSType = "com.netapp.ndvp.fstype"
 
+// LUN attributes holding the owner and mode to give a LUN's file system when Attach formats it
+const (
+	LUNAttributeFSOwner = "com.netapp.ndvp.fsowner"
+	LUNAttributeFSMode  = "com.netapp.ndvp.fsmode"
+)
+
+// maxIgroupNameLength is the longest igroup name ONTAP accepts
+const maxIgroupNameLength = 96
+
 func lunPath(name string) string {
 	return fmt.Sprintf("/vol/%v/lun0", name)
 }
@@ -29,18 +42,30 @@ func lunPath(name string) string {
 type SANStorageDriver struct {
 	initialized bool
 	Config      drivers.OntapStorageDriverConfig
-	API         *api.Client
+	API         api.ZapiClient
 	Telemetry   *Telemetry
+	journal     drivers.Journal
+
+	housekeeping *utils.HousekeepingScheduler
 }
 
 func (d *SANStorageDriver) GetConfig() *drivers.OntapStorageDriverConfig {
 	return &d.Config
 }
 
-func (d *SANStorageDriver) GetAPI() *api.Client {
+func (d *SANStorageDriver) GetAPI() api.ZapiClient {
 	return d.API
 }
 
+func (d *SANStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *SANStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.API.SetDebugTraceFlags(flags)
+}
+
 func (d *SANStorageDriver) GetTelemetry() *Telemetry {
 	return d.Telemetry
 }
@@ -70,6 +95,9 @@ func (d *SANStorageDriver) Initialize(
 	if config.IgroupName == "" {
 		config.IgroupName = drivers.GetDefaultIgroupName(context)
 	}
+	if err = validateHostOptions(config); err != nil {
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
 
 	d.API, err = InitializeOntapDriver(config)
 	if err != nil {
@@ -82,9 +110,15 @@ func (d *SANStorageDriver) Initialize(
 		return fmt.Errorf("error validating %s driver: %v", d.Name(), err)
 	}
 
-	// Set up the autosupport heartbeat
+	// Set up the autosupport heartbeat, and probing of the cluster while it isn't responding
+	d.housekeeping = NewHousekeepingScheduler(d)
 	d.Telemetry = NewOntapTelemetry(d)
-	d.Telemetry.Start()
+	if err = d.Telemetry.Start(d.housekeeping); err != nil {
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
+	if err = d.housekeeping.Schedule(NewCircuitProbeTask(d)); err != nil {
+		return fmt.Errorf("error initializing %s driver: %v", d.Name(), err)
+	}
 
 	d.initialized = true
 	return nil
@@ -102,6 +136,9 @@ func (d *SANStorageDriver) Terminate() {
 		defer log.WithFields(fields).Debug("<<<< Terminate")
 	}
 	d.Telemetry.Stop()
+	if d.housekeeping != nil {
+		d.housekeeping.Stop()
+	}
 	d.initialized = false
 }
 
@@ -124,6 +161,10 @@ func (d *SANStorageDriver) validate() error {
 	} else {
 		log.WithField("dataLIFs", dataLIFs).Debug("Found iSCSI LIFs.")
 	}
+	if len(dataLIFs) == 1 && ExpectsHAPaths(&d.Config) {
+		log.WithField("SVM", d.Config.SVM).Warning("SVM has a single iSCSI LIF, so its LUNs will lose " +
+			"their only path during a takeover; create an iSCSI LIF on each node of the HA pair.")
+	}
 
 	// If they didn't set a LIF to use in the config, we'll set it to the first iSCSI LIF we happen to find
 	if d.Config.DataLIF == "" {
@@ -137,9 +178,28 @@ func (d *SANStorageDriver) validate() error {
 		d.Config.DataLIF = dataLIFs[0]
 	}
 
+	// Hosts may be able to reach only some of the LIFs, in which case only those are advertised
+	if len(d.Config.ISCSIPortals) > 0 {
+		if err = validateISCSIPortals(d.Config.ISCSIPortals, dataLIFs); err != nil {
+			return fmt.Errorf("iSCSI portal validation failed: %v", err)
+		}
+		if !portalListed(d.Config.DataLIF, d.Config.ISCSIPortals) {
+			d.Config.DataLIF = d.Config.ISCSIPortals[0]
+		}
+	}
+
+	if err = ValidateSVMPeers(d.API, &d.Config); err != nil {
+		return fmt.Errorf("SVM peer validation failed: %v", err)
+	}
+
+	if d.Config.ISCSIDiscovery == drivers.ISCSIDiscoveryStatic && d.Config.DriverContext != trident.ContextDocker {
+		log.Warning("The iscsiDiscovery setting applies only to Docker hosts; Kubernetes nodes run " +
+			"discovery at the volume's portals themselves.")
+	}
+
 	if d.Config.DriverContext == trident.ContextDocker {
 		// Make sure this host is logged into the ONTAP iSCSI target
-		err := utils.EnsureISCSISession(d.Config.DataLIF)
+		err := d.ensureISCSISession()
 		if err != nil {
 			return fmt.Errorf("error establishing iSCSI session: %v", err)
 		}
@@ -154,8 +214,32 @@ func (d *SANStorageDriver) validate() error {
 	return nil
 }
 
+// validateHostOptions rejects options that only take effect where Trident attaches volumes to the
+// host itself, which it does for Docker.  On Kubernetes, nodes attach volumes themselves, so the
+// options would be silently ignored.
+func validateHostOptions(config *drivers.OntapStorageDriverConfig) error {
+	if config.DriverContext == trident.ContextDocker {
+		return nil
+	}
+	if config.IgroupPerNode {
+		return fmt.Errorf("igroupPerNode applies only to Docker hosts; %s backends map LUNs to igroup %s",
+			config.DriverContext, config.IgroupName)
+	}
+	return nil
+}
+
+// GetVolumeSize returns the size of the LUN, and the Flexvol holding it, that Create makes for a
+// requested size.  LUN sizes are rounded up to a whole number of blocks.
+func (d *SANStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	sizeBytes, err := GetVolumeSize(sizeBytes, d.Config)
+	if err != nil {
+		return 0, err
+	}
+	return utils.RoundUpSize(sizeBytes, LUNBlockSizeBytes), nil
+}
+
 // Create a volume+LUN with the specified options
-func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+func (d *SANStorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -169,16 +253,24 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		defer log.WithFields(fields).Debug("<<<< Create")
 	}
 
-	// If the volume already exists, bail out
-	volExists, err := d.API.VolumeExists(name)
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
+	// If the volume already exists, an earlier attempt may have failed partway through, so
+	// it is completed below once the options have been validated
+	volExists, err := client.VolumeExists(name)
 	if err != nil {
-		return fmt.Errorf("error checking for existing volume: %v", err)
+		return classifyError(err, "error checking for existing volume")
 	}
-	if volExists {
-		return fmt.Errorf("volume %s already exists", name)
+	if !volExists {
+		aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
+		if err = CheckVolumeLimit(name, aggregate, &d.Config, client); err != nil {
+			return err
+		}
 	}
 
-	sizeBytes, err = GetVolumeSize(sizeBytes, d.Config)
+	sizeBytes, err = d.GetVolumeSize(sizeBytes)
 	if err != nil {
 		return err
 	}
@@ -194,12 +286,20 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 	aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
 	securityStyle := utils.GetV(opts, "securityStyle", d.Config.SecurityStyle)
 	encryption := utils.GetV(opts, "encryption", d.Config.Encryption)
+	tieringPolicy := utils.GetV(opts, "tieringPolicy", d.Config.TieringPolicy)
 
-	encrypt, err := ValidateEncryptionAttribute(encryption, d.API)
+	encrypt, err := ValidateEncryptionAttribute(encryption, aggregate, &d.Config, client)
 	if err != nil {
 		return err
 	}
 
+	if unixPermissions, err = ConvertUnixPermissions(unixPermissions); err != nil {
+		return err
+	}
+	if err = ValidateSecurityStyle(securityStyle); err != nil {
+		return err
+	}
+
 	// Check for a supported file system type
 	fstype := strings.ToLower(utils.GetV(opts, "fstype|fileSystemType", d.Config.FileSystemType))
 	switch fstype {
@@ -208,6 +308,23 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 	default:
 		return fmt.Errorf("unsupported fileSystemType option: %s", fstype)
 	}
+	if err = utils.ValidateFilesystemPermissions(
+		utils.GetV(opts, "fileSystemOwner", d.Config.FileSystemOwner),
+		utils.GetV(opts, "fileSystemMode", d.Config.FileSystemMode),
+	); err != nil {
+		return err
+	}
+
+	if err = ValidateCachingPolicy(opts); err != nil {
+		return err
+	}
+	if err = EnsureQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+
+	if volExists {
+		return d.resumeCreate(client, name, opts, sizeBytes, fstype)
+	}
 
 	log.WithFields(log.Fields{
 		"name":            name,
@@ -220,12 +337,13 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		"aggregate":       aggregate,
 		"securityStyle":   securityStyle,
 		"encryption":      encryption,
+		"tieringPolicy":   tieringPolicy,
 	}).Debug("Creating Flexvol.")
 
 	// Create the volume
-	volCreateResponse, err := d.API.VolumeCreate(
+	volCreateResponse, err := client.VolumeCreate(
 		name, aggregate, size, spaceReserve, snapshotPolicy,
-		unixPermissions, exportPolicy, securityStyle, encrypt)
+		unixPermissions, exportPolicy, securityStyle, tieringPolicy, encrypt)
 
 	if err = api.GetError(volCreateResponse, err); err != nil {
 		if zerr, ok := err.(api.ZapiError); ok {
@@ -236,35 +354,109 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 				return nil
 			}
 		}
-		return fmt.Errorf("error creating volume: %v", err)
+		return classifyError(err, "error creating volume")
+	}
+
+	if err = SetQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+	if err = SetCachingPolicy(name, opts, client); err != nil {
+		return err
 	}
 
+	// Apply any ONTAP options from the backend config that Trident doesn't model
+	if err = ApplyAdvancedOptions(name, &d.Config, client); err != nil {
+		return err
+	}
+
+	return d.finishCreate(client, name, opts, sizeBytes, fstype, false)
+}
+
+// resumeCreate completes the creation of a Flexvol that already exists by creating its LUN if
+// an earlier attempt failed before doing so.
+func (d *SANStorageDriver) resumeCreate(
+	client api.ZapiClient, name string, opts map[string]string, sizeBytes uint64, fstype string,
+) error {
+
+	lunResponse, err := client.LunGetAll(lunPath(name))
+	if err = api.GetError(lunResponse, err); err != nil {
+		return classifyError(err, "error checking for existing LUN")
+	}
+	lunExists := lunResponse.Result.NumRecords() > 0
+
+	log.WithFields(log.Fields{
+		"volume":    name,
+		"lunExists": lunExists,
+	}).Info("Volume already exists, completing any remaining creation steps.")
+
+	if err = SetQosPolicyGroup(name, opts, &d.Config, client); err != nil {
+		return err
+	}
+	if err = SetCachingPolicy(name, opts, client); err != nil {
+		return err
+	}
+	if err = ApplyAdvancedOptions(name, &d.Config, client); err != nil {
+		return err
+	}
+
+	return d.finishCreate(client, name, opts, sizeBytes, fstype, lunExists)
+}
+
+// finishCreate creates the LUN within a new Flexvol, unless it already exists, and records the
+// LUN attributes Trident needs.  Setting the attributes may safely be repeated.  If they can't be
+// set, a LUN created by this call is destroyed, but one left by an earlier attempt is kept.
+func (d *SANStorageDriver) finishCreate(
+	client api.ZapiClient, name string, opts map[string]string, sizeBytes uint64, fstype string, lunExists bool,
+) error {
+
 	lunPath := lunPath(name)
 	osType := "linux"
 
 	// Create the LUN
-	lunCreateResponse, err := d.API.LunCreate(lunPath, int(sizeBytes), osType, false)
-	if err = api.GetError(lunCreateResponse, err); err != nil {
-		return fmt.Errorf("error creating LUN: %v", err)
+	if !lunExists {
+		lunCreateResponse, err := client.LunCreate(lunPath, int(sizeBytes), osType, false)
+		if err = api.GetError(lunCreateResponse, err); err != nil {
+			return classifyError(err, "error creating LUN")
+		}
 	}
 
 	// Save the fstype in a LUN attribute so we know what to do in Attach
-	attrResponse, err := d.API.LunSetAttribute(lunPath, LUNAttributeFSType, fstype)
+	attrResponse, err := client.LunSetAttribute(lunPath, LUNAttributeFSType, fstype)
 	if err = api.GetError(attrResponse, err); err != nil {
-		defer d.API.LunDestroy(lunPath)
+		if !lunExists {
+			defer d.API.LunDestroy(lunPath)
+		}
 		return fmt.Errorf("error saving file system type for LUN: %v", err)
 	}
 	// Save the context
-	attrResponse, err = d.API.LunSetAttribute(lunPath, "context", string(d.Config.DriverContext))
+	attrResponse, err = client.LunSetAttribute(lunPath, "context", string(d.Config.DriverContext))
 	if err = api.GetError(attrResponse, err); err != nil {
 		log.WithField("name", name).Warning("Failed to save the driver context attribute for new volume.")
 	}
+	// Save the owner and mode for the file system, if any
+	for attribute, value := range map[string]string{
+		LUNAttributeFSOwner: utils.GetV(opts, "fileSystemOwner", d.Config.FileSystemOwner),
+		LUNAttributeFSMode:  utils.GetV(opts, "fileSystemMode", d.Config.FileSystemMode),
+	} {
+		if value == "" {
+			continue
+		}
+		attrResponse, err = client.LunSetAttribute(lunPath, attribute, value)
+		if err = api.GetError(attrResponse, err); err != nil {
+			if !lunExists {
+				defer d.API.LunDestroy(lunPath)
+			}
+			return fmt.Errorf("error saving file system permissions for LUN: %v", err)
+		}
+	}
 
 	return nil
 }
 
 // Create a volume clone
-func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *SANStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -279,17 +471,81 @@ func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[s
 		defer log.WithFields(fields).Debug("<<<< CreateClone")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
 	split, err := strconv.ParseBool(utils.GetV(opts, "splitOnClone", d.Config.SplitOnClone))
 	if err != nil {
-		return fmt.Errorf("invalid boolean value for splitOnClone: %v", err)
+		return drivers.NewFatalError(fmt.Sprintf("invalid boolean value for splitOnClone: %v", err))
 	}
 
 	log.WithField("splitOnClone", split).Debug("Creating volume clone.")
-	return CreateOntapClone(name, source, snapshot, split, &d.Config, d.API)
+	return CreateOntapClone(name, source, snapshot, split, &d.Config, client, d.journal)
+}
+
+// SetJournal provides the journal in which multi-step operations are recorded
+func (d *SANStorageDriver) SetJournal(journal drivers.Journal) {
+	d.journal = journal
+}
+
+// ReconcileJournalEntry completes or cleans up after an operation interrupted by a restart
+func (d *SANStorageDriver) ReconcileJournalEntry(entry *drivers.JournalEntry) error {
+	return ReconcileOntapJournalEntry(entry, &d.Config, d.API)
+}
+
+// ListOrphanedObjects returns the clone snapshots whose clones Trident no longer knows about, as
+// well as any unknown Flexvols that lack a LUN, such as those left by an interrupted create.
+// Flexvols with LUNs are reported as volumes, so they needn't be considered here.
+func (d *SANStorageDriver) ListOrphanedObjects(knownVolumes map[string]bool) ([]string, error) {
+
+	orphans, err := ListOrphanedCloneSnapshots(knownVolumes, &d.Config, d.API)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prefix := range storagePrefixes(&d.Config) {
+		volumesResponse, err := d.API.VolumeList(prefix)
+		if err = api.GetError(volumesResponse, err); err != nil {
+			return nil, fmt.Errorf("error listing volumes: %v", err)
+		}
+		lunsResponse, err := d.API.LunGetAll(fmt.Sprintf("/vol/%v/lun0", prefix+"*"))
+		if err = api.GetError(lunsResponse, err); err != nil {
+			return nil, fmt.Errorf("error listing LUNs: %v", err)
+		}
+
+		volumesWithLUNs := make(map[string]bool)
+		for _, lun := range lunsResponse.Result.AttributesList() {
+			volumesWithLUNs[lun.Volume()] = true
+		}
+		for _, volume := range volumesResponse.Result.AttributesList() {
+			name := string(volume.VolumeIdAttributesPtr.Name())
+			if !volumesWithLUNs[name] && !knownVolumes[name] {
+				orphans = append(orphans, orphanFlexvolPrefix+name)
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// DeleteOrphanedObject deletes an object returned by ListOrphanedObjects
+func (d *SANStorageDriver) DeleteOrphanedObject(object string) error {
+	return DeleteOrphanedOntapObject(object, d.API)
+}
+
+// GetOwnership returns the backend that created a volume, as recorded in its Flexvol's comment
+func (d *SANStorageDriver) GetOwnership(name string) (string, time.Time, error) {
+	return GetOntapOwnership(name, d.API)
+}
+
+// SupportsOnDelete reports that Destroy can retain a volume, optionally taking it offline
+func (d *SANStorageDriver) SupportsOnDelete(onDelete string) bool {
+	return onDelete == drivers.OnDeleteRetain || onDelete == drivers.OnDeleteOffline
 }
 
 // Destroy the requested (volume,lun) storage tuple
-func (d *SANStorageDriver) Destroy(name string) error {
+func (d *SANStorageDriver) Destroy(ctx context.Context, name string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -301,6 +557,15 @@ func (d *SANStorageDriver) Destroy(name string) error {
 		defer log.WithFields(fields).Debug("<<<< Destroy")
 	}
 
+	LogAuditEvent(ctx, d, name)
+
+	client := d.API.WithContext(ctx)
+
+	// A clone may be left on ONTAP, no longer managed by Trident, instead of being destroyed
+	if onDelete := drivers.OnDeleteFromContext(ctx); onDelete != drivers.OnDeleteDelete {
+		return RetainOntapVolume(name, onDelete, false, client)
+	}
+
 	var (
 		err           error
 		iSCSINodeName string
@@ -308,9 +573,9 @@ func (d *SANStorageDriver) Destroy(name string) error {
 	)
 
 	// Validate Flexvol exists before trying to destroy
-	volExists, err := d.API.VolumeExists(name)
+	volExists, err := client.VolumeExists(name)
 	if err != nil {
-		return fmt.Errorf("error checking for existing volume: %v", err)
+		return classifyError(err, "error checking for existing volume")
 	}
 	if !volExists {
 		log.WithField("volume", name).Debug("Volume already deleted, skipping destroy.")
@@ -327,16 +592,14 @@ func (d *SANStorageDriver) Destroy(name string) error {
 		}
 
 		// Get the LUN ID
-		lunPath := fmt.Sprintf("/vol/%s/lun0", name)
-		lunMapResponse, err := d.API.LunMapListInfo(lunPath)
+		var igroupName string
+		igroupName, err = d.hostIgroupName()
 		if err != nil {
-			return fmt.Errorf("error reading LUN maps for volume %s: %v", name, err)
+			return err
 		}
-		lunID = -1
-		for _, lunMapResponse := range lunMapResponse.Result.InitiatorGroups() {
-			if lunMapResponse.InitiatorGroupName() == d.Config.IgroupName {
-				lunID = lunMapResponse.LunId()
-			}
+		lunID, err = d.getMappedLunID(name, igroupName, client)
+		if err != nil {
+			return err
 		}
 		if lunID >= 0 {
 			// Inform the host about the device removal
@@ -345,7 +608,7 @@ func (d *SANStorageDriver) Destroy(name string) error {
 	}
 
 	// Delete the Flexvol & LUN
-	volDestroyResponse, err := d.API.VolumeDestroy(name, true)
+	volDestroyResponse, err := client.VolumeDestroy(name, true)
 	if err != nil {
 		return fmt.Errorf("error destroying volume %v: %v", name, err)
 	}
@@ -358,6 +621,9 @@ func (d *SANStorageDriver) Destroy(name string) error {
 		}
 	}
 
+	DeleteVolumeQosPolicyGroup(name, &d.Config, client)
+	ReleaseQosPolicyGroup(drivers.ManagedQoSPolicyFromContext(ctx), &d.Config, client)
+
 	return nil
 }
 
@@ -376,15 +642,15 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		defer log.WithFields(fields).Debug("<<<< Attach")
 	}
 
-	var err error
+	// Ensure the host will assemble multipath devices from the LUN's paths
+	if err := drivers.CheckHostMultipath(d.Config.CommonStorageDriverConfig, utils.OntapMultipathDevice); err != nil {
+		return err
+	}
 
-	// Error if no iSCSI session exists for the specified iscsi portal
-	sessionExists, err := utils.ISCSISessionExists(d.Config.DataLIF)
+	// Log in to every portal of the target, so that all paths are available to multipath
+	err := d.ensureISCSISession()
 	if err != nil {
-		return fmt.Errorf("unexpected iSCSI session error: %v", err)
-	}
-	if !sessionExists {
-		return fmt.Errorf("expected iSCSI session %v not found; please login to the iSCSI portal", d.Config.DataLIF)
+		return fmt.Errorf("could not establish iSCSI session to %s: %v", d.Config.DataLIF, err)
 	}
 
 	// Get target info
@@ -393,7 +659,10 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		return err
 	}
 
-	igroupName := d.Config.IgroupName
+	igroupName, err := d.hostIgroupName()
+	if err != nil {
+		return err
+	}
 	lunPath := lunPath(name)
 
 	// Get the fstype
@@ -447,12 +716,12 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 	}
 
 	// Rescan and wait for the device(s) to appear
-	err = utils.RescanTargetAndWaitForDevice(lunID, iSCSINodeName)
+	err = utils.RescanTargetAndWaitForDevice(lunID, iSCSINodeName, d.Config.Timeouts.Mount)
 	if err != nil {
 		return fmt.Errorf("could not find iSCSI device: %v", err)
 	}
 
-	err = utils.WaitForMultiPathDevice(lunID, iSCSINodeName)
+	err = utils.WaitForMultiPathDevice(lunID, iSCSINodeName, d.Config.Timeouts.Mount)
 	if err != nil {
 		return err
 	}
@@ -485,12 +754,14 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 	devicePath := "/dev/" + deviceToUse
 
 	// Put a filesystem on it if there isn't one already there
+	formatted := false
 	if deviceInfo.Filesystem == "" {
 		log.WithFields(log.Fields{"LUN": lunPath, "fstype": fstype}).Debug("Formatting LUN.")
 		err := utils.FormatVolume(devicePath, fstype)
 		if err != nil {
 			return fmt.Errorf("error formatting LUN %v, device %v: %v", name, deviceToUse, err)
 		}
+		formatted = true
 	} else if deviceInfo.Filesystem != fstype {
 		log.WithFields(log.Fields{
 			"LUN":             lunPath,
@@ -508,9 +779,54 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 			name, deviceToUse, mountpoint, err)
 	}
 
+	// Give a new file system the owner and mode requested when the volume was created
+	if formatted {
+		owner := d.getLunAttribute(lunPath, LUNAttributeFSOwner)
+		mode := d.getLunAttribute(lunPath, LUNAttributeFSMode)
+		if err = utils.SetFilesystemPermissions(mountpoint, owner, mode); err != nil {
+			utils.Umount(mountpoint)
+			return fmt.Errorf("error setting permissions of LUN %v: %v", name, err)
+		}
+	}
+
 	return nil
 }
 
+// getLunAttribute returns the value of a LUN attribute, or an empty string if it isn't set.
+func (d *SANStorageDriver) getLunAttribute(lunPath, attribute string) string {
+
+	attrResponse, err := d.API.LunGetAttribute(lunPath, attribute)
+	if err = api.GetError(attrResponse, err); err != nil {
+		log.WithFields(log.Fields{"LUN": lunPath, "attribute": attribute}).Debug("LUN attribute not found.")
+		return ""
+	}
+	if attrResponse.Result.ValuePtr == nil {
+		return ""
+	}
+	return attrResponse.Result.Value()
+}
+
+// ensureISCSISession logs this host in to the SVM's iSCSI target, through discovery at the data
+// LIF or, if the backend forbids discovery, at each iSCSI LIF the backend advertises.
+func (d *SANStorageDriver) ensureISCSISession() error {
+
+	if d.Config.ISCSIDiscovery != drivers.ISCSIDiscoveryStatic {
+		return utils.EnsureISCSISessionWithPortals(d.Config.DataLIF, d.Config.ISCSIPortals)
+	}
+
+	iSCSINodeName, _, err := d.getISCSITargetInfo()
+	if err != nil {
+		return err
+	}
+	portals := d.Config.ISCSIPortals
+	if len(portals) == 0 {
+		if portals, err = d.API.NetInterfaceGetDataLIFs("iscsi"); err != nil {
+			return fmt.Errorf("could not get iSCSI LIFs: %v", err)
+		}
+	}
+	return utils.EnsureISCSISessionStatic(iSCSINodeName, d.Config.DataLIF, portals)
+}
+
 func (d *SANStorageDriver) getISCSITargetInfo() (iSCSINodeName string, iSCSIInterfaces []string, returnError error) {
 
 	// Get the SVM iSCSI IQN
@@ -564,9 +880,96 @@ func (d *SANStorageDriver) Detach(name, mountpoint string) error {
 		return fmt.Errorf("error unmounting volume %v, mountpoint %v: %v", name, mountpoint, err)
 	}
 
+	// Remove the LUN's devices from the host, and log out of the target if nothing else uses it.
+	// The volume is already unmounted, so failures here only leave stale devices behind.
+	iSCSINodeName, _, err := d.getISCSITargetInfo()
+	if err != nil {
+		log.WithField("error", err).Warning("Could not get target info, not cleaning up iSCSI devices.")
+		return nil
+	}
+	igroupName, err := d.hostIgroupName()
+	if err != nil {
+		log.WithField("error", err).Warning("Could not get igroup name, not cleaning up iSCSI devices.")
+		return nil
+	}
+	lunID, err := d.getMappedLunID(name, igroupName, d.API)
+	if err != nil {
+		log.WithField("error", err).Warning("Could not get LUN ID, not cleaning up iSCSI devices.")
+		return nil
+	}
+	if lunID >= 0 {
+		utils.PrepareDeviceForRemoval(lunID, iSCSINodeName)
+
+		// With an igroup per host, no host keeps access to a LUN it no longer uses
+		if d.Config.IgroupPerNode {
+			unmapResponse, err := d.API.LunUnmap(igroupName, lunPath(name))
+			if err = api.GetError(unmapResponse, err); err != nil {
+				if zerr, ok := err.(api.ZapiError); !ok || zerr.Code() != azgo.EVDISK_ERROR_NO_SUCH_LUNMAP {
+					log.WithFields(log.Fields{
+						"LUN":    lunPath(name),
+						"igroup": igroupName,
+						"error":  err,
+					}).Warning("Could not unmap LUN from this host's igroup.")
+				}
+			} else {
+				log.WithFields(log.Fields{"LUN": lunPath(name), "igroup": igroupName}).Debug("Unmapped LUN.")
+			}
+		}
+	}
+	if err = utils.ISCSILogoutIfUnused(iSCSINodeName); err != nil {
+		log.WithField("error", err).Warning("Could not log out of iSCSI target.")
+	}
+
 	return nil
 }
 
+// hostIgroupName returns the igroup to which LUNs are mapped for this host: its own igroup if
+// the backend maps LUNs per node, or the backend's shared igroup otherwise.
+func (d *SANStorageDriver) hostIgroupName() (string, error) {
+
+	if !d.Config.IgroupPerNode {
+		return d.Config.IgroupName, nil
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("could not determine hostname for igroup: %v", err)
+	}
+	return nodeIgroupName(d.Config.IgroupName, hostname), nil
+}
+
+// nodeIgroupName returns the name of a host's own igroup, made from the backend's igroup name and
+// the hostname, with characters ONTAP doesn't allow in igroup names replaced.
+func nodeIgroupName(igroupName, hostname string) string {
+
+	name := []rune(igroupName + "-" + hostname)
+	for i, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.:", r)) {
+			name[i] = '_'
+		}
+	}
+	if len(name) > maxIgroupNameLength {
+		name = name[:maxIgroupNameLength]
+	}
+	return string(name)
+}
+
+// getMappedLunID returns the ID at which a volume's LUN is mapped to an igroup, or -1 if it
+// isn't mapped.
+func (d *SANStorageDriver) getMappedLunID(name, igroupName string, client api.ZapiClient) (int, error) {
+
+	lunMapResponse, err := client.LunMapListInfo(lunPath(name))
+	if err != nil {
+		return -1, fmt.Errorf("error reading LUN maps for volume %s: %v", name, err)
+	}
+	lunID := -1
+	for _, lunMapResponse := range lunMapResponse.Result.InitiatorGroups() {
+		if lunMapResponse.InitiatorGroupName() == igroupName {
+			lunID = lunMapResponse.LunId()
+		}
+	}
+	return lunID, nil
+}
+
 // Return the list of snapshots associated with the named volume
 func (d *SANStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error) {
 
@@ -583,6 +986,52 @@ func (d *SANStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error)
 	return GetSnapshotList(name, &d.Config, d.API)
 }
 
+// CreateSnapshot takes a snapshot of the named volume
+func (d *SANStorageDriver) CreateSnapshot(snapshotName, volumeName string) (*storage.Snapshot, error) {
+	return CreateSnapshot(snapshotName, volumeName, &d.Config, d.API)
+}
+
+// CreateGroupSnapshot snapshots the named volumes at the same point in time
+func (d *SANStorageDriver) CreateGroupSnapshot(snapshotName string, volumeNames []string) ([]*storage.Snapshot, error) {
+	return CreateGroupSnapshot(snapshotName, volumeNames, &d.Config, d.API)
+}
+
+// DeleteSnapshot deletes a snapshot of the named volume
+func (d *SANStorageDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+	return DeleteSnapshot(snapshotName, volumeName, &d.Config, d.API)
+}
+
+// CanReplicateFrom reports whether volumes may be replicated from the source using SnapMirror,
+// which requires the source to be another ontap-san backend whose SVM is peered with this one.
+func (d *SANStorageDriver) CanReplicateFrom(source storage.Driver) bool {
+	sourceDriver, ok := source.(*SANStorageDriver)
+	return ok && CanUseSVMPeer(&d.Config, &sourceDriver.Config, PeerApplicationSnapMirror)
+}
+
+// CreateReplica creates a data protection Flexvol and starts replicating the source, LUN and all, into it
+func (d *SANStorageDriver) CreateReplica(
+	ctx context.Context, name, sourceName string, source storage.Driver, sizeBytes uint64, opts map[string]string,
+) error {
+	aggregate := utils.GetV(opts, "aggregate", d.Config.Aggregate)
+	return CreateOntapReplica(name, sourceName, source.(StorageDriver), aggregate, "",
+		&d.Config, d.API.WithContext(ctx))
+}
+
+// UpdateReplica transfers the changes made to the source since the last transfer
+func (d *SANStorageDriver) UpdateReplica(ctx context.Context, name string) error {
+	return UpdateOntapReplica(ctx, name, &d.Config, d.API.WithContext(ctx))
+}
+
+// PromoteReplica makes a replica's LUN writable.  The LUN is mapped by CreateFollowup.
+func (d *SANStorageDriver) PromoteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+	return PromoteOntapReplica(ctx, name, sourceName, source.(StorageDriver), &d.Config, d.API.WithContext(ctx))
+}
+
+// DeleteReplica stops replicating into a replica and destroys it
+func (d *SANStorageDriver) DeleteReplica(ctx context.Context, name, sourceName string, source storage.Driver) error {
+	return DeleteOntapReplica(name, sourceName, source.(StorageDriver), &d.Config, d.API.WithContext(ctx))
+}
+
 // Return the list of volumes associated with this tenant
 func (d *SANStorageDriver) List() ([]string, error) {
 
@@ -607,6 +1056,21 @@ func (d *SANStorageDriver) Get(name string) error {
 	return GetVolume(name, d.API, &d.Config)
 }
 
+// GetPoolCapacity reports the space in the aggregate backing a storage pool.
+func (d *SANStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+	return getPoolCapacityCommon(d.API, pool)
+}
+
+// GetVolumeStats samples the performance counters of a volume's Flexvol.
+func (d *SANStorageDriver) GetVolumeStats(name string) (*storage.VolumeStats, error) {
+	return getVolumeStatsCommon(d.API, name)
+}
+
+// GetVolumeUsedBytes returns the space consumed by a volume's Flexvol, which holds its LUN.
+func (d *SANStorageDriver) GetVolumeUsedBytes(name string) (uint64, error) {
+	return getVolumeUsedBytesCommon(d.API, name)
+}
+
 // Retrieve storage backend capabilities
 func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 
@@ -617,13 +1081,16 @@ func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) erro
 
 func (d *SANStorageDriver) GetStoragePoolAttributes() map[string]sa.Offer {
 
-	return map[string]sa.Offer{
+	return addCachingPolicyOffers(addQosPolicyGroupOffers(map[string]sa.Offer{
 		sa.BackendType:      sa.NewStringOffer(d.Name()),
 		sa.Snapshots:        sa.NewBoolOffer(true),
-		sa.Clones:           sa.NewBoolOffer(true),
-		sa.Encryption:       sa.NewBoolOffer(d.API.SupportsFeature(api.NetAppVolumeEncryption)),
+		sa.Clones:           sa.NewBoolOffer(IsLicensed(&d.Config, LicenseFlexClone)),
+		sa.Encryption:       sa.NewBoolOffer(SupportsVersionFeature(&d.Config, api.NetAppVolumeEncryption)),
+		sa.Resize:           sa.NewBoolOffer(false),
+		sa.QoS:              sa.NewBoolOffer(IsFeatureAvailable(&d.Config, FeatureQoSPolicyGroups)),
+		sa.Replication:      sa.NewBoolOffer(supportsReplication(&d.Config)),
 		sa.ProvisioningType: sa.NewStringOffer("thick", "thin"),
-	}
+	}, &d.Config))
 }
 
 func (d *SANStorageDriver) GetVolumeOpts(
@@ -638,8 +1105,13 @@ func (d *SANStorageDriver) GetInternalVolumeName(name string) string {
 	return getInternalVolumeNameCommon(d.Config.CommonStorageDriverConfig, name)
 }
 
+// GetInternalVolumeNameFromConfig names a volume from the backend's naming template, if it has one.
+func (d *SANStorageDriver) GetInternalVolumeNameFromConfig(volConfig *storage.VolumeConfig) (string, error) {
+	return getInternalVolumeNameFromTemplate(&d.Config, volConfig), nil
+}
+
 func (d *SANStorageDriver) CreatePrepare(volConfig *storage.VolumeConfig) bool {
-	return createPrepareCommon(d, volConfig)
+	return createPrepareCommon(d, &d.Config, volConfig)
 }
 
 func (d *SANStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error {
@@ -692,21 +1164,73 @@ func (d *SANStorageDriver) mapOntapSANLun(volConfig *storage.VolumeConfig) error
 		return err
 	}
 
+	// Get the LUN serial number, from which hosts derive the LUN's WWID
+	serialResponse, err := d.API.LunGetSerialNumber(lunPath)
+	if err = api.GetError(serialResponse, err); err != nil {
+		return fmt.Errorf("could not get serial number of LUN %s: %v", lunPath, err)
+	}
+	serial := serialResponse.Result.SerialNumber()
+
 	volConfig.AccessInfo.IscsiTargetPortal = d.Config.DataLIF
+	volConfig.AccessInfo.IscsiPortals = additionalISCSIPortals(d.Config.DataLIF, d.Config.ISCSIPortals)
 	volConfig.AccessInfo.IscsiTargetIQN = targetIQN
 	volConfig.AccessInfo.IscsiLunNumber = int32(lunID)
+	volConfig.AccessInfo.IscsiLunSerial = serial
+	volConfig.AccessInfo.IscsiLunWWID = utils.NAAWWID(lunNAAIdentifier(serial))
 	volConfig.AccessInfo.IscsiIgroup = d.Config.IgroupName
 	log.WithFields(log.Fields{
 		"volume":          volConfig.Name,
 		"volume_internal": volConfig.InternalName,
 		"targetIQN":       volConfig.AccessInfo.IscsiTargetIQN,
+		"portals":         volConfig.AccessInfo.IscsiPortals,
 		"lunNumber":       volConfig.AccessInfo.IscsiLunNumber,
+		"lunWWID":         volConfig.AccessInfo.IscsiLunWWID,
 		"igroup":          volConfig.AccessInfo.IscsiIgroup,
 	}).Debug("Mapped ONTAP LUN.")
 
 	return nil
 }
 
+// validateISCSIPortals ensures each portal to be advertised to hosts is one of the SVM's iSCSI LIFs.
+func validateISCSIPortals(portals, dataLIFs []string) error {
+	for _, portal := range portals {
+		if !portalListed(portal, dataLIFs) {
+			return fmt.Errorf("%s is not an iSCSI LIF of the SVM; iSCSI LIFs are %v", portal, dataLIFs)
+		}
+	}
+	return nil
+}
+
+// portalListed returns true if a portal IP address is in a list of them.
+func portalListed(portal string, portals []string) bool {
+	for _, p := range portals {
+		if p == portal {
+			return true
+		}
+	}
+	return false
+}
+
+// additionalISCSIPortals returns the portals advertised to hosts besides the data LIF.
+func additionalISCSIPortals(dataLIF string, portals []string) []string {
+	var additional []string
+	for _, portal := range portals {
+		if portal != dataLIF {
+			additional = append(additional, portal)
+		}
+	}
+	return additional
+}
+
+// lunNAAIdentifier returns the NAA identifier ONTAP reports for a LUN to SCSI hosts: a type 6
+// identifier holding NetApp's OUI followed by the LUN's 12-character serial number.
+func lunNAAIdentifier(serial string) string {
+	if serial == "" {
+		return ""
+	}
+	return "600a0980" + hex.EncodeToString([]byte(serial))
+}
+
 func (d *SANStorageDriver) GetProtocol() trident.Protocol {
 	return trident.Block
 }
@@ -718,6 +1242,16 @@ func (d *SANStorageDriver) StoreConfig(
 	b.OntapConfig = &d.Config
 }
 
+// StorageIdentity identifies the volumes the driver manages, so that duplicate backends can be
+// recognized.
+func (d *SANStorageDriver) StorageIdentity() string {
+	return StorageIdentity(&d.Config)
+}
+
+func (d *SANStorageDriver) Degraded() string {
+	return Degraded(&d.Config)
+}
+
 func (d *SANStorageDriver) GetExternalConfig() interface{} {
 	return getExternalConfig(d.Config)
 }
@@ -751,38 +1285,42 @@ func (d *SANStorageDriver) GetVolumeExternalWrappers(
 	// Let the caller know we're done by closing the channel
 	defer close(channel)
 
-	// Get all volumes matching the storage prefix
-	volumesResponse, err := d.API.VolumeGetAll(*d.Config.StoragePrefix)
-	if err = api.GetError(volumesResponse, err); err != nil {
-		channel <- &storage.VolumeExternalWrapper{nil, err}
-		return
-	}
+	// Each tenant's volumes are listed by their own prefix
+	for _, prefix := range storagePrefixes(&d.Config) {
 
-	// Get all LUNs named 'lun0' in volumes matching the storage prefix
-	lunPathPattern := fmt.Sprintf("/vol/%v/lun0", *d.Config.StoragePrefix+"*")
-	lunsResponse, err := d.API.LunGetAll(lunPathPattern)
-	if err = api.GetError(lunsResponse, err); err != nil {
-		channel <- &storage.VolumeExternalWrapper{nil, err}
-		return
-	}
-
-	// Make a map of volumes for faster correlation with LUNs
-	volumeMap := make(map[string]azgo.VolumeAttributesType)
-	for _, volumeAttrs := range volumesResponse.Result.AttributesList() {
-		internalName := string(volumeAttrs.VolumeIdAttributesPtr.Name())
-		volumeMap[internalName] = volumeAttrs
-	}
+		// Get all volumes matching the prefix
+		volumesResponse, err := d.API.VolumeGetAll(prefix)
+		if err = api.GetError(volumesResponse, err); err != nil {
+			channel <- &storage.VolumeExternalWrapper{nil, err}
+			return
+		}
 
-	// Convert all LUNs to VolumeExternal and write them to the channel
-	for _, lun := range lunsResponse.Result.AttributesList() {
+		// Get all LUNs named 'lun0' in volumes matching the prefix
+		lunPathPattern := fmt.Sprintf("/vol/%v/lun0", prefix+"*")
+		lunsResponse, err := d.API.LunGetAll(lunPathPattern)
+		if err = api.GetError(lunsResponse, err); err != nil {
+			channel <- &storage.VolumeExternalWrapper{nil, err}
+			return
+		}
 
-		volume, ok := volumeMap[lun.Volume()]
-		if !ok {
-			log.WithField("path", lun.Path()).Warning("Flexvol not found for LUN.")
-			continue
+		// Make a map of volumes for faster correlation with LUNs
+		volumeMap := make(map[string]azgo.VolumeAttributesType)
+		for _, volumeAttrs := range volumesResponse.Result.AttributesList() {
+			internalName := string(volumeAttrs.VolumeIdAttributesPtr.Name())
+			volumeMap[internalName] = volumeAttrs
 		}
 
-		channel <- &storage.VolumeExternalWrapper{d.getVolumeExternal(&lun, &volume), nil}
+		// Convert all LUNs to VolumeExternal and write them to the channel
+		for _, lun := range lunsResponse.Result.AttributesList() {
+
+			volume, ok := volumeMap[lun.Volume()]
+			if !ok {
+				log.WithField("path", lun.Path()).Warning("Flexvol not found for LUN.")
+				continue
+			}
+
+			channel <- &storage.VolumeExternalWrapper{d.getVolumeExternal(&lun, &volume), nil}
+		}
 	}
 }
 
@@ -794,18 +1332,18 @@ func (d *SANStorageDriver) getVolumeExternal(
 ) *storage.VolumeExternal {
 
 	volumeIDAttrs := volumeAttrs.VolumeIdAttributesPtr
-	volumeSnapshotAttrs := volumeAttrs.VolumeSnapshotAttributesPtr
 
 	internalName := string(volumeIDAttrs.Name())
-	name := internalName[len(*d.Config.StoragePrefix):]
+	tenant, name := splitStoragePrefix(&d.Config, internalName)
 
 	volumeConfig := &storage.VolumeConfig{
 		Version:         trident.OrchestratorAPIVersion,
 		Name:            name,
 		InternalName:    internalName,
+		Namespace:       tenant,
 		Size:            strconv.FormatInt(int64(lunAttrs.Size()), 10),
 		Protocol:        trident.Block,
-		SnapshotPolicy:  volumeSnapshotAttrs.SnapshotPolicy(),
+		SnapshotPolicy:  "",
 		ExportPolicy:    "",
 		SnapshotDir:     "false",
 		UnixPermissions: "",
@@ -816,8 +1354,24 @@ func (d *SANStorageDriver) getVolumeExternal(
 		FileSystem:      "",
 	}
 
+	// The LUN's Flexvol has no snapshot directory or permissions of its own to report
+	setFlexvolExternalConfig(volumeConfig, volumeAttrs)
+	setFlexvolCloneSource(volumeConfig, volumeAttrs)
+	volumeConfig.SnapshotDir = "false"
+	volumeConfig.UnixPermissions = ""
+	if lunAttrs.SerialNumberPtr != nil {
+		volumeConfig.AccessInfo.IscsiLunSerial = lunAttrs.SerialNumber()
+	}
+
+	// A LUN can't be used while its Flexvol is offline, whatever state the LUN is in
+	state := flexvolState(volumeAttrs)
+	if (state == "" || state == storage.VolumeStateOnline) && lunAttrs.StatePtr != nil {
+		state = lunAttrs.State()
+	}
+
 	return &storage.VolumeExternal{
 		Config: volumeConfig,
-		Pool:   volumeIDAttrs.ContainingAggregateName(),
+		Pool:   flexvolPool(volumeAttrs),
+		State:  state,
 	}
 }
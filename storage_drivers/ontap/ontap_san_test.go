@@ -0,0 +1,202 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+func TestLunNAAIdentifier(t *testing.T) {
+	for serial, expected := range map[string]string{
+		"":             "",
+		"804s3YGwGcC/": "600a098038303473335947774763432f",
+		"80BS4$Ezjio1": "600a0980383042533424457a6a696f31",
+	} {
+		if naa := lunNAAIdentifier(serial); naa != expected {
+			t.Errorf("Expected lunNAAIdentifier(%q) == %q, got %q", serial, expected, naa)
+		}
+	}
+}
+
+func TestISCSIPortals(t *testing.T) {
+	dataLIFs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+
+	if err := validateISCSIPortals([]string{"10.0.0.3", "10.0.0.1"}, dataLIFs); err != nil {
+		t.Errorf("Expected portals among the data LIFs to be valid: %v", err)
+	}
+	if err := validateISCSIPortals([]string{"10.0.0.1", "10.0.0.4"}, dataLIFs); err == nil {
+		t.Error("Expected a portal that isn't a data LIF to be rejected")
+	}
+
+	if additional := additionalISCSIPortals("10.0.0.1", []string{"10.0.0.1", "10.0.0.3"}); !reflect.DeepEqual(
+		additional, []string{"10.0.0.3"}) {
+		t.Errorf("Expected additional portal 10.0.0.3, got %v", additional)
+	}
+	if additional := additionalISCSIPortals("10.0.0.1", nil); additional != nil {
+		t.Errorf("Expected no additional portals, got %v", additional)
+	}
+}
+
+func TestNodeIgroupName(t *testing.T) {
+	for _, test := range []struct {
+		igroupName, hostname, expected string
+	}{
+		{"trident", "docker1.example.com", "trident-docker1.example.com"},
+		{"trident", "host_2:a", "trident-host_2:a"},
+		{"trident", "host 3/b", "trident-host_3_b"},
+		{"trident", strings.Repeat("h", 100), "trident-" + strings.Repeat("h", maxIgroupNameLength-8)},
+	} {
+		if name := nodeIgroupName(test.igroupName, test.hostname); name != test.expected {
+			t.Errorf("Expected igroup %q for host %q, got %q", test.expected, test.hostname, name)
+		}
+	}
+}
+
+func TestSANGetVolumeSize(t *testing.T) {
+	d := &SANStorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	d.Config.Size = "1G"
+
+	for _, test := range []struct {
+		rounding, requested, expected uint64
+	}{
+		{rounding: 0, requested: 0, expected: 1073741824},
+		{rounding: 0, requested: 100000001, expected: 100003840},
+		{rounding: 1048576, requested: 100000001, expected: 100663296},
+	} {
+		d.Config.SizeRounding = test.rounding
+		size, err := d.GetVolumeSize(test.requested)
+		if err != nil {
+			t.Errorf("Unexpected error sizing %d bytes: %v", test.requested, err)
+		} else if size != test.expected {
+			t.Errorf("Expected %d bytes for %d requested, got %d", test.expected, test.requested, size)
+		}
+	}
+
+	d.Config.SizeRounding = 0
+	if _, err := d.GetVolumeSize(4096); !drivers.IsFatalError(err) {
+		t.Errorf("Expected a fatal error for a volume below the minimum size, got %v", err)
+	}
+}
+
+func TestSANGetVolumeExternal(t *testing.T) {
+	prefix := "trident_"
+	d := &SANStorageDriver{}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix}
+
+	// Encryption isn't read from older versions of ONTAP
+	volumeAttrs := azgo.NewVolumeAttributesType().
+		SetVolumeIdAttributes(*azgo.NewVolumeIdAttributesType().
+			SetName("trident_vol1").
+			SetContainingAggregateName("aggr1")).
+		SetVolumeSpaceAttributes(*azgo.NewVolumeSpaceAttributesType().SetSpaceGuarantee("none")).
+		SetVolumeSnapshotAttributes(*azgo.NewVolumeSnapshotAttributesType().
+			SetSnapshotPolicy("default").
+			SetSnapdirAccessEnabled(true)).
+		SetVolumeStateAttributes(*azgo.NewVolumeStateAttributesType().SetState("online"))
+	lunAttrs := azgo.NewLunInfoType().SetSize(1073741824).SetSerialNumber("804s3YGwGcC/").SetState("offline")
+
+	volume := d.getVolumeExternal(lunAttrs, volumeAttrs)
+	if volume.Config.Name != "vol1" || volume.Config.Size != "1073741824" || volume.Pool != "aggr1" {
+		t.Errorf("Expected 1 GiB volume vol1 in aggr1, got %+v in %s", volume.Config, volume.Pool)
+	}
+	if volume.Config.SpaceReserve != "none" || volume.Config.SnapshotPolicy != "default" ||
+		volume.Config.SnapshotDir != "false" || volume.Config.Encryption != "" {
+		t.Errorf("Unexpected Flexvol attributes in %+v", volume.Config)
+	}
+	if volume.Config.AccessInfo.IscsiLunSerial != "804s3YGwGcC/" {
+		t.Errorf("Expected LUN serial 804s3YGwGcC/, got %s", volume.Config.AccessInfo.IscsiLunSerial)
+	}
+	if volume.State != storage.VolumeStateOffline {
+		t.Errorf("Expected the LUN's state to be reported, got %s", volume.State)
+	}
+
+	volumeAttrs.SetEncrypt(true)
+	volumeAttrs.SetVolumeStateAttributes(*azgo.NewVolumeStateAttributesType().SetState("restricted"))
+	lunAttrs.SetState("online")
+	volume = d.getVolumeExternal(lunAttrs, volumeAttrs)
+	if volume.State != storage.VolumeStateRestricted || volume.Config.Encryption != "true" {
+		t.Errorf("Expected an encrypted volume in its Flexvol's state, got %s and %+v", volume.State, volume.Config)
+	}
+}
+
+func TestValidateHostOptions(t *testing.T) {
+	config := &drivers.OntapStorageDriverConfig{CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{}}
+	config.IgroupName = "trident"
+	config.IgroupPerNode = true
+
+	config.DriverContext = trident.ContextDocker
+	if err := validateHostOptions(config); err != nil {
+		t.Errorf("Expected igroupPerNode to be allowed on Docker, got %v", err)
+	}
+	config.DriverContext = trident.ContextKubernetes
+	if err := validateHostOptions(config); err == nil {
+		t.Error("Expected igroupPerNode to be rejected on Kubernetes")
+	}
+	config.IgroupPerNode = false
+	if err := validateHostOptions(config); err != nil {
+		t.Errorf("Expected a shared igroup to be allowed on Kubernetes, got %v", err)
+	}
+}
+
+func TestSANFinishCreateKeepsExistingLUN(t *testing.T) {
+	client := &mockClient{luns: map[string]bool{}, lunAttributeErr: errors.New("attribute failed")}
+	d := &SANStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	path := lunPath("trident_vol1")
+
+	// A LUN created by this call is destroyed if its attributes can't be saved
+	if err := d.finishCreate(client, "trident_vol1", map[string]string{}, 1073741824, "ext4", false); err == nil {
+		t.Fatal("Expected the failure to save attributes to be returned")
+	}
+	if client.luns[path] {
+		t.Error("Expected the new LUN to be destroyed")
+	}
+
+	// One left by an earlier attempt is kept, so that a retry can complete it
+	client.luns[path] = true
+	if err := d.finishCreate(client, "trident_vol1", map[string]string{}, 1073741824, "ext4", true); err == nil {
+		t.Fatal("Expected the failure to save attributes to be returned")
+	}
+	if !client.luns[path] {
+		t.Error("Expected the existing LUN to be kept")
+	}
+}
+
+func TestSANResumeCreateAdvancedOptions(t *testing.T) {
+	client := &mockClient{
+		luns:            map[string]bool{},
+		volumeOptions:   map[string]map[string]string{},
+		volumeOptionErr: errors.New("connection reset"),
+	}
+	d := &SANStorageDriver{API: client}
+	d.Config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+	d.Config.AdvancedOptions = map[string]string{"no_atime_update": "on"}
+	path := lunPath("trident_vol1")
+
+	// The volume exists, so a failure to set its options is retried rather than creating the LUN
+	err := d.resumeCreate(client, "trident_vol1", map[string]string{}, 1073741824, "ext4")
+	if !drivers.IsRetryableError(err) {
+		t.Fatalf("Expected a retryable error, got %v", err)
+	}
+	if client.luns[path] {
+		t.Error("Expected no LUN before the options are set")
+	}
+
+	// The retry sets the options and creates the LUN
+	client.volumeOptionErr = nil
+	if err := d.resumeCreate(client, "trident_vol1", map[string]string{}, 1073741824, "ext4"); err != nil {
+		t.Fatalf("Unable to resume the create: %v", err)
+	}
+	if client.volumeOptions["trident_vol1"]["no_atime_update"] != "on" || !client.luns[path] {
+		t.Errorf("Expected the options to be set and the LUN created, got %v", client.volumeOptions["trident_vol1"])
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+const (
+	// MaxSnapshotPolicySchedules is the number of schedules ONTAP allows a snapshot policy
+	MaxSnapshotPolicySchedules = 5
+
+	// MaxSnapshotScheduleCount is the number of snapshots ONTAP allows a policy's schedules to keep
+	MaxSnapshotScheduleCount = 1023
+)
+
+// ValidateSnapshotPolicySpec checks that a declared snapshot policy is one ONTAP would accept.
+// The policies ONTAP provides itself may not be declared, as Trident would change every volume
+// that uses them.
+func ValidateSnapshotPolicySpec(spec *drivers.OntapSnapshotPolicySpec) error {
+
+	switch spec.Name {
+	case "":
+		return errors.New("a snapshot policy name is required")
+	case "none", "default":
+		return fmt.Errorf("snapshot policy %s is provided by ONTAP and may not be declared", spec.Name)
+	}
+
+	if len(spec.Schedules) == 0 || len(spec.Schedules) > MaxSnapshotPolicySchedules {
+		return fmt.Errorf("snapshot policy %s must have between 1 and %d schedules", spec.Name,
+			MaxSnapshotPolicySchedules)
+	}
+
+	schedules := make(map[string]bool)
+	for _, schedule := range spec.Schedules {
+		if schedule.Schedule == "" {
+			return fmt.Errorf("every schedule of snapshot policy %s must be named", spec.Name)
+		}
+		if schedules[schedule.Schedule] {
+			return fmt.Errorf("snapshot policy %s has schedule %s more than once", spec.Name, schedule.Schedule)
+		}
+		schedules[schedule.Schedule] = true
+		if schedule.Count < 1 || schedule.Count > MaxSnapshotScheduleCount {
+			return fmt.Errorf("schedule %s of snapshot policy %s must keep between 1 and %d snapshots",
+				schedule.Schedule, spec.Name, MaxSnapshotScheduleCount)
+		}
+	}
+	return nil
+}
+
+// EnsureSnapshotPolicy creates the snapshot policy the backend declares on the SVM, or, if the
+// SVM has it already, adds, changes and removes its schedules until they match the declaration.
+// ONTAP can't change the prefix of an existing schedule, so a new prefix only applies to schedules
+// that are added.  The cron schedules themselves must already exist.
+func EnsureSnapshotPolicy(config *drivers.OntapStorageDriverConfig, client api.ZapiClient) error {
+
+	spec := config.SnapshotPolicySpec
+	if spec == nil {
+		return nil
+	}
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "EnsureSnapshotPolicy", "Type": "ontap_common", "policy": spec.Name}
+		log.WithFields(fields).Debug(">>>> EnsureSnapshotPolicy")
+		defer log.WithFields(fields).Debug("<<<< EnsureSnapshotPolicy")
+	}
+
+	policy, err := client.SnapshotPolicyGet(spec.Name)
+	if err != nil {
+		return fmt.Errorf("could not read snapshot policy %s: %v", spec.Name, err)
+	}
+
+	// ONTAP creates a policy with its first schedule, so the rest are added as for an existing one
+	existing := make(map[string]int)
+	if policy == nil {
+		first := spec.Schedules[0]
+		response, err := client.SnapshotPolicyCreate(spec.Name, first.Schedule, first.Count, first.Prefix)
+		if err = api.GetError(response, err); err != nil {
+			return fmt.Errorf("could not create snapshot policy %s: %v", spec.Name, err)
+		}
+		log.WithField("policy", spec.Name).Info("Created snapshot policy.")
+		existing[first.Schedule] = first.Count
+	} else {
+		for _, schedule := range policy.SnapshotPolicySchedules() {
+			existing[schedule.Schedule()] = schedule.Count()
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, schedule := range spec.Schedules {
+		wanted[schedule.Schedule] = true
+		logFields := log.Fields{"policy": spec.Name, "schedule": schedule.Schedule, "count": schedule.Count}
+
+		count, ok := existing[schedule.Schedule]
+		if !ok {
+			response, err := client.SnapshotPolicyAddSchedule(spec.Name, schedule.Schedule, schedule.Count,
+				schedule.Prefix)
+			if err = api.GetError(response, err); err != nil {
+				return fmt.Errorf("could not add schedule %s to snapshot policy %s: %v", schedule.Schedule,
+					spec.Name, err)
+			}
+			log.WithFields(logFields).Info("Added schedule to snapshot policy.")
+		} else if count != schedule.Count {
+			response, err := client.SnapshotPolicyModifySchedule(spec.Name, schedule.Schedule, schedule.Count)
+			if err = api.GetError(response, err); err != nil {
+				return fmt.Errorf("could not change schedule %s of snapshot policy %s: %v", schedule.Schedule,
+					spec.Name, err)
+			}
+			log.WithFields(logFields).Info("Changed schedule of snapshot policy.")
+		}
+	}
+
+	// Schedules are removed last, as ONTAP won't leave a policy without any
+	if policy == nil {
+		return nil
+	}
+	for _, existingSchedule := range policy.SnapshotPolicySchedules() {
+		schedule := existingSchedule.Schedule()
+		if wanted[schedule] {
+			continue
+		}
+		response, err := client.SnapshotPolicyRemoveSchedule(spec.Name, schedule)
+		if err = api.GetError(response, err); err != nil {
+			return fmt.Errorf("could not remove schedule %s from snapshot policy %s: %v", schedule, spec.Name, err)
+		}
+		log.WithFields(log.Fields{
+			"policy":   spec.Name,
+			"schedule": schedule,
+		}).Info("Removed schedule from snapshot policy.")
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"testing"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api/azgo"
+)
+
+func TestValidateSnapshotPolicySpec(t *testing.T) {
+	hourly := drivers.OntapSnapshotSchedule{Schedule: "hourly", Count: 6}
+	for _, test := range []struct {
+		name        string
+		spec        drivers.OntapSnapshotPolicySpec
+		expectedErr bool
+	}{
+		{"valid", drivers.OntapSnapshotPolicySpec{Name: "gold", Schedules: []drivers.OntapSnapshotSchedule{
+			hourly, {Schedule: "daily", Count: 2, Prefix: "nightly"}}}, false},
+		{"noName", drivers.OntapSnapshotPolicySpec{Schedules: []drivers.OntapSnapshotSchedule{hourly}}, true},
+		{"ontapPolicy", drivers.OntapSnapshotPolicySpec{Name: "default",
+			Schedules: []drivers.OntapSnapshotSchedule{hourly}}, true},
+		{"noSchedules", drivers.OntapSnapshotPolicySpec{Name: "gold"}, true},
+		{"tooManySchedules", drivers.OntapSnapshotPolicySpec{Name: "gold",
+			Schedules: []drivers.OntapSnapshotSchedule{{"a", 1, ""}, {"b", 1, ""}, {"c", 1, ""}, {"d", 1, ""},
+				{"e", 1, ""}, {"f", 1, ""}}}, true},
+		{"duplicateSchedule", drivers.OntapSnapshotPolicySpec{Name: "gold",
+			Schedules: []drivers.OntapSnapshotSchedule{hourly, hourly}}, true},
+		{"noCount", drivers.OntapSnapshotPolicySpec{Name: "gold",
+			Schedules: []drivers.OntapSnapshotSchedule{{Schedule: "hourly"}}}, true},
+		{"countTooHigh", drivers.OntapSnapshotPolicySpec{Name: "gold",
+			Schedules: []drivers.OntapSnapshotSchedule{{Schedule: "hourly", Count: 1024}}}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSnapshotPolicySpec(&test.spec)
+			if test.expectedErr && err == nil {
+				t.Error("Expected an error for an invalid snapshot policy spec.")
+			} else if !test.expectedErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSnapshotPolicySpecDefaults(t *testing.T) {
+	spec := &drivers.OntapSnapshotPolicySpec{Name: "gold",
+		Schedules: []drivers.OntapSnapshotSchedule{{Schedule: "hourly", Count: 6}}}
+
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+		SnapshotPolicySpec:        spec,
+	}
+	if err := PopulateConfigurationDefaults(config); err != nil {
+		t.Fatal("Unable to populate defaults: ", err)
+	}
+	if config.SnapshotPolicy != "gold" {
+		t.Errorf("Expected volumes to get the declared snapshot policy, got %s.", config.SnapshotPolicy)
+	}
+
+	config = &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+		SnapshotPolicySpec:        spec,
+	}
+	config.SnapshotPolicy = "silver"
+	if err := PopulateConfigurationDefaults(config); err == nil {
+		t.Error("Expected an error for a snapshot policy other than the declared one.")
+	}
+}
+
+func TestEnsureSnapshotPolicy(t *testing.T) {
+	client := &mockClient{snapshotPolicies: make(map[string][]azgo.SnapshotScheduleInfoType)}
+	config := &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+		SnapshotPolicySpec: &drivers.OntapSnapshotPolicySpec{Name: "gold",
+			Schedules: []drivers.OntapSnapshotSchedule{
+				{Schedule: "hourly", Count: 6},
+				{Schedule: "daily", Count: 2, Prefix: "nightly"},
+			}},
+	}
+
+	counts := func() map[string]int {
+		schedules := make(map[string]int)
+		for _, schedule := range client.snapshotPolicies["gold"] {
+			schedules[schedule.Schedule()] = schedule.Count()
+		}
+		return schedules
+	}
+
+	// A missing policy is created with every schedule
+	if err := EnsureSnapshotPolicy(config, client); err != nil {
+		t.Fatal("Unable to create snapshot policy: ", err)
+	}
+	if schedules := counts(); len(schedules) != 2 || schedules["hourly"] != 6 || schedules["daily"] != 2 {
+		t.Errorf("Unexpected schedules %v.", schedules)
+	}
+	if client.snapshotPolicies["gold"][1].Prefix() != "nightly" {
+		t.Error("Expected the schedule's prefix to be set.")
+	}
+
+	// An existing policy is changed to match
+	config.SnapshotPolicySpec.Schedules = []drivers.OntapSnapshotSchedule{
+		{Schedule: "daily", Count: 7},
+		{Schedule: "weekly", Count: 4},
+	}
+	if err := EnsureSnapshotPolicy(config, client); err != nil {
+		t.Fatal("Unable to reconcile snapshot policy: ", err)
+	}
+	if schedules := counts(); len(schedules) != 2 || schedules["daily"] != 7 || schedules["weekly"] != 4 {
+		t.Errorf("Unexpected schedules %v.", schedules)
+	}
+
+	// Without a declaration, the SVM's policies are left alone
+	config.SnapshotPolicySpec = nil
+	if err := EnsureSnapshotPolicy(config, &mockClient{}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+// Ways in which a backend's usage heartbeats reach NetApp
+const (
+	TelemetryTransportEMS   = "ems"   // logged to the cluster's EMS, which forwards them by AutoSupport
+	TelemetryTransportHTTPS = "https" // posted directly to ActiveIQ
+	TelemetryTransportAuto  = "auto"  // logged to EMS, or posted to ActiveIQ if EMS is unavailable
+
+	DefaultTelemetryTransport = TelemetryTransportEMS
+	DefaultTelemetryURL       = "https://support.netapp.com/put/AsupPut"
+
+	DefaultHeartbeatIntervalHours = 24.0
+
+	heartbeatTask        = "heartbeat"
+	telemetryPostTimeout = 30 * time.Second
+)
+
+// Telemetry is the usage heartbeat of an ONTAP backend.  Its exported fields make up the message.
+type Telemetry struct {
+	trident.Telemetry
+	Plugin        string        `json:"plugin"`
+	SVM           string        `json:"svm"`
+	StoragePrefix string        `json:"storagePrefix"`
+	Driver        StorageDriver `json:"-"`
+
+	mutex        *sync.Mutex
+	interval     time.Duration
+	https        *httpsTelemetry
+	housekeeping *utils.HousekeepingScheduler // set while heartbeats are scheduled
+}
+
+// NewOntapTelemetry returns the heartbeat of a driver, configured from the driver's config.  The
+// heartbeat doesn't run until started.
+func NewOntapTelemetry(d StorageDriver) *Telemetry {
+	t := &Telemetry{
+		Telemetry: trident.OrchestratorTelemetry,
+		Plugin:    d.Name(),
+		Driver:    d,
+		mutex:     &sync.Mutex{},
+	}
+	t.configure()
+	return t
+}
+
+// configure reads the heartbeat's settings from the driver's config.
+func (t *Telemetry) configure() {
+
+	config := t.Driver.GetConfig()
+	t.SVM = config.SVM
+	t.StoragePrefix = ""
+	if config.StoragePrefix != nil {
+		t.StoragePrefix = *config.StoragePrefix
+	}
+	t.interval = heartbeatInterval(config.UsageHeartbeat)
+
+	https, err := newHTTPSTelemetry(config)
+	if err != nil {
+		log.WithField("driver", t.Driver.Name()).Warnf("Could not configure HTTPS telemetry. %v", err)
+	}
+	t.https = https
+}
+
+// heartbeatInterval parses the usageHeartbeat setting, in hours.  A setting that isn't a positive
+// number is ignored in favor of the default, so that heartbeats always repeat.
+func heartbeatInterval(usageHeartbeat string) time.Duration {
+
+	hours := DefaultHeartbeatIntervalHours
+	if usageHeartbeat != "" {
+		f, err := strconv.ParseFloat(usageHeartbeat, 64)
+		if err != nil || f <= 0 {
+			log.WithField("interval", usageHeartbeat).Warnf(
+				"Invalid heartbeat interval, using %v hours.", DefaultHeartbeatIntervalHours)
+		} else {
+			hours = f
+		}
+	}
+	log.WithField("intervalHours", hours).Debug("Configured heartbeat.")
+
+	interval := time.Millisecond * time.Duration(MSecPerHour*hours)
+	if interval <= 0 {
+		// An interval too short to measure would make the heartbeat a one-off
+		interval = time.Millisecond
+	}
+	return interval
+}
+
+// Start schedules the flow of heartbeat messages for the driver, which stops when the heartbeat
+// or the scheduler is stopped.  Starting a running heartbeat does nothing, unless it is started
+// on a different scheduler, to which it moves.  Starting a stopped heartbeat restarts it with the
+// driver's current settings, such as after its backend's config is updated.
+func (t *Telemetry) Start(housekeeping *utils.HousekeepingScheduler) error {
+
+	if housekeeping == nil {
+		return errors.New("heartbeat requires a housekeeping scheduler")
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.housekeeping == housekeeping {
+		return nil
+	}
+	if t.housekeeping != nil {
+		t.housekeeping.Cancel(heartbeatTask)
+		t.housekeeping = nil
+	}
+
+	t.configure()
+	err := housekeeping.Schedule(utils.HousekeepingTask{
+		Name:         heartbeatTask,
+		Interval:     t.interval,
+		InitialDelay: HousekeepingStartupDelaySecs * time.Second,
+		Jitter:       HousekeepingMaxJitterSecs * time.Second,
+		Run:          func() { SendHeartbeat(t.Driver) },
+	})
+	if err != nil {
+		return err
+	}
+	t.housekeeping = housekeeping
+	return nil
+}
+
+// Stop cancels the driver's heartbeat messages.  A heartbeat being sent is allowed to finish.
+// Stopping a heartbeat that isn't running, or a nil one, does nothing.
+func (t *Telemetry) Stop() {
+
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.housekeeping != nil {
+		t.housekeeping.Cancel(heartbeatTask)
+		t.housekeeping = nil
+	}
+}
+
+// populateTelemetryDefaults validates how the backend's usage heartbeats are sent.  Heartbeats
+// leave the cluster over HTTPS only if the administrator has consented to it.
+func populateTelemetryDefaults(config *drivers.OntapStorageDriverConfig) error {
+
+	switch config.TelemetryTransport {
+	case "":
+		config.TelemetryTransport = DefaultTelemetryTransport
+	case TelemetryTransportEMS:
+	case TelemetryTransportHTTPS, TelemetryTransportAuto:
+		if !config.TelemetryConsent {
+			return fmt.Errorf("telemetryTransport %s posts usage data to NetApp, which requires "+
+				"telemetryConsent to be true", config.TelemetryTransport)
+		}
+	default:
+		return fmt.Errorf("invalid value for telemetryTransport: %s", config.TelemetryTransport)
+	}
+
+	if config.TelemetryURL == "" {
+		config.TelemetryURL = DefaultTelemetryURL
+	} else if _, err := parseTelemetryURL("telemetryURL", config.TelemetryURL); err != nil {
+		return err
+	}
+
+	if config.TelemetryProxyURL != "" {
+		if _, err := parseTelemetryURL("telemetryProxyURL", config.TelemetryProxyURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTelemetryURL checks that a telemetry setting is an absolute HTTP or HTTPS URL.
+func parseTelemetryURL(setting, value string) (*url.URL, error) {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %v", setting, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid value for %s; it must be an http or https URL", setting)
+	}
+	return parsed, nil
+}
+
+// httpsTelemetry posts usage heartbeats to NetApp ActiveIQ, optionally through a proxy.
+type httpsTelemetry struct {
+	url    string
+	client *http.Client
+}
+
+// newHTTPSTelemetry returns a sender for the heartbeats of a backend whose transport may use
+// HTTPS, or nil if heartbeats only go to EMS.
+func newHTTPSTelemetry(config *drivers.OntapStorageDriverConfig) (*httpsTelemetry, error) {
+
+	if config.TelemetryTransport != TelemetryTransportHTTPS && config.TelemetryTransport != TelemetryTransportAuto {
+		return nil, nil
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if config.TelemetryProxyURL != "" {
+		proxyURL, err := parseTelemetryURL("telemetryProxyURL", config.TelemetryProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &httpsTelemetry{
+		url:    config.TelemetryURL,
+		client: &http.Client{Transport: transport, Timeout: telemetryPostTimeout},
+	}, nil
+}
+
+// post sends one heartbeat message.
+func (h *httpsTelemetry) post(message []byte, hostname string) error {
+
+	request, err := http.NewRequest("POST", h.url, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Netapp-Asup-Source", trident.OrchestratorName)
+	request.Header.Set("X-Netapp-Asup-Hostname", hostname)
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("ActiveIQ returned %s", response.Status)
+	}
+	return nil
+}
+
+// sendHTTPSHeartbeat posts a heartbeat message to ActiveIQ on behalf of a driver.
+func sendHTTPSHeartbeat(driver StorageDriver, message []byte, hostname string) error {
+
+	telemetry := driver.GetTelemetry()
+	if telemetry == nil || telemetry.https == nil {
+		return errors.New("HTTPS telemetry is not configured")
+	}
+	if err := telemetry.https.post(message, hostname); err != nil {
+		return fmt.Errorf("could not post heartbeat to %s: %v", drivers.RedactURL(telemetry.https.url), err)
+	}
+	log.WithField("driver", driver.Name()).Info("Posted heartbeat to ActiveIQ.")
+	return nil
+}
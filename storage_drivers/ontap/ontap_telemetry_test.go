@@ -0,0 +1,200 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/utils"
+)
+
+func TestPopulateTelemetryDefaults(t *testing.T) {
+	for _, test := range []struct {
+		name, transport, proxyURL string
+		consent                   bool
+		expectedTransport         string
+		expectedErr               bool
+	}{
+		{"defaults", "", "", false, TelemetryTransportEMS, false},
+		{"https", "https", "http://proxy.example.com:3128", true, TelemetryTransportHTTPS, false},
+		{"auto", "auto", "", true, TelemetryTransportAuto, false},
+		{"noConsent", "https", "", false, "", true},
+		{"badTransport", "smtp", "", true, "", true},
+		{"badProxy", "https", "proxy.example.com:3128", true, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{},
+				TelemetryTransport:        test.transport,
+				TelemetryConsent:          test.consent,
+				TelemetryProxyURL:         test.proxyURL,
+			}
+			err := populateTelemetryDefaults(config)
+			if test.expectedErr {
+				if err == nil {
+					t.Error("Expected an error for invalid telemetry settings.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unable to populate defaults: ", err)
+			}
+			if config.TelemetryTransport != test.expectedTransport {
+				t.Errorf("Expected transport %s, got %s", test.expectedTransport, config.TelemetryTransport)
+			}
+			if config.TelemetryURL != DefaultTelemetryURL {
+				t.Errorf("Expected URL %s, got %s", DefaultTelemetryURL, config.TelemetryURL)
+			}
+		})
+	}
+}
+
+func TestHTTPSTelemetryPost(t *testing.T) {
+	var body, hostname string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		hostname = r.Header.Get("X-Netapp-Asup-Hostname")
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	config := &drivers.OntapStorageDriverConfig{
+		TelemetryTransport: TelemetryTransportHTTPS,
+		TelemetryURL:       server.URL,
+	}
+	sender, err := newHTTPSTelemetry(config)
+	if err != nil {
+		t.Fatal("Unable to create sender: ", err)
+	}
+	if err = sender.post([]byte(`{"plugin":"ontap-nas"}`), "node1"); err != nil {
+		t.Fatal("Unable to post heartbeat: ", err)
+	}
+	if body != `{"plugin":"ontap-nas"}` || hostname != "node1" {
+		t.Errorf("Unexpected heartbeat; got body %s from host %s", body, hostname)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	sender.url = failing.URL
+	if err = sender.post([]byte("{}"), "node1"); err == nil {
+		t.Error("Expected an error for a rejected heartbeat.")
+	}
+}
+
+func TestHTTPSTelemetryProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	config := &drivers.OntapStorageDriverConfig{
+		TelemetryTransport: TelemetryTransportAuto,
+		TelemetryURL:       "http://activeiq.example.com/put/AsupPut",
+		TelemetryProxyURL:  proxy.URL,
+	}
+	sender, err := newHTTPSTelemetry(config)
+	if err != nil {
+		t.Fatal("Unable to create sender: ", err)
+	}
+	if err = sender.post([]byte("{}"), "node1"); err != nil {
+		t.Fatal("Unable to post heartbeat through proxy: ", err)
+	}
+	if proxied != config.TelemetryURL {
+		t.Errorf("Expected the proxy to receive %s, got %s", config.TelemetryURL, proxied)
+	}
+
+	config.TelemetryTransport = TelemetryTransportEMS
+	if sender, _ = newHTTPSTelemetry(config); sender != nil {
+		t.Error("Expected no HTTPS sender for the EMS transport.")
+	}
+}
+
+func newTestTelemetryDriver(usageHeartbeat string) *NASStorageDriver {
+	prefix := "trident_"
+	return &NASStorageDriver{
+		Config: drivers.OntapStorageDriverConfig{
+			CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{StoragePrefix: &prefix},
+			SVM:                       "svm0",
+			UsageHeartbeat:            usageHeartbeat,
+		},
+	}
+}
+
+func TestHeartbeatInterval(t *testing.T) {
+	for usageHeartbeat, expected := range map[string]time.Duration{
+		"":     24 * time.Hour,
+		"0.5":  30 * time.Minute,
+		"0":    24 * time.Hour,
+		"-1":   24 * time.Hour,
+		"lots": 24 * time.Hour,
+	} {
+		if interval := heartbeatInterval(usageHeartbeat); interval != expected {
+			t.Errorf("Expected interval %v for usageHeartbeat %q, got %v", expected, usageHeartbeat, interval)
+		}
+	}
+}
+
+func TestTelemetryLifecycle(t *testing.T) {
+	driver := newTestTelemetryDriver("")
+	telemetry := NewOntapTelemetry(driver)
+	if telemetry.SVM != "svm0" || telemetry.StoragePrefix != "trident_" {
+		t.Errorf("Unexpected heartbeat message fields: %+v", telemetry)
+	}
+
+	// Stopping a heartbeat that was never started, or a nil one, is harmless
+	telemetry.Stop()
+	var nilTelemetry *Telemetry
+	nilTelemetry.Stop()
+
+	if err := telemetry.Start(nil); err == nil {
+		t.Error("Expected an error starting without a scheduler.")
+	}
+
+	housekeeping := utils.NewHousekeepingScheduler("test")
+	defer housekeeping.Stop()
+
+	for i := 0; i < 2; i++ {
+		if err := telemetry.Start(housekeeping); err != nil {
+			t.Fatalf("Unable to start heartbeat (attempt %d): %v", i+1, err)
+		}
+	}
+	if telemetry.housekeeping != housekeeping {
+		t.Error("Expected the heartbeat to be running.")
+	}
+
+	telemetry.Stop()
+	telemetry.Stop()
+	if telemetry.housekeeping != nil {
+		t.Error("Expected the heartbeat to be stopped.")
+	}
+
+	// Restarting picks up the driver's current settings
+	driver.Config.UsageHeartbeat = "2"
+	if err := telemetry.Start(housekeeping); err != nil {
+		t.Fatal("Unable to restart heartbeat: ", err)
+	}
+	if telemetry.interval != 2*time.Hour {
+		t.Errorf("Expected the restarted heartbeat to run every 2h, got %v", telemetry.interval)
+	}
+
+	// Moving to another scheduler cancels the heartbeat on the first one
+	other := utils.NewHousekeepingScheduler("other")
+	defer other.Stop()
+	if err := telemetry.Start(other); err != nil {
+		t.Fatal("Unable to move heartbeat: ", err)
+	}
+	if err := housekeeping.Schedule(utils.HousekeepingTask{Name: heartbeatTask, Run: func() {}}); err != nil {
+		t.Errorf("Expected the first scheduler to no longer run the heartbeat: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+// tenantPrefixRegex matches the storage prefixes a tenant may be given, which ONTAP accepts at the
+// start of a volume name and which naming never alters.
+var tenantPrefixRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateTenantPrefixes checks that each tenant's volumes can be named and listed apart from every
+// other tenant's and from the backend's own.  No prefix may begin another, including the backend's
+// storage prefix, so that listing the volumes of one prefix never finds those of another.
+func validateTenantPrefixes(config *drivers.OntapStorageDriverConfig) error {
+
+	if len(config.TenantPrefixes) == 0 {
+		return nil
+	}
+
+	switch config.StorageDriverName {
+	case drivers.OntapNASStorageDriverName, drivers.OntapSANStorageDriverName:
+	default:
+		return fmt.Errorf("tenantPrefixes is not supported by the %s driver", config.StorageDriverName)
+	}
+
+	// With a passthrough store, volumes are found by reversing the name mapping
+	if trident.UsingPassthroughStore {
+		return errors.New("tenantPrefixes requires an external store, as tenants' volume names " +
+			"can't be mapped back to volumes")
+	}
+
+	if *config.StoragePrefix == "" {
+		return errors.New("tenantPrefixes requires a storagePrefix, as an empty prefix would list " +
+			"every tenant's volumes")
+	}
+
+	owners := map[string]string{*config.StoragePrefix: "the backend"}
+	for tenant, prefix := range config.TenantPrefixes {
+		if tenant == "" {
+			return errors.New("tenantPrefixes must name the namespace of each tenant")
+		}
+		if !tenantPrefixRegex.MatchString(prefix) {
+			return fmt.Errorf("prefix %s of tenant %s may only contain letters, digits and underscores, "+
+				"and may not begin with a digit", prefix, tenant)
+		}
+		if owner, ok := owners[prefix]; ok {
+			return fmt.Errorf("tenant %s has the same prefix, %s, as %s", tenant, prefix, owner)
+		}
+		owners[prefix] = "tenant " + tenant
+	}
+
+	prefixes := storagePrefixes(config)
+	for _, prefix := range prefixes {
+		for _, other := range prefixes {
+			if prefix != other && strings.HasPrefix(other, prefix) {
+				return fmt.Errorf("prefix %s of %s begins prefix %s of %s, so their volumes can't be "+
+					"listed apart", prefix, owners[prefix], other, owners[other])
+			}
+		}
+	}
+
+	return nil
+}
+
+// tenantStoragePrefix returns the prefix of the volumes a namespace requests, which is its
+// tenant's prefix if it has one, or else the backend's storage prefix.
+func tenantStoragePrefix(config *drivers.OntapStorageDriverConfig, namespace string) string {
+	if prefix, ok := config.TenantPrefixes[namespace]; ok && namespace != "" {
+		return prefix
+	}
+	return *config.StoragePrefix
+}
+
+// storagePrefixes returns every prefix of the backend's volumes: its storage prefix, followed by
+// those of its tenants in order.
+func storagePrefixes(config *drivers.OntapStorageDriverConfig) []string {
+	tenantPrefixes := make([]string, 0, len(config.TenantPrefixes))
+	for _, prefix := range config.TenantPrefixes {
+		tenantPrefixes = append(tenantPrefixes, prefix)
+	}
+	sort.Strings(tenantPrefixes)
+	return append([]string{*config.StoragePrefix}, tenantPrefixes...)
+}
+
+// splitStoragePrefix returns the tenant whose prefix a volume's name begins with, if any, along
+// with the name without its prefix.  Prefixes never begin one another, so at most one matches.
+func splitStoragePrefix(config *drivers.OntapStorageDriverConfig, internalName string) (string, string) {
+	for tenant, prefix := range config.TenantPrefixes {
+		if strings.HasPrefix(internalName, prefix) {
+			return tenant, internalName[len(prefix):]
+		}
+	}
+	return "", strings.TrimPrefix(internalName, *config.StoragePrefix)
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+)
+
+func newTenantConfig(driverName string, tenantPrefixes map[string]string) *drivers.OntapStorageDriverConfig {
+	prefix := "trident_"
+	return &drivers.OntapStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+			StorageDriverName: driverName,
+			StoragePrefix:     &prefix,
+		},
+		TenantPrefixes: tenantPrefixes,
+	}
+}
+
+func TestValidateTenantPrefixes(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		driverName     string
+		tenantPrefixes map[string]string
+		expectedErr    bool
+	}{
+		{"none", drivers.OntapNASQtreeStorageDriverName, nil, false},
+		{"valid", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "teama_", "team-b": "teamb_"}, false},
+		{"san", drivers.OntapSANStorageDriverName, map[string]string{"team-a": "teama_"}, false},
+		{"economy", drivers.OntapNASQtreeStorageDriverName, map[string]string{"team-a": "teama_"}, true},
+		{"noNamespace", drivers.OntapNASStorageDriverName, map[string]string{"": "teama_"}, true},
+		{"invalidPrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "team-a"}, true},
+		{"digitPrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "1a_"}, true},
+		{"samePrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "a_", "team-b": "a_"}, true},
+		{"backendPrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "trident_"}, true},
+		{"nestedPrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "a_", "team-b": "a_b_"}, true},
+		{"nestedBackendPrefix", drivers.OntapNASStorageDriverName, map[string]string{"team-a": "trident_a_"}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateTenantPrefixes(newTenantConfig(test.driverName, test.tenantPrefixes))
+			if test.expectedErr && err == nil {
+				t.Error("Expected an error for invalid tenant prefixes.")
+			} else if !test.expectedErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	config := newTenantConfig(drivers.OntapNASStorageDriverName, map[string]string{"team-a": "teama_"})
+	emptyPrefix := ""
+	config.StoragePrefix = &emptyPrefix
+	if err := validateTenantPrefixes(config); err == nil {
+		t.Error("Expected an error for tenant prefixes without a storage prefix.")
+	}
+}
+
+func TestTenantVolumeNames(t *testing.T) {
+	config := newTenantConfig(drivers.OntapNASStorageDriverName, map[string]string{"team-a": "teama_"})
+
+	for namespace, expected := range map[string]string{
+		"team-a": "teama_data",
+		"team-b": "trident_data",
+		"":       "trident_data",
+	} {
+		volConfig := &storage.VolumeConfig{Name: "data", Namespace: namespace, RequestName: "pvc1"}
+		if name := getInternalVolumeNameFromTemplate(config, volConfig); name != expected {
+			t.Errorf("Expected name %s for namespace %q, got %s", expected, namespace, name)
+		}
+	}
+
+	config.NameTemplate = "{{prefix}}_{{pvc}}"
+	volConfig := &storage.VolumeConfig{Name: "data", Namespace: "team-a", RequestName: "pvc1"}
+	if name := getInternalVolumeNameFromTemplate(config, volConfig); name != "teama_pvc1" {
+		t.Errorf("Expected the template's prefix to be the tenant's, got %s", name)
+	}
+	if *config.StoragePrefix != "trident_" {
+		t.Errorf("Expected the backend's storage prefix to be unchanged, got %s", *config.StoragePrefix)
+	}
+
+	tenant, name := splitStoragePrefix(config, "teama_pvc1")
+	if tenant != "team-a" || name != "pvc1" {
+		t.Errorf("Expected volume pvc1 of tenant team-a, got %s of %q", name, tenant)
+	}
+	tenant, name = splitStoragePrefix(config, "trident_data")
+	if tenant != "" || name != "data" {
+		t.Errorf("Expected volume data of no tenant, got %s of %q", name, tenant)
+	}
+}
+
+func TestTenantVolumeList(t *testing.T) {
+	config := newTenantConfig(drivers.OntapNASStorageDriverName,
+		map[string]string{"team-a": "teama_", "team-b": "teamb_"})
+	client := &mockClient{volumeStates: map[string]string{
+		"trident_vol1": "online",
+		"teama_vol2":   "online",
+		"teamb_vol3":   "online",
+		"other_vol4":   "online",
+	}}
+
+	volumes, err := GetVolumeList(client, config)
+	if err != nil {
+		t.Fatal("Unable to list volumes: ", err)
+	}
+	if expected := []string{"vol1", "vol2", "vol3"}; !reflect.DeepEqual(volumes, expected) {
+		t.Errorf("Expected volumes %v, got %v", expected, volumes)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+// ontapReleasePattern finds the release, such as 9.5 or 9.5.1, in the version ONTAP reports, such
+// as "NetApp Release 9.5P2: Tue Feb 12 07:03:38 UTC 2019".
+var ontapReleasePattern = regexp.MustCompile(`Release (\d+\.\d+(?:\.\d+)?)`)
+
+// versionGatedOptions are backend options that only clusters supporting a feature in the version
+// matrix accept, along with the release that introduced the feature.
+var versionGatedOptions = []struct {
+	feature api.Feature
+	release string
+	option  string
+	value   func(config *drivers.OntapStorageDriverConfig) string
+}{
+	{api.NetAppVolumeEncryption, "9.1", "encryption", func(config *drivers.OntapStorageDriverConfig) string {
+		if config.Encryption == "true" {
+			return config.Encryption
+		}
+		return ""
+	}},
+	{api.FabricPool, "9.2", "tieringPolicy", func(config *drivers.OntapStorageDriverConfig) string {
+		return config.TieringPolicy
+	}},
+}
+
+// DetectVersionMatrix reads the cluster's ONTAP release and ONTAPI version and records in the
+// config which of the features in the version matrix they support, so that the driver's defaults
+// and code paths are chosen from one place rather than by asking the cluster each time.
+func DetectVersionMatrix(client api.ZapiClient, config *drivers.OntapStorageDriverConfig) error {
+
+	ontapi, err := client.SystemGetOntapiVersion()
+	if err != nil {
+		return fmt.Errorf("could not determine Data ONTAP API version: %v", err)
+	}
+	config.ONTAPIVersion = ontapi
+
+	// The release is only reported, so the driver can do without it
+	versionResponse, err := client.SystemGetVersion()
+	if err = api.GetError(versionResponse, err); err != nil {
+		log.Debugf("Could not determine ONTAP release. %v", err)
+	} else if versionResponse.Result.VersionPtr != nil {
+		if match := ontapReleasePattern.FindStringSubmatch(versionResponse.Result.Version()); match != nil {
+			config.ONTAPVersion = match[1]
+		}
+	}
+
+	config.VersionFeatures = make(map[string]bool)
+	supported := make([]string, 0)
+	for _, feature := range api.Features() {
+		config.VersionFeatures[string(feature)] = client.SupportsFeature(feature)
+		if config.VersionFeatures[string(feature)] {
+			supported = append(supported, string(feature))
+		}
+	}
+	sort.Strings(supported)
+
+	log.WithFields(log.Fields{
+		"ontapVersion":  config.ONTAPVersion,
+		"ontapiVersion": config.ONTAPIVersion,
+		"features":      strings.Join(supported, ","),
+	}).Debug("ONTAP version matrix.")
+
+	return nil
+}
+
+// SupportsVersionFeature returns true if the cluster's version supports a feature, according to
+// the version matrix found when the driver was initialized.
+func SupportsVersionFeature(config *drivers.OntapStorageDriverConfig, feature api.Feature) bool {
+	return config.VersionFeatures[string(feature)]
+}
+
+// PopulateVersionDefaults adjusts the backend's defaults to the cluster's version.  A default that
+// a profile filled in is dropped if the cluster is too old for it, while one that the backend config
+// set is rejected, since ONTAP would refuse every volume created with it.
+func PopulateVersionDefaults(config *drivers.OntapStorageDriverConfig) error {
+
+	for _, gated := range versionGatedOptions {
+		value := gated.value(config)
+		if value == "" || SupportsVersionFeature(config, gated.feature) {
+			continue
+		}
+		if gated.option == "tieringPolicy" && IsCloudVolumesONTAP(config) && value == CVODefaultTieringPolicy {
+			log.WithFields(log.Fields{
+				"tieringPolicy": value,
+				"ontapVersion":  config.ONTAPVersion,
+			}).Warn("ONTAP is too old for FabricPool, so volumes will be created without a tiering policy.")
+			config.TieringPolicy = ""
+			continue
+		}
+		return fmt.Errorf("%s requires ONTAP %s or later", gated.option, gated.release)
+	}
+	return nil
+}
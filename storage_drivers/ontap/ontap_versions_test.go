@@ -0,0 +1,68 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package ontap
+
+import (
+	"testing"
+
+	trident "github.com/netapp/trident/config"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap/api"
+)
+
+func TestDetectVersionMatrix(t *testing.T) {
+	client := &mockClient{
+		ontapiVersion: "1.140",
+		ontapVersion:  "NetApp Release 9.4P1: Mon Jun 04 12:35:22 UTC 2018",
+		features:      map[api.Feature]bool{api.MinimumONTAPIVersion: true, api.QosMinThroughput: true},
+	}
+	config := &drivers.OntapStorageDriverConfig{}
+	if err := DetectVersionMatrix(client, config); err != nil {
+		t.Fatal("Unable to detect version matrix: ", err)
+	}
+	if config.ONTAPVersion != "9.4" || config.ONTAPIVersion != "1.140" {
+		t.Errorf("Expected ONTAP 9.4 with ONTAPI 1.140, got %s with %s.", config.ONTAPVersion, config.ONTAPIVersion)
+	}
+	if len(config.VersionFeatures) != len(api.Features()) {
+		t.Errorf("Expected every feature in the matrix, got %v.", config.VersionFeatures)
+	}
+	if !SupportsVersionFeature(config, api.QosMinThroughput) || SupportsVersionFeature(config, api.RESTAPI) {
+		t.Errorf("Unexpected version matrix %v.", config.VersionFeatures)
+	}
+}
+
+func TestPopulateVersionDefaults(t *testing.T) {
+	oldONTAP := versionFeatures(map[api.Feature]bool{api.MinimumONTAPIVersion: true})
+
+	// Explicit settings the cluster can't honor are rejected
+	for _, config := range []*drivers.OntapStorageDriverConfig{
+		{OntapStorageDriverConfigDefaults: drivers.OntapStorageDriverConfigDefaults{Encryption: "true"}},
+		{OntapStorageDriverConfigDefaults: drivers.OntapStorageDriverConfigDefaults{TieringPolicy: "auto"}},
+	} {
+		config.CommonStorageDriverConfig = &drivers.CommonStorageDriverConfig{}
+		config.VersionFeatures = oldONTAP
+		if err := PopulateVersionDefaults(config); err == nil {
+			t.Errorf("Expected an error for defaults %+v on older ONTAP.", config.OntapStorageDriverConfigDefaults)
+		}
+	}
+
+	// A profile's tiering policy is dropped instead
+	config := newProfileConfig(ProfileCloudVolumesONTAP, trident.ContextKubernetes)
+	if err := PopulateProfileDefaults(config); err != nil {
+		t.Fatal("Unable to populate profile defaults: ", err)
+	}
+	config.Encryption = "false"
+	config.VersionFeatures = oldONTAP
+	if err := PopulateVersionDefaults(config); err != nil || config.TieringPolicy != "" {
+		t.Errorf("Expected the profile's tiering policy to be dropped, got %s (%v).", config.TieringPolicy, err)
+	}
+
+	config = newProfileConfig(ProfileCloudVolumesONTAP, trident.ContextKubernetes)
+	if err := PopulateProfileDefaults(config); err != nil {
+		t.Fatal("Unable to populate profile defaults: ", err)
+	}
+	config.VersionFeatures = versionFeatures(map[api.Feature]bool{api.FabricPool: true})
+	if err := PopulateVersionDefaults(config); err != nil || config.TieringPolicy != CVODefaultTieringPolicy {
+		t.Errorf("Expected the profile's tiering policy to be kept, got %s (%v).", config.TieringPolicy, err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import "context"
+
+// ProgressReporter is notified of significant steps in provisioning a volume, such as the choice
+// of backend or the start of a clone split, so that a frontend can relay them to the user.
+type ProgressReporter interface {
+	ReportProgress(reason, message string)
+	ReportWarning(reason, message string)
+}
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a copy of the context that carries the supplied reporter.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ReportProgress passes a provisioning step to the context's reporter, if there is one.
+func ReportProgress(ctx context.Context, reason, message string) {
+	if reporter := progressReporterFromContext(ctx); reporter != nil {
+		reporter.ReportProgress(reason, message)
+	}
+}
+
+// ReportWarning passes a provisioning problem that is not necessarily fatal, such as the failure
+// of one of several candidate backends, to the context's reporter, if there is one.
+func ReportWarning(ctx context.Context, reason, message string) {
+	if reporter := progressReporterFromContext(ctx); reporter != nil {
+		reporter.ReportWarning(reason, message)
+	}
+}
+
+func progressReporterFromContext(ctx context.Context) ProgressReporter {
+	if ctx == nil {
+		return nil
+	}
+	reporter, _ := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return reporter
+}
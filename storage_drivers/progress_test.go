@@ -0,0 +1,39 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"context"
+	"testing"
+)
+
+type testReporter struct {
+	progress []string
+	warnings []string
+}
+
+func (r *testReporter) ReportProgress(reason, message string) {
+	r.progress = append(r.progress, reason+": "+message)
+}
+
+func (r *testReporter) ReportWarning(reason, message string) {
+	r.warnings = append(r.warnings, reason+": "+message)
+}
+
+func TestProgressReporting(t *testing.T) {
+	// Reporting without a reporter must be harmless
+	ReportProgress(context.Background(), "Step", "no reporter")
+	ReportWarning(nil, "Step", "no context")
+
+	reporter := &testReporter{}
+	ctx := WithProgressReporter(context.Background(), reporter)
+	ReportProgress(ctx, "Step", "first")
+	ReportWarning(ctx, "Problem", "second")
+
+	if len(reporter.progress) != 1 || reporter.progress[0] != "Step: first" {
+		t.Errorf("Unexpected progress reports: %v", reporter.progress)
+	}
+	if len(reporter.warnings) != 1 || reporter.warnings[0] != "Problem: second" {
+		t.Errorf("Unexpected warnings: %v", reporter.warnings)
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"context"
+)
+
+type managedQoSPolicyKey struct{}
+
+// WithManagedQoSPolicy returns a copy of the context that tells a driver's Destroy which QoS policy
+// Trident manages for the volume, so that the policy may be deleted once no volumes use it.
+func WithManagedQoSPolicy(ctx context.Context, policy string) context.Context {
+	return context.WithValue(ctx, managedQoSPolicyKey{}, policy)
+}
+
+// ManagedQoSPolicyFromContext returns the QoS policy carried by the context, or an empty string if
+// Trident doesn't manage a policy for the volume.
+func ManagedQoSPolicyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	policy, _ := ctx.Value(managedQoSPolicyKey{}).(string)
+	return policy
+}
@@ -0,0 +1,21 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManagedQoSPolicyContext(t *testing.T) {
+	if policy := ManagedQoSPolicyFromContext(context.Background()); policy != "" {
+		t.Errorf("Expected no policy in the context, got %s", policy)
+	}
+	if policy := ManagedQoSPolicyFromContext(nil); policy != "" {
+		t.Errorf("Expected no policy without a context, got %s", policy)
+	}
+	ctx := WithManagedQoSPolicy(context.Background(), "gold")
+	if policy := ManagedQoSPolicyFromContext(ctx); policy != "gold" {
+		t.Errorf("Expected gold, got %s", policy)
+	}
+}
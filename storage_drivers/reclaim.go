@@ -0,0 +1,49 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"context"
+	"fmt"
+)
+
+// What becomes of a volume on its storage when it is deleted from Trident.  By default it is
+// deleted, but a clone may instead be retained, and optionally taken offline, for an administrator
+// to deal with once Trident no longer manages it.
+const (
+	OnDeleteDelete  = "delete"
+	OnDeleteRetain  = "retain"
+	OnDeleteOffline = "offline"
+)
+
+// ValidateOnDelete returns an error if a volume's on-delete behavior is unknown.  An empty value
+// means the default of OnDeleteDelete.
+func ValidateOnDelete(onDelete string) error {
+	switch onDelete {
+	case "", OnDeleteDelete, OnDeleteRetain, OnDeleteOffline:
+		return nil
+	default:
+		return fmt.Errorf("invalid onDelete value %s; must be one of %s, %s or %s", onDelete,
+			OnDeleteDelete, OnDeleteRetain, OnDeleteOffline)
+	}
+}
+
+type onDeleteKey struct{}
+
+// WithOnDelete returns a copy of the context that tells a driver's Destroy what to do with the
+// volume on its storage.
+func WithOnDelete(ctx context.Context, onDelete string) context.Context {
+	return context.WithValue(ctx, onDeleteKey{}, onDelete)
+}
+
+// OnDeleteFromContext returns the on-delete behavior carried by the context, or OnDeleteDelete if
+// there is none.
+func OnDeleteFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return OnDeleteDelete
+	}
+	if onDelete, _ := ctx.Value(onDeleteKey{}).(string); onDelete != "" {
+		return onDelete
+	}
+	return OnDeleteDelete
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOnDeleteContext(t *testing.T) {
+	if onDelete := OnDeleteFromContext(context.Background()); onDelete != OnDeleteDelete {
+		t.Errorf("Expected %s without a behavior in the context, got %s", OnDeleteDelete, onDelete)
+	}
+	if onDelete := OnDeleteFromContext(nil); onDelete != OnDeleteDelete {
+		t.Errorf("Expected %s without a context, got %s", OnDeleteDelete, onDelete)
+	}
+	if onDelete := OnDeleteFromContext(WithOnDelete(context.Background(), "")); onDelete != OnDeleteDelete {
+		t.Errorf("Expected %s for an empty behavior, got %s", OnDeleteDelete, onDelete)
+	}
+	ctx := WithOnDelete(context.Background(), OnDeleteOffline)
+	if onDelete := OnDeleteFromContext(ctx); onDelete != OnDeleteOffline {
+		t.Errorf("Expected %s, got %s", OnDeleteOffline, onDelete)
+	}
+}
+
+func TestValidateOnDelete(t *testing.T) {
+	for _, onDelete := range []string{"", OnDeleteDelete, OnDeleteRetain, OnDeleteOffline} {
+		if err := ValidateOnDelete(onDelete); err != nil {
+			t.Errorf("Unexpected error for %q: %v", onDelete, err)
+		}
+	}
+	if err := ValidateOnDelete("archive"); err == nil {
+		t.Error("Expected an error for an unknown behavior")
+	}
+}
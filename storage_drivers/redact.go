@@ -0,0 +1,111 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in configs and volumes returned to clients.
+const RedactedValue = "<REDACTED>"
+
+// Values of the sensitive tag, which marks the fields of configs and volumes that must not be
+// returned to clients.  A secret field is replaced entirely, while a URL field keeps its address
+// but loses the credentials embedded in it.
+const (
+	sensitiveTag    = "sensitive"
+	sensitiveSecret = "true"
+	sensitiveURL    = "url"
+)
+
+// RedactedConfig returns a copy of a driver config, which must be a pointer to a struct, with its
+// sensitive fields redacted.  The copy is made by a JSON round trip, so it holds only the fields
+// that are serialized, and it shares nothing with the original.
+func RedactedConfig(config interface{}) (interface{}, error) {
+
+	configType := reflect.TypeOf(config)
+	if configType == nil || configType.Kind() != reflect.Ptr || configType.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot redact config of type %T", config)
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not copy config; %v", err)
+	}
+	configCopy := reflect.New(configType.Elem()).Interface()
+	if err = json.Unmarshal(configJSON, configCopy); err != nil {
+		return nil, fmt.Errorf("could not copy config; %v", err)
+	}
+
+	RedactSensitiveFields(configCopy)
+	return configCopy, nil
+}
+
+// RedactSensitiveFields redacts, in place, the sensitive string fields of the struct to which obj
+// points, including those of its embedded and nested structs.  Empty fields are left empty, so a
+// client can still tell whether a value was set.  Pointers are followed, so anything shared with
+// obj must be copied first.
+func RedactSensitiveFields(obj interface{}) {
+	redactValue(reflect.ValueOf(obj))
+}
+
+func redactValue(v reflect.Value) {
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		structField := v.Type().Field(i)
+		if structField.PkgPath != "" && !structField.Anonymous {
+			continue // unexported
+		}
+
+		switch structField.Tag.Get(sensitiveTag) {
+		case sensitiveSecret:
+			if field.Kind() == reflect.String && field.String() != "" {
+				field.SetString(RedactedValue)
+			}
+		case sensitiveURL:
+			if field.Kind() == reflect.String {
+				field.SetString(RedactURL(field.String()))
+			}
+		default:
+			redactValue(field)
+		}
+	}
+}
+
+// RedactURL returns a URL without the password embedded in it.  A string that can't be parsed
+// as a URL might still hold credentials, so it is redacted entirely.
+func RedactURL(rawURL string) string {
+
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RedactedValue
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return rawURL
+	}
+
+	// The username is escaped, so the first @ ends it
+	u.User = url.User(u.User.Username())
+	return strings.Replace(u.String(), "@", ":"+RedactedValue+"@", 1)
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// sampleConfigs holds the config struct of each driver for which a sample config can be generated.
+var sampleConfigs = map[string]interface{}{
+	EseriesIscsiStorageDriverName:  ESeriesStorageDriverConfig{},
+	OntapNASStorageDriverName:      OntapStorageDriverConfig{},
+	OntapNASQtreeStorageDriverName: OntapStorageDriverConfig{},
+	OntapSANStorageDriverName:      OntapStorageDriverConfig{},
+	SolidfireSANStorageDriverName:  SolidfireStorageDriverConfig{},
+	GCPNFSStorageDriverName:        GCPNFSStorageDriverConfig{},
+	GenericNFSStorageDriverName:    GenericNFSStorageDriverConfig{},
+	FakeStorageDriverName:          FakeStorageDriverConfig{},
+}
+
+// sampleField is a setting in a sample config, holding either a JSON value or nested settings.
+type sampleField struct {
+	name    string
+	comment string
+	value   string
+	fields  []sampleField
+}
+
+// SampleDriverNames returns the names of the drivers for which sample configs can be generated.
+func SampleDriverNames() []string {
+	names := make([]string, 0, len(sampleConfigs))
+	for name := range sampleConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SampleConfig returns a sample backend config for a driver, with every setting the driver supports
+// set to its default value and preceded by a comment describing it.  The settings, descriptions and
+// defaults are read from the tags of the driver's config struct.  The comments are full lines starting
+// with //, which StripConfigComments removes to leave valid JSON.
+func SampleConfig(driverName string) (string, error) {
+
+	config, ok := sampleConfigs[driverName]
+	if !ok {
+		return "", fmt.Errorf("unknown storage driver: %s; expected one of %s",
+			driverName, strings.Join(SampleDriverNames(), ", "))
+	}
+
+	var buffer bytes.Buffer
+	writeSampleObject(&buffer, sampleFields(reflect.TypeOf(config), driverName), "")
+	buffer.WriteString("\n")
+	return buffer.String(), nil
+}
+
+// StripConfigComments removes the full-line // comments that SampleConfig adds to a config.
+func StripConfigComments(config []byte) []byte {
+
+	lines := bytes.Split(config, []byte("\n"))
+	kept := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("//")) {
+			kept = append(kept, line)
+		}
+	}
+	return bytes.Join(kept, []byte("\n"))
+}
+
+// sampleFields returns the settings of a config struct that apply to a driver.  Embedded structs
+// without a JSON name are flattened into their parent, as encoding/json does.
+func sampleFields(t reflect.Type, driverName string) []sampleField {
+
+	fields := make([]sampleField, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, sampleFields(fieldType, driverName)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		desc := field.Tag.Get("desc")
+		if name == "-" || desc == "-" || !sampleFieldApplies(field, driverName) {
+			continue
+		}
+
+		sample := sampleField{name: name, comment: desc}
+
+		if field.Anonymous && fieldType.Kind() == reflect.Struct {
+			sample.fields = sampleFields(fieldType, driverName)
+		} else if name == "version" {
+			sample.value = fmt.Sprint(ConfigVersion)
+		} else if name == "storageDriverName" {
+			sample.value = sampleJSONString(driverName)
+		} else if defaultValue, ok := sampleDefault(field, driverName); ok {
+			sample.value = sampleDefaultValue(fieldType, defaultValue)
+			sample.comment = fmt.Sprintf("%s (default %s)", desc, sample.value)
+		} else {
+			sample.value = sampleZeroValue(fieldType)
+		}
+
+		fields = append(fields, sample)
+	}
+
+	return fields
+}
+
+// sampleFieldApplies checks whether a setting is limited to other drivers by its drivers tag.
+func sampleFieldApplies(field reflect.StructField, driverName string) bool {
+
+	drivers, ok := field.Tag.Lookup("drivers")
+	if !ok {
+		return true
+	}
+	for _, driver := range strings.Split(drivers, ",") {
+		if driver == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleDefault returns a setting's default for a driver, preferring a default.<driver> tag.
+func sampleDefault(field reflect.StructField, driverName string) (string, bool) {
+
+	if defaultValue, ok := field.Tag.Lookup("default." + driverName); ok {
+		return defaultValue, true
+	}
+	return field.Tag.Lookup("default")
+}
+
+// sampleDefaultValue converts a default from a tag to JSON.  Defaults of string settings, and any
+// defaults that aren't already valid JSON, are quoted.
+func sampleDefaultValue(t reflect.Type, defaultValue string) string {
+
+	if t.Kind() != reflect.String && json.Valid([]byte(defaultValue)) {
+		return defaultValue
+	}
+	return sampleJSONString(defaultValue)
+}
+
+// sampleZeroValue returns the JSON for a setting without a default.
+func sampleZeroValue(t reflect.Type) string {
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "[]"
+	case reflect.Map, reflect.Struct:
+		return "{}"
+	}
+	zero, _ := json.Marshal(reflect.Zero(t).Interface())
+	return string(zero)
+}
+
+func sampleJSONString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// writeSampleObject writes settings as an indented JSON object with a comment above each one.
+func writeSampleObject(buffer *bytes.Buffer, fields []sampleField, indent string) {
+
+	buffer.WriteString("{\n")
+
+	for i, field := range fields {
+
+		if field.comment != "" {
+			fmt.Fprintf(buffer, "%s  // %s\n", indent, field.comment)
+		}
+		fmt.Fprintf(buffer, "%s  %s: ", indent, sampleJSONString(field.name))
+
+		if field.fields != nil {
+			writeSampleObject(buffer, field.fields, indent+"  ")
+		} else {
+			buffer.WriteString(field.value)
+		}
+
+		if i < len(fields)-1 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n")
+	}
+
+	buffer.WriteString(indent + "}")
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSampleConfig(t *testing.T) {
+	for _, driverName := range SampleDriverNames() {
+
+		sample, err := SampleConfig(driverName)
+		if err != nil {
+			t.Fatalf("Could not generate sample config for %s: %v", driverName, err)
+		}
+
+		configJSON := StripConfigComments([]byte(sample))
+		commonConfig, err := ValidateCommonSettings(string(configJSON))
+		if err != nil {
+			t.Errorf("Sample config for %s is invalid: %v\n%s", driverName, err, sample)
+			continue
+		}
+		if commonConfig.StorageDriverName != driverName {
+			t.Errorf("Expected driver %s, got %s", driverName, commonConfig.StorageDriverName)
+		}
+
+		config := reflect.New(reflect.TypeOf(sampleConfigs[driverName])).Interface()
+		if err = json.Unmarshal(configJSON, config); err != nil {
+			t.Errorf("Could not parse sample config for %s: %v", driverName, err)
+		}
+	}
+
+	if _, err := SampleConfig("unknown"); err == nil {
+		t.Error("Expected an error for an unknown driver.")
+	}
+}
+
+func TestSampleConfigDriverSettings(t *testing.T) {
+	nas, _ := SampleConfig(OntapNASStorageDriverName)
+	san, _ := SampleConfig(OntapSANStorageDriverName)
+	solidfire, _ := SampleConfig(SolidfireSANStorageDriverName)
+
+	if strings.Contains(nas, `"igroupName"`) || !strings.Contains(san, `"igroupName": "trident"`) {
+		t.Error("Expected igroupName in the ontap-san sample only.")
+	}
+	if !strings.Contains(nas, `"unixPermissions": "---rwxrwxrwx"`) || strings.Contains(san, `"unixPermissions"`) {
+		t.Error("Expected unixPermissions in the ontap-nas sample only.")
+	}
+	if !strings.Contains(solidfire, `"storagePrefix": ""`) || !strings.Contains(solidfire, `"TenantName": ""`) {
+		t.Errorf("Unexpected solidfire-san sample:\n%s", solidfire)
+	}
+	if strings.Contains(nas, `"debug"`) || strings.Contains(nas, `"usageHeartbeat"`) {
+		t.Error("Expected unsupported settings to be left out of samples.")
+	}
+}
+
+// TestSampleConfigDescriptions ensures that every setting a config accepts is described for samples.
+func TestSampleConfigDescriptions(t *testing.T) {
+
+	var checkType func(t *testing.T, configType reflect.Type)
+	checkType = func(t *testing.T, configType reflect.Type) {
+		for i := 0; i < configType.NumField(); i++ {
+			field := configType.Field(i)
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if field.Tag.Get("json") == "-" {
+				continue
+			}
+			if field.Anonymous && field.Tag.Get("json") == "" && fieldType.Kind() == reflect.Struct {
+				checkType(t, fieldType)
+				continue
+			}
+			if _, ok := field.Tag.Lookup("desc"); !ok {
+				t.Errorf("Setting %s.%s has no desc tag.", configType.Name(), field.Name)
+			}
+			if field.Anonymous && fieldType.Kind() == reflect.Struct {
+				checkType(t, fieldType)
+			}
+		}
+	}
+
+	for _, config := range sampleConfigs {
+		checkType(t, reflect.TypeOf(config))
+	}
+}
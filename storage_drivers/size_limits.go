@@ -0,0 +1,96 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netapp/trident/utils"
+)
+
+// VolumeSizeLimits holds the size limits set in a backend's config.  A zero value means the
+// setting wasn't specified, so only the driver's own limits apply.
+type VolumeSizeLimits struct {
+	Minimum uint64 // smallest volume, in bytes, the backend will create
+	Maximum uint64 // largest volume, in bytes, the backend will create
+}
+
+// parseVolumeSizeLimits validates the size limits in a backend's config and saves the results.
+func parseVolumeSizeLimits(config *CommonStorageDriverConfig) error {
+	var err error
+	limits := VolumeSizeLimits{}
+	if limits.Minimum, err = parseSizeSetting("minimumVolumeSize", config.MinimumVolumeSize); err != nil {
+		return err
+	}
+	if limits.Maximum, err = parseSizeSetting("limitVolumeSize", config.LimitVolumeSize); err != nil {
+		return err
+	}
+	if limits.Maximum != 0 && limits.Minimum > limits.Maximum {
+		return fmt.Errorf("minimumVolumeSize (%s) is larger than limitVolumeSize (%s)",
+			config.MinimumVolumeSize, config.LimitVolumeSize)
+	}
+	config.SizeLimits = limits
+	return nil
+}
+
+// parseVolumeSizeRounding validates the size rounding unit in a backend's config and saves it in
+// bytes.  No rounding is done by default, leaving sizes to the driver's own granularity.
+func parseVolumeSizeRounding(config *CommonStorageDriverConfig) error {
+	switch strings.ToLower(config.VolumeSizeRounding) {
+	case "", "none":
+		config.SizeRounding = 0
+	case "mib":
+		config.SizeRounding = 1048576
+	case "gib":
+		config.SizeRounding = 1073741824
+	default:
+		return fmt.Errorf("invalid value for volumeSizeRounding: %s; it must be MiB, GiB or none",
+			config.VolumeSizeRounding)
+	}
+	return nil
+}
+
+// RoundVolumeSize rounds a volume size up to the unit set in the backend's config.
+func RoundVolumeSize(sizeBytes uint64, config *CommonStorageDriverConfig) uint64 {
+	if config == nil {
+		return sizeBytes
+	}
+	return utils.RoundUpSize(sizeBytes, config.SizeRounding)
+}
+
+// parseSizeSetting converts a size setting, with or without units, to bytes.  An empty setting
+// yields zero.
+func parseSizeSetting(setting, value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	sizeString, err := utils.ConvertSizeToBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", setting, err)
+	}
+	size, err := strconv.ParseUint(sizeString, 10, 64)
+	if err != nil || size == 0 {
+		return 0, fmt.Errorf("invalid value for %s: %s; it must be a positive size", setting, value)
+	}
+	return size, nil
+}
+
+// CheckVolumeSizeLimits returns a FatalError if a requested volume size is outside the limits
+// set in the backend's config.
+func CheckVolumeSizeLimits(sizeBytes uint64, config *CommonStorageDriverConfig) error {
+	if config == nil {
+		return nil
+	}
+	limits := config.SizeLimits
+	if limits.Minimum != 0 && sizeBytes < limits.Minimum {
+		return NewFatalError(fmt.Sprintf("requested volume size (%d bytes) is smaller than the "+
+			"backend's minimumVolumeSize (%d bytes)", sizeBytes, limits.Minimum))
+	}
+	if limits.Maximum != 0 && sizeBytes > limits.Maximum {
+		return NewFatalError(fmt.Sprintf("requested volume size (%d bytes) is larger than the "+
+			"backend's limitVolumeSize (%d bytes)", sizeBytes, limits.Maximum))
+	}
+	return nil
+}
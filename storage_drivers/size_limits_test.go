@@ -0,0 +1,80 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"testing"
+)
+
+func TestValidateCommonSettingsSizeLimits(t *testing.T) {
+	config, err := ValidateCommonSettings(`{"version": 1, "storageDriverName": "fake",
+		"minimumVolumeSize": "1Gi", "limitVolumeSize": "10Gi"}`)
+	if err != nil {
+		t.Fatal("Unable to validate config: ", err)
+	}
+	expected := VolumeSizeLimits{Minimum: 1073741824, Maximum: 10737418240}
+	if config.SizeLimits != expected {
+		t.Errorf("Mismatch between size limits.  Expected %v, got %v", expected, config.SizeLimits)
+	}
+
+	for _, configJSON := range []string{
+		`{"version": 1, "storageDriverName": "fake", "limitVolumeSize": "lots"}`,
+		`{"version": 1, "storageDriverName": "fake", "minimumVolumeSize": "0"}`,
+		`{"version": 1, "storageDriverName": "fake", "minimumVolumeSize": "2Ti", "limitVolumeSize": "1Ti"}`,
+	} {
+		if _, err = ValidateCommonSettings(configJSON); err == nil {
+			t.Errorf("Expected an error for config %s.", configJSON)
+		}
+	}
+}
+
+func TestCheckVolumeSizeLimits(t *testing.T) {
+	config := &CommonStorageDriverConfig{SizeLimits: VolumeSizeLimits{Minimum: 1000, Maximum: 5000}}
+	for _, test := range []struct {
+		size  uint64
+		valid bool
+	}{
+		{size: 999, valid: false},
+		{size: 1000, valid: true},
+		{size: 5000, valid: true},
+		{size: 5001, valid: false},
+	} {
+		err := CheckVolumeSizeLimits(test.size, config)
+		if test.valid && err != nil {
+			t.Errorf("Unexpected error for size %d: %v", test.size, err)
+		} else if !test.valid && !IsFatalError(err) {
+			t.Errorf("Expected a fatal error for size %d, got %v", test.size, err)
+		}
+	}
+
+	if err := CheckVolumeSizeLimits(1<<50, &CommonStorageDriverConfig{}); err != nil {
+		t.Errorf("Unexpected error without limits: %v", err)
+	}
+}
+
+func TestValidateCommonSettingsSizeRounding(t *testing.T) {
+	for rounding, expected := range map[string]uint64{
+		"":     0,
+		"none": 0,
+		"MiB":  1048576,
+		"GiB":  1073741824,
+	} {
+		config, err := ValidateCommonSettings(`{"version": 1, "storageDriverName": "fake",
+			"volumeSizeRounding": "` + rounding + `"}`)
+		if err != nil {
+			t.Errorf("Unable to validate config with rounding %q: %v", rounding, err)
+		} else if config.SizeRounding != expected {
+			t.Errorf("Expected rounding %q to be %d bytes, got %d", rounding, expected, config.SizeRounding)
+		}
+	}
+
+	if _, err := ValidateCommonSettings(`{"version": 1, "storageDriverName": "fake",
+		"volumeSizeRounding": "KiB"}`); err == nil {
+		t.Error("Expected an error for an unsupported volumeSizeRounding.")
+	}
+
+	config := &CommonStorageDriverConfig{SizeRounding: 1073741824}
+	if size := RoundVolumeSize(1000000000, config); size != 1073741824 {
+		t.Errorf("Expected 1000000000 bytes to round up to 1073741824, got %d", size)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Get the limits the cluster places on API requests, such as the range of QoS values
+func (c *Client) GetLimits() (*Limits, error) {
+	var (
+		limitsReq    struct{}
+		limitsResult GetLimitsResult
+	)
+
+	response, err := c.Request("GetLimits", limitsReq, NewReqID())
+	if err != nil {
+		log.Errorf("Error detected in GetLimits API response: %+v", err)
+		return nil, errors.New("device API error")
+	}
+
+	if err := json.Unmarshal([]byte(response), &limitsResult); err != nil {
+		log.Errorf("Error detected unmarshalling json response: %+v", err)
+		return nil, errors.New("json decode error")
+	}
+	return &limitsResult.Result, err
+}
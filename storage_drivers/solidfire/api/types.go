@@ -330,6 +330,21 @@ type ClusterHardwareInfo struct {
 	Nodes  interface{} `json:"nodes"`
 }
 
+type GetLimitsResult struct {
+	ID     int    `json:"id"`
+	Result Limits `json:"result"`
+}
+
+// Limits holds the subset of the cluster's limits that Trident checks requests against
+type Limits struct {
+	VolumeMinIOPSMin   int64 `json:"volumeMinIOPSMin"`
+	VolumeMinIOPSMax   int64 `json:"volumeMinIOPSMax"`
+	VolumeMaxIOPSMin   int64 `json:"volumeMaxIOPSMin"`
+	VolumeMaxIOPSMax   int64 `json:"volumeMaxIOPSMax"`
+	VolumeBurstIOPSMin int64 `json:"volumeBurstIOPSMin"`
+	VolumeBurstIOPSMax int64 `json:"volumeBurstIOPSMax"`
+}
+
 type ModifyVolumeRequest struct {
 	VolumeID   int64       `json:"volumeID"`
 	AccountID  int64       `json:"accountID,omitempty"`
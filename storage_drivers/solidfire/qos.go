@@ -0,0 +1,178 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package solidfire
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/solidfire/api"
+)
+
+// defaultQoSLimits are Element's QoS limits, used if the cluster doesn't report its own.
+var defaultQoSLimits = api.Limits{
+	VolumeMinIOPSMin:   50,
+	VolumeMinIOPSMax:   15000,
+	VolumeMaxIOPSMin:   100,
+	VolumeMaxIOPSMax:   200000,
+	VolumeBurstIOPSMin: 100,
+	VolumeBurstIOPSMax: 200000,
+}
+
+// getQoSLimits returns the range of QoS values the cluster accepts.
+func (d *SANStorageDriver) getQoSLimits() api.Limits {
+	limits, err := d.Client.GetLimits()
+	if err != nil || limits.VolumeMaxIOPSMax == 0 {
+		log.Warningf("Could not read QoS limits from the cluster, using Element defaults. %v", err)
+		return defaultQoSLimits
+	}
+	log.WithField("limits", fmt.Sprintf("%+v", *limits)).Debug("Read QoS limits from the cluster.")
+	return *limits
+}
+
+// validateQoS checks that QoS settings are consistent and within the cluster's limits.  Values
+// left at zero are set by the cluster, so aren't checked.
+func validateQoS(qos api.QoS, limits api.Limits) error {
+	for _, check := range []struct {
+		name          string
+		value, lo, hi int64
+	}{
+		{"minIOPS", qos.MinIOPS, limits.VolumeMinIOPSMin, limits.VolumeMinIOPSMax},
+		{"maxIOPS", qos.MaxIOPS, limits.VolumeMaxIOPSMin, limits.VolumeMaxIOPSMax},
+		{"burstIOPS", qos.BurstIOPS, limits.VolumeBurstIOPSMin, limits.VolumeBurstIOPSMax},
+	} {
+		if check.value != 0 && (check.value < check.lo || check.value > check.hi) {
+			return drivers.NewFatalError(fmt.Sprintf("%s of %d is outside the cluster's limits of %d to %d",
+				check.name, check.value, check.lo, check.hi))
+		}
+	}
+	if qos.MinIOPS != 0 && qos.MaxIOPS != 0 && qos.MinIOPS > qos.MaxIOPS {
+		return drivers.NewFatalError(fmt.Sprintf("minIOPS of %d exceeds maxIOPS of %d",
+			qos.MinIOPS, qos.MaxIOPS))
+	}
+	if qos.MaxIOPS != 0 && qos.BurstIOPS != 0 && qos.MaxIOPS > qos.BurstIOPS {
+		return drivers.NewFatalError(fmt.Sprintf("maxIOPS of %d exceeds burstIOPS of %d",
+			qos.MaxIOPS, qos.BurstIOPS))
+	}
+	return nil
+}
+
+// formatQoS returns QoS settings in the form accepted by the qos volume option.
+func formatQoS(qos api.QoS) string {
+	return fmt.Sprintf("%d,%d,%d", qos.MinIOPS, qos.MaxIOPS, qos.BurstIOPS)
+}
+
+// getTierQoS returns the QoS settings of a named QoS tier, including the default tier used when
+// the config defines none.
+func (d *SANStorageDriver) getTierQoS(tier string) (api.QoS, error) {
+	if d.Client.VolumeTypes == nil || len(*d.Client.VolumeTypes) == 0 {
+		if tier == sfDefaultVolTypeName {
+			return api.QoS{MinIOPS: sfDefaultMinIOPS, MaxIOPS: sfDefaultMaxIOPS}, nil
+		}
+		return api.QoS{}, drivers.NewFatalError(fmt.Sprintf("QoS type %s not found", tier))
+	}
+	qos, err := parseType(*d.Client.VolumeTypes, tier)
+	if err != nil {
+		return qos, drivers.NewFatalError(fmt.Sprintf("QoS type %s not found", tier))
+	}
+	return qos, nil
+}
+
+// getQoSFromRequests returns QoS settings built from the minIOPS, maxIOPS and burstIOPS
+// attributes of a storage class, starting from those of the pool's QoS tier so that a storage
+// class need only set the values it wants to change.  It returns false if the storage class
+// doesn't set any of them.
+func (d *SANStorageDriver) getQoSFromRequests(
+	pool *storage.Pool, requests map[string]sa.Request,
+) (api.QoS, bool, error) {
+
+	var qos api.QoS
+	explicit := false
+	for _, attribute := range []string{sa.MinIOPS, sa.MaxIOPS, sa.BurstIOPS} {
+		if _, ok := requests[attribute]; ok {
+			explicit = true
+		}
+	}
+	if !explicit {
+		return qos, false, nil
+	}
+
+	if pool != nil {
+		if tierQoS, err := d.getTierQoS(pool.Name); err == nil {
+			qos = tierQoS
+		}
+	}
+	for attribute, value := range map[string]*int64{
+		sa.MinIOPS:   &qos.MinIOPS,
+		sa.MaxIOPS:   &qos.MaxIOPS,
+		sa.BurstIOPS: &qos.BurstIOPS,
+	} {
+		request, ok := requests[attribute]
+		if !ok {
+			continue
+		}
+		iops, ok := request.Value().(int)
+		if !ok {
+			return qos, true, drivers.NewFatalError(fmt.Sprintf("expected an integer for %s", attribute))
+		}
+		*value = int64(iops)
+	}
+
+	if err := validateQoS(qos, d.QoSLimits); err != nil {
+		return qos, true, err
+	}
+	return qos, true, nil
+}
+
+// ModifyVolumeQoS changes the QoS of an existing volume to that of the QoS tier named by the
+// config's QoSType or, if no tier is named, to the IOPS in its QoS field.
+func (d *SANStorageDriver) ModifyVolumeQoS(volConfig *storage.VolumeConfig) error {
+
+	name := volConfig.InternalName
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":  "ModifyVolumeQoS",
+			"Type":    "SANStorageDriver",
+			"name":    name,
+			"qos":     volConfig.QoS,
+			"qosType": volConfig.QoSType,
+		}
+		log.WithFields(fields).Debug(">>>> ModifyVolumeQoS")
+		defer log.WithFields(fields).Debug("<<<< ModifyVolumeQoS")
+	}
+
+	var qos api.QoS
+	var err error
+	if volConfig.QoSType != "" {
+		if qos, err = d.getTierQoS(volConfig.QoSType); err != nil {
+			return err
+		}
+	} else if qos, err = parseQOS(volConfig.QoS); err != nil {
+		return drivers.NewFatalError(err.Error())
+	}
+	if err = validateQoS(qos, d.QoSLimits); err != nil {
+		return err
+	}
+
+	v, err := d.GetVolume(name)
+	if err != nil || v.VolumeID == 0 {
+		return fmt.Errorf("could not find volume %s", name)
+	}
+
+	modifyReq := api.ModifyVolumeRequest{VolumeID: v.VolumeID, Qos: qos}
+	if err = d.Client.ModifyVolume(&modifyReq); err != nil {
+		return fmt.Errorf("could not change QoS of volume %s: %v", name, err)
+	}
+
+	log.WithFields(log.Fields{
+		"volume": name,
+		"qos":    formatQoS(qos),
+	}).Debug("Changed volume QoS.")
+
+	return nil
+}
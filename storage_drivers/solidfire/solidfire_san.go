@@ -3,6 +3,7 @@
 package solidfire
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +38,7 @@ type SANStorageDriver struct {
 	LegacyNamePrefix string
 	InitiatorIFace   string
 	Telemetry        *Telemetry
+	QoSLimits        api.Limits
 }
 
 type StorageDriverConfigExternal struct {
@@ -57,10 +59,15 @@ type Telemetry struct {
 
 func parseQOS(qosOpt string) (qos api.QoS, err error) {
 	iops := strings.Split(qosOpt, ",")
-	qos.MinIOPS, err = strconv.ParseInt(iops[0], 10, 64)
-	qos.MaxIOPS, err = strconv.ParseInt(iops[1], 10, 64)
-	qos.BurstIOPS, err = strconv.ParseInt(iops[2], 10, 64)
-	return qos, err
+	if len(iops) != 3 {
+		return qos, fmt.Errorf("invalid qos value %s; expected minIOPS,maxIOPS,burstIOPS", qosOpt)
+	}
+	for i, value := range []*int64{&qos.MinIOPS, &qos.MaxIOPS, &qos.BurstIOPS} {
+		if *value, err = strconv.ParseInt(strings.TrimSpace(iops[i]), 10, 64); err != nil {
+			return qos, fmt.Errorf("invalid qos value %s; expected minIOPS,maxIOPS,burstIOPS", qosOpt)
+		}
+	}
+	return qos, nil
 }
 
 func parseType(vTypes []api.VolType, typeName string) (qos api.QoS, err error) {
@@ -85,6 +92,16 @@ func (d SANStorageDriver) Name() string {
 	return drivers.SolidfireSANStorageDriverName
 }
 
+func (d *SANStorageDriver) GetDebugTraceFlags() map[string]bool {
+	return d.Config.DebugTraceFlags
+}
+
+func (d *SANStorageDriver) SetDebugTraceFlags(flags map[string]bool) {
+	d.Config.DebugTraceFlags = flags
+	d.Client.DebugTraceFlags = flags
+	d.Client.Config.DebugTraceFlags = flags
+}
+
 // Initialize from the provided config
 func (d *SANStorageDriver) Initialize(
 	context trident.DriverContext, configJSON string, commonConfig *drivers.CommonStorageDriverConfig,
@@ -215,6 +232,16 @@ func (d *SANStorageDriver) Initialize(
 		return errors.New("error encountered validating SolidFire driver on init")
 	}
 
+	// Make sure the QoS types are ones the cluster will accept
+	d.QoSLimits = d.getQoSLimits()
+	if config.Types != nil {
+		for _, volType := range *config.Types {
+			if err = validateQoS(volType.QOS, d.QoSLimits); err != nil {
+				return fmt.Errorf("invalid QoS type %s: %v", volType.Type, err)
+			}
+		}
+	}
+
 	// log cluster node serial numbers asynchronously since the API can take a long time
 	go d.getNodeSerialNumbers(config.CommonStorageDriverConfig)
 
@@ -284,6 +311,10 @@ func (d *SANStorageDriver) populateConfigurationDefaults(config *drivers.Solidfi
 			return fmt.Errorf("invalid config value for default volume size: %v", err)
 		}
 	}
+	if err := drivers.ValidateFilesystemPermissions(
+		config.DriverContext, config.FileSystemOwner, config.FileSystemMode); err != nil {
+		return err
+	}
 
 	if config.DriverContext == trident.ContextDocker {
 		if !config.UseCHAP {
@@ -338,8 +369,25 @@ func MakeSolidFireName(name string) string {
 	return strings.Replace(name, "_", "-", -1)
 }
 
+// GetVolumeSize returns the size of the volume that Create makes for a requested size.
+func (d *SANStorageDriver) GetVolumeSize(sizeBytes uint64) (uint64, error) {
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	sizeBytes = drivers.RoundVolumeSize(sizeBytes, d.Config.CommonStorageDriverConfig)
+	if sizeBytes < MinimumVolumeSizeBytes {
+		return 0, fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
+			sizeBytes, MinimumVolumeSizeBytes)
+	}
+	if err := drivers.CheckVolumeSizeLimits(sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+	return sizeBytes, nil
+}
+
 // Create a SolidFire volume
-func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string]string) error {
+func (d *SANStorageDriver) Create(ctx context.Context, name string, sizeBytes uint64, opts map[string]string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -367,13 +415,8 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		return errors.New("volume with requested name already exists")
 	}
 
-	if sizeBytes == 0 {
-		defaultSize, _ := utils.ConvertSizeToBytes(d.Config.Size)
-		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
-	}
-	if sizeBytes < MinimumVolumeSizeBytes {
-		return fmt.Errorf("requested volume size (%d bytes) is too small; the minimum volume size is %d bytes",
-			sizeBytes, MinimumVolumeSizeBytes)
+	if sizeBytes, err = d.GetVolumeSize(sizeBytes); err != nil {
+		return err
 	}
 
 	qosOpt := utils.GetV(opts, "qos", "")
@@ -395,6 +438,9 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 			return err
 		}
 	}
+	if err = validateQoS(qos, d.QoSLimits); err != nil {
+		return err
+	}
 
 	// Use whatever is set in the config as default
 	if d.Client.DefaultBlockSize == 4096 {
@@ -427,6 +473,19 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 		return fmt.Errorf("unsupported fileSystemType option: %s", fstype)
 	}
 
+	// Save the owner and mode to give the file system when Attach creates it
+	fsOwner := utils.GetV(opts, "fileSystemOwner", d.Config.FileSystemOwner)
+	fsMode := utils.GetV(opts, "fileSystemMode", d.Config.FileSystemMode)
+	if err := utils.ValidateFilesystemPermissions(fsOwner, fsMode); err != nil {
+		return err
+	}
+	if fsOwner != "" {
+		meta["fsowner"] = fsOwner
+	}
+	if fsMode != "" {
+		meta["fsmode"] = fsMode
+	}
+
 	req.Qos = qos
 	req.TotalSize = int64(sizeBytes)
 	req.AccountID = d.TenantID
@@ -440,7 +499,9 @@ func (d *SANStorageDriver) Create(name string, sizeBytes uint64, opts map[string
 }
 
 // Create a volume clone
-func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[string]string) error {
+func (d *SANStorageDriver) CreateClone(
+	ctx context.Context, name, source, snapshot string, opts map[string]string,
+) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -524,6 +585,9 @@ func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[s
 	}
 
 	if doModify {
+		if err = validateQoS(qos, d.QoSLimits); err != nil {
+			return err
+		}
 		modifyReq.Qos = qos
 		err = d.Client.ModifyVolume(&modifyReq)
 		if err != nil {
@@ -535,7 +599,7 @@ func (d *SANStorageDriver) CreateClone(name, source, snapshot string, opts map[s
 }
 
 // Destroy the requested docker volume
-func (d *SANStorageDriver) Destroy(name string) error {
+func (d *SANStorageDriver) Destroy(ctx context.Context, name string) error {
 
 	if d.Config.DebugTraceFlags["method"] {
 		fields := log.Fields{
@@ -591,6 +655,11 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		defer log.WithFields(fields).Debug("<<<< Attach")
 	}
 
+	// Ensure the host will assemble multipath devices from the volume's paths
+	if err := drivers.CheckHostMultipath(d.Config.CommonStorageDriverConfig, utils.SolidFireMultipathDevice); err != nil {
+		return err
+	}
+
 	v, err := d.GetVolume(name)
 	if err != nil {
 		log.Errorf("Unable to locate volume for mount operation: %+v", err)
@@ -605,14 +674,14 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		}
 
 		// Rescan and wait for the device(s) to appear
-		err = utils.RescanTargetAndWaitForDevice(0, v.Iqn)
+		err = utils.RescanTargetAndWaitForDevice(0, v.Iqn, d.Config.Timeouts.Mount)
 		if err != nil {
 			log.Errorf("could not find iSCSI device: %+v", err)
 			return err
 		}
 	}
 
-	err = utils.WaitForMultiPathDevice(0, v.Iqn)
+	err = utils.WaitForMultiPathDevice(0, v.Iqn, d.Config.Timeouts.Mount)
 	if err != nil {
 		return err
 	}
@@ -670,6 +739,16 @@ func (d *SANStorageDriver) Attach(name, mountpoint string, opts map[string]strin
 		return errors.New("unable to mount device")
 	}
 
+	// Give a new file system the owner and mode requested when the volume was created
+	if existingFstype == "" {
+		fsOwner, _ := attrs["fsowner"].(string)
+		fsMode, _ := attrs["fsmode"].(string)
+		if err := utils.SetFilesystemPermissions(mountpoint, fsOwner, fsMode); err != nil {
+			utils.Umount(mountpoint)
+			return fmt.Errorf("could not set permissions of volume %s: %v", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -769,6 +848,66 @@ func (d *SANStorageDriver) SnapshotList(name string) ([]storage.Snapshot, error)
 	return snapshots, nil
 }
 
+// CreateSnapshot takes a snapshot of the named volume
+func (d *SANStorageDriver) CreateSnapshot(snapshotName, volumeName string) (*storage.Snapshot, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "CreateSnapshot",
+			"Type":         "SANStorageDriver",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> CreateSnapshot")
+		defer log.WithFields(fields).Debug("<<<< CreateSnapshot")
+	}
+
+	v, err := d.GetVolume(volumeName)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s: %v", volumeName, err)
+	}
+
+	snap, err := d.Client.CreateSnapshot(&api.CreateSnapshotRequest{VolumeID: v.VolumeID, Name: snapshotName})
+	if err != nil {
+		return nil, fmt.Errorf("could not create snapshot %s of volume %s: %v", snapshotName, volumeName, err)
+	}
+
+	return &storage.Snapshot{Name: snap.Name, Created: snap.CreateTime}, nil
+}
+
+// DeleteSnapshot deletes a snapshot of the named volume
+func (d *SANStorageDriver) DeleteSnapshot(snapshotName, volumeName string) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "DeleteSnapshot",
+			"Type":         "SANStorageDriver",
+			"snapshotName": snapshotName,
+			"volumeName":   volumeName,
+		}
+		log.WithFields(fields).Debug(">>>> DeleteSnapshot")
+		defer log.WithFields(fields).Debug("<<<< DeleteSnapshot")
+	}
+
+	v, err := d.GetVolume(volumeName)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s: %v", volumeName, err)
+	}
+
+	snap, err := d.Client.GetSnapshot(0, v.VolumeID, snapshotName)
+	if err != nil {
+		return fmt.Errorf("could not find snapshot %s of volume %s: %v", snapshotName, volumeName, err)
+	}
+	if snap.SnapshotID == 0 {
+		return fmt.Errorf("snapshot %s of volume %s not found", snapshotName, volumeName)
+	}
+
+	if err = d.Client.DeleteSnapshot(snap.SnapshotID); err != nil {
+		return fmt.Errorf("could not delete snapshot %s of volume %s: %v", snapshotName, volumeName, err)
+	}
+	return nil
+}
+
 // Get tests for the existence of a volume
 func (d *SANStorageDriver) Get(name string) error {
 
@@ -846,6 +985,29 @@ func (d *SANStorageDriver) GetVolume(name string) (api.Volume, error) {
 }
 
 // GetStorageBackendSpecs retrieves storage backend capabilities
+// GetPoolCapacity reports the provisioned space of the cluster, which every QoS pool shares.  The cluster
+// refuses new volumes once the maximum provisioned space is reached, regardless of how much is physically used.
+func (d *SANStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	capacity, err := d.Client.GetClusterCapacity()
+	if err != nil {
+		return nil, fmt.Errorf("could not get cluster capacity: %v", err)
+	}
+
+	total := uint64(capacity.MaxProvisionedSpace)
+	used := uint64(capacity.ProvisionedSpace)
+	available := uint64(0)
+	if total > used {
+		available = total - used
+	}
+
+	return &storage.PoolCapacity{
+		TotalBytes:     total,
+		UsedBytes:      used,
+		AvailableBytes: available,
+	}, nil
+}
+
 func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 
 	backend.Name = "solidfire_" + strings.Split(d.Config.SVIP, ":")[0]
@@ -870,9 +1032,19 @@ func (d *SANStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) erro
 		pool.Attributes[sa.Media] = sa.NewStringOffer(sa.SSD)
 		pool.Attributes[sa.IOPS] = sa.NewIntOffer(int(volType.QOS.MinIOPS),
 			int(volType.QOS.MaxIOPS))
+		pool.Attributes[sa.QoSTier] = sa.NewStringOffer(volType.Type)
+		pool.Attributes[sa.MinIOPS] = sa.NewIntOffer(int(d.QoSLimits.VolumeMinIOPSMin),
+			int(d.QoSLimits.VolumeMinIOPSMax))
+		pool.Attributes[sa.MaxIOPS] = sa.NewIntOffer(int(d.QoSLimits.VolumeMaxIOPSMin),
+			int(d.QoSLimits.VolumeMaxIOPSMax))
+		pool.Attributes[sa.BurstIOPS] = sa.NewIntOffer(int(d.QoSLimits.VolumeBurstIOPSMin),
+			int(d.QoSLimits.VolumeBurstIOPSMax))
 		pool.Attributes[sa.Snapshots] = sa.NewBoolOffer(true)
 		pool.Attributes[sa.Clones] = sa.NewBoolOffer(true)
 		pool.Attributes[sa.Encryption] = sa.NewBoolOffer(false)
+		pool.Attributes[sa.Resize] = sa.NewBoolOffer(false)
+		pool.Attributes[sa.QoS] = sa.NewBoolOffer(true)
+		pool.Attributes[sa.Replication] = sa.NewBoolOffer(false)
 		pool.Attributes[sa.ProvisioningType] = sa.NewStringOffer("thin")
 		pool.Attributes[sa.BackendType] = sa.NewStringOffer(d.Name())
 		backend.AddStoragePool(pool)
@@ -934,6 +1106,36 @@ func (d *SANStorageDriver) CreateFollowup(volConfig *storage.VolumeConfig) error
 	return d.mapSolidfireLun(volConfig)
 }
 
+// SetVolumeReadOnly sets a volume's access to read-only, so that its initiators can't write to
+// it, or to read-write otherwise.
+func (d *SANStorageDriver) SetVolumeReadOnly(volConfig *storage.VolumeConfig) error {
+
+	name := volConfig.InternalName
+	v, err := d.GetVolume(name)
+	if err != nil || v.VolumeID == 0 {
+		return fmt.Errorf("could not find volume %s", name)
+	}
+
+	access := "readWrite"
+	if volConfig.ReadOnly {
+		access = "readOnly"
+	}
+	if v.Access == access {
+		return nil
+	}
+
+	modifyReq := api.ModifyVolumeRequest{VolumeID: v.VolumeID, Access: access}
+	if err = d.Client.ModifyVolume(&modifyReq); err != nil {
+		return fmt.Errorf("could not set access of volume %s to %s: %v", name, access, err)
+	}
+
+	log.WithFields(log.Fields{
+		"volume": name,
+		"access": access,
+	}).Debug("Changed volume access.")
+	return nil
+}
+
 func (d *SANStorageDriver) mapSolidfireLun(volConfig *storage.VolumeConfig) error {
 	// Add the newly created volume to the default VAG
 	name := volConfig.InternalName
@@ -946,6 +1148,7 @@ func (d *SANStorageDriver) mapSolidfireLun(volConfig *storage.VolumeConfig) erro
 	volConfig.AccessInfo.IscsiTargetPortal = d.Config.SVIP
 	volConfig.AccessInfo.IscsiTargetIQN = v.Iqn
 	volConfig.AccessInfo.IscsiLunNumber = 0
+	volConfig.AccessInfo.IscsiLunWWID = utils.NAAWWID(v.ScsiNAADeviceID)
 	volConfig.AccessInfo.IscsiInterface = d.Config.InitiatorIFace
 
 	if d.Config.UseCHAP {
@@ -984,6 +1187,7 @@ func (d *SANStorageDriver) mapSolidfireLun(volConfig *storage.VolumeConfig) erro
 		"volume_internal": volConfig.InternalName,
 		"targetIQN":       volConfig.AccessInfo.IscsiTargetIQN,
 		"lunNumber":       volConfig.AccessInfo.IscsiLunNumber,
+		"lunWWID":         volConfig.AccessInfo.IscsiLunWWID,
 		"interface":       volConfig.AccessInfo.IscsiInterface,
 		"VAGs":            volConfig.AccessInfo.IscsiVAGs,
 		"Username":        volConfig.AccessInfo.IscsiUsername,
@@ -1006,6 +1210,12 @@ func (d *SANStorageDriver) GetVolumeOpts(
 	if volConfig.FileSystem != "" {
 		opts["fileSystemType"] = volConfig.FileSystem
 	}
+	if volConfig.FileSystemOwner != "" {
+		opts["fileSystemOwner"] = volConfig.FileSystemOwner
+	}
+	if volConfig.FileSystemMode != "" {
+		opts["fileSystemMode"] = volConfig.FileSystemMode
+	}
 	if pool != nil {
 		opts["type"] = pool.Name
 	}
@@ -1029,6 +1239,19 @@ func (d *SANStorageDriver) GetVolumeOpts(
 		}
 	}
 
+	// IOPS set by the storage class override those of the pool's QoS tier, unless the volume
+	// has its own QoS settings
+	if volConfig.QoS == "" && volConfig.QoSType == "" {
+		qos, explicit, err := d.getQoSFromRequests(pool, requests)
+		if err != nil {
+			return nil, err
+		}
+		if explicit {
+			opts["qos"] = formatQoS(qos)
+			opts["type"] = ""
+		}
+	}
+
 	return opts, nil
 }
 
@@ -1162,9 +1385,17 @@ func (d *SANStorageDriver) getVolumeExternal(
 		BlockSize:       strconv.FormatInt(volumeAttrs.BlockSize, 10),
 		FileSystem:      "",
 	}
+	volumeConfig.AccessInfo.IscsiTargetIQN = volumeAttrs.Iqn
+
+	// Deleted volumes are kept until purged, so only active ones are online
+	state := volumeAttrs.Status
+	if state == "active" {
+		state = storage.VolumeStateOnline
+	}
 
 	return &storage.VolumeExternal{
 		Config: volumeConfig,
 		Pool:   drivers.UnsetPool,
+		State:  state,
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
 	drivers "github.com/netapp/trident/storage_drivers"
 	"github.com/netapp/trident/storage_drivers/solidfire/api"
 )
@@ -72,3 +74,75 @@ func TestGetExternalConfig(t *testing.T) {
 	}
 	t.Log("Main config endpoint:  ", driver.Config.EndPoint)
 }
+
+func TestParseQOS(t *testing.T) {
+	qos, err := parseQOS("1000, 2000,4000")
+	if err != nil {
+		t.Fatal("Unable to parse QoS: ", err)
+	}
+	expected := api.QoS{MinIOPS: 1000, MaxIOPS: 2000, BurstIOPS: 4000}
+	if qos != expected {
+		t.Errorf("Mismatch parsing QoS.  Expected %+v, got %+v", expected, qos)
+	}
+	for _, invalid := range []string{"", "1000,2000", "1000,lots,4000"} {
+		if _, err = parseQOS(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q.", invalid)
+		}
+	}
+}
+
+func TestValidateQoS(t *testing.T) {
+	for _, test := range []struct {
+		qos   api.QoS
+		valid bool
+	}{
+		{api.QoS{MinIOPS: 1000, MaxIOPS: 2000, BurstIOPS: 4000}, true},
+		{api.QoS{MinIOPS: 1000}, true},
+		{api.QoS{MinIOPS: 10}, false},
+		{api.QoS{MinIOPS: 1000, MaxIOPS: 500000}, false},
+		{api.QoS{MinIOPS: 3000, MaxIOPS: 2000}, false},
+		{api.QoS{MaxIOPS: 5000, BurstIOPS: 4000}, false},
+	} {
+		err := validateQoS(test.qos, defaultQoSLimits)
+		if test.valid && err != nil {
+			t.Errorf("Unexpected error validating %+v: %v", test.qos, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected an error validating %+v.", test.qos)
+		}
+	}
+}
+
+func TestGetQoSFromRequests(t *testing.T) {
+	driver := &SANStorageDriver{
+		Client: &api.Client{
+			VolumeTypes: &[]api.VolType{
+				{Type: "Gold", QOS: api.QoS{MinIOPS: 6000, MaxIOPS: 8000, BurstIOPS: 10000}},
+			},
+		},
+		QoSLimits: defaultQoSLimits,
+	}
+	pool := storage.NewStoragePool(nil, "Gold")
+
+	if _, explicit, _ := driver.getQoSFromRequests(pool, map[string]sa.Request{}); explicit {
+		t.Error("Expected no QoS without IOPS in the storage class.")
+	}
+
+	// Values not set by the storage class come from the pool's tier
+	qos, explicit, err := driver.getQoSFromRequests(pool, map[string]sa.Request{
+		sa.MaxIOPS:   sa.NewIntRequest(9000),
+		sa.BurstIOPS: sa.NewIntRequest(12000),
+	})
+	if err != nil || !explicit {
+		t.Fatalf("Expected explicit QoS, got %v, %v", explicit, err)
+	}
+	expected := api.QoS{MinIOPS: 6000, MaxIOPS: 9000, BurstIOPS: 12000}
+	if qos != expected {
+		t.Errorf("Mismatch between QoS.  Expected %+v, got %+v", expected, qos)
+	}
+
+	if _, _, err = driver.getQoSFromRequests(pool, map[string]sa.Request{
+		sa.MaxIOPS: sa.NewIntRequest(5000),
+	}); err == nil {
+		t.Error("Expected an error for maxIOPS below the tier's minIOPS.")
+	}
+}
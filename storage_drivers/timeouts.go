@@ -0,0 +1,52 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// OperationTimeouts holds the time limits set in a backend's config.  A zero value means the
+// setting wasn't specified, so the operation is limited only by its default, if any.
+type OperationTimeouts struct {
+	Create time.Duration // creating a volume; no default limit
+	Clone  time.Duration // cloning a volume, including waiting for the clone to appear
+	Delete time.Duration // deleting a volume; no default limit
+	Mount  time.Duration // waiting for a volume's devices to appear on the host
+}
+
+// parseOperationTimeouts validates the timeouts in a backend's config and saves the results.
+func parseOperationTimeouts(config *CommonStorageDriverConfig) error {
+	var err error
+	timeouts := OperationTimeouts{}
+	if timeouts.Create, err = ParseTimeout("createTimeout", config.CreateTimeout); err != nil {
+		return err
+	}
+	if timeouts.Clone, err = ParseTimeout("cloneTimeout", config.CloneTimeout); err != nil {
+		return err
+	}
+	if timeouts.Delete, err = ParseTimeout("deleteTimeout", config.DeleteTimeout); err != nil {
+		return err
+	}
+	if timeouts.Mount, err = ParseTimeout("mountTimeout", config.MountTimeout); err != nil {
+		return err
+	}
+	config.Timeouts = timeouts
+	return nil
+}
+
+// ParseTimeout converts a timeout setting, in seconds, to a duration.  An empty setting yields
+// zero, leaving the caller to apply its default.
+func ParseTimeout(setting, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || seconds == 0 {
+		return 0, fmt.Errorf("invalid value for %s: %s; it must be a positive number of seconds",
+			setting, value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
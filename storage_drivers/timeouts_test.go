@@ -0,0 +1,48 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package storagedrivers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	for _, test := range []struct {
+		value    string
+		expected time.Duration
+		valid    bool
+	}{
+		{value: "", expected: 0, valid: true},
+		{value: "45", expected: 45 * time.Second, valid: true},
+		{value: "0", valid: false},
+		{value: "-5", valid: false},
+		{value: "10m", valid: false},
+	} {
+		got, err := ParseTimeout("mountTimeout", test.value)
+		if test.valid && err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", test.value, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected an error parsing %q.", test.value)
+		} else if got != test.expected {
+			t.Errorf("Mismatch parsing %q.  Expected %v, got %v", test.value, test.expected, got)
+		}
+	}
+}
+
+func TestValidateCommonSettingsTimeouts(t *testing.T) {
+	config, err := ValidateCommonSettings(`{"version": 1, "storageDriverName": "fake",
+		"cloneTimeout": "600", "mountTimeout": "180"}`)
+	if err != nil {
+		t.Fatal("Unable to validate config: ", err)
+	}
+	expected := OperationTimeouts{Clone: 600 * time.Second, Mount: 180 * time.Second}
+	if config.Timeouts != expected {
+		t.Errorf("Mismatch between timeouts.  Expected %v, got %v", expected, config.Timeouts)
+	}
+
+	if _, err = ValidateCommonSettings(`{"version": 1, "storageDriverName": "fake",
+		"deleteTimeout": "soon"}`); err == nil {
+		t.Error("Expected an error for an invalid deleteTimeout.")
+	}
+}
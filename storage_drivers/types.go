@@ -5,29 +5,69 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	trident "github.com/netapp/trident/config"
 	"github.com/netapp/trident/storage/fake"
+	gcpapi "github.com/netapp/trident/storage_drivers/gcp/api"
 	sfapi "github.com/netapp/trident/storage_drivers/solidfire/api"
+	"github.com/netapp/trident/utils"
 )
 
-// CommonStorageDriverConfig holds settings in common across all StorageDrivers
+// CommonStorageDriverConfig holds settings in common across all StorageDrivers.  The desc and default
+// tags describe each setting in sample configs; a default.<driver> tag overrides the default for one
+// driver, and a drivers tag limits a setting to the listed drivers.
 type CommonStorageDriverConfig struct {
-	Version           int                   `json:"version"`
-	StorageDriverName string                `json:"storageDriverName"`
-	Debug             bool                  `json:"debug"`           // Unsupported!
-	DebugTraceFlags   map[string]bool       `json:"debugTraceFlags"` // Example: {"api":false, "method":true}
-	DisableDelete     bool                  `json:"disableDelete"`
-	StoragePrefixRaw  json.RawMessage       `json:"storagePrefix,string"`
+	Version           int                   `json:"version" desc:"Config file version, always 1"`
+	StorageDriverName string                `json:"storageDriverName" desc:"Name of the storage driver"`
+	BackendName       string                `json:"backendName" desc:"Name of the backend, instead of one generated from its storage system's address"`
+	Debug             bool                  `json:"debug" desc:"-"` // Unsupported!
+	DebugTraceFlags   map[string]bool       `json:"debugTraceFlags" desc:"Debug tracing to enable, e.g. {\"api\": true, \"method\": true}"`
+	DisableDelete     bool                  `json:"disableDelete" desc:"-"`
+	StoragePrefixRaw  json.RawMessage       `json:"storagePrefix,string" desc:"Prefix of volume names on the storage system" default:"trident_" default.gcp-cvs:"trident" default.solidfire-san:""`
 	StoragePrefix     *string               `json:"-"`
 	SerialNumbers     []string              `json:"-"`
 	DriverContext     trident.DriverContext `json:"-"`
+
+	// Placement preferences consulted when choosing a pool for a new volume
+	Priority      int                      `json:"priority" desc:"Backends with higher priority are chosen first for new volumes" default:"0"`
+	Weight        int                      `json:"weight" desc:"Relative share of new volumes among backends of the same priority" default:"0"`
+	PoolPlacement map[string]PoolPlacement `json:"poolPlacement" desc:"Priority and weight overrides by pool name, e.g. {\"<pool>\": {\"weight\": 2}}"`
+
+	// Time limits for operations on this backend, in seconds, parsed into Timeouts
+	CreateTimeout string            `json:"createTimeout" desc:"Seconds allowed to create a volume, empty for no limit"`
+	CloneTimeout  string            `json:"cloneTimeout" desc:"Seconds allowed to clone a volume, empty for the driver's limit"`
+	DeleteTimeout string            `json:"deleteTimeout" desc:"Seconds allowed to delete a volume, empty for no limit"`
+	MountTimeout  string            `json:"mountTimeout" desc:"Seconds to wait for a volume's devices to appear, empty for the driver's limit"`
+	Timeouts      OperationTimeouts `json:"-"`
+
+	// Bounds on the size of new volumes, parsed into SizeLimits, and the unit to which sizes are
+	// rounded up, parsed into SizeRounding
+	MinimumVolumeSize  string           `json:"minimumVolumeSize" desc:"Smallest volume the backend will create, e.g. 1Gi, empty for the driver's minimum"`
+	LimitVolumeSize    string           `json:"limitVolumeSize" desc:"Largest volume the backend will create, e.g. 10Ti, empty for no limit"`
+	VolumeSizeRounding string           `json:"volumeSizeRounding" desc:"Unit to which volume sizes are rounded up, \"MiB\", \"GiB\" or \"none\"" default:"none"`
+	SizeLimits         VolumeSizeLimits `json:"-"`
+	SizeRounding       uint64           `json:"-"`
+
+	// What to do when attaching iSCSI volumes to a host whose multipath setup is unsound
+	MultipathCheck string `json:"multipathCheck" desc:"Action when a host's multipath setup would attach iSCSI volumes over a single path: warn, fail or ignore" default:"warn"`
+
+	// How hosts find the iSCSI target's portals
+	ISCSIDiscovery string `json:"iscsiDiscovery" desc:"How Docker hosts find iSCSI portals: sendtargets discovery, or static node records where discovery is forbidden" default:"sendtargets" drivers:"ontap-san,eseries-iscsi"`
+}
+
+// PoolPlacement overrides the backend's placement priority and weight for one storage pool.
+type PoolPlacement struct {
+	Priority *int `json:"priority"`
+	Weight   *int `json:"weight"`
 }
 
 type CommonStorageDriverConfigDefaults struct {
-	Size string `json:"size"`
+	Size            string `json:"size" desc:"Size of new volumes when not specified" default:"1G" default.gcp-cvs:"1TiB"`
+	FileSystemOwner string `json:"fileSystemOwner" desc:"Owner, uid or uid:gid, given to new file systems" drivers:"ontap-san,solidfire-san,eseries-iscsi"`
+	FileSystemMode  string `json:"fileSystemMode" desc:"Mode, in octal, given to new file systems" drivers:"ontap-san,solidfire-san,eseries-iscsi"`
 }
 
 // ESeriesStorageDriverConfig holds settings for ESeriesStorageDriver
@@ -35,28 +75,31 @@ type ESeriesStorageDriverConfig struct {
 	*CommonStorageDriverConfig
 
 	// Web Proxy Services Info
-	WebProxyHostname  string `json:"webProxyHostname"`
-	WebProxyPort      string `json:"webProxyPort"`      // optional
-	WebProxyUseHTTP   bool   `json:"webProxyUseHTTP"`   // optional
-	WebProxyVerifyTLS bool   `json:"webProxyVerifyTLS"` // optional
-	Username          string `json:"username"`
-	Password          string `json:"password"`
+	WebProxyHostname  string `json:"webProxyHostname" desc:"Hostname or IP address of the web services proxy"`
+	WebProxyPort      string `json:"webProxyPort" desc:"Port of the web services proxy, empty for 80 (HTTP) or 443 (HTTPS)"`     // optional
+	WebProxyUseHTTP   bool   `json:"webProxyUseHTTP" desc:"Use HTTP instead of HTTPS to reach the proxy" default:"false"`        // optional
+	WebProxyVerifyTLS bool   `json:"webProxyVerifyTLS" desc:"Verify the proxy's certificate chain and hostname" default:"false"` // optional
+	Username          string `json:"username" desc:"Username for the web services proxy"`
+	Password          string `json:"password" desc:"Password for the web services proxy" sensitive:"true"`
 
 	// Array Info
-	ControllerA   string `json:"controllerA"`
-	ControllerB   string `json:"controllerB"`
-	PasswordArray string `json:"passwordArray"` //optional
+	ControllerA   string `json:"controllerA" desc:"IP address of controller A"`
+	ControllerB   string `json:"controllerB" desc:"IP address of controller B"`
+	PasswordArray string `json:"passwordArray" desc:"Password for the storage array, if set" sensitive:"true"` //optional
 
 	// Options
-	PoolNameSearchPattern string `json:"poolNameSearchPattern"` //optional
+	PoolNameSearchPattern string `json:"poolNameSearchPattern" desc:"Regular expression matching the storage pools to use" default:".+"` //optional
 
 	// Host Networking
-	HostDataIPDeprecated string `json:"hostData_IP,omitempty"` // for backward compatibility only
-	HostDataIP           string `json:"hostDataIP"`            // for iSCSI can be either port if multipathing is setup
-	AccessGroup          string `json:"accessGroupName"`       // name for host group
-	HostType             string `json:"hostType"`              // host type, default is 'linux_dm_mp'
+	HostDataIPDeprecated string `json:"hostData_IP,omitempty" desc:"-"`                                                  // for backward compatibility only
+	HostDataIP           string `json:"hostDataIP" desc:"iSCSI IP address of the array used for discovery"`              // for iSCSI can be either port if multipathing is setup
+	AccessGroup          string `json:"accessGroupName" desc:"Host group to which volumes are mapped" default:"trident"` // name for host group
+	HostType             string `json:"hostType" desc:"Host type of hosts created by the driver" default:"linux_dm_mp"`  // host type, default is 'linux_dm_mp'
 
-	EseriesStorageDriverConfigDefaults `json:"defaults"`
+	// Initiators of the cluster's nodes, for which hosts are kept in the host group
+	HostIQNs []string `json:"hostIQNs" desc:"IQNs of the cluster's nodes, kept in the host group; empty if an admin manages the hosts"`
+
+	EseriesStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
 }
 
 type EseriesStorageDriverConfigDefaults struct {
@@ -65,60 +108,198 @@ type EseriesStorageDriverConfigDefaults struct {
 
 // OntapStorageDriverConfig holds settings for OntapStorageDrivers
 type OntapStorageDriverConfig struct {
-	*CommonStorageDriverConfig              // embedded types replicate all fields
-	ManagementLIF                    string `json:"managementLIF"`
-	DataLIF                          string `json:"dataLIF"`
-	IgroupName                       string `json:"igroupName"`
-	SVM                              string `json:"svm"`
-	Username                         string `json:"username"`
-	Password                         string `json:"password"`
-	Aggregate                        string `json:"aggregate"`
-	UsageHeartbeat                   string `json:"usageHeartbeat"`           // in hours, default to 24.0
-	QtreePruneFlexvolsPeriod         string `json:"qtreePruneFlexvolsPeriod"` // in seconds, default to 600
-	QtreeQuotaResizePeriod           string `json:"qtreeQuotaResizePeriod"`   // in seconds, default to 60
-	NfsMountOptions                  string `json:"nfsMountOptions"`
-	OntapStorageDriverConfigDefaults `json:"defaults"`
+	*CommonStorageDriverConfig                         // embedded types replicate all fields
+	ManagementLIF                    string            `json:"managementLIF" desc:"IP address of a cluster or SVM management LIF"`
+	DataLIF                          string            `json:"dataLIF" desc:"IP address of a protocol LIF, derived from the SVM if empty"`
+	IgroupName                       string            `json:"igroupName" desc:"Igroup to which LUNs are mapped" default:"trident" drivers:"ontap-san"`
+	IgroupPerNode                    bool              `json:"igroupPerNode" desc:"Map LUNs only to an igroup of the Docker host using them, unmapping them when it stops" default:"false" drivers:"ontap-san"`
+	ISCSIPortals                     []string          `json:"iscsiPortals" desc:"IP addresses of the SVM's iSCSI LIFs to advertise to hosts, empty for the data LIF alone" drivers:"ontap-san"`
+	SVM                              string            `json:"svm" desc:"SVM to use, derived if managementLIF is an SVM management LIF"`
+	Username                         string            `json:"username" desc:"Username for the cluster or SVM"`
+	Password                         string            `json:"password" desc:"Password for the cluster or SVM" sensitive:"true"`
+	Aggregate                        string            `json:"aggregate" desc:"Aggregate in which volumes are created"`
+	UsageHeartbeat                   string            `json:"usageHeartbeat" desc:"-"`                                                                                               // in hours, default to 24.0
+	QtreePruneFlexvolsPeriod         string            `json:"qtreePruneFlexvolsPeriod" desc:"Seconds between deletions of empty Flexvols" default:"600" drivers:"ontap-nas-economy"` // in seconds, default to 600
+	QtreeQuotaResizePeriod           string            `json:"qtreeQuotaResizePeriod" desc:"Seconds between resizes of Flexvol quotas" default:"60" drivers:"ontap-nas-economy"`      // in seconds, default to 60
+	NfsMountOptions                  string            `json:"nfsMountOptions" desc:"NFS mount options, used by Docker only" default:"-o nfsvers=3" drivers:"ontap-nas,ontap-nas-economy"`
+	NASType                          string            `json:"nasType" desc:"Protocol by which hosts reach volumes, \"nfs\" or \"smb\" (Docker only)" default:"nfs" drivers:"ontap-nas"`
+	SMBShare                         string            `json:"smbShare" desc:"SMB share of the SVM's root junction, through which volumes are reached when nasType is smb" drivers:"ontap-nas"`
+	SMBUsername                      string            `json:"smbUsername" desc:"User as which hosts map the SMB share, empty for guest access" drivers:"ontap-nas"`
+	SMBPassword                      string            `json:"smbPassword" desc:"Password of the SMB user" sensitive:"true" drivers:"ontap-nas"`
+	SMBDomain                        string            `json:"smbDomain" desc:"Domain of the SMB user" drivers:"ontap-nas"`
+	AdvancedOptions                  map[string]string `json:"advancedOptions" desc:"ONTAP volume options to set on each new volume"`                                                                 // applied with volume-set-option
+	ZapiRecordFile                   string            `json:"zapiRecordFile" desc:"File to which ZAPI calls are recorded, for reproducing issues"`                                                   // for reproducing field issues
+	ZapiTimeout                      string            `json:"zapiTimeout" desc:"Seconds allowed for each ZAPI call, empty for no limit"`                                                             // in seconds, default to none
+	LSMirrorTimeout                  string            `json:"lsMirrorTimeout" desc:"Seconds to wait for SVM root load-sharing mirrors to update" default:"30" drivers:"ontap-nas,ontap-nas-economy"` // in seconds, default to 30
+	Profile                          string            `json:"profile" desc:"\"cvo\" to tune the backend for Cloud Volumes ONTAP"`                                                                    // "" or "cvo"
+	LimitFlexvolsPerSVM              int               `json:"limitFlexvolsPerSVM" desc:"Flexvols the SVM may hold before Trident stops creating them, 0 for no limit" default:"0"`
+	LimitFlexvolsPerAggregate        int               `json:"limitFlexvolsPerAggregate" desc:"Flexvols each aggregate may hold in the SVM before Trident stops creating them there, 0 for no limit" default:"0"`
+	FlexvolLimitWarningPercent       int               `json:"flexvolLimitWarningPercent" desc:"Percentage of a Flexvol limit, including the SVM's own max-volumes, at which Trident warns" default:"90"`
+	EMSSeverity                      string            `json:"emsSeverity" desc:"Severity of EMS heartbeat messages, from emergency to debug" default:"notice"`
+	EMSEventSource                   string            `json:"emsEventSource" desc:"Event source of EMS heartbeat messages, for matching by EMS filters" default:"trident"`
+	EMSAutoSupport                   string            `json:"emsAutoSupport" desc:"Whether EMS heartbeat messages also send an AutoSupport" default:"false"`
+	TelemetryTransport               string            `json:"telemetryTransport" desc:"How usage heartbeats reach NetApp: ems, https (ActiveIQ), or auto for https when EMS fails" default:"ems"`
+	TelemetryConsent                 bool              `json:"telemetryConsent" desc:"Consent to posting usage heartbeats to NetApp ActiveIQ over HTTPS" default:"false"`
+	TelemetryProxyURL                string            `json:"telemetryProxyURL" desc:"HTTP proxy for heartbeats posted to ActiveIQ" sensitive:"url"`
+	TelemetryURL                     string            `json:"telemetryURL" desc:"-"`
+	NameTemplate                     string            `json:"nameTemplate" desc:"Template of volume names, such as {{prefix}}_{{namespace}}_{{pvc}}, empty for the prefix and volume name"`
+	PeerSVMs                         []string          `json:"peerSVMs" desc:"SVMs that volumes are replicated or cached from, which must be peered with this SVM and reachable" drivers:"ontap-nas,ontap-san"`
+	CircuitBreakerFailures           int               `json:"circuitBreakerFailures" desc:"Consecutive ZAPI calls the cluster fails to answer before Trident pauses calls to it" default:"5"`
+	CircuitBreakerCooldown           string            `json:"circuitBreakerCooldown" desc:"Seconds ZAPI calls are paused before the cluster is probed, doubling while it stays down" default:"30"`
+	TenantPrefixes                   map[string]string `json:"tenantPrefixes" desc:"Storage prefix of each tenant's volumes, by the namespace that requests them, in place of storagePrefix" drivers:"ontap-nas,ontap-san"`
+	NormalizeDiscoveredVolumes       bool              `json:"normalizeDiscoveredVolumes" desc:"Give volumes found on the storage with -passthrough the backend's export policy, snapshot policy and security style" default:"false" drivers:"ontap-nas"`
+	Licenses                         []string          `json:"-"`
+	OntapStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
+
+	// Snapshot policy that Trident creates or updates on the SVM, given to volumes unless they name another
+	SnapshotPolicySpec *OntapSnapshotPolicySpec `json:"snapshotPolicySpec" desc:"Snapshot policy that Trident creates or updates on the SVM and gives new volumes" drivers:"ontap-nas,ontap-nas-economy,ontap-san"`
+
+	// Parsed from ZapiTimeout, LSMirrorTimeout and CircuitBreakerCooldown when the driver is initialized
+	ZapiTimeoutDuration            time.Duration `json:"-"`
+	LSMirrorTimeoutDuration        time.Duration `json:"-"`
+	CircuitBreakerCooldownDuration time.Duration `json:"-"`
+
+	// CircuitBreaker pauses the driver's ZAPI calls while the cluster isn't responding
+	CircuitBreaker *utils.CircuitBreaker `json:"-"`
+
+	// Parsed from EMSSeverity and EMSAutoSupport when the driver is initialized
+	EMSLogLevel           int  `json:"-"`
+	EMSAutoSupportEnabled bool `json:"-"`
+
+	// SVMUUID is the UUID of the SVM, found when the driver is initialized
+	SVMUUID string `json:"-"`
+
+	// The cluster's ONTAP release and ONTAPI version, and the version-dependent features they
+	// support, found when the driver is initialized
+	ONTAPVersion    string          `json:"-"`
+	ONTAPIVersion   string          `json:"-"`
+	VersionFeatures map[string]bool `json:"-"`
+
+	// UnavailableFeatures lists the features that the configured user's rights don't allow,
+	// such as for SVM-scoped users, found when the driver is initialized
+	UnavailableFeatures []string `json:"-"`
+
+	// SingleNode is set when the cluster is known to have a single node
+	SingleNode bool `json:"-"`
+	// ONTAPSelect is set when the cluster's nodes are ONTAP Select virtual machines
+	ONTAPSelect bool `json:"-"`
+
+	// SVMPeers are the SVMs peered with the SVM, found when the driver is initialized
+	SVMPeers []OntapSVMPeer `json:"-"`
+}
+
+// OntapSnapshotPolicySpec declares a snapshot policy that Trident keeps on an ONTAP backend's SVM,
+// creating it or changing its schedules to match when the driver is initialized.
+type OntapSnapshotPolicySpec struct {
+	Name      string                  `json:"name"`
+	Schedules []OntapSnapshotSchedule `json:"schedules"`
+}
+
+// OntapSnapshotSchedule is a schedule of a snapshot policy, naming an existing cron schedule and
+// the number of its snapshots to retain.
+type OntapSnapshotSchedule struct {
+	Schedule string `json:"schedule"`
+	Count    int    `json:"count"`
+	Prefix   string `json:"prefix,omitempty"`
+}
+
+// OntapSVMPeer is an SVM peered with an ONTAP backend's SVM, which volumes may be replicated or
+// cached from if the peering allows it.
+type OntapSVMPeer struct {
+	// SVM is the peer's name on its own cluster, and LocalName the name it is known by on this
+	// one, which differs if the peer was given a local name to avoid a conflict
+	SVM          string   `json:"svm"`
+	LocalName    string   `json:"localName,omitempty"`
+	Cluster      string   `json:"cluster"`
+	State        string   `json:"state"`
+	Applications []string `json:"applications"`
+	// Intercluster is the health of the intercluster LIFs linking this cluster to the peer's,
+	// such as "available" or "partial", or empty if the peer is on this cluster or the health
+	// couldn't be read
+	Intercluster    string   `json:"intercluster,omitempty"`
+	ActiveAddresses []string `json:"activeAddresses,omitempty"`
+	PeerAddresses   []string `json:"peerAddresses,omitempty"`
 }
 
 type OntapStorageDriverConfigDefaults struct {
-	SpaceReserve    string `json:"spaceReserve"`
-	SnapshotPolicy  string `json:"snapshotPolicy"`
-	UnixPermissions string `json:"unixPermissions"`
-	SnapshotDir     string `json:"snapshotDir"`
-	ExportPolicy    string `json:"exportPolicy"`
-	SecurityStyle   string `json:"securityStyle"`
-	SplitOnClone    string `json:"splitOnClone"`
-	FileSystemType  string `json:"fileSystemType"`
-	Encryption      string `json:"encryption"`
+	SpaceReserve    string `json:"spaceReserve" desc:"Space reservation mode, \"none\" (thin) or \"volume\" (thick)" default:"none"`
+	SnapshotPolicy  string `json:"snapshotPolicy" desc:"Snapshot policy to use" default:"none"`
+	UnixPermissions string `json:"unixPermissions" desc:"Mode of new volumes" default:"---rwxrwxrwx" drivers:"ontap-nas,ontap-nas-economy"`
+	SnapshotDir     string `json:"snapshotDir" desc:"Whether the .snapshot directory is visible" default:"false" drivers:"ontap-nas,ontap-nas-economy"`
+	ExportPolicy    string `json:"exportPolicy" desc:"Export policy to use" default:"default" drivers:"ontap-nas,ontap-nas-economy"`
+	SecurityStyle   string `json:"securityStyle" desc:"Security style of new volumes" default:"unix" drivers:"ontap-nas,ontap-nas-economy"`
+	SplitOnClone    string `json:"splitOnClone" desc:"Split a clone from its parent upon creation" default:"false" drivers:"ontap-nas,ontap-san"`
+	FileSystemType  string `json:"fileSystemType" desc:"File system created on new LUNs" default:"ext4" drivers:"ontap-san"`
+	Encryption      string `json:"encryption" desc:"Enable NetApp volume encryption" default:"false"`
+	CloneMethod     string `json:"cloneMethod" desc:"How clones are created, \"flexclone\", \"copy\" or \"auto\"" default:"flexclone" drivers:"ontap-nas"` // flexclone, copy, or auto
+	TieringPolicy   string `json:"tieringPolicy" desc:"FabricPool tiering policy, empty to let ONTAP choose"`
 	CommonStorageDriverConfigDefaults
 }
 
 // SolidfireStorageDriverConfig holds settings for SolidfireStorageDrivers
 type SolidfireStorageDriverConfig struct {
-	*CommonStorageDriverConfig           // embedded types replicate all fields
-	TenantName                           string
-	EndPoint                             string
-	SVIP                                 string
-	InitiatorIFace                       string //iface to use of iSCSI initiator
-	Types                                *[]sfapi.VolType
-	LegacyNamePrefix                     string //name prefix used in earlier ndvp versions
-	AccessGroups                         []int64
-	UseCHAP                              bool
-	DefaultBlockSize                     int64 //blocksize to use on create when not specified  (512|4096, 512 is default)
-	SolidfireStorageDriverConfigDefaults `json:"defaults"`
+	*CommonStorageDriverConfig                            // embedded types replicate all fields
+	TenantName                           string           `desc:"Tenant to use, created if not found"`
+	EndPoint                             string           `desc:"URL of the cluster MVIP, with tenant credentials" sensitive:"url"`
+	SVIP                                 string           `desc:"Storage (iSCSI) IP address and port"`
+	InitiatorIFace                       string           `desc:"Host interface to which iSCSI traffic is restricted" default:"default"` //iface to use of iSCSI initiator
+	Types                                *[]sfapi.VolType `desc:"QoS types, e.g. [{\"Type\": \"Bronze\", \"Qos\": {\"minIOPS\": 1000, \"maxIOPS\": 2000, \"burstIOPS\": 4000}}]"`
+	LegacyNamePrefix                     string           `desc:"Name prefix of volumes created by earlier versions of the plugin"` //name prefix used in earlier ndvp versions
+	AccessGroups                         []int64          `desc:"IDs of the access groups to use, empty to find the group named trident"`
+	UseCHAP                              bool             `desc:"Use CHAP to authenticate iSCSI instead of access groups" default:"false"`
+	DefaultBlockSize                     int64            `desc:"Block size of new volumes, 512 or 4096" default:"512"` //blocksize to use on create when not specified  (512|4096, 512 is default)
+	SolidfireStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
 }
 
 type SolidfireStorageDriverConfigDefaults struct {
 	CommonStorageDriverConfigDefaults
 }
 
+// GCPNFSStorageDriverConfig holds settings for the Cloud Volumes Service for GCP driver
+type GCPNFSStorageDriverConfig struct {
+	*CommonStorageDriverConfig
+	ProjectNumber   string                   `json:"projectNumber" desc:"GCP project number"`
+	APIKey          gcpapi.ServiceAccountKey `json:"apiKey" desc:"The JSON key of the GCP service account"`
+	APIRegion       string                   `json:"apiRegion" desc:"GCP region in which volumes are created"`
+	APIURL          string                   `json:"apiURL" desc:"-"`                                                            // optional, for testing or proxies
+	APIAudience     string                   `json:"apiAudience" desc:"-"`                                                       // optional, for testing or proxies
+	Network         string                   `json:"network" desc:"VPC network to which volumes are attached" default:"default"` // VPC network, default to "default"
+	ServiceLevel    string                   `json:"serviceLevel" desc:"Limits the backend to one service level, empty for all"`
+	NfsMountOptions string                   `json:"nfsMountOptions" desc:"NFS mount options, used by Docker only" default:"-o nfsvers=3"`
+
+	GCPNFSStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
+}
+
+type GCPNFSStorageDriverConfigDefaults struct {
+	ExportRule  string `json:"exportRule" desc:"Comma-separated IP addresses and CIDR blocks allowed to mount" default:"0.0.0.0/0"`
+	SnapshotDir string `json:"snapshotDir" desc:"Whether the .snapshot directory is visible" default:"false"`
+	CommonStorageDriverConfigDefaults
+}
+
+// GenericNFSStorageDriverConfig holds settings for the driver that provisions directories on any NFS export
+type GenericNFSStorageDriverConfig struct {
+	*CommonStorageDriverConfig
+	NfsServer       string `json:"nfsServer" desc:"IP address or hostname of the NFS server"`
+	NfsExport       string `json:"nfsExport" desc:"Path of the export in which volumes are created"`
+	MountPoint      string `json:"mountPoint" desc:"Existing local mount of the export, empty for Trident to mount it"` // where Trident reaches the export, default to mounting it itself
+	NfsMountOptions string `json:"nfsMountOptions" desc:"NFS mount options" default:"-o nfsvers=3"`
+	QuotaType       string `json:"quotaType" desc:"Limits the size of volumes, \"none\" or \"xfs\"" default:"none"` // "none" or "xfs"
+
+	GenericNFSStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
+}
+
+type GenericNFSStorageDriverConfigDefaults struct {
+	UnixPermissions string `json:"unixPermissions" desc:"Mode of new volume directories" default:"0777"`
+	CommonStorageDriverConfigDefaults
+}
+
 type FakeStorageDriverConfig struct {
 	*CommonStorageDriverConfig
-	Protocol trident.Protocol `json:"protocol"`
+	Protocol trident.Protocol `json:"protocol" desc:"Protocol of the fake volumes, \"file\" or \"block\""`
 	// pools represents the possible buckets into which a given volume should go
-	Pools                           map[string]*fake.StoragePool `json:"pools"`
-	InstanceName                    string                       `json:"instanceName"`
-	FakeStorageDriverConfigDefaults `json:"defaults"`
+	Pools                           map[string]*fake.StoragePool `json:"pools" desc:"Fake storage pools by name, each with attributes and a sizeBytes"`
+	InstanceName                    string                       `json:"instanceName" desc:"Name of the fake backend"`
+	FakeStorageDriverConfigDefaults `json:"defaults" desc:"Defaults for new volumes"`
 }
 
 type FakeStorageDriverConfigDefaults struct {
@@ -157,6 +338,37 @@ func ValidateCommonSettings(configJSON string) (*CommonStorageDriverConfig, erro
 			"use the command line --debug switch instead.")
 	}
 
+	// Weights are relative, so zero means the default and negative values make no sense
+	if config.Weight < 0 {
+		return nil, fmt.Errorf("invalid weight %d; weight must not be negative", config.Weight)
+	}
+	for poolName, placement := range config.PoolPlacement {
+		if placement.Weight != nil && *placement.Weight < 1 {
+			return nil, fmt.Errorf("invalid weight %d for pool %s; weight must be positive",
+				*placement.Weight, poolName)
+		}
+	}
+
+	if err = parseOperationTimeouts(config); err != nil {
+		return nil, err
+	}
+
+	if err = parseVolumeSizeLimits(config); err != nil {
+		return nil, err
+	}
+
+	if err = parseVolumeSizeRounding(config); err != nil {
+		return nil, err
+	}
+
+	if err = validateMultipathCheck(config.MultipathCheck); err != nil {
+		return nil, err
+	}
+
+	if err = validateISCSIDiscovery(config.ISCSIDiscovery); err != nil {
+		return nil, err
+	}
+
 	// The storage prefix may have three states: nil (no prefix specified, drivers will use
 	// a default prefix), "" (specified as an empty string, drivers will use no prefix), and
 	// "<value>" (a prefix specified in the backend config file).  For historical reasons,
@@ -217,10 +429,19 @@ func GetDefaultIgroupName(context trident.DriverContext) string {
 }
 
 type CommonStorageDriverConfigExternal struct {
-	Version           int      `json:"version"`
-	StorageDriverName string   `json:"storageDriverName"`
-	StoragePrefix     *string  `json:"storagePrefix"`
-	SerialNumbers     []string `json:"serialNumbers"`
+	Version           int                      `json:"version"`
+	StorageDriverName string                   `json:"storageDriverName"`
+	StoragePrefix     *string                  `json:"storagePrefix"`
+	SerialNumbers     []string                 `json:"serialNumbers"`
+	Priority          int                      `json:"priority"`
+	Weight            int                      `json:"weight"`
+	PoolPlacement     map[string]PoolPlacement `json:"poolPlacement,omitempty"`
+	CreateTimeout     string                   `json:"createTimeout,omitempty"`
+	CloneTimeout      string                   `json:"cloneTimeout,omitempty"`
+	DeleteTimeout     string                   `json:"deleteTimeout,omitempty"`
+	MountTimeout      string                   `json:"mountTimeout,omitempty"`
+	MultipathCheck    string                   `json:"multipathCheck,omitempty"`
+	ISCSIDiscovery    string                   `json:"iscsiDiscovery,omitempty"`
 }
 
 func SanitizeCommonStorageDriverConfig(c *CommonStorageDriverConfig) {
@@ -240,6 +461,15 @@ func GetCommonStorageDriverConfigExternal(
 		StorageDriverName: c.StorageDriverName,
 		StoragePrefix:     c.StoragePrefix,
 		SerialNumbers:     c.SerialNumbers,
+		Priority:          c.Priority,
+		Weight:            c.Weight,
+		PoolPlacement:     c.PoolPlacement,
+		CreateTimeout:     c.CreateTimeout,
+		CloneTimeout:      c.CloneTimeout,
+		DeleteTimeout:     c.DeleteTimeout,
+		MountTimeout:      c.MountTimeout,
+		MultipathCheck:    c.MultipathCheck,
+		ISCSIDiscovery:    c.ISCSIDiscovery,
 	}
 }
 
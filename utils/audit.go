@@ -0,0 +1,115 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditInfo identifies the Trident operation on whose behalf storage is changed, so that the
+// changes recorded by the storage, such as in ONTAP's audit log, can be matched to the operation
+// and to whoever requested it.
+type AuditInfo struct {
+	CorrelationID string
+	Operation     string
+	Volume        string
+	Requester     string
+}
+
+type requesterKey struct{}
+type auditInfoKey struct{}
+type ownerKey struct{}
+
+// WithRequester returns a copy of the context that names who requested the operations made with
+// it, such as a frontend and the client or claim it acts for.
+func WithRequester(ctx context.Context, requester string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requester)
+}
+
+// WithAuditInfo returns a copy of the context that carries a new correlation ID for an operation
+// on a volume, along with the operation's details.
+func WithAuditInfo(ctx context.Context, operation, volume string) (context.Context, *AuditInfo) {
+	requester, _ := ctx.Value(requesterKey{}).(string)
+	if requester == "" {
+		requester = "unknown"
+	}
+	audit := &AuditInfo{
+		CorrelationID: newCorrelationID(),
+		Operation:     operation,
+		Volume:        volume,
+		Requester:     requester,
+	}
+	return context.WithValue(ctx, auditInfoKey{}, audit), audit
+}
+
+// GetAuditInfo returns the operation a context was created for, or nil if it has none.
+func GetAuditInfo(ctx context.Context) *AuditInfo {
+	if ctx == nil {
+		return nil
+	}
+	audit, _ := ctx.Value(auditInfoKey{}).(*AuditInfo)
+	return audit
+}
+
+// Fields returns the operation's details for logging.
+func (a *AuditInfo) Fields() log.Fields {
+	return log.Fields{
+		"correlationID": a.CorrelationID,
+		"operation":     a.Operation,
+		"volume":        a.Volume,
+		"requester":     a.Requester,
+	}
+}
+
+// String describes the operation for storage that records it, such as in a volume comment.
+func (a *AuditInfo) String() string {
+	return fmt.Sprintf("%s of %s requested by %s, correlation ID %s", a.Operation, a.Volume, a.Requester,
+		a.CorrelationID)
+}
+
+// newCorrelationID returns a random ID, short enough to be read from logs and volume comments.
+func newCorrelationID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		log.Warnf("Could not generate a correlation ID. %v", err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// WithOwner returns a copy of the context that names the owner of the volumes created with it,
+// such as the UUID of the backend creating them.  Storage that records the owner lets Trident tell
+// its own volumes from those of other instances sharing the same storage.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// GetOwner returns the owner of the volumes created with a context, or empty if it has none.
+func GetOwner(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+// ownerMarkerRegex matches the owner recorded on storage by OwnerMarker.
+var ownerMarkerRegex = regexp.MustCompile(`\[trident-owner ([A-Za-z0-9-]+)\]`)
+
+// OwnerMarker returns the text recording a volume's owner on storage, such as in a volume comment.
+func OwnerMarker(owner string) string {
+	return fmt.Sprintf("[trident-owner %s]", owner)
+}
+
+// ParseOwnerMarker returns the owner recorded in text by OwnerMarker, or empty if there is none.
+func ParseOwnerMarker(text string) string {
+	if match := ownerMarkerRegex.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
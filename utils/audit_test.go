@@ -0,0 +1,56 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestAuditInfo(t *testing.T) {
+	log.Debug("Running TestAuditInfo...")
+
+	if GetAuditInfo(nil) != nil || GetAuditInfo(context.Background()) != nil {
+		t.Error("Expected no audit info without an operation.")
+	}
+
+	ctx := WithRequester(context.Background(), "docker")
+	ctx, audit := WithAuditInfo(ctx, "create", "vol1")
+	if GetAuditInfo(ctx) != audit {
+		t.Fatal("Expected the context to carry the operation's audit info.")
+	}
+	if len(audit.CorrelationID) != 16 || audit.Requester != "docker" {
+		t.Errorf("Unexpected audit info %+v.", audit)
+	}
+	expected := "create of vol1 requested by docker, correlation ID " + audit.CorrelationID
+	if audit.String() != expected {
+		t.Errorf("Expected description %q, got %q.", expected, audit.String())
+	}
+
+	_, other := WithAuditInfo(context.Background(), "delete", "vol1")
+	if other.CorrelationID == audit.CorrelationID || other.Requester != "unknown" {
+		t.Errorf("Expected a new correlation ID and an unknown requester, got %+v.", other)
+	}
+}
+
+func TestOwnerMarker(t *testing.T) {
+	log.Debug("Running TestOwnerMarker...")
+
+	if GetOwner(nil) != "" || GetOwner(context.Background()) != "" {
+		t.Error("Expected no owner without one being set.")
+	}
+	ctx := WithOwner(context.Background(), "0f3c2a1e-5b7d-4c1a-9e2b-8d6f4a3b2c1d")
+	if GetOwner(ctx) != "0f3c2a1e-5b7d-4c1a-9e2b-8d6f4a3b2c1d" {
+		t.Errorf("Expected the context to carry the owner, got %q.", GetOwner(ctx))
+	}
+
+	comment := "Created by Trident: create of vol1 " + OwnerMarker(GetOwner(ctx))
+	if owner := ParseOwnerMarker(comment); owner != GetOwner(ctx) {
+		t.Errorf("Expected owner %s from comment %q, got %q.", GetOwner(ctx), comment, owner)
+	}
+	if owner := ParseOwnerMarker("Created by Trident"); owner != "" {
+		t.Errorf("Expected no owner from a comment without a marker, got %q.", owner)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Defaults for CIFS mounts, chosen so that shares aren't reached with SMB1 or without signing
+const (
+	DefaultCIFSVersion  = "3.0"
+	DefaultCIFSSecurity = "ntlmssp"
+)
+
+// CIFS protocol versions and security modes accepted by mount.cifs
+var (
+	cifsVersions   = []string{"1.0", "2.0", "2.1", "3", "3.0", "3.02", "3.1.1", "default"}
+	cifsSecurities = []string{"none", "krb5", "krb5i", "ntlm", "ntlmi", "ntlmv2", "ntlmv2i", "ntlmssp", "ntlmsspi"}
+)
+
+// CIFSCredentials identifies the user as which a CIFS share is mounted.  Without a username, the
+// share is mounted as guest, or with Kerberos credentials if the security mode calls for them.
+type CIFSCredentials struct {
+	Username string
+	Password string
+	Domain   string
+}
+
+// MountCIFS mounts the supplied CIFS share, given as //server/share or \\server\share, at the
+// supplied location.  The options are those of mount.cifs, separated by commas; vers and sec
+// default to SMB 3.0 and NTLMSSP.  Credentials are passed to mount.cifs in a file readable only
+// by root, which is removed once the share is mounted, so they never appear in a command line.
+func MountCIFS(share, mountpoint string, credentials CIFSCredentials, options string) error {
+
+	log.WithFields(log.Fields{
+		"share":      share,
+		"mountpoint": mountpoint,
+		"username":   credentials.Username,
+		"options":    options,
+	}).Debug(">>>> cifs.MountCIFS")
+	defer log.Debug("<<<< cifs.MountCIFS")
+
+	share = strings.Replace(share, `\`, "/", -1)
+	if !strings.HasPrefix(share, "//") || len(strings.Split(strings.TrimPrefix(share, "//"), "/")) < 2 {
+		return fmt.Errorf("invalid CIFS share %s; it must be //server/share", share)
+	}
+
+	credentialsFile := ""
+	if credentials.Username != "" {
+		var err error
+		if credentialsFile, err = writeCIFSCredentialsFile(credentials); err != nil {
+			return err
+		}
+		defer os.Remove(credentialsFile)
+	}
+
+	mountOptions, err := cifsMountOptions(credentialsFile, options)
+	if err != nil {
+		return err
+	}
+
+	if _, err = execCommand("mkdir", "-p", mountpoint); err != nil {
+		log.WithField("error", err).Warning("Mkdir failed.")
+	}
+	if out, err := execCommand("mount", "-t", "cifs", "-o", mountOptions, share, mountpoint); err != nil {
+		return fmt.Errorf("could not mount CIFS share %s: %v; %s", share, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// cifsMountOptions returns the options for mount.cifs, adding the credentials file and the
+// default protocol version and security mode to those supplied.
+func cifsMountOptions(credentialsFile, options string) (string, error) {
+
+	mountOptions := make([]string, 0)
+	hasVersion, hasSecurity := false, false
+
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		parts := strings.SplitN(option, "=", 2)
+		switch parts[0] {
+		case "username", "user", "password", "pass", "credentials", "cred", "domain", "dom", "workgroup":
+			return "", fmt.Errorf("invalid CIFS mount option %s; credentials must not be given as options", parts[0])
+		case "vers":
+			if len(parts) != 2 || !containsString(cifsVersions, parts[1]) {
+				return "", fmt.Errorf("invalid CIFS protocol version in %s; it must be one of %s",
+					option, strings.Join(cifsVersions, ", "))
+			}
+			hasVersion = true
+		case "sec":
+			if len(parts) != 2 || !containsString(cifsSecurities, parts[1]) {
+				return "", fmt.Errorf("invalid CIFS security mode in %s; it must be one of %s",
+					option, strings.Join(cifsSecurities, ", "))
+			}
+			hasSecurity = true
+		}
+		mountOptions = append(mountOptions, option)
+	}
+
+	if !hasVersion {
+		mountOptions = append(mountOptions, "vers="+DefaultCIFSVersion)
+	}
+	if !hasSecurity {
+		mountOptions = append(mountOptions, "sec="+DefaultCIFSSecurity)
+	}
+	if credentialsFile != "" {
+		mountOptions = append(mountOptions, "credentials="+credentialsFile)
+	} else if !strings.HasPrefix(optionValue(mountOptions, "sec"), "krb5") {
+		mountOptions = append(mountOptions, "guest")
+	}
+
+	return strings.Join(mountOptions, ","), nil
+}
+
+// optionValue returns the value of the last option of a given name in a list of name=value options.
+func optionValue(options []string, name string) string {
+	value := ""
+	for _, option := range options {
+		if strings.HasPrefix(option, name+"=") {
+			value = strings.TrimPrefix(option, name+"=")
+		}
+	}
+	return value
+}
+
+// cifsCredentialsFileContents returns credentials in the format mount.cifs reads from a
+// credentials file.
+func cifsCredentialsFileContents(credentials CIFSCredentials) (string, error) {
+
+	for _, value := range []string{credentials.Username, credentials.Password, credentials.Domain} {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("CIFS credentials must not contain line breaks")
+		}
+	}
+
+	contents := fmt.Sprintf("username=%s\npassword=%s\n", credentials.Username, credentials.Password)
+	if credentials.Domain != "" {
+		contents += fmt.Sprintf("domain=%s\n", credentials.Domain)
+	}
+	return contents, nil
+}
+
+// writeCIFSCredentialsFile writes credentials to a new file readable only by its owner, and
+// returns its path.
+func writeCIFSCredentialsFile(credentials CIFSCredentials) (string, error) {
+
+	contents, err := cifsCredentialsFileContents(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	// ioutil.TempFile creates the file with mode 0600
+	file, err := ioutil.TempFile("", "trident-cifs-")
+	if err != nil {
+		return "", fmt.Errorf("could not create CIFS credentials file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(contents); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("could not write CIFS credentials file: %v", err)
+	}
+	return file.Name(), nil
+}
+
+// containsString returns true if a slice holds a given string.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
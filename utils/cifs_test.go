@@ -0,0 +1,103 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestCIFSMountOptions(t *testing.T) {
+	log.Debug("Running TestCIFSMountOptions...")
+
+	for _, test := range []struct {
+		credentialsFile, options, expected string
+	}{
+		{"/tmp/creds", "", "vers=3.0,sec=ntlmssp,credentials=/tmp/creds"},
+		{"/tmp/creds", "vers=2.1, sec=ntlmv2,uid=1000", "vers=2.1,sec=ntlmv2,uid=1000,credentials=/tmp/creds"},
+		{"", "", "vers=3.0,sec=ntlmssp,guest"},
+		{"", "sec=krb5", "sec=krb5,vers=3.0"},
+	} {
+		options, err := cifsMountOptions(test.credentialsFile, test.options)
+		if err != nil {
+			t.Errorf("Expected options %q to be valid: %v", test.options, err)
+		} else if options != test.expected {
+			t.Errorf("Expected mount options %q for %q, got %q", test.expected, test.options, options)
+		}
+	}
+
+	for _, options := range []string{"vers=4.0", "sec=plaintext", "sec", "password=secret", "username=admin"} {
+		if _, err := cifsMountOptions("", options); err == nil {
+			t.Errorf("Expected options %q to be rejected", options)
+		}
+	}
+}
+
+func TestCIFSCredentialsFile(t *testing.T) {
+	log.Debug("Running TestCIFSCredentialsFile...")
+
+	path, err := writeCIFSCredentialsFile(CIFSCredentials{Username: "admin", Password: "secret", Domain: "CORP"})
+	if err != nil {
+		t.Fatalf("Could not write credentials file: %v", err)
+	}
+	defer os.Remove(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Could not stat credentials file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected credentials file mode 0600, got %v", info.Mode().Perm())
+	}
+	contents, _ := ioutil.ReadFile(path)
+	if string(contents) != "username=admin\npassword=secret\ndomain=CORP\n" {
+		t.Errorf("Unexpected credentials file contents %q", contents)
+	}
+
+	if _, err = cifsCredentialsFileContents(CIFSCredentials{Username: "admin", Password: "a\nb"}); err == nil {
+		t.Error("Expected a password with a line break to be rejected")
+	}
+}
+
+func TestSMBShareRoot(t *testing.T) {
+	log.Debug("Running TestSMBShareRoot...")
+
+	for path, expected := range map[string]string{
+		`\\10.0.0.1\root\trident_vol1`: `\\10.0.0.1\root`,
+		`//10.0.0.1/root/trident_vol1`: `\\10.0.0.1\root`,
+		`\\server\share`:               `\\server\share`,
+	} {
+		if share, err := smbShareRoot(path); err != nil || share != expected {
+			t.Errorf("Expected share %s for %s, got %s and %v", expected, path, share, err)
+		}
+	}
+	for _, path := range []string{`\\server`, `\\\share`, `server\share`, ""} {
+		if _, err := smbShareRoot(path); err == nil {
+			t.Errorf("Expected path %q to be rejected", path)
+		}
+	}
+}
+
+func TestSMBMappingEnv(t *testing.T) {
+	log.Debug("Running TestSMBMappingEnv...")
+
+	env, err := smbMappingEnv(`\\server\share`, CIFSCredentials{Username: "admin", Password: "secret", Domain: "CORP"})
+	if err != nil {
+		t.Fatalf("Unable to build SMB mapping environment: %v", err)
+	}
+	expected := []string{`TRIDENT_SMB_SHARE=\\server\share`, `TRIDENT_SMB_USERNAME=CORP\admin`, "TRIDENT_SMB_PASSWORD=secret"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected environment %v, got %v", expected, env)
+	}
+
+	if _, err = smbMappingEnv(`\\server\share`, CIFSCredentials{}); err == nil {
+		t.Error("Expected mapping without a username to be rejected")
+	}
+	if _, err = smbMappingEnv(`\\server\share`, CIFSCredentials{Username: "admin", Password: "a\nb"}); err == nil {
+		t.Error("Expected credentials with line breaks to be rejected")
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through, as the service is responding.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen refuses every request until the cool-down has passed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through to learn whether the service has recovered.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// circuitMaxCooldownFactor limits how far the cool-down grows while a service stays down, as a
+// multiple of the initial cool-down.
+const circuitMaxCooldownFactor = 16
+
+// CircuitBreaker stops requests to a service that has failed repeatedly, so that callers fail at
+// once rather than each waiting out a timeout and retrying against a service that is down.  After
+// enough consecutive failures the circuit opens, and no requests are sent for a cool-down period.
+// The next request after that is let through as a probe: if it succeeds the circuit closes, and
+// if it fails the circuit opens again with the cool-down doubled, up to a limit.  A nil breaker
+// lets every request through.
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	trips    int
+	retryAt  time.Time
+	lastErr  error
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a closed breaker that opens after threshold consecutive failures and
+// then waits at least cooldown before probing.  The name identifies the service in logs.
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     CircuitClosed,
+		now:       time.Now,
+	}
+}
+
+// CircuitOpenError is returned instead of sending a request while a circuit is open.  It is a
+// temporary net.Error, so that callers treat it as they would the failure to reach the service.
+type CircuitOpenError struct {
+	Name    string
+	RetryAt time.Time
+	LastErr error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s is not responding, so requests are paused until %s; last error: %v",
+		e.Name, e.RetryAt.Format(time.RFC3339), e.LastErr)
+}
+
+func (e *CircuitOpenError) Timeout() bool   { return false }
+func (e *CircuitOpenError) Temporary() bool { return true }
+
+// IsCircuitOpenError returns true if the error is a CircuitOpenError.
+func IsCircuitOpenError(err error) bool {
+	_, ok := err.(*CircuitOpenError)
+	return ok
+}
+
+// Allow returns nil if a request may be sent, or a CircuitOpenError if it may not.  Once the
+// cool-down has passed, the first caller is allowed through as the probe and others are refused
+// until it finishes.  Each allowed request must be followed by a call to Success, Failure or
+// Abandon.
+func (b *CircuitBreaker) Allow() error {
+
+	if b == nil {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Before(b.retryAt) {
+			return b.openError()
+		}
+		b.state = CircuitHalfOpen
+		log.WithField("service", b.name).Info("Probing whether the service has recovered.")
+		return nil
+	case CircuitHalfOpen:
+		return b.openError()
+	default:
+		return nil
+	}
+}
+
+// Success records that a request succeeded, which closes the circuit.
+func (b *CircuitBreaker) Success() {
+
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state != CircuitClosed {
+		log.WithField("service", b.name).Info("Service is responding again, resuming requests.")
+	}
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trips = 0
+	b.lastErr = nil
+}
+
+// Failure records that a request failed because the service couldn't be reached or couldn't
+// respond.  Enough consecutive failures open the circuit, as does a failed probe.
+func (b *CircuitBreaker) Failure(err error) {
+
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastErr = err
+	b.failures++
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.open()
+	case CircuitClosed:
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// Abandon records that an allowed request ended without showing whether the service is
+// responding, such as because its caller gave up.  An abandoned probe lets the next request probe.
+func (b *CircuitBreaker) Abandon() {
+
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == CircuitHalfOpen {
+		b.state = CircuitOpen
+	}
+}
+
+// State returns the state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+// ProbeDue returns true if the circuit is open and its cool-down has passed, so that the next
+// request would be sent as a probe.
+func (b *CircuitBreaker) ProbeDue() bool {
+
+	if b == nil {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state == CircuitOpen && !b.now().Before(b.retryAt)
+}
+
+// Reason returns why requests are paused, or an empty string if the circuit is closed.
+func (b *CircuitBreaker) Reason() string {
+
+	if b == nil {
+		return ""
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == CircuitClosed {
+		return ""
+	}
+	return fmt.Sprintf("%d consecutive requests failed, last with: %v; probing again at %s",
+		b.failures, b.lastErr, b.retryAt.Format(time.RFC3339))
+}
+
+// open opens the circuit for a cool-down that doubles with each consecutive failed probe.  The
+// caller must hold the mutex.
+func (b *CircuitBreaker) open() {
+
+	cooldown := b.cooldown
+	for i := 0; i < b.trips && cooldown < b.cooldown*circuitMaxCooldownFactor; i++ {
+		cooldown *= 2
+	}
+	if cooldown > b.cooldown*circuitMaxCooldownFactor {
+		cooldown = b.cooldown * circuitMaxCooldownFactor
+	}
+	b.trips++
+
+	b.state = CircuitOpen
+	b.retryAt = b.now().Add(cooldown)
+
+	log.WithFields(log.Fields{
+		"service":  b.name,
+		"failures": b.failures,
+		"cooldown": cooldown,
+	}).Errorf("Service is not responding, pausing requests. %v", b.lastErr)
+}
+
+// openError returns the error refusing a request while the circuit is open.  The caller must hold
+// the mutex.
+func (b *CircuitBreaker) openError() error {
+	return &CircuitOpenError{Name: b.name, RetryAt: b.retryAt, LastErr: b.lastErr}
+}
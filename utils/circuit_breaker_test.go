@@ -0,0 +1,120 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	log.Debug("Running TestCircuitBreaker...")
+
+	now := time.Now()
+	b := NewCircuitBreaker("cluster", 3, time.Minute)
+	b.now = func() time.Time { return now }
+	down := errors.New("connection refused")
+
+	// Failures short of the threshold, or broken up by a success, leave the circuit closed
+	b.Failure(down)
+	b.Failure(down)
+	b.Success()
+	b.Failure(down)
+	b.Failure(down)
+	if state := b.State(); state != CircuitClosed {
+		t.Fatalf("Expected a closed circuit, got %s.", state)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a closed circuit to allow requests, got %v.", err)
+	}
+
+	b.Failure(down)
+	if state := b.State(); state != CircuitOpen {
+		t.Fatalf("Expected an open circuit, got %s.", state)
+	}
+	err := b.Allow()
+	if !IsCircuitOpenError(err) {
+		t.Fatalf("Expected an open circuit to refuse requests, got %v.", err)
+	}
+	if netErr, ok := err.(net.Error); !ok || !netErr.Temporary() {
+		t.Error("Expected the refusal to be a temporary network error.")
+	}
+	if b.Reason() == "" || b.ProbeDue() {
+		t.Error("Expected a reason and no probe during the cool-down.")
+	}
+
+	// After the cool-down one probe is let through, and a failed probe doubles the cool-down
+	now = now.Add(time.Minute)
+	if !b.ProbeDue() {
+		t.Fatal("Expected a probe to be due after the cool-down.")
+	}
+	if err = b.Allow(); err != nil {
+		t.Fatalf("Expected the probe to be allowed, got %v.", err)
+	}
+	if err = b.Allow(); !IsCircuitOpenError(err) {
+		t.Fatalf("Expected only one probe at a time, got %v.", err)
+	}
+	b.Failure(down)
+	now = now.Add(time.Minute)
+	if b.ProbeDue() {
+		t.Fatal("Expected the cool-down to double after a failed probe.")
+	}
+	now = now.Add(time.Minute)
+	if !b.ProbeDue() {
+		t.Fatal("Expected a probe to be due after the doubled cool-down.")
+	}
+
+	// An abandoned probe leaves the next request to probe
+	if err = b.Allow(); err != nil {
+		t.Fatalf("Expected the probe to be allowed, got %v.", err)
+	}
+	b.Abandon()
+	if err = b.Allow(); err != nil {
+		t.Fatalf("Expected another probe after one was abandoned, got %v.", err)
+	}
+
+	b.Success()
+	if state := b.State(); state != CircuitClosed || b.Reason() != "" {
+		t.Fatalf("Expected a successful probe to close the circuit, got %s.", state)
+	}
+
+	// Recovery resets the cool-down
+	b.Failure(down)
+	b.Failure(down)
+	b.Failure(down)
+	now = now.Add(time.Minute)
+	if !b.ProbeDue() {
+		t.Error("Expected the cool-down to be reset after the service recovered.")
+	}
+
+	var nilBreaker *CircuitBreaker
+	if err = nilBreaker.Allow(); err != nil || nilBreaker.State() != CircuitClosed {
+		t.Errorf("Expected a nil breaker to allow requests, got %v.", err)
+	}
+	nilBreaker.Failure(down)
+}
+
+func TestCircuitBreakerCooldownLimit(t *testing.T) {
+	log.Debug("Running TestCircuitBreakerCooldownLimit...")
+
+	now := time.Now()
+	b := NewCircuitBreaker("cluster", 1, time.Second)
+	b.now = func() time.Time { return now }
+
+	b.Failure(errors.New("timeout"))
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Hour)
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected the probe to be allowed, got %v.", err)
+		}
+		b.Failure(errors.New("timeout"))
+	}
+	if limit := now.Add(circuitMaxCooldownFactor * time.Second); b.retryAt.After(limit) {
+		t.Errorf("Expected the cool-down to be limited to %d seconds, got %s.", circuitMaxCooldownFactor,
+			b.retryAt.Sub(now))
+	}
+}
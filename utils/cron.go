@@ -0,0 +1,153 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a schedule in the five-field format of crontab: minute, hour, day of month,
+// month and day of week.  Each field may be *, a number, a range such as 1-5, any of those with a
+// step such as */15 or 0-12/2, or a comma-separated list of them.  As in cron, a time matches if
+// its day matches either the day of month or the day of week when both are restricted.
+type CronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	daysRestricted, weekdaysRestricted     bool
+}
+
+// cronMacros are the shorthand schedules understood by ParseCronSchedule.
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// cronSearchLimit bounds the search for the next time a schedule matches, since a schedule such
+// as February 30th never does.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// ParseCronSchedule parses a schedule in crontab format, or one of @hourly, @daily, @weekly,
+// @monthly and @yearly.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+
+	if macro, ok := cronMacros[strings.TrimSpace(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q; expected 5 fields, found %d", spec, len(fields))
+	}
+
+	var err error
+	schedule := &CronSchedule{}
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute in schedule %q; %v", spec, err)
+	}
+	if schedule.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour in schedule %q; %v", spec, err)
+	}
+	if schedule.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day of month in schedule %q; %v", spec, err)
+	}
+	if schedule.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month in schedule %q; %v", spec, err)
+	}
+	// Sunday may be either 0 or 7
+	if schedule.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day of week in schedule %q; %v", spec, err)
+	}
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+	schedule.daysRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.weekdaysRestricted = !strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// parseCronField returns a bit set of the values matched by one field of a schedule.
+func parseCronField(field string, min, max int) (uint64, error) {
+
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+
+		rangePart, step := part, 1
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			var err error
+			rangePart = part[:slash]
+			if step, err = strconv.Atoi(part[slash+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s", part)
+			}
+		}
+
+		first, last := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if first, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %s", bounds[0])
+			}
+			last = first
+			if len(bounds) == 2 {
+				if last, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %s", bounds[1])
+				}
+			} else if step > 1 {
+				// As in cron, a single value with a step runs from that value to the end
+				last = max
+			}
+		}
+		if first < min || last > max || first > last {
+			return 0, fmt.Errorf("%s is outside the range %d-%d", rangePart, min, max)
+		}
+
+		for value := first; value <= last; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first time after t, rounded down to the minute, at which the schedule matches.
+// It returns the zero time if the schedule never matches.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(cronSearchLimit)
+
+	for next.Before(limit) {
+		switch {
+		case s.months&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hours&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minutes&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+
+	dayMatches := s.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := s.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if s.daysRestricted && s.weekdaysRestricted {
+		return dayMatches || weekdayMatches
+	}
+	return dayMatches && weekdayMatches
+}
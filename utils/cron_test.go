@@ -0,0 +1,51 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+
+	// Sunday, April 1st 2018
+	start := time.Date(2018, 4, 1, 10, 7, 30, 0, time.UTC)
+
+	for _, test := range []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2018, 4, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2018, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2018, 4, 1, 11, 5, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, 4, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2018, 4, 2, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2018, 4, 2, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 6,7", time.Date(2018, 4, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * *", time.Date(2018, 4, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2018, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either the day of month or the day of week may match when both are restricted
+		{"0 0 20 * 3", time.Date(2018, 4, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		schedule, err := ParseCronSchedule(test.spec)
+		if err != nil {
+			t.Errorf("Could not parse %s: %v", test.spec, err)
+			continue
+		}
+		if next := schedule.Next(start); !next.Equal(test.expected) {
+			t.Errorf("Expected %s to next run at %v, got %v.", test.spec, test.expected, next)
+		}
+	}
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@sometimes"} {
+		if _, err := ParseCronSchedule(spec); err == nil {
+			t.Errorf("Expected an error parsing %q.", spec)
+		}
+	}
+}
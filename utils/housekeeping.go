@@ -0,0 +1,190 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HousekeepingTask is background work that a HousekeepingScheduler runs periodically.
+type HousekeepingTask struct {
+	// Name identifies the task in logs, and must be unique within its scheduler
+	Name string
+	// Interval is the time between runs; a task without one runs just once
+	Interval time.Duration
+	// InitialDelay is the time before the first run
+	InitialDelay time.Duration
+	// Jitter is the most by which each delay is randomly extended, so that the same task in
+	// many schedulers, such as one per backend, doesn't run everywhere at once
+	Jitter time.Duration
+	// RunOnStop runs the task one last time when the scheduler stops
+	RunOnStop bool
+	// Run does the work
+	Run func()
+}
+
+// HousekeepingScheduler runs named periodic tasks, each in its own goroutine.  A task that panics
+// is logged and run again at its next interval, and stopping the scheduler waits for any running
+// tasks to finish.
+type HousekeepingScheduler struct {
+	name    string
+	tasks   map[string]*scheduledTask
+	mutex   *sync.Mutex
+	wg      *sync.WaitGroup
+	done    chan struct{}
+	stopped bool
+}
+
+type scheduledTask struct {
+	HousekeepingTask
+	cancel chan struct{}
+}
+
+// NewHousekeepingScheduler returns a scheduler without any tasks.  The name identifies the owner
+// of the scheduler, such as a backend, in logs.
+func NewHousekeepingScheduler(name string) *HousekeepingScheduler {
+	return &HousekeepingScheduler{
+		name:  name,
+		tasks: make(map[string]*scheduledTask),
+		mutex: &sync.Mutex{},
+		wg:    &sync.WaitGroup{},
+		done:  make(chan struct{}),
+	}
+}
+
+// Schedule starts running a task.
+func (s *HousekeepingScheduler) Schedule(task HousekeepingTask) error {
+
+	if task.Name == "" || task.Run == nil {
+		return fmt.Errorf("housekeeping task must have a name and a function")
+	}
+	if task.Interval < 0 || task.InitialDelay < 0 || task.Jitter < 0 {
+		return fmt.Errorf("housekeeping task %s may not have a negative interval, delay or jitter", task.Name)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return fmt.Errorf("housekeeping scheduler %s is stopped", s.name)
+	}
+	if _, ok := s.tasks[task.Name]; ok {
+		return fmt.Errorf("housekeeping task %s is already scheduled", task.Name)
+	}
+
+	t := &scheduledTask{HousekeepingTask: task, cancel: make(chan struct{})}
+	s.tasks[task.Name] = t
+	s.wg.Add(1)
+	go s.loop(t)
+
+	log.WithFields(log.Fields{
+		"scheduler":    s.name,
+		"task":         task.Name,
+		"interval":     task.Interval,
+		"initialDelay": task.InitialDelay,
+		"jitter":       task.Jitter,
+	}).Debug("Scheduled housekeeping task.")
+
+	return nil
+}
+
+// Cancel stops running a task, without running it again even if it runs on stop.  A run in
+// progress is allowed to finish.
+func (s *HousekeepingScheduler) Cancel(name string) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t, ok := s.tasks[name]; ok {
+		close(t.cancel)
+		delete(s.tasks, name)
+	}
+}
+
+// Stop stops all tasks, waits for any in progress to finish, and then runs once more those that
+// run on stop.  A stopped scheduler accepts no more tasks.
+func (s *HousekeepingScheduler) Stop() {
+
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		return
+	}
+	s.stopped = true
+	close(s.done)
+	tasks := make([]*scheduledTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+
+	for _, t := range tasks {
+		if t.RunOnStop {
+			s.run(t)
+		}
+	}
+	log.WithField("scheduler", s.name).Debug("Stopped housekeeping.")
+}
+
+func (s *HousekeepingScheduler) loop(t *scheduledTask) {
+
+	defer s.wg.Done()
+
+	delay := t.InitialDelay
+	for {
+		timer := time.NewTimer(jitter(delay, t.Jitter))
+		select {
+		case <-timer.C:
+		case <-t.cancel:
+			timer.Stop()
+			return
+		case <-s.done:
+			timer.Stop()
+			return
+		}
+
+		s.run(t)
+
+		if t.Interval == 0 {
+			return
+		}
+		delay = t.Interval
+	}
+}
+
+// run runs a task once, recovering from any panic so that one faulty task can't take down its
+// owner, much less the whole process.
+func (s *HousekeepingScheduler) run(t *scheduledTask) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{
+				"scheduler": s.name,
+				"task":      t.Name,
+				"panic":     r,
+			}).Errorf("Housekeeping task panicked.\n%s", debug.Stack())
+		}
+	}()
+
+	log.WithFields(log.Fields{
+		"scheduler": s.name,
+		"task":      t.Name,
+	}).Debug("Performing housekeeping task.")
+
+	t.Run()
+}
+
+func jitter(delay, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return delay
+	}
+	return delay + time.Duration(rand.Int63n(int64(maxJitter)+1))
+}
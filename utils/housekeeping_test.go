@@ -0,0 +1,137 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestHousekeepingSchedulerRunsTasks(t *testing.T) {
+	log.Debug("Running TestHousekeepingSchedulerRunsTasks...")
+
+	s := NewHousekeepingScheduler("test")
+	var periodic, once, final int32
+
+	if err := s.Schedule(HousekeepingTask{
+		Name:     "periodic",
+		Interval: 5 * time.Millisecond,
+		Jitter:   time.Millisecond,
+		Run:      func() { atomic.AddInt32(&periodic, 1) },
+	}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+	if err := s.Schedule(HousekeepingTask{
+		Name: "once",
+		Run:  func() { atomic.AddInt32(&once, 1) },
+	}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+	if err := s.Schedule(HousekeepingTask{
+		Name:         "final",
+		InitialDelay: time.Hour,
+		RunOnStop:    true,
+		Run:          func() { atomic.AddInt32(&final, 1) },
+	}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	if atomic.LoadInt32(&periodic) < 2 {
+		t.Errorf("Expected the periodic task to run repeatedly, it ran %d times.", periodic)
+	}
+	if once != 1 {
+		t.Errorf("Expected the task without an interval to run once, it ran %d times.", once)
+	}
+	if final != 1 {
+		t.Errorf("Expected the task to run once on stop, it ran %d times.", final)
+	}
+
+	// Nothing runs after the scheduler stops
+	runs := atomic.LoadInt32(&periodic)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&periodic) != runs {
+		t.Error("Expected no runs after the scheduler stopped.")
+	}
+}
+
+func TestHousekeepingSchedulerRecoversFromPanic(t *testing.T) {
+	log.Debug("Running TestHousekeepingSchedulerRecoversFromPanic...")
+
+	s := NewHousekeepingScheduler("test")
+	defer s.Stop()
+
+	runs := make(chan struct{}, 10)
+	if err := s.Schedule(HousekeepingTask{
+		Name:     "faulty",
+		Interval: time.Millisecond,
+		Run: func() {
+			runs <- struct{}{}
+			panic("faulty task")
+		},
+	}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the task to keep running after a panic.")
+		}
+	}
+}
+
+func TestHousekeepingSchedulerCancel(t *testing.T) {
+	log.Debug("Running TestHousekeepingSchedulerCancel...")
+
+	s := NewHousekeepingScheduler("test")
+	var runs int32
+	if err := s.Schedule(HousekeepingTask{
+		Name:         "canceled",
+		Interval:     time.Hour,
+		InitialDelay: time.Hour,
+		RunOnStop:    true,
+		Run:          func() { atomic.AddInt32(&runs, 1) },
+	}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+
+	s.Cancel("canceled")
+	s.Stop()
+	if runs != 0 {
+		t.Errorf("Expected a canceled task not to run on stop, it ran %d times.", runs)
+	}
+}
+
+func TestHousekeepingSchedulerInvalidTasks(t *testing.T) {
+	log.Debug("Running TestHousekeepingSchedulerInvalidTasks...")
+
+	s := NewHousekeepingScheduler("test")
+	noop := func() {}
+
+	if err := s.Schedule(HousekeepingTask{Name: "task", Interval: time.Hour, InitialDelay: time.Hour, Run: noop}); err != nil {
+		t.Fatal("Unable to schedule task: ", err)
+	}
+	for name, task := range map[string]HousekeepingTask{
+		"duplicate":        {Name: "task", Interval: time.Hour, Run: noop},
+		"unnamed":          {Interval: time.Hour, Run: noop},
+		"noFunction":       {Name: "noFunction", Interval: time.Hour},
+		"negativeInterval": {Name: "negativeInterval", Interval: -time.Second, Run: noop},
+	} {
+		if err := s.Schedule(task); err == nil {
+			t.Errorf("Expected an error scheduling the %s task.", name)
+		}
+	}
+
+	s.Stop()
+	s.Stop()
+	if err := s.Schedule(HousekeepingTask{Name: "late", Interval: time.Hour, Run: noop}); err == nil {
+		t.Error("Expected an error scheduling a task after the scheduler stopped.")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job tracks a long-running operation, such as a copy-based clone, that continues in the
+// background after the call that started it has returned.
+type Job struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Target    string   `json:"target"`
+	State     JobState `json:"state"`
+	Progress  int      `json:"progress"` // percent complete
+	Message   string   `json:"message,omitempty"`
+	StartTime string   `json:"startTime"`
+	EndTime   string   `json:"endTime,omitempty"`
+
+	sequence uint64    // orders jobs started within the same second
+	ended    time.Time // when the job finished, if it has
+}
+
+// JobRetention is how long a finished job is kept, so that its result may still be read, before
+// it is forgotten.
+var JobRetention = 24 * time.Hour
+
+var (
+	jobs         = make(map[string]*Job)
+	jobsMutex    = &sync.Mutex{}
+	jobsSequence uint64
+)
+
+// StartJob registers a new running job and returns its ID.  Jobs that finished more than
+// JobRetention ago are forgotten, so that jobs don't accumulate for as long as Trident runs.
+func StartJob(jobType, target string) string {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	pruneJobs()
+
+	jobsSequence++
+	job := &Job{
+		ID:        fmt.Sprintf("%s-%s-%s", jobType, target, RandomString(6)),
+		Type:      jobType,
+		Target:    target,
+		State:     JobRunning,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+		sequence:  jobsSequence,
+	}
+	jobs[job.ID] = job
+
+	log.WithFields(log.Fields{
+		"job":    job.ID,
+		"type":   jobType,
+		"target": target,
+	}).Debug("Started job.")
+
+	return job.ID
+}
+
+// pruneJobs forgets the jobs that finished more than JobRetention ago.  The caller must hold the
+// jobs mutex.
+func pruneJobs() {
+	for id, job := range jobs {
+		if job.State != JobRunning && time.Since(job.ended) > JobRetention {
+			delete(jobs, id)
+		}
+	}
+}
+
+// UpdateJob records the progress of a running job.
+func UpdateJob(id string, progress int, message string) {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	if job, ok := jobs[id]; ok && job.State == JobRunning {
+		job.Progress = progress
+		job.Message = message
+	}
+}
+
+// FinishJob marks a job as succeeded, or as failed if an error is supplied.
+func FinishJob(id string, err error) {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	job, ok := jobs[id]
+	if !ok {
+		return
+	}
+
+	job.ended = time.Now()
+	job.EndTime = job.ended.UTC().Format(time.RFC3339)
+	if err != nil {
+		job.State = JobFailed
+		job.Message = err.Error()
+	} else {
+		job.State = JobSucceeded
+		job.Progress = 100
+		job.Message = ""
+	}
+
+	log.WithFields(log.Fields{
+		"job":   job.ID,
+		"state": job.State,
+	}).Info("Job finished.")
+}
+
+// GetJob returns a copy of the specified job, or nil if it doesn't exist.
+func GetJob(id string) *Job {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	job, ok := jobs[id]
+	if !ok {
+		return nil
+	}
+	jobCopy := *job
+	return &jobCopy
+}
+
+// LatestJob returns a copy of the job of the specified type most recently started on the target,
+// or nil if there is none.
+func LatestJob(jobType, target string) *Job {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	var latest *Job
+	for _, job := range jobs {
+		if job.Type == jobType && job.Target == target && (latest == nil || job.sequence > latest.sequence) {
+			latest = job
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	jobCopy := *latest
+	return &jobCopy
+}
+
+// ListJobs returns copies of all known jobs, ordered by ID.
+func ListJobs() []*Job {
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	list := make([]*Job, 0, len(jobs))
+	for _, job := range jobs {
+		jobCopy := *job
+		list = append(list, &jobCopy)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return list
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	log.Debug("Running TestJobLifecycle...")
+
+	id := StartJob("clone-copy", "vol1")
+
+	job := GetJob(id)
+	if job == nil {
+		t.Fatalf("Expected job %s to exist", id)
+	}
+	if job.State != JobRunning || job.Type != "clone-copy" || job.Target != "vol1" {
+		t.Errorf("Unexpected job after start: %+v", job)
+	}
+
+	UpdateJob(id, 40, "copying")
+	if job = GetJob(id); job.Progress != 40 || job.Message != "copying" {
+		t.Errorf("Unexpected job after update: %+v", job)
+	}
+
+	FinishJob(id, nil)
+	if job = GetJob(id); job.State != JobSucceeded || job.Progress != 100 || job.EndTime == "" {
+		t.Errorf("Unexpected job after finish: %+v", job)
+	}
+
+	// Updates to a finished job are ignored
+	UpdateJob(id, 10, "late")
+	if job = GetJob(id); job.Progress != 100 {
+		t.Errorf("Expected finished job to be unchanged, got %+v", job)
+	}
+}
+
+func TestJobFailure(t *testing.T) {
+	log.Debug("Running TestJobFailure...")
+
+	id := StartJob("clone-copy", "vol2")
+	FinishJob(id, errors.New("copy failed"))
+
+	job := GetJob(id)
+	if job.State != JobFailed || job.Message != "copy failed" {
+		t.Errorf("Unexpected job after failure: %+v", job)
+	}
+
+	if GetJob("missing") != nil {
+		t.Error("Expected nil for unknown job")
+	}
+
+	found := false
+	for _, j := range ListJobs() {
+		if j.ID == id {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected job %s in job list", id)
+	}
+}
+
+func TestLatestJob(t *testing.T) {
+	log.Debug("Running TestLatestJob...")
+
+	first := StartJob("clone-copy", "vol3")
+	FinishJob(first, errors.New("copy failed"))
+	second := StartJob("clone-copy", "vol3")
+	StartJob("migrate", "vol3")
+
+	if job := LatestJob("clone-copy", "vol3"); job == nil || job.ID != second || job.State != JobRunning {
+		t.Errorf("Expected the running job %s, got %+v", second, job)
+	}
+	if LatestJob("clone-copy", "vol4") != nil {
+		t.Error("Expected nil for a target without jobs")
+	}
+}
+
+func TestJobRetention(t *testing.T) {
+	log.Debug("Running TestJobRetention...")
+
+	defer func(retention time.Duration) { JobRetention = retention }(JobRetention)
+	JobRetention = time.Hour
+
+	finished := StartJob("clone-copy", "vol5")
+	FinishJob(finished, nil)
+	running := StartJob("clone-copy", "vol6")
+	count := len(ListJobs())
+
+	// A job that finished within the retention period is kept, like a running job
+	StartJob("clone-copy", "vol7")
+	if GetJob(finished) == nil || len(ListJobs()) != count+1 {
+		t.Errorf("Expected the finished job to be kept, got %d jobs", len(ListJobs()))
+	}
+
+	// Once the retention period has passed, starting another job forgets it
+	JobRetention = 0
+	StartJob("clone-copy", "vol8")
+	if GetJob(finished) != nil {
+		t.Errorf("Expected finished job %s to be forgotten", finished)
+	}
+	if GetJob(running) == nil {
+		t.Errorf("Expected running job %s to be kept", running)
+	}
+	if jobCount := len(ListJobs()); jobCount > count {
+		t.Errorf("Expected fewer than %d jobs once finished jobs expire, got %d", count+1, jobCount)
+	}
+}
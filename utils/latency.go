@@ -0,0 +1,188 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets into which a LatencyTracker sorts durations.
+// Provisioning ranges from well under a second to minutes for clones and busy storage.
+var LatencyBuckets = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+}
+
+// LatencyHistogram is the distribution of the durations of one kind of operation.
+type LatencyHistogram struct {
+	// Buckets counts the durations at most each of LatencyBuckets, cumulatively
+	Buckets []uint64
+	// Count is the number of durations, including those longer than the last bucket
+	Count uint64
+	// Sum is the total of the durations
+	Sum time.Duration
+	// Slow is the number of durations longer than the tracker's threshold
+	Slow uint64
+}
+
+// LatencyTracker keeps a latency histogram for each kind of operation, and counts those longer
+// than a threshold, such as a service level objective.
+type LatencyTracker struct {
+	mutex      *sync.Mutex
+	histograms map[string]*LatencyHistogram
+	threshold  time.Duration
+}
+
+// NewLatencyTracker returns a tracker that counts durations longer than the threshold as slow.
+// A threshold of zero counts none as slow.
+func NewLatencyTracker(threshold time.Duration) *LatencyTracker {
+	return &LatencyTracker{
+		mutex:      &sync.Mutex{},
+		histograms: make(map[string]*LatencyHistogram),
+		threshold:  threshold,
+	}
+}
+
+// SetThreshold changes the duration beyond which operations are slow.
+func (t *LatencyTracker) SetThreshold(threshold time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.threshold = threshold
+}
+
+// Observe adds a duration to an operation's histogram, and returns whether it was slow.
+func (t *LatencyTracker) Observe(operation string, duration time.Duration) bool {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	histogram, ok := t.histograms[operation]
+	if !ok {
+		histogram = &LatencyHistogram{Buckets: make([]uint64, len(LatencyBuckets))}
+		t.histograms[operation] = histogram
+	}
+	for i, bound := range LatencyBuckets {
+		if duration <= bound {
+			histogram.Buckets[i]++
+		}
+	}
+	histogram.Count++
+	histogram.Sum += duration
+
+	slow := t.threshold > 0 && duration > t.threshold
+	if slow {
+		histogram.Slow++
+	}
+	return slow
+}
+
+// Histograms returns a copy of each operation's histogram.
+func (t *LatencyTracker) Histograms() map[string]*LatencyHistogram {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	histograms := make(map[string]*LatencyHistogram, len(t.histograms))
+	for operation, histogram := range t.histograms {
+		histogramCopy := *histogram
+		histogramCopy.Buckets = append([]uint64(nil), histogram.Buckets...)
+		histograms[operation] = &histogramCopy
+	}
+	return histograms
+}
+
+// CallTiming is the time spent in calls of one kind, such as a ZAPI, made during an operation.
+type CallTiming struct {
+	Name  string
+	Count int
+	Total time.Duration
+}
+
+// CallTimings records the calls to storage made during one operation, so that a slow operation
+// can be attributed to the storage or to Trident.
+type CallTimings struct {
+	mutex *sync.Mutex
+	calls map[string]*CallTiming
+}
+
+type callTimingsKey struct{}
+
+// WithCallTimings returns a copy of the context in which RecordCall adds to the returned timings.
+func WithCallTimings(ctx context.Context) (context.Context, *CallTimings) {
+	timings := &CallTimings{mutex: &sync.Mutex{}, calls: make(map[string]*CallTiming)}
+	return context.WithValue(ctx, callTimingsKey{}, timings), timings
+}
+
+// RecordCall adds a call's duration to the timings carried by the context, if it has any.
+func RecordCall(ctx context.Context, name string, duration time.Duration) {
+	if ctx == nil {
+		return
+	}
+	timings, ok := ctx.Value(callTimingsKey{}).(*CallTimings)
+	if !ok {
+		return
+	}
+
+	timings.mutex.Lock()
+	defer timings.mutex.Unlock()
+
+	call, ok := timings.calls[name]
+	if !ok {
+		call = &CallTiming{Name: name}
+		timings.calls[name] = call
+	}
+	call.Count++
+	call.Total += duration
+}
+
+// Calls returns the recorded calls, those that took longest in total first.
+func (c *CallTimings) Calls() []CallTiming {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	calls := make([]CallTiming, 0, len(c.calls))
+	for _, call := range c.calls {
+		calls = append(calls, *call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].Total != calls[j].Total {
+			return calls[i].Total > calls[j].Total
+		}
+		return calls[i].Name < calls[j].Name
+	})
+	return calls
+}
+
+// Total returns the time spent in all the recorded calls.
+func (c *CallTimings) Total() time.Duration {
+	var total time.Duration
+	for _, call := range c.Calls() {
+		total += call.Total
+	}
+	return total
+}
+
+// String summarizes the recorded calls, such as "volume-create: 1 in 2.1s, job-get: 4 in 800ms".
+func (c *CallTimings) String() string {
+	calls := c.Calls()
+	summary := make([]string, 0, len(calls))
+	for _, call := range calls {
+		summary = append(summary, fmt.Sprintf("%s: %d in %v", call.Name, call.Count,
+			call.Total.Round(time.Millisecond)))
+	}
+	return strings.Join(summary, ", ")
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	log.Debug("Running TestLatencyTracker...")
+
+	tracker := NewLatencyTracker(10 * time.Second)
+	if tracker.Observe("create", 400*time.Millisecond) {
+		t.Error("Expected a fast create not to be slow.")
+	}
+	if !tracker.Observe("create", 20*time.Second) {
+		t.Error("Expected a create longer than the threshold to be slow.")
+	}
+	tracker.Observe("create", time.Hour)
+	tracker.Observe("delete", time.Second)
+
+	histograms := tracker.Histograms()
+	create := histograms["create"]
+	if create == nil || create.Count != 3 || create.Slow != 2 || create.Sum != time.Hour+20400*time.Millisecond {
+		t.Fatalf("Unexpected create histogram %+v.", create)
+	}
+	// 400ms falls in the 500ms bucket and every later one; 20s from the 30s bucket on
+	expected := []uint64{0, 1, 1, 1, 1, 1, 2, 2, 2, 2}
+	for i, count := range expected {
+		if create.Buckets[i] != count {
+			t.Errorf("Expected %d creates within %v, got %d.", count, LatencyBuckets[i], create.Buckets[i])
+		}
+	}
+	if histograms["delete"] == nil || histograms["delete"].Count != 1 {
+		t.Errorf("Unexpected delete histogram %+v.", histograms["delete"])
+	}
+
+	// The copies are unaffected by later observations
+	tracker.Observe("create", time.Millisecond)
+	if create.Count != 3 || create.Buckets[0] != 0 {
+		t.Error("Expected the histograms returned to be copies.")
+	}
+
+	tracker.SetThreshold(0)
+	if tracker.Observe("create", time.Hour) {
+		t.Error("Expected no operation to be slow without a threshold.")
+	}
+}
+
+func TestCallTimings(t *testing.T) {
+	log.Debug("Running TestCallTimings...")
+
+	// Calls outside an operation are ignored
+	RecordCall(context.Background(), "volume-get-iter", time.Second)
+	RecordCall(nil, "volume-get-iter", time.Second)
+
+	ctx, timings := WithCallTimings(context.Background())
+	child, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	RecordCall(child, "job-get", 200*time.Millisecond)
+	RecordCall(child, "volume-create", 2*time.Second)
+	RecordCall(ctx, "job-get", 300*time.Millisecond)
+
+	calls := timings.Calls()
+	if len(calls) != 2 || calls[0].Name != "volume-create" || calls[1].Name != "job-get" ||
+		calls[1].Count != 2 || calls[1].Total != 500*time.Millisecond {
+		t.Errorf("Unexpected calls %+v.", calls)
+	}
+	if timings.Total() != 2500*time.Millisecond {
+		t.Errorf("Expected 2.5s of calls, got %v.", timings.Total())
+	}
+	if summary := timings.String(); summary != "volume-create: 1 in 2s, job-get: 2 in 500ms" {
+		t.Errorf("Unexpected summary %s.", summary)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const multipathConfFile = "/etc/multipath.conf"
+
+// MultipathDeviceType identifies a kind of storage device to multipath, along with the settings
+// its vendor recommends.  A device section in multipath.conf that overrides one of those settings
+// with another value is reported as a problem.
+type MultipathDeviceType struct {
+	Vendor              string
+	Product             string
+	RecommendedSettings map[string]string
+}
+
+var (
+	// OntapMultipathDevice describes ONTAP LUNs, which should be grouped by ALUA priority and
+	// queue I/O while all paths are down, as the built-in multipath defaults for them do.
+	OntapMultipathDevice = MultipathDeviceType{
+		Vendor:  "NETAPP",
+		Product: "LUN",
+		RecommendedSettings: map[string]string{
+			"path_grouping_policy": "group_by_prio",
+			"no_path_retry":        "queue",
+		},
+	}
+
+	// ESeriesMultipathDevice describes E-Series volumes, for which the built-in defaults suffice.
+	ESeriesMultipathDevice = MultipathDeviceType{Vendor: "NETAPP", Product: "INF-01-00"}
+
+	// SolidFireMultipathDevice describes SolidFire volumes, for which the built-in defaults suffice.
+	SolidFireMultipathDevice = MultipathDeviceType{Vendor: "SolidFir", Product: "SSD SAN"}
+)
+
+// multipathConfSection is one section of multipath.conf, such as "defaults", "blacklist" or
+// a "device" within "devices", holding its settings in the order they appear.
+type multipathConfSection struct {
+	Name     string
+	Settings [][2]string
+	Sections []*multipathConfSection
+}
+
+// Get returns the last value of a setting in a section, or an empty string.
+func (s *multipathConfSection) Get(key string) string {
+	value := ""
+	for _, setting := range s.Settings {
+		if setting[0] == key {
+			value = setting[1]
+		}
+	}
+	return value
+}
+
+// Children returns the sections of a given name within a section.
+func (s *multipathConfSection) Children(name string) []*multipathConfSection {
+	children := make([]*multipathConfSection, 0)
+	for _, section := range s.Sections {
+		if section.Name == name {
+			children = append(children, section)
+		}
+	}
+	return children
+}
+
+// matches returns true if a device section's vendor and product patterns match a device type.
+func (s *multipathConfSection) matches(device MultipathDeviceType) bool {
+	return multipathPatternMatches(s.Get("vendor"), device.Vendor) &&
+		multipathPatternMatches(s.Get("product"), device.Product)
+}
+
+// multipathPatternMatches returns true if a multipath.conf regular expression matches a value.
+// A missing pattern matches anything, as does the common idiom "*".
+func multipathPatternMatches(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*") {
+		pattern = "." + pattern
+	}
+	matched, err := regexp.MatchString(pattern, value)
+	return err == nil && matched
+}
+
+// parseMultipathConf parses the contents of multipath.conf into its sections.  The returned
+// section is unnamed and holds the top-level sections.
+func parseMultipathConf(contents string) (*multipathConfSection, error) {
+
+	root := &multipathConfSection{}
+	stack := []*multipathConfSection{root}
+
+	for lineNumber, line := range strings.Split(contents, "\n") {
+
+		// Strip comments, which begin with # or !
+		if i := strings.IndexAny(line, "#!"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		current := stack[len(stack)-1]
+		switch {
+		case fields[0] == "}":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected '}' on line %d", lineNumber+1)
+			}
+			stack = stack[:len(stack)-1]
+		case len(fields) == 2 && fields[1] == "{":
+			section := &multipathConfSection{Name: fields[0]}
+			current.Sections = append(current.Sections, section)
+			stack = append(stack, section)
+		default:
+			value := strings.Trim(strings.Join(fields[1:], " "), "\"")
+			current.Settings = append(current.Settings, [2]string{fields[0], value})
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("section %s is not closed", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// multipathConfProblems returns the ways a parsed multipath.conf would keep devices of the given
+// type from being assembled into multipath devices, or would depart from the vendor's
+// recommendations for them, each with the change that would fix it.
+func multipathConfProblems(conf *multipathConfSection, device MultipathDeviceType) []string {
+
+	problems := make([]string, 0)
+
+	excepted := false
+	for _, exceptions := range conf.Children("blacklist_exceptions") {
+		for _, d := range exceptions.Children("device") {
+			if d.matches(device) {
+				excepted = true
+			}
+		}
+	}
+
+	if !excepted {
+		for _, blacklist := range conf.Children("blacklist") {
+			for _, setting := range blacklist.Settings {
+				if (setting[0] == "devnode" && multipathPatternMatches(setting[1], "sdb")) ||
+					(setting[0] == "wwid" && (setting[1] == "*" || setting[1] == ".*")) {
+					problems = append(problems, fmt.Sprintf(
+						"the blacklist entry '%s \"%s\"' excludes %s %s devices from multipath; remove it, or "+
+							"add a device with vendor \"%s\" and product \"%s\" to blacklist_exceptions",
+						setting[0], setting[1], device.Vendor, device.Product, device.Vendor, device.Product))
+				}
+			}
+			for _, d := range blacklist.Children("device") {
+				if d.matches(device) {
+					problems = append(problems, fmt.Sprintf(
+						"the blacklist device with vendor \"%s\" and product \"%s\" excludes %s %s devices "+
+							"from multipath; remove it from the blacklist section",
+						d.Get("vendor"), d.Get("product"), device.Vendor, device.Product))
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(device.RecommendedSettings))
+	for key := range device.RecommendedSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, devices := range conf.Children("devices") {
+		for _, d := range devices.Children("device") {
+			if !d.matches(device) {
+				continue
+			}
+			for _, key := range keys {
+				recommended := device.RecommendedSettings[key]
+				if value := d.Get(key); value != "" && value != recommended {
+					problems = append(problems, fmt.Sprintf(
+						"the device section for vendor \"%s\" sets %s to \"%s\"; set it to \"%s\", "+
+							"or remove it to use the built-in default", d.Get("vendor"), key, value, recommended))
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// CheckMultipathConfiguration inspects this host's multipath setup before iSCSI devices of the
+// given type are attached.  It returns a description of each problem found, along with how to fix
+// it, since a host that silently attaches LUNs over a single path survives only until that path
+// fails.
+func CheckMultipathConfiguration(device MultipathDeviceType) []string {
+
+	log.WithField("vendor", device.Vendor).Debug(">>>> multipath.CheckMultipathConfiguration")
+	defer log.Debug("<<<< multipath.CheckMultipathConfiguration")
+
+	problems := make([]string, 0)
+
+	if !multipathdIsRunning() {
+		problems = append(problems, "multipathd is not running; install the device-mapper-multipath "+
+			"(RHEL/CentOS) or multipath-tools (Ubuntu/Debian) package and start the multipathd service")
+	}
+
+	contents, err := ioutil.ReadFile(multipathConfFile)
+	if os.IsNotExist(err) {
+		return append(problems, fmt.Sprintf("%s does not exist; create it with 'defaults { "+
+			"user_friendly_names yes find_multipaths yes }' and restart multipathd", multipathConfFile))
+	} else if err != nil {
+		return append(problems, fmt.Sprintf("could not read %s: %v", multipathConfFile, err))
+	}
+
+	conf, err := parseMultipathConf(string(contents))
+	if err != nil {
+		return append(problems, fmt.Sprintf("could not parse %s: %v", multipathConfFile, err))
+	}
+
+	return append(problems, multipathConfProblems(conf, device)...)
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestParseMultipathConf(t *testing.T) {
+	log.Debug("Running TestParseMultipathConf...")
+
+	conf, err := parseMultipathConf(`
+# A comment
+defaults {
+    user_friendly_names yes
+    find_multipaths     yes   ! another comment
+}
+devices {
+    device {
+        vendor  "NETAPP"
+        product "LUN.*"
+        path_grouping_policy multibus
+    }
+}
+`)
+	if err != nil {
+		t.Fatalf("Could not parse multipath.conf: %v", err)
+	}
+
+	defaults := conf.Children("defaults")
+	if len(defaults) != 1 || defaults[0].Get("find_multipaths") != "yes" {
+		t.Errorf("Expected find_multipaths yes in defaults, got %+v", defaults)
+	}
+	devices := conf.Children("devices")
+	if len(devices) != 1 || len(devices[0].Children("device")) != 1 {
+		t.Fatalf("Expected one device in devices, got %+v", devices)
+	}
+	device := devices[0].Children("device")[0]
+	if device.Get("vendor") != "NETAPP" || device.Get("product") != "LUN.*" {
+		t.Errorf("Expected quotes to be stripped from vendor and product, got %+v", device.Settings)
+	}
+
+	for _, bad := range []string{"defaults {\n", "defaults {\n}\n}\n"} {
+		if _, err := parseMultipathConf(bad); err == nil {
+			t.Errorf("Expected error parsing %q", bad)
+		}
+	}
+}
+
+func TestMultipathConfProblems(t *testing.T) {
+	log.Debug("Running TestMultipathConfProblems...")
+
+	tests := []struct {
+		name     string
+		conf     string
+		problems []string
+	}{
+		{"defaults only", "defaults {\n user_friendly_names yes\n}\n", nil},
+		{"blacklist all devnodes", "blacklist {\n devnode \"*\"\n}\n", []string{"devnode"}},
+		{"blacklist all wwids", "blacklist {\n wwid .*\n}\n", []string{"wwid"}},
+		{"blacklist local disks only", "blacklist {\n devnode \"^hd[a-z]\"\n}\n", nil},
+		{
+			"blacklist with exception",
+			"blacklist {\n devnode \"*\"\n}\nblacklist_exceptions {\n device {\n vendor NETAPP\n product LUN\n }\n}\n",
+			nil,
+		},
+		{
+			"blacklisted vendor",
+			"blacklist {\n device {\n vendor NETAPP\n product \".*\"\n }\n}\n",
+			[]string{"remove it from the blacklist"},
+		},
+		{
+			"overridden settings",
+			"devices {\n device {\n vendor NETAPP\n product LUN\n path_grouping_policy multibus\n no_path_retry fail\n }\n}\n",
+			[]string{"no_path_retry", "path_grouping_policy"},
+		},
+		{
+			"recommended settings",
+			"devices {\n device {\n vendor NETAPP\n product LUN\n path_grouping_policy group_by_prio\n }\n}\n",
+			nil,
+		},
+		{
+			"other vendor",
+			"devices {\n device {\n vendor SolidFir\n path_grouping_policy multibus\n }\n}\n",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		conf, err := parseMultipathConf(test.conf)
+		if err != nil {
+			t.Errorf("%s: could not parse multipath.conf: %v", test.name, err)
+			continue
+		}
+		problems := multipathConfProblems(conf, OntapMultipathDevice)
+		if len(problems) != len(test.problems) {
+			t.Errorf("%s: expected %d problems, got %v", test.name, len(test.problems), problems)
+			continue
+		}
+		for i, expected := range test.problems {
+			if !strings.Contains(problems[i], expected) {
+				t.Errorf("%s: expected problem to mention %s, got %s", test.name, expected, problems[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultAllowedMountOptions are the mount options users may give, such as in Kubernetes storage
+// classes and PVC annotations, unless more are allowed with SetAllowedMountOptions.  They cover
+// NFS versions, transports, timeouts and caching, and the generic options that restrict a mount.
+var DefaultAllowedMountOptions = []string{
+	"nfsvers", "vers", "minorversion", "proto", "tcp", "udp", "port", "mountport", "mountproto", "mountvers",
+	"nconnect", "sec", "hard", "soft", "intr", "nointr", "timeo", "retrans", "retry", "bg", "fg", "rsize", "wsize",
+	"ac", "noac", "actimeo", "acregmin", "acregmax", "acdirmin", "acdirmax", "lookupcache", "cto", "nocto",
+	"sharecache", "nosharecache", "fsc", "nofsc", "resvport", "noresvport",
+	"ro", "rw", "noatime", "atime", "nodiratime", "diratime", "relatime", "norelatime", "strictatime",
+	"nosuid", "nodev", "noexec", "sync", "async", "discard", "nodiscard", "_netdev",
+}
+
+// DeniedMountOptions are never accepted from users unless allowed by name.  They weaken the
+// host's security, such as by honoring setuid programs or device files on the volume, risk the
+// volume's data, such as by overriding NFS locking, or change what is mounted rather than how.
+var DeniedMountOptions = []string{
+	"nolock", "lock", "local_lock", "suid", "dev", "exec", "defaults", "user", "users", "owner", "group",
+	"remount", "bind", "rbind", "move", "loop", "context", "fscontext", "defcontext", "rootcontext",
+}
+
+// AllowAnyMountOption, given to SetAllowedMountOptions, allows any option that isn't denied.
+const AllowAnyMountOption = "*"
+
+var (
+	// mountOptionRegex matches an option and its value, if any, so that an option can't be taken
+	// for a command-line flag or carry more than one option
+	mountOptionRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(=[A-Za-z0-9_.:/@+-]*)?$`)
+
+	mountOptionsLock    sync.RWMutex
+	extraMountOptions   []string
+	allowAnyMountOption bool
+)
+
+// SetAllowedMountOptions allows users to give the named mount options in addition to the
+// defaults.  Including AllowAnyMountOption allows any option that isn't denied; denied options
+// are only allowed by naming them.
+func SetAllowedMountOptions(names []string) {
+
+	mountOptionsLock.Lock()
+	defer mountOptionsLock.Unlock()
+
+	extraMountOptions = make([]string, 0, len(names))
+	allowAnyMountOption = false
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case AllowAnyMountOption:
+			allowAnyMountOption = true
+		default:
+			extraMountOptions = append(extraMountOptions, name)
+		}
+	}
+	if len(extraMountOptions) > 0 || allowAnyMountOption {
+		log.WithFields(log.Fields{
+			"options": strings.Join(extraMountOptions, ","),
+			"any":     allowAnyMountOption,
+		}).Info("Allowing additional mount options.")
+	}
+}
+
+// ParseMountOptions splits mount options separated by commas, as in a PVC annotation.
+func ParseMountOptions(options string) []string {
+	parsed := make([]string, 0)
+	for _, option := range strings.Split(options, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			parsed = append(parsed, option)
+		}
+	}
+	return parsed
+}
+
+// ValidateMountOptions checks mount options given by a user, returning an error naming the first
+// that is malformed, denied or not allowed.
+func ValidateMountOptions(options []string) error {
+
+	mountOptionsLock.RLock()
+	defer mountOptionsLock.RUnlock()
+
+	for _, option := range options {
+		if !mountOptionRegex.MatchString(option) {
+			return fmt.Errorf("invalid mount option '%s'", option)
+		}
+		name := strings.SplitN(option, "=", 2)[0]
+		if containsString(extraMountOptions, name) {
+			continue
+		}
+		if containsString(DeniedMountOptions, name) {
+			return fmt.Errorf("mount option %s is not allowed, as it may weaken the host's security or "+
+				"risk the volume's data", name)
+		}
+		if !allowAnyMountOption && !containsString(DefaultAllowedMountOptions, name) {
+			return fmt.Errorf("mount option %s is not allowed", name)
+		}
+	}
+	return nil
+}
+
+// MountNFS mounts the supplied NFS export, given as server:/path, at the supplied location.  The
+// options are given as to the mount command, such as "-o nfsvers=3".  They are parsed rather than
+// passed through a shell, and only -o and -t are accepted, so they can't add other commands or
+// flags to the mount.
+func MountNFS(exportPath, mountpoint, options string) error {
+
+	log.WithFields(log.Fields{
+		"exportPath": exportPath,
+		"mountpoint": mountpoint,
+		"options":    options,
+	}).Debug(">>>> nfs.MountNFS")
+	defer log.Debug("<<<< nfs.MountNFS")
+
+	args, err := nfsMountArgs(runtime.GOOS, exportPath, mountpoint, options)
+	if err != nil {
+		return err
+	}
+	if out, err := execCommand("mount", args...); err != nil {
+		log.WithField("output", string(out)).Debug("Mount failed.")
+		return fmt.Errorf("error mounting NFS volume %v on mountpoint %v: %v", exportPath, mountpoint, err)
+	}
+	return nil
+}
+
+// nfsMountArgs returns the arguments of the mount command that mounts an NFS export.
+func nfsMountArgs(goos, exportPath, mountpoint, options string) ([]string, error) {
+
+	var args []string
+	switch goos {
+	case Linux:
+		args = []string{"-v"}
+	case Darwin:
+		args = []string{"-v", "-o", "rw"}
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %v", goos)
+	}
+
+	optionArgs, err := parseNFSMountOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, optionArgs...)
+	if goos == Darwin {
+		args = append(args, "-t", "nfs")
+	}
+
+	return append(args, exportPath, mountpoint), nil
+}
+
+// ValidateNFSMountOptions checks the NFS mount options of a backend, so that options MountNFS
+// would refuse are reported when the backend is added rather than when a volume is mounted.
+func ValidateNFSMountOptions(options string) error {
+	_, err := parseNFSMountOptions(options)
+	return err
+}
+
+// parseNFSMountOptions splits NFS mount options, given as to the mount command, into arguments,
+// accepting only -o and -t.
+func parseNFSMountOptions(options string) ([]string, error) {
+
+	args := make([]string, 0)
+	fields := strings.Fields(options)
+	for i := 0; i < len(fields); i++ {
+		flag := fields[i]
+		if flag != "-o" && flag != "-t" {
+			return nil, fmt.Errorf("invalid NFS mount options '%s'; only -o and -t may be given", options)
+		}
+		if i+1 == len(fields) {
+			return nil, fmt.Errorf("invalid NFS mount options '%s'; %s needs a value", options, flag)
+		}
+		i++
+		value := fields[i]
+		if flag == "-t" {
+			if value != "nfs" && value != "nfs4" {
+				return nil, fmt.Errorf("invalid NFS mount options '%s'; the type must be nfs or nfs4", options)
+			}
+		} else {
+			for _, option := range strings.Split(value, ",") {
+				if !mountOptionRegex.MatchString(option) {
+					return nil, fmt.Errorf("invalid NFS mount option '%s'", option)
+				}
+			}
+		}
+		args = append(args, flag, value)
+	}
+	return args, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestValidateMountOptions(t *testing.T) {
+	log.Debug("Running TestValidateMountOptions...")
+
+	defer SetAllowedMountOptions(nil)
+
+	if err := ValidateMountOptions([]string{"nfsvers=4.1", "hard", "nosuid", "timeo=600", "sec=krb5p"}); err != nil {
+		t.Errorf("Expected default mount options to be allowed: %v", err)
+	}
+	for _, option := range []string{"nolock", "suid", "exec", "remount", "-o", "nfsvers=3,nolock", "ro;reboot",
+		"vers=3 -o nolock", "fstype"} {
+		if err := ValidateMountOptions([]string{option}); err == nil {
+			t.Errorf("Expected mount option %q to be rejected", option)
+		}
+	}
+
+	SetAllowedMountOptions([]string{"fstype", " nolock", ""})
+	if err := ValidateMountOptions([]string{"fstype=x", "nolock"}); err != nil {
+		t.Errorf("Expected named mount options to be allowed: %v", err)
+	}
+	if err := ValidateMountOptions([]string{"sloppy"}); err == nil {
+		t.Error("Expected unnamed mount option to be rejected")
+	}
+
+	SetAllowedMountOptions([]string{AllowAnyMountOption})
+	if err := ValidateMountOptions([]string{"sloppy"}); err != nil {
+		t.Errorf("Expected any mount option to be allowed: %v", err)
+	}
+	if err := ValidateMountOptions([]string{"nolock"}); err == nil {
+		t.Error("Expected denied mount option to be rejected unless named")
+	}
+}
+
+func TestParseMountOptions(t *testing.T) {
+	log.Debug("Running TestParseMountOptions...")
+
+	for options, expected := range map[string][]string{
+		"":                          {},
+		"nfsvers=3":                 {"nfsvers=3"},
+		" nfsvers=3, hard,,noatime": {"nfsvers=3", "hard", "noatime"},
+	} {
+		if parsed := ParseMountOptions(options); !reflect.DeepEqual(parsed, expected) {
+			t.Errorf("Expected %q to be parsed as %v, got %v", options, expected, parsed)
+		}
+	}
+}
+
+func TestNFSMountArgs(t *testing.T) {
+	log.Debug("Running TestNFSMountArgs...")
+
+	for _, test := range []struct {
+		goos, options string
+		expected      []string
+	}{
+		{Linux, "", []string{"-v", "1.2.3.4:/vol", "/mnt"}},
+		{Linux, "-o nfsvers=3,hard -t nfs4", []string{"-v", "-o", "nfsvers=3,hard", "-t", "nfs4", "1.2.3.4:/vol", "/mnt"}},
+		{Darwin, "-o vers=3", []string{"-v", "-o", "rw", "-o", "vers=3", "-t", "nfs", "1.2.3.4:/vol", "/mnt"}},
+	} {
+		args, err := nfsMountArgs(test.goos, "1.2.3.4:/vol", "/mnt", test.options)
+		if err != nil {
+			t.Errorf("Expected options %q to be valid: %v", test.options, err)
+		} else if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("Expected mount arguments %v for %q, got %v", test.expected, test.options, args)
+		}
+	}
+
+	for _, options := range []string{"-o nfsvers=3; rm -rf /", "-x", "-o", "-t cifs", "-o vers=3,$(reboot)"} {
+		if _, err := nfsMountArgs(Linux, "1.2.3.4:/vol", "/mnt", options); err == nil {
+			t.Errorf("Expected options %q to be rejected", options)
+		}
+	}
+	if _, err := nfsMountArgs("windows", "1.2.3.4:/vol", "/mnt", ""); err == nil {
+		t.Error("Expected an unsupported operating system to be rejected")
+	}
+}
+
+func TestValidateNFSMountOptions(t *testing.T) {
+	log.Debug("Running TestValidateNFSMountOptions...")
+
+	for _, options := range []string{"", "-o nfsvers=3", "-o nfsvers=4.1,hard -t nfs4"} {
+		if err := ValidateNFSMountOptions(options); err != nil {
+			t.Errorf("Expected options %q to be valid: %v", options, err)
+		}
+	}
+	for _, options := range []string{"nfsvers=3", "-o nfsvers=3 -s", "-t", "-t cifs", "-o vers=3;reboot"} {
+		if err := ValidateNFSMountOptions(options); err == nil {
+			t.Errorf("Expected options %q to be rejected", options)
+		}
+	}
+}
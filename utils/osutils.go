@@ -3,13 +3,16 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"syscall"
@@ -78,6 +81,32 @@ func GetDFOutput() ([]DFInfo, error) {
 	return result, nil
 }
 
+// IsMounted returns true if a volume is mounted at a location.  Windows hosts reach volumes
+// through links to SMB shares rather than mounts, so there a link stands for a mount.
+func IsMounted(mountpoint string) (bool, error) {
+
+	if runtime.GOOS == Windows {
+		info, err := os.Lstat(mountpoint)
+		if os.IsNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return info.Mode()&os.ModeSymlink != 0, nil
+	}
+
+	dfOutput, err := GetDFOutput()
+	if err != nil {
+		return false, err
+	}
+	for _, e := range dfOutput {
+		if e.Target == mountpoint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetInitiatorIqns returns parsed contents of /etc/iscsi/initiatorname.iscsi
 func GetInitiatorIqns() ([]string, error) {
 
@@ -148,8 +177,9 @@ func GetDevicesForLUN(paths []string) ([]string, error) {
 }
 
 // RescanTargetAndWaitForDevice rescans all paths to a specific LUN and waits until all
-// SCSI disk-by-path devices for that LUN are present on the host.
-func RescanTargetAndWaitForDevice(lunID int, iSCSINodeName string) error {
+// SCSI disk-by-path devices for that LUN are present on the host.  A zero timeout means
+// the default of iSCSIDeviceDiscoveryTimeoutSecs.
+func RescanTargetAndWaitForDevice(lunID int, iSCSINodeName string, timeout time.Duration) error {
 
 	fields := log.Fields{
 		"lunID":         lunID,
@@ -201,6 +231,14 @@ func RescanTargetAndWaitForDevice(lunID int, iSCSINodeName string) error {
 	deviceBackoff.RandomizationFactor = 0.1
 	deviceBackoff.MaxElapsedTime = 5 * time.Second
 
+	if timeout <= 0 {
+		timeout = iSCSIDeviceDiscoveryTimeoutSecs * time.Second
+	}
+	remaining := timeout - deviceBackoff.MaxElapsedTime
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+
 	if err := backoff.RetryNotify(checkAllDevicesExist, deviceBackoff, devicesNotify); err == nil {
 		log.Debugf("Paths found: %v", found)
 		return nil
@@ -232,11 +270,11 @@ func RescanTargetAndWaitForDevice(lunID int, iSCSINodeName string) error {
 	deviceBackoff.InitialInterval = 1 * time.Second
 	deviceBackoff.Multiplier = 1.414 // approx sqrt(2)
 	deviceBackoff.RandomizationFactor = 0.1
-	deviceBackoff.MaxElapsedTime = (iSCSIDeviceDiscoveryTimeoutSecs - 5) * time.Second
+	deviceBackoff.MaxElapsedTime = remaining
 
 	// Run the check/rescan using an exponential backoff
 	if err := backoff.RetryNotify(checkAnyDeviceExists, deviceBackoff, devicesNotify); err != nil {
-		log.Warnf("Could not find all devices after %d seconds.", iSCSIDeviceDiscoveryTimeoutSecs)
+		log.Warnf("Could not find all devices after %v.", timeout)
 
 		// In the case of a failure, log info about what devices are present
 		execCommand("ls", "-al", "/dev")
@@ -325,8 +363,9 @@ func GetDeviceInfoForLUN(lunID int, iSCSINodeName string) (*ScsiDeviceInfo, erro
 	return info, nil
 }
 
-// WaitForMultiPathDevice
-func WaitForMultiPathDevice(lunID int, iSCSINodeName string) error {
+// WaitForMultiPathDevice waits for a multipath device to appear for a LUN.  A zero timeout
+// means the default of multipathDeviceDiscoveryTimeoutSecs.
+func WaitForMultiPathDevice(lunID int, iSCSINodeName string, timeout time.Duration) error {
 	fields := log.Fields{
 		"lunID":         lunID,
 		"iSCSINodeName": iSCSINodeName,
@@ -346,14 +385,20 @@ func WaitForMultiPathDevice(lunID int, iSCSINodeName string) error {
 		return err
 	}
 
-	waitForMultipathDevice(devices)
+	if _, err = waitForMultipathDevice(devices, timeout); err != nil {
+		return fmt.Errorf("multipath device for LUN %d on target %s was not assembled: %v",
+			lunID, iSCSINodeName, err)
+	}
 	return nil
 }
 
-// waitForMultipathDevice accepts a list of sd* device names and waits until
-// a multipath device is present for at least one of those.  It returns the name of the
-// multipath device, or an empty string if multipathd isn't running or there is only one path.
-func waitForMultipathDevice(devices []string) string {
+// waitForMultipathDevice accepts a list of sd* device names and waits until a multipath device
+// has been assembled from all of them.  It returns the name of the multipath device, or an empty
+// string if multipathd isn't running or there is only one path.  If multipathd is running but no
+// multipath device appears, it returns an error, as the host's multipath configuration is broken
+// and I/O would otherwise silently use a single path.  A multipath device that is missing some of
+// the paths is used, with a warning.
+func waitForMultipathDevice(devices []string, timeout time.Duration) (string, error) {
 
 	fields := log.Fields{"devices": devices}
 	log.WithFields(fields).Debug(">>>> osutils.waitForMultipathDevice")
@@ -361,31 +406,40 @@ func waitForMultipathDevice(devices []string) string {
 
 	if len(devices) <= 1 {
 		log.Debugf("Skipping multipath discovery, %d device(s) specified.", len(devices))
-		return ""
+		return "", nil
 	} else if !multipathdIsRunning() {
 		log.Debug("Skipping multipath discovery, multipathd isn't running.")
-		return ""
+		return "", nil
 	}
 
-	maxDuration := multipathDeviceDiscoveryTimeoutSecs * time.Second
+	maxDuration := timeout
+	if maxDuration <= 0 {
+		maxDuration = multipathDeviceDiscoveryTimeoutSecs * time.Second
+	}
 	multipathDevice := ""
+	var missingPaths []string
 
-	checkMultipathDeviceExists := func() error {
+	checkMultipathDeviceAssembled := func() error {
 
+		multipathDevice = ""
 		for _, device := range devices {
 			multipathDevice = findMultipathDeviceForDevice(device)
 			if multipathDevice != "" {
-				return nil
+				break
 			}
 		}
 		if multipathDevice == "" {
 			return errors.New("multipath device not yet present")
 		}
+		missingPaths = multipathMissingPaths(devices, getMultipathDeviceSlaves(multipathDevice))
+		if len(missingPaths) > 0 {
+			return fmt.Errorf("multipath device %s does not yet include %v", multipathDevice, missingPaths)
+		}
 		return nil
 	}
 
 	deviceNotify := func(err error, duration time.Duration) {
-		log.WithField("increment", duration).Debug("Multipath device not yet present, waiting.")
+		log.WithField("increment", duration).Debugf("Multipath device not yet assembled, waiting. %v", err)
 	}
 
 	multipathDeviceBackoff := backoff.NewExponentialBackOff()
@@ -395,12 +449,51 @@ func waitForMultipathDevice(devices []string) string {
 	multipathDeviceBackoff.MaxElapsedTime = maxDuration
 
 	// Run the check/rescan using an exponential backoff
-	if err := backoff.RetryNotify(checkMultipathDeviceExists, multipathDeviceBackoff, deviceNotify); err != nil {
-		log.Warnf("Could not find multipath device after %3.2f seconds.", maxDuration.Seconds())
-	} else {
+	err := backoff.RetryNotify(checkMultipathDeviceAssembled, multipathDeviceBackoff, deviceNotify)
+	if err == nil {
 		log.WithField("multipathDevice", multipathDevice).Debug("Multipath device found.")
+		return multipathDevice, nil
+	} else if multipathDevice != "" {
+		log.WithFields(log.Fields{
+			"multipathDevice": multipathDevice,
+			"missingPaths":    missingPaths,
+		}).Warnf("Multipath device is missing paths after %3.2f seconds.", maxDuration.Seconds())
+		return multipathDevice, nil
+	}
+
+	log.Warnf("Could not find multipath device after %3.2f seconds.", maxDuration.Seconds())
+	return "", err
+}
+
+// getMultipathDeviceSlaves returns the names of the devices, such as sdx, that make up a
+// devicemapper device like dm-0.
+func getMultipathDeviceSlaves(multipathDevice string) []string {
+
+	slaves := make([]string, 0)
+	slavesDir := "/sys/block/" + multipathDevice + "/slaves"
+	if dirs, err := ioutil.ReadDir(slavesDir); err == nil {
+		for _, f := range dirs {
+			slaves = append(slaves, f.Name())
+		}
 	}
-	return multipathDevice
+	return slaves
+}
+
+// multipathMissingPaths returns the devices that aren't among the slaves of a multipath device.
+func multipathMissingPaths(devices, slaves []string) []string {
+
+	assembled := make(map[string]bool, len(slaves))
+	for _, slave := range slaves {
+		assembled[slave] = true
+	}
+
+	missing := make([]string, 0)
+	for _, device := range devices {
+		if !assembled[device] {
+			missing = append(missing, device)
+		}
+	}
+	return missing
 }
 
 // findMultipathDeviceForDevice finds the devicemapper parent of a device name like /dev/sdx.
@@ -456,6 +549,72 @@ func PrepareDeviceForRemoval(lunID int, iSCSINodeName string) {
 	time.Sleep(time.Second)
 }
 
+// RescanDeviceSizesForLUN makes the host notice a change in the size of an iSCSI LUN, such as after
+// the LUN is resized on its storage.  Each path's SCSI device is rescanned, and then the multipath
+// device, if any, is resized to match, so that the file system on it may be grown.
+func RescanDeviceSizesForLUN(lunID int, iSCSINodeName string) error {
+
+	fields := log.Fields{
+		"lunID":         lunID,
+		"iSCSINodeName": iSCSINodeName,
+	}
+	log.WithFields(fields).Debug(">>>> osutils.RescanDeviceSizesForLUN")
+	defer log.WithFields(fields).Debug("<<<< osutils.RescanDeviceSizesForLUN")
+
+	deviceInfo, err := GetDeviceInfoForLUN(lunID, iSCSINodeName)
+	if err != nil {
+		return fmt.Errorf("could not find devices for LUN %d: %v", lunID, err)
+	}
+
+	for _, deviceName := range deviceInfo.Devices {
+		filename := fmt.Sprintf("/sys/block/%s/device/rescan", deviceName)
+		if err = writeSysfsFile(filename, "1"); err != nil {
+			return fmt.Errorf("could not rescan device %s: %v", deviceName, err)
+		}
+		log.WithField("scanFile", filename).Debug("Invoked device rescan.")
+	}
+
+	if deviceInfo.MultipathDevice != "" {
+		out, err := execCommandWithTimeout("multipathd", 30, "resize", "map", deviceInfo.MultipathDevice)
+		if err != nil {
+			return fmt.Errorf("could not resize multipath device %s: %v; %s", deviceInfo.MultipathDevice, err,
+				strings.TrimSpace(string(out)))
+		}
+		log.WithField("multipathDevice", deviceInfo.MultipathDevice).Debug("Resized multipath device.")
+	}
+
+	return nil
+}
+
+// writeSysfsFile writes a value, such as a scan command, to a sysfs file.
+func writeSysfsFile(filename, value string) error {
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0200)
+	if err != nil {
+		log.WithField("file", filename).Warning("Could not open file for writing.")
+		return err
+	}
+	defer f.Close()
+
+	if written, err := f.WriteString(value); err != nil {
+		log.WithFields(log.Fields{"file": filename, "error": err}).Warning("Could not write to file.")
+		return err
+	} else if written == 0 {
+		log.WithField("file", filename).Warning("No data written to file.")
+		return fmt.Errorf("no data written to %s", filename)
+	}
+	return nil
+}
+
+// NAAWWID formats a LUN's NAA identifier, given as hexadecimal digits, the way Linux reports it
+// in /sys/block/<device>/device/wwid, so that a volume can be matched to its block devices.
+func NAAWWID(naa string) string {
+	if naa == "" {
+		return ""
+	}
+	return "naa." + strings.TrimPrefix(strings.ToLower(naa), "naa.")
+}
+
 // GetDeviceFileFromISCSIPath returns the /dev device for the supplied iSCSIPath.
 func GetDeviceFileFromISCSIPath(iSCSIPath string) string {
 
@@ -998,6 +1157,215 @@ func Umount(mountpoint string) (err error) {
 	return
 }
 
+// parseFilesystemOwner parses the owner of a new file system, given as a numeric uid or as
+// uid:gid.  A gid of -1 leaves the group unchanged, as os.Chown does.
+func parseFilesystemOwner(owner string) (uid, gid int, err error) {
+
+	parts := strings.SplitN(owner, ":", 2)
+	if uid, err = strconv.Atoi(parts[0]); err != nil || uid < 0 {
+		return 0, 0, fmt.Errorf("invalid file system owner %s; it must be a numeric uid or uid:gid", owner)
+	}
+	gid = -1
+	if len(parts) == 2 {
+		if gid, err = strconv.Atoi(parts[1]); err != nil || gid < 0 {
+			return 0, 0, fmt.Errorf("invalid file system owner %s; it must be a numeric uid or uid:gid", owner)
+		}
+	}
+	return uid, gid, nil
+}
+
+// parseFilesystemMode parses the mode of a new file system, given in octal, such as 0775.
+func parseFilesystemMode(mode string) (os.FileMode, error) {
+
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 07777 {
+		return 0, fmt.Errorf("invalid file system mode %s; it must be octal, such as 0775", mode)
+	}
+
+	fileMode := os.FileMode(value & 0777)
+	if value&04000 != 0 {
+		fileMode |= os.ModeSetuid
+	}
+	if value&02000 != 0 {
+		fileMode |= os.ModeSetgid
+	}
+	if value&01000 != 0 {
+		fileMode |= os.ModeSticky
+	}
+	return fileMode, nil
+}
+
+// ValidateFilesystemPermissions checks the owner and mode to be given to the root of a new file
+// system.  Either may be empty, leaving that aspect as mkfs created it.
+func ValidateFilesystemPermissions(owner, mode string) error {
+
+	if owner != "" {
+		if _, _, err := parseFilesystemOwner(owner); err != nil {
+			return err
+		}
+	}
+	if mode != "" {
+		if _, err := parseFilesystemMode(mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetFilesystemPermissions gives the root of a newly formatted and mounted file system the
+// supplied owner and mode, so that containers running as other users than root may write to it.
+func SetFilesystemPermissions(mountpoint, owner, mode string) error {
+
+	log.WithFields(log.Fields{
+		"mountpoint": mountpoint,
+		"owner":      owner,
+		"mode":       mode,
+	}).Debug(">>>> osutils.SetFilesystemPermissions")
+	defer log.Debug("<<<< osutils.SetFilesystemPermissions")
+
+	if owner != "" {
+		uid, gid, err := parseFilesystemOwner(owner)
+		if err != nil {
+			return err
+		}
+		if err = os.Chown(mountpoint, uid, gid); err != nil {
+			return fmt.Errorf("could not set owner of %s: %v", mountpoint, err)
+		}
+	}
+	if mode != "" {
+		fileMode, err := parseFilesystemMode(mode)
+		if err != nil {
+			return err
+		}
+		if err = os.Chmod(mountpoint, fileMode); err != nil {
+			return fmt.Errorf("could not set mode of %s: %v", mountpoint, err)
+		}
+	}
+	return nil
+}
+
+// CopyDirectory recursively copies the contents of one directory into another, preserving
+// permissions, ownership, and symbolic links.  The optional progress function is called after
+// each file with the number of bytes copied so far and the total number of bytes to copy.
+func CopyDirectory(source, destination string, progress func(copied, total int64)) error {
+
+	log.WithFields(log.Fields{
+		"source":      source,
+		"destination": destination,
+	}).Debug(">>>> osutils.CopyDirectory")
+	defer log.Debug("<<<< osutils.CopyDirectory")
+
+	// Size up the job first so progress can be reported as a fraction of the whole
+	var total, copied int64
+	err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not read source directory %s: %v", source, err)
+	}
+
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destination, relPath)
+
+		switch {
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			n, err := copyFile(path, target, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+			copied += n
+			if progress != nil {
+				progress(copied, total)
+			}
+		default:
+			log.WithField("path", path).Warning("Skipping special file.")
+			return nil
+		}
+
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			if err := os.Lchown(target, int(stat.Uid), int(stat.Gid)); err != nil {
+				log.WithField("path", target).Warningf("Could not set ownership. %v", err)
+			}
+		}
+		if info.IsDir() {
+			// MkdirAll is subject to the umask, so set the mode explicitly
+			return os.Chmod(target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+// copyFile copies a single regular file, returning the number of bytes copied.
+func copyFile(source, destination string, mode os.FileMode) (int64, error) {
+
+	in, err := os.Open(source)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// SyncDirectory makes the destination directory a copy of the source with rsync, copying only the
+// files that differ and deleting any the source lacks, so that repeated calls catch up with changes
+// to the source.  The copy is abandoned if the context is done first.
+func SyncDirectory(ctx context.Context, source, destination string) error {
+
+	log.WithFields(log.Fields{
+		"source":      source,
+		"destination": destination,
+	}).Debug(">>>> osutils.SyncDirectory")
+	defer log.Debug("<<<< osutils.SyncDirectory")
+
+	// The trailing slashes make rsync copy the source's contents rather than the directory itself
+	out, err := exec.CommandContext(ctx, "rsync", "--archive", "--hard-links", "--sparse", "--delete",
+		source+"/", destination+"/").CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("could not copy %s to %s: %v; %s", source, destination, err,
+			strings.TrimSpace(sanitizeString(string(out))))
+	}
+	return nil
+}
+
 // LoginISCSITarget logs in to an iSCSI target.
 func LoginISCSITarget(iqn, portal string) error {
 
@@ -1067,10 +1435,19 @@ func LoginWithChap(tiqn, portal, username, password, iface string, logSensitiveI
 	return nil
 }
 
+// EnsureISCSISession logs in to each portal of the iSCSI target at hostDataIP to which this host
+// has no session, so that multipath may use every path to the target.
 func EnsureISCSISession(hostDataIP string) error {
+	return EnsureISCSISessionWithPortals(hostDataIP, nil)
+}
 
-	log.WithField("hostDataIP", hostDataIP).Debug(">>>> osutils.EnsureISCSISession")
-	defer log.Debug("<<<< osutils.EnsureISCSISession")
+// EnsureISCSISessionWithPortals is EnsureISCSISession for hosts that may use only some of the
+// target's portals.  If any portals are listed, the host logs in to no others.
+func EnsureISCSISessionWithPortals(hostDataIP string, allowedPortals []string) error {
+
+	fields := log.Fields{"hostDataIP": hostDataIP, "allowedPortals": allowedPortals}
+	log.WithFields(fields).Debug(">>>> osutils.EnsureISCSISessionWithPortals")
+	defer log.WithFields(fields).Debug("<<<< osutils.EnsureISCSISessionWithPortals")
 
 	// Ensure iSCSI is supported on system
 	if !ISCSISupported() {
@@ -1082,59 +1459,191 @@ func EnsureISCSISession(hostDataIP string) error {
 	if err != nil {
 		return fmt.Errorf("could not check for iSCSI session: %v", err)
 	}
-	if !sessionExists {
 
-		// Run discovery in case we haven't seen this target from this host
-		targets, err := iSCSIDiscovery(hostDataIP)
-		if err != nil {
-			return fmt.Errorf("could not run iSCSI discovery: %v", err)
+	// Always run discovery, since the target may have gained portals since we last logged in
+	targets, err := iSCSIDiscovery(hostDataIP)
+	if err != nil {
+		if sessionExists {
+			log.WithFields(log.Fields{
+				"hostDataIP": hostDataIP,
+				"error":      err,
+			}).Warning("Could not run iSCSI discovery, using the existing session.")
+			return nil
 		}
-		if len(targets) == 0 {
-			return errors.New("iSCSI discovery found no targets")
+		return fmt.Errorf("could not run iSCSI discovery: %v", err)
+	}
+	if len(targets) == 0 {
+		return errors.New("iSCSI discovery found no targets")
+	}
+
+	log.WithFields(log.Fields{
+		"Targets": targets,
+	}).Debug("Found matching iSCSI targets.")
+
+	// Determine which target matches the portal we requested
+	targetName := ""
+	for _, target := range targets {
+		if target.PortalIP == hostDataIP {
+			targetName = target.TargetName
+			break
 		}
+	}
+	if targetName == "" {
+		return fmt.Errorf("iSCSI discovery found no targets with portal %s", hostDataIP)
+	}
 
-		log.WithFields(log.Fields{
-			"Targets": targets,
-		}).Debug("Found matching iSCSI targets.")
-
-		// Determine which target matches the portal we requested
-		targetIndex := -1
-		for i, target := range targets {
-			if target.PortalIP == hostDataIP {
-				targetIndex = i
-				break
+	// To enable multipath, log in to each discovered portal of the same IQN (target name)
+	return loginToISCSIPortals(targetName, hostDataIP, targets, allowedPortals, false)
+}
+
+// EnsureISCSISessionStatic logs in to each of the listed portals of an iSCSI target to which
+// this host has no session, without running discovery, for hosts whose security policies
+// forbid discovery sessions.  The target's node records are created by hand instead.
+func EnsureISCSISessionStatic(targetName, hostDataIP string, portals []string) error {
+
+	fields := log.Fields{"targetName": targetName, "hostDataIP": hostDataIP, "portals": portals}
+	log.WithFields(fields).Debug(">>>> osutils.EnsureISCSISessionStatic")
+	defer log.WithFields(fields).Debug("<<<< osutils.EnsureISCSISessionStatic")
+
+	// Ensure iSCSI is supported on system
+	if !ISCSISupported() {
+		return errors.New("iSCSI support not detected")
+	}
+
+	targets := []ISCSIDiscoveryInfo{{PortalIP: hostDataIP, TargetName: targetName}}
+	for _, portal := range portals {
+		targets = append(targets, ISCSIDiscoveryInfo{PortalIP: portal, TargetName: targetName})
+	}
+
+	return loginToISCSIPortals(targetName, hostDataIP, targets, nil, true)
+}
+
+// loginToISCSIPortals logs in to each portal of an iSCSI target to which this host has no session,
+// first creating the portal's node record if discovery hasn't.  Failing to reach a portal other
+// than hostDataIP only costs a path, so it isn't fatal.
+func loginToISCSIPortals(
+	targetName, hostDataIP string, targets []ISCSIDiscoveryInfo, allowedPortals []string, createNodes bool,
+) error {
+
+	sessions, err := getISCSISessionInfo()
+	if err != nil {
+		return fmt.Errorf("could not check for iSCSI sessions: %v", err)
+	}
+
+	for _, portalIP := range portalsWithoutSessions(targets, sessions, targetName, allowedPortals) {
+		if createNodes {
+			err = createISCSINode(targetName, portalIP)
+		}
+		if err == nil {
+			err = LoginISCSITarget(targetName, portalIP)
+		}
+		if err != nil {
+			if portalIP == hostDataIP {
+				return fmt.Errorf("login to iSCSI target failed: %v", err)
 			}
+			log.WithFields(log.Fields{
+				"targetName": targetName,
+				"portal":     portalIP,
+				"error":      err,
+			}).Warning("Could not log in to iSCSI portal, the target will have fewer paths.")
 		}
+	}
 
-		if targetIndex == -1 {
-			return fmt.Errorf("iSCSI discovery found no targets with portal %s", hostDataIP)
-		}
+	// Recheck to ensure a session is now open
+	sessionExists, err := ISCSISessionExists(hostDataIP)
+	if err != nil {
+		return fmt.Errorf("could not recheck for iSCSI session: %v", err)
+	}
+	if !sessionExists {
+		return fmt.Errorf("expected iSCSI session %v NOT found, please login to the iSCSI portal", hostDataIP)
+	}
 
-		// To enable multipath, log in to each discovered target with the same IQN (target name)
-		targetName := targets[targetIndex].TargetName
-		for _, target := range targets {
-			if target.TargetName == targetName {
+	log.WithField("hostDataIP", hostDataIP).Debug("Found session to iSCSI portal.")
 
-				// Log in to target
-				err = LoginISCSITarget(target.TargetName, target.PortalIP)
-				if err != nil {
-					return fmt.Errorf("login to iSCSI target failed: %v", err)
-				}
-			}
+	return nil
+}
+
+// createISCSINode creates the node record for one portal of an iSCSI target, as discovery would.
+func createISCSINode(iqn, portal string) error {
+
+	log.WithFields(log.Fields{
+		"IQN":    iqn,
+		"Portal": portal,
+	}).Debug(">>>> osutils.createISCSINode")
+	defer log.Debug("<<<< osutils.createISCSINode")
+
+	args := []string{"-m", "node", "-T", iqn, "-p", portal + ":3260", "--op", "new"}
+
+	if _, err := execIscsiadmCommand(args...); err != nil {
+		log.WithField("error", err).Error("Error creating iSCSI node record.")
+		return err
+	}
+	return nil
+}
+
+// portalsWithoutSessions returns the IPs of the discovered portals for an iSCSI target to which
+// this host has no session, limited to the allowed portals if any are listed.
+func portalsWithoutSessions(
+	targets []ISCSIDiscoveryInfo, sessions []ISCSISessionInfo, targetName string, allowedPortals []string,
+) []string {
+
+	allowed := make(map[string]bool)
+	for _, portal := range allowedPortals {
+		allowed[portal] = true
+	}
+
+	loggedIn := make(map[string]bool)
+	for _, session := range sessions {
+		if session.TargetName == targetName {
+			loggedIn[session.PortalIP] = true
 		}
+	}
 
-		// Recheck to ensure a session is now open
-		sessionExists, err = ISCSISessionExists(hostDataIP)
-		if err != nil {
-			return fmt.Errorf("could not recheck for iSCSI session: %v", err)
+	portals := make([]string, 0)
+	for _, target := range targets {
+		if target.TargetName == targetName && !loggedIn[target.PortalIP] &&
+			(len(allowed) == 0 || allowed[target.PortalIP]) {
+			loggedIn[target.PortalIP] = true
+			portals = append(portals, target.PortalIP)
 		}
-		if !sessionExists {
-			return fmt.Errorf("expected iSCSI session %v NOT found, please login to the iSCSI portal", hostDataIP)
+	}
+	return portals
+}
+
+// ISCSILogoutIfUnused logs out of all sessions to an iSCSI target if no LUNs from that target
+// remain attached to this host, so that detaching the last volume leaves the host as it was found.
+func ISCSILogoutIfUnused(iSCSINodeName string) error {
+
+	fields := log.Fields{"iSCSINodeName": iSCSINodeName}
+	log.WithFields(fields).Debug(">>>> osutils.ISCSILogoutIfUnused")
+	defer log.WithFields(fields).Debug("<<<< osutils.ISCSILogoutIfUnused")
+
+	hostSessionMap := getISCSIHostSessionMapForTarget(iSCSINodeName)
+	if len(hostSessionMap) == 0 {
+		log.WithFields(fields).Debug("No sessions to iSCSI target.")
+		return nil
+	}
+
+	for hostNumber, sessionNumber := range hostSessionMap {
+		pattern := fmt.Sprintf("/sys/class/scsi_host/host%d/device/session%d/iscsi_session/session%d/device/target%d:0:0/%d:0:0:*",
+			hostNumber, sessionNumber, sessionNumber, hostNumber, hostNumber)
+		if luns, err := filepath.Glob(pattern); err != nil {
+			return fmt.Errorf("could not check for LUNs on iSCSI target %s: %v", iSCSINodeName, err)
+		} else if len(luns) > 0 {
+			log.WithFields(log.Fields{
+				"iSCSINodeName": iSCSINodeName,
+				"luns":          len(luns),
+			}).Debug("iSCSI target still has LUNs attached, not logging out.")
+			return nil
 		}
 	}
 
-	log.WithField("hostDataIP", hostDataIP).Debug("Found session to iSCSI portal.")
+	if _, err := execIscsiadmCommand("-m", "node", "-T", iSCSINodeName, "-u"); err != nil {
+		log.WithField("error", err).Error("Error logging out of iSCSI target.")
+		return err
+	}
 
+	log.WithFields(fields).Debug("Logged out of iSCSI target.")
 	return nil
 }
 
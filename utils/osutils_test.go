@@ -0,0 +1,133 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestPortalsWithoutSessions(t *testing.T) {
+	log.Debug("Running TestPortalsWithoutSessions...")
+
+	iqn := "iqn.1992-08.com.netapp:sn.afbb1784f77411e582f8080027e22798:vs.3"
+	otherIqn := "iqn.1992-08.com.netapp:sn.afbb1784f77411e582f8080027e22798:vs.4"
+
+	targets := []ISCSIDiscoveryInfo{
+		{Portal: "10.0.207.7:3260,1028", PortalIP: "10.0.207.7", TargetName: iqn},
+		{Portal: "10.0.207.8:3260,1029", PortalIP: "10.0.207.8", TargetName: iqn},
+		{Portal: "10.0.207.9:3260,1030", PortalIP: "10.0.207.9", TargetName: iqn},
+		{Portal: "10.0.207.10:3260,1031", PortalIP: "10.0.207.10", TargetName: otherIqn},
+	}
+
+	tests := []struct {
+		name     string
+		sessions []ISCSISessionInfo
+		allowed  []string
+		expected []string
+	}{
+		{"no sessions", []ISCSISessionInfo{}, nil, []string{"10.0.207.7", "10.0.207.8", "10.0.207.9"}},
+		{
+			"one session",
+			[]ISCSISessionInfo{{SID: "3", PortalIP: "10.0.207.7", TargetName: iqn}},
+			nil,
+			[]string{"10.0.207.8", "10.0.207.9"},
+		},
+		{
+			"allowed portals",
+			[]ISCSISessionInfo{{SID: "3", PortalIP: "10.0.207.7", TargetName: iqn}},
+			[]string{"10.0.207.7", "10.0.207.9"},
+			[]string{"10.0.207.9"},
+		},
+		{
+			"all sessions",
+			[]ISCSISessionInfo{
+				{SID: "3", PortalIP: "10.0.207.7", TargetName: iqn},
+				{SID: "4", PortalIP: "10.0.207.8", TargetName: iqn},
+				{SID: "5", PortalIP: "10.0.207.9", TargetName: iqn},
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"session to another target",
+			[]ISCSISessionInfo{{SID: "3", PortalIP: "10.0.207.8", TargetName: otherIqn}},
+			nil,
+			[]string{"10.0.207.7", "10.0.207.8", "10.0.207.9"},
+		},
+	}
+
+	for _, test := range tests {
+		portals := portalsWithoutSessions(targets, test.sessions, iqn, test.allowed)
+		if !reflect.DeepEqual(portals, test.expected) {
+			t.Errorf("%s: expected portals %v, got %v", test.name, test.expected, portals)
+		}
+	}
+}
+
+func TestMultipathMissingPaths(t *testing.T) {
+	log.Debug("Running TestMultipathMissingPaths...")
+
+	devices := []string{"sdb", "sdc", "sdd"}
+
+	if missing := multipathMissingPaths(devices, []string{"sdb", "sdc", "sdd"}); len(missing) != 0 {
+		t.Errorf("Expected no missing paths, got %v", missing)
+	}
+	if missing := multipathMissingPaths(devices, []string{"sdd", "sdb"}); !reflect.DeepEqual(missing, []string{"sdc"}) {
+		t.Errorf("Expected missing path sdc, got %v", missing)
+	}
+	if missing := multipathMissingPaths(devices, []string{}); !reflect.DeepEqual(missing, devices) {
+		t.Errorf("Expected all paths missing, got %v", missing)
+	}
+}
+
+func TestNAAWWID(t *testing.T) {
+	log.Debug("Running TestNAAWWID...")
+
+	for naa, expected := range map[string]string{
+		"":                                     "",
+		"600A098038303053453F463045727A31":     "naa.600a098038303053453f463045727a31",
+		"6f47acc100000000707a427300000001":     "naa.6f47acc100000000707a427300000001",
+		"naa.6f47acc100000000707a427300000001": "naa.6f47acc100000000707a427300000001",
+	} {
+		if wwid := NAAWWID(naa); wwid != expected {
+			t.Errorf("Expected NAAWWID(%q) == %q, got %q", naa, expected, wwid)
+		}
+	}
+}
+
+func TestFilesystemPermissions(t *testing.T) {
+	log.Debug("Running TestFilesystemPermissions...")
+
+	for _, test := range []struct {
+		owner, mode string
+		valid       bool
+	}{
+		{"", "", true},
+		{"1000", "", true},
+		{"1000:2000", "0775", true},
+		{"", "2770", true},
+		{"root", "", false},
+		{"1000:", "", false},
+		{"-1", "", false},
+		{"", "0778", false},
+		{"", "17777", false},
+	} {
+		err := ValidateFilesystemPermissions(test.owner, test.mode)
+		if test.valid && err != nil {
+			t.Errorf("Expected owner %q and mode %q to be valid: %v", test.owner, test.mode, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected owner %q and mode %q to be rejected", test.owner, test.mode)
+		}
+	}
+
+	if uid, gid, _ := parseFilesystemOwner("1000"); uid != 1000 || gid != -1 {
+		t.Errorf("Expected uid 1000 and unchanged gid, got %d:%d", uid, gid)
+	}
+	if mode, _ := parseFilesystemMode("2775"); mode != os.ModeSetgid|0775 {
+		t.Errorf("Expected setgid mode 0775, got %v", mode)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// smbGlobalMappingScript maps an SMB share for the whole host, so that containers can reach it,
+// unless it is mapped already.  Its arguments are passed in the environment rather than on the
+// command line, so that the password isn't exposed and no value is interpreted by PowerShell.
+const smbGlobalMappingScript = `$ErrorActionPreference = 'Stop'
+if (-not (Get-SmbGlobalMapping -RemotePath $env:TRIDENT_SMB_SHARE -ErrorAction SilentlyContinue)) {
+    $password = ConvertTo-SecureString -String $env:TRIDENT_SMB_PASSWORD -AsPlainText -Force
+    $credential = New-Object System.Management.Automation.PSCredential($env:TRIDENT_SMB_USERNAME, $password)
+    New-SmbGlobalMapping -RemotePath $env:TRIDENT_SMB_SHARE -Credential $credential | Out-Null
+}`
+
+// MapSMBShare makes a path on an SMB share, given as \\server\share\path, available at the
+// supplied location on a Windows host.  The share is mapped for the whole host, as Windows
+// containers can only reach global mappings, and the location is made a symbolic link to the
+// path.  The mapping is kept when the path is unmapped, as other volumes may be reached through it.
+func MapSMBShare(path, mountpoint string, credentials CIFSCredentials) error {
+
+	log.WithFields(log.Fields{
+		"path":       path,
+		"mountpoint": mountpoint,
+		"username":   credentials.Username,
+	}).Debug(">>>> smb.MapSMBShare")
+	defer log.Debug("<<<< smb.MapSMBShare")
+
+	share, err := smbShareRoot(path)
+	if err != nil {
+		return err
+	}
+	env, err := smbMappingEnv(share, credentials)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", smbGlobalMappingScript)
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("could not map SMB share %s: %v; %s", share, err, strings.TrimSpace(string(out)))
+	}
+
+	// The mountpoint may have been created as an empty directory, which the link replaces
+	if info, err := os.Lstat(mountpoint); err == nil && info.IsDir() {
+		if err = os.Remove(mountpoint); err != nil {
+			return fmt.Errorf("could not replace mountpoint %s with a link to %s: %v", mountpoint, path, err)
+		}
+	}
+	if err = os.Symlink(path, mountpoint); err != nil {
+		return fmt.Errorf("could not link mountpoint %s to %s: %v", mountpoint, path, err)
+	}
+	return nil
+}
+
+// UnmapSMBShare removes the link by which MapSMBShare made a path on an SMB share available.
+func UnmapSMBShare(mountpoint string) error {
+
+	log.WithField("mountpoint", mountpoint).Debug(">>>> smb.UnmapSMBShare")
+	defer log.Debug("<<<< smb.UnmapSMBShare")
+
+	info, err := os.Lstat(mountpoint)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("mountpoint %s is not a link to an SMB share", mountpoint)
+	}
+	// Removing a link to a directory leaves the directory's contents alone
+	if err = os.Remove(mountpoint); err != nil {
+		return fmt.Errorf("could not unmap SMB share from mountpoint %s: %v", mountpoint, err)
+	}
+	return nil
+}
+
+// smbShareRoot returns the share, \\server\share, that holds a path given as \\server\share\path.
+func smbShareRoot(path string) (string, error) {
+	uncPath := strings.Replace(path, "/", `\`, -1)
+	parts := strings.Split(strings.TrimPrefix(uncPath, `\\`), `\`)
+	if !strings.HasPrefix(uncPath, `\\`) || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf(`invalid SMB path %s; it must be \\server\share\path`, path)
+	}
+	return `\\` + parts[0] + `\` + parts[1], nil
+}
+
+// smbMappingEnv returns the environment in which smbGlobalMappingScript maps a share.
+func smbMappingEnv(share string, credentials CIFSCredentials) ([]string, error) {
+
+	if credentials.Username == "" {
+		return nil, fmt.Errorf("mapping SMB share %s for Windows containers needs a username", share)
+	}
+	username := credentials.Username
+	if credentials.Domain != "" {
+		username = credentials.Domain + `\` + username
+	}
+	for _, value := range []string{share, username, credentials.Password} {
+		if strings.ContainsAny(value, "\x00\r\n") {
+			return nil, fmt.Errorf("SMB share names and credentials must not contain line breaks")
+		}
+	}
+
+	return []string{
+		"TRIDENT_SMB_SHARE=" + share,
+		"TRIDENT_SMB_USERNAME=" + username,
+		"TRIDENT_SMB_PASSWORD=" + credentials.Password,
+	}, nil
+}
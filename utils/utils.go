@@ -134,6 +134,14 @@ func Pow(x int64, y int) int64 {
 	return result
 }
 
+// RoundUpSize rounds a size up to the next multiple of unit.  A unit of zero leaves the size unchanged.
+func RoundUpSize(size, unit uint64) uint64 {
+	if unit == 0 || size%unit == 0 {
+		return size
+	}
+	return (size/unit + 1) * unit
+}
+
 // ConvertSizeToBytes converts size to bytes; see also https://en.wikipedia.org/wiki/Kilobyte
 func ConvertSizeToBytes(s string) (string, error) {
 
@@ -253,6 +261,16 @@ func GetV(opts map[string]string, keys string, defaultValue string) string {
 	return defaultValue
 }
 
+// SliceContainsString returns true if the slice contains the string.
+func SliceContainsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // RandomString returns a string of the specified length consisting only of alphabetic characters.
 func RandomString(strSize int) string {
 	chars := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -28,6 +28,24 @@ func TestPow(t *testing.T) {
 	}
 }
 
+func TestRoundUpSize(t *testing.T) {
+	log.Debug("Running TestRoundUpSize...")
+
+	for _, test := range []struct {
+		size, unit, expected uint64
+	}{
+		{size: 1000000000, unit: 1048576, expected: 1000341504},
+		{size: 1073741824, unit: 1073741824, expected: 1073741824},
+		{size: 1073741825, unit: 1073741824, expected: 2147483648},
+		{size: 4097, unit: 4096, expected: 8192},
+		{size: 12345, unit: 0, expected: 12345},
+	} {
+		if got := RoundUpSize(test.size, test.unit); got != test.expected {
+			t.Errorf("Expected RoundUpSize(%d, %d) == %d but was %d", test.size, test.unit, test.expected, got)
+		}
+	}
+}
+
 func TestConvertSizeToBytes(t *testing.T) {
 	log.Debug("Running TestConvertSizeToBytes...")
 